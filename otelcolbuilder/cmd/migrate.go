@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/configmigrator"
+)
+
+// The main function is generated by the builder, so the migrate commands are run before it,
+// e.g. otelcol-sumo migrate --sources sources.json --user-properties user.properties
+// or otelcol-sumo migrate-fluent --input fluent-bit.conf --parsers parsers.conf
+func init() {
+	if len(os.Args) < 2 {
+		return
+	}
+
+	var cmd interface {
+		SetArgs(args []string)
+		Execute() error
+	}
+	switch os.Args[1] {
+	case "migrate":
+		cmd = configmigrator.NewCommand()
+	case "migrate-fluent":
+		cmd = configmigrator.NewFluentCommand()
+	default:
+		return
+	}
+	cmd.SetArgs(os.Args[2:])
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service"
+	"go.opentelemetry.io/collector/service/parserprovider"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sumologicexporter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/collectorlogsreceiver"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/configprovider"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/crashreport"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain"
+)
+
+// sumoComponents are the components with non-upstreamed changes, listed in .otelcol-builder.yaml,
+// whose panics are recovered and written to the crash reports
+var sumoComponents = crashreport.Components{
+	Receivers: []config.Type{
+		"awsfirehose", "azureeventhub", "collector_logs", "docker_stats", "googlecloudpubsub", "journald",
+		"k8s_events", "sumo_http", "telegraf", "windows_event_log",
+	},
+	Processors: []config.Type{
+		"attribute_normalization", "cardinality_limiter", "cascading_filter", "geoip", "grok", "histogram_rebucket",
+		"k8s_tagger", "log_dedup", "log_sampling", "logs_cascading_filter", "logs_to_metrics", "metric_frequency",
+		"metrics_rollup", "multiline", "pii_masking", "prometheus_naming", "red_metrics", "resource_compaction",
+		"schema_translation", "severity", "source", "span_events_to_logs", "span_to_resource", "staleness",
+		"sumologic_resource_detection", "sumologic_syslog", "text_parser", "threshold_events", "timestamp",
+		"tokenization", "user_agent",
+	},
+	Exporters: []config.Type{"sumologic"},
+}
+
+// newCollectorSettings returns the settings of the collector. The main function generated by the builder
+// is patched to use them, see the build target of the Makefile.
+func newCollectorSettings(info component.BuildInfo, factories component.Factories) service.CollectorSettings {
+	shutdownTimeout, err := drain.TimeoutFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	coordinator := drain.NewCoordinator(shutdownTimeout)
+	factories.Exporters["sumologic"] = sumologicexporter.NewFactoryWithDrain(coordinator)
+	factories.Processors["cascading_filter"] = cascadingfilterprocessor.NewFactoryWithDrain(coordinator)
+	go exitWhenShutdownNotComplete(coordinator)
+
+	parserProvider, layered := newParserProvider()
+
+	return service.CollectorSettings{
+		BuildInfo:      info,
+		Factories:      crashreport.NewWriter(crashreport.DirFromEnv()).Wrap(factories, sumoComponents),
+		ParserProvider: parserProvider,
+		LoggingOptions: []zap.Option{
+			layered.LoggingOption(),
+			collectorlogsreceiver.LoggingOption(),
+			coordinator.LoggingOption(),
+		},
+	}
+}
+
+// exitWhenShutdownNotComplete starts the shutdown deadline of the coordinator on SIGTERM or SIGINT,
+// which the collector shuts down on, and exits if the collector is still running when it expires.
+func exitWhenShutdownNotComplete(coordinator *drain.Coordinator) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	<-signals
+
+	<-coordinator.Start().Done()
+	log.Fatal(coordinator.NotComplete())
+}
+
+// newParserProvider returns the provider of the configuration: the --config file merged with
+// the overrides configured in it, then with the --set flags, which take precedence, and then
+// with the self-monitoring pipelines, the memory limiter and the ballast sized from the memory limit
+// and the persistence set up in the --storage-dir.
+// The --storage-dir flag is removed from the arguments, as it isn't a flag of the collector.
+func newParserProvider() (parserprovider.ParserProvider, *configprovider.Layered) {
+	storageDir, args, err := configprovider.StorageDirFromArgs(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	os.Args = append(os.Args[:1], args...)
+
+	layered := configprovider.NewLayered(parserprovider.NewFile())
+	selfMonitoring := configprovider.NewSelfMonitoring(parserprovider.NewSetFlag(layered))
+	memoryLimits := configprovider.NewMemoryLimits(selfMonitoring)
+	return configprovider.NewStorage(memoryLimits, storageDir), layered
+}
@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/configvalidator"
+)
+
+// The main function is generated by the builder, so the validate command is run before it,
+// e.g. otelcol-sumo validate --config config.yaml
+func init() {
+	if len(os.Args) < 2 || os.Args[1] != "validate" {
+		return
+	}
+
+	factories, err := components()
+	if err != nil {
+		log.Fatalf("failed to build components: %v", err)
+	}
+	parserProvider, _ := newParserProvider()
+	cmd := configvalidator.NewCommand(factories, parserProvider)
+	cmd.SetArgs(os.Args[2:])
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -15,6 +15,9 @@
 package sumologicexporter
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
 	"go.opentelemetry.io/collector/config"
@@ -30,6 +33,10 @@ type Config struct {
 	exporterhelper.QueueSettings  `mapstructure:"sending_queue"`
 	exporterhelper.RetrySettings  `mapstructure:"retry_on_failure"`
 
+	// Storage is the ID of the storage extension (e.g. file_storage) persisting the requests of the sending queue,
+	// so the requests left in it are sent after a restart. It requires the sending queue to be enabled.
+	Storage string `mapstructure:"storage"`
+
 	// Compression encoding format, either empty string, gzip or deflate (default gzip)
 	// Empty string means no compression
 	CompressEncoding CompressEncodingType `mapstructure:"compress_encoding"`
@@ -91,6 +98,60 @@ type Config struct {
 	ClearLogsTimestamp bool `mapstructure:"clear_logs_timestamp"`
 }
 
+// Validate checks if the formats, the compression and the endpoint of the exporter are valid
+func (cfg *Config) Validate() error {
+	switch cfg.LogFormat {
+	case JSONFormat:
+	case TextFormat:
+	case OTLPLogFormat:
+	default:
+		return fmt.Errorf("unexpected log format: %s", cfg.LogFormat)
+	}
+
+	switch cfg.MetricFormat {
+	case GraphiteFormat:
+	case Carbon2Format:
+	case PrometheusFormat:
+	case OTLPMetricFormat:
+	default:
+		return fmt.Errorf("unexpected metric format: %s", cfg.MetricFormat)
+	}
+
+	switch cfg.TraceFormat {
+	case OTLPTraceFormat:
+	default:
+		return fmt.Errorf("unexpected trace format: %s", cfg.TraceFormat)
+	}
+
+	switch cfg.CompressEncoding {
+	case GZIPCompression:
+	case DeflateCompression:
+	case NoCompression:
+	default:
+		return fmt.Errorf("unexpected compression encoding: %s", cfg.CompressEncoding)
+	}
+
+	if len(cfg.HTTPClientSettings.Endpoint) == 0 && cfg.HTTPClientSettings.Auth == nil {
+		return errors.New("no endpoint and no auth extension specified")
+	}
+
+	if _, err := url.Parse(cfg.HTTPClientSettings.Endpoint); err != nil {
+		return fmt.Errorf("failed parsing endpoint URL: %s; err: %w",
+			cfg.HTTPClientSettings.Endpoint, err,
+		)
+	}
+
+	if cfg.Storage != "" {
+		if _, err := config.NewIDFromString(cfg.Storage); err != nil {
+			return fmt.Errorf("invalid storage extension id %q: %w", cfg.Storage, err)
+		}
+		if !cfg.QueueSettings.Enabled {
+			return errors.New("storage requires the sending_queue to be enabled")
+		}
+	}
+	return nil
+}
+
 // CreateDefaultHTTPClientSettings returns default http client settings
 func CreateDefaultHTTPClientSettings() confighttp.HTTPClientSettings {
 	return confighttp.HTTPClientSettings{
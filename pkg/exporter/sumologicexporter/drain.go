@@ -0,0 +1,303 @@
+// Copyright 2021 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// drainPollInterval is the interval of checking whether all the requests have been sent on shutdown
+const drainPollInterval = 100 * time.Millisecond
+
+type requestKey struct{}
+
+// request is a request accepted by the exporter, which is tracked until it is sent or dropped
+type request struct {
+	firstAttempt time.Time
+	// seq is the sequence number of the request in the persistent queue, 0 if it's not persisted
+	seq uint64
+}
+
+// requestTracker tracks the requests accepted by the exporter, including the queued requests
+// and the requests waiting for a retry, so that they can be drained on shutdown
+type requestTracker struct {
+	mu       sync.Mutex
+	requests map[*request]struct{}
+	// retry is false when the failed requests are dropped right away
+	retry bool
+	// maxAge is the time after the first attempt, after which the request is dropped by the retry mechanism
+	maxAge time.Duration
+	// queue holds the persisted requests, which are removed from it when they are done
+	queue *persistentQueue
+}
+
+func newRequestTracker(cfg *Config) *requestTracker {
+	t := &requestTracker{
+		requests: map[*request]struct{}{},
+		retry:    cfg.RetrySettings.Enabled,
+	}
+	if cfg.RetrySettings.MaxElapsedTime > 0 {
+		t.maxAge = cfg.RetrySettings.MaxElapsedTime + cfg.RetrySettings.MaxInterval
+	}
+	return t
+}
+
+// accept starts tracking the request, carried by the returned context through the queue and the retries
+func (t *requestTracker) accept(ctx context.Context) (context.Context, *request) {
+	return t.acceptPersisted(ctx, 0)
+}
+
+// acceptPersisted starts tracking the request stored in the persistent queue under the sequence number
+func (t *requestTracker) acceptPersisted(ctx context.Context, seq uint64) (context.Context, *request) {
+	r := &request{seq: seq}
+	t.mu.Lock()
+	t.requests[r] = struct{}{}
+	t.mu.Unlock()
+	return context.WithValue(ctx, requestKey{}, r), r
+}
+
+// done stops tracking the request, which is removed from the persistent queue
+func (t *requestTracker) done(r *request) {
+	t.release(r)
+	t.remove(r)
+}
+
+// release stops tracking the request, which is kept in the persistent queue
+func (t *requestTracker) release(r *request) {
+	t.mu.Lock()
+	delete(t.requests, r)
+	t.mu.Unlock()
+}
+
+func (t *requestTracker) remove(r *request) {
+	if t.queue != nil && r.seq != 0 {
+		t.queue.remove(r.seq)
+	}
+}
+
+// attempted records the result of sending the request; the request is done unless it is going to be retried
+func (t *requestTracker) attempted(ctx context.Context, start time.Time, err error) {
+	r, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return
+	}
+	if err == nil || !t.retry || consumererror.IsPermanent(err) {
+		t.done(r)
+		return
+	}
+	t.mu.Lock()
+	if r.firstAttempt.IsZero() {
+		r.firstAttempt = start
+	}
+	t.mu.Unlock()
+}
+
+// pending returns the number of the requests which haven't been sent yet
+func (t *requestTracker) pending() int {
+	var dropped []*request
+	t.mu.Lock()
+	if t.maxAge > 0 {
+		for r := range t.requests {
+			// the retry mechanism has given up on the request
+			if !r.firstAttempt.IsZero() && time.Since(r.firstAttempt) > t.maxAge {
+				delete(t.requests, r)
+				dropped = append(dropped, r)
+			}
+		}
+	}
+	pending := len(t.requests)
+	t.mu.Unlock()
+
+	for _, r := range dropped {
+		t.remove(r)
+	}
+	return pending
+}
+
+// drain waits for the pending requests to be sent, until the shutdown deadline,
+// and reports the requests left behind
+func (se *sumologicexporter) drain(ctx context.Context) {
+	ctx, cancel := se.drainCoordinator.Context(ctx)
+	defer cancel()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		pending := se.requests.pending()
+		if pending == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			se.drainCoordinator.Report("exporter/"+se.config.ID().String(), pending, "requests")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pushLogs sends the logs and records the result of the attempt
+func (se *sumologicexporter) pushLogs(ctx context.Context, ld pdata.Logs) error {
+	start := time.Now()
+	err := se.pushLogsData(ctx, ld)
+	se.requests.attempted(ctx, start, err)
+	return err
+}
+
+// pushMetrics sends the metrics and records the result of the attempt
+func (se *sumologicexporter) pushMetrics(ctx context.Context, md pdata.Metrics) error {
+	start := time.Now()
+	err := se.pushMetricsData(ctx, md)
+	se.requests.attempted(ctx, start, err)
+	return err
+}
+
+// pushTraces sends the traces and records the result of the attempt
+func (se *sumologicexporter) pushTraces(ctx context.Context, td pdata.Traces) error {
+	start := time.Now()
+	err := se.pushTracesData(ctx, td)
+	se.requests.attempted(ctx, start, err)
+	return err
+}
+
+// The unmarshalers of the requests stored in the persistent queue, see the marshalers in sender.go
+var (
+	logsUnmarshaler    = otlp.NewProtobufLogsUnmarshaler()
+	metricsUnmarshaler = otlp.NewProtobufMetricsUnmarshaler()
+	tracesUnmarshaler  = otlp.NewProtobufTracesUnmarshaler()
+)
+
+// drainingLogsExporter drains the pending requests before the exporter helper is shut down,
+// as the exporter helper drops the queued requests. When the storage is configured, it persists
+// the accepted requests and sends the requests left by the previous run again on start.
+type drainingLogsExporter struct {
+	component.LogsExporter
+	se *sumologicexporter
+}
+
+func (e *drainingLogsExporter) Start(ctx context.Context, host component.Host) error {
+	if err := e.LogsExporter.Start(ctx, host); err != nil {
+		return err
+	}
+	e.se.startReplay(func(ctx context.Context, data []byte) error {
+		ld, err := logsUnmarshaler.UnmarshalLogs(data)
+		if err != nil {
+			return consumererror.Permanent(err)
+		}
+		return e.LogsExporter.ConsumeLogs(ctx, ld)
+	})
+	return nil
+}
+
+func (e *drainingLogsExporter) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
+	ctx, r, err := e.se.accept(ctx, func() ([]byte, error) { return logsMarshaler.MarshalLogs(ld) })
+	if err != nil {
+		return err
+	}
+	err = e.LogsExporter.ConsumeLogs(ctx, ld)
+	if err != nil {
+		e.se.requests.done(r)
+	}
+	return err
+}
+
+func (e *drainingLogsExporter) Shutdown(ctx context.Context) error {
+	e.se.stopReplaying()
+	e.se.drain(ctx)
+	return e.LogsExporter.Shutdown(ctx)
+}
+
+// drainingMetricsExporter drains the pending requests before the exporter helper is shut down
+type drainingMetricsExporter struct {
+	component.MetricsExporter
+	se *sumologicexporter
+}
+
+func (e *drainingMetricsExporter) Start(ctx context.Context, host component.Host) error {
+	if err := e.MetricsExporter.Start(ctx, host); err != nil {
+		return err
+	}
+	e.se.startReplay(func(ctx context.Context, data []byte) error {
+		md, err := metricsUnmarshaler.UnmarshalMetrics(data)
+		if err != nil {
+			return consumererror.Permanent(err)
+		}
+		return e.MetricsExporter.ConsumeMetrics(ctx, md)
+	})
+	return nil
+}
+
+func (e *drainingMetricsExporter) ConsumeMetrics(ctx context.Context, md pdata.Metrics) error {
+	ctx, r, err := e.se.accept(ctx, func() ([]byte, error) { return metricsMarshaler.MarshalMetrics(md) })
+	if err != nil {
+		return err
+	}
+	err = e.MetricsExporter.ConsumeMetrics(ctx, md)
+	if err != nil {
+		e.se.requests.done(r)
+	}
+	return err
+}
+
+func (e *drainingMetricsExporter) Shutdown(ctx context.Context) error {
+	e.se.stopReplaying()
+	e.se.drain(ctx)
+	return e.MetricsExporter.Shutdown(ctx)
+}
+
+// drainingTracesExporter drains the pending requests before the exporter helper is shut down
+type drainingTracesExporter struct {
+	component.TracesExporter
+	se *sumologicexporter
+}
+
+func (e *drainingTracesExporter) Start(ctx context.Context, host component.Host) error {
+	if err := e.TracesExporter.Start(ctx, host); err != nil {
+		return err
+	}
+	e.se.startReplay(func(ctx context.Context, data []byte) error {
+		td, err := tracesUnmarshaler.UnmarshalTraces(data)
+		if err != nil {
+			return consumererror.Permanent(err)
+		}
+		return e.TracesExporter.ConsumeTraces(ctx, td)
+	})
+	return nil
+}
+
+func (e *drainingTracesExporter) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	ctx, r, err := e.se.accept(ctx, func() ([]byte, error) { return tracesMarshaler.MarshalTraces(td) })
+	if err != nil {
+		return err
+	}
+	err = e.TracesExporter.ConsumeTraces(ctx, td)
+	if err != nil {
+		e.se.requests.done(r)
+	}
+	return err
+}
+
+func (e *drainingTracesExporter) Shutdown(ctx context.Context) error {
+	e.se.stopReplaying()
+	e.se.drain(ctx)
+	return e.TracesExporter.Shutdown(ctx)
+}
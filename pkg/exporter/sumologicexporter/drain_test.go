@@ -0,0 +1,133 @@
+// Copyright 2021 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain"
+)
+
+func createDrainTestLogs() pdata.Logs {
+	logs := pdata.NewLogs()
+	logs.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty().Body().SetStringVal("Example log")
+	return logs
+}
+
+func TestShutdownDrainsQueue(t *testing.T) {
+	var received int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&received, 1)
+	}))
+	defer srv.Close()
+
+	cfg := createTestConfig()
+	cfg.HTTPClientSettings.Endpoint = srv.URL
+	cfg.HTTPClientSettings.Auth = nil
+	cfg.QueueSettings.Enabled = true
+	cfg.QueueSettings.NumConsumers = 1
+
+	exp, err := NewFactory().CreateLogsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, exp.ConsumeLogs(context.Background(), createDrainTestLogs()))
+	}
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	assert.EqualValues(t, 5, atomic.LoadInt32(&received))
+}
+
+func TestShutdownLeavesRequestsAfterDeadline(t *testing.T) {
+	var received int32
+	unblock := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-unblock
+		atomic.AddInt32(&received, 1)
+	}))
+	defer srv.Close()
+	defer close(unblock)
+
+	cfg := createTestConfig()
+	cfg.HTTPClientSettings.Endpoint = srv.URL
+	cfg.HTTPClientSettings.Auth = nil
+	cfg.HTTPClientSettings.Timeout = 500 * time.Millisecond
+	cfg.QueueSettings.Enabled = true
+	cfg.QueueSettings.NumConsumers = 1
+
+	coordinator := drain.NewCoordinator(200 * time.Millisecond)
+	exp, err := NewFactoryWithDrain(coordinator).CreateLogsExporter(context.Background(),
+		componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, exp.ConsumeLogs(context.Background(), createDrainTestLogs()))
+	}
+
+	coordinator.Start()
+	start := time.Now()
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	assert.Less(t, int64(time.Since(start)), int64(2*time.Second))
+	assert.EqualValues(t, 0, atomic.LoadInt32(&received))
+	assert.Equal(t, []string{"exporter/sumologic requests: 3"}, coordinator.Summary())
+}
+
+func TestRequestTracker(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.RetrySettings.MaxElapsedTime = time.Minute
+	cfg.RetrySettings.MaxInterval = time.Second
+	tracker := newRequestTracker(cfg)
+
+	sentCtx, _ := tracker.accept(context.Background())
+	retriedCtx, _ := tracker.accept(context.Background())
+	droppedCtx, _ := tracker.accept(context.Background())
+	expiredCtx, _ := tracker.accept(context.Background())
+	assert.Equal(t, 4, tracker.pending())
+
+	tracker.attempted(sentCtx, time.Now(), nil)
+	tracker.attempted(retriedCtx, time.Now(), errors.New("temporary failure"))
+	tracker.attempted(droppedCtx, time.Now(), consumererror.Permanent(errors.New("permanent failure")))
+	tracker.attempted(expiredCtx, time.Now().Add(-2*time.Minute), errors.New("temporary failure"))
+	assert.Equal(t, 1, tracker.pending())
+
+	tracker.attempted(retriedCtx, time.Now(), nil)
+	assert.Equal(t, 0, tracker.pending())
+}
+
+func TestRequestTrackerRetryDisabled(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.RetrySettings.Enabled = false
+	tracker := newRequestTracker(cfg)
+
+	ctx, _ := tracker.accept(context.Background())
+	tracker.attempted(ctx, time.Now(), errors.New("temporary failure"))
+	assert.Equal(t, 0, tracker.pending())
+}
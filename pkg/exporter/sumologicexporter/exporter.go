@@ -16,7 +16,6 @@ package sumologicexporter
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -26,8 +25,11 @@ import (
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/sumologicextension"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain"
 )
 
 const (
@@ -46,48 +48,24 @@ type sumologicexporter struct {
 	dataUrlMetrics      string
 	dataUrlLogs         string
 	dataUrlTraces       string
+	requests            *requestTracker
+	drainCoordinator    *drain.Coordinator
+	logger              *zap.Logger
+	// queue persists the accepted requests when the storage is configured
+	queue      *persistentQueue
+	stopReplay func()
 }
 
 func initExporter(cfg *Config) (*sumologicexporter, error) {
-	switch cfg.LogFormat {
-	case JSONFormat:
-	case TextFormat:
-	case OTLPLogFormat:
-	default:
-		return nil, fmt.Errorf("unexpected log format: %s", cfg.LogFormat)
-	}
-
-	switch cfg.MetricFormat {
-	case GraphiteFormat:
-	case Carbon2Format:
-	case PrometheusFormat:
-	case OTLPMetricFormat:
-	default:
-		return nil, fmt.Errorf("unexpected metric format: %s", cfg.MetricFormat)
-	}
-
-	switch cfg.TraceFormat {
-	case OTLPTraceFormat:
-	default:
-		return nil, fmt.Errorf("unexpected trace format: %s", cfg.TraceFormat)
-	}
-
-	switch cfg.CompressEncoding {
-	case GZIPCompression:
-	case DeflateCompression:
-	case NoCompression:
-	default:
-		return nil, fmt.Errorf("unexpected compression encoding: %s", cfg.CompressEncoding)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
-	if len(cfg.HTTPClientSettings.Endpoint) == 0 && cfg.HTTPClientSettings.Auth == nil {
-		return nil, errors.New("no endpoint and no auth extension specified")
+	if err := fips.ValidateEndpoint(cfg.ID().String(), cfg.HTTPClientSettings.Endpoint); err != nil {
+		return nil, err
 	}
-
-	if _, err := url.Parse(cfg.HTTPClientSettings.Endpoint); err != nil {
-		return nil, fmt.Errorf("failed parsing endpoint URL: %s; err: %w",
-			cfg.HTTPClientSettings.Endpoint, err,
-		)
+	if err := fips.ValidateTLSClient(cfg.ID().String(), cfg.HTTPClientSettings.TLSSetting); err != nil {
+		return nil, err
 	}
 
 	if cfg.TranslateAttributes {
@@ -122,6 +100,9 @@ func initExporter(cfg *Config) (*sumologicexporter, error) {
 		filter:              f,
 		prometheusFormatter: pf,
 		graphiteFormatter:   gf,
+		requests:            newRequestTracker(cfg),
+		drainCoordinator:    drain.NewCoordinator(drain.DefaultTimeout),
+		logger:              zap.NewNop(),
 	}
 
 	return se, nil
@@ -130,16 +111,22 @@ func initExporter(cfg *Config) (*sumologicexporter, error) {
 func newLogsExporter(
 	cfg *Config,
 	params component.ExporterCreateSettings,
+	coordinator *drain.Coordinator,
 ) (component.LogsExporter, error) {
 	se, err := initExporter(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize the logs exporter: %w", err)
 	}
+	se.drainCoordinator = coordinator
+	se.logger = params.Logger
+	if err := se.setPersistentQueue("logs"); err != nil {
+		return nil, err
+	}
 
-	return exporterhelper.NewLogsExporter(
+	exp, err := exporterhelper.NewLogsExporter(
 		cfg,
 		params,
-		se.pushLogsData,
+		se.pushLogs,
 		// Disable exporterhelper Timeout, since we are using a custom mechanism
 		// within exporter itself
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
@@ -148,21 +135,32 @@ func newLogsExporter(
 		exporterhelper.WithStart(se.start),
 		exporterhelper.WithShutdown(se.shutdown),
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &drainingLogsExporter{LogsExporter: exp, se: se}, nil
 }
 
 func newMetricsExporter(
 	cfg *Config,
 	params component.ExporterCreateSettings,
+	coordinator *drain.Coordinator,
 ) (component.MetricsExporter, error) {
 	se, err := initExporter(cfg)
 	if err != nil {
 		return nil, err
 	}
+	se.drainCoordinator = coordinator
+	se.logger = params.Logger
+	if err := se.setPersistentQueue("metrics"); err != nil {
+		return nil, err
+	}
 
-	return exporterhelper.NewMetricsExporter(
+	exp, err := exporterhelper.NewMetricsExporter(
 		cfg,
 		params,
-		se.pushMetricsData,
+		se.pushMetrics,
 		// Disable exporterhelper Timeout, since we are using a custom mechanism
 		// within exporter itself
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
@@ -171,21 +169,32 @@ func newMetricsExporter(
 		exporterhelper.WithStart(se.start),
 		exporterhelper.WithShutdown(se.shutdown),
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &drainingMetricsExporter{MetricsExporter: exp, se: se}, nil
 }
 
 func newTracesExporter(
 	cfg *Config,
 	params component.ExporterCreateSettings,
+	coordinator *drain.Coordinator,
 ) (component.TracesExporter, error) {
 	se, err := initExporter(cfg)
 	if err != nil {
 		return nil, err
 	}
+	se.drainCoordinator = coordinator
+	se.logger = params.Logger
+	if err := se.setPersistentQueue("traces"); err != nil {
+		return nil, err
+	}
 
-	return exporterhelper.NewTracesExporter(
+	exp, err := exporterhelper.NewTracesExporter(
 		cfg,
 		params,
-		se.pushTracesData,
+		se.pushTraces,
 		// Disable exporterhelper Timeout, since we are using a custom mechanism
 		// within exporter itself
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
@@ -194,6 +203,11 @@ func newTracesExporter(
 		exporterhelper.WithStart(se.start),
 		exporterhelper.WithShutdown(se.shutdown),
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &drainingTracesExporter{TracesExporter: exp, se: se}, nil
 }
 
 // pushLogsData groups data with common metadata and sends them as separate batched requests.
@@ -506,9 +520,16 @@ func (se *sumologicexporter) start(ctx context.Context, host component.Host) err
 	}
 
 	se.client = client
+
+	if se.queue != nil {
+		return se.queue.start(ctx, host)
+	}
 	return nil
 }
 
-func (se *sumologicexporter) shutdown(context.Context) error {
+func (se *sumologicexporter) shutdown(ctx context.Context) error {
+	if se.queue != nil {
+		return se.queue.shutdown(ctx)
+	}
 	return nil
 }
@@ -21,6 +21,8 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain"
 )
 
 const (
@@ -30,15 +32,26 @@ const (
 
 // NewFactory returns a new factory for the sumologic exporter.
 func NewFactory() component.ExporterFactory {
+	return NewFactoryWithDrain(drain.NewCoordinator(drain.DefaultTimeout))
+}
+
+// NewFactoryWithDrain returns a new factory for the sumologic exporter, whose exporters send the pending
+// requests on shutdown until the deadline of the coordinator, which the requests left behind are reported to.
+func NewFactoryWithDrain(coordinator *drain.Coordinator) component.ExporterFactory {
+	f := &factory{drain: coordinator}
 	return exporterhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
-		exporterhelper.WithLogs(createLogsExporter),
-		exporterhelper.WithMetrics(createMetricsExporter),
-		exporterhelper.WithTraces(createTracesExporter),
+		exporterhelper.WithLogs(f.createLogsExporter),
+		exporterhelper.WithMetrics(f.createMetricsExporter),
+		exporterhelper.WithTraces(f.createTracesExporter),
 	)
 }
 
+type factory struct {
+	drain *drain.Coordinator
+}
+
 func createDefaultConfig() config.Exporter {
 	qs := exporterhelper.DefaultQueueSettings()
 	qs.Enabled = false
@@ -65,12 +78,12 @@ func createDefaultConfig() config.Exporter {
 	}
 }
 
-func createLogsExporter(
+func (f *factory) createLogsExporter(
 	_ context.Context,
 	params component.ExporterCreateSettings,
 	cfg config.Exporter,
 ) (component.LogsExporter, error) {
-	exp, err := newLogsExporter(cfg.(*Config), params)
+	exp, err := newLogsExporter(cfg.(*Config), params, f.drain)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the logs exporter: %w", err)
 	}
@@ -78,12 +91,12 @@ func createLogsExporter(
 	return exp, nil
 }
 
-func createMetricsExporter(
+func (f *factory) createMetricsExporter(
 	_ context.Context,
 	params component.ExporterCreateSettings,
 	cfg config.Exporter,
 ) (component.MetricsExporter, error) {
-	exp, err := newMetricsExporter(cfg.(*Config), params)
+	exp, err := newMetricsExporter(cfg.(*Config), params, f.drain)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the metrics exporter: %w", err)
 	}
@@ -91,12 +104,12 @@ func createMetricsExporter(
 	return exp, nil
 }
 
-func createTracesExporter(
+func (f *factory) createTracesExporter(
 	_ context.Context,
 	params component.ExporterCreateSettings,
 	cfg config.Exporter,
 ) (component.TracesExporter, error) {
-	exp, err := newTracesExporter(cfg.(*Config), params)
+	exp, err := newTracesExporter(cfg.(*Config), params, f.drain)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the traces exporter: %w", err)
 	}
@@ -63,4 +63,5 @@ func TestCreateDefaultConfig(t *testing.T) {
 		QueueSettings: qs,
 	})
 	assert.NoError(t, configcheck.ValidateConfig(cfg))
+	assert.NoError(t, cfg.(*Config).Validate())
 }
@@ -0,0 +1,486 @@
+// Copyright 2021 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	queueHeadKey = "head"
+	queueTailKey = "tail"
+
+	// maxBatchedWrites is the maximum number of the requests stored with a single storage write
+	maxBatchedWrites = 256
+	// removalFlushInterval is the interval of deleting the removed requests from the storage,
+	// unless they are deleted earlier, together with the stored requests
+	removalFlushInterval = time.Second
+	// recoveryGap is the number of consecutive missing requests after which the end of the queue
+	// is assumed, when its tail can't be read
+	recoveryGap = 1024
+)
+
+// persistentQueue keeps the requests accepted by the exporter in a storage extension until they are
+// sent or dropped, so the requests left in the sending queue on shutdown, or on a crash, are sent
+// after the restart. The requests are stored under consecutive sequence numbers, from the head,
+// the oldest request which may be still stored, to the tail, the sequence number of the next request.
+//
+// The writes are batched: the requests stored concurrently are written together by a single writer,
+// along with the removed requests and the head and the tail. The removed requests are deleted with
+// the next write, or after removalFlushInterval, so the requests removed right before a crash may be
+// sent again after the restart.
+type persistentQueue struct {
+	storageID  config.ComponentID
+	exporterID config.ComponentID
+	// signal is the name of the storage, as the exporters of all the signals share the exporter ID
+	signal string
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	client  storage.Client
+	head    uint64
+	tail    uint64
+	pending map[uint64]struct{}
+	// removed are the requests which haven't been deleted from the storage yet
+	removed []uint64
+	// writtenHead and writtenTail are the head and the tail in the storage
+	writtenHead uint64
+	writtenTail uint64
+
+	writes  chan *queueWrite
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// queueWrite is a request waiting for the writer to store it
+type queueWrite struct {
+	seq  uint64
+	data []byte
+	done chan error
+}
+
+func newPersistentQueue(cfg *Config, signal string, logger *zap.Logger) (*persistentQueue, error) {
+	storageID, err := config.NewIDFromString(cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage extension id %q: %w", cfg.Storage, err)
+	}
+	return &persistentQueue{
+		storageID:  storageID,
+		exporterID: cfg.ID(),
+		signal:     signal,
+		logger:     logger,
+		pending:    map[uint64]struct{}{},
+		writes:     make(chan *queueWrite),
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}, nil
+}
+
+// start gets the storage client, reads the head and the tail of the queue and starts the writer
+func (q *persistentQueue) start(ctx context.Context, host component.Host) error {
+	ext, found := host.GetExtensions()[q.storageID]
+	if !found {
+		return fmt.Errorf("storage extension %q not found", q.storageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("extension %q is not a storage extension", q.storageID)
+	}
+	client, err := storageExt.GetClient(ctx, component.KindExporter, q.exporterID, q.signal)
+	if err != nil {
+		return fmt.Errorf("failed to get the storage client: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.client = client
+	if err = q.readState(ctx); err != nil {
+		q.client = nil
+		return err
+	}
+	// The stored requests are pending until they are sent again
+	for seq := q.head; seq < q.tail; seq++ {
+		q.pending[seq] = struct{}{}
+	}
+	go q.write(client)
+	return nil
+}
+
+// readState reads the head and the tail of the queue. When they are corrupt, e.g. after a crash,
+// they are recovered from the stored requests.
+func (q *persistentQueue) readState(ctx context.Context) error {
+	head, headValid, err := q.readSeq(ctx, queueHeadKey)
+	if err != nil {
+		return err
+	}
+	tail, tailValid, err := q.readSeq(ctx, queueTailKey)
+	if err != nil {
+		return err
+	}
+	q.writtenHead, q.writtenTail = head, tail
+
+	// Sequence number 0 marks the requests which are not persisted
+	if head == 0 {
+		head = 1
+	}
+	if !tailValid {
+		if tail, err = q.findTail(ctx, head); err != nil {
+			return err
+		}
+	}
+	if tail < head {
+		tail = head
+	}
+	if !headValid {
+		if head, err = q.findHead(ctx, head, tail); err != nil {
+			return err
+		}
+	}
+	q.head, q.tail = head, tail
+	return nil
+}
+
+// findTail returns the sequence number following the last stored request,
+// looking for it from the head until recoveryGap consecutive requests are missing
+func (q *persistentQueue) findTail(ctx context.Context, head uint64) (uint64, error) {
+	tail := head
+	for from := head; from < tail+recoveryGap; from += recoveryGap {
+		found, err := q.findStored(ctx, from, from+recoveryGap)
+		if err != nil {
+			return 0, err
+		}
+		for _, seq := range found {
+			tail = seq + 1
+		}
+	}
+	return tail, nil
+}
+
+// findHead returns the sequence number of the first stored request between head and tail, or the tail
+func (q *persistentQueue) findHead(ctx context.Context, head uint64, tail uint64) (uint64, error) {
+	for from := head; from < tail; from += recoveryGap {
+		to := from + recoveryGap
+		if to > tail {
+			to = tail
+		}
+		found, err := q.findStored(ctx, from, to)
+		if err != nil {
+			return 0, err
+		}
+		if len(found) > 0 {
+			return found[0], nil
+		}
+	}
+	return tail, nil
+}
+
+// findStored returns the sequence numbers of the requests stored in the range, reading them with a single batch
+func (q *persistentQueue) findStored(ctx context.Context, from uint64, to uint64) ([]uint64, error) {
+	ops := make([]storage.Operation, 0, to-from)
+	for seq := from; seq < to; seq++ {
+		ops = append(ops, storage.GetOperation(storedRequestKey(seq)))
+	}
+	if err := q.client.Batch(ctx, ops...); err != nil {
+		return nil, fmt.Errorf("failed to recover the persistent queue: %w", err)
+	}
+	var found []uint64
+	for i, op := range ops {
+		if op.Value != nil {
+			found = append(found, from+uint64(i))
+		}
+	}
+	return found, nil
+}
+
+func (q *persistentQueue) shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	client := q.client
+	q.client = nil
+	q.mu.Unlock()
+	if client == nil {
+		return nil
+	}
+	// The writer deletes the removed requests before it stops
+	close(q.stop)
+	<-q.stopped
+	return client.Close(ctx)
+}
+
+// stored returns the range of the sequence numbers of the requests stored by the previous run
+func (q *persistentQueue) stored() (uint64, uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.head, q.tail
+}
+
+// get returns the stored request, nil if it has already been removed
+func (q *persistentQueue) get(ctx context.Context, seq uint64) ([]byte, error) {
+	q.mu.Lock()
+	client := q.client
+	_, pending := q.pending[seq]
+	q.mu.Unlock()
+	if client == nil || !pending {
+		return nil, nil
+	}
+	return client.Get(ctx, storedRequestKey(seq))
+}
+
+// put stores the request and returns its sequence number, once it's written by the writer
+func (q *persistentQueue) put(ctx context.Context, data []byte) (uint64, error) {
+	q.mu.Lock()
+	if q.client == nil {
+		q.mu.Unlock()
+		return 0, fmt.Errorf("the persistent queue of %s is not started", q.exporterID)
+	}
+	seq := q.tail
+	q.tail++
+	q.pending[seq] = struct{}{}
+	q.mu.Unlock()
+
+	w := &queueWrite{seq: seq, data: data, done: make(chan error, 1)}
+	var err error
+	select {
+	case q.writes <- w:
+		err = <-w.done
+	case <-q.stopped:
+		err = fmt.Errorf("the persistent queue of %s is stopped", q.exporterID)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		// The request may have been written, it's deleted with the next write
+		q.remove(seq)
+		return 0, fmt.Errorf("failed to store the request: %w", err)
+	}
+	return seq, nil
+}
+
+// remove removes the request, which has been sent or dropped, and moves the head past the removed requests.
+// The request is deleted from the storage by the writer.
+func (q *persistentQueue) remove(seq uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.pending[seq]; !ok {
+		return
+	}
+	delete(q.pending, seq)
+	q.removed = append(q.removed, seq)
+	for q.head < q.tail {
+		if _, ok := q.pending[q.head]; ok {
+			break
+		}
+		q.head++
+	}
+}
+
+// write writes the requests to the storage, batching the requests stored meanwhile,
+// and deletes the removed requests, until the queue is shut down
+func (q *persistentQueue) write(client storage.Client) {
+	defer close(q.stopped)
+	ticker := time.NewTicker(removalFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case w := <-q.writes:
+			batch := []*queueWrite{w}
+		collect:
+			for len(batch) < maxBatchedWrites {
+				select {
+				case w = <-q.writes:
+					batch = append(batch, w)
+				default:
+					break collect
+				}
+			}
+			err := q.flush(client, batch)
+			for _, w := range batch {
+				w.done <- err
+			}
+		case <-ticker.C:
+			_ = q.flush(client, nil)
+		case <-q.stop:
+			if err := q.flush(client, nil); err != nil {
+				q.logger.Warn("Failed to delete the removed requests, they are sent again after the restart", zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+// flush writes the requests, the deletions of the removed requests and the changed head and tail with a single batch
+func (q *persistentQueue) flush(client storage.Client, batch []*queueWrite) error {
+	ops := make([]storage.Operation, 0, len(batch)+2)
+	for _, w := range batch {
+		ops = append(ops, storage.SetOperation(storedRequestKey(w.seq), w.data))
+	}
+
+	q.mu.Lock()
+	removed := q.removed
+	q.removed = nil
+	head, tail := q.head, q.tail
+	q.mu.Unlock()
+	for _, seq := range removed {
+		ops = append(ops, storage.DeleteOperation(storedRequestKey(seq)))
+	}
+	// The tail may be past the requests in the batch, the missing requests are skipped after the restart
+	if tail != q.writtenTail {
+		ops = append(ops, storage.SetOperation(queueTailKey, []byte(strconv.FormatUint(tail, 10))))
+	}
+	if head != q.writtenHead {
+		ops = append(ops, storage.SetOperation(queueHeadKey, []byte(strconv.FormatUint(head, 10))))
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	if err := client.Batch(context.Background(), ops...); err != nil {
+		// The deletions are retried with the next write
+		q.mu.Lock()
+		q.removed = append(q.removed, removed...)
+		q.mu.Unlock()
+		return err
+	}
+	q.writtenHead, q.writtenTail = head, tail
+	return nil
+}
+
+// readSeq reads the sequence number stored under the key, 0 if it's missing, and false if it's corrupt
+func (q *persistentQueue) readSeq(ctx context.Context, key string) (uint64, bool, error) {
+	value, err := q.client.Get(ctx, key)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read the %s of the persistent queue: %w", key, err)
+	}
+	if value == nil {
+		return 0, true, nil
+	}
+	seq, err := strconv.ParseUint(string(value), 10, 64)
+	if err != nil {
+		q.logger.Warn("Invalid sequence number in the persistent queue, recovering it from the stored requests",
+			zap.String("key", key), zap.ByteString("value", value))
+		return 0, false, nil
+	}
+	return seq, true, nil
+}
+
+func storedRequestKey(seq uint64) string {
+	return "request-" + strconv.FormatUint(seq, 10)
+}
+
+// replayRetryInterval is the interval of retrying to send a stored request again, e.g. when the sending queue is full
+const replayRetryInterval = time.Second
+
+// setPersistentQueue persists the requests of the signal in the storage extension, if it's configured
+func (se *sumologicexporter) setPersistentQueue(signal string) error {
+	if se.config.Storage == "" {
+		return nil
+	}
+	q, err := newPersistentQueue(se.config, signal, se.logger)
+	if err != nil {
+		return err
+	}
+	se.queue = q
+	se.requests.queue = q
+	return nil
+}
+
+// accept starts tracking the request and stores it in the persistent queue, if it's enabled
+func (se *sumologicexporter) accept(ctx context.Context, marshal func() ([]byte, error)) (context.Context, *request, error) {
+	if se.queue == nil {
+		ctx, r := se.requests.accept(ctx)
+		return ctx, r, nil
+	}
+	data, err := marshal()
+	if err != nil {
+		return ctx, nil, consumererror.Permanent(err)
+	}
+	seq, err := se.queue.put(ctx, data)
+	if err != nil {
+		return ctx, nil, err
+	}
+	ctx, r := se.requests.acceptPersisted(ctx, seq)
+	return ctx, r, nil
+}
+
+// startReplay sends the requests left in the persistent queue by the previous run again, with consume
+func (se *sumologicexporter) startReplay(consume func(context.Context, []byte) error) {
+	if se.queue == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	se.stopReplay = func() {
+		cancel()
+		<-done
+	}
+
+	head, tail := se.queue.stored()
+	go func() {
+		defer close(done)
+		for seq := head; seq < tail; seq++ {
+			if !se.replay(ctx, seq, consume) {
+				return
+			}
+		}
+	}()
+}
+
+// replay sends the stored request again, retrying until it is accepted, e.g. by the sending queue.
+// It returns false when the replay is stopped.
+func (se *sumologicexporter) replay(ctx context.Context, seq uint64, consume func(context.Context, []byte) error) bool {
+	for {
+		data, err := se.queue.get(ctx, seq)
+		if err == nil && data == nil {
+			// The request has been removed already
+			se.queue.remove(seq)
+			return true
+		}
+		if err == nil {
+			reqCtx, r := se.requests.acceptPersisted(context.Background(), seq)
+			if err = consume(reqCtx, data); err == nil {
+				return true
+			}
+			if consumererror.IsPermanent(err) {
+				se.requests.done(r)
+				se.logger.Warn("Dropping the stored request", zap.Uint64("seq", seq), zap.Error(err))
+				return true
+			}
+			se.requests.release(r)
+		}
+		se.logger.Debug("Failed to send the stored request, retrying", zap.Uint64("seq", seq), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(replayRetryInterval):
+		}
+	}
+}
+
+// stopReplaying stops sending the stored requests, which are sent after the next restart
+func (se *sumologicexporter) stopReplaying() {
+	if se.stopReplay != nil {
+		se.stopReplay()
+	}
+}
@@ -0,0 +1,445 @@
+// Copyright 2021 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain"
+)
+
+type mapStorageClient struct {
+	sync.Mutex
+	data map[string][]byte
+}
+
+func (c *mapStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.data[key], nil
+}
+
+func (c *mapStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.Lock()
+	defer c.Unlock()
+	c.data[key] = value
+	return nil
+}
+
+func (c *mapStorageClient) Delete(_ context.Context, key string) error {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.data, key)
+	return nil
+}
+
+func (c *mapStorageClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	for _, op := range ops {
+		var err error
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = c.Get(ctx, op.Key)
+		case storage.Set:
+			err = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = c.Delete(ctx, op.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *mapStorageClient) Close(context.Context) error {
+	return nil
+}
+
+// storedRequests returns the number of the requests in the storage
+func (c *mapStorageClient) storedRequests() int {
+	c.Lock()
+	defer c.Unlock()
+	stored := 0
+	for key := range c.data {
+		if strings.HasPrefix(key, "request-") {
+			stored++
+		}
+	}
+	return stored
+}
+
+type mapStorageExtension struct {
+	client storage.Client
+}
+
+func (e *mapStorageExtension) Start(context.Context, component.Host) error {
+	return nil
+}
+
+func (e *mapStorageExtension) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *mapStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return e.client, nil
+}
+
+type storageHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *storageHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func newStorageHost(client *mapStorageClient) component.Host {
+	return &storageHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewID("file_storage"): &mapStorageExtension{client: client},
+		},
+	}
+}
+
+func createPersistentTestLogs(body string) pdata.Logs {
+	logs := pdata.NewLogs()
+	logs.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty().Body().SetStringVal(body)
+	return logs
+}
+
+func TestPersistentQueueSendsRequestsAfterRestart(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []string
+		failing  = true
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received = append(received, string(body))
+	}))
+	defer srv.Close()
+
+	cfg := createTestConfig()
+	cfg.HTTPClientSettings.Endpoint = srv.URL
+	cfg.HTTPClientSettings.Auth = nil
+	cfg.QueueSettings.Enabled = true
+	cfg.QueueSettings.NumConsumers = 1
+	cfg.RetrySettings.InitialInterval = 10 * time.Millisecond
+	cfg.RetrySettings.MaxInterval = 10 * time.Millisecond
+	cfg.Storage = "file_storage"
+	client := &mapStorageClient{data: map[string][]byte{}}
+	host := newStorageHost(client)
+
+	// The requests can't be sent before the shutdown, so they are left in the storage
+	coordinator := drain.NewCoordinator(100 * time.Millisecond)
+	exp, err := NewFactoryWithDrain(coordinator).CreateLogsExporter(context.Background(),
+		componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), host))
+	require.NoError(t, exp.ConsumeLogs(context.Background(), createPersistentTestLogs("first log")))
+	require.NoError(t, exp.ConsumeLogs(context.Background(), createPersistentTestLogs("second log")))
+	require.NoError(t, exp.Shutdown(context.Background()))
+	assert.Equal(t, 2, client.storedRequests())
+	assert.Equal(t, []string{"exporter/sumologic requests: 2"}, coordinator.Summary())
+
+	// The restarted exporter sends them and removes them from the storage
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	exp, err = NewFactory().CreateLogsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), host))
+	require.NoError(t, exp.ConsumeLogs(context.Background(), createPersistentTestLogs("third log")))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	assert.ElementsMatch(t, []string{"first log", "second log", "third log"}, received)
+	assert.Equal(t, 0, client.storedRequests())
+	assert.Equal(t, client.data["tail"], client.data["head"])
+}
+
+func TestPersistentQueueMovesHeadPastRemovedRequests(t *testing.T) {
+	client := &mapStorageClient{data: map[string][]byte{}}
+	cfg := createTestConfig()
+	cfg.Storage = "file_storage"
+	q, err := newPersistentQueue(cfg, "logs", zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, q.start(context.Background(), newStorageHost(client)))
+
+	var seqs []uint64
+	for i := 0; i < 3; i++ {
+		seq, err := q.put(context.Background(), []byte("request"))
+		require.NoError(t, err)
+		seqs = append(seqs, seq)
+	}
+	assert.Equal(t, []uint64{1, 2, 3}, seqs)
+	assert.Equal(t, 3, client.storedRequests())
+
+	// The head stays at the oldest request until it is removed
+	q.remove(2)
+	head, tail := q.stored()
+	assert.Equal(t, uint64(1), head)
+	assert.Equal(t, uint64(4), tail)
+
+	q.remove(1)
+	head, _ = q.stored()
+	assert.Equal(t, uint64(3), head)
+
+	// The removed requests are deleted on shutdown at the latest
+	// and the restarted queue continues from the stored head and tail
+	require.NoError(t, q.shutdown(context.Background()))
+	assert.Equal(t, 1, client.storedRequests())
+	q = startTestPersistentQueue(t, cfg, client)
+	head, tail = q.stored()
+	assert.Equal(t, uint64(3), head)
+	assert.Equal(t, uint64(4), tail)
+	data, err := q.get(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("request"), data)
+}
+
+// blockingStorageClient blocks the first batch until it's released
+type blockingStorageClient struct {
+	*mapStorageClient
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	batches int
+}
+
+func (c *blockingStorageClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	c.once.Do(func() {
+		close(c.entered)
+		<-c.release
+	})
+	c.mu.Lock()
+	c.batches++
+	c.mu.Unlock()
+	return c.mapStorageClient.Batch(ctx, ops...)
+}
+
+func TestPersistentQueueBatchesConcurrentWrites(t *testing.T) {
+	client := &blockingStorageClient{
+		mapStorageClient: &mapStorageClient{data: map[string][]byte{}},
+		entered:          make(chan struct{}),
+		release:          make(chan struct{}),
+	}
+	cfg := createTestConfig()
+	cfg.Storage = "file_storage"
+	q, err := newPersistentQueue(cfg, "logs", zap.NewNop())
+	require.NoError(t, err)
+	host := &storageHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewID("file_storage"): &mapStorageExtension{client: client},
+		},
+	}
+	require.NoError(t, q.start(context.Background(), host))
+
+	// The first request blocks the writer, the requests stored meanwhile are written with the next batch
+	var wg sync.WaitGroup
+	put := func() {
+		defer wg.Done()
+		_, err := q.put(context.Background(), []byte("request"))
+		assert.NoError(t, err)
+	}
+	wg.Add(1)
+	go put()
+	<-client.entered
+	const concurrent = 10
+	wg.Add(concurrent)
+	for i := 0; i < concurrent; i++ {
+		go put()
+	}
+	assert.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return q.tail == concurrent+2
+	}, 5*time.Second, time.Millisecond)
+	// Give the writes the time to line up for the writer
+	time.Sleep(50 * time.Millisecond)
+	close(client.release)
+	wg.Wait()
+
+	assert.Equal(t, concurrent+1, client.storedRequests())
+	client.mu.Lock()
+	assert.Equal(t, 2, client.batches)
+	client.mu.Unlock()
+	require.NoError(t, q.shutdown(context.Background()))
+}
+
+func startTestPersistentQueue(t *testing.T, cfg *Config, client *mapStorageClient) *persistentQueue {
+	q, err := newPersistentQueue(cfg, "logs", zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, q.start(context.Background(), newStorageHost(client)))
+	t.Cleanup(func() {
+		_ = q.shutdown(context.Background())
+	})
+	return q
+}
+
+func TestPersistentQueueRecoversCorruptHeadAndTail(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt map[string][]byte
+	}{
+		{name: "corrupt head", corrupt: map[string][]byte{"head": []byte("garbage")}},
+		{name: "corrupt tail", corrupt: map[string][]byte{"tail": []byte("-1")}},
+		{name: "corrupt head and tail", corrupt: map[string][]byte{"head": {0xff}, "tail": {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mapStorageClient{data: map[string][]byte{}}
+			cfg := createTestConfig()
+			cfg.Storage = "file_storage"
+
+			// The requests 3 and 5 are left in the storage
+			q := startTestPersistentQueue(t, cfg, client)
+			for i := 0; i < 5; i++ {
+				_, err := q.put(context.Background(), []byte("request"))
+				require.NoError(t, err)
+			}
+			q.remove(1)
+			q.remove(2)
+			q.remove(4)
+			require.NoError(t, q.shutdown(context.Background()))
+			require.Equal(t, 2, client.storedRequests())
+			for key, value := range tt.corrupt {
+				client.data[key] = value
+			}
+
+			q = startTestPersistentQueue(t, cfg, client)
+			head, tail := q.stored()
+			assert.Equal(t, uint64(3), head)
+			assert.Equal(t, uint64(6), tail)
+
+			// The recovered queue doesn't overwrite the stored requests
+			seq, err := q.put(context.Background(), []byte("new request"))
+			require.NoError(t, err)
+			assert.Equal(t, uint64(6), seq)
+			for _, seq := range []uint64{3, 5} {
+				data, err := q.get(context.Background(), seq)
+				require.NoError(t, err)
+				assert.Equal(t, []byte("request"), data)
+			}
+			require.NoError(t, q.shutdown(context.Background()))
+			assert.Equal(t, []byte("3"), client.data["head"])
+			assert.Equal(t, []byte("7"), client.data["tail"])
+		})
+	}
+}
+
+func TestPersistentQueueSendsRequestsAfterCrash(t *testing.T) {
+	// The exporter crashes with the requests it couldn't send
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	cfg := createTestConfig()
+	cfg.HTTPClientSettings.Endpoint = unavailable.URL
+	cfg.HTTPClientSettings.Auth = nil
+	cfg.QueueSettings.Enabled = true
+	cfg.QueueSettings.NumConsumers = 1
+	cfg.RetrySettings.InitialInterval = 10 * time.Millisecond
+	cfg.RetrySettings.MaxInterval = 10 * time.Millisecond
+	cfg.Storage = "file_storage"
+	client := &mapStorageClient{data: map[string][]byte{}}
+	host := newStorageHost(client)
+
+	crashed, err := NewFactoryWithDrain(drain.NewCoordinator(100*time.Millisecond)).CreateLogsExporter(context.Background(),
+		componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, crashed.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, crashed.Shutdown(context.Background()))
+	}()
+	require.NoError(t, crashed.ConsumeLogs(context.Background(), createPersistentTestLogs("first log")))
+	require.NoError(t, crashed.ConsumeLogs(context.Background(), createPersistentTestLogs("second log")))
+	// The head is lost in the crash, e.g. written partially
+	client.Lock()
+	client.data["head"] = []byte("1\x00")
+	client.Unlock()
+
+	// The exporter started on the same storage sends the requests
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, string(body))
+	}))
+	defer srv.Close()
+	restartedCfg := *cfg
+	restartedCfg.HTTPClientSettings.Endpoint = srv.URL
+	exp, err := NewFactory().CreateLogsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), &restartedCfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), host))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []string{"first log", "second log"}, received)
+}
+
+func TestStorageRequiresSendingQueue(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.HTTPClientSettings.Endpoint = "http://localhost"
+	cfg.Storage = "file_storage"
+	assert.EqualError(t, cfg.Validate(), "storage requires the sending_queue to be enabled")
+
+	cfg.QueueSettings.Enabled = true
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnosticsextension
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Config has the configuration of the diagnostics extension.
+type Config struct {
+	config.ExtensionSettings      `mapstructure:"-"`
+	confighttp.HTTPServerSettings `mapstructure:",squash"`
+
+	// Metrics are the regular expressions of the names of the reported metrics, all of them are reported when empty
+	Metrics []string `mapstructure:"metrics"`
+	// ErrorMetrics is the regular expression of the names of the counters whose increases are reported as errors
+	ErrorMetrics string `mapstructure:"error_metrics"`
+	// RecentErrors is the number of the most recent errors kept
+	RecentErrors int `mapstructure:"recent_errors"`
+	// PollInterval is the period in which the counters are checked for the errors
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// Profiling has the settings of the pprof and expvar endpoints
+	Profiling ProfilingConfig `mapstructure:"profiling"`
+}
+
+// ProfilingConfig has the settings of the pprof and expvar endpoints, which are disabled by default.
+type ProfilingConfig struct {
+	// Enabled serves the endpoints, requiring the token
+	Enabled bool `mapstructure:"enabled"`
+	// TokenFile is the file with the token authorizing the requests, it's generated when it doesn't exist
+	TokenFile string `mapstructure:"token_file"`
+	// AllowedNetworks are the networks, in the CIDR notation, of the clients allowed to use the endpoints,
+	// the local host only when empty
+	AllowedNetworks []string `mapstructure:"allowed_networks"`
+}
+
+const (
+	defaultEndpoint     = "localhost:55690"
+	defaultErrorMetrics = `(failed|refused|dropped|error)`
+	defaultRecentErrors = 20
+	defaultPollInterval = 10 * time.Second
+)
+
+// defaultAllowedNetworks allow the profiling requests from the local host only
+var defaultAllowedNetworks = []string{"127.0.0.0/8", "::1/128"}
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must not be empty")
+	}
+	for _, expression := range cfg.Metrics {
+		if _, err := regexp.Compile(expression); err != nil {
+			return fmt.Errorf("invalid metrics regex %q: %w", expression, err)
+		}
+	}
+	if _, err := regexp.Compile(cfg.ErrorMetrics); err != nil {
+		return fmt.Errorf("invalid error_metrics regex %q: %w", cfg.ErrorMetrics, err)
+	}
+	if cfg.RecentErrors < 0 {
+		return fmt.Errorf("recent_errors must not be negative, got %d", cfg.RecentErrors)
+	}
+	if cfg.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive, got %s", cfg.PollInterval)
+	}
+	if cfg.Profiling.Enabled {
+		return cfg.validateProfiling()
+	}
+	return nil
+}
+
+// validateProfiling checks the profiling settings. Without TLS the token would be sent in plain text,
+// so the endpoint must be bound to the loopback interface.
+func (cfg *Config) validateProfiling() error {
+	if cfg.Profiling.TokenFile == "" {
+		return errors.New("profiling.token_file must be set when profiling is enabled")
+	}
+	if _, err := cfg.Profiling.networks(); err != nil {
+		return err
+	}
+	if cfg.TLSSetting == nil && !isLoopback(cfg.Endpoint) {
+		return fmt.Errorf("profiling requires the endpoint to be bound to the loopback interface, or tls to be set, got %s", cfg.Endpoint)
+	}
+	return nil
+}
+
+// networks returns the parsed allowed networks
+func (cfg *ProfilingConfig) networks() ([]*net.IPNet, error) {
+	cidrs := cfg.AllowedNetworks
+	if len(cidrs) == 0 {
+		cidrs = defaultAllowedNetworks
+	}
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid profiling.allowed_networks entry %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// isLoopback checks if the address is bound to the loopback interface only
+func isLoopback(endpoint string) bool {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnosticsextension
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, createDefaultConfig(), cfg.Extensions[config.NewID(typeStr)])
+
+	id := config.NewIDWithName(typeStr, "custom")
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings:  config.NewExtensionSettings(id),
+			HTTPServerSettings: confighttp.HTTPServerSettings{Endpoint: "0.0.0.0:8080"},
+			Metrics:            []string{"^exporter/", "^cascading_"},
+			ErrorMetrics:       "failed",
+			RecentErrors:       50,
+			PollInterval:       30 * time.Second,
+		},
+		cfg.Extensions[id])
+
+	id = config.NewIDWithName(typeStr, "profiling")
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings:  config.NewExtensionSettings(id),
+			HTTPServerSettings: confighttp.HTTPServerSettings{Endpoint: "localhost:55690"},
+			ErrorMetrics:       defaultErrorMetrics,
+			RecentErrors:       defaultRecentErrors,
+			PollInterval:       defaultPollInterval,
+			Profiling: ProfilingConfig{
+				Enabled:         true,
+				TokenFile:       "/var/lib/otelcol-sumo/diagnostics.token",
+				AllowedNetworks: []string{"127.0.0.1/32"},
+			},
+		},
+		cfg.Extensions[id])
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.NoError(t, createDefaultConfig().(*Config).Validate())
+
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{name: "no endpoint", modify: func(cfg *Config) { cfg.Endpoint = "" }},
+		{name: "invalid metrics regex", modify: func(cfg *Config) { cfg.Metrics = []string{"("} }},
+		{name: "invalid error metrics regex", modify: func(cfg *Config) { cfg.ErrorMetrics = "(" }},
+		{name: "negative recent errors", modify: func(cfg *Config) { cfg.RecentErrors = -1 }},
+		{name: "no poll interval", modify: func(cfg *Config) { cfg.PollInterval = 0 }},
+		{name: "profiling without token file", modify: func(cfg *Config) { cfg.Profiling.Enabled = true }},
+		{name: "invalid allowed network", modify: func(cfg *Config) {
+			cfg.Profiling = ProfilingConfig{Enabled: true, TokenFile: "token", AllowedNetworks: []string{"10.0.0.1"}}
+		}},
+		{name: "profiling on non-loopback address", modify: func(cfg *Config) {
+			cfg.Endpoint = "0.0.0.0:55690"
+			cfg.Profiling = ProfilingConfig{Enabled: true, TokenFile: "token"}
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
+
+func TestValidateProfilingConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Profiling = ProfilingConfig{Enabled: true, TokenFile: "token"}
+	for _, endpoint := range []string{"localhost:55690", "127.0.0.1:55690", "[::1]:55690"} {
+		cfg.Endpoint = endpoint
+		assert.NoError(t, cfg.Validate(), endpoint)
+	}
+
+	// the token is not sent in plain text with TLS, so any address can be used
+	cfg.Endpoint = "0.0.0.0:55690"
+	cfg.TLSSetting = &configtls.TLSServerSetting{}
+	cfg.Profiling.AllowedNetworks = []string{"10.0.0.0/8"}
+	assert.NoError(t, cfg.Validate())
+}
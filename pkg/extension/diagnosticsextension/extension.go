@@ -0,0 +1,282 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnosticsextension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opencensus.io/metric/metricproducer"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// Provider is implemented by the extensions and the exporters reporting their own diagnostics,
+// e.g. the sumologic extension reporting its registration state. The components do not need to import
+// this package, having the method is enough.
+type Provider interface {
+	// Diagnostics returns the state of the component, it needs to be serializable to JSON
+	Diagnostics() map[string]interface{}
+}
+
+const diagnosticsPath = "/diagnostics"
+
+// diagnosticsExtension serves the diagnostics of the collector components over HTTP
+type diagnosticsExtension struct {
+	config       *Config
+	logger       *zap.Logger
+	metrics      []*regexp.Regexp
+	errorMetrics *regexp.Regexp
+	producers    func() []metricproducer.Producer
+	now          func() time.Time
+
+	host      component.Host
+	startTime time.Time
+	server    *http.Server
+	wg        sync.WaitGroup
+	stopCh    chan struct{}
+
+	mu sync.Mutex
+	// counters are the last values of the error counters by their series keys
+	counters     map[string]float64
+	recentErrors []errorReport
+}
+
+// report is the diagnostics document
+type report struct {
+	StartTime    time.Time                  `json:"start_time"`
+	Uptime       string                     `json:"uptime"`
+	Extensions   map[string]componentReport `json:"extensions"`
+	Exporters    map[string]exporterReport  `json:"exporters"`
+	Metrics      []metricReport             `json:"metrics"`
+	RecentErrors []errorReport              `json:"recent_errors"`
+}
+
+type componentReport struct {
+	Diagnostics map[string]interface{} `json:"diagnostics,omitempty"`
+}
+
+type exporterReport struct {
+	DataTypes []string `json:"data_types"`
+	// QueueSize is the number of the batches in the sending queue, when the exporter has one
+	QueueSize   interface{}            `json:"queue_size,omitempty"`
+	Diagnostics map[string]interface{} `json:"diagnostics,omitempty"`
+}
+
+func newDiagnosticsExtension(cfg *Config, logger *zap.Logger) *diagnosticsExtension {
+	e := &diagnosticsExtension{
+		config:       cfg,
+		logger:       logger,
+		errorMetrics: regexp.MustCompile(cfg.ErrorMetrics),
+		producers:    metricproducer.GlobalManager().GetAll,
+		now:          time.Now,
+		stopCh:       make(chan struct{}),
+		counters:     map[string]float64{},
+	}
+	for _, expression := range cfg.Metrics {
+		e.metrics = append(e.metrics, regexp.MustCompile(expression))
+	}
+	return e
+}
+
+// Start starts the HTTP server and the polling of the error counters
+func (e *diagnosticsExtension) Start(_ context.Context, host component.Host) error {
+	e.host = host
+	e.startTime = e.now()
+
+	listener, err := e.config.ToListener()
+	if err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", e.config.Endpoint, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(diagnosticsPath, e.serveDiagnostics)
+	if e.config.Profiling.Enabled {
+		profiling, err := newProfiling(e.config.Profiling, e.logger)
+		if err != nil {
+			_ = listener.Close()
+			return err
+		}
+		profiling.register(mux)
+		e.logger.Info("Serving the profiling endpoints",
+			zap.String("pprof_url", "http://"+listener.Addr().String()+pprofPath),
+			zap.String("expvar_url", "http://"+listener.Addr().String()+expvarPath),
+		)
+	}
+	e.server = e.config.ToServer(mux)
+
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		if err := e.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			host.ReportFatalError(err)
+		}
+	}()
+	go func() {
+		defer e.wg.Done()
+		e.pollLoop()
+	}()
+	e.logger.Info("Serving the diagnostics", zap.String("url", "http://"+listener.Addr().String()+diagnosticsPath))
+	return nil
+}
+
+// Shutdown stops the HTTP server and the polling
+func (e *diagnosticsExtension) Shutdown(ctx context.Context) error {
+	if e.server == nil {
+		return nil
+	}
+	close(e.stopCh)
+	err := e.server.Shutdown(ctx)
+	e.wg.Wait()
+	return err
+}
+
+func (e *diagnosticsExtension) pollLoop() {
+	ticker := time.NewTicker(e.config.PollInterval)
+	defer ticker.Stop()
+	e.poll()
+	for {
+		select {
+		case <-ticker.C:
+			e.poll()
+		case <-e.stopCh:
+			return
+		}
+	}
+}
+
+// poll reads the metrics, records the increases of the error counters and returns the reported metrics
+func (e *diagnosticsExtension) poll() []metricReport {
+	metrics, cumulative := readMetrics(e.producers())
+	now := e.now()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, metric := range metrics {
+		if !cumulative[metric.Name] || !e.errorMetrics.MatchString(metric.Name) {
+			continue
+		}
+		value, ok := numericValue(metric.Value)
+		if !ok {
+			continue
+		}
+		key := seriesKey(metric)
+		// the series appearing after the start are new errors, so their previous value is 0
+		previous := e.counters[key]
+		e.counters[key] = value
+		if value > previous {
+			e.addError(errorReport{Time: now, Metric: metric.Name, Labels: metric.Labels, Increase: value - previous})
+		}
+	}
+	return metrics
+}
+
+// addError keeps the error, dropping the oldest one when there are too many
+func (e *diagnosticsExtension) addError(err errorReport) {
+	if e.config.RecentErrors == 0 {
+		return
+	}
+	if len(e.recentErrors) == e.config.RecentErrors {
+		e.recentErrors = append(e.recentErrors[:0], e.recentErrors[1:]...)
+	}
+	e.recentErrors = append(e.recentErrors, err)
+}
+
+// serveDiagnostics serves the diagnostics document as JSON
+func (e *diagnosticsExtension) serveDiagnostics(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := json.MarshalIndent(e.report(), "", "  ")
+	if err != nil {
+		e.logger.Error("Failed to serialize the diagnostics", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// report collects the diagnostics of the components
+func (e *diagnosticsExtension) report() report {
+	metrics := e.poll()
+	r := report{
+		StartTime:  e.startTime,
+		Uptime:     e.now().Sub(e.startTime).Round(time.Second).String(),
+		Extensions: map[string]componentReport{},
+		Exporters:  map[string]exporterReport{},
+		Metrics:    []metricReport{},
+	}
+
+	for id, extension := range e.host.GetExtensions() {
+		r.Extensions[id.String()] = componentReport{Diagnostics: diagnostics(extension)}
+	}
+
+	queueSizes := map[string]interface{}{}
+	for _, metric := range metrics {
+		if metric.Name == queueSizeMetric {
+			queueSizes[metric.Labels[exporterLabel]] = metric.Value
+		}
+		if e.reported(metric.Name) {
+			r.Metrics = append(r.Metrics, metric)
+		}
+	}
+	for dataType, exporters := range e.host.GetExporters() {
+		for id, exporter := range exporters {
+			name := id.String()
+			exporterReport, ok := r.Exporters[name]
+			if !ok {
+				exporterReport.QueueSize = queueSizes[name]
+				exporterReport.Diagnostics = diagnostics(exporter)
+			}
+			exporterReport.DataTypes = append(exporterReport.DataTypes, string(dataType))
+			sort.Strings(exporterReport.DataTypes)
+			r.Exporters[name] = exporterReport
+		}
+	}
+
+	e.mu.Lock()
+	r.RecentErrors = append([]errorReport{}, e.recentErrors...)
+	e.mu.Unlock()
+	return r
+}
+
+// reported checks if the metric matches one of the configured regular expressions, or if none are configured
+func (e *diagnosticsExtension) reported(name string) bool {
+	if len(e.metrics) == 0 {
+		return true
+	}
+	for _, regex := range e.metrics {
+		if regex.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnostics returns the diagnostics of the component if it reports them
+func diagnostics(c component.Component) map[string]interface{} {
+	if provider, ok := c.(Provider); ok {
+		return provider.Diagnostics()
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnosticsextension
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricproducer"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+// testProducer produces the metrics set by the test
+type testProducer struct {
+	metrics []*metricdata.Metric
+}
+
+func (p *testProducer) Read() []*metricdata.Metric {
+	return p.metrics
+}
+
+func newTestMetric(name string, metricType metricdata.Type, labels map[string]string, value interface{}) *metricdata.Metric {
+	metric := &metricdata.Metric{
+		Descriptor: metricdata.Descriptor{Name: name, Type: metricType},
+	}
+	ts := &metricdata.TimeSeries{Points: []metricdata.Point{{Value: value}}}
+	for key, value := range labels {
+		metric.Descriptor.LabelKeys = append(metric.Descriptor.LabelKeys, metricdata.LabelKey{Key: key})
+		ts.LabelValues = append(ts.LabelValues, metricdata.NewLabelValue(value))
+	}
+	metric.TimeSeries = []*metricdata.TimeSeries{ts}
+	return metric
+}
+
+// testComponent is an extension or exporter, optionally reporting its diagnostics
+type testComponent struct {
+	component.Component
+}
+
+type testProviderComponent struct {
+	testComponent
+}
+
+func (c testProviderComponent) Diagnostics() map[string]interface{} {
+	return map[string]interface{}{"registered": true}
+}
+
+// testHost provides the extensions and the exporters
+type testHost struct {
+	component.Host
+}
+
+func (h testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return map[config.ComponentID]component.Extension{
+		config.NewID("sumologic"):    testProviderComponent{},
+		config.NewID("file_storage"): testComponent{},
+	}
+}
+
+func (h testHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return map[config.DataType]map[config.ComponentID]component.Exporter{
+		config.LogsDataType: {
+			config.NewID("sumologic"): testComponent{},
+		},
+		config.MetricsDataType: {
+			config.NewID("sumologic"):         testComponent{},
+			config.NewIDWithName("otlp", "a"): testComponent{},
+		},
+	}
+}
+
+func newTestExtension(t *testing.T, modify func(cfg *Config)) (*diagnosticsExtension, *testProducer, *time.Time) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "localhost:0"
+	modify(cfg)
+	require.NoError(t, cfg.Validate())
+
+	producer := &testProducer{}
+	now := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	e := newDiagnosticsExtension(cfg, zap.NewNop())
+	e.producers = func() []metricproducer.Producer { return []metricproducer.Producer{producer} }
+	e.now = func() time.Time { return now }
+	e.host = testHost{Host: componenttest.NewNopHost()}
+	e.startTime = now
+	return e, producer, &now
+}
+
+func TestReport(t *testing.T) {
+	e, producer, now := newTestExtension(t, func(cfg *Config) {
+		cfg.Metrics = []string{"^exporter/", "^cascading_"}
+	})
+	producer.metrics = []*metricdata.Metric{
+		newTestMetric("exporter/queue_size", metricdata.TypeGaugeInt64, map[string]string{"exporter": "sumologic"}, int64(12)),
+		newTestMetric("exporter/sent_log_records", metricdata.TypeCumulativeInt64, map[string]string{"exporter": "sumologic"}, int64(100)),
+		newTestMetric("cascading_traces_on_memory", metricdata.TypeGaugeInt64, nil, int64(42)),
+		newTestMetric("cascading_trace_removal_age", metricdata.TypeCumulativeDistribution, nil,
+			&metricdata.Distribution{Count: 3, Sum: 7.5}),
+		newTestMetric("receiver/accepted_spans", metricdata.TypeCumulativeInt64, nil, int64(5)),
+	}
+	*now = now.Add(90 * time.Second)
+
+	r := e.report()
+	assert.Equal(t, "1m30s", r.Uptime)
+	assert.Equal(t, map[string]componentReport{
+		"sumologic":    {Diagnostics: map[string]interface{}{"registered": true}},
+		"file_storage": {},
+	}, r.Extensions)
+	assert.Equal(t, map[string]exporterReport{
+		"sumologic": {DataTypes: []string{"logs", "metrics"}, QueueSize: int64(12)},
+		"otlp/a":    {DataTypes: []string{"metrics"}},
+	}, r.Exporters)
+	assert.Equal(t, []metricReport{
+		{Name: "cascading_trace_removal_age", Value: distributionValue{Count: 3, Sum: 7.5}},
+		{Name: "cascading_traces_on_memory", Value: int64(42)},
+		{Name: "exporter/queue_size", Labels: map[string]string{"exporter": "sumologic"}, Value: int64(12)},
+		{Name: "exporter/sent_log_records", Labels: map[string]string{"exporter": "sumologic"}, Value: int64(100)},
+	}, r.Metrics)
+	assert.Empty(t, r.RecentErrors)
+}
+
+func TestRecentErrors(t *testing.T) {
+	e, producer, now := newTestExtension(t, func(cfg *Config) { cfg.RecentErrors = 2 })
+	failed := func(value int64) *metricdata.Metric {
+		return newTestMetric("exporter/send_failed_log_records", metricdata.TypeCumulativeInt64,
+			map[string]string{"exporter": "sumologic"}, value)
+	}
+
+	producer.metrics = []*metricdata.Metric{
+		failed(0),
+		// the gauges are not counters, so they are not errors
+		newTestMetric("dropped_gauge", metricdata.TypeGaugeInt64, nil, int64(10)),
+	}
+	e.poll()
+	assert.Empty(t, e.report().RecentErrors)
+
+	for _, value := range []int64{5, 5, 8, 20} {
+		*now = now.Add(time.Minute)
+		producer.metrics = []*metricdata.Metric{failed(value)}
+		e.poll()
+	}
+	*now = now.Add(time.Minute)
+	producer.metrics = []*metricdata.Metric{
+		failed(20),
+		newTestMetric("processor/refused_spans", metricdata.TypeCumulativeFloat64, nil, 3.0),
+	}
+
+	errors := e.report().RecentErrors
+	require.Len(t, errors, 2, "only the most recent errors are kept")
+	assert.Equal(t, errorReport{
+		Time:     time.Date(2021, 9, 1, 12, 4, 0, 0, time.UTC),
+		Metric:   "exporter/send_failed_log_records",
+		Labels:   map[string]string{"exporter": "sumologic"},
+		Increase: 12,
+	}, errors[0])
+	assert.Equal(t, errorReport{
+		Time:     time.Date(2021, 9, 1, 12, 5, 0, 0, time.UTC),
+		Metric:   "processor/refused_spans",
+		Increase: 3,
+	}, errors[1])
+}
+
+func TestServeDiagnostics(t *testing.T) {
+	e, _, _ := newTestExtension(t, func(cfg *Config) {})
+
+	recorder := httptest.NewRecorder()
+	e.serveDiagnostics(recorder, httptest.NewRequest(http.MethodGet, diagnosticsPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+	assert.Equal(t, "2021-09-01T12:00:00Z", document["start_time"])
+	assert.Equal(t, map[string]interface{}{"diagnostics": map[string]interface{}{"registered": true}},
+		document["extensions"].(map[string]interface{})["sumologic"])
+	assert.Equal(t, []interface{}{}, document["metrics"])
+	assert.Equal(t, []interface{}{}, document["recent_errors"])
+
+	recorder = httptest.NewRecorder()
+	e.serveDiagnostics(recorder, httptest.NewRequest(http.MethodPost, diagnosticsPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestStartShutdown(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "localhost:0"
+	e := newDiagnosticsExtension(cfg, zap.NewNop())
+
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, e.Shutdown(context.Background()))
+
+	cfg.Endpoint = "invalid:address:0"
+	e = newDiagnosticsExtension(cfg, zap.NewNop())
+	assert.Error(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, e.Shutdown(context.Background()))
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnosticsextension
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricproducer"
+)
+
+// queueSizeMetric is the metric of the exporterhelper sending queue sizes, with the exporter label
+const (
+	queueSizeMetric = "exporter/queue_size"
+	exporterLabel   = "exporter"
+)
+
+// metricReport is the last value of a single series of an internal metric
+type metricReport struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  interface{}       `json:"value"`
+}
+
+// distributionValue is the reported value of the distribution metrics
+type distributionValue struct {
+	Count int64   `json:"count"`
+	Sum   float64 `json:"sum"`
+}
+
+// errorReport is an increase of an error counter
+type errorReport struct {
+	Time     time.Time         `json:"time"`
+	Metric   string            `json:"metric"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Increase float64           `json:"increase"`
+}
+
+// readMetrics returns the last values of all the series of the metrics of the producers,
+// sorted by the metric names, and whether the metrics are cumulative
+func readMetrics(producers []metricproducer.Producer) ([]metricReport, map[string]bool) {
+	var reports []metricReport
+	cumulative := map[string]bool{}
+	for _, producer := range producers {
+		for _, metric := range producer.Read() {
+			if metric == nil {
+				continue
+			}
+			descriptor := metric.Descriptor
+			switch descriptor.Type {
+			case metricdata.TypeCumulativeInt64, metricdata.TypeCumulativeFloat64:
+				cumulative[descriptor.Name] = true
+			}
+			for _, ts := range metric.TimeSeries {
+				if ts == nil || len(ts.Points) == 0 {
+					continue
+				}
+				report := metricReport{
+					Name:  descriptor.Name,
+					Value: pointValue(ts.Points[len(ts.Points)-1]),
+				}
+				for i, key := range descriptor.LabelKeys {
+					if i < len(ts.LabelValues) && ts.LabelValues[i].Present {
+						if report.Labels == nil {
+							report.Labels = map[string]string{}
+						}
+						report.Labels[key.Key] = ts.LabelValues[i].Value
+					}
+				}
+				reports = append(reports, report)
+			}
+		}
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		if reports[i].Name != reports[j].Name {
+			return reports[i].Name < reports[j].Name
+		}
+		return seriesKey(reports[i]) < seriesKey(reports[j])
+	})
+	return reports, cumulative
+}
+
+// pointValue returns the value of the point, the count and the sum of the distributions
+func pointValue(point metricdata.Point) interface{} {
+	switch value := point.Value.(type) {
+	case *metricdata.Distribution:
+		return distributionValue{Count: value.Count, Sum: value.Sum}
+	case *metricdata.Summary:
+		return distributionValue{Count: value.Count, Sum: value.Sum}
+	default:
+		return value
+	}
+}
+
+// numericValue returns the value of the int64 or float64 points
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// seriesKey identifies the series of the metric by its name and labels
+func seriesKey(report metricReport) string {
+	names := make([]string, 0, len(report.Labels))
+	for name := range report.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var key strings.Builder
+	key.WriteString(report.Name)
+	for _, name := range names {
+		key.WriteByte(0)
+		key.WriteString(name)
+		key.WriteByte('=')
+		key.WriteString(report.Labels[name])
+	}
+	return key.String()
+}
@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnosticsextension
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	pprofPath  = "/debug/pprof/"
+	expvarPath = "/debug/vars"
+
+	// tokenBytes is the number of the random bytes of the generated token
+	tokenBytes = 32
+)
+
+// profiling guards the pprof and expvar handlers with the token and the allowed networks
+type profiling struct {
+	token    []byte
+	networks []*net.IPNet
+	logger   *zap.Logger
+}
+
+func newProfiling(cfg ProfilingConfig, logger *zap.Logger) (*profiling, error) {
+	networks, err := cfg.networks()
+	if err != nil {
+		return nil, err
+	}
+	token, err := loadToken(cfg.TokenFile, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &profiling{token: token, networks: networks, logger: logger}, nil
+}
+
+// loadToken reads the token from the file, or generates it and writes it to the file
+// readable by the collector's user only, when the file doesn't exist
+func loadToken(path string, logger *zap.Logger) ([]byte, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		random := make([]byte, tokenBytes)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("failed to generate the profiling token: %w", err)
+		}
+		token := []byte(hex.EncodeToString(random))
+		if err := ioutil.WriteFile(path, append(token, '\n'), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write the profiling token: %w", err)
+		}
+		logger.Info("Generated the profiling token", zap.String("token_file", path))
+		return token, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the profiling token: %w", err)
+	}
+
+	token := []byte(strings.TrimSpace(string(content)))
+	if len(token) == 0 {
+		return nil, fmt.Errorf("profiling token file %s is empty", path)
+	}
+	if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		logger.Warn("The profiling token file is accessible by other users, restrict its permissions to 0600",
+			zap.String("token_file", path), zap.Stringer("mode", info.Mode().Perm()))
+	}
+	return token, nil
+}
+
+// register adds the pprof and expvar handlers to the mux
+func (p *profiling) register(mux *http.ServeMux) {
+	mux.Handle(pprofPath, p.guard(http.HandlerFunc(pprof.Index)))
+	mux.Handle(pprofPath+"cmdline", p.guard(http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle(pprofPath+"profile", p.guard(http.HandlerFunc(pprof.Profile)))
+	mux.Handle(pprofPath+"symbol", p.guard(http.HandlerFunc(pprof.Symbol)))
+	mux.Handle(pprofPath+"trace", p.guard(http.HandlerFunc(pprof.Trace)))
+	mux.Handle(expvarPath, p.guard(expvar.Handler()))
+}
+
+// guard serves the requests from the allowed networks with the bearer token, and logs all of them,
+// as profiling affects the performance of the collector
+func (p *profiling) guard(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		logger := p.logger.With(zap.String("path", req.URL.Path), zap.String("remote_addr", req.RemoteAddr))
+		if err := p.authorize(req); err != nil {
+			logger.Warn("Refused the profiling request", zap.Error(err))
+			if errors.Is(err, errForbiddenNetwork) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		logger.Info("Serving the profiling request")
+		handler.ServeHTTP(w, req)
+	})
+}
+
+var (
+	errForbiddenNetwork = errors.New("the client address is not in the allowed networks")
+	errInvalidToken     = errors.New("missing or invalid bearer token")
+)
+
+// authorize checks the address of the client and the token of the request
+func (p *profiling) authorize(req *http.Request) error {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	allowed := false
+	for _, network := range p.networks {
+		if ip != nil && network.Contains(ip) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return errForbiddenNetwork
+	}
+
+	const prefix = "Bearer "
+	header := req.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) ||
+		subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, prefix)), p.token) != 1 {
+		return errInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnosticsextension
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+)
+
+func TestLoadToken(t *testing.T) {
+	tokenFile := path.Join(t.TempDir(), "diagnostics.token")
+
+	token, err := loadToken(tokenFile, zap.NewNop())
+	require.NoError(t, err)
+	assert.Len(t, token, 2*tokenBytes)
+	info, err := os.Stat(tokenFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := loadToken(tokenFile, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, token, loaded, "the existing token is used")
+
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("\n"), 0600))
+	_, err = loadToken(tokenFile, zap.NewNop())
+	assert.Error(t, err)
+
+	_, err = loadToken(path.Join(t.TempDir(), "missing", "diagnostics.token"), zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestProfilingGuard(t *testing.T) {
+	tokenFile := path.Join(t.TempDir(), "diagnostics.token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("secret\n"), 0600))
+	p, err := newProfiling(ProfilingConfig{Enabled: true, TokenFile: tokenFile}, zap.NewNop())
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	p.register(mux)
+
+	testcases := []struct {
+		name       string
+		remoteAddr string
+		token      string
+		status     int
+	}{
+		{name: "valid token", remoteAddr: "127.0.0.1:40000", token: "secret", status: http.StatusOK},
+		{name: "ipv6 loopback", remoteAddr: "[::1]:40000", token: "secret", status: http.StatusOK},
+		{name: "no token", remoteAddr: "127.0.0.1:40000", status: http.StatusUnauthorized},
+		{name: "invalid token", remoteAddr: "127.0.0.1:40000", token: "guess", status: http.StatusUnauthorized},
+		{name: "remote client", remoteAddr: "192.0.2.1:40000", token: "secret", status: http.StatusForbidden},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, target := range []string{expvarPath, pprofPath, pprofPath + "goroutine?debug=1", pprofPath + "cmdline"} {
+				req := httptest.NewRequest(http.MethodGet, target, nil)
+				req.RemoteAddr = tc.remoteAddr
+				if tc.token != "" {
+					req.Header.Set("Authorization", "Bearer "+tc.token)
+				}
+				recorder := httptest.NewRecorder()
+				mux.ServeHTTP(recorder, req)
+				assert.Equal(t, tc.status, recorder.Code, target)
+				if tc.status == http.StatusUnauthorized {
+					assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+				}
+			}
+		})
+	}
+}
+
+func TestProfilingAllowedNetworks(t *testing.T) {
+	tokenFile := path.Join(t.TempDir(), "diagnostics.token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("secret"), 0600))
+	p, err := newProfiling(ProfilingConfig{
+		Enabled:         true,
+		TokenFile:       tokenFile,
+		AllowedNetworks: []string{"192.0.2.0/24"},
+	}, zap.NewNop())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, expvarPath, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	assert.NoError(t, p.authorize(req), "httptest requests come from 192.0.2.1")
+
+	req.RemoteAddr = "127.0.0.1:40000"
+	assert.Equal(t, errForbiddenNetwork, p.authorize(req), "the local host is not allowed when not listed")
+}
+
+func TestStartWithProfiling(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "localhost:0"
+	cfg.Profiling = ProfilingConfig{Enabled: true, TokenFile: path.Join(t.TempDir(), "diagnostics.token")}
+	e := newDiagnosticsExtension(cfg, zap.NewNop())
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	assert.FileExists(t, cfg.Profiling.TokenFile)
+	assert.NoError(t, e.Shutdown(context.Background()))
+
+	cfg.Profiling.TokenFile = path.Join(t.TempDir(), "missing", "diagnostics.token")
+	e = newDiagnosticsExtension(cfg, zap.NewNop())
+	assert.Error(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, e.Shutdown(context.Background()))
+}
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedstorageextension
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/collector/extension/storage"
+)
+
+// valueHeader starts the encrypted values; it is followed by the nonce and the sealed value
+var valueHeader = []byte{'E', 'N', 'C', 1}
+
+// encryptedClient encrypts the values with AES-GCM, authenticating them together with their keys,
+// so that a value cannot be moved to another key unnoticed. The keys are stored as they are.
+type encryptedClient struct {
+	client storage.Client
+	aead   cipher.AEAD
+}
+
+var _ storage.Client = (*encryptedClient)(nil)
+
+// Get returns the decrypted value of the key, or nil if it is not found
+func (c *encryptedClient) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+	return c.decrypt(key, value)
+}
+
+// Set stores the encrypted value of the key
+func (c *encryptedClient) Set(ctx context.Context, key string, value []byte) error {
+	encrypted, err := c.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, key, encrypted)
+}
+
+// Delete deletes the key
+func (c *encryptedClient) Delete(ctx context.Context, key string) error {
+	return c.client.Delete(ctx, key)
+}
+
+// Batch encrypts the values of the set operations and decrypts the results of the get operations
+func (c *encryptedClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	encryptedOps := make([]storage.Operation, len(ops))
+	for i, op := range ops {
+		switch op.Type {
+		case storage.Set:
+			encrypted, err := c.encrypt(op.Key, op.Value)
+			if err != nil {
+				return err
+			}
+			encryptedOps[i] = storage.SetOperation(op.Key, encrypted)
+		case storage.Get:
+			encryptedOps[i] = storage.GetOperation(op.Key)
+		default:
+			encryptedOps[i] = op
+		}
+	}
+
+	if err := c.client.Batch(ctx, encryptedOps...); err != nil {
+		return err
+	}
+
+	for i, op := range ops {
+		if op.Type != storage.Get {
+			continue
+		}
+		op.Value = nil
+		if encrypted := encryptedOps[i].Value; encrypted != nil {
+			value, err := c.decrypt(op.Key, encrypted)
+			if err != nil {
+				return err
+			}
+			op.Value = value
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying client
+func (c *encryptedClient) Close(ctx context.Context) error {
+	return c.client.Close(ctx)
+}
+
+func (c *encryptedClient) encrypt(key string, value []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	encrypted := make([]byte, 0, len(valueHeader)+len(nonce)+len(value)+c.aead.Overhead())
+	encrypted = append(encrypted, valueHeader...)
+	encrypted = append(encrypted, nonce...)
+	return c.aead.Seal(encrypted, nonce, value, []byte(key)), nil
+}
+
+func (c *encryptedClient) decrypt(key string, encrypted []byte) ([]byte, error) {
+	if !bytes.HasPrefix(encrypted, valueHeader) || len(encrypted) < len(valueHeader)+c.aead.NonceSize() {
+		return nil, fmt.Errorf("the value of %q is not encrypted", key)
+	}
+	encrypted = encrypted[len(valueHeader):]
+	nonce, sealed := encrypted[:c.aead.NonceSize()], encrypted[c.aead.NonceSize():]
+	// the empty values are opened into a non-nil slice, since nil stands for a missing key
+	value, err := c.aead.Open([]byte{}, nonce, sealed, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the value of %q: %w", key, err)
+	}
+	return value, nil
+}
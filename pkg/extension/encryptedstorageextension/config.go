@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedstorageextension
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config has the configuration of the encrypted storage extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:"-"`
+
+	// Storage is the ID of the storage extension the encrypted values are kept in, e.g. file_storage
+	Storage string `mapstructure:"storage"`
+	// Key configures where the encryption key is read from
+	Key KeyConfig `mapstructure:"key"`
+}
+
+// KeyConfig configures the source of the 256-bit AES key; exactly one of the sources must be set.
+type KeyConfig struct {
+	// Env is the name of the environment variable holding the base64 encoded key
+	Env string `mapstructure:"env"`
+	// File is the path of the file holding the base64 encoded key
+	File string `mapstructure:"file"`
+	// AWSKMS decrypts the key with AWS KMS
+	AWSKMS *AWSKMSConfig `mapstructure:"aws_kms"`
+}
+
+// AWSKMSConfig configures the data key encrypted with an AWS KMS key, e.g. the CiphertextBlob
+// returned by aws kms generate-data-key --key-spec AES_256
+type AWSKMSConfig struct {
+	// Region is the AWS region of the KMS key, the one of the AWS SDK configuration when empty
+	Region string `mapstructure:"region"`
+	// KeyID is the ID or the ARN of the KMS key the data key is encrypted with; when set,
+	// the data key encrypted with any other KMS key is rejected
+	KeyID string `mapstructure:"key_id"`
+	// EncryptedKeyFile is the path of the file holding the base64 encoded encrypted data key
+	EncryptedKeyFile string `mapstructure:"encrypted_key_file"`
+}
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Storage == "" {
+		return errors.New("storage must not be empty")
+	}
+	id, err := config.NewIDFromString(cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("invalid storage extension id %q: %w", cfg.Storage, err)
+	}
+	if id == cfg.ID() {
+		return errors.New("storage must not refer to the extension itself")
+	}
+
+	sources := 0
+	if cfg.Key.Env != "" {
+		sources++
+	}
+	if cfg.Key.File != "" {
+		sources++
+	}
+	if cfg.Key.AWSKMS != nil {
+		sources++
+		if cfg.Key.AWSKMS.EncryptedKeyFile == "" {
+			return errors.New("key.aws_kms.encrypted_key_file must not be empty")
+		}
+	}
+	if sources != 1 {
+		return errors.New("exactly one of key.env, key.file and key.aws_kms must be set")
+	}
+	return nil
+}
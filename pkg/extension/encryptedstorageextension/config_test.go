@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedstorageextension
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewID(typeStr)),
+			Storage:           "file_storage",
+			Key:               KeyConfig{Env: "OTELCOL_STORAGE_KEY"},
+		},
+		cfg.Extensions[config.NewID(typeStr)])
+
+	id := config.NewIDWithName(typeStr, "kms")
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(id),
+			Storage:           "file_storage/queue",
+			Key: KeyConfig{
+				AWSKMS: &AWSKMSConfig{
+					Region:           "us-east-1",
+					KeyID:            "alias/otelcol",
+					EncryptedKeyFile: "/etc/otelcol/storage.key.enc",
+				},
+			},
+		},
+		cfg.Extensions[id])
+}
+
+func TestValidateConfig(t *testing.T) {
+	newConfig := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Storage = "file_storage"
+		cfg.Key.File = "/etc/otelcol/storage.key"
+		return cfg
+	}
+	assert.NoError(t, newConfig().Validate())
+
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{name: "no storage", modify: func(cfg *Config) { cfg.Storage = "" }},
+		{name: "invalid storage", modify: func(cfg *Config) { cfg.Storage = "file_storage/" }},
+		{name: "own storage", modify: func(cfg *Config) { cfg.Storage = typeStr }},
+		{name: "no key", modify: func(cfg *Config) { cfg.Key = KeyConfig{} }},
+		{name: "two keys", modify: func(cfg *Config) { cfg.Key.Env = "KEY" }},
+		{
+			name: "no encrypted key file",
+			modify: func(cfg *Config) {
+				cfg.Key = KeyConfig{AWSKMS: &AWSKMSConfig{Region: "us-east-1"}}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := newConfig()
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedstorageextension
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.uber.org/zap"
+)
+
+// encryptedStorage provides the clients of the underlying storage extension which encrypt the values
+type encryptedStorage struct {
+	config     *Config
+	logger     *zap.Logger
+	decryptKMS kmsDecryptFunc
+
+	aead    cipher.AEAD
+	storage storage.Extension
+}
+
+var _ storage.Extension = (*encryptedStorage)(nil)
+
+func newEncryptedStorage(cfg *Config, logger *zap.Logger, decryptKMS kmsDecryptFunc) *encryptedStorage {
+	return &encryptedStorage{
+		config:     cfg,
+		logger:     logger,
+		decryptKMS: decryptKMS,
+	}
+}
+
+// Start loads the encryption key and looks up the underlying storage extension
+func (e *encryptedStorage) Start(ctx context.Context, host component.Host) error {
+	// AES-256-GCM is approved in the FIPS mode, but the collector has to be built with BoringCrypto
+	if err := fips.Check(); err != nil {
+		return err
+	}
+	key, err := loadKey(ctx, e.config.Key, e.decryptKMS)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	if e.aead, err = cipher.NewGCM(block); err != nil {
+		return err
+	}
+
+	id, err := config.NewIDFromString(e.config.Storage)
+	if err != nil {
+		return err
+	}
+	extension, ok := host.GetExtensions()[id]
+	if !ok {
+		return fmt.Errorf("storage extension %q not found", e.config.Storage)
+	}
+	if e.storage, ok = extension.(storage.Extension); !ok {
+		return fmt.Errorf("extension %q is not a storage extension", e.config.Storage)
+	}
+	e.logger.Info("Encrypting the values of the storage extension", zap.String("storage", e.config.Storage))
+	return nil
+}
+
+// Shutdown does nothing, the underlying storage extension is shut down on its own
+func (e *encryptedStorage) Shutdown(context.Context) error {
+	return nil
+}
+
+// GetClient returns the client of the underlying storage extension for the component,
+// encrypting the values it sets and decrypting the ones it gets
+func (e *encryptedStorage) GetClient(ctx context.Context, kind component.Kind, id config.ComponentID, name string) (storage.Client, error) {
+	if e.storage == nil {
+		return nil, fmt.Errorf("extension %s has not been started", e.config.ID())
+	}
+	client, err := e.storage.GetClient(ctx, kind, id, name)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedClient{client: client, aead: e.aead}, nil
+}
@@ -0,0 +1,204 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedstorageextension
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.uber.org/zap"
+)
+
+// memoryStorage is the storage extension keeping the values of all the clients in a single map
+type memoryStorage struct {
+	component.Extension
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memoryStorage) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return &memoryClient{storage: s}, nil
+}
+
+type memoryClient struct {
+	storage *memoryStorage
+}
+
+func (c *memoryClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := c.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (c *memoryClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (c *memoryClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (c *memoryClient) Batch(_ context.Context, ops ...storage.Operation) error {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = c.storage.data[op.Key]
+		case storage.Set:
+			c.storage.data[op.Key] = op.Value
+		case storage.Delete:
+			delete(c.storage.data, op.Key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryClient) Close(context.Context) error {
+	return nil
+}
+
+type testHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func startTestStorage(t *testing.T) (*encryptedStorage, *memoryStorage) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = "file_storage"
+	cfg.Key.File = writeKeyFile(t, base64.StdEncoding.EncodeToString(testKey))
+
+	inner := &memoryStorage{data: map[string][]byte{}}
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewID("file_storage"): inner,
+		},
+	}
+	ext := newEncryptedStorage(cfg, zap.NewNop(), noKMS)
+	require.NoError(t, ext.Start(context.Background(), host))
+	return ext, inner
+}
+
+func TestEncryptedClient(t *testing.T) {
+	ext, inner := startTestStorage(t)
+	ctx := context.Background()
+
+	client, err := ext.GetClient(ctx, component.KindReceiver, config.NewID("filelog"), "")
+	require.NoError(t, err)
+
+	value, err := client.Get(ctx, "checkpoint")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, client.Set(ctx, "checkpoint", []byte("offset=42 fragment=user@example.com")))
+	stored := inner.data["checkpoint"]
+	assert.False(t, bytes.Contains(stored, []byte("example.com")), "the value is stored in plain text")
+
+	value, err = client.Get(ctx, "checkpoint")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("offset=42 fragment=user@example.com"), value)
+
+	// the empty value is not mistaken for a missing one
+	require.NoError(t, client.Set(ctx, "empty", []byte{}))
+	value, err = client.Get(ctx, "empty")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{}, value)
+
+	require.NoError(t, client.Delete(ctx, "checkpoint"))
+	value, err = client.Get(ctx, "checkpoint")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	assert.NoError(t, client.Close(ctx))
+}
+
+func TestEncryptedClientBatch(t *testing.T) {
+	ext, inner := startTestStorage(t)
+	ctx := context.Background()
+
+	client, err := ext.GetClient(ctx, component.KindExporter, config.NewID("sumologic"), "queue")
+	require.NoError(t, err)
+
+	require.NoError(t, client.Batch(ctx,
+		storage.SetOperation("a", []byte("first")),
+		storage.SetOperation("b", []byte("second")),
+	))
+	assert.Len(t, inner.data, 2)
+
+	getA, getB, getC := storage.GetOperation("a"), storage.GetOperation("b"), storage.GetOperation("c")
+	require.NoError(t, client.Batch(ctx, getA, storage.DeleteOperation("b"), getB, getC))
+	assert.Equal(t, []byte("first"), getA.Value)
+	assert.Nil(t, getB.Value)
+	assert.Nil(t, getC.Value)
+}
+
+func TestTamperedValues(t *testing.T) {
+	ext, inner := startTestStorage(t)
+	ctx := context.Background()
+
+	client, err := ext.GetClient(ctx, component.KindReceiver, config.NewID("filelog"), "")
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "a", []byte("first")))
+
+	// the value moved to another key does not decrypt
+	inner.data["b"] = inner.data["a"]
+	_, err = client.Get(ctx, "b")
+	assert.Error(t, err)
+
+	// the plain text value left from before the encryption was enabled is rejected
+	inner.data["c"] = []byte("plain")
+	_, err = client.Get(ctx, "c")
+	assert.Error(t, err)
+
+	// the modified value does not decrypt
+	inner.data["a"][len(inner.data["a"])-1] ^= 1
+	_, err = client.Get(ctx, "a")
+	assert.Error(t, err)
+}
+
+func TestStartErrors(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = "file_storage"
+	cfg.Key.File = writeKeyFile(t, base64.StdEncoding.EncodeToString(testKey))
+
+	// the storage extension is missing
+	ext := newEncryptedStorage(cfg, zap.NewNop(), noKMS)
+	assert.Error(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	_, err := ext.GetClient(context.Background(), component.KindReceiver, config.NewID("filelog"), "")
+	assert.Error(t, err)
+
+	// the extension is not a storage extension
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewID("file_storage"): struct{ component.Extension }{},
+		},
+	}
+	assert.Error(t, ext.Start(context.Background(), host))
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedstorageextension
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips"
+)
+
+// keySize is the size of the AES-256 key
+const keySize = 32
+
+// kmsDecryptFunc decrypts the data key with the AWS KMS key
+type kmsDecryptFunc func(ctx context.Context, cfg *AWSKMSConfig, ciphertext []byte) ([]byte, error)
+
+// loadKey reads the key from the configured source
+func loadKey(ctx context.Context, cfg KeyConfig, decryptKMS kmsDecryptFunc) ([]byte, error) {
+	var key []byte
+	var err error
+	switch {
+	case cfg.Env != "":
+		value, ok := os.LookupEnv(cfg.Env)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s with the encryption key is not set", cfg.Env)
+		}
+		key, err = decodeKey(value)
+	case cfg.File != "":
+		key, err = readKeyFile(cfg.File)
+	case cfg.AWSKMS != nil:
+		var ciphertext []byte
+		if ciphertext, err = readKeyFile(cfg.AWSKMS.EncryptedKeyFile); err != nil {
+			return nil, err
+		}
+		if key, err = decryptKMS(ctx, cfg.AWSKMS, ciphertext); err != nil {
+			return nil, fmt.Errorf("failed to decrypt the encryption key with AWS KMS: %w", err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("the encryption key must be %d bytes long, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+func readKeyFile(path string) ([]byte, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the key file: %w", err)
+	}
+	return decodeKey(string(content))
+}
+
+func decodeKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("the key is not base64 encoded: %w", err)
+	}
+	return key, nil
+}
+
+// decryptWithAWSKMS decrypts the data key with the AWS KMS API, using the credentials
+// of the AWS SDK default chain; in the FIPS mode the FIPS endpoint of the region is used
+func decryptWithAWSKMS(ctx context.Context, cfg *AWSKMSConfig, ciphertext []byte) ([]byte, error) {
+	awsConfig := aws.NewConfig()
+	if cfg.Region != "" {
+		awsConfig = awsConfig.WithRegion(cfg.Region)
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	input := &kms.DecryptInput{CiphertextBlob: ciphertext}
+	if cfg.KeyID != "" {
+		input.KeyId = aws.String(cfg.KeyID)
+	}
+	kmsConfig := aws.NewConfig()
+	if fips.Enabled() {
+		endpoint, err := kmsFIPSEndpoint(aws.StringValue(sess.Config.Region))
+		if err != nil {
+			return nil, err
+		}
+		kmsConfig = kmsConfig.WithEndpoint(endpoint)
+	}
+	output, err := kms.New(sess, kmsConfig).DecryptWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return output.Plaintext, nil
+}
+
+// kmsFIPSEndpoint returns the FIPS endpoint of AWS KMS in the region
+func kmsFIPSEndpoint(region string) (string, error) {
+	if region == "" {
+		return "", errors.New("FIPS mode requires the AWS region of the KMS key to use its FIPS endpoint")
+	}
+	return fmt.Sprintf("https://kms-fips.%s.amazonaws.com", region), nil
+}
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedstorageextension
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = bytes.Repeat([]byte{7}, keySize)
+
+func writeKeyFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "storage.key")
+	require.NoError(t, ioutil.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+func noKMS(context.Context, *AWSKMSConfig, []byte) ([]byte, error) {
+	return nil, errors.New("unexpected KMS call")
+}
+
+func TestLoadKey(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testKey)
+
+	require.NoError(t, os.Setenv("ENCRYPTED_STORAGE_TEST_KEY", encoded))
+	defer os.Unsetenv("ENCRYPTED_STORAGE_TEST_KEY")
+	key, err := loadKey(context.Background(), KeyConfig{Env: "ENCRYPTED_STORAGE_TEST_KEY"}, noKMS)
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	key, err = loadKey(context.Background(), KeyConfig{File: writeKeyFile(t, encoded+"\n")}, noKMS)
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	kmsConfig := &AWSKMSConfig{Region: "eu-west-1", EncryptedKeyFile: writeKeyFile(t, "ZW5jcnlwdGVk")}
+	decryptKMS := func(_ context.Context, cfg *AWSKMSConfig, ciphertext []byte) ([]byte, error) {
+		assert.Equal(t, kmsConfig, cfg)
+		assert.Equal(t, []byte("encrypted"), ciphertext)
+		return testKey, nil
+	}
+	key, err = loadKey(context.Background(), KeyConfig{AWSKMS: kmsConfig}, decryptKMS)
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+}
+
+func TestLoadKeyErrors(t *testing.T) {
+	testcases := []struct {
+		name string
+		cfg  KeyConfig
+	}{
+		{name: "missing environment variable", cfg: KeyConfig{Env: "ENCRYPTED_STORAGE_MISSING_KEY"}},
+		{name: "missing file", cfg: KeyConfig{File: filepath.Join(t.TempDir(), "missing")}},
+		{name: "not base64", cfg: KeyConfig{File: writeKeyFile(t, "not base64!")}},
+		{name: "too short", cfg: KeyConfig{File: writeKeyFile(t, base64.StdEncoding.EncodeToString(testKey[:16]))}},
+		{name: "KMS failure", cfg: KeyConfig{AWSKMS: &AWSKMSConfig{EncryptedKeyFile: writeKeyFile(t, "ZW5jcnlwdGVk")}}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadKey(context.Background(), tc.cfg, noKMS)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKMSFIPSEndpoint(t *testing.T) {
+	endpoint, err := kmsFIPSEndpoint("us-gov-west-1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://kms-fips.us-gov-west-1.amazonaws.com", endpoint)
+
+	_, err = kmsFIPSEndpoint("")
+	assert.Error(t, err)
+}
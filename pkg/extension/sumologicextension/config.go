@@ -15,6 +15,9 @@
 package sumologicextension
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
 	"go.opentelemetry.io/collector/config"
@@ -77,6 +80,25 @@ type Config struct {
 	BackOff backOffConfig `mapstructure:"backoff"`
 }
 
+// Validate checks if the credentials are provided and the API base URL is valid
+func (cfg *Config) Validate() error {
+	if cfg.Credentials.AccessID == "" || cfg.Credentials.AccessKey == "" {
+		return errors.New("access_key and/or access_id not provided, " +
+			"set them e.g. with access_id: ${SUMOLOGIC_ACCESS_ID} and access_key: ${SUMOLOGIC_ACCESS_KEY}")
+	}
+	u, err := url.Parse(cfg.ApiBaseUrl)
+	if err != nil {
+		return fmt.Errorf("failed parsing api_base_url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("api_base_url must be an http(s) URL, got %q", cfg.ApiBaseUrl)
+	}
+	if cfg.HeartBeatInterval <= 0 {
+		return fmt.Errorf("heartbeat_interval must be positive, got %s", cfg.HeartBeatInterval)
+	}
+	return nil
+}
+
 type credentials struct {
 	AccessID  string `mapstructure:"access_id"`
 	AccessKey string `mapstructure:"access_key"`
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:   "missing credentials",
+			modify: func(cfg *Config) { cfg.Credentials.AccessKey = "" },
+			err: "access_key and/or access_id not provided, " +
+				"set them e.g. with access_id: ${SUMOLOGIC_ACCESS_ID} and access_key: ${SUMOLOGIC_ACCESS_KEY}",
+		},
+		{
+			name:   "not an http URL",
+			modify: func(cfg *Config) { cfg.ApiBaseUrl = "collectors.sumologic.com" },
+			err:    `api_base_url must be an http(s) URL, got "collectors.sumologic.com"`,
+		},
+		{
+			name:   "zero heartbeat interval",
+			modify: func(cfg *Config) { cfg.HeartBeatInterval = 0 },
+			err:    "heartbeat_interval must be positive, got 0s",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Credentials.AccessID = "dummy_access_id"
+			cfg.Credentials.AccessKey = "dummy_access_key"
+			cfg.HeartBeatInterval = 15 * time.Second
+			tc.modify(cfg)
+
+			err := cfg.Validate()
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips"
 	"go.uber.org/zap"
 )
 
@@ -34,21 +35,31 @@ type localFsCredentialsStore struct {
 }
 
 // Check checks if collector credentials can be found under a name being a hash
-// of provided key inside collectorCredentialsDirectory.
+// of provided key inside collectorCredentialsDirectory. In the FIPS mode the credentials
+// stored before it was enabled are found as well.
 func (cr localFsCredentialsStore) Check(key string) bool {
 	filenameHash, err := hash(key)
 	if err != nil {
 		return false
 	}
-	path := path.Join(cr.collectorCredentialsDirectory, filenameHash)
-	if _, err := os.Stat(path); err != nil {
+	if _, err := os.Stat(path.Join(cr.collectorCredentialsDirectory, filenameHash)); err == nil {
+		return true
+	}
+	if !fips.Enabled() {
+		return false
+	}
+	md5FilenameHash, err := md5Hash(key)
+	if err != nil {
 		return false
 	}
-	return true
+	_, err = os.Stat(path.Join(cr.collectorCredentialsDirectory, md5FilenameHash))
+	return err == nil
 }
 
 // Get retrieves collector credentials stored in local file system and then
 // decrypts it using a hash of provided key.
+// In the FIPS mode, the credentials stored before it was enabled, i.e. under the md5 hash
+// of the key, are migrated to the sha256 one, so the collector isn't registered again.
 func (cr localFsCredentialsStore) Get(key string) (CollectorCredentials, error) {
 	filenameHash, err := hash(key)
 	if err != nil {
@@ -56,6 +67,57 @@ func (cr localFsCredentialsStore) Get(key string) (CollectorCredentials, error)
 	}
 
 	path := path.Join(cr.collectorCredentialsDirectory, filenameHash)
+	credentialsInfo, err := cr.read(path, filenameHash)
+	if os.IsNotExist(err) && fips.Enabled() {
+		return cr.migrateFromMD5(key)
+	}
+	if err != nil {
+		return CollectorCredentials{}, err
+	}
+
+	cr.logger.Info("Collector registration credentials retrieved from local fs",
+		zap.String("path", path),
+	)
+
+	return credentialsInfo, nil
+}
+
+// migrateFromMD5 retrieves collector credentials stored under the md5 hash of provided key
+// and stores them again under the current one. The old file is removed once they are stored.
+func (cr localFsCredentialsStore) migrateFromMD5(key string) (CollectorCredentials, error) {
+	filenameHash, err := md5Hash(key)
+	if err != nil {
+		return CollectorCredentials{}, err
+	}
+
+	path := path.Join(cr.collectorCredentialsDirectory, filenameHash)
+	credentialsInfo, err := cr.read(path, filenameHash)
+	if err != nil {
+		return CollectorCredentials{}, err
+	}
+
+	// The credentials are valid even if they can't be migrated, they are retrieved again on the next start
+	if err = cr.Store(key, credentialsInfo); err != nil {
+		cr.logger.Warn("Failed to migrate collector registration credentials to the FIPS mode",
+			zap.String("path", path), zap.Error(err),
+		)
+		return credentialsInfo, nil
+	}
+	if err = os.Remove(path); err != nil {
+		cr.logger.Warn("Failed to remove collector registration credentials migrated to the FIPS mode",
+			zap.String("path", path), zap.Error(err),
+		)
+	}
+
+	cr.logger.Info("Collector registration credentials migrated to the FIPS mode",
+		zap.String("path", path),
+	)
+
+	return credentialsInfo, nil
+}
+
+// read reads the collector credentials from the file and decrypts them using the hashed key
+func (cr localFsCredentialsStore) read(path string, hashedKey string) (CollectorCredentials, error) {
 	creds, err := os.Open(path)
 	if err != nil {
 		return CollectorCredentials{}, err
@@ -67,7 +129,7 @@ func (cr localFsCredentialsStore) Get(key string) (CollectorCredentials, error)
 		return CollectorCredentials{}, err
 	}
 
-	collectorCreds, err := decrypt(encryptedCreds, key)
+	collectorCreds, err := decryptWithKey(encryptedCreds, hashedKey)
 	if err != nil {
 		return CollectorCredentials{}, err
 	}
@@ -76,11 +138,6 @@ func (cr localFsCredentialsStore) Get(key string) (CollectorCredentials, error)
 	if err = json.Unmarshal(collectorCreds, &credentialsInfo); err != nil {
 		return CollectorCredentials{}, err
 	}
-
-	cr.logger.Info("Collector registration credentials retrieved from local fs",
-		zap.String("path", path),
-	)
-
 	return credentialsInfo, nil
 }
 
@@ -16,9 +16,11 @@ package sumologicextension
 
 import (
 	"os"
+	"path"
 	"testing"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/sumologicextension/api"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -55,3 +57,78 @@ func TestCredentialsStoreLocalFs(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, creds, actual)
 }
+
+func TestCredentialsStoreLocalFsMigratesMD5CredentialsInFIPSMode(t *testing.T) {
+	dir, err := os.MkdirTemp("", "otelcol-sumo-credentials-store-local-fs-test-*")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	const key = "my_storage_key"
+
+	creds := CollectorCredentials{
+		CollectorName: "name",
+		Credentials: api.OpenRegisterResponsePayload{
+			CollectorCredentialId:  "credentialId",
+			CollectorCredentialKey: "credentialKey",
+			CollectorId:            "id",
+		},
+	}
+
+	sut := localFsCredentialsStore{
+		collectorCredentialsDirectory: dir,
+		logger:                        zap.NewNop(),
+	}
+
+	// The credentials are stored before the FIPS mode is enabled
+	require.NoError(t, sut.Store(key, creds))
+	md5Filename, err := md5Hash(key)
+	require.NoError(t, err)
+	require.FileExists(t, path.Join(dir, md5Filename))
+
+	require.NoError(t, os.Setenv(fips.EnvVar, "true"))
+	t.Cleanup(func() {
+		os.Unsetenv(fips.EnvVar)
+	})
+	sha256Filename, err := hash(key)
+	require.NoError(t, err)
+	require.NotEqual(t, md5Filename, sha256Filename)
+
+	require.True(t, sut.Check(key))
+	actual, err := sut.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, creds, actual)
+
+	// The credentials are moved under the sha256 name and encrypted with it
+	assert.NoFileExists(t, path.Join(dir, md5Filename))
+	assert.FileExists(t, path.Join(dir, sha256Filename))
+	require.True(t, sut.Check(key))
+	actual, err = sut.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, creds, actual)
+}
+
+func TestCredentialsStoreLocalFsDoesNotUseMD5CredentialsOutsideOfFIPSMode(t *testing.T) {
+	dir, err := os.MkdirTemp("", "otelcol-sumo-credentials-store-local-fs-test-*")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	const key = "my_storage_key"
+
+	sut := localFsCredentialsStore{
+		collectorCredentialsDirectory: dir,
+		logger:                        zap.NewNop(),
+	}
+
+	// Credentials stored in the FIPS mode aren't looked up under the other hash
+	require.NoError(t, os.Setenv(fips.EnvVar, "true"))
+	require.NoError(t, sut.Store(key, CollectorCredentials{CollectorName: "name"}))
+	require.NoError(t, os.Unsetenv(fips.EnvVar))
+
+	assert.False(t, sut.Check(key))
+	_, err = sut.Get(key)
+	assert.True(t, os.IsNotExist(err))
+}
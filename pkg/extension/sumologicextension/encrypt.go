@@ -19,18 +19,32 @@ import (
 	"crypto/cipher"
 	"crypto/md5"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips"
 )
 
-// hash returns an md5 hashed string of provided key and an error.
+// hashLength is the length of the hashed string, also used as the AES-256 key
+const hashLength = 32
+
+// hash returns the hashed string of provided key and an error. It's the md5 hash, or in the FIPS mode,
+// where md5 is not allowed, the truncated sha256 hash.
 func hash(key string) (string, error) {
-	hasher := md5.New()
-	if _, err := hasher.Write([]byte(key)); err != nil {
-		return "", err
+	if fips.Enabled() {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])[:hashLength], nil
 	}
-	return hex.EncodeToString(hasher.Sum(nil)), nil
+	return md5Hash(key)
+}
+
+// md5Hash returns the md5 hashed string of provided key. In the FIPS mode it's only used to find
+// and decrypt the credentials stored before the FIPS mode was enabled, so they can be migrated.
+func md5Hash(key string) (string, error) {
+	sum := md5.Sum([]byte(key))
+	return hex.EncodeToString(sum[:]), nil
 }
 
 // encrypt encrypts provided byte slice with AES using the passphrase
@@ -55,13 +69,9 @@ func encrypt(data []byte, passphrase string) ([]byte, error) {
 	return ciphertext, nil
 }
 
-// decrypt decrypts provided byte slice with AES using the passphrase.
-func decrypt(data []byte, passphrase string) ([]byte, error) {
-	h, err := hash(passphrase)
-	if err != nil {
-		return nil, err
-	}
-	block, err := aes.NewCipher([]byte(h))
+// decryptWithKey decrypts provided byte slice with AES using the already hashed key.
+func decryptWithKey(data []byte, key string) ([]byte, error) {
+	block, err := aes.NewCipher([]byte(key))
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +32,7 @@ import (
 	"github.com/cenkalti/backoff/v4"
 	"github.com/google/uuid"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/sumologicextension/api"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips"
 	"go.opentelemetry.io/collector/component"
 	"go.uber.org/zap"
 )
@@ -48,6 +49,12 @@ type SumologicExtension struct {
 	closeChan        chan struct{}
 	closeOnce        sync.Once
 	backOff          *backoff.ExponentialBackOff
+
+	// statusMu guards the fields reported by Diagnostics()
+	statusMu           sync.Mutex
+	registered         bool
+	lastHeartbeat      time.Time
+	lastHeartbeatError error
 }
 
 const (
@@ -75,6 +82,12 @@ func newSumologicExtension(conf *Config, logger *zap.Logger) (*SumologicExtensio
 	if conf.Credentials.AccessID == "" || conf.Credentials.AccessKey == "" {
 		return nil, errors.New("access_key and/or access_id not provided")
 	}
+	if err := fips.ValidateEndpoint(conf.ID().String(), conf.ApiBaseUrl); err != nil {
+		return nil, err
+	}
+	if err := fips.ValidateTLSClient(conf.ID().String(), conf.HTTPClientSettings.TLSSetting); err != nil {
+		return nil, err
+	}
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, err
@@ -164,6 +177,10 @@ func (se *SumologicExtension) Start(ctx context.Context, host component.Host) er
 		se.logger.Info("Local collector credentials all good, starting up the collector")
 	}
 
+	se.statusMu.Lock()
+	se.registered = true
+	se.statusMu.Unlock()
+
 	go se.heartbeatLoop()
 
 	return nil
@@ -369,12 +386,18 @@ func (se *SumologicExtension) heartbeatLoop() {
 			se.logger.Info("Heartbeat sender turned off")
 			return
 		default:
-			if err := se.sendHeartbeat(ctx); err != nil {
+			err := se.sendHeartbeat(ctx)
+			if err != nil {
 				se.logger.Error("Heartbeat error", zap.Error(err))
 			} else {
 				se.logger.Debug("Heartbeat sent")
 			}
 
+			se.statusMu.Lock()
+			se.lastHeartbeat = time.Now()
+			se.lastHeartbeatError = err
+			se.statusMu.Unlock()
+
 			select {
 			case <-timer.C:
 				timer.Stop()
@@ -431,6 +454,29 @@ func (se *SumologicExtension) BaseUrl() string {
 	return se.baseUrl
 }
 
+// Diagnostics returns the registration and heartbeat state of the collector,
+// to be exposed by the diagnostics extension.
+func (se *SumologicExtension) Diagnostics() map[string]interface{} {
+	se.statusMu.Lock()
+	defer se.statusMu.Unlock()
+
+	diagnostics := map[string]interface{}{
+		"api_base_url": se.baseUrl,
+		"registered":   se.registered,
+	}
+	if se.registered {
+		diagnostics["collector_name"] = se.registrationInfo.CollectorName
+		diagnostics["collector_id"] = se.registrationInfo.CollectorId
+	}
+	if !se.lastHeartbeat.IsZero() {
+		diagnostics["last_heartbeat"] = se.lastHeartbeat.UTC().Format(time.RFC3339)
+	}
+	if se.lastHeartbeatError != nil {
+		diagnostics["last_heartbeat_error"] = se.lastHeartbeatError.Error()
+	}
+	return diagnostics
+}
+
 // Implement [1] in order for this extension to be used as custom exporter
 // authenticator.
 //
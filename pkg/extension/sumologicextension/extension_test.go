@@ -145,6 +145,62 @@ func TestBasicStart(t *testing.T) {
 	require.NoError(t, se.Shutdown(context.Background()))
 }
 
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case registerUrl:
+			_, err := w.Write([]byte(`{
+				"collectorCredentialId": "collectorId",
+				"collectorCredentialKey": "collectorKey",
+				"collectorId": "id",
+				"collectorName": "collector_name"
+			}`))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+		case heartbeatUrl:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(func() { srv.Close() })
+
+	dir, err := os.MkdirTemp("", "otelcol-sumo-diagnostics-test-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "collector_name"
+	cfg.ExtensionSettings = config.ExtensionSettings{}
+	cfg.ApiBaseUrl = srv.URL
+	cfg.Credentials.AccessID = "dummy_access_id"
+	cfg.Credentials.AccessKey = "dummy_access_key"
+	cfg.CollectorCredentialsDirectory = dir
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"api_base_url": srv.URL,
+		"registered":   false,
+	}, se.Diagnostics())
+
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { se.Shutdown(context.Background()) })
+
+	require.Eventually(t, func() bool {
+		_, ok := se.Diagnostics()["last_heartbeat"]
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	diagnostics := se.Diagnostics()
+	assert.Equal(t, true, diagnostics["registered"])
+	assert.Equal(t, "collector_name", diagnostics["collector_name"])
+	assert.Equal(t, "id", diagnostics["collector_id"])
+	assert.Contains(t, diagnostics["last_heartbeat_error"], "status code: 503")
+}
+
 func TestStoreCredentials(t *testing.T) {
 	t.Parallel()
 
@@ -10,6 +10,7 @@ require (
 	github.com/mattn/go-colorable v0.1.7 // indirect
 	github.com/onsi/ginkgo v1.14.1 // indirect
 	github.com/onsi/gomega v1.10.2 // indirect
+	github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips v0.33.0
 	github.com/stretchr/testify v1.7.0
 	go.opentelemetry.io/collector v0.33.0
 	go.uber.org/zap v1.19.0
@@ -19,4 +20,6 @@ replace github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sumol
 
 replace github.com/open-telemetry/opentelemetry-collector-contrib/extension/sumologicextension => ./
 
+replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips => ../../internal/fips
+
 replace go.opentelemetry.io/collector => github.com/SumoLogic/opentelemetry-collector v0.33.0-sumo-1
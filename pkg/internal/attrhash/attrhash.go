@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+// Package attrhash hashes the attributes of the telemetry, so the processors can key their state
+// by the series or the record without building the string keys.
+package attrhash
+
+import (
+	"hash"
+	"sort"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// Attributes writes the attributes to the hash in the order of their names and returns the sum,
+// so the same attributes give the same sum regardless of their order
+func Attributes(h hash.Hash64, attributes pdata.AttributeMap) uint64 {
+	names := make([]string, 0, attributes.Len())
+	attributes.Range(func(name string, _ pdata.AttributeValue) bool {
+		names = append(names, name)
+		return true
+	})
+	sort.Strings(names)
+	for _, name := range names {
+		value, _ := attributes.Get(name)
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(pdata.AttributeValueToString(value)))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// WriteUint64 writes the value to the hash, e.g. the sum of the resource attributes,
+// which the attributes of the record are hashed with
+func WriteUint64(h hash.Hash64, value uint64) {
+	var b [8]byte
+	for i := range b {
+		b[i] = byte(value >> (8 * i))
+	}
+	h.Write(b[:])
+}
@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package attrhash
+
+import (
+	"hash/fnv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestAttributesIgnoresOrder(t *testing.T) {
+	first := pdata.NewAttributeMap()
+	first.InsertString("service", "api")
+	first.InsertInt("code", 200)
+	second := pdata.NewAttributeMap()
+	second.InsertInt("code", 200)
+	second.InsertString("service", "api")
+
+	assert.Equal(t, Attributes(fnv.New64a(), first), Attributes(fnv.New64a(), second))
+}
+
+func TestAttributesSeparatesNamesAndValues(t *testing.T) {
+	first := pdata.NewAttributeMap()
+	first.InsertString("ab", "c")
+	second := pdata.NewAttributeMap()
+	second.InsertString("a", "bc")
+
+	assert.NotEqual(t, Attributes(fnv.New64a(), first), Attributes(fnv.New64a(), second))
+}
+
+func TestWriteUint64(t *testing.T) {
+	attributes := pdata.NewAttributeMap()
+	attributes.InsertString("service", "api")
+
+	first := fnv.New64a()
+	WriteUint64(first, 1)
+	second := fnv.New64a()
+	WriteUint64(second, 2)
+	assert.NotEqual(t, Attributes(first, attributes), Attributes(second, attributes))
+}
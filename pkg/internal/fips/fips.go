@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fips holds the checks of the FIPS mode shared by the Sumo Logic components.
+//
+// The FIPS mode is enabled when the collector is built with BoringCrypto, i.e. with the dev.boringcrypto
+// Go toolchain and the boringcrypto tag, or requested with the OTELCOL_SUMO_FIPS_MODE environment variable. In the latter case the collector
+// refuses to start unless it is built with BoringCrypto as well.
+package fips
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+// EnvVar is the environment variable requiring the FIPS mode, e.g. OTELCOL_SUMO_FIPS_MODE=true
+const EnvVar = "OTELCOL_SUMO_FIPS_MODE"
+
+// ErrNotSupported is returned when the FIPS mode is required but the collector is not built with BoringCrypto
+var ErrNotSupported = errors.New("FIPS mode is required with " + EnvVar +
+	", but the collector is not built with BoringCrypto, use the FIPS build of the collector")
+
+// getenv is replaced in the tests
+var getenv = os.Getenv
+
+// Enabled returns true if the FIPS mode is enabled, either by the build or the environment
+func Enabled() bool {
+	return boringCrypto || required()
+}
+
+// BoringCrypto returns true if the collector is built with BoringCrypto
+func BoringCrypto() bool {
+	return boringCrypto
+}
+
+func required() bool {
+	required, err := strconv.ParseBool(getenv(EnvVar))
+	return err == nil && required
+}
+
+// Check returns an error if the FIPS mode is required but the collector is not built with BoringCrypto
+func Check() error {
+	if required() && !boringCrypto {
+		return ErrNotSupported
+	}
+	return nil
+}
+
+// ValidateEndpoint checks if the endpoint of the component is an HTTPS URL in the FIPS mode,
+// as the data can't be sent in plain text
+func ValidateEndpoint(component string, endpoint string) error {
+	if !Enabled() || endpoint == "" {
+		return Check()
+	}
+	if err := Check(); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("%s: invalid endpoint %q: %w", component, endpoint, err)
+	}
+	if !strings.EqualFold(u.Scheme, "https") {
+		return fmt.Errorf("%s: FIPS mode requires an HTTPS endpoint, got %q", component, endpoint)
+	}
+	return nil
+}
+
+// ValidateTLSClient checks if the TLS client settings of the component use only the approved
+// protocol versions and verify the server certificates in the FIPS mode
+func ValidateTLSClient(component string, settings configtls.TLSClientSetting) error {
+	if !Enabled() {
+		return Check()
+	}
+	if err := Check(); err != nil {
+		return err
+	}
+
+	if settings.Insecure {
+		return fmt.Errorf("%s: FIPS mode doesn't allow the insecure connection without TLS", component)
+	}
+	if settings.InsecureSkipVerify {
+		return fmt.Errorf("%s: FIPS mode doesn't allow insecure_skip_verify", component)
+	}
+	// The approved versions are TLS 1.2 and 1.3, the default minimum version is TLS 1.2
+	for name, version := range map[string]string{"min_version": settings.MinVersion, "max_version": settings.MaxVersion} {
+		switch version {
+		case "", "1.2", "1.3":
+		default:
+			return fmt.Errorf("%s: FIPS mode requires TLS 1.2 or 1.3, got %s %q", component, name, version)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fips
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+// setMode sets the build and the environment for the test
+func setMode(t *testing.T, boring bool, env string) {
+	previousBoring, previousGetenv := boringCrypto, getenv
+	t.Cleanup(func() { boringCrypto, getenv = previousBoring, previousGetenv })
+
+	boringCrypto = boring
+	getenv = func(key string) string {
+		if key == EnvVar {
+			return env
+		}
+		return ""
+	}
+}
+
+func TestMode(t *testing.T) {
+	testcases := []struct {
+		name        string
+		boring      bool
+		env         string
+		enabled     bool
+		expectedErr error
+	}{
+		{name: "disabled"},
+		{name: "disabled explicitly", env: "false"},
+		{name: "invalid environment variable", env: "yes please"},
+		{name: "build", boring: true, enabled: true},
+		{name: "build and environment", boring: true, env: "true", enabled: true},
+		{name: "environment without build", env: "1", enabled: true, expectedErr: ErrNotSupported},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			setMode(t, tc.boring, tc.env)
+			assert.Equal(t, tc.enabled, Enabled())
+			assert.Equal(t, tc.boring, BoringCrypto())
+			assert.Equal(t, tc.expectedErr, Check())
+		})
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	setMode(t, false, "")
+	assert.NoError(t, ValidateEndpoint("exporter", "http://localhost:3000"), "not validated without the FIPS mode")
+
+	setMode(t, true, "")
+	assert.NoError(t, ValidateEndpoint("exporter", ""))
+	assert.NoError(t, ValidateEndpoint("exporter", "https://collectors.sumologic.com/receiver/v1/http/abc"))
+	assert.NoError(t, ValidateEndpoint("exporter", "HTTPS://collectors.sumologic.com"))
+	assert.EqualError(t, ValidateEndpoint("exporter", "http://localhost:3000"),
+		`exporter: FIPS mode requires an HTTPS endpoint, got "http://localhost:3000"`)
+	assert.Error(t, ValidateEndpoint("exporter", "https://[::1"))
+
+	setMode(t, false, "true")
+	assert.Equal(t, ErrNotSupported, ValidateEndpoint("exporter", ""))
+}
+
+func TestValidateTLSClient(t *testing.T) {
+	setMode(t, false, "")
+	assert.NoError(t, ValidateTLSClient("exporter", configtls.TLSClientSetting{InsecureSkipVerify: true}),
+		"not validated without the FIPS mode")
+
+	setMode(t, true, "")
+	testcases := []struct {
+		name        string
+		settings    configtls.TLSClientSetting
+		expectedErr string
+	}{
+		{name: "default"},
+		{
+			name:     "approved versions",
+			settings: configtls.TLSClientSetting{TLSSetting: configtls.TLSSetting{MinVersion: "1.2", MaxVersion: "1.3"}},
+		},
+		{
+			name:        "insecure",
+			settings:    configtls.TLSClientSetting{Insecure: true},
+			expectedErr: "exporter: FIPS mode doesn't allow the insecure connection without TLS",
+		},
+		{
+			name:        "insecure skip verify",
+			settings:    configtls.TLSClientSetting{InsecureSkipVerify: true},
+			expectedErr: "exporter: FIPS mode doesn't allow insecure_skip_verify",
+		},
+		{
+			name:        "old min version",
+			settings:    configtls.TLSClientSetting{TLSSetting: configtls.TLSSetting{MinVersion: "1.1"}},
+			expectedErr: `exporter: FIPS mode requires TLS 1.2 or 1.3, got min_version "1.1"`,
+		},
+		{
+			name:        "old max version",
+			settings:    configtls.TLSClientSetting{TLSSetting: configtls.TLSSetting{MaxVersion: "1.0"}},
+			expectedErr: `exporter: FIPS mode requires TLS 1.2 or 1.3, got max_version "1.0"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTLSClient("exporter", tc.settings)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips
+
+go 1.14
+
+require (
+	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/collector v0.33.0
+)
+
+replace go.opentelemetry.io/collector => github.com/SumoLogic/opentelemetry-collector v0.33.0-sumo-1
@@ -0,0 +1,203 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ottl
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// Context describes which telemetry item a condition is evaluated against.
+type Context int
+
+const (
+	// SpanContext conditions are evaluated for each span.
+	SpanContext Context = iota
+	// SpanEventContext conditions are evaluated for each span event.
+	SpanEventContext
+	// ResourceContext expressions are evaluated for each resource, regardless of the signal.
+	ResourceContext
+)
+
+// TransformContext holds the telemetry a condition is evaluated against.
+type TransformContext struct {
+	Resource               pdata.Resource
+	InstrumentationLibrary pdata.InstrumentationLibrary
+	// Span is not used in ResourceContext.
+	Span pdata.Span
+	// SpanEvent is only used in SpanEventContext.
+	SpanEvent pdata.SpanEvent
+}
+
+// getter returns the value of an expression for the given telemetry. The returned value
+// is one of: nil, string, bool, int64, float64, []byte, pdata.AttributeMap, pdata.AnyValueArray
+// or []interface{} (for list literals).
+type getter func(tCtx *TransformContext) interface{}
+
+// enums contains the symbolic names which might be used in place of the numeric values.
+var enums = map[string]int64{
+	"SPAN_KIND_UNSPECIFIED": int64(pdata.SpanKindUnspecified),
+	"SPAN_KIND_INTERNAL":    int64(pdata.SpanKindInternal),
+	"SPAN_KIND_SERVER":      int64(pdata.SpanKindServer),
+	"SPAN_KIND_CLIENT":      int64(pdata.SpanKindClient),
+	"SPAN_KIND_PRODUCER":    int64(pdata.SpanKindProducer),
+	"SPAN_KIND_CONSUMER":    int64(pdata.SpanKindConsumer),
+	"STATUS_CODE_UNSET":     int64(pdata.StatusCodeUnset),
+	"STATUS_CODE_OK":        int64(pdata.StatusCodeOk),
+	"STATUS_CODE_ERROR":     int64(pdata.StatusCodeError),
+}
+
+// resolvePath returns a getter for the dotted path (e.g. "resource.attributes") in the given context.
+func resolvePath(ctx Context, fields []string) (getter, error) {
+	var g getter
+	switch ctx {
+	case SpanContext:
+		g = spanPath(fields)
+	case SpanEventContext:
+		g = spanEventPath(fields)
+	case ResourceContext:
+		g = resourcePath(fields)
+	default:
+		return nil, fmt.Errorf("unknown context %d", ctx)
+	}
+	if g == nil {
+		return nil, fmt.Errorf("unknown path %q", strings.Join(fields, "."))
+	}
+	return g, nil
+}
+
+func commonPath(fields []string) getter {
+	switch {
+	case len(fields) == 2 && fields[0] == "resource" && fields[1] == "attributes":
+		return func(tCtx *TransformContext) interface{} { return tCtx.Resource.Attributes() }
+	case len(fields) == 2 && (fields[0] == "instrumentation_library" || fields[0] == "instrumentation_scope"):
+		switch fields[1] {
+		case "name":
+			return func(tCtx *TransformContext) interface{} { return tCtx.InstrumentationLibrary.Name() }
+		case "version":
+			return func(tCtx *TransformContext) interface{} { return tCtx.InstrumentationLibrary.Version() }
+		}
+	}
+	return nil
+}
+
+func resourcePath(fields []string) getter {
+	switch strings.Join(fields, ".") {
+	case "attributes", "resource.attributes":
+		return func(tCtx *TransformContext) interface{} { return tCtx.Resource.Attributes() }
+	}
+	return nil
+}
+
+func spanPath(fields []string) getter {
+	if g := commonPath(fields); g != nil {
+		return g
+	}
+
+	switch strings.Join(fields, ".") {
+	case "name":
+		return func(tCtx *TransformContext) interface{} { return tCtx.Span.Name() }
+	case "kind":
+		return func(tCtx *TransformContext) interface{} { return int64(tCtx.Span.Kind()) }
+	case "attributes":
+		return func(tCtx *TransformContext) interface{} { return tCtx.Span.Attributes() }
+	case "dropped_attributes_count":
+		return func(tCtx *TransformContext) interface{} { return int64(tCtx.Span.DroppedAttributesCount()) }
+	case "start_time_unix_nano":
+		return func(tCtx *TransformContext) interface{} { return int64(tCtx.Span.StartTimestamp()) }
+	case "end_time_unix_nano":
+		return func(tCtx *TransformContext) interface{} { return int64(tCtx.Span.EndTimestamp()) }
+	case "status.code":
+		return func(tCtx *TransformContext) interface{} { return int64(tCtx.Span.Status().Code()) }
+	case "status.message":
+		return func(tCtx *TransformContext) interface{} { return tCtx.Span.Status().Message() }
+	case "trace_id.string":
+		return func(tCtx *TransformContext) interface{} { return tCtx.Span.TraceID().HexString() }
+	case "span_id.string":
+		return func(tCtx *TransformContext) interface{} { return tCtx.Span.SpanID().HexString() }
+	case "parent_span_id.string":
+		return func(tCtx *TransformContext) interface{} { return tCtx.Span.ParentSpanID().HexString() }
+	case "trace_state":
+		return func(tCtx *TransformContext) interface{} { return string(tCtx.Span.TraceState()) }
+	}
+	return nil
+}
+
+func spanEventPath(fields []string) getter {
+	if g := commonPath(fields); g != nil {
+		return g
+	}
+
+	if len(fields) > 1 && fields[0] == "span" {
+		return spanPath(fields[1:])
+	}
+
+	switch strings.Join(fields, ".") {
+	case "name":
+		return func(tCtx *TransformContext) interface{} { return tCtx.SpanEvent.Name() }
+	case "attributes":
+		return func(tCtx *TransformContext) interface{} { return tCtx.SpanEvent.Attributes() }
+	case "dropped_attributes_count":
+		return func(tCtx *TransformContext) interface{} { return int64(tCtx.SpanEvent.DroppedAttributesCount()) }
+	case "time_unix_nano":
+		return func(tCtx *TransformContext) interface{} { return int64(tCtx.SpanEvent.Timestamp()) }
+	}
+	return nil
+}
+
+// index applies a single map key or array index to the value.
+func index(v interface{}, key interface{}) interface{} {
+	switch container := v.(type) {
+	case pdata.AttributeMap:
+		k, ok := key.(string)
+		if !ok {
+			return nil
+		}
+		av, found := container.Get(k)
+		if !found {
+			return nil
+		}
+		return fromAttributeValue(av)
+	case pdata.AnyValueArray:
+		i, ok := key.(int64)
+		if !ok || i < 0 || int(i) >= container.Len() {
+			return nil
+		}
+		return fromAttributeValue(container.At(int(i)))
+	}
+	return nil
+}
+
+func fromAttributeValue(av pdata.AttributeValue) interface{} {
+	switch av.Type() {
+	case pdata.AttributeValueTypeString:
+		return av.StringVal()
+	case pdata.AttributeValueTypeInt:
+		return av.IntVal()
+	case pdata.AttributeValueTypeDouble:
+		return av.DoubleVal()
+	case pdata.AttributeValueTypeBool:
+		return av.BoolVal()
+	case pdata.AttributeValueTypeMap:
+		return av.MapVal()
+	case pdata.AttributeValueTypeArray:
+		return av.ArrayVal()
+	case pdata.AttributeValueTypeBytes:
+		return av.BytesVal()
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ottl
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// function builds a getter out of the compiled arguments.
+type function func(args []value) (getter, error)
+
+// functions lists the supported converters. As in OTTL, converters return nil
+// when the argument cannot be converted instead of failing the evaluation.
+var functions = map[string]function{
+	"IsMatch":     isMatch,
+	"Int":         toInt,
+	"Double":      toDouble,
+	"Len":         length,
+	"ConvertCase": convertCase,
+	"Concat":      concat,
+	"Substring":   substring,
+}
+
+func expectArgs(args []value, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("expected %d argument(s), got %d", n, len(args))
+	}
+	return nil
+}
+
+func literalString(arg value, what string) (string, error) {
+	s, ok := arg.literal.(string)
+	if !arg.isLiteral || !ok {
+		return "", fmt.Errorf("%s must be a string literal", what)
+	}
+	return s, nil
+}
+
+// isMatch implements IsMatch(target, pattern) which returns true when target is a string
+// matching the regular expression.
+func isMatch(args []value) (getter, error) {
+	if err := expectArgs(args, 2); err != nil {
+		return nil, err
+	}
+	pattern, err := literalString(args[1], "pattern")
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	target := args[0].get
+	return func(tCtx *TransformContext) interface{} {
+		s, ok := target(tCtx).(string)
+		return ok && re.MatchString(s)
+	}, nil
+}
+
+// toInt implements Int(value).
+func toInt(args []value) (getter, error) {
+	if err := expectArgs(args, 1); err != nil {
+		return nil, err
+	}
+	target := args[0].get
+	return func(tCtx *TransformContext) interface{} {
+		switch v := target(tCtx).(type) {
+		case int64:
+			return v
+		case float64:
+			return int64(v)
+		case bool:
+			if v {
+				return int64(1)
+			}
+			return int64(0)
+		case string:
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return i
+			}
+		}
+		return nil
+	}, nil
+}
+
+// toDouble implements Double(value).
+func toDouble(args []value) (getter, error) {
+	if err := expectArgs(args, 1); err != nil {
+		return nil, err
+	}
+	target := args[0].get
+	return func(tCtx *TransformContext) interface{} {
+		switch v := target(tCtx).(type) {
+		case int64:
+			return float64(v)
+		case float64:
+			return v
+		case bool:
+			if v {
+				return float64(1)
+			}
+			return float64(0)
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f
+			}
+		}
+		return nil
+	}, nil
+}
+
+// length implements Len(value) for strings, byte slices, maps and arrays.
+func length(args []value) (getter, error) {
+	if err := expectArgs(args, 1); err != nil {
+		return nil, err
+	}
+	target := args[0].get
+	return func(tCtx *TransformContext) interface{} {
+		switch v := target(tCtx).(type) {
+		case string:
+			return int64(len(v))
+		case []byte:
+			return int64(len(v))
+		case pdata.AttributeMap:
+			return int64(v.Len())
+		case pdata.AnyValueArray:
+			return int64(v.Len())
+		case []interface{}:
+			return int64(len(v))
+		}
+		return nil
+	}, nil
+}
+
+// convertCase implements ConvertCase(target, "lower"|"upper").
+func convertCase(args []value) (getter, error) {
+	if err := expectArgs(args, 2); err != nil {
+		return nil, err
+	}
+	toCase, err := literalString(args[1], "case")
+	if err != nil {
+		return nil, err
+	}
+	var convert func(string) string
+	switch toCase {
+	case "lower":
+		convert = strings.ToLower
+	case "upper":
+		convert = strings.ToUpper
+	default:
+		return nil, fmt.Errorf("unsupported case %q", toCase)
+	}
+
+	target := args[0].get
+	return func(tCtx *TransformContext) interface{} {
+		if s, ok := target(tCtx).(string); ok {
+			return convert(s)
+		}
+		return nil
+	}, nil
+}
+
+// concat implements Concat([values], delimiter). Values which cannot be represented
+// as a string (e.g. missing attributes) are concatenated as empty strings.
+func concat(args []value) (getter, error) {
+	if err := expectArgs(args, 2); err != nil {
+		return nil, err
+	}
+	delimiter, err := literalString(args[1], "delimiter")
+	if err != nil {
+		return nil, err
+	}
+
+	target := args[0].get
+	return func(tCtx *TransformContext) interface{} {
+		var values []string
+		switch v := target(tCtx).(type) {
+		case []interface{}:
+			values = make([]string, len(v))
+			for i, item := range v {
+				values[i], _ = toString(item)
+			}
+		case pdata.AnyValueArray:
+			values = make([]string, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				values[i], _ = toString(fromAttributeValue(v.At(i)))
+			}
+		default:
+			return nil
+		}
+		return strings.Join(values, delimiter)
+	}, nil
+}
+
+// substring implements Substring(target, start, length). It returns nil when the
+// range is out of the target bounds.
+func substring(args []value) (getter, error) {
+	if err := expectArgs(args, 3); err != nil {
+		return nil, err
+	}
+
+	target, start, count := args[0].get, args[1].get, args[2].get
+	return func(tCtx *TransformContext) interface{} {
+		s, ok := target(tCtx).(string)
+		if !ok {
+			return nil
+		}
+		from, ok := start(tCtx).(int64)
+		if !ok {
+			return nil
+		}
+		n, ok := count(tCtx).(int64)
+		if !ok || from < 0 || n < 0 || from+n > int64(len(s)) {
+			return nil
+		}
+		return s[from : from+n]
+	}, nil
+}
+
+// toString converts scalar values to strings. Maps, arrays and nil are not converted.
+func toString(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case bool:
+		return strconv.FormatBool(s), true
+	case int64:
+		return strconv.FormatInt(s, 10), true
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// compare applies the comparison operator. Following OTTL semantics, values of
+// incompatible types are never equal and cannot be ordered.
+func compare(left, right interface{}, op string) bool {
+	if left == nil || right == nil {
+		switch op {
+		case "==":
+			return left == nil && right == nil
+		case "!=":
+			return !(left == nil && right == nil)
+		}
+		return false
+	}
+
+	var cmp int
+	switch l := left.(type) {
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return op == "!="
+		}
+		cmp = strings.Compare(l, r)
+	case bool:
+		r, ok := right.(bool)
+		if !ok {
+			return op == "!="
+		}
+		switch op {
+		case "==":
+			return l == r
+		case "!=":
+			return l != r
+		}
+		return false
+	case []byte:
+		r, ok := right.([]byte)
+		if !ok {
+			return op == "!="
+		}
+		cmp = bytes.Compare(l, r)
+	case int64, float64:
+		var ok bool
+		cmp, ok = compareNumbers(l, right)
+		if !ok {
+			return op == "!="
+		}
+	default:
+		return op == "!="
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+func compareNumbers(left, right interface{}) (int, bool) {
+	li, lIsInt := left.(int64)
+	ri, rIsInt := right.(int64)
+	if lIsInt && rIsInt {
+		switch {
+		case li < ri:
+			return -1, true
+		case li > ri:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	lf, ok := toFloat(left)
+	if !ok {
+		return 0, false
+	}
+	rf, ok := toFloat(right)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case lf < rf:
+		return -1, true
+	case lf > rf:
+		return 1, true
+	}
+	return 0, true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
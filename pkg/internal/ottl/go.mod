@@ -0,0 +1,11 @@
+module github.com/open-telemetry/opentelemetry-collector-contrib/internal/ottl
+
+go 1.14
+
+require (
+	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/collector/model v0.33.0
+	golang.org/x/net v0.0.0-20210614182718-04defd469f4e // indirect
+	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
+	golang.org/x/text v0.3.7 // indirect
+)
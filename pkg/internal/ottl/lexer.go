@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ottl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokFloat
+	tokOp
+	tokMinus
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+	tokDot
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q at position %d", t.text, t.pos)
+}
+
+// tokenize splits the condition into tokens. Keywords (and, or, not, true, false, nil)
+// are returned as identifiers and recognized by the parser.
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			s, n, err := readString(input[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w at position %d", err, i)
+			}
+			tokens = append(tokens, token{kind: tokString, text: s, pos: i})
+			i += n
+		case c >= '0' && c <= '9':
+			start := i
+			kind := tokInt
+			for i < len(input) && (isDigit(input[i]) || input[i] == '.') {
+				if input[i] == '.' {
+					if kind == tokFloat {
+						return nil, fmt.Errorf("invalid number at position %d", start)
+					}
+					kind = tokFloat
+				}
+				i++
+			}
+			tokens = append(tokens, token{kind: kind, text: input[start:i], pos: start})
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(input) && (input[i] == '_' || isDigit(input[i]) || unicode.IsLetter(rune(input[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: input[start:i], pos: start})
+		case strings.HasPrefix(input[i:], "=="), strings.HasPrefix(input[i:], "!="),
+			strings.HasPrefix(input[i:], "<="), strings.HasPrefix(input[i:], ">="):
+			tokens = append(tokens, token{kind: tokOp, text: input[i : i+2], pos: i})
+			i += 2
+		case c == '<' || c == '>':
+			tokens = append(tokens, token{kind: tokOp, text: input[i : i+1], pos: i})
+			i++
+		default:
+			kind, ok := punctuation[c]
+			if !ok {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{kind: kind, text: input[i : i+1], pos: i})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(input)}), nil
+}
+
+var punctuation = map[byte]tokenKind{
+	'-': tokMinus,
+	'(': tokLParen,
+	')': tokRParen,
+	'[': tokLBracket,
+	']': tokRBracket,
+	',': tokComma,
+	'.': tokDot,
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// readString reads a double quoted string literal from the beginning of input and returns
+// its unescaped value together with the number of consumed bytes.
+func readString(input string) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			if i+1 >= len(input) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			i++
+			switch input[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(input[i])
+			}
+		case '"':
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(input[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
@@ -0,0 +1,393 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ottl
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// boolExpr evaluates a (sub)condition for the given telemetry.
+type boolExpr func(tCtx *TransformContext) bool
+
+// Condition is a compiled OTTL boolean expression.
+type Condition struct {
+	text string
+	eval boolExpr
+}
+
+// ParseCondition compiles the condition for use in the given context. Supported are
+// paths (e.g. `attributes["http.status_code"]`, `resource.attributes["service.name"]`),
+// string, numeric, boolean and nil literals, enums (e.g. `STATUS_CODE_ERROR`),
+// comparisons (`==`, `!=`, `<`, `<=`, `>`, `>=`), `and`, `or`, `not`, parentheses
+// and the converter functions listed in functions.go.
+func ParseCondition(ctx Context, text string) (*Condition, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", text, err)
+	}
+	p := &parser{tokens: tokens, ctx: ctx}
+	eval, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %v", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", text, err)
+	}
+	return &Condition{text: text, eval: eval}, nil
+}
+
+// Eval returns true if the condition is met for the given telemetry.
+func (c *Condition) Eval(tCtx *TransformContext) bool {
+	return c.eval(tCtx)
+}
+
+// String returns the condition source.
+func (c *Condition) String() string {
+	return c.text
+}
+
+// Expression is a compiled OTTL value expression, optionally followed by a
+// `where <condition>` clause.
+type Expression struct {
+	text  string
+	get   getter
+	where boolExpr
+}
+
+// ParseExpression compiles the value expression (e.g. `Concat([attributes["k8s.namespace.name"],
+// attributes["k8s.pod.name"]], "/")`) for use in the given context. The expression might be
+// followed by `where` and a condition using the syntax accepted by ParseCondition.
+func ParseExpression(ctx Context, text string) (*Expression, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", text, err)
+	}
+	p := &parser{tokens: tokens, ctx: ctx}
+	expr := &Expression{text: text}
+	v, err := p.parseValue()
+	if err == nil && p.isKeyword("where") {
+		p.next()
+		expr.where, err = p.parseOr()
+	}
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %v", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", text, err)
+	}
+	expr.get = v.get
+	return expr, nil
+}
+
+// Eval returns the value of the expression for the given telemetry. The second result is
+// false when the `where` condition is not met, in which case the value is nil.
+func (e *Expression) Eval(tCtx *TransformContext) (interface{}, bool) {
+	if e.where != nil && !e.where(tCtx) {
+		return nil, false
+	}
+	return e.get(tCtx), true
+}
+
+// EvalString is like Eval, but converts the value to a string. The second result is false
+// when the condition is not met or the value is nil or cannot be represented as a string.
+func (e *Expression) EvalString(tCtx *TransformContext) (string, bool) {
+	v, ok := e.Eval(tCtx)
+	if !ok {
+		return "", false
+	}
+	return toString(v)
+}
+
+// String returns the expression source.
+func (e *Expression) String() string {
+	return e.text
+}
+
+// value is a compiled expression which yields a value. Literals are kept aside so
+// functions might validate (or precompile) their constant arguments.
+type value struct {
+	get       getter
+	literal   interface{}
+	isLiteral bool
+}
+
+func literalValue(v interface{}) value {
+	return value{
+		get:       func(*TransformContext) interface{} { return v },
+		literal:   v,
+		isLiteral: true,
+	}
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	ctx    Context
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s, got %v", what, t)
+	}
+	return t, nil
+}
+
+func (p *parser) isKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && t.text == word
+}
+
+func (p *parser) parseOr() (boolExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(tCtx *TransformContext) bool { return l(tCtx) || right(tCtx) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (boolExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(tCtx *TransformContext) bool { return l(tCtx) && right(tCtx) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (boolExpr, error) {
+	if p.isKeyword("not") {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(tCtx *TransformContext) bool { return !inner(tCtx) }, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (boolExpr, error) {
+	if p.peek().kind == tokLParen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	left, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokOp {
+		get := left.get
+		return func(tCtx *TransformContext) bool {
+			b, ok := get(tCtx).(bool)
+			return ok && b
+		}, nil
+	}
+
+	op := p.next().text
+	right, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	lget, rget := left.get, right.get
+	return func(tCtx *TransformContext) bool {
+		return compare(lget(tCtx), rget(tCtx), op)
+	}, nil
+}
+
+func (p *parser) parseValue() (value, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return literalValue(t.text), nil
+	case tokInt, tokFloat:
+		return parseNumber(t.text, false)
+	case tokMinus:
+		n := p.next()
+		if n.kind != tokInt && n.kind != tokFloat {
+			return value{}, fmt.Errorf("expected number after '-', got %v", n)
+		}
+		return parseNumber(n.text, true)
+	case tokLBracket:
+		return p.parseList()
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return literalValue(true), nil
+		case "false":
+			return literalValue(false), nil
+		case "nil":
+			return literalValue(nil), nil
+		}
+		if v, ok := enums[t.text]; ok {
+			return literalValue(v), nil
+		}
+		if p.peek().kind == tokLParen && unicode.IsUpper(rune(t.text[0])) {
+			return p.parseFunction(t)
+		}
+		return p.parsePath(t)
+	}
+	return value{}, fmt.Errorf("unexpected %v", t)
+}
+
+// parseList parses the remainder of a list literal (e.g. `["a", attributes["b"]]`).
+func (p *parser) parseList() (value, error) {
+	var items []value
+	for p.peek().kind != tokRBracket {
+		if len(items) > 0 {
+			if _, err := p.expect(tokComma, "','"); err != nil {
+				return value{}, err
+			}
+		}
+		item, err := p.parseValue()
+		if err != nil {
+			return value{}, err
+		}
+		items = append(items, item)
+	}
+	p.next() // ']'
+
+	return value{get: func(tCtx *TransformContext) interface{} {
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			list[i] = item.get(tCtx)
+		}
+		return list
+	}}, nil
+}
+
+func parseNumber(text string, negative bool) (value, error) {
+	if negative {
+		text = "-" + text
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return literalValue(i), nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return value{}, fmt.Errorf("invalid number %q", text)
+	}
+	return literalValue(f), nil
+}
+
+func (p *parser) parsePath(first token) (value, error) {
+	fields := []string{first.text}
+	for p.peek().kind == tokDot {
+		p.next()
+		t, err := p.expect(tokIdent, "path segment")
+		if err != nil {
+			return value{}, err
+		}
+		fields = append(fields, t.text)
+	}
+
+	get, err := resolvePath(p.ctx, fields)
+	if err != nil {
+		return value{}, err
+	}
+
+	for p.peek().kind == tokLBracket {
+		p.next()
+		var key interface{}
+		t := p.next()
+		switch t.kind {
+		case tokString:
+			key = t.text
+		case tokInt:
+			i, err := strconv.ParseInt(t.text, 10, 64)
+			if err != nil {
+				return value{}, fmt.Errorf("invalid index %v", t)
+			}
+			key = i
+		default:
+			return value{}, fmt.Errorf("expected string key or integer index, got %v", t)
+		}
+		if _, err := p.expect(tokRBracket, "']'"); err != nil {
+			return value{}, err
+		}
+		inner := get
+		get = func(tCtx *TransformContext) interface{} { return index(inner(tCtx), key) }
+	}
+
+	return value{get: get}, nil
+}
+
+func (p *parser) parseFunction(name token) (value, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return value{}, fmt.Errorf("unknown function %q", name.text)
+	}
+
+	p.next() // '('
+	var args []value
+	for p.peek().kind != tokRParen {
+		if len(args) > 0 {
+			if _, err := p.expect(tokComma, "','"); err != nil {
+				return value{}, err
+			}
+		}
+		arg, err := p.parseValue()
+		if err != nil {
+			return value{}, err
+		}
+		args = append(args, arg)
+	}
+	p.next() // ')'
+
+	get, err := fn(args)
+	if err != nil {
+		return value{}, fmt.Errorf("%s: %w", name.text, err)
+	}
+	return value{get: get}, nil
+}
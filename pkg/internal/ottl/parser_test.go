@@ -0,0 +1,221 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ottl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func newTestContext() *TransformContext {
+	resource := pdata.NewResource()
+	resource.Attributes().InsertString("service.name", "checkout")
+
+	il := pdata.NewInstrumentationLibrary()
+	il.SetName("io.opentelemetry.http")
+
+	span := pdata.NewSpan()
+	span.SetName("GET /cart")
+	span.SetKind(pdata.SpanKindServer)
+	span.Status().SetCode(pdata.StatusCodeError)
+	span.Attributes().InsertInt("http.status_code", 503)
+	span.Attributes().InsertDouble("ratio", 0.5)
+	span.Attributes().InsertBool("retry", true)
+	span.Attributes().InsertString("code", "42")
+	nested := pdata.NewAttributeValueMap()
+	nested.MapVal().InsertString("tenant", "acme")
+	span.Attributes().Insert("customer", nested)
+	list := pdata.NewAttributeValueArray()
+	list.ArrayVal().AppendEmpty().SetStringVal("first")
+	span.Attributes().Insert("list", list)
+
+	event := span.Events().AppendEmpty()
+	event.SetName("exception")
+	event.Attributes().InsertString("exception.type", "java.lang.NullPointerException")
+
+	return &TransformContext{
+		Resource:               resource,
+		InstrumentationLibrary: il,
+		Span:                   span,
+		SpanEvent:              event,
+	}
+}
+
+func TestSpanConditions(t *testing.T) {
+	cases := []struct {
+		condition string
+		expected  bool
+	}{
+		{`name == "GET /cart"`, true},
+		{`name != "GET /cart"`, false},
+		{`kind == SPAN_KIND_SERVER`, true},
+		{`status.code == STATUS_CODE_ERROR`, true},
+		{`attributes["http.status_code"] >= 500`, true},
+		{`attributes["http.status_code"] < 500`, false},
+		{`attributes["http.status_code"] == 503.0`, true},
+		{`attributes["ratio"] > 0.25 and attributes["ratio"] < 1`, true},
+		{`attributes["retry"]`, true},
+		{`attributes["retry"] == false`, false},
+		{`attributes["missing"] == nil`, true},
+		{`attributes["missing"] != nil`, false},
+		{`attributes["missing"] > 5`, false},
+		{`attributes["code"] == 42`, false},
+		{`Int(attributes["code"]) == 42`, true},
+		{`Double(attributes["http.status_code"]) == 503`, true},
+		{`attributes["customer"]["tenant"] == "acme"`, true},
+		{`attributes["list"][0] == "first"`, true},
+		{`attributes["list"][1] == nil`, true},
+		{`Len(attributes["list"]) == 1`, true},
+		{`resource.attributes["service.name"] == "checkout"`, true},
+		{`instrumentation_library.name == "io.opentelemetry.http"`, true},
+		{`IsMatch(name, "^GET /c.*")`, true},
+		{`IsMatch(ConvertCase(name, "lower"), "^get")`, true},
+		{`not IsMatch(name, "^POST")`, true},
+		{`name == "foo" or (kind == SPAN_KIND_SERVER and not attributes["retry"] == false)`, true},
+		{`name == "foo" or kind == SPAN_KIND_CLIENT`, false},
+		{`attributes["http.status_code"] > -1`, true},
+		{`Len(["a", attributes["code"]]) == 2`, true},
+		{`Concat([name, kind], " ") == "GET /cart 2"`, true},
+		{`Substring(name, 0, 3) == "GET"`, true},
+		{`Substring(name, 5, 10) == nil`, true},
+	}
+
+	tCtx := newTestContext()
+	for _, c := range cases {
+		t.Run(c.condition, func(t *testing.T) {
+			condition, err := ParseCondition(SpanContext, c.condition)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, condition.Eval(tCtx))
+		})
+	}
+}
+
+func TestSpanEventConditions(t *testing.T) {
+	cases := []struct {
+		condition string
+		expected  bool
+	}{
+		{`name == "exception"`, true},
+		{`IsMatch(attributes["exception.type"], "NullPointer")`, true},
+		{`span.name == "GET /cart" and span.attributes["http.status_code"] == 503`, true},
+		{`resource.attributes["service.name"] == "payments"`, false},
+	}
+
+	tCtx := newTestContext()
+	for _, c := range cases {
+		t.Run(c.condition, func(t *testing.T) {
+			condition, err := ParseCondition(SpanEventContext, c.condition)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, condition.Eval(tCtx))
+		})
+	}
+}
+
+func TestInvalidConditions(t *testing.T) {
+	cases := []string{
+		``,
+		`name ==`,
+		`name == "unterminated`,
+		`unknown_path == 1`,
+		`time_unix_nano > 0`,
+		`attributes["a" == 1`,
+		`(name == "a"`,
+		`name == "a" extra`,
+		`Unknown(name)`,
+		`IsMatch(name)`,
+		`IsMatch(name, attributes["pattern"])`,
+		`IsMatch(name, "[")`,
+		`ConvertCase(name, "kebab")`,
+		`attributes["a"] == 1.2.3`,
+		`name == #`,
+	}
+
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			_, err := ParseCondition(SpanContext, c)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConditionString(t *testing.T) {
+	condition, err := ParseCondition(SpanContext, `name == "a"`)
+	require.NoError(t, err)
+	assert.Equal(t, `name == "a"`, condition.String())
+}
+
+func TestResourceExpressions(t *testing.T) {
+	cases := []struct {
+		expression string
+		expected   string
+		found      bool
+	}{
+		{`attributes["service.name"]`, "checkout", true},
+		{`resource.attributes["service.name"]`, "checkout", true},
+		{`ConvertCase(attributes["service.name"], "upper")`, "CHECKOUT", true},
+		{`Concat(["prod", attributes["service.name"]], "/")`, "prod/checkout", true},
+		{`Concat(["prod", attributes["missing"], 1], "-")`, "prod--1", true},
+		{`Substring(attributes["service.name"], 0, 5)`, "check", true},
+		{`"static"`, "static", true},
+		{`attributes["missing"]`, "", false},
+		{`"checkout" where attributes["service.name"] == "checkout"`, "checkout", true},
+		{`"other" where not IsMatch(attributes["service.name"], "^check")`, "", false},
+	}
+
+	tCtx := newTestContext()
+	for _, c := range cases {
+		t.Run(c.expression, func(t *testing.T) {
+			expression, err := ParseExpression(ResourceContext, c.expression)
+			require.NoError(t, err)
+			value, found := expression.EvalString(tCtx)
+			assert.Equal(t, c.found, found)
+			assert.Equal(t, c.expected, value)
+		})
+	}
+}
+
+func TestInvalidExpressions(t *testing.T) {
+	cases := []string{
+		``,
+		`name`,
+		`attributes["a"] where`,
+		`attributes["a"] == "b"`,
+		`Concat(["a", "b"])`,
+		`Concat(["a", "b"], attributes["delimiter"])`,
+		`Concat(["a" "b"], "")`,
+		`Concat(["a", "b", "")`,
+		`Substring(attributes["a"], 0)`,
+	}
+
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			_, err := ParseExpression(ResourceContext, c)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExpressionEval(t *testing.T) {
+	expression, err := ParseExpression(SpanContext, `attributes["http.status_code"] where kind == SPAN_KIND_SERVER`)
+	require.NoError(t, err)
+	assert.Equal(t, `attributes["http.status_code"] where kind == SPAN_KIND_SERVER`, expression.String())
+
+	value, ok := expression.Eval(newTestContext())
+	assert.True(t, ok)
+	assert.Equal(t, int64(503), value)
+}
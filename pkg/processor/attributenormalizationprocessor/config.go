@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributenormalizationprocessor
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the attribute normalization.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Rename maps the attribute keys to their new names. A renamed key is not normalized further.
+	Rename map[string]string `mapstructure:"rename"`
+	// TrimPrefixes are the prefixes removed from the keys, only the first matching one is removed
+	TrimPrefixes []string `mapstructure:"trim_prefixes"`
+	// Separator replaces the dots and underscores separating the words of the keys: either "." or "_".
+	// The keys are not changed when it is empty.
+	Separator string `mapstructure:"separator"`
+	// Case folds the keys to either lower or upper case. The keys are not changed when it is empty.
+	Case string `mapstructure:"case"`
+	// IncludeResourceAttributes enables normalizing the resource attributes too
+	IncludeResourceAttributes bool `mapstructure:"include_resource_attributes"`
+	// ExcludeAttributes lists the attributes which are never normalized, e.g. _sourceCategory
+	ExcludeAttributes []string `mapstructure:"exclude_attributes"`
+}
+
+const (
+	caseLower = "lower"
+	caseUpper = "upper"
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	switch cfg.Separator {
+	case "", ".", "_":
+	default:
+		return fmt.Errorf("separator must be either \".\" or \"_\", got %q", cfg.Separator)
+	}
+	switch cfg.Case {
+	case "", caseLower, caseUpper:
+	default:
+		return fmt.Errorf("case must be either %q or %q, got %q", caseLower, caseUpper, cfg.Case)
+	}
+	for from, to := range cfg.Rename {
+		if to == "" {
+			return fmt.Errorf("the new name of attribute %q must not be empty", from)
+		}
+	}
+	for _, prefix := range cfg.TrimPrefixes {
+		if prefix == "" {
+			return fmt.Errorf("trim_prefixes must not contain an empty prefix")
+		}
+	}
+	return nil
+}
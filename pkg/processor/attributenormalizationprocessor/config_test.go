@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributenormalizationprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "attribute_normalization_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)], factory.CreateDefaultConfig())
+
+	id := config.NewIDWithName(typeStr, "custom")
+	assert.Equal(t, cfg.Processors[id],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(id),
+			// the keys of the map keep their case
+			Rename:            map[string]string{"HostName": "host.name", "k8s_pod": "k8s.pod.name"},
+			TrimPrefixes:      []string{"tag_", "label."},
+			Separator:         ".",
+			Case:              caseLower,
+			ExcludeAttributes: []string{"_sourceCategory"},
+		})
+}
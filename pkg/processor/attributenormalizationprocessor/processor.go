@@ -0,0 +1,206 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributenormalizationprocessor
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// attributeNormalizationProcessor normalizes the attribute keys of all the signals with the same rules
+type attributeNormalizationProcessor struct {
+	rename                    map[string]string
+	trimPrefixes              []string
+	separator                 string
+	caseFolding               string
+	includeResourceAttributes bool
+	excludeAttributes         map[string]bool
+}
+
+// keyRename is a key of an attribute map to be renamed
+type keyRename struct {
+	from string
+	to   string
+}
+
+func newAttributeNormalizationProcessor(cfg *Config) (*attributeNormalizationProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	anp := &attributeNormalizationProcessor{
+		rename:                    cfg.Rename,
+		trimPrefixes:              cfg.TrimPrefixes,
+		separator:                 cfg.Separator,
+		caseFolding:               cfg.Case,
+		includeResourceAttributes: cfg.IncludeResourceAttributes,
+		excludeAttributes:         map[string]bool{},
+	}
+	for _, name := range cfg.ExcludeAttributes {
+		anp.excludeAttributes[name] = true
+	}
+	return anp, nil
+}
+
+// ProcessTraces normalizes the attributes of the spans, their events and links
+func (anp *attributeNormalizationProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		anp.normalizeResource(rs.Resource())
+		ilss := rs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				anp.normalizeAttributes(span.Attributes())
+				events := span.Events()
+				for l := 0; l < events.Len(); l++ {
+					anp.normalizeAttributes(events.At(l).Attributes())
+				}
+				links := span.Links()
+				for l := 0; l < links.Len(); l++ {
+					anp.normalizeAttributes(links.At(l).Attributes())
+				}
+			}
+		}
+	}
+	return td, nil
+}
+
+// ProcessMetrics normalizes the attributes of the data points
+func (anp *attributeNormalizationProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		anp.normalizeResource(rm.Resource())
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				anp.normalizeMetric(metrics.At(k))
+			}
+		}
+	}
+	return md, nil
+}
+
+func (anp *attributeNormalizationProcessor) normalizeMetric(metric pdata.Metric) {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			anp.normalizeAttributes(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			anp.normalizeAttributes(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			anp.normalizeAttributes(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			anp.normalizeAttributes(dps.At(i).Attributes())
+		}
+	}
+}
+
+// ProcessLogs normalizes the attributes of the log records
+func (anp *attributeNormalizationProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		anp.normalizeResource(rl.Resource())
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				anp.normalizeAttributes(logs.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (anp *attributeNormalizationProcessor) normalizeResource(resource pdata.Resource) {
+	if anp.includeResourceAttributes {
+		anp.normalizeAttributes(resource.Attributes())
+	}
+}
+
+// normalizeAttributes renames the attributes to their normalized keys. When an attribute with
+// the normalized key already exists, its value is kept and the renamed attribute is dropped.
+func (anp *attributeNormalizationProcessor) normalizeAttributes(attributes pdata.AttributeMap) {
+	var renames []keyRename
+	attributes.Range(func(key string, _ pdata.AttributeValue) bool {
+		if normalized := anp.normalize(key); normalized != key {
+			renames = append(renames, keyRename{from: key, to: normalized})
+		}
+		return true
+	})
+
+	for _, r := range renames {
+		value, ok := attributes.Get(r.from)
+		if !ok {
+			continue
+		}
+		if _, exists := attributes.Get(r.to); !exists {
+			attributes.Insert(r.to, value)
+		}
+		attributes.Delete(r.from)
+	}
+}
+
+// normalize returns the normalized key: renamed, or with the prefix trimmed, the separators replaced
+// and the case folded
+func (anp *attributeNormalizationProcessor) normalize(key string) string {
+	if anp.excludeAttributes[key] {
+		return key
+	}
+	if to, ok := anp.rename[key]; ok {
+		return to
+	}
+
+	for _, prefix := range anp.trimPrefixes {
+		if len(key) > len(prefix) && strings.HasPrefix(key, prefix) {
+			key = key[len(prefix):]
+			break
+		}
+	}
+
+	switch anp.separator {
+	case "_":
+		key = strings.ReplaceAll(key, ".", "_")
+	case ".":
+		// the leading underscores are kept, as they mark the Sumo Logic built-in fields, e.g. _sourceCategory
+		trimmed := strings.TrimLeft(key, "_")
+		key = key[:len(key)-len(trimmed)] + strings.ReplaceAll(trimmed, "_", ".")
+	}
+
+	switch anp.caseFolding {
+	case caseLower:
+		key = strings.ToLower(key)
+	case caseUpper:
+		key = strings.ToUpper(key)
+	}
+	return key
+}
@@ -0,0 +1,224 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributenormalizationprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) *attributeNormalizationProcessor {
+	cfg := createDefaultConfig().(*Config)
+	modify(cfg)
+	anp, err := newAttributeNormalizationProcessor(cfg)
+	require.NoError(t, err)
+	return anp
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
+
+func TestNormalize(t *testing.T) {
+	testcases := []struct {
+		name     string
+		modify   func(cfg *Config)
+		key      string
+		expected string
+	}{
+		{
+			name:     "dots to underscores",
+			modify:   func(cfg *Config) { cfg.Separator = "_" },
+			key:      "k8s.pod.name",
+			expected: "k8s_pod_name",
+		},
+		{
+			name:     "underscores to dots",
+			modify:   func(cfg *Config) { cfg.Separator = "." },
+			key:      "k8s_pod_name",
+			expected: "k8s.pod.name",
+		},
+		{
+			name:     "leading underscores kept",
+			modify:   func(cfg *Config) { cfg.Separator = "." },
+			key:      "_source_category",
+			expected: "_source.category",
+		},
+		{
+			name:     "lower case",
+			modify:   func(cfg *Config) { cfg.Case = caseLower },
+			key:      "Host.Name",
+			expected: "host.name",
+		},
+		{
+			name:     "upper case",
+			modify:   func(cfg *Config) { cfg.Case = caseUpper },
+			key:      "host",
+			expected: "HOST",
+		},
+		{
+			name: "first prefix trimmed",
+			modify: func(cfg *Config) {
+				cfg.TrimPrefixes = []string{"tag_", "tag_tag_"}
+				cfg.Separator = "."
+			},
+			key:      "tag_tag_service_name",
+			expected: "tag.service.name",
+		},
+		{
+			name:     "prefix only key kept",
+			modify:   func(cfg *Config) { cfg.TrimPrefixes = []string{"tag_"} },
+			key:      "tag_",
+			expected: "tag_",
+		},
+		{
+			name: "rename takes precedence",
+			modify: func(cfg *Config) {
+				cfg.Rename = map[string]string{"HostName": "host.name"}
+				cfg.Case = caseUpper
+			},
+			key:      "HostName",
+			expected: "host.name",
+		},
+		{
+			name: "excluded",
+			modify: func(cfg *Config) {
+				cfg.Rename = map[string]string{"_sourceCategory": "category"}
+				cfg.Case = caseLower
+				cfg.ExcludeAttributes = []string{"_sourceCategory"}
+			},
+			key:      "_sourceCategory",
+			expected: "_sourceCategory",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			anp := newTestProcessor(t, tc.modify)
+			assert.Equal(t, tc.expected, anp.normalize(tc.key))
+		})
+	}
+}
+
+func TestNormalizeAttributesConflict(t *testing.T) {
+	anp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Separator = "."
+	})
+
+	attributes := pdata.NewAttributeMap()
+	attributes.InsertString("host_name", "renamed")
+	attributes.InsertString("host.name", "existing")
+	attributes.InsertString("pod_name", "web-1")
+	attributes.InsertInt("status_code", 200)
+	anp.normalizeAttributes(attributes)
+
+	assert.Equal(t, map[string]string{
+		"host.name":   "existing",
+		"pod.name":    "web-1",
+		"status.code": "200",
+	}, attributesToMap(attributes))
+	statusCode, _ := attributes.Get("status.code")
+	assert.Equal(t, pdata.AttributeValueTypeInt, statusCode.Type())
+}
+
+func TestProcessTraces(t *testing.T) {
+	anp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Separator = "_"
+	})
+
+	td := pdata.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().InsertString("service.name", "checkout")
+	span := rs.InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().InsertString("http.method", "GET")
+	span.Events().AppendEmpty().Attributes().InsertString("exception.type", "IOError")
+	span.Links().AppendEmpty().Attributes().InsertString("link.kind", "parent")
+
+	td, err := anp.ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"service_name": "checkout"}, attributesToMap(rs.Resource().Attributes()))
+	assert.Equal(t, map[string]string{"http_method": "GET"}, attributesToMap(span.Attributes()))
+	assert.Equal(t, map[string]string{"exception_type": "IOError"}, attributesToMap(span.Events().At(0).Attributes()))
+	assert.Equal(t, map[string]string{"link_kind": "parent"}, attributesToMap(span.Links().At(0).Attributes()))
+}
+
+func TestProcessMetrics(t *testing.T) {
+	anp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Separator = "."
+		cfg.IncludeResourceAttributes = false
+	})
+
+	md := pdata.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("host_name", "web-1")
+	metrics := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	for _, dataType := range []pdata.MetricDataType{
+		pdata.MetricDataTypeGauge, pdata.MetricDataTypeSum, pdata.MetricDataTypeHistogram, pdata.MetricDataTypeSummary,
+	} {
+		metric := metrics.AppendEmpty()
+		metric.SetDataType(dataType)
+		switch dataType {
+		case pdata.MetricDataTypeGauge:
+			metric.Gauge().DataPoints().AppendEmpty().Attributes().InsertString("cpu_core", "0")
+		case pdata.MetricDataTypeSum:
+			metric.Sum().DataPoints().AppendEmpty().Attributes().InsertString("cpu_core", "0")
+		case pdata.MetricDataTypeHistogram:
+			metric.Histogram().DataPoints().AppendEmpty().Attributes().InsertString("cpu_core", "0")
+		case pdata.MetricDataTypeSummary:
+			metric.Summary().DataPoints().AppendEmpty().Attributes().InsertString("cpu_core", "0")
+		}
+	}
+
+	md, err := anp.ProcessMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host_name": "web-1"}, attributesToMap(rm.Resource().Attributes()))
+	expected := map[string]string{"cpu.core": "0"}
+	assert.Equal(t, expected, attributesToMap(metrics.At(0).Gauge().DataPoints().At(0).Attributes()))
+	assert.Equal(t, expected, attributesToMap(metrics.At(1).Sum().DataPoints().At(0).Attributes()))
+	assert.Equal(t, expected, attributesToMap(metrics.At(2).Histogram().DataPoints().At(0).Attributes()))
+	assert.Equal(t, expected, attributesToMap(metrics.At(3).Summary().DataPoints().At(0).Attributes()))
+}
+
+func TestProcessLogs(t *testing.T) {
+	anp := newTestProcessor(t, func(cfg *Config) {
+		cfg.TrimPrefixes = []string{"fields."}
+		cfg.Case = caseLower
+		cfg.ExcludeAttributes = []string{"_sourceCategory"}
+	})
+
+	ld := pdata.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("_sourceCategory", "prod/web")
+	lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	lr.Attributes().InsertString("fields.Level", "info")
+	lr.Attributes().InsertString("Log.File.Path", "/var/log/app.log")
+
+	ld, err := anp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"_sourceCategory": "prod/web"}, attributesToMap(rl.Resource().Attributes()))
+	assert.Equal(t, map[string]string{
+		"level":         "info",
+		"log.file.path": "/var/log/app.log",
+	}, attributesToMap(lr.Attributes()))
+}
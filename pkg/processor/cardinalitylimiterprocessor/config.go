@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the cardinality limiting.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// MaxSeriesPerMetric is the number of active series of each metric name above which the new series are limited
+	MaxSeriesPerMetric int `mapstructure:"max_series_per_metric"`
+	// Action is either drop, dropping the data points of the new series, or overflow, aggregating them
+	// into the overflow series
+	Action string `mapstructure:"action"`
+	// OverflowKeepLabels are the labels kept on the overflow series, all the other ones are removed
+	OverflowKeepLabels []string `mapstructure:"overflow_keep_labels"`
+	// OverflowAttribute is the label added to the overflow series
+	OverflowAttribute string `mapstructure:"overflow_attribute"`
+	// SeriesTTL is the time after which a series which has not been received is no longer active
+	SeriesTTL time.Duration `mapstructure:"series_ttl"`
+}
+
+const (
+	actionDrop     = "drop"
+	actionOverflow = "overflow"
+
+	defaultMaxSeriesPerMetric = 1000
+	defaultOverflowAttribute  = "otel.metric.overflow"
+	defaultSeriesTTL          = 5 * time.Minute
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.MaxSeriesPerMetric <= 0 {
+		return fmt.Errorf("max_series_per_metric must be positive, got %d", cfg.MaxSeriesPerMetric)
+	}
+	if cfg.Action != actionDrop && cfg.Action != actionOverflow {
+		return fmt.Errorf("unknown action %q, expected %s or %s", cfg.Action, actionDrop, actionOverflow)
+	}
+	if cfg.Action == actionOverflow && cfg.OverflowAttribute == "" {
+		return fmt.Errorf("overflow_attribute must not be empty")
+	}
+	if cfg.SeriesTTL <= 0 {
+		return fmt.Errorf("series_ttl must be positive, got %s", cfg.SeriesTTL)
+	}
+	return nil
+}
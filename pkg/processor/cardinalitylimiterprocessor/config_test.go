@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "cardinality_limiter_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings:  config.NewProcessorSettings(config.NewID(typeStr)),
+			MaxSeriesPerMetric: 500,
+			Action:             actionOverflow,
+			OverflowKeepLabels: []string{"service", "status_code"},
+			OverflowAttribute:  defaultOverflowAttribute,
+			SeriesTTL:          10 * time.Minute,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{name: "no limit", modify: func(cfg *Config) { cfg.MaxSeriesPerMetric = 0 }},
+		{name: "unknown action", modify: func(cfg *Config) { cfg.Action = "sample" }},
+		{name: "no overflow attribute", modify: func(cfg *Config) {
+			cfg.Action = actionOverflow
+			cfg.OverflowAttribute = ""
+		}},
+		{name: "no ttl", modify: func(cfg *Config) { cfg.SeriesTTL = 0 }},
+	}
+
+	assert.NoError(t, createDefaultConfig().(*Config).Validate())
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+const (
+	// The value of "type" Cardinality Limiter in configuration.
+	typeStr = "cardinality_limiter"
+)
+
+var processorCapabilities = consumer.Capabilities{MutatesData: true}
+
+// NewFactory returns a new factory for the Cardinality Limiter processor.
+func NewFactory() component.ProcessorFactory {
+	return processorhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		processorhelper.WithMetrics(createMetricsProcessor))
+}
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings:  config.NewProcessorSettings(config.NewID(typeStr)),
+		MaxSeriesPerMetric: defaultMaxSeriesPerMetric,
+		Action:             actionDrop,
+		OverflowAttribute:  defaultOverflowAttribute,
+		SeriesTTL:          defaultSeriesTTL,
+	}
+}
+
+func createMetricsProcessor(
+	_ context.Context,
+	params component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Metrics,
+) (component.MetricsProcessor, error) {
+	mCfg := cfg.(*Config)
+	if err := mCfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	clp := newCardinalityLimiterProcessor(params.Logger, mCfg)
+
+	return processorhelper.NewMetricsProcessor(
+		cfg,
+		nextConsumer,
+		clp.ProcessMetrics,
+		processorhelper.WithCapabilities(processorCapabilities))
+}
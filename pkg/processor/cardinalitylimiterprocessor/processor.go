@@ -0,0 +1,329 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/attrhash"
+)
+
+// cardinalityLimiterProcessor tracks the active series of each metric name and limits the new series
+// above the configured number
+type cardinalityLimiterProcessor struct {
+	logger            *zap.Logger
+	maxSeries         int
+	overflow          bool
+	keepLabels        map[string]bool
+	overflowAttribute string
+	seriesTTL         time.Duration
+	now               func() time.Time
+
+	mu        sync.Mutex
+	metrics   map[string]*metricSeries
+	lastSweep time.Time
+}
+
+// metricSeries holds the active series of a metric name
+type metricSeries struct {
+	// lastSeen is the time each series was last received at, by the hash of its resource and labels
+	lastSeen map[uint64]time.Time
+	// limited is set once the limit is reached, so it is logged once
+	limited bool
+}
+
+func newCardinalityLimiterProcessor(logger *zap.Logger, cfg *Config) *cardinalityLimiterProcessor {
+	clp := &cardinalityLimiterProcessor{
+		logger:            logger,
+		maxSeries:         cfg.MaxSeriesPerMetric,
+		overflow:          cfg.Action == actionOverflow,
+		keepLabels:        map[string]bool{},
+		overflowAttribute: cfg.OverflowAttribute,
+		seriesTTL:         cfg.SeriesTTL,
+		now:               time.Now,
+		metrics:           map[string]*metricSeries{},
+	}
+	for _, label := range cfg.OverflowKeepLabels {
+		clp.keepLabels[label] = true
+	}
+	return clp
+}
+
+// ProcessMetrics drops or aggregates the data points of the series above the limit
+func (clp *cardinalityLimiterProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	now := clp.now()
+
+	clp.mu.Lock()
+	defer clp.mu.Unlock()
+	clp.sweep(now)
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resourceKey := attrhash.Attributes(fnv.New64a(), rm.Resource().Attributes())
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				clp.limitMetric(metrics.At(k), resourceKey, now)
+			}
+			metrics.RemoveIf(func(metric pdata.Metric) bool {
+				return dataPointCount(metric) == 0
+			})
+		}
+	}
+	return md, nil
+}
+
+func (clp *cardinalityLimiterProcessor) limitMetric(metric pdata.Metric, resourceKey uint64, now time.Time) {
+	series := clp.metrics[metric.Name()]
+	if series == nil {
+		series = &metricSeries{lastSeen: map[uint64]time.Time{}}
+		clp.metrics[metric.Name()] = series
+	}
+	admit := func(attributes pdata.AttributeMap) bool {
+		return clp.admit(metric.Name(), series, resourceKey, attributes, now)
+	}
+
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		clp.limitNumberDataPoints(metric.Gauge().DataPoints(), admit, mergeGauge)
+	case pdata.MetricDataTypeSum:
+		clp.limitNumberDataPoints(metric.Sum().DataPoints(), admit, mergeSum)
+	case pdata.MetricDataTypeHistogram:
+		clp.limitHistogramDataPoints(metric.Histogram().DataPoints(), admit)
+	case pdata.MetricDataTypeSummary:
+		clp.limitSummaryDataPoints(metric.Summary().DataPoints(), admit)
+	}
+}
+
+// admit tracks the series and checks whether it is within the limit
+func (clp *cardinalityLimiterProcessor) admit(
+	name string,
+	series *metricSeries,
+	resourceKey uint64,
+	attributes pdata.AttributeMap,
+	now time.Time,
+) bool {
+	h := fnv.New64a()
+	attrhash.WriteUint64(h, resourceKey)
+	key := attrhash.Attributes(h, attributes)
+	if _, ok := series.lastSeen[key]; ok || len(series.lastSeen) < clp.maxSeries {
+		series.lastSeen[key] = now
+		return true
+	}
+
+	if !series.limited {
+		series.limited = true
+		if clp.overflow {
+			clp.logger.Warn("Metric reached the series limit, the new series are aggregated into the overflow series",
+				zap.String("metric", name), zap.Int("max_series_per_metric", clp.maxSeries))
+		} else {
+			clp.logger.Warn("Metric reached the series limit, the new series are dropped",
+				zap.String("metric", name), zap.Int("max_series_per_metric", clp.maxSeries))
+		}
+	}
+	return false
+}
+
+// reduceLabels turns the labels of the data point into the ones of the overflow series
+func (clp *cardinalityLimiterProcessor) reduceLabels(attributes pdata.AttributeMap) uint64 {
+	var removed []string
+	attributes.Range(func(name string, _ pdata.AttributeValue) bool {
+		if !clp.keepLabels[name] {
+			removed = append(removed, name)
+		}
+		return true
+	})
+	for _, name := range removed {
+		attributes.Delete(name)
+	}
+	attributes.UpsertBool(clp.overflowAttribute, true)
+	return attrhash.Attributes(fnv.New64a(), attributes)
+}
+
+func (clp *cardinalityLimiterProcessor) limitNumberDataPoints(
+	dps pdata.NumberDataPointSlice,
+	admit func(pdata.AttributeMap) bool,
+	merge func(dest, src pdata.NumberDataPoint),
+) {
+	overflows := map[uint64]pdata.NumberDataPoint{}
+	dps.RemoveIf(func(dp pdata.NumberDataPoint) bool {
+		if admit(dp.Attributes()) {
+			return false
+		}
+		if !clp.overflow {
+			return true
+		}
+		key := clp.reduceLabels(dp.Attributes())
+		if dest, ok := overflows[key]; ok {
+			merge(dest, dp)
+			return true
+		}
+		overflows[key] = dp
+		return false
+	})
+}
+
+func (clp *cardinalityLimiterProcessor) limitHistogramDataPoints(
+	dps pdata.HistogramDataPointSlice,
+	admit func(pdata.AttributeMap) bool,
+) {
+	overflows := map[uint64]pdata.HistogramDataPoint{}
+	dps.RemoveIf(func(dp pdata.HistogramDataPoint) bool {
+		if admit(dp.Attributes()) {
+			return false
+		}
+		if !clp.overflow {
+			return true
+		}
+		key := clp.reduceLabels(dp.Attributes())
+		// The histograms with different buckets cannot be merged, so they are kept as separate data points
+		if dest, ok := overflows[key]; ok && equalBounds(dest.ExplicitBounds(), dp.ExplicitBounds()) {
+			mergeHistogram(dest, dp)
+			return true
+		}
+		overflows[key] = dp
+		return false
+	})
+}
+
+func (clp *cardinalityLimiterProcessor) limitSummaryDataPoints(
+	dps pdata.SummaryDataPointSlice,
+	admit func(pdata.AttributeMap) bool,
+) {
+	overflows := map[uint64]pdata.SummaryDataPoint{}
+	dps.RemoveIf(func(dp pdata.SummaryDataPoint) bool {
+		if admit(dp.Attributes()) {
+			return false
+		}
+		if !clp.overflow {
+			return true
+		}
+		key := clp.reduceLabels(dp.Attributes())
+		// The quantiles cannot be merged, so the latest data point is kept
+		if dest, ok := overflows[key]; ok {
+			if dp.Timestamp() > dest.Timestamp() {
+				dp.CopyTo(dest)
+			}
+			return true
+		}
+		overflows[key] = dp
+		return false
+	})
+}
+
+// mergeGauge keeps the latest value
+func mergeGauge(dest, src pdata.NumberDataPoint) {
+	if src.Timestamp() > dest.Timestamp() {
+		src.CopyTo(dest)
+	}
+}
+
+// mergeSum adds the values and keeps the latest timestamp
+func mergeSum(dest, src pdata.NumberDataPoint) {
+	if dest.Type() == pdata.MetricValueTypeInt && src.Type() == pdata.MetricValueTypeInt {
+		dest.SetIntVal(dest.IntVal() + src.IntVal())
+	} else {
+		dest.SetDoubleVal(numberValue(dest) + numberValue(src))
+	}
+	if src.Timestamp() > dest.Timestamp() {
+		dest.SetTimestamp(src.Timestamp())
+	}
+	if src.StartTimestamp() < dest.StartTimestamp() {
+		dest.SetStartTimestamp(src.StartTimestamp())
+	}
+}
+
+// mergeHistogram adds the counts and sums of the histograms with the same buckets
+func mergeHistogram(dest, src pdata.HistogramDataPoint) {
+	dest.SetCount(dest.Count() + src.Count())
+	dest.SetSum(dest.Sum() + src.Sum())
+	counts := dest.BucketCounts()
+	srcCounts := src.BucketCounts()
+	for i := range counts {
+		if i < len(srcCounts) {
+			counts[i] += srcCounts[i]
+		}
+	}
+	dest.SetBucketCounts(counts)
+	if src.Timestamp() > dest.Timestamp() {
+		dest.SetTimestamp(src.Timestamp())
+	}
+	if src.StartTimestamp() < dest.StartTimestamp() {
+		dest.SetStartTimestamp(src.StartTimestamp())
+	}
+}
+
+func numberValue(dp pdata.NumberDataPoint) float64 {
+	if dp.Type() == pdata.MetricValueTypeInt {
+		return float64(dp.IntVal())
+	}
+	return dp.DoubleVal()
+}
+
+func equalBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sweep forgets the series which have not been received within the TTL, so the new series can take their place
+func (clp *cardinalityLimiterProcessor) sweep(now time.Time) {
+	if now.Sub(clp.lastSweep) < clp.seriesTTL/2 {
+		return
+	}
+	clp.lastSweep = now
+
+	for name, series := range clp.metrics {
+		for key, lastSeen := range series.lastSeen {
+			if now.Sub(lastSeen) > clp.seriesTTL {
+				delete(series.lastSeen, key)
+			}
+		}
+		if len(series.lastSeen) == 0 {
+			delete(clp.metrics, name)
+		} else if len(series.lastSeen) < clp.maxSeries {
+			series.limited = false
+		}
+	}
+}
+
+func dataPointCount(metric pdata.Metric) int {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		return metric.Gauge().DataPoints().Len()
+	case pdata.MetricDataTypeSum:
+		return metric.Sum().DataPoints().Len()
+	case pdata.MetricDataTypeHistogram:
+		return metric.Histogram().DataPoints().Len()
+	case pdata.MetricDataTypeSummary:
+		return metric.Summary().DataPoints().Len()
+	}
+	// The metrics without a type are left as they are
+	return 1
+}
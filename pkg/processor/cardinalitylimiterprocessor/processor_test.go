@@ -0,0 +1,198 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+func newTestProcessor(modify func(cfg *Config)) (*cardinalityLimiterProcessor, *time.Time) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxSeriesPerMetric = 2
+	if modify != nil {
+		modify(cfg)
+	}
+	clp := newCardinalityLimiterProcessor(zap.NewNop(), cfg)
+	now := time.Unix(1600000000, 0)
+	clp.now = func() time.Time {
+		return now
+	}
+	return clp, &now
+}
+
+// newSums builds a sum with a data point for each of the users
+func newSums(name string, users ...string) pdata.Metrics {
+	md := pdata.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("k8s.pod.name", "api")
+	metric := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pdata.MetricDataTypeSum)
+	for i, user := range users {
+		dp := metric.Sum().DataPoints().AppendEmpty()
+		dp.Attributes().InsertString("user", user)
+		dp.Attributes().InsertString("service", "api")
+		dp.SetIntVal(1)
+		dp.SetTimestamp(pdata.Timestamp(i + 1))
+	}
+	return md
+}
+
+func sumDataPoints(md pdata.Metrics) pdata.NumberDataPointSlice {
+	return md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Sum().DataPoints()
+}
+
+func users(dps pdata.NumberDataPointSlice) []string {
+	var result []string
+	for i := 0; i < dps.Len(); i++ {
+		user, _ := dps.At(i).Attributes().Get("user")
+		result = append(result, user.StringVal())
+	}
+	return result
+}
+
+func TestDropNewSeries(t *testing.T) {
+	clp, _ := newTestProcessor(nil)
+
+	md, err := clp.ProcessMetrics(context.Background(), newSums("requests", "alice", "bob", "carol"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, users(sumDataPoints(md)))
+
+	// The active series are still accepted, while the limit applies per metric name
+	md, err = clp.ProcessMetrics(context.Background(), newSums("requests", "dave", "bob"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, users(sumDataPoints(md)))
+
+	md, err = clp.ProcessMetrics(context.Background(), newSums("errors", "dave"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dave"}, users(sumDataPoints(md)))
+
+	// The metrics left without data points are removed
+	md, err = clp.ProcessMetrics(context.Background(), newSums("requests", "erin"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().Len())
+}
+
+func TestSeriesExpire(t *testing.T) {
+	clp, now := newTestProcessor(nil)
+
+	_, err := clp.ProcessMetrics(context.Background(), newSums("requests", "alice", "bob"))
+	require.NoError(t, err)
+
+	*now = now.Add(3 * time.Minute)
+	md, err := clp.ProcessMetrics(context.Background(), newSums("requests", "alice", "carol"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users(sumDataPoints(md)))
+
+	// bob has not been received for longer than the TTL
+	*now = now.Add(3 * time.Minute)
+	md, err = clp.ProcessMetrics(context.Background(), newSums("requests", "carol"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol"}, users(sumDataPoints(md)))
+}
+
+func TestOverflowSum(t *testing.T) {
+	clp, _ := newTestProcessor(func(cfg *Config) {
+		cfg.Action = actionOverflow
+		cfg.OverflowKeepLabels = []string{"service"}
+	})
+
+	md, err := clp.ProcessMetrics(context.Background(), newSums("requests", "alice", "bob", "carol", "dave", "erin"))
+	require.NoError(t, err)
+
+	dps := sumDataPoints(md)
+	require.Equal(t, 3, dps.Len())
+	assert.Equal(t, []string{"alice", "bob", ""}, users(dps))
+
+	overflow := dps.At(2)
+	assert.Equal(t, 2, overflow.Attributes().Len())
+	service, _ := overflow.Attributes().Get("service")
+	assert.Equal(t, "api", service.StringVal())
+	marker, ok := overflow.Attributes().Get(defaultOverflowAttribute)
+	require.True(t, ok)
+	assert.True(t, marker.BoolVal())
+	assert.Equal(t, int64(3), overflow.IntVal())
+	assert.Equal(t, pdata.Timestamp(5), overflow.Timestamp())
+}
+
+func TestOverflowHistogram(t *testing.T) {
+	clp, _ := newTestProcessor(func(cfg *Config) {
+		cfg.Action = actionOverflow
+		cfg.MaxSeriesPerMetric = 1
+	})
+
+	md := pdata.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("latency")
+	metric.SetDataType(pdata.MetricDataTypeHistogram)
+	for i, bounds := range [][]float64{{1, 10}, {1, 10}, {1, 10}, {5}} {
+		dp := metric.Histogram().DataPoints().AppendEmpty()
+		dp.Attributes().InsertString("path", fmt.Sprintf("/users/%d", i))
+		dp.SetExplicitBounds(bounds)
+		counts := make([]uint64, len(bounds)+1)
+		counts[0] = 2
+		dp.SetBucketCounts(counts)
+		dp.SetCount(2)
+		dp.SetSum(1)
+	}
+
+	md, err := clp.ProcessMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	dps := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Histogram().DataPoints()
+	require.Equal(t, 3, dps.Len())
+	merged := dps.At(1)
+	assert.Equal(t, uint64(4), merged.Count())
+	assert.Equal(t, 2.0, merged.Sum())
+	assert.Equal(t, []uint64{4, 0, 0}, merged.BucketCounts())
+	_, ok := merged.Attributes().Get("path")
+	assert.False(t, ok)
+	// Different buckets cannot be merged
+	assert.Equal(t, []float64{5}, dps.At(2).ExplicitBounds())
+}
+
+func TestOverflowGaugeKeepsLatest(t *testing.T) {
+	dest := pdata.NewNumberDataPoint()
+	dest.SetDoubleVal(1)
+	dest.SetTimestamp(2)
+	src := pdata.NewNumberDataPoint()
+	src.SetDoubleVal(5)
+	src.SetTimestamp(1)
+
+	mergeGauge(dest, src)
+	assert.Equal(t, 1.0, dest.DoubleVal())
+	src.SetTimestamp(3)
+	mergeGauge(dest, src)
+	assert.Equal(t, 5.0, dest.DoubleVal())
+}
+
+func TestMergeSumTypes(t *testing.T) {
+	dest := pdata.NewNumberDataPoint()
+	dest.SetIntVal(2)
+	src := pdata.NewNumberDataPoint()
+	src.SetDoubleVal(0.5)
+
+	mergeSum(dest, src)
+	assert.Equal(t, pdata.MetricValueTypeDouble, dest.Type())
+	assert.Equal(t, 2.5, dest.DoubleVal())
+}
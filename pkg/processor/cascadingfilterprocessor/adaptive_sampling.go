@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+)
+
+const (
+	adaptiveSamplingWindow = 10 * time.Second
+	// adaptiveSamplingSmoothing is the weight of the newly computed probability, the rest is kept
+	// from the previous one so a single unusual window does not swing the output rate
+	adaptiveSamplingSmoothing = 0.5
+)
+
+// adaptiveSampler adjusts the probability of the probabilistic filter so the number of sampled traces
+// gets close to the target. Traces selected by the policies are always counted first and the probabilistic
+// filter is given only what remains of the target.
+// It's not safe for concurrent use, the processor calls it while holding decisionLock.
+type adaptiveSampler struct {
+	ctx             context.Context
+	logger          *zap.Logger
+	filter          *sampling.AdaptiveProbabilisticFilter
+	targetPerWindow float64
+	window          time.Duration
+	windowStart     time.Time
+
+	// policySampled counts traces sampled due to any of the policies
+	policySampled int64
+	// candidates counts traces not selected by any of the policies, i.e. the ones the probability applies to
+	candidates int64
+}
+
+func newAdaptiveSampler(ctx context.Context, logger *zap.Logger, targetTracesPerMinute uint64) *adaptiveSampler {
+	return &adaptiveSampler{
+		ctx:             ctx,
+		logger:          logger,
+		filter:          sampling.NewAdaptiveProbabilisticFilter(1.0),
+		targetPerWindow: float64(targetTracesPerMinute) * adaptiveSamplingWindow.Seconds() / time.Minute.Seconds(),
+		window:          adaptiveSamplingWindow,
+		windowStart:     time.Now(),
+	}
+}
+
+// observe accounts a trace with the final decision already taken
+func (as *adaptiveSampler) observe(policies []*Policy, trace *sampling.TraceData) {
+	selectedByPolicy := false
+	for i, policy := range policies {
+		if i < len(trace.Decisions) && !policy.probabilisticFilter && trace.Decisions[i] == sampling.Sampled {
+			selectedByPolicy = true
+			break
+		}
+	}
+
+	if !selectedByPolicy {
+		as.candidates++
+	} else if trace.FinalDecision == sampling.Sampled {
+		as.policySampled++
+	}
+}
+
+// adjust computes the new probability once the current window is over
+func (as *adaptiveSampler) adjust(now time.Time) {
+	if now.Sub(as.windowStart) < as.window {
+		return
+	}
+
+	// The target is scaled in case the window took longer, e.g. when there was no data for a while
+	target := as.targetPerWindow * now.Sub(as.windowStart).Seconds() / as.window.Seconds()
+	previous := as.filter.Probability()
+	probability := previous
+	if remaining := target - float64(as.policySampled); remaining <= 0 {
+		probability = 0
+	} else if as.candidates > 0 {
+		probability = remaining / float64(as.candidates)
+	}
+	if probability > 1 {
+		probability = 1
+	}
+	probability = adaptiveSamplingSmoothing*probability + (1-adaptiveSamplingSmoothing)*previous
+
+	as.filter.SetProbability(probability)
+	stats.Record(as.ctx, statAdaptiveSamplingProbability.M(as.filter.Probability()))
+	as.logger.Debug("Adaptive sampling probability adjusted",
+		zap.Float64("probability", probability),
+		zap.Float64("target", target),
+		zap.Int64("policySampled", as.policySampled),
+		zap.Int64("candidates", as.candidates),
+	)
+
+	as.windowStart = now
+	as.policySampled = 0
+	as.candidates = 0
+}
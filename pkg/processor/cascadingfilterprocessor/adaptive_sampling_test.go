@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+
+	cfconfig "github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+)
+
+// simulateWindow feeds the sampler with one window of traces, policySampled of which are selected by a policy
+func simulateWindow(as *adaptiveSampler, policies []*Policy, policySampled, other int, now time.Time) {
+	for i := 0; i < policySampled+other; i++ {
+		trace := &sampling.TraceData{Decisions: []sampling.Decision{sampling.NotSampled, sampling.NotSampled}}
+		if i < policySampled {
+			trace.Decisions[1] = sampling.Sampled
+			trace.FinalDecision = sampling.Sampled
+		}
+		as.observe(policies, trace)
+	}
+	as.adjust(now)
+}
+
+func TestAdaptiveSamplerConverges(t *testing.T) {
+	// 60 traces per minute is 10 traces per window
+	as := newAdaptiveSampler(context.Background(), zap.NewNop(), 60)
+	policies := []*Policy{{probabilisticFilter: true}, {}}
+
+	now := as.windowStart
+	for i := 0; i < 20; i++ {
+		now = now.Add(adaptiveSamplingWindow)
+		simulateWindow(as, policies, 4, 600, now)
+	}
+
+	// The policies already selected 4 traces, so 6 out of 600 remaining ones are needed
+	assert.InDelta(t, 0.01, as.filter.Probability(), 0.0001)
+}
+
+func TestAdaptiveSamplerPoliciesTakePrecedence(t *testing.T) {
+	as := newAdaptiveSampler(context.Background(), zap.NewNop(), 60)
+	policies := []*Policy{{probabilisticFilter: true}, {}}
+
+	now := as.windowStart
+	for i := 0; i < 20; i++ {
+		now = now.Add(adaptiveSamplingWindow)
+		simulateWindow(as, policies, 15, 600, now)
+	}
+
+	assert.InDelta(t, 0.0, as.filter.Probability(), 0.0001)
+}
+
+func TestAdaptiveSamplerWaitsForWindowEnd(t *testing.T) {
+	as := newAdaptiveSampler(context.Background(), zap.NewNop(), 60)
+	policies := []*Policy{{probabilisticFilter: true}, {}}
+
+	simulateWindow(as, policies, 0, 1000, as.windowStart.Add(adaptiveSamplingWindow/2))
+	assert.Equal(t, 1.0, as.filter.Probability())
+	assert.Equal(t, int64(1000), as.candidates)
+}
+
+func TestAdaptiveSamplingReplacesProbabilisticFilter(t *testing.T) {
+	ratio := float32(0.5)
+	cfg := cfconfig.Config{
+		DecisionWait:                2 * time.Second,
+		NumTraces:                   100,
+		SpansPerSecond:              1000,
+		ProbabilisticFilteringRatio: &ratio,
+		TargetTracesPerMinute:       600,
+		PolicyCfgs:                  []cfconfig.PolicyCfg{{Name: "everything", SpansPerSecond: -1}},
+	}
+	tsp, err := newCascadingFilterSpanProcessor(zap.NewNop(), consumertest.NewNop(), cfg)
+	require.NoError(t, err)
+
+	require.NotNil(t, tsp.adaptiveSampler)
+	require.Len(t, tsp.policies, 2)
+	assert.Equal(t, probabilisticFilterPolicyName, tsp.policies[0].Name)
+	assert.Same(t, tsp.adaptiveSampler.filter, tsp.policies[0].Evaluator)
+}
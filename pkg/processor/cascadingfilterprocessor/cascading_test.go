@@ -138,6 +138,29 @@ func TestProbabilisticFilter(t *testing.T) {
 	cfg.ProbabilisticFilteringRatio = &ratio
 }
 
+func TestGlobalServiceBudget(t *testing.T) {
+	budgetCfg := cfg
+	budgetCfg.SpansPerSecond = 100
+	budgetCfg.ServiceBudgetCfg = &cfconfig.ServiceBudgetCfg{
+		Default:  10,
+		Services: map[string]int64{"chatty": 50},
+	}
+	cascading, err := newCascadingFilterSpanProcessor(zap.NewNop(), nil, budgetCfg)
+	require.NoError(t, err)
+
+	newTrace := func(service string, numSpans int) *sampling.TraceData {
+		trace := createTrace(cascading, numSpans, 1000)
+		trace.ServiceName = service
+		return trace
+	}
+
+	require.Equal(t, sampling.Sampled, cascading.updateRate(1, newTrace("chatty", 50)))
+	require.Equal(t, sampling.NotSampled, cascading.updateRate(1, newTrace("chatty", 1)))
+	require.Equal(t, sampling.Sampled, cascading.updateRate(1, newTrace("quiet", 10)))
+	require.Equal(t, sampling.NotSampled, cascading.updateRate(1, newTrace("quiet", 1)))
+	require.Equal(t, sampling.Sampled, cascading.updateRate(1, newTrace("other", 10)))
+}
+
 //func TestSecondChanceReevaluation(t *testing.T) {
 //	cascading := createCascadingEvaluator()
 //
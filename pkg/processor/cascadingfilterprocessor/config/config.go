@@ -30,10 +30,21 @@ type PolicyCfg struct {
 	StringAttributeCfg *StringAttributeCfg `mapstructure:"string_attribute"`
 	// Configs for properties sampling policy evaluator.
 	PropertiesCfg PropertiesCfg `mapstructure:"properties"`
+	// Configs for OTTL condition sampling policy evaluator.
+	OTTLConditionCfg *OTTLConditionCfg `mapstructure:"ottl_condition"`
+	// Configs for selecting the slowest traces per operation.
+	LatencyPercentileCfg *LatencyPercentileCfg `mapstructure:"latency_percentile"`
+	// Configs for guaranteeing the minimum number of traces per attribute value.
+	StratifiedCfg *StratifiedCfg `mapstructure:"stratified"`
 	// SpansPerSecond specifies the rule budget that should never be exceeded for it
 	SpansPerSecond int64 `mapstructure:"spans_per_second"`
+	// ServiceBudgetCfg scopes the rule budget per service.name, in addition to SpansPerSecond
+	ServiceBudgetCfg *ServiceBudgetCfg `mapstructure:"service_spans_per_second"`
 	// InvertMatch specifies if the match should be inverted. Default: false
 	InvertMatch bool `mapstructure:"invert_match"`
+	// AlwaysKeep makes the policy bypass the rule and total budgets. The traces it selects are released
+	// as soon as they match, without waiting for DecisionWait. Default: false
+	AlwaysKeep bool `mapstructure:"always_keep"`
 }
 
 // PropertiesCfg holds the configurable settings to create a duration filter
@@ -64,6 +75,106 @@ type StringAttributeCfg struct {
 	Key string `mapstructure:"key"`
 	// Values is the set of attribute values that if any is equal to the actual attribute value to be considered a match.
 	Values []string `mapstructure:"values"`
+	// MatchType describes how Values are matched: "strict" (default) requires equality, while "contains"
+	// requires the attribute value to contain any of the Values.
+	MatchType string `mapstructure:"match_type"`
+}
+
+// OTTLConditionCfg holds the configurable settings to create an OTTL condition filter
+// sampling policy evaluator.
+type OTTLConditionCfg struct {
+	// SpanConditions are evaluated against each span. The trace is matched if any span meets any of them.
+	SpanConditions []string `mapstructure:"span"`
+	// SpanEventConditions are evaluated against each span event. The trace is matched if any event meets any of them.
+	SpanEventConditions []string `mapstructure:"spanevent"`
+}
+
+// LatencyPercentileCfg holds the configurable settings to create a filter selecting traces with any span
+// slower than the given percentile of the recent durations of its operation (service.name and span name).
+type LatencyPercentileCfg struct {
+	// Percentile (0-100, exclusive) of the operation durations, e.g. 99 selects the slowest 1% of spans.
+	Percentile float64 `mapstructure:"percentile"`
+	// MinSamples is the number of observed durations required before an operation is considered. Default: 100
+	MinSamples int `mapstructure:"min_samples"`
+	// Window is the half-life of the observed durations, so the percentile follows latency changes. Default: 5m
+	Window time.Duration `mapstructure:"window"`
+	// MaxOperations limits the number of tracked operations, others are never selected. Default: 1000
+	MaxOperations int `mapstructure:"max_operations"`
+}
+
+// StratifiedCfg holds the configurable settings to create a filter selecting traces until each distinct value
+// of the key attribute has the minimum number of traces sampled in the current minute.
+type StratifiedCfg struct {
+	// Key of the attribute (at resource or span level), e.g. tenant.id
+	Key string `mapstructure:"key"`
+	// MinTracesPerMinute is the number of traces selected per each distinct value every minute.
+	MinTracesPerMinute int64 `mapstructure:"min_traces_per_minute"`
+	// MaxValues limits the number of distinct values tracked in a minute, others are not selected. Default: 10000
+	MaxValues int `mapstructure:"max_values"`
+}
+
+// RootSpanBudgetCfg holds the traces per second budgets scoped per root span name.
+type RootSpanBudgetCfg struct {
+	// Default is the budget of each root span name not listed in RootSpans. When zero, such traces are not limited.
+	Default int64 `mapstructure:"default"`
+	// RootSpans maps the root span name to its budget.
+	RootSpans map[string]int64 `mapstructure:"root_spans"`
+}
+
+// ServiceBudgetCfg holds the spans per second budgets scoped per service.name.
+type ServiceBudgetCfg struct {
+	// Default is the budget of each service not listed in Services. When zero, such services are not limited.
+	Default int64 `mapstructure:"default"`
+	// Services maps service.name to its budget.
+	Services map[string]int64 `mapstructure:"services"`
+}
+
+// SpilloverCfg holds the configuration of spilling buffered traces to a storage extension.
+type SpilloverCfg struct {
+	// Storage is the ID of the storage extension (e.g. file_storage) used for the spilled traces
+	Storage string `mapstructure:"storage"`
+	// MaxTraces limits the number of traces kept in the storage at the same time. When reached,
+	// the traces are evicted as specified by MemoryLimitEviction. Default: NumTraces
+	MaxTraces uint64 `mapstructure:"max_traces"`
+}
+
+// DecisionExportCfg holds the configuration of exporting sampling decisions to downstream collectors.
+type DecisionExportCfg struct {
+	// Target is where the decision is stored: "attributes" (default) sets span attributes, while
+	// "trace_state" adds an entry to the span trace state.
+	Target string `mapstructure:"target"`
+}
+
+// SamplingHintsCfg holds the configuration of honoring the sampling hints set by instrumentation.
+type SamplingHintsCfg struct {
+	// Attributes are the span attributes carrying the hint, in the order of precedence. A positive number
+	// or true forces keeping the trace, while 0 or false forces dropping it. Default: [sampling.priority]
+	Attributes []string `mapstructure:"attributes"`
+	// OnConflict describes the decision when spans of a trace have contradicting values of the same attribute:
+	// "keep" (default) or "drop".
+	OnConflict string `mapstructure:"on_conflict"`
+}
+
+// PoliciesReloadCfg holds the configuration of reloading the policies at runtime.
+type PoliciesReloadCfg struct {
+	// File is the path of the YAML file with "policies" list, using the same format as in the processor config.
+	File string `mapstructure:"file"`
+	// CheckInterval is how often the file is checked for changes. Default: 10s
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+}
+
+// PoliciesFile is the content of the file the policies are reloaded from.
+type PoliciesFile struct {
+	PolicyCfgs []PolicyCfg `mapstructure:"policies"`
+}
+
+// ServiceStatsCfg holds the configurable settings for periodic per-service filtering statistics.
+type ServiceStatsCfg struct {
+	// Interval is the length of the window statistics are aggregated over. Default: 1m
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxServices limits the number of services tracked in a window, the remaining ones are reported
+	// together as "other". Default: 100
+	MaxServices int `mapstructure:"max_services"`
 }
 
 // Config holds the configuration for cascading-filter-based sampling.
@@ -72,18 +183,62 @@ type Config struct {
 	// DecisionWait is the desired wait time from the arrival of the first span of
 	// trace until the decision about sampling it or not is evaluated.
 	DecisionWait time.Duration `mapstructure:"decision_wait"`
+	// DecisionTickInterval is how often the decisions are made for the traces which reached DecisionWait.
+	// Default: 1s
+	DecisionTickInterval time.Duration `mapstructure:"decision_tick_interval"`
+	// DecisionBatchSize is the number of traces decided while holding the decision lock, so the decisions forced
+	// by the memory limit or always keep policies do not wait for the whole tick. Default: 1000
+	DecisionBatchSize int `mapstructure:"decision_batch_size"`
 	// SpansPerSecond specifies the total budget that should never be exceeded
 	SpansPerSecond int64 `mapstructure:"spans_per_second"`
+	// ServiceBudgetCfg scopes the total budget per service.name, in addition to SpansPerSecond
+	ServiceBudgetCfg *ServiceBudgetCfg `mapstructure:"service_spans_per_second"`
+	// RootSpanBudgetCfg limits the number of traces per second per root span name, in addition to SpansPerSecond
+	RootSpanBudgetCfg *RootSpanBudgetCfg `mapstructure:"root_span_traces_per_second"`
 	// ProbabilisticFilteringRatio describes which part (0.0-1.0) of the SpansPerSecond budget
 	// is exclusively allocated for probabilistically selected spans
 	ProbabilisticFilteringRatio *float32 `mapstructure:"probabilistic_filtering_ratio"`
+	// TargetTracesPerMinute enables adaptive probabilistic sampling. The probability is continuously adjusted
+	// so the total number of traces sampled per minute gets close to the target, after accounting for the traces
+	// selected by the policies, which always take precedence. When set, ProbabilisticFilteringRatio is ignored.
+	TargetTracesPerMinute uint64 `mapstructure:"target_traces_per_minute"`
 	// NumTraces is the number of traces kept on memory. Typically most of the data
 	// of a trace is released after a sampling decision is taken.
 	NumTraces uint64 `mapstructure:"num_traces"`
+	// MaxMemoryMiB sets the limit (in MiB) for the span data buffered while waiting for the decision.
+	// When exceeded, the oldest traces are evicted as specified by MemoryLimitEviction. Zero disables the limit.
+	MaxMemoryMiB uint64 `mapstructure:"max_memory_mib"`
+	// MemoryLimitEviction describes what happens to traces evicted due to MaxMemoryMiB limit: "decide_now" (default)
+	// evaluates the policies for them immediately, while "drop" discards them without evaluation.
+	MemoryLimitEviction string `mapstructure:"memory_limit_eviction"`
+	// SpilloverCfg enables moving the oldest undecided traces to a storage extension when MaxMemoryMiB is exceeded,
+	// so they can be still evaluated after the full DecisionWait
+	SpilloverCfg *SpilloverCfg `mapstructure:"spillover"`
 	// ExpectedNewTracesPerSec sets the expected number of new traces sending to the Cascading Filter processor
 	// per second. This helps with allocating data structures with closer to actual usage size.
 	ExpectedNewTracesPerSec uint64 `mapstructure:"expected_new_traces_per_sec"`
+	// DryRun forwards all traces, marking the ones which would have been dropped with an attribute instead,
+	// so the policies can be validated before enforcing them. The decisions and metrics are not affected.
+	DryRun bool `mapstructure:"dry_run"`
+	// SamplingHintsCfg enables honoring the sampling hints set on spans, which take precedence over PolicyCfgs
+	// and budgets.
+	SamplingHintsCfg *SamplingHintsCfg `mapstructure:"sampling_hints"`
+	// DecisionExportCfg enables attaching the sampling decision (policy name and decision id) to the spans
+	// of sampled traces, so a downstream cascading filter can honor it.
+	DecisionExportCfg *DecisionExportCfg `mapstructure:"decision_export"`
+	// HonorUpstreamDecisions keeps the traces carrying a decision exported by an upstream cascading filter,
+	// without evaluating the policies. Their spans are still accounted in SpansPerSecond.
+	HonorUpstreamDecisions bool `mapstructure:"honor_upstream_decisions"`
+	// RecordMatchingPolicies sets an attribute listing all policies which matched the trace on the spans
+	// of sampled traces, including the ones which did not have the budget left. Useful for finding
+	// overlapping or redundant policies.
+	RecordMatchingPolicies bool `mapstructure:"record_matching_policies"`
 	// PolicyCfgs sets the cascading-filter-based sampling policy which makes a sampling decision
 	// for a given trace when requested.
 	PolicyCfgs []PolicyCfg `mapstructure:"policies"`
+	// PoliciesReloadCfg enables loading the policies from a separate file, which is reloaded at runtime when changed.
+	// When set, PolicyCfgs are ignored.
+	PoliciesReloadCfg *PoliciesReloadCfg `mapstructure:"policies_reload"`
+	// ServiceStatsCfg enables periodic output of the number of traces seen, sampled and dropped per service.name
+	ServiceStatsCfg *ServiceStatsCfg `mapstructure:"service_stats"`
 }
@@ -48,12 +48,41 @@ func TestLoadConfig(t *testing.T) {
 	ps := config.NewProcessorSettings(id)
 	assert.Equal(t, cfg.Processors[id],
 		&cfconfig.Config{
-			ProcessorSettings:           &ps,
-			DecisionWait:                10 * time.Second,
-			NumTraces:                   100,
-			ExpectedNewTracesPerSec:     10,
-			SpansPerSecond:              1000,
+			ProcessorSettings:    &ps,
+			DecisionWait:         10 * time.Second,
+			DecisionTickInterval: 500 * time.Millisecond,
+			DecisionBatchSize:    5000,
+			NumTraces:            100,
+			MaxMemoryMiB:         512,
+			MemoryLimitEviction:  "drop",
+			SpilloverCfg: &cfconfig.SpilloverCfg{
+				Storage:   "file_storage/cascading",
+				MaxTraces: 5000,
+			},
+			ExpectedNewTracesPerSec: 10,
+			DryRun:                  true,
+			SpansPerSecond:          1000,
+			ServiceBudgetCfg: &cfconfig.ServiceBudgetCfg{
+				Default:  100,
+				Services: map[string]int64{"checkout": 300},
+			},
+			RootSpanBudgetCfg: &cfconfig.RootSpanBudgetCfg{
+				Default:   50,
+				RootSpans: map[string]int64{"GET /search": 10},
+			},
 			ProbabilisticFilteringRatio: &probFilteringRatio,
+			TargetTracesPerMinute:       600,
+			SamplingHintsCfg: &cfconfig.SamplingHintsCfg{
+				Attributes: []string{"sumo.keep", "sampling.priority"},
+				OnConflict: "drop",
+			},
+			DecisionExportCfg:      &cfconfig.DecisionExportCfg{Target: "trace_state"},
+			HonorUpstreamDecisions: true,
+			RecordMatchingPolicies: true,
+			ServiceStatsCfg: &cfconfig.ServiceStatsCfg{
+				Interval:    5 * time.Minute,
+				MaxServices: 20,
+			},
 			PolicyCfgs: []cfconfig.PolicyCfg{
 				{
 					Name: "test-policy-1",
@@ -64,11 +93,12 @@ func TestLoadConfig(t *testing.T) {
 				},
 				{
 					Name:               "test-policy-3",
-					StringAttributeCfg: &cfconfig.StringAttributeCfg{Key: "key2", Values: []string{"value1", "value2"}},
+					StringAttributeCfg: &cfconfig.StringAttributeCfg{Key: "key2", Values: []string{"value1", "value2"}, MatchType: "contains"},
 				},
 				{
-					Name:           "test-policy-4",
-					SpansPerSecond: 35,
+					Name:             "test-policy-4",
+					SpansPerSecond:   35,
+					ServiceBudgetCfg: &cfconfig.ServiceBudgetCfg{Default: 10},
 				},
 				{
 					Name:           "test-policy-5",
@@ -91,6 +121,40 @@ func TestLoadConfig(t *testing.T) {
 						MinNumberOfSpans: &minSpansValue,
 					},
 				},
+				{
+					Name:           "test-policy-8",
+					SpansPerSecond: 20,
+					OTTLConditionCfg: &cfconfig.OTTLConditionCfg{
+						SpanConditions: []string{
+							`attributes["http.status_code"] >= 500`,
+							`resource.attributes["service.name"] == "checkout" and kind == SPAN_KIND_SERVER`,
+						},
+						SpanEventConditions: []string{`name == "exception"`},
+					},
+				},
+				{
+					Name:           "test-policy-9",
+					SpansPerSecond: 10,
+					LatencyPercentileCfg: &cfconfig.LatencyPercentileCfg{
+						Percentile: 99,
+						MinSamples: 500,
+						Window:     10 * time.Minute,
+					},
+				},
+				{
+					Name:           "test-policy-10",
+					SpansPerSecond: 100,
+					StratifiedCfg: &cfconfig.StratifiedCfg{
+						Key:                "tenant.id",
+						MinTracesPerMinute: 5,
+					},
+				},
+				{
+					Name:       "test-policy-11",
+					AlwaysKeep: true,
+					NumericAttributeCfg: &cfconfig.NumericAttributeCfg{
+						Key: "http.status_code", MinValue: 500, MaxValue: 599},
+				},
 				{
 					Name:           "everything_else",
 					SpansPerSecond: -1,
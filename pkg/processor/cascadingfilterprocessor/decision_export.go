@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+)
+
+const (
+	// AttributeSamplingPolicy is the name of the policy which sampled the trace
+	AttributeSamplingPolicy = "sampling.cascading_filter.policy"
+	// AttributeSamplingDecisionID uniquely identifies the decision, so all spans of the trace can be matched with it
+	AttributeSamplingDecisionID = "sampling.cascading_filter.decision_id"
+
+	// traceStateDecisionKey is the trace state entry used when the decision is exported to the trace state
+	traceStateDecisionKey = "cascading_filter"
+
+	decisionExportAttributes = "attributes"
+	decisionExportTraceState = "trace_state"
+
+	// samplingHintPolicyName and upstreamPolicyName describe traces sampled without evaluating the policies
+	samplingHintPolicyName = "sampling_hint"
+	upstreamPolicyName     = "upstream"
+)
+
+// decisionExporter attaches the sampling decision to the spans of sampled traces, so a downstream
+// cascading filter can honor it. A nil decisionExporter does not export anything.
+type decisionExporter struct {
+	toTraceState bool
+}
+
+func newDecisionExporter(cfg *config.DecisionExportCfg) (*decisionExporter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	switch cfg.Target {
+	case "", decisionExportAttributes:
+		return &decisionExporter{}, nil
+	case decisionExportTraceState:
+		return &decisionExporter{toTraceState: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown decision_export target %q, must be one of: %s, %s",
+			cfg.Target, decisionExportAttributes, decisionExportTraceState)
+	}
+}
+
+// export sets the policy name and a new decision id on all spans
+func (de *decisionExporter) export(traces pdata.Traces, policyName string) {
+	if de == nil {
+		return
+	}
+
+	decisionID := uuid.New().String()
+	traceStateValue := traceStateDecisionValue(policyName, decisionID)
+
+	rs := traces.ResourceSpans()
+	for i := 0; i < rs.Len(); i++ {
+		ils := rs.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ils.Len(); j++ {
+			spans := ils.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				if de.toTraceState {
+					span.SetTraceState(pdata.TraceState(upsertTraceStateEntry(string(span.TraceState()), traceStateValue)))
+				} else {
+					span.Attributes().UpsertString(AttributeSamplingPolicy, policyName)
+					span.Attributes().UpsertString(AttributeSamplingDecisionID, decisionID)
+				}
+			}
+		}
+	}
+}
+
+// traceStateDecisionValue formats the trace state value, replacing the characters not allowed by W3C Trace Context
+func traceStateDecisionValue(policyName string, decisionID string) string {
+	sanitizedName := strings.Map(func(r rune) rune {
+		if r < 0x21 || r > 0x7e || r == ',' || r == '=' || r == ';' {
+			return '_'
+		}
+		return r
+	}, policyName)
+	return fmt.Sprintf("id:%s;policy:%s", decisionID, sanitizedName)
+}
+
+// upsertTraceStateEntry puts the decision entry first in the trace state, removing the previous one if present
+func upsertTraceStateEntry(traceState string, value string) string {
+	entries := []string{traceStateDecisionKey + "=" + value}
+	for _, entry := range strings.Split(traceState, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, traceStateDecisionKey+"=") {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
+
+// hasUpstreamDecision returns true if any span of the trace carries a decision exported by an upstream
+// cascading filter, either as an attribute or in the trace state
+func hasUpstreamDecision(trace *sampling.TraceData) bool {
+	trace.Lock()
+	batches := trace.ReceivedBatches
+	trace.Unlock()
+
+	for _, batch := range batches {
+		rs := batch.ResourceSpans()
+		for i := 0; i < rs.Len(); i++ {
+			ils := rs.At(i).InstrumentationLibrarySpans()
+			for j := 0; j < ils.Len(); j++ {
+				spans := ils.At(j).Spans()
+				for k := 0; k < spans.Len(); k++ {
+					span := spans.At(k)
+					if _, ok := span.Attributes().Get(AttributeSamplingDecisionID); ok {
+						return true
+					}
+					if hasTraceStateEntry(string(span.TraceState())) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func hasTraceStateEntry(traceState string) bool {
+	for _, entry := range strings.Split(traceState, ",") {
+		if strings.HasPrefix(strings.TrimSpace(entry), traceStateDecisionKey+"=") {
+			return true
+		}
+	}
+	return false
+}
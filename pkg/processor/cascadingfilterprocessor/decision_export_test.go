@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+)
+
+func newDecisionExportTestProcessor(t *testing.T, msp *consumertest.TracesSink, cfg *config.DecisionExportCfg, decision sampling.Decision) *cascadingFilterSpanProcessor {
+	exporter, err := newDecisionExporter(cfg)
+	require.NoError(t, err)
+	return &cascadingFilterSpanProcessor{
+		ctx:               context.Background(),
+		nextConsumer:      msp,
+		maxNumTraces:      10,
+		logger:            zap.NewNop(),
+		decisionBatcher:   newSyncIDBatcher(1),
+		policies:          []*Policy{{Name: "errors", Evaluator: &mockPolicyEvaluator{NextDecision: decision}, ctx: context.TODO()}},
+		deleteChan:        make(chan traceKey, 10),
+		policyTicker:      &manualTTicker{},
+		maxSpansPerSecond: 10000,
+		decisionExport:    exporter,
+		honorUpstream:     true,
+	}
+}
+
+func decideTraces(t *testing.T, tsp *cascadingFilterSpanProcessor, traces pdata.Traces) {
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), traces))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+}
+
+func TestDecisionExportAttributes(t *testing.T) {
+	upstream := new(consumertest.TracesSink)
+	decideTraces(t, newDecisionExportTestProcessor(t, upstream, &config.DecisionExportCfg{}, sampling.Sampled), simpleTraces())
+	require.Equal(t, 1, upstream.SpanCount())
+
+	attrs := upstream.AllTraces()[0].ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Attributes()
+	policy, ok := attrs.Get(AttributeSamplingPolicy)
+	require.True(t, ok)
+	assert.Equal(t, "errors", policy.StringVal())
+	decisionID, ok := attrs.Get(AttributeSamplingDecisionID)
+	require.True(t, ok)
+	assert.NotEmpty(t, decisionID.StringVal())
+
+	// The downstream processor keeps the trace even though its policy would not, and passes on the decision
+	downstream := new(consumertest.TracesSink)
+	decideTraces(t, newDecisionExportTestProcessor(t, downstream, &config.DecisionExportCfg{}, sampling.NotSampled), upstream.AllTraces()[0])
+	require.Equal(t, 1, downstream.SpanCount())
+	attrs = downstream.AllTraces()[0].ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Attributes()
+	downstreamID, ok := attrs.Get(AttributeSamplingDecisionID)
+	require.True(t, ok)
+	assert.Equal(t, decisionID.StringVal(), downstreamID.StringVal())
+}
+
+func TestDecisionExportTraceState(t *testing.T) {
+	upstream := new(consumertest.TracesSink)
+	traces := simpleTraces()
+	traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).SetTraceState("vendor=value")
+	decideTraces(t, newDecisionExportTestProcessor(t, upstream, &config.DecisionExportCfg{Target: "trace_state"}, sampling.Sampled), traces)
+	require.Equal(t, 1, upstream.SpanCount())
+
+	span := upstream.AllTraces()[0].ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0)
+	assert.Regexp(t, `^cascading_filter=id:[0-9a-f-]+;policy:errors,vendor=value$`, string(span.TraceState()))
+	_, ok := span.Attributes().Get(AttributeSamplingDecisionID)
+	assert.False(t, ok)
+
+	downstream := new(consumertest.TracesSink)
+	decideTraces(t, newDecisionExportTestProcessor(t, downstream, nil, sampling.NotSampled), upstream.AllTraces()[0])
+	assert.Equal(t, 1, downstream.SpanCount())
+}
+
+func TestUpstreamDecisionNotHonoredByDefault(t *testing.T) {
+	traces := simpleTraces()
+	traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Attributes().InsertString(AttributeSamplingDecisionID, "id")
+
+	msp := new(consumertest.TracesSink)
+	tsp := newDecisionExportTestProcessor(t, msp, nil, sampling.NotSampled)
+	tsp.honorUpstream = false
+	decideTraces(t, tsp, traces)
+	assert.Equal(t, 0, msp.SpanCount())
+}
+
+func TestUpsertTraceStateEntry(t *testing.T) {
+	assert.Equal(t, "cascading_filter=v", upsertTraceStateEntry("", "v"))
+	assert.Equal(t, "cascading_filter=v,a=1,b=2", upsertTraceStateEntry("a=1, cascading_filter=old,b=2", "v"))
+	assert.Equal(t, "id:x;policy:my_policy_", traceStateDecisionValue("my policy,", "x"))
+}
+
+func TestDecisionExportInvalidTarget(t *testing.T) {
+	_, err := newDecisionExporter(&config.DecisionExportCfg{Target: "baggage"})
+	assert.Error(t, err)
+}
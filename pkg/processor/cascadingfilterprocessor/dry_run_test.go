@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+)
+
+func newDryRunTestProcessor(msp *consumertest.TracesSink, decision sampling.Decision) *cascadingFilterSpanProcessor {
+	return &cascadingFilterSpanProcessor{
+		ctx:               context.Background(),
+		nextConsumer:      msp,
+		maxNumTraces:      10,
+		logger:            zap.NewNop(),
+		decisionBatcher:   newSyncIDBatcher(1),
+		policies:          []*Policy{{Name: "mock-policy", Evaluator: &mockPolicyEvaluator{NextDecision: decision}, ctx: context.TODO()}},
+		deleteChan:        make(chan traceKey, 10),
+		policyTicker:      &manualTTicker{},
+		maxSpansPerSecond: 10000,
+		dryRun:            true,
+	}
+}
+
+func wouldHaveBeenDropped(t *testing.T, traces pdata.Traces) bool {
+	attrs := traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Attributes()
+	value, ok := attrs.Get(AttributeWouldHaveBeenDropped)
+	require.True(t, ok)
+	_, ok = attrs.Get(AttributeSamplingRule)
+	assert.False(t, ok)
+	return value.BoolVal()
+}
+
+func TestDryRunForwardsDroppedTraces(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newDryRunTestProcessor(msp, sampling.NotSampled)
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	require.Equal(t, 1, msp.SpanCount())
+	assert.True(t, wouldHaveBeenDropped(t, msp.AllTraces()[0]))
+
+	// Late spans are forwarded as well
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+	require.Equal(t, 2, msp.SpanCount())
+	assert.True(t, wouldHaveBeenDropped(t, msp.AllTraces()[1]))
+}
+
+func TestDryRunForwardsSampledTraces(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newDryRunTestProcessor(msp, sampling.Sampled)
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+
+	require.Equal(t, 2, msp.SpanCount())
+	assert.False(t, wouldHaveBeenDropped(t, msp.AllTraces()[0]))
+	assert.False(t, wouldHaveBeenDropped(t, msp.AllTraces()[1]))
+}
+
+func TestDryRunForwardsTracesDroppedBeforeDecision(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newDryRunTestProcessor(msp, sampling.Sampled)
+	tsp.deleteChan = make(chan traceKey, 1)
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(pdata.NewTraceID([16]byte{1}))))
+	// No room for both traces, so the first one is dropped
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(pdata.NewTraceID([16]byte{2}))))
+
+	require.Equal(t, 1, msp.SpanCount())
+	assert.True(t, wouldHaveBeenDropped(t, msp.AllTraces()[0]))
+}
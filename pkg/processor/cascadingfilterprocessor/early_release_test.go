@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+)
+
+func newAlwaysKeepTestProcessor(t *testing.T, msp *consumertest.TracesSink) *cascadingFilterSpanProcessor {
+	policies, err := newRulePolicies(context.Background(), zap.NewNop(), []config.PolicyCfg{
+		{
+			Name:       "errors",
+			AlwaysKeep: true,
+			NumericAttributeCfg: &config.NumericAttributeCfg{
+				Key: "http.status_code", MinValue: 500, MaxValue: 599,
+			},
+		},
+		{
+			Name:           "everything",
+			SpansPerSecond: 1000,
+		},
+	})
+	require.NoError(t, err)
+
+	return &cascadingFilterSpanProcessor{
+		ctx:             context.Background(),
+		nextConsumer:    msp,
+		maxNumTraces:    10,
+		logger:          zap.NewNop(),
+		decisionBatcher: newSyncIDBatcher(1),
+		policies:        policies,
+		deleteChan:      make(chan traceKey, 10),
+		policyTicker:    &manualTTicker{},
+		// No budget left for traces selected by the regular policies
+		maxSpansPerSecond: 0,
+	}
+}
+
+func tracesWithStatusCode(traceID pdata.TraceID, statusCode int64) pdata.Traces {
+	traces := simpleTracesWithID(traceID)
+	traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Attributes().InsertInt("http.status_code", statusCode)
+	return traces
+}
+
+func TestAlwaysKeepReleasesEarly(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newAlwaysKeepTestProcessor(t, msp)
+	errorID := pdata.NewTraceID([16]byte{1})
+	okID := pdata.NewTraceID([16]byte{2})
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), tracesWithStatusCode(errorID, 200)))
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), tracesWithStatusCode(okID, 200)))
+	assert.Equal(t, 0, msp.SpanCount())
+
+	// The trace is sent as soon as the matching span arrives, along with the spans received earlier
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), tracesWithStatusCode(errorID, 503)))
+	assert.Equal(t, 2, msp.SpanCount())
+
+	d, ok := tsp.idToTrace.Load(traceKey(errorID.Bytes()))
+	require.True(t, ok)
+	trace := d.(*sampling.TraceData)
+	assert.True(t, trace.ReleasedEarly)
+	assert.Equal(t, "errors", trace.DecidingPolicy)
+	assert.Equal(t, []sampling.Decision{sampling.Sampled, sampling.NotSampled}, trace.Decisions)
+
+	// Late spans are sent immediately
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), tracesWithStatusCode(errorID, 200)))
+	assert.Equal(t, 3, msp.SpanCount())
+
+	// The tick does not send the released trace again, while the other one exceeds the total budget
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	assert.Equal(t, 3, msp.SpanCount())
+}
+
+func TestAlwaysKeepBypassesBudgetOnTick(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newAlwaysKeepTestProcessor(t, msp)
+
+	// Spilled traces are left for the regular decision
+	traces := tracesWithStatusCode(pdata.NewTraceID([16]byte{1}), 500)
+	d, _ := tsp.idToTrace.LoadOrStore(traceKey(pdata.NewTraceID([16]byte{1}).Bytes()), &sampling.TraceData{
+		Decisions:     []sampling.Decision{sampling.Pending, sampling.Pending},
+		SpilledChunks: 1,
+	})
+	tsp.decisionBatcher.AddToCurrentBatch(pdata.NewTraceID([16]byte{1}))
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), traces))
+	assert.Equal(t, 0, msp.SpanCount())
+	d.(*sampling.TraceData).SpilledChunks = 0
+
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	assert.Equal(t, 1, msp.SpanCount())
+	assert.Equal(t, int64(1), tsp.spansInCurrentSecond)
+}
+
+func TestBlockedConsumerOnEarlyReleaseDoesNotBlockIngestion(t *testing.T) {
+	consumer := newBlockingConsumer()
+	tsp := newAlwaysKeepTestProcessor(t, &consumer.TracesSink)
+	tsp.nextConsumer = consumer
+
+	released := make(chan struct{})
+	go func() {
+		defer close(released)
+		assert.NoError(t, tsp.ConsumeTraces(context.Background(), tracesWithStatusCode(pdata.NewTraceID([16]byte{1}), 500)))
+	}()
+	<-consumer.entered
+
+	// The trace is marked as released under decisionLock, the send happens after it's released
+	requireCompletes(t, func() {
+		assert.NoError(t, tsp.ConsumeTraces(context.Background(), tracesWithStatusCode(pdata.NewTraceID([16]byte{2}), 503)))
+	})
+	assert.Equal(t, 1, consumer.SpanCount())
+
+	close(consumer.release)
+	<-released
+	assert.Equal(t, 2, consumer.SpanCount())
+}
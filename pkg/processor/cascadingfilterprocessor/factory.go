@@ -26,6 +26,7 @@ import (
 	"go.opentelemetry.io/collector/processor/processorhelper"
 
 	cfconfig "github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain"
 )
 
 const (
@@ -47,31 +48,45 @@ func init() {
 
 // NewFactory returns a new factory for the Cascading Filter processor.
 func NewFactory() component.ProcessorFactory {
+	return NewFactoryWithDrain(drain.NewCoordinator(drain.DefaultTimeout))
+}
+
+// NewFactoryWithDrain returns a new factory for the Cascading Filter processor, whose processors make
+// the pending decisions on shutdown until the deadline of the coordinator, which the traces left behind
+// are reported to.
+func NewFactoryWithDrain(coordinator *drain.Coordinator) component.ProcessorFactory {
+	f := &factory{drain: coordinator}
 	return processorhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
-		processorhelper.WithTraces(createTraceProcessor))
+		processorhelper.WithTraces(f.createTraceProcessor))
+}
+
+type factory struct {
+	drain *drain.Coordinator
 }
 
 func createDefaultConfig() config.Processor {
 	id := config.NewID("cascading_filter")
 	ps := config.NewProcessorSettings(id)
+	// Each config gets its own copy, so unmarshalling one of them doesn't change the default
+	probabilisticFilteringRatio := defaultProbabilisticFilteringRatio
 
 	return &cfconfig.Config{
 		ProcessorSettings:           &ps,
 		DecisionWait:                30 * time.Second,
 		NumTraces:                   50000,
 		SpansPerSecond:              1500,
-		ProbabilisticFilteringRatio: &defaultProbabilisticFilteringRatio,
+		ProbabilisticFilteringRatio: &probabilisticFilteringRatio,
 	}
 }
 
-func createTraceProcessor(
+func (f *factory) createTraceProcessor(
 	_ context.Context,
 	params component.ProcessorCreateSettings,
 	cfg config.Processor,
 	nextConsumer consumer.Traces,
 ) (component.TracesProcessor, error) {
 	tCfg := cfg.(*cfconfig.Config)
-	return newTraceProcessor(params.Logger, nextConsumer, *tCfg)
+	return newTraceProcessor(params.Logger, nextConsumer, *tCfg, f.drain)
 }
@@ -4,11 +4,18 @@ go 1.14
 
 require (
 	github.com/google/uuid v1.3.0
+	github.com/open-telemetry/opentelemetry-collector-contrib/internal/ottl v0.33.0
+	github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain v0.33.0
 	github.com/stretchr/testify v1.7.0
 	go.opencensus.io v0.23.0
 	go.opentelemetry.io/collector v0.33.0
 	go.opentelemetry.io/collector/model v0.33.0
+	go.uber.org/multierr v1.6.0
 	go.uber.org/zap v1.19.0
 )
 
 replace go.opentelemetry.io/collector => github.com/SumoLogic/opentelemetry-collector v0.33.0-sumo-1
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/ottl => ./../../internal/ottl
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain => ./../../tools/drain
@@ -29,10 +29,15 @@ var (
 	statusSecondChance         = "SecondChance"
 	statusSecondChanceSampled  = "SecondChanceSampled"
 	statusSecondChanceExceeded = "SecondChanceRateExceeded"
+	statusHintKept             = "HintKept"
+	statusHintDropped          = "HintDropped"
+	statusUpstreamKept         = "UpstreamKept"
+	statusReleasedEarly        = "ReleasedEarly"
 
 	tagPolicyKey, _                  = tag.NewKey("policy")
 	tagCascadingFilterDecisionKey, _ = tag.NewKey("cascading_filter_decision")
 	tagPolicyDecisionKey, _          = tag.NewKey("policy_decision")
+	tagServiceKey, _                 = tag.NewKey("service")
 
 	statDecisionLatencyMicroSec  = stats.Int64("policy_decision_latency", "Latency (in microseconds) of a given filtering policy", "µs")
 	statOverallDecisionLatencyus = stats.Int64("cascading_filtering_batch_processing_latency", "Latency (in microseconds) of each run of the cascading filter timer", "µs")
@@ -48,6 +53,14 @@ var (
 	statDroppedTooEarlyCount    = stats.Int64("casdading_trace_dropped_too_early", "Count of traces that needed to be dropped the configured wait time", stats.UnitDimensionless)
 	statNewTraceIDReceivedCount = stats.Int64("cascading_new_trace_id_received", "Counts the arrival of new traces", stats.UnitDimensionless)
 	statTracesOnMemoryGauge     = stats.Int64("cascading_traces_on_memory", "Tracks the number of traces current on memory", stats.UnitDimensionless)
+
+	statTracesOnMemoryBytesGauge        = stats.Int64("cascading_traces_on_memory_bytes", "Tracks the size of span data buffered on memory", stats.UnitBytes)
+	statServiceTracesCount              = stats.Int64("cascading_service_traces", "Count of traces with final decision per service", stats.UnitDimensionless)
+	statTracesEvictedOnMemoryLimitCount = stats.Int64("cascading_traces_evicted_on_memory_limit", "Count of traces evicted early due to the memory limit", stats.UnitDimensionless)
+	statTracesSpilledCount              = stats.Int64("cascading_traces_spilled", "Count of traces spilled to the storage due to the memory limit", stats.UnitDimensionless)
+	statTracesOnStorageGauge            = stats.Int64("cascading_traces_on_storage", "Tracks the number of traces currently spilled to the storage", stats.UnitDimensionless)
+	statSpilledChunksDroppedCount       = stats.Int64("cascading_spilled_chunks_dropped", "Count of spilled chunks which could not be restored from the storage", stats.UnitDimensionless)
+	statAdaptiveSamplingProbability     = stats.Float64("cascading_adaptive_sampling_probability", "Current probability used by the adaptive probabilistic filter", stats.UnitDimensionless)
 )
 
 // CascadingFilterMetricViews return the metrics views according to given telemetry level.
@@ -129,6 +142,49 @@ func CascadingFilterMetricViews(level configtelemetry.Level) []*view.View {
 		Description: statTracesOnMemoryGauge.Description(),
 		Aggregation: view.LastValue(),
 	}
+	trackTracesOnMemoryBytesView := &view.View{
+		Name:        statTracesOnMemoryBytesGauge.Name(),
+		Measure:     statTracesOnMemoryBytesGauge,
+		Description: statTracesOnMemoryBytesGauge.Description(),
+		Aggregation: view.LastValue(),
+	}
+	countTracesEvictedOnMemoryLimitView := &view.View{
+		Name:        statTracesEvictedOnMemoryLimitCount.Name(),
+		Measure:     statTracesEvictedOnMemoryLimitCount,
+		Description: statTracesEvictedOnMemoryLimitCount.Description(),
+		Aggregation: view.Sum(),
+	}
+	countServiceTracesView := &view.View{
+		Name:        statServiceTracesCount.Name(),
+		Measure:     statServiceTracesCount,
+		Description: statServiceTracesCount.Description(),
+		TagKeys:     []tag.Key{tagServiceKey, tagCascadingFilterDecisionKey},
+		Aggregation: view.Sum(),
+	}
+	countTracesSpilledView := &view.View{
+		Name:        statTracesSpilledCount.Name(),
+		Measure:     statTracesSpilledCount,
+		Description: statTracesSpilledCount.Description(),
+		Aggregation: view.Sum(),
+	}
+	trackTracesOnStorageView := &view.View{
+		Name:        statTracesOnStorageGauge.Name(),
+		Measure:     statTracesOnStorageGauge,
+		Description: statTracesOnStorageGauge.Description(),
+		Aggregation: view.LastValue(),
+	}
+	countSpilledChunksDroppedView := &view.View{
+		Name:        statSpilledChunksDroppedCount.Name(),
+		Measure:     statSpilledChunksDroppedCount,
+		Description: statSpilledChunksDroppedCount.Description(),
+		Aggregation: view.Sum(),
+	}
+	trackAdaptiveSamplingProbabilityView := &view.View{
+		Name:        statAdaptiveSamplingProbability.Name(),
+		Measure:     statAdaptiveSamplingProbability,
+		Description: statAdaptiveSamplingProbability.Description(),
+		Aggregation: view.LastValue(),
+	}
 
 	legacyViews := []*view.View{
 		overallDecisionLatencyView,
@@ -143,6 +199,13 @@ func CascadingFilterMetricViews(level configtelemetry.Level) []*view.View {
 		countTraceDroppedTooEarlyView,
 		countTraceIDArrivalView,
 		trackTracesOnMemorylView,
+		trackTracesOnMemoryBytesView,
+		countTracesEvictedOnMemoryLimitView,
+		countServiceTracesView,
+		countTracesSpilledView,
+		trackTracesOnStorageView,
+		countSpilledChunksDroppedView,
+		trackAdaptiveSamplingProbabilityView,
 	}
 
 	// return obsreport.ProcessorMetricViews(typeStr, legacyViews)
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configparser"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+const defaultPoliciesCheckInterval = 10 * time.Second
+
+// policiesReloader watches the policies file and passes its content to onReload when it changes
+type policiesReloader struct {
+	sync.Mutex
+	logger        *zap.Logger
+	file          string
+	checkInterval time.Duration
+	onReload      func([]config.PolicyCfg) error
+
+	modTime time.Time
+	size    int64
+}
+
+func newPoliciesReloader(logger *zap.Logger, cfg *config.PoliciesReloadCfg) *policiesReloader {
+	checkInterval := cfg.CheckInterval
+	if checkInterval <= 0 {
+		checkInterval = defaultPoliciesCheckInterval
+	}
+	return &policiesReloader{
+		logger:        logger,
+		file:          cfg.File,
+		checkInterval: checkInterval,
+	}
+}
+
+// load reads and parses the policies file, remembering its state for detecting further changes
+func (pr *policiesReloader) load() ([]config.PolicyCfg, error) {
+	info, err := os.Stat(pr.file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policies file: %w", err)
+	}
+
+	parser, err := configparser.NewParserFromFile(pr.file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policies file: %w", err)
+	}
+	var policiesFile config.PoliciesFile
+	if err := parser.UnmarshalExact(&policiesFile); err != nil {
+		return nil, fmt.Errorf("cannot parse policies file %q: %w", pr.file, err)
+	}
+
+	pr.modTime = info.ModTime()
+	pr.size = info.Size()
+	return policiesFile.PolicyCfgs, nil
+}
+
+// check reloads the policies if the file was modified. When the new policies are invalid, the current ones are kept.
+func (pr *policiesReloader) check() {
+	pr.Lock()
+	defer pr.Unlock()
+
+	info, err := os.Stat(pr.file)
+	if err != nil {
+		pr.logger.Error("Cannot check policies file", zap.String("file", pr.file), zap.Error(err))
+		return
+	}
+	if info.ModTime().Equal(pr.modTime) && info.Size() == pr.size {
+		return
+	}
+
+	policyCfgs, err := pr.load()
+	if err == nil {
+		err = pr.onReload(policyCfgs)
+	}
+	if err != nil {
+		pr.logger.Error("Reloading policies failed, keeping the current ones", zap.String("file", pr.file), zap.Error(err))
+		// Do not retry until the file changes again
+		pr.modTime = info.ModTime()
+		pr.size = info.Size()
+		return
+	}
+
+	pr.logger.Info("Policies reloaded", zap.String("file", pr.file), zap.Int("policies", len(policyCfgs)))
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+func writePoliciesFile(t *testing.T, file string, content string) {
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	// Make sure the change is noticed regardless of the file system timestamp resolution
+	modTime := time.Now().Add(time.Duration(len(content)) * time.Second)
+	require.NoError(t, os.Chtimes(file, modTime, modTime))
+}
+
+func policyNames(policies []*Policy) []string {
+	names := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		names = append(names, policy.Name)
+	}
+	return names
+}
+
+func TestPoliciesReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policies.yaml")
+	writePoliciesFile(t, file, `
+policies:
+  - name: errors
+    numeric_attribute: {key: http.status_code, min_value: 500, max_value: 599}
+`)
+
+	ratio := float32(0.1)
+	msp := new(consumertest.TracesSink)
+	tsp, err := newCascadingFilterSpanProcessor(zap.NewNop(), msp, config.Config{
+		DecisionWait:                2 * time.Second,
+		NumTraces:                   100,
+		SpansPerSecond:              1000,
+		ProbabilisticFilteringRatio: &ratio,
+		PolicyCfgs:                  []config.PolicyCfg{{Name: "ignored"}},
+		PoliciesReloadCfg:           &config.PoliciesReloadCfg{File: file},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{probabilisticFilterPolicyName, "errors"}, policyNames(tsp.getPolicies()))
+	assert.Equal(t, defaultPoliciesCheckInterval, tsp.policiesReloader.checkInterval)
+
+	// A trace received before the reload is evaluated with the new policies
+	tsp.decisionBatcher = newSyncIDBatcher(1)
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+
+	writePoliciesFile(t, file, `
+policies:
+  - name: errors
+    numeric_attribute: {key: http.status_code, min_value: 500, max_value: 599}
+  - name: everything
+    spans_per_second: 100
+`)
+	tsp.policiesReloader.check()
+	assert.Equal(t, []string{probabilisticFilterPolicyName, "errors", "everything"}, policyNames(tsp.getPolicies()))
+
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	assert.Equal(t, 1, msp.SpanCount())
+
+	// Invalid policies are not applied
+	writePoliciesFile(t, file, `
+policies:
+  - name: invalid
+    string_attribute: {key: http.url, values: [/admin], match_type: regex}
+`)
+	tsp.policiesReloader.check()
+	assert.Equal(t, []string{probabilisticFilterPolicyName, "errors", "everything"}, policyNames(tsp.getPolicies()))
+	require.NoError(t, tsp.Shutdown(context.Background()))
+}
+
+func TestPoliciesReloadMissingFile(t *testing.T) {
+	_, err := newCascadingFilterSpanProcessor(zap.NewNop(), consumertest.NewNop(), config.Config{
+		DecisionWait:      2 * time.Second,
+		NumTraces:         100,
+		PoliciesReloadCfg: &config.PoliciesReloadCfg{File: filepath.Join(t.TempDir(), "missing.yaml")},
+	})
+	require.Error(t, err)
+}
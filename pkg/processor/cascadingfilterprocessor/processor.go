@@ -16,6 +16,7 @@ package cascadingfilterprocessor
 
 import (
 	"context"
+	"fmt"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -26,12 +27,14 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenterror"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/idbatcher"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain"
 )
 
 // Policy combines a sampling policy evaluator with the destinations to be
@@ -45,6 +48,8 @@ type Policy struct {
 	ctx context.Context
 	// probabilisticFilter determines whether `sampling.probability` field must be calculated and added
 	probabilisticFilter bool
+	// alwaysKeep determines whether the traces selected by this policy bypass the total budget and are released early
+	alwaysKeep bool
 }
 
 // traceKey is defined since sync.Map requires a comparable type, isolating it on its own
@@ -54,21 +59,50 @@ type traceKey [16]byte
 // cascadingFilterSpanProcessor handles the incoming trace data and uses the given sampling
 // policy to sample traces.
 type cascadingFilterSpanProcessor struct {
-	ctx             context.Context
-	nextConsumer    consumer.Traces
-	start           sync.Once
-	maxNumTraces    uint64
-	policies        []*Policy
-	logger          *zap.Logger
-	idToTrace       sync.Map
-	policyTicker    tTicker
-	decisionBatcher idbatcher.Batcher
-	deleteChan      chan traceKey
-	numTracesOnMap  uint64
+	ctx              context.Context
+	id               string
+	nextConsumer     consumer.Traces
+	start            sync.Once
+	maxNumTraces     uint64
+	policies         []*Policy
+	logger           *zap.Logger
+	policiesLock     sync.RWMutex
+	idToTrace        sync.Map
+	policyTicker     tTicker
+	reloadTicker     tTicker
+	statsTicker      tTicker
+	serviceStats     *serviceStats
+	policiesReloader *policiesReloader
+	decisionBatcher  idbatcher.Batcher
+	// numDecisionBatches is the number of the batches waiting for the decision, besides the current one
+	numDecisionBatches uint64
+	deleteChan         chan traceKey
+	numTracesOnMap     uint64
+	// drain holds the deadline of making the pending decisions on shutdown
+	drain *drain.Coordinator
 
 	currentSecond        int64
 	maxSpansPerSecond    int64
 	spansInCurrentSecond int64
+	serviceBudget        *sampling.ServiceBudget
+	rootSpanBudget       *sampling.RootSpanBudget
+	samplingHints        *sampling.SamplingHints
+	decisionExport       *decisionExporter
+	honorUpstream        bool
+	recordMatching       bool
+	dryRun               bool
+
+	// decisionLock serializes the decisions taken on tick with the ones forced by the memory limit
+	decisionLock       sync.Mutex
+	tickInterval       time.Duration
+	decisionBatchSize  int
+	probabilisticRatio float64
+	adaptiveSampler    *adaptiveSampler
+
+	maxBufferedBytes  int64
+	bufferedBytes     int64
+	dropOnMemoryLimit bool
+	spillover         *spillover
 }
 
 const (
@@ -78,30 +112,109 @@ const (
 	AttributeSamplingRule         = "sampling.rule"
 
 	AttributeSamplingProbability = "sampling.probability"
+	// AttributeWouldHaveBeenDropped is set on all spans in the dry run mode, describing the decision
+	AttributeWouldHaveBeenDropped = "sampling.would_have_been_dropped"
+	// AttributeMatchingPolicies lists the names of all policies which matched the trace
+	AttributeMatchingPolicies = "sampling.cascading_filter.matching_policies"
+
+	defaultDecisionTickInterval = time.Second
+	defaultDecisionBatchSize    = 1000
+
+	memoryLimitEvictionDecideNow = "decide_now"
+	memoryLimitEvictionDrop      = "drop"
+
+	bytesInMiB = 1024 * 1024
 )
 
+var tracesSizer = otlp.NewProtobufTracesMarshaler().(pdata.TracesSizer)
+
 // newTraceProcessor returns a processor.TraceProcessor that will perform Cascading Filter according to the given
-// configuration.
-func newTraceProcessor(logger *zap.Logger, nextConsumer consumer.Traces, cfg config.Config) (component.TracesProcessor, error) {
+// configuration, and make the pending decisions on shutdown until the deadline of the coordinator.
+func newTraceProcessor(logger *zap.Logger, nextConsumer consumer.Traces, cfg config.Config,
+	coordinator *drain.Coordinator) (component.TracesProcessor, error) {
 	if nextConsumer == nil {
 		return nil, componenterror.ErrNilNextConsumer
 	}
 
-	return newCascadingFilterSpanProcessor(logger, nextConsumer, cfg)
+	cfsp, err := newCascadingFilterSpanProcessor(logger, nextConsumer, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfsp.drain = coordinator
+	return cfsp, nil
 }
 
 func newCascadingFilterSpanProcessor(logger *zap.Logger, nextConsumer consumer.Traces, cfg config.Config) (*cascadingFilterSpanProcessor, error) {
-	numDecisionBatches := uint64(cfg.DecisionWait.Seconds())
+	tickInterval := cfg.DecisionTickInterval
+	if tickInterval == 0 {
+		tickInterval = defaultDecisionTickInterval
+	}
+	if tickInterval < 0 || tickInterval > cfg.DecisionWait {
+		return nil, fmt.Errorf("decision_tick_interval must be greater than 0 and not greater than decision_wait")
+	}
+	if cfg.DecisionBatchSize < 0 {
+		return nil, fmt.Errorf("decision_batch_size must not be negative")
+	}
+	decisionBatchSize := cfg.DecisionBatchSize
+	if decisionBatchSize == 0 {
+		decisionBatchSize = defaultDecisionBatchSize
+	}
+
+	numDecisionBatches := uint64(cfg.DecisionWait / tickInterval)
 	inBatcher, err := idbatcher.New(numDecisionBatches, cfg.ExpectedNewTracesPerSec, uint64(2*runtime.NumCPU()))
 	if err != nil {
 		return nil, err
 	}
 
+	var dropOnMemoryLimit bool
+	switch cfg.MemoryLimitEviction {
+	case "", memoryLimitEvictionDecideNow:
+	case memoryLimitEvictionDrop:
+		dropOnMemoryLimit = true
+	default:
+		return nil, fmt.Errorf("unknown memory_limit_eviction %q, must be one of: %s, %s",
+			cfg.MemoryLimitEviction, memoryLimitEvictionDecideNow, memoryLimitEvictionDrop)
+	}
+
+	var spill *spillover
+	if cfg.SpilloverCfg != nil {
+		if cfg.MaxMemoryMiB == 0 {
+			return nil, fmt.Errorf("spillover requires max_memory_mib to be set")
+		}
+		spill, err = newSpillover(&cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	samplingHints, err := sampling.NewSamplingHints(cfg.SamplingHintsCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	decisionExport, err := newDecisionExporter(cfg.DecisionExportCfg)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx := context.Background()
 	var policies []*Policy
+	var adaptive *adaptiveSampler
 
 	// This must be always first as it must select traces independently of other policies
-	if cfg.ProbabilisticFilteringRatio != nil && *cfg.ProbabilisticFilteringRatio > 0.0 {
+	if cfg.TargetTracesPerMinute > 0 {
+		policyCtx, err := tag.New(ctx, tag.Upsert(tagPolicyKey, probabilisticFilterPolicyName))
+		if err != nil {
+			return nil, err
+		}
+		adaptive = newAdaptiveSampler(ctx, logger, cfg.TargetTracesPerMinute)
+		policies = append(policies, &Policy{
+			Name:                probabilisticFilterPolicyName,
+			Evaluator:           adaptive.filter,
+			ctx:                 policyCtx,
+			probabilisticFilter: true,
+		})
+	} else if cfg.ProbabilisticFilteringRatio != nil && *cfg.ProbabilisticFilteringRatio > 0.0 {
 		policyCtx, err := tag.New(ctx, tag.Upsert(tagPolicyKey, probabilisticFilterPolicyName))
 		if err != nil {
 			return nil, err
@@ -119,8 +232,77 @@ func newCascadingFilterSpanProcessor(logger *zap.Logger, nextConsumer consumer.T
 		policies = append(policies, policy)
 	}
 
-	for i := range cfg.PolicyCfgs {
-		policyCfg := &cfg.PolicyCfgs[i]
+	var reloader *policiesReloader
+	policyCfgs := cfg.PolicyCfgs
+	if cfg.PoliciesReloadCfg != nil {
+		reloader = newPoliciesReloader(logger, cfg.PoliciesReloadCfg)
+		policyCfgs, err = reloader.load()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rulePolicies, err := newRulePolicies(ctx, logger, policyCfgs)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RecordMatchingPolicies {
+		enableMatchReporting(rulePolicies)
+	}
+	policies = append(policies, rulePolicies...)
+
+	id := typeStr
+	if cfg.ProcessorSettings != nil {
+		id = cfg.ID().String()
+	}
+
+	cfsp := &cascadingFilterSpanProcessor{
+		ctx:                ctx,
+		id:                 id,
+		nextConsumer:       nextConsumer,
+		maxNumTraces:       cfg.NumTraces,
+		maxSpansPerSecond:  cfg.SpansPerSecond,
+		serviceBudget:      sampling.NewServiceBudget(cfg.ServiceBudgetCfg),
+		rootSpanBudget:     sampling.NewRootSpanBudget(cfg.RootSpanBudgetCfg),
+		samplingHints:      samplingHints,
+		decisionExport:     decisionExport,
+		honorUpstream:      cfg.HonorUpstreamDecisions,
+		recordMatching:     cfg.RecordMatchingPolicies,
+		dryRun:             cfg.DryRun,
+		tickInterval:       tickInterval,
+		decisionBatchSize:  decisionBatchSize,
+		logger:             logger,
+		decisionBatcher:    inBatcher,
+		numDecisionBatches: numDecisionBatches,
+		policies:           policies,
+		maxBufferedBytes:   int64(cfg.MaxMemoryMiB) * bytesInMiB,
+		dropOnMemoryLimit:  dropOnMemoryLimit,
+		spillover:          spill,
+		adaptiveSampler:    adaptive,
+		// Used for traces decided before the first tick
+		probabilisticRatio: 1.0,
+		drain:              drain.NewCoordinator(drain.DefaultTimeout),
+	}
+
+	cfsp.policyTicker = &policyTicker{onTick: cfsp.samplingPolicyOnTick}
+	if reloader != nil {
+		reloader.onReload = cfsp.replaceRulePolicies
+		cfsp.policiesReloader = reloader
+		cfsp.reloadTicker = &policyTicker{onTick: reloader.check}
+	}
+	if cfg.ServiceStatsCfg != nil {
+		cfsp.serviceStats = newServiceStats(ctx, logger, cfg.ServiceStatsCfg.Interval, cfg.ServiceStatsCfg.MaxServices)
+		cfsp.statsTicker = &policyTicker{onTick: cfsp.serviceStats.flush}
+	}
+	cfsp.deleteChan = make(chan traceKey, cfg.NumTraces)
+
+	return cfsp, nil
+}
+
+func newRulePolicies(ctx context.Context, logger *zap.Logger, policyCfgs []config.PolicyCfg) ([]*Policy, error) {
+	policies := make([]*Policy, 0, len(policyCfgs))
+	for i := range policyCfgs {
+		policyCfg := &policyCfgs[i]
 		policyCtx, err := tag.New(ctx, tag.Upsert(tagPolicyKey, policyCfg.Name))
 		if err != nil {
 			return nil, err
@@ -134,24 +316,51 @@ func newCascadingFilterSpanProcessor(logger *zap.Logger, nextConsumer consumer.T
 			Evaluator:           eval,
 			ctx:                 policyCtx,
 			probabilisticFilter: false,
+			alwaysKeep:          policyCfg.AlwaysKeep,
 		}
 		policies = append(policies, policy)
 	}
+	return policies, nil
+}
 
-	cfsp := &cascadingFilterSpanProcessor{
-		ctx:               ctx,
-		nextConsumer:      nextConsumer,
-		maxNumTraces:      cfg.NumTraces,
-		maxSpansPerSecond: cfg.SpansPerSecond,
-		logger:            logger,
-		decisionBatcher:   inBatcher,
-		policies:          policies,
+// enableMatchReporting makes the evaluators check the rules of all policies, so the ones which matched
+// but had no budget left are known too
+func enableMatchReporting(policies []*Policy) {
+	for _, policy := range policies {
+		if reporter, ok := policy.Evaluator.(sampling.MatchReporter); ok {
+			reporter.EnableMatchReporting()
+		}
 	}
+}
 
-	cfsp.policyTicker = &policyTicker{onTick: cfsp.samplingPolicyOnTick}
-	cfsp.deleteChan = make(chan traceKey, cfg.NumTraces)
+// getPolicies returns the current policies. The returned slice is never modified, reloading replaces it.
+func (cfsp *cascadingFilterSpanProcessor) getPolicies() []*Policy {
+	cfsp.policiesLock.RLock()
+	defer cfsp.policiesLock.RUnlock()
+	return cfsp.policies
+}
 
-	return cfsp, nil
+// replaceRulePolicies builds the policies from the config and replaces the current ones, keeping
+// the probabilistic filter. Traces already in memory are evaluated using the new policies.
+func (cfsp *cascadingFilterSpanProcessor) replaceRulePolicies(policyCfgs []config.PolicyCfg) error {
+	rulePolicies, err := newRulePolicies(cfsp.ctx, cfsp.logger, policyCfgs)
+	if err != nil {
+		return err
+	}
+	if cfsp.recordMatching {
+		enableMatchReporting(rulePolicies)
+	}
+
+	cfsp.policiesLock.Lock()
+	defer cfsp.policiesLock.Unlock()
+	var policies []*Policy
+	for _, policy := range cfsp.policies {
+		if policy.probabilisticFilter {
+			policies = append(policies, policy)
+		}
+	}
+	cfsp.policies = append(policies, rulePolicies...)
+	return nil
 }
 
 func getPolicyEvaluator(logger *zap.Logger, cfg *config.PolicyCfg) (sampling.PolicyEvaluator, error) {
@@ -166,165 +375,382 @@ type policyMetrics struct {
 	idNotFoundOnMapCount, evaluateErrorCount, decisionSampled, decisionNotSampled int64
 }
 
-func (cfsp *cascadingFilterSpanProcessor) updateRate(currSecond int64, numSpans int64) sampling.Decision {
-	if cfsp.currentSecond != currSecond {
-		cfsp.currentSecond = currSecond
-		cfsp.spansInCurrentSecond = 0
-	}
+func (cfsp *cascadingFilterSpanProcessor) updateRate(currSecond int64, trace *sampling.TraceData) sampling.Decision {
+	cfsp.resetRateIfNeeded(currSecond)
 
+	numSpans := trace.SpanCount
 	spansInSecondIfSampled := cfsp.spansInCurrentSecond + numSpans
-	if spansInSecondIfSampled <= cfsp.maxSpansPerSecond {
+	if spansInSecondIfSampled <= cfsp.maxSpansPerSecond &&
+		cfsp.serviceBudget.Fits(currSecond, trace.ServiceName, numSpans) &&
+		cfsp.rootSpanBudget.Fits(currSecond, trace.RootSpanName) {
 		cfsp.spansInCurrentSecond = spansInSecondIfSampled
+		cfsp.serviceBudget.Consume(currSecond, trace.ServiceName, numSpans)
+		cfsp.rootSpanBudget.Consume(currSecond, trace.RootSpanName)
 		return sampling.Sampled
 	}
 
 	return sampling.NotSampled
 }
 
+// budgetDecision returns the final decision for a trace selected by the policies. The total budget applies,
+// unless the trace was selected by an always keep policy.
+func (cfsp *cascadingFilterSpanProcessor) budgetDecision(currSecond int64, trace *sampling.TraceData) sampling.Decision {
+	if selectedByAlwaysKeep(cfsp.getPolicies(), trace) {
+		cfsp.consumeBudget(currSecond, trace)
+		return sampling.Sampled
+	}
+	return cfsp.updateRate(currSecond, trace)
+}
+
+// consumeBudget accounts the spans of a trace kept regardless of the budget, so they lower the budget left
+// for other traces
+func (cfsp *cascadingFilterSpanProcessor) consumeBudget(currSecond int64, trace *sampling.TraceData) {
+	cfsp.resetRateIfNeeded(currSecond)
+	cfsp.spansInCurrentSecond += trace.SpanCount
+	cfsp.serviceBudget.Consume(currSecond, trace.ServiceName, trace.SpanCount)
+	cfsp.rootSpanBudget.Consume(currSecond, trace.RootSpanName)
+}
+
+func selectedByAlwaysKeep(policies []*Policy, trace *sampling.TraceData) bool {
+	for i, policy := range policies {
+		if policy.alwaysKeep && i < len(trace.Decisions) && trace.Decisions[i] == sampling.Sampled {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfsp *cascadingFilterSpanProcessor) resetRateIfNeeded(currSecond int64) {
+	if cfsp.currentSecond != currSecond {
+		cfsp.currentSecond = currSecond
+		cfsp.spansInCurrentSecond = 0
+	}
+}
+
+// forcedDecision returns the decision forced by an upstream cascading filter or a sampling hint, along with
+// the name describing it in exported decisions, or Unspecified if the policies need to be evaluated
+func (cfsp *cascadingFilterSpanProcessor) forcedDecision(trace *sampling.TraceData) (sampling.Decision, string) {
+	if cfsp.honorUpstream && hasUpstreamDecision(trace) {
+		return sampling.Sampled, upstreamPolicyName
+	}
+	return cfsp.samplingHints.Evaluate(trace), samplingHintPolicyName
+}
+
+// applyForcedDecision makes the final decision forced by the sampling hint or upstream decision, bypassing policies
+// and budgets. The spans of kept traces are still accounted, so they lower the budget left for other traces.
+// Returns the decision status for the metrics.
+func (cfsp *cascadingFilterSpanProcessor) applyForcedDecision(currSecond int64, trace *sampling.TraceData, decision sampling.Decision, policyName string) string {
+	for i := range trace.Decisions {
+		trace.Decisions[i] = decision
+	}
+	trace.FinalDecision = decision
+	trace.DecidingPolicy = policyName
+
+	decisionStatus := statusHintDropped
+	if policyName == upstreamPolicyName {
+		decisionStatus = statusUpstreamKept
+	} else if decision == sampling.Sampled {
+		decisionStatus = statusHintKept
+	}
+	if decision == sampling.Sampled {
+		cfsp.consumeBudget(currSecond, trace)
+	}
+	return decisionStatus
+}
+
 func (cfsp *cascadingFilterSpanProcessor) samplingPolicyOnTick() {
+	batch, _ := cfsp.decisionBatcher.CloseCurrentAndTakeFirstBatch()
+	cfsp.logger.Debug("Sampling Policy Evaluation ticked")
+	cfsp.decideBatch(batch)
+}
+
+// decideBatch makes and executes the decisions on the traces in the batch
+func (cfsp *cascadingFilterSpanProcessor) decideBatch(batch idbatcher.Batch) {
 	metrics := policyMetrics{}
 
 	startTime := time.Now()
-	batch, _ := cfsp.decisionBatcher.CloseCurrentAndTakeFirstBatch()
 	batchLen := len(batch)
-	cfsp.logger.Debug("Sampling Policy Evaluation ticked")
 
-	currSecond := time.Now().Unix()
+	// The decision lock is taken separately for each chunk, so the decisions forced by the memory limit
+	// or early release are not blocked for the whole tick. All traces go through the first run before
+	// the second one, so the "SecondChance" traces get only what's left of the budget.
+	totals := tickTotals{}
+	decided := make([]*sampling.TraceData, 0, batchLen)
+	cfsp.forEachChunk(batchLen, func(start, end int) {
+		decided = append(decided, cfsp.makeDecisions(batch[start:end], &metrics, &totals)...)
+	})
+
+	cfsp.decisionLock.Lock()
+	if cfsp.adaptiveSampler != nil {
+		cfsp.probabilisticRatio = cfsp.adaptiveSampler.filter.Probability()
+	} else if totals.spans > 0 {
+		cfsp.probabilisticRatio = float64(totals.selectedByProbabilisticFilterSpans) / float64(totals.spans)
+	}
+	cfsp.decisionLock.Unlock()
+
+	cfsp.forEachChunk(len(decided), func(start, end int) {
+		cfsp.executeDecisions(decided[start:end], &metrics)
+	})
+
+	if cfsp.adaptiveSampler != nil {
+		cfsp.decisionLock.Lock()
+		cfsp.adaptiveSampler.adjust(time.Now())
+		cfsp.decisionLock.Unlock()
+	}
+
+	stats.Record(cfsp.ctx,
+		statOverallDecisionLatencyus.M(int64(time.Since(startTime)/time.Microsecond)),
+		statDroppedTooEarlyCount.M(metrics.idNotFoundOnMapCount),
+		statPolicyEvaluationErrorCount.M(metrics.evaluateErrorCount),
+		statTracesOnMemoryGauge.M(int64(atomic.LoadUint64(&cfsp.numTracesOnMap))),
+		statTracesOnMemoryBytesGauge.M(atomic.LoadInt64(&cfsp.bufferedBytes)))
+	if cfsp.spillover != nil {
+		stats.Record(cfsp.ctx, statTracesOnStorageGauge.M(cfsp.spillover.numSpilledTraces()))
+	}
+
+	cfsp.logger.Debug("Sampling policy evaluation completed",
+		zap.Int("batch.len", batchLen),
+		zap.Int64("sampled", metrics.decisionSampled),
+		zap.Int64("notSampled", metrics.decisionNotSampled),
+		zap.Int64("droppedPriorToEvaluation", metrics.idNotFoundOnMapCount),
+		zap.Int64("policyEvaluationErrors", metrics.evaluateErrorCount),
+	)
+}
+
+// tickTotals accumulates the number of spans decided during a tick, for calculating the probabilistic ratio
+type tickTotals struct {
+	spans, selectedByProbabilisticFilterSpans int64
+}
+
+// decisionCounts accumulates the number of final decisions per status, so the metrics are recorded once per batch
+type decisionCounts map[string]int64
+
+func (cfsp *cascadingFilterSpanProcessor) recordDecisionCounts(counts decisionCounts) {
+	for status, count := range counts {
+		err := stats.RecordWithTags(
+			cfsp.ctx,
+			[]tag.Mutator{tag.Insert(tagCascadingFilterDecisionKey, status)},
+			statCascadingFilterDecision.M(count),
+		)
+		if err != nil {
+			cfsp.logger.Error("Sampling Policy Evaluation error on recording decisions", zap.Error(err))
+		}
+	}
+}
+
+// forEachChunk splits n items into chunks of decisionBatchSize
+func (cfsp *cascadingFilterSpanProcessor) forEachChunk(n int, fn func(start, end int)) {
+	chunkSize := cfsp.decisionBatchSize
+	if chunkSize <= 0 {
+		chunkSize = n
+	}
+	for start := 0; start < n; start += chunkSize {
+		end := start + chunkSize
+		if end > n {
+			end = n
+		}
+		fn(start, end)
+	}
+}
 
-	totalSpans := int64(0)
-	selectedByProbabilisticFilterSpans := int64(0)
+// makeDecisions applies the decisions to the given traces, executing each policy separately.
+// Returns the traces which were decided.
+func (cfsp *cascadingFilterSpanProcessor) makeDecisions(ids []pdata.TraceID, metrics *policyMetrics, totals *tickTotals) []*sampling.TraceData {
+	cfsp.decisionLock.Lock()
+	defer cfsp.decisionLock.Unlock()
 
-	// The first run applies decisions to batches, executing each policy separately
-	for _, id := range batch {
+	now := time.Now()
+	currSecond := now.Unix()
+	counts := decisionCounts{}
+	traces := make([]*sampling.TraceData, 0, len(ids))
+
+	for _, id := range ids {
 		d, ok := cfsp.idToTrace.Load(traceKey(id.Bytes()))
 		if !ok {
 			metrics.idNotFoundOnMapCount++
 			continue
 		}
 		trace := d.(*sampling.TraceData)
-		trace.DecisionTime = time.Now()
-		totalSpans += trace.SpanCount
+		if trace.ReleasedEarly {
+			continue
+		}
+		traces = append(traces, trace)
+		trace.DecisionTime = now
+		totals.spans += trace.SpanCount
+		cfsp.restoreSpilled(traceKey(id.Bytes()), trace)
+
+		if forced, policyName := cfsp.forcedDecision(trace); forced != sampling.Unspecified {
+			counts[cfsp.applyForcedDecision(currSecond, trace, forced, policyName)]++
+			continue
+		}
 
 		provisionalDecision, _ := cfsp.makeProvisionalDecision(id, trace)
-		if provisionalDecision == sampling.Sampled {
-			trace.FinalDecision = cfsp.updateRate(currSecond, trace.SpanCount)
+		switch provisionalDecision {
+		case sampling.Sampled:
+			trace.FinalDecision = cfsp.budgetDecision(currSecond, trace)
 			if trace.FinalDecision == sampling.Sampled {
 				if trace.SelectedByProbabilisticFilter {
-					selectedByProbabilisticFilterSpans += trace.SpanCount
-				}
-				err := stats.RecordWithTags(
-					cfsp.ctx,
-					[]tag.Mutator{tag.Insert(tagCascadingFilterDecisionKey, statusSampled)},
-					statCascadingFilterDecision.M(int64(1)),
-				)
-				if err != nil {
-					cfsp.logger.Error("Sampling Policy Evaluation error on first run tick", zap.Error(err))
+					totals.selectedByProbabilisticFilterSpans += trace.SpanCount
 				}
+				counts[statusSampled]++
 			} else {
-				err := stats.RecordWithTags(
-					cfsp.ctx,
-					[]tag.Mutator{tag.Insert(tagCascadingFilterDecisionKey, statusExceededKey)},
-					statCascadingFilterDecision.M(int64(1)),
-				)
-				if err != nil {
-					cfsp.logger.Error("Sampling Policy Evaluation error on first run tick", zap.Error(err))
-				}
+				counts[statusExceededKey]++
 			}
-		} else if provisionalDecision == sampling.SecondChance {
+		case sampling.SecondChance:
 			trace.FinalDecision = sampling.SecondChance
-		} else {
+		default:
 			trace.FinalDecision = provisionalDecision
-			err := stats.RecordWithTags(
-				cfsp.ctx,
-				[]tag.Mutator{tag.Insert(tagCascadingFilterDecisionKey, statusNotSampled)},
-				statCascadingFilterDecision.M(int64(1)),
-			)
-			if err != nil {
-				cfsp.logger.Error("Sampling Policy Evaluation error on first run tick", zap.Error(err))
-			}
+			counts[statusNotSampled]++
 		}
 	}
 
-	// The second run executes the decisions and makes "SecondChance" decisions in the meantime
-	for _, id := range batch {
-		d, ok := cfsp.idToTrace.Load(traceKey(id.Bytes()))
-		if !ok {
-			continue
-		}
-		trace := d.(*sampling.TraceData)
+	cfsp.recordDecisionCounts(counts)
+	return traces
+}
+
+// executeDecisions makes the "SecondChance" decisions and sends the sampled traces to the next consumer.
+// The traces are sent after releasing decisionLock, so a slow consumer does not block the ingestion.
+func (cfsp *cascadingFilterSpanProcessor) executeDecisions(traces []*sampling.TraceData, metrics *policyMetrics) {
+	cfsp.decisionLock.Lock()
+
+	currSecond := time.Now().Unix()
+	counts := decisionCounts{}
+	sends := pendingSends{}
+
+	for _, trace := range traces {
 		if trace.FinalDecision == sampling.SecondChance {
-			trace.FinalDecision = cfsp.updateRate(currSecond, trace.SpanCount)
+			trace.FinalDecision = cfsp.updateRate(currSecond, trace)
 			if trace.FinalDecision == sampling.Sampled {
-				err := stats.RecordWithTags(
-					cfsp.ctx,
-					[]tag.Mutator{tag.Insert(tagCascadingFilterDecisionKey, statusSecondChanceSampled)},
-					statCascadingFilterDecision.M(int64(1)),
-				)
-				if err != nil {
-					cfsp.logger.Error("Sampling Policy Evaluation error on second run tick", zap.Error(err))
-				}
+				counts[statusSecondChanceSampled]++
 			} else {
-				err := stats.RecordWithTags(
-					cfsp.ctx,
-					[]tag.Mutator{tag.Insert(tagCascadingFilterDecisionKey, statusSecondChanceExceeded)},
-					statCascadingFilterDecision.M(int64(1)),
-				)
-				if err != nil {
-					cfsp.logger.Error("Sampling Policy Evaluation error on second run tick", zap.Error(err))
-				}
+				counts[statusSecondChanceExceeded]++
 			}
 		}
 
 		// Sampled or not, remove the batches
-		trace.Lock()
-		traceBatches := trace.ReceivedBatches
-		trace.ReceivedBatches = nil
-		trace.Unlock()
+		traceBatches := cfsp.releaseBatches(trace)
+		cfsp.recordServiceDecision(trace, trace.FinalDecision)
+		cfsp.observeAdaptiveSampling(trace)
+		cfsp.observeSpans(traceBatches)
 
 		if trace.FinalDecision == sampling.Sampled {
 			metrics.decisionSampled++
-
-			// Combine all individual batches into a single batch so
-			// consumers may operate on the entire trace
-			allSpans := pdata.NewTraces()
-			for j := 0; j < len(traceBatches); j++ {
-				batch := traceBatches[j]
-				batch.ResourceSpans().MoveAndAppendTo(allSpans.ResourceSpans())
+			sends.add(cfsp.sampledTraceBatch(trace, traceBatches), "Sampling Policy Evaluation error on consuming traces")
+		} else {
+			metrics.decisionNotSampled++
+			if allSpans, ok := cfsp.droppedTraceBatch(traceBatches); ok {
+				sends.add(allSpans, "Sampling Policy Evaluation error on consuming dropped traces in dry run")
 			}
+		}
+	}
 
-			if trace.SelectedByProbabilisticFilter {
-				updateProbabilisticRateTag(allSpans, selectedByProbabilisticFilterSpans, totalSpans)
-			} else {
-				updateFilteringTag(allSpans)
-			}
+	cfsp.recordDecisionCounts(counts)
+	cfsp.decisionLock.Unlock()
 
-			err := cfsp.nextConsumer.ConsumeTraces(cfsp.ctx, allSpans)
-			if err != nil {
-				cfsp.logger.Error("Sampling Policy Evaluation error on consuming traces", zap.Error(err))
-			}
-		} else {
-			metrics.decisionNotSampled++
+	cfsp.sendPending(sends)
+}
+
+// pendingSend is a decided trace waiting to be sent to the next consumer
+type pendingSend struct {
+	traces pdata.Traces
+	errMsg string
+}
+
+// pendingSends collects the traces decided while holding decisionLock, so they are sent once it's released
+type pendingSends []pendingSend
+
+func (s *pendingSends) add(traces pdata.Traces, errMsg string) {
+	*s = append(*s, pendingSend{traces: traces, errMsg: errMsg})
+}
+
+// sendPending passes the collected traces to the next consumer. Must be called without decisionLock held.
+func (cfsp *cascadingFilterSpanProcessor) sendPending(sends pendingSends) {
+	for _, send := range sends {
+		if err := cfsp.nextConsumer.ConsumeTraces(cfsp.ctx, send.traces); err != nil {
+			cfsp.logger.Error(send.errMsg, zap.Error(err))
 		}
 	}
+}
+
+// sendDroppedTrace sends the batches of a dropped trace to the next consumer in the dry run mode, does nothing otherwise
+func (cfsp *cascadingFilterSpanProcessor) sendDroppedTrace(traceBatches []pdata.Traces) error {
+	allSpans, ok := cfsp.droppedTraceBatch(traceBatches)
+	if !ok {
+		return nil
+	}
+	return cfsp.nextConsumer.ConsumeTraces(cfsp.ctx, allSpans)
+}
 
-	stats.Record(cfsp.ctx,
-		statOverallDecisionLatencyus.M(int64(time.Since(startTime)/time.Microsecond)),
-		statDroppedTooEarlyCount.M(metrics.idNotFoundOnMapCount),
-		statPolicyEvaluationErrorCount.M(metrics.evaluateErrorCount),
-		statTracesOnMemoryGauge.M(int64(atomic.LoadUint64(&cfsp.numTracesOnMap))))
+// sampledTraceBatch combines all individual batches into a single batch, so consumers may operate on the entire
+// trace, and sets the attributes describing the decision. Must be called with decisionLock held.
+func (cfsp *cascadingFilterSpanProcessor) sampledTraceBatch(trace *sampling.TraceData, traceBatches []pdata.Traces) pdata.Traces {
+	allSpans := pdata.NewTraces()
+	for _, batch := range traceBatches {
+		batch.ResourceSpans().MoveAndAppendTo(allSpans.ResourceSpans())
+	}
+	if cfsp.dryRun {
+		// The spans are forwarded unchanged, except for the decision
+		updateWouldHaveBeenDroppedTag(allSpans, false)
+	} else {
+		cfsp.annotateSampledSpans(allSpans, trace)
+	}
+	return allSpans
+}
 
-	cfsp.logger.Debug("Sampling policy evaluation completed",
-		zap.Int("batch.len", batchLen),
-		zap.Int64("sampled", metrics.decisionSampled),
-		zap.Int64("notSampled", metrics.decisionNotSampled),
-		zap.Int64("droppedPriorToEvaluation", metrics.idNotFoundOnMapCount),
-		zap.Int64("policyEvaluationErrors", metrics.evaluateErrorCount),
-	)
+// droppedTraceBatch combines the batches of a dropped trace in the dry run mode. Returns false when there is
+// nothing to send.
+func (cfsp *cascadingFilterSpanProcessor) droppedTraceBatch(traceBatches []pdata.Traces) (pdata.Traces, bool) {
+	if !cfsp.dryRun || len(traceBatches) == 0 {
+		return pdata.Traces{}, false
+	}
+
+	allSpans := pdata.NewTraces()
+	for _, batch := range traceBatches {
+		batch.ResourceSpans().MoveAndAppendTo(allSpans.ResourceSpans())
+	}
+	updateWouldHaveBeenDroppedTag(allSpans, true)
+	return allSpans, true
 }
 
-func updateProbabilisticRateTag(traces pdata.Traces, probabilisticSpans int64, allSpans int64) {
-	ratio := float64(probabilisticSpans) / float64(allSpans)
+// annotateSampledSpans sets the attributes describing the decision on the spans of a sampled trace
+func (cfsp *cascadingFilterSpanProcessor) annotateSampledSpans(traces pdata.Traces, trace *sampling.TraceData) {
+	if trace.SelectedByProbabilisticFilter {
+		updateProbabilisticRateTag(traces, cfsp.probabilisticRatio)
+	} else {
+		updateFilteringTag(traces)
+	}
+
+	// The decision of the upstream cascading filter is passed on unchanged
+	if trace.DecidingPolicy != upstreamPolicyName {
+		cfsp.decisionExport.export(traces, trace.DecidingPolicy)
+	}
 
+	if cfsp.recordMatching && len(trace.MatchingPolicies) > 0 {
+		updateMatchingPoliciesTag(traces, trace.MatchingPolicies)
+	}
+}
+
+func updateMatchingPoliciesTag(traces pdata.Traces, policyNames []string) {
+	rs := traces.ResourceSpans()
+
+	for i := 0; i < rs.Len(); i++ {
+		ils := rs.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ils.Len(); j++ {
+			spans := ils.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				value := pdata.NewAttributeValueArray()
+				names := value.ArrayVal()
+				names.EnsureCapacity(len(policyNames))
+				for _, name := range policyNames {
+					names.AppendEmpty().SetStringVal(name)
+				}
+				spans.At(k).Attributes().Upsert(AttributeMatchingPolicies, value)
+			}
+		}
+	}
+}
+
+func updateProbabilisticRateTag(traces pdata.Traces, ratio float64) {
 	rs := traces.ResourceSpans()
 
 	for i := 0; i < rs.Len(); i++ {
@@ -345,6 +771,20 @@ func updateProbabilisticRateTag(traces pdata.Traces, probabilisticSpans int64, a
 	}
 }
 
+func updateWouldHaveBeenDroppedTag(traces pdata.Traces, dropped bool) {
+	rs := traces.ResourceSpans()
+
+	for i := 0; i < rs.Len(); i++ {
+		ils := rs.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ils.Len(); j++ {
+			spans := ils.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				spans.At(k).Attributes().UpsertBool(AttributeWouldHaveBeenDropped, dropped)
+			}
+		}
+	}
+}
+
 func updateFilteringTag(traces pdata.Traces) {
 	rs := traces.ResourceSpans()
 
@@ -364,7 +804,19 @@ func (cfsp *cascadingFilterSpanProcessor) makeProvisionalDecision(id pdata.Trace
 	provisionalDecision := sampling.Unspecified
 	var matchingPolicy *Policy = nil
 
-	for i, policy := range cfsp.policies {
+	policies := cfsp.getPolicies()
+	if len(trace.Decisions) != len(policies) {
+		// The policies were reloaded since the trace arrived
+		trace.Lock()
+		trace.Decisions = make([]sampling.Decision, len(policies))
+		for i := range trace.Decisions {
+			trace.Decisions[i] = sampling.Pending
+		}
+		trace.Unlock()
+	}
+	trace.MatchingPolicies = nil
+
+	for i, policy := range policies {
 		policyEvaluateStartTime := time.Now()
 		decision := policy.Evaluator.Evaluate(id, trace)
 		stats.Record(
@@ -372,6 +824,9 @@ func (cfsp *cascadingFilterSpanProcessor) makeProvisionalDecision(id pdata.Trace
 			statDecisionLatencyMicroSec.M(int64(time.Since(policyEvaluateStartTime)/time.Microsecond)))
 
 		trace.Decisions[i] = decision
+		if cfsp.recordMatching && policyMatched(policy, decision) {
+			trace.MatchingPolicies = append(trace.MatchingPolicies, policy.Name)
+		}
 
 		switch decision {
 		case sampling.Sampled:
@@ -380,6 +835,7 @@ func (cfsp *cascadingFilterSpanProcessor) makeProvisionalDecision(id pdata.Trace
 			provisionalDecision = sampling.Sampled
 			if matchingPolicy == nil {
 				matchingPolicy = policy
+				trace.DecidingPolicy = policy.Name
 			}
 
 			if policy.probabilisticFilter {
@@ -408,6 +864,9 @@ func (cfsp *cascadingFilterSpanProcessor) makeProvisionalDecision(id pdata.Trace
 			}
 		case sampling.SecondChance:
 			if provisionalDecision != sampling.Sampled {
+				if provisionalDecision != sampling.SecondChance {
+					trace.DecidingPolicy = policy.Name
+				}
 				provisionalDecision = sampling.SecondChance
 			}
 
@@ -425,11 +884,20 @@ func (cfsp *cascadingFilterSpanProcessor) makeProvisionalDecision(id pdata.Trace
 	return provisionalDecision, matchingPolicy
 }
 
+// policyMatched tells whether the policy rules matched the trace, using the last evaluation result when
+// the evaluator reports it and the decision otherwise
+func policyMatched(policy *Policy, decision sampling.Decision) bool {
+	if reporter, ok := policy.Evaluator.(sampling.MatchReporter); ok {
+		return reporter.LastEvaluationMatched()
+	}
+	return decision == sampling.Sampled || decision == sampling.SecondChance
+}
+
 // ConsumeTraceData is required by the SpanProcessor interface.
 func (cfsp *cascadingFilterSpanProcessor) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
 	cfsp.start.Do(func() {
 		cfsp.logger.Info("First trace data arrived, starting cascading_filter timers")
-		cfsp.policyTicker.Start(1 * time.Second)
+		cfsp.policyTicker.Start(cfsp.decisionTickInterval())
 	})
 	resourceSpans := td.ResourceSpans()
 	for i := 0; i < resourceSpans.Len(); i++ {
@@ -439,6 +907,23 @@ func (cfsp *cascadingFilterSpanProcessor) ConsumeTraces(ctx context.Context, td
 	return nil
 }
 
+func (cfsp *cascadingFilterSpanProcessor) decisionTickInterval() time.Duration {
+	if cfsp.tickInterval <= 0 {
+		return defaultDecisionTickInterval
+	}
+	return cfsp.tickInterval
+}
+
+// rootSpanName returns the name of the span without a parent or an empty string if there's no such span
+func rootSpanName(spans []*pdata.Span) string {
+	for _, span := range spans {
+		if span.ParentSpanID().IsEmpty() {
+			return span.Name()
+		}
+	}
+	return ""
+}
+
 func (cfsp *cascadingFilterSpanProcessor) groupSpansByTraceKey(resourceSpans pdata.ResourceSpans) map[traceKey][]*pdata.Span {
 	idToSpans := make(map[traceKey][]*pdata.Span)
 	ilss := resourceSpans.InstrumentationLibrarySpans()
@@ -461,9 +946,14 @@ func (cfsp *cascadingFilterSpanProcessor) processTraces(resourceSpans pdata.Reso
 	// Group spans per their traceId to minimize contention on idToTrace
 	idToSpans := cfsp.groupSpansByTraceKey(resourceSpans)
 	var newTraceIDs int64
+	policies := cfsp.getPolicies()
+	hasAlwaysKeep := false
+	for _, policy := range policies {
+		hasAlwaysKeep = hasAlwaysKeep || policy.alwaysKeep
+	}
 	for id, spans := range idToSpans {
 		lenSpans := int64(len(spans))
-		lenPolicies := len(cfsp.policies)
+		lenPolicies := len(policies)
 		initialDecisions := make([]sampling.Decision, lenPolicies)
 		for i := 0; i < lenPolicies; i++ {
 			initialDecisions[i] = sampling.Pending
@@ -493,14 +983,20 @@ func (cfsp *cascadingFilterSpanProcessor) processTraces(resourceSpans pdata.Reso
 				default:
 					// Note this is a buffered channel, so this will only delete excessive traces (if they exist)
 					traceKeyToDrop := <-cfsp.deleteChan
-					cfsp.dropTrace(traceKeyToDrop, currTime)
+					cfsp.sendPending(cfsp.dropTrace(traceKeyToDrop, currTime))
 				}
 			}
 		}
 
-		for i, policy := range cfsp.policies {
+		appended, lateForwarded := false, false
+		for i, policy := range policies {
 			var traceTd pdata.Traces
 			actualData.Lock()
+			if i >= len(actualData.Decisions) {
+				// The policies were reloaded since the decision was made
+				actualData.Unlock()
+				break
+			}
 			actualDecision := actualData.Decisions[i]
 			// If decision is pending, we want to add the new spans still under the lock, so the decision doesn't happen
 			// in between the transition from pending.
@@ -509,7 +1005,19 @@ func (cfsp *cascadingFilterSpanProcessor) processTraces(resourceSpans pdata.Reso
 				// be duplicated in the final trace.
 				traceTd = prepareTraceBatch(resourceSpans, spans)
 				actualData.ReceivedBatches = append(actualData.ReceivedBatches, traceTd)
+				if actualData.ServiceName == "" {
+					actualData.ServiceName = sampling.ResourceServiceName(resourceSpans.Resource())
+				}
+				if actualData.RootSpanName == "" {
+					actualData.RootSpanName = rootSpanName(spans)
+				}
+				if cfsp.maxBufferedBytes > 0 {
+					size := int64(tracesSizer.TracesSize(traceTd))
+					actualData.SizeBytes += size
+					atomic.AddInt64(&cfsp.bufferedBytes, size)
+				}
 				actualData.Unlock()
+				appended = true
 				break
 			}
 			actualData.Unlock()
@@ -523,6 +1031,9 @@ func (cfsp *cascadingFilterSpanProcessor) processTraces(resourceSpans pdata.Reso
 			case sampling.Sampled:
 				// Forward the spans to the policy destinations
 				traceTd := prepareTraceBatch(resourceSpans, spans)
+				if cfsp.dryRun {
+					updateWouldHaveBeenDroppedTag(traceTd, actualData.FinalDecision != sampling.Sampled)
+				}
 				if err := cfsp.nextConsumer.ConsumeTraces(policy.ctx, traceTd); err != nil {
 					cfsp.logger.Warn("Error sending late arrived spans to destination",
 						zap.String("policy", policy.Name),
@@ -546,12 +1057,302 @@ func (cfsp *cascadingFilterSpanProcessor) processTraces(resourceSpans pdata.Reso
 			// At this point the late arrival has been passed to nextConsumer. Need to break out of the policy loop
 			// so that it isn't sent to nextConsumer more than once when multiple policies chose to sample
 			if actualDecision == sampling.Sampled {
+				lateForwarded = true
 				break
 			}
 		}
+
+		if cfsp.dryRun && !appended && !lateForwarded && len(policies) > 0 {
+			// Late spans of a dropped trace
+			if err := cfsp.sendDroppedTrace([]pdata.Traces{prepareTraceBatch(resourceSpans, spans)}); err != nil {
+				cfsp.logger.Warn("Error sending late arrived spans of dropped trace in dry run", zap.Error(err))
+			}
+		}
+
+		if appended && hasAlwaysKeep {
+			cfsp.releaseIfAlwaysKeep(id)
+		}
 	}
 
 	stats.Record(cfsp.ctx, statNewTraceIDReceivedCount.M(newTraceIDs))
+
+	cfsp.enforceMemoryLimit()
+}
+
+// enforceMemoryLimit evicts the oldest traces until the buffered span data fits within the memory limit
+func (cfsp *cascadingFilterSpanProcessor) enforceMemoryLimit() {
+	if cfsp.maxBufferedBytes <= 0 {
+		return
+	}
+
+	currTime := time.Now()
+	// Spilled traces are put back to the queue, so each one is visited at most once
+	for i := len(cfsp.deleteChan); i > 0 && atomic.LoadInt64(&cfsp.bufferedBytes) > cfsp.maxBufferedBytes; i-- {
+		var traceKeyToEvict traceKey
+		select {
+		case traceKeyToEvict = <-cfsp.deleteChan:
+		default:
+			// Nothing left to evict
+			return
+		}
+
+		if cfsp.spillTrace(traceKeyToEvict) {
+			stats.Record(cfsp.ctx, statTracesSpilledCount.M(int64(1)))
+			continue
+		}
+
+		cfsp.evictTrace(traceKeyToEvict, currTime)
+		stats.Record(cfsp.ctx, statTracesEvictedOnMemoryLimitCount.M(int64(1)))
+	}
+}
+
+// spillTrace moves the span data of an undecided trace to the storage. The trace stays in memory otherwise
+// and is put back to the end of the deletion queue, so it is evaluated once the decision wait passes.
+// The span data is taken under the locks and written after releasing them. Returns false if the trace
+// needs to be evicted instead.
+func (cfsp *cascadingFilterSpanProcessor) spillTrace(id traceKey) bool {
+	if cfsp.spillover == nil {
+		return false
+	}
+
+	key, ok := cfsp.takeSpillChunk(id)
+	if !ok {
+		return false
+	}
+	if key == "" {
+		return true
+	}
+
+	size, err := cfsp.spillover.writeChunk(cfsp.ctx, key)
+	if err != nil {
+		cfsp.logger.Error("Spilling trace to the storage failed, keeping it in memory", zap.Error(err))
+	}
+	atomic.AddInt64(&cfsp.bufferedBytes, -size)
+	return true
+}
+
+// takeSpillChunk takes the span data of an undecided trace for spilling. Returns the key of the chunk to write,
+// which is empty when there is nothing to write, and false if the trace cannot be spilled.
+func (cfsp *cascadingFilterSpanProcessor) takeSpillChunk(id traceKey) (string, bool) {
+	cfsp.decisionLock.Lock()
+	defer cfsp.decisionLock.Unlock()
+
+	d, ok := cfsp.idToTrace.Load(id)
+	if !ok {
+		return "", false
+	}
+	trace := d.(*sampling.TraceData)
+	if trace.FinalDecision != sampling.Unspecified {
+		// Nothing to spill, the span data was released when the decision was made
+		return "", false
+	}
+
+	trace.Lock()
+	defer trace.Unlock()
+	if !cfsp.spillover.canSpill(trace) {
+		return "", false
+	}
+
+	select {
+	case cfsp.deleteChan <- id:
+	default:
+		// The queue was filled by new traces in the meantime
+		return "", false
+	}
+
+	key, err := cfsp.spillover.takeChunk(id, trace)
+	if err != nil {
+		cfsp.logger.Error("Spilling trace to the storage failed, keeping it in memory", zap.Error(err))
+	}
+	return key, true
+}
+
+// restoreSpilled brings back the span data spilled to the storage, so the trace can be evaluated.
+// Must be called with decisionLock held.
+func (cfsp *cascadingFilterSpanProcessor) restoreSpilled(id traceKey, trace *sampling.TraceData) {
+	if cfsp.spillover == nil {
+		return
+	}
+
+	trace.Lock()
+	defer trace.Unlock()
+	if err := cfsp.spillover.restore(cfsp.ctx, id, trace); err != nil {
+		cfsp.logger.Error("Restoring spilled trace from the storage failed", zap.Error(err))
+	}
+}
+
+// releaseIfAlwaysKeep evaluates the always keep policies as the spans arrive and sends the trace immediately
+// when any of them matches. The trace stays in memory, so the late spans are sent as well.
+func (cfsp *cascadingFilterSpanProcessor) releaseIfAlwaysKeep(id traceKey) {
+	cfsp.decisionLock.Lock()
+	sends := cfsp.decideIfAlwaysKeep(id)
+	cfsp.decisionLock.Unlock()
+
+	cfsp.sendPending(sends)
+}
+
+// decideIfAlwaysKeep marks the trace as sampled when any of the always keep policies matches and returns it
+// for sending. Must be called with decisionLock held.
+func (cfsp *cascadingFilterSpanProcessor) decideIfAlwaysKeep(id traceKey) pendingSends {
+	d, ok := cfsp.idToTrace.Load(id)
+	if !ok {
+		return nil
+	}
+	trace := d.(*sampling.TraceData)
+	if trace.FinalDecision != sampling.Unspecified || trace.SpilledChunks > 0 {
+		// Already decided or partially in the storage, so left for the regular decision
+		return nil
+	}
+
+	policies := cfsp.getPolicies()
+	if len(trace.Decisions) != len(policies) {
+		return nil
+	}
+	var matchingPolicy *Policy
+	for _, policy := range policies {
+		if policy.alwaysKeep && policy.Evaluator.Evaluate(pdata.NewTraceID(id), trace) == sampling.Sampled {
+			matchingPolicy = policy
+			break
+		}
+	}
+	if matchingPolicy == nil || cfsp.samplingHints.Evaluate(trace) == sampling.NotSampled {
+		return nil
+	}
+
+	trace.Lock()
+	for i, policy := range policies {
+		if policy == matchingPolicy {
+			trace.Decisions[i] = sampling.Sampled
+		} else {
+			trace.Decisions[i] = sampling.NotSampled
+		}
+	}
+	trace.Unlock()
+	trace.DecisionTime = time.Now()
+	trace.FinalDecision = sampling.Sampled
+	trace.DecidingPolicy = matchingPolicy.Name
+	trace.ReleasedEarly = true
+	if cfsp.recordMatching {
+		// The remaining policies are not evaluated for the traces released early
+		trace.MatchingPolicies = []string{matchingPolicy.Name}
+	}
+	cfsp.consumeBudget(trace.DecisionTime.Unix(), trace)
+
+	cfsp.recordDecisionCounts(decisionCounts{statusReleasedEarly: 1})
+
+	traceBatches := cfsp.releaseBatches(trace)
+	cfsp.recordServiceDecision(trace, trace.FinalDecision)
+	cfsp.observeAdaptiveSampling(trace)
+	cfsp.observeSpans(traceBatches)
+	sends := pendingSends{}
+	sends.add(cfsp.sampledTraceBatch(trace, traceBatches), "Sampling Policy Evaluation error on consuming early released traces")
+	return sends
+}
+
+// evictTrace removes the trace from memory, making the decision first unless configured to drop it.
+// The trace is sent after releasing decisionLock.
+func (cfsp *cascadingFilterSpanProcessor) evictTrace(id traceKey, currTime time.Time) {
+	cfsp.decisionLock.Lock()
+	var sends pendingSends
+	if !cfsp.dropOnMemoryLimit {
+		sends = cfsp.decideNow(id)
+	}
+	sends = append(sends, cfsp.dropTrace(id, currTime)...)
+	cfsp.decisionLock.Unlock()
+
+	cfsp.sendPending(sends)
+}
+
+// decideNow makes the final decision for a trace prior to the end of decision wait time and returns it
+// for sending. Must be called with decisionLock held.
+func (cfsp *cascadingFilterSpanProcessor) decideNow(id traceKey) pendingSends {
+	d, ok := cfsp.idToTrace.Load(id)
+	if !ok {
+		return nil
+	}
+	trace := d.(*sampling.TraceData)
+
+	if trace.FinalDecision != sampling.Unspecified {
+		// The decision was already made on tick
+		return nil
+	}
+
+	trace.DecisionTime = time.Now()
+	cfsp.restoreSpilled(id, trace)
+	var decisionStatus string
+	if forced, policyName := cfsp.forcedDecision(trace); forced != sampling.Unspecified {
+		decisionStatus = cfsp.applyForcedDecision(trace.DecisionTime.Unix(), trace, forced, policyName)
+	} else {
+		provisionalDecision, _ := cfsp.makeProvisionalDecision(pdata.NewTraceID(id), trace)
+		trace.FinalDecision = sampling.NotSampled
+		decisionStatus = statusNotSampled
+		if provisionalDecision == sampling.Sampled || provisionalDecision == sampling.SecondChance {
+			trace.FinalDecision = cfsp.budgetDecision(trace.DecisionTime.Unix(), trace)
+			decisionStatus = statusExceededKey
+			if trace.FinalDecision == sampling.Sampled {
+				decisionStatus = statusSampled
+			}
+		}
+	}
+	cfsp.recordDecisionCounts(decisionCounts{decisionStatus: 1})
+
+	traceBatches := cfsp.releaseBatches(trace)
+	cfsp.recordServiceDecision(trace, trace.FinalDecision)
+	cfsp.observeAdaptiveSampling(trace)
+	cfsp.observeSpans(traceBatches)
+	sends := pendingSends{}
+	if trace.FinalDecision != sampling.Sampled {
+		if allSpans, ok := cfsp.droppedTraceBatch(traceBatches); ok {
+			sends.add(allSpans, "Sampling Policy Evaluation error on consuming dropped traces in dry run")
+		}
+		return sends
+	}
+
+	sends.add(cfsp.sampledTraceBatch(trace, traceBatches), "Sampling Policy Evaluation error on consuming evicted traces")
+	return sends
+}
+
+// recordServiceDecision accounts the decision in per-service statistics, if these are enabled
+func (cfsp *cascadingFilterSpanProcessor) recordServiceDecision(trace *sampling.TraceData, decision sampling.Decision) {
+	if cfsp.serviceStats == nil {
+		return
+	}
+	service := trace.ServiceName
+	if service == "" {
+		service = unknownServiceName
+	}
+	cfsp.serviceStats.record(service, decision == sampling.Sampled)
+}
+
+// observeAdaptiveSampling accounts the decided trace in the adaptive sampling, if it's enabled
+func (cfsp *cascadingFilterSpanProcessor) observeAdaptiveSampling(trace *sampling.TraceData) {
+	if cfsp.adaptiveSampler != nil {
+		cfsp.adaptiveSampler.observe(cfsp.getPolicies(), trace)
+	}
+}
+
+// observeSpans passes the spans of the decided trace to the policies learning from them, once per trace
+func (cfsp *cascadingFilterSpanProcessor) observeSpans(traceBatches []pdata.Traces) {
+	for _, policy := range cfsp.getPolicies() {
+		if observer, ok := policy.Evaluator.(sampling.SpanObserver); ok {
+			observer.ObserveSpans(traceBatches)
+		}
+	}
+}
+
+// releaseBatches takes out the batches received for the trace and updates the memory usage accordingly
+func (cfsp *cascadingFilterSpanProcessor) releaseBatches(trace *sampling.TraceData) []pdata.Traces {
+	trace.Lock()
+	traceBatches := trace.ReceivedBatches
+	trace.ReceivedBatches = nil
+	size := trace.SizeBytes
+	trace.SizeBytes = 0
+	trace.Unlock()
+
+	if size > 0 {
+		atomic.AddInt64(&cfsp.bufferedBytes, -size)
+	}
+	return traceBatches
 }
 
 // func (cfsp *cascadingFilterSpanProcessor) GetCapabilities() component.ProcessorCapabilities {
@@ -563,16 +1364,72 @@ func (cfsp *cascadingFilterSpanProcessor) Capabilities() consumer.Capabilities {
 }
 
 // Start is invoked during service startup.
-func (cfsp *cascadingFilterSpanProcessor) Start(context.Context, component.Host) error {
+func (cfsp *cascadingFilterSpanProcessor) Start(ctx context.Context, host component.Host) error {
+	if cfsp.spillover != nil {
+		if err := cfsp.spillover.start(ctx, host); err != nil {
+			return err
+		}
+	}
+	if cfsp.statsTicker != nil {
+		cfsp.statsTicker.Start(cfsp.serviceStats.interval)
+	}
+	if cfsp.reloadTicker != nil {
+		cfsp.reloadTicker.Start(cfsp.policiesReloader.checkInterval)
+	}
 	return nil
 }
 
+// flushDecisions makes the decisions on all the traces waiting for them, without waiting for the late spans,
+// so the traces are not lost on shutdown. The traces which were not decided before the shutdown deadline
+// are reported as left behind.
+func (cfsp *cascadingFilterSpanProcessor) flushDecisions(ctx context.Context) {
+	cfsp.policyTicker.Stop()
+
+	ctx, cancel := cfsp.drain.Context(ctx)
+	defer cancel()
+
+	left := 0
+	// The current batch is moved to the end of the pipeline with each take, so all the batches are taken
+	for i := uint64(0); i <= cfsp.numDecisionBatches; i++ {
+		batch, _ := cfsp.decisionBatcher.CloseCurrentAndTakeFirstBatch()
+		if ctx.Err() != nil {
+			left += len(batch)
+			continue
+		}
+		cfsp.decideBatch(batch)
+	}
+	cfsp.drain.Report("processor/"+cfsp.id, left, "traces")
+}
+
 // Shutdown is invoked during service shutdown.
-func (cfsp *cascadingFilterSpanProcessor) Shutdown(context.Context) error {
+func (cfsp *cascadingFilterSpanProcessor) Shutdown(ctx context.Context) error {
+	cfsp.flushDecisions(ctx)
+	if cfsp.reloadTicker != nil {
+		cfsp.reloadTicker.Stop()
+	}
+	if cfsp.statsTicker != nil {
+		cfsp.statsTicker.Stop()
+		cfsp.serviceStats.flush()
+	}
+	if cfsp.spillover != nil {
+		// Spilled data is not recovered after restart, so it must not be left in the storage
+		cfsp.idToTrace.Range(func(key, value interface{}) bool {
+			trace := value.(*sampling.TraceData)
+			trace.Lock()
+			if err := cfsp.spillover.discard(ctx, key.(traceKey), trace); err != nil {
+				cfsp.logger.Error("Removing spilled trace from the storage failed", zap.Error(err))
+			}
+			trace.Unlock()
+			return true
+		})
+		return cfsp.spillover.shutdown(ctx)
+	}
 	return nil
 }
 
-func (cfsp *cascadingFilterSpanProcessor) dropTrace(traceID traceKey, deletionTime time.Time) {
+// dropTrace removes the trace from memory. In the dry run mode, returns the trace dropped before the decision
+// for sending.
+func (cfsp *cascadingFilterSpanProcessor) dropTrace(traceID traceKey, deletionTime time.Time) pendingSends {
 	var trace *sampling.TraceData
 	if d, ok := cfsp.idToTrace.Load(traceID); ok {
 		trace = d.(*sampling.TraceData)
@@ -582,10 +1439,31 @@ func (cfsp *cascadingFilterSpanProcessor) dropTrace(traceID traceKey, deletionTi
 	}
 	if trace == nil {
 		cfsp.logger.Error("Attempt to delete traceID not on table")
-		return
+		return nil
+	}
+	if cfsp.dryRun && trace.FinalDecision == sampling.Unspecified {
+		// The trace is forwarded below, so the spilled spans are needed as well
+		cfsp.restoreSpilled(traceID, trace)
+	}
+	if cfsp.spillover != nil {
+		trace.Lock()
+		if err := cfsp.spillover.discard(cfsp.ctx, traceID, trace); err != nil {
+			cfsp.logger.Error("Removing spilled trace from the storage failed", zap.Error(err))
+		}
+		trace.Unlock()
+	}
+	traceBatches := cfsp.releaseBatches(trace)
+	sends := pendingSends{}
+	if trace.FinalDecision == sampling.Unspecified {
+		// The trace is dropped before any decision was made
+		cfsp.recordServiceDecision(trace, sampling.Dropped)
+		if allSpans, ok := cfsp.droppedTraceBatch(traceBatches); ok {
+			sends.add(allSpans, "Error sending trace dropped before the decision in dry run")
+		}
 	}
 
 	stats.Record(cfsp.ctx, statTraceRemovalAgeSec.M(int64(deletionTime.Sub(trace.ArrivalTime)/time.Second)))
+	return sends
 }
 
 func prepareTraceBatch(rss pdata.ResourceSpans, spans []*pdata.Span) pdata.Traces {
@@ -627,7 +1505,10 @@ func (pt *policyTicker) OnTick() {
 	pt.onTick()
 }
 func (pt *policyTicker) Stop() {
-	pt.ticker.Stop()
+	// Shutdown might be called without Start
+	if pt.ticker != nil {
+		pt.ticker.Stop()
+	}
 }
 
 var _ tTicker = (*policyTicker)(nil)
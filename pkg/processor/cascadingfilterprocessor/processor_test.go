@@ -17,8 +17,10 @@ package cascadingfilterprocessor
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,12 +34,15 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/idbatcher"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain"
 )
 
 const (
 	defaultTestDecisionWait = 30 * time.Second
 )
 
+var testDrain = drain.NewCoordinator(drain.DefaultTimeout)
+
 //nolint:unused
 var testPolicy = []config.PolicyCfg{{
 	Name:           "test-policy",
@@ -52,7 +57,7 @@ func TestSequentialTraceArrival(t *testing.T) {
 		ExpectedNewTracesPerSec: 64,
 		PolicyCfgs:              testPolicy,
 	}
-	sp, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg)
+	sp, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg, testDrain)
 	require.NoError(t, err)
 	tsp := sp.(*cascadingFilterSpanProcessor)
 	for _, batch := range batches {
@@ -77,7 +82,7 @@ func TestConcurrentTraceArrival(t *testing.T) {
 		ExpectedNewTracesPerSec: 64,
 		PolicyCfgs:              testPolicy,
 	}
-	sp, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg)
+	sp, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg, testDrain)
 	require.NoError(t, err)
 	tsp := sp.(*cascadingFilterSpanProcessor)
 	for _, batch := range batches {
@@ -116,7 +121,7 @@ func TestSequentialTraceMapSize(t *testing.T) {
 		ExpectedNewTracesPerSec: 64,
 		PolicyCfgs:              testPolicy,
 	}
-	sp, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg)
+	sp, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg, testDrain)
 	require.NoError(t, err)
 	tsp := sp.(*cascadingFilterSpanProcessor)
 	for _, batch := range batches {
@@ -142,7 +147,7 @@ func TestConcurrentTraceMapSize(t *testing.T) {
 		ExpectedNewTracesPerSec: 64,
 		PolicyCfgs:              testPolicy,
 	}
-	sp, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg)
+	sp, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg, testDrain)
 	require.NoError(t, err)
 	tsp := sp.(*cascadingFilterSpanProcessor)
 	for _, batch := range batches {
@@ -167,6 +172,102 @@ func TestConcurrentTraceMapSize(t *testing.T) {
 	require.Equal(t, maxSize, cnt, "Incorrect traces count on idToTrace")
 }
 
+func TestMemoryLimitEviction(t *testing.T) {
+	traceIDs := make([]pdata.TraceID, 5)
+	for i := range traceIDs {
+		traceIDs[i] = pdata.NewTraceID([16]byte{byte(i + 1)})
+	}
+
+	for _, mode := range []string{memoryLimitEvictionDecideNow, memoryLimitEvictionDrop} {
+		t.Run(mode, func(t *testing.T) {
+			msp := new(consumertest.TracesSink)
+			cfg := config.Config{
+				DecisionWait:            defaultTestDecisionWait,
+				NumTraces:               100,
+				ExpectedNewTracesPerSec: 64,
+				SpansPerSecond:          1000,
+				PolicyCfgs:              testPolicy,
+				MaxMemoryMiB:            1,
+				MemoryLimitEviction:     mode,
+			}
+			sp, err := newTraceProcessor(zap.NewNop(), msp, cfg, testDrain)
+			require.NoError(t, err)
+			tsp := sp.(*cascadingFilterSpanProcessor)
+
+			require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(traceIDs[0])))
+			traceSize := atomic.LoadInt64(&tsp.bufferedBytes)
+			require.Greater(t, traceSize, int64(0))
+
+			// Only three traces fit in memory, so the two oldest ones must be evicted
+			tsp.maxBufferedBytes = 3 * traceSize
+			for _, traceID := range traceIDs[1:] {
+				require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(traceID)))
+			}
+
+			require.Equal(t, 3*traceSize, atomic.LoadInt64(&tsp.bufferedBytes))
+			for i, traceID := range traceIDs {
+				_, ok := tsp.idToTrace.Load(traceKey(traceID.Bytes()))
+				require.Equal(t, i >= 2, ok, "unexpected presence of trace %d on map", i)
+			}
+
+			if mode == memoryLimitEvictionDecideNow {
+				require.Equal(t, 2, msp.SpanCount())
+			} else {
+				require.Equal(t, 0, msp.SpanCount())
+			}
+		})
+	}
+}
+
+func TestMemoryReleasedOnDecision(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := &cascadingFilterSpanProcessor{
+		ctx:               context.Background(),
+		nextConsumer:      msp,
+		maxNumTraces:      10,
+		logger:            zap.NewNop(),
+		decisionBatcher:   newSyncIDBatcher(1),
+		policies:          []*Policy{{Name: "mock-policy", Evaluator: &mockPolicyEvaluator{NextDecision: sampling.Sampled}, ctx: context.TODO()}},
+		deleteChan:        make(chan traceKey, 10),
+		policyTicker:      &manualTTicker{},
+		maxSpansPerSecond: 10000,
+		maxBufferedBytes:  bytesInMiB,
+	}
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+	require.Greater(t, atomic.LoadInt64(&tsp.bufferedBytes), int64(0))
+
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	require.Equal(t, 1, msp.SpanCount())
+	require.Equal(t, int64(0), atomic.LoadInt64(&tsp.bufferedBytes))
+}
+
+func TestInvalidMemoryLimitEviction(t *testing.T) {
+	cfg := config.Config{
+		DecisionWait:        defaultTestDecisionWait,
+		NumTraces:           100,
+		MemoryLimitEviction: "unknown",
+	}
+	_, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg, testDrain)
+	require.Error(t, err)
+}
+
+func TestInvalidDecisionTickInterval(t *testing.T) {
+	cfg := config.Config{
+		DecisionWait:         defaultTestDecisionWait,
+		DecisionTickInterval: 2 * defaultTestDecisionWait,
+		NumTraces:            100,
+	}
+	_, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg, testDrain)
+	require.Error(t, err)
+
+	cfg.DecisionTickInterval = 0
+	cfg.DecisionBatchSize = -1
+	_, err = newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg, testDrain)
+	require.Error(t, err)
+}
+
 func TestSamplingPolicyTypicalPath(t *testing.T) {
 	const maxSize = 100
 	const decisionWaitSeconds = 5
@@ -573,3 +674,389 @@ func (s *syncIDBatcher) CloseCurrentAndTakeFirstBatch() (idbatcher.Batch, bool)
 
 func (s *syncIDBatcher) Stop() {
 }
+
+func TestSamplingHintsBypassPolicies(t *testing.T) {
+	hints, err := sampling.NewSamplingHints(&config.SamplingHintsCfg{})
+	require.NoError(t, err)
+
+	msp := new(consumertest.TracesSink)
+	tsp := &cascadingFilterSpanProcessor{
+		ctx:             context.Background(),
+		nextConsumer:    msp,
+		maxNumTraces:    10,
+		logger:          zap.NewNop(),
+		decisionBatcher: newSyncIDBatcher(1),
+		policies:        []*Policy{{Name: "mock-policy", Evaluator: &mockPolicyEvaluator{NextDecision: sampling.Sampled}, ctx: context.TODO()}},
+		deleteChan:      make(chan traceKey, 10),
+		policyTicker:    &manualTTicker{},
+		// No budget left for traces selected by the policies
+		maxSpansPerSecond: 0,
+		samplingHints:     hints,
+	}
+
+	keptTraces := simpleTracesWithID(pdata.NewTraceID([16]byte{1}))
+	keptTraces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Attributes().InsertInt("sampling.priority", 1)
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), keptTraces))
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(pdata.NewTraceID([16]byte{2}))))
+
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+
+	require.Equal(t, 1, msp.SpanCount())
+	require.Equal(t, pdata.NewTraceID([16]byte{1}), msp.AllTraces()[0].ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).TraceID())
+
+	d, ok := tsp.idToTrace.Load(traceKey(pdata.NewTraceID([16]byte{1}).Bytes()))
+	require.True(t, ok)
+	assert.Equal(t, []sampling.Decision{sampling.Sampled}, d.(*sampling.TraceData).Decisions)
+}
+
+func TestRootSpanBudget(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := &cascadingFilterSpanProcessor{
+		ctx:               context.Background(),
+		nextConsumer:      msp,
+		maxNumTraces:      10,
+		logger:            zap.NewNop(),
+		decisionBatcher:   newSyncIDBatcher(1),
+		policies:          []*Policy{{Name: "mock-policy", Evaluator: &mockPolicyEvaluator{NextDecision: sampling.Sampled}, ctx: context.TODO()}},
+		deleteChan:        make(chan traceKey, 10),
+		policyTicker:      &manualTTicker{},
+		maxSpansPerSecond: 10000,
+		rootSpanBudget:    sampling.NewRootSpanBudget(&config.RootSpanBudgetCfg{RootSpans: map[string]int64{"GET /search": 1}}),
+	}
+
+	newTrace := func(id byte, rootSpanName string) pdata.Traces {
+		traces := simpleTracesWithID(pdata.NewTraceID([16]byte{id}))
+		root := traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0)
+		root.SetName(rootSpanName)
+		child := traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().AppendEmpty()
+		child.SetTraceID(root.TraceID())
+		child.SetParentSpanID(pdata.NewSpanID([8]byte{1}))
+		child.SetName("db query")
+		return traces
+	}
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), newTrace(1, "GET /search")))
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), newTrace(2, "GET /search")))
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), newTrace(3, "GET /cart")))
+
+	d, ok := tsp.idToTrace.Load(traceKey(pdata.NewTraceID([16]byte{1}).Bytes()))
+	require.True(t, ok)
+	assert.Equal(t, "GET /search", d.(*sampling.TraceData).RootSpanName)
+
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+
+	// Only one trace of "GET /search" fits in the budget
+	assert.Equal(t, 4, msp.SpanCount())
+}
+
+func TestRecordMatchingPolicies(t *testing.T) {
+	policyCfgs := []config.PolicyCfg{
+		{Name: "everything", SpansPerSecond: 1000},
+		{Name: "everything-no-budget", SpansPerSecond: 0},
+		{Name: "errors", SpansPerSecond: 1000, NumericAttributeCfg: &config.NumericAttributeCfg{Key: "http.status_code", MinValue: 500, MaxValue: 599}},
+	}
+	policies, err := newRulePolicies(context.Background(), zap.NewNop(), policyCfgs)
+	require.NoError(t, err)
+	enableMatchReporting(policies)
+
+	msp := new(consumertest.TracesSink)
+	tsp := &cascadingFilterSpanProcessor{
+		ctx:               context.Background(),
+		nextConsumer:      msp,
+		maxNumTraces:      10,
+		logger:            zap.NewNop(),
+		decisionBatcher:   newSyncIDBatcher(1),
+		policies:          policies,
+		deleteChan:        make(chan traceKey, 10),
+		policyTicker:      &manualTTicker{},
+		maxSpansPerSecond: 10000,
+		recordMatching:    true,
+	}
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+
+	require.Equal(t, 1, msp.SpanCount())
+	attrs := msp.AllTraces()[0].ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Attributes()
+	matching, ok := attrs.Get(AttributeMatchingPolicies)
+	require.True(t, ok)
+	require.Equal(t, pdata.AttributeValueTypeArray, matching.Type())
+	var names []string
+	for i := 0; i < matching.ArrayVal().Len(); i++ {
+		names = append(names, matching.ArrayVal().At(i).StringVal())
+	}
+	assert.Equal(t, []string{"everything", "everything-no-budget"}, names)
+}
+
+// traceIDPolicyEvaluator returns the decision set for the first byte of the trace id
+type traceIDPolicyEvaluator struct {
+	decisions map[byte]sampling.Decision
+}
+
+func (e *traceIDPolicyEvaluator) OnLateArrivingSpans(sampling.Decision, []*pdata.Span) error {
+	return nil
+}
+
+func (e *traceIDPolicyEvaluator) Evaluate(traceID pdata.TraceID, _ *sampling.TraceData) sampling.Decision {
+	return e.decisions[traceID.Bytes()[0]]
+}
+
+func TestDecisionsInChunks(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	evaluator := &traceIDPolicyEvaluator{decisions: map[byte]sampling.Decision{
+		1: sampling.SecondChance,
+		2: sampling.Sampled,
+		3: sampling.Sampled,
+		4: sampling.Sampled,
+	}}
+	tsp := &cascadingFilterSpanProcessor{
+		ctx:               context.Background(),
+		nextConsumer:      msp,
+		maxNumTraces:      10,
+		logger:            zap.NewNop(),
+		decisionBatcher:   newSyncIDBatcher(1),
+		policies:          []*Policy{{Name: "by-trace-id", Evaluator: evaluator, ctx: context.TODO()}},
+		deleteChan:        make(chan traceKey, 10),
+		policyTicker:      &manualTTicker{},
+		maxSpansPerSecond: 3,
+		decisionBatchSize: 1,
+	}
+
+	for id := byte(1); id <= 4; id++ {
+		require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(pdata.NewTraceID([16]byte{id}))))
+	}
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+
+	// The second chance trace gets only what's left after all chunks were decided
+	require.Equal(t, 3, msp.SpanCount())
+	for _, traces := range msp.AllTraces() {
+		traceID := traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).TraceID()
+		assert.NotEqual(t, byte(1), traceID.Bytes()[0])
+	}
+}
+
+// generateBenchmarkTraces creates numTraces traces with spansPerTrace spans each, split into batches of 1000 traces
+func generateBenchmarkTraces(numTraces int, spansPerTrace int) []pdata.Traces {
+	var batches []pdata.Traces
+	for i := 0; i < numTraces; i += 1000 {
+		traces := pdata.NewTraces()
+		rs := traces.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().InsertString("service.name", "benchmark")
+		spans := rs.InstrumentationLibrarySpans().AppendEmpty().Spans()
+		for j := i; j < i+1000 && j < numTraces; j++ {
+			traceID := bigendianconverter.UInt64ToTraceID(1, uint64(j+1))
+			for k := 0; k < spansPerTrace; k++ {
+				span := spans.AppendEmpty()
+				span.SetTraceID(traceID)
+				span.SetSpanID(bigendianconverter.UInt64ToSpanID(uint64(k + 1)))
+				span.SetName("operation")
+				if k > 0 {
+					span.SetParentSpanID(bigendianconverter.UInt64ToSpanID(1))
+				}
+				if j%100 == 0 {
+					span.Attributes().InsertInt("http.status_code", 500)
+				}
+			}
+		}
+		batches = append(batches, traces)
+	}
+	return batches
+}
+
+func newBenchmarkProcessor(b *testing.B, numTraces int, decisionBatchSize int) *cascadingFilterSpanProcessor {
+	cfg := config.Config{
+		DecisionWait:      defaultTestDecisionWait,
+		NumTraces:         uint64(2 * numTraces),
+		SpansPerSecond:    100000,
+		DecisionBatchSize: decisionBatchSize,
+		PolicyCfgs: []config.PolicyCfg{
+			{
+				Name:                "errors",
+				SpansPerSecond:      10000,
+				NumericAttributeCfg: &config.NumericAttributeCfg{Key: "http.status_code", MinValue: 500, MaxValue: 599},
+			},
+			{
+				Name:           "everything_else",
+				SpansPerSecond: -1,
+			},
+		},
+	}
+	tsp, err := newCascadingFilterSpanProcessor(zap.NewNop(), consumertest.NewNop(), cfg)
+	require.NoError(b, err)
+	tsp.decisionBatcher = newSyncIDBatcher(1)
+	tsp.policyTicker = &manualTTicker{}
+	return tsp
+}
+
+// BenchmarkSamplingPolicyOnTick measures deciding 1M buffered spans (100k traces with 10 spans each) in a single tick
+func BenchmarkSamplingPolicyOnTick(b *testing.B) {
+	const numTraces = 100000
+	batches := generateBenchmarkTraces(numTraces, 10)
+
+	for _, decisionBatchSize := range []int{100, 1000, numTraces} {
+		b.Run(fmt.Sprintf("decision_batch_size=%d", decisionBatchSize), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				tsp := newBenchmarkProcessor(b, numTraces, decisionBatchSize)
+				for _, batch := range batches {
+					require.NoError(b, tsp.ConsumeTraces(context.Background(), batch))
+				}
+				tsp.samplingPolicyOnTick()
+				b.StartTimer()
+
+				tsp.samplingPolicyOnTick()
+			}
+		})
+	}
+}
+
+// BenchmarkConsumeTraces measures buffering 1M spans (100k traces with 10 spans each)
+func BenchmarkConsumeTraces(b *testing.B) {
+	const numTraces = 100000
+	batches := generateBenchmarkTraces(numTraces, 10)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tsp := newBenchmarkProcessor(b, numTraces, 0)
+		b.StartTimer()
+
+		for _, batch := range batches {
+			require.NoError(b, tsp.ConsumeTraces(context.Background(), batch))
+		}
+	}
+}
+
+func newShutdownTestProcessor(msp *consumertest.TracesSink) *cascadingFilterSpanProcessor {
+	return &cascadingFilterSpanProcessor{
+		ctx:                context.Background(),
+		id:                 typeStr,
+		nextConsumer:       msp,
+		maxNumTraces:       10,
+		logger:             zap.NewNop(),
+		decisionBatcher:    newSyncIDBatcher(3),
+		numDecisionBatches: 3,
+		policies:           []*Policy{{Name: "mock-policy", Evaluator: &mockPolicyEvaluator{NextDecision: sampling.Sampled}, ctx: context.TODO()}},
+		deleteChan:         make(chan traceKey, 10),
+		policyTicker:       &manualTTicker{},
+		maxSpansPerSecond:  10000,
+		drain:              testDrain,
+	}
+}
+
+func TestShutdownFlushesPendingDecisions(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newShutdownTestProcessor(msp)
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+	// The trace waits in the decision pipeline
+	tsp.samplingPolicyOnTick()
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+	require.Equal(t, 0, msp.SpanCount())
+
+	require.NoError(t, tsp.Shutdown(context.Background()))
+	assert.Equal(t, 2, msp.SpanCount())
+}
+
+func TestShutdownAfterDeadlineLeavesTraces(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newShutdownTestProcessor(msp)
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, tsp.Shutdown(ctx))
+	assert.Equal(t, 0, msp.SpanCount())
+}
+
+type observingPolicyEvaluator struct {
+	mockPolicyEvaluator
+	ObservedTraces int
+}
+
+var _ sampling.SpanObserver = (*observingPolicyEvaluator)(nil)
+
+func (m *observingPolicyEvaluator) ObserveSpans([]pdata.Traces) {
+	m.ObservedTraces++
+}
+
+func TestDecidedTraceObservedOnce(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newShutdownTestProcessor(msp)
+	evaluator := &observingPolicyEvaluator{mockPolicyEvaluator: mockPolicyEvaluator{NextDecision: sampling.Sampled}}
+	tsp.policies = []*Policy{{Name: "observing-policy", Evaluator: evaluator, ctx: context.TODO()}}
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+	tsp.samplingPolicyOnTick()
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+
+	require.NoError(t, tsp.Shutdown(context.Background()))
+	assert.Equal(t, 2, msp.SpanCount())
+	assert.Equal(t, 1, evaluator.ObservedTraces)
+}
+
+// blockingConsumer blocks the first call until released, the following calls pass through to the sink
+type blockingConsumer struct {
+	consumertest.TracesSink
+	entered chan struct{}
+	release chan struct{}
+	calls   int32
+}
+
+func newBlockingConsumer() *blockingConsumer {
+	return &blockingConsumer{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (c *blockingConsumer) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	if atomic.AddInt32(&c.calls, 1) == 1 {
+		close(c.entered)
+		<-c.release
+	}
+	return c.TracesSink.ConsumeTraces(ctx, td)
+}
+
+// requireCompletes fails the test if fn doesn't return in time
+func requireCompletes(t *testing.T, fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "blocked by the pending send to the next consumer")
+	}
+}
+
+func TestBlockedConsumerOnTickDoesNotBlockIngestion(t *testing.T) {
+	consumer := newBlockingConsumer()
+	tsp := newAlwaysKeepTestProcessor(t, &consumer.TracesSink)
+	tsp.nextConsumer = consumer
+	tsp.maxSpansPerSecond = 1000
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), tracesWithStatusCode(pdata.NewTraceID([16]byte{1}), 200)))
+	ticked := make(chan struct{})
+	go func() {
+		defer close(ticked)
+		tsp.samplingPolicyOnTick()
+		tsp.samplingPolicyOnTick()
+	}()
+	<-consumer.entered
+
+	// The early release takes decisionLock, which must not be held while the tick sends the sampled trace
+	requireCompletes(t, func() {
+		assert.NoError(t, tsp.ConsumeTraces(context.Background(), tracesWithStatusCode(pdata.NewTraceID([16]byte{2}), 503)))
+	})
+	assert.Equal(t, 1, consumer.SpanCount())
+
+	close(consumer.release)
+	<-ticked
+	assert.Equal(t, 2, consumer.SpanCount())
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"encoding/binary"
+	"math"
+	"sync/atomic"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// AdaptiveProbabilisticFilter samples traces with a probability which might be changed at runtime.
+// The decision is based on the trace id, so it's consistent across collectors using the same probability.
+type AdaptiveProbabilisticFilter struct {
+	// threshold is the probability scaled to the uint64 range
+	threshold uint64
+}
+
+var _ PolicyEvaluator = (*AdaptiveProbabilisticFilter)(nil)
+
+// NewAdaptiveProbabilisticFilter creates a filter sampling traces with given initial probability
+func NewAdaptiveProbabilisticFilter(probability float64) *AdaptiveProbabilisticFilter {
+	f := &AdaptiveProbabilisticFilter{}
+	f.SetProbability(probability)
+	return f
+}
+
+// SetProbability changes the sampling probability, the value is clamped to [0, 1] range
+func (f *AdaptiveProbabilisticFilter) SetProbability(probability float64) {
+	var threshold uint64
+	switch {
+	case probability <= 0:
+		threshold = 0
+	case probability >= 1:
+		threshold = math.MaxUint64
+	default:
+		threshold = uint64(probability * math.MaxUint64)
+	}
+	atomic.StoreUint64(&f.threshold, threshold)
+}
+
+// Probability returns the current sampling probability
+func (f *AdaptiveProbabilisticFilter) Probability() float64 {
+	return float64(atomic.LoadUint64(&f.threshold)) / math.MaxUint64
+}
+
+// OnLateArrivingSpans notifies the evaluator that the given list of spans arrived
+// after the sampling decision was already taken for the trace.
+func (f *AdaptiveProbabilisticFilter) OnLateArrivingSpans(Decision, []*pdata.Span) error {
+	return nil
+}
+
+// Evaluate looks at the trace data and returns a corresponding SamplingDecision.
+func (f *AdaptiveProbabilisticFilter) Evaluate(traceID pdata.TraceID, _ *TraceData) Decision {
+	threshold := atomic.LoadUint64(&f.threshold)
+	if threshold == 0 {
+		return NotSampled
+	}
+
+	// The lower half of the trace id is random for both W3C and X-Ray generated ids
+	id := traceID.Bytes()
+	if threshold == math.MaxUint64 || binary.BigEndian.Uint64(id[8:]) < threshold {
+		return Sampled
+	}
+	return NotSampled
+}
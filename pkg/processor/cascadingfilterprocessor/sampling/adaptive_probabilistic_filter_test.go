@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func sampledFraction(filter *AdaptiveProbabilisticFilter, numTraces int) float64 {
+	r := rand.New(rand.NewSource(1))
+	sampled := 0
+	for i := 0; i < numTraces; i++ {
+		var id [16]byte
+		r.Read(id[:])
+		if filter.Evaluate(pdata.NewTraceID(id), nil) == Sampled {
+			sampled++
+		}
+	}
+	return float64(sampled) / float64(numTraces)
+}
+
+func TestAdaptiveProbabilisticFilter(t *testing.T) {
+	filter := NewAdaptiveProbabilisticFilter(0.25)
+	assert.InDelta(t, 0.25, filter.Probability(), 0.0001)
+	assert.InDelta(t, 0.25, sampledFraction(filter, 10000), 0.02)
+
+	filter.SetProbability(0)
+	assert.Equal(t, 0.0, sampledFraction(filter, 1000))
+
+	filter.SetProbability(1.5)
+	assert.Equal(t, 1.0, filter.Probability())
+	assert.Equal(t, 1.0, sampledFraction(filter, 1000))
+}
+
+func TestAdaptiveProbabilisticFilterIsConsistent(t *testing.T) {
+	filter := NewAdaptiveProbabilisticFilter(0.5)
+	traceID := pdata.NewTraceID([16]byte{0, 0, 0, 0, 0, 0, 0, 0, 0x01})
+	assert.Equal(t, Sampled, filter.Evaluate(traceID, nil))
+
+	// Lowering the probability never selects traces which were not selected before
+	filter.SetProbability(0.01)
+	assert.Equal(t, NotSampled, filter.Evaluate(pdata.NewTraceID([16]byte{0, 0, 0, 0, 0, 0, 0, 0, 0x80}), nil))
+	assert.Equal(t, Sampled, filter.Evaluate(traceID, nil))
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+const (
+	defaultLatencyMinSamples    = 100
+	defaultLatencyWindow        = 5 * time.Minute
+	defaultLatencyMaxOperations = 1000
+
+	// Buckets grow by 10% starting from 1µs, which covers durations up to ~2h
+	latencyBucketGrowth = 1.1
+	latencyMinDuration  = float64(time.Microsecond)
+	latencyNumBuckets   = 240
+)
+
+var latencyBucketGrowthLog = math.Log(latencyBucketGrowth)
+
+type operationKey struct {
+	service, name string
+}
+
+// latencyHistogram keeps the (decaying) counts of durations in log-scale buckets
+type latencyHistogram struct {
+	counts [latencyNumBuckets]float64
+	total  float64
+}
+
+func latencyBucket(duration time.Duration) int {
+	if float64(duration) < latencyMinDuration {
+		return 0
+	}
+	bucket := 1 + int(math.Log(float64(duration)/latencyMinDuration)/latencyBucketGrowthLog)
+	if bucket >= latencyNumBuckets {
+		return latencyNumBuckets - 1
+	}
+	return bucket
+}
+
+// quantileBucket returns the bucket containing the given quantile (0-1) of the observed durations
+func (h *latencyHistogram) quantileBucket(quantile float64) int {
+	target := quantile * h.total
+	cumulative := 0.0
+	for bucket, count := range h.counts {
+		cumulative += count
+		if cumulative >= target {
+			return bucket
+		}
+	}
+	return latencyNumBuckets - 1
+}
+
+func (h *latencyHistogram) scale(factor float64) {
+	for i := range h.counts {
+		h.counts[i] *= factor
+	}
+	h.total *= factor
+}
+
+// latencyPercentileFilter tracks the span durations per operation and finds the spans slower
+// than the configured percentile. It's not safe for concurrent use.
+type latencyPercentileFilter struct {
+	quantile      float64
+	minSamples    float64
+	window        time.Duration
+	maxOperations int
+
+	histograms map[operationKey]*latencyHistogram
+	lastDecay  time.Time
+}
+
+func createLatencyPercentileFilter(cfg *config.LatencyPercentileCfg) (*latencyPercentileFilter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	if cfg.Percentile <= 0 || cfg.Percentile >= 100 {
+		return nil, errors.New("latency percentile must be greater than 0 and less than 100")
+	}
+
+	filter := &latencyPercentileFilter{
+		quantile:      cfg.Percentile / 100,
+		minSamples:    defaultLatencyMinSamples,
+		window:        defaultLatencyWindow,
+		maxOperations: defaultLatencyMaxOperations,
+		histograms:    make(map[operationKey]*latencyHistogram),
+		lastDecay:     time.Now(),
+	}
+	if cfg.MinSamples > 0 {
+		filter.minSamples = float64(cfg.MinSamples)
+	}
+	if cfg.Window > 0 {
+		filter.window = cfg.Window
+	}
+	if cfg.MaxOperations > 0 {
+		filter.maxOperations = cfg.MaxOperations
+	}
+	return filter, nil
+}
+
+// decayIfNeeded halves the counts once per window elapsed since the last decay, so older durations weigh less
+func (f *latencyPercentileFilter) decayIfNeeded(now time.Time) {
+	windows := now.Sub(f.lastDecay) / f.window
+	if windows <= 0 {
+		return
+	}
+	factor := math.Pow(0.5, float64(windows))
+	for _, h := range f.histograms {
+		h.scale(factor)
+	}
+	f.lastDecay = f.lastDecay.Add(windows * f.window)
+}
+
+// isSlow returns true if the span duration is above the percentile of the operation durations observed so far
+func (f *latencyPercentileFilter) isSlow(service string, name string, duration time.Duration) bool {
+	h, ok := f.histograms[operationKey{service: service, name: name}]
+	if !ok {
+		return false
+	}
+	return h.total >= f.minSamples && latencyBucket(duration) > h.quantileBucket(f.quantile)
+}
+
+// observe records the span duration of the operation
+func (f *latencyPercentileFilter) observe(service string, name string, duration time.Duration) {
+	key := operationKey{service: service, name: name}
+	h, ok := f.histograms[key]
+	if !ok {
+		if len(f.histograms) >= f.maxOperations {
+			return
+		}
+		h = &latencyHistogram{}
+		f.histograms[key] = h
+	}
+
+	h.counts[latencyBucket(duration)]++
+	h.total++
+}
@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+func newLatencyPercentilePolicy(t *testing.T, cfg *config.LatencyPercentileCfg) *policyEvaluator {
+	filter, err := createLatencyPercentileFilter(cfg)
+	require.NoError(t, err)
+	return &policyEvaluator{
+		logger:            zap.NewNop(),
+		latencyPercentile: filter,
+		maxSpansPerSecond: math.MaxInt64,
+	}
+}
+
+func newTraceServiceLatency(serviceName string, operationName string, duration time.Duration) *TraceData {
+	trace := newTraceAttrs(operationName, duration, 1)
+	trace.ReceivedBatches[0].ResourceSpans().At(0).Resource().Attributes().InsertString("service.name", serviceName)
+	return trace
+}
+
+// evaluateAndObserve evaluates the trace and then observes it, as done when the final decision is made
+func evaluateAndObserve(t *testing.T, filter *policyEvaluator, trace *TraceData, expected Decision) {
+	evaluate(t, *filter, trace, expected)
+	filter.ObserveSpans(trace.ReceivedBatches)
+}
+
+func TestLatencyPercentileFilter(t *testing.T) {
+	filter := newLatencyPercentilePolicy(t, &config.LatencyPercentileCfg{Percentile: 90, MinSamples: 10})
+
+	// Not enough samples yet
+	evaluateAndObserve(t, filter, newTraceServiceLatency("api", "GET /slow", 10*time.Second), NotSampled)
+
+	for i := 0; i < 100; i++ {
+		evaluateAndObserve(t, filter, newTraceServiceLatency("api", "GET /fast", 10*time.Millisecond), NotSampled)
+		evaluateAndObserve(t, filter, newTraceServiceLatency("api", "GET /slow", 2*time.Second), NotSampled)
+	}
+
+	// Each operation is compared with its own durations
+	evaluateAndObserve(t, filter, newTraceServiceLatency("api", "GET /fast", 100*time.Millisecond), Sampled)
+	evaluateAndObserve(t, filter, newTraceServiceLatency("api", "GET /slow", 100*time.Millisecond), NotSampled)
+	evaluateAndObserve(t, filter, newTraceServiceLatency("api", "GET /slow", 5*time.Second), Sampled)
+
+	// Same span name in another service is a different operation
+	evaluateAndObserve(t, filter, newTraceServiceLatency("web", "GET /fast", 100*time.Millisecond), NotSampled)
+}
+
+func TestLatencyPercentileFilterMaxOperations(t *testing.T) {
+	filter := newLatencyPercentilePolicy(t, &config.LatencyPercentileCfg{Percentile: 50, MinSamples: 1, MaxOperations: 1})
+
+	evaluateAndObserve(t, filter, newTraceServiceLatency("api", "first", time.Millisecond), NotSampled)
+	evaluateAndObserve(t, filter, newTraceServiceLatency("api", "second", time.Millisecond), NotSampled)
+	evaluateAndObserve(t, filter, newTraceServiceLatency("api", "second", time.Second), NotSampled)
+	evaluateAndObserve(t, filter, newTraceServiceLatency("api", "first", time.Second), Sampled)
+	assert.Len(t, filter.latencyPercentile.histograms, 1)
+}
+
+func TestLatencyPercentileFilterDecay(t *testing.T) {
+	filter, err := createLatencyPercentileFilter(&config.LatencyPercentileCfg{Percentile: 50, MinSamples: 10, Window: time.Minute})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		filter.observe("api", "op", time.Millisecond)
+	}
+	assert.True(t, filter.isSlow("api", "op", time.Second))
+	filter.observe("api", "op", time.Second)
+
+	lastDecay := filter.lastDecay
+	filter.decayIfNeeded(lastDecay.Add(2*time.Minute + 30*time.Second))
+	h := filter.histograms[operationKey{service: "api", name: "op"}]
+	assert.InDelta(t, 11.0/4, h.total, 1e-9)
+	// The part of the window which hasn't elapsed yet counts towards the next decay
+	assert.Equal(t, lastDecay.Add(2*time.Minute), filter.lastDecay)
+	// Not enough samples after the decay
+	assert.False(t, filter.isSlow("api", "op", time.Second))
+
+	// Many elapsed windows are applied at once
+	filter.decayIfNeeded(filter.lastDecay.Add(1000 * time.Hour))
+	assert.Equal(t, lastDecay.Add(2*time.Minute+1000*time.Hour), filter.lastDecay)
+	assert.InDelta(t, 0, h.total, 1e-9)
+}
+
+func TestLatencyPercentileFilterEvaluationDoesNotObserve(t *testing.T) {
+	filter := newLatencyPercentilePolicy(t, &config.LatencyPercentileCfg{Percentile: 90, MinSamples: 10})
+	trace := newTraceServiceLatency("api", "GET /", 10*time.Millisecond)
+
+	// The trace can be evaluated many times before the decision, e.g. by the always keep policies
+	for i := 0; i < 5; i++ {
+		evaluate(t, *filter, trace, NotSampled)
+	}
+	assert.Empty(t, filter.latencyPercentile.histograms)
+
+	filter.ObserveSpans(trace.ReceivedBatches)
+	h := filter.latencyPercentile.histograms[operationKey{service: "api", name: "GET /"}]
+	require.NotNil(t, h)
+	assert.Equal(t, 1.0, h.total)
+}
+
+func TestLatencyPercentileInvalidConfig(t *testing.T) {
+	for _, percentile := range []float64{0, -1, 100, 150} {
+		_, err := NewFilter(zap.NewNop(), &config.PolicyCfg{
+			Name:                 "latency",
+			LatencyPercentileCfg: &config.LatencyPercentileCfg{Percentile: percentile},
+		})
+		assert.Error(t, err)
+	}
+}
+
+func TestLatencyBucket(t *testing.T) {
+	assert.Equal(t, 0, latencyBucket(0))
+	assert.Equal(t, 0, latencyBucket(time.Nanosecond))
+	assert.Equal(t, 1, latencyBucket(time.Microsecond))
+	assert.Less(t, latencyBucket(time.Millisecond), latencyBucket(1100*time.Microsecond))
+	assert.Equal(t, latencyNumBuckets-1, latencyBucket(time.Duration(math.MaxInt64)))
+	assert.Less(t, latencyBucket(time.Hour), latencyNumBuckets-1)
+}
@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"fmt"
+)
+
+const (
+	// MatchTypeStrict selects values equal to any of the configured ones
+	MatchTypeStrict = "strict"
+	// MatchTypeContains selects values containing any of the configured ones
+	MatchTypeContains = "contains"
+)
+
+// stringMatcher is built once, when the policy is created, so matching a value
+// does not depend on the number of configured values.
+type stringMatcher interface {
+	Match(value string) bool
+}
+
+func newStringMatcher(matchType string, values []string) (stringMatcher, error) {
+	switch matchType {
+	case "", MatchTypeStrict:
+		return newHashSetMatcher(values), nil
+	case MatchTypeContains:
+		return newAhoCorasickMatcher(values), nil
+	}
+	return nil, fmt.Errorf("unknown match_type %q, must be one of: %s, %s", matchType, MatchTypeStrict, MatchTypeContains)
+}
+
+type hashSetMatcher map[string]struct{}
+
+func newHashSetMatcher(values []string) hashSetMatcher {
+	m := make(hashSetMatcher, len(values))
+	for _, value := range values {
+		if value != "" {
+			m[value] = struct{}{}
+		}
+	}
+	return m
+}
+
+func (m hashSetMatcher) Match(value string) bool {
+	_, ok := m[value]
+	return ok
+}
+
+// acNode is a state of the Aho-Corasick automaton
+type acNode struct {
+	children map[byte]int32
+	fail     int32
+	// terminal is set when any of the values ends in this state or in any state on its fail chain
+	terminal bool
+}
+
+// ahoCorasickMatcher finds whether any of the values is a substring of the matched string
+// in a single pass over it.
+type ahoCorasickMatcher struct {
+	nodes []acNode
+}
+
+func newAhoCorasickMatcher(values []string) *ahoCorasickMatcher {
+	m := &ahoCorasickMatcher{nodes: []acNode{{children: map[byte]int32{}}}}
+
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		state := int32(0)
+		for i := 0; i < len(value); i++ {
+			next, ok := m.nodes[state].children[value[i]]
+			if !ok {
+				next = int32(len(m.nodes))
+				m.nodes = append(m.nodes, acNode{children: map[byte]int32{}})
+				m.nodes[state].children[value[i]] = next
+			}
+			state = next
+		}
+		m.nodes[state].terminal = true
+	}
+
+	// Breadth-first traversal guarantees the fail links of shallower states are known
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].children {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for c, child := range m.nodes[state].children {
+			fail := m.nodes[state].fail
+			for {
+				if next, ok := m.nodes[fail].children[c]; ok {
+					m.nodes[child].fail = next
+					break
+				}
+				if fail == 0 {
+					m.nodes[child].fail = 0
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if m.nodes[m.nodes[child].fail].terminal {
+				m.nodes[child].terminal = true
+			}
+			queue = append(queue, child)
+		}
+	}
+
+	return m
+}
+
+func (m *ahoCorasickMatcher) Match(value string) bool {
+	state := int32(0)
+	for i := 0; i < len(value); i++ {
+		for {
+			if next, ok := m.nodes[state].children[value[i]]; ok {
+				state = next
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = m.nodes[state].fail
+		}
+		if m.nodes[state].terminal {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+func TestHashSetMatcher(t *testing.T) {
+	m, err := newStringMatcher(MatchTypeStrict, []string{"foo", "bar", ""})
+	require.NoError(t, err)
+
+	assert.True(t, m.Match("foo"))
+	assert.True(t, m.Match("bar"))
+	assert.False(t, m.Match("foobar"))
+	assert.False(t, m.Match(""))
+}
+
+func TestAhoCorasickMatcher(t *testing.T) {
+	m, err := newStringMatcher(MatchTypeContains, []string{"he", "she", "hers", "his", ""})
+	require.NoError(t, err)
+
+	cases := []struct {
+		value    string
+		expected bool
+	}{
+		{"ushers", true},
+		{"ahishers", true},
+		{"h", false},
+		{"hi", false},
+		{"this", true},
+		{"sh", false},
+		{"", false},
+		{"xyz", false},
+		{"xxxxhe", true},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			assert.Equal(t, c.expected, m.Match(c.value))
+		})
+	}
+}
+
+func TestAhoCorasickMatcherFailLinks(t *testing.T) {
+	// "abcd" is not present, but the automaton must fall back from "abc" to "bc" to find "bcx"
+	m := newAhoCorasickMatcher([]string{"abcd", "bcx", "cy"})
+	assert.True(t, m.Match("abcx"))
+	assert.True(t, m.Match("abcy"))
+	assert.False(t, m.Match("abce"))
+}
+
+func TestUnknownMatchType(t *testing.T) {
+	_, err := NewFilter(zap.NewNop(), &config.PolicyCfg{
+		Name:               "unknown",
+		StringAttributeCfg: &config.StringAttributeCfg{Key: "example", Values: []string{"value"}, MatchType: "regex"},
+	})
+	assert.Error(t, err)
+}
+
+func TestStringAttributeContainsFilter(t *testing.T) {
+	filter, err := NewFilter(zap.NewNop(), &config.PolicyCfg{
+		Name:               "contains",
+		SpansPerSecond:     math.MaxInt64,
+		StringAttributeCfg: &config.StringAttributeCfg{Key: "http.url", Values: []string{"/admin", "/debug"}, MatchType: MatchTypeContains},
+	})
+	require.NoError(t, err)
+
+	empty := map[string]pdata.AttributeValue{}
+	traceID := pdata.NewTraceID([16]byte{1})
+	assert.Equal(t, Sampled, filter.Evaluate(traceID, newTraceStringAttrs(empty, "http.url", "https://example.com/admin/users")))
+	assert.Equal(t, NotSampled, filter.Evaluate(traceID, newTraceStringAttrs(empty, "http.url", "https://example.com/users")))
+}
+
+const (
+	benchmarkNumValues     = 10000
+	benchmarkSpansPerTrace = 100
+)
+
+func benchmarkStringAttributeFilter(b *testing.B, matchType string) {
+	values := make([]string, benchmarkNumValues)
+	for i := range values {
+		values[i] = fmt.Sprintf("customer-%06d", i)
+	}
+	filter, err := NewFilter(zap.NewNop(), &config.PolicyCfg{
+		Name:               "benchmark",
+		SpansPerSecond:     math.MaxInt64,
+		StringAttributeCfg: &config.StringAttributeCfg{Key: "customer.id", Values: values, MatchType: matchType},
+	})
+	require.NoError(b, err)
+
+	// None of the spans match, so every span needs to be checked
+	traces := pdata.NewTraces()
+	spans := traces.ResourceSpans().AppendEmpty().InstrumentationLibrarySpans().AppendEmpty().Spans()
+	for i := 0; i < benchmarkSpansPerTrace; i++ {
+		spans.AppendEmpty().Attributes().InsertString("customer.id", fmt.Sprintf("unknown-client-%06d", i))
+	}
+	trace := &TraceData{ReceivedBatches: []pdata.Traces{traces}, SpanCount: benchmarkSpansPerTrace}
+	traceID := pdata.NewTraceID([16]byte{1})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if filter.Evaluate(traceID, trace) != NotSampled {
+			b.Fatal("unexpected decision")
+		}
+	}
+	b.ReportMetric(float64(b.N*benchmarkSpansPerTrace)/time.Since(start).Seconds(), "spans/s")
+}
+
+func BenchmarkStringAttributeFilterStrict(b *testing.B) {
+	benchmarkStringAttributeFilter(b, MatchTypeStrict)
+}
+
+func BenchmarkStringAttributeFilterContains(b *testing.B) {
+	benchmarkStringAttributeFilter(b, MatchTypeContains)
+}
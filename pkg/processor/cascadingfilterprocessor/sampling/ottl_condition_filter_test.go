@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+func newOTTLConditionFilter(t *testing.T, cfg *config.OTTLConditionCfg) PolicyEvaluator {
+	filter, err := NewFilter(zap.NewNop(), &config.PolicyCfg{
+		Name:             "ottl",
+		SpansPerSecond:   math.MaxInt64,
+		OTTLConditionCfg: cfg,
+	})
+	require.NoError(t, err)
+	return filter
+}
+
+func TestOTTLConditionFilter(t *testing.T) {
+	filter := newOTTLConditionFilter(t, &config.OTTLConditionCfg{
+		SpanConditions: []string{
+			`attributes["http.status_code"] >= 500`,
+			`resource.attributes["service.name"] == "payments" and kind == SPAN_KIND_SERVER`,
+		},
+		SpanEventConditions: []string{
+			`name == "exception" and IsMatch(attributes["exception.type"], "Timeout")`,
+		},
+	})
+
+	cases := []struct {
+		Desc     string
+		Trace    *TraceData
+		Decision Decision
+	}{
+		{
+			Desc: "nonmatching span",
+			Trace: newTraceOTTLAttrs("checkout", func(span pdata.Span) {
+				span.Attributes().InsertInt("http.status_code", 200)
+			}),
+			Decision: NotSampled,
+		},
+		{
+			Desc: "matching span attribute",
+			Trace: newTraceOTTLAttrs("checkout", func(span pdata.Span) {
+				span.Attributes().InsertInt("http.status_code", 502)
+			}),
+			Decision: Sampled,
+		},
+		{
+			Desc: "matching resource attribute and kind",
+			Trace: newTraceOTTLAttrs("payments", func(span pdata.Span) {
+				span.SetKind(pdata.SpanKindServer)
+			}),
+			Decision: Sampled,
+		},
+		{
+			Desc: "matching resource attribute only",
+			Trace: newTraceOTTLAttrs("payments", func(span pdata.Span) {
+				span.SetKind(pdata.SpanKindClient)
+			}),
+			Decision: NotSampled,
+		},
+		{
+			Desc: "matching span event",
+			Trace: newTraceOTTLAttrs("checkout", func(span pdata.Span) {
+				event := span.Events().AppendEmpty()
+				event.SetName("exception")
+				event.Attributes().InsertString("exception.type", "java.net.SocketTimeoutException")
+			}),
+			Decision: Sampled,
+		},
+		{
+			Desc: "nonmatching span event",
+			Trace: newTraceOTTLAttrs("checkout", func(span pdata.Span) {
+				event := span.Events().AppendEmpty()
+				event.SetName("exception")
+				event.Attributes().InsertString("exception.type", "java.lang.NullPointerException")
+			}),
+			Decision: NotSampled,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Desc, func(t *testing.T) {
+			decision := filter.Evaluate(pdata.NewTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}), c.Trace)
+			assert.Equal(t, c.Decision, decision)
+		})
+	}
+}
+
+func TestOTTLConditionFilterInvalidConfig(t *testing.T) {
+	_, err := NewFilter(zap.NewNop(), &config.PolicyCfg{
+		Name:             "ottl",
+		OTTLConditionCfg: &config.OTTLConditionCfg{},
+	})
+	assert.Error(t, err)
+
+	_, err = NewFilter(zap.NewNop(), &config.PolicyCfg{
+		Name:             "ottl",
+		OTTLConditionCfg: &config.OTTLConditionCfg{SpanConditions: []string{`attributes["a"] ==`}},
+	})
+	assert.Error(t, err)
+}
+
+func newTraceOTTLAttrs(serviceName string, fillSpan func(span pdata.Span)) *TraceData {
+	traces := pdata.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().InsertString("service.name", serviceName)
+	ils := rs.InstrumentationLibrarySpans().AppendEmpty()
+	span := ils.Spans().AppendEmpty()
+	span.SetTraceID(pdata.NewTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}))
+	span.SetSpanID(pdata.NewSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8}))
+	fillSpan(span)
+	return &TraceData{
+		ReceivedBatches: []pdata.Traces{traces},
+		SpanCount:       1,
+	}
+}
@@ -30,12 +30,27 @@ type TraceData struct {
 	FinalDecision Decision
 	// SelectedByProbabilisticFilter determines if this trace was selected by probabilistic filter
 	SelectedByProbabilisticFilter bool
+	// DecidingPolicy is the name of the first policy which selected the trace (or gave it a second chance).
+	DecidingPolicy string
+	// MatchingPolicies lists the names of all policies which matched the trace, regardless of their budgets.
+	// It's only filled when recording of the matching policies is enabled.
+	MatchingPolicies []string
+	// ReleasedEarly is set when the trace was sent before DecisionWait, due to matching an always keep policy.
+	ReleasedEarly bool
 	// Arrival time the first span for the trace was received.
 	ArrivalTime time.Time
 	// Decisiontime time when sampling decision was taken.
 	DecisionTime time.Time
 	// SpanCount track the number of spans on the trace.
 	SpanCount int64
+	// ServiceName is the service.name of the first received batch which has it set.
+	ServiceName string
+	// RootSpanName is the name of the span without a parent, empty until it's received.
+	RootSpanName string
+	// SizeBytes tracks the (protobuf encoded) size of ReceivedBatches.
+	SizeBytes int64
+	// SpilledChunks is the number of chunks of ReceivedBatches moved to the storage.
+	SpilledChunks int
 	// ReceivedBatches stores all the batches received for the trace.
 	ReceivedBatches []pdata.Traces
 }
@@ -74,3 +89,20 @@ type PolicyEvaluator interface {
 	// Evaluate looks at the trace data and returns a corresponding SamplingDecision.
 	Evaluate(traceID pdata.TraceID, trace *TraceData) Decision
 }
+
+// SpanObserver is implemented by the policy evaluators which learn from the spans of the decided traces,
+// e.g. the latency percentile. The spans of each trace are observed once, when the final decision is made.
+type SpanObserver interface {
+	// ObserveSpans records the spans of the trace, which the final decision was made for.
+	ObserveSpans(batches []pdata.Traces)
+}
+
+// MatchReporter is implemented by the policy evaluators which can tell whether the trace matched
+// the policy rules, even when it was not sampled due to the budget.
+type MatchReporter interface {
+	// EnableMatchReporting makes the evaluator check the rules also for the traces which do not fit the budget.
+	EnableMatchReporting()
+
+	// LastEvaluationMatched returns true if the trace passed to the most recent Evaluate call matched the rules.
+	LastEvaluationMatched() bool
+}
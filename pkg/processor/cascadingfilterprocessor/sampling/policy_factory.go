@@ -21,6 +21,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/ottl"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
 )
 
@@ -30,13 +31,22 @@ type numericAttributeFilter struct {
 }
 
 type stringAttributeFilter struct {
-	key    string
-	values map[string]struct{}
+	key     string
+	matcher stringMatcher
+}
+
+type ottlConditionFilter struct {
+	spanConditions      []*ottl.Condition
+	spanEventConditions []*ottl.Condition
 }
 
 type policyEvaluator struct {
-	numericAttr *numericAttributeFilter
-	stringAttr  *stringAttributeFilter
+	numericAttr   *numericAttributeFilter
+	stringAttr    *stringAttributeFilter
+	ottlCondition *ottlConditionFilter
+
+	latencyPercentile *latencyPercentileFilter
+	stratified        *stratifiedFilter
 
 	operationRe      *regexp.Regexp
 	minDuration      *time.Duration
@@ -45,13 +55,19 @@ type policyEvaluator struct {
 	currentSecond        int64
 	maxSpansPerSecond    int64
 	spansInCurrentSecond int64
+	serviceBudget        *ServiceBudget
 
 	invertMatch bool
+	alwaysKeep  bool
+
+	reportMatches bool
+	lastMatched   bool
 
 	logger *zap.Logger
 }
 
 var _ PolicyEvaluator = (*policyEvaluator)(nil)
+var _ MatchReporter = (*policyEvaluator)(nil)
 
 func createNumericAttributeFilter(cfg *config.NumericAttributeCfg) *numericAttributeFilter {
 	if cfg == nil {
@@ -65,22 +81,48 @@ func createNumericAttributeFilter(cfg *config.NumericAttributeCfg) *numericAttri
 	}
 }
 
-func createStringAttributeFilter(cfg *config.StringAttributeCfg) *stringAttributeFilter {
+func createStringAttributeFilter(cfg *config.StringAttributeCfg) (*stringAttributeFilter, error) {
 	if cfg == nil {
-		return nil
+		return nil, nil
 	}
 
-	valuesMap := make(map[string]struct{})
-	for _, value := range cfg.Values {
-		if value != "" {
-			valuesMap[value] = struct{}{}
-		}
+	matcher, err := newStringMatcher(cfg.MatchType, cfg.Values)
+	if err != nil {
+		return nil, err
 	}
 
 	return &stringAttributeFilter{
-		key:    cfg.Key,
-		values: valuesMap,
+		key:     cfg.Key,
+		matcher: matcher,
+	}, nil
+}
+
+func createOTTLConditionFilter(cfg *config.OTTLConditionCfg) (*ottlConditionFilter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	if len(cfg.SpanConditions) == 0 && len(cfg.SpanEventConditions) == 0 {
+		return nil, errors.New("at least one span or span event OTTL condition must be provided")
+	}
+
+	filter := &ottlConditionFilter{}
+	for _, text := range cfg.SpanConditions {
+		condition, err := ottl.ParseCondition(ottl.SpanContext, text)
+		if err != nil {
+			return nil, err
+		}
+		filter.spanConditions = append(filter.spanConditions, condition)
+	}
+	for _, text := range cfg.SpanEventConditions {
+		condition, err := ottl.ParseCondition(ottl.SpanEventContext, text)
+		if err != nil {
+			return nil, err
+		}
+		filter.spanEventConditions = append(filter.spanEventConditions, condition)
 	}
+
+	return filter, nil
 }
 
 // NewProbabilisticFilter creates a policy evaluator intended for selecting samples probabilistically
@@ -96,10 +138,26 @@ func NewProbabilisticFilter(logger *zap.Logger, maxSpanRate int64) (PolicyEvalua
 // NewFilter creates a policy evaluator that samples all traces with the specified criteria
 func NewFilter(logger *zap.Logger, cfg *config.PolicyCfg) (PolicyEvaluator, error) {
 	numericAttrFilter := createNumericAttributeFilter(cfg.NumericAttributeCfg)
-	stringAttrFilter := createStringAttributeFilter(cfg.StringAttributeCfg)
+	stringAttrFilter, err := createStringAttributeFilter(cfg.StringAttributeCfg)
+	if err != nil {
+		return nil, err
+	}
+	ottlConditionFilter, err := createOTTLConditionFilter(cfg.OTTLConditionCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	latencyPercentileFilter, err := createLatencyPercentileFilter(cfg.LatencyPercentileCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	stratifiedFilter, err := createStratifiedFilter(cfg.StratifiedCfg)
+	if err != nil {
+		return nil, err
+	}
 
 	var operationRe *regexp.Regexp
-	var err error
 
 	if cfg.PropertiesCfg.NamePattern != nil {
 		operationRe, err = regexp.Compile(*cfg.PropertiesCfg.NamePattern)
@@ -119,6 +177,9 @@ func NewFilter(logger *zap.Logger, cfg *config.PolicyCfg) (PolicyEvaluator, erro
 	return &policyEvaluator{
 		stringAttr:           stringAttrFilter,
 		numericAttr:          numericAttrFilter,
+		ottlCondition:        ottlConditionFilter,
+		latencyPercentile:    latencyPercentileFilter,
+		stratified:           stratifiedFilter,
 		operationRe:          operationRe,
 		minDuration:          cfg.PropertiesCfg.MinDuration,
 		minNumberOfSpans:     cfg.PropertiesCfg.MinNumberOfSpans,
@@ -126,6 +187,8 @@ func NewFilter(logger *zap.Logger, cfg *config.PolicyCfg) (PolicyEvaluator, erro
 		currentSecond:        0,
 		spansInCurrentSecond: 0,
 		maxSpansPerSecond:    cfg.SpansPerSecond,
+		serviceBudget:        NewServiceBudget(cfg.ServiceBudgetCfg),
 		invertMatch:          cfg.InvertMatch,
+		alwaysKeep:           cfg.AlwaysKeep,
 	}, nil
 }
@@ -18,6 +18,8 @@ import (
 	"time"
 
 	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/ottl"
 )
 
 // OnLateArrivingSpans notifies the evaluator that the given list of spans arrived
@@ -28,6 +30,29 @@ func (pe *policyEvaluator) OnLateArrivingSpans(earlyDecision Decision, spans []*
 	return nil
 }
 
+// ObserveSpans records the span durations of the decided trace in the latency percentile, if it's configured
+func (pe *policyEvaluator) ObserveSpans(batches []pdata.Traces) {
+	if pe.latencyPercentile == nil {
+		return
+	}
+	pe.latencyPercentile.decayIfNeeded(time.Now())
+
+	for _, batch := range batches {
+		rs := batch.ResourceSpans()
+		for i := 0; i < rs.Len(); i++ {
+			serviceName := ResourceServiceName(rs.At(i).Resource())
+			ils := rs.At(i).InstrumentationLibrarySpans()
+			for j := 0; j < ils.Len(); j++ {
+				spans := ils.At(j).Spans()
+				for k := 0; k < spans.Len(); k++ {
+					span := spans.At(k)
+					pe.latencyPercentile.observe(serviceName, span.Name(), time.Duration(span.EndTimestamp()-span.StartTimestamp()))
+				}
+			}
+		}
+	}
+}
+
 func tsToMicros(ts pdata.Timestamp) int64 {
 	return int64(ts / 1000)
 }
@@ -46,11 +71,31 @@ func checkIfStringAttrFound(attrs pdata.AttributeMap, filter *stringAttributeFil
 	if v, ok := attrs.Get(filter.key); ok {
 		truncableStr := v.StringVal()
 		if len(truncableStr) > 0 {
-			if _, ok := filter.values[truncableStr]; ok {
-				return true
+			return filter.matcher.Match(truncableStr)
+		}
+	}
+	return false
+}
+
+func checkIfOTTLConditionFound(tCtx *ottl.TransformContext, filter *ottlConditionFilter) bool {
+	for _, condition := range filter.spanConditions {
+		if condition.Eval(tCtx) {
+			return true
+		}
+	}
+
+	if len(filter.spanEventConditions) > 0 {
+		events := tCtx.Span.Events()
+		for i := 0; i < events.Len(); i++ {
+			tCtx.SpanEvent = events.At(i)
+			for _, condition := range filter.spanEventConditions {
+				if condition.Eval(tCtx) {
+					return true
+				}
 			}
 		}
 	}
+
 	return false
 }
 
@@ -63,6 +108,8 @@ func (pe *policyEvaluator) evaluateRules(_ pdata.TraceID, trace *TraceData) Deci
 	matchingOperationFound := false
 	matchingStringAttrFound := false
 	matchingNumericAttrFound := false
+	matchingOTTLConditionFound := false
+	matchingSlowSpanFound := false
 	spanCount := 0
 	minStartTime := int64(0)
 	maxEndTime := int64(0)
@@ -71,6 +118,11 @@ func (pe *policyEvaluator) evaluateRules(_ pdata.TraceID, trace *TraceData) Deci
 		rs := batch.ResourceSpans()
 
 		for i := 0; i < rs.Len(); i++ {
+			var serviceName string
+			if pe.latencyPercentile != nil {
+				serviceName = ResourceServiceName(rs.At(i).Resource())
+			}
+
 			if pe.stringAttr != nil || pe.numericAttr != nil {
 				res := rs.At(i).Resource()
 				if !matchingStringAttrFound && pe.stringAttr != nil {
@@ -88,6 +140,15 @@ func (pe *policyEvaluator) evaluateRules(_ pdata.TraceID, trace *TraceData) Deci
 				for k := 0; k < spans.Len(); k++ {
 					span := spans.At(k)
 
+					if pe.ottlCondition != nil && !matchingOTTLConditionFound {
+						tCtx := &ottl.TransformContext{
+							Resource:               rs.At(i).Resource(),
+							InstrumentationLibrary: ils.At(j).InstrumentationLibrary(),
+							Span:                   span,
+						}
+						matchingOTTLConditionFound = checkIfOTTLConditionFound(tCtx, pe.ottlCondition)
+					}
+
 					if pe.stringAttr != nil || pe.numericAttr != nil {
 						if !matchingStringAttrFound && pe.stringAttr != nil {
 							matchingStringAttrFound = checkIfStringAttrFound(span.Attributes(), pe.stringAttr)
@@ -97,6 +158,11 @@ func (pe *policyEvaluator) evaluateRules(_ pdata.TraceID, trace *TraceData) Deci
 						}
 					}
 
+					if pe.latencyPercentile != nil && !matchingSlowSpanFound {
+						duration := time.Duration(span.EndTimestamp() - span.StartTimestamp())
+						matchingSlowSpanFound = pe.latencyPercentile.isSlow(serviceName, span.Name(), duration)
+					}
+
 					if pe.operationRe != nil && !matchingOperationFound {
 						if pe.operationRe.MatchString(span.Name()) {
 							matchingOperationFound = true
@@ -126,13 +192,16 @@ func (pe *policyEvaluator) evaluateRules(_ pdata.TraceID, trace *TraceData) Deci
 	}
 
 	conditionMet := struct {
-		operationName, minDuration, minSpanCount, stringAttr, numericAttr bool
+		operationName, minDuration, minSpanCount, stringAttr, numericAttr, ottlCondition, slowSpan, stratum bool
 	}{
 		operationName: true,
 		minDuration:   true,
 		minSpanCount:  true,
 		stringAttr:    true,
 		numericAttr:   true,
+		ottlCondition: true,
+		slowSpan:      true,
+		stratum:       true,
 	}
 
 	if pe.operationRe != nil {
@@ -150,12 +219,24 @@ func (pe *policyEvaluator) evaluateRules(_ pdata.TraceID, trace *TraceData) Deci
 	if pe.stringAttr != nil {
 		conditionMet.stringAttr = matchingStringAttrFound
 	}
+	if pe.ottlCondition != nil {
+		conditionMet.ottlCondition = matchingOTTLConditionFound
+	}
+	if pe.latencyPercentile != nil {
+		conditionMet.slowSpan = matchingSlowSpanFound
+	}
+	if pe.stratified != nil {
+		_, conditionMet.stratum = pe.stratified.uncoveredValue(currentMinute(), batches)
+	}
 
 	if conditionMet.minSpanCount &&
 		conditionMet.minDuration &&
 		conditionMet.operationName &&
 		conditionMet.numericAttr &&
-		conditionMet.stringAttr {
+		conditionMet.stringAttr &&
+		conditionMet.ottlCondition &&
+		conditionMet.slowSpan &&
+		conditionMet.stratum {
 		if pe.invertMatch {
 			return NotSampled
 		}
@@ -188,15 +269,17 @@ func (pe *policyEvaluator) emitsSecondChance() bool {
 	return pe.maxSpansPerSecond < 0
 }
 
-func (pe *policyEvaluator) updateRate(currSecond int64, numSpans int64) Decision {
+func (pe *policyEvaluator) updateRate(currSecond int64, trace *TraceData) Decision {
 	if pe.currentSecond != currSecond {
 		pe.currentSecond = currSecond
 		pe.spansInCurrentSecond = 0
 	}
 
+	numSpans := trace.SpanCount
 	spansInSecondIfSampled := pe.spansInCurrentSecond + numSpans
-	if spansInSecondIfSampled <= pe.maxSpansPerSecond {
+	if spansInSecondIfSampled <= pe.maxSpansPerSecond && pe.serviceBudget.Fits(currSecond, trace.ServiceName, numSpans) {
 		pe.spansInCurrentSecond = spansInSecondIfSampled
+		pe.serviceBudget.Consume(currSecond, trace.ServiceName, numSpans)
 		return Sampled
 	}
 
@@ -207,19 +290,53 @@ func (pe *policyEvaluator) updateRate(currSecond int64, numSpans int64) Decision
 // the usage of sampling rate budget
 func (pe *policyEvaluator) Evaluate(traceID pdata.TraceID, trace *TraceData) Decision {
 	currSecond := time.Now().Unix()
+	pe.lastMatched = false
 
-	if !pe.shouldConsider(currSecond, trace) {
+	// Budgets do not apply to always keep policies
+	considered := pe.alwaysKeep || pe.shouldConsider(currSecond, trace)
+	if !considered && !pe.reportMatches {
 		return NotSampled
 	}
 
 	decision := pe.evaluateRules(traceID, trace)
+	pe.lastMatched = decision == Sampled
 	if decision != Sampled {
 		return decision
 	}
+	if !considered {
+		return NotSampled
+	}
+
+	if !pe.alwaysKeep {
+		if pe.emitsSecondChance() {
+			return SecondChance
+		}
+		decision = pe.updateRate(currSecond, trace)
+	}
 
-	if pe.emitsSecondChance() {
-		return SecondChance
+	if decision == Sampled && pe.stratified != nil {
+		// The minimum is accounted only for the traces which fit in the policy budget
+		trace.Lock()
+		batches := trace.ReceivedBatches
+		trace.Unlock()
+		currMinute := currentMinute()
+		if value, ok := pe.stratified.uncoveredValue(currMinute, batches); ok {
+			pe.stratified.consume(currMinute, value)
+		}
 	}
+	return decision
+}
+
+// EnableMatchReporting makes Evaluate check the rules even when the trace cannot fit the policy budget
+func (pe *policyEvaluator) EnableMatchReporting() {
+	pe.reportMatches = true
+}
+
+// LastEvaluationMatched returns true if the rules matched the trace during the most recent Evaluate call
+func (pe *policyEvaluator) LastEvaluationMatched() bool {
+	return pe.lastMatched
+}
 
-	return pe.updateRate(currSecond, trace.SpanCount)
+func currentMinute() int64 {
+	return time.Now().Unix() / 60
 }
@@ -57,6 +57,33 @@ func TestRateLimiter(t *testing.T) {
 	assert.Equal(t, decision, Sampled)
 }
 
+func TestAlwaysKeepIgnoresRateLimit(t *testing.T) {
+	trace := newTraceStringAttrs(map[string]pdata.AttributeValue{}, "example", "value")
+	traceID := pdata.NewTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	alwaysKeep := newRateLimiterFilter(3)
+	alwaysKeep.alwaysKeep = true
+
+	trace.SpanCount = 10
+	assert.Equal(t, Sampled, alwaysKeep.Evaluate(traceID, trace))
+	assert.Equal(t, Sampled, alwaysKeep.Evaluate(traceID, trace))
+	assert.Equal(t, int64(0), alwaysKeep.spansInCurrentSecond)
+}
+
+func TestMatchReportingBeyondRateLimit(t *testing.T) {
+	trace := newTraceStringAttrs(map[string]pdata.AttributeValue{}, "example", "value")
+	traceID := pdata.NewTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	rateLimiter := newRateLimiterFilter(3)
+	trace.SpanCount = 10
+
+	assert.Equal(t, NotSampled, rateLimiter.Evaluate(traceID, trace))
+	assert.False(t, rateLimiter.LastEvaluationMatched())
+
+	rateLimiter.EnableMatchReporting()
+	assert.Equal(t, NotSampled, rateLimiter.Evaluate(traceID, trace))
+	assert.True(t, rateLimiter.LastEvaluationMatched())
+	assert.Equal(t, int64(0), rateLimiter.spansInCurrentSecond)
+}
+
 func TestOnLateArrivingSpans_RateLimiter(t *testing.T) {
 	rateLimiter := newRateLimiterFilter(3)
 	err := rateLimiter.OnLateArrivingSpans(NotSampled, nil)
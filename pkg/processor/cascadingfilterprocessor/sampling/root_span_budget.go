@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+// RootSpanBudget tracks traces per second budgets scoped per root span name. A nil RootSpanBudget
+// does not limit anything.
+type RootSpanBudget struct {
+	defaultTracesPerSecond int64
+	tracesPerSecond        map[string]int64
+
+	currentSecond         int64
+	tracesInCurrentSecond map[string]int64
+}
+
+// NewRootSpanBudget creates the budget described by the config or returns nil if no config is provided.
+func NewRootSpanBudget(cfg *config.RootSpanBudgetCfg) *RootSpanBudget {
+	if cfg == nil {
+		return nil
+	}
+
+	tracesPerSecond := make(map[string]int64, len(cfg.RootSpans))
+	for name, limit := range cfg.RootSpans {
+		tracesPerSecond[name] = limit
+	}
+
+	return &RootSpanBudget{
+		defaultTracesPerSecond: cfg.Default,
+		tracesPerSecond:        tracesPerSecond,
+		tracesInCurrentSecond:  make(map[string]int64),
+	}
+}
+
+func (rb *RootSpanBudget) limit(name string) (int64, bool) {
+	if limit, ok := rb.tracesPerSecond[name]; ok {
+		return limit, true
+	}
+	return rb.defaultTracesPerSecond, rb.defaultTracesPerSecond > 0
+}
+
+func (rb *RootSpanBudget) resetIfNeeded(currSecond int64) {
+	if rb.currentSecond != currSecond {
+		rb.currentSecond = currSecond
+		rb.tracesInCurrentSecond = make(map[string]int64, len(rb.tracesInCurrentSecond))
+	}
+}
+
+// Fits returns true if one more trace with the given root span name fits within its budget for the given second.
+// Traces without the root span (empty name) are not limited.
+func (rb *RootSpanBudget) Fits(currSecond int64, name string) bool {
+	if rb == nil || name == "" {
+		return true
+	}
+	limit, ok := rb.limit(name)
+	if !ok {
+		return true
+	}
+	rb.resetIfNeeded(currSecond)
+	return rb.tracesInCurrentSecond[name] < limit
+}
+
+// Consume accounts a trace with the given root span name in the budget for the given second.
+func (rb *RootSpanBudget) Consume(currSecond int64, name string) {
+	if rb == nil || name == "" {
+		return
+	}
+	rb.resetIfNeeded(currSecond)
+	rb.tracesInCurrentSecond[name]++
+}
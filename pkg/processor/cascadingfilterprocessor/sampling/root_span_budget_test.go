@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+func TestRootSpanBudget(t *testing.T) {
+	rb := NewRootSpanBudget(&config.RootSpanBudgetCfg{
+		Default:   1,
+		RootSpans: map[string]int64{"GET /search": 2},
+	})
+
+	assert.True(t, rb.Fits(1, "GET /search"))
+	rb.Consume(1, "GET /search")
+	rb.Consume(1, "GET /search")
+	assert.False(t, rb.Fits(1, "GET /search"))
+
+	// Each root span name not listed gets its own default budget
+	rb.Consume(1, "GET /cart")
+	assert.False(t, rb.Fits(1, "GET /cart"))
+	assert.True(t, rb.Fits(1, "POST /cart"))
+
+	// Traces without the root span are not limited
+	rb.Consume(1, "")
+	assert.True(t, rb.Fits(1, ""))
+
+	// The budget is renewed every second
+	assert.True(t, rb.Fits(2, "GET /search"))
+	assert.True(t, rb.Fits(2, "GET /cart"))
+}
+
+func TestRootSpanBudgetWithoutDefault(t *testing.T) {
+	rb := NewRootSpanBudget(&config.RootSpanBudgetCfg{RootSpans: map[string]int64{"GET /search": 0}})
+	assert.False(t, rb.Fits(1, "GET /search"))
+	assert.True(t, rb.Fits(1, "GET /cart"))
+}
+
+func TestNilRootSpanBudget(t *testing.T) {
+	rb := NewRootSpanBudget(nil)
+	require.Nil(t, rb)
+	rb.Consume(1, "GET /search")
+	assert.True(t, rb.Fits(1, "GET /search"))
+}
@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+const (
+	// DefaultSamplingHintAttribute is the attribute checked when no attributes are configured
+	DefaultSamplingHintAttribute = "sampling.priority"
+
+	// HintConflictKeep keeps the trace when its spans have contradicting hints
+	HintConflictKeep = "keep"
+	// HintConflictDrop drops the trace when its spans have contradicting hints
+	HintConflictDrop = "drop"
+)
+
+// SamplingHints finds the sampling decisions forced by instrumentation via span attributes.
+// A nil SamplingHints never forces any decision.
+type SamplingHints struct {
+	attributes []string
+	onConflict Decision
+}
+
+// NewSamplingHints creates the hints evaluator described by the config or returns nil if no config is provided.
+func NewSamplingHints(cfg *config.SamplingHintsCfg) (*SamplingHints, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	sh := &SamplingHints{attributes: cfg.Attributes}
+	if len(sh.attributes) == 0 {
+		sh.attributes = []string{DefaultSamplingHintAttribute}
+	}
+
+	switch cfg.OnConflict {
+	case "", HintConflictKeep:
+		sh.onConflict = Sampled
+	case HintConflictDrop:
+		sh.onConflict = NotSampled
+	default:
+		return nil, fmt.Errorf("unknown sampling hints on_conflict %q, must be one of: %s, %s",
+			cfg.OnConflict, HintConflictKeep, HintConflictDrop)
+	}
+
+	return sh, nil
+}
+
+// Evaluate returns Sampled or NotSampled if the trace carries a hint, Unspecified otherwise.
+// The first attribute (in the order of precedence) present on any span decides.
+func (sh *SamplingHints) Evaluate(trace *TraceData) Decision {
+	if sh == nil {
+		return Unspecified
+	}
+
+	for _, attribute := range sh.attributes {
+		keep, drop := false, false
+		for _, batch := range trace.ReceivedBatches {
+			rs := batch.ResourceSpans()
+			for i := 0; i < rs.Len(); i++ {
+				ils := rs.At(i).InstrumentationLibrarySpans()
+				for j := 0; j < ils.Len(); j++ {
+					spans := ils.At(j).Spans()
+					for k := 0; k < spans.Len(); k++ {
+						value, ok := spans.At(k).Attributes().Get(attribute)
+						if !ok {
+							continue
+						}
+						switch hintValue(value) {
+						case Sampled:
+							keep = true
+						case NotSampled:
+							drop = true
+						}
+					}
+				}
+			}
+		}
+
+		switch {
+		case keep && drop:
+			return sh.onConflict
+		case keep:
+			return Sampled
+		case drop:
+			return NotSampled
+		}
+	}
+
+	return Unspecified
+}
+
+// hintValue interprets the attribute value as a hint: positive numbers and true keep the trace,
+// while zero and false drop it. Other values are ignored.
+func hintValue(value pdata.AttributeValue) Decision {
+	switch value.Type() {
+	case pdata.AttributeValueTypeInt:
+		return numericHint(float64(value.IntVal()))
+	case pdata.AttributeValueTypeDouble:
+		return numericHint(value.DoubleVal())
+	case pdata.AttributeValueTypeBool:
+		if value.BoolVal() {
+			return Sampled
+		}
+		return NotSampled
+	case pdata.AttributeValueTypeString:
+		if b, err := strconv.ParseBool(value.StringVal()); err == nil {
+			if b {
+				return Sampled
+			}
+			return NotSampled
+		}
+		if f, err := strconv.ParseFloat(value.StringVal(), 64); err == nil {
+			return numericHint(f)
+		}
+	}
+	return Unspecified
+}
+
+func numericHint(value float64) Decision {
+	switch {
+	case value > 0:
+		return Sampled
+	case value == 0:
+		return NotSampled
+	}
+	return Unspecified
+}
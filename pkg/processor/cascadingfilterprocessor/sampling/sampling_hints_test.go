@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+func newTraceWithSpanAttributes(spanAttrs ...map[string]pdata.AttributeValue) *TraceData {
+	traces := pdata.NewTraces()
+	spans := traces.ResourceSpans().AppendEmpty().InstrumentationLibrarySpans().AppendEmpty().Spans()
+	for _, attrs := range spanAttrs {
+		spans.AppendEmpty().Attributes().InitFromMap(attrs)
+	}
+	return &TraceData{ReceivedBatches: []pdata.Traces{traces}}
+}
+
+func TestSamplingHintValues(t *testing.T) {
+	sh, err := NewSamplingHints(&config.SamplingHintsCfg{})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		value    pdata.AttributeValue
+		expected Decision
+	}{
+		{"int keep", pdata.NewAttributeValueInt(1), Sampled},
+		{"int drop", pdata.NewAttributeValueInt(0), NotSampled},
+		{"negative", pdata.NewAttributeValueInt(-1), Unspecified},
+		{"double keep", pdata.NewAttributeValueDouble(0.5), Sampled},
+		{"bool keep", pdata.NewAttributeValueBool(true), Sampled},
+		{"bool drop", pdata.NewAttributeValueBool(false), NotSampled},
+		{"string keep", pdata.NewAttributeValueString("1"), Sampled},
+		{"string drop", pdata.NewAttributeValueString("false"), NotSampled},
+		{"string other", pdata.NewAttributeValueString("maybe"), Unspecified},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			trace := newTraceWithSpanAttributes(map[string]pdata.AttributeValue{DefaultSamplingHintAttribute: c.value})
+			assert.Equal(t, c.expected, sh.Evaluate(trace))
+		})
+	}
+
+	assert.Equal(t, Unspecified, sh.Evaluate(newTraceWithSpanAttributes(map[string]pdata.AttributeValue{})))
+}
+
+func TestSamplingHintPrecedence(t *testing.T) {
+	sh, err := NewSamplingHints(&config.SamplingHintsCfg{Attributes: []string{"sumo.keep", "sampling.priority"}})
+	require.NoError(t, err)
+
+	trace := newTraceWithSpanAttributes(
+		map[string]pdata.AttributeValue{"sampling.priority": pdata.NewAttributeValueInt(1)},
+		map[string]pdata.AttributeValue{"sumo.keep": pdata.NewAttributeValueBool(false)},
+	)
+	assert.Equal(t, NotSampled, sh.Evaluate(trace))
+
+	trace = newTraceWithSpanAttributes(map[string]pdata.AttributeValue{"sampling.priority": pdata.NewAttributeValueInt(1)})
+	assert.Equal(t, Sampled, sh.Evaluate(trace))
+}
+
+func TestSamplingHintConflict(t *testing.T) {
+	trace := newTraceWithSpanAttributes(
+		map[string]pdata.AttributeValue{"sampling.priority": pdata.NewAttributeValueInt(1)},
+		map[string]pdata.AttributeValue{"sampling.priority": pdata.NewAttributeValueInt(0)},
+	)
+
+	keep, err := NewSamplingHints(&config.SamplingHintsCfg{})
+	require.NoError(t, err)
+	assert.Equal(t, Sampled, keep.Evaluate(trace))
+
+	drop, err := NewSamplingHints(&config.SamplingHintsCfg{OnConflict: HintConflictDrop})
+	require.NoError(t, err)
+	assert.Equal(t, NotSampled, drop.Evaluate(trace))
+
+	_, err = NewSamplingHints(&config.SamplingHintsCfg{OnConflict: "first"})
+	assert.Error(t, err)
+}
+
+func TestNilSamplingHints(t *testing.T) {
+	sh, err := NewSamplingHints(nil)
+	require.NoError(t, err)
+	require.Nil(t, sh)
+	trace := newTraceWithSpanAttributes(map[string]pdata.AttributeValue{"sampling.priority": pdata.NewAttributeValueInt(1)})
+	assert.Equal(t, Unspecified, sh.Evaluate(trace))
+}
@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+const serviceNameAttribute = "service.name"
+
+// ServiceBudget tracks spans per second budgets scoped per service.name. A nil ServiceBudget
+// does not limit anything.
+type ServiceBudget struct {
+	defaultSpansPerSecond int64
+	spansPerSecond        map[string]int64
+
+	currentSecond        int64
+	spansInCurrentSecond map[string]int64
+}
+
+// NewServiceBudget creates the budget described by the config or returns nil if no config is provided.
+func NewServiceBudget(cfg *config.ServiceBudgetCfg) *ServiceBudget {
+	if cfg == nil {
+		return nil
+	}
+
+	spansPerSecond := make(map[string]int64, len(cfg.Services))
+	for service, limit := range cfg.Services {
+		spansPerSecond[service] = limit
+	}
+
+	return &ServiceBudget{
+		defaultSpansPerSecond: cfg.Default,
+		spansPerSecond:        spansPerSecond,
+		spansInCurrentSecond:  make(map[string]int64),
+	}
+}
+
+func (sb *ServiceBudget) limit(service string) (int64, bool) {
+	if limit, ok := sb.spansPerSecond[service]; ok {
+		return limit, true
+	}
+	return sb.defaultSpansPerSecond, sb.defaultSpansPerSecond > 0
+}
+
+func (sb *ServiceBudget) resetIfNeeded(currSecond int64) {
+	if sb.currentSecond != currSecond {
+		sb.currentSecond = currSecond
+		sb.spansInCurrentSecond = make(map[string]int64, len(sb.spansInCurrentSecond))
+	}
+}
+
+// Fits returns true if numSpans of the service still fit within its budget for the given second.
+func (sb *ServiceBudget) Fits(currSecond int64, service string, numSpans int64) bool {
+	if sb == nil {
+		return true
+	}
+	limit, ok := sb.limit(service)
+	if !ok {
+		return true
+	}
+	sb.resetIfNeeded(currSecond)
+	return sb.spansInCurrentSecond[service]+numSpans <= limit
+}
+
+// Consume accounts numSpans of the service in the budget for the given second.
+func (sb *ServiceBudget) Consume(currSecond int64, service string, numSpans int64) {
+	if sb == nil {
+		return
+	}
+	sb.resetIfNeeded(currSecond)
+	sb.spansInCurrentSecond[service] += numSpans
+}
+
+// ResourceServiceName returns the service.name resource attribute or an empty string if it's not set.
+func ResourceServiceName(resource pdata.Resource) string {
+	if v, ok := resource.Attributes().Get(serviceNameAttribute); ok {
+		return v.StringVal()
+	}
+	return ""
+}
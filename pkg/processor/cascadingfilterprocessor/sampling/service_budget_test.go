@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+func TestServiceBudget(t *testing.T) {
+	sb := NewServiceBudget(&config.ServiceBudgetCfg{
+		Default:  10,
+		Services: map[string]int64{"checkout": 20},
+	})
+
+	assert.True(t, sb.Fits(1, "checkout", 20))
+	sb.Consume(1, "checkout", 20)
+	assert.False(t, sb.Fits(1, "checkout", 1))
+
+	// Each service not listed gets its own default budget
+	assert.True(t, sb.Fits(1, "cart", 10))
+	sb.Consume(1, "cart", 10)
+	assert.False(t, sb.Fits(1, "cart", 1))
+	assert.True(t, sb.Fits(1, "payments", 10))
+
+	// The budget is renewed every second
+	assert.True(t, sb.Fits(2, "checkout", 20))
+	assert.True(t, sb.Fits(2, "cart", 10))
+}
+
+func TestServiceBudgetWithoutDefault(t *testing.T) {
+	sb := NewServiceBudget(&config.ServiceBudgetCfg{Services: map[string]int64{"checkout": 5}})
+	assert.False(t, sb.Fits(1, "checkout", 6))
+	assert.True(t, sb.Fits(1, "cart", 1000))
+}
+
+func TestNilServiceBudget(t *testing.T) {
+	sb := NewServiceBudget(nil)
+	require.Nil(t, sb)
+	sb.Consume(1, "checkout", 1000)
+	assert.True(t, sb.Fits(1, "checkout", 1000))
+}
+
+func TestPolicyServiceBudget(t *testing.T) {
+	filter, err := NewFilter(zap.NewNop(), &config.PolicyCfg{
+		Name:             "service-budget",
+		SpansPerSecond:   100,
+		ServiceBudgetCfg: &config.ServiceBudgetCfg{Default: 2},
+	})
+	require.NoError(t, err)
+	pe := filter.(*policyEvaluator)
+
+	newTrace := func(service string) *TraceData {
+		trace := newTraceStringAttrs(map[string]pdata.AttributeValue{}, "", "")
+		trace.SpanCount = 1
+		trace.ServiceName = service
+		return trace
+	}
+
+	assert.Equal(t, Sampled, pe.updateRate(1, newTrace("chatty")))
+	assert.Equal(t, Sampled, pe.updateRate(1, newTrace("chatty")))
+	assert.Equal(t, NotSampled, pe.updateRate(1, newTrace("chatty")))
+	assert.Equal(t, Sampled, pe.updateRate(1, newTrace("quiet")))
+}
+
+func TestResourceServiceName(t *testing.T) {
+	resource := pdata.NewResource()
+	assert.Equal(t, "", ResourceServiceName(resource))
+	resource.Attributes().InsertString("service.name", "checkout")
+	assert.Equal(t, "checkout", ResourceServiceName(resource))
+}
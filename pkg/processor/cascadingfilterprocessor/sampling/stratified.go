@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+const defaultStratifiedMaxValues = 10000
+
+// stratifiedFilter selects traces carrying a value of the key attribute which did not reach its minimum
+// number of sampled traces in the current minute yet. It's not safe for concurrent use.
+type stratifiedFilter struct {
+	key                string
+	minTracesPerMinute int64
+	maxValues          int
+
+	currentMinute         int64
+	tracesInCurrentMinute map[string]int64
+}
+
+func createStratifiedFilter(cfg *config.StratifiedCfg) (*stratifiedFilter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	if cfg.Key == "" {
+		return nil, errors.New("stratified key must be provided")
+	}
+	if cfg.MinTracesPerMinute <= 0 {
+		return nil, errors.New("stratified min_traces_per_minute must be greater than 0")
+	}
+
+	maxValues := cfg.MaxValues
+	if maxValues <= 0 {
+		maxValues = defaultStratifiedMaxValues
+	}
+
+	return &stratifiedFilter{
+		key:                   cfg.Key,
+		minTracesPerMinute:    cfg.MinTracesPerMinute,
+		maxValues:             maxValues,
+		tracesInCurrentMinute: make(map[string]int64),
+	}, nil
+}
+
+func (sf *stratifiedFilter) resetIfNeeded(currMinute int64) {
+	if sf.currentMinute != currMinute {
+		sf.currentMinute = currMinute
+		sf.tracesInCurrentMinute = make(map[string]int64, len(sf.tracesInCurrentMinute))
+	}
+}
+
+// needsCoverage returns true if the value did not reach its minimum in the given minute. New values
+// are not accepted once maxValues distinct values were seen in the minute.
+func (sf *stratifiedFilter) needsCoverage(currMinute int64, value string) bool {
+	sf.resetIfNeeded(currMinute)
+	count, ok := sf.tracesInCurrentMinute[value]
+	if !ok {
+		return len(sf.tracesInCurrentMinute) < sf.maxValues
+	}
+	return count < sf.minTracesPerMinute
+}
+
+// uncoveredValue returns the first value of the key attribute (at resource or span level) found in the trace,
+// which still needs coverage in the given minute
+func (sf *stratifiedFilter) uncoveredValue(currMinute int64, batches []pdata.Traces) (string, bool) {
+	check := func(attrs pdata.AttributeMap) (string, bool) {
+		if v, ok := attrs.Get(sf.key); ok {
+			value := pdata.AttributeValueToString(v)
+			return value, sf.needsCoverage(currMinute, value)
+		}
+		return "", false
+	}
+
+	for _, batch := range batches {
+		rs := batch.ResourceSpans()
+		for i := 0; i < rs.Len(); i++ {
+			if value, ok := check(rs.At(i).Resource().Attributes()); ok {
+				return value, true
+			}
+			ils := rs.At(i).InstrumentationLibrarySpans()
+			for j := 0; j < ils.Len(); j++ {
+				spans := ils.At(j).Spans()
+				for k := 0; k < spans.Len(); k++ {
+					if value, ok := check(spans.At(k).Attributes()); ok {
+						return value, true
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// consume accounts a sampled trace for the value in the given minute
+func (sf *stratifiedFilter) consume(currMinute int64, value string) {
+	sf.resetIfNeeded(currMinute)
+	sf.tracesInCurrentMinute[value]++
+}
@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+func newStratifiedPolicy(t *testing.T, cfg *config.StratifiedCfg, spansPerSecond int64) *policyEvaluator {
+	filter, err := NewFilter(zap.NewNop(), &config.PolicyCfg{
+		Name:           "stratified",
+		SpansPerSecond: spansPerSecond,
+		StratifiedCfg:  cfg,
+	})
+	require.NoError(t, err)
+	return filter.(*policyEvaluator)
+}
+
+func newTenantTrace(tenant string) *TraceData {
+	trace := newTraceStringAttrs(map[string]pdata.AttributeValue{}, "tenant.id", tenant)
+	trace.SpanCount = 1
+	return trace
+}
+
+func TestStratifiedFilter(t *testing.T) {
+	pe := newStratifiedPolicy(t, &config.StratifiedCfg{Key: "tenant.id", MinTracesPerMinute: 2}, 1000)
+
+	evaluate(t, *pe, newTenantTrace("big"), Sampled)
+	evaluate(t, *pe, newTenantTrace("big"), Sampled)
+	evaluate(t, *pe, newTenantTrace("big"), NotSampled)
+	evaluate(t, *pe, newTenantTrace("small"), Sampled)
+
+	// Traces without the attribute are never selected
+	evaluate(t, *pe, newTraceStringAttrs(map[string]pdata.AttributeValue{}, "other", "big"), NotSampled)
+
+	// Resource attributes and non-string values are accepted as well
+	trace := newTraceStringAttrs(map[string]pdata.AttributeValue{"tenant.id": pdata.NewAttributeValueInt(7)}, "", "")
+	trace.SpanCount = 1
+	evaluate(t, *pe, trace, Sampled)
+	assert.Equal(t, int64(1), pe.stratified.tracesInCurrentMinute["7"])
+}
+
+func TestStratifiedFilterMinuteReset(t *testing.T) {
+	sf, err := createStratifiedFilter(&config.StratifiedCfg{Key: "tenant.id", MinTracesPerMinute: 1})
+	require.NoError(t, err)
+
+	assert.True(t, sf.needsCoverage(1, "a"))
+	sf.consume(1, "a")
+	assert.False(t, sf.needsCoverage(1, "a"))
+	assert.True(t, sf.needsCoverage(2, "a"))
+}
+
+func TestStratifiedFilterMaxValues(t *testing.T) {
+	pe := newStratifiedPolicy(t, &config.StratifiedCfg{Key: "tenant.id", MinTracesPerMinute: 5, MaxValues: 1}, 1000)
+
+	evaluate(t, *pe, newTenantTrace("first"), Sampled)
+	evaluate(t, *pe, newTenantTrace("second"), NotSampled)
+	evaluate(t, *pe, newTenantTrace("first"), Sampled)
+}
+
+func TestStratifiedFilterCountsOnlySampled(t *testing.T) {
+	pe := newStratifiedPolicy(t, &config.StratifiedCfg{Key: "tenant.id", MinTracesPerMinute: 1}, 1)
+
+	// Does not fit the policy budget, so the minimum of "a" is still not covered
+	trace := newTenantTrace("a")
+	trace.SpanCount = 2
+	evaluate(t, *pe, trace, NotSampled)
+	assert.Equal(t, int64(0), pe.stratified.tracesInCurrentMinute["a"])
+	evaluate(t, *pe, newTenantTrace("a"), Sampled)
+	assert.Equal(t, int64(1), pe.stratified.tracesInCurrentMinute["a"])
+}
+
+func TestStratifiedInvalidConfig(t *testing.T) {
+	_, err := createStratifiedFilter(&config.StratifiedCfg{MinTracesPerMinute: 1})
+	assert.Error(t, err)
+	_, err = createStratifiedFilter(&config.StratifiedCfg{Key: "tenant.id"})
+	assert.Error(t, err)
+}
@@ -27,8 +27,8 @@ func newStringAttributeFilter() *policyEvaluator {
 	return &policyEvaluator{
 		logger: zap.NewNop(),
 		stringAttr: &stringAttributeFilter{
-			key:    "example",
-			values: map[string]struct{}{"value": {}},
+			key:     "example",
+			matcher: newHashSetMatcher([]string{"value"}),
 		},
 		maxSpansPerSecond: math.MaxInt64,
 	}
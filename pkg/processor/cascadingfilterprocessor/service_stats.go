@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+)
+
+const (
+	serviceNameAttribute = "service.name"
+	unknownServiceName   = "unknown"
+	otherServicesName    = "other"
+
+	defaultServiceStatsInterval    = time.Minute
+	defaultServiceStatsMaxServices = 100
+)
+
+type serviceCounters struct {
+	seen, sampled, dropped int64
+}
+
+// serviceStats aggregates the final decisions per service.name over a time window
+type serviceStats struct {
+	sync.Mutex
+	ctx         context.Context
+	logger      *zap.Logger
+	interval    time.Duration
+	maxServices int
+	windowStart time.Time
+	counters    map[string]*serviceCounters
+}
+
+func newServiceStats(ctx context.Context, logger *zap.Logger, interval time.Duration, maxServices int) *serviceStats {
+	if interval <= 0 {
+		interval = defaultServiceStatsInterval
+	}
+	if maxServices <= 0 {
+		maxServices = defaultServiceStatsMaxServices
+	}
+	return &serviceStats{
+		ctx:         ctx,
+		logger:      logger,
+		interval:    interval,
+		maxServices: maxServices,
+		windowStart: time.Now(),
+		counters:    make(map[string]*serviceCounters),
+	}
+}
+
+// record accounts a trace with given final decision. Services above maxServices are reported as "other".
+func (ss *serviceStats) record(service string, sampled bool) {
+	ss.Lock()
+	defer ss.Unlock()
+
+	counters, ok := ss.counters[service]
+	if !ok {
+		if len(ss.counters) >= ss.maxServices {
+			service = otherServicesName
+			counters = ss.counters[service]
+		}
+		if counters == nil {
+			counters = &serviceCounters{}
+			ss.counters[service] = counters
+		}
+	}
+
+	counters.seen++
+	if sampled {
+		counters.sampled++
+	} else {
+		counters.dropped++
+	}
+}
+
+// flush emits the statistics for the current window and starts a new one
+func (ss *serviceStats) flush() {
+	ss.Lock()
+	counters := ss.counters
+	windowStart := ss.windowStart
+	ss.counters = make(map[string]*serviceCounters, len(counters))
+	ss.windowStart = time.Now()
+	ss.Unlock()
+
+	services := make([]string, 0, len(counters))
+	for service := range counters {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	window := time.Since(windowStart)
+	for _, service := range services {
+		c := counters[service]
+		ss.logger.Info("Cascading filter service statistics",
+			zap.String(serviceNameAttribute, service),
+			zap.Duration("window", window),
+			zap.Int64("traces.seen", c.seen),
+			zap.Int64("traces.sampled", c.sampled),
+			zap.Int64("traces.dropped", c.dropped),
+		)
+
+		ss.recordMetric(service, statusSampled, c.sampled)
+		ss.recordMetric(service, statusNotSampled, c.dropped)
+	}
+}
+
+func (ss *serviceStats) recordMetric(service string, decision string, count int64) {
+	if count == 0 {
+		return
+	}
+	err := stats.RecordWithTags(
+		ss.ctx,
+		[]tag.Mutator{tag.Insert(tagServiceKey, service), tag.Insert(tagCascadingFilterDecisionKey, decision)},
+		statServiceTracesCount.M(count),
+	)
+	if err != nil {
+		ss.logger.Error("Recording service statistics error", zap.Error(err))
+	}
+}
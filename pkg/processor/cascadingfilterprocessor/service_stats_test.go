@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+)
+
+func TestServiceStatsFlush(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	ss := newServiceStats(context.Background(), zap.New(core), time.Minute, 2)
+
+	ss.record("checkout", true)
+	ss.record("checkout", false)
+	ss.record("checkout", false)
+	ss.record("cart", true)
+	// Above max_services, hence reported as "other"
+	ss.record("payments", false)
+	ss.record("shipping", true)
+
+	ss.flush()
+
+	entries := logs.All()
+	require.Len(t, entries, 3)
+	expected := []struct {
+		service                string
+		seen, sampled, dropped int64
+	}{
+		{"cart", 1, 1, 0},
+		{"checkout", 3, 1, 2},
+		{"other", 2, 1, 1},
+	}
+	for i, e := range expected {
+		fields := entries[i].ContextMap()
+		assert.Equal(t, e.service, fields["service.name"])
+		assert.Equal(t, e.seen, fields["traces.seen"])
+		assert.Equal(t, e.sampled, fields["traces.sampled"])
+		assert.Equal(t, e.dropped, fields["traces.dropped"])
+	}
+
+	// The window is reset after flush
+	ss.flush()
+	assert.Len(t, logs.All(), 3)
+}
+
+func TestServiceStatsRecordedOnDecision(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	cfg := config.Config{
+		DecisionWait:    defaultTestDecisionWait,
+		NumTraces:       10,
+		ServiceStatsCfg: &config.ServiceStatsCfg{},
+	}
+	sp, err := newTraceProcessor(zap.New(core), consumertest.NewNop(), cfg, testDrain)
+	require.NoError(t, err)
+	tsp := sp.(*cascadingFilterSpanProcessor)
+	require.Equal(t, defaultServiceStatsInterval, tsp.serviceStats.interval)
+
+	tsp.decisionBatcher = newSyncIDBatcher(1)
+	tsp.policyTicker = &manualTTicker{}
+	tsp.statsTicker = &manualTTicker{}
+	tsp.policies = []*Policy{{Name: "mock-policy", Evaluator: &mockPolicyEvaluator{NextDecision: sampling.NotSampled}, ctx: context.TODO()}}
+
+	td := simpleTraces()
+	td.ResourceSpans().At(0).Resource().Attributes().InsertString("service.name", "checkout")
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), td))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+
+	require.NoError(t, tsp.Shutdown(context.Background()))
+	entries := logs.FilterMessage("Cascading filter service statistics").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "checkout", entries[0].ContextMap()["service.name"])
+	assert.Equal(t, int64(1), entries[0].ContextMap()["traces.dropped"])
+}
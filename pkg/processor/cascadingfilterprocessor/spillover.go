@@ -0,0 +1,283 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.opencensus.io/stats"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+
+	cfconfig "github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+)
+
+const spilloverStorageName = "traces"
+
+var (
+	spilloverMarshaler   = otlp.NewProtobufTracesMarshaler()
+	spilloverUnmarshaler = otlp.NewProtobufTracesUnmarshaler()
+)
+
+// spillover moves the span data of undecided traces to a storage extension and brings it back
+// when the decision is made. The caller is responsible for holding the trace lock, except for writeChunk,
+// which does the storage I/O and is called without any locks held.
+type spillover struct {
+	storageID   config.ComponentID
+	processorID config.ComponentID
+	maxTraces   int64
+	client      storage.Client
+
+	spilledTraces int64
+
+	// pendingChunks holds the chunks taken from the traces which are not written to the storage yet,
+	// so they are restored from memory if the decision comes first
+	pendingChunks map[string]*pendingChunk
+	pendingLock   sync.Mutex
+}
+
+// pendingChunk is the span data of a trace waiting to be written to the storage
+type pendingChunk struct {
+	traces pdata.Traces
+	data   []byte
+	size   int64
+}
+
+func newSpillover(cfg *cfconfig.Config) (*spillover, error) {
+	storageID, err := config.NewIDFromString(cfg.SpilloverCfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spillover storage %q: %w", cfg.SpilloverCfg.Storage, err)
+	}
+	processorID := config.NewID(typeStr)
+	if cfg.ProcessorSettings != nil {
+		processorID = cfg.ID()
+	}
+	maxTraces := cfg.SpilloverCfg.MaxTraces
+	if maxTraces == 0 {
+		maxTraces = cfg.NumTraces
+	}
+	return &spillover{
+		storageID:   storageID,
+		processorID: processorID,
+		maxTraces:   int64(maxTraces),
+
+		pendingChunks: map[string]*pendingChunk{},
+	}, nil
+}
+
+func (s *spillover) start(ctx context.Context, host component.Host) error {
+	ext, found := host.GetExtensions()[s.storageID]
+	if !found {
+		return fmt.Errorf("spillover storage extension %q not found", s.storageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("extension %q is not a storage extension", s.storageID)
+	}
+	client, err := storageExt.GetClient(ctx, component.KindProcessor, s.processorID, spilloverStorageName)
+	if err != nil {
+		return fmt.Errorf("failed to get spillover storage client: %w", err)
+	}
+	s.client = client
+	return nil
+}
+
+func (s *spillover) shutdown(ctx context.Context) error {
+	if s.client == nil {
+		return nil
+	}
+	return s.client.Close(ctx)
+}
+
+// canSpill returns true if there's still room for spilling a trace which is not in the storage yet
+func (s *spillover) canSpill(trace *sampling.TraceData) bool {
+	return s.client != nil && (trace.SpilledChunks > 0 || atomic.LoadInt64(&s.spilledTraces) < s.maxTraces)
+}
+
+func (s *spillover) numSpilledTraces() int64 {
+	return atomic.LoadInt64(&s.spilledTraces)
+}
+
+func spilloverKey(id traceKey, chunk int) string {
+	return fmt.Sprintf("%x-%d", id[:], chunk)
+}
+
+// takeChunk moves the received batches of the trace to a new chunk, which is written to the storage by writeChunk
+// once the locks are released. Returns the key of the chunk, or an empty key when there's nothing to spill.
+func (s *spillover) takeChunk(id traceKey, trace *sampling.TraceData) (string, error) {
+	if len(trace.ReceivedBatches) == 0 {
+		return "", nil
+	}
+
+	chunk := pdata.NewTraces()
+	for _, batch := range trace.ReceivedBatches {
+		batch.ResourceSpans().MoveAndAppendTo(chunk.ResourceSpans())
+	}
+	data, err := spilloverMarshaler.MarshalTraces(chunk)
+	if err != nil {
+		// Keep the data in memory, so the trace is not lost
+		trace.ReceivedBatches = []pdata.Traces{chunk}
+		return "", err
+	}
+
+	key := spilloverKey(id, trace.SpilledChunks)
+	s.pendingLock.Lock()
+	s.pendingChunks[key] = &pendingChunk{traces: chunk, data: data, size: trace.SizeBytes}
+	s.pendingLock.Unlock()
+
+	if trace.SpilledChunks == 0 {
+		atomic.AddInt64(&s.spilledTraces, 1)
+	}
+	trace.SpilledChunks++
+	trace.ReceivedBatches = nil
+	trace.SizeBytes = 0
+	return key, nil
+}
+
+// writeChunk writes the chunk taken by takeChunk to the storage. Returns the size of the span data which is
+// no longer kept in memory. When writing fails, the chunk stays in memory until the trace is restored or discarded.
+func (s *spillover) writeChunk(ctx context.Context, key string) (int64, error) {
+	s.pendingLock.Lock()
+	chunk, ok := s.pendingChunks[key]
+	s.pendingLock.Unlock()
+	if !ok {
+		return 0, nil
+	}
+
+	err := s.client.Set(ctx, key, chunk.data)
+
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+	if s.pendingChunks[key] != chunk {
+		// The trace was restored or discarded while writing, so the chunk is not needed anymore
+		if err == nil {
+			err = s.client.Delete(ctx, key)
+		}
+		return 0, err
+	}
+	if err != nil {
+		return 0, err
+	}
+	delete(s.pendingChunks, key)
+	return chunk.size, nil
+}
+
+// takePending removes the chunks of the trace not written to the storage yet and returns them by their index
+func (s *spillover) takePending(id traceKey, trace *sampling.TraceData) map[int]*pendingChunk {
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+
+	pending := map[int]*pendingChunk{}
+	for i := 0; i < trace.SpilledChunks; i++ {
+		key := spilloverKey(id, i)
+		if chunk, ok := s.pendingChunks[key]; ok {
+			pending[i] = chunk
+			delete(s.pendingChunks, key)
+		}
+	}
+	return pending
+}
+
+// restore reads all spilled chunks of the trace back to its received batches and removes them from the storage.
+// The chunks which cannot be read are dropped and counted, so the rest of the trace is still restored.
+func (s *spillover) restore(ctx context.Context, id traceKey, trace *sampling.TraceData) error {
+	if trace.SpilledChunks == 0 {
+		return nil
+	}
+
+	pending := s.takePending(id, trace)
+	ops := make([]storage.Operation, 0, trace.SpilledChunks-len(pending))
+	for i := 0; i < trace.SpilledChunks; i++ {
+		if _, ok := pending[i]; !ok {
+			ops = append(ops, storage.GetOperation(spilloverKey(id, i)))
+		}
+	}
+	if len(ops) > 0 {
+		if err := s.client.Batch(ctx, ops...); err != nil {
+			// The chunks stay in the storage until the trace is discarded
+			s.returnPending(id, pending)
+			return err
+		}
+	}
+
+	restored := make([]pdata.Traces, 0, trace.SpilledChunks+len(trace.ReceivedBatches))
+	var dropped int
+	var unmarshalErr error
+	for i := 0; i < trace.SpilledChunks; i++ {
+		if chunk, ok := pending[i]; ok {
+			restored = append(restored, chunk.traces)
+			// The chunk was never written, so its size is still accounted as buffered
+			trace.SizeBytes += chunk.size
+			continue
+		}
+		op := ops[0]
+		ops = ops[1:]
+		if op.Value == nil {
+			continue
+		}
+		chunk, err := spilloverUnmarshaler.UnmarshalTraces(op.Value)
+		if err != nil {
+			dropped++
+			unmarshalErr = err
+			continue
+		}
+		restored = append(restored, chunk)
+	}
+	trace.ReceivedBatches = append(restored, trace.ReceivedBatches...)
+
+	if dropped > 0 {
+		stats.Record(ctx, statSpilledChunksDroppedCount.M(int64(dropped)))
+		unmarshalErr = fmt.Errorf("dropped %d of %d spilled chunks: %w", dropped, trace.SpilledChunks, unmarshalErr)
+	}
+	if err := s.discard(ctx, id, trace); err != nil && unmarshalErr == nil {
+		return err
+	}
+	return unmarshalErr
+}
+
+// returnPending puts back the chunks taken by takePending
+func (s *spillover) returnPending(id traceKey, pending map[int]*pendingChunk) {
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+	for i, chunk := range pending {
+		s.pendingChunks[spilloverKey(id, i)] = chunk
+	}
+}
+
+// discard removes all spilled chunks of the trace from the storage. The size of the chunks not written yet
+// is added back to the trace, so it's released along with the trace.
+func (s *spillover) discard(ctx context.Context, id traceKey, trace *sampling.TraceData) error {
+	if trace.SpilledChunks == 0 {
+		return nil
+	}
+
+	for _, chunk := range s.takePending(id, trace) {
+		trace.SizeBytes += chunk.size
+	}
+	ops := make([]storage.Operation, trace.SpilledChunks)
+	for i := range ops {
+		ops[i] = storage.DeleteOperation(spilloverKey(id, i))
+	}
+	trace.SpilledChunks = 0
+	atomic.AddInt64(&s.spilledTraces, -1)
+	return s.client.Batch(ctx, ops...)
+}
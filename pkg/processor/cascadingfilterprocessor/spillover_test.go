@@ -0,0 +1,294 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	otelconfig "go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/sampling"
+)
+
+type mapStorageClient struct {
+	sync.Mutex
+	data map[string][]byte
+}
+
+func (c *mapStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.data[key], nil
+}
+
+func (c *mapStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.Lock()
+	defer c.Unlock()
+	c.data[key] = value
+	return nil
+}
+
+func (c *mapStorageClient) Delete(_ context.Context, key string) error {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.data, key)
+	return nil
+}
+
+func (c *mapStorageClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	for _, op := range ops {
+		var err error
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = c.Get(ctx, op.Key)
+		case storage.Set:
+			err = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = c.Delete(ctx, op.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *mapStorageClient) Close(context.Context) error {
+	return nil
+}
+
+func (c *mapStorageClient) len() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.data)
+}
+
+type mapStorageExtension struct {
+	client *mapStorageClient
+}
+
+func (e *mapStorageExtension) Start(context.Context, component.Host) error {
+	return nil
+}
+
+func (e *mapStorageExtension) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *mapStorageExtension) GetClient(context.Context, component.Kind, otelconfig.ComponentID, string) (storage.Client, error) {
+	return e.client, nil
+}
+
+type storageHost struct {
+	component.Host
+	extensions map[otelconfig.ComponentID]component.Extension
+}
+
+func (h *storageHost) GetExtensions() map[otelconfig.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func newSpilloverTestProcessor(t *testing.T, msp *consumertest.TracesSink, maxTraces uint64) (*cascadingFilterSpanProcessor, *mapStorageClient) {
+	client := &mapStorageClient{data: map[string][]byte{}}
+	host := &storageHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[otelconfig.ComponentID]component.Extension{otelconfig.NewID("test_storage"): &mapStorageExtension{client: client}},
+	}
+
+	spill, err := newSpillover(&config.Config{
+		NumTraces:    10,
+		SpilloverCfg: &config.SpilloverCfg{Storage: "test_storage", MaxTraces: maxTraces},
+	})
+	require.NoError(t, err)
+
+	tsp := &cascadingFilterSpanProcessor{
+		ctx:               context.Background(),
+		nextConsumer:      msp,
+		maxNumTraces:      10,
+		logger:            zap.NewNop(),
+		decisionBatcher:   newSyncIDBatcher(1),
+		policies:          []*Policy{{Name: "mock-policy", Evaluator: &mockPolicyEvaluator{NextDecision: sampling.Sampled}, ctx: context.TODO()}},
+		deleteChan:        make(chan traceKey, 10),
+		policyTicker:      &manualTTicker{},
+		maxSpansPerSecond: 10000,
+		maxBufferedBytes:  bytesInMiB,
+		spillover:         spill,
+		drain:             testDrain,
+	}
+	require.NoError(t, tsp.Start(context.Background(), host))
+	return tsp, client
+}
+
+func TestSpilloverKeepsTracesForDecision(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp, client := newSpilloverTestProcessor(t, msp, 0)
+
+	traceIDs := make([]pdata.TraceID, 5)
+	for i := range traceIDs {
+		traceIDs[i] = pdata.NewTraceID([16]byte{byte(i + 1)})
+	}
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(traceIDs[0])))
+	traceSize := atomic.LoadInt64(&tsp.bufferedBytes)
+	tsp.maxBufferedBytes = 3 * traceSize
+	for _, traceID := range traceIDs[1:] {
+		require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(traceID)))
+	}
+
+	// The two oldest traces are moved to the storage instead of being evicted
+	assert.Equal(t, 3*traceSize, atomic.LoadInt64(&tsp.bufferedBytes))
+	assert.Equal(t, 2, client.len())
+	assert.Equal(t, int64(2), tsp.spillover.numSpilledTraces())
+	for _, traceID := range traceIDs {
+		_, ok := tsp.idToTrace.Load(traceKey(traceID.Bytes()))
+		assert.True(t, ok)
+	}
+
+	// Late spans of a spilled trace are added to it as usual
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(traceIDs[0])))
+
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	assert.Equal(t, 6, msp.SpanCount())
+	assert.Equal(t, 0, client.len())
+	assert.Equal(t, int64(0), tsp.spillover.numSpilledTraces())
+	assert.Equal(t, int64(0), atomic.LoadInt64(&tsp.bufferedBytes))
+	require.NoError(t, tsp.Shutdown(context.Background()))
+}
+
+func TestSpilloverFallsBackToEviction(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp, client := newSpilloverTestProcessor(t, msp, 1)
+
+	traceIDs := make([]pdata.TraceID, 5)
+	for i := range traceIDs {
+		traceIDs[i] = pdata.NewTraceID([16]byte{byte(i + 1)})
+	}
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(traceIDs[0])))
+	tsp.maxBufferedBytes = 3 * atomic.LoadInt64(&tsp.bufferedBytes)
+	for _, traceID := range traceIDs[1:] {
+		require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(traceID)))
+	}
+
+	// Only one trace fits in the storage, the other one is decided immediately
+	assert.Equal(t, 1, client.len())
+	assert.Equal(t, 1, msp.SpanCount())
+	_, ok := tsp.idToTrace.Load(traceKey(traceIDs[1].Bytes()))
+	assert.False(t, ok)
+}
+
+func TestSpilloverRequiresMemoryLimit(t *testing.T) {
+	cfg := config.Config{
+		DecisionWait: defaultTestDecisionWait,
+		NumTraces:    100,
+		SpilloverCfg: &config.SpilloverCfg{Storage: "file_storage"},
+	}
+	_, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg, testDrain)
+	require.Error(t, err)
+}
+
+func TestSpilloverMissingStorage(t *testing.T) {
+	spill, err := newSpillover(&config.Config{SpilloverCfg: &config.SpilloverCfg{Storage: "file_storage"}})
+	require.NoError(t, err)
+	require.Error(t, spill.start(context.Background(), componenttest.NewNopHost()))
+}
+
+func TestSpilloverCleanedUpOnShutdown(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp, client := newSpilloverTestProcessor(t, msp, 0)
+
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTraces()))
+	tsp.maxBufferedBytes = 1
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(pdata.NewTraceID([16]byte{5}))))
+	require.Equal(t, 2, client.len())
+
+	require.NoError(t, tsp.Shutdown(context.Background()))
+	assert.Equal(t, 0, client.len())
+}
+
+func newTestSpillover(t *testing.T) (*spillover, *mapStorageClient) {
+	spill, err := newSpillover(&config.Config{NumTraces: 10, SpilloverCfg: &config.SpilloverCfg{Storage: "test_storage"}})
+	require.NoError(t, err)
+	client := &mapStorageClient{data: map[string][]byte{}}
+	spill.client = client
+	return spill, client
+}
+
+func spillChunk(t *testing.T, spill *spillover, id traceKey, trace *sampling.TraceData) {
+	trace.ReceivedBatches = append(trace.ReceivedBatches, simpleTracesWithID(pdata.NewTraceID(id)))
+	key, err := spill.takeChunk(id, trace)
+	require.NoError(t, err)
+	_, err = spill.writeChunk(context.Background(), key)
+	require.NoError(t, err)
+}
+
+func TestSpilloverRestoreDropsCorruptChunk(t *testing.T) {
+	spill, client := newTestSpillover(t)
+	id := traceKey(pdata.NewTraceID([16]byte{1}).Bytes())
+	trace := &sampling.TraceData{}
+
+	spillChunk(t, spill, id, trace)
+	spillChunk(t, spill, id, trace)
+	spillChunk(t, spill, id, trace)
+	require.Equal(t, 3, client.len())
+	client.data[spilloverKey(id, 1)] = []byte("corrupt")
+
+	// The readable chunks are restored, the corrupt one is reported
+	err := spill.restore(context.Background(), id, trace)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dropped 1 of 3 spilled chunks")
+	require.Len(t, trace.ReceivedBatches, 2)
+	assert.Equal(t, 2, trace.ReceivedBatches[0].SpanCount()+trace.ReceivedBatches[1].SpanCount())
+	assert.Equal(t, 0, trace.SpilledChunks)
+	assert.Equal(t, 0, client.len())
+	assert.Equal(t, int64(0), spill.numSpilledTraces())
+}
+
+func TestSpilloverRestoresChunkNotWrittenYet(t *testing.T) {
+	spill, client := newTestSpillover(t)
+	id := traceKey(pdata.NewTraceID([16]byte{1}).Bytes())
+	trace := &sampling.TraceData{
+		ReceivedBatches: []pdata.Traces{simpleTraces()},
+		SizeBytes:       100,
+	}
+
+	key, err := spill.takeChunk(id, trace)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), trace.SizeBytes)
+
+	// The decision comes before the chunk is written, so it's restored from memory
+	require.NoError(t, spill.restore(context.Background(), id, trace))
+	require.Len(t, trace.ReceivedBatches, 1)
+	assert.Equal(t, int64(100), trace.SizeBytes)
+
+	// The late write doesn't leave the chunk behind in the storage
+	size, err := spill.writeChunk(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+	assert.Equal(t, 0, client.len())
+}
@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoipprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the GeoIP enrichment.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Databases are the paths of the MaxMind DB files the addresses are looked up in, e.g. a City and an ASN one.
+	// The results of all the databases are merged.
+	Databases []string `mapstructure:"databases"`
+	// Attributes are the log record or span attributes holding the IP addresses
+	Attributes []AttributeConfig `mapstructure:"attributes"`
+	// Language is the language of the country, region and city names
+	Language string `mapstructure:"language"`
+	// ReloadInterval is how often the databases are checked for changes and reloaded. Zero disables reloading.
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// AttributeConfig is the attribute holding the IP address, and the prefix of the attributes
+// the location of the address is placed into
+type AttributeConfig struct {
+	// Attribute is the name of the attribute holding the IP address
+	Attribute string `mapstructure:"attribute"`
+	// Prefix is prepended to the names of the location attributes. The attribute name followed by a dot is used
+	// when it is empty.
+	Prefix string `mapstructure:"prefix"`
+}
+
+const (
+	defaultLanguage       = "en"
+	defaultReloadInterval = time.Minute
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Databases) == 0 {
+		return errors.New("at least one database must be configured")
+	}
+	for i, path := range cfg.Databases {
+		if path == "" {
+			return fmt.Errorf("database %d: path must be set", i)
+		}
+	}
+	if len(cfg.Attributes) == 0 {
+		return errors.New("at least one attribute must be configured")
+	}
+	for i, attr := range cfg.Attributes {
+		if attr.Attribute == "" {
+			return fmt.Errorf("attribute %d: attribute name must be set", i)
+		}
+	}
+	if cfg.Language == "" {
+		return errors.New("language must be set")
+	}
+	if cfg.ReloadInterval < 0 {
+		return errors.New("reload_interval must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoipprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "geoip_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			Databases: []string{
+				"/var/lib/GeoIP/GeoLite2-City.mmdb",
+				"/var/lib/GeoIP/GeoLite2-ASN.mmdb",
+			},
+			Attributes: []AttributeConfig{
+				{Attribute: "client.ip", Prefix: "client."},
+				{Attribute: "net.peer.ip"},
+			},
+			Language:       "de",
+			ReloadInterval: 10 * time.Minute,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "valid", modify: func(cfg *Config) {}},
+		{name: "reloading disabled", modify: func(cfg *Config) { cfg.ReloadInterval = 0 }},
+		{
+			name:    "no databases",
+			modify:  func(cfg *Config) { cfg.Databases = nil },
+			wantErr: "at least one database must be configured",
+		},
+		{
+			name:    "empty database path",
+			modify:  func(cfg *Config) { cfg.Databases = append(cfg.Databases, "") },
+			wantErr: "database 1: path must be set",
+		},
+		{
+			name:    "no attributes",
+			modify:  func(cfg *Config) { cfg.Attributes = nil },
+			wantErr: "at least one attribute must be configured",
+		},
+		{
+			name:    "attribute without name",
+			modify:  func(cfg *Config) { cfg.Attributes = []AttributeConfig{{Prefix: "client."}} },
+			wantErr: "attribute 0: attribute name must be set",
+		},
+		{
+			name:    "no language",
+			modify:  func(cfg *Config) { cfg.Language = "" },
+			wantErr: "language must be set",
+		},
+		{
+			name:    "negative reload interval",
+			modify:  func(cfg *Config) { cfg.ReloadInterval = -time.Second },
+			wantErr: "reload_interval must not be negative",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Databases = []string{"GeoLite2-City.mmdb"}
+			cfg.Attributes = []AttributeConfig{{Attribute: "client.ip"}}
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoipprocessor
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// database is a MaxMind DB file, which can be reloaded when it changes on disk
+type database struct {
+	path string
+
+	lock    sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+	size    int64
+}
+
+func openDatabase(path string) (*database, error) {
+	db := &database{path: path}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	db.reader, db.modTime, db.size = reader, info.ModTime(), info.Size()
+	return db, nil
+}
+
+// reloadIfChanged opens the database again if its file has been modified since it was opened.
+// It returns whether the database has been reloaded. The old reader is kept when the new file can't be opened.
+func (db *database) reloadIfChanged() (bool, error) {
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return false, err
+	}
+
+	db.lock.RLock()
+	unchanged := info.ModTime().Equal(db.modTime) && info.Size() == db.size
+	db.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	reader, err := maxminddb.Open(db.path)
+	if err != nil {
+		return false, err
+	}
+
+	db.lock.Lock()
+	old := db.reader
+	db.reader, db.modTime, db.size = reader, info.ModTime(), info.Size()
+	db.lock.Unlock()
+
+	return true, old.Close()
+}
+
+// lookup decodes the record of the address into result, keeping the fields missing in the record.
+// It returns whether the address has been found.
+func (db *database) lookup(ip net.IP, result interface{}) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	_, ok, err := db.reader.LookupNetwork(ip, result)
+	return ok, err
+}
+
+func (db *database) close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	return db.reader.Close()
+}
@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoipprocessor
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configcheck"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg := createDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configcheck.ValidateConfig(cfg))
+}
+
+func TestCreateProcessors(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Databases = []string{path.Join("testdata", "GeoIP2-City-Test.mmdb")}
+	cfg.Attributes = []AttributeConfig{{Attribute: "client.ip"}}
+	params := component.ProcessorCreateSettings{Logger: zap.NewNop()}
+
+	lp, err := factory.CreateLogsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err, "cannot create logs processor")
+	require.NoError(t, lp.Start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, lp.Shutdown(context.Background()))
+
+	tp, err := factory.CreateTracesProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err, "cannot create traces processor")
+	require.NoError(t, tp.Start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, tp.Shutdown(context.Background()))
+}
+
+func TestInvalidConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{name: "no databases", modify: func(cfg *Config) { cfg.Databases = nil }},
+		{name: "no attributes", modify: func(cfg *Config) { cfg.Attributes = nil }},
+		{name: "negative reload interval", modify: func(cfg *Config) { cfg.ReloadInterval = -1 }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Databases = []string{path.Join("testdata", "GeoIP2-City-Test.mmdb")}
+			cfg.Attributes = []AttributeConfig{{Attribute: "client.ip"}}
+			tc.modify(cfg)
+			params := component.ProcessorCreateSettings{Logger: zap.NewNop()}
+			_, err := NewFactory().CreateLogsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+			assert.Error(t, err)
+		})
+	}
+}
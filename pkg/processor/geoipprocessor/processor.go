@@ -0,0 +1,268 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoipprocessor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// The names of the attributes the location of the address is placed into, without the prefix.
+const (
+	countryISOCodeAttribute     = "geo.country_iso_code"
+	countryNameAttribute        = "geo.country_name"
+	regionISOCodeAttribute      = "geo.region_iso_code"
+	regionNameAttribute         = "geo.region_name"
+	cityNameAttribute           = "geo.city_name"
+	postalCodeAttribute         = "geo.postal_code"
+	latitudeAttribute           = "geo.location.lat"
+	longitudeAttribute          = "geo.location.lon"
+	asNumberAttribute           = "as.number"
+	asOrganizationNameAttribute = "as.organization.name"
+)
+
+// geoRecord holds the used fields of the GeoIP2/GeoLite2 City, Country and ASN databases,
+// and the compatible ones
+type geoRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Postal struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"postal"`
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+	ASNumber       uint   `maxminddb:"autonomous_system_number"`
+	ASOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// geoIPProcessor adds the location of the IP addresses found in the attributes of the log records and spans
+type geoIPProcessor struct {
+	logger         *zap.Logger
+	paths          []string
+	attributes     []AttributeConfig
+	language       string
+	reloadInterval time.Duration
+
+	databases []*database
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+func newGeoIPProcessor(logger *zap.Logger, cfg *Config) (*geoIPProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	gp := &geoIPProcessor{
+		logger:         logger,
+		paths:          cfg.Databases,
+		language:       cfg.Language,
+		reloadInterval: cfg.ReloadInterval,
+	}
+	for _, attr := range cfg.Attributes {
+		if attr.Prefix == "" {
+			attr.Prefix = attr.Attribute + "."
+		}
+		gp.attributes = append(gp.attributes, attr)
+	}
+	return gp, nil
+}
+
+// Start opens the databases and starts watching them for changes
+func (gp *geoIPProcessor) Start(_ context.Context, _ component.Host) error {
+	for _, path := range gp.paths {
+		db, err := openDatabase(path)
+		if err != nil {
+			gp.closeDatabases()
+			return err
+		}
+		gp.databases = append(gp.databases, db)
+	}
+
+	if gp.reloadInterval > 0 {
+		gp.stopCh = make(chan struct{})
+		gp.done = make(chan struct{})
+		go gp.reloadLoop()
+	}
+	return nil
+}
+
+func (gp *geoIPProcessor) Shutdown(context.Context) error {
+	if gp.stopCh != nil {
+		close(gp.stopCh)
+		<-gp.done
+		gp.stopCh = nil
+	}
+	gp.closeDatabases()
+	return nil
+}
+
+func (gp *geoIPProcessor) closeDatabases() {
+	for _, db := range gp.databases {
+		if err := db.close(); err != nil {
+			gp.logger.Warn("Failed to close database", zap.String("path", db.path), zap.Error(err))
+		}
+	}
+	gp.databases = nil
+}
+
+func (gp *geoIPProcessor) reloadLoop() {
+	defer close(gp.done)
+
+	ticker := time.NewTicker(gp.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-gp.stopCh:
+			return
+		case <-ticker.C:
+			gp.reload()
+		}
+	}
+}
+
+func (gp *geoIPProcessor) reload() {
+	for _, db := range gp.databases {
+		reloaded, err := db.reloadIfChanged()
+		if err != nil {
+			gp.logger.Warn("Failed to reload database, keeping the previous version",
+				zap.String("path", db.path), zap.Error(err))
+		}
+		if reloaded {
+			gp.logger.Info("Database reloaded", zap.String("path", db.path))
+		}
+	}
+}
+
+func (gp *geoIPProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				gp.processAttributes(logs.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (gp *geoIPProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		ilss := rss.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				gp.processAttributes(spans.At(k).Attributes())
+			}
+		}
+	}
+	return td, nil
+}
+
+func (gp *geoIPProcessor) processAttributes(attrs pdata.AttributeMap) {
+	for _, attr := range gp.attributes {
+		value, ok := attrs.Get(attr.Attribute)
+		if !ok || value.Type() != pdata.AttributeValueTypeString {
+			continue
+		}
+		ip := parseIP(value.StringVal())
+		if ip == nil {
+			continue
+		}
+
+		record, found := gp.lookup(ip)
+		if found {
+			gp.setAttributes(attrs, attr.Prefix, record)
+		}
+	}
+}
+
+// lookup merges the records of the address from all the databases
+func (gp *geoIPProcessor) lookup(ip net.IP) (*geoRecord, bool) {
+	record := &geoRecord{}
+	found := false
+	for _, db := range gp.databases {
+		ok, err := db.lookup(ip, record)
+		if err != nil {
+			gp.logger.Debug("Failed to look up address", zap.String("path", db.path), zap.Error(err))
+			continue
+		}
+		found = found || ok
+	}
+	return record, found
+}
+
+func (gp *geoIPProcessor) setAttributes(attrs pdata.AttributeMap, prefix string, record *geoRecord) {
+	setString := func(name, value string) {
+		if value != "" {
+			attrs.UpsertString(prefix+name, value)
+		}
+	}
+
+	setString(countryISOCodeAttribute, record.Country.ISOCode)
+	setString(countryNameAttribute, record.Country.Names[gp.language])
+	if len(record.Subdivisions) > 0 {
+		setString(regionISOCodeAttribute, record.Subdivisions[0].ISOCode)
+		setString(regionNameAttribute, record.Subdivisions[0].Names[gp.language])
+	}
+	setString(cityNameAttribute, record.City.Names[gp.language])
+	setString(postalCodeAttribute, record.Postal.Code)
+	if record.Location.Latitude != nil && record.Location.Longitude != nil {
+		attrs.UpsertDouble(prefix+latitudeAttribute, *record.Location.Latitude)
+		attrs.UpsertDouble(prefix+longitudeAttribute, *record.Location.Longitude)
+	}
+	if record.ASNumber != 0 {
+		attrs.UpsertInt(prefix+asNumberAttribute, int64(record.ASNumber))
+	}
+	setString(asOrganizationNameAttribute, record.ASOrganization)
+}
+
+// parseIP parses the address, which may be followed by a port, or be the first one
+// of a comma separated list, like in the X-Forwarded-For header
+func parseIP(value string) net.IP {
+	if i := strings.IndexByte(value, ','); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.TrimSpace(value)
+
+	if ip := net.ParseIP(value); ip != nil {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		return net.ParseIP(host)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoipprocessor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+var (
+	cityDatabase        = path.Join("testdata", "GeoIP2-City-Test.mmdb")
+	updatedCityDatabase = path.Join("testdata", "GeoIP2-City-Test-Updated.mmdb")
+	asnDatabase         = path.Join("testdata", "GeoLite2-ASN-Test.mmdb")
+)
+
+func attributesToMap(attrs pdata.AttributeMap) map[string]string {
+	m := map[string]string{}
+	attrs.Range(func(k string, v pdata.AttributeValue) bool {
+		m[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return m
+}
+
+func startTestProcessor(t *testing.T, modify func(cfg *Config)) *geoIPProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Databases = []string{cityDatabase, asnDatabase}
+	cfg.Attributes = []AttributeConfig{{Attribute: "client.ip", Prefix: "client."}}
+	cfg.ReloadInterval = 0
+	if modify != nil {
+		modify(cfg)
+	}
+
+	gp, err := newGeoIPProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, gp.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, gp.Shutdown(context.Background()))
+	})
+	return gp
+}
+
+func TestLookup(t *testing.T) {
+	testcases := []struct {
+		name     string
+		ip       string
+		expected map[string]string
+	}{
+		{
+			name: "city and ASN",
+			ip:   "81.2.69.160",
+			expected: map[string]string{
+				"client.geo.country_iso_code": "GB",
+				"client.geo.country_name":     "United Kingdom",
+				"client.geo.region_iso_code":  "ENG",
+				"client.geo.region_name":      "England",
+				"client.geo.city_name":        "London",
+				"client.geo.postal_code":      "SW1A",
+				"client.geo.location.lat":     "51.5142",
+				"client.geo.location.lon":     "-0.0931",
+				"client.as.number":            "20712",
+				"client.as.organization.name": "Andrews & Arnold Ltd",
+			},
+		},
+		{
+			name: "IPv6 country only",
+			ip:   "2001:db8::1",
+			expected: map[string]string{
+				"client.geo.country_iso_code": "PL",
+				"client.geo.country_name":     "Poland",
+				"client.geo.location.lat":     "52",
+				"client.geo.location.lon":     "20",
+			},
+		},
+		{
+			name: "with port",
+			ip:   "81.2.69.160:54321",
+			expected: map[string]string{
+				"client.geo.country_iso_code": "GB",
+				"client.geo.country_name":     "United Kingdom",
+				"client.geo.region_iso_code":  "ENG",
+				"client.geo.region_name":      "England",
+				"client.geo.city_name":        "London",
+				"client.geo.postal_code":      "SW1A",
+				"client.geo.location.lat":     "51.5142",
+				"client.geo.location.lon":     "-0.0931",
+				"client.as.number":            "20712",
+				"client.as.organization.name": "Andrews & Arnold Ltd",
+			},
+		},
+		{
+			name: "forwarded for",
+			ip:   "2001:db8::1, 10.0.0.1",
+			expected: map[string]string{
+				"client.geo.country_iso_code": "PL",
+				"client.geo.country_name":     "Poland",
+				"client.geo.location.lat":     "52",
+				"client.geo.location.lon":     "20",
+			},
+		},
+		{name: "not found", ip: "10.0.0.1", expected: map[string]string{}},
+		{name: "not an address", ip: "unknown", expected: map[string]string{}},
+	}
+
+	gp := startTestProcessor(t, nil)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			attrs := pdata.NewAttributeMap()
+			attrs.InsertString("client.ip", tc.ip)
+			gp.processAttributes(attrs)
+
+			tc.expected["client.ip"] = tc.ip
+			assert.Equal(t, tc.expected, attributesToMap(attrs))
+		})
+	}
+}
+
+func TestLanguageAndDefaultPrefix(t *testing.T) {
+	gp := startTestProcessor(t, func(cfg *Config) {
+		cfg.Databases = []string{cityDatabase}
+		cfg.Attributes = []AttributeConfig{{Attribute: "net.peer.ip"}}
+		cfg.Language = "de"
+	})
+
+	attrs := pdata.NewAttributeMap()
+	attrs.InsertString("net.peer.ip", "81.2.69.160")
+	gp.processAttributes(attrs)
+
+	m := attributesToMap(attrs)
+	assert.Equal(t, "Vereinigtes Königreich", m["net.peer.ip.geo.country_name"])
+	assert.Equal(t, "GB", m["net.peer.ip.geo.country_iso_code"])
+	assert.NotContains(t, m, "net.peer.ip.as.number")
+}
+
+func TestProcessLogsAndTraces(t *testing.T) {
+	gp := startTestProcessor(t, func(cfg *Config) {
+		cfg.Attributes = []AttributeConfig{
+			{Attribute: "client.ip", Prefix: "client."},
+			{Attribute: "server.ip", Prefix: "server."},
+		}
+	})
+
+	ld := pdata.NewLogs()
+	logs := ld.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	attrs := logs.AppendEmpty().Attributes()
+	attrs.InsertString("client.ip", "81.2.69.160")
+	attrs.InsertString("server.ip", "2001:db8::1")
+	logs.AppendEmpty().Attributes().InsertInt("client.ip", 1)
+
+	ld, err := gp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+	logs = ld.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
+	m := attributesToMap(logs.At(0).Attributes())
+	assert.Equal(t, "London", m["client.geo.city_name"])
+	assert.Equal(t, "PL", m["server.geo.country_iso_code"])
+	assert.Equal(t, map[string]string{"client.ip": "1"}, attributesToMap(logs.At(1).Attributes()))
+
+	td := pdata.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().InsertString("client.ip", "81.2.69.160")
+
+	td, err = gp.ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+	span = td.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0)
+	assert.Equal(t, "20712", attributesToMap(span.Attributes())["client.as.number"])
+}
+
+func TestStartFailure(t *testing.T) {
+	testcases := []struct {
+		name     string
+		database string
+	}{
+		{name: "missing file", database: path.Join("testdata", "missing.mmdb")},
+		{name: "invalid file", database: path.Join("testdata", "geoip_config.yaml")},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Databases = []string{cityDatabase, tc.database}
+			cfg.Attributes = []AttributeConfig{{Attribute: "client.ip"}}
+			gp, err := newGeoIPProcessor(zap.NewNop(), cfg)
+			require.NoError(t, err)
+
+			assert.Error(t, gp.Start(context.Background(), componenttest.NewNopHost()))
+			assert.Empty(t, gp.databases)
+		})
+	}
+}
+
+func copyFile(t *testing.T, from, to string) {
+	data, err := ioutil.ReadFile(from)
+	require.NoError(t, err)
+	replaceFile(t, to, data)
+}
+
+// replaceFile replaces the file atomically, like the database updaters do. Writing the file in place
+// would change the memory mapped database.
+func replaceFile(t *testing.T, to string, data []byte) {
+	tmp := to + ".tmp"
+	require.NoError(t, ioutil.WriteFile(tmp, data, 0600))
+	require.NoError(t, os.Rename(tmp, to))
+}
+
+func cityOf(gp *geoIPProcessor, ip string) string {
+	attrs := pdata.NewAttributeMap()
+	attrs.InsertString("client.ip", ip)
+	gp.processAttributes(attrs)
+	return attributesToMap(attrs)["client.geo.city_name"]
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	database := filepath.Join(dir, "GeoIP2-City.mmdb")
+	copyFile(t, cityDatabase, database)
+
+	gp := startTestProcessor(t, func(cfg *Config) { cfg.Databases = []string{database} })
+	assert.Equal(t, "London", cityOf(gp, "81.2.69.160"))
+
+	// unchanged file
+	gp.reload()
+	assert.Equal(t, "London", cityOf(gp, "81.2.69.160"))
+
+	// invalid file keeps the previous version
+	replaceFile(t, database, []byte("not a database"))
+	gp.reload()
+	assert.Equal(t, "London", cityOf(gp, "81.2.69.160"))
+
+	copyFile(t, updatedCityDatabase, database)
+	gp.reload()
+	assert.Equal(t, "Manchester", cityOf(gp, "81.2.69.160"))
+}
+
+func TestReloadLoop(t *testing.T) {
+	dir := t.TempDir()
+	database := filepath.Join(dir, "GeoIP2-City.mmdb")
+	copyFile(t, cityDatabase, database)
+
+	gp := startTestProcessor(t, func(cfg *Config) {
+		cfg.Databases = []string{database}
+		cfg.ReloadInterval = 10 * time.Millisecond
+	})
+	assert.Equal(t, "London", cityOf(gp, "81.2.69.160"))
+
+	copyFile(t, updatedCityDatabase, database)
+	assert.Eventually(t, func() bool {
+		return cityOf(gp, "81.2.69.160") == "Manchester"
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grokprocessor
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the grok parsing.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Patterns are the grok patterns matched against the source, in order, e.g. %{IP:client.ip} %{WORD:method}
+	Patterns []string `mapstructure:"patterns"`
+	// PatternDefinitions are the custom patterns, by name, which can be used in the patterns and in each other
+	PatternDefinitions []PatternDefinition `mapstructure:"pattern_definitions"`
+	// PatternFiles are the paths of the files, or the directories of the files, with the custom patterns
+	// in the Logstash format: one pattern per line, its name followed by whitespace and its expression
+	PatternFiles []string `mapstructure:"pattern_files"`
+	// SourceAttribute is the name of the attribute holding the text to parse, the string body is parsed when empty
+	SourceAttribute string `mapstructure:"source_attribute"`
+	// BreakOnMatch stops at the first matching pattern, otherwise all of them are applied
+	BreakOnMatch bool `mapstructure:"break_on_match"`
+	// Overwrite enables replacing the existing attributes with the captured values
+	Overwrite bool `mapstructure:"overwrite"`
+	// KeepEmptyCaptures enables setting the attributes of the captures which matched an empty string
+	KeepEmptyCaptures bool `mapstructure:"keep_empty_captures"`
+	// FailureAttribute is the name of the boolean attribute set to true on the records no pattern matched,
+	// none is set when empty
+	FailureAttribute string `mapstructure:"failure_attribute"`
+}
+
+// PatternDefinition is a custom grok pattern.
+type PatternDefinition struct {
+	// Name is the name the pattern is referred to with, e.g. MYAPP_ID for %{MYAPP_ID:id}
+	Name string `mapstructure:"name"`
+	// Pattern is the expression of the pattern, which can refer to the other patterns
+	Pattern string `mapstructure:"pattern"`
+}
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Patterns) == 0 {
+		return errors.New("at least one pattern must be configured")
+	}
+	for _, definition := range cfg.PatternDefinitions {
+		if definition.Name == "" {
+			return errors.New("the names of the pattern definitions must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grokprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "grok_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			Patterns: []string{
+				`%{MYAPP_ID:request.id} %{MYAPP_REQUEST} %{NUMBER:duration:float}`,
+				`%{COMMONAPACHELOG}`,
+			},
+			PatternDefinitions: []PatternDefinition{
+				{Name: "MYAPP_ID", Pattern: `[A-Z]{3}-\d+`},
+			},
+			PatternFiles:      []string{"testdata/patterns"},
+			SourceAttribute:   "message",
+			BreakOnMatch:      false,
+			Overwrite:         true,
+			KeepEmptyCaptures: true,
+			FailureAttribute:  "grok.failure",
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "valid", cfg: Config{Patterns: []string{"%{IP:client.ip}"}}},
+		{name: "no patterns", cfg: Config{}, wantErr: true},
+		{
+			name: "unnamed definition",
+			cfg: Config{
+				Patterns:           []string{"%{IP:client.ip}"},
+				PatternDefinitions: []PatternDefinition{{Pattern: `\d+`}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grokprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configcheck"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg := createDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configcheck.ValidateConfig(cfg))
+}
+
+func TestCreateProcessor(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Patterns = []string{"%{COMMONAPACHELOG}"}
+	params := component.ProcessorCreateSettings{Logger: zap.NewNop()}
+
+	lp, err := factory.CreateLogsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	assert.NotNil(t, lp)
+	assert.NoError(t, err, "cannot create logs processor")
+}
+
+func TestInvalidConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{name: "no patterns", modify: func(cfg *Config) { cfg.Patterns = nil }},
+		{name: "unknown pattern", modify: func(cfg *Config) { cfg.Patterns = []string{"%{UNKNOWN:field}"} }},
+		{name: "unknown capture type", modify: func(cfg *Config) { cfg.Patterns = []string{"%{IP:client.ip:long}"} }},
+		{name: "invalid regex", modify: func(cfg *Config) { cfg.Patterns = []string{"%{IP:client.ip} ("} }},
+		{name: "unknown definition reference", modify: func(cfg *Config) {
+			cfg.PatternDefinitions = []PatternDefinition{{Name: "MINE", Pattern: "%{UNKNOWN}"}}
+		}},
+		{name: "missing pattern file", modify: func(cfg *Config) { cfg.PatternFiles = []string{"testdata/missing"} }},
+		{name: "malformed pattern file", modify: func(cfg *Config) { cfg.PatternFiles = []string{"testdata/grok_config.yaml"} }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Patterns = []string{"%{COMMONAPACHELOG}"}
+			tc.modify(cfg)
+			params := component.ProcessorCreateSettings{Logger: zap.NewNop()}
+			_, err := NewFactory().CreateLogsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grokprocessor
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// fieldReference matches the captures named with Logstash field references, e.g. %{IP:[client][ip]}
+	fieldReference = regexp.MustCompile(`%{(\w+):((?:\[[^\]\[]+\])+)(:\w+)?}`)
+	// semantic matches the typed captures, e.g. %{NUMBER:bytes:int}
+	semantic = regexp.MustCompile(`%{[\w-.]+:([\w-.]+):(int|float)}`)
+)
+
+// normalizePattern replaces the Logstash field references with the dotted attribute names,
+// e.g. %{IP:[client][ip]} with %{IP:client.ip}
+func normalizePattern(pattern string) string {
+	return fieldReference.ReplaceAllStringFunc(pattern, func(match string) string {
+		groups := fieldReference.FindStringSubmatch(match)
+		name := strings.Join(strings.Split(strings.Trim(groups[2], "[]"), "]["), ".")
+		return "%{" + groups[1] + ":" + name + groups[3] + "}"
+	})
+}
+
+// captureTypes returns the types of the typed captures of the pattern by their names
+func captureTypes(pattern string) map[string]string {
+	types := map[string]string{}
+	for _, match := range semantic.FindAllStringSubmatch(pattern, -1) {
+		types[match[1]] = match[2]
+	}
+	return types
+}
+
+// loadPatternFiles reads the patterns from the files, the directories are replaced with the files they contain
+func loadPatternFiles(paths []string) (map[string]string, error) {
+	patterns := map[string]string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		files := []string{path}
+		if info.IsDir() {
+			entries, err := ioutil.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			files = files[:0]
+			for _, entry := range entries {
+				if entry.Mode().IsRegular() {
+					files = append(files, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+		for _, file := range files {
+			if err := loadPatternFile(file, patterns); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return patterns, nil
+}
+
+// loadPatternFile reads the patterns of the file in the Logstash format; the empty lines and the ones
+// starting with # are skipped, the others hold the name of the pattern followed by whitespace and its expression
+func loadPatternFile(path string, patterns map[string]string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.SplitN(text, " ", 2)
+		if tab := strings.SplitN(text, "\t", 2); len(tab[0]) < len(fields[0]) {
+			fields = tab
+		}
+		if len(fields) < 2 || strings.TrimSpace(fields[1]) == "" {
+			return fmt.Errorf("%s:%d: pattern %q has no expression", path, line, fields[0])
+		}
+		patterns[fields[0]] = normalizePattern(strings.TrimSpace(fields[1]))
+	}
+	return scanner.Err()
+}
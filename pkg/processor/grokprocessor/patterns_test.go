@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grokprocessor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePattern(t *testing.T) {
+	testcases := []struct {
+		pattern  string
+		expected string
+	}{
+		{pattern: "%{IP:[client][ip]}", expected: "%{IP:client.ip}"},
+		{pattern: "%{NUMBER:[http][response][bytes]:int} %{WORD:[verb]}", expected: "%{NUMBER:http.response.bytes:int} %{WORD:verb}"},
+		{pattern: "%{IP:client.ip} %{GREEDYDATA}", expected: "%{IP:client.ip} %{GREEDYDATA}"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizePattern(tc.pattern))
+		})
+	}
+}
+
+func TestCaptureTypes(t *testing.T) {
+	assert.Equal(t,
+		map[string]string{"bytes": "int", "duration": "float"},
+		captureTypes("%{NUMBER:bytes:int} %{NUMBER:duration:float} %{WORD:verb:string} %{IP:client.ip}"))
+}
+
+func TestLoadPatternFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "grokprocessor")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "extra")
+	require.NoError(t, ioutil.WriteFile(file, []byte("EXTRA_CODE   E\\d{4}\n"), 0600))
+
+	patterns, err := loadPatternFiles([]string{"testdata/patterns", file})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"MYAPP_ID":      `[A-Z]{3}-\d+`,
+		"MYAPP_REQUEST": `%{WORD:http.method} %{URIPATHPARAM:url.path}`,
+		"EXTRA_CODE":    `E\d{4}`,
+	}, patterns)
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grokprocessor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/vjeantet/grok"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// grokProcessor sets the attributes of the log records to the values captured by the grok patterns
+type grokProcessor struct {
+	logger            *zap.Logger
+	grok              *grok.Grok
+	patterns          []*pattern
+	sourceAttribute   string
+	breakOnMatch      bool
+	overwrite         bool
+	keepEmptyCaptures bool
+	failureAttribute  string
+}
+
+// pattern is a grok pattern with the types of its typed captures
+type pattern struct {
+	expression string
+	types      map[string]string
+}
+
+func newGrokProcessor(logger *zap.Logger, cfg *Config) (*grokProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	definitions, err := loadPatternFiles(cfg.PatternFiles)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the pattern files: %w", err)
+	}
+	for _, definition := range cfg.PatternDefinitions {
+		definitions[definition.Name] = normalizePattern(definition.Pattern)
+	}
+
+	g, err := grok.NewWithConfig(&grok.Config{NamedCapturesOnly: true, Patterns: definitions})
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern definitions: %w", err)
+	}
+
+	gp := &grokProcessor{
+		logger:            logger,
+		grok:              g,
+		sourceAttribute:   cfg.SourceAttribute,
+		breakOnMatch:      cfg.BreakOnMatch,
+		overwrite:         cfg.Overwrite,
+		keepEmptyCaptures: cfg.KeepEmptyCaptures,
+		failureAttribute:  cfg.FailureAttribute,
+	}
+	for _, expression := range cfg.Patterns {
+		p := &pattern{expression: normalizePattern(expression)}
+		p.types = captureTypes(p.expression)
+		// the patterns are compiled upfront, as the compilation is not safe for concurrent use
+		if _, err := g.Match(p.expression, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", expression, err)
+		}
+		gp.patterns = append(gp.patterns, p)
+	}
+	return gp, nil
+}
+
+// ProcessLogs parses the log records
+func (gp *grokProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				gp.processRecord(logs.At(k))
+			}
+		}
+	}
+	return ld, nil
+}
+
+// processRecord matches the source of the record with the patterns in order;
+// the failure attribute is set when none of them matches
+func (gp *grokProcessor) processRecord(lr pdata.LogRecord) {
+	text, ok := gp.source(lr)
+	if !ok {
+		return
+	}
+
+	matched := false
+	for _, p := range gp.patterns {
+		captures, err := gp.grok.Parse(p.expression, text)
+		if err != nil {
+			gp.logger.Debug("Cannot parse the log record", zap.String("pattern", p.expression), zap.Error(err))
+			continue
+		}
+		// the patterns without named captures never match, as there is nothing to set
+		if len(captures) == 0 {
+			continue
+		}
+		matched = true
+		gp.setCaptures(lr.Attributes(), captures, p.types)
+		if gp.breakOnMatch {
+			break
+		}
+	}
+
+	if !matched && gp.failureAttribute != "" {
+		lr.Attributes().Upsert(gp.failureAttribute, pdata.NewAttributeValueBool(true))
+	}
+}
+
+// source returns the text to parse, either the string value of the source attribute or the string body
+func (gp *grokProcessor) source(lr pdata.LogRecord) (string, bool) {
+	value := lr.Body()
+	if gp.sourceAttribute != "" {
+		var ok bool
+		if value, ok = lr.Attributes().Get(gp.sourceAttribute); !ok {
+			return "", false
+		}
+	}
+	if value.Type() != pdata.AttributeValueTypeString {
+		return "", false
+	}
+	return value.StringVal(), true
+}
+
+// setCaptures sets the attributes of the captures, converted to the types of the typed ones
+func (gp *grokProcessor) setCaptures(attributes pdata.AttributeMap, captures map[string]string, types map[string]string) {
+	for name, captured := range captures {
+		if captured == "" && !gp.keepEmptyCaptures {
+			continue
+		}
+		value := convert(captured, types[name])
+		if gp.overwrite {
+			attributes.Upsert(name, value)
+		} else {
+			attributes.Insert(name, value)
+		}
+	}
+}
+
+// convert returns the captured value as an int or a float when typed so and valid, otherwise as a string;
+// the decimal values of the int captures are truncated
+func convert(captured string, typ string) pdata.AttributeValue {
+	switch typ {
+	case "int":
+		if i, err := strconv.ParseInt(captured, 10, 64); err == nil {
+			return pdata.NewAttributeValueInt(i)
+		}
+		if f, err := strconv.ParseFloat(captured, 64); err == nil && f >= math.MinInt64 && f < math.MaxInt64 {
+			return pdata.NewAttributeValueInt(int64(f))
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(captured, 64); err == nil {
+			return pdata.NewAttributeValueDouble(f)
+		}
+	}
+	return pdata.NewAttributeValueString(captured)
+}
@@ -0,0 +1,213 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grokprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) *grokProcessor {
+	cfg := createDefaultConfig().(*Config)
+	modify(cfg)
+	gp, err := newGrokProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	return gp
+}
+
+func newTestLogs(records ...func(lr pdata.LogRecord)) (pdata.Logs, pdata.LogSlice) {
+	ld := pdata.NewLogs()
+	logs := ld.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	for _, fill := range records {
+		fill(logs.AppendEmpty())
+	}
+	return ld, logs
+}
+
+func TestProcessLogsBuiltInPatterns(t *testing.T) {
+	gp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Patterns = []string{"%{COMMONAPACHELOG}"}
+		cfg.FailureAttribute = "grok.failure"
+	})
+
+	ld, logs := newTestLogs(
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal(`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`)
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal("not an access log")
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetIntVal(200)
+		},
+	)
+
+	_, err := gp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"clientip":    "127.0.0.1",
+		"ident":       "-",
+		"auth":        "frank",
+		"timestamp":   "10/Oct/2000:13:55:36 -0700",
+		"verb":        "GET",
+		"request":     "/apache_pb.gif",
+		"httpversion": "1.0",
+		"response":    "200",
+		"bytes":       "2326",
+	}, attributesToMap(logs.At(0).Attributes()))
+	assert.Equal(t, map[string]interface{}{"grok.failure": true}, attributesToMap(logs.At(1).Attributes()))
+	assert.Empty(t, attributesToMap(logs.At(2).Attributes()), "non-string bodies are not parsed")
+}
+
+func TestProcessLogsCustomPatterns(t *testing.T) {
+	gp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Patterns = []string{`%{MYAPP_ID:[request][id]} %{MYAPP_REQUEST} %{NUMBER:duration:float}ms %{NUMBER:size:int}`}
+		cfg.PatternDefinitions = []PatternDefinition{{Name: "MYAPP_ID", Pattern: `[A-Z]{3}-\d+`}}
+		cfg.PatternFiles = []string{"testdata/patterns"}
+		cfg.SourceAttribute = "message"
+	})
+
+	ld, logs := newTestLogs(
+		func(lr pdata.LogRecord) {
+			lr.Attributes().InsertString("message", "REQ-42 POST /api/users?id=1 12.5ms 1024")
+		},
+		func(lr pdata.LogRecord) {
+			lr.Attributes().InsertString("message", "REQ-43 GET /api/users 3ms 1500.7")
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal("REQ-44 GET /api/users 3ms 10")
+		},
+	)
+
+	_, err := gp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"message":     "REQ-42 POST /api/users?id=1 12.5ms 1024",
+		"request.id":  "REQ-42",
+		"http.method": "POST",
+		"url.path":    "/api/users?id=1",
+		"duration":    12.5,
+		"size":        int64(1024),
+	}, attributesToMap(logs.At(0).Attributes()))
+	assert.Equal(t, int64(1500), attributesToMap(logs.At(1).Attributes())["size"])
+	assert.Equal(t, float64(3), attributesToMap(logs.At(1).Attributes())["duration"])
+	assert.Empty(t, attributesToMap(logs.At(2).Attributes()), "the body is not parsed when a source attribute is set")
+}
+
+func TestProcessLogsBreakOnMatch(t *testing.T) {
+	testcases := []struct {
+		name         string
+		breakOnMatch bool
+		expected     map[string]interface{}
+	}{
+		{
+			name:         "break on match",
+			breakOnMatch: true,
+			expected:     map[string]interface{}{"client.ip": "10.0.0.1"},
+		},
+		{
+			name:         "all patterns",
+			breakOnMatch: false,
+			expected:     map[string]interface{}{"client.ip": "10.0.0.1", "user.name": "alice"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gp := newTestProcessor(t, func(cfg *Config) {
+				cfg.Patterns = []string{"^%{IP:client.ip}", "user=%{USERNAME:user.name}", "%{UUID:trace.id}"}
+				cfg.BreakOnMatch = tc.breakOnMatch
+			})
+			ld, logs := newTestLogs(func(lr pdata.LogRecord) {
+				lr.Body().SetStringVal("10.0.0.1 login user=alice")
+			})
+
+			_, err := gp.ProcessLogs(context.Background(), ld)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, attributesToMap(logs.At(0).Attributes()))
+		})
+	}
+}
+
+func TestProcessLogsOverwriteAndEmptyCaptures(t *testing.T) {
+	testcases := []struct {
+		name              string
+		overwrite         bool
+		keepEmptyCaptures bool
+		expected          map[string]interface{}
+	}{
+		{
+			name:     "defaults",
+			expected: map[string]interface{}{"level": "original", "message": "started"},
+		},
+		{
+			name:      "overwrite",
+			overwrite: true,
+			expected:  map[string]interface{}{"level": "INFO", "message": "started"},
+		},
+		{
+			name:              "keep empty captures",
+			keepEmptyCaptures: true,
+			expected:          map[string]interface{}{"level": "original", "message": "started", "component": ""},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gp := newTestProcessor(t, func(cfg *Config) {
+				cfg.Patterns = []string{`%{LOGLEVEL:level} (?:\[%{WORD:component}\] )?%{GREEDYDATA:message}`}
+				cfg.Overwrite = tc.overwrite
+				cfg.KeepEmptyCaptures = tc.keepEmptyCaptures
+			})
+			ld, logs := newTestLogs(func(lr pdata.LogRecord) {
+				lr.Attributes().InsertString("level", "original")
+				lr.Body().SetStringVal("INFO started")
+			})
+
+			_, err := gp.ProcessLogs(context.Background(), ld)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, attributesToMap(logs.At(0).Attributes()))
+		})
+	}
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]interface{} {
+	result := map[string]interface{}{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = valueToInterface(v)
+		return true
+	})
+	return result
+}
+
+func valueToInterface(v pdata.AttributeValue) interface{} {
+	switch v.Type() {
+	case pdata.AttributeValueTypeInt:
+		return v.IntVal()
+	case pdata.AttributeValueTypeDouble:
+		return v.DoubleVal()
+	case pdata.AttributeValueTypeBool:
+		return v.BoolVal()
+	default:
+		return v.StringVal()
+	}
+}
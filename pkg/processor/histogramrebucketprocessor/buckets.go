@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+// mergeInto merges the buckets into the ones with the target bounds.
+//
+// Each of the buckets is added to the target bucket containing its upper bound, so the count of the
+// observations up to a target bound is exact when the bound is one of the original ones, and otherwise
+// it is the count up to the closest original bound below it.
+func mergeInto(bounds []float64, counts []uint64, targetBounds []float64) []uint64 {
+	targetCounts := make([]uint64, len(targetBounds)+1)
+	target := 0
+	for i, count := range counts {
+		if i < len(bounds) {
+			for target < len(targetBounds) && bounds[i] > targetBounds[target] {
+				target++
+			}
+		} else {
+			// the overflow bucket
+			target = len(targetBounds)
+		}
+		targetCounts[target] += count
+	}
+	return targetCounts
+}
+
+// selectBounds returns the subset of the bounds, spread evenly, which leaves at most maxBuckets buckets,
+// or the bounds themselves if there are not more buckets than that
+func selectBounds(bounds []float64, maxBuckets int) []float64 {
+	if maxBuckets == 0 || len(bounds)+1 <= maxBuckets {
+		return bounds
+	}
+	// The buckets are merged in groups of about the same number of the original buckets,
+	// the bounds kept are the upper bounds of the groups, except for the last one
+	buckets := len(bounds) + 1
+	selected := make([]float64, 0, maxBuckets-1)
+	for i := 1; i < maxBuckets; i++ {
+		selected = append(selected, bounds[i*buckets/maxBuckets-1])
+	}
+	return selected
+}
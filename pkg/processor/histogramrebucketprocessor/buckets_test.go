@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeInto(t *testing.T) {
+	bounds := []float64{1, 2, 5, 10, 20}
+	counts := []uint64{1, 2, 3, 4, 5, 6}
+
+	testcases := []struct {
+		name         string
+		targetBounds []float64
+		expected     []uint64
+	}{
+		{
+			name:         "subset",
+			targetBounds: []float64{2, 10},
+			expected:     []uint64{3, 7, 11},
+		},
+		{
+			name:         "same",
+			targetBounds: bounds,
+			expected:     counts,
+		},
+		{
+			name:         "bounds between the original ones",
+			targetBounds: []float64{3, 15},
+			expected:     []uint64{3, 7, 11},
+		},
+		{
+			name:         "more buckets",
+			targetBounds: []float64{0.5, 1, 2, 3, 5, 10, 20, 50},
+			expected:     []uint64{0, 1, 2, 0, 3, 4, 5, 0, 6},
+		},
+		{
+			name:         "below all the bounds",
+			targetBounds: []float64{0.1},
+			expected:     []uint64{0, 21},
+		},
+		{
+			name:         "above all the bounds",
+			targetBounds: []float64{100},
+			expected:     []uint64{15, 6},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, mergeInto(bounds, counts, tc.targetBounds))
+		})
+	}
+}
+
+func TestSelectBounds(t *testing.T) {
+	bounds := []float64{0, 5, 10, 25, 50, 75, 100, 250, 500, 750, 1000, 2500, 5000, 7500, 10000}
+
+	assert.Equal(t, bounds, selectBounds(bounds, 0))
+	assert.Equal(t, bounds, selectBounds(bounds, 16))
+	assert.Equal(t, []float64{5, 25, 75, 250, 750, 2500, 7500}, selectBounds(bounds, 8))
+	assert.Equal(t, []float64{50, 750}, selectBounds(bounds, 3))
+	assert.Equal(t, []float64{250}, selectBounds(bounds, 2))
+}
@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the histogram re-bucketing.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Rules are evaluated in the order as specified, the first one matching the metric name re-buckets it.
+	// The histograms not matched by any rule are passed through.
+	Rules []RuleConfig `mapstructure:"rules"`
+}
+
+// RuleConfig holds the configuration of a single re-bucketing rule.
+type RuleConfig struct {
+	// Metrics are the regular expressions matching the names of the re-bucketed histograms
+	Metrics []string `mapstructure:"metrics"`
+	// Boundaries are the bucket boundaries the buckets are merged into
+	Boundaries []float64 `mapstructure:"boundaries"`
+	// MaxBuckets is the maximum number of the buckets, including the overflow one, 0 means no limit
+	MaxBuckets int `mapstructure:"max_buckets"`
+}
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Rules) == 0 {
+		return errors.New("at least one rule is required")
+	}
+	for i, rule := range cfg.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (rule *RuleConfig) validate() error {
+	if len(rule.Metrics) == 0 {
+		return errors.New("metrics must not be empty")
+	}
+	for _, metric := range rule.Metrics {
+		if _, err := regexp.Compile(metric); err != nil {
+			return fmt.Errorf("invalid metrics regex %q: %w", metric, err)
+		}
+	}
+	if len(rule.Boundaries) == 0 && rule.MaxBuckets == 0 {
+		return errors.New("boundaries or max_buckets must be set")
+	}
+	for i, boundary := range rule.Boundaries {
+		if math.IsNaN(boundary) || math.IsInf(boundary, 0) {
+			return fmt.Errorf("boundaries must be finite, got %v", boundary)
+		}
+		if i > 0 && boundary <= rule.Boundaries[i-1] {
+			return fmt.Errorf("boundaries must be increasing, got %v after %v", boundary, rule.Boundaries[i-1])
+		}
+	}
+	if rule.MaxBuckets < 0 || rule.MaxBuckets == 1 {
+		return fmt.Errorf("max_buckets must be 0 or at least 2, got %d", rule.MaxBuckets)
+	}
+	return nil
+}
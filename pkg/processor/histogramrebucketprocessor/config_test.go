@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "histogram_rebucket_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			Rules: []RuleConfig{
+				{
+					Metrics:    []string{"^http_server_duration$", "^http_client_duration$"},
+					Boundaries: []float64{0.005, 0.025, 0.1, 0.5, 2.5},
+				},
+				{
+					Metrics:    []string{".*"},
+					MaxBuckets: 8,
+				},
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	validConfig := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Rules = []RuleConfig{{Metrics: []string{"_duration$"}, Boundaries: []float64{-1, 0, 1}, MaxBuckets: 2}}
+		return cfg
+	}
+	assert.NoError(t, validConfig().Validate())
+
+	assert.EqualError(t, createDefaultConfig().(*Config).Validate(), "at least one rule is required")
+
+	cfg := validConfig()
+	cfg.Rules[0].Metrics = nil
+	assert.EqualError(t, cfg.Validate(), "invalid rule 0: metrics must not be empty")
+
+	cfg = validConfig()
+	cfg.Rules[0].Metrics = []string{"duration("}
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Rules[0].Boundaries = nil
+	cfg.Rules[0].MaxBuckets = 0
+	assert.EqualError(t, cfg.Validate(), "invalid rule 0: boundaries or max_buckets must be set")
+
+	cfg = validConfig()
+	cfg.Rules[0].Boundaries = []float64{1, 5, 5}
+	assert.EqualError(t, cfg.Validate(), "invalid rule 0: boundaries must be increasing, got 5 after 5")
+
+	cfg = validConfig()
+	cfg.Rules[0].MaxBuckets = 1
+	assert.EqualError(t, cfg.Validate(), "invalid rule 0: max_buckets must be 0 or at least 2, got 1")
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+import (
+	"context"
+	"regexp"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// histogramRebucketProcessor merges the buckets of the matching histograms into fewer or coarser ones
+type histogramRebucketProcessor struct {
+	logger *zap.Logger
+	rules  []*rule
+}
+
+// rule is the compiled RuleConfig
+type rule struct {
+	metrics    []*regexp.Regexp
+	boundaries []float64
+	maxBuckets int
+}
+
+func newHistogramRebucketProcessor(logger *zap.Logger, cfg *Config) (*histogramRebucketProcessor, error) {
+	p := &histogramRebucketProcessor{logger: logger}
+	for _, ruleCfg := range cfg.Rules {
+		r := &rule{
+			boundaries: ruleCfg.Boundaries,
+			maxBuckets: ruleCfg.MaxBuckets,
+		}
+		for _, metric := range ruleCfg.Metrics {
+			regex, err := regexp.Compile(metric)
+			if err != nil {
+				return nil, err
+			}
+			r.metrics = append(r.metrics, regex)
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// ProcessMetrics re-buckets the data points of the histograms matched by the rules
+func (p *histogramRebucketProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		ilms := rms.At(i).InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				if metric.DataType() != pdata.MetricDataTypeHistogram {
+					continue
+				}
+				if r := p.match(metric.Name()); r != nil {
+					p.rebucket(r, metric)
+				}
+			}
+		}
+	}
+	return md, nil
+}
+
+func (p *histogramRebucketProcessor) match(name string) *rule {
+	for _, r := range p.rules {
+		for _, regex := range r.metrics {
+			if regex.MatchString(name) {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+func (p *histogramRebucketProcessor) rebucket(r *rule, metric pdata.Metric) {
+	dps := metric.Histogram().DataPoints()
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		bounds := dp.ExplicitBounds()
+		counts := dp.BucketCounts()
+		if len(counts) == 0 {
+			// only the count and the sum are set
+			continue
+		}
+		if len(counts) != len(bounds)+1 {
+			p.logger.Debug("Invalid histogram data point, not re-bucketed",
+				zap.String("metric", metric.Name()),
+				zap.Int("bounds", len(bounds)),
+				zap.Int("bucket_counts", len(counts)))
+			continue
+		}
+
+		changed := false
+		if len(r.boundaries) > 0 {
+			counts = mergeInto(bounds, counts, r.boundaries)
+			bounds = r.boundaries
+			changed = true
+		}
+		if selected := selectBounds(bounds, r.maxBuckets); len(selected) < len(bounds) {
+			counts = mergeInto(bounds, counts, selected)
+			bounds = selected
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+
+		// The bounds are copied, as they may be shared by the data points
+		dp.SetExplicitBounds(append([]float64(nil), bounds...))
+		dp.SetBucketCounts(counts)
+	}
+}
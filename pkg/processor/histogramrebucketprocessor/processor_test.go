@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// The default bucket boundaries of the OpenTelemetry SDKs
+var sdkBounds = []float64{0, 5, 10, 25, 50, 75, 100, 250, 500, 750, 1000, 2500, 5000, 7500, 10000}
+
+func newTestProcessor(t *testing.T, rules ...RuleConfig) *histogramRebucketProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Rules = rules
+	require.NoError(t, cfg.Validate())
+	p, err := newHistogramRebucketProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	return p
+}
+
+// newTestMetrics creates the histograms with a data point of the bounds and the counts
+func newTestMetrics(names []string, bounds []float64, counts []uint64) pdata.Metrics {
+	md := pdata.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	for _, name := range names {
+		metric := metrics.AppendEmpty()
+		metric.SetName(name)
+		metric.SetDataType(pdata.MetricDataTypeHistogram)
+		metric.Histogram().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		dp := metric.Histogram().DataPoints().AppendEmpty()
+		dp.SetExplicitBounds(bounds)
+		dp.SetBucketCounts(counts)
+		total := uint64(0)
+		for _, count := range counts {
+			total += count
+		}
+		dp.SetCount(total)
+		dp.SetSum(1234.5)
+	}
+	return md
+}
+
+// dataPoints returns the first data point of each of the histograms, by the metric name
+func dataPoints(md pdata.Metrics) map[string]pdata.HistogramDataPoint {
+	result := map[string]pdata.HistogramDataPoint{}
+	metrics := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		result[metrics.At(i).Name()] = metrics.At(i).Histogram().DataPoints().At(0)
+	}
+	return result
+}
+
+func TestRebucket(t *testing.T) {
+	p := newTestProcessor(t,
+		RuleConfig{Metrics: []string{"^http_server_duration$"}, Boundaries: []float64{10, 100, 1000}},
+		RuleConfig{Metrics: []string{"_duration$"}, MaxBuckets: 4},
+		RuleConfig{Metrics: []string{"^db_"}, Boundaries: []float64{10, 100, 1000}, MaxBuckets: 2},
+	)
+
+	counts := []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	md, err := p.ProcessMetrics(context.Background(), newTestMetrics(
+		[]string{"http_server_duration", "http_client_duration", "db_query_time", "queue_size"},
+		sdkBounds, counts,
+	))
+	require.NoError(t, err)
+	dps := dataPoints(md)
+
+	assert.Equal(t, []float64{10, 100, 1000}, dps["http_server_duration"].ExplicitBounds())
+	assert.Equal(t, []uint64{6, 22, 38, 70}, dps["http_server_duration"].BucketCounts())
+
+	assert.Equal(t, []float64{25, 250, 2500}, dps["http_client_duration"].ExplicitBounds())
+	assert.Equal(t, []uint64{10, 26, 42, 58}, dps["http_client_duration"].BucketCounts())
+
+	assert.Equal(t, []float64{100}, dps["db_query_time"].ExplicitBounds(),
+		"the buckets merged into the boundaries are capped")
+	assert.Equal(t, []uint64{28, 108}, dps["db_query_time"].BucketCounts())
+
+	assert.Equal(t, sdkBounds, dps["queue_size"].ExplicitBounds(), "the histograms not matched are passed through")
+	assert.Equal(t, counts, dps["queue_size"].BucketCounts())
+
+	for name, dp := range dps {
+		assert.Equal(t, uint64(136), dp.Count(), name)
+		assert.Equal(t, 1234.5, dp.Sum(), name)
+	}
+}
+
+func TestRebucketSkipsInvalidDataPoints(t *testing.T) {
+	p := newTestProcessor(t, RuleConfig{Metrics: []string{".*"}, MaxBuckets: 2})
+
+	md, err := p.ProcessMetrics(context.Background(), newTestMetrics([]string{"count_only"}, nil, nil))
+	require.NoError(t, err)
+	assert.Empty(t, dataPoints(md)["count_only"].BucketCounts())
+
+	md, err = p.ProcessMetrics(context.Background(), newTestMetrics([]string{"invalid"}, []float64{1, 2, 3}, []uint64{1, 2}))
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 3}, dataPoints(md)["invalid"].ExplicitBounds())
+	assert.Equal(t, []uint64{1, 2}, dataPoints(md)["invalid"].BucketCounts())
+}
+
+func TestRebucketKeepsOtherMetrics(t *testing.T) {
+	p := newTestProcessor(t, RuleConfig{Metrics: []string{".*"}, MaxBuckets: 2})
+
+	md := pdata.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("cpu_usage")
+	metric.SetDataType(pdata.MetricDataTypeGauge)
+	metric.Gauge().DataPoints().AppendEmpty().SetDoubleVal(0.5)
+
+	md, err := p.ProcessMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, 1, md.MetricCount())
+	assert.Equal(t, 0.5, md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).
+		Metrics().At(0).Gauge().DataPoints().At(0).DoubleVal())
+}
@@ -21,18 +21,20 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
 )
 
 // fakeClient is used as a replacement for WatchClient in test cases.
 type fakeClient struct {
 	Pods         map[kube.PodIdentifier]*kube.Pod
+	Services     map[kube.PodIdentifier][]string
 	Rules        kube.ExtractionRules
 	Filters      kube.Filters
 	Associations []kube.Association
 	Informer     cache.SharedInformer
 	StopCh       chan struct{}
+	// HasSyncedFunc overrides the result of HasSynced, which is true by default
+	HasSyncedFunc func() bool
 }
 
 func selectors() (labels.Selector, fields.Selector) {
@@ -43,7 +45,7 @@ func selectors() (labels.Selector, fields.Selector) {
 // newFakeClient instantiates a new FakeClient object and satisfies the ClientProvider type
 func newFakeClient(
 	_ *zap.Logger,
-	apiCfg k8sconfig.APIConfig,
+	apiCfg kube.APIConfig,
 	rules kube.ExtractionRules,
 	filters kube.Filters,
 	associations []kube.Association,
@@ -56,6 +58,7 @@ func newFakeClient(
 	ls, fs := selectors()
 	return &fakeClient{
 		Pods:         map[kube.PodIdentifier]*kube.Pod{},
+		Services:     map[kube.PodIdentifier][]string{},
 		Rules:        rules,
 		Filters:      filters,
 		Associations: associations,
@@ -71,6 +74,19 @@ func (f *fakeClient) GetPod(identifier kube.PodIdentifier) (*kube.Pod, bool) {
 	return p, ok
 }
 
+// GetServicesByIP looks up FakeClient.Services map by the provided IP address.
+func (f *fakeClient) GetServicesByIP(identifier kube.PodIdentifier) []string {
+	return f.Services[identifier]
+}
+
+// HasSynced returns true unless overridden by HasSyncedFunc.
+func (f *fakeClient) HasSynced() bool {
+	if f.HasSyncedFunc != nil {
+		return f.HasSyncedFunc()
+	}
+	return true
+}
+
 // Start is a noop for FakeClient.
 func (f *fakeClient) Start() {
 	if f.Informer != nil {
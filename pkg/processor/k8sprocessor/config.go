@@ -15,9 +15,12 @@
 package k8sprocessor
 
 import (
+	"time"
+
 	"go.opentelemetry.io/collector/config"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
 )
 
 // Config defines configuration for k8s attributes processor.
@@ -26,6 +29,16 @@ type Config struct {
 
 	k8sconfig.APIConfig `mapstructure:",squash"`
 
+	// KubeConfigPath is the kubeconfig file used with the kubeConfig auth type instead of
+	// the default one, e.g. to enrich the data forwarded from another cluster.
+	KubeConfigPath string `mapstructure:"kube_config_path"`
+
+	// Context is the kubeconfig context used with the kubeConfig auth type instead of the current one.
+	Context string `mapstructure:"context"`
+
+	// ClusterName is set as the cluster name attribute of all the data, unless it is already set.
+	ClusterName string `mapstructure:"cluster_name"`
+
 	// Passthrough mode only annotates resources with the pod IP and
 	// does not try to extract any other metadata. It does not need
 	// access to the K8S cluster API. Agent/Collector must receive spans
@@ -36,6 +49,26 @@ type Config struct {
 	// additional calls to Kubernetes API
 	OwnerLookupEnabled bool `mapstructure:"owner_lookup_enabled"`
 
+	// OwnerLookupMaxDepth limits the number of owner references followed from the pod,
+	// e.g. 2 for Pod -> ReplicaSet -> Deployment. There is no limit when it is not set.
+	OwnerLookupMaxDepth int `mapstructure:"owner_lookup_max_depth"`
+
+	// CustomOwnerKinds allows extracting the names of the owners other than the built-in
+	// workloads, e.g. Argo Rollouts, when OwnerLookupEnabled is set.
+	CustomOwnerKinds []CustomOwnerKindConfig `mapstructure:"custom_owner_kinds"`
+
+	// WatchedResources limits the resources watched besides the pods when OwnerLookupEnabled
+	// is set, e.g. to the namespaces and replicasets. All of them are watched when it is not set.
+	WatchedResources []string `mapstructure:"watched_resources"`
+
+	// ResyncPeriod is the interval at which the pod informer resyncs its handlers.
+	// The default is used when it is not set.
+	ResyncPeriod time.Duration `mapstructure:"resync_period"`
+
+	// WaitForSyncTimeout is how long the data received right after the start is delayed until
+	// the pods are cached, so it can be enriched. The data is not delayed when it is not set.
+	WaitForSyncTimeout time.Duration `mapstructure:"wait_for_sync_timeout"`
+
 	// Extract section allows specifying extraction rules to extract
 	// data from k8s pod specs
 	Extract ExtractConfig `mapstructure:"extract"`
@@ -50,7 +83,11 @@ type Config struct {
 }
 
 func (cfg *Config) Validate() error {
-	return cfg.APIConfig.Validate()
+	return kube.APIConfig{
+		APIConfig:      cfg.APIConfig,
+		KubeConfigPath: cfg.KubeConfigPath,
+		Context:        cfg.Context,
+	}.Validate()
 }
 
 // ExtractConfig section allows specifying extraction rules to extract
@@ -88,6 +125,25 @@ type ExtractConfig struct {
 	// It is a list of FieldExtractConfig type. See FieldExtractConfig
 	// documentation for more details.
 	NamespaceLabels []FieldExtractConfig `mapstructure:"namespace_labels"`
+
+	// NamespaceAnnotations allows extracting data from namespace annotations and record it
+	// as resource attributes.
+	// It is a list of FieldExtractConfig type. See FieldExtractConfig
+	// documentation for more details.
+	NamespaceAnnotations []FieldExtractConfig `mapstructure:"namespace_annotations"`
+
+	// NodeLabels allows extracting data from the labels of the node the pod is scheduled to
+	// and record it as resource attributes, e.g. topology.kubernetes.io/zone.
+	// It is a list of FieldExtractConfig type. See FieldExtractConfig
+	// documentation for more details.
+	NodeLabels []FieldExtractConfig `mapstructure:"node_labels"`
+
+	// WorkloadAnnotations allows extracting data from the annotations of the workloads
+	// owning the pod, i.e. Deployments, StatefulSets, DaemonSets and CronJobs, and record it
+	// as resource attributes.
+	// It is a list of FieldExtractConfig type. See FieldExtractConfig
+	// documentation for more details.
+	WorkloadAnnotations []FieldExtractConfig `mapstructure:"workload_annotations"`
 }
 
 //FieldExtractConfig allows specifying an extraction rule to extract a value from exactly one field.
@@ -134,11 +190,26 @@ type ExtractConfig struct {
 //      annotations:
 //        - tag_name: k8s.annotation/%s
 //          key: *
+//
+//- key_regex can be used instead of key to extract only the keys matching the regular expression.
+//  When it contains a capturing group, its match is substituted for `%s` instead of the whole key,
+//  which allows renaming the keys. For example, the following rule extracts the namespace labels
+//  starting with `acme.io/` as `team` and `env`, rather than `acme.io/team` and `acme.io/env`:
+//
+//  procesors:
+//    k8s-tagger:
+//      namespace_labels:
+//        - tag_name: "%s"
+//          key_regex: ^acme\.io/(team|env)$
+//
+//  The tag_name can also refer to the capturing groups with $1 or ${name}, e.g. `${domain}_${key}`
+//  for `^(?P<domain>app|team)\.acme\.io/(?P<key>.+)$`.
 
 type FieldExtractConfig struct {
-	TagName string `mapstructure:"tag_name"`
-	Key     string `mapstructure:"key"`
-	Regex   string `mapstructure:"regex"`
+	TagName  string `mapstructure:"tag_name"`
+	Key      string `mapstructure:"key"`
+	KeyRegex string `mapstructure:"key_regex"`
+	Regex    string `mapstructure:"regex"`
 }
 
 // FilterConfig section allows specifying filters to filter
@@ -189,6 +260,25 @@ type FilterConfig struct {
 	//
 	// Check FieldFilterConfig for more details.
 	Labels []FieldFilterConfig `mapstructure:"labels"`
+
+	// Exclude section allows specifying the pods for which no metadata is extracted.
+	// Unlike the other filters, the excluded pods are still watched.
+	Exclude ExcludeConfig `mapstructure:"exclude"`
+}
+
+// ExcludeConfig allows specifying the pods for which no metadata is extracted.
+// A pod is excluded when it matches any of the namespace or pod name patterns,
+// or all of the label filters.
+type ExcludeConfig struct {
+	// Namespaces is a list of regular expressions matching the names of the namespaces.
+	Namespaces []string `mapstructure:"namespaces"`
+
+	// Pods is a list of regular expressions matching the names of the pods.
+	Pods []string `mapstructure:"pods"`
+
+	// Labels is a list of pod label filters, supporting the same operations as
+	// the label filters.
+	Labels []FieldFilterConfig `mapstructure:"labels"`
 }
 
 // FieldFilterConfig allows specifying exactly one filter by a field.
@@ -208,14 +298,29 @@ type FieldFilterConfig struct {
 	Op string `mapstructure:"op"`
 }
 
+// CustomOwnerKindConfig allows extracting the name of an owner of the given kind.
+type CustomOwnerKindConfig struct {
+	// Kind is the kind of the owner, e.g. Rollout.
+	Kind string `mapstructure:"kind"`
+
+	// TagName is the name of the tag the owner name is put in.
+	// It defaults to k8s.<kind in lower case>.name, e.g. k8s.rollout.name.
+	TagName string `mapstructure:"tag_name"`
+}
+
 // PodAssociationConfig contain single rule how to associate Pod metadata
 // with logs, spans and metrics
 type PodAssociationConfig struct {
 	// From represents the source of the association.
-	// Allowed values are "connection" and "labels".
+	// Allowed values are "connection", "resource_attribute" and "build_hostname".
 	From string `mapstructure:"from"`
 
 	// Name represents extracted key name.
 	// e.g. ip, pod_uid, k8s.pod.ip
 	Name string `mapstructure:"name"`
+
+	// Port is the name of the resource attribute holding the port, e.g. net.host.port.
+	// When set, the pods in the host network, sharing the IP address of the node, are
+	// associated using the IP address and one of the ports declared by their containers.
+	Port string `mapstructure:"port"`
 }
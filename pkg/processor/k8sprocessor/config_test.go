@@ -17,6 +17,7 @@ package k8sprocessor
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,6 +29,15 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 )
 
+func TestConfigValidateKubeConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Context = "prod-eu"
+	assert.Error(t, cfg.Validate())
+
+	cfg.AuthType = k8sconfig.AuthTypeKubeConfig
+	assert.NoError(t, cfg.Validate())
+}
+
 func TestLoadConfig(t *testing.T) {
 	factories, err := componenttest.NopFactories()
 	require.NoError(t, err)
@@ -55,10 +65,18 @@ func TestLoadConfig(t *testing.T) {
 	p1 := cfg.Processors[config.NewIDWithName(typeStr, "2")]
 	assert.Equal(t, p1,
 		&Config{
-			ProcessorSettings:  config.NewProcessorSettings(config.NewIDWithName(typeStr, "2")),
-			APIConfig:          k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig},
-			Passthrough:        false,
-			OwnerLookupEnabled: true,
+			ProcessorSettings:   config.NewProcessorSettings(config.NewIDWithName(typeStr, "2")),
+			APIConfig:           k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig},
+			KubeConfigPath:      "/etc/kube/clusters",
+			Context:             "prod-eu",
+			ClusterName:         "prod-eu",
+			Passthrough:         false,
+			OwnerLookupEnabled:  true,
+			OwnerLookupMaxDepth: 3,
+			CustomOwnerKinds:    []CustomOwnerKindConfig{{Kind: "Rollout"}},
+			WatchedResources:    []string{"pods", "namespaces", "replicasets", "deployments"},
+			ResyncPeriod:        10 * time.Minute,
+			WaitForSyncTimeout:  10 * time.Second,
 			Extract: ExtractConfig{
 				Metadata: []string{
 					"podName",
@@ -80,6 +98,13 @@ func TestLoadConfig(t *testing.T) {
 				NamespaceLabels: []FieldExtractConfig{
 					{TagName: "namespace_labels_%s", Key: "*"},
 				},
+				NamespaceAnnotations: []FieldExtractConfig{
+					{TagName: "namespace_annotations_%s", Key: "*"},
+					{TagName: "%s", KeyRegex: `^acme\.io/(.+)$`},
+				},
+				NodeLabels: []FieldExtractConfig{
+					{TagName: "k8s.node.zone", Key: "topology.kubernetes.io/zone"},
+				},
 				Tags: map[string]string{
 					"containerId": "my.namespace.containerId",
 				},
@@ -96,6 +121,11 @@ func TestLoadConfig(t *testing.T) {
 					{Key: "key1", Value: "value1"},
 					{Key: "key2", Value: "value2", Op: "not-equals"},
 				},
+				Exclude: ExcludeConfig{
+					Namespaces: []string{"^kube-"},
+					Pods:       []string{"^cleanup-"},
+					Labels:     []FieldFilterConfig{{Key: "job-name", Op: "exists"}},
+				},
 			},
 			Association: []PodAssociationConfig{
 				{
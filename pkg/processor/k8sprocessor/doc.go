@@ -19,7 +19,9 @@
 // running in a cluster, keeps a record of their IP addresses, pod UIDs and interesting metadata.
 // The rules for associating the data passing through the processor (spans, metrics and logs)
 // with specific Pod Metadata are configured via "pod_association" key.
-// It represents a list of rules that are executed in the specified order until the first one is able to do the match.
+// It represents a list of rules that are executed in the specified order until the first one is able to do the match,
+// i.e. the identifier it extracts belongs to a known Pod; the following rules are the fallbacks for the data
+// not matched by the previous ones. Without any match, the identifier of the first applicable rule is recorded.
 // Each rule is specified as a pair of from (representing the rule type) and name (representing the extracted key name).
 // Following rule types are available:
 //   from: "resource_attribute" - allows to specify the attribute name to lookup up in the list of attributes of the received Resource.
@@ -43,7 +45,11 @@
 //  - from: resource_attribute
 //    name: k8s.pod.uid
 //
-// If Pod association rules are not configured resources are associated with metadata only by connection's IP Address.
+// If Pod association rules are not configured resources are associated with metadata by the "k8s.pod.ip" and "ip"
+// attributes, connection's IP Address and "host.name" attribute (if it is an IP Address), in this order.
+//
+// The Pods running in the host network share the IP Address of the node, so they are associated only
+// using their Pod UID or `pod_name.namespace_name`, e.g. with the rules following "connection".
 //
 // RBAC
 //
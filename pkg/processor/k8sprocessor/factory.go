@@ -179,19 +179,39 @@ func createProcessorOpts(cfg config.Processor) []Option {
 	opts = append(opts, WithExtractMetadata(oCfg.Extract.Metadata...))
 	opts = append(opts, WithExtractLabels(oCfg.Extract.Labels...))
 	opts = append(opts, WithExtractNamespaceLabels(oCfg.Extract.NamespaceLabels...))
+	opts = append(opts, WithExtractNamespaceAnnotations(oCfg.Extract.NamespaceAnnotations...))
+	opts = append(opts, WithExtractNodeLabels(oCfg.Extract.NodeLabels...))
+	opts = append(opts, WithExtractWorkloadAnnotations(oCfg.Extract.WorkloadAnnotations...))
 	opts = append(opts, WithExtractAnnotations(oCfg.Extract.Annotations...))
 	opts = append(opts, WithExtractTags(oCfg.Extract.Tags))
 
 	if oCfg.OwnerLookupEnabled {
 		opts = append(opts, WithOwnerLookupEnabled())
 	}
+	if oCfg.OwnerLookupMaxDepth != 0 {
+		opts = append(opts, WithOwnerLookupMaxDepth(oCfg.OwnerLookupMaxDepth))
+	}
+	opts = append(opts, WithCustomOwnerKinds(oCfg.CustomOwnerKinds...))
+	if oCfg.WatchedResources != nil {
+		opts = append(opts, WithWatchedResources(oCfg.WatchedResources...))
+	}
+	if oCfg.ResyncPeriod != 0 {
+		opts = append(opts, WithResyncPeriod(oCfg.ResyncPeriod))
+	}
+	if oCfg.WaitForSyncTimeout != 0 {
+		opts = append(opts, WithWaitForSyncTimeout(oCfg.WaitForSyncTimeout))
+	}
 
 	// filters
 	opts = append(opts, WithFilterNode(oCfg.Filter.Node, oCfg.Filter.NodeFromEnvVar))
 	opts = append(opts, WithFilterNamespace(oCfg.Filter.Namespace))
 	opts = append(opts, WithFilterLabels(oCfg.Filter.Labels...))
 	opts = append(opts, WithFilterFields(oCfg.Filter.Fields...))
+	opts = append(opts, WithExcludes(oCfg.Filter.Exclude))
+	// The kubeconfig is validated together with the auth type
+	opts = append(opts, WithKubeConfig(oCfg.KubeConfigPath, oCfg.Context))
 	opts = append(opts, WithAPIConfig(oCfg.APIConfig))
+	opts = append(opts, WithClusterName(oCfg.ClusterName))
 
 	opts = append(opts, WithExtractPodAssociations(oCfg.Association...))
 
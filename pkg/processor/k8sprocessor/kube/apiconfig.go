@@ -0,0 +1,70 @@
+// Copyright 2021 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
+)
+
+// APIConfig contains the options of connecting to the K8s API. Besides the common ones, it allows
+// using an explicit kubeconfig file and context, e.g. to enrich the data of several clusters.
+type APIConfig struct {
+	k8sconfig.APIConfig
+
+	// KubeConfigPath is the kubeconfig file used instead of the default one, with the kubeConfig auth type
+	KubeConfigPath string
+	// Context is the kubeconfig context used instead of the current one, with the kubeConfig auth type
+	Context string
+}
+
+// Validate validates the K8s API config
+func (c APIConfig) Validate() error {
+	if err := c.APIConfig.Validate(); err != nil {
+		return err
+	}
+	if (c.KubeConfigPath != "" || c.Context != "") && c.AuthType != k8sconfig.AuthTypeKubeConfig {
+		return fmt.Errorf("kube_config_path and context can only be set with auth_type %s", k8sconfig.AuthTypeKubeConfig)
+	}
+	return nil
+}
+
+// key identifies the cluster and credentials used by the config
+func (c APIConfig) key() string {
+	return fmt.Sprintf("%s/%s/%s", c.AuthType, c.KubeConfigPath, c.Context)
+}
+
+// MakeClient creates a K8s API client, using the given kubeconfig file and context if set.
+func MakeClient(apiCfg APIConfig) (kubernetes.Interface, error) {
+	if err := apiCfg.Validate(); err != nil {
+		return nil, err
+	}
+	if apiCfg.KubeConfigPath == "" && apiCfg.Context == "" {
+		return k8sconfig.MakeClient(apiCfg.APIConfig)
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = apiCfg.KubeConfigPath
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: apiCfg.Context}
+	restCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to k8s with auth_type=%s: %w", k8sconfig.AuthTypeKubeConfig, err)
+	}
+	return kubernetes.NewForConfig(restCfg)
+}
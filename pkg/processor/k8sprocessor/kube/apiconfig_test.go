@@ -0,0 +1,96 @@
+// Copyright 2021 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: eu
+  cluster:
+    server: https://eu.example.com:6443
+- name: us
+  cluster:
+    server: https://us.example.com:6443
+users:
+- name: collector
+  user:
+    token: secret
+contexts:
+- name: prod-eu
+  context:
+    cluster: eu
+    user: collector
+- name: prod-us
+  context:
+    cluster: us
+    user: collector
+current-context: prod-eu
+`
+
+func TestMakeClientKubeConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, ioutil.WriteFile(path, []byte(testKubeConfig), 0600))
+
+	testCases := []struct {
+		context string
+		host    string
+	}{
+		{context: "", host: "eu.example.com:6443"},
+		{context: "prod-us", host: "us.example.com:6443"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			kc, err := MakeClient(APIConfig{
+				APIConfig:      k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig},
+				KubeConfigPath: path,
+				Context:        tc.context,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.host, kc.(*kubernetes.Clientset).CoreV1().RESTClient().Get().URL().Host)
+		})
+	}
+
+	_, err := MakeClient(APIConfig{
+		APIConfig:      k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig},
+		KubeConfigPath: path,
+		Context:        "unknown",
+	})
+	assert.Error(t, err)
+}
+
+func TestAPIConfigValidate(t *testing.T) {
+	assert.NoError(t, APIConfig{APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeServiceAccount}}.Validate())
+	assert.NoError(t, APIConfig{
+		APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig},
+		Context:   "prod-eu",
+	}.Validate())
+	assert.Error(t, APIConfig{
+		APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeServiceAccount},
+		Context:   "prod-eu",
+	}.Validate())
+	assert.Error(t, APIConfig{APIConfig: k8sconfig.APIConfig{AuthType: "unknown"}}.Validate())
+}
@@ -15,21 +15,25 @@
 package kube
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 	api_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/observability"
 )
 
@@ -40,10 +44,15 @@ type WatchClient struct {
 	logger          *zap.Logger
 	kc              kubernetes.Interface
 	informer        cache.SharedInformer
+	sharedInformer  *sharedInformer
 	deploymentRegex *regexp.Regexp
 	deleteQueue     []deleteRequest
 	stopCh          chan struct{}
 	op              OwnerAPI
+	excludeLabels   labels.Selector
+	synced          int32
+	// podsForbidden is set when the pods cannot be listed, so no metadata is extracted
+	podsForbidden bool
 
 	// A map containing Pod related data, used to associate them with resources.
 	// Key can be either an IP address or Pod UID
@@ -60,7 +69,7 @@ var dRegex = regexp.MustCompile(`^(.*)-[0-9a-zA-Z]*-[0-9a-zA-Z]*$`)
 // New initializes a new k8s Client.
 func New(
 	logger *zap.Logger,
-	apiCfg k8sconfig.APIConfig,
+	apiCfg APIConfig,
 	rules ExtractionRules,
 	filters Filters,
 	associations []Association,
@@ -76,30 +85,40 @@ func New(
 		deploymentRegex: dRegex,
 		stopCh:          make(chan struct{}),
 	}
-	go c.deleteLoop(time.Second*30, defaultPodDeleteGracePeriod)
-
-	c.Pods = map[PodIdentifier]*Pod{}
-	if newClientSet == nil {
-		newClientSet = k8sconfig.MakeClient
+	// The informers are shared unless custom providers are used, e.g. in the tests
+	registry := defaultRegistry
+	if newClientSet != nil || newInformer != nil || newOwnerProviderFunc != nil {
+		registry = newInformerRegistry(newClientSet, newInformer, newOwnerProviderFunc)
 	}
 
-	kc, err := newClientSet(apiCfg)
+	c.Pods = map[PodIdentifier]*Pod{}
+	kc, err := registry.client(apiCfg)
 	if err != nil {
 		return nil, err
 	}
 	c.kc = kc
 
+	c.podsForbidden, err = isListForbidden(func(ctx context.Context, opts meta_v1.ListOptions) error {
+		_, err := kc.CoreV1().Pods(c.Filters.Namespace).List(ctx, opts)
+		return err
+	})
+	if c.podsForbidden {
+		logger.Error("Missing permissions to watch K8S pods, no metadata is extracted", zap.Error(err))
+	}
+
 	labelSelector, fieldSelector, err := selectorsFromFilters(c.Filters)
 	if err != nil {
 		return nil, err
 	}
-
-	if c.Rules.OwnerLookupEnabled {
-		if newOwnerProviderFunc == nil {
-			newOwnerProviderFunc = newOwnerProvider
+	if len(c.Filters.Exclude.Labels) > 0 {
+		c.excludeLabels, err = labelSelectorFromFilters(c.Filters.Exclude.Labels)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		c.op, err = newOwnerProviderFunc(logger, c.kc, labelSelector, fieldSelector, c.Filters.Namespace)
+	if c.Rules.OwnerLookupEnabled && !c.podsForbidden {
+		c.op, err = registry.ownerCache(logger, apiCfg, c.Filters.Namespace, c.Rules.WatchedResources)
 		if err != nil {
 			return nil, err
 		}
@@ -110,30 +129,82 @@ func New(
 		zap.String("labelSelector", labelSelector.String()),
 		zap.String("fieldSelector", fieldSelector.String()),
 	)
-	if newInformer == nil {
-		newInformer = newSharedInformer
-	}
 
-	c.informer = newInformer(c.kc, c.Filters.Namespace, labelSelector, fieldSelector)
-	return c, err
+	c.sharedInformer, err = registry.podInformer(apiCfg, c.Filters.Namespace, labelSelector, fieldSelector, c.Rules.ResyncPeriod)
+	if err != nil {
+		if c.op != nil {
+			c.op.Stop()
+		}
+		return nil, err
+	}
+	c.informer = c.sharedInformer.informer
+	go c.deleteLoop(time.Second*30, defaultPodDeleteGracePeriod)
+	return c, nil
 }
 
 // Start registers pod event handlers and starts watching the kubernetes cluster for pod changes.
+// The informers shared with other clients are started only once.
 func (c *WatchClient) Start() {
+	if c.podsForbidden {
+		// There is nothing to wait for, the data is passed through
+		atomic.StoreInt32(&c.synced, 1)
+		<-c.stopCh
+		return
+	}
 	if c.op != nil {
 		c.op.Start()
 	}
 
-	c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	handler := cache.ResourceEventHandlerFuncs{
 		AddFunc:    c.handlePodAdd,
 		UpdateFunc: c.handlePodUpdate,
 		DeleteFunc: c.handlePodDelete,
-	})
-	c.informer.Run(c.stopCh)
+	}
+	if c.Rules.ResyncPeriod > 0 {
+		c.informer.AddEventHandlerWithResyncPeriod(handler, c.Rules.ResyncPeriod)
+	} else {
+		c.informer.AddEventHandler(handler)
+	}
+	c.sharedInformer.start()
+	go c.waitForSync()
+	<-c.stopCh
+}
+
+// waitForSync marks the client as synced once the pods and their owners are cached. As the handlers
+// might still be processing the initial pods and the owners might have not been cached yet when
+// the pods were added, the cached pods are extracted again first.
+func (c *WatchClient) waitForSync() {
+	syncs := []cache.InformerSynced{c.informer.HasSynced}
+	if c.op != nil {
+		syncs = append(syncs, c.op.HasSynced)
+	}
+	if !cache.WaitForCacheSync(c.stopCh, syncs...) {
+		return
+	}
+
+	store := c.informer.GetStore()
+	pods := store.List()
+	for _, obj := range pods {
+		// Skip the pods deleted in the meantime, which would not be forgotten otherwise
+		if pod, ok := obj.(*api_v1.Pod); ok {
+			if _, exists, _ := store.Get(pod); exists {
+				c.addOrUpdatePod(pod)
+			}
+		}
+	}
+	atomic.StoreInt32(&c.synced, 1)
+	c.logger.Info("k8s pods synced", zap.Int("pods", len(pods)))
+}
+
+// HasSynced checks if the pods and their owners have been cached
+func (c *WatchClient) HasSynced() bool {
+	return atomic.LoadInt32(&c.synced) == 1
 }
 
 // Stop signals the the k8s watcher/informer to stop watching for new events.
+// The informers shared with other clients are stopped when the last of them stops.
 func (c *WatchClient) Stop() {
+	c.sharedInformer.release()
 	close(c.stopCh)
 
 	if c.op != nil {
@@ -230,6 +301,21 @@ func (c *WatchClient) GetPod(identifier PodIdentifier) (*Pod, bool) {
 	return nil, false
 }
 
+// GetServicesByIP takes an IP address and returns the services having it among their endpoints.
+// The services are known only when the owner lookup is enabled.
+func (c *WatchClient) GetServicesByIP(identifier PodIdentifier) []string {
+	if c.op == nil {
+		return nil
+	}
+	c.m.RLock()
+	pod, ok := c.Pods[identifier]
+	c.m.RUnlock()
+	if ok && pod.Ignore {
+		return nil
+	}
+	return c.op.GetServicesByIP(string(identifier))
+}
+
 func (c *WatchClient) extractPodAttributes(pod *api_v1.Pod) map[string]string {
 	tags := map[string]string{}
 	if c.Rules.PodName {
@@ -283,26 +369,58 @@ func (c *WatchClient) extractPodAttributes(pod *api_v1.Pod) map[string]string {
 	}
 
 	if c.Rules.OwnerLookupEnabled {
-		owners := c.op.GetOwners(pod)
+		owners := c.op.GetOwners(pod, c.Rules.OwnerLookupMaxDepth)
+		// The deployment name extracted from the pod name is wrong for pods of other workloads
+		otherWorkload := false
+		deploymentFound := false
 
 		for _, owner := range owners {
 			switch owner.kind {
+			case "CronJob":
+				otherWorkload = true
+				if c.Rules.CronJobName {
+					tags[c.Rules.Tags.CronJobName] = owner.name
+				}
 			case "DaemonSet":
+				otherWorkload = true
 				if c.Rules.DaemonSetName {
 					tags[c.Rules.Tags.DaemonSetName] = owner.name
 				}
-			case "DeploymentName":
-				// This should be already set earlier
+			case "Deployment":
+				deploymentFound = true
+				// The owner is more accurate than the name extracted from the pod name earlier
+				if c.Rules.DeploymentName {
+					tags[c.Rules.Tags.DeploymentName] = owner.name
+				}
+			case "Job":
+				otherWorkload = true
 			case "ReplicaSet":
 				if c.Rules.ReplicaSetName {
 					tags[c.Rules.Tags.ReplicaSetName] = owner.name
 				}
 			case "StatefulSet":
+				otherWorkload = true
 				if c.Rules.StatefulSetName {
 					tags[c.Rules.Tags.StatefulSetName] = owner.name
 				}
 			default:
-				// Do nothing
+				if tag, ok := c.Rules.CustomOwnerKinds[owner.kind]; ok {
+					otherWorkload = true
+					tags[tag] = owner.name
+				}
+			}
+		}
+
+		if otherWorkload && !deploymentFound && c.Rules.DeploymentName {
+			delete(tags, c.Rules.Tags.DeploymentName)
+		}
+
+		for _, owner := range owners {
+			if owner.annotations == nil {
+				continue
+			}
+			for _, r := range c.Rules.WorkloadAnnotations {
+				c.extractLabelsIntoTags(r, owner.annotations, tags)
 			}
 		}
 
@@ -328,22 +446,50 @@ func (c *WatchClient) extractPodAttributes(pod *api_v1.Pod) map[string]string {
 		if c.Rules.ContainerImage {
 			tags[c.Rules.Tags.ContainerImage] = container.Image
 		}
+		c.extractImageAttributes(container.Image, imageID(pod.Status.ContainerStatuses, container.Name), tags)
 	}
 
 	if c.Rules.PodUID {
 		tags[c.Rules.Tags.PodUID] = string(pod.UID)
 	}
 
+	if c.Rules.PVCName {
+		var claims []string
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				claims = append(claims, v.PersistentVolumeClaim.ClaimName)
+			}
+		}
+		if len(claims) > 0 {
+			tags[c.Rules.Tags.PVCName] = strings.Join(claims, ", ")
+		}
+	}
+
 	for _, r := range c.Rules.Labels {
 		c.extractLabelsIntoTags(r, pod.Labels, tags)
 	}
 
-	if len(c.Rules.NamespaceLabels) > 0 && c.Rules.OwnerLookupEnabled {
+	if (len(c.Rules.NamespaceLabels) > 0 || len(c.Rules.NamespaceAnnotations) > 0) && c.Rules.OwnerLookupEnabled {
 		namespace := c.op.GetNamespace(pod)
 		if namespace != nil {
 			for _, r := range c.Rules.NamespaceLabels {
 				c.extractLabelsIntoTags(r, namespace.Labels, tags)
 			}
+			for _, r := range c.Rules.NamespaceAnnotations {
+				c.extractLabelsIntoTags(r, namespace.Annotations, tags)
+			}
+		}
+	}
+
+	if (len(c.Rules.NodeLabels) > 0 || c.Rules.KubeletVersion) && c.Rules.OwnerLookupEnabled {
+		node := c.op.GetNode(pod)
+		if node != nil {
+			for _, r := range c.Rules.NodeLabels {
+				c.extractLabelsIntoTags(r, node.Labels, tags)
+			}
+			if c.Rules.KubeletVersion {
+				tags[c.Rules.Tags.KubeletVersion] = node.Status.NodeInfo.KubeletVersion
+			}
 		}
 	}
 
@@ -353,8 +499,96 @@ func (c *WatchClient) extractPodAttributes(pod *api_v1.Pod) map[string]string {
 	return tags
 }
 
+// extractContainerAttributes extracts the metadata of each of the containers, which is added
+// to the data of the container in addition to the pod attributes
+func (c *WatchClient) extractContainerAttributes(pod *api_v1.Pod) map[string]map[string]string {
+	if !c.Rules.ContainerID && !c.Rules.ContainerImage && !c.Rules.ImageDigest && !c.Rules.ImageName && !c.Rules.ImageTag {
+		return nil
+	}
+
+	containers := map[string]map[string]string{}
+	add := func(specs []api_v1.Container, statuses []api_v1.ContainerStatus) {
+		for _, container := range specs {
+			tags := map[string]string{}
+			if c.Rules.ContainerImage {
+				tags[c.Rules.Tags.ContainerImage] = container.Image
+			}
+			c.extractImageAttributes(container.Image, imageID(statuses, container.Name), tags)
+			if c.Rules.ContainerID {
+				for _, cs := range statuses {
+					if cs.Name == container.Name && cs.ContainerID != "" {
+						tags[c.Rules.Tags.ContainerID] = cs.ContainerID
+					}
+				}
+			}
+			containers[container.Name] = tags
+		}
+	}
+	add(pod.Spec.InitContainers, pod.Status.InitContainerStatuses)
+	add(pod.Spec.Containers, pod.Status.ContainerStatuses)
+	return containers
+}
+
+// extractImageAttributes extracts the name, tag and digest of the image, e.g. the name `registry.example.com/app`
+// and the tag `1.4.2` of `registry.example.com/app:1.4.2`. The digest is taken from the image ID reported
+// by the runtime, so it is known also for the images referred to by tag.
+func (c *WatchClient) extractImageAttributes(image string, id string, tags map[string]string) {
+	name, tag := image, ""
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	// The registry might contain a port, e.g. registry.example.com:5000/app
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	} else if name == image {
+		// Neither a tag nor a digest
+		tag = "latest"
+	}
+
+	if c.Rules.ImageName && name != "" {
+		tags[c.Rules.Tags.ImageName] = name
+	}
+	if c.Rules.ImageTag && tag != "" {
+		tags[c.Rules.Tags.ImageTag] = tag
+	}
+	if c.Rules.ImageDigest {
+		if i := strings.Index(id, "@"); i >= 0 {
+			tags[c.Rules.Tags.ImageDigest] = id[i+1:]
+		}
+	}
+}
+
+// imageID returns the ID of the image the container runs, e.g. docker-pullable://nginx@sha256:...
+func imageID(statuses []api_v1.ContainerStatus, container string) string {
+	for _, cs := range statuses {
+		if cs.Name == container {
+			return cs.ImageID
+		}
+	}
+	return ""
+}
+
 func (c *WatchClient) extractLabelsIntoTags(r FieldExtractionRule, labels map[string]string, tags map[string]string) {
-	if r.Key == "*" {
+	if r.KeyRegex != nil {
+		expand := strings.Contains(r.Name, "$")
+		for label, value := range labels {
+			matches := r.KeyRegex.FindStringSubmatchIndex(label)
+			if matches == nil {
+				continue
+			}
+			var name string
+			switch {
+			case expand:
+				// The name refers to the capturing groups, e.g. ${team} or $1
+				name = string(r.KeyRegex.ExpandString(nil, r.Name, label, matches))
+			case len(matches) == 4:
+				name = fmt.Sprintf(r.Name, label[matches[2]:matches[3]])
+			default:
+				name = fmt.Sprintf(r.Name, label)
+			}
+			tags[name] = c.extractField(value, r)
+		}
+	} else if r.Key == "*" {
 		// Special case, extract everything
 		for label, value := range labels {
 			tags[fmt.Sprintf(r.Name, label)] = c.extractField(value, r)
@@ -392,6 +626,7 @@ func (c *WatchClient) addOrUpdatePod(pod *api_v1.Pod) {
 		newPod.Ignore = true
 	} else {
 		newPod.Attributes = c.extractPodAttributes(pod)
+		newPod.Containers = c.extractContainerAttributes(pod)
 	}
 
 	c.m.Lock()
@@ -400,7 +635,9 @@ func (c *WatchClient) addOrUpdatePod(pod *api_v1.Pod) {
 	if pod.UID != "" {
 		c.Pods[PodIdentifier(pod.UID)] = newPod
 	}
-	if pod.Status.PodIP != "" {
+	// Pods in the host network share the IP address of the node, so they can be only
+	// associated using the other identifiers
+	if pod.Status.PodIP != "" && !pod.Spec.HostNetwork {
 		// compare initial scheduled timestamp for existing pod and new pod with same IP
 		// and only replace old pod if scheduled time of new pod is newer? This should fix
 		// the case where scheduler has assigned the same IP to a new pod but update event for
@@ -412,6 +649,15 @@ func (c *WatchClient) addOrUpdatePod(pod *api_v1.Pod) {
 		}
 		c.Pods[PodIdentifier(pod.Status.PodIP)] = newPod
 	}
+	// The pods in the host network can be still told apart by the ports they listen on
+	for _, id := range hostPortIdentifiers(pod) {
+		if p, ok := c.Pods[id]; ok {
+			if p.StartTime != nil && pod.Status.StartTime.Before(p.StartTime) {
+				continue
+			}
+		}
+		c.Pods[id] = newPod
+	}
 	// Use pod_name.namespace_name identifier
 	if newPod.Name != "" && newPod.Attributes[c.Rules.Tags.Namespace] != "" {
 		c.Pods[PodIdentifier(fmt.Sprintf("%s.%s", newPod.Name, newPod.Attributes[c.Rules.Tags.Namespace]))] = newPod
@@ -434,6 +680,31 @@ func (c *WatchClient) forgetPod(pod *api_v1.Pod) {
 	if ok && p.Name == pod.Name {
 		c.appendDeleteQueue(PodIdentifier(pod.UID), pod.Name)
 	}
+
+	for _, id := range hostPortIdentifiers(pod) {
+		c.m.RLock()
+		p, ok = c.GetPod(id)
+		c.m.RUnlock()
+
+		if ok && p.Name == pod.Name {
+			c.appendDeleteQueue(id, pod.Name)
+		}
+	}
+}
+
+// hostPortIdentifiers returns the `ip:port` identifiers of the ports declared by the containers
+// of a pod in the host network
+func hostPortIdentifiers(pod *api_v1.Pod) []PodIdentifier {
+	if !pod.Spec.HostNetwork || pod.Status.PodIP == "" {
+		return nil
+	}
+	var ids []PodIdentifier
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			ids = append(ids, PodIdentifier(net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port.ContainerPort)))))
+		}
+	}
+	return ids
 }
 
 func (c *WatchClient) appendDeleteQueue(podID PodIdentifier, podName string) {
@@ -447,15 +718,6 @@ func (c *WatchClient) appendDeleteQueue(podID PodIdentifier, podName string) {
 }
 
 func (c *WatchClient) shouldIgnorePod(pod *api_v1.Pod) bool {
-	// Host network mode is not supported right now with IP based
-	// tagging as all pods in host network get same IP addresses.
-	// Such pods are very rare and usually are used to monitor or control
-	// host traffic (e.g, linkerd, flannel) instead of service business needs.
-	// We plan to support host network pods in future.
-	if pod.Spec.HostNetwork {
-		return true
-	}
-
 	// Check if user requested the pod to be ignored through annotations
 	if v, ok := pod.Annotations[ignoreAnnotation]; ok {
 		if strings.ToLower(strings.TrimSpace(v)) == "true" {
@@ -470,18 +732,45 @@ func (c *WatchClient) shouldIgnorePod(pod *api_v1.Pod) bool {
 		}
 	}
 
-	return false
+	return c.isExcluded(pod)
 }
 
-func selectorsFromFilters(filters Filters) (labels.Selector, fields.Selector, error) {
+// isExcluded checks if the pod matches the configured exclusions
+func (c *WatchClient) isExcluded(pod *api_v1.Pod) bool {
+	for _, rexp := range c.Filters.Exclude.Namespaces {
+		if rexp.MatchString(pod.Namespace) {
+			return true
+		}
+	}
+	for _, rexp := range c.Filters.Exclude.Pods {
+		if rexp.MatchString(pod.Name) {
+			return true
+		}
+	}
+	return c.excludeLabels != nil && c.excludeLabels.Matches(labels.Set(pod.Labels))
+}
+
+func labelSelectorFromFilters(filters []FieldFilter) (labels.Selector, error) {
 	labelSelector := labels.Everything()
-	for _, f := range filters.Labels {
-		r, err := labels.NewRequirement(f.Key, f.Op, []string{f.Value})
+	for _, f := range filters {
+		var values []string
+		if f.Op != selection.Exists && f.Op != selection.DoesNotExist {
+			values = []string{f.Value}
+		}
+		r, err := labels.NewRequirement(f.Key, f.Op, values)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		labelSelector = labelSelector.Add(*r)
 	}
+	return labelSelector, nil
+}
+
+func selectorsFromFilters(filters Filters) (labels.Selector, fields.Selector, error) {
+	labelSelector, err := labelSelectorFromFilters(filters.Labels)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	var selectors []fields.Selector
 	for _, f := range filters.Fields {
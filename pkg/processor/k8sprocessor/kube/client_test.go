@@ -31,13 +31,14 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 )
 
-func newFakeAPIClientset(_ k8sconfig.APIConfig) (kubernetes.Interface, error) {
+func newFakeAPIClientset(_ APIConfig) (kubernetes.Interface, error) {
 	return fake.NewSimpleClientset(), nil
 }
 
@@ -87,12 +88,12 @@ func podAddAndUpdateTest(t *testing.T, c *WatchClient, handler func(obj interfac
 }
 
 func TestDefaultClientset(t *testing.T) {
-	c, err := New(zap.NewNop(), k8sconfig.APIConfig{}, ExtractionRules{}, Filters{}, []Association{}, nil, nil, nil)
+	c, err := New(zap.NewNop(), APIConfig{}, ExtractionRules{}, Filters{}, []Association{}, nil, nil, nil)
 	assert.Error(t, err)
 	assert.Equal(t, "invalid authType for kubernetes: ", err.Error())
 	assert.Nil(t, c)
 
-	c, err = New(zap.NewNop(), k8sconfig.APIConfig{}, ExtractionRules{}, Filters{}, []Association{}, newFakeAPIClientset, nil, nil)
+	c, err = New(zap.NewNop(), APIConfig{}, ExtractionRules{}, Filters{}, []Association{}, newFakeAPIClientset, nil, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, c)
 }
@@ -100,7 +101,7 @@ func TestDefaultClientset(t *testing.T) {
 func TestBadFilters(t *testing.T) {
 	c, err := New(
 		zap.NewNop(),
-		k8sconfig.APIConfig{},
+		APIConfig{},
 		ExtractionRules{},
 		Filters{Fields: []FieldFilter{{Op: selection.Exists}}},
 		[]Association{},
@@ -130,15 +131,29 @@ func TestClientStartStop(t *testing.T) {
 	assert.True(t, fctr.HasStopped())
 }
 
+func TestClientHasSynced(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{OwnerLookupEnabled: true}, Filters{})
+	assert.False(t, c.HasSynced())
+
+	done := make(chan struct{})
+	go func() {
+		c.Start()
+		close(done)
+	}()
+	assert.Eventually(t, c.HasSynced, time.Second, 10*time.Millisecond)
+	c.Stop()
+	<-done
+}
+
 func TestConstructorErrors(t *testing.T) {
 	er := ExtractionRules{}
 	ff := Filters{}
 	t.Run("client-provider-call", func(t *testing.T) {
-		var gotAPIConfig k8sconfig.APIConfig
-		apiCfg := k8sconfig.APIConfig{
-			AuthType: "test-auth-type",
+		var gotAPIConfig APIConfig
+		apiCfg := APIConfig{
+			APIConfig: k8sconfig.APIConfig{AuthType: "test-auth-type"},
 		}
-		clientProvider := func(c k8sconfig.APIConfig) (kubernetes.Interface, error) {
+		clientProvider := func(c APIConfig) (kubernetes.Interface, error) {
 			gotAPIConfig = c
 			return nil, fmt.Errorf("error creating k8s client")
 		}
@@ -161,14 +176,48 @@ func TestPodHostNetwork(t *testing.T) {
 
 	pod := &api_v1.Pod{}
 	pod.Name = "podA"
+	pod.UID = "11111"
 	pod.Status.PodIP = "1.1.1.1"
 	pod.Spec.HostNetwork = true
 	c.handlePodAdd(pod)
+	// The IP address of the node is not associated with the pod
 	assert.Equal(t, len(c.Pods), 1)
-	got := c.Pods["1.1.1.1"]
+	got := c.Pods["11111"]
 	assert.Equal(t, got.Address, "1.1.1.1")
 	assert.Equal(t, got.Name, "podA")
-	assert.True(t, got.Ignore)
+	assert.False(t, got.Ignore)
+	_, ok := c.GetPod("1.1.1.1")
+	assert.False(t, ok)
+}
+
+func TestPodHostNetworkPorts(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	pod := &api_v1.Pod{}
+	pod.Name = "node-exporter"
+	pod.UID = "11111"
+	pod.Status.PodIP = "1.1.1.1"
+	pod.Spec.HostNetwork = true
+	pod.Spec.Containers = []api_v1.Container{
+		{Name: "exporter", Ports: []api_v1.ContainerPort{{ContainerPort: 9100}}},
+		{Name: "proxy", Ports: []api_v1.ContainerPort{{ContainerPort: 9101}, {ContainerPort: 9102}}},
+	}
+	c.handlePodAdd(pod)
+	assert.Len(t, c.Pods, 4)
+	for _, id := range []PodIdentifier{"1.1.1.1:9100", "1.1.1.1:9101", "1.1.1.1:9102"} {
+		got, ok := c.GetPod(id)
+		require.True(t, ok)
+		assert.Equal(t, "node-exporter", got.Name)
+	}
+	_, ok := c.GetPod("1.1.1.1")
+	assert.False(t, ok)
+
+	c.handlePodDelete(pod)
+	var deleted []PodIdentifier
+	for _, r := range c.deleteQueue {
+		deleted = append(deleted, r.id)
+	}
+	assert.ElementsMatch(t, []PodIdentifier{"11111", "1.1.1.1:9100", "1.1.1.1:9101", "1.1.1.1:9102"}, deleted)
 }
 
 func TestPodAddOutOfSync(t *testing.T) {
@@ -574,12 +623,69 @@ func TestExtractionRules(t *testing.T) {
 					Key:  "*",
 				},
 				},
+				NamespaceAnnotations: []FieldExtractionRule{{
+					Name: "namespace_annotations_%s",
+					Key:  "*",
+				},
+				},
+			},
+			attributes: map[string]string{
+				"k8s.pod.label.label1":             "lv1",
+				"k8s.pod.label.label2":             "k1=v1 k5=v5 extra!",
+				"k8s.pod.annotation.annotation1":   "av1",
+				"namespace_labels_label":           "namespace_label_value",
+				"namespace_annotations_annotation": "namespace_annotation_value",
+			},
+		},
+		{
+			name: "node",
+			rules: ExtractionRules{
+				KubeletVersion:     true,
+				OwnerLookupEnabled: true,
+				Tags:               NewExtractionFieldTags(),
+				NodeLabels: []FieldExtractionRule{{
+					Name: "k8s.node.zone",
+					Key:  "topology.kubernetes.io/zone",
+				},
+				},
+			},
+			attributes: map[string]string{
+				"k8s.node.zone":            "us-east-1a",
+				"k8s.node.kubelet_version": "v1.21.2",
+			},
+		},
+		{
+			name: "key-regex",
+			rules: ExtractionRules{
+				OwnerLookupEnabled: true,
+				Tags:               NewExtractionFieldTags(),
+				Labels: []FieldExtractionRule{{
+					Name:     "k8s.pod.label.%s",
+					KeyRegex: regexp.MustCompile(`^label(\d)$`),
+				}, {
+					Name:     "pod_${kind}_${index}",
+					KeyRegex: regexp.MustCompile(`^(?P<kind>[a-z]+)(?P<index>\d)$`),
+				},
+				},
+				NamespaceLabels: []FieldExtractionRule{{
+					Name:     "namespace_%s",
+					KeyRegex: regexp.MustCompile(`^lab`),
+				},
+				},
+				NamespaceAnnotations: []FieldExtractionRule{{
+					Name:     "ns_%s",
+					KeyRegex: regexp.MustCompile(`^(annotation)$`),
+					Regex:    regexp.MustCompile(`^(?P<value>\w+?)_`),
+				},
+				},
 			},
 			attributes: map[string]string{
-				"k8s.pod.label.label1":           "lv1",
-				"k8s.pod.label.label2":           "k1=v1 k5=v5 extra!",
-				"k8s.pod.annotation.annotation1": "av1",
-				"namespace_labels_label":         "namespace_label_value",
+				"k8s.pod.label.1": "lv1",
+				"k8s.pod.label.2": "k1=v1 k5=v5 extra!",
+				"pod_label_1":     "lv1",
+				"pod_label_2":     "k1=v1 k5=v5 extra!",
+				"namespace_label": "namespace_label_value",
+				"ns_annotation":   "namespace",
 			},
 		},
 	}
@@ -600,6 +706,241 @@ func TestExtractionRules(t *testing.T) {
 	}
 }
 
+func TestExtractionRulesWorkloadOwners(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{OwnerLookupEnabled: true}, Filters{})
+
+	op, err := newOwnerProvider(zap.NewNop(), fake.NewSimpleClientset(), labels.Everything(), fields.Everything(), "", nil)
+	require.NoError(t, err)
+	ownerCache := op.(*OwnerCache)
+	c.op = ownerCache
+
+	owner := func(kind, name, uid string, ownerUIDs ...string) {
+		meta := &meta_v1.ObjectMeta{Name: name, Namespace: "ns1", UID: types.UID(uid)}
+		for _, ownerUID := range ownerUIDs {
+			meta.OwnerReferences = append(meta.OwnerReferences, meta_v1.OwnerReference{UID: types.UID(ownerUID)})
+		}
+		ownerCache.cacheObject(kind, meta)
+	}
+	owner("Deployment", "checkout", "deployment-uid")
+	owner("ReplicaSet", "checkout-5db86d8867", "replicaset-uid", "deployment-uid")
+	owner("CronJob", "nightly-report", "cronjob-uid")
+	owner("Job", "nightly-report-27153720", "job-uid", "cronjob-uid")
+	owner("DaemonSet", "fluent-bit", "daemonset-uid")
+	owner("StatefulSet", "kafka", "statefulset-uid")
+	ownerCache.cacheObject("ReplicaSet", &meta_v1.ObjectMeta{
+		Name:      "canary-6f8b9c7d5",
+		Namespace: "ns1",
+		UID:       "rollout-replicaset-uid",
+		OwnerReferences: []meta_v1.OwnerReference{
+			{Kind: "Rollout", Name: "canary", UID: "rollout-uid"},
+		},
+	})
+
+	rules := ExtractionRules{
+		CustomOwnerKinds:   map[string]string{"Rollout": "k8s.rollout.name"},
+		CronJobName:        true,
+		DaemonSetName:      true,
+		DeploymentName:     true,
+		ReplicaSetName:     true,
+		StatefulSetName:    true,
+		OwnerLookupEnabled: true,
+		Tags:               NewExtractionFieldTags(),
+	}
+
+	testCases := []struct {
+		name       string
+		podName    string
+		ownerUID   string
+		attributes map[string]string
+	}{{
+		name:     "deployment",
+		podName:  "checkout-5db86d8867-sdqlj",
+		ownerUID: "replicaset-uid",
+		attributes: map[string]string{
+			"k8s.deployment.name": "checkout",
+			"k8s.replicaset.name": "checkout-5db86d8867",
+		},
+	}, {
+		name:     "cronjob",
+		podName:  "nightly-report-27153720-x2kd9",
+		ownerUID: "job-uid",
+		attributes: map[string]string{
+			"k8s.cronjob.name": "nightly-report",
+		},
+	}, {
+		name:     "daemonset",
+		podName:  "fluent-bit-x2kd9",
+		ownerUID: "daemonset-uid",
+		attributes: map[string]string{
+			"k8s.daemonset.name": "fluent-bit",
+		},
+	}, {
+		name:     "statefulset",
+		podName:  "kafka-0",
+		ownerUID: "statefulset-uid",
+		attributes: map[string]string{
+			"k8s.statefulset.name": "kafka",
+		},
+	}, {
+		name:     "rollout",
+		podName:  "canary-6f8b9c7d5-x2kd9",
+		ownerUID: "rollout-replicaset-uid",
+		attributes: map[string]string{
+			"k8s.replicaset.name": "canary-6f8b9c7d5",
+			"k8s.rollout.name":    "canary",
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c.Rules = rules
+			pod := &api_v1.Pod{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      tc.podName,
+					Namespace: "ns1",
+					UID:       types.UID(tc.name),
+					OwnerReferences: []meta_v1.OwnerReference{
+						{UID: types.UID(tc.ownerUID)},
+					},
+				},
+				Status: api_v1.PodStatus{
+					PodIP: "1.1.1.1",
+				},
+			}
+			c.handlePodAdd(pod)
+			p, ok := c.GetPod(PodIdentifier(pod.Status.PodIP))
+			require.True(t, ok)
+			assert.Equal(t, tc.attributes, p.Attributes)
+		})
+	}
+}
+
+func TestExtractionRulesWorkloadAnnotationsAndPVCs(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{}, Filters{})
+	ownerCache := newTestOwnerCache(t)
+	c.op = ownerCache
+
+	ownerCache.cacheObject("StatefulSet", &meta_v1.ObjectMeta{
+		Name:      "kafka",
+		Namespace: "ns1",
+		UID:       "statefulset-uid",
+		Annotations: map[string]string{
+			"example.com/team":            "streaming",
+			lastAppliedConfigAnnotation:   "{}",
+			"example.com/backup-schedule": "daily",
+		},
+	})
+	c.Rules = ExtractionRules{
+		OwnerLookupEnabled: true,
+		PVCName:            true,
+		WorkloadAnnotations: []FieldExtractionRule{
+			{Name: "team", Key: "example.com/team"},
+			{Name: "k8s.workload.annotation.%s", KeyRegex: regexp.MustCompile(`^kubectl\.kubernetes\.io/.*$`)},
+		},
+		Tags: NewExtractionFieldTags(),
+	}
+
+	pod := &api_v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "kafka-0",
+			Namespace: "ns1",
+			UID:       "kafka-0-uid",
+			OwnerReferences: []meta_v1.OwnerReference{
+				{Kind: "StatefulSet", Name: "kafka", UID: "statefulset-uid"},
+			},
+		},
+		Spec: api_v1.PodSpec{
+			Volumes: []api_v1.Volume{
+				{Name: "data", VolumeSource: api_v1.VolumeSource{
+					PersistentVolumeClaim: &api_v1.PersistentVolumeClaimVolumeSource{ClaimName: "data-kafka-0"},
+				}},
+				{Name: "config", VolumeSource: api_v1.VolumeSource{
+					ConfigMap: &api_v1.ConfigMapVolumeSource{},
+				}},
+				{Name: "logs", VolumeSource: api_v1.VolumeSource{
+					PersistentVolumeClaim: &api_v1.PersistentVolumeClaimVolumeSource{ClaimName: "logs-kafka-0"},
+				}},
+			},
+		},
+	}
+	c.handlePodAdd(pod)
+	p, ok := c.GetPod(PodIdentifier("kafka-0-uid"))
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{
+		"team":         "streaming",
+		"k8s.pvc.name": "data-kafka-0, logs-kafka-0",
+	}, p.Attributes)
+}
+
+func TestExtractionRulesContainers(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{
+		ContainerID:    true,
+		ContainerImage: true,
+		ContainerName:  true,
+		ImageDigest:    true,
+		ImageName:      true,
+		ImageTag:       true,
+		Tags:           NewExtractionFieldTags(),
+	}, Filters{})
+
+	pod := &api_v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "podA", UID: "podA-uid"},
+		Spec: api_v1.PodSpec{
+			InitContainers: []api_v1.Container{
+				{Name: "init", Image: "busybox"},
+			},
+			Containers: []api_v1.Container{
+				{Name: "app", Image: "registry.example.com:5000/team/app:1.4.2"},
+				{Name: "proxy", Image: "envoyproxy/envoy@sha256:0123abcd"},
+			},
+		},
+		Status: api_v1.PodStatus{
+			InitContainerStatuses: []api_v1.ContainerStatus{
+				{Name: "init", ContainerID: "containerd://init", ImageID: "docker.io/library/busybox@sha256:aaaa"},
+			},
+			ContainerStatuses: []api_v1.ContainerStatus{
+				{Name: "proxy", ContainerID: "containerd://proxy", ImageID: "docker.io/envoyproxy/envoy@sha256:0123abcd"},
+				{Name: "app", ContainerID: "containerd://app", ImageID: "registry.example.com:5000/team/app@sha256:bbbb"},
+			},
+		},
+	}
+	c.handlePodAdd(pod)
+	p, ok := c.GetPod("podA-uid")
+	require.True(t, ok)
+
+	assert.Equal(t, "registry.example.com:5000/team/app", p.Attributes["k8s.container.image.name"])
+	assert.Equal(t, "1.4.2", p.Attributes["k8s.container.image.tag"])
+	assert.Equal(t, "sha256:bbbb", p.Attributes["k8s.container.image.digest"])
+
+	assert.Equal(t, map[string]map[string]string{
+		"init": {
+			"k8s.container.id":           "containerd://init",
+			"k8s.container.image":        "busybox",
+			"k8s.container.image.name":   "busybox",
+			"k8s.container.image.tag":    "latest",
+			"k8s.container.image.digest": "sha256:aaaa",
+		},
+		"app": {
+			"k8s.container.id":           "containerd://app",
+			"k8s.container.image":        "registry.example.com:5000/team/app:1.4.2",
+			"k8s.container.image.name":   "registry.example.com:5000/team/app",
+			"k8s.container.image.tag":    "1.4.2",
+			"k8s.container.image.digest": "sha256:bbbb",
+		},
+		"proxy": {
+			"k8s.container.id":           "containerd://proxy",
+			"k8s.container.image":        "envoyproxy/envoy@sha256:0123abcd",
+			"k8s.container.image.name":   "envoyproxy/envoy",
+			"k8s.container.image.digest": "sha256:0123abcd",
+		},
+	}, p.Containers)
+
+	c.Rules = ExtractionRules{PodName: true, Tags: NewExtractionFieldTags()}
+	c.handlePodAdd(pod)
+	p, ok = c.GetPod("podA-uid")
+	require.True(t, ok)
+	assert.Nil(t, p.Containers)
+}
+
 func TestFilters(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -673,7 +1014,7 @@ func TestPodIgnorePatterns(t *testing.T) {
 		ignore: false,
 		pod:    api_v1.Pod{},
 	}, {
-		ignore: true,
+		ignore: false,
 		pod: api_v1.Pod{
 			Spec: api_v1.PodSpec{
 				HostNetwork: true,
@@ -738,6 +1079,71 @@ func TestPodIgnorePatterns(t *testing.T) {
 	}
 }
 
+func TestPodExcludes(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{PodName: true, Tags: NewExtractionFieldTags()}, Filters{
+		Exclude: Excludes{
+			Namespaces: []*regexp.Regexp{regexp.MustCompile("^kube-")},
+			Pods:       []*regexp.Regexp{regexp.MustCompile("^cleanup-")},
+			Labels: []FieldFilter{
+				{Key: "job-name", Op: selection.Exists},
+				{Key: "team", Value: "batch", Op: selection.Equals},
+			},
+		},
+	})
+
+	testCases := []struct {
+		name    string
+		exclude bool
+		pod     meta_v1.ObjectMeta
+	}{
+		{name: "kept", exclude: false, pod: meta_v1.ObjectMeta{Name: "app", Namespace: "default"}},
+		{name: "namespace", exclude: true, pod: meta_v1.ObjectMeta{Name: "dns", Namespace: "kube-system"}},
+		{name: "pod", exclude: true, pod: meta_v1.ObjectMeta{Name: "cleanup-1234", Namespace: "default"}},
+		{
+			name:    "labels",
+			exclude: true,
+			pod: meta_v1.ObjectMeta{
+				Name:      "report-1234",
+				Namespace: "default",
+				Labels:    map[string]string{"job-name": "report", "team": "batch"},
+			},
+		},
+		{
+			name:    "some labels",
+			exclude: false,
+			pod: meta_v1.ObjectMeta{
+				Name:      "report-1234",
+				Namespace: "default",
+				Labels:    map[string]string{"job-name": "report", "team": "web"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &api_v1.Pod{ObjectMeta: tc.pod}
+			pod.UID = types.UID(tc.name)
+			c.handlePodAdd(pod)
+
+			got, ok := c.Pods[PodIdentifier(tc.name)]
+			require.True(t, ok)
+			assert.Equal(t, tc.exclude, got.Ignore)
+			if tc.exclude {
+				assert.Empty(t, got.Attributes)
+			} else {
+				assert.Equal(t, tc.pod.Name, got.Attributes["k8s.pod.name"])
+			}
+		})
+	}
+}
+
+func TestPodExcludesInvalidLabels(t *testing.T) {
+	_, err := New(zap.NewNop(), APIConfig{}, ExtractionRules{}, Filters{
+		Exclude: Excludes{Labels: []FieldFilter{{Key: "a", Value: "b", Op: selection.GreaterThan}}},
+	}, []Association{}, newFakeAPIClientset, NewFakeInformer, newFakeOwnerProvider)
+	assert.Error(t, err)
+}
+
 func Test_extractField(t *testing.T) {
 	c := WatchClient{}
 	type args struct {
@@ -830,7 +1236,7 @@ func Test_selectorsFromFilters(t *testing.T) {
 func newTestClientWithRulesAndFilters(t *testing.T, e ExtractionRules, f Filters) (*WatchClient, *observer.ObservedLogs) {
 	observedLogger, logs := observer.New(zapcore.WarnLevel)
 	logger := zap.New(observedLogger)
-	c, err := New(logger, k8sconfig.APIConfig{}, e, f, []Association{}, newFakeAPIClientset, NewFakeInformer, newFakeOwnerProvider)
+	c, err := New(logger, APIConfig{}, e, f, []Association{}, newFakeAPIClientset, NewFakeInformer, newFakeOwnerProvider)
 	require.NoError(t, err)
 	return c.(*WatchClient), logs
 }
@@ -0,0 +1,159 @@
+// Copyright 2021 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"sort"
+
+	api_v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
+	discovery_v1beta1 "k8s.io/api/discovery/v1beta1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// serviceEndpoints keeps the pods and addresses backing a service, found either in Endpoints or EndpointSlices
+type serviceEndpoints struct {
+	service   string
+	namespace string
+	pods      []string
+	ips       []string
+}
+
+// serviceEndpointsFunc extracts serviceEndpoints from an informer object
+type serviceEndpointsFunc func(obj interface{}) (serviceEndpoints, bool)
+
+func podKey(namespace string, name string) string {
+	return namespace + "/" + name
+}
+
+func fromEndpoints(obj interface{}) (serviceEndpoints, bool) {
+	ep, ok := obj.(*api_v1.Endpoints)
+	if !ok {
+		return serviceEndpoints{}, false
+	}
+
+	se := serviceEndpoints{service: ep.Name, namespace: ep.Namespace}
+	addAddresses := func(addresses []api_v1.EndpointAddress) {
+		for _, addr := range addresses {
+			se.ips = append(se.ips, addr.IP)
+			if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
+				se.pods = append(se.pods, addr.TargetRef.Name)
+			}
+		}
+	}
+	for _, it := range ep.Subsets {
+		addAddresses(it.Addresses)
+		addAddresses(it.NotReadyAddresses)
+	}
+	return se, true
+}
+
+func fromEndpointSliceV1(obj interface{}) (serviceEndpoints, bool) {
+	slice, ok := obj.(*discovery_v1.EndpointSlice)
+	if !ok {
+		return serviceEndpoints{}, false
+	}
+
+	se := serviceEndpoints{service: slice.Labels[discovery_v1.LabelServiceName], namespace: slice.Namespace}
+	for _, endpoint := range slice.Endpoints {
+		se.ips = append(se.ips, endpoint.Addresses...)
+		if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+			se.pods = append(se.pods, endpoint.TargetRef.Name)
+		}
+	}
+	return se, se.service != ""
+}
+
+func fromEndpointSliceV1beta1(obj interface{}) (serviceEndpoints, bool) {
+	slice, ok := obj.(*discovery_v1beta1.EndpointSlice)
+	if !ok {
+		return serviceEndpoints{}, false
+	}
+
+	se := serviceEndpoints{service: slice.Labels[discovery_v1beta1.LabelServiceName], namespace: slice.Namespace}
+	for _, endpoint := range slice.Endpoints {
+		se.ips = append(se.ips, endpoint.Addresses...)
+		if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+			se.pods = append(se.pods, endpoint.TargetRef.Name)
+		}
+	}
+	return se, se.service != ""
+}
+
+// withTombstone makes the function accept the final state of objects deleted while the watch was down
+func withTombstone(f serviceEndpointsFunc) serviceEndpointsFunc {
+	return func(obj interface{}) (serviceEndpoints, bool) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		return f(obj)
+	}
+}
+
+// isResourceServed checks if the API server serves the resource in the given group version
+func isResourceServed(client kubernetes.Interface, groupVersion string, resource string) bool {
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil || resources == nil {
+		return false
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceSet counts the references to each service, since a pod or address might be listed
+// in more than one EndpointSlice of the same service
+type serviceSet struct {
+	counts map[string]int
+	// names are sorted and replaced on every change, so they can be returned to the callers
+	names []string
+}
+
+func (s *serviceSet) add(service string) {
+	if s.counts[service] == 0 {
+		names := make([]string, 0, len(s.names)+1)
+		names = append(names, s.names...)
+		names = append(names, service)
+		sort.Strings(names)
+		s.names = names
+	}
+	s.counts[service]++
+}
+
+// remove drops a reference to the service and returns true when the set becomes empty
+func (s *serviceSet) remove(service string) bool {
+	count, found := s.counts[service]
+	if !found {
+		return len(s.counts) == 0
+	}
+	if count > 1 {
+		s.counts[service] = count - 1
+		return false
+	}
+
+	delete(s.counts, service)
+	names := make([]string, 0, len(s.names))
+	for _, name := range s.names {
+		if name != service {
+			names = append(names, name)
+		}
+	}
+	s.names = names
+	return len(s.counts) == 0
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	api_v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestOwnerCache(t *testing.T) *OwnerCache {
+	op, err := newOwnerProvider(zap.NewNop(), fake.NewSimpleClientset(), labels.Everything(), fields.Everything(), "", nil)
+	require.NoError(t, err)
+	return op.(*OwnerCache)
+}
+
+func podOf(namespace string, name string) *api_v1.Pod {
+	return &api_v1.Pod{ObjectMeta: meta_v1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func TestServicesFromEndpoints(t *testing.T) {
+	op := newTestOwnerCache(t)
+
+	endpoints := func(service string, ips ...string) *api_v1.Endpoints {
+		ep := &api_v1.Endpoints{
+			ObjectMeta: meta_v1.ObjectMeta{Namespace: "shop", Name: service},
+			Subsets:    []api_v1.EndpointSubset{{}},
+		}
+		for i, ip := range ips {
+			addr := api_v1.EndpointAddress{
+				IP:        ip,
+				TargetRef: &api_v1.ObjectReference{Kind: "Pod", Name: "pod-" + ip},
+			}
+			if i%2 == 0 {
+				ep.Subsets[0].Addresses = append(ep.Subsets[0].Addresses, addr)
+			} else {
+				ep.Subsets[0].NotReadyAddresses = append(ep.Subsets[0].NotReadyAddresses, addr)
+			}
+		}
+		return ep
+	}
+
+	op.cacheServiceEndpoints(fromEndpoints(endpoints("checkout", "10.0.0.1", "10.0.0.2")))
+	op.cacheServiceEndpoints(fromEndpoints(endpoints("backend", "10.0.0.1")))
+
+	assert.Equal(t, []string{"backend", "checkout"}, op.GetServicesByIP("10.0.0.1"))
+	assert.Equal(t, []string{"checkout"}, op.GetServicesByIP("10.0.0.2"))
+	assert.Equal(t, []string{"backend", "checkout"}, op.GetServices(podOf("shop", "pod-10.0.0.1")))
+	assert.Equal(t, []string{}, op.GetServices(podOf("other", "pod-10.0.0.1")))
+
+	// An update replaces the addresses of the service
+	op.deleteServiceEndpoints(fromEndpoints(endpoints("checkout", "10.0.0.1", "10.0.0.2")))
+	op.cacheServiceEndpoints(fromEndpoints(endpoints("checkout", "10.0.0.3")))
+	assert.Equal(t, []string{"backend"}, op.GetServicesByIP("10.0.0.1"))
+	assert.Equal(t, []string{}, op.GetServicesByIP("10.0.0.2"))
+	assert.Equal(t, []string{"checkout"}, op.GetServicesByIP("10.0.0.3"))
+
+	deleted := cache.DeletedFinalStateUnknown{Key: "shop/backend", Obj: endpoints("backend", "10.0.0.1")}
+	op.deleteServiceEndpoints(withTombstone(fromEndpoints)(deleted))
+	assert.Equal(t, []string{}, op.GetServicesByIP("10.0.0.1"))
+	assert.Empty(t, op.ipServices["10.0.0.1"])
+}
+
+func TestServicesFromEndpointSlices(t *testing.T) {
+	op := newTestOwnerCache(t)
+
+	slice := func(name string, ip string) *discovery_v1.EndpointSlice {
+		return &discovery_v1.EndpointSlice{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Namespace: "shop",
+				Name:      name,
+				Labels:    map[string]string{discovery_v1.LabelServiceName: "checkout"},
+			},
+			Endpoints: []discovery_v1.Endpoint{{
+				Addresses: []string{ip},
+				TargetRef: &api_v1.ObjectReference{Kind: "Pod", Name: "checkout-0"},
+			}},
+		}
+	}
+
+	// A dual-stack pod is listed in two slices of the same service
+	op.cacheServiceEndpoints(fromEndpointSliceV1(slice("checkout-ipv4", "10.0.0.1")))
+	op.cacheServiceEndpoints(fromEndpointSliceV1(slice("checkout-ipv6", "fd00::1")))
+	assert.Equal(t, []string{"checkout"}, op.GetServicesByIP("10.0.0.1"))
+	assert.Equal(t, []string{"checkout"}, op.GetServicesByIP("fd00::1"))
+
+	op.deleteServiceEndpoints(fromEndpointSliceV1(slice("checkout-ipv6", "fd00::1")))
+	assert.Equal(t, []string{}, op.GetServicesByIP("fd00::1"))
+	assert.Equal(t, []string{"checkout"}, op.GetServices(podOf("shop", "checkout-0")))
+
+	// The slices not managed for a service are skipped
+	unmanaged := slice("custom", "10.0.0.2")
+	unmanaged.Labels = nil
+	op.cacheServiceEndpoints(fromEndpointSliceV1(unmanaged))
+	assert.Equal(t, []string{}, op.GetServicesByIP("10.0.0.2"))
+}
+
+func TestServiceInformerSelection(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	assert.False(t, isResourceServed(client, "discovery.k8s.io/v1", "endpointslices"))
+
+	client.Resources = []*meta_v1.APIResourceList{{
+		GroupVersion: "discovery.k8s.io/v1",
+		APIResources: []meta_v1.APIResource{{Name: "endpointslices"}},
+	}}
+	assert.True(t, isResourceServed(client, "discovery.k8s.io/v1", "endpointslices"))
+	assert.False(t, isResourceServed(client, "discovery.k8s.io/v1beta1", "endpointslices"))
+}
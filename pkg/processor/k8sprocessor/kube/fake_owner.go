@@ -20,7 +20,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -35,7 +34,8 @@ func newFakeOwnerProvider(logger *zap.Logger,
 	client kubernetes.Interface,
 	labelSelector labels.Selector,
 	fieldSelector fields.Selector,
-	namespace string) (OwnerAPI, error) {
+	namespace string,
+	resources []string) (OwnerAPI, error) {
 	ownerCache := fakeOwnerCache{}
 	ownerCache.objectOwners = map[string]*ObjectOwner{}
 	ownerCache.logger = logger
@@ -43,7 +43,7 @@ func newFakeOwnerProvider(logger *zap.Logger,
 	oo := ObjectOwner{
 		UID:       "1a1658f9-7818-11e9-90f1-02324f7e0d1e",
 		namespace: "kube-system",
-		ownerUIDs: []types.UID{},
+		ownerRefs: []metav1.OwnerReference{},
 		kind:      "ReplicaSet",
 		name:      "SomeReplicaSet",
 	}
@@ -58,24 +58,49 @@ func (op *fakeOwnerCache) Start() {}
 // Stop
 func (op *fakeOwnerCache) Stop() {}
 
+// HasSynced
+func (op *fakeOwnerCache) HasSynced() bool {
+	return true
+}
+
 // GetServices fetches list of services for a given pod
 func (op *fakeOwnerCache) GetServices(pod *api_v1.Pod) []string {
 	return []string{"foo", "bar"}
 }
 
+// GetServicesByIP fetches list of services for a given address
+func (op *fakeOwnerCache) GetServicesByIP(ip string) []string {
+	return []string{"foo", "bar"}
+}
+
 // GetNamespace returns a namespace
 func (op *fakeOwnerCache) GetNamespace(pod *api_v1.Pod) *api_v1.Namespace {
 	namespace := api_v1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   pod.Namespace,
-			Labels: map[string]string{"label": "namespace_label_value"},
+			Name:        pod.Namespace,
+			Labels:      map[string]string{"label": "namespace_label_value"},
+			Annotations: map[string]string{"annotation": "namespace_annotation_value"},
 		},
 	}
 	return &namespace
 }
 
+// GetNode returns a node
+func (op *fakeOwnerCache) GetNode(pod *api_v1.Pod) *api_v1.Node {
+	node := api_v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   pod.Spec.NodeName,
+			Labels: map[string]string{"topology.kubernetes.io/zone": "us-east-1a"},
+		},
+		Status: api_v1.NodeStatus{
+			NodeInfo: api_v1.NodeSystemInfo{KubeletVersion: "v1.21.2"},
+		},
+	}
+	return &node
+}
+
 // GetOwners fetches deep tree of owners for a given pod
-func (op *fakeOwnerCache) GetOwners(pod *api_v1.Pod) []*ObjectOwner {
+func (op *fakeOwnerCache) GetOwners(pod *api_v1.Pod, maxDepth int) []*ObjectOwner {
 	objectOwners := []*ObjectOwner{}
 
 	// Make sure the tree is cached/traversed first
@@ -24,14 +24,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/tools/cache"
-
-	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 )
 
 func Test_newSharedInformer(t *testing.T) {
 	labelSelector, fieldSelector, err := selectorsFromFilters(Filters{})
 	require.NoError(t, err)
-	client, err := newFakeAPIClientset(k8sconfig.APIConfig{})
+	client, err := newFakeAPIClientset(APIConfig{})
 	require.NoError(t, err)
 	informer := newSharedInformer(client, "testns", labelSelector, fieldSelector)
 	assert.NotNil(t, informer)
@@ -55,7 +53,7 @@ func Test_informerListFuncWithSelectors(t *testing.T) {
 		},
 	})
 	assert.NoError(t, err)
-	c, err := newFakeAPIClientset(k8sconfig.APIConfig{})
+	c, err := newFakeAPIClientset(APIConfig{})
 	assert.NoError(t, err)
 	listFunc := informerListFuncWithSelectors(c, "test-ns", ls, fs)
 	opts := metav1.ListOptions{}
@@ -82,7 +80,7 @@ func Test_informerWatchFuncWithSelectors(t *testing.T) {
 		},
 	})
 	assert.NoError(t, err)
-	c, err := newFakeAPIClientset(k8sconfig.APIConfig{})
+	c, err := newFakeAPIClientset(APIConfig{})
 	assert.NoError(t, err)
 	watchFunc := informerWatchFuncWithSelectors(c, "test-ns", ls, fs)
 	opts := metav1.ListOptions{}
@@ -93,7 +91,7 @@ func Test_informerWatchFuncWithSelectors(t *testing.T) {
 
 func Test_fakeInformer(t *testing.T) {
 	// nothing real to test here. just to make coverage happy
-	c, err := newFakeAPIClientset(k8sconfig.APIConfig{})
+	c, err := newFakeAPIClientset(APIConfig{})
 	assert.NoError(t, err)
 	i := NewFakeInformer(c, "ns", nil, nil)
 	i.AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{}, time.Second)
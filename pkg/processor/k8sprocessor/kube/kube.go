@@ -24,8 +24,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/kubernetes"
-
-	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 )
 
 const (
@@ -34,11 +32,16 @@ const (
 
 	defaultTagContainerID     = "k8s.container.id"
 	defaultTagContainerImage  = "k8s.container.image"
+	defaultTagImageDigest     = "k8s.container.image.digest"
+	defaultTagImageName       = "k8s.container.image.name"
+	defaultTagImageTag        = "k8s.container.image.tag"
 	defaultTagContainerName   = "k8s.container.name"
 	defaultTagDaemonSetName   = "k8s.daemonset.name"
 	defaultTagHostName        = "k8s.pod.hostname"
+	defaultTagKubeletVersion  = "k8s.node.kubelet_version"
 	defaultTagNodeName        = "k8s.node.name"
 	defaultTagPodUID          = "k8s.pod.id"
+	defaultTagPVCName         = "k8s.pvc.name"
 	defaultTagReplicaSetName  = "k8s.replicaset.name"
 	defaultTagServiceName     = "k8s.service.name"
 	defaultTagStatefulSetName = "k8s.statefulset.name"
@@ -64,16 +67,18 @@ var (
 // Client defines the main interface that allows querying pods by metadata.
 type Client interface {
 	GetPod(PodIdentifier) (*Pod, bool)
+	GetServicesByIP(PodIdentifier) []string
+	HasSynced() bool
 	Start()
 	Stop()
 }
 
 // ClientProvider defines a func type that returns a new Client.
-type ClientProvider func(*zap.Logger, k8sconfig.APIConfig, ExtractionRules, Filters, []Association, APIClientsetProvider, InformerProvider, OwnerProvider) (Client, error)
+type ClientProvider func(*zap.Logger, APIConfig, ExtractionRules, Filters, []Association, APIClientsetProvider, InformerProvider, OwnerProvider) (Client, error)
 
 // APIClientsetProvider defines a func type that initializes and return a new kubernetes
 // Clientset object.
-type APIClientsetProvider func(config k8sconfig.APIConfig) (kubernetes.Interface, error)
+type APIClientsetProvider func(config APIConfig) (kubernetes.Interface, error)
 
 // Pod represents a kubernetes pod.
 type Pod struct {
@@ -81,6 +86,8 @@ type Pod struct {
 	Address    string
 	PodUID     string
 	Attributes map[string]string
+	// Containers holds the attributes specific to each of the containers, keyed by their names
+	Containers map[string]map[string]string
 	StartTime  *metav1.Time
 	Ignore     bool
 
@@ -105,6 +112,16 @@ type Filters struct {
 	Fields          []FieldFilter
 	Labels          []FieldFilter
 	NamespaceLabels []FieldFilter
+	Exclude         Excludes
+}
+
+// Excludes represent the pods which are watched, but for which no metadata is extracted
+// and cached. A pod is excluded when it matches any of the namespace or pod name patterns,
+// or all of the label filters.
+type Excludes struct {
+	Namespaces []*regexp.Regexp
+	Pods       []*regexp.Regexp
+	Labels     []FieldFilter
 }
 
 // FieldFilter represents exactly one filter by field rule.
@@ -124,14 +141,20 @@ type FieldFilter struct {
 // from pods and added to the spans as tags.
 type ExtractionRules struct {
 	ClusterName     bool
+	CronJobName     bool
 	ContainerID     bool
 	ContainerImage  bool
+	ImageDigest     bool
+	ImageName       bool
+	ImageTag        bool
 	ContainerName   bool
 	DaemonSetName   bool
 	DeploymentName  bool
 	HostName        bool
+	KubeletVersion  bool
 	PodUID          bool
 	PodName         bool
+	PVCName         bool
 	ReplicaSetName  bool
 	ServiceName     bool
 	StatefulSetName bool
@@ -140,24 +163,44 @@ type ExtractionRules struct {
 	NodeName        bool
 
 	OwnerLookupEnabled bool
+	// OwnerLookupMaxDepth limits the number of owner references followed from the pod; there is no limit when it is zero
+	OwnerLookupMaxDepth int
+	// CustomOwnerKinds maps the kinds of the owners other than the built-in workloads, e.g. Argo Rollouts,
+	// to the tags their names are put in
+	CustomOwnerKinds map[string]string
+	// WatchedResources are the resources watched besides the pods when OwnerLookupEnabled is set,
+	// see AllResources; all of them are watched when it is nil
+	WatchedResources []string
+	// ResyncPeriod is the period of re-extracting the metadata of all the pods, e.g. to refresh the owner data;
+	// the default one is used when it is zero
+	ResyncPeriod time.Duration
 
-	Tags            ExtractionFieldTags
-	Annotations     []FieldExtractionRule
-	Labels          []FieldExtractionRule
-	NamespaceLabels []FieldExtractionRule
+	Tags                 ExtractionFieldTags
+	Annotations          []FieldExtractionRule
+	Labels               []FieldExtractionRule
+	NamespaceLabels      []FieldExtractionRule
+	NamespaceAnnotations []FieldExtractionRule
+	NodeLabels           []FieldExtractionRule
+	WorkloadAnnotations  []FieldExtractionRule
 }
 
 // ExtractionFieldTags is used to describe selected exported key names for the extracted data
 type ExtractionFieldTags struct {
 	ClusterName     string
+	CronJobName     string
 	ContainerID     string
 	ContainerImage  string
+	ImageDigest     string
+	ImageName       string
+	ImageTag        string
 	ContainerName   string
 	DaemonSetName   string
 	DeploymentName  string
 	HostName        string
+	KubeletVersion  string
 	PodUID          string
 	PodName         string
+	PVCName         string
 	Namespace       string
 	NodeName        string
 	ReplicaSetName  string
@@ -170,14 +213,20 @@ type ExtractionFieldTags struct {
 func NewExtractionFieldTags() ExtractionFieldTags {
 	tags := ExtractionFieldTags{}
 	tags.ClusterName = conventions.AttributeK8SClusterName
+	tags.CronJobName = conventions.AttributeK8SCronJobName
 	tags.ContainerID = defaultTagContainerID
 	tags.ContainerImage = defaultTagContainerImage
+	tags.ImageDigest = defaultTagImageDigest
+	tags.ImageName = defaultTagImageName
+	tags.ImageTag = defaultTagImageTag
 	tags.ContainerName = defaultTagContainerName
 	tags.DaemonSetName = defaultTagDaemonSetName
 	tags.DeploymentName = conventions.AttributeK8SDeploymentName
 	tags.HostName = defaultTagHostName
+	tags.KubeletVersion = defaultTagKubeletVersion
 	tags.PodUID = defaultTagPodUID
 	tags.PodName = conventions.AttributeK8SPodName
+	tags.PVCName = defaultTagPVCName
 	tags.Namespace = conventions.AttributeK8SNamespaceName
 	tags.NodeName = defaultTagNodeName
 	tags.ReplicaSetName = defaultTagReplicaSetName
@@ -194,6 +243,10 @@ type FieldExtractionRule struct {
 	Name string
 	// Key is used to lookup k8s pod fields.
 	Key string
+	// KeyRegex is used to lookup all the k8s pod fields matching it, instead of Key.
+	// Name can refer to its capturing groups (e.g. ${team}), otherwise its only capturing group,
+	// if any, is substituted in Name instead of the whole field name.
+	KeyRegex *regexp.Regexp
 	// Regex is a regular expression used to extract a sub-part of a field value.
 	// Full value is extracted when no regexp is provided.
 	Regex *regexp.Regexp
@@ -208,4 +261,7 @@ type Associations struct {
 type Association struct {
 	From string
 	Name string
+	// Port is the name of the attribute holding the port, which is used together with the IP address
+	// to tell apart the pods in the host network
+	Port string
 }
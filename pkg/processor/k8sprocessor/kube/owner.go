@@ -15,7 +15,7 @@
 package kube
 
 import (
-	"sort"
+	"context"
 	"sync"
 
 	"go.uber.org/zap"
@@ -38,22 +38,39 @@ type OwnerProvider func(
 	labelSelector labels.Selector,
 	fieldSelector fields.Selector,
 	namespace string,
+	resources []string,
 ) (OwnerAPI, error)
 
 // ObjectOwner keeps single entry
 type ObjectOwner struct {
 	UID       types.UID
-	ownerUIDs []types.UID
+	ownerRefs []meta_v1.OwnerReference
 	namespace string
 	kind      string
 	name      string
+	// annotations are only kept for the workloads, see workloadKinds
+	annotations map[string]string
 }
 
+// workloadKinds are the kinds of the owners whose annotations are cached
+var workloadKinds = map[string]bool{
+	"CronJob":     true,
+	"DaemonSet":   true,
+	"Deployment":  true,
+	"StatefulSet": true,
+}
+
+// lastAppliedConfigAnnotation holds the whole object, so it is not cached
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
 // OwnerAPI describes functions that could allow retrieving owner info
 type OwnerAPI interface {
-	GetOwners(pod *api_v1.Pod) []*ObjectOwner
+	GetOwners(pod *api_v1.Pod, maxDepth int) []*ObjectOwner
 	GetNamespace(pod *api_v1.Pod) *api_v1.Namespace
+	GetNode(pod *api_v1.Pod) *api_v1.Node
 	GetServices(pod *api_v1.Pod) []string
+	GetServicesByIP(ip string) []string
+	HasSynced() bool
 	Start()
 	Stop()
 }
@@ -61,13 +78,19 @@ type OwnerAPI interface {
 // OwnerCache is a simple structure which aids querying for owners
 type OwnerCache struct {
 	objectOwners map[string]*ObjectOwner
-	podServices  map[string][]string
+	podServices  map[string]*serviceSet
+	ipServices   map[string]*serviceSet
 	namespaces   map[string]*api_v1.Namespace
+	nodes        map[string]*api_v1.Node
 	cacheMutex   sync.RWMutex
 
 	client kubernetes.Interface
 	logger *zap.Logger
 
+	// watched are the resources to watch, forbidden those which could not be watched due to missing permissions
+	watched   map[string]bool
+	forbidden []string
+
 	stopCh    chan struct{}
 	informers []cache.SharedIndexInformer
 }
@@ -85,20 +108,34 @@ func (op *OwnerCache) Stop() {
 	close(op.stopCh)
 }
 
+// HasSynced checks if all the informers have synced
+func (op *OwnerCache) HasSynced() bool {
+	for _, informer := range op.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
 func newOwnerProvider(
 	logger *zap.Logger,
 	client kubernetes.Interface,
 	labelSelector labels.Selector,
 	fieldSelector fields.Selector,
-	namespace string) (OwnerAPI, error) {
+	namespace string,
+	resources []string) (OwnerAPI, error) {
 	ownerCache := OwnerCache{}
 	ownerCache.objectOwners = map[string]*ObjectOwner{}
-	ownerCache.podServices = map[string][]string{}
+	ownerCache.podServices = map[string]*serviceSet{}
+	ownerCache.ipServices = map[string]*serviceSet{}
 	ownerCache.namespaces = map[string]*api_v1.Namespace{}
+	ownerCache.nodes = map[string]*api_v1.Node{}
 	ownerCache.cacheMutex = sync.RWMutex{}
 
 	ownerCache.client = client
 	ownerCache.logger = logger
+	ownerCache.watched = resourceSet(resources)
 
 	factory := informers.NewSharedInformerFactoryWithOptions(client, watchSyncPeriod,
 		informers.WithNamespace(namespace),
@@ -107,27 +144,86 @@ func newOwnerProvider(
 			opts.FieldSelector = fieldSelector.String()
 		}))
 
-	ownerCache.addNamespaceInformer(factory)
+	if ownerCache.canWatch(ResourceNamespaces, func(ctx context.Context, opts meta_v1.ListOptions) error {
+		_, err := client.CoreV1().Namespaces().List(ctx, opts)
+		return err
+	}) {
+		ownerCache.addNamespaceInformer(factory)
+	}
+	if ownerCache.canWatch(ResourceNodes, func(ctx context.Context, opts meta_v1.ListOptions) error {
+		_, err := client.CoreV1().Nodes().List(ctx, opts)
+		return err
+	}) {
+		// The nodes are not selected using the pod selectors, e.g. spec.nodeName
+		ownerCache.addNodeInformer(informers.NewSharedInformerFactory(client, watchSyncPeriod))
+	}
+
+	if ownerCache.canWatch(ResourceReplicaSets, func(ctx context.Context, opts meta_v1.ListOptions) error {
+		_, err := client.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+		return err
+	}) {
+		ownerCache.addOwnerInformer("ReplicaSet",
+			factory.Apps().V1().ReplicaSets().Informer(),
+			ownerCache.cacheObject,
+			ownerCache.deleteObject)
+	}
+
+	if ownerCache.canWatch(ResourceDeployments, func(ctx context.Context, opts meta_v1.ListOptions) error {
+		_, err := client.AppsV1().Deployments(namespace).List(ctx, opts)
+		return err
+	}) {
+		ownerCache.addOwnerInformer("Deployment",
+			factory.Apps().V1().Deployments().Informer(),
+			ownerCache.cacheObject,
+			ownerCache.deleteObject)
+	}
+
+	if ownerCache.canWatch(ResourceStatefulSets, func(ctx context.Context, opts meta_v1.ListOptions) error {
+		_, err := client.AppsV1().StatefulSets(namespace).List(ctx, opts)
+		return err
+	}) {
+		ownerCache.addOwnerInformer("StatefulSet",
+			factory.Apps().V1().StatefulSets().Informer(),
+			ownerCache.cacheObject,
+			ownerCache.deleteObject)
+	}
 
-	ownerCache.addOwnerInformer("ReplicaSet",
-		factory.Apps().V1().ReplicaSets().Informer(),
-		ownerCache.cacheObject,
-		ownerCache.deleteObject)
+	if ownerCache.canWatch(ResourceDaemonSets, func(ctx context.Context, opts meta_v1.ListOptions) error {
+		_, err := client.AppsV1().DaemonSets(namespace).List(ctx, opts)
+		return err
+	}) {
+		ownerCache.addOwnerInformer("DaemonSet",
+			factory.Apps().V1().DaemonSets().Informer(),
+			ownerCache.cacheObject,
+			ownerCache.deleteObject)
+	}
+
+	if ownerCache.canWatch(ResourceJobs, func(ctx context.Context, opts meta_v1.ListOptions) error {
+		_, err := client.BatchV1().Jobs(namespace).List(ctx, opts)
+		return err
+	}) {
+		ownerCache.addOwnerInformer("Job",
+			factory.Batch().V1().Jobs().Informer(),
+			ownerCache.cacheObject,
+			ownerCache.deleteObject)
+	}
 
-	ownerCache.addOwnerInformer("Deployment",
-		factory.Apps().V1().Deployments().Informer(),
-		ownerCache.cacheObject,
-		ownerCache.deleteObject)
+	// batch/v1beta1 is used, since batch/v1 CronJobs are not served before Kubernetes 1.21
+	if ownerCache.canWatch(ResourceCronJobs, func(ctx context.Context, opts meta_v1.ListOptions) error {
+		_, err := client.BatchV1beta1().CronJobs(namespace).List(ctx, opts)
+		return err
+	}) {
+		ownerCache.addOwnerInformer("CronJob",
+			factory.Batch().V1beta1().CronJobs().Informer(),
+			ownerCache.cacheObject,
+			ownerCache.deleteObject)
+	}
 
-	ownerCache.addOwnerInformer("StatefulSet",
-		factory.Apps().V1().StatefulSets().Informer(),
-		ownerCache.cacheObject,
-		ownerCache.deleteObject)
+	ownerCache.addServiceInformer(factory, namespace)
 
-	ownerCache.addOwnerInformer("Endpoint",
-		factory.Core().V1().Endpoints().Informer(),
-		ownerCache.cacheEndpoint,
-		ownerCache.deleteEndpoint)
+	ownerCache.logger.Info("K8S resources watched",
+		zap.Strings("resources", ownerCache.watchedResources()),
+		zap.Strings("forbidden", ownerCache.forbidden))
 
 	return &ownerCache, nil
 }
@@ -166,6 +262,56 @@ func (op *OwnerCache) addNamespaceInformer(factory informers.SharedInformerFacto
 	op.informers = append(op.informers, informer)
 }
 
+func (op *OwnerCache) upsertNode(obj interface{}) {
+	node := obj.(*api_v1.Node)
+	// Only the metadata used for the extraction is kept, e.g. the images are skipped
+	trimmed := &api_v1.Node{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:   node.Name,
+			Labels: node.Labels,
+		},
+		Status: api_v1.NodeStatus{
+			NodeInfo: node.Status.NodeInfo,
+		},
+	}
+	op.cacheMutex.Lock()
+	defer op.cacheMutex.Unlock()
+	op.nodes[node.Name] = trimmed
+}
+
+func (op *OwnerCache) deleteNode(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	node, ok := obj.(*api_v1.Node)
+	if !ok {
+		return
+	}
+	op.cacheMutex.Lock()
+	defer op.cacheMutex.Unlock()
+	delete(op.nodes, node.Name)
+}
+
+func (op *OwnerCache) addNodeInformer(factory informers.SharedInformerFactory) {
+	informer := factory.Core().V1().Nodes().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			observability.RecordOtherAdded()
+			op.upsertNode(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			observability.RecordOtherUpdated()
+			op.upsertNode(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			observability.RecordOtherDeleted()
+			op.deleteNode(obj)
+		},
+	})
+
+	op.informers = append(op.informers, informer)
+}
+
 func (op *OwnerCache) addOwnerInformer(
 	kind string,
 	informer cache.SharedIndexInformer,
@@ -201,12 +347,17 @@ func (op *OwnerCache) cacheObject(kind string, obj interface{}) {
 	oo := ObjectOwner{
 		UID:       meta.GetUID(),
 		namespace: meta.GetNamespace(),
-		ownerUIDs: []types.UID{},
+		ownerRefs: meta.GetOwnerReferences(),
 		kind:      kind,
 		name:      meta.GetName(),
 	}
-	for _, or := range meta.GetOwnerReferences() {
-		oo.ownerUIDs = append(oo.ownerUIDs, or.UID)
+	if workloadKinds[kind] && len(meta.GetAnnotations()) > 0 {
+		oo.annotations = map[string]string{}
+		for k, v := range meta.GetAnnotations() {
+			if k != lastAppliedConfigAnnotation {
+				oo.annotations[k] = v
+			}
+		}
 	}
 
 	op.cacheMutex.Lock()
@@ -214,66 +365,127 @@ func (op *OwnerCache) cacheObject(kind string, obj interface{}) {
 	op.objectOwners[string(oo.UID)] = &oo
 }
 
-func (op *OwnerCache) addEndpointToPod(pod string, endpoint string) {
-	op.cacheMutex.RLock()
-	services := op.podServices[pod]
-	op.cacheMutex.RUnlock()
+func (op *OwnerCache) addServiceEndpointsInformer(informer cache.SharedIndexInformer, f serviceEndpointsFunc) {
+	f = withTombstone(f)
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			observability.RecordOtherAdded()
+			op.cacheServiceEndpoints(f(obj))
+		},
+		UpdateFunc: func(oldObj, obj interface{}) {
+			observability.RecordOtherUpdated()
+			// The addresses which are gone might be reused by other pods
+			op.deleteServiceEndpoints(f(oldObj))
+			op.cacheServiceEndpoints(f(obj))
+		},
+		DeleteFunc: func(obj interface{}) {
+			observability.RecordOtherDeleted()
+			op.deleteServiceEndpoints(f(obj))
+		},
+	})
+
+	op.informers = append(op.informers, informer)
+}
 
-	for _, it := range services {
-		if it == endpoint {
-			return
+// addServiceInformer watches EndpointSlices if they are served, since the Endpoints are truncated
+// for services with more than 1000 addresses, and falls back to the Endpoints otherwise
+func (op *OwnerCache) addServiceInformer(factory informers.SharedInformerFactory, namespace string) {
+	switch {
+	case isResourceServed(op.client, "discovery.k8s.io/v1", "endpointslices"):
+		if op.canWatch(ResourceServices, func(ctx context.Context, opts meta_v1.ListOptions) error {
+			_, err := op.client.DiscoveryV1().EndpointSlices(namespace).List(ctx, opts)
+			return err
+		}) {
+			op.addServiceEndpointsInformer(factory.Discovery().V1().EndpointSlices().Informer(), fromEndpointSliceV1)
+		}
+	case isResourceServed(op.client, "discovery.k8s.io/v1beta1", "endpointslices"):
+		if op.canWatch(ResourceServices, func(ctx context.Context, opts meta_v1.ListOptions) error {
+			_, err := op.client.DiscoveryV1beta1().EndpointSlices(namespace).List(ctx, opts)
+			return err
+		}) {
+			op.addServiceEndpointsInformer(factory.Discovery().V1beta1().EndpointSlices().Informer(), fromEndpointSliceV1beta1)
+		}
+	default:
+		if op.canWatch(ResourceServices, func(ctx context.Context, opts meta_v1.ListOptions) error {
+			_, err := op.client.CoreV1().Endpoints(namespace).List(ctx, opts)
+			return err
+		}) {
+			op.addServiceEndpointsInformer(factory.Core().V1().Endpoints().Informer(), fromEndpoints)
 		}
 	}
+}
 
-	services = append(services, endpoint)
-	sort.Strings(services)
-
-	op.cacheMutex.Lock()
-	defer op.cacheMutex.Unlock()
-	op.podServices[pod] = services
+// canWatch checks whether the resource is to be watched and whether it can be listed. The forbidden
+// resources are not watched, so that the other ones still sync, and the metadata depending on them
+// is not extracted.
+func (op *OwnerCache) canWatch(resource string, list listFunc) bool {
+	if !op.watched[resource] {
+		return false
+	}
+	if forbidden, err := isListForbidden(list); forbidden {
+		op.logger.Warn("Missing permissions to watch K8S resource, the metadata depending on it is not extracted",
+			zap.String("resource", resource), zap.Error(err))
+		op.forbidden = append(op.forbidden, resource)
+		delete(op.watched, resource)
+		return false
+	}
+	return true
 }
 
-func (op *OwnerCache) deleteEndpointFromPod(pod string, endpoint string) {
-	op.cacheMutex.RLock()
-	services := op.podServices[pod]
-	op.cacheMutex.RUnlock()
+// watchedResources lists the resources which are watched
+func (op *OwnerCache) watchedResources() []string {
+	var resources []string
+	for _, resource := range AllResources {
+		if op.watched[resource] {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
 
-	newServices := []string{}
+func (op *OwnerCache) cacheServiceEndpoints(se serviceEndpoints, ok bool) {
+	if !ok {
+		return
+	}
 
-	for _, it := range services {
-		if it != endpoint {
-			newServices = append(newServices, it)
+	add := func(services map[string]*serviceSet, key string) {
+		set, found := services[key]
+		if !found {
+			set = &serviceSet{counts: map[string]int{}}
+			services[key] = set
 		}
+		set.add(se.service)
 	}
 
 	op.cacheMutex.Lock()
 	defer op.cacheMutex.Unlock()
-	op.podServices[pod] = newServices
+	for _, pod := range se.pods {
+		add(op.podServices, podKey(se.namespace, pod))
+	}
+	for _, ip := range se.ips {
+		add(op.ipServices, ip)
+	}
 }
 
-func (op *OwnerCache) genericEndpointOp(obj interface{}, endpointFunc func(pod string, endpoint string)) {
-	ep := obj.(*api_v1.Endpoints)
+func (op *OwnerCache) deleteServiceEndpoints(se serviceEndpoints, ok bool) {
+	if !ok {
+		return
+	}
 
-	for _, it := range ep.Subsets {
-		for _, addr := range it.Addresses {
-			if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
-				endpointFunc(addr.TargetRef.Name, ep.Name)
-			}
-		}
-		for _, addr := range it.NotReadyAddresses {
-			if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
-				endpointFunc(addr.TargetRef.Name, ep.Name)
-			}
+	remove := func(services map[string]*serviceSet, key string) {
+		if set, found := services[key]; found && set.remove(se.service) {
+			delete(services, key)
 		}
 	}
-}
 
-func (op *OwnerCache) deleteEndpoint(obj interface{}) {
-	op.genericEndpointOp(obj, op.deleteEndpointFromPod)
-}
-
-func (op *OwnerCache) cacheEndpoint(kind string, obj interface{}) {
-	op.genericEndpointOp(obj, op.addEndpointToPod)
+	op.cacheMutex.Lock()
+	defer op.cacheMutex.Unlock()
+	for _, pod := range se.pods {
+		remove(op.podServices, podKey(se.namespace, pod))
+	}
+	for _, ip := range se.ips {
+		remove(op.ipServices, ip)
+	}
 }
 
 // GetNamespaces returns a cached namespace object (if one is found) or nil otherwise
@@ -285,49 +497,71 @@ func (op *OwnerCache) GetNamespace(pod *api_v1.Pod) *api_v1.Namespace {
 	return nil
 }
 
+// GetNode returns a cached node object of the node the pod is scheduled to (if one is found) or nil otherwise
+func (op *OwnerCache) GetNode(pod *api_v1.Pod) *api_v1.Node {
+	op.cacheMutex.RLock()
+	defer op.cacheMutex.RUnlock()
+	return op.nodes[pod.Spec.NodeName]
+}
+
 // GetServices returns a slice with matched services - in case no services are found, it returns an empty slice
 func (op *OwnerCache) GetServices(pod *api_v1.Pod) []string {
 	op.cacheMutex.RLock()
-	oo, found := op.podServices[pod.Name]
+	set, found := op.podServices[podKey(pod.Namespace, pod.Name)]
 	op.cacheMutex.RUnlock()
 
 	if found {
-		return oo
+		return set.names
 	}
 	return []string{}
 }
 
-// GetOwners goes through the cached data and assigns relevant metadata for pod
-func (op *OwnerCache) GetOwners(pod *api_v1.Pod) []*ObjectOwner {
-	objectOwners := []*ObjectOwner{}
-
-	visited := map[types.UID]bool{}
-	queue := []types.UID{}
+// GetServicesByIP returns the services having the address among their endpoints - in case no services
+// are found, it returns an empty slice
+func (op *OwnerCache) GetServicesByIP(ip string) []string {
+	op.cacheMutex.RLock()
+	set, found := op.ipServices[ip]
+	op.cacheMutex.RUnlock()
 
-	for _, or := range pod.OwnerReferences {
-		if _, uidVisited := visited[or.UID]; !uidVisited {
-			queue = append(queue, or.UID)
-			visited[or.UID] = true
-		}
+	if found {
+		return set.names
 	}
+	return []string{}
+}
 
-	for len(queue) > 0 {
-		uid := queue[0]
-		queue = queue[1:]
+// GetOwners goes through the cached data and assigns relevant metadata for pod.
+// The owners which are not cached, e.g. the custom resources, are taken from the owner
+// references of their children, so the lookup does not go past them. The number of followed
+// references is limited by maxDepth, unless it is 0.
+func (op *OwnerCache) GetOwners(pod *api_v1.Pod, maxDepth int) []*ObjectOwner {
+	objectOwners := []*ObjectOwner{}
+	visited := map[types.UID]bool{}
 
-		op.cacheMutex.RLock()
-		oo, found := op.objectOwners[string(uid)]
-		if found {
-			objectOwners = append(objectOwners, oo)
+	op.cacheMutex.RLock()
+	defer op.cacheMutex.RUnlock()
+
+	refs := pod.OwnerReferences
+	for depth := 1; len(refs) > 0 && (maxDepth == 0 || depth <= maxDepth); depth++ {
+		var next []meta_v1.OwnerReference
+		for _, or := range refs {
+			if visited[or.UID] {
+				continue
+			}
+			visited[or.UID] = true
 
-			for _, ownerUID := range oo.ownerUIDs {
-				if _, uidVisited := visited[ownerUID]; !uidVisited {
-					queue = append(queue, ownerUID)
-					visited[ownerUID] = true
-				}
+			if oo, found := op.objectOwners[string(or.UID)]; found {
+				objectOwners = append(objectOwners, oo)
+				next = append(next, oo.ownerRefs...)
+			} else if or.Kind != "" {
+				objectOwners = append(objectOwners, &ObjectOwner{
+					UID:       or.UID,
+					namespace: pod.Namespace,
+					kind:      or.Kind,
+					name:      or.Name,
+				})
 			}
 		}
-		op.cacheMutex.RUnlock()
+		refs = next
 	}
 
 	return objectOwners
@@ -0,0 +1,102 @@
+// Copyright 2021 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	api_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNodeCache(t *testing.T) {
+	op := newTestOwnerCache(t)
+
+	node := &api_v1.Node{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:   "node1",
+			Labels: map[string]string{"topology.kubernetes.io/zone": "us-east-1a"},
+		},
+		Status: api_v1.NodeStatus{
+			NodeInfo: api_v1.NodeSystemInfo{KubeletVersion: "v1.21.2"},
+			Images:   []api_v1.ContainerImage{{Names: []string{"nginx:1.21"}}},
+		},
+	}
+	op.upsertNode(node)
+
+	pod := &api_v1.Pod{Spec: api_v1.PodSpec{NodeName: "node1"}}
+	cached := op.GetNode(pod)
+	if assert.NotNil(t, cached) {
+		assert.Equal(t, node.Labels, cached.Labels)
+		assert.Equal(t, "v1.21.2", cached.Status.NodeInfo.KubeletVersion)
+		assert.Empty(t, cached.Status.Images)
+	}
+	assert.Nil(t, op.GetNode(&api_v1.Pod{Spec: api_v1.PodSpec{NodeName: "node2"}}))
+
+	op.deleteNode(cache.DeletedFinalStateUnknown{Key: "node1", Obj: node})
+	assert.Nil(t, op.GetNode(pod))
+}
+
+func TestGetOwnersDepth(t *testing.T) {
+	op := newTestOwnerCache(t)
+
+	// Knative: Pod -> ReplicaSet -> Deployment -> Revision, the Revision not being cached
+	op.cacheObject("Deployment", &meta_v1.ObjectMeta{
+		Name:      "hello-00001-deployment",
+		Namespace: "ns1",
+		UID:       "deployment-uid",
+		OwnerReferences: []meta_v1.OwnerReference{
+			{Kind: "Revision", Name: "hello-00001", UID: "revision-uid"},
+		},
+	})
+	op.cacheObject("ReplicaSet", &meta_v1.ObjectMeta{
+		Name:      "hello-00001-deployment-5db86d8867",
+		Namespace: "ns1",
+		UID:       "replicaset-uid",
+		OwnerReferences: []meta_v1.OwnerReference{
+			{Kind: "Deployment", Name: "hello-00001-deployment", UID: "deployment-uid"},
+		},
+	})
+	pod := &api_v1.Pod{ObjectMeta: meta_v1.ObjectMeta{
+		Name:      "hello-00001-deployment-5db86d8867-sdqlj",
+		Namespace: "ns1",
+		OwnerReferences: []meta_v1.OwnerReference{
+			{Kind: "ReplicaSet", Name: "hello-00001-deployment-5db86d8867", UID: "replicaset-uid"},
+		},
+	}}
+
+	kinds := func(owners []*ObjectOwner) []string {
+		var result []string
+		for _, owner := range owners {
+			result = append(result, owner.kind+"/"+owner.name)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{
+		"ReplicaSet/hello-00001-deployment-5db86d8867",
+		"Deployment/hello-00001-deployment",
+		"Revision/hello-00001",
+	}, kinds(op.GetOwners(pod, 0)))
+	assert.Equal(t, []string{
+		"ReplicaSet/hello-00001-deployment-5db86d8867",
+		"Deployment/hello-00001-deployment",
+	}, kinds(op.GetOwners(pod, 2)))
+	assert.Equal(t, []string{
+		"ReplicaSet/hello-00001-deployment-5db86d8867",
+	}, kinds(op.GetOwners(pod, 1)))
+}
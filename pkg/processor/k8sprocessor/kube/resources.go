@@ -0,0 +1,90 @@
+// Copyright 2021 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The resources watched besides the pods, which are always watched
+const (
+	ResourceNamespaces   = "namespaces"
+	ResourceNodes        = "nodes"
+	ResourceReplicaSets  = "replicasets"
+	ResourceDeployments  = "deployments"
+	ResourceStatefulSets = "statefulsets"
+	ResourceDaemonSets   = "daemonsets"
+	ResourceJobs         = "jobs"
+	ResourceCronJobs     = "cronjobs"
+	// ResourceServices stands for the EndpointSlices or Endpoints the services are found with
+	ResourceServices = "services"
+)
+
+// AllResources lists all the resources which can be watched besides the pods
+var AllResources = []string{
+	ResourceNamespaces,
+	ResourceNodes,
+	ResourceReplicaSets,
+	ResourceDeployments,
+	ResourceStatefulSets,
+	ResourceDaemonSets,
+	ResourceJobs,
+	ResourceCronJobs,
+	ResourceServices,
+}
+
+// resourcesKey identifies the watched resources in the informer registry
+func resourcesKey(resources []string) string {
+	if resources == nil {
+		return "*"
+	}
+	sorted := append([]string{}, resources...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// resourceSet builds the set of the given resources, or all the resources when they are nil
+func resourceSet(resources []string) map[string]bool {
+	if resources == nil {
+		resources = AllResources
+	}
+	set := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		set[resource] = true
+	}
+	return set
+}
+
+// permissionCheckTimeout limits the time of checking the permissions to list a resource
+const permissionCheckTimeout = 10 * time.Second
+
+// listFunc lists the resource, e.g. client.CoreV1().Nodes().List
+type listFunc func(ctx context.Context, opts meta_v1.ListOptions) error
+
+// isListForbidden checks whether listing the resource is not permitted, in which case its informer
+// would never sync. Other errors, e.g. when the API server is not reachable yet, are left
+// to the informer, which retries them.
+func isListForbidden(list listFunc) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), permissionCheckTimeout)
+	defer cancel()
+	err := list(ctx, meta_v1.ListOptions{Limit: 1})
+	return apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err), err
+}
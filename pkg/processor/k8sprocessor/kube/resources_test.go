@@ -0,0 +1,103 @@
+// Copyright 2021 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newForbiddingClientset returns a clientset which is not permitted to list the given resources
+func newForbiddingClientset(resources ...string) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	for _, resource := range resources {
+		resource := resource
+		client.PrependReactor("list", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: resource}, "", nil)
+		})
+	}
+	return client
+}
+
+func TestOwnerCacheForbiddenResources(t *testing.T) {
+	client := newForbiddingClientset(ResourceNodes, ResourceReplicaSets)
+	op, err := newOwnerProvider(zap.NewNop(), client, labels.Everything(), fields.Everything(), "", nil)
+	require.NoError(t, err)
+
+	ownerCache := op.(*OwnerCache)
+	assert.Equal(t, []string{ResourceNodes, ResourceReplicaSets}, ownerCache.forbidden)
+	assert.Equal(t, []string{
+		ResourceNamespaces,
+		ResourceDeployments,
+		ResourceStatefulSets,
+		ResourceDaemonSets,
+		ResourceJobs,
+		ResourceCronJobs,
+		ResourceServices,
+	}, ownerCache.watchedResources())
+	assert.Len(t, ownerCache.informers, 7)
+}
+
+func TestOwnerCacheWatchedResources(t *testing.T) {
+	op, err := newOwnerProvider(zap.NewNop(), fake.NewSimpleClientset(), labels.Everything(), fields.Everything(), "",
+		[]string{ResourceNamespaces, ResourceReplicaSets})
+	require.NoError(t, err)
+	assert.Equal(t, []string{ResourceNamespaces, ResourceReplicaSets}, op.(*OwnerCache).watchedResources())
+	assert.Len(t, op.(*OwnerCache).informers, 2)
+
+	op, err = newOwnerProvider(zap.NewNop(), fake.NewSimpleClientset(), labels.Everything(), fields.Everything(), "", []string{})
+	require.NoError(t, err)
+	assert.Empty(t, op.(*OwnerCache).informers)
+}
+
+func TestClientPodsForbidden(t *testing.T) {
+	clientset := func(_ APIConfig) (kubernetes.Interface, error) {
+		return newForbiddingClientset("pods"), nil
+	}
+	c, err := New(zap.NewNop(), APIConfig{}, ExtractionRules{OwnerLookupEnabled: true}, Filters{}, []Association{},
+		clientset, NewFakeInformer, newFakeOwnerProvider)
+	require.NoError(t, err)
+
+	wc := c.(*WatchClient)
+	assert.True(t, wc.podsForbidden)
+	assert.Nil(t, wc.op)
+
+	done := make(chan struct{})
+	go func() {
+		c.Start()
+		close(done)
+	}()
+	assert.Eventually(t, c.HasSynced, time.Second, 10*time.Millisecond)
+	c.Stop()
+	<-done
+}
+
+func TestResourcesKey(t *testing.T) {
+	assert.Equal(t, "*", resourcesKey(nil))
+	assert.Equal(t, "", resourcesKey([]string{}))
+	assert.Equal(t, "namespaces,replicasets", resourcesKey([]string{ResourceReplicaSets, ResourceNamespaces}))
+}
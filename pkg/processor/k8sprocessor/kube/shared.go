@@ -0,0 +1,226 @@
+// Copyright 2021 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// defaultRegistry is shared by all the clients created with the default providers, so the processors
+// of different pipelines watching the same pods use the same informers and caches
+var defaultRegistry = newInformerRegistry(nil, nil, nil)
+
+// informerRegistry shares the API clients, informers and owner caches between the clients with
+// the same configuration. The informers are started by the first client and stopped by the last one.
+type informerRegistry struct {
+	mu sync.Mutex
+
+	newClientSet     APIClientsetProvider
+	newInformer      InformerProvider
+	newOwnerProvider OwnerProvider
+
+	clients   map[string]kubernetes.Interface
+	informers map[string]*sharedInformer
+	owners    map[string]*sharedOwnerCache
+}
+
+func newInformerRegistry(
+	newClientSet APIClientsetProvider,
+	newInformer InformerProvider,
+	newOwnerProviderFunc OwnerProvider,
+) *informerRegistry {
+	if newClientSet == nil {
+		newClientSet = MakeClient
+	}
+	if newInformer == nil {
+		newInformer = newSharedInformer
+	}
+	if newOwnerProviderFunc == nil {
+		newOwnerProviderFunc = newOwnerProvider
+	}
+	return &informerRegistry{
+		newClientSet:     newClientSet,
+		newInformer:      newInformer,
+		newOwnerProvider: newOwnerProviderFunc,
+		clients:          map[string]kubernetes.Interface{},
+		informers:        map[string]*sharedInformer{},
+		owners:           map[string]*sharedOwnerCache{},
+	}
+}
+
+func (r *informerRegistry) client(apiCfg APIConfig) (kubernetes.Interface, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if kc, ok := r.clients[apiCfg.key()]; ok {
+		return kc, nil
+	}
+	kc, err := r.newClientSet(apiCfg)
+	if err != nil {
+		return nil, err
+	}
+	r.clients[apiCfg.key()] = kc
+	return kc, nil
+}
+
+// podInformer returns the pod informer for the given selectors, which needs to be released
+// when it is no longer used
+func (r *informerRegistry) podInformer(
+	apiCfg APIConfig,
+	namespace string,
+	labelSelector labels.Selector,
+	fieldSelector fields.Selector,
+	resyncPeriod time.Duration,
+) (*sharedInformer, error) {
+	kc, err := r.client(apiCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The resync period is a part of the key, as the informer resyncs all the handlers with the shortest one
+	key := fmt.Sprintf("%s/%s/%s/%s/%s", apiCfg.key(), namespace, labelSelector, fieldSelector, resyncPeriod)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	si, ok := r.informers[key]
+	if !ok {
+		si = &sharedInformer{informer: r.newInformer(kc, namespace, labelSelector, fieldSelector)}
+		started := false
+		stopCh := make(chan struct{})
+		done := make(chan struct{})
+		si.start = func() {
+			r.mu.Lock()
+			if started {
+				r.mu.Unlock()
+				return
+			}
+			started = true
+			released := si.refs == 0
+			r.mu.Unlock()
+
+			if released {
+				// Stopped before being started, Run returns right away
+				si.informer.Run(stopCh)
+				close(done)
+				return
+			}
+			go func() {
+				defer close(done)
+				si.informer.Run(stopCh)
+			}()
+		}
+		si.release = func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if si.refs--; si.refs == 0 {
+				delete(r.informers, key)
+				close(stopCh)
+				if started {
+					<-done
+				}
+			}
+		}
+		r.informers[key] = si
+	}
+	si.refs++
+	return si, nil
+}
+
+// ownerCache returns the owner cache for the given namespace and resources, which needs to be stopped
+// when it is no longer used
+func (r *informerRegistry) ownerCache(logger *zap.Logger, apiCfg APIConfig, namespace string, resources []string) (OwnerAPI, error) {
+	kc, err := r.client(apiCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", apiCfg.key(), namespace, resourcesKey(resources))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	so, ok := r.owners[key]
+	if !ok {
+		// The pod selectors do not apply to the other resources, e.g. spec.nodeName
+		op, err := r.newOwnerProvider(logger, kc, labels.Everything(), fields.Everything(), namespace, resources)
+		if err != nil {
+			return nil, err
+		}
+		so = &sharedOwnerCache{OwnerAPI: op}
+		started := false
+		so.start = func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if !started {
+				started = true
+				op.Start()
+			}
+		}
+		so.release = func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if so.refs--; so.refs == 0 {
+				delete(r.owners, key)
+				if started {
+					op.Stop()
+				}
+			}
+		}
+		r.owners[key] = so
+	}
+	so.refs++
+	return &ownerCacheRef{sharedOwnerCache: so}, nil
+}
+
+// sharedInformer is a pod informer used by several clients. It is started by the first
+// of them and stopped when the last one releases it.
+type sharedInformer struct {
+	informer cache.SharedInformer
+	refs     int
+	start    func()
+	release  func()
+}
+
+// sharedOwnerCache is an owner cache used by several clients. It is started by the first
+// of them and stopped when the last one releases it.
+type sharedOwnerCache struct {
+	OwnerAPI
+	refs    int
+	start   func()
+	release func()
+}
+
+// ownerCacheRef is the reference to a shared owner cache held by a single client
+type ownerCacheRef struct {
+	*sharedOwnerCache
+	stopOnce sync.Once
+}
+
+// Start starts the owner cache unless it was already started by another client
+func (o *ownerCacheRef) Start() {
+	o.start()
+}
+
+// Stop releases the owner cache, which is stopped when no other client uses it
+func (o *ownerCacheRef) Stop() {
+	o.stopOnce.Do(o.release)
+}
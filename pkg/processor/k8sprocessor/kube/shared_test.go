@@ -0,0 +1,142 @@
+// Copyright 2021 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
+)
+
+func newTestRegistry() *informerRegistry {
+	return newInformerRegistry(newFakeAPIClientset, NewFakeInformer, newFakeOwnerProvider)
+}
+
+func TestRegistrySharesPodInformer(t *testing.T) {
+	r := newTestRegistry()
+	apiCfg := APIConfig{APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeNone}}
+	ls := labels.Everything()
+	fs := fields.OneTermEqualSelector("spec.nodeName", "node-1")
+
+	first, err := r.podInformer(apiCfg, "default", ls, fs, 0)
+	require.NoError(t, err)
+	second, err := r.podInformer(apiCfg, "default", ls, fs, 0)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	other, err := r.podInformer(apiCfg, "kube-system", ls, fs, 0)
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+	resynced, err := r.podInformer(apiCfg, "default", ls, fs, time.Minute)
+	require.NoError(t, err)
+	assert.NotSame(t, first, resynced)
+
+	controller := first.informer.GetController().(*FakeController)
+	first.start()
+	second.start()
+
+	first.release()
+	assert.False(t, controller.HasStopped())
+	assert.Len(t, r.informers, 3)
+
+	second.release()
+	assert.True(t, controller.HasStopped())
+	assert.Len(t, r.informers, 2)
+
+	other.release()
+	resynced.release()
+	assert.Empty(t, r.informers)
+}
+
+func TestRegistryReleaseBeforeStart(t *testing.T) {
+	r := newTestRegistry()
+	si, err := r.podInformer(APIConfig{}, "", labels.Everything(), fields.Everything(), 0)
+	require.NoError(t, err)
+
+	si.release()
+	si.start()
+	assert.True(t, si.informer.GetController().(*FakeController).HasStopped())
+}
+
+type countingOwnerCache struct {
+	OwnerAPI
+	starts int
+	stops  int
+}
+
+func (c *countingOwnerCache) Start() { c.starts++ }
+
+func (c *countingOwnerCache) Stop() { c.stops++ }
+
+func TestRegistrySharesOwnerCache(t *testing.T) {
+	var caches []*countingOwnerCache
+	r := newInformerRegistry(newFakeAPIClientset, NewFakeInformer, func(
+		logger *zap.Logger,
+		client kubernetes.Interface,
+		labelSelector labels.Selector,
+		fieldSelector fields.Selector,
+		namespace string,
+		resources []string,
+	) (OwnerAPI, error) {
+		op, err := newFakeOwnerProvider(logger, client, labelSelector, fieldSelector, namespace, resources)
+		c := &countingOwnerCache{OwnerAPI: op}
+		caches = append(caches, c)
+		return c, err
+	})
+	apiCfg := APIConfig{APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeNone}}
+
+	first, err := r.ownerCache(zap.NewNop(), apiCfg, "default", nil)
+	require.NoError(t, err)
+	second, err := r.ownerCache(zap.NewNop(), apiCfg, "default", nil)
+	require.NoError(t, err)
+	other, err := r.ownerCache(zap.NewNop(), apiCfg, "", nil)
+	require.NoError(t, err)
+	namespacesOnly, err := r.ownerCache(zap.NewNop(), apiCfg, "default", []string{ResourceNamespaces})
+	require.NoError(t, err)
+	require.Len(t, caches, 3)
+
+	first.Start()
+	second.Start()
+	assert.Equal(t, 1, caches[0].starts)
+
+	first.Stop()
+	first.Stop()
+	assert.Equal(t, 0, caches[0].stops)
+	second.Stop()
+	assert.Equal(t, 1, caches[0].stops)
+
+	// Never started, so there is nothing to stop
+	other.Stop()
+	assert.Equal(t, 0, caches[1].stops)
+	namespacesOnly.Stop()
+	assert.Empty(t, r.owners)
+}
+
+func TestRegistrySharesClient(t *testing.T) {
+	r := newTestRegistry()
+	first, err := r.client(APIConfig{APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeNone}})
+	require.NoError(t, err)
+	second, err := r.client(APIConfig{APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeNone}})
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+}
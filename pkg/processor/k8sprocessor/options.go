@@ -19,6 +19,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/selection"
 
@@ -36,13 +37,19 @@ const (
 	metadataContainerName   = "containerName"
 	metadataContainerImage  = "containerImage"
 	metadataClusterName     = "clusterName"
+	metadataCronJobName     = "cronJobName"
 	metadataDaemonSetName   = "daemonSetName"
 	metadataDeploymentName  = "deploymentName"
 	metadataHostName        = "hostName"
+	metadataImageDigest     = "containerImageDigest"
+	metadataImageName       = "containerImageName"
+	metadataImageTag        = "containerImageTag"
+	metadataKubeletVersion  = "kubeletVersion"
 	metadataNamespace       = "namespace"
 	metadataNodeName        = "nodeName"
 	metadataPodID           = "podId"
 	metadataPodName         = "podName"
+	metadataPVCName         = "pvcName"
 	metadataReplicaSetName  = "replicaSetName"
 	metadataServiceName     = "serviceName"
 	metadataStartTime       = "startTime"
@@ -57,11 +64,29 @@ type Option func(*kubernetesprocessor) error
 // It defaults the authentication method to in-cluster auth using service accounts.
 func WithAPIConfig(cfg k8sconfig.APIConfig) Option {
 	return func(p *kubernetesprocessor) error {
-		p.apiConfig = cfg
+		p.apiConfig.APIConfig = cfg
 		return p.apiConfig.Validate()
 	}
 }
 
+// WithKubeConfig sets the kubeconfig file and context used instead of the default ones.
+// It requires the kubeConfig auth type.
+func WithKubeConfig(path string, context string) Option {
+	return func(p *kubernetesprocessor) error {
+		p.apiConfig.KubeConfigPath = path
+		p.apiConfig.Context = context
+		return nil
+	}
+}
+
+// WithClusterName sets the cluster name added to all the data.
+func WithClusterName(name string) Option {
+	return func(p *kubernetesprocessor) error {
+		p.clusterName = name
+		return nil
+	}
+}
+
 // WithPassthrough enables passthrough mode. In passthrough mode, the processor
 // only detects and tags the pod IP and does not invoke any k8s APIs.
 func WithPassthrough() Option {
@@ -79,6 +104,92 @@ func WithOwnerLookupEnabled() Option {
 	}
 }
 
+// WithOwnerLookupMaxDepth limits the number of owner references followed from the pod.
+func WithOwnerLookupMaxDepth(depth int) Option {
+	return func(p *kubernetesprocessor) error {
+		if depth < 0 {
+			return fmt.Errorf("owner_lookup_max_depth must not be negative, got %d", depth)
+		}
+		p.rules.OwnerLookupMaxDepth = depth
+		return nil
+	}
+}
+
+// WithCustomOwnerKinds allows extracting the names of the owners of the given kinds.
+func WithCustomOwnerKinds(kinds ...CustomOwnerKindConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		if len(kinds) == 0 {
+			return nil
+		}
+		tags := map[string]string{}
+		for _, k := range kinds {
+			switch k.Kind {
+			case "":
+				return fmt.Errorf("kind must be set for custom owner kinds")
+			case "CronJob", "DaemonSet", "Deployment", "Job", "ReplicaSet", "StatefulSet":
+				return fmt.Errorf("%s is not a custom owner kind", k.Kind)
+			}
+			if k.TagName == "" {
+				k.TagName = fmt.Sprintf("k8s.%s.name", strings.ToLower(k.Kind))
+			}
+			tags[k.Kind] = k.TagName
+		}
+		p.rules.CustomOwnerKinds = tags
+		return nil
+	}
+}
+
+// WithWatchedResources limits the resources watched besides the pods, which are always watched,
+// e.g. to the namespaces and replicasets.
+func WithWatchedResources(resources ...string) Option {
+	return func(p *kubernetesprocessor) error {
+		watched := []string{}
+		for _, resource := range resources {
+			if resource == "pods" {
+				continue
+			}
+			if !isKnownResource(resource) {
+				return fmt.Errorf("unknown watched resource %q, expected pods or one of: %s",
+					resource, strings.Join(kube.AllResources, ", "))
+			}
+			watched = append(watched, resource)
+		}
+		p.rules.WatchedResources = watched
+		return nil
+	}
+}
+
+func isKnownResource(resource string) bool {
+	for _, r := range kube.AllResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// WithResyncPeriod sets the interval at which the pod informer resyncs its handlers.
+func WithResyncPeriod(period time.Duration) Option {
+	return func(p *kubernetesprocessor) error {
+		if period < 0 {
+			return fmt.Errorf("resync_period must not be negative, got %s", period)
+		}
+		p.rules.ResyncPeriod = period
+		return nil
+	}
+}
+
+// WithWaitForSyncTimeout sets how long the data is delayed after the start until the pods are cached.
+func WithWaitForSyncTimeout(timeout time.Duration) Option {
+	return func(p *kubernetesprocessor) error {
+		if timeout < 0 {
+			return fmt.Errorf("wait_for_sync_timeout must not be negative, got %s", timeout)
+		}
+		p.waitForSyncTimeout = timeout
+		return nil
+	}
+}
+
 // WithExtractMetadata allows specifying options to control extraction of pod metadata.
 // If no fields explicitly provided, all metadata extracted by default.
 func WithExtractMetadata(fields ...string) Option {
@@ -89,9 +200,13 @@ func WithExtractMetadata(fields ...string) Option {
 				metadataContainerID,
 				metadataContainerImage,
 				metadataContainerName,
+				metadataImageName,
+				metadataImageTag,
+				metadataCronJobName,
 				metadataDaemonSetName,
 				metadataDeploymentName,
 				metadataHostName,
+				metadataKubeletVersion,
 				metadataNamespace,
 				metadataNodeName,
 				metadataPodName,
@@ -112,12 +227,22 @@ func WithExtractMetadata(fields ...string) Option {
 				p.rules.ContainerImage = true
 			case metadataContainerName:
 				p.rules.ContainerName = true
+			case metadataCronJobName:
+				p.rules.CronJobName = true
 			case metadataDaemonSetName:
 				p.rules.DaemonSetName = true
 			case metadataDeploymentName:
 				p.rules.DeploymentName = true
 			case metadataHostName:
 				p.rules.HostName = true
+			case metadataImageDigest:
+				p.rules.ImageDigest = true
+			case metadataImageName:
+				p.rules.ImageName = true
+			case metadataImageTag:
+				p.rules.ImageTag = true
+			case metadataKubeletVersion:
+				p.rules.KubeletVersion = true
 			case metadataNamespace:
 				p.rules.Namespace = true
 			case metadataNodeName:
@@ -126,6 +251,8 @@ func WithExtractMetadata(fields ...string) Option {
 				p.rules.PodUID = true
 			case metadataPodName:
 				p.rules.PodName = true
+			case metadataPVCName:
+				p.rules.PVCName = true
 			case metadataReplicaSetName:
 				p.rules.ReplicaSetName = true
 			case metadataServiceName:
@@ -156,12 +283,22 @@ func WithExtractTags(tagsMap map[string]string) Option {
 				tags.ContainerName = tag
 			case strings.ToLower(metadataContainerImage):
 				tags.ContainerImage = tag
+			case strings.ToLower(metadataCronJobName):
+				tags.CronJobName = tag
 			case strings.ToLower(metadataDaemonSetName):
 				tags.DaemonSetName = tag
 			case strings.ToLower(metadataDeploymentName):
 				tags.DeploymentName = tag
 			case strings.ToLower(metadataHostName):
 				tags.HostName = tag
+			case strings.ToLower(metadataImageDigest):
+				tags.ImageDigest = tag
+			case strings.ToLower(metadataImageName):
+				tags.ImageName = tag
+			case strings.ToLower(metadataImageTag):
+				tags.ImageTag = tag
+			case strings.ToLower(metadataKubeletVersion):
+				tags.KubeletVersion = tag
 			case strings.ToLower(metadataNamespace):
 				tags.Namespace = tag
 			case strings.ToLower(metadataNodeName):
@@ -170,6 +307,8 @@ func WithExtractTags(tagsMap map[string]string) Option {
 				tags.PodUID = tag
 			case strings.ToLower(metadataPodName):
 				tags.PodName = tag
+			case strings.ToLower(metadataPVCName):
+				tags.PVCName = tag
 			case strings.ToLower(metadataReplicaSetName):
 				tags.ReplicaSetName = tag
 			case strings.ToLower(metadataServiceName):
@@ -211,6 +350,43 @@ func WithExtractNamespaceLabels(labels ...FieldExtractConfig) Option {
 	}
 }
 
+// WithExtractNamespaceAnnotations allows specifying options to control extraction of namespace annotations.
+func WithExtractNamespaceAnnotations(annotations ...FieldExtractConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		annotations, err := extractFieldRules("namespace_annotations", annotations...)
+		if err != nil {
+			return err
+		}
+		p.rules.NamespaceAnnotations = annotations
+		return nil
+	}
+}
+
+// WithExtractNodeLabels allows specifying options to control extraction of labels of the node the pod runs on.
+func WithExtractNodeLabels(labels ...FieldExtractConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		labels, err := extractFieldRules("node_labels", labels...)
+		if err != nil {
+			return err
+		}
+		p.rules.NodeLabels = labels
+		return nil
+	}
+}
+
+// WithExtractWorkloadAnnotations allows specifying options to control extraction of the annotations
+// of the workloads owning the pod, e.g. Deployments and StatefulSets.
+func WithExtractWorkloadAnnotations(annotations ...FieldExtractConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		annotations, err := extractFieldRules("workload_annotations", annotations...)
+		if err != nil {
+			return err
+		}
+		p.rules.WorkloadAnnotations = annotations
+		return nil
+	}
+}
+
 // WithExtractAnnotations allows specifying options to control extraction of pod annotations tags.
 func WithExtractAnnotations(annotations ...FieldExtractConfig) Option {
 	return func(p *kubernetesprocessor) error {
@@ -228,13 +404,32 @@ func extractFieldRules(fieldType string, fields ...FieldExtractConfig) ([]kube.F
 	for _, a := range fields {
 		name := a.TagName
 		if name == "" {
-			if a.Key == "*" {
+			if a.Key == "*" || a.KeyRegex != "" {
 				name = fmt.Sprintf("k8s.%s.%%s", fieldType)
 			} else {
 				name = fmt.Sprintf("k8s.%s.%s", fieldType, a.Key)
 			}
 		}
 
+		var keyRegex *regexp.Regexp
+		if a.KeyRegex != "" {
+			if a.Key != "" {
+				return rules, fmt.Errorf("only one of key and key_regex can be set")
+			}
+			expand := strings.Contains(name, "$")
+			if !expand && !strings.Contains(name, "%s") {
+				return rules, fmt.Errorf("tag_name must contain %%s or a capturing group reference when key_regex is set")
+			}
+			var err error
+			keyRegex, err = regexp.Compile(a.KeyRegex)
+			if err != nil {
+				return rules, err
+			}
+			if !expand && keyRegex.NumSubexp() > 1 {
+				return rules, fmt.Errorf("key_regex must contain at most one capturing group when tag_name contains %%s")
+			}
+		}
+
 		var r *regexp.Regexp
 		if a.Regex != "" {
 			var err error
@@ -249,7 +444,7 @@ func extractFieldRules(fieldType string, fields ...FieldExtractConfig) ([]kube.F
 		}
 
 		rules = append(rules, kube.FieldExtractionRule{
-			Name: name, Key: a.Key, Regex: r,
+			Name: name, Key: a.Key, KeyRegex: keyRegex, Regex: r,
 		})
 	}
 	return rules, nil
@@ -278,36 +473,80 @@ func WithFilterNamespace(ns string) Option {
 // WithFilterLabels allows specifying options to control filtering pods by pod labels.
 func WithFilterLabels(filters ...FieldFilterConfig) Option {
 	return func(p *kubernetesprocessor) error {
-		labels := []kube.FieldFilter{}
-		for _, f := range filters {
-			if f.Op == "" {
-				f.Op = filterOPEquals
-			}
-
-			var op selection.Operator
-			switch f.Op {
-			case filterOPEquals:
-				op = selection.Equals
-			case filterOPNotEquals:
-				op = selection.NotEquals
-			case filterOPExists:
-				op = selection.Exists
-			case filterOPDoesNotExist:
-				op = selection.DoesNotExist
-			default:
-				return fmt.Errorf("'%s' is not a valid label filter operation for key=%s, value=%s", f.Op, f.Key, f.Value)
-			}
-			labels = append(labels, kube.FieldFilter{
-				Key:   f.Key,
-				Value: f.Value,
-				Op:    op,
-			})
+		labels, err := labelFilters(filters)
+		if err != nil {
+			return err
 		}
 		p.filters.Labels = labels
 		return nil
 	}
 }
 
+// WithExcludes allows specifying the pods for which no metadata is extracted.
+func WithExcludes(exclude ExcludeConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		namespaces, err := compileRegexes(exclude.Namespaces)
+		if err != nil {
+			return fmt.Errorf("invalid exclude namespace pattern: %w", err)
+		}
+		pods, err := compileRegexes(exclude.Pods)
+		if err != nil {
+			return fmt.Errorf("invalid exclude pod pattern: %w", err)
+		}
+		labels, err := labelFilters(exclude.Labels)
+		if err != nil {
+			return err
+		}
+		p.filters.Exclude = kube.Excludes{
+			Namespaces: namespaces,
+			Pods:       pods,
+			Labels:     labels,
+		}
+		return nil
+	}
+}
+
+func compileRegexes(patterns []string) ([]*regexp.Regexp, error) {
+	var regexes []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		regexes = append(regexes, re)
+	}
+	return regexes, nil
+}
+
+func labelFilters(filters []FieldFilterConfig) ([]kube.FieldFilter, error) {
+	labels := []kube.FieldFilter{}
+	for _, f := range filters {
+		if f.Op == "" {
+			f.Op = filterOPEquals
+		}
+
+		var op selection.Operator
+		switch f.Op {
+		case filterOPEquals:
+			op = selection.Equals
+		case filterOPNotEquals:
+			op = selection.NotEquals
+		case filterOPExists:
+			op = selection.Exists
+		case filterOPDoesNotExist:
+			op = selection.DoesNotExist
+		default:
+			return nil, fmt.Errorf("'%s' is not a valid label filter operation for key=%s, value=%s", f.Op, f.Key, f.Value)
+		}
+		labels = append(labels, kube.FieldFilter{
+			Key:   f.Key,
+			Value: f.Value,
+			Op:    op,
+		})
+	}
+	return labels, nil
+}
+
 // WithFilterFields allows specifying options to control filtering pods by pod fields.
 func WithFilterFields(filters ...FieldFilterConfig) Option {
 	return func(p *kubernetesprocessor) error {
@@ -345,6 +584,7 @@ func WithExtractPodAssociations(podAssociations ...PodAssociationConfig) Option
 			associations = append(associations, kube.Association{
 				From: association.From,
 				Name: association.Name,
+				Port: association.Port,
 			})
 		}
 		p.podAssociations = associations
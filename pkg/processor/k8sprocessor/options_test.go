@@ -19,6 +19,7 @@ import (
 	"reflect"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/selection"
@@ -37,7 +38,25 @@ func TestWithAPIConfig(t *testing.T) {
 	apiConfig = k8sconfig.APIConfig{AuthType: "kubeConfig"}
 	err = WithAPIConfig(apiConfig)(p)
 	assert.NoError(t, err)
-	assert.Equal(t, apiConfig, p.apiConfig)
+	assert.Equal(t, apiConfig, p.apiConfig.APIConfig)
+}
+
+func TestWithKubeConfig(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithKubeConfig("/etc/kube/clusters", "prod-eu")(p))
+	assert.Error(t, WithAPIConfig(k8sconfig.APIConfig{AuthType: "serviceAccount"})(p))
+	assert.NoError(t, WithAPIConfig(k8sconfig.APIConfig{AuthType: "kubeConfig"})(p))
+	assert.Equal(t, kube.APIConfig{
+		APIConfig:      k8sconfig.APIConfig{AuthType: "kubeConfig"},
+		KubeConfigPath: "/etc/kube/clusters",
+		Context:        "prod-eu",
+	}, p.apiConfig)
+}
+
+func TestWithClusterName(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithClusterName("prod-eu")(p))
+	assert.Equal(t, "prod-eu", p.clusterName)
 }
 
 func TestWithFilterNamespace(t *testing.T) {
@@ -69,6 +88,58 @@ func TestWithPassthrough(t *testing.T) {
 	assert.True(t, p.passthroughMode)
 }
 
+func TestWithOwnerLookupMaxDepth(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithOwnerLookupMaxDepth(2)(p))
+	assert.Equal(t, 2, p.rules.OwnerLookupMaxDepth)
+	assert.Error(t, WithOwnerLookupMaxDepth(-1)(p))
+}
+
+func TestWithCustomOwnerKinds(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithCustomOwnerKinds()(p))
+	assert.Nil(t, p.rules.CustomOwnerKinds)
+
+	assert.NoError(t, WithCustomOwnerKinds(
+		CustomOwnerKindConfig{Kind: "Rollout"},
+		CustomOwnerKindConfig{Kind: "Revision", TagName: "knative.revision"},
+	)(p))
+	assert.Equal(t, map[string]string{
+		"Rollout":  "k8s.rollout.name",
+		"Revision": "knative.revision",
+	}, p.rules.CustomOwnerKinds)
+
+	assert.Error(t, WithCustomOwnerKinds(CustomOwnerKindConfig{TagName: "tag"})(p))
+	assert.Error(t, WithCustomOwnerKinds(CustomOwnerKindConfig{Kind: "Deployment"})(p))
+}
+
+func TestWithWaitForSyncTimeout(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithWaitForSyncTimeout(10*time.Second)(p))
+	assert.Equal(t, 10*time.Second, p.waitForSyncTimeout)
+	assert.Error(t, WithWaitForSyncTimeout(-time.Second)(p))
+}
+
+func TestWithResyncPeriod(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithResyncPeriod(10*time.Minute)(p))
+	assert.Equal(t, 10*time.Minute, p.rules.ResyncPeriod)
+	assert.Error(t, WithResyncPeriod(-time.Second)(p))
+}
+
+func TestWithWatchedResources(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithWatchedResources("pods", "namespaces", "replicasets")(p))
+	assert.Equal(t, []string{"namespaces", "replicasets"}, p.rules.WatchedResources)
+
+	p = &kubernetesprocessor{}
+	assert.NoError(t, WithWatchedResources("pods")(p))
+	assert.NotNil(t, p.rules.WatchedResources)
+	assert.Empty(t, p.rules.WatchedResources)
+
+	assert.Error(t, WithWatchedResources("secrets")(p))
+}
+
 func TestWithExtractAnnotations(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -174,6 +245,22 @@ func TestWithExtractLabels(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"key-regex",
+			[]FieldExtractConfig{
+				{
+					TagName:  "${domain}_${name}",
+					KeyRegex: `^(?P<domain>\w+)\.acme\.io/(?P<name>.+)$`,
+				},
+			},
+			[]kube.FieldExtractionRule{
+				{
+					Name:     "${domain}_${name}",
+					KeyRegex: regexp.MustCompile(`^(?P<domain>\w+)\.acme\.io/(?P<name>.+)$`),
+				},
+			},
+			"",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -236,6 +323,56 @@ func TestWithExtractNamespaceLabels(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"key-regex",
+			[]FieldExtractConfig{
+				{
+					TagName:  "team_%s",
+					KeyRegex: `^acme\.io/(.+)$`,
+				},
+				{
+					KeyRegex: `^acme\.io/`,
+				},
+			},
+			[]kube.FieldExtractionRule{
+				{
+					Name:     "team_%s",
+					KeyRegex: regexp.MustCompile(`^acme\.io/(.+)$`),
+				},
+				{
+					Name:     "k8s.namespace_labels.%s",
+					KeyRegex: regexp.MustCompile(`^acme\.io/`),
+				},
+			},
+			"",
+		},
+		{
+			"key-and-key-regex",
+			[]FieldExtractConfig{{
+				TagName:  "t1_%s",
+				Key:      "k1",
+				KeyRegex: "k",
+			}},
+			[]kube.FieldExtractionRule{},
+			"only one of key and key_regex can be set",
+		},
+		{
+			"key-regex-without-placeholder",
+			[]FieldExtractConfig{{
+				TagName:  "t1",
+				KeyRegex: "k",
+			}},
+			[]kube.FieldExtractionRule{},
+			"tag_name must contain %s or a capturing group reference when key_regex is set",
+		},
+		{
+			"key-regex-groups",
+			[]FieldExtractConfig{{
+				KeyRegex: "(a)(b)",
+			}},
+			[]kube.FieldExtractionRule{},
+			"key_regex must contain at most one capturing group when tag_name contains %s",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -257,6 +394,38 @@ func TestWithExtractNamespaceLabels(t *testing.T) {
 	}
 }
 
+func TestWithExtractNamespaceAnnotations(t *testing.T) {
+	p := &kubernetesprocessor{}
+	err := WithExtractNamespaceAnnotations(
+		FieldExtractConfig{TagName: "namespace_annotation_%s", Key: "*"},
+		FieldExtractConfig{Key: "team"},
+	)(p)
+	assert.NoError(t, err)
+	assert.Equal(t, []kube.FieldExtractionRule{
+		{Name: "namespace_annotation_%s", Key: "*"},
+		{Name: "k8s.namespace_annotations.team", Key: "team"},
+	}, p.rules.NamespaceAnnotations)
+
+	err = WithExtractNamespaceAnnotations(FieldExtractConfig{Key: "k1", Regex: "["})(p)
+	assert.Error(t, err)
+}
+
+func TestWithExtractNodeLabels(t *testing.T) {
+	p := &kubernetesprocessor{}
+	err := WithExtractNodeLabels(
+		FieldExtractConfig{TagName: "k8s.node.zone", Key: "topology.kubernetes.io/zone"},
+		FieldExtractConfig{Key: "node.kubernetes.io/instance-type"},
+	)(p)
+	assert.NoError(t, err)
+	assert.Equal(t, []kube.FieldExtractionRule{
+		{Name: "k8s.node.zone", Key: "topology.kubernetes.io/zone"},
+		{Name: "k8s.node_labels.node.kubernetes.io/instance-type", Key: "node.kubernetes.io/instance-type"},
+	}, p.rules.NodeLabels)
+
+	err = WithExtractNodeLabels(FieldExtractConfig{Key: "k1", Regex: "["})(p)
+	assert.Error(t, err)
+}
+
 func TestWithExtractMetadata(t *testing.T) {
 	p := &kubernetesprocessor{}
 	assert.NoError(t, WithExtractMetadata()(p))
@@ -267,6 +436,11 @@ func TestWithExtractMetadata(t *testing.T) {
 	assert.True(t, p.rules.DeploymentName)
 	assert.True(t, p.rules.ClusterName)
 	assert.True(t, p.rules.NodeName)
+	assert.True(t, p.rules.KubeletVersion)
+	assert.True(t, p.rules.ImageName)
+	assert.True(t, p.rules.ImageTag)
+	assert.False(t, p.rules.ImageDigest)
+	assert.False(t, p.rules.PVCName)
 
 	p = &kubernetesprocessor{}
 	err := WithExtractMetadata("randomfield")(p)
@@ -281,6 +455,44 @@ func TestWithExtractMetadata(t *testing.T) {
 	assert.False(t, p.rules.StartTime)
 	assert.False(t, p.rules.DeploymentName)
 	assert.False(t, p.rules.NodeName)
+
+	assert.NoError(t, WithExtractMetadata("pvcName", "containerImageDigest")(p))
+	assert.True(t, p.rules.PVCName)
+	assert.True(t, p.rules.ImageDigest)
+}
+
+func TestWithExtractWorkloadAnnotations(t *testing.T) {
+	p := &kubernetesprocessor{}
+	err := WithExtractWorkloadAnnotations(
+		FieldExtractConfig{TagName: "team", Key: "example.com/team"},
+		FieldExtractConfig{Key: "example.com/tier"},
+	)(p)
+	assert.NoError(t, err)
+	assert.Equal(t, []kube.FieldExtractionRule{
+		{Name: "team", Key: "example.com/team"},
+		{Name: "k8s.workload_annotations.example.com/tier", Key: "example.com/tier"},
+	}, p.rules.WorkloadAnnotations)
+
+	err = WithExtractWorkloadAnnotations(FieldExtractConfig{Key: "k1", Regex: "["})(p)
+	assert.Error(t, err)
+}
+
+func TestWithExcludes(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithExcludes(ExcludeConfig{
+		Namespaces: []string{"^kube-"},
+		Pods:       []string{"^cleanup-"},
+		Labels:     []FieldFilterConfig{{Key: "job-name", Op: filterOPExists}},
+	})(p))
+	assert.Equal(t, kube.Excludes{
+		Namespaces: []*regexp.Regexp{regexp.MustCompile("^kube-")},
+		Pods:       []*regexp.Regexp{regexp.MustCompile("^cleanup-")},
+		Labels:     []kube.FieldFilter{{Key: "job-name", Op: selection.Exists}},
+	}, p.filters.Exclude)
+
+	assert.Error(t, WithExcludes(ExcludeConfig{Namespaces: []string{"("}})(p))
+	assert.Error(t, WithExcludes(ExcludeConfig{Pods: []string{"("}})(p))
+	assert.Error(t, WithExcludes(ExcludeConfig{Labels: []FieldFilterConfig{{Key: "a", Op: "in"}}})(p))
 }
 
 func TestWithFilterLabels(t *testing.T) {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 
 	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/model/pdata"
@@ -26,82 +27,101 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
 )
 
-// extractPodIds extracts IP and pod UID from attributes or request context.
-// It returns a value pair containing configured label and IP Address and/or Pod UID.
+// podIdentifier is a candidate identifier of the Pod, along with the attribute it is recorded as
+type podIdentifier struct {
+	key   string
+	value kube.PodIdentifier
+	// port is used together with the IP address to find the pods in the host network
+	port string
+}
+
+// lookupValue returns the identifier the Pod is looked up by
+func (id podIdentifier) lookupValue() kube.PodIdentifier {
+	if id.port == "" {
+		return id.value
+	}
+	return kube.PodIdentifier(net.JoinHostPort(string(id.value), id.port))
+}
+
+// extractPodIDs extracts IP addresses, pod UIDs and `pod_name.namespace_name` values from attributes or request context.
+// It returns the candidates in the order of the configured associations, each containing the configured label
+// and the identifier, so the next ones can be used as fallbacks when no Pod matches the previous ones.
 // If empty value in return it means that attributes does not contains configured label to match resources for Pod.
-func extractPodID(ctx context.Context, attrs pdata.AttributeMap, associations []kube.Association) (podIdentifierKey string, podIdentifierValue kube.PodIdentifier) {
+func extractPodIDs(ctx context.Context, attrs pdata.AttributeMap, associations []kube.Association) []podIdentifier {
 	hostname := stringAttributeFromMap(attrs, conventions.AttributeHostName)
 	var connectionIP kube.PodIdentifier
 	if c, ok := client.FromContext(ctx); ok {
 		connectionIP = kube.PodIdentifier(c.IP)
 	}
+
+	var ids []podIdentifier
+	var port string
+	add := func(key string, value kube.PodIdentifier) {
+		if value == "" {
+			return
+		}
+		if port != "" {
+			// The value alone is the fallback for the pods outside of the host network
+			ids = append(ids, podIdentifier{key: key, value: value, port: port})
+		}
+		ids = append(ids, podIdentifier{key: key, value: value})
+	}
+
 	// If pod association is not set
 	if len(associations) == 0 {
-		var podIP, labelIP kube.PodIdentifier
-		podIP = kube.PodIdentifier(stringAttributeFromMap(attrs, k8sIPLabelName))
-		labelIP = kube.PodIdentifier(stringAttributeFromMap(attrs, clientIPLabelName))
-		podIdentifierKey = k8sIPLabelName
-		if podIP != "" {
-			podIdentifierValue = podIP
-			return
-		} else if labelIP != "" {
-			podIdentifierValue = labelIP
-			return
-		} else if connectionIP != "" {
-			podIdentifierValue = connectionIP
-			return
-		} else if net.ParseIP(hostname) != nil {
-			podIdentifierValue = kube.PodIdentifier(hostname)
-			return
+		add(k8sIPLabelName, kube.PodIdentifier(stringAttributeFromMap(attrs, k8sIPLabelName)))
+		add(k8sIPLabelName, kube.PodIdentifier(stringAttributeFromMap(attrs, clientIPLabelName)))
+		add(k8sIPLabelName, connectionIP)
+		if net.ParseIP(hostname) != nil {
+			add(k8sIPLabelName, kube.PodIdentifier(hostname))
 		}
-		podIdentifierKey = ""
-		return
+		return ids
 	}
 
 	for _, asso := range associations {
+		port = ""
+		if asso.Port != "" {
+			port = portAttributeFromMap(attrs, asso.Port)
+		}
 		switch {
 		// If association configured to take IP address from connection
-		case asso.From == "connection" && connectionIP != "":
-			podIdentifierKey = k8sIPLabelName
-			podIdentifierValue = connectionIP
-			return
+		case asso.From == "connection":
+			add(k8sIPLabelName, connectionIP)
 		case asso.From == "resource_attribute": // If association configured by resource_attribute
 			// In k8s environment, host.name label set to a pod IP address.
 			// If the value doesn't represent an IP address, we skip it.
 			if asso.Name == conventions.AttributeHostName {
 				if net.ParseIP(hostname) != nil {
-					podIdentifierKey = k8sIPLabelName
-					podIdentifierValue = kube.PodIdentifier(hostname)
-					return
+					add(k8sIPLabelName, kube.PodIdentifier(hostname))
 				}
 			} else {
 				// Extract values based on configured resource_attribute.
 				// Value should be a pod ip, pod uid or `pod_name.namespace_name`
-				attributeValue := stringAttributeFromMap(attrs, asso.Name)
-				if attributeValue != "" {
-					podIdentifierKey = asso.Name
-					podIdentifierValue = kube.PodIdentifier(attributeValue)
-					return
-				}
+				add(asso.Name, kube.PodIdentifier(stringAttributeFromMap(attrs, asso.Name)))
 			}
 		case asso.From == "build_hostname":
 			// Build hostname from pod k8s.pod.name and k8s.namespace.name attributes
-			pod, ok := attrs.Get(conventions.AttributeK8SPodName)
-			if !ok {
-				return "", ""
-			}
-
-			namespace, ok := attrs.Get(conventions.AttributeK8SNamespaceName)
-			if !ok {
-				return "", ""
+			pod := stringAttributeFromMap(attrs, conventions.AttributeK8SPodName)
+			namespace := stringAttributeFromMap(attrs, conventions.AttributeK8SNamespaceName)
+			if pod != "" && namespace != "" {
+				add(asso.Name, kube.PodIdentifier(fmt.Sprintf("%s.%s", pod, namespace)))
 			}
+		}
+	}
+	return ids
+}
 
-			if pod.StringVal() == "" || namespace.StringVal() != "" {
-				return asso.Name, kube.PodIdentifier(fmt.Sprintf("%s.%s", pod.StringVal(), namespace.StringVal()))
-			}
+// portAttributeFromMap returns the port held by the attribute as either a string or an integer
+func portAttributeFromMap(attrs pdata.AttributeMap, key string) string {
+	if val, ok := attrs.Get(key); ok {
+		switch val.Type() {
+		case pdata.AttributeValueTypeString:
+			return val.StringVal()
+		case pdata.AttributeValueTypeInt:
+			return strconv.FormatInt(val.IntVal(), 10)
 		}
 	}
-	return "", kube.PodIdentifier("")
+	return ""
 }
 
 func stringAttributeFromMap(attrs pdata.AttributeMap, key string) string {
@@ -16,28 +16,38 @@ package k8sprocessor
 
 import (
 	"context"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/model/pdata"
 	"go.uber.org/zap"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
 )
 
 const (
 	k8sIPLabelName    string = "k8s.pod.ip"
 	clientIPLabelName string = "ip"
+
+	syncPollInterval = 100 * time.Millisecond
 )
 
 type kubernetesprocessor struct {
 	logger          *zap.Logger
-	apiConfig       k8sconfig.APIConfig
+	apiConfig       kube.APIConfig
+	clusterName     string
 	kc              kube.Client
 	passthroughMode bool
 	rules           kube.ExtractionRules
 	filters         kube.Filters
 	podAssociations []kube.Association
+
+	// waitForSyncTimeout is how long the data is delayed after the start until the pods are cached
+	waitForSyncTimeout time.Duration
+	syncDeadline       time.Time
+	synced             int32
 }
 
 func (kp *kubernetesprocessor) initKubeClient(logger *zap.Logger, kubeClient kube.ClientProvider) error {
@@ -56,11 +66,37 @@ func (kp *kubernetesprocessor) initKubeClient(logger *zap.Logger, kubeClient kub
 
 func (kp *kubernetesprocessor) Start(_ context.Context, _ component.Host) error {
 	if !kp.passthroughMode {
+		kp.syncDeadline = time.Now().Add(kp.waitForSyncTimeout)
 		go kp.kc.Start()
 	}
 	return nil
 }
 
+// waitForSync delays the data until the pods are cached or the timeout since the start passes,
+// so the data received right after the start is enriched too
+func (kp *kubernetesprocessor) waitForSync(ctx context.Context) {
+	if kp.passthroughMode || kp.waitForSyncTimeout <= 0 || atomic.LoadInt32(&kp.synced) == 1 {
+		return
+	}
+
+	ticker := time.NewTicker(syncPollInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Until(kp.syncDeadline))
+	defer timer.Stop()
+	for !kp.kc.HasSynced() {
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			kp.logger.Warn("Timed out waiting for the pods to be cached, the data might be not enriched")
+			atomic.StoreInt32(&kp.synced, 1)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+	atomic.StoreInt32(&kp.synced, 1)
+}
+
 func (kp *kubernetesprocessor) Shutdown(context.Context) error {
 	if !kp.passthroughMode {
 		kp.kc.Stop()
@@ -70,6 +106,7 @@ func (kp *kubernetesprocessor) Shutdown(context.Context) error {
 
 // ProcessTraces process traces and add k8s metadata using resource IP or incoming IP as pod origin.
 func (kp *kubernetesprocessor) ProcessTraces(ctx context.Context, td pdata.Traces) (pdata.Traces, error) {
+	kp.waitForSync(ctx)
 	rss := td.ResourceSpans()
 	for i := 0; i < rss.Len(); i++ {
 		kp.processResource(ctx, rss.At(i).Resource())
@@ -80,6 +117,7 @@ func (kp *kubernetesprocessor) ProcessTraces(ctx context.Context, td pdata.Trace
 
 // ProcessMetrics process metrics and add k8s metadata using resource IP, hostname or incoming IP as pod origin.
 func (kp *kubernetesprocessor) ProcessMetrics(ctx context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	kp.waitForSync(ctx)
 	rm := md.ResourceMetrics()
 	for i := 0; i < rm.Len(); i++ {
 		kp.processResource(ctx, rm.At(i).Resource())
@@ -90,6 +128,7 @@ func (kp *kubernetesprocessor) ProcessMetrics(ctx context.Context, md pdata.Metr
 
 // ProcessLogs process logs and add k8s metadata using resource IP, hostname or incoming IP as pod origin.
 func (kp *kubernetesprocessor) ProcessLogs(ctx context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	kp.waitForSync(ctx)
 	rl := ld.ResourceLogs()
 	for i := 0; i < rl.Len(); i++ {
 		kp.processResource(ctx, rl.At(i).Resource())
@@ -98,31 +137,70 @@ func (kp *kubernetesprocessor) ProcessLogs(ctx context.Context, ld pdata.Logs) (
 	return ld, nil
 }
 
-// processResource adds Pod metadata tags to resource based on pod association configuration
+// processResource adds Pod metadata tags to resource based on pod association configuration.
+// The associations are tried in order until one of them matches a Pod.
 func (kp *kubernetesprocessor) processResource(ctx context.Context, resource pdata.Resource) {
+	if kp.clusterName != "" {
+		resource.Attributes().InsertString(kp.rules.Tags.ClusterName, kp.clusterName)
+	}
 
-	podIdentifierKey, podIdentifierValue := extractPodID(ctx, resource.Attributes(), kp.podAssociations)
-	if podIdentifierValue == "" {
+	ids := extractPodIDs(ctx, resource.Attributes(), kp.podAssociations)
+	if len(ids) == 0 {
 		return
 	}
 
-	if podIdentifierKey != "" {
-		resource.Attributes().InsertString(podIdentifierKey, string(podIdentifierValue))
+	// Without a matching Pod, the first identifier is still recorded
+	id := ids[0]
+	var pod *kube.Pod
+	if !kp.passthroughMode {
+		for _, candidate := range ids {
+			if p, ok := kp.kc.GetPod(candidate.lookupValue()); ok {
+				id, pod = candidate, p
+				break
+			}
+		}
+	}
+
+	if id.key != "" {
+		resource.Attributes().InsertString(id.key, string(id.value))
 	}
 
 	if kp.passthroughMode {
 		return
 	}
-	attrsToAdd := kp.getAttributesForPod(podIdentifierValue)
-	for key, val := range attrsToAdd {
+
+	// The services are looked up for each resource, as they change independently of the pods
+	// and are known also for the addresses of pods which are not watched
+	if kp.rules.ServiceName && kp.rules.OwnerLookupEnabled {
+		if services := kp.kc.GetServicesByIP(id.value); len(services) > 0 {
+			resource.Attributes().InsertString(kp.rules.Tags.ServiceName, strings.Join(services, ", "))
+		}
+	}
+
+	if pod == nil {
+		return
+	}
+
+	// The attributes of the container the data comes from replace the ones of the first container of the pod
+	container, ok := pod.Containers[stringAttributeFromMap(resource.Attributes(), kp.rules.Tags.ContainerName)]
+	for key, val := range container {
+		resource.Attributes().InsertString(key, val)
+	}
+
+	for key, val := range pod.Attributes {
+		if ok && kp.isContainerAttribute(key) {
+			continue
+		}
 		resource.Attributes().InsertString(key, val)
 	}
 }
 
-func (kp *kubernetesprocessor) getAttributesForPod(identifier kube.PodIdentifier) map[string]string {
-	pod, ok := kp.kc.GetPod(identifier)
-	if !ok {
-		return nil
+// isContainerAttribute checks if the attribute is specific to a container
+func (kp *kubernetesprocessor) isContainerAttribute(key string) bool {
+	tags := kp.rules.Tags
+	switch key {
+	case tags.ContainerID, tags.ContainerImage, tags.ImageDigest, tags.ImageName, tags.ImageTag:
+		return true
 	}
-	return pod.Attributes
+	return false
 }
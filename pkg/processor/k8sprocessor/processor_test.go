@@ -17,6 +17,7 @@ package k8sprocessor
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -219,7 +220,7 @@ func TestProcessorBadConfig(t *testing.T) {
 func TestProcessorBadClientProvider(t *testing.T) {
 	clientProvider := func(
 		_ *zap.Logger,
-		_ k8sconfig.APIConfig,
+		_ kube.APIConfig,
 		_ kube.ExtractionRules,
 		_ kube.Filters,
 		_ []kube.Association,
@@ -610,6 +611,362 @@ func TestPodUID(t *testing.T) {
 	})
 }
 
+func TestPodAssociationFallbacks(t *testing.T) {
+	m := newMultiTest(
+		t,
+		NewFactory().CreateDefaultConfig(),
+		nil,
+	)
+	m.kubernetesProcessorOperation(func(kp *kubernetesprocessor) {
+		kp.podAssociations = []kube.Association{
+			{
+				From: "connection",
+			},
+			{
+				From: "resource_attribute",
+				Name: "k8s.pod.uid",
+			},
+			{
+				From: "build_hostname",
+				Name: "_hostname",
+			},
+		}
+		kp.kc.(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{
+			Name:       "PodA",
+			Attributes: map[string]string{"pod": "PodA"},
+		}
+		// A pod in the host network, which is not associated with the IP address of the node
+		kp.kc.(*fakeClient).Pods["ef10d10b-2da5-4030-812e-5f45c1531227"] = &kube.Pod{
+			Name:       "PodB",
+			Attributes: map[string]string{"pod": "PodB"},
+		}
+		kp.kc.(*fakeClient).Pods["PodC.test"] = &kube.Pod{
+			Name:       "PodC",
+			Attributes: map[string]string{"pod": "PodC"},
+		}
+	})
+
+	testCases := []struct {
+		name      string
+		contextIP string
+		resource  []generateResourceFunc
+		outLabel  string
+		outValue  string
+		pod       string
+	}{
+		{
+			name:      "connection",
+			contextIP: "1.1.1.1",
+			resource:  []generateResourceFunc{withPodUID("ef10d10b-2da5-4030-812e-5f45c1531227")},
+			outLabel:  k8sIPLabelName,
+			outValue:  "1.1.1.1",
+			pod:       "PodA",
+		},
+		{
+			name:      "pod uid",
+			contextIP: "10.0.0.1",
+			resource:  []generateResourceFunc{withPodUID("ef10d10b-2da5-4030-812e-5f45c1531227")},
+			outLabel:  "k8s.pod.uid",
+			outValue:  "ef10d10b-2da5-4030-812e-5f45c1531227",
+			pod:       "PodB",
+		},
+		{
+			name:      "pod name and namespace",
+			contextIP: "10.0.0.1",
+			resource:  []generateResourceFunc{withPodUID("unknown"), withPodAndNamespace("PodC", "test")},
+			outLabel:  "_hostname",
+			outValue:  "PodC.test",
+			pod:       "PodC",
+		},
+		{
+			name:      "no match",
+			contextIP: "10.0.0.1",
+			resource:  []generateResourceFunc{withPodUID("unknown")},
+			outLabel:  k8sIPLabelName,
+			outValue:  "10.0.0.1",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := client.NewContext(context.Background(), &client.Client{IP: tc.contextIP})
+			m.testConsume(ctx,
+				generateTraces(tc.resource...),
+				generateMetrics(tc.resource...),
+				generateLogs(tc.resource...),
+				nil)
+
+			m.assertBatchesLen(i + 1)
+			m.assertResource(i, func(r pdata.Resource) {
+				assertResourceHasStringAttribute(t, r, tc.outLabel, tc.outValue)
+				if tc.pod == "" {
+					_, ok := r.Attributes().Get("pod")
+					assert.False(t, ok)
+				} else {
+					assertResourceHasStringAttribute(t, r, "pod", tc.pod)
+				}
+			})
+		})
+	}
+}
+
+func TestPodAssociationHostPort(t *testing.T) {
+	m := newMultiTest(
+		t,
+		NewFactory().CreateDefaultConfig(),
+		nil,
+	)
+	m.kubernetesProcessorOperation(func(kp *kubernetesprocessor) {
+		kp.podAssociations = []kube.Association{
+			{
+				From: "connection",
+				Port: "net.host.port",
+			},
+		}
+		// Pods in the host network of the node 10.0.0.1, and a pod with its own IP address
+		kp.kc.(*fakeClient).Pods["10.0.0.1:9100"] = &kube.Pod{
+			Name:       "node-exporter",
+			Attributes: map[string]string{"pod": "node-exporter"},
+		}
+		kp.kc.(*fakeClient).Pods["10.0.0.1:8125"] = &kube.Pod{
+			Name:       "statsd",
+			Attributes: map[string]string{"pod": "statsd"},
+		}
+		kp.kc.(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{
+			Name:       "PodA",
+			Attributes: map[string]string{"pod": "PodA"},
+		}
+	})
+
+	withPort := func(port pdata.AttributeValue) generateResourceFunc {
+		return func(res pdata.Resource) {
+			res.Attributes().Insert("net.host.port", port)
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		contextIP string
+		resource  []generateResourceFunc
+		pod       string
+	}{
+		{
+			name:      "string port",
+			contextIP: "10.0.0.1",
+			resource:  []generateResourceFunc{withPort(pdata.NewAttributeValueString("9100"))},
+			pod:       "node-exporter",
+		},
+		{
+			name:      "int port",
+			contextIP: "10.0.0.1",
+			resource:  []generateResourceFunc{withPort(pdata.NewAttributeValueInt(8125))},
+			pod:       "statsd",
+		},
+		{
+			name:      "ip fallback",
+			contextIP: "1.1.1.1",
+			resource:  []generateResourceFunc{withPort(pdata.NewAttributeValueInt(8080))},
+			pod:       "PodA",
+		},
+		{
+			name:      "unknown port",
+			contextIP: "10.0.0.1",
+			resource:  []generateResourceFunc{withPort(pdata.NewAttributeValueInt(8080))},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := client.NewContext(context.Background(), &client.Client{IP: tc.contextIP})
+			m.testConsume(ctx,
+				generateTraces(tc.resource...),
+				generateMetrics(tc.resource...),
+				generateLogs(tc.resource...),
+				nil)
+
+			m.assertBatchesLen(i + 1)
+			m.assertResource(i, func(r pdata.Resource) {
+				assertResourceHasStringAttribute(t, r, k8sIPLabelName, tc.contextIP)
+				if tc.pod == "" {
+					_, ok := r.Attributes().Get("pod")
+					assert.False(t, ok)
+				} else {
+					assertResourceHasStringAttribute(t, r, "pod", tc.pod)
+				}
+			})
+		})
+	}
+}
+
+func TestProcessorContainerAttributes(t *testing.T) {
+	m := newMultiTest(
+		t,
+		NewFactory().CreateDefaultConfig(),
+		nil,
+	)
+	m.kubernetesProcessorOperation(func(kp *kubernetesprocessor) {
+		kp.podAssociations = []kube.Association{{From: "connection"}}
+		kp.kc.(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{
+			Name: "PodA",
+			Attributes: map[string]string{
+				"k8s.pod.name":             "PodA",
+				"k8s.container.name":       "app",
+				"k8s.container.id":         "containerd://app",
+				"k8s.container.image.name": "app",
+			},
+			Containers: map[string]map[string]string{
+				"app": {
+					"k8s.container.id":         "containerd://app",
+					"k8s.container.image.name": "app",
+				},
+				"istio-proxy": {
+					"k8s.container.image.name": "istio/proxyv2",
+				},
+			},
+		}
+	})
+
+	testCases := []struct {
+		name       string
+		container  string
+		attributes map[string]string
+	}{
+		{
+			name: "pod",
+			attributes: map[string]string{
+				"k8s.container.name":       "app",
+				"k8s.container.id":         "containerd://app",
+				"k8s.container.image.name": "app",
+			},
+		},
+		{
+			name:      "container",
+			container: "istio-proxy",
+			attributes: map[string]string{
+				"k8s.container.name":       "istio-proxy",
+				"k8s.container.image.name": "istio/proxyv2",
+			},
+		},
+		{
+			name:      "unknown container",
+			container: "debugger",
+			attributes: map[string]string{
+				"k8s.container.name":       "debugger",
+				"k8s.container.id":         "containerd://app",
+				"k8s.container.image.name": "app",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resource []generateResourceFunc
+			if tc.container != "" {
+				resource = append(resource, func(res pdata.Resource) {
+					res.Attributes().InsertString("k8s.container.name", tc.container)
+				})
+			}
+			ctx := client.NewContext(context.Background(), &client.Client{IP: "1.1.1.1"})
+			m.testConsume(ctx,
+				generateTraces(resource...),
+				generateMetrics(resource...),
+				generateLogs(resource...),
+				nil)
+
+			m.assertBatchesLen(i + 1)
+			m.assertResource(i, func(r pdata.Resource) {
+				assertResourceHasStringAttribute(t, r, "k8s.pod.name", "PodA")
+				for k, v := range tc.attributes {
+					assertResourceHasStringAttribute(t, r, k, v)
+				}
+				if _, ok := tc.attributes["k8s.container.id"]; !ok {
+					_, found := r.Attributes().Get("k8s.container.id")
+					assert.False(t, found)
+				}
+			})
+		})
+	}
+}
+
+func TestProcessorWaitForSync(t *testing.T) {
+	var synced int32
+	newProcessor := func(timeout time.Duration) *kubernetesprocessor {
+		kc, err := newFakeClient(zap.NewNop(), kube.APIConfig{}, kube.ExtractionRules{}, kube.Filters{}, nil, nil, nil, nil)
+		require.NoError(t, err)
+		kc.(*fakeClient).HasSyncedFunc = func() bool {
+			return atomic.LoadInt32(&synced) == 1
+		}
+		kp := &kubernetesprocessor{logger: zap.NewNop(), kc: kc, waitForSyncTimeout: timeout}
+		require.NoError(t, kp.Start(context.Background(), componenttest.NewNopHost()))
+		t.Cleanup(func() {
+			require.NoError(t, kp.Shutdown(context.Background()))
+		})
+		return kp
+	}
+
+	t.Run("synced", func(t *testing.T) {
+		kp := newProcessor(10 * time.Second)
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			atomic.StoreInt32(&synced, 1)
+		}()
+
+		start := time.Now()
+		_, err := kp.ProcessLogs(context.Background(), generateLogs())
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(200*time.Millisecond))
+		assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		atomic.StoreInt32(&synced, 0)
+		kp := newProcessor(200 * time.Millisecond)
+
+		start := time.Now()
+		_, err := kp.ProcessTraces(context.Background(), generateTraces())
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(200*time.Millisecond))
+
+		// The data is not delayed anymore after the timeout
+		start = time.Now()
+		_, err = kp.ProcessMetrics(context.Background(), generateMetrics())
+		require.NoError(t, err)
+		assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		kp := newProcessor(0)
+		start := time.Now()
+		_, err := kp.ProcessLogs(context.Background(), generateLogs())
+		require.NoError(t, err)
+		assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+	})
+}
+
+func TestProcessorClusterName(t *testing.T) {
+	m := newMultiTest(
+		t,
+		NewFactory().CreateDefaultConfig(),
+		nil,
+		WithClusterName("prod-eu"),
+	)
+
+	withCluster := func(res pdata.Resource) {
+		res.Attributes().InsertString("k8s.cluster.name", "prod-us")
+	}
+	ctx := client.NewContext(context.Background(), &client.Client{IP: "1.1.1.1"})
+	m.testConsume(ctx, generateTraces(), generateMetrics(), generateLogs(), nil)
+	m.testConsume(ctx, generateTraces(withCluster), generateMetrics(withCluster), generateLogs(withCluster), nil)
+
+	m.assertBatchesLen(2)
+	m.assertResource(0, func(r pdata.Resource) {
+		assertResourceHasStringAttribute(t, r, "k8s.cluster.name", "prod-eu")
+	})
+	m.assertResource(1, func(r pdata.Resource) {
+		assertResourceHasStringAttribute(t, r, "k8s.cluster.name", "prod-us")
+	})
+}
+
 func TestProcessorAddLabels(t *testing.T) {
 	m := newMultiTest(
 		t,
@@ -711,6 +1068,58 @@ func TestProcessorPicksUpPassthoughPodIp(t *testing.T) {
 	})
 }
 
+func TestProcessorServicesByIP(t *testing.T) {
+	m := newMultiTest(
+		t,
+		NewFactory().CreateDefaultConfig(),
+		nil,
+	)
+
+	m.kubernetesProcessorOperation(func(kp *kubernetesprocessor) {
+		kp.rules.OwnerLookupEnabled = true
+		kp.rules.ServiceName = true
+		kp.rules.Tags = kube.NewExtractionFieldTags()
+		kp.kc.(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{
+			Name: "PodA",
+			Attributes: map[string]string{
+				"k8s.service.name": "stale",
+				"k":                "v",
+			},
+		}
+		kp.kc.(*fakeClient).Services["1.1.1.1"] = []string{"checkout", "checkout-headless"}
+		// The pod is not watched, e.g. it runs on another node
+		kp.kc.(*fakeClient).Services["2.2.2.2"] = []string{"statsd"}
+	})
+
+	m.testConsume(
+		context.Background(),
+		generateTraces(withPassthroughIP("1.1.1.1")),
+		generateMetrics(withPassthroughIP("1.1.1.1")),
+		generateLogs(withPassthroughIP("1.1.1.1")),
+		func(err error) {
+			assert.NoError(t, err)
+		})
+	m.testConsume(
+		context.Background(),
+		generateTraces(withPassthroughIP("2.2.2.2")),
+		generateMetrics(withPassthroughIP("2.2.2.2")),
+		generateLogs(withPassthroughIP("2.2.2.2")),
+		func(err error) {
+			assert.NoError(t, err)
+		})
+
+	m.assertBatchesLen(2)
+	m.assertResourceAttributesLen(0, 3)
+	m.assertResource(0, func(res pdata.Resource) {
+		assertResourceHasStringAttribute(t, res, "k8s.service.name", "checkout, checkout-headless")
+		assertResourceHasStringAttribute(t, res, "k", "v")
+	})
+	m.assertResourceAttributesLen(1, 2)
+	m.assertResource(1, func(res pdata.Resource) {
+		assertResourceHasStringAttribute(t, res, "k8s.service.name", "statsd")
+	})
+}
+
 func TestProcessorByPodNameAndNamespace(t *testing.T) {
 	m := newMultiTest(
 		t,
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdedupprocessor
+
+import (
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the log deduplication.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Interval is the window in which the duplicates of a record are counted instead of being forwarded.
+	// The aggregate records are emitted at the end of each window.
+	Interval time.Duration `mapstructure:"interval"`
+	// KeyAttributes lists the record attributes which, together with the body and the resource,
+	// decide whether the records are identical
+	KeyAttributes []string `mapstructure:"key_attributes"`
+	// CountAttribute is the name of the attribute holding the number of duplicates in the aggregate record
+	CountAttribute string `mapstructure:"count_attribute"`
+	// MaxEntries limits the number of distinct records tracked in a window. The records which do not fit
+	// are passed through.
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+const (
+	defaultInterval       = 10 * time.Second
+	defaultCountAttribute = "duplicate_count"
+	defaultMaxEntries     = 10000
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", cfg.Interval)
+	}
+	if cfg.CountAttribute == "" {
+		return fmt.Errorf("count_attribute must not be empty")
+	}
+	if cfg.MaxEntries <= 0 {
+		return fmt.Errorf("max_entries must be positive, got %d", cfg.MaxEntries)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdedupprocessor
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// logDedupProcessor forwards the first occurrence of each record and counts its duplicates
+// received in the same window, which are then reported with a single aggregate record.
+type logDedupProcessor struct {
+	logger         *zap.Logger
+	nextConsumer   consumer.Logs
+	interval       time.Duration
+	keyAttributes  []string
+	countAttribute string
+	maxEntries     int
+
+	mu sync.Mutex
+	// entries are the records of the current window by the hash of their identity, the records
+	// with colliding hashes share the slice
+	entries    map[uint64][]*dedupEntry
+	numEntries int
+	// hashIdentity is replaced in the tests
+	hashIdentity func(identity string) uint64
+
+	started bool
+	stopCh  chan struct{}
+	done    chan struct{}
+}
+
+// dedupEntry is the first occurrence of a record in the current window
+type dedupEntry struct {
+	// identity is the canonical form of the resource, the body and the key attributes of the record,
+	// compared when the hashes match, so a hash collision doesn't drop a distinct record
+	identity         string
+	resourceIdentity string
+	resource         pdata.Resource
+	library          pdata.InstrumentationLibrary
+	record           pdata.LogRecord
+	// duplicates is the number of the identical records dropped in the window
+	duplicates int64
+	// lastTimestamp is the timestamp of the last duplicate
+	lastTimestamp pdata.Timestamp
+}
+
+var _ component.LogsProcessor = (*logDedupProcessor)(nil)
+
+func newLogDedupProcessor(logger *zap.Logger, cfg *Config, nextConsumer consumer.Logs) *logDedupProcessor {
+	return &logDedupProcessor{
+		logger:         logger,
+		nextConsumer:   nextConsumer,
+		interval:       cfg.Interval,
+		keyAttributes:  cfg.KeyAttributes,
+		countAttribute: cfg.CountAttribute,
+		maxEntries:     cfg.MaxEntries,
+		entries:        map[uint64][]*dedupEntry{},
+		hashIdentity:   hashIdentity,
+		stopCh:         make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+}
+
+// Start starts emitting the aggregate records at the end of each window
+func (p *logDedupProcessor) Start(context.Context, component.Host) error {
+	p.started = true
+	go p.flushLoop()
+	return nil
+}
+
+// Shutdown emits the aggregate records of the current window
+func (p *logDedupProcessor) Shutdown(ctx context.Context) error {
+	if !p.started {
+		p.flush(ctx)
+		return nil
+	}
+	close(p.stopCh)
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Capabilities returns the consumer capabilities
+func (p *logDedupProcessor) Capabilities() consumer.Capabilities {
+	return processorCapabilities
+}
+
+// ConsumeLogs forwards the records seen for the first time in the current window and drops their duplicates
+func (p *logDedupProcessor) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
+	p.mu.Lock()
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resourceIdentity := attributesIdentity(rl.Resource().Attributes())
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			ill := ills.At(j)
+			ill.Logs().RemoveIf(func(lr pdata.LogRecord) bool {
+				return p.isDuplicate(resourceIdentity, rl.Resource(), ill.InstrumentationLibrary(), lr)
+			})
+		}
+		ills.RemoveIf(func(ill pdata.InstrumentationLibraryLogs) bool {
+			return ill.Logs().Len() == 0
+		})
+	}
+	p.mu.Unlock()
+
+	rls.RemoveIf(func(rl pdata.ResourceLogs) bool {
+		return rl.InstrumentationLibraryLogs().Len() == 0
+	})
+	if rls.Len() == 0 {
+		return nil
+	}
+	return p.nextConsumer.ConsumeLogs(ctx, ld)
+}
+
+// isDuplicate counts the record if it was already seen in the current window, otherwise it is tracked
+// unless there are too many records tracked already
+func (p *logDedupProcessor) isDuplicate(
+	resourceIdentity string,
+	resource pdata.Resource,
+	library pdata.InstrumentationLibrary,
+	lr pdata.LogRecord,
+) bool {
+	identity := p.recordIdentity(resourceIdentity, lr)
+	key := p.hashIdentity(identity)
+	for _, entry := range p.entries[key] {
+		if entry.identity == identity {
+			entry.duplicates++
+			entry.lastTimestamp = lr.Timestamp()
+			return true
+		}
+	}
+	if p.numEntries >= p.maxEntries {
+		return false
+	}
+
+	entry := &dedupEntry{
+		identity:         identity,
+		resourceIdentity: resourceIdentity,
+		resource:         pdata.NewResource(),
+		library:          pdata.NewInstrumentationLibrary(),
+		record:           pdata.NewLogRecord(),
+	}
+	resource.CopyTo(entry.resource)
+	library.CopyTo(entry.library)
+	lr.CopyTo(entry.record)
+	p.entries[key] = append(p.entries[key], entry)
+	p.numEntries++
+	return false
+}
+
+// recordIdentity returns the canonical form of the resource, the body and the key attributes of the record
+func (p *logDedupProcessor) recordIdentity(resourceIdentity string, lr pdata.LogRecord) string {
+	var b strings.Builder
+	writeField(&b, resourceIdentity)
+	writeValue(&b, lr.Body())
+	for _, name := range p.keyAttributes {
+		// The missing attribute differs from an empty one
+		if value, ok := lr.Attributes().Get(name); ok {
+			writeValue(&b, value)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// attributesIdentity returns the canonical form of the attributes, in the order of their names
+func attributesIdentity(attributes pdata.AttributeMap) string {
+	names := make([]string, 0, attributes.Len())
+	attributes.Range(func(name string, _ pdata.AttributeValue) bool {
+		names = append(names, name)
+		return true
+	})
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		value, _ := attributes.Get(name)
+		writeField(&b, name)
+		writeValue(&b, value)
+	}
+	return b.String()
+}
+
+// writeValue writes the type and the value, so e.g. the string "1" differs from the number 1
+func writeValue(b *strings.Builder, value pdata.AttributeValue) {
+	b.WriteString(strconv.Itoa(int(value.Type())))
+	writeField(b, pdata.AttributeValueToString(value))
+}
+
+// writeField writes the length before the content, so the fields can't run into each other
+func writeField(b *strings.Builder, field string) {
+	b.WriteByte(':')
+	b.WriteString(strconv.Itoa(len(field)))
+	b.WriteByte(':')
+	b.WriteString(field)
+}
+
+func hashIdentity(identity string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(identity))
+	return h.Sum64()
+}
+
+func (p *logDedupProcessor) flushLoop() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.flush(context.Background())
+		case <-p.stopCh:
+			p.flush(context.Background())
+			return
+		}
+	}
+}
+
+// flush starts a new window and emits the aggregate records of the records which had duplicates
+func (p *logDedupProcessor) flush(ctx context.Context) {
+	p.mu.Lock()
+	entries := p.entries
+	p.entries = make(map[uint64][]*dedupEntry, len(entries))
+	p.numEntries = 0
+	p.mu.Unlock()
+
+	ld := p.aggregates(entries)
+	if ld.LogRecordCount() == 0 {
+		return
+	}
+	if err := p.nextConsumer.ConsumeLogs(ctx, ld); err != nil {
+		p.logger.Error("Failed to send the aggregate records", zap.Error(err))
+	}
+}
+
+// aggregates builds the aggregate records, grouped by their resources. Each of them is a copy of the first
+// occurrence, with the timestamp of the last duplicate and the number of duplicates.
+func (p *logDedupProcessor) aggregates(entries map[uint64][]*dedupEntry) pdata.Logs {
+	ld := pdata.NewLogs()
+	resources := map[string]pdata.ResourceLogs{}
+	for _, bucket := range entries {
+		for _, entry := range bucket {
+			if entry.duplicates == 0 {
+				continue
+			}
+			rl, ok := resources[entry.resourceIdentity]
+			if !ok {
+				rl = ld.ResourceLogs().AppendEmpty()
+				entry.resource.CopyTo(rl.Resource())
+				resources[entry.resourceIdentity] = rl
+			}
+			ill := rl.InstrumentationLibraryLogs().AppendEmpty()
+			entry.library.CopyTo(ill.InstrumentationLibrary())
+			lr := ill.Logs().AppendEmpty()
+			entry.record.CopyTo(lr)
+			if entry.lastTimestamp != 0 {
+				lr.SetTimestamp(entry.lastTimestamp)
+			}
+			lr.Attributes().UpsertInt(p.countAttribute, entry.duplicates)
+		}
+	}
+	return ld
+}
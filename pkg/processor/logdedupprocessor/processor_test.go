@@ -0,0 +1,234 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logdedupprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+type testRecord struct {
+	pod        string
+	body       string
+	attributes map[string]string
+	timestamp  pdata.Timestamp
+}
+
+func newTestLogs(records ...testRecord) pdata.Logs {
+	ld := pdata.NewLogs()
+	for _, r := range records {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().InsertString("k8s.pod.name", r.pod)
+		lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+		lr.Body().SetStringVal(r.body)
+		lr.SetTimestamp(r.timestamp)
+		for k, v := range r.attributes {
+			lr.Attributes().InsertString(k, v)
+		}
+	}
+	return ld
+}
+
+func newTestProcessor(cfg *Config) (*logDedupProcessor, *consumertest.LogsSink) {
+	sink := &consumertest.LogsSink{}
+	return newLogDedupProcessor(zap.NewNop(), cfg, sink), sink
+}
+
+func bodies(ld pdata.Logs) []string {
+	var result []string
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				result = append(result, logs.At(k).Body().StringVal())
+			}
+		}
+	}
+	return result
+}
+
+func TestDeduplication(t *testing.T) {
+	p, sink := newTestProcessor(createDefaultConfig().(*Config))
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app-1", body: "connection refused", timestamp: 1},
+		testRecord{pod: "app-1", body: "connection refused", timestamp: 2},
+		testRecord{pod: "app-1", body: "started", timestamp: 3},
+		testRecord{pod: "app-2", body: "connection refused", timestamp: 4},
+	)))
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app-1", body: "connection refused", timestamp: 5},
+		testRecord{pod: "app-1", body: "started", timestamp: 6},
+	)))
+
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Equal(t, []string{"connection refused", "started", "connection refused"}, bodies(sink.AllLogs()[0]))
+
+	p.flush(context.Background())
+	require.Len(t, sink.AllLogs(), 2)
+	aggregates := sink.AllLogs()[1]
+	require.Equal(t, 1, aggregates.ResourceLogs().Len())
+
+	rl := aggregates.ResourceLogs().At(0)
+	pod, _ := rl.Resource().Attributes().Get("k8s.pod.name")
+	assert.Equal(t, "app-1", pod.StringVal())
+	assert.Equal(t, 2, rl.InstrumentationLibraryLogs().Len())
+	counts := map[string]int64{}
+	for _, lr := range []pdata.LogRecord{
+		rl.InstrumentationLibraryLogs().At(0).Logs().At(0),
+		rl.InstrumentationLibraryLogs().At(1).Logs().At(0),
+	} {
+		count, ok := lr.Attributes().Get(defaultCountAttribute)
+		require.True(t, ok)
+		counts[lr.Body().StringVal()] = count.IntVal()
+		if lr.Body().StringVal() == "connection refused" {
+			assert.Equal(t, pdata.Timestamp(5), lr.Timestamp())
+		}
+	}
+	assert.Equal(t, map[string]int64{"connection refused": 2, "started": 1}, counts)
+
+	// A new window starts after the flush
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app-1", body: "connection refused"},
+	)))
+	require.Len(t, sink.AllLogs(), 3)
+	p.flush(context.Background())
+	assert.Len(t, sink.AllLogs(), 3)
+}
+
+func TestDeduplicationKeyAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.KeyAttributes = []string{"level"}
+	p, sink := newTestProcessor(cfg)
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "retrying", attributes: map[string]string{"level": "warn", "attempt": "1"}},
+		testRecord{pod: "app", body: "retrying", attributes: map[string]string{"level": "warn", "attempt": "2"}},
+		testRecord{pod: "app", body: "retrying", attributes: map[string]string{"level": "error"}},
+		testRecord{pod: "app", body: "retrying", attributes: map[string]string{"level": ""}},
+		testRecord{pod: "app", body: "retrying"},
+	)))
+
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Len(t, bodies(sink.AllLogs()[0]), 4)
+}
+
+func TestDeduplicationMaxEntries(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxEntries = 1
+	p, sink := newTestProcessor(cfg)
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "first"},
+		testRecord{pod: "app", body: "second"},
+		testRecord{pod: "app", body: "second"},
+		testRecord{pod: "app", body: "first"},
+	)))
+
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Equal(t, []string{"first", "second", "second"}, bodies(sink.AllLogs()[0]))
+}
+
+func TestDeduplicationAllDropped(t *testing.T) {
+	p, sink := newTestProcessor(createDefaultConfig().(*Config))
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(testRecord{pod: "app", body: "crash"})))
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(testRecord{pod: "app", body: "crash"})))
+	assert.Len(t, sink.AllLogs(), 1)
+}
+
+func TestShutdownFlushes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Interval = time.Hour
+	p, sink := newTestProcessor(cfg)
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "crash"},
+		testRecord{pod: "app", body: "crash"},
+	)))
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	require.Len(t, sink.AllLogs(), 2)
+	assert.Equal(t, []string{"crash"}, bodies(sink.AllLogs()[1]))
+}
+
+func TestPeriodicFlush(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Interval = 10 * time.Millisecond
+	p, sink := newTestProcessor(cfg)
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, p.Shutdown(context.Background()))
+	}()
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "crash"},
+		testRecord{pod: "app", body: "crash"},
+	)))
+	assert.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestHashCollisionKeepsDistinctRecords(t *testing.T) {
+	p, sink := newTestProcessor(createDefaultConfig().(*Config))
+	// All the records have the same hash
+	p.hashIdentity = func(string) uint64 { return 1 }
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app-1", body: "connection refused"},
+		testRecord{pod: "app-1", body: "started"},
+		testRecord{pod: "app-2", body: "started"},
+		testRecord{pod: "app-1", body: "started"},
+	)))
+
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Equal(t, []string{"connection refused", "started", "started"}, bodies(sink.AllLogs()[0]))
+
+	p.flush(context.Background())
+	require.Len(t, sink.AllLogs(), 2)
+	aggregates := sink.AllLogs()[1]
+	assert.Equal(t, []string{"started"}, bodies(aggregates))
+	pod, _ := aggregates.ResourceLogs().At(0).Resource().Attributes().Get("k8s.pod.name")
+	assert.Equal(t, "app-1", pod.StringVal())
+}
+
+func TestRecordIdentityTellsApartTypesAndMissingAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.KeyAttributes = []string{"level"}
+	p, _ := newTestProcessor(cfg)
+
+	stringBody := pdata.NewLogRecord()
+	stringBody.Body().SetStringVal("1")
+	intBody := pdata.NewLogRecord()
+	intBody.Body().SetIntVal(1)
+	assert.NotEqual(t, p.recordIdentity("", stringBody), p.recordIdentity("", intBody))
+
+	emptyLevel := pdata.NewLogRecord()
+	emptyLevel.Body().SetStringVal("1")
+	emptyLevel.Attributes().InsertString("level", "")
+	assert.NotEqual(t, p.recordIdentity("", stringBody), p.recordIdentity("", emptyLevel))
+}
@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logsamplingprocessor
+
+import (
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the log sampling.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// KeyAttributes are the attributes whose values make the key the records are sampled by,
+	// e.g. the pod name or the source category. The record attributes take precedence over the resource ones.
+	KeyAttributes []string `mapstructure:"key_attributes"`
+	// SamplingPercentage is the percentage of the records of each key which are kept
+	SamplingPercentage float64 `mapstructure:"sampling_percentage"`
+	// MaxRecords is the budget of the records of each key kept in an interval, 0 means no limit
+	MaxRecords int `mapstructure:"max_records"`
+	// Interval is the period the budget of the records applies to
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxKeys limits the number of the keys the budgets are tracked for. The records of the keys
+	// which do not fit share a single budget.
+	MaxKeys int `mapstructure:"max_keys"`
+	// AlwaysKeep holds the conditions of the records which are never sampled out
+	AlwaysKeep AlwaysKeepConfig `mapstructure:"always_keep"`
+}
+
+// AlwaysKeepConfig holds the conditions of the records which are never sampled out.
+type AlwaysKeepConfig struct {
+	// MinSeverity is the lowest severity of the records always kept, e.g. WARN; empty disables the condition
+	MinSeverity string `mapstructure:"min_severity"`
+}
+
+const (
+	defaultSamplingPercentage = 100
+	defaultInterval           = time.Minute
+	defaultMaxKeys            = 10000
+	defaultMinSeverity        = "WARN"
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.SamplingPercentage < 0 || cfg.SamplingPercentage > 100 {
+		return fmt.Errorf("sampling_percentage must be between 0 and 100, got %v", cfg.SamplingPercentage)
+	}
+	if cfg.MaxRecords < 0 {
+		return fmt.Errorf("max_records must not be negative, got %d", cfg.MaxRecords)
+	}
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", cfg.Interval)
+	}
+	if cfg.MaxKeys <= 0 {
+		return fmt.Errorf("max_keys must be positive, got %d", cfg.MaxKeys)
+	}
+	if cfg.AlwaysKeep.MinSeverity != "" {
+		if _, ok := parseSeverity(cfg.AlwaysKeep.MinSeverity); !ok {
+			return fmt.Errorf("invalid always_keep.min_severity %q, must be one of: TRACE, DEBUG, INFO, WARN, ERROR, FATAL",
+				cfg.AlwaysKeep.MinSeverity)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logsamplingprocessor
+
+import (
+	"context"
+
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+const (
+	// The value of "type" Log Sampling in configuration.
+	typeStr = "log_sampling"
+)
+
+var processorCapabilities = consumer.Capabilities{MutatesData: true}
+
+func init() {
+	// TODO: this is hardcoding the metrics level
+	err := view.Register(MetricViews(configtelemetry.LevelNormal)...)
+	if err != nil {
+		panic("failed to register logsamplingprocessor: " + err.Error())
+	}
+}
+
+// NewFactory returns a new factory for the Log Sampling processor.
+func NewFactory() component.ProcessorFactory {
+	return processorhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		processorhelper.WithLogs(createLogsProcessor))
+}
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings:  config.NewProcessorSettings(config.NewID(typeStr)),
+		SamplingPercentage: defaultSamplingPercentage,
+		Interval:           defaultInterval,
+		MaxKeys:            defaultMaxKeys,
+		AlwaysKeep: AlwaysKeepConfig{
+			MinSeverity: defaultMinSeverity,
+		},
+	}
+}
+
+func createLogsProcessor(
+	_ context.Context,
+	_ component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Logs,
+) (component.LogsProcessor, error) {
+	lCfg := cfg.(*Config)
+	if err := lCfg.Validate(); err != nil {
+		return nil, err
+	}
+	lsp := newLogSamplingProcessor(lCfg)
+
+	return processorhelper.NewLogsProcessor(
+		cfg,
+		nextConsumer,
+		lsp.ProcessLogs,
+		processorhelper.WithCapabilities(processorCapabilities))
+}
@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logsamplingprocessor
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+)
+
+// Variables related to metrics specific to Log Sampling.
+var (
+	tagReasonKey, _ = tag.NewKey("reason")
+
+	statDroppedCount = stats.Int64("log_sampling_dropped_records", "Count of log records dropped per reason", stats.UnitDimensionless)
+)
+
+const (
+	reasonProbabilistic = "probabilistic"
+	reasonBudget        = "budget"
+)
+
+// MetricViews return the metrics views according to given telemetry level.
+func MetricViews(level configtelemetry.Level) []*view.View {
+	if level == configtelemetry.LevelNone {
+		return nil
+	}
+
+	countDroppedView := &view.View{
+		Name:        statDroppedCount.Name(),
+		Measure:     statDroppedCount,
+		Description: statDroppedCount.Description(),
+		TagKeys:     []tag.Key{tagReasonKey},
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{countDroppedView}
+}
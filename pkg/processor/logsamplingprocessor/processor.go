@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logsamplingprocessor
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+// keySeparator separates the values of the key attributes, it is not expected in the attribute values
+const keySeparator = "\x00"
+
+// overflowKey is the key of the shared budget of the records whose keys do not fit in max_keys
+const overflowKey = "\x00overflow"
+
+// logSamplingProcessor samples the log records probabilistically and within the budgets of their keys
+type logSamplingProcessor struct {
+	keyAttributes []string
+	ratio         float64
+	maxRecords    int
+	interval      time.Duration
+	maxKeys       int
+	minSeverity   pdata.SeverityNumber
+
+	mu          sync.Mutex
+	random      func() float64
+	now         func() time.Time
+	budgets     map[string]*budget
+	lastCleanup time.Time
+}
+
+// budget counts the records of a key kept in the current interval
+type budget struct {
+	start time.Time
+	count int
+}
+
+// dropCounts counts the dropped records per reason
+type dropCounts map[string]int64
+
+func newLogSamplingProcessor(cfg *Config) *logSamplingProcessor {
+	minSeverity, _ := parseSeverity(cfg.AlwaysKeep.MinSeverity)
+	return &logSamplingProcessor{
+		keyAttributes: cfg.KeyAttributes,
+		ratio:         cfg.SamplingPercentage / 100,
+		maxRecords:    cfg.MaxRecords,
+		interval:      cfg.Interval,
+		maxKeys:       cfg.MaxKeys,
+		minSeverity:   minSeverity,
+		random:        rand.New(rand.NewSource(time.Now().UnixNano())).Float64,
+		now:           time.Now,
+		budgets:       map[string]*budget{},
+	}
+}
+
+// ProcessLogs drops the sampled out records, skipping the further processing when none of them are left
+func (lsp *logSamplingProcessor) ProcessLogs(ctx context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	counts := dropCounts{}
+
+	lsp.mu.Lock()
+	now := lsp.now()
+	lsp.cleanup(now)
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			ills.At(j).Logs().RemoveIf(func(lr pdata.LogRecord) bool {
+				reason := lsp.sample(rl.Resource(), lr, now)
+				if reason != "" {
+					counts[reason]++
+				}
+				return reason != ""
+			})
+		}
+		ills.RemoveIf(func(ill pdata.InstrumentationLibraryLogs) bool {
+			return ill.Logs().Len() == 0
+		})
+	}
+	lsp.mu.Unlock()
+
+	counts.record(ctx)
+	rls.RemoveIf(func(rl pdata.ResourceLogs) bool {
+		return rl.InstrumentationLibraryLogs().Len() == 0
+	})
+	if rls.Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+// sample returns the reason the record is dropped for, or an empty string if it is kept
+func (lsp *logSamplingProcessor) sample(resource pdata.Resource, lr pdata.LogRecord, now time.Time) string {
+	if lsp.alwaysKeep(lr) {
+		return ""
+	}
+	if lsp.ratio < 1 && lsp.random() >= lsp.ratio {
+		return reasonProbabilistic
+	}
+	if lsp.maxRecords == 0 {
+		return ""
+	}
+
+	b := lsp.budget(lsp.key(resource, lr), now)
+	if now.Sub(b.start) >= lsp.interval {
+		b.start = now
+		b.count = 0
+	}
+	if b.count >= lsp.maxRecords {
+		return reasonBudget
+	}
+	b.count++
+	return ""
+}
+
+// alwaysKeep checks if the severity of the record reaches the minimum severity,
+// falling back to the severity text for the records without the severity number
+func (lsp *logSamplingProcessor) alwaysKeep(lr pdata.LogRecord) bool {
+	if lsp.minSeverity == pdata.SeverityNumberUNDEFINED {
+		return false
+	}
+	severity := lr.SeverityNumber()
+	if severity == pdata.SeverityNumberUNDEFINED {
+		severity, _ = parseSeverity(lr.SeverityText())
+	}
+	return severity >= lsp.minSeverity
+}
+
+// key joins the values of the key attributes, taken from the record or the resource
+func (lsp *logSamplingProcessor) key(resource pdata.Resource, lr pdata.LogRecord) string {
+	values := make([]string, len(lsp.keyAttributes))
+	for i, name := range lsp.keyAttributes {
+		if value, ok := lr.Attributes().Get(name); ok {
+			values[i] = pdata.AttributeValueToString(value)
+		} else if value, ok := resource.Attributes().Get(name); ok {
+			values[i] = pdata.AttributeValueToString(value)
+		}
+	}
+	return strings.Join(values, keySeparator)
+}
+
+// budget returns the budget of the key, the shared overflow one when there are too many keys tracked
+func (lsp *logSamplingProcessor) budget(key string, now time.Time) *budget {
+	if b, ok := lsp.budgets[key]; ok {
+		return b
+	}
+	if len(lsp.budgets) >= lsp.maxKeys {
+		key = overflowKey
+		if b, ok := lsp.budgets[key]; ok {
+			return b
+		}
+	}
+	b := &budget{start: now}
+	lsp.budgets[key] = b
+	return b
+}
+
+// cleanup forgets the budgets whose intervals have expired, once per interval
+func (lsp *logSamplingProcessor) cleanup(now time.Time) {
+	if now.Sub(lsp.lastCleanup) < lsp.interval {
+		return
+	}
+	lsp.lastCleanup = now
+	for key, b := range lsp.budgets {
+		if now.Sub(b.start) >= lsp.interval {
+			delete(lsp.budgets, key)
+		}
+	}
+}
+
+func (counts dropCounts) record(ctx context.Context) {
+	for reason, count := range counts {
+		_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(tagReasonKey, reason)}, statDroppedCount.M(count))
+	}
+}
+
+// parseSeverity maps the severity name to the severity number, accepting the common aliases
+func parseSeverity(name string) (pdata.SeverityNumber, bool) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "TRACE":
+		return pdata.SeverityNumberTRACE, true
+	case "DEBUG":
+		return pdata.SeverityNumberDEBUG, true
+	case "INFO", "INFORMATION", "NOTICE":
+		return pdata.SeverityNumberINFO, true
+	case "WARN", "WARNING":
+		return pdata.SeverityNumberWARN, true
+	case "ERROR", "ERR":
+		return pdata.SeverityNumberERROR, true
+	case "FATAL", "CRITICAL", "CRIT", "ALERT", "EMERG", "EMERGENCY", "PANIC":
+		return pdata.SeverityNumberFATAL, true
+	default:
+		return pdata.SeverityNumberUNDEFINED, false
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logsamplingprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+type testRecord struct {
+	pod      string
+	severity pdata.SeverityNumber
+	text     string
+}
+
+// newTestLogs creates the records with the pod name as the resource attribute
+func newTestLogs(records ...testRecord) pdata.Logs {
+	ld := pdata.NewLogs()
+	for _, r := range records {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().InsertString("k8s.pod.name", r.pod)
+		lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+		lr.SetSeverityNumber(r.severity)
+		lr.SetSeverityText(r.text)
+		lr.Body().SetStringVal("message")
+	}
+	return ld
+}
+
+func podsOf(ld pdata.Logs) []string {
+	var pods []string
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		pod, _ := rls.At(i).Resource().Attributes().Get("k8s.pod.name")
+		pods = append(pods, pod.StringVal())
+	}
+	return pods
+}
+
+func newTestProcessor(modify func(cfg *Config)) *logSamplingProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.KeyAttributes = []string{"k8s.pod.name"}
+	modify(cfg)
+	return newLogSamplingProcessor(cfg)
+}
+
+func TestBudgetPerKey(t *testing.T) {
+	lsp := newTestProcessor(func(cfg *Config) {
+		cfg.MaxRecords = 2
+	})
+	now := time.Now()
+	lsp.now = func() time.Time { return now }
+
+	ld, err := lsp.ProcessLogs(context.Background(), newTestLogs(
+		testRecord{pod: "a"}, testRecord{pod: "a"}, testRecord{pod: "a"},
+		testRecord{pod: "b"}, testRecord{pod: "a", severity: pdata.SeverityNumberERROR},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "a", "b", "a"}, podsOf(ld))
+
+	// the budget is exhausted until the interval ends
+	_, err = lsp.ProcessLogs(context.Background(), newTestLogs(testRecord{pod: "a"}))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+
+	now = now.Add(time.Minute)
+	ld, err = lsp.ProcessLogs(context.Background(), newTestLogs(testRecord{pod: "a"}, testRecord{pod: "a"}, testRecord{pod: "a"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "a"}, podsOf(ld))
+}
+
+func TestProbabilisticSampling(t *testing.T) {
+	lsp := newTestProcessor(func(cfg *Config) {
+		cfg.SamplingPercentage = 50
+	})
+	values := []float64{0.1, 0.7, 0.49, 0.5}
+	lsp.random = func() float64 {
+		value := values[0]
+		values = values[1:]
+		return value
+	}
+
+	ld, err := lsp.ProcessLogs(context.Background(), newTestLogs(
+		testRecord{pod: "a"}, testRecord{pod: "b"}, testRecord{pod: "c"},
+		testRecord{pod: "d", text: "Error"}, testRecord{pod: "e"},
+	))
+	require.NoError(t, err)
+	// the error record is kept without being sampled
+	assert.Equal(t, []string{"a", "c", "d"}, podsOf(ld))
+	assert.Empty(t, values)
+}
+
+func TestAlwaysKeepDisabled(t *testing.T) {
+	lsp := newTestProcessor(func(cfg *Config) {
+		cfg.SamplingPercentage = 0
+		cfg.AlwaysKeep.MinSeverity = ""
+	})
+
+	_, err := lsp.ProcessLogs(context.Background(), newTestLogs(testRecord{pod: "a", severity: pdata.SeverityNumberFATAL}))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+}
+
+func TestMaxKeys(t *testing.T) {
+	lsp := newTestProcessor(func(cfg *Config) {
+		cfg.MaxRecords = 1
+		cfg.MaxKeys = 1
+	})
+
+	// the records of the keys over the limit share a single budget
+	ld, err := lsp.ProcessLogs(context.Background(), newTestLogs(
+		testRecord{pod: "a"}, testRecord{pod: "b"}, testRecord{pod: "c"}, testRecord{pod: "a"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, podsOf(ld))
+	assert.Len(t, lsp.budgets, 2)
+}
+
+func TestKeyFromRecordAttributes(t *testing.T) {
+	lsp := newTestProcessor(func(cfg *Config) {
+		cfg.KeyAttributes = []string{"_sourceCategory", "k8s.pod.name"}
+	})
+
+	ld := newTestLogs(testRecord{pod: "a"})
+	rl := ld.ResourceLogs().At(0)
+	rl.Resource().Attributes().InsertString("_sourceCategory", "resource/category")
+	lr := rl.InstrumentationLibraryLogs().At(0).Logs().At(0)
+	assert.Equal(t, "resource/category\x00a", lsp.key(rl.Resource(), lr))
+
+	lr.Attributes().InsertString("_sourceCategory", "record/category")
+	assert.Equal(t, "record/category\x00a", lsp.key(rl.Resource(), lr))
+}
+
+func TestCleanup(t *testing.T) {
+	lsp := newTestProcessor(func(cfg *Config) {
+		cfg.MaxRecords = 10
+	})
+	now := time.Now()
+	lsp.now = func() time.Time { return now }
+
+	_, err := lsp.ProcessLogs(context.Background(), newTestLogs(testRecord{pod: "a"}, testRecord{pod: "b"}))
+	require.NoError(t, err)
+	assert.Len(t, lsp.budgets, 2)
+
+	now = now.Add(2 * time.Minute)
+	_, err = lsp.ProcessLogs(context.Background(), newTestLogs(testRecord{pod: "c"}))
+	require.NoError(t, err)
+	assert.Len(t, lsp.budgets, 1)
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, ok := parseSeverity("warning")
+	assert.True(t, ok)
+	assert.Equal(t, pdata.SeverityNumberWARN, severity)
+
+	severity, ok = parseSeverity(" CRIT ")
+	assert.True(t, ok)
+	assert.Equal(t, pdata.SeverityNumberFATAL, severity)
+
+	_, ok = parseSeverity("verbose")
+	assert.False(t, ok)
+}
@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logscascadingfilterprocessor
+
+// rateBudget tracks the number of records per second, separately for each group.
+// A budget with zero limit does not limit anything.
+type rateBudget struct {
+	limit         int64
+	currentSecond int64
+	used          map[string]int64
+}
+
+func newRateBudget(limit int64) *rateBudget {
+	return &rateBudget{
+		limit: limit,
+		used:  make(map[string]int64),
+	}
+}
+
+func (rb *rateBudget) resetIfNeeded(currSecond int64) {
+	if rb.currentSecond != currSecond {
+		rb.currentSecond = currSecond
+		rb.used = make(map[string]int64, len(rb.used))
+	}
+}
+
+// fits returns true if one more record of the group fits within the budget for the given second
+func (rb *rateBudget) fits(currSecond int64, group string) bool {
+	if rb.limit <= 0 {
+		return true
+	}
+	rb.resetIfNeeded(currSecond)
+	return rb.used[group] < rb.limit
+}
+
+// consume accounts one record of the group in the budget for the given second
+func (rb *rateBudget) consume(currSecond int64, group string) {
+	if rb.limit <= 0 {
+		return
+	}
+	rb.resetIfNeeded(currSecond)
+	rb.used[group]++
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logscascadingfilterprocessor
+
+import (
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration for the cascading filtering of logs.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// RecordsPerSecond is the total budget that should never be exceeded, except for always_keep policies
+	RecordsPerSecond int64 `mapstructure:"records_per_second"`
+	// Policies are evaluated in the order as specified, the first one which selects the record decides
+	Policies []PolicyCfg `mapstructure:"policies"`
+}
+
+// PolicyCfg holds the configuration of a single logs filtering policy. All of the conditions
+// which are set must be met by the record to be selected by the policy.
+type PolicyCfg struct {
+	// Name identifies the policy in metrics and logs
+	Name string `mapstructure:"name"`
+	// AlwaysKeep makes the policy bypass all budgets, e.g. for errors or audit logs
+	AlwaysKeep bool `mapstructure:"always_keep"`
+	// RecordsPerSecond is the policy budget. When zero, only the total budget applies.
+	RecordsPerSecond int64 `mapstructure:"records_per_second"`
+	// GroupBy lists the attributes (of the record or its resource) scoping RecordsPerSecond, so each
+	// distinct set of their values gets its own budget
+	GroupBy []string `mapstructure:"group_by"`
+
+	// MinSeverity selects records with at least given severity: trace, debug, info, warn, error or fatal
+	MinSeverity string `mapstructure:"min_severity"`
+	// StringAttribute selects records with given attribute (of the record or its resource) equal to any of the values
+	StringAttribute *StringAttributeCfg `mapstructure:"string_attribute"`
+	// BodyPattern selects records with string body matching the regular expression
+	BodyPattern string `mapstructure:"body_pattern"`
+}
+
+// StringAttributeCfg holds the configuration of matching the string attribute.
+type StringAttributeCfg struct {
+	// Key is the attribute name
+	Key string `mapstructure:"key"`
+	// Values is the set of accepted attribute values
+	Values []string `mapstructure:"values"`
+}
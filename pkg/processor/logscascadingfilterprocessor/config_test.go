@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logscascadingfilterprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "logs_cascading_filter_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			RecordsPerSecond:  500,
+			Policies: []PolicyCfg{
+				{
+					Name:        "errors",
+					AlwaysKeep:  true,
+					MinSeverity: "error",
+				},
+				{
+					Name:            "audit",
+					AlwaysKeep:      true,
+					StringAttribute: &StringAttributeCfg{Key: "log.type", Values: []string{"audit"}},
+				},
+				{
+					Name:             "timeouts",
+					BodyPattern:      "(?i)timeout",
+					RecordsPerSecond: 50,
+				},
+				{
+					Name:             "everything_else",
+					RecordsPerSecond: 10,
+					GroupBy:          []string{"k8s.namespace.name", "k8s.container.name"},
+				},
+			},
+		})
+}
@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logscascadingfilterprocessor
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+)
+
+// Variables related to metrics specific to Logs Cascading Filter.
+var (
+	statusKept     = "Kept"
+	statusExceeded = "RateExceeded"
+	statusDropped  = "Dropped"
+
+	noPolicyName = "none"
+
+	tagPolicyKey, _   = tag.NewKey("policy")
+	tagDecisionKey, _ = tag.NewKey("logs_cascading_filter_decision")
+
+	statRecordsCount = stats.Int64("logs_cascading_filter_records", "Count of log records kept, dropped or exceeding the budget per policy", stats.UnitDimensionless)
+)
+
+// MetricViews return the metrics views according to given telemetry level.
+func MetricViews(level configtelemetry.Level) []*view.View {
+	if level == configtelemetry.LevelNone {
+		return nil
+	}
+
+	countRecordsView := &view.View{
+		Name:        statRecordsCount.Name(),
+		Measure:     statRecordsCount,
+		Description: statRecordsCount.Description(),
+		TagKeys:     []tag.Key{tagPolicyKey, tagDecisionKey},
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{countRecordsView}
+}
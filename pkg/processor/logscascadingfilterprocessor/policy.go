@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logscascadingfilterprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+var severityNumbers = map[string]pdata.SeverityNumber{
+	"trace": pdata.SeverityNumberTRACE,
+	"debug": pdata.SeverityNumberDEBUG,
+	"info":  pdata.SeverityNumberINFO,
+	"warn":  pdata.SeverityNumberWARN,
+	"error": pdata.SeverityNumberERROR,
+	"fatal": pdata.SeverityNumberFATAL,
+}
+
+// policy is the compiled PolicyCfg
+type policy struct {
+	name       string
+	alwaysKeep bool
+	groupBy    []string
+	budget     *rateBudget
+
+	minSeverity     pdata.SeverityNumber
+	attributeKey    string
+	attributeValues map[string]struct{}
+	bodyPattern     *regexp.Regexp
+}
+
+func newPolicy(cfg *PolicyCfg) (*policy, error) {
+	p := &policy{
+		name:       cfg.Name,
+		alwaysKeep: cfg.AlwaysKeep,
+		groupBy:    cfg.GroupBy,
+		budget:     newRateBudget(cfg.RecordsPerSecond),
+	}
+
+	if cfg.MinSeverity != "" {
+		severity, ok := severityNumbers[strings.ToLower(cfg.MinSeverity)]
+		if !ok {
+			return nil, fmt.Errorf("policy %q: unknown min_severity %q", cfg.Name, cfg.MinSeverity)
+		}
+		p.minSeverity = severity
+	}
+
+	if cfg.StringAttribute != nil {
+		p.attributeKey = cfg.StringAttribute.Key
+		p.attributeValues = make(map[string]struct{}, len(cfg.StringAttribute.Values))
+		for _, value := range cfg.StringAttribute.Values {
+			p.attributeValues[value] = struct{}{}
+		}
+	}
+
+	if cfg.BodyPattern != "" {
+		re, err := regexp.Compile(cfg.BodyPattern)
+		if err != nil {
+			return nil, fmt.Errorf("policy %q: invalid body_pattern: %w", cfg.Name, err)
+		}
+		p.bodyPattern = re
+	}
+
+	return p, nil
+}
+
+// matches returns true if the record meets all conditions of the policy
+func (p *policy) matches(resource pdata.Resource, lr pdata.LogRecord) bool {
+	if p.minSeverity != pdata.SeverityNumberUNDEFINED && lr.SeverityNumber() < p.minSeverity {
+		return false
+	}
+
+	if p.attributeValues != nil {
+		value, ok := lookupAttribute(resource, lr, p.attributeKey)
+		if !ok || value.Type() != pdata.AttributeValueTypeString {
+			return false
+		}
+		if _, ok := p.attributeValues[value.StringVal()]; !ok {
+			return false
+		}
+	}
+
+	if p.bodyPattern != nil {
+		body := lr.Body()
+		if body.Type() != pdata.AttributeValueTypeString || !p.bodyPattern.MatchString(body.StringVal()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// groupKey identifies the budget scope of the record
+func (p *policy) groupKey(resource pdata.Resource, lr pdata.LogRecord) string {
+	if len(p.groupBy) == 0 {
+		return ""
+	}
+
+	values := make([]string, len(p.groupBy))
+	for i, key := range p.groupBy {
+		if value, ok := lookupAttribute(resource, lr, key); ok {
+			values[i] = pdata.AttributeValueToString(value)
+		}
+	}
+	return strings.Join(values, "\x00")
+}
+
+// lookupAttribute finds the attribute of the record, falling back to the resource attributes
+func lookupAttribute(resource pdata.Resource, lr pdata.LogRecord, key string) (pdata.AttributeValue, bool) {
+	if value, ok := lr.Attributes().Get(key); ok {
+		return value, true
+	}
+	return resource.Attributes().Get(key)
+}
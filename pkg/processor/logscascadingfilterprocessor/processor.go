@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logscascadingfilterprocessor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+type decisionCounts map[string]map[string]int64
+
+func (dc decisionCounts) add(policy string, decision string) {
+	if dc[policy] == nil {
+		dc[policy] = make(map[string]int64)
+	}
+	dc[policy][decision]++
+}
+
+// logsCascadingFilterProcessor keeps the log records selected by the policies, within the policy and total budgets.
+// Contrary to traces, each record is decided immediately, so nothing is buffered.
+type logsCascadingFilterProcessor struct {
+	logger   *zap.Logger
+	policies []*policy
+
+	// lock guards the budgets
+	lock        sync.Mutex
+	totalBudget *rateBudget
+}
+
+func newLogsCascadingFilterProcessor(logger *zap.Logger, cfg *Config) (*logsCascadingFilterProcessor, error) {
+	policies := make([]*policy, 0, len(cfg.Policies))
+	for i := range cfg.Policies {
+		p, err := newPolicy(&cfg.Policies[i])
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+
+	return &logsCascadingFilterProcessor{
+		logger:      logger,
+		policies:    policies,
+		totalBudget: newRateBudget(cfg.RecordsPerSecond),
+	}, nil
+}
+
+// ProcessLogs removes the records which are not selected by any policy or exceed the budgets
+func (lcf *logsCascadingFilterProcessor) ProcessLogs(ctx context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	currSecond := time.Now().Unix()
+	counts := decisionCounts{}
+
+	lcf.lock.Lock()
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resource := rl.Resource()
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			ills.At(j).Logs().RemoveIf(func(lr pdata.LogRecord) bool {
+				return !lcf.decide(currSecond, resource, lr, counts)
+			})
+		}
+		ills.RemoveIf(func(ill pdata.InstrumentationLibraryLogs) bool {
+			return ill.Logs().Len() == 0
+		})
+	}
+	rls.RemoveIf(func(rl pdata.ResourceLogs) bool {
+		return rl.InstrumentationLibraryLogs().Len() == 0
+	})
+	lcf.lock.Unlock()
+
+	lcf.recordMetrics(ctx, counts)
+	if ld.LogRecordCount() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+// decide returns true if the record should be kept. Must be called with lock held.
+func (lcf *logsCascadingFilterProcessor) decide(currSecond int64, resource pdata.Resource, lr pdata.LogRecord, counts decisionCounts) bool {
+	for _, p := range lcf.policies {
+		if !p.matches(resource, lr) {
+			continue
+		}
+
+		if p.alwaysKeep {
+			// Still accounted, so it lowers the total budget left for other records
+			lcf.totalBudget.consume(currSecond, "")
+			counts.add(p.name, statusKept)
+			return true
+		}
+
+		group := p.groupKey(resource, lr)
+		if !p.budget.fits(currSecond, group) || !lcf.totalBudget.fits(currSecond, "") {
+			// Next policies might still have the budget for the record
+			counts.add(p.name, statusExceeded)
+			continue
+		}
+
+		p.budget.consume(currSecond, group)
+		lcf.totalBudget.consume(currSecond, "")
+		counts.add(p.name, statusKept)
+		return true
+	}
+
+	counts.add(noPolicyName, statusDropped)
+	return false
+}
+
+func (lcf *logsCascadingFilterProcessor) recordMetrics(ctx context.Context, counts decisionCounts) {
+	for policy, decisions := range counts {
+		for decision, count := range decisions {
+			err := stats.RecordWithTags(
+				ctx,
+				[]tag.Mutator{tag.Insert(tagPolicyKey, policy), tag.Insert(tagDecisionKey, decision)},
+				statRecordsCount.M(count),
+			)
+			if err != nil {
+				lcf.logger.Error("Recording logs cascading filter metrics error", zap.Error(err))
+			}
+		}
+	}
+}
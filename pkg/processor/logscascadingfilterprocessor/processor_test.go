@@ -0,0 +1,159 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logscascadingfilterprocessor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+type testRecord struct {
+	severity  pdata.SeverityNumber
+	body      string
+	namespace string
+	attrs     map[string]string
+}
+
+func newLogs(records ...testRecord) pdata.Logs {
+	ld := pdata.NewLogs()
+	for _, r := range records {
+		rl := ld.ResourceLogs().AppendEmpty()
+		if r.namespace != "" {
+			rl.Resource().Attributes().InsertString("k8s.namespace.name", r.namespace)
+		}
+		lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+		lr.SetSeverityNumber(r.severity)
+		lr.Body().SetStringVal(r.body)
+		for k, v := range r.attrs {
+			lr.Attributes().InsertString(k, v)
+		}
+	}
+	return ld
+}
+
+func bodies(ld pdata.Logs) []string {
+	var result []string
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				result = append(result, logs.At(k).Body().StringVal())
+			}
+		}
+	}
+	return result
+}
+
+func newTestProcessor(t *testing.T, recordsPerSecond int64, policies ...PolicyCfg) *logsCascadingFilterProcessor {
+	lcf, err := newLogsCascadingFilterProcessor(zap.NewNop(), &Config{RecordsPerSecond: recordsPerSecond, Policies: policies})
+	require.NoError(t, err)
+	return lcf
+}
+
+func TestAlwaysKeepBypassesBudgets(t *testing.T) {
+	lcf := newTestProcessor(t, 1,
+		PolicyCfg{Name: "errors", AlwaysKeep: true, MinSeverity: "error"},
+		PolicyCfg{Name: "audit", AlwaysKeep: true, StringAttribute: &StringAttributeCfg{Key: "log.type", Values: []string{"audit"}}},
+		PolicyCfg{Name: "everything_else"},
+	)
+
+	ld, err := lcf.ProcessLogs(context.Background(), newLogs(
+		testRecord{severity: pdata.SeverityNumberINFO, body: "info-1"},
+		testRecord{severity: pdata.SeverityNumberERROR, body: "error-1"},
+		testRecord{severity: pdata.SeverityNumberFATAL, body: "fatal-1"},
+		testRecord{severity: pdata.SeverityNumberINFO, body: "audit-1", attrs: map[string]string{"log.type": "audit"}},
+		testRecord{severity: pdata.SeverityNumberINFO, body: "info-2"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"info-1", "error-1", "fatal-1", "audit-1"}, bodies(ld))
+}
+
+func TestRecordsNotSelectedAreDropped(t *testing.T) {
+	lcf := newTestProcessor(t, 100, PolicyCfg{Name: "timeouts", BodyPattern: "(?i)timeout"})
+
+	ld, err := lcf.ProcessLogs(context.Background(), newLogs(
+		testRecord{body: "Connection Timeout"},
+		testRecord{body: "all good"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Connection Timeout"}, bodies(ld))
+	assert.Equal(t, 1, ld.ResourceLogs().Len())
+
+	_, err = lcf.ProcessLogs(context.Background(), newLogs(testRecord{body: "all good"}))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+}
+
+func TestGroupBudgets(t *testing.T) {
+	lcf := newTestProcessor(t, 100,
+		PolicyCfg{Name: "per-namespace", RecordsPerSecond: 2, GroupBy: []string{"k8s.namespace.name"}},
+	)
+
+	var records []testRecord
+	for i := 0; i < 5; i++ {
+		records = append(records,
+			testRecord{body: fmt.Sprintf("noisy-%d", i), namespace: "noisy"},
+			testRecord{body: fmt.Sprintf("quiet-%d", i), namespace: "quiet"},
+		)
+	}
+	ld, err := lcf.ProcessLogs(context.Background(), newLogs(records...))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"noisy-0", "quiet-0", "noisy-1", "quiet-1"}, bodies(ld))
+}
+
+func TestCascadingToNextPolicy(t *testing.T) {
+	lcf := newTestProcessor(t, 100,
+		PolicyCfg{Name: "warnings", MinSeverity: "warn", RecordsPerSecond: 1},
+		PolicyCfg{Name: "everything_else", RecordsPerSecond: 2},
+	)
+
+	ld, err := lcf.ProcessLogs(context.Background(), newLogs(
+		testRecord{severity: pdata.SeverityNumberWARN, body: "warn-1"},
+		testRecord{severity: pdata.SeverityNumberWARN, body: "warn-2"},
+		testRecord{severity: pdata.SeverityNumberINFO, body: "info-1"},
+		testRecord{severity: pdata.SeverityNumberINFO, body: "info-2"},
+	))
+	require.NoError(t, err)
+	// warn-2 exceeds its policy budget, but is still selected by the next policy
+	assert.Equal(t, []string{"warn-1", "warn-2", "info-1"}, bodies(ld))
+}
+
+func TestRateBudget(t *testing.T) {
+	rb := newRateBudget(1)
+	assert.True(t, rb.fits(1, "a"))
+	rb.consume(1, "a")
+	assert.False(t, rb.fits(1, "a"))
+	assert.True(t, rb.fits(1, "b"))
+	assert.True(t, rb.fits(2, "a"))
+
+	unlimited := newRateBudget(0)
+	unlimited.consume(1, "a")
+	assert.True(t, unlimited.fits(1, "a"))
+}
+
+func TestInvalidPolicies(t *testing.T) {
+	_, err := newPolicy(&PolicyCfg{Name: "severity", MinSeverity: "critical"})
+	assert.Error(t, err)
+	_, err = newPolicy(&PolicyCfg{Name: "body", BodyPattern: "("})
+	assert.Error(t, err)
+}
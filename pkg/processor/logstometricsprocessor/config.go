@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstometricsprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the logs to metrics conversion.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// MetricsExporter is the ID of the exporter the metrics are sent to, e.g. sumologic/metrics
+	MetricsExporter string `mapstructure:"metrics_exporter"`
+	// Interval is the period in which the metrics are sent
+	Interval time.Duration `mapstructure:"interval"`
+	// Metrics are the metrics created from the log records
+	Metrics []MetricConfig `mapstructure:"metrics"`
+}
+
+// MetricConfig holds the configuration of a single metric created from the log records.
+type MetricConfig struct {
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	Unit        string `mapstructure:"unit"`
+	// Type is one of counter, gauge or histogram
+	Type string `mapstructure:"type"`
+	// Match selects the log records the metric is created from, all of them when empty
+	Match MatchConfig `mapstructure:"match"`
+	// ValueAttribute is the attribute holding the value of the metric. When empty, the counter counts the records.
+	ValueAttribute string `mapstructure:"value_attribute"`
+	// Attributes are the attributes of the log records (or their resources) set as the data point attributes
+	Attributes []string `mapstructure:"attributes"`
+	// Buckets are the explicit bounds of the histogram buckets
+	Buckets []float64 `mapstructure:"buckets"`
+}
+
+// MatchConfig holds the conditions a log record has to meet, all of them need to be met.
+type MatchConfig struct {
+	// Attributes are the regular expressions the attributes of the log records (or their resources)
+	// have to match, by the attribute names
+	Attributes map[string]string `mapstructure:"attributes"`
+	// Body is the regular expression the body of the log records has to match
+	Body string `mapstructure:"body"`
+}
+
+const (
+	defaultInterval = 60 * time.Second
+	defaultType     = typeCounter
+)
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// defaultBuckets are the default bounds of the histogram buckets, the same as in the Prometheus clients
+var defaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.MetricsExporter == "" {
+		return fmt.Errorf("metrics_exporter must not be empty")
+	}
+	if _, err := config.NewIDFromString(cfg.MetricsExporter); err != nil {
+		return fmt.Errorf("invalid metrics_exporter: %w", err)
+	}
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", cfg.Interval)
+	}
+	if len(cfg.Metrics) == 0 {
+		return fmt.Errorf("at least one metric is required")
+	}
+	for i, metric := range cfg.Metrics {
+		if err := metric.validate(); err != nil {
+			return fmt.Errorf("invalid metric %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (metric *MetricConfig) validate() error {
+	if metric.Name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+	switch metric.Type {
+	case "", typeCounter:
+	case typeGauge, typeHistogram:
+		if metric.ValueAttribute == "" {
+			return fmt.Errorf("value_attribute is required for the %s metrics", metric.Type)
+		}
+	default:
+		return fmt.Errorf("unknown type %q, expected one of: %s, %s, %s", metric.Type, typeCounter, typeGauge, typeHistogram)
+	}
+	if len(metric.Buckets) > 0 && metric.Type != typeHistogram {
+		return fmt.Errorf("buckets can only be set for the histogram metrics")
+	}
+	if !sort.Float64sAreSorted(metric.Buckets) {
+		return fmt.Errorf("buckets must be sorted")
+	}
+	for name, expression := range metric.Match.Attributes {
+		if _, err := regexp.Compile(expression); err != nil {
+			return fmt.Errorf("invalid regex %q for attribute %s: %w", expression, name, err)
+		}
+	}
+	if _, err := regexp.Compile(metric.Match.Body); err != nil {
+		return fmt.Errorf("invalid body regex %q: %w", metric.Match.Body, err)
+	}
+	return nil
+}
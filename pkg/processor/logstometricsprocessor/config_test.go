@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstometricsprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "logs_to_metrics_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			MetricsExporter:   "sumologic/metrics",
+			Interval:          30 * time.Second,
+			Metrics: []MetricConfig{
+				{
+					Name:        "app_errors_total",
+					Description: "Number of the error logs",
+					Match: MatchConfig{
+						Attributes: map[string]string{"level": "^(error|ERROR)$"},
+						Body:       "exception",
+					},
+					Attributes: []string{"k8s.namespace.name", "k8s.deployment.name"},
+				},
+				{
+					Name:           "http_request_duration_seconds",
+					Unit:           "s",
+					Type:           "histogram",
+					ValueAttribute: "duration",
+					Buckets:        []float64{0.1, 0.5, 1},
+					Attributes:     []string{"http.route"},
+				},
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	validConfig := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.MetricsExporter = "sumologic"
+		cfg.Metrics = []MetricConfig{{Name: "errors_total", Match: MatchConfig{Body: "error"}}}
+		return cfg
+	}
+	assert.NoError(t, validConfig().Validate())
+
+	cfg := validConfig()
+	cfg.MetricsExporter = ""
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.MetricsExporter = "sumologic/"
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Interval = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Metrics = nil
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Metrics[0].Name = ""
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Metrics[0].Type = "summary"
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Metrics[0].Type = typeGauge
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Metrics[0].Buckets = []float64{1}
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Metrics[0].Type = typeHistogram
+	cfg.Metrics[0].ValueAttribute = "duration"
+	cfg.Metrics[0].Buckets = []float64{1, 0.5}
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Metrics[0].Match.Body = "error("
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Metrics[0].Match.Attributes = map[string]string{"level": "error("}
+	assert.Error(t, cfg.Validate())
+}
@@ -0,0 +1,392 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstometricsprocessor
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/attrhash"
+)
+
+// logsToMetricsProcessor creates the metrics from the log records, sends them to the metrics exporter
+// and passes the log records through
+type logsToMetricsProcessor struct {
+	logger          *zap.Logger
+	nextConsumer    consumer.Logs
+	exporterID      config.ComponentID
+	metricsExporter component.MetricsExporter
+	interval        time.Duration
+	rules           []*rule
+	now             func() time.Time
+
+	mu             sync.Mutex
+	series         map[uint64]*series
+	startTimestamp pdata.Timestamp
+
+	started bool
+	stopCh  chan struct{}
+	done    chan struct{}
+}
+
+// rule is the compiled MetricConfig
+type rule struct {
+	index          int
+	name           string
+	description    string
+	unit           string
+	metricType     string
+	attributes     []attributeMatcher
+	body           *regexp.Regexp
+	valueAttribute string
+	labels         []string
+	buckets        []float64
+}
+
+type attributeMatcher struct {
+	name  string
+	regex *regexp.Regexp
+}
+
+// series holds the value of a metric with the same resource and data point attributes
+type series struct {
+	rule        *rule
+	resourceKey uint64
+	resource    pdata.AttributeMap
+	attributes  pdata.AttributeMap
+
+	// value is the total of the counter or the last value of the gauge
+	value float64
+	// updated is set when the gauge got a value in the current interval
+	updated bool
+
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+var _ component.LogsProcessor = (*logsToMetricsProcessor)(nil)
+
+func newLogsToMetricsProcessor(logger *zap.Logger, cfg *Config, nextConsumer consumer.Logs) (*logsToMetricsProcessor, error) {
+	exporterID, err := config.NewIDFromString(cfg.MetricsExporter)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &logsToMetricsProcessor{
+		logger:       logger,
+		nextConsumer: nextConsumer,
+		exporterID:   exporterID,
+		interval:     cfg.Interval,
+		now:          time.Now,
+		series:       map[uint64]*series{},
+		stopCh:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	for i, metricCfg := range cfg.Metrics {
+		r := &rule{
+			index:          i,
+			name:           metricCfg.Name,
+			description:    metricCfg.Description,
+			unit:           metricCfg.Unit,
+			metricType:     metricCfg.Type,
+			valueAttribute: metricCfg.ValueAttribute,
+			labels:         metricCfg.Attributes,
+			buckets:        metricCfg.Buckets,
+		}
+		if r.metricType == "" {
+			r.metricType = defaultType
+		}
+		if r.metricType == typeHistogram && len(r.buckets) == 0 {
+			r.buckets = defaultBuckets
+		}
+		for name, expression := range metricCfg.Match.Attributes {
+			regex, err := regexp.Compile(expression)
+			if err != nil {
+				return nil, err
+			}
+			r.attributes = append(r.attributes, attributeMatcher{name: name, regex: regex})
+		}
+		sort.Slice(r.attributes, func(i, j int) bool {
+			return r.attributes[i].name < r.attributes[j].name
+		})
+		if metricCfg.Match.Body != "" {
+			regex, err := regexp.Compile(metricCfg.Match.Body)
+			if err != nil {
+				return nil, err
+			}
+			r.body = regex
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// Start finds the metrics exporter and starts sending the metrics at the end of each interval
+func (p *logsToMetricsProcessor) Start(_ context.Context, host component.Host) error {
+	exporter, ok := host.GetExporters()[config.MetricsDataType][p.exporterID]
+	if !ok {
+		return fmt.Errorf("metrics exporter %s not found, it needs to be used in a metrics pipeline", p.exporterID)
+	}
+	metricsExporter, ok := exporter.(component.MetricsExporter)
+	if !ok {
+		return fmt.Errorf("exporter %s is not a metrics exporter", p.exporterID)
+	}
+	p.metricsExporter = metricsExporter
+
+	p.startTimestamp = pdata.TimestampFromTime(p.now())
+	p.started = true
+	go p.flushLoop()
+	return nil
+}
+
+// Shutdown sends the metrics for the current interval
+func (p *logsToMetricsProcessor) Shutdown(ctx context.Context) error {
+	if !p.started {
+		return nil
+	}
+	close(p.stopCh)
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Capabilities returns the consumer capabilities
+func (p *logsToMetricsProcessor) Capabilities() consumer.Capabilities {
+	return processorCapabilities
+}
+
+// ConsumeLogs updates the metrics with the matching log records and passes the logs through
+func (p *logsToMetricsProcessor) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
+	p.mu.Lock()
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		resource := rls.At(i).Resource().Attributes()
+		resourceKey := attrhash.Attributes(fnv.New64a(), resource)
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				for _, r := range p.rules {
+					p.add(r, resourceKey, resource, logs.At(k))
+				}
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	return p.nextConsumer.ConsumeLogs(ctx, ld)
+}
+
+// add updates the metric of the rule if the log record matches it
+func (p *logsToMetricsProcessor) add(r *rule, resourceKey uint64, resource pdata.AttributeMap, record pdata.LogRecord) {
+	if !r.matches(resource, record) {
+		return
+	}
+
+	value := 1.0
+	if r.valueAttribute != "" {
+		var ok bool
+		value, ok = numericValue(lookup(resource, record, r.valueAttribute))
+		if !ok {
+			p.logger.Debug("Log record has no numeric value",
+				zap.String("metric", r.name), zap.String("attribute", r.valueAttribute))
+			return
+		}
+	}
+
+	attributes := pdata.NewAttributeMap()
+	for _, label := range r.labels {
+		if value, ok := lookup(resource, record, label); ok {
+			attributes.Insert(label, value)
+		}
+	}
+
+	h := fnv.New64a()
+	attrhash.WriteUint64(h, uint64(r.index))
+	attrhash.WriteUint64(h, resourceKey)
+	key := attrhash.Attributes(h, attributes)
+
+	s, ok := p.series[key]
+	if !ok {
+		s = &series{
+			rule:        r,
+			resourceKey: resourceKey,
+			resource:    pdata.NewAttributeMap(),
+			attributes:  attributes,
+		}
+		resource.CopyTo(s.resource)
+		if r.metricType == typeHistogram {
+			s.bucketCounts = make([]uint64, len(r.buckets)+1)
+		}
+		p.series[key] = s
+	}
+	s.add(value)
+}
+
+func (s *series) add(value float64) {
+	switch s.rule.metricType {
+	case typeCounter:
+		s.value += value
+	case typeGauge:
+		s.value = value
+		s.updated = true
+	case typeHistogram:
+		s.bucketCounts[sort.SearchFloat64s(s.rule.buckets, value)]++
+		s.count++
+		s.sum += value
+	}
+}
+
+// matches checks if the log record meets all the conditions of the rule
+func (r *rule) matches(resource pdata.AttributeMap, record pdata.LogRecord) bool {
+	for _, matcher := range r.attributes {
+		value, ok := lookup(resource, record, matcher.name)
+		if !ok || !matcher.regex.MatchString(pdata.AttributeValueToString(value)) {
+			return false
+		}
+	}
+	if r.body != nil {
+		body := record.Body()
+		if body.Type() == pdata.AttributeValueTypeString {
+			return r.body.MatchString(body.StringVal())
+		}
+		return r.body.MatchString(pdata.AttributeValueToString(body))
+	}
+	return true
+}
+
+// lookup returns the attribute of the log record or, if it is not set, the attribute of its resource
+func lookup(resource pdata.AttributeMap, record pdata.LogRecord, name string) (pdata.AttributeValue, bool) {
+	if value, ok := record.Attributes().Get(name); ok {
+		return value, true
+	}
+	return resource.Get(name)
+}
+
+func numericValue(value pdata.AttributeValue, ok bool) (float64, bool) {
+	if !ok {
+		return 0, false
+	}
+	switch value.Type() {
+	case pdata.AttributeValueTypeInt:
+		return float64(value.IntVal()), true
+	case pdata.AttributeValueTypeDouble:
+		return value.DoubleVal(), true
+	case pdata.AttributeValueTypeString:
+		parsed, err := strconv.ParseFloat(value.StringVal(), 64)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+func (p *logsToMetricsProcessor) flushLoop() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.flush(context.Background())
+		case <-p.stopCh:
+			p.flush(context.Background())
+			return
+		}
+	}
+}
+
+// flush sends the current values of the metrics to the metrics exporter
+func (p *logsToMetricsProcessor) flush(ctx context.Context) {
+	now := pdata.TimestampFromTime(p.now())
+	md := pdata.NewMetrics()
+	resources := map[uint64]pdata.MetricSlice{}
+
+	p.mu.Lock()
+	for _, s := range p.series {
+		if s.rule.metricType == typeGauge && !s.updated {
+			continue
+		}
+		metrics, ok := resources[s.resourceKey]
+		if !ok {
+			rm := md.ResourceMetrics().AppendEmpty()
+			s.resource.CopyTo(rm.Resource().Attributes())
+			metrics = rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+			resources[s.resourceKey] = metrics
+		}
+		s.appendMetric(metrics, p.startTimestamp, now)
+		s.updated = false
+	}
+	p.mu.Unlock()
+
+	if md.DataPointCount() == 0 {
+		return
+	}
+	if err := p.metricsExporter.ConsumeMetrics(ctx, md); err != nil {
+		p.logger.Error("Failed to send the metrics created from the logs", zap.Error(err))
+	}
+}
+
+// appendMetric adds the metric with a single data point holding the value of the series
+func (s *series) appendMetric(metrics pdata.MetricSlice, start, now pdata.Timestamp) {
+	metric := metrics.AppendEmpty()
+	metric.SetName(s.rule.name)
+	metric.SetDescription(s.rule.description)
+	metric.SetUnit(s.rule.unit)
+
+	switch s.rule.metricType {
+	case typeCounter:
+		metric.SetDataType(pdata.MetricDataTypeSum)
+		metric.Sum().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		metric.Sum().SetIsMonotonic(true)
+		dp := metric.Sum().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(start)
+		dp.SetTimestamp(now)
+		dp.SetDoubleVal(s.value)
+		s.attributes.CopyTo(dp.Attributes())
+	case typeGauge:
+		metric.SetDataType(pdata.MetricDataTypeGauge)
+		dp := metric.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetDoubleVal(s.value)
+		s.attributes.CopyTo(dp.Attributes())
+	case typeHistogram:
+		metric.SetDataType(pdata.MetricDataTypeHistogram)
+		metric.Histogram().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		dp := metric.Histogram().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(start)
+		dp.SetTimestamp(now)
+		dp.SetCount(s.count)
+		dp.SetSum(s.sum)
+		dp.SetBucketCounts(append([]uint64(nil), s.bucketCounts...))
+		dp.SetExplicitBounds(s.rule.buckets)
+		s.attributes.CopyTo(dp.Attributes())
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstometricsprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// testExporter is a metrics exporter collecting the metrics
+type testExporter struct {
+	consumertest.MetricsSink
+}
+
+func (e *testExporter) Start(context.Context, component.Host) error { return nil }
+func (e *testExporter) Shutdown(context.Context) error              { return nil }
+
+// testHost provides the metrics exporters
+type testHost struct {
+	component.Host
+	exporters map[config.ComponentID]component.Exporter
+}
+
+func (h testHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return map[config.DataType]map[config.ComponentID]component.Exporter{config.MetricsDataType: h.exporters}
+}
+
+type testRecord struct {
+	namespace  string
+	body       string
+	attributes map[string]interface{}
+}
+
+func newTestLogs(records ...testRecord) pdata.Logs {
+	ld := pdata.NewLogs()
+	for _, record := range records {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().InsertString("k8s.namespace.name", record.namespace)
+		lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+		lr.Body().SetStringVal(record.body)
+		for k, v := range record.attributes {
+			switch value := v.(type) {
+			case string:
+				lr.Attributes().InsertString(k, value)
+			case int:
+				lr.Attributes().InsertInt(k, int64(value))
+			case float64:
+				lr.Attributes().InsertDouble(k, value)
+			}
+		}
+	}
+	return ld
+}
+
+func newTestProcessor(t *testing.T, metrics ...MetricConfig) (*logsToMetricsProcessor, *testExporter, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MetricsExporter = "sumologic/metrics"
+	cfg.Metrics = metrics
+	require.NoError(t, cfg.Validate())
+
+	logsSink := &consumertest.LogsSink{}
+	p, err := newLogsToMetricsProcessor(zap.NewNop(), cfg, logsSink)
+	require.NoError(t, err)
+
+	exporter := &testExporter{}
+	host := testHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.ComponentID]component.Exporter{
+			config.NewIDWithName("sumologic", "metrics"): exporter,
+		},
+	}
+	require.NoError(t, p.Start(context.Background(), host))
+	return p, exporter, logsSink
+}
+
+// collectMetrics returns the metrics by the namespace of their resource
+func collectMetrics(md pdata.Metrics) map[string][]pdata.Metric {
+	result := map[string][]pdata.Metric{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		namespace, _ := rms.At(i).Resource().Attributes().Get("k8s.namespace.name")
+		metrics := rms.At(i).InstrumentationLibraryMetrics().At(0).Metrics()
+		for j := 0; j < metrics.Len(); j++ {
+			result[namespace.StringVal()] = append(result[namespace.StringVal()], metrics.At(j))
+		}
+	}
+	return result
+}
+
+func TestCounter(t *testing.T) {
+	p, exporter, logsSink := newTestProcessor(t, MetricConfig{
+		Name: "errors_total",
+		Match: MatchConfig{
+			Attributes: map[string]string{"level": "^error$"},
+			Body:       "failed",
+		},
+		Attributes: []string{"service", "k8s.namespace.name"},
+	})
+
+	logs := newTestLogs(
+		testRecord{namespace: "payments", body: "request failed", attributes: map[string]interface{}{"level": "error", "service": "api"}},
+		testRecord{namespace: "payments", body: "request failed", attributes: map[string]interface{}{"level": "error", "service": "api"}},
+		testRecord{namespace: "payments", body: "request failed", attributes: map[string]interface{}{"level": "info", "service": "api"}},
+		testRecord{namespace: "payments", body: "request succeeded", attributes: map[string]interface{}{"level": "error", "service": "api"}},
+		testRecord{namespace: "shipping", body: "delivery failed", attributes: map[string]interface{}{"level": "error"}},
+	)
+	require.NoError(t, p.ConsumeLogs(context.Background(), logs))
+	// the logs are passed through
+	assert.Equal(t, 5, logsSink.LogRecordCount())
+
+	p.flush(context.Background())
+	require.Len(t, exporter.AllMetrics(), 1)
+	metrics := collectMetrics(exporter.AllMetrics()[0])
+	require.Len(t, metrics["payments"], 1)
+	require.Len(t, metrics["shipping"], 1)
+
+	payments := metrics["payments"][0]
+	assert.Equal(t, "errors_total", payments.Name())
+	require.Equal(t, pdata.MetricDataTypeSum, payments.DataType())
+	assert.True(t, payments.Sum().IsMonotonic())
+	assert.Equal(t, pdata.AggregationTemporalityCumulative, payments.Sum().AggregationTemporality())
+	dp := payments.Sum().DataPoints().At(0)
+	assert.Equal(t, 2.0, dp.DoubleVal())
+	assert.Equal(t, map[string]string{"service": "api", "k8s.namespace.name": "payments"}, attributesToMap(dp.Attributes()))
+	assert.Equal(t, p.startTimestamp, dp.StartTimestamp())
+
+	dp = metrics["shipping"][0].Sum().DataPoints().At(0)
+	assert.Equal(t, 1.0, dp.DoubleVal())
+	assert.Equal(t, map[string]string{"k8s.namespace.name": "shipping"}, attributesToMap(dp.Attributes()))
+
+	// the counter is cumulative
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{namespace: "payments", body: "request failed", attributes: map[string]interface{}{"level": "error", "service": "api"}},
+	)))
+	p.flush(context.Background())
+	require.Len(t, exporter.AllMetrics(), 2)
+	metrics = collectMetrics(exporter.AllMetrics()[1])
+	assert.Equal(t, 3.0, metrics["payments"][0].Sum().DataPoints().At(0).DoubleVal())
+	assert.Equal(t, 1.0, metrics["shipping"][0].Sum().DataPoints().At(0).DoubleVal())
+
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestCounterValue(t *testing.T) {
+	p, exporter, _ := newTestProcessor(t, MetricConfig{
+		Name:           "bytes_sent_total",
+		ValueAttribute: "bytes",
+	})
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{namespace: "payments", attributes: map[string]interface{}{"bytes": 100}},
+		testRecord{namespace: "payments", attributes: map[string]interface{}{"bytes": "50.5"}},
+		testRecord{namespace: "payments", attributes: map[string]interface{}{"bytes": "unknown"}},
+		testRecord{namespace: "payments"},
+	)))
+
+	p.flush(context.Background())
+	require.Len(t, exporter.AllMetrics(), 1)
+	metrics := collectMetrics(exporter.AllMetrics()[0])
+	assert.Equal(t, 150.5, metrics["payments"][0].Sum().DataPoints().At(0).DoubleVal())
+}
+
+func TestGauge(t *testing.T) {
+	p, exporter, _ := newTestProcessor(t, MetricConfig{
+		Name:           "queue_size",
+		Type:           typeGauge,
+		ValueAttribute: "queue_size",
+	})
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{namespace: "payments", attributes: map[string]interface{}{"queue_size": 10}},
+		testRecord{namespace: "payments", attributes: map[string]interface{}{"queue_size": 4.5}},
+	)))
+
+	p.flush(context.Background())
+	require.Len(t, exporter.AllMetrics(), 1)
+	metric := collectMetrics(exporter.AllMetrics()[0])["payments"][0]
+	require.Equal(t, pdata.MetricDataTypeGauge, metric.DataType())
+	assert.Equal(t, 4.5, metric.Gauge().DataPoints().At(0).DoubleVal())
+
+	// the gauge is not sent again without a new value
+	p.flush(context.Background())
+	assert.Len(t, exporter.AllMetrics(), 1)
+}
+
+func TestHistogram(t *testing.T) {
+	p, exporter, _ := newTestProcessor(t, MetricConfig{
+		Name:           "request_duration_seconds",
+		Unit:           "s",
+		Type:           typeHistogram,
+		ValueAttribute: "duration",
+		Buckets:        []float64{0.1, 0.5, 1},
+	})
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{namespace: "payments", attributes: map[string]interface{}{"duration": 0.05}},
+		testRecord{namespace: "payments", attributes: map[string]interface{}{"duration": 0.1}},
+		testRecord{namespace: "payments", attributes: map[string]interface{}{"duration": 0.7}},
+		testRecord{namespace: "payments", attributes: map[string]interface{}{"duration": 3}},
+	)))
+
+	p.flush(context.Background())
+	require.Len(t, exporter.AllMetrics(), 1)
+	metric := collectMetrics(exporter.AllMetrics()[0])["payments"][0]
+	assert.Equal(t, "s", metric.Unit())
+	require.Equal(t, pdata.MetricDataTypeHistogram, metric.DataType())
+	dp := metric.Histogram().DataPoints().At(0)
+	assert.Equal(t, uint64(4), dp.Count())
+	assert.InDelta(t, 3.85, dp.Sum(), 1e-9)
+	assert.Equal(t, []float64{0.1, 0.5, 1}, dp.ExplicitBounds())
+	assert.Equal(t, []uint64{2, 0, 1, 1}, dp.BucketCounts())
+}
+
+func TestShutdownFlushes(t *testing.T) {
+	p, exporter, _ := newTestProcessor(t, MetricConfig{Name: "logs_total"})
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(testRecord{namespace: "payments"})))
+	assert.Empty(t, exporter.AllMetrics())
+
+	require.NoError(t, p.Shutdown(context.Background()))
+	require.Len(t, exporter.AllMetrics(), 1)
+}
+
+func TestMissingExporter(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MetricsExporter = "sumologic/metrics"
+	cfg.Metrics = []MetricConfig{{Name: "logs_total"}}
+	p, err := newLogsToMetricsProcessor(zap.NewNop(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	host := testHost{Host: componenttest.NewNopHost(), exporters: map[config.ComponentID]component.Exporter{}}
+	assert.Error(t, p.Start(context.Background(), host))
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricfrequencyprocessor
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the constant-metric sieve.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Metrics are the regular expressions matching the names of the sieved metrics, all of them are sieved when empty
+	Metrics []string `mapstructure:"metrics"`
+	// Epsilon is the largest change of the value of a series which is still considered constant
+	Epsilon float64 `mapstructure:"epsilon"`
+	// Window is the time for which the value of a series must stay within epsilon to be considered constant
+	Window time.Duration `mapstructure:"window"`
+	// ReportEvery is the N of the 1 in N data points of a constant series which are passed through
+	ReportEvery int `mapstructure:"report_every"`
+	// MaxSeries is the maximum number of the tracked series, the data points of the new ones above it are passed through
+	MaxSeries int `mapstructure:"max_series"`
+	// Overrides set the minimum reporting interval of the matching metrics, which are not sieved then
+	Overrides []Override `mapstructure:"overrides"`
+}
+
+// Override sets the minimum reporting interval of the series of the metrics matching any of the regular expressions
+type Override struct {
+	// Metrics are the regular expressions matching the names of the metrics
+	Metrics []string `mapstructure:"metrics"`
+	// MinReportInterval is the minimum time between the data points of a series which are passed through,
+	// the metrics are never thinned when it's 0
+	MinReportInterval time.Duration `mapstructure:"min_report_interval"`
+}
+
+const (
+	defaultWindow      = 5 * time.Minute
+	defaultReportEvery = 10
+	defaultMaxSeries   = 100000
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	for _, metric := range cfg.Metrics {
+		if _, err := regexp.Compile(metric); err != nil {
+			return fmt.Errorf("invalid metrics regex %q: %w", metric, err)
+		}
+	}
+	if cfg.Epsilon < 0 || math.IsNaN(cfg.Epsilon) {
+		return fmt.Errorf("epsilon must not be negative, got %v", cfg.Epsilon)
+	}
+	if cfg.Window <= 0 {
+		return fmt.Errorf("window must be positive, got %s", cfg.Window)
+	}
+	if cfg.ReportEvery <= 0 {
+		return fmt.Errorf("report_every must be positive, got %d", cfg.ReportEvery)
+	}
+	if cfg.MaxSeries <= 0 {
+		return fmt.Errorf("max_series must be positive, got %d", cfg.MaxSeries)
+	}
+	for i, override := range cfg.Overrides {
+		if len(override.Metrics) == 0 {
+			return fmt.Errorf("override %d: metrics must not be empty", i)
+		}
+		for _, metric := range override.Metrics {
+			if _, err := regexp.Compile(metric); err != nil {
+				return fmt.Errorf("override %d: invalid metrics regex %q: %w", i, metric, err)
+			}
+		}
+		if override.MinReportInterval < 0 {
+			return fmt.Errorf("override %d: min_report_interval must not be negative, got %s", i, override.MinReportInterval)
+		}
+	}
+	return nil
+}
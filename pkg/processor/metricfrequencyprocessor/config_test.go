@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricfrequencyprocessor
+
+import (
+	"math"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "metric_frequency_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			Metrics:           []string{`^k8s\.node\..*`, "^kube_.*_capacity$"},
+			Epsilon:           0.5,
+			Window:            10 * time.Minute,
+			ReportEvery:       5,
+			MaxSeries:         defaultMaxSeries,
+			Overrides: []Override{
+				{Metrics: []string{"^up$", "^kube_node_status_condition$"}},
+				{Metrics: []string{"^container_fs_.*"}, MinReportInterval: 5 * time.Minute},
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{name: "invalid metrics regex", modify: func(cfg *Config) { cfg.Metrics = []string{"k8s_("} }},
+		{name: "negative epsilon", modify: func(cfg *Config) { cfg.Epsilon = -1 }},
+		{name: "nan epsilon", modify: func(cfg *Config) { cfg.Epsilon = math.NaN() }},
+		{name: "no window", modify: func(cfg *Config) { cfg.Window = 0 }},
+		{name: "no report_every", modify: func(cfg *Config) { cfg.ReportEvery = 0 }},
+		{name: "no max_series", modify: func(cfg *Config) { cfg.MaxSeries = 0 }},
+		{name: "override without metrics", modify: func(cfg *Config) {
+			cfg.Overrides = []Override{{MinReportInterval: time.Minute}}
+		}},
+		{name: "invalid override regex", modify: func(cfg *Config) {
+			cfg.Overrides = []Override{{Metrics: []string{"up("}}}
+		}},
+		{name: "negative min_report_interval", modify: func(cfg *Config) {
+			cfg.Overrides = []Override{{Metrics: []string{"^up$"}, MinReportInterval: -time.Minute}}
+		}},
+	}
+
+	assert.NoError(t, createDefaultConfig().(*Config).Validate())
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricfrequencyprocessor
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/attrhash"
+)
+
+// metricFrequencyProcessor reduces the reporting frequency of the series of the gauges and the cumulative
+// sums whose values haven't changed beyond epsilon over the window, passing 1 in N of their data points.
+// The series of the metrics matching an override are passed at most once per its minimum reporting interval instead.
+type metricFrequencyProcessor struct {
+	logger      *zap.Logger
+	metrics     []*regexp.Regexp
+	epsilon     float64
+	window      time.Duration
+	reportEvery int
+	maxSeries   int
+	overrides   []override
+	// seriesTTL is the time after which a series which has not been received is forgotten
+	seriesTTL time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	series    map[uint64]*series
+	lastSweep time.Time
+	// limitLogged is set once the series limit has been reported, until the number of the series drops
+	limitLogged bool
+}
+
+// series is the state of a tracked series
+type series struct {
+	// reference is the value the following values are compared with, until one of them changes beyond epsilon
+	reference float64
+	// unchangedSince is the time the value of the series has been within epsilon of the reference since
+	unchangedSince time.Time
+	// constantPoints is the number of the data points received since the series became constant
+	constantPoints int
+	// lastReported is the time the last data point of the series was passed through with the minimum reporting interval
+	lastReported time.Time
+	lastSeen     time.Time
+}
+
+// override is the minimum reporting interval of the matching metrics
+type override struct {
+	metrics           []*regexp.Regexp
+	minReportInterval time.Duration
+}
+
+func newMetricFrequencyProcessor(logger *zap.Logger, cfg *Config) (*metricFrequencyProcessor, error) {
+	mfp := &metricFrequencyProcessor{
+		logger:      logger,
+		epsilon:     cfg.Epsilon,
+		window:      cfg.Window,
+		reportEvery: cfg.ReportEvery,
+		maxSeries:   cfg.MaxSeries,
+		now:         time.Now,
+		series:      map[uint64]*series{},
+	}
+	var err error
+	if mfp.metrics, err = compileRegexes(cfg.Metrics); err != nil {
+		return nil, err
+	}
+	// The series are kept for long enough to tell if they are constant or if their interval has passed
+	maxInterval := cfg.Window
+	for _, o := range cfg.Overrides {
+		regexes, err := compileRegexes(o.Metrics)
+		if err != nil {
+			return nil, err
+		}
+		mfp.overrides = append(mfp.overrides, override{metrics: regexes, minReportInterval: o.MinReportInterval})
+		if o.MinReportInterval > maxInterval {
+			maxInterval = o.MinReportInterval
+		}
+	}
+	mfp.seriesTTL = 2 * maxInterval
+	return mfp, nil
+}
+
+func compileRegexes(exprs []string) ([]*regexp.Regexp, error) {
+	var regexes []*regexp.Regexp
+	for _, expr := range exprs {
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		regexes = append(regexes, regex)
+	}
+	return regexes, nil
+}
+
+// ProcessMetrics drops the data points of the constant series, except for 1 in N of them,
+// and the data points of the overridden metrics reported more often than their minimum reporting interval
+func (mfp *metricFrequencyProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	now := mfp.now()
+
+	mfp.mu.Lock()
+	defer mfp.mu.Unlock()
+	mfp.sweep(now)
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resourceKey := attrhash.Attributes(fnv.New64a(), rm.Resource().Attributes())
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			ilm := ilms.At(j)
+			metrics := ilm.Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				if minReportInterval, ok := mfp.override(metric.Name()); ok {
+					if minReportInterval > 0 {
+						mfp.sieve(metric, resourceKey, ilm.InstrumentationLibrary(), minReportInterval, now)
+					}
+				} else if mfp.match(metric.Name()) {
+					mfp.sieve(metric, resourceKey, ilm.InstrumentationLibrary(), 0, now)
+				}
+			}
+			metrics.RemoveIf(func(metric pdata.Metric) bool {
+				return dataPointCount(metric) == 0
+			})
+		}
+		ilms.RemoveIf(func(ilm pdata.InstrumentationLibraryMetrics) bool {
+			return ilm.Metrics().Len() == 0
+		})
+	}
+	rms.RemoveIf(func(rm pdata.ResourceMetrics) bool {
+		return rm.InstrumentationLibraryMetrics().Len() == 0
+	})
+
+	if rms.Len() == 0 {
+		return md, processorhelper.ErrSkipProcessingData
+	}
+	return md, nil
+}
+
+func (mfp *metricFrequencyProcessor) match(name string) bool {
+	return len(mfp.metrics) == 0 || matchAny(mfp.metrics, name)
+}
+
+// override returns the minimum reporting interval set by the first override matching the metric
+func (mfp *metricFrequencyProcessor) override(name string) (time.Duration, bool) {
+	for _, o := range mfp.overrides {
+		if matchAny(o.metrics, name) {
+			return o.minReportInterval, true
+		}
+	}
+	return 0, false
+}
+
+func matchAny(regexes []*regexp.Regexp, name string) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sieve removes the data points of the constant series, or the ones within the minimum reporting interval
+// when it's set. The metrics other than the gauges and the cumulative sums are passed through,
+// as dropping a delta would lose its value.
+func (mfp *metricFrequencyProcessor) sieve(
+	metric pdata.Metric,
+	resourceKey uint64,
+	library pdata.InstrumentationLibrary,
+	minReportInterval time.Duration,
+	now time.Time,
+) {
+	var dps pdata.NumberDataPointSlice
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps = metric.Gauge().DataPoints()
+	case pdata.MetricDataTypeSum:
+		if metric.Sum().AggregationTemporality() != pdata.AggregationTemporalityCumulative {
+			return
+		}
+		dps = metric.Sum().DataPoints()
+	default:
+		return
+	}
+
+	dps.RemoveIf(func(dp pdata.NumberDataPoint) bool {
+		h := fnv.New64a()
+		h.Write([]byte(metric.Name()))
+		h.Write([]byte{0})
+		h.Write([]byte(library.Name()))
+		h.Write([]byte{0})
+		h.Write([]byte(library.Version()))
+		h.Write([]byte{0})
+		attrhash.WriteUint64(h, resourceKey)
+		key := attrhash.Attributes(h, dp.Attributes())
+		return !mfp.pass(key, value(dp), minReportInterval, now)
+	})
+}
+
+// pass records the value of the series and returns true if its data point is passed through
+func (mfp *metricFrequencyProcessor) pass(key uint64, value float64, minReportInterval time.Duration, now time.Time) bool {
+	s, ok := mfp.series[key]
+	if !ok {
+		if len(mfp.series) >= mfp.maxSeries {
+			if !mfp.limitLogged {
+				mfp.logger.Warn("Maximum number of the tracked series reached, the new series are passed through",
+					zap.Int("max_series", mfp.maxSeries))
+				mfp.limitLogged = true
+			}
+			return true
+		}
+		mfp.series[key] = &series{reference: value, unchangedSince: now, lastReported: now, lastSeen: now}
+		return true
+	}
+	s.lastSeen = now
+
+	if minReportInterval > 0 {
+		if now.Sub(s.lastReported) < minReportInterval {
+			return false
+		}
+		s.lastReported = now
+		return true
+	}
+
+	// NaN, e.g. the staleness marker, is never considered unchanged
+	if !(math.Abs(value-s.reference) <= mfp.epsilon) {
+		s.reference = value
+		s.unchangedSince = now
+		s.constantPoints = 0
+		return true
+	}
+	if now.Sub(s.unchangedSince) < mfp.window {
+		return true
+	}
+	pass := s.constantPoints%mfp.reportEvery == 0
+	s.constantPoints++
+	return pass
+}
+
+// sweep forgets the series which haven't been received for the series TTL
+func (mfp *metricFrequencyProcessor) sweep(now time.Time) {
+	if now.Sub(mfp.lastSweep) < mfp.seriesTTL/2 {
+		return
+	}
+	mfp.lastSweep = now
+
+	for key, s := range mfp.series {
+		if now.Sub(s.lastSeen) > mfp.seriesTTL {
+			delete(mfp.series, key)
+		}
+	}
+	if len(mfp.series) < mfp.maxSeries {
+		mfp.limitLogged = false
+	}
+}
+
+func value(dp pdata.NumberDataPoint) float64 {
+	if dp.Type() == pdata.MetricValueTypeInt {
+		return float64(dp.IntVal())
+	}
+	return dp.DoubleVal()
+}
+
+func dataPointCount(metric pdata.Metric) int {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		return metric.Gauge().DataPoints().Len()
+	case pdata.MetricDataTypeSum:
+		return metric.Sum().DataPoints().Len()
+	case pdata.MetricDataTypeHistogram:
+		return metric.Histogram().DataPoints().Len()
+	case pdata.MetricDataTypeSummary:
+		return metric.Summary().DataPoints().Len()
+	}
+	// The metrics without a type are left as they are
+	return 1
+}
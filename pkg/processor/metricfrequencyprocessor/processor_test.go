@@ -0,0 +1,269 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricfrequencyprocessor
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) (*metricFrequencyProcessor, *time.Time) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Window = time.Minute
+	cfg.ReportEvery = 3
+	if modify != nil {
+		modify(cfg)
+	}
+	mfp, err := newMetricFrequencyProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	now := time.Unix(1600000000, 0)
+	mfp.now = func() time.Time {
+		return now
+	}
+	return mfp, &now
+}
+
+// newGauge builds a gauge with a data point for each of the hosts, with the values in the same order
+func newGauge(name string, hosts []string, values ...float64) pdata.Metrics {
+	md := pdata.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("k8s.cluster.name", "prod")
+	metric := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pdata.MetricDataTypeGauge)
+	for i, host := range hosts {
+		dp := metric.Gauge().DataPoints().AppendEmpty()
+		dp.Attributes().InsertString("host", host)
+		dp.SetDoubleVal(values[i])
+	}
+	return md
+}
+
+// process processes the metrics at the given offset from the start and returns the hosts of the passed data points
+func process(t *testing.T, mfp *metricFrequencyProcessor, now *time.Time, start time.Time, offset time.Duration, md pdata.Metrics) []string {
+	*now = start.Add(offset)
+	md, err := mfp.ProcessMetrics(context.Background(), md)
+	if err == processorhelper.ErrSkipProcessingData {
+		return nil
+	}
+	require.NoError(t, err)
+
+	var hosts []string
+	dps := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	for i := 0; i < dps.Len(); i++ {
+		host, _ := dps.At(i).Attributes().Get("host")
+		hosts = append(hosts, host.StringVal())
+	}
+	return hosts
+}
+
+func TestConstantSeriesReportedOneInN(t *testing.T) {
+	mfp, now := newTestProcessor(t, nil)
+	start := *now
+
+	// The changing series is passed through, the constant one only every third time once the window passes
+	var passed [][]string
+	for i := 0; i < 9; i++ {
+		md := newGauge("node_memory", []string{"changing", "constant"}, float64(i), 100)
+		passed = append(passed, process(t, mfp, now, start, time.Duration(i)*30*time.Second, md))
+	}
+	assert.Equal(t, [][]string{
+		{"changing", "constant"},
+		{"changing", "constant"},
+		{"changing", "constant"},
+		{"changing"},
+		{"changing"},
+		{"changing", "constant"},
+		{"changing"},
+		{"changing"},
+		{"changing", "constant"},
+	}, passed)
+
+	// A change passes the series through again, until it is constant for the window
+	md := newGauge("node_memory", []string{"constant"}, 200)
+	assert.Equal(t, []string{"constant"}, process(t, mfp, now, start, 270*time.Second, md))
+	md = newGauge("node_memory", []string{"constant"}, 200)
+	assert.Equal(t, []string{"constant"}, process(t, mfp, now, start, 300*time.Second, md))
+}
+
+func TestEpsilon(t *testing.T) {
+	mfp, now := newTestProcessor(t, func(cfg *Config) {
+		cfg.Epsilon = 1
+	})
+	start := *now
+
+	// The small changes don't reset the window, while a drift beyond epsilon from the reference does
+	assert.Equal(t, []string{"host"}, process(t, mfp, now, start, 0, newGauge("load", []string{"host"}, 10)))
+	assert.Equal(t, []string{"host"}, process(t, mfp, now, start, 30*time.Second, newGauge("load", []string{"host"}, 10.5)))
+	assert.Equal(t, []string{"host"}, process(t, mfp, now, start, 60*time.Second, newGauge("load", []string{"host"}, 9.5)))
+	assert.Nil(t, process(t, mfp, now, start, 90*time.Second, newGauge("load", []string{"host"}, 10.9)))
+	assert.Equal(t, []string{"host"}, process(t, mfp, now, start, 120*time.Second, newGauge("load", []string{"host"}, 11.1)))
+	assert.Equal(t, []string{"host"}, process(t, mfp, now, start, 150*time.Second, newGauge("load", []string{"host"}, 11.1)))
+}
+
+func TestNaNNeverConstant(t *testing.T) {
+	mfp, now := newTestProcessor(t, nil)
+	start := *now
+
+	for i := 0; i < 6; i++ {
+		md := newGauge("up", []string{"host"}, math.NaN())
+		assert.Equal(t, []string{"host"}, process(t, mfp, now, start, time.Duration(i)*30*time.Second, md))
+	}
+}
+
+func TestMetricsFilter(t *testing.T) {
+	mfp, now := newTestProcessor(t, func(cfg *Config) {
+		cfg.Metrics = []string{"^node_.*"}
+	})
+	start := *now
+
+	for i := 0; i < 6; i++ {
+		md := newGauge("pod_memory", []string{"host"}, 100)
+		assert.Equal(t, []string{"host"}, process(t, mfp, now, start, time.Duration(i)*30*time.Second, md))
+	}
+}
+
+func TestDeltaSumsAndHistogramsPassedThrough(t *testing.T) {
+	mfp, now := newTestProcessor(t, nil)
+
+	md := pdata.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	delta := metrics.AppendEmpty()
+	delta.SetName("requests")
+	delta.SetDataType(pdata.MetricDataTypeSum)
+	delta.Sum().SetAggregationTemporality(pdata.AggregationTemporalityDelta)
+	delta.Sum().DataPoints().AppendEmpty().SetIntVal(1)
+	histogram := metrics.AppendEmpty()
+	histogram.SetName("latency")
+	histogram.SetDataType(pdata.MetricDataTypeHistogram)
+	histogram.Histogram().DataPoints().AppendEmpty().SetCount(1)
+	cumulative := metrics.AppendEmpty()
+	cumulative.SetName("restarts")
+	cumulative.SetDataType(pdata.MetricDataTypeSum)
+	cumulative.Sum().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+	cumulative.Sum().DataPoints().AppendEmpty().SetIntVal(3)
+
+	start := *now
+	for i := 0; i < 4; i++ {
+		*now = start.Add(time.Duration(i) * 30 * time.Second)
+		result, err := mfp.ProcessMetrics(context.Background(), md.Clone())
+		require.NoError(t, err)
+		var names []string
+		resultMetrics := result.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+		for j := 0; j < resultMetrics.Len(); j++ {
+			names = append(names, resultMetrics.At(j).Name())
+		}
+		// The constant cumulative sum is thinned like a gauge
+		if i == 3 {
+			assert.Equal(t, []string{"requests", "latency"}, names)
+		} else {
+			assert.Equal(t, []string{"requests", "latency", "restarts"}, names)
+		}
+	}
+}
+
+func TestEmptyBatchSkipped(t *testing.T) {
+	mfp, now := newTestProcessor(t, nil)
+	start := *now
+
+	for i := 0; i < 3; i++ {
+		process(t, mfp, now, start, time.Duration(i)*30*time.Second, newGauge("capacity", []string{"host"}, 100))
+	}
+	*now = start.Add(90 * time.Second)
+	_, err := mfp.ProcessMetrics(context.Background(), newGauge("capacity", []string{"host"}, 100))
+	assert.Equal(t, processorhelper.ErrSkipProcessingData, err)
+}
+
+func TestMaxSeries(t *testing.T) {
+	mfp, now := newTestProcessor(t, func(cfg *Config) {
+		cfg.MaxSeries = 1
+	})
+	start := *now
+
+	// The series above the limit are not tracked, so they are never thinned
+	for i := 0; i < 4; i++ {
+		md := newGauge("capacity", []string{"tracked", "untracked"}, 100, 100)
+		passed := process(t, mfp, now, start, time.Duration(i)*30*time.Second, md)
+		assert.Contains(t, passed, "untracked")
+	}
+	assert.Len(t, mfp.series, 1)
+}
+
+func TestSeriesForgotten(t *testing.T) {
+	mfp, now := newTestProcessor(t, nil)
+	start := *now
+
+	process(t, mfp, now, start, 0, newGauge("capacity", []string{"old"}, 100))
+	process(t, mfp, now, start, 3*time.Minute, newGauge("capacity", []string{"new"}, 100))
+	assert.Len(t, mfp.series, 1)
+
+	// The forgotten series starts over
+	assert.Equal(t, []string{"old"}, process(t, mfp, now, start, 3*time.Minute, newGauge("capacity", []string{"old"}, 100)))
+	assert.Equal(t, []string{"old"}, process(t, mfp, now, start, 3*time.Minute+30*time.Second, newGauge("capacity", []string{"old"}, 100)))
+}
+
+func TestOverrides(t *testing.T) {
+	mfp, now := newTestProcessor(t, func(cfg *Config) {
+		cfg.Metrics = []string{"^node_.*"}
+		cfg.Overrides = []Override{
+			{Metrics: []string{"^node_critical$"}},
+			{Metrics: []string{"^node_.*", "^pod_noisy$"}, MinReportInterval: 2 * time.Minute},
+		}
+	})
+	start := *now
+
+	// The critical metric is never thinned, while the noisy ones are passed once per the interval,
+	// whether they change or not and whether they match the sieved metrics or not
+	var passed [][]string
+	for i := 0; i < 9; i++ {
+		offset := time.Duration(i) * 30 * time.Second
+		critical := process(t, mfp, now, start, offset, newGauge("node_critical", []string{"host"}, 100))
+		noisy := process(t, mfp, now, start, offset, newGauge("node_noisy", []string{"host"}, float64(i)))
+		pod := process(t, mfp, now, start, offset, newGauge("pod_noisy", []string{"host"}, 100))
+		passed = append(passed, []string{strings.Join(critical, ","), strings.Join(noisy, ","), strings.Join(pod, ",")})
+	}
+	assert.Equal(t, [][]string{
+		{"host", "host", "host"},
+		{"host", "", ""},
+		{"host", "", ""},
+		{"host", "", ""},
+		{"host", "host", "host"},
+		{"host", "", ""},
+		{"host", "", ""},
+		{"host", "", ""},
+		{"host", "host", "host"},
+	}, passed)
+}
+
+func TestSeriesKeptForMinReportInterval(t *testing.T) {
+	mfp, now := newTestProcessor(t, func(cfg *Config) {
+		cfg.Overrides = []Override{{Metrics: []string{"^pod_noisy$"}, MinReportInterval: 10 * time.Minute}}
+	})
+	start := *now
+
+	// The series reported less often than twice the window is still within its interval
+	assert.Equal(t, []string{"host"}, process(t, mfp, now, start, 0, newGauge("pod_noisy", []string{"host"}, 1)))
+	assert.Nil(t, process(t, mfp, now, start, 5*time.Minute, newGauge("pod_noisy", []string{"host"}, 2)))
+	assert.Equal(t, []string{"host"}, process(t, mfp, now, start, 10*time.Minute, newGauge("pod_noisy", []string{"host"}, 3)))
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsrollupprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the metrics rollup.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Interval is the period over which the data points are aggregated
+	Interval time.Duration `mapstructure:"interval"`
+	// Rules are evaluated in the order as specified, the first one matching the metric name aggregates it.
+	// The metrics not matched by any rule are passed through.
+	Rules []RuleConfig `mapstructure:"rules"`
+}
+
+// RuleConfig holds the configuration of a single rollup rule.
+type RuleConfig struct {
+	// Metrics are the regular expressions matching the names of the aggregated metrics
+	Metrics []string `mapstructure:"metrics"`
+	// KeepLabels are the resource attributes and labels the data points are grouped by, all the other ones are dropped
+	KeepLabels []string `mapstructure:"keep_labels"`
+	// DropLabels are the resource attributes and labels which are dropped, all the other ones are kept
+	DropLabels []string `mapstructure:"drop_labels"`
+	// Functions aggregate the gauge values: sum, avg, min, max, count or a percentile, e.g. p90
+	Functions []string `mapstructure:"functions"`
+}
+
+const (
+	defaultInterval = 60 * time.Second
+	defaultFunction = functionAvg
+)
+
+const (
+	functionSum   = "sum"
+	functionAvg   = "avg"
+	functionMin   = "min"
+	functionMax   = "max"
+	functionCount = "count"
+)
+
+var percentileRegex = regexp.MustCompile(`^p(\d{1,2}(\.\d+)?)$`)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", cfg.Interval)
+	}
+	if len(cfg.Rules) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+	for i, rule := range cfg.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (rule *RuleConfig) validate() error {
+	if len(rule.Metrics) == 0 {
+		return fmt.Errorf("metrics must not be empty")
+	}
+	for _, metric := range rule.Metrics {
+		if _, err := regexp.Compile(metric); err != nil {
+			return fmt.Errorf("invalid metrics regex %q: %w", metric, err)
+		}
+	}
+	if len(rule.KeepLabels) > 0 && len(rule.DropLabels) > 0 {
+		return fmt.Errorf("keep_labels and drop_labels cannot be both set")
+	}
+	for _, function := range rule.Functions {
+		if _, err := parseFunction(function); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// function aggregates the values of a gauge
+type function struct {
+	name string
+	// percentile is set for the percentile functions, in the range of 0 to 100
+	percentile float64
+}
+
+func parseFunction(name string) (function, error) {
+	switch name {
+	case functionSum, functionAvg, functionMin, functionMax, functionCount:
+		return function{name: name}, nil
+	}
+	if match := percentileRegex.FindStringSubmatch(name); match != nil {
+		percentile, err := strconv.ParseFloat(match[1], 64)
+		if err == nil {
+			return function{name: name, percentile: percentile}, nil
+		}
+	}
+	return function{}, fmt.Errorf("unknown function %q, expected one of: %s or a percentile, e.g. p90",
+		name, strings.Join([]string{functionSum, functionAvg, functionMin, functionMax, functionCount}, ", "))
+}
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsrollupprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "metrics_rollup_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			Interval:          30 * time.Second,
+			Rules: []RuleConfig{
+				{
+					Metrics:    []string{"^container_cpu_.*", "^container_memory_.*"},
+					KeepLabels: []string{"k8s.namespace.name", "k8s.deployment.name"},
+					Functions:  []string{"avg", "max", "p95"},
+				},
+				{
+					Metrics:    []string{"^http_requests_total$"},
+					DropLabels: []string{"k8s.pod.name"},
+				},
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	validConfig := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Rules = []RuleConfig{{Metrics: []string{"^cpu$"}, Functions: []string{"sum", "p99.9"}}}
+		return cfg
+	}
+	assert.NoError(t, validConfig().Validate())
+
+	assert.Error(t, createDefaultConfig().(*Config).Validate())
+
+	cfg := validConfig()
+	cfg.Interval = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Rules[0].Metrics = nil
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Rules[0].Metrics = []string{"cpu("}
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Rules[0].KeepLabels = []string{"host"}
+	cfg.Rules[0].DropLabels = []string{"pod"}
+	assert.Error(t, cfg.Validate())
+
+	for _, function := range []string{"median", "p100", "p", "p-1"} {
+		cfg = validConfig()
+		cfg.Rules[0].Functions = []string{function}
+		assert.Error(t, cfg.Validate(), function)
+	}
+}
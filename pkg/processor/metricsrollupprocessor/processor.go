@@ -0,0 +1,428 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsrollupprocessor
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/attrhash"
+)
+
+// metricsRollupProcessor aggregates the data points of the matching metrics over the configured labels
+// and interval, and passes the other metrics through
+type metricsRollupProcessor struct {
+	logger       *zap.Logger
+	nextConsumer consumer.Metrics
+	interval     time.Duration
+	rules        []*rule
+	now          func() time.Time
+
+	mu          sync.Mutex
+	rollups     map[uint64]*rollup
+	windowStart pdata.Timestamp
+
+	started bool
+	stopCh  chan struct{}
+	done    chan struct{}
+}
+
+// rule is the compiled RuleConfig
+type rule struct {
+	metrics    []*regexp.Regexp
+	keepLabels map[string]bool
+	dropLabels map[string]bool
+	functions  []function
+}
+
+// rollup aggregates the data points of a metric with the same kept resource attributes and labels
+type rollup struct {
+	rule        *rule
+	resourceKey uint64
+	resource    pdata.AttributeMap
+	attributes  pdata.AttributeMap
+
+	name        string
+	description string
+	unit        string
+	dataType    pdata.MetricDataType
+	temporality pdata.AggregationTemporality
+	monotonic   bool
+
+	// values are the gauge values
+	values []float64
+	// sum is the total of the delta sums
+	sum float64
+	// latest are the last values of the cumulative sums, by their series
+	latest         map[uint64]pdata.NumberDataPoint
+	startTimestamp pdata.Timestamp
+}
+
+var _ component.MetricsProcessor = (*metricsRollupProcessor)(nil)
+
+func newMetricsRollupProcessor(logger *zap.Logger, cfg *Config, nextConsumer consumer.Metrics) (*metricsRollupProcessor, error) {
+	p := &metricsRollupProcessor{
+		logger:       logger,
+		nextConsumer: nextConsumer,
+		interval:     cfg.Interval,
+		now:          time.Now,
+		rollups:      map[uint64]*rollup{},
+		stopCh:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	p.windowStart = pdata.TimestampFromTime(p.now())
+
+	for _, ruleCfg := range cfg.Rules {
+		r := &rule{}
+		for _, metric := range ruleCfg.Metrics {
+			regex, err := regexp.Compile(metric)
+			if err != nil {
+				return nil, err
+			}
+			r.metrics = append(r.metrics, regex)
+		}
+		if len(ruleCfg.KeepLabels) > 0 {
+			r.keepLabels = toSet(ruleCfg.KeepLabels)
+		} else {
+			r.dropLabels = toSet(ruleCfg.DropLabels)
+		}
+		functions := ruleCfg.Functions
+		if len(functions) == 0 {
+			functions = []string{defaultFunction}
+		}
+		for _, name := range functions {
+			f, err := parseFunction(name)
+			if err != nil {
+				return nil, err
+			}
+			r.functions = append(r.functions, f)
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// Start starts emitting the aggregated data points at the end of each interval
+func (p *metricsRollupProcessor) Start(context.Context, component.Host) error {
+	p.started = true
+	go p.flushLoop()
+	return nil
+}
+
+// Shutdown emits the data points aggregated in the current interval
+func (p *metricsRollupProcessor) Shutdown(ctx context.Context) error {
+	if !p.started {
+		p.flush(ctx)
+		return nil
+	}
+	close(p.stopCh)
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Capabilities returns the consumer capabilities
+func (p *metricsRollupProcessor) Capabilities() consumer.Capabilities {
+	return processorCapabilities
+}
+
+// ConsumeMetrics takes the data points of the matching gauges and sums for the aggregation and passes
+// the other metrics through
+func (p *metricsRollupProcessor) ConsumeMetrics(ctx context.Context, md pdata.Metrics) error {
+	p.mu.Lock()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			ilms.At(j).Metrics().RemoveIf(func(metric pdata.Metric) bool {
+				r := p.match(metric.Name())
+				if r == nil {
+					return false
+				}
+				return p.add(r, rm.Resource(), metric)
+			})
+		}
+		ilms.RemoveIf(func(ilm pdata.InstrumentationLibraryMetrics) bool {
+			return ilm.Metrics().Len() == 0
+		})
+	}
+	p.mu.Unlock()
+
+	rms.RemoveIf(func(rm pdata.ResourceMetrics) bool {
+		return rm.InstrumentationLibraryMetrics().Len() == 0
+	})
+	if rms.Len() == 0 {
+		return nil
+	}
+	return p.nextConsumer.ConsumeMetrics(ctx, md)
+}
+
+func (p *metricsRollupProcessor) match(name string) *rule {
+	for _, r := range p.rules {
+		for _, regex := range r.metrics {
+			if regex.MatchString(name) {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// add aggregates the data points of the gauge or sum, the other metrics are not aggregated
+func (p *metricsRollupProcessor) add(r *rule, resource pdata.Resource, metric pdata.Metric) bool {
+	var dps pdata.NumberDataPointSlice
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps = metric.Gauge().DataPoints()
+	case pdata.MetricDataTypeSum:
+		dps = metric.Sum().DataPoints()
+	default:
+		return false
+	}
+
+	keptResource := r.filter(resource.Attributes())
+	resourceKey := attrhash.Attributes(fnv.New64a(), keptResource)
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		attributes := r.filter(dp.Attributes())
+
+		h := fnv.New64a()
+		h.Write([]byte(metric.Name()))
+		h.Write([]byte{0})
+		attrhash.WriteUint64(h, resourceKey)
+		key := attrhash.Attributes(h, attributes)
+
+		ru, ok := p.rollups[key]
+		if !ok {
+			ru = &rollup{
+				rule:        r,
+				resourceKey: resourceKey,
+				resource:    keptResource,
+				attributes:  attributes,
+				name:        metric.Name(),
+				description: metric.Description(),
+				unit:        metric.Unit(),
+				dataType:    metric.DataType(),
+				latest:      map[uint64]pdata.NumberDataPoint{},
+			}
+			if metric.DataType() == pdata.MetricDataTypeSum {
+				ru.temporality = metric.Sum().AggregationTemporality()
+				ru.monotonic = metric.Sum().IsMonotonic()
+			}
+			p.rollups[key] = ru
+		}
+		ru.add(resource.Attributes(), dp)
+	}
+	return true
+}
+
+func (ru *rollup) add(resource pdata.AttributeMap, dp pdata.NumberDataPoint) {
+	switch {
+	case ru.dataType == pdata.MetricDataTypeGauge:
+		ru.values = append(ru.values, numberValue(dp))
+	case ru.temporality == pdata.AggregationTemporalityCumulative:
+		// The cumulative values of a series are not added up, only its latest value counts
+		h := fnv.New64a()
+		attrhash.WriteUint64(h, attrhash.Attributes(fnv.New64a(), resource))
+		series := attrhash.Attributes(h, dp.Attributes())
+		if latest, ok := ru.latest[series]; !ok || dp.Timestamp() >= latest.Timestamp() {
+			ru.latest[series] = dp
+		}
+		if ru.startTimestamp == 0 || dp.StartTimestamp() < ru.startTimestamp {
+			ru.startTimestamp = dp.StartTimestamp()
+		}
+	default:
+		ru.sum += numberValue(dp)
+	}
+}
+
+// filter returns the attributes which are kept by the rule
+func (r *rule) filter(attributes pdata.AttributeMap) pdata.AttributeMap {
+	kept := pdata.NewAttributeMap()
+	attributes.Range(func(name string, value pdata.AttributeValue) bool {
+		if (r.keepLabels != nil && r.keepLabels[name]) || (r.keepLabels == nil && !r.dropLabels[name]) {
+			kept.Insert(name, value)
+		}
+		return true
+	})
+	return kept
+}
+
+func (p *metricsRollupProcessor) flushLoop() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.flush(context.Background())
+		case <-p.stopCh:
+			p.flush(context.Background())
+			return
+		}
+	}
+}
+
+// flush emits the data points aggregated in the interval and starts the next one
+func (p *metricsRollupProcessor) flush(ctx context.Context) {
+	now := pdata.TimestampFromTime(p.now())
+
+	p.mu.Lock()
+	rollups := p.rollups
+	windowStart := p.windowStart
+	p.rollups = make(map[uint64]*rollup, len(rollups))
+	p.windowStart = now
+	p.mu.Unlock()
+
+	if len(rollups) == 0 {
+		return
+	}
+
+	md := pdata.NewMetrics()
+	resources := map[uint64]pdata.MetricSlice{}
+	for _, ru := range rollups {
+		metrics, ok := resources[ru.resourceKey]
+		if !ok {
+			rm := md.ResourceMetrics().AppendEmpty()
+			ru.resource.CopyTo(rm.Resource().Attributes())
+			metrics = rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+			resources[ru.resourceKey] = metrics
+		}
+		ru.appendMetrics(metrics, windowStart, now)
+	}
+
+	if err := p.nextConsumer.ConsumeMetrics(ctx, md); err != nil {
+		p.logger.Error("Failed to send the aggregated metrics", zap.Error(err))
+	}
+}
+
+// appendMetrics adds a gauge for each of the functions or the sum
+func (ru *rollup) appendMetrics(metrics pdata.MetricSlice, windowStart, now pdata.Timestamp) {
+	if ru.dataType == pdata.MetricDataTypeGauge {
+		for _, f := range ru.rule.functions {
+			metric := ru.appendMetric(metrics, f)
+			metric.SetDataType(pdata.MetricDataTypeGauge)
+			dp := metric.Gauge().DataPoints().AppendEmpty()
+			ru.attributes.CopyTo(dp.Attributes())
+			dp.SetTimestamp(now)
+			dp.SetDoubleVal(f.apply(ru.values))
+		}
+		return
+	}
+
+	metric := ru.appendMetric(metrics, function{})
+	metric.SetDataType(pdata.MetricDataTypeSum)
+	metric.Sum().SetAggregationTemporality(ru.temporality)
+	metric.Sum().SetIsMonotonic(ru.monotonic)
+	dp := metric.Sum().DataPoints().AppendEmpty()
+	ru.attributes.CopyTo(dp.Attributes())
+	dp.SetTimestamp(now)
+	if ru.temporality == pdata.AggregationTemporalityCumulative {
+		total := 0.0
+		for _, latest := range ru.latest {
+			total += numberValue(latest)
+		}
+		dp.SetStartTimestamp(ru.startTimestamp)
+		dp.SetDoubleVal(total)
+	} else {
+		dp.SetStartTimestamp(windowStart)
+		dp.SetDoubleVal(ru.sum)
+	}
+}
+
+// appendMetric adds the metric named after the function, unless it is the only one
+func (ru *rollup) appendMetric(metrics pdata.MetricSlice, f function) pdata.Metric {
+	metric := metrics.AppendEmpty()
+	name := ru.name
+	if f.name != "" && len(ru.rule.functions) > 1 {
+		name += "_" + f.name
+	}
+	metric.SetName(name)
+	metric.SetDescription(ru.description)
+	metric.SetUnit(ru.unit)
+	return metric
+}
+
+// apply computes the function of the values, which are not empty
+func (f function) apply(values []float64) float64 {
+	switch f.name {
+	case functionSum:
+		return sum(values)
+	case functionAvg:
+		return sum(values) / float64(len(values))
+	case functionMin:
+		min := math.Inf(1)
+		for _, v := range values {
+			min = math.Min(min, v)
+		}
+		return min
+	case functionMax:
+		max := math.Inf(-1)
+		for _, v := range values {
+			max = math.Max(max, v)
+		}
+		return max
+	case functionCount:
+		return float64(len(values))
+	}
+	return percentile(values, f.percentile)
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// percentile interpolates linearly between the closest ranks
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func numberValue(dp pdata.NumberDataPoint) float64 {
+	if dp.Type() == pdata.MetricValueTypeInt {
+		return float64(dp.IntVal())
+	}
+	return dp.DoubleVal()
+}
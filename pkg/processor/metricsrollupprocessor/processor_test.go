@@ -0,0 +1,279 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsrollupprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+type testPoint struct {
+	pod       string
+	namespace string
+	value     float64
+	start     pdata.Timestamp
+	timestamp pdata.Timestamp
+}
+
+func newTestMetrics(name string, dataType pdata.MetricDataType, temporality pdata.AggregationTemporality, points ...testPoint) pdata.Metrics {
+	md := pdata.NewMetrics()
+	for _, point := range points {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().InsertString("k8s.namespace.name", point.namespace)
+		rm.Resource().Attributes().InsertString("k8s.pod.name", point.pod)
+		metric := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName(name)
+		metric.SetUnit("1")
+		metric.SetDataType(dataType)
+
+		var dp pdata.NumberDataPoint
+		switch dataType {
+		case pdata.MetricDataTypeGauge:
+			dp = metric.Gauge().DataPoints().AppendEmpty()
+		case pdata.MetricDataTypeSum:
+			metric.Sum().SetAggregationTemporality(temporality)
+			metric.Sum().SetIsMonotonic(true)
+			dp = metric.Sum().DataPoints().AppendEmpty()
+		case pdata.MetricDataTypeHistogram:
+			metric.Histogram().DataPoints().AppendEmpty().SetCount(1)
+			continue
+		}
+		dp.SetDoubleVal(point.value)
+		dp.SetStartTimestamp(point.start)
+		dp.SetTimestamp(point.timestamp)
+		dp.Attributes().InsertString("container", "app")
+	}
+	return md
+}
+
+func newTestProcessor(t *testing.T, rules ...RuleConfig) (*metricsRollupProcessor, *consumertest.MetricsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Rules = rules
+	sink := &consumertest.MetricsSink{}
+	p, err := newMetricsRollupProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	return p, sink
+}
+
+// collectMetrics returns the metrics by name
+func collectMetrics(md pdata.Metrics) map[string]pdata.Metric {
+	result := map[string]pdata.Metric{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		ilms := rms.At(i).InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				result[metrics.At(k).Name()] = metrics.At(k)
+			}
+		}
+	}
+	return result
+}
+
+func TestRollupGauge(t *testing.T) {
+	p, sink := newTestProcessor(t, RuleConfig{
+		Metrics:    []string{"^cpu_usage$"},
+		KeepLabels: []string{"k8s.namespace.name"},
+		Functions:  []string{"avg", "min", "max", "sum", "count", "p50"},
+	})
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, 0,
+		testPoint{pod: "app-1", namespace: "payments", value: 1},
+		testPoint{pod: "app-2", namespace: "payments", value: 4},
+	)))
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, 0,
+		testPoint{pod: "app-1", namespace: "payments", value: 7},
+	)))
+	assert.Empty(t, sink.AllMetrics())
+
+	p.flush(context.Background())
+	require.Len(t, sink.AllMetrics(), 1)
+	md := sink.AllMetrics()[0]
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	resource := md.ResourceMetrics().At(0).Resource().Attributes()
+	assert.Equal(t, 1, resource.Len())
+	namespace, _ := resource.Get("k8s.namespace.name")
+	assert.Equal(t, "payments", namespace.StringVal())
+
+	metrics := collectMetrics(md)
+	expected := map[string]float64{
+		"cpu_usage_avg":   4,
+		"cpu_usage_min":   1,
+		"cpu_usage_max":   7,
+		"cpu_usage_sum":   12,
+		"cpu_usage_count": 3,
+		"cpu_usage_p50":   4,
+	}
+	require.Len(t, metrics, len(expected))
+	for name, value := range expected {
+		metric, ok := metrics[name]
+		require.True(t, ok, name)
+		assert.Equal(t, pdata.MetricDataTypeGauge, metric.DataType())
+		assert.Equal(t, "1", metric.Unit())
+		dp := metric.Gauge().DataPoints().At(0)
+		assert.Equal(t, value, dp.DoubleVal(), name)
+		assert.Equal(t, 0, dp.Attributes().Len())
+	}
+
+	// A new interval starts after the flush
+	p.flush(context.Background())
+	assert.Len(t, sink.AllMetrics(), 1)
+}
+
+func TestRollupSingleFunction(t *testing.T) {
+	p, sink := newTestProcessor(t, RuleConfig{Metrics: []string{"^cpu_usage$"}, DropLabels: []string{"k8s.pod.name"}})
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, 0,
+		testPoint{pod: "app-1", namespace: "payments", value: 1},
+		testPoint{pod: "app-2", namespace: "payments", value: 3},
+		testPoint{pod: "app-3", namespace: "billing", value: 10},
+	)))
+	p.flush(context.Background())
+
+	require.Len(t, sink.AllMetrics(), 1)
+	md := sink.AllMetrics()[0]
+	require.Equal(t, 2, md.ResourceMetrics().Len())
+	values := map[string]float64{}
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		namespace, _ := rm.Resource().Attributes().Get("k8s.namespace.name")
+		_, ok := rm.Resource().Attributes().Get("k8s.pod.name")
+		assert.False(t, ok)
+		metric := rm.InstrumentationLibraryMetrics().At(0).Metrics().At(0)
+		assert.Equal(t, "cpu_usage", metric.Name())
+		dp := metric.Gauge().DataPoints().At(0)
+		container, _ := dp.Attributes().Get("container")
+		assert.Equal(t, "app", container.StringVal())
+		values[namespace.StringVal()] = dp.DoubleVal()
+	}
+	assert.Equal(t, map[string]float64{"payments": 2, "billing": 10}, values)
+}
+
+func TestRollupDeltaSum(t *testing.T) {
+	p, sink := newTestProcessor(t, RuleConfig{Metrics: []string{"^requests$"}, KeepLabels: []string{"k8s.namespace.name"}})
+	p.windowStart = 100
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("requests", pdata.MetricDataTypeSum,
+		pdata.AggregationTemporalityDelta,
+		testPoint{pod: "app-1", namespace: "payments", value: 2},
+		testPoint{pod: "app-2", namespace: "payments", value: 3},
+		testPoint{pod: "app-1", namespace: "payments", value: 5},
+	)))
+	p.flush(context.Background())
+
+	require.Len(t, sink.AllMetrics(), 1)
+	metric := collectMetrics(sink.AllMetrics()[0])["requests"]
+	require.Equal(t, pdata.MetricDataTypeSum, metric.DataType())
+	assert.Equal(t, pdata.AggregationTemporalityDelta, metric.Sum().AggregationTemporality())
+	assert.True(t, metric.Sum().IsMonotonic())
+	dp := metric.Sum().DataPoints().At(0)
+	assert.Equal(t, 10.0, dp.DoubleVal())
+	assert.Equal(t, pdata.Timestamp(100), dp.StartTimestamp())
+}
+
+func TestRollupCumulativeSum(t *testing.T) {
+	p, sink := newTestProcessor(t, RuleConfig{Metrics: []string{"^requests$"}, KeepLabels: []string{"k8s.namespace.name"}})
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("requests", pdata.MetricDataTypeSum,
+		pdata.AggregationTemporalityCumulative,
+		testPoint{pod: "app-1", namespace: "payments", value: 10, start: 5, timestamp: 10},
+		testPoint{pod: "app-2", namespace: "payments", value: 3, start: 8, timestamp: 10},
+		testPoint{pod: "app-1", namespace: "payments", value: 15, start: 5, timestamp: 20},
+		testPoint{pod: "app-1", namespace: "payments", value: 12, start: 5, timestamp: 15},
+	)))
+	p.flush(context.Background())
+
+	require.Len(t, sink.AllMetrics(), 1)
+	metric := collectMetrics(sink.AllMetrics()[0])["requests"]
+	assert.Equal(t, pdata.AggregationTemporalityCumulative, metric.Sum().AggregationTemporality())
+	dp := metric.Sum().DataPoints().At(0)
+	assert.Equal(t, 18.0, dp.DoubleVal())
+	assert.Equal(t, pdata.Timestamp(5), dp.StartTimestamp())
+}
+
+func TestRollupPassThrough(t *testing.T) {
+	p, sink := newTestProcessor(t, RuleConfig{Metrics: []string{"^cpu_usage$", "^latency$"}})
+
+	md := newTestMetrics("memory_usage", pdata.MetricDataTypeGauge, 0, testPoint{pod: "app", value: 1})
+	newTestMetrics("latency", pdata.MetricDataTypeHistogram, 0, testPoint{pod: "app"}).
+		ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+	newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, 0, testPoint{pod: "app", value: 1}).
+		ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+	require.NoError(t, p.ConsumeMetrics(context.Background(), md))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	passed := sink.AllMetrics()[0]
+	assert.Equal(t, 2, passed.ResourceMetrics().Len())
+	metrics := collectMetrics(passed)
+	assert.Contains(t, metrics, "memory_usage")
+	assert.Contains(t, metrics, "latency")
+}
+
+func TestRollupAllAggregated(t *testing.T) {
+	p, sink := newTestProcessor(t, RuleConfig{Metrics: []string{".*"}})
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, 0,
+		testPoint{pod: "app", value: 1},
+	)))
+	assert.Empty(t, sink.AllMetrics())
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3}
+	assert.Equal(t, 1.0, percentile(values, 0))
+	assert.Equal(t, 3.0, percentile(values, 50))
+	assert.Equal(t, 4.6, percentile(values, 90))
+	assert.Equal(t, 7.0, percentile([]float64{7}, 99))
+	assert.Equal(t, []float64{5, 1, 4, 2, 3}, values)
+}
+
+func TestShutdownFlushes(t *testing.T) {
+	p, sink := newTestProcessor(t, RuleConfig{Metrics: []string{".*"}})
+	p.interval = time.Hour
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, 0,
+		testPoint{pod: "app", value: 1},
+	)))
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	assert.Contains(t, collectMetrics(sink.AllMetrics()[0]), "cpu_usage")
+}
+
+func TestPeriodicFlush(t *testing.T) {
+	p, sink := newTestProcessor(t, RuleConfig{Metrics: []string{".*"}})
+	p.interval = 10 * time.Millisecond
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, p.Shutdown(context.Background()))
+	}()
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, 0,
+		testPoint{pod: "app", value: 1},
+	)))
+	assert.Eventually(t, func() bool {
+		return sink.DataPointCount() == 1
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multilineprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the multiline recombination.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// SourceAttributes lists the record attributes which, together with the resource, identify the stream
+	// the lines are recombined in, e.g. the log file or the container output stream
+	SourceAttributes []string `mapstructure:"source_attributes"`
+	// FirstLineRegexes match the first lines of the multiline records; the other lines are appended
+	// to the preceding record. When empty, only the partial lines are recombined.
+	FirstLineRegexes []string `mapstructure:"first_line_regexes"`
+
+	// PartialTagAttribute is the attribute holding the CRI-O and containerd log tag, which is P
+	// for the lines split by the container runtime
+	PartialTagAttribute string `mapstructure:"partial_tag_attribute"`
+	// DockerPartialLines treats the bodies without the trailing newline as the lines split by Docker
+	// and removes the trailing newline from the complete ones
+	DockerPartialLines bool `mapstructure:"docker_partial_lines"`
+
+	// Separator is put between the recombined lines
+	Separator string `mapstructure:"separator"`
+	// Timeout is the time after which a record without new lines is considered complete
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxLines is the maximum number of lines of a record, the following lines start a new record
+	MaxLines int `mapstructure:"max_lines"`
+	// MaxBytes is the maximum size of the body of a record, the following lines start a new record
+	MaxBytes int `mapstructure:"max_bytes"`
+}
+
+const (
+	defaultPartialTagAttribute = "logtag"
+	defaultSeparator           = "\n"
+	defaultTimeout             = time.Second
+	defaultMaxLines            = 1000
+	defaultMaxBytes            = 1024 * 1024
+)
+
+// defaultSourceAttributes are used when no source attributes are configured
+var defaultSourceAttributes = []string{"log.file.path", "stream"}
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	for _, expr := range cfg.FirstLineRegexes {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid first line regex %q: %w", expr, err)
+		}
+	}
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", cfg.Timeout)
+	}
+	if cfg.MaxLines <= 0 {
+		return fmt.Errorf("max_lines must be positive, got %d", cfg.MaxLines)
+	}
+	if cfg.MaxBytes <= 0 {
+		return fmt.Errorf("max_bytes must be positive, got %d", cfg.MaxBytes)
+	}
+	return nil
+}
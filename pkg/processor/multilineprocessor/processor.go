@@ -0,0 +1,366 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multilineprocessor
+
+import (
+	"context"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/attrhash"
+)
+
+const (
+	partialTag = "P"
+	fullTag    = "F"
+)
+
+// multilineProcessor recombines the lines of the multiline records, e.g. stack traces, and the lines
+// split by the container runtimes, holding the last record of each stream until it is complete
+type multilineProcessor struct {
+	logger              *zap.Logger
+	nextConsumer        consumer.Logs
+	sourceAttributes    []string
+	firstLines          []*regexp.Regexp
+	partialTagAttribute string
+	dockerPartialLines  bool
+	separator           string
+	timeout             time.Duration
+	maxLines            int
+	maxBytes            int
+	now                 func() time.Time
+
+	mu      sync.Mutex
+	streams map[uint64]*pendingRecord
+
+	started bool
+	stopCh  chan struct{}
+	done    chan struct{}
+}
+
+// pendingRecord is the record being recombined in a stream
+type pendingRecord struct {
+	resourceKey uint64
+	resource    pdata.Resource
+	library     pdata.InstrumentationLibrary
+	record      pdata.LogRecord
+	body        strings.Builder
+	lines       int
+	// partial tells if the last line was split and continues in the next one
+	partial bool
+	// updated is the time the last line was received at
+	updated time.Time
+}
+
+var _ component.LogsProcessor = (*multilineProcessor)(nil)
+
+func newMultilineProcessor(logger *zap.Logger, cfg *Config, nextConsumer consumer.Logs) *multilineProcessor {
+	sourceAttributes := cfg.SourceAttributes
+	if sourceAttributes == nil {
+		sourceAttributes = defaultSourceAttributes
+	}
+	firstLines := make([]*regexp.Regexp, len(cfg.FirstLineRegexes))
+	for i, expr := range cfg.FirstLineRegexes {
+		// the expressions have been validated with the config
+		firstLines[i] = regexp.MustCompile(expr)
+	}
+	return &multilineProcessor{
+		logger:              logger,
+		nextConsumer:        nextConsumer,
+		sourceAttributes:    sourceAttributes,
+		firstLines:          firstLines,
+		partialTagAttribute: cfg.PartialTagAttribute,
+		dockerPartialLines:  cfg.DockerPartialLines,
+		separator:           cfg.Separator,
+		timeout:             cfg.Timeout,
+		maxLines:            cfg.MaxLines,
+		maxBytes:            cfg.MaxBytes,
+		now:                 time.Now,
+		streams:             map[uint64]*pendingRecord{},
+		stopCh:              make(chan struct{}),
+		done:                make(chan struct{}),
+	}
+}
+
+// Start starts emitting the records which have not received new lines within the timeout
+func (p *multilineProcessor) Start(context.Context, component.Host) error {
+	p.started = true
+	go p.flushLoop()
+	return nil
+}
+
+// Shutdown emits all the pending records
+func (p *multilineProcessor) Shutdown(ctx context.Context) error {
+	if !p.started {
+		p.flush(ctx, time.Time{})
+		return nil
+	}
+	close(p.stopCh)
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Capabilities returns the consumer capabilities
+func (p *multilineProcessor) Capabilities() consumer.Capabilities {
+	return processorCapabilities
+}
+
+// ConsumeLogs recombines the lines and forwards the complete records
+func (p *multilineProcessor) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
+	out := newLogsBuilder()
+	now := p.now()
+
+	p.mu.Lock()
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resourceKey := attrhash.Attributes(fnv.New64a(), rl.Resource().Attributes())
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			ill := ills.At(j)
+			logs := ill.Logs()
+			for k := 0; k < logs.Len(); k++ {
+				p.processRecord(out, resourceKey, rl.Resource(), ill.InstrumentationLibrary(), logs.At(k), now)
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	if out.logs.ResourceLogs().Len() == 0 {
+		return nil
+	}
+	return p.nextConsumer.ConsumeLogs(ctx, out.logs)
+}
+
+// processRecord appends the line to the pending record of its stream, or emits the pending record
+// and starts a new one
+func (p *multilineProcessor) processRecord(
+	out *logsBuilder,
+	resourceKey uint64,
+	resource pdata.Resource,
+	library pdata.InstrumentationLibrary,
+	lr pdata.LogRecord,
+	now time.Time,
+) {
+	key := p.streamKey(resourceKey, lr)
+	pending := p.streams[key]
+
+	// only the string bodies can be recombined, the other records are passed through in order
+	if lr.Body().Type() != pdata.AttributeValueTypeString {
+		if pending != nil {
+			p.emit(out, key, pending)
+		}
+		lr.CopyTo(out.appendRecord(resourceKey, resource, library))
+		return
+	}
+
+	line, partial := p.splitLine(lr)
+	switch {
+	case pending != nil && pending.partial && pending.body.Len()+len(line) <= p.maxBytes:
+		pending.body.WriteString(line)
+	case pending != nil && !pending.partial && !p.isFirstLine(line) &&
+		pending.lines < p.maxLines && pending.body.Len()+len(p.separator)+len(line) <= p.maxBytes:
+		pending.body.WriteString(p.separator)
+		pending.body.WriteString(line)
+		pending.lines++
+	default:
+		if pending != nil {
+			p.emit(out, key, pending)
+		}
+		pending = newPendingRecord(resourceKey, resource, library, lr, line)
+		p.streams[key] = pending
+	}
+	pending.partial = partial
+	pending.updated = now
+
+	// without the first line regexes, each complete line is a record on its own
+	if !partial && len(p.firstLines) == 0 {
+		p.emit(out, key, pending)
+	}
+}
+
+// splitLine returns the body of the record and whether it is a partial line
+func (p *multilineProcessor) splitLine(lr pdata.LogRecord) (string, bool) {
+	line := lr.Body().StringVal()
+	partial := false
+	if p.partialTagAttribute != "" {
+		if tag, ok := lr.Attributes().Get(p.partialTagAttribute); ok && tag.Type() == pdata.AttributeValueTypeString {
+			partial = tag.StringVal() == partialTag
+		}
+	}
+	if p.dockerPartialLines {
+		if strings.HasSuffix(line, "\n") {
+			line = strings.TrimSuffix(line, "\n")
+		} else {
+			partial = true
+		}
+	}
+	return line, partial
+}
+
+func (p *multilineProcessor) isFirstLine(line string) bool {
+	for _, re := range p.firstLines {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamKey hashes the resource and the source attributes of the record
+func (p *multilineProcessor) streamKey(resourceKey uint64, lr pdata.LogRecord) uint64 {
+	h := fnv.New64a()
+	attrhash.WriteUint64(h, resourceKey)
+	for _, name := range p.sourceAttributes {
+		// The separator tells apart a missing attribute from an empty one
+		h.Write([]byte{0})
+		if value, ok := lr.Attributes().Get(name); ok {
+			h.Write([]byte{1})
+			h.Write([]byte(pdata.AttributeValueToString(value)))
+		}
+	}
+	return h.Sum64()
+}
+
+func newPendingRecord(
+	resourceKey uint64,
+	resource pdata.Resource,
+	library pdata.InstrumentationLibrary,
+	lr pdata.LogRecord,
+	line string,
+) *pendingRecord {
+	pending := &pendingRecord{
+		resourceKey: resourceKey,
+		resource:    pdata.NewResource(),
+		library:     pdata.NewInstrumentationLibrary(),
+		record:      pdata.NewLogRecord(),
+		lines:       1,
+	}
+	resource.CopyTo(pending.resource)
+	library.CopyTo(pending.library)
+	lr.CopyTo(pending.record)
+	pending.body.WriteString(line)
+	return pending
+}
+
+// emit appends the recombined record to the output and removes it from the stream. The record keeps
+// the timestamp and the attributes of its first line.
+func (p *multilineProcessor) emit(out *logsBuilder, key uint64, pending *pendingRecord) {
+	delete(p.streams, key)
+	lr := out.appendRecord(pending.resourceKey, pending.resource, pending.library)
+	pending.record.CopyTo(lr)
+	lr.Body().SetStringVal(pending.body.String())
+	if p.partialTagAttribute != "" {
+		if _, ok := lr.Attributes().Get(p.partialTagAttribute); ok {
+			lr.Attributes().UpsertString(p.partialTagAttribute, fullTag)
+		}
+	}
+}
+
+func (p *multilineProcessor) flushLoop() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.timeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.flush(context.Background(), p.now().Add(-p.timeout))
+		case <-p.stopCh:
+			p.flush(context.Background(), time.Time{})
+			return
+		}
+	}
+}
+
+// flush emits the pending records which have not been updated after the deadline,
+// or all of them when the deadline is zero
+func (p *multilineProcessor) flush(ctx context.Context, deadline time.Time) {
+	out := newLogsBuilder()
+	p.mu.Lock()
+	keys := make([]uint64, 0, len(p.streams))
+	for key, pending := range p.streams {
+		if deadline.IsZero() || !pending.updated.After(deadline) {
+			keys = append(keys, key)
+		}
+	}
+	// the records of different streams are emitted in the order they were started in
+	sort.Slice(keys, func(i, j int) bool {
+		return p.streams[keys[i]].record.Timestamp() < p.streams[keys[j]].record.Timestamp()
+	})
+	for _, key := range keys {
+		p.emit(out, key, p.streams[key])
+	}
+	p.mu.Unlock()
+
+	if out.logs.ResourceLogs().Len() == 0 {
+		return
+	}
+	if err := p.nextConsumer.ConsumeLogs(ctx, out.logs); err != nil {
+		p.logger.Error("Failed to send the recombined records", zap.Error(err))
+	}
+}
+
+// logsBuilder groups the records by their resources and instrumentation libraries
+type logsBuilder struct {
+	logs      pdata.Logs
+	resources map[uint64]pdata.ResourceLogs
+	libraries map[libraryKey]pdata.LogSlice
+}
+
+type libraryKey struct {
+	resourceKey uint64
+	name        string
+	version     string
+}
+
+func newLogsBuilder() *logsBuilder {
+	return &logsBuilder{
+		logs:      pdata.NewLogs(),
+		resources: map[uint64]pdata.ResourceLogs{},
+		libraries: map[libraryKey]pdata.LogSlice{},
+	}
+}
+
+// appendRecord returns a new empty record of the resource and the instrumentation library
+func (b *logsBuilder) appendRecord(resourceKey uint64, resource pdata.Resource, library pdata.InstrumentationLibrary) pdata.LogRecord {
+	key := libraryKey{resourceKey: resourceKey, name: library.Name(), version: library.Version()}
+	logs, ok := b.libraries[key]
+	if !ok {
+		rl, ok := b.resources[resourceKey]
+		if !ok {
+			rl = b.logs.ResourceLogs().AppendEmpty()
+			resource.CopyTo(rl.Resource())
+			b.resources[resourceKey] = rl
+		}
+		ill := rl.InstrumentationLibraryLogs().AppendEmpty()
+		library.CopyTo(ill.InstrumentationLibrary())
+		logs = ill.Logs()
+		b.libraries[key] = logs
+	}
+	return logs.AppendEmpty()
+}
@@ -0,0 +1,268 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multilineprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+type testRecord struct {
+	pod        string
+	body       string
+	attributes map[string]string
+	timestamp  pdata.Timestamp
+}
+
+func newTestLogs(records ...testRecord) pdata.Logs {
+	ld := pdata.NewLogs()
+	for _, r := range records {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().InsertString("k8s.pod.name", r.pod)
+		lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+		lr.Body().SetStringVal(r.body)
+		lr.SetTimestamp(r.timestamp)
+		for k, v := range r.attributes {
+			lr.Attributes().InsertString(k, v)
+		}
+	}
+	return ld
+}
+
+func newTestProcessor(modify func(cfg *Config)) (*multilineProcessor, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*Config)
+	modify(cfg)
+	sink := &consumertest.LogsSink{}
+	return newMultilineProcessor(zap.NewNop(), cfg, sink), sink
+}
+
+// records returns the records received by the sink, in order
+func records(sink *consumertest.LogsSink) []pdata.LogRecord {
+	var result []pdata.LogRecord
+	for _, ld := range sink.AllLogs() {
+		rls := ld.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			ills := rls.At(i).InstrumentationLibraryLogs()
+			for j := 0; j < ills.Len(); j++ {
+				logs := ills.At(j).Logs()
+				for k := 0; k < logs.Len(); k++ {
+					result = append(result, logs.At(k))
+				}
+			}
+		}
+	}
+	return result
+}
+
+func bodies(sink *consumertest.LogsSink) []string {
+	var result []string
+	for _, lr := range records(sink) {
+		result = append(result, pdata.AttributeValueToString(lr.Body()))
+	}
+	return result
+}
+
+func withFirstLines(cfg *Config) {
+	cfg.FirstLineRegexes = []string{`^\d{4}-\d{2}-\d{2} `}
+}
+
+func TestRecombineStackTraces(t *testing.T) {
+	p, sink := newTestProcessor(withFirstLines)
+	stdout := map[string]string{"stream": "stdout"}
+	stderr := map[string]string{"stream": "stderr"}
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "2021-09-01 12:00:00 ERROR request failed", attributes: stderr, timestamp: 1},
+		testRecord{pod: "app", body: "2021-09-01 12:00:00 INFO handled", attributes: stdout, timestamp: 2},
+		testRecord{pod: "app", body: "java.lang.NullPointerException", attributes: stderr, timestamp: 3},
+		testRecord{pod: "other", body: "\tat other.Main.main(Main.java:1)", timestamp: 4},
+		testRecord{pod: "app", body: "\tat com.example.Handler.handle(Handler.java:42)", attributes: stderr, timestamp: 5},
+	)))
+	// nothing is complete yet
+	assert.Empty(t, bodies(sink))
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "\tat com.example.Server.run(Server.java:7)", attributes: stderr, timestamp: 6},
+		testRecord{pod: "app", body: "2021-09-01 12:00:01 ERROR retry failed", attributes: stderr, timestamp: 7},
+	)))
+	require.Len(t, records(sink), 1)
+	lr := records(sink)[0]
+	assert.Equal(t, "2021-09-01 12:00:00 ERROR request failed\n"+
+		"java.lang.NullPointerException\n"+
+		"\tat com.example.Handler.handle(Handler.java:42)\n"+
+		"\tat com.example.Server.run(Server.java:7)", lr.Body().StringVal())
+	assert.Equal(t, pdata.Timestamp(1), lr.Timestamp())
+
+	// the pending records are grouped by their resources
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, []string{
+		"2021-09-01 12:00:00 ERROR request failed\n" +
+			"java.lang.NullPointerException\n" +
+			"\tat com.example.Handler.handle(Handler.java:42)\n" +
+			"\tat com.example.Server.run(Server.java:7)",
+		"2021-09-01 12:00:00 INFO handled",
+		"2021-09-01 12:00:01 ERROR retry failed",
+		"\tat other.Main.main(Main.java:1)",
+	}, bodies(sink))
+}
+
+func TestPartialTagLines(t *testing.T) {
+	p, sink := newTestProcessor(func(*Config) {})
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "very ", attributes: map[string]string{"logtag": "P"}},
+		testRecord{pod: "app", body: "long ", attributes: map[string]string{"logtag": "P"}},
+		testRecord{pod: "app", body: "line", attributes: map[string]string{"logtag": "F"}},
+		testRecord{pod: "app", body: "short line", attributes: map[string]string{"logtag": "F"}},
+		testRecord{pod: "app", body: "split ", attributes: map[string]string{"logtag": "P"}},
+	)))
+
+	// without the first line regexes the complete lines are forwarded right away
+	lrs := records(sink)
+	require.Len(t, lrs, 2)
+	assert.Equal(t, "very long line", lrs[0].Body().StringVal())
+	tag, _ := lrs[0].Attributes().Get("logtag")
+	assert.Equal(t, "F", tag.StringVal())
+	assert.Equal(t, "short line", lrs[1].Body().StringVal())
+
+	require.NoError(t, p.Shutdown(context.Background()))
+	lrs = records(sink)
+	require.Len(t, lrs, 3)
+	assert.Equal(t, "split ", lrs[2].Body().StringVal())
+	tag, _ = lrs[2].Attributes().Get("logtag")
+	assert.Equal(t, "F", tag.StringVal())
+}
+
+func TestDockerPartialLines(t *testing.T) {
+	p, sink := newTestProcessor(func(cfg *Config) {
+		withFirstLines(cfg)
+		cfg.DockerPartialLines = true
+	})
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "2021-09-01 12:00:00 ERROR very "},
+		testRecord{pod: "app", body: "long\n"},
+		testRecord{pod: "app", body: "\tat Main.main(Main.java:1)\n"},
+		testRecord{pod: "app", body: "2021-09-01 12:00:01 INFO done\n"},
+	)))
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, []string{
+		"2021-09-01 12:00:00 ERROR very long\n\tat Main.main(Main.java:1)",
+		"2021-09-01 12:00:01 INFO done",
+	}, bodies(sink))
+}
+
+func TestLimits(t *testing.T) {
+	p, sink := newTestProcessor(func(cfg *Config) {
+		withFirstLines(cfg)
+		cfg.MaxLines = 2
+		cfg.MaxBytes = 40
+	})
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "2021-09-01 12:00:00 ERROR"},
+		testRecord{pod: "app", body: "line 2"},
+		testRecord{pod: "app", body: "line 3"},
+		testRecord{pod: "app", body: "2021-09-01 12:00:01 ERROR"},
+		testRecord{pod: "app", body: "a line too long to fit"},
+	)))
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, []string{
+		"2021-09-01 12:00:00 ERROR\nline 2",
+		"line 3",
+		"2021-09-01 12:00:01 ERROR",
+		"a line too long to fit",
+	}, bodies(sink))
+}
+
+func TestNonStringBodies(t *testing.T) {
+	p, sink := newTestProcessor(withFirstLines)
+
+	ld := newTestLogs(testRecord{pod: "app", body: "2021-09-01 12:00:00 ERROR"})
+	lr := ld.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	ld.ResourceLogs().At(1).Resource().Attributes().InsertString("k8s.pod.name", "app")
+	lr.Body().SetIntVal(42)
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), ld))
+	// the pending record is forwarded first to keep the order of the stream
+	assert.Equal(t, []string{"2021-09-01 12:00:00 ERROR", "42"}, bodies(sink))
+}
+
+func TestTimeout(t *testing.T) {
+	p, sink := newTestProcessor(func(cfg *Config) {
+		withFirstLines(cfg)
+		cfg.Timeout = time.Second
+	})
+	now := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app-1", body: "2021-09-01 12:00:00 ERROR first", timestamp: 1},
+	)))
+	now = now.Add(500 * time.Millisecond)
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app-2", body: "2021-09-01 12:00:00 ERROR second", timestamp: 2},
+	)))
+
+	now = now.Add(600 * time.Millisecond)
+	p.flush(context.Background(), now.Add(-p.timeout))
+	assert.Equal(t, []string{"2021-09-01 12:00:00 ERROR first"}, bodies(sink))
+
+	// the resources of the records are kept
+	pod, _ := sink.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().Get("k8s.pod.name")
+	assert.Equal(t, "app-1", pod.StringVal())
+}
+
+func TestFlushLoop(t *testing.T) {
+	p, sink := newTestProcessor(func(cfg *Config) {
+		withFirstLines(cfg)
+		cfg.Timeout = 10 * time.Millisecond
+	})
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app", body: "2021-09-01 12:00:00 ERROR"},
+		testRecord{pod: "app", body: "caused by"},
+	)))
+	assert.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"2021-09-01 12:00:00 ERROR\ncaused by"}, bodies(sink))
+
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestGroupByResource(t *testing.T) {
+	p, sink := newTestProcessor(func(*Config) {})
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), newTestLogs(
+		testRecord{pod: "app-1", body: "a"},
+		testRecord{pod: "app-2", body: "b"},
+		testRecord{pod: "app-1", body: "c"},
+	)))
+	require.Len(t, sink.AllLogs(), 1)
+	rls := sink.AllLogs()[0].ResourceLogs()
+	require.Equal(t, 2, rls.Len())
+	assert.Equal(t, 2, rls.At(0).InstrumentationLibraryLogs().At(0).Logs().Len())
+	assert.Equal(t, 1, rls.At(1).InstrumentationLibraryLogs().At(0).Logs().Len())
+}
@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piimaskingprocessor
+
+import (
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the PII masking.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Detectors lists the built-in detectors to use: email, credit_card, ssn, ip and jwt.
+	// All of them are used when it is not set.
+	Detectors []string `mapstructure:"detectors"`
+	// Patterns are the custom regular expressions matching the values to mask
+	Patterns []PatternConfig `mapstructure:"patterns"`
+	// Action is either mask, replacing the matches with MaskValue, or hash, replacing them with their SHA-256 hashes
+	Action string `mapstructure:"action"`
+	// MaskValue replaces the matches when Action is mask
+	MaskValue string `mapstructure:"mask_value"`
+	// HashSalt is prepended to the matches before hashing them
+	HashSalt string `mapstructure:"hash_salt"`
+	// IncludeResourceAttributes enables masking the resource attributes too
+	IncludeResourceAttributes bool `mapstructure:"include_resource_attributes"`
+	// ExcludeAttributes lists the attributes which are never masked, e.g. k8s.pod.ip
+	ExcludeAttributes []string `mapstructure:"exclude_attributes"`
+}
+
+// PatternConfig holds the configuration of a custom pattern.
+type PatternConfig struct {
+	// Name identifies the pattern in metrics
+	Name string `mapstructure:"name"`
+	// Regex matches the values to mask
+	Regex string `mapstructure:"regex"`
+}
+
+const (
+	actionMask = "mask"
+	actionHash = "hash"
+
+	defaultMaskValue = "****"
+)
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piimaskingprocessor
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// detector matches the values of one kind of PII
+type detector struct {
+	name  string
+	regex *regexp.Regexp
+	// valid rejects the false positives of the regular expression, all the matches are masked when it is nil
+	valid func(match string) bool
+	// bounded rejects the matches which are a part of a longer word, e.g. `data::base` for IPv6
+	bounded bool
+}
+
+// builtinDetectors are the detectors available by their names
+var builtinDetectors = map[string]*detector{
+	"email": {
+		name:  "email",
+		regex: regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+	},
+	"credit_card": {
+		name:  "credit_card",
+		regex: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		valid: isLuhnValid,
+	},
+	"ssn": {
+		name:  "ssn",
+		regex: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		valid: isSSNValid,
+	},
+	"ip": {
+		name: "ip",
+		// IPv4 addresses or the candidates for IPv6 addresses, which are validated
+		regex:   regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|(?:[0-9a-fA-F]{0,4}:){2,7}[0-9a-fA-F]{0,4}`),
+		valid:   isIPValid,
+		bounded: true,
+	},
+	"jwt": {
+		name:  "jwt",
+		regex: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+	},
+}
+
+// builtinDetectorNames keeps the order in which the built-in detectors are applied, so the JWTs
+// are masked before the emails or IPs they might contain are found
+var builtinDetectorNames = []string{"jwt", "email", "credit_card", "ssn", "ip"}
+
+// isLuhnValid checks the credit card number checksum
+func isLuhnValid(match string) bool {
+	sum := 0
+	double := false
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// isSSNValid rejects the numbers which are never assigned as Social Security Numbers
+func isSSNValid(match string) bool {
+	parts := strings.Split(match, "-")
+	area, group, serial := parts[0], parts[1], parts[2]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// isIPValid checks if the match is an IP address; the IPv6 ones need at least two groups of digits,
+// so e.g. `std::bad` is not masked
+func isIPValid(match string) bool {
+	if net.ParseIP(match) == nil {
+		return false
+	}
+	if !strings.Contains(match, ":") {
+		return true
+	}
+	groups := 0
+	for _, group := range strings.Split(match, ":") {
+		if group != "" {
+			groups++
+		}
+	}
+	return groups >= 2
+}
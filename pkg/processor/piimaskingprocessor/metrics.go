@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piimaskingprocessor
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+)
+
+// Variables related to metrics specific to PII Masking.
+var (
+	tagDetectorKey, _ = tag.NewKey("detector")
+
+	statMaskedCount = stats.Int64("pii_masking_masked_values", "Count of values masked per detector", stats.UnitDimensionless)
+)
+
+// MetricViews return the metrics views according to given telemetry level.
+func MetricViews(level configtelemetry.Level) []*view.View {
+	if level == configtelemetry.LevelNone {
+		return nil
+	}
+
+	countMaskedView := &view.View{
+		Name:        statMaskedCount.Name(),
+		Measure:     statMaskedCount,
+		Description: statMaskedCount.Description(),
+		TagKeys:     []tag.Key{tagDetectorKey},
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{countMaskedView}
+}
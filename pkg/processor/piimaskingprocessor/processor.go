@@ -0,0 +1,297 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piimaskingprocessor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// piiMaskingProcessor masks the values matched by the detectors in the log bodies and the attributes
+type piiMaskingProcessor struct {
+	detectors                 []*detector
+	replace                   func(match string) string
+	includeResourceAttributes bool
+	excludeAttributes         map[string]bool
+}
+
+// maskCounts counts the masked values per detector
+type maskCounts map[string]int64
+
+func newPIIMaskingProcessor(cfg *Config) (*piiMaskingProcessor, error) {
+	detectors, err := newDetectors(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pmp := &piiMaskingProcessor{
+		detectors:                 detectors,
+		includeResourceAttributes: cfg.IncludeResourceAttributes,
+		excludeAttributes:         map[string]bool{},
+	}
+	for _, name := range cfg.ExcludeAttributes {
+		pmp.excludeAttributes[name] = true
+	}
+
+	switch cfg.Action {
+	case actionMask:
+		maskValue := cfg.MaskValue
+		pmp.replace = func(string) string {
+			return maskValue
+		}
+	case actionHash:
+		salt := cfg.HashSalt
+		pmp.replace = func(match string) string {
+			hash := sha256.Sum256([]byte(salt + match))
+			return hex.EncodeToString(hash[:])
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %q, expected %s or %s", cfg.Action, actionMask, actionHash)
+	}
+	return pmp, nil
+}
+
+// newDetectors returns the configured built-in detectors followed by the custom patterns
+func newDetectors(cfg *Config) ([]*detector, error) {
+	var detectors []*detector
+	if cfg.Detectors == nil {
+		for _, name := range builtinDetectorNames {
+			detectors = append(detectors, builtinDetectors[name])
+		}
+	} else {
+		enabled := map[string]bool{}
+		for _, name := range cfg.Detectors {
+			if _, ok := builtinDetectors[name]; !ok {
+				return nil, fmt.Errorf("unknown detector %q, expected one of: %s", name, strings.Join(builtinDetectorNames, ", "))
+			}
+			enabled[name] = true
+		}
+		for _, name := range builtinDetectorNames {
+			if enabled[name] {
+				detectors = append(detectors, builtinDetectors[name])
+			}
+		}
+	}
+
+	for _, pattern := range cfg.Patterns {
+		if pattern.Name == "" {
+			return nil, fmt.Errorf("pattern %q has no name", pattern.Regex)
+		}
+		regex, err := regexp.Compile(pattern.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex of pattern %q: %w", pattern.Name, err)
+		}
+		detectors = append(detectors, &detector{name: pattern.Name, regex: regex})
+	}
+
+	if len(detectors) == 0 {
+		return nil, fmt.Errorf("no detectors or patterns are configured")
+	}
+	return detectors, nil
+}
+
+// ProcessTraces masks the attributes of the spans, their events and links
+func (pmp *piiMaskingProcessor) ProcessTraces(ctx context.Context, td pdata.Traces) (pdata.Traces, error) {
+	counts := maskCounts{}
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		pmp.maskResource(rs.Resource(), counts)
+		ilss := rs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				pmp.maskAttributes(span.Attributes(), counts)
+				events := span.Events()
+				for l := 0; l < events.Len(); l++ {
+					pmp.maskAttributes(events.At(l).Attributes(), counts)
+				}
+				links := span.Links()
+				for l := 0; l < links.Len(); l++ {
+					pmp.maskAttributes(links.At(l).Attributes(), counts)
+				}
+			}
+		}
+	}
+	counts.record(ctx)
+	return td, nil
+}
+
+// ProcessMetrics masks the attributes of the data points
+func (pmp *piiMaskingProcessor) ProcessMetrics(ctx context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	counts := maskCounts{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		pmp.maskResource(rm.Resource(), counts)
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				pmp.maskMetric(metrics.At(k), counts)
+			}
+		}
+	}
+	counts.record(ctx)
+	return md, nil
+}
+
+func (pmp *piiMaskingProcessor) maskMetric(metric pdata.Metric, counts maskCounts) {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			pmp.maskAttributes(dps.At(i).Attributes(), counts)
+		}
+	case pdata.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			pmp.maskAttributes(dps.At(i).Attributes(), counts)
+		}
+	case pdata.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			pmp.maskAttributes(dps.At(i).Attributes(), counts)
+		}
+	case pdata.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			pmp.maskAttributes(dps.At(i).Attributes(), counts)
+		}
+	}
+}
+
+// ProcessLogs masks the bodies and the attributes of the log records
+func (pmp *piiMaskingProcessor) ProcessLogs(ctx context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	counts := maskCounts{}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		pmp.maskResource(rl.Resource(), counts)
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				lr := logs.At(k)
+				pmp.maskValue(lr.Body(), counts)
+				pmp.maskAttributes(lr.Attributes(), counts)
+			}
+		}
+	}
+	counts.record(ctx)
+	return ld, nil
+}
+
+func (pmp *piiMaskingProcessor) maskResource(resource pdata.Resource, counts maskCounts) {
+	if pmp.includeResourceAttributes {
+		pmp.maskAttributes(resource.Attributes(), counts)
+	}
+}
+
+func (pmp *piiMaskingProcessor) maskAttributes(attributes pdata.AttributeMap, counts maskCounts) {
+	attributes.Range(func(name string, value pdata.AttributeValue) bool {
+		if !pmp.excludeAttributes[name] {
+			pmp.maskValue(value, counts)
+		}
+		return true
+	})
+}
+
+// maskValue masks the string value, or the string values nested in the map or array
+func (pmp *piiMaskingProcessor) maskValue(value pdata.AttributeValue, counts maskCounts) {
+	switch value.Type() {
+	case pdata.AttributeValueTypeString:
+		if masked, ok := pmp.mask(value.StringVal(), counts); ok {
+			value.SetStringVal(masked)
+		}
+	case pdata.AttributeValueTypeMap:
+		pmp.maskAttributes(value.MapVal(), counts)
+	case pdata.AttributeValueTypeArray:
+		values := value.ArrayVal()
+		for i := 0; i < values.Len(); i++ {
+			pmp.maskValue(values.At(i), counts)
+		}
+	}
+}
+
+// mask applies the detectors in order and reports whether anything was masked
+func (pmp *piiMaskingProcessor) mask(s string, counts maskCounts) (string, bool) {
+	masked := false
+	for _, d := range pmp.detectors {
+		matches := d.regex.FindAllStringIndex(s, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var sb strings.Builder
+		last := 0
+		replaced := false
+		for _, m := range matches {
+			match := s[m[0]:m[1]]
+			if match == "" || (d.valid != nil && !d.valid(match)) || (d.bounded && !isBounded(s, m[0], m[1])) {
+				continue
+			}
+			sb.WriteString(s[last:m[0]])
+			sb.WriteString(pmp.replace(match))
+			last = m[1]
+			replaced = true
+			counts[d.name]++
+		}
+		if !replaced {
+			continue
+		}
+		sb.WriteString(s[last:])
+		s = sb.String()
+		masked = true
+	}
+	return s, masked
+}
+
+// isBounded checks that the match is not a part of a longer word, i.e. it is not preceded by a word character,
+// a dot or a colon, and not followed by a word character or a dot or colon continuing the word
+func isBounded(s string, start, end int) bool {
+	if start > 0 && (isWordChar(s[start-1]) || s[start-1] == '.' || s[start-1] == ':') {
+		return false
+	}
+	if end < len(s) {
+		next := s[end]
+		if isWordChar(next) {
+			return false
+		}
+		if (next == '.' || next == ':') && end+1 < len(s) && isWordChar(s[end+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func (counts maskCounts) record(ctx context.Context) {
+	for name, count := range counts {
+		_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(tagDetectorKey, name)}, statMaskedCount.M(count))
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piimaskingprocessor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) *piiMaskingProcessor {
+	cfg := createDefaultConfig().(*Config)
+	if modify != nil {
+		modify(cfg)
+	}
+	pmp, err := newPIIMaskingProcessor(cfg)
+	require.NoError(t, err)
+	return pmp
+}
+
+func assertStringAttribute(t *testing.T, attributes pdata.AttributeMap, name string, expected string) {
+	value, ok := attributes.Get(name)
+	require.True(t, ok, name)
+	assert.Equal(t, expected, value.StringVal(), name)
+}
+
+func TestProcessLogs(t *testing.T) {
+	pmp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Patterns = []PatternConfig{{Name: "api_key", Regex: `sk_live_[0-9a-zA-Z]{8}`}}
+		cfg.ExcludeAttributes = []string{"net.peer.ip"}
+	})
+
+	ld := pdata.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("k8s.pod.ip", "10.1.2.3")
+	lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	lr.Body().SetStringVal("payment by jane@example.com with key sk_live_abcd1234")
+	lr.Attributes().InsertString("user", "jane@example.com")
+	lr.Attributes().InsertString("net.peer.ip", "10.1.2.4")
+	lr.Attributes().InsertInt("status", 200)
+	request := pdata.NewAttributeValueMap()
+	request.MapVal().InsertString("client", "10.1.2.5")
+	lr.Attributes().Insert("request", request)
+
+	structured := rl.InstrumentationLibraryLogs().At(0).Logs().AppendEmpty()
+	pdata.NewAttributeValueMap().CopyTo(structured.Body())
+	structured.Body().MapVal().InsertString("message", "ssn 123-45-6789")
+	emails := pdata.NewAttributeValueArray()
+	emails.ArrayVal().AppendEmpty().SetStringVal("a@example.com")
+	structured.Body().MapVal().Insert("emails", emails)
+
+	result, err := pmp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	rl = result.ResourceLogs().At(0)
+	assertStringAttribute(t, rl.Resource().Attributes(), "k8s.pod.ip", "10.1.2.3")
+	lr = rl.InstrumentationLibraryLogs().At(0).Logs().At(0)
+	assert.Equal(t, "payment by **** with key ****", lr.Body().StringVal())
+	assertStringAttribute(t, lr.Attributes(), "user", "****")
+	assertStringAttribute(t, lr.Attributes(), "net.peer.ip", "10.1.2.4")
+	request, _ = lr.Attributes().Get("request")
+	assertStringAttribute(t, request.MapVal(), "client", "****")
+
+	structured = rl.InstrumentationLibraryLogs().At(0).Logs().At(1)
+	assertStringAttribute(t, structured.Body().MapVal(), "message", "ssn ****")
+	emails, _ = structured.Body().MapVal().Get("emails")
+	assert.Equal(t, "****", emails.ArrayVal().At(0).StringVal())
+}
+
+func TestProcessLogsHash(t *testing.T) {
+	pmp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Action = actionHash
+		cfg.HashSalt = "salt"
+		cfg.Detectors = []string{"email"}
+		cfg.IncludeResourceAttributes = true
+	})
+
+	ld := pdata.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("owner", "jane@example.com")
+	lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	lr.Body().SetStringVal("login of jane@example.com from 10.1.2.3")
+
+	result, err := pmp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	hash := sha256.Sum256([]byte("saltjane@example.com"))
+	hashed := hex.EncodeToString(hash[:])
+	rl = result.ResourceLogs().At(0)
+	assertStringAttribute(t, rl.Resource().Attributes(), "owner", hashed)
+	assert.Equal(t, "login of "+hashed+" from 10.1.2.3", rl.InstrumentationLibraryLogs().At(0).Logs().At(0).Body().StringVal())
+}
+
+func TestProcessTraces(t *testing.T) {
+	pmp := newTestProcessor(t, nil)
+
+	td := pdata.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().InsertString("http.url", "https://example.com/users/jane@example.com")
+	span.Events().AppendEmpty().Attributes().InsertString("exception.message", "invalid card 4111111111111111")
+	span.Links().AppendEmpty().Attributes().InsertString("peer", "10.1.2.3")
+
+	result, err := pmp.ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+
+	span = result.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0)
+	assertStringAttribute(t, span.Attributes(), "http.url", "https://example.com/users/****")
+	assertStringAttribute(t, span.Events().At(0).Attributes(), "exception.message", "invalid card ****")
+	assertStringAttribute(t, span.Links().At(0).Attributes(), "peer", "****")
+}
+
+func TestProcessMetrics(t *testing.T) {
+	pmp := newTestProcessor(t, nil)
+
+	md := pdata.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty()
+	gauge.SetDataType(pdata.MetricDataTypeGauge)
+	gauge.Gauge().DataPoints().AppendEmpty().Attributes().InsertString("user", "jane@example.com")
+	sum := metrics.AppendEmpty()
+	sum.SetDataType(pdata.MetricDataTypeSum)
+	sum.Sum().DataPoints().AppendEmpty().Attributes().InsertString("client", "10.1.2.3")
+	histogram := metrics.AppendEmpty()
+	histogram.SetDataType(pdata.MetricDataTypeHistogram)
+	histogram.Histogram().DataPoints().AppendEmpty().Attributes().InsertString("client", "10.1.2.3")
+	summary := metrics.AppendEmpty()
+	summary.SetDataType(pdata.MetricDataTypeSummary)
+	summary.Summary().DataPoints().AppendEmpty().Attributes().InsertString("client", "10.1.2.3")
+
+	result, err := pmp.ProcessMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	metrics = result.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	assertStringAttribute(t, metrics.At(0).Gauge().DataPoints().At(0).Attributes(), "user", "****")
+	assertStringAttribute(t, metrics.At(1).Sum().DataPoints().At(0).Attributes(), "client", "****")
+	assertStringAttribute(t, metrics.At(2).Histogram().DataPoints().At(0).Attributes(), "client", "****")
+	assertStringAttribute(t, metrics.At(3).Summary().DataPoints().At(0).Attributes(), "client", "****")
+}
+
+func TestMaskCounts(t *testing.T) {
+	pmp := newTestProcessor(t, nil)
+	counts := maskCounts{}
+	pmp.mask("a@example.com, b@example.com from 10.1.2.3", counts)
+	assert.Equal(t, maskCounts{"email": 2, "ip": 1}, counts)
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusnamingprocessor
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the Prometheus metric naming.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Presets are the names of the built-in rule sets applied after the configured rules,
+	// e.g. kube_state_metrics or node_exporter
+	Presets []string `mapstructure:"presets"`
+	// Rules are the metric renames, applied in order
+	Rules []RuleConfig `mapstructure:"rules"`
+	// LabelRenames maps the data point label names to their new names
+	LabelRenames map[string]string `mapstructure:"label_renames"`
+	// NormalizeBucketLabels formats the values of the le and quantile labels the way Prometheus does, e.g. +Inf or 0.5
+	NormalizeBucketLabels bool `mapstructure:"normalize_bucket_labels"`
+}
+
+// RuleConfig renames the metric, or only its data points with the matching labels
+type RuleConfig struct {
+	// Metric is the name of the renamed metric; the rule applies also to the _bucket, _sum and _count
+	// series of the flattened histograms and summaries
+	Metric string `mapstructure:"metric"`
+	// MatchLabels are the label values the data points must have to be renamed, all of them are renamed when empty
+	MatchLabels map[string]string `mapstructure:"match_labels"`
+	// NewName is the new name of the metric
+	NewName string `mapstructure:"new_name"`
+	// DropLabels are the labels removed from the renamed data points
+	DropLabels []string `mapstructure:"drop_labels"`
+}
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	for _, preset := range cfg.Presets {
+		if _, ok := presets[preset]; !ok {
+			return fmt.Errorf("unknown preset %q", preset)
+		}
+	}
+	for i, rule := range cfg.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+	}
+	for from, to := range cfg.LabelRenames {
+		if from == "" || to == "" {
+			return errors.New("label names must not be empty")
+		}
+		if isBucketLabel(from) || isBucketLabel(to) {
+			return fmt.Errorf("the %s and %s labels cannot be renamed", labelLe, labelQuantile)
+		}
+	}
+	return nil
+}
+
+func (rule RuleConfig) validate() error {
+	if rule.Metric == "" {
+		return errors.New("metric must not be empty")
+	}
+	if rule.NewName == "" {
+		return errors.New("new_name must not be empty")
+	}
+	for _, label := range rule.DropLabels {
+		if isBucketLabel(label) {
+			return fmt.Errorf("the %s and %s labels cannot be dropped", labelLe, labelQuantile)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusnamingprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfigAndValidate(path.Join(".", "testdata", "prometheus_naming_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, factory.CreateDefaultConfig(), cfg.Processors[config.NewID(typeStr)])
+
+	id := config.NewIDWithName(typeStr, "custom")
+	assert.Equal(t,
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(id),
+			Presets:           []string{"kube_state_metrics", "node_exporter"},
+			Rules: []RuleConfig{
+				{
+					Metric:  "apiserver_request_duration_seconds",
+					NewName: "apiserver_request_latencies",
+				},
+				{
+					Metric:      "kube_pod_status_phase",
+					MatchLabels: map[string]string{"phase": "Running"},
+					NewName:     "kube_pod_status_running",
+					DropLabels:  []string{"phase"},
+				},
+			},
+			LabelRenames:          map[string]string{"kubernetes_namespace": "namespace"},
+			NormalizeBucketLabels: false,
+		},
+		cfg.Processors[id])
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusnamingprocessor
+
+import (
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// forEachDataPoint calls fn with the attributes of each data point of the metric
+func forEachDataPoint(metric pdata.Metric, fn func(pdata.AttributeMap)) {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
+
+// anyDataPoint checks if the attributes of any data point of the metric satisfy fn
+func anyDataPoint(metric pdata.Metric, fn func(pdata.AttributeMap) bool) bool {
+	found := false
+	forEachDataPoint(metric, func(attributes pdata.AttributeMap) {
+		found = found || fn(attributes)
+	})
+	return found
+}
+
+// removeDataPoints removes the data points of the metric whose attributes satisfy fn
+func removeDataPoints(metric pdata.Metric, fn func(pdata.AttributeMap) bool) {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		metric.Gauge().DataPoints().RemoveIf(func(dp pdata.NumberDataPoint) bool { return fn(dp.Attributes()) })
+	case pdata.MetricDataTypeSum:
+		metric.Sum().DataPoints().RemoveIf(func(dp pdata.NumberDataPoint) bool { return fn(dp.Attributes()) })
+	case pdata.MetricDataTypeHistogram:
+		metric.Histogram().DataPoints().RemoveIf(func(dp pdata.HistogramDataPoint) bool { return fn(dp.Attributes()) })
+	case pdata.MetricDataTypeSummary:
+		metric.Summary().DataPoints().RemoveIf(func(dp pdata.SummaryDataPoint) bool { return fn(dp.Attributes()) })
+	}
+}
+
+// dataPointCount returns the number of the data points of the metric
+func dataPointCount(metric pdata.Metric) int {
+	count := 0
+	forEachDataPoint(metric, func(pdata.AttributeMap) {
+		count++
+	})
+	return count
+}
@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusnamingprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configcheck"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg := createDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configcheck.ValidateConfig(cfg))
+}
+
+func TestCreateProcessor(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Presets = []string{"kube_state_metrics"}
+
+	mp, err := factory.CreateMetricsProcessor(context.Background(), component.ProcessorCreateSettings{}, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotNil(t, mp)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{name: "unknown preset", modify: func(cfg *Config) { cfg.Presets = []string{"cadvisor"} }},
+		{name: "rule without metric", modify: func(cfg *Config) { cfg.Rules = []RuleConfig{{NewName: "b"}} }},
+		{name: "rule without new name", modify: func(cfg *Config) { cfg.Rules = []RuleConfig{{Metric: "a"}} }},
+		{
+			name:   "rule dropping le",
+			modify: func(cfg *Config) { cfg.Rules = []RuleConfig{{Metric: "a", NewName: "b", DropLabels: []string{"le"}}} },
+		},
+		{name: "empty label rename", modify: func(cfg *Config) { cfg.LabelRenames = map[string]string{"a": ""} }},
+		{name: "quantile rename", modify: func(cfg *Config) { cfg.LabelRenames = map[string]string{"quantile": "q"} }},
+	}
+
+	factory := NewFactory()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := factory.CreateDefaultConfig().(*Config)
+			tc.modify(cfg)
+			_, err := factory.CreateMetricsProcessor(context.Background(), component.ProcessorCreateSettings{}, cfg, consumertest.NewNop())
+			assert.Error(t, err)
+		})
+	}
+}
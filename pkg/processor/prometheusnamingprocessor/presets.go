@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusnamingprocessor
+
+// presets are the built-in rule sets, by name
+var presets = map[string][]RuleConfig{
+	// kube_state_metrics restores the metric names of kube-state-metrics v1, used by the Sumo Logic
+	// Kubernetes app, from the v2 metrics with the resource and unit labels
+	"kube_state_metrics": {
+		resourceRule("kube_pod_container_resource_requests", "cpu", "kube_pod_container_resource_requests_cpu_cores"),
+		resourceRule("kube_pod_container_resource_requests", "memory", "kube_pod_container_resource_requests_memory_bytes"),
+		resourceRule("kube_pod_container_resource_limits", "cpu", "kube_pod_container_resource_limits_cpu_cores"),
+		resourceRule("kube_pod_container_resource_limits", "memory", "kube_pod_container_resource_limits_memory_bytes"),
+		resourceRule("kube_node_status_capacity", "cpu", "kube_node_status_capacity_cpu_cores"),
+		resourceRule("kube_node_status_capacity", "memory", "kube_node_status_capacity_memory_bytes"),
+		resourceRule("kube_node_status_capacity", "pods", "kube_node_status_capacity_pods"),
+		resourceRule("kube_node_status_allocatable", "cpu", "kube_node_status_allocatable_cpu_cores"),
+		resourceRule("kube_node_status_allocatable", "memory", "kube_node_status_allocatable_memory_bytes"),
+		resourceRule("kube_node_status_allocatable", "pods", "kube_node_status_allocatable_pods"),
+		{Metric: "kube_horizontalpodautoscaler_spec_max_replicas", NewName: "kube_hpa_spec_max_replicas"},
+		{Metric: "kube_horizontalpodautoscaler_spec_min_replicas", NewName: "kube_hpa_spec_min_replicas"},
+		{Metric: "kube_horizontalpodautoscaler_status_current_replicas", NewName: "kube_hpa_status_current_replicas"},
+		{Metric: "kube_horizontalpodautoscaler_status_desired_replicas", NewName: "kube_hpa_status_desired_replicas"},
+	},
+	// node_exporter restores the metric names of node-exporter before v0.16, used by the Sumo Logic
+	// Kubernetes app, from the names with the unit suffixes
+	"node_exporter": {
+		{Metric: "node_cpu_seconds_total", NewName: "node_cpu"},
+		{Metric: "node_memory_MemTotal_bytes", NewName: "node_memory_MemTotal"},
+		{Metric: "node_memory_MemFree_bytes", NewName: "node_memory_MemFree"},
+		{Metric: "node_memory_MemAvailable_bytes", NewName: "node_memory_MemAvailable"},
+		{Metric: "node_memory_Buffers_bytes", NewName: "node_memory_Buffers"},
+		{Metric: "node_memory_Cached_bytes", NewName: "node_memory_Cached"},
+		{Metric: "node_filesystem_size_bytes", NewName: "node_filesystem_size"},
+		{Metric: "node_filesystem_avail_bytes", NewName: "node_filesystem_avail"},
+		{Metric: "node_filesystem_free_bytes", NewName: "node_filesystem_free"},
+		{Metric: "node_network_receive_bytes_total", NewName: "node_network_receive_bytes"},
+		{Metric: "node_network_transmit_bytes_total", NewName: "node_network_transmit_bytes"},
+		{Metric: "node_disk_read_bytes_total", NewName: "node_disk_bytes_read"},
+		{Metric: "node_disk_written_bytes_total", NewName: "node_disk_bytes_written"},
+	},
+}
+
+// resourceRule renames the data points of the resource, dropping the resource and unit labels
+func resourceRule(metric string, resource string, newName string) RuleConfig {
+	return RuleConfig{
+		Metric:      metric,
+		MatchLabels: map[string]string{"resource": resource},
+		NewName:     newName,
+		DropLabels:  []string{"resource", "unit"},
+	}
+}
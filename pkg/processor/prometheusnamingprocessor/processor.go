@@ -0,0 +1,196 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusnamingprocessor
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	labelLe       = "le"
+	labelQuantile = "quantile"
+)
+
+// seriesSuffixes are the suffixes of the series of the flattened histograms and summaries
+var seriesSuffixes = []string{"_bucket", "_sum", "_count"}
+
+// prometheusNamingProcessor renames the metrics and labels to the Sumo Logic naming conventions
+type prometheusNamingProcessor struct {
+	rules                 map[string][]RuleConfig
+	labelRenames          map[string]string
+	normalizeBucketLabels bool
+}
+
+func newPrometheusNamingProcessor(cfg *Config) *prometheusNamingProcessor {
+	rules := map[string][]RuleConfig{}
+	for _, rule := range cfg.Rules {
+		rules[rule.Metric] = append(rules[rule.Metric], rule)
+	}
+	for _, preset := range cfg.Presets {
+		for _, rule := range presets[preset] {
+			rules[rule.Metric] = append(rules[rule.Metric], rule)
+		}
+	}
+	return &prometheusNamingProcessor{
+		rules:                 rules,
+		labelRenames:          cfg.LabelRenames,
+		normalizeBucketLabels: cfg.NormalizeBucketLabels,
+	}
+}
+
+// ProcessMetrics renames the metrics, moving the data points matched by the rules to the new metrics,
+// and renames the labels of all the data points
+func (p *prometheusNamingProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		ilms := rms.At(i).InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			// the metrics split by the rules are appended to the slice and are not matched again
+			count := metrics.Len()
+			emptied := make([]bool, count)
+			for k := 0; k < count; k++ {
+				emptied[k] = p.applyRules(metrics, metrics.At(k))
+			}
+			for k := 0; k < metrics.Len(); k++ {
+				forEachDataPoint(metrics.At(k), p.processLabels)
+			}
+			index := 0
+			metrics.RemoveIf(func(pdata.Metric) bool {
+				index++
+				return index <= count && emptied[index-1]
+			})
+		}
+	}
+	return md, nil
+}
+
+// applyRules applies the rules of the metric in order; the rules with the labels to match move
+// the matching data points to a new metric, while the first rule without them renames the metric.
+// It returns true if all the data points have been moved.
+func (p *prometheusNamingProcessor) applyRules(metrics pdata.MetricSlice, metric pdata.Metric) bool {
+	rules, suffix := p.rulesOf(metric.Name())
+	for _, rule := range rules {
+		if len(rule.MatchLabels) == 0 {
+			metric.SetName(rule.NewName + suffix)
+			forEachDataPoint(metric, func(attributes pdata.AttributeMap) {
+				dropLabels(attributes, rule.DropLabels)
+			})
+			return false
+		}
+
+		matches := func(attributes pdata.AttributeMap) bool {
+			return matchLabels(attributes, rule.MatchLabels)
+		}
+		if !anyDataPoint(metric, matches) {
+			continue
+		}
+		renamed := metrics.AppendEmpty()
+		metric.CopyTo(renamed)
+		renamed.SetName(rule.NewName + suffix)
+		removeDataPoints(renamed, func(attributes pdata.AttributeMap) bool { return !matches(attributes) })
+		forEachDataPoint(renamed, func(attributes pdata.AttributeMap) {
+			dropLabels(attributes, rule.DropLabels)
+		})
+		removeDataPoints(metric, matches)
+		if dataPointCount(metric) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rulesOf returns the rules of the metric, or of the flattened histogram or summary the series belongs to
+// together with the suffix of the series
+func (p *prometheusNamingProcessor) rulesOf(name string) ([]RuleConfig, string) {
+	if rules, ok := p.rules[name]; ok {
+		return rules, ""
+	}
+	for _, suffix := range seriesSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			if rules, ok := p.rules[strings.TrimSuffix(name, suffix)]; ok {
+				return rules, suffix
+			}
+		}
+	}
+	return nil, ""
+}
+
+// processLabels renames the labels, keeping the value of the new name if both are set,
+// and normalizes the bucket labels
+func (p *prometheusNamingProcessor) processLabels(attributes pdata.AttributeMap) {
+	for from, to := range p.labelRenames {
+		value, ok := attributes.Get(from)
+		if !ok {
+			continue
+		}
+		if _, exists := attributes.Get(to); !exists {
+			attributes.Insert(to, value)
+		}
+		attributes.Delete(from)
+	}
+	if p.normalizeBucketLabels {
+		normalizeBucketLabel(attributes, labelLe)
+		normalizeBucketLabel(attributes, labelQuantile)
+	}
+}
+
+// normalizeBucketLabel formats the bound of the bucket or the quantile the way Prometheus does,
+// e.g. Inf as +Inf and 0.50 as 0.5, so that the series of the same bucket are not split
+func normalizeBucketLabel(attributes pdata.AttributeMap, label string) {
+	value, ok := attributes.Get(label)
+	if !ok || value.Type() != pdata.AttributeValueTypeString {
+		return
+	}
+	bound, err := strconv.ParseFloat(value.StringVal(), 64)
+	if err != nil {
+		return
+	}
+	var formatted string
+	switch {
+	case math.IsInf(bound, 1):
+		formatted = "+Inf"
+	case math.IsInf(bound, -1):
+		formatted = "-Inf"
+	default:
+		formatted = strconv.FormatFloat(bound, 'g', -1, 64)
+	}
+	value.SetStringVal(formatted)
+}
+
+func isBucketLabel(label string) bool {
+	return label == labelLe || label == labelQuantile
+}
+
+func matchLabels(attributes pdata.AttributeMap, labels map[string]string) bool {
+	for label, expected := range labels {
+		value, ok := attributes.Get(label)
+		if !ok || pdata.AttributeValueToString(value) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func dropLabels(attributes pdata.AttributeMap, labels []string) {
+	for _, label := range labels {
+		attributes.Delete(label)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusnamingprocessor
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// addGauge adds the gauge with a data point for each of the label sets
+func addGauge(metrics pdata.MetricSlice, name string, labels ...map[string]string) {
+	metric := metrics.AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pdata.MetricDataTypeGauge)
+	for i, set := range labels {
+		dp := metric.Gauge().DataPoints().AppendEmpty()
+		dp.SetDoubleVal(float64(i))
+		for k, v := range set {
+			dp.Attributes().InsertString(k, v)
+		}
+	}
+}
+
+// collect returns the labels of the data points by the metric names
+func collect(metrics pdata.MetricSlice) map[string][]map[string]string {
+	result := map[string][]map[string]string{}
+	for i := 0; i < metrics.Len(); i++ {
+		metric := metrics.At(i)
+		points := []map[string]string{}
+		forEachDataPoint(metric, func(attributes pdata.AttributeMap) {
+			points = append(points, attributesToMap(attributes))
+		})
+		result[metric.Name()] = points
+	}
+	return result
+}
+
+func process(t *testing.T, cfg *Config, fill func(metrics pdata.MetricSlice)) pdata.MetricSlice {
+	md := pdata.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	fill(metrics)
+	_, err := newPrometheusNamingProcessor(cfg).ProcessMetrics(context.Background(), md)
+	require.NoError(t, err)
+	return metrics
+}
+
+func newConfig(modify func(cfg *Config)) *Config {
+	cfg := createDefaultConfig().(*Config)
+	modify(cfg)
+	return cfg
+}
+
+func TestKubeStateMetricsPreset(t *testing.T) {
+	cfg := newConfig(func(cfg *Config) { cfg.Presets = []string{"kube_state_metrics"} })
+	metrics := process(t, cfg, func(metrics pdata.MetricSlice) {
+		addGauge(metrics, "kube_pod_container_resource_requests",
+			map[string]string{"pod": "a", "resource": "cpu", "unit": "core"},
+			map[string]string{"pod": "a", "resource": "memory", "unit": "byte"},
+			map[string]string{"pod": "a", "resource": "nvidia_com_gpu", "unit": "integer"},
+		)
+		addGauge(metrics, "kube_node_status_capacity",
+			map[string]string{"node": "n", "resource": "pods", "unit": "integer"},
+		)
+		addGauge(metrics, "kube_horizontalpodautoscaler_spec_max_replicas", map[string]string{"hpa": "h"})
+		addGauge(metrics, "kube_pod_info", map[string]string{"pod": "a"})
+	})
+
+	assert.Equal(t, map[string][]map[string]string{
+		"kube_pod_container_resource_requests": {
+			{"pod": "a", "resource": "nvidia_com_gpu", "unit": "integer"},
+		},
+		"kube_pod_container_resource_requests_cpu_cores":    {{"pod": "a"}},
+		"kube_pod_container_resource_requests_memory_bytes": {{"pod": "a"}},
+		"kube_node_status_capacity_pods":                    {{"node": "n"}},
+		"kube_hpa_spec_max_replicas":                        {{"hpa": "h"}},
+		"kube_pod_info":                                     {{"pod": "a"}},
+	}, collect(metrics))
+
+	// the metric emptied by the rules is removed, while the split metrics are appended in order
+	names := []string{}
+	for i := 0; i < metrics.Len(); i++ {
+		names = append(names, metrics.At(i).Name())
+	}
+	assert.Equal(t, []string{
+		"kube_pod_container_resource_requests",
+		"kube_hpa_spec_max_replicas",
+		"kube_pod_info",
+		"kube_pod_container_resource_requests_cpu_cores",
+		"kube_pod_container_resource_requests_memory_bytes",
+		"kube_node_status_capacity_pods",
+	}, names)
+}
+
+func TestRulesOrder(t *testing.T) {
+	cfg := newConfig(func(cfg *Config) {
+		cfg.Presets = []string{"node_exporter"}
+		cfg.Rules = []RuleConfig{
+			{Metric: "node_cpu_seconds_total", MatchLabels: map[string]string{"mode": "idle"}, NewName: "node_cpu_idle", DropLabels: []string{"mode"}},
+			{Metric: "node_memory_MemFree_bytes", NewName: "node_memory_free"},
+		}
+	})
+	metrics := process(t, cfg, func(metrics pdata.MetricSlice) {
+		addGauge(metrics, "node_cpu_seconds_total",
+			map[string]string{"cpu": "0", "mode": "idle"},
+			map[string]string{"cpu": "0", "mode": "user"},
+		)
+		addGauge(metrics, "node_memory_MemFree_bytes", map[string]string{})
+		addGauge(metrics, "node_memory_MemTotal_bytes", map[string]string{})
+	})
+
+	assert.Equal(t, map[string][]map[string]string{
+		"node_cpu":             {{"cpu": "0", "mode": "user"}},
+		"node_cpu_idle":        {{"cpu": "0"}},
+		"node_memory_free":     {{}},
+		"node_memory_MemTotal": {{}},
+	}, collect(metrics))
+}
+
+func TestFlattenedSeries(t *testing.T) {
+	cfg := newConfig(func(cfg *Config) {
+		cfg.Rules = []RuleConfig{{Metric: "apiserver_request_duration_seconds", NewName: "apiserver_request_latencies"}}
+	})
+	metrics := process(t, cfg, func(metrics pdata.MetricSlice) {
+		addGauge(metrics, "apiserver_request_duration_seconds_bucket",
+			map[string]string{"le": "0.50"},
+			map[string]string{"le": "Inf"},
+		)
+		addGauge(metrics, "apiserver_request_duration_seconds_count", map[string]string{})
+		addGauge(metrics, "apiserver_request_duration_seconds_total", map[string]string{})
+
+		histogram := metrics.AppendEmpty()
+		histogram.SetName("apiserver_request_duration_seconds")
+		histogram.SetDataType(pdata.MetricDataTypeHistogram)
+		histogram.Histogram().DataPoints().AppendEmpty().Attributes().InsertString("verb", "GET")
+	})
+
+	assert.Equal(t, map[string][]map[string]string{
+		"apiserver_request_latencies_bucket":       {{"le": "0.5"}, {"le": "+Inf"}},
+		"apiserver_request_latencies_count":        {{}},
+		"apiserver_request_duration_seconds_total": {{}},
+		"apiserver_request_latencies":              {{"verb": "GET"}},
+	}, collect(metrics))
+}
+
+func TestLabelRenames(t *testing.T) {
+	cfg := newConfig(func(cfg *Config) {
+		cfg.LabelRenames = map[string]string{"kubernetes_namespace": "namespace", "kubernetes_pod_name": "pod"}
+		cfg.NormalizeBucketLabels = false
+	})
+	metrics := process(t, cfg, func(metrics pdata.MetricSlice) {
+		addGauge(metrics, "up",
+			map[string]string{"kubernetes_namespace": "default", "kubernetes_pod_name": "a", "pod": "b"},
+			map[string]string{"quantile": "0.50"},
+		)
+		empty := metrics.AppendEmpty()
+		empty.SetName("empty")
+	})
+
+	assert.Equal(t, map[string][]map[string]string{
+		"up": {
+			{"namespace": "default", "pod": "b"},
+			{"quantile": "0.50"},
+		},
+		// the metrics without data points are kept if no rule has emptied them
+		"empty": {},
+	}, collect(metrics))
+}
+
+func TestNormalizeBucketLabel(t *testing.T) {
+	for value, expected := range map[string]string{
+		"0.50":  "0.5",
+		"1e3":   "1000",
+		"+Inf":  "+Inf",
+		"inf":   "+Inf",
+		"-Inf":  "-Inf",
+		"0.999": "0.999",
+		"le":    "le",
+	} {
+		attributes := pdata.NewAttributeMap()
+		attributes.InsertString(labelLe, value)
+		normalizeBucketLabel(attributes, labelLe)
+		le, _ := attributes.Get(labelLe)
+		assert.Equal(t, expected, le.StringVal(), value)
+	}
+}
+
+func TestPresetsValid(t *testing.T) {
+	names := make([]string, 0, len(presets))
+	for name, rules := range presets {
+		names = append(names, name)
+		for _, rule := range rules {
+			assert.NoError(t, rule.validate(), rule.Metric)
+		}
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"kube_state_metrics", "node_exporter"}, names)
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
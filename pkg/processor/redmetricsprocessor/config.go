@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redmetricsprocessor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the RED metrics generation.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// MetricsExporter is the ID of the exporter the metrics are sent to, e.g. sumologic/metrics
+	MetricsExporter string `mapstructure:"metrics_exporter"`
+	// Interval is the period in which the metrics are sent
+	Interval time.Duration `mapstructure:"interval"`
+	// Dimensions are the attributes of the spans (or their resources) the metrics are grouped by,
+	// in addition to the service, operation and span kind. When empty, defaultDimensions are used.
+	Dimensions []string `mapstructure:"dimensions"`
+	// SpanKinds are the kinds of the spans the metrics are created from. When empty, defaultSpanKinds are used.
+	SpanKinds []string `mapstructure:"span_kinds"`
+	// LatencyBuckets are the explicit bounds of the duration histogram buckets, in milliseconds.
+	// When empty, defaultLatencyBuckets are used.
+	LatencyBuckets []float64 `mapstructure:"latency_buckets"`
+	// TranslateAttributes renames the dimensions to the Sumo Logic conventions, e.g. k8s.namespace.name to Namespace
+	TranslateAttributes bool `mapstructure:"translate_attributes"`
+}
+
+const (
+	defaultInterval = 60 * time.Second
+)
+
+const (
+	spanKindServer   = "server"
+	spanKindClient   = "client"
+	spanKindProducer = "producer"
+	spanKindConsumer = "consumer"
+	spanKindInternal = "internal"
+)
+
+var (
+	defaultDimensions = []string{"k8s.cluster.name", "k8s.namespace.name", "k8s.deployment.name"}
+	defaultSpanKinds  = []string{spanKindServer, spanKindConsumer}
+	// defaultLatencyBuckets are the same as the ones of the span metrics processor
+	defaultLatencyBuckets = []float64{2, 4, 6, 8, 10, 50, 100, 200, 400, 800, 1000, 1400, 2000, 5000, 10000, 15000}
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.MetricsExporter == "" {
+		return fmt.Errorf("metrics_exporter must not be empty")
+	}
+	if _, err := config.NewIDFromString(cfg.MetricsExporter); err != nil {
+		return fmt.Errorf("invalid metrics_exporter: %w", err)
+	}
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", cfg.Interval)
+	}
+	for _, kind := range cfg.SpanKinds {
+		switch kind {
+		case spanKindServer, spanKindClient, spanKindProducer, spanKindConsumer, spanKindInternal:
+		default:
+			return fmt.Errorf("unknown span kind %q, expected one of: %s, %s, %s, %s, %s", kind,
+				spanKindServer, spanKindClient, spanKindProducer, spanKindConsumer, spanKindInternal)
+		}
+	}
+	if !sort.Float64sAreSorted(cfg.LatencyBuckets) {
+		return fmt.Errorf("latency_buckets must be sorted")
+	}
+	return nil
+}
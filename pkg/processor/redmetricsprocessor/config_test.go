@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redmetricsprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "red_metrics_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	defaultCfg := factory.CreateDefaultConfig().(*Config)
+	defaultCfg.MetricsExporter = "sumologic/metrics"
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)], defaultCfg)
+
+	id := config.NewIDWithName(typeStr, "custom")
+	assert.Equal(t, cfg.Processors[id],
+		&Config{
+			ProcessorSettings:   config.NewProcessorSettings(id),
+			MetricsExporter:     "sumologic/metrics",
+			Interval:            30 * time.Second,
+			Dimensions:          []string{"k8s.namespace.name", "http.route"},
+			SpanKinds:           []string{"server"},
+			LatencyBuckets:      []float64{10, 100, 1000},
+			TranslateAttributes: false,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	validConfig := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.MetricsExporter = "sumologic"
+		return cfg
+	}
+	assert.NoError(t, validConfig().Validate())
+
+	cfg := validConfig()
+	cfg.MetricsExporter = ""
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.MetricsExporter = "sumologic/"
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.Interval = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.SpanKinds = []string{"SPAN_KIND_SERVER"}
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.LatencyBuckets = []float64{100, 10}
+	assert.Error(t, cfg.Validate())
+}
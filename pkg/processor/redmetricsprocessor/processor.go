@@ -0,0 +1,341 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redmetricsprocessor
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/attrhash"
+)
+
+const (
+	requestsMetricName = "service_requests_total"
+	errorsMetricName   = "service_errors_total"
+	durationMetricName = "service_request_duration_ms"
+
+	serviceNameKey = "service.name"
+	operationKey   = "operation"
+	spanKindKey    = "span.kind"
+
+	// unknownService is the service name of the spans without service.name, as in the OpenTelemetry SDKs
+	unknownService = "unknown_service"
+)
+
+// attributeTranslations maps OpenTelemetry attribute names to Sumo attribute names,
+// the same way as the Sumo Logic exporter does
+var attributeTranslations = map[string]string{
+	"cloud.account.id":        "AccountId",
+	"cloud.availability_zone": "AvailabilityZone",
+	"cloud.platform":          "aws_service",
+	"cloud.region":            "Region",
+	"host.id":                 "InstanceId",
+	"host.name":               "host",
+	"host.type":               "InstanceType",
+	"k8s.cluster.name":        "Cluster",
+	"k8s.container.name":      "container",
+	"k8s.daemonset.name":      "daemonset",
+	"k8s.deployment.name":     "Deployment",
+	"k8s.namespace.name":      "Namespace",
+	"k8s.node.name":           "node",
+	"k8s.pod.hostname":        "host",
+	"k8s.pod.name":            "pod",
+	"k8s.pod.uid":             "pod_id",
+	"k8s.replicaset.name":     "replicaset",
+	"k8s.statefulset.name":    "statefulset",
+	"service.name":            "service",
+}
+
+var spanKinds = map[string]pdata.SpanKind{
+	spanKindServer:   pdata.SpanKindServer,
+	spanKindClient:   pdata.SpanKindClient,
+	spanKindProducer: pdata.SpanKindProducer,
+	spanKindConsumer: pdata.SpanKindConsumer,
+	spanKindInternal: pdata.SpanKindInternal,
+}
+
+// redMetricsProcessor creates the request, error and duration metrics from the spans, sends them
+// to the metrics exporter and passes the spans through
+type redMetricsProcessor struct {
+	logger          *zap.Logger
+	nextConsumer    consumer.Traces
+	exporterID      config.ComponentID
+	metricsExporter component.MetricsExporter
+	interval        time.Duration
+	dimensions      []string
+	spanKinds       map[pdata.SpanKind]string
+	buckets         []float64
+	translate       bool
+	now             func() time.Time
+
+	mu             sync.Mutex
+	series         map[uint64]*series
+	startTimestamp pdata.Timestamp
+
+	started bool
+	stopCh  chan struct{}
+	done    chan struct{}
+}
+
+// series holds the metrics of the spans with the same dimensions
+type series struct {
+	attributes   pdata.AttributeMap
+	requests     uint64
+	errors       uint64
+	bucketCounts []uint64
+	durationSum  float64
+}
+
+var _ component.TracesProcessor = (*redMetricsProcessor)(nil)
+
+func newRedMetricsProcessor(logger *zap.Logger, cfg *Config, nextConsumer consumer.Traces) (*redMetricsProcessor, error) {
+	exporterID, err := config.NewIDFromString(cfg.MetricsExporter)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &redMetricsProcessor{
+		logger:       logger,
+		nextConsumer: nextConsumer,
+		exporterID:   exporterID,
+		interval:     cfg.Interval,
+		dimensions:   cfg.Dimensions,
+		spanKinds:    map[pdata.SpanKind]string{},
+		buckets:      cfg.LatencyBuckets,
+		translate:    cfg.TranslateAttributes,
+		now:          time.Now,
+		series:       map[uint64]*series{},
+		stopCh:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	if len(p.dimensions) == 0 {
+		p.dimensions = defaultDimensions
+	}
+	if len(p.buckets) == 0 {
+		p.buckets = defaultLatencyBuckets
+	}
+	kinds := cfg.SpanKinds
+	if len(kinds) == 0 {
+		kinds = defaultSpanKinds
+	}
+	for _, name := range kinds {
+		kind, ok := spanKinds[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown span kind %q", name)
+		}
+		p.spanKinds[kind] = name
+	}
+	return p, nil
+}
+
+// Start finds the metrics exporter and starts sending the metrics at the end of each interval
+func (p *redMetricsProcessor) Start(_ context.Context, host component.Host) error {
+	exporter, ok := host.GetExporters()[config.MetricsDataType][p.exporterID]
+	if !ok {
+		return fmt.Errorf("metrics exporter %s not found, it needs to be used in a metrics pipeline", p.exporterID)
+	}
+	metricsExporter, ok := exporter.(component.MetricsExporter)
+	if !ok {
+		return fmt.Errorf("exporter %s is not a metrics exporter", p.exporterID)
+	}
+	p.metricsExporter = metricsExporter
+
+	p.startTimestamp = pdata.TimestampFromTime(p.now())
+	p.started = true
+	go p.flushLoop()
+	return nil
+}
+
+// Shutdown sends the metrics for the current interval
+func (p *redMetricsProcessor) Shutdown(ctx context.Context) error {
+	if !p.started {
+		return nil
+	}
+	close(p.stopCh)
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Capabilities returns the consumer capabilities
+func (p *redMetricsProcessor) Capabilities() consumer.Capabilities {
+	return processorCapabilities
+}
+
+// ConsumeTraces updates the metrics with the spans of the configured kinds and passes the spans through
+func (p *redMetricsProcessor) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	p.mu.Lock()
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		resource := rss.At(i).Resource().Attributes()
+		ilss := rss.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				p.add(resource, spans.At(k))
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	return p.nextConsumer.ConsumeTraces(ctx, td)
+}
+
+func (p *redMetricsProcessor) add(resource pdata.AttributeMap, span pdata.Span) {
+	kind, ok := p.spanKinds[span.Kind()]
+	if !ok {
+		return
+	}
+
+	attributes := p.dimensionsOf(resource, span, kind)
+	key := attrhash.Attributes(fnv.New64a(), attributes)
+	s, ok := p.series[key]
+	if !ok {
+		s = &series{
+			attributes:   attributes,
+			bucketCounts: make([]uint64, len(p.buckets)+1),
+		}
+		p.series[key] = s
+	}
+
+	s.requests++
+	if span.Status().Code() == pdata.StatusCodeError {
+		s.errors++
+	}
+	duration := float64(span.EndTimestamp()-span.StartTimestamp()) / float64(time.Millisecond)
+	if span.EndTimestamp() < span.StartTimestamp() {
+		duration = 0
+	}
+	s.bucketCounts[sort.SearchFloat64s(p.buckets, duration)]++
+	s.durationSum += duration
+}
+
+// dimensionsOf returns the attributes of the metrics for the span
+func (p *redMetricsProcessor) dimensionsOf(resource pdata.AttributeMap, span pdata.Span, kind string) pdata.AttributeMap {
+	attributes := pdata.NewAttributeMap()
+	service := unknownService
+	if value, ok := resource.Get(serviceNameKey); ok && value.StringVal() != "" {
+		service = value.StringVal()
+	}
+	attributes.InsertString(p.attributeName(serviceNameKey), service)
+	attributes.InsertString(operationKey, span.Name())
+	attributes.InsertString(spanKindKey, kind)
+
+	for _, dimension := range p.dimensions {
+		value, ok := span.Attributes().Get(dimension)
+		if !ok {
+			value, ok = resource.Get(dimension)
+		}
+		if ok {
+			attributes.Insert(p.attributeName(dimension), value)
+		}
+	}
+	return attributes
+}
+
+func (p *redMetricsProcessor) attributeName(name string) string {
+	if !p.translate {
+		return name
+	}
+	if translated, ok := attributeTranslations[name]; ok {
+		return translated
+	}
+	return name
+}
+
+func (p *redMetricsProcessor) flushLoop() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.flush(context.Background())
+		case <-p.stopCh:
+			p.flush(context.Background())
+			return
+		}
+	}
+}
+
+// flush sends the current values of the cumulative metrics to the metrics exporter
+func (p *redMetricsProcessor) flush(ctx context.Context) {
+	now := pdata.TimestampFromTime(p.now())
+
+	p.mu.Lock()
+	if len(p.series) == 0 {
+		p.mu.Unlock()
+		return
+	}
+	md := pdata.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	requests := appendSum(metrics, requestsMetricName, "1")
+	errors := appendSum(metrics, errorsMetricName, "1")
+	duration := metrics.AppendEmpty()
+	duration.SetName(durationMetricName)
+	duration.SetUnit("ms")
+	duration.SetDataType(pdata.MetricDataTypeHistogram)
+	duration.Histogram().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+
+	for _, s := range p.series {
+		appendCount(requests, s.requests, s.attributes, p.startTimestamp, now)
+		appendCount(errors, s.errors, s.attributes, p.startTimestamp, now)
+
+		dp := duration.Histogram().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(p.startTimestamp)
+		dp.SetTimestamp(now)
+		dp.SetCount(s.requests)
+		dp.SetSum(s.durationSum)
+		dp.SetBucketCounts(append([]uint64(nil), s.bucketCounts...))
+		dp.SetExplicitBounds(p.buckets)
+		s.attributes.CopyTo(dp.Attributes())
+	}
+	p.mu.Unlock()
+
+	if err := p.metricsExporter.ConsumeMetrics(ctx, md); err != nil {
+		p.logger.Error("Failed to send the RED metrics", zap.Error(err))
+	}
+}
+
+func appendSum(metrics pdata.MetricSlice, name string, unit string) pdata.Sum {
+	metric := metrics.AppendEmpty()
+	metric.SetName(name)
+	metric.SetUnit(unit)
+	metric.SetDataType(pdata.MetricDataTypeSum)
+	metric.Sum().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+	metric.Sum().SetIsMonotonic(true)
+	return metric.Sum()
+}
+
+func appendCount(sum pdata.Sum, count uint64, attributes pdata.AttributeMap, start, now pdata.Timestamp) {
+	dp := sum.DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(now)
+	dp.SetIntVal(int64(count))
+	attributes.CopyTo(dp.Attributes())
+}
@@ -0,0 +1,238 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redmetricsprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// testExporter is a metrics exporter collecting the metrics
+type testExporter struct {
+	consumertest.MetricsSink
+}
+
+func (e *testExporter) Start(context.Context, component.Host) error { return nil }
+func (e *testExporter) Shutdown(context.Context) error              { return nil }
+
+// testHost provides the metrics exporters
+type testHost struct {
+	component.Host
+	exporters map[config.ComponentID]component.Exporter
+}
+
+func (h testHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return map[config.DataType]map[config.ComponentID]component.Exporter{config.MetricsDataType: h.exporters}
+}
+
+type testSpan struct {
+	service    string
+	deployment string
+	name       string
+	kind       pdata.SpanKind
+	duration   time.Duration
+	err        bool
+}
+
+func newTestTraces(spans ...testSpan) pdata.Traces {
+	td := pdata.NewTraces()
+	start := time.Unix(1600000000, 0)
+	for _, s := range spans {
+		rs := td.ResourceSpans().AppendEmpty()
+		if s.service != "" {
+			rs.Resource().Attributes().InsertString("service.name", s.service)
+		}
+		rs.Resource().Attributes().InsertString("k8s.namespace.name", "payments")
+		rs.Resource().Attributes().InsertString("k8s.pod.name", "api-1234")
+		span := rs.InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty()
+		span.SetName(s.name)
+		span.SetKind(s.kind)
+		span.SetStartTimestamp(pdata.TimestampFromTime(start))
+		span.SetEndTimestamp(pdata.TimestampFromTime(start.Add(s.duration)))
+		if s.deployment != "" {
+			span.Attributes().InsertString("k8s.deployment.name", s.deployment)
+		}
+		if s.err {
+			span.Status().SetCode(pdata.StatusCodeError)
+		}
+	}
+	return td
+}
+
+func newTestProcessor(t *testing.T, cfg *Config) (*redMetricsProcessor, *testExporter, *consumertest.TracesSink) {
+	cfg.MetricsExporter = "sumologic/metrics"
+	require.NoError(t, cfg.Validate())
+
+	tracesSink := &consumertest.TracesSink{}
+	p, err := newRedMetricsProcessor(zap.NewNop(), cfg, tracesSink)
+	require.NoError(t, err)
+
+	exporter := &testExporter{}
+	host := testHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.ComponentID]component.Exporter{
+			config.NewIDWithName("sumologic", "metrics"): exporter,
+		},
+	}
+	require.NoError(t, p.Start(context.Background(), host))
+	return p, exporter, tracesSink
+}
+
+// collectDataPoints returns the data points of the metrics by the metric name and operation
+func collectDataPoints(md pdata.Metrics) map[string]map[string]interface{} {
+	result := map[string]map[string]interface{}{}
+	metrics := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		metric := metrics.At(i)
+		byOperation := map[string]interface{}{}
+		switch metric.DataType() {
+		case pdata.MetricDataTypeSum:
+			dps := metric.Sum().DataPoints()
+			for j := 0; j < dps.Len(); j++ {
+				operation, _ := dps.At(j).Attributes().Get(operationKey)
+				byOperation[operation.StringVal()] = dps.At(j)
+			}
+		case pdata.MetricDataTypeHistogram:
+			dps := metric.Histogram().DataPoints()
+			for j := 0; j < dps.Len(); j++ {
+				operation, _ := dps.At(j).Attributes().Get(operationKey)
+				byOperation[operation.StringVal()] = dps.At(j)
+			}
+		}
+		result[metric.Name()] = byOperation
+	}
+	return result
+}
+
+func TestREDMetrics(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LatencyBuckets = []float64{10, 100}
+	p, exporter, tracesSink := newTestProcessor(t, cfg)
+
+	td := newTestTraces(
+		testSpan{service: "api", deployment: "api", name: "GET /orders", kind: pdata.SpanKindServer, duration: 5 * time.Millisecond},
+		testSpan{service: "api", deployment: "api", name: "GET /orders", kind: pdata.SpanKindServer, duration: 50 * time.Millisecond, err: true},
+		testSpan{service: "api", deployment: "api", name: "GET /orders", kind: pdata.SpanKindServer, duration: 500 * time.Millisecond},
+		testSpan{service: "api", deployment: "api", name: "SELECT orders", kind: pdata.SpanKindClient, duration: 5 * time.Millisecond},
+		testSpan{name: "process", kind: pdata.SpanKindConsumer, duration: time.Millisecond},
+	)
+	require.NoError(t, p.ConsumeTraces(context.Background(), td))
+	// the spans are passed through
+	assert.Equal(t, 5, tracesSink.SpanCount())
+
+	p.flush(context.Background())
+	require.Len(t, exporter.AllMetrics(), 1)
+	dps := collectDataPoints(exporter.AllMetrics()[0])
+
+	// the client spans are not counted by default
+	require.Len(t, dps[requestsMetricName], 2)
+
+	requests := dps[requestsMetricName]["GET /orders"].(pdata.NumberDataPoint)
+	assert.Equal(t, int64(3), requests.IntVal())
+	assert.Equal(t, map[string]string{
+		"service":    "api",
+		"operation":  "GET /orders",
+		"span.kind":  "server",
+		"Namespace":  "payments",
+		"Deployment": "api",
+	}, attributesToMap(requests.Attributes()))
+	assert.Equal(t, p.startTimestamp, requests.StartTimestamp())
+
+	errors := dps[errorsMetricName]["GET /orders"].(pdata.NumberDataPoint)
+	assert.Equal(t, int64(1), errors.IntVal())
+
+	duration := dps[durationMetricName]["GET /orders"].(pdata.HistogramDataPoint)
+	assert.Equal(t, uint64(3), duration.Count())
+	assert.Equal(t, 555.0, duration.Sum())
+	assert.Equal(t, []uint64{1, 1, 1}, duration.BucketCounts())
+	assert.Equal(t, []float64{10, 100}, duration.ExplicitBounds())
+
+	consumer := dps[requestsMetricName]["process"].(pdata.NumberDataPoint)
+	assert.Equal(t, map[string]string{
+		"service":   unknownService,
+		"operation": "process",
+		"span.kind": "consumer",
+		"Namespace": "payments",
+	}, attributesToMap(consumer.Attributes()))
+	assert.Equal(t, int64(0), dps[errorsMetricName]["process"].(pdata.NumberDataPoint).IntVal())
+
+	// the metrics are cumulative
+	require.NoError(t, p.ConsumeTraces(context.Background(), newTestTraces(
+		testSpan{service: "api", deployment: "api", name: "GET /orders", kind: pdata.SpanKindServer, duration: 5 * time.Millisecond},
+	)))
+	p.flush(context.Background())
+	require.Len(t, exporter.AllMetrics(), 2)
+	dps = collectDataPoints(exporter.AllMetrics()[1])
+	assert.Equal(t, int64(4), dps[requestsMetricName]["GET /orders"].(pdata.NumberDataPoint).IntVal())
+	assert.Equal(t, int64(1), dps[requestsMetricName]["process"].(pdata.NumberDataPoint).IntVal())
+
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Len(t, exporter.AllMetrics(), 3)
+}
+
+func TestUntranslatedDimensions(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.TranslateAttributes = false
+	cfg.Dimensions = []string{"k8s.pod.name"}
+	cfg.SpanKinds = []string{spanKindClient}
+	p, exporter, _ := newTestProcessor(t, cfg)
+
+	require.NoError(t, p.ConsumeTraces(context.Background(), newTestTraces(
+		testSpan{service: "api", name: "GET /orders", kind: pdata.SpanKindServer},
+		testSpan{service: "api", name: "SELECT orders", kind: pdata.SpanKindClient},
+	)))
+	p.flush(context.Background())
+
+	require.Len(t, exporter.AllMetrics(), 1)
+	dps := collectDataPoints(exporter.AllMetrics()[0])
+	require.Len(t, dps[requestsMetricName], 1)
+	requests := dps[requestsMetricName]["SELECT orders"].(pdata.NumberDataPoint)
+	assert.Equal(t, map[string]string{
+		"service.name": "api",
+		"operation":    "SELECT orders",
+		"span.kind":    "client",
+		"k8s.pod.name": "api-1234",
+	}, attributesToMap(requests.Attributes()))
+}
+
+func TestMissingExporter(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MetricsExporter = "sumologic/metrics"
+	p, err := newRedMetricsProcessor(zap.NewNop(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	host := testHost{Host: componenttest.NewNopHost(), exporters: map[config.ComponentID]component.Exporter{}}
+	assert.Error(t, p.Start(context.Background(), host))
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
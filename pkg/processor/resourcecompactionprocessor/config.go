@@ -0,0 +1,30 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcecompactionprocessor
+
+import (
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the resource compaction.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// MergeInstrumentationLibraries merges the instrumentation libraries with the same name and version
+	// within each of the merged resources
+	MergeInstrumentationLibraries bool `mapstructure:"merge_instrumentation_libraries"`
+}
+
+const defaultMergeInstrumentationLibraries = true
@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcecompactionprocessor
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// resourceCompactionProcessor merges the entries of the resources with the same attributes within a batch
+type resourceCompactionProcessor struct {
+	mergeLibraries bool
+}
+
+func newResourceCompactionProcessor(cfg *Config) *resourceCompactionProcessor {
+	return &resourceCompactionProcessor{
+		mergeLibraries: cfg.MergeInstrumentationLibraries,
+	}
+}
+
+// ProcessLogs moves the log records of each resource to its first entry in the batch
+func (p *resourceCompactionProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	resources := map[string]pdata.ResourceLogs{}
+	ld.ResourceLogs().RemoveIf(func(rl pdata.ResourceLogs) bool {
+		key := resourceKey(rl.Resource())
+		first, ok := resources[key]
+		if !ok {
+			resources[key] = rl
+			return false
+		}
+		rl.InstrumentationLibraryLogs().MoveAndAppendTo(first.InstrumentationLibraryLogs())
+		return true
+	})
+
+	if p.mergeLibraries {
+		for _, rl := range resources {
+			libraries := map[string]pdata.InstrumentationLibraryLogs{}
+			rl.InstrumentationLibraryLogs().RemoveIf(func(ill pdata.InstrumentationLibraryLogs) bool {
+				key := libraryKey(ill.InstrumentationLibrary())
+				first, ok := libraries[key]
+				if !ok {
+					libraries[key] = ill
+					return false
+				}
+				ill.Logs().MoveAndAppendTo(first.Logs())
+				return true
+			})
+		}
+	}
+	return ld, nil
+}
+
+// ProcessMetrics moves the metrics of each resource to its first entry in the batch
+func (p *resourceCompactionProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	resources := map[string]pdata.ResourceMetrics{}
+	md.ResourceMetrics().RemoveIf(func(rm pdata.ResourceMetrics) bool {
+		key := resourceKey(rm.Resource())
+		first, ok := resources[key]
+		if !ok {
+			resources[key] = rm
+			return false
+		}
+		rm.InstrumentationLibraryMetrics().MoveAndAppendTo(first.InstrumentationLibraryMetrics())
+		return true
+	})
+
+	if p.mergeLibraries {
+		for _, rm := range resources {
+			libraries := map[string]pdata.InstrumentationLibraryMetrics{}
+			rm.InstrumentationLibraryMetrics().RemoveIf(func(ilm pdata.InstrumentationLibraryMetrics) bool {
+				key := libraryKey(ilm.InstrumentationLibrary())
+				first, ok := libraries[key]
+				if !ok {
+					libraries[key] = ilm
+					return false
+				}
+				ilm.Metrics().MoveAndAppendTo(first.Metrics())
+				return true
+			})
+		}
+	}
+	return md, nil
+}
+
+// ProcessTraces moves the spans of each resource to its first entry in the batch
+func (p *resourceCompactionProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+	resources := map[string]pdata.ResourceSpans{}
+	td.ResourceSpans().RemoveIf(func(rs pdata.ResourceSpans) bool {
+		key := resourceKey(rs.Resource())
+		first, ok := resources[key]
+		if !ok {
+			resources[key] = rs
+			return false
+		}
+		rs.InstrumentationLibrarySpans().MoveAndAppendTo(first.InstrumentationLibrarySpans())
+		return true
+	})
+
+	if p.mergeLibraries {
+		for _, rs := range resources {
+			libraries := map[string]pdata.InstrumentationLibrarySpans{}
+			rs.InstrumentationLibrarySpans().RemoveIf(func(ils pdata.InstrumentationLibrarySpans) bool {
+				key := libraryKey(ils.InstrumentationLibrary())
+				first, ok := libraries[key]
+				if !ok {
+					libraries[key] = ils
+					return false
+				}
+				ils.Spans().MoveAndAppendTo(first.Spans())
+				return true
+			})
+		}
+	}
+	return td, nil
+}
+
+// resourceKey returns the attributes of the resource in the order of their names, with their types,
+// so that the resources with the same attributes have the same key
+func resourceKey(resource pdata.Resource) string {
+	attributes := resource.Attributes()
+	names := make([]string, 0, attributes.Len())
+	attributes.Range(func(name string, _ pdata.AttributeValue) bool {
+		names = append(names, name)
+		return true
+	})
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		value, _ := attributes.Get(name)
+		writeField(&b, name)
+		writeField(&b, value.Type().String())
+		writeField(&b, pdata.AttributeValueToString(value))
+	}
+	return b.String()
+}
+
+func libraryKey(library pdata.InstrumentationLibrary) string {
+	var b strings.Builder
+	writeField(&b, library.Name())
+	writeField(&b, library.Version())
+	return b.String()
+}
+
+// writeField writes the length of the value before it, so that no two different sets of fields
+// result in the same key
+func writeField(b *strings.Builder, value string) {
+	b.WriteString(strconv.Itoa(len(value)))
+	b.WriteByte(':')
+	b.WriteString(value)
+}
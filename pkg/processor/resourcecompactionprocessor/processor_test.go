@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcecompactionprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// testEntry is a resource entry with a single instrumentation library and record
+type testEntry struct {
+	pod     string
+	library string
+	record  string
+}
+
+func newTestProcessor(mergeLibraries bool) *resourceCompactionProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MergeInstrumentationLibraries = mergeLibraries
+	return newResourceCompactionProcessor(cfg)
+}
+
+func setTestResource(resource pdata.Resource, pod string) {
+	resource.Attributes().InsertString("k8s.pod.name", pod)
+	resource.Attributes().InsertString("k8s.namespace.name", "default")
+}
+
+func newTestLogs(entries ...testEntry) pdata.Logs {
+	ld := pdata.NewLogs()
+	for _, entry := range entries {
+		rl := ld.ResourceLogs().AppendEmpty()
+		setTestResource(rl.Resource(), entry.pod)
+		ill := rl.InstrumentationLibraryLogs().AppendEmpty()
+		ill.InstrumentationLibrary().SetName(entry.library)
+		ill.Logs().AppendEmpty().SetName(entry.record)
+	}
+	return ld
+}
+
+// logsSummary returns the pod, the libraries and the records of each of the resources
+func logsSummary(ld pdata.Logs) []map[string][]string {
+	var result []map[string][]string
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		pod, _ := rls.At(i).Resource().Attributes().Get("k8s.pod.name")
+		summary := map[string][]string{"pod": {pdata.AttributeValueToString(pod)}}
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			library := ills.At(j).InstrumentationLibrary().Name()
+			summary["libraries"] = append(summary["libraries"], library)
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				summary[library] = append(summary[library], logs.At(k).Name())
+			}
+		}
+		result = append(result, summary)
+	}
+	return result
+}
+
+func TestCompactLogs(t *testing.T) {
+	entries := []testEntry{
+		{pod: "app-1", library: "filelog", record: "a"},
+		{pod: "app-2", library: "filelog", record: "b"},
+		{pod: "app-1", library: "journald", record: "c"},
+		{pod: "app-1", library: "filelog", record: "d"},
+	}
+
+	testcases := []struct {
+		name           string
+		mergeLibraries bool
+		expected       []map[string][]string
+	}{
+		{
+			name:           "merge libraries",
+			mergeLibraries: true,
+			expected: []map[string][]string{
+				{"pod": {"app-1"}, "libraries": {"filelog", "journald"}, "filelog": {"a", "d"}, "journald": {"c"}},
+				{"pod": {"app-2"}, "libraries": {"filelog"}, "filelog": {"b"}},
+			},
+		},
+		{
+			name:           "resources only",
+			mergeLibraries: false,
+			expected: []map[string][]string{
+				{"pod": {"app-1"}, "libraries": {"filelog", "journald", "filelog"}, "filelog": {"a", "d"}, "journald": {"c"}},
+				{"pod": {"app-2"}, "libraries": {"filelog"}, "filelog": {"b"}},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ld, err := newTestProcessor(tc.mergeLibraries).ProcessLogs(context.Background(), newTestLogs(entries...))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, logsSummary(ld))
+		})
+	}
+}
+
+func TestCompactDistinguishesAttributes(t *testing.T) {
+	ld := newTestLogs(
+		testEntry{pod: "app", library: "filelog", record: "a"},
+		testEntry{pod: "app", library: "filelog", record: "b"},
+		testEntry{pod: "app", library: "filelog", record: "c"},
+		testEntry{pod: "app", library: "filelog", record: "d"},
+	)
+	rls := ld.ResourceLogs()
+	// the same attributes in another order
+	rls.At(1).Resource().Attributes().Clear()
+	rls.At(1).Resource().Attributes().InsertString("k8s.namespace.name", "default")
+	rls.At(1).Resource().Attributes().InsertString("k8s.pod.name", "app")
+	// the same value of another type
+	rls.At(2).Resource().Attributes().InsertInt("replica", 1)
+	rls.At(3).Resource().Attributes().InsertString("replica", "1")
+
+	ld, err := newTestProcessor(true).ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+	summary := logsSummary(ld)
+	require.Len(t, summary, 3)
+	assert.Equal(t, []string{"a", "b"}, summary[0]["filelog"])
+	assert.Equal(t, []string{"c"}, summary[1]["filelog"])
+	assert.Equal(t, []string{"d"}, summary[2]["filelog"])
+}
+
+func TestCompactMetrics(t *testing.T) {
+	md := pdata.NewMetrics()
+	for _, entry := range []testEntry{
+		{pod: "app-1", library: "kubeletstats", record: "cpu"},
+		{pod: "app-2", library: "kubeletstats", record: "cpu"},
+		{pod: "app-1", library: "kubeletstats", record: "memory"},
+	} {
+		rm := md.ResourceMetrics().AppendEmpty()
+		setTestResource(rm.Resource(), entry.pod)
+		ilm := rm.InstrumentationLibraryMetrics().AppendEmpty()
+		ilm.InstrumentationLibrary().SetName(entry.library)
+		ilm.Metrics().AppendEmpty().SetName(entry.record)
+	}
+
+	md, err := newTestProcessor(true).ProcessMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, 3, md.MetricCount())
+	rms := md.ResourceMetrics()
+	require.Equal(t, 2, rms.Len())
+	require.Equal(t, 1, rms.At(0).InstrumentationLibraryMetrics().Len())
+	metrics := rms.At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+	assert.Equal(t, "cpu", metrics.At(0).Name())
+	assert.Equal(t, "memory", metrics.At(1).Name())
+}
+
+func TestCompactTraces(t *testing.T) {
+	td := pdata.NewTraces()
+	for _, entry := range []testEntry{
+		{pod: "app-1", library: "http", record: "GET /"},
+		{pod: "app-1", library: "sql", record: "SELECT"},
+		{pod: "app-2", library: "http", record: "GET /"},
+		{pod: "app-1", library: "http", record: "POST /"},
+	} {
+		rs := td.ResourceSpans().AppendEmpty()
+		setTestResource(rs.Resource(), entry.pod)
+		ils := rs.InstrumentationLibrarySpans().AppendEmpty()
+		ils.InstrumentationLibrary().SetName(entry.library)
+		ils.Spans().AppendEmpty().SetName(entry.record)
+	}
+
+	td, err := newTestProcessor(true).ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+	assert.Equal(t, 4, td.SpanCount())
+	rss := td.ResourceSpans()
+	require.Equal(t, 2, rss.Len())
+	ilss := rss.At(0).InstrumentationLibrarySpans()
+	require.Equal(t, 2, ilss.Len())
+	assert.Equal(t, "http", ilss.At(0).InstrumentationLibrary().Name())
+	require.Equal(t, 2, ilss.At(0).Spans().Len())
+	assert.Equal(t, "POST /", ilss.At(0).Spans().At(1).Name())
+	assert.Equal(t, "sql", ilss.At(1).InstrumentationLibrary().Name())
+}
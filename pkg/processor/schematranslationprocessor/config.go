@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schematranslationprocessor
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the schema translation.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// SchemaFile is the path of the schema file, in the OpenTelemetry schema file format,
+	// describing the changes between the versions of the semantic conventions
+	SchemaFile string `mapstructure:"schema_file"`
+	// TargetSchemaURL is the schema URL the data is translated to, e.g. https://opentelemetry.io/schemas/1.7.0
+	TargetSchemaURL string `mapstructure:"target_schema_url"`
+	// DefaultSchemaURL is the schema URL assumed for the data without one. When empty, such data is not translated.
+	DefaultSchemaURL string `mapstructure:"default_schema_url"`
+}
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.SchemaFile == "" {
+		return fmt.Errorf("schema_file must not be empty")
+	}
+	if _, _, err := parseSchemaURL(cfg.TargetSchemaURL); err != nil {
+		return fmt.Errorf("invalid target_schema_url: %w", err)
+	}
+	if cfg.DefaultSchemaURL != "" {
+		if _, _, err := parseSchemaURL(cfg.DefaultSchemaURL); err != nil {
+			return fmt.Errorf("invalid default_schema_url: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schematranslationprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "schema_translation_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			SchemaFile:        "./testdata/schema.yaml",
+			TargetSchemaURL:   "https://example.com/schemas/1.2.0",
+			DefaultSchemaURL:  "https://example.com/schemas/1.0.0",
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	validConfig := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.SchemaFile = "schema.yaml"
+		cfg.TargetSchemaURL = "https://opentelemetry.io/schemas/1.7.0"
+		return cfg
+	}
+	assert.NoError(t, validConfig().Validate())
+
+	cfg := validConfig()
+	cfg.SchemaFile = ""
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.TargetSchemaURL = ""
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.TargetSchemaURL = "https://opentelemetry.io/schemas/latest"
+	assert.Error(t, cfg.Validate())
+
+	cfg = validConfig()
+	cfg.DefaultSchemaURL = "https://opentelemetry.io/schemas/"
+	assert.Error(t, cfg.Validate())
+}
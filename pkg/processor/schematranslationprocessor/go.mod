@@ -0,0 +1,14 @@
+module github.com/open-telemetry/opentelemetry-collector-contrib/processor/schematranslationprocessor
+
+go 1.14
+
+require (
+	github.com/StackExchange/wmi v1.2.1 // indirect
+	github.com/pquerna/cachecontrol v0.2.0 // indirect
+	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/collector v0.33.0
+	go.opentelemetry.io/collector/model v0.33.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+replace go.opentelemetry.io/collector => github.com/SumoLogic/opentelemetry-collector v0.33.0-sumo-1
@@ -0,0 +1,273 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schematranslationprocessor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// schemaTranslationProcessor translates the attribute, span event and metric names between the versions
+// of the semantic conventions
+type schemaTranslationProcessor struct {
+	schema     *schema
+	target     string
+	defaultURL string
+}
+
+func newSchemaTranslationProcessor(cfg *Config) (*schemaTranslationProcessor, error) {
+	s, err := loadSchema(cfg.SchemaFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateTarget(cfg.TargetSchemaURL); err != nil {
+		return nil, err
+	}
+	return &schemaTranslationProcessor{
+		schema:     s,
+		target:     cfg.TargetSchemaURL,
+		defaultURL: cfg.DefaultSchemaURL,
+	}, nil
+}
+
+// steps returns the translation steps from the schema URL, or false when the data cannot be translated
+func (p *schemaTranslationProcessor) steps(url string) ([]step, bool) {
+	if url == "" {
+		url = p.defaultURL
+	}
+	if url == "" {
+		return nil, false
+	}
+	return p.schema.steps(url, p.target)
+}
+
+// ProcessTraces translates the resources, spans and span events
+func (p *schemaTranslationProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		resourceURL := rs.SchemaUrl()
+		if steps, ok := p.steps(resourceURL); ok {
+			translateResource(rs.Resource().Attributes(), steps)
+			rs.SetSchemaUrl(p.target)
+		}
+
+		ilss := rs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			ils := ilss.At(j)
+			steps, ok := p.steps(schemaURLOf(ils.SchemaUrl(), resourceURL))
+			if !ok {
+				continue
+			}
+			spans := ils.Spans()
+			for k := 0; k < spans.Len(); k++ {
+				translateSpan(spans.At(k), steps)
+			}
+			if ils.SchemaUrl() != "" {
+				ils.SetSchemaUrl(p.target)
+			}
+		}
+	}
+	return td, nil
+}
+
+// ProcessMetrics translates the resources, metric names and data point attributes
+func (p *schemaTranslationProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resourceURL := rm.SchemaUrl()
+		if steps, ok := p.steps(resourceURL); ok {
+			translateResource(rm.Resource().Attributes(), steps)
+			rm.SetSchemaUrl(p.target)
+		}
+
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			ilm := ilms.At(j)
+			steps, ok := p.steps(schemaURLOf(ilm.SchemaUrl(), resourceURL))
+			if !ok {
+				continue
+			}
+			metrics := ilm.Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				translateMetric(metrics.At(k), steps)
+			}
+			if ilm.SchemaUrl() != "" {
+				ilm.SetSchemaUrl(p.target)
+			}
+		}
+	}
+	return md, nil
+}
+
+// ProcessLogs translates the resources and log record attributes
+func (p *schemaTranslationProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resourceURL := rl.SchemaUrl()
+		if steps, ok := p.steps(resourceURL); ok {
+			translateResource(rl.Resource().Attributes(), steps)
+			rl.SetSchemaUrl(p.target)
+		}
+
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			ill := ills.At(j)
+			steps, ok := p.steps(schemaURLOf(ill.SchemaUrl(), resourceURL))
+			if !ok {
+				continue
+			}
+			logs := ill.Logs()
+			for k := 0; k < logs.Len(); k++ {
+				for _, st := range steps {
+					renameAll(logs.At(k).Attributes(), st, st.version.all, st.version.logs)
+				}
+			}
+			if ill.SchemaUrl() != "" {
+				ill.SetSchemaUrl(p.target)
+			}
+		}
+	}
+	return ld, nil
+}
+
+// schemaURLOf returns the schema URL of the instrumentation library data, which defaults to the one of the resource
+func schemaURLOf(libraryURL string, resourceURL string) string {
+	if libraryURL != "" {
+		return libraryURL
+	}
+	return resourceURL
+}
+
+func translateResource(attributes pdata.AttributeMap, steps []step) {
+	for _, st := range steps {
+		renameAll(attributes, st, st.version.all, st.version.resources)
+	}
+}
+
+func translateSpan(span pdata.Span, steps []step) {
+	for _, st := range steps {
+		for _, c := range st.ordered(st.version.all, st.version.spans) {
+			if c.attributes != nil && c.appliesTo(span.Name()) {
+				renameAttributes(span.Attributes(), c.attributes.get(st.backward))
+			}
+		}
+
+		events := span.Events()
+		for i := 0; i < events.Len(); i++ {
+			event := events.At(i)
+			for _, c := range st.ordered(st.version.all, st.version.spanEvents) {
+				if c.names != nil {
+					if name, ok := c.names.get(st.backward)[event.Name()]; ok {
+						event.SetName(name)
+					}
+				}
+				if c.attributes != nil && c.appliesTo(span.Name()) &&
+					(c.applyToEvents == nil || c.applyToEvents[event.Name()]) {
+					renameAttributes(event.Attributes(), c.attributes.get(st.backward))
+				}
+			}
+		}
+	}
+}
+
+func translateMetric(metric pdata.Metric, steps []step) {
+	for _, st := range steps {
+		for _, c := range st.ordered(st.version.all, st.version.metrics) {
+			if c.names != nil {
+				if name, ok := c.names.get(st.backward)[metric.Name()]; ok {
+					metric.SetName(name)
+				}
+			}
+			if c.attributes != nil && c.appliesTo(metric.Name()) {
+				renames := c.attributes.get(st.backward)
+				forEachDataPoint(metric, func(attributes pdata.AttributeMap) {
+					renameAttributes(attributes, renames)
+				})
+			}
+		}
+	}
+}
+
+func forEachDataPoint(metric pdata.Metric, fn func(pdata.AttributeMap)) {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
+
+// renameAll applies the attribute renames of the "all" section and of the signal section
+func renameAll(attributes pdata.AttributeMap, st step, all []change, section []change) {
+	for _, c := range st.ordered(all, section) {
+		if c.attributes != nil {
+			renameAttributes(attributes, c.attributes.get(st.backward))
+		}
+	}
+}
+
+// ordered returns the changes of the "all" section and of the signal section in the order they are applied in:
+// the "all" changes are applied first when upgrading and last when downgrading
+func (st step) ordered(all []change, section []change) []change {
+	changes := make([]change, 0, len(all)+len(section))
+	changes = append(changes, all...)
+	changes = append(changes, section...)
+	if !st.backward {
+		return changes
+	}
+	for i, j := 0, len(changes)-1; i < j; i, j = i+1, j-1 {
+		changes[i], changes[j] = changes[j], changes[i]
+	}
+	return changes
+}
+
+// appliesTo checks if the change applies to the span or metric with the name
+func (c change) appliesTo(name string) bool {
+	return c.applyTo == nil || c.applyTo[name]
+}
+
+// renameAttributes renames the attributes, keeping the value of the new name if both are set
+func renameAttributes(attributes pdata.AttributeMap, renames map[string]string) {
+	for oldName, newName := range renames {
+		value, ok := attributes.Get(oldName)
+		if !ok {
+			continue
+		}
+		if _, exists := attributes.Get(newName); !exists {
+			attributes.Insert(newName, value)
+		}
+		attributes.Delete(oldName)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schematranslationprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	schemaV100 = "https://example.com/schemas/1.0.0"
+	schemaV110 = "https://example.com/schemas/1.1.0"
+	schemaV120 = "https://example.com/schemas/1.2.0"
+)
+
+func newTestProcessor(t *testing.T, target string, defaultURL string) *schemaTranslationProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.SchemaFile = "testdata/schema.yaml"
+	cfg.TargetSchemaURL = target
+	cfg.DefaultSchemaURL = defaultURL
+	require.NoError(t, cfg.Validate())
+	p, err := newSchemaTranslationProcessor(cfg)
+	require.NoError(t, err)
+	return p
+}
+
+func newTestTraces(resourceURL string, libraryURL string) pdata.Traces {
+	td := pdata.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.SetSchemaUrl(resourceURL)
+	rs.Resource().Attributes().InsertString("k8s.cluster", "prod")
+	ils := rs.InstrumentationLibrarySpans().AppendEmpty()
+	ils.SetSchemaUrl(libraryURL)
+
+	span := ils.Spans().AppendEmpty()
+	span.SetName("SELECT orders")
+	span.Attributes().InsertString("peer.service", "db")
+	span.Attributes().InsertInt("http.status", 500)
+	event := span.Events().AppendEmpty()
+	event.SetName("error")
+	event.Attributes().InsertString("message", "timeout")
+
+	other := ils.Spans().AppendEmpty()
+	other.SetName("GET /orders")
+	other.Attributes().InsertString("peer.service", "api")
+	return td
+}
+
+func TestTracesUpgrade(t *testing.T) {
+	p := newTestProcessor(t, schemaV120, "")
+
+	td, err := p.ProcessTraces(context.Background(), newTestTraces(schemaV100, ""))
+	require.NoError(t, err)
+
+	rs := td.ResourceSpans().At(0)
+	assert.Equal(t, schemaV120, rs.SchemaUrl())
+	assert.Equal(t, map[string]string{"k8s.cluster.name": "prod"}, attributesToMap(rs.Resource().Attributes()))
+
+	ils := rs.InstrumentationLibrarySpans().At(0)
+	assert.Equal(t, "", ils.SchemaUrl())
+	span := ils.Spans().At(0)
+	assert.Equal(t, map[string]string{"net.peer.service": "db", "http.status_code": "500"}, attributesToMap(span.Attributes()))
+	event := span.Events().At(0)
+	assert.Equal(t, "exception", event.Name())
+	assert.Equal(t, map[string]string{"exception.message": "timeout"}, attributesToMap(event.Attributes()))
+
+	// the rename only applies to the listed spans
+	other := ils.Spans().At(1)
+	assert.Equal(t, map[string]string{"peer.service": "api"}, attributesToMap(other.Attributes()))
+}
+
+func TestTracesDowngrade(t *testing.T) {
+	p := newTestProcessor(t, schemaV100, "")
+
+	upgraded, err := newTestProcessor(t, schemaV120, "").ProcessTraces(context.Background(), newTestTraces(schemaV100, ""))
+	require.NoError(t, err)
+	td, err := p.ProcessTraces(context.Background(), upgraded)
+	require.NoError(t, err)
+
+	assert.Equal(t, newTestTraces(schemaV100, ""), td)
+}
+
+func TestTracesSchemaURLs(t *testing.T) {
+	// the data without a schema URL is not translated
+	p := newTestProcessor(t, schemaV110, "")
+	td, err := p.ProcessTraces(context.Background(), newTestTraces("", ""))
+	require.NoError(t, err)
+	assert.Equal(t, newTestTraces("", ""), td)
+
+	// unless there is a default schema URL
+	p = newTestProcessor(t, schemaV110, schemaV100)
+	td, err = p.ProcessTraces(context.Background(), newTestTraces("", ""))
+	require.NoError(t, err)
+	assert.Equal(t, schemaV110, td.ResourceSpans().At(0).SchemaUrl())
+	_, ok := td.ResourceSpans().At(0).Resource().Attributes().Get("k8s.cluster.name")
+	assert.True(t, ok)
+
+	// the schema URL of the instrumentation library takes precedence
+	td, err = p.ProcessTraces(context.Background(), newTestTraces(schemaV100, schemaV110))
+	require.NoError(t, err)
+	ils := td.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0)
+	assert.Equal(t, schemaV110, ils.SchemaUrl())
+	assert.Equal(t, "error", ils.Spans().At(0).Events().At(0).Name())
+
+	// the data of other schema families is not translated
+	td, err = p.ProcessTraces(context.Background(), newTestTraces("https://opentelemetry.io/schemas/1.0.0", ""))
+	require.NoError(t, err)
+	assert.Equal(t, newTestTraces("https://opentelemetry.io/schemas/1.0.0", ""), td)
+}
+
+func TestMetrics(t *testing.T) {
+	p := newTestProcessor(t, schemaV120, "")
+
+	md := pdata.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.SetSchemaUrl(schemaV110)
+	metrics := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+
+	cpu := metrics.AppendEmpty()
+	cpu.SetName("container.cpu.usage")
+	cpu.SetDataType(pdata.MetricDataTypeGauge)
+	dp := cpu.Gauge().DataPoints().AppendEmpty()
+	dp.Attributes().InsertString("state", "user")
+	dp.Attributes().InsertString("http.status", "200")
+
+	requests := metrics.AppendEmpty()
+	requests.SetName("http.server.duration")
+	requests.SetDataType(pdata.MetricDataTypeHistogram)
+	hdp := requests.Histogram().DataPoints().AppendEmpty()
+	hdp.Attributes().InsertString("state", "idle")
+	hdp.Attributes().InsertString("http.status", "200")
+
+	md, err := p.ProcessMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	metrics = md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	assert.Equal(t, "container.cpu.utilization", metrics.At(0).Name())
+	assert.Equal(t, map[string]string{"cpu.state": "user", "http.status_code": "200"},
+		attributesToMap(metrics.At(0).Gauge().DataPoints().At(0).Attributes()))
+	assert.Equal(t, "http.server.duration", metrics.At(1).Name())
+	assert.Equal(t, map[string]string{"state": "idle", "http.status_code": "200"},
+		attributesToMap(metrics.At(1).Histogram().DataPoints().At(0).Attributes()))
+}
+
+func TestLogs(t *testing.T) {
+	p := newTestProcessor(t, schemaV120, "")
+
+	ld := pdata.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.SetSchemaUrl(schemaV100)
+	rl.Resource().Attributes().InsertString("k8s.cluster", "prod")
+	record := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	record.Attributes().InsertString("level", "error")
+	// the value of the new name is kept if both are set
+	record.Attributes().InsertString("http.status", "500")
+	record.Attributes().InsertString("http.status_code", "503")
+
+	ld, err := p.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	rl = ld.ResourceLogs().At(0)
+	assert.Equal(t, schemaV120, rl.SchemaUrl())
+	assert.Equal(t, map[string]string{"k8s.cluster.name": "prod"}, attributesToMap(rl.Resource().Attributes()))
+	assert.Equal(t, map[string]string{"severity": "error", "http.status_code": "503"},
+		attributesToMap(rl.InstrumentationLibraryLogs().At(0).Logs().At(0).Attributes()))
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
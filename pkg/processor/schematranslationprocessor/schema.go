@@ -0,0 +1,313 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schematranslationprocessor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// schemaFile is the OpenTelemetry schema file, see
+// https://github.com/open-telemetry/oteps/blob/main/text/0152-telemetry-schemas.md
+type schemaFile struct {
+	FileFormat string                 `yaml:"file_format"`
+	SchemaURL  string                 `yaml:"schema_url"`
+	Versions   map[string]versionFile `yaml:"versions"`
+}
+
+type versionFile struct {
+	All        attributeChanges `yaml:"all"`
+	Resources  attributeChanges `yaml:"resources"`
+	Spans      attributeChanges `yaml:"spans"`
+	SpanEvents spanEventChanges `yaml:"span_events"`
+	Metrics    metricChanges    `yaml:"metrics"`
+	Logs       attributeChanges `yaml:"logs"`
+}
+
+type attributeChanges struct {
+	Changes []struct {
+		RenameAttributes *renameAttributesFile `yaml:"rename_attributes"`
+	} `yaml:"changes"`
+}
+
+type spanEventChanges struct {
+	Changes []struct {
+		RenameEvents *struct {
+			NameMap map[string]string `yaml:"name_map"`
+		} `yaml:"rename_events"`
+		RenameAttributes *renameAttributesFile `yaml:"rename_attributes"`
+	} `yaml:"changes"`
+}
+
+type metricChanges struct {
+	Changes []struct {
+		RenameMetrics    map[string]string     `yaml:"rename_metrics"`
+		RenameAttributes *renameAttributesFile `yaml:"rename_attributes"`
+	} `yaml:"changes"`
+}
+
+type renameAttributesFile struct {
+	AttributeMap   map[string]string `yaml:"attribute_map"`
+	ApplyToSpans   []string          `yaml:"apply_to_spans"`
+	ApplyToEvents  []string          `yaml:"apply_to_events"`
+	ApplyToMetrics []string          `yaml:"apply_to_metrics"`
+}
+
+// schema holds the changes of each version of a schema family, in the ascending order of the versions
+type schema struct {
+	// family is the schema URL without the version, e.g. https://opentelemetry.io/schemas
+	family   string
+	versions []*version
+}
+
+type version struct {
+	name   string
+	number []int
+
+	all        []change
+	resources  []change
+	spans      []change
+	spanEvents []change
+	metrics    []change
+	logs       []change
+}
+
+// change renames the names (of the span events or metrics) and the attributes, optionally only for the listed
+// spans, events or metrics
+type change struct {
+	names      *rename
+	attributes *rename
+	applyTo    map[string]bool
+	// applyToEvents restricts the attribute renames of the span events
+	applyToEvents map[string]bool
+}
+
+// rename holds the renames in both directions
+type rename struct {
+	forward  map[string]string
+	backward map[string]string
+}
+
+func newRename(m map[string]string) *rename {
+	if len(m) == 0 {
+		return nil
+	}
+	r := &rename{forward: m, backward: make(map[string]string, len(m))}
+	for oldName, newName := range m {
+		r.backward[newName] = oldName
+	}
+	return r
+}
+
+func (r *rename) get(backward bool) map[string]string {
+	if backward {
+		return r.backward
+	}
+	return r.forward
+}
+
+func newSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+func newAttributeChange(ra *renameAttributesFile) change {
+	if ra == nil {
+		return change{}
+	}
+	return change{attributes: newRename(ra.AttributeMap)}
+}
+
+// loadSchema reads the schema file
+func loadSchema(path string) (*schema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the schema file: %w", err)
+	}
+	return parseSchema(data)
+}
+
+func parseSchema(data []byte) (*schema, error) {
+	file := schemaFile{}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid schema file: %w", err)
+	}
+	family, _, err := parseSchemaURL(file.SchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema_url of the schema file: %w", err)
+	}
+
+	s := &schema{family: family}
+	for name, vf := range file.Versions {
+		number, err := parseVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		v := &version{name: name, number: number}
+		for _, c := range vf.All.Changes {
+			v.all = append(v.all, newAttributeChange(c.RenameAttributes))
+		}
+		for _, c := range vf.Resources.Changes {
+			v.resources = append(v.resources, newAttributeChange(c.RenameAttributes))
+		}
+		for _, c := range vf.Spans.Changes {
+			ch := newAttributeChange(c.RenameAttributes)
+			if c.RenameAttributes != nil {
+				ch.applyTo = newSet(c.RenameAttributes.ApplyToSpans)
+			}
+			v.spans = append(v.spans, ch)
+		}
+		for _, c := range vf.SpanEvents.Changes {
+			ch := newAttributeChange(c.RenameAttributes)
+			if c.RenameAttributes != nil {
+				ch.applyTo = newSet(c.RenameAttributes.ApplyToSpans)
+				ch.applyToEvents = newSet(c.RenameAttributes.ApplyToEvents)
+			}
+			if c.RenameEvents != nil {
+				ch.names = newRename(c.RenameEvents.NameMap)
+			}
+			v.spanEvents = append(v.spanEvents, ch)
+		}
+		for _, c := range vf.Metrics.Changes {
+			ch := newAttributeChange(c.RenameAttributes)
+			if c.RenameAttributes != nil {
+				ch.applyTo = newSet(c.RenameAttributes.ApplyToMetrics)
+			}
+			ch.names = newRename(c.RenameMetrics)
+			v.metrics = append(v.metrics, ch)
+		}
+		for _, c := range vf.Logs.Changes {
+			v.logs = append(v.logs, newAttributeChange(c.RenameAttributes))
+		}
+		s.versions = append(s.versions, v)
+	}
+	sort.Slice(s.versions, func(i, j int) bool {
+		return compareVersions(s.versions[i].number, s.versions[j].number) < 0
+	})
+	return s, nil
+}
+
+// parseSchemaURL splits the schema URL into the family and version, e.g. https://opentelemetry.io/schemas
+// and 1.7.0
+func parseSchemaURL(url string) (string, string, error) {
+	i := strings.LastIndex(url, "/")
+	if url == "" || i < 0 || i == len(url)-1 {
+		return "", "", fmt.Errorf("schema URL %q does not end with a version", url)
+	}
+	if _, err := parseVersion(url[i+1:]); err != nil {
+		return "", "", err
+	}
+	return url[:i], url[i+1:], nil
+}
+
+func parseVersion(name string) ([]int, error) {
+	parts := strings.Split(name, ".")
+	number := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid schema version %q", name)
+		}
+		number[i] = n
+	}
+	return number, nil
+}
+
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func (s *schema) index(name string) int {
+	for i, v := range s.versions {
+		if v.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// step applies the changes of a version, backward when translating to an older version
+type step struct {
+	version  *version
+	backward bool
+}
+
+// steps returns the versions whose changes translate from the source schema URL to the target one,
+// or false when the source schema URL is not known
+func (s *schema) steps(from string, to string) ([]step, bool) {
+	if from == to {
+		return nil, true
+	}
+	fromFamily, fromVersion, err := parseSchemaURL(from)
+	if err != nil || fromFamily != s.family {
+		return nil, false
+	}
+	_, toVersion, _ := parseSchemaURL(to)
+	i, j := s.index(fromVersion), s.index(toVersion)
+	if i < 0 || j < 0 {
+		return nil, false
+	}
+
+	var steps []step
+	// upgrading applies the changes of the newer versions, downgrading reverts the changes of the current ones
+	for k := i + 1; k <= j; k++ {
+		steps = append(steps, step{version: s.versions[k]})
+	}
+	for k := i; k > j; k-- {
+		steps = append(steps, step{version: s.versions[k], backward: true})
+	}
+	return steps, true
+}
+
+// validateTarget checks if the target schema URL is one of the versions of the schema
+func (s *schema) validateTarget(target string) error {
+	family, name, err := parseSchemaURL(target)
+	if err != nil {
+		return err
+	}
+	if family != s.family {
+		return fmt.Errorf("target schema URL %q is not of the schema family %s", target, s.family)
+	}
+	if s.index(name) < 0 {
+		return fmt.Errorf("target schema version %s is not defined in the schema file", name)
+	}
+	return nil
+}
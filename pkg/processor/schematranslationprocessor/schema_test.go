@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schematranslationprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchema(t *testing.T) {
+	s, err := loadSchema("testdata/schema.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/schemas", s.family)
+
+	var names []string
+	for _, v := range s.versions {
+		names = append(names, v.name)
+	}
+	assert.Equal(t, []string{"1.0.0", "1.1.0", "1.2.0"}, names)
+
+	_, err = parseSchema([]byte("schema_url: https://example.com/schemas/1.0.0\nversions:\n  latest:\n"))
+	assert.Error(t, err)
+	_, err = parseSchema([]byte("schema_url: https://example.com/schemas\n"))
+	assert.Error(t, err)
+	_, err = parseSchema([]byte("schema_url: https://example.com/schemas/1.0.0\nunknown: true\n"))
+	assert.Error(t, err)
+}
+
+func TestSteps(t *testing.T) {
+	s, err := loadSchema("testdata/schema.yaml")
+	require.NoError(t, err)
+
+	stepNames := func(steps []step) []string {
+		var names []string
+		for _, st := range steps {
+			name := st.version.name
+			if st.backward {
+				name = "-" + name
+			}
+			names = append(names, name)
+		}
+		return names
+	}
+
+	steps, ok := s.steps("https://example.com/schemas/1.0.0", "https://example.com/schemas/1.2.0")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"1.1.0", "1.2.0"}, stepNames(steps))
+
+	steps, ok = s.steps("https://example.com/schemas/1.2.0", "https://example.com/schemas/1.0.0")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"-1.2.0", "-1.1.0"}, stepNames(steps))
+
+	steps, ok = s.steps("https://example.com/schemas/1.2.0", "https://example.com/schemas/1.2.0")
+	assert.True(t, ok)
+	assert.Empty(t, steps)
+
+	_, ok = s.steps("https://opentelemetry.io/schemas/1.0.0", "https://example.com/schemas/1.2.0")
+	assert.False(t, ok)
+	_, ok = s.steps("https://example.com/schemas/0.9.0", "https://example.com/schemas/1.2.0")
+	assert.False(t, ok)
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, -1, compareVersions([]int{1, 9}, []int{1, 10}))
+	assert.Equal(t, 0, compareVersions([]int{1, 0}, []int{1, 0, 0}))
+	assert.Equal(t, 1, compareVersions([]int{2}, []int{1, 10, 1}))
+}
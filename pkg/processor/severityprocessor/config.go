@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package severityprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the severity mapping.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Sources are the places the severity is taken from, tried in order until the value of one of them is mapped
+	Sources []SourceConfig `mapstructure:"sources"`
+	// Rules map the values to the severities, tried in order before the default ones
+	Rules []RuleConfig `mapstructure:"rules"`
+	// DefaultRules enables the rules of the common level names and the syslog severity numbers
+	DefaultRules bool `mapstructure:"default_rules"`
+	// SeverityText is what the severity text is set to: original, mapped or unchanged
+	SeverityText string `mapstructure:"severity_text"`
+	// Overwrite enables replacing the severities already set on the records
+	Overwrite bool `mapstructure:"overwrite"`
+}
+
+// SourceConfig is the place the severity is taken from. Exactly one of the fields must be set.
+type SourceConfig struct {
+	// Attribute is the name of the record attribute holding the severity
+	Attribute string `mapstructure:"attribute"`
+	// BodyField is the key of the map body holding the severity
+	BodyField string `mapstructure:"body_field"`
+	// BodyRegex matches the severity in the string body, in the "severity" named group or the whole match
+	BodyRegex string `mapstructure:"body_regex"`
+}
+
+// RuleConfig maps the values matching it to the severity. At least one of values and regex must be set.
+type RuleConfig struct {
+	// Severity is the name of the severity number, e.g. WARN or ERROR2
+	Severity string `mapstructure:"severity"`
+	// Values are the values mapped to the severity, compared case-insensitively
+	Values []string `mapstructure:"values"`
+	// Regex matches the values mapped to the severity
+	Regex string `mapstructure:"regex"`
+}
+
+const (
+	severityTextOriginal  = "original"
+	severityTextMapped    = "mapped"
+	severityTextUnchanged = "unchanged"
+
+	severityGroup = "severity"
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Sources) == 0 {
+		return errors.New("at least one source must be configured")
+	}
+	for i, source := range cfg.Sources {
+		if err := source.validate(); err != nil {
+			return fmt.Errorf("invalid source %d: %w", i, err)
+		}
+	}
+	if len(cfg.Rules) == 0 && !cfg.DefaultRules {
+		return errors.New("at least one rule must be configured when the default rules are disabled")
+	}
+	for i, rule := range cfg.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+	}
+	switch cfg.SeverityText {
+	case severityTextOriginal, severityTextMapped, severityTextUnchanged:
+	default:
+		return fmt.Errorf("invalid severity_text %q, must be one of: original, mapped, unchanged", cfg.SeverityText)
+	}
+	return nil
+}
+
+func (s SourceConfig) validate() error {
+	set := 0
+	for _, field := range []string{s.Attribute, s.BodyField, s.BodyRegex} {
+		if field != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of attribute, body_field and body_regex must be set")
+	}
+	if s.BodyRegex != "" {
+		if _, err := regexp.Compile(s.BodyRegex); err != nil {
+			return fmt.Errorf("invalid body_regex %q: %w", s.BodyRegex, err)
+		}
+	}
+	return nil
+}
+
+func (r RuleConfig) validate() error {
+	if _, ok := severityNumbers[strings.ToUpper(r.Severity)]; !ok {
+		return fmt.Errorf("unknown severity %q", r.Severity)
+	}
+	if len(r.Values) == 0 && r.Regex == "" {
+		return errors.New("at least one of values and regex must be set")
+	}
+	if r.Regex != "" {
+		if _, err := regexp.Compile(r.Regex); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", r.Regex, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package severityprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "severity_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			Sources: []SourceConfig{
+				{Attribute: "level"},
+				{BodyField: "severity"},
+				{BodyRegex: `^\S+ (?P<severity>[A-Z]+) `},
+			},
+			Rules: []RuleConfig{
+				{Severity: "warn", Values: []string{"W", "CAUTION"}},
+				{Severity: "ERROR2", Regex: `^E\d+$`},
+			},
+			DefaultRules: false,
+			SeverityText: "mapped",
+			Overwrite:    true,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(cfg *Config) {}},
+		{name: "no sources", modify: func(cfg *Config) { cfg.Sources = nil }, wantErr: true},
+		{name: "empty source", modify: func(cfg *Config) { cfg.Sources = []SourceConfig{{}} }, wantErr: true},
+		{name: "ambiguous source", modify: func(cfg *Config) {
+			cfg.Sources = []SourceConfig{{Attribute: "level", BodyField: "level"}}
+		}, wantErr: true},
+		{name: "invalid body regex", modify: func(cfg *Config) { cfg.Sources = []SourceConfig{{BodyRegex: "("}} }, wantErr: true},
+		{name: "no rules", modify: func(cfg *Config) { cfg.DefaultRules = false }, wantErr: true},
+		{name: "unknown severity", modify: func(cfg *Config) {
+			cfg.Rules = []RuleConfig{{Severity: "WARNING", Values: []string{"w"}}}
+		}, wantErr: true},
+		{name: "empty rule", modify: func(cfg *Config) { cfg.Rules = []RuleConfig{{Severity: "WARN"}} }, wantErr: true},
+		{name: "invalid rule regex", modify: func(cfg *Config) {
+			cfg.Rules = []RuleConfig{{Severity: "WARN", Regex: "("}}
+		}, wantErr: true},
+		{name: "unknown severity text", modify: func(cfg *Config) { cfg.SeverityText = "upper" }, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Sources = []SourceConfig{{Attribute: "level"}}
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
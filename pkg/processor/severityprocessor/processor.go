@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package severityprocessor
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// severityProcessor sets the severities of the log records to the ones mapped from their attributes or bodies
+type severityProcessor struct {
+	logger       *zap.Logger
+	sources      []*source
+	rules        []*rule
+	severityText string
+	overwrite    bool
+}
+
+// source extracts the severity value from the record
+type source struct {
+	attribute string
+	bodyField string
+	bodyRegex *regexp.Regexp
+	// group is the index of the regex submatch holding the severity
+	group int
+}
+
+func newSeverityProcessor(logger *zap.Logger, cfg *Config) (*severityProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	sp := &severityProcessor{
+		logger:       logger,
+		severityText: cfg.SeverityText,
+		overwrite:    cfg.Overwrite,
+	}
+
+	for _, sourceCfg := range cfg.Sources {
+		s := &source{attribute: sourceCfg.Attribute, bodyField: sourceCfg.BodyField}
+		if sourceCfg.BodyRegex != "" {
+			s.bodyRegex = regexp.MustCompile(sourceCfg.BodyRegex)
+			for i, name := range s.bodyRegex.SubexpNames() {
+				if name == severityGroup {
+					s.group = i
+				}
+			}
+		}
+		sp.sources = append(sp.sources, s)
+	}
+
+	rules := cfg.Rules
+	if cfg.DefaultRules {
+		rules = append(append([]RuleConfig{}, rules...), defaultRules...)
+	}
+	for _, ruleCfg := range rules {
+		sp.rules = append(sp.rules, newRule(ruleCfg))
+	}
+	return sp, nil
+}
+
+// ProcessLogs sets the severities of the log records
+func (sp *severityProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				sp.processRecord(logs.At(k))
+			}
+		}
+	}
+	return ld, nil
+}
+
+// processRecord sets the severity mapped from the first source holding a value matched by one of the rules;
+// the record is left unchanged when none of them does
+func (sp *severityProcessor) processRecord(lr pdata.LogRecord) {
+	if !sp.overwrite && lr.SeverityNumber() != pdata.SeverityNumberUNDEFINED {
+		return
+	}
+
+	for _, s := range sp.sources {
+		value, ok := s.extract(lr)
+		if !ok {
+			continue
+		}
+		severity, ok := sp.mapValue(strings.TrimSpace(value))
+		if !ok {
+			continue
+		}
+		lr.SetSeverityNumber(severity)
+		switch sp.severityText {
+		case severityTextOriginal:
+			lr.SetSeverityText(value)
+		case severityTextMapped:
+			lr.SetSeverityText(strings.TrimPrefix(severity.String(), "SEVERITY_NUMBER_"))
+		}
+		return
+	}
+	sp.logger.Debug("No severity mapped from the log record")
+}
+
+// mapValue returns the severity of the first rule matching the value
+func (sp *severityProcessor) mapValue(value string) (pdata.SeverityNumber, bool) {
+	for _, r := range sp.rules {
+		if r.matches(value) {
+			return r.severity, true
+		}
+	}
+	return pdata.SeverityNumberUNDEFINED, false
+}
+
+// extract returns the severity from the attribute, the map body field or the regex match of the string body
+func (s *source) extract(lr pdata.LogRecord) (string, bool) {
+	switch {
+	case s.attribute != "":
+		return attributeString(lr.Attributes(), s.attribute)
+	case s.bodyField != "":
+		if lr.Body().Type() != pdata.AttributeValueTypeMap {
+			return "", false
+		}
+		return attributeString(lr.Body().MapVal(), s.bodyField)
+	default:
+		if lr.Body().Type() != pdata.AttributeValueTypeString {
+			return "", false
+		}
+		match := s.bodyRegex.FindStringSubmatch(lr.Body().StringVal())
+		if match == nil {
+			return "", false
+		}
+		return match[s.group], true
+	}
+}
+
+// attributeString returns the string or the integer value of the attribute as a string
+func attributeString(attributes pdata.AttributeMap, name string) (string, bool) {
+	value, ok := attributes.Get(name)
+	if !ok {
+		return "", false
+	}
+	switch value.Type() {
+	case pdata.AttributeValueTypeString, pdata.AttributeValueTypeInt:
+		return pdata.AttributeValueToString(value), true
+	default:
+		return "", false
+	}
+}
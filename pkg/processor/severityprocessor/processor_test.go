@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package severityprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) *severityProcessor {
+	cfg := createDefaultConfig().(*Config)
+	modify(cfg)
+	sp, err := newSeverityProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	return sp
+}
+
+func newTestLogs(records ...func(lr pdata.LogRecord)) (pdata.Logs, pdata.LogSlice) {
+	ld := pdata.NewLogs()
+	logs := ld.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	for _, fill := range records {
+		fill(logs.AppendEmpty())
+	}
+	return ld, logs
+}
+
+func TestProcessLogsSources(t *testing.T) {
+	sp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Sources = []SourceConfig{
+			{Attribute: "level"},
+			{BodyField: "severity"},
+			{BodyRegex: `^\S+ (?P<severity>[A-Z]+) `},
+		}
+	})
+
+	ld, logs := newTestLogs(
+		func(lr pdata.LogRecord) {
+			lr.Attributes().InsertString("level", "Warning")
+			lr.Body().SetStringVal("2021-09-01T12:00:00Z ERROR ignored")
+		},
+		func(lr pdata.LogRecord) {
+			lr.Attributes().InsertString("level", "unknown")
+			pdata.NewAttributeValueMap().CopyTo(lr.Body())
+			lr.Body().MapVal().InsertInt("severity", 3)
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal("2021-09-01T12:00:00Z ERROR connection refused")
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal("no severity")
+		},
+	)
+
+	_, err := sp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, pdata.SeverityNumberWARN, logs.At(0).SeverityNumber())
+	assert.Equal(t, "Warning", logs.At(0).SeverityText())
+	assert.Equal(t, pdata.SeverityNumberERROR, logs.At(1).SeverityNumber(), "syslog severity number")
+	assert.Equal(t, "3", logs.At(1).SeverityText())
+	assert.Equal(t, pdata.SeverityNumberERROR, logs.At(2).SeverityNumber())
+	assert.Equal(t, "ERROR", logs.At(2).SeverityText())
+	assert.Equal(t, pdata.SeverityNumberUNDEFINED, logs.At(3).SeverityNumber())
+	assert.Empty(t, logs.At(3).SeverityText())
+}
+
+func TestProcessLogsRules(t *testing.T) {
+	sp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Sources = []SourceConfig{{Attribute: "level"}}
+		cfg.Rules = []RuleConfig{
+			{Severity: "warn", Values: []string{"W", "caution"}},
+			{Severity: "ERROR2", Regex: `^E\d+$`},
+			{Severity: "DEBUG", Values: []string{"3"}},
+		}
+		cfg.SeverityText = "mapped"
+	})
+
+	testcases := []struct {
+		value    string
+		expected pdata.SeverityNumber
+		text     string
+	}{
+		{value: "w", expected: pdata.SeverityNumberWARN, text: "WARN"},
+		{value: "CAUTION", expected: pdata.SeverityNumberWARN, text: "WARN"},
+		{value: "E42", expected: pdata.SeverityNumberERROR2, text: "ERROR2"},
+		{value: "3", expected: pdata.SeverityNumberDEBUG, text: "DEBUG"},
+		{value: " notice ", expected: pdata.SeverityNumberINFO2, text: "INFO2"},
+		{value: "0", expected: pdata.SeverityNumberFATAL3, text: "FATAL3"},
+		{value: "e42x", expected: pdata.SeverityNumberUNDEFINED},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			ld, logs := newTestLogs(func(lr pdata.LogRecord) {
+				lr.Attributes().InsertString("level", tc.value)
+			})
+			_, err := sp.ProcessLogs(context.Background(), ld)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, logs.At(0).SeverityNumber())
+			assert.Equal(t, tc.text, logs.At(0).SeverityText())
+		})
+	}
+}
+
+func TestProcessLogsWithoutDefaultRules(t *testing.T) {
+	sp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Sources = []SourceConfig{{Attribute: "level"}}
+		cfg.Rules = []RuleConfig{{Severity: "WARN", Values: []string{"W"}}}
+		cfg.DefaultRules = false
+	})
+
+	ld, logs := newTestLogs(
+		func(lr pdata.LogRecord) { lr.Attributes().InsertString("level", "W") },
+		func(lr pdata.LogRecord) { lr.Attributes().InsertString("level", "error") },
+	)
+	_, err := sp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, pdata.SeverityNumberWARN, logs.At(0).SeverityNumber())
+	assert.Equal(t, pdata.SeverityNumberUNDEFINED, logs.At(1).SeverityNumber())
+}
+
+func TestProcessLogsOverwrite(t *testing.T) {
+	testcases := []struct {
+		name         string
+		overwrite    bool
+		severityText string
+		expected     pdata.SeverityNumber
+		text         string
+	}{
+		{name: "keep", overwrite: false, severityText: "original", expected: pdata.SeverityNumberINFO, text: "I"},
+		{name: "overwrite", overwrite: true, severityText: "original", expected: pdata.SeverityNumberERROR, text: "error"},
+		{name: "overwrite number only", overwrite: true, severityText: "unchanged", expected: pdata.SeverityNumberERROR, text: "I"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sp := newTestProcessor(t, func(cfg *Config) {
+				cfg.Sources = []SourceConfig{{Attribute: "level"}}
+				cfg.Overwrite = tc.overwrite
+				cfg.SeverityText = tc.severityText
+			})
+			ld, logs := newTestLogs(func(lr pdata.LogRecord) {
+				lr.SetSeverityNumber(pdata.SeverityNumberINFO)
+				lr.SetSeverityText("I")
+				lr.Attributes().InsertString("level", "error")
+			})
+
+			_, err := sp.ProcessLogs(context.Background(), ld)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, logs.At(0).SeverityNumber())
+			assert.Equal(t, tc.text, logs.At(0).SeverityText())
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package severityprocessor
+
+import (
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// severityNumbers are the severity numbers by their names, e.g. WARN or ERROR2
+var severityNumbers = func() map[string]pdata.SeverityNumber {
+	numbers := map[string]pdata.SeverityNumber{}
+	for number := pdata.SeverityNumberTRACE; number <= pdata.SeverityNumberFATAL4; number++ {
+		numbers[strings.TrimPrefix(number.String(), "SEVERITY_NUMBER_")] = number
+	}
+	return numbers
+}()
+
+// defaultRules map the common level names and the syslog severity numbers, the latter the same way
+// the journald receiver does
+var defaultRules = []RuleConfig{
+	{Severity: "TRACE", Values: []string{"trace", "trc", "finest", "finer"}},
+	{Severity: "DEBUG", Values: []string{"debug", "dbg", "fine", "verbose", "7"}},
+	{Severity: "INFO", Values: []string{"info", "inf", "information", "informational", "6"}},
+	{Severity: "INFO2", Values: []string{"notice", "5"}},
+	{Severity: "WARN", Values: []string{"warn", "warning", "wrn", "4"}},
+	{Severity: "ERROR", Values: []string{"error", "err", "severe", "3"}},
+	{Severity: "FATAL", Values: []string{"fatal", "critical", "crit", "panic", "2"}},
+	{Severity: "FATAL2", Values: []string{"alert", "1"}},
+	{Severity: "FATAL3", Values: []string{"emerg", "emergency", "0"}},
+}
+
+// rule maps the values matching it to the severity
+type rule struct {
+	severity pdata.SeverityNumber
+	values   map[string]struct{}
+	regex    *regexp.Regexp
+}
+
+func newRule(cfg RuleConfig) *rule {
+	r := &rule{
+		severity: severityNumbers[strings.ToUpper(cfg.Severity)],
+		values:   map[string]struct{}{},
+	}
+	for _, value := range cfg.Values {
+		r.values[strings.ToLower(value)] = struct{}{}
+	}
+	if cfg.Regex != "" {
+		r.regex = regexp.MustCompile(cfg.Regex)
+	}
+	return r
+}
+
+// matches returns true if the value is one of the rule's values or matches its regex
+func (r *rule) matches(value string) bool {
+	if _, ok := r.values[strings.ToLower(value)]; ok {
+		return true
+	}
+	return r.regex != nil && r.regex.MatchString(value)
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// attributeFilter matches attributes against regular expressions, keyed by the attribute name.
+// A nil attributeFilter does not match anything.
+type attributeFilter struct {
+	regexes map[string]*regexp.Regexp
+}
+
+// newAttributeFilter compiles the rules, returning nil if there are none
+func newAttributeFilter(rules map[string]string) (*attributeFilter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	f := &attributeFilter{regexes: make(map[string]*regexp.Regexp, len(rules))}
+	for attribute, regex := range rules {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression for attribute %q: %w", attribute, err)
+		}
+		f.regexes[attribute] = re
+	}
+	return f, nil
+}
+
+// matches returns true if any of the attributes matches its regular expression. Values other than strings
+// are matched using their string representation.
+func (f *attributeFilter) matches(atts pdata.AttributeMap) bool {
+	if f == nil {
+		return false
+	}
+
+	for attribute, re := range f.regexes {
+		if value, found := atts.Get(attribute); found && re.MatchString(pdata.AttributeValueToString(value)) {
+			return true
+		}
+	}
+	return false
+}
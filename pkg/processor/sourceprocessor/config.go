@@ -15,6 +15,9 @@
 package sourceprocessor
 
 import (
+	"fmt"
+	"regexp"
+
 	"go.opentelemetry.io/collector/config"
 )
 
@@ -26,19 +29,128 @@ type Config struct {
 	Source                    string `mapstructure:"source"`
 	SourceName                string `mapstructure:"source_name"`
 	SourceCategory            string `mapstructure:"source_category"`
+	SourceHost                string `mapstructure:"source_host"`
 	SourceCategoryPrefix      string `mapstructure:"source_category_prefix"`
 	SourceCategoryReplaceDash string `mapstructure:"source_category_replace_dash"`
+	SourceNameReplaceDash     string `mapstructure:"source_name_replace_dash"`
+	SourceHostReplaceDash     string `mapstructure:"source_host_replace_dash"`
 	ExcludeNamespaceRegex     string `mapstructure:"exclude_namespace_regex"`
 	ExcludePodRegex           string `mapstructure:"exclude_pod_regex"`
 	ExcludeContainerRegex     string `mapstructure:"exclude_container_regex"`
 	ExcludeHostRegex          string `mapstructure:"exclude_host_regex"`
+	// Exclude drops the data having any of the attributes matching the regular expression, keyed by the attribute
+	// name. The resource attributes exclude all its data, while the attributes of spans and log records exclude
+	// only the given record.
+	Exclude map[string]string `mapstructure:"exclude"`
+	// Include drops the data not having any of the attributes matching the regular expression, keyed by
+	// the attribute name. Spans and log records are kept if either the resource or the record matches.
+	Include map[string]string `mapstructure:"include"`
+	// Fields are set as resource attributes on all data, e.g. the cluster or environment name.
+	// The fields from annotations take precedence over them.
+	Fields map[string]string `mapstructure:"fields"`
+	// PodLabelFields sets the selected pod labels as fields, keyed by the label name, with the name of the field
+	// as the value, e.g. `app.kubernetes.io/name: app`. The labels are found using PodLabelPrefix.
+	PodLabelFields map[string]string `mapstructure:"pod_label_fields"`
+	// SourceCategoryExpressions, SourceNameExpressions and SourceHostExpressions compute the source metadata
+	// with OTTL expressions evaluated against the resource, as an alternative to the templates. The first
+	// expression which meets its `where` condition and yields a non-empty value is used. Annotations still
+	// take precedence, while the template is used when none of the expressions yields a value.
+	SourceCategoryExpressions []string `mapstructure:"source_category_expressions"`
+	SourceNameExpressions     []string `mapstructure:"source_name_expressions"`
+	SourceHostExpressions     []string `mapstructure:"source_host_expressions"`
+	// Logs, Metrics and Traces override the source templates for the given signal only.
+	Logs    *SignalConfig `mapstructure:"logs"`
+	Metrics *SignalConfig `mapstructure:"metrics"`
+	Traces  *SignalConfig `mapstructure:"traces"`
+	// Debug logs how the source attributes were resolved for a sample of the resources.
+	Debug DebugConfig `mapstructure:"debug"`
+
+	// AnnotationKeyPrefixes are the prefixes of the annotation names (e.g. sumologic.com/), in the order
+	// of precedence.
+	AnnotationKeyPrefixes []string `mapstructure:"annotation_key_prefixes"`
+
+	AnnotationPrefix          string `mapstructure:"annotation_prefix"`
+	NamespaceAnnotationPrefix string `mapstructure:"namespace_annotation_prefix"`
+	ContainerKey              string `mapstructure:"container_key"`
+	ContainerIDKey            string `mapstructure:"container_id_key"`
+	ContainerImageKey         string `mapstructure:"container_image_key"`
+	NamespaceKey              string `mapstructure:"namespace_key"`
+	PodKey                    string `mapstructure:"pod_key"`
+	PodLabelPrefix            string `mapstructure:"pod_label_prefix"`
+	PodIDKey                  string `mapstructure:"pod_id_key"`
+	PodNameKey                string `mapstructure:"pod_name_key"`
+	PodTemplateHashKey        string `mapstructure:"pod_template_hash_key"`
+	SourceHostKey             string `mapstructure:"source_host_key"`
+}
+
+// Validate checks if the source templates, the expressions, the regular expressions and the filters
+// of the processor are valid, so the errors are reported when the configuration is loaded
+func (cfg *Config) Validate() error {
+	keys := newSourceKeys(cfg)
+	templates := map[string]string{
+		"source_category": cfg.SourceCategory,
+		"source_name":     cfg.SourceName,
+		"source_host":     cfg.SourceHost,
+	}
+	for signal, signalCfg := range map[string]*SignalConfig{"logs": cfg.Logs, "metrics": cfg.Metrics, "traces": cfg.Traces} {
+		if signalCfg == nil {
+			continue
+		}
+		templates[signal+"::source_category"] = signalCfg.SourceCategory
+		templates[signal+"::source_name"] = signalCfg.SourceName
+		templates[signal+"::source_host"] = signalCfg.SourceHost
+	}
+	for name, template := range templates {
+		if _, err := parseTemplate(template, keys); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	for name, expressions := range map[string][]string{
+		"source_category_expressions": cfg.SourceCategoryExpressions,
+		"source_name_expressions":     cfg.SourceNameExpressions,
+		"source_host_expressions":     cfg.SourceHostExpressions,
+	} {
+		if _, err := parseExpressions(expressions); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	for name, regex := range map[string]string{
+		"exclude_namespace_regex": cfg.ExcludeNamespaceRegex,
+		"exclude_pod_regex":       cfg.ExcludePodRegex,
+		"exclude_container_regex": cfg.ExcludeContainerRegex,
+		"exclude_host_regex":      cfg.ExcludeHostRegex,
+	} {
+		if _, err := regexp.Compile(regex); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	if _, err := newAttributeFilter(cfg.Exclude); err != nil {
+		return fmt.Errorf("exclude: %w", err)
+	}
+	if _, err := newAttributeFilter(cfg.Include); err != nil {
+		return fmt.Errorf("include: %w", err)
+	}
+	if _, err := newDebugSampler(cfg.Debug); err != nil {
+		return fmt.Errorf("debug: %w", err)
+	}
+	return nil
+}
+
+// DebugConfig configures the logging of the source resolution details: the attributes of the resource,
+// the resolved source attributes and the annotation, expression or template which decided each of them.
+type DebugConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// SampleEvery logs every n-th processed resource, 1 logs all of them.
+	SampleEvery int `mapstructure:"sample_every"`
+}
 
-	AnnotationPrefix   string `mapstructure:"annotation_prefix"`
-	ContainerKey       string `mapstructure:"container_key"`
-	NamespaceKey       string `mapstructure:"namespace_key"`
-	PodKey             string `mapstructure:"pod_key"`
-	PodIDKey           string `mapstructure:"pod_id_key"`
-	PodNameKey         string `mapstructure:"pod_name_key"`
-	PodTemplateHashKey string `mapstructure:"pod_template_hash_key"`
-	SourceHostKey      string `mapstructure:"source_host_key"`
+// SignalConfig holds the source templates overridden for a single signal. Empty values
+// fall back to the top level ones.
+type SignalConfig struct {
+	SourceName     string `mapstructure:"source_name"`
+	SourceCategory string `mapstructure:"source_category"`
+	SourceHost     string `mapstructure:"source_host"`
 }
@@ -49,22 +49,89 @@ func TestLoadConfig(t *testing.T) {
 		ProcessorSettings:         &ps2,
 		Collector:                 "somecollector",
 		Source:                    "tracesource",
+		Fields:                    map[string]string{"cluster": "prod-eu", "environment": "production"},
+		PodLabelFields:            map[string]string{"app.kubernetes.io/name": "app"},
 		SourceName:                "%{namespace}.%{pod}.%{container}/foo",
 		SourceCategory:            "%{namespace}/%{pod_name}/bar",
+		SourceHost:                "%{node}",
 		SourceCategoryPrefix:      "kubernetes/",
+		SourceCategoryExpressions: []string{`Concat([attributes["namespace"], "critical"], "/") where attributes["tier"] == "critical"`},
 		SourceCategoryReplaceDash: "/",
+		SourceNameReplaceDash:     "_",
 		ExcludeContainerRegex:     "excluded_container_regex",
 		ExcludeHostRegex:          "excluded_host_regex",
 		ExcludeNamespaceRegex:     "excluded_namespace_regex",
 		ExcludePodRegex:           "excluded_pod_regex",
+		Exclude:                   map[string]string{"k8s.pod.labels.team": "^legacy-.*"},
+		Include:                   map[string]string{"k8s.namespace.name": "^kube-system$"},
+		Logs:                      &SignalConfig{SourceCategory: "%{namespace}/%{pod_name}/logs"},
+		Metrics:                   &SignalConfig{SourceName: "%{container}"},
+		Debug:                     DebugConfig{Enabled: true, SampleEvery: 10},
 
-		AnnotationPrefix:   "pod_annotation_",
-		ContainerKey:       "container",
-		NamespaceKey:       "namespace",
-		PodKey:             "pod",
-		PodIDKey:           "pod_id",
-		PodNameKey:         "pod_name",
-		PodTemplateHashKey: "pod_labels_pod-template-hash",
-		SourceHostKey:      "source_host",
+		AnnotationKeyPrefixes:     []string{"sumologic.com/", "logging.acme.io/"},
+		AnnotationPrefix:          "pod_annotation_",
+		NamespaceAnnotationPrefix: "ns_annotation_",
+		ContainerKey:              "container",
+		ContainerIDKey:            "k8s.container.id",
+		ContainerImageKey:         "k8s.container.image",
+		NamespaceKey:              "namespace",
+		PodKey:                    "pod",
+		PodLabelPrefix:            "k8s.pod.labels.",
+		PodIDKey:                  "pod_id",
+		PodNameKey:                "pod_name",
+		PodTemplateHashKey:        "pod_labels_pod-template-hash",
+		SourceHostKey:             "source_host",
 	})
 }
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{
+			name:   "default",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:   "unclosed placeholder",
+			modify: func(cfg *Config) { cfg.SourceCategory = "%{namespace/%{pod_name}" },
+			err:    "source_category: invalid template",
+		},
+		{
+			name:   "invalid signal template",
+			modify: func(cfg *Config) { cfg.Logs = &SignalConfig{SourceName: "%{unknown_function(pod)}"} },
+			err:    "logs::source_name: invalid template",
+		},
+		{
+			name:   "invalid expression",
+			modify: func(cfg *Config) { cfg.SourceHostExpressions = []string{`Concat(`} },
+			err:    "source_host_expressions:",
+		},
+		{
+			name:   "invalid regex",
+			modify: func(cfg *Config) { cfg.ExcludePodRegex = "(" },
+			err:    "exclude_pod_regex:",
+		},
+		{
+			name:   "invalid filter",
+			modify: func(cfg *Config) { cfg.Include = map[string]string{"namespace": "["} },
+			err:    "include:",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewFactory().CreateDefaultConfig().(*Config)
+			tc.modify(cfg)
+
+			err := cfg.Validate()
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// debugSampler selects the resources for which the source resolution is logged
+type debugSampler struct {
+	every uint64
+	count uint64
+}
+
+func newDebugSampler(cfg DebugConfig) (*debugSampler, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.SampleEvery < 1 {
+		return nil, fmt.Errorf("debug sample_every must be positive, got %d", cfg.SampleEvery)
+	}
+	return &debugSampler{every: uint64(cfg.SampleEvery)}, nil
+}
+
+// sample returns true for every n-th resource, starting with the first one
+func (s *debugSampler) sample() bool {
+	if s == nil {
+		return false
+	}
+	return (atomic.AddUint64(&s.count, 1)-1)%s.every == 0
+}
+
+const (
+	annotationRule = "annotation"
+	expressionRule = "expression"
+	templateRule   = "template"
+)
+
+// fillRule describes which annotation, OTTL expression or template decided the value of a source attribute.
+// It is only formatted when logged.
+type fillRule struct {
+	kind string
+	// annotation is the attribute holding the annotation
+	annotation string
+	// source is the text of the template or expression
+	source string
+}
+
+func (r fillRule) String() string {
+	if r.kind == annotationRule {
+		return fmt.Sprintf("%s %s: %q", r.kind, r.annotation, r.source)
+	}
+	return fmt.Sprintf("%s: %q", r.kind, r.source)
+}
+
+// logResolution logs the attributes the resource came with, along with the resolved source
+// attributes and the rules which decided them
+func (sp *sourceProcessor) logResolution(input pdata.AttributeMap, output pdata.AttributeMap, rules map[string]fillRule) {
+	fields := []zap.Field{zap.Any("attributes", attributesToStringMap(input))}
+	for _, key := range []string{sourceCategoryKey, sourceNameKey, sourceHostKey} {
+		value := "<not set>"
+		if v, found := output.Get(key); found {
+			value = v.StringVal()
+		}
+		fields = append(fields, zap.String(key, value), zap.Stringer(key+"_rule", rules[key]))
+	}
+	sp.logger.Info("Source resolution", fields...)
+}
+
+func attributesToStringMap(atts pdata.AttributeMap) map[string]string {
+	result := make(map[string]string, atts.Len())
+	atts.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
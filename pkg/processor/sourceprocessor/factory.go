@@ -34,15 +34,21 @@ const (
 	defaultSourceCategory            = "%{namespace}/%{pod_name}"
 	defaultSourceCategoryPrefix      = "kubernetes/"
 	defaultSourceCategoryReplaceDash = "/"
-
-	defaultAnnotationPrefix   = "pod_annotation_"
-	defaultContainerKey       = "container"
-	defaultNamespaceKey       = "namespace"
-	defaultPodIDKey           = "pod_id"
-	defaultPodKey             = "pod"
-	defaultPodNameKey         = "pod_name"
-	defaultPodTemplateHashKey = "pod_labels_pod-template-hash"
-	defaultSourceHostKey      = "source_host"
+	defaultDebugSampleEvery          = 100
+
+	defaultAnnotationKeyPrefix       = "sumologic.com/"
+	defaultAnnotationPrefix          = "pod_annotation_"
+	defaultNamespaceAnnotationPrefix = "namespace_annotation_"
+	defaultContainerKey              = "container"
+	defaultContainerIDKey            = "container_id"
+	defaultContainerImageKey         = "container_image"
+	defaultNamespaceKey              = "namespace"
+	defaultPodIDKey                  = "pod_id"
+	defaultPodKey                    = "pod"
+	defaultPodLabelPrefix            = "pod_labels_"
+	defaultPodNameKey                = "pod_name"
+	defaultPodTemplateHashKey        = "pod_labels_pod-template-hash"
+	defaultSourceHostKey             = "source_host"
 )
 
 var processorCapabilities = consumer.Capabilities{MutatesData: true}
@@ -69,15 +75,21 @@ func createDefaultConfig() config.Processor {
 		SourceCategory:            defaultSourceCategory,
 		SourceCategoryPrefix:      defaultSourceCategoryPrefix,
 		SourceCategoryReplaceDash: defaultSourceCategoryReplaceDash,
-
-		AnnotationPrefix:   defaultAnnotationPrefix,
-		ContainerKey:       defaultContainerKey,
-		NamespaceKey:       defaultNamespaceKey,
-		PodKey:             defaultPodKey,
-		PodIDKey:           defaultPodIDKey,
-		PodNameKey:         defaultPodNameKey,
-		PodTemplateHashKey: defaultPodTemplateHashKey,
-		SourceHostKey:      defaultSourceHostKey,
+		Debug:                     DebugConfig{SampleEvery: defaultDebugSampleEvery},
+
+		AnnotationKeyPrefixes:     []string{defaultAnnotationKeyPrefix},
+		AnnotationPrefix:          defaultAnnotationPrefix,
+		NamespaceAnnotationPrefix: defaultNamespaceAnnotationPrefix,
+		ContainerKey:              defaultContainerKey,
+		ContainerIDKey:            defaultContainerIDKey,
+		ContainerImageKey:         defaultContainerImageKey,
+		NamespaceKey:              defaultNamespaceKey,
+		PodKey:                    defaultPodKey,
+		PodLabelPrefix:            defaultPodLabelPrefix,
+		PodIDKey:                  defaultPodIDKey,
+		PodNameKey:                defaultPodNameKey,
+		PodTemplateHashKey:        defaultPodTemplateHashKey,
+		SourceHostKey:             defaultSourceHostKey,
 	}
 }
 
@@ -90,7 +102,10 @@ func createTraceProcessor(
 
 	oCfg := cfg.(*Config)
 
-	sp := newSourceProcessor(oCfg)
+	sp, err := newSourceProcessor(params.Logger, oCfg)
+	if err != nil {
+		return nil, err
+	}
 
 	return processorhelper.NewTracesProcessor(
 		cfg,
@@ -109,7 +124,10 @@ func createMetricsProcessor(
 ) (component.MetricsProcessor, error) {
 	oCfg := cfg.(*Config)
 
-	sp := newSourceProcessor(oCfg)
+	sp, err := newSourceProcessor(params.Logger, oCfg)
+	if err != nil {
+		return nil, err
+	}
 	return processorhelper.NewMetricsProcessor(
 		cfg,
 		next,
@@ -127,7 +145,10 @@ func createLogsProcessor(
 ) (component.LogsProcessor, error) {
 	oCfg := cfg.(*Config)
 
-	sp := newSourceProcessor(oCfg)
+	sp, err := newSourceProcessor(params.Logger, oCfg)
+	if err != nil {
+		return nil, err
+	}
 	return processorhelper.NewLogsProcessor(
 		cfg,
 		next,
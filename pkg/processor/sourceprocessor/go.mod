@@ -3,10 +3,14 @@ module github.com/open-telemetry/opentelemetry-collector-contrib/processor/sourc
 go 1.14
 
 require (
+	github.com/open-telemetry/opentelemetry-collector-contrib/internal/ottl v0.33.0
 	github.com/stretchr/testify v1.7.0
 	go.opencensus.io v0.23.0
 	go.opentelemetry.io/collector v0.33.0
 	go.opentelemetry.io/collector/model v0.33.0
+	go.uber.org/zap v1.19.0
 )
 
+replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/ottl => ./../../internal/ottl
+
 replace go.opentelemetry.io/collector => github.com/SumoLogic/opentelemetry-collector v0.33.0-sumo-1
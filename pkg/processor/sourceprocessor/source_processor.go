@@ -16,38 +16,33 @@ package sourceprocessor
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"regexp"
 	"strings"
+	"sync"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/model/pdata"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/sourceprocessor/observability"
-)
+	"go.uber.org/zap"
 
-var (
-	formatRegex *regexp.Regexp
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/sourceprocessor/observability"
 )
 
-func init() {
-	var err error
-	formatRegex, err = regexp.Compile(`\%\{(\w+)\}`)
-	if err != nil {
-		panic("failed to parse regex: " + err.Error())
-	}
-}
-
 type sourceKeys struct {
-	annotationPrefix   string
-	containerKey       string
-	namespaceKey       string
-	podKey             string
-	podIDKey           string
-	podNameKey         string
-	podTemplateHashKey string
-	sourceHostKey      string
+	annotationPrefix          string
+	namespaceAnnotationPrefix string
+	containerKey              string
+	containerIDKey            string
+	containerImageKey         string
+	namespaceKey              string
+	podKey                    string
+	podLabelPrefix            string
+	podIDKey                  string
+	podNameKey                string
+	podTemplateHashKey        string
+	sourceHostKey             string
 }
 
 func (stk sourceKeys) convertKey(key string) string {
@@ -71,34 +66,72 @@ func (stk sourceKeys) convertKey(key string) string {
 
 type attributeFiller struct {
 	name            string
-	compiledFormat  string
 	dashReplacement string
 	prefix          string
-	labels          []string
+	template        *compiledTemplate
+	// ottlExpressions are evaluated before falling back to the template
+	ottlExpressions []*ottl.Expression
+}
+
+// sourceFillers fill the source attributes of a single signal
+type sourceFillers struct {
+	sourceCategoryFiller attributeFiller
+	sourceNameFiller     attributeFiller
+	sourceHostFiller     attributeFiller
 }
 
 type sourceProcessor struct {
 	collector             string
 	source                string
-	sourceCategoryFiller  attributeFiller
-	sourceNameFiller      attributeFiller
-	sourceHostFiller      attributeFiller
+	fields                map[string]string
+	podLabelFields        map[string]string
+	logsFillers           sourceFillers
+	metricsFillers        sourceFillers
+	tracesFillers         sourceFillers
 	excludeNamespaceRegex *regexp.Regexp
 	excludePodRegex       *regexp.Regexp
 	excludeContainerRegex *regexp.Regexp
 	excludeHostRegex      *regexp.Regexp
+	exclude               *attributeFilter
+	include               *attributeFilter
 	keys                  sourceKeys
+	// annotationKeyPrefixes are the prefixes of the annotation names, in the order of precedence
+	annotationKeyPrefixes []string
+	logger                *zap.Logger
+	// debugSampler is nil unless the debug logging is enabled
+	debugSampler *debugSampler
+	// knownAnnotationAttributes and knownNamespaceAnnotationAttributes hold the attributes of the annotations
+	// used by the processor (for each of the prefixes), so they are not built for each resource
+	knownAnnotationAttributes          map[string][]string
+	knownNamespaceAnnotationAttributes map[string][]string
+	// fieldsAnnotationAttributes are the attributes of the fields annotations, in the order they are applied
+	fieldsAnnotationAttributes []string
+	// annotationTemplates caches the templates of the source annotations
+	annotationTemplates *templateCache
+	// sourceAttributes caches the results of sourceAnnotationAttributes, which depend on the container name
+	sourceAttributes   map[sourceAttributesKey][]string
+	sourceAttributesMu sync.RWMutex
+}
+
+// maxCachedSourceAttributes bounds the memory used by the source annotation attributes of the containers
+const maxCachedSourceAttributes = 4096
+
+type sourceAttributesKey struct {
+	container      string
+	annotationName string
 }
 
 const (
 	alphanums = "bcdfghjklmnpqrstvwxz2456789"
 
-	sourceHostSpecialAnnotation     = "sumologic.com/sourceHost"
-	sourceNameSpecialAnnotation     = "sumologic.com/sourceName"
-	sourceCategorySpecialAnnotation = "sumologic.com/sourceCategory"
+	// The annotation names are prefixed with each of the annotation key prefixes (e.g. sumologic.com/)
+	sourceHostSpecialAnnotation     = "sourceHost"
+	sourceNameSpecialAnnotation     = "sourceName"
+	sourceCategorySpecialAnnotation = "sourceCategory"
 
-	includeAnnotation = "sumologic.com/include"
-	excludeAnnotation = "sumologic.com/exclude"
+	includeAnnotation = "include"
+	excludeAnnotation = "exclude"
+	fieldsAnnotation  = "fields"
 
 	collectorKey      = "_collector"
 	sourceCategoryKey = "_sourceCategory"
@@ -133,29 +166,103 @@ func matchRegexMaybe(re *regexp.Regexp, atts pdata.AttributeMap, attributeName s
 	return false
 }
 
-func newSourceProcessor(cfg *Config) *sourceProcessor {
-	keys := sourceKeys{
-		annotationPrefix:   cfg.AnnotationPrefix,
-		containerKey:       cfg.ContainerKey,
-		namespaceKey:       cfg.NamespaceKey,
-		podIDKey:           cfg.PodIDKey,
-		podKey:             cfg.PodKey,
-		podNameKey:         cfg.PodNameKey,
-		podTemplateHashKey: cfg.PodTemplateHashKey,
-		sourceHostKey:      cfg.SourceHostKey,
+// newSourceKeys returns the attribute names configured for the processor
+func newSourceKeys(cfg *Config) sourceKeys {
+	return sourceKeys{
+		annotationPrefix:          cfg.AnnotationPrefix,
+		namespaceAnnotationPrefix: cfg.NamespaceAnnotationPrefix,
+		containerKey:              cfg.ContainerKey,
+		containerIDKey:            cfg.ContainerIDKey,
+		containerImageKey:         cfg.ContainerImageKey,
+		namespaceKey:              cfg.NamespaceKey,
+		podIDKey:                  cfg.PodIDKey,
+		podKey:                    cfg.PodKey,
+		podLabelPrefix:            cfg.PodLabelPrefix,
+		podNameKey:                cfg.PodNameKey,
+		podTemplateHashKey:        cfg.PodTemplateHashKey,
+		sourceHostKey:             cfg.SourceHostKey,
+	}
+}
+
+func newSourceProcessor(logger *zap.Logger, cfg *Config) (*sourceProcessor, error) {
+	keys := newSourceKeys(cfg)
+
+	logsFillers, err := createSourceFillers(cfg, cfg.Logs, keys)
+	if err != nil {
+		return nil, err
+	}
+	metricsFillers, err := createSourceFillers(cfg, cfg.Metrics, keys)
+	if err != nil {
+		return nil, err
+	}
+	tracesFillers, err := createSourceFillers(cfg, cfg.Traces, keys)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := newAttributeFilter(cfg.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	include, err := newAttributeFilter(cfg.Include)
+	if err != nil {
+		return nil, err
+	}
+	debugSampler, err := newDebugSampler(cfg.Debug)
+	if err != nil {
+		return nil, err
 	}
 
-	return &sourceProcessor{
+	sp := &sourceProcessor{
 		collector:             cfg.Collector,
+		fields:                cfg.Fields,
+		podLabelFields:        cfg.PodLabelFields,
 		keys:                  keys,
+		annotationKeyPrefixes: cfg.AnnotationKeyPrefixes,
+		logger:                logger,
+		debugSampler:          debugSampler,
+		annotationTemplates:   newTemplateCache(keys, logger),
+		sourceAttributes:      make(map[sourceAttributesKey][]string),
 		source:                cfg.Source,
-		sourceHostFiller:      createSourceHostFiller(),
-		sourceCategoryFiller:  createSourceCategoryFiller(cfg, keys),
-		sourceNameFiller:      createSourceNameFiller(cfg, keys),
+		logsFillers:           logsFillers,
+		metricsFillers:        metricsFillers,
+		tracesFillers:         tracesFillers,
 		excludeNamespaceRegex: compileRegex(cfg.ExcludeNamespaceRegex),
 		excludeHostRegex:      compileRegex(cfg.ExcludeHostRegex),
 		excludeContainerRegex: compileRegex(cfg.ExcludeContainerRegex),
 		excludePodRegex:       compileRegex(cfg.ExcludePodRegex),
+		exclude:               exclude,
+		include:               include,
+	}
+	sp.cacheAnnotationAttributes()
+	return sp, nil
+}
+
+// cacheAnnotationAttributes builds the attribute names of the annotations used by the processor
+func (sp *sourceProcessor) cacheAnnotationAttributes() {
+	knownAnnotationAttributes := make(map[string][]string)
+	knownNamespaceAnnotationAttributes := make(map[string][]string)
+	for _, annotationName := range []string{
+		sourceHostSpecialAnnotation,
+		sourceNameSpecialAnnotation,
+		sourceCategorySpecialAnnotation,
+		includeAnnotation,
+		excludeAnnotation,
+		fieldsAnnotation,
+	} {
+		knownAnnotationAttributes[annotationName] = sp.annotationAttributes(annotationName)
+		knownNamespaceAnnotationAttributes[annotationName] = sp.namespaceAnnotationAttributes(annotationName)
+	}
+	sp.knownAnnotationAttributes = knownAnnotationAttributes
+	sp.knownNamespaceAnnotationAttributes = knownNamespaceAnnotationAttributes
+
+	// In the order fillAnnotationFields applies them, i.e. from the lowest precedence
+	for _, scoped := range [][]string{
+		sp.namespaceAnnotationAttributes(fieldsAnnotation),
+		sp.annotationAttributes(fieldsAnnotation),
+	} {
+		for i := len(scoped) - 1; i >= 0; i-- {
+			sp.fieldsAnnotationAttributes = append(sp.fieldsAnnotationAttributes, scoped[i])
+		}
 	}
 }
 
@@ -163,26 +270,58 @@ func (sp *sourceProcessor) fillOtherMeta(atts pdata.AttributeMap) {
 	if sp.collector != "" {
 		atts.UpsertString(collectorKey, sp.collector)
 	}
+	for key, value := range sp.fields {
+		atts.UpsertString(key, value)
+	}
+}
+
+// fillPodLabelFields sets the fields from the selected pod labels, renamed according to the config.
+// The labels which are not strings, e.g. set by the other processors, are converted to strings.
+func (sp *sourceProcessor) fillPodLabelFields(atts pdata.AttributeMap) {
+	for label, field := range sp.podLabelFields {
+		value, found := atts.Get(sp.keys.podLabelPrefix + label)
+		if !found {
+			continue
+		}
+		atts.UpsertString(field, pdata.AttributeValueToString(value))
+	}
+}
+
+// fillAnnotationFields sets the fields listed in the sumologic.com/fields annotation, e.g. "team=a,env=prod".
+// The fields of the namespace are set first, so the pod ones override them. Likewise, the annotations
+// with a prefix of lower precedence are set first.
+func (sp *sourceProcessor) fillAnnotationFields(atts pdata.AttributeMap) {
+	for _, attributeName := range sp.fieldsAnnotationAttributes {
+		value, found := atts.Get(attributeName)
+		if !found || value.Type() != pdata.AttributeValueTypeString {
+			continue
+		}
+		for _, field := range strings.Split(value.StringVal(), ",") {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+				continue
+			}
+			atts.UpsertString(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+		}
+	}
 }
 
 func (sp *sourceProcessor) isFilteredOut(atts pdata.AttributeMap) bool {
 	// TODO: This is quite inefficient when done for each package (ore even more so, span) separately.
 	// It should be moved to K8S Meta Processor and done once per new pod/changed pod
 
-	if value, found := atts.Get(sp.annotationAttribute(excludeAnnotation)); found {
-		if value.Type() == pdata.AttributeValueTypeString && value.StringVal() == "true" {
-			return true
-		} else if value.Type() == pdata.AttributeValueTypeBool && value.BoolVal() {
-			return true
-		}
+	// The pod annotations take precedence over the namespace ones
+	if isAnyAnnotationSet(atts, sp.annotationAttributes(excludeAnnotation)) {
+		return true
 	}
-
-	if value, found := atts.Get(sp.annotationAttribute(includeAnnotation)); found {
-		if value.Type() == pdata.AttributeValueTypeString && value.StringVal() == "true" {
-			return false
-		} else if value.Type() == pdata.AttributeValueTypeBool && value.BoolVal() {
-			return false
-		}
+	if isAnyAnnotationSet(atts, sp.annotationAttributes(includeAnnotation)) {
+		return false
+	}
+	if isAnyAnnotationSet(atts, sp.namespaceAnnotationAttributes(excludeAnnotation)) {
+		return true
+	}
+	if isAnyAnnotationSet(atts, sp.namespaceAnnotationAttributes(includeAnnotation)) {
+		return false
 	}
 
 	if matchRegexMaybe(sp.excludeNamespaceRegex, atts, sp.keys.namespaceKey) {
@@ -197,12 +336,112 @@ func (sp *sourceProcessor) isFilteredOut(atts pdata.AttributeMap) bool {
 	if matchRegexMaybe(sp.excludeHostRegex, atts, sp.keys.sourceHostKey) {
 		return true
 	}
+	if sp.exclude.matches(atts) {
+		return true
+	}
 
 	return false
 }
 
-func (sp *sourceProcessor) annotationAttribute(annotationKey string) string {
-	return sp.keys.annotationPrefix + annotationKey
+// isAnyAnnotationSet returns true if any of the annotations is present and set to true
+func isAnyAnnotationSet(atts pdata.AttributeMap, attributeNames []string) bool {
+	for _, attributeName := range attributeNames {
+		if isAnnotationSet(atts, attributeName) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAnnotationSet returns true if the annotation is present and set to true
+func isAnnotationSet(atts pdata.AttributeMap, attributeName string) bool {
+	value, found := atts.Get(attributeName)
+	if !found {
+		return false
+	}
+	if value.Type() == pdata.AttributeValueTypeString && value.StringVal() == "true" {
+		return true
+	}
+	return value.Type() == pdata.AttributeValueTypeBool && value.BoolVal()
+}
+
+// isResourceIncluded returns true if there are no include rules or the resource attributes match them
+func (sp *sourceProcessor) isResourceIncluded(atts pdata.AttributeMap) bool {
+	return sp.include == nil || sp.include.matches(atts)
+}
+
+// isRecordFilteredOut checks the attributes of a single span or log record against the include and exclude rules
+func (sp *sourceProcessor) isRecordFilteredOut(resourceIncluded bool, atts pdata.AttributeMap) bool {
+	if !resourceIncluded && !sp.include.matches(atts) {
+		return true
+	}
+	return sp.exclude.matches(atts)
+}
+
+// annotationAttributes returns the attributes holding the pod annotation with each of the annotation
+// key prefixes, in the order of precedence
+func (sp *sourceProcessor) annotationAttributes(annotationName string) []string {
+	if attributes, found := sp.knownAnnotationAttributes[annotationName]; found {
+		return attributes
+	}
+	return prefixedAnnotationAttributes(sp.keys.annotationPrefix, sp.annotationKeyPrefixes, annotationName)
+}
+
+// namespaceAnnotationAttributes returns the attributes holding the namespace annotation with each of
+// the annotation key prefixes, in the order of precedence
+func (sp *sourceProcessor) namespaceAnnotationAttributes(annotationName string) []string {
+	if attributes, found := sp.knownNamespaceAnnotationAttributes[annotationName]; found {
+		return attributes
+	}
+	return prefixedAnnotationAttributes(sp.keys.namespaceAnnotationPrefix, sp.annotationKeyPrefixes, annotationName)
+}
+
+func prefixedAnnotationAttributes(attributePrefix string, annotationKeyPrefixes []string, annotationName string) []string {
+	attributes := make([]string, len(annotationKeyPrefixes))
+	for i, annotationKeyPrefix := range annotationKeyPrefixes {
+		attributes[i] = attributePrefix + annotationKeyPrefix + annotationName
+	}
+	return attributes
+}
+
+// sourceAnnotationAttributes returns the attributes which may hold the given source annotation, in the order
+// of precedence: the container specific annotations (e.g. sumologic.com/<container>.sourceCategory) come first,
+// followed by the pod-level ones and the namespace defaults. Within each of them, the annotation key prefixes
+// are considered in the configured order.
+func (sp *sourceProcessor) sourceAnnotationAttributes(atts pdata.AttributeMap, annotationName string) []string {
+	container := ""
+	if value, found := atts.Get(sp.keys.containerKey); found {
+		container = value.StringVal()
+	}
+
+	key := sourceAttributesKey{container: container, annotationName: annotationName}
+	sp.sourceAttributesMu.RLock()
+	attributes, found := sp.sourceAttributes[key]
+	sp.sourceAttributesMu.RUnlock()
+	if found {
+		return attributes
+	}
+
+	attributes = make([]string, 0, 3*len(sp.annotationKeyPrefixes))
+	if container != "" {
+		attributes = append(attributes, sp.annotationAttributes(containerAnnotation(container, annotationName))...)
+	}
+	attributes = append(attributes, sp.annotationAttributes(annotationName)...)
+	attributes = append(attributes, sp.namespaceAnnotationAttributes(annotationName)...)
+
+	sp.sourceAttributesMu.Lock()
+	if len(sp.sourceAttributes) >= maxCachedSourceAttributes {
+		sp.sourceAttributes = make(map[sourceAttributesKey][]string)
+	}
+	sp.sourceAttributes[key] = attributes
+	sp.sourceAttributesMu.Unlock()
+	return attributes
+}
+
+// containerAnnotation returns the container scoped variant of the annotation name. The container name is
+// separated with a dot, as slashes are not allowed in the annotation names.
+func containerAnnotation(container string, annotationName string) string {
+	return container + "." + annotationName
 }
 
 // ProcessTraces processes traces
@@ -213,7 +452,7 @@ func (sp *sourceProcessor) ProcessTraces(ctx context.Context, td pdata.Traces) (
 		observability.RecordResourceSpansProcessed()
 
 		rs := rss.At(i)
-		res := sp.processResource(rs.Resource())
+		res := sp.processResource(rs.Resource(), sp.tracesFillers)
 		atts := res.Attributes()
 
 		ilss := rs.InstrumentationLibrarySpans()
@@ -226,9 +465,26 @@ func (sp *sourceProcessor) ProcessTraces(ctx context.Context, td pdata.Traces) (
 		if sp.isFilteredOut(atts) {
 			rs.InstrumentationLibrarySpans().RemoveIf(func(pdata.InstrumentationLibrarySpans) bool { return true })
 			observability.RecordFilteredOutN(totalSpans)
-		} else {
-			observability.RecordFilteredInN(totalSpans)
+			continue
+		}
+
+		filteredOutSpans := 0
+		resourceIncluded := sp.isResourceIncluded(atts)
+		if !resourceIncluded || sp.exclude != nil {
+			for j := 0; j < ilss.Len(); j++ {
+				ilss.At(j).Spans().RemoveIf(func(span pdata.Span) bool {
+					if sp.isRecordFilteredOut(resourceIncluded, span.Attributes()) {
+						filteredOutSpans++
+						return true
+					}
+					return false
+				})
+			}
+		}
+		if filteredOutSpans > 0 {
+			observability.RecordFilteredOutN(filteredOutSpans)
 		}
+		observability.RecordFilteredInN(totalSpans - filteredOutSpans)
 	}
 
 	return td, nil
@@ -240,10 +496,11 @@ func (sp *sourceProcessor) ProcessMetrics(ctx context.Context, md pdata.Metrics)
 
 	for i := 0; i < rss.Len(); i++ {
 		rs := rss.At(i)
-		res := sp.processResource(rs.Resource())
+		res := sp.processResource(rs.Resource(), sp.metricsFillers)
 		atts := res.Attributes()
 
-		if sp.isFilteredOut(atts) {
+		// The metrics are matched only using the resource attributes
+		if sp.isFilteredOut(atts) || !sp.isResourceIncluded(atts) {
 			rs.InstrumentationLibraryMetrics().RemoveIf(func(pdata.InstrumentationLibraryMetrics) bool { return true })
 		}
 	}
@@ -257,11 +514,22 @@ func (sp *sourceProcessor) ProcessLogs(ctx context.Context, md pdata.Logs) (pdat
 
 	for i := 0; i < rss.Len(); i++ {
 		rs := rss.At(i)
-		res := sp.processResource(rs.Resource())
+		res := sp.processResource(rs.Resource(), sp.logsFillers)
 		atts := res.Attributes()
 
 		if sp.isFilteredOut(atts) {
 			rs.InstrumentationLibraryLogs().RemoveIf(func(pdata.InstrumentationLibraryLogs) bool { return true })
+			continue
+		}
+
+		resourceIncluded := sp.isResourceIncluded(atts)
+		if !resourceIncluded || sp.exclude != nil {
+			ills := rs.InstrumentationLibraryLogs()
+			for j := 0; j < ills.Len(); j++ {
+				ills.At(j).Logs().RemoveIf(func(log pdata.LogRecord) bool {
+					return sp.isRecordFilteredOut(resourceIncluded, log.Attributes())
+				})
+			}
 		}
 	}
 
@@ -270,27 +538,44 @@ func (sp *sourceProcessor) ProcessLogs(ctx context.Context, md pdata.Logs) (pdat
 
 // processResource performs multiple actions on resource:
 //   - enrich pod name, so it can be used in templates
-//   - fills source attributes based on config or annotations
+//   - fills source attributes based on config (for the given signal) or annotations
 //   - set metadata (collector name)
-func (sp *sourceProcessor) processResource(res pdata.Resource) pdata.Resource {
+func (sp *sourceProcessor) processResource(res pdata.Resource, fillers sourceFillers) pdata.Resource {
 	atts := res.Attributes()
 
+	var input pdata.AttributeMap
+	debug := sp.debugSampler.sample()
+	if debug {
+		input = pdata.NewAttributeMap()
+		atts.CopyTo(input)
+	}
+
 	sp.enrichPodName(&atts)
 	sp.fillOtherMeta(atts)
+	sp.fillPodLabelFields(atts)
+	sp.fillAnnotationFields(atts)
 
-	sp.sourceHostFiller.fillResourceOrUseAnnotation(&atts,
-		sp.annotationAttribute(sourceHostSpecialAnnotation),
-		sp.keys,
+	sourceHostRule, _ := fillers.sourceHostFiller.fillResourceOrUseAnnotation(res,
+		sp.sourceAnnotationAttributes(atts, sourceHostSpecialAnnotation),
+		sp.annotationTemplates,
 	)
-	sp.sourceCategoryFiller.fillResourceOrUseAnnotation(&atts,
-		sp.annotationAttribute(sourceCategorySpecialAnnotation),
-		sp.keys,
+	sourceCategoryRule, _ := fillers.sourceCategoryFiller.fillResourceOrUseAnnotation(res,
+		sp.sourceAnnotationAttributes(atts, sourceCategorySpecialAnnotation),
+		sp.annotationTemplates,
 	)
-	sp.sourceNameFiller.fillResourceOrUseAnnotation(&atts,
-		sp.annotationAttribute(sourceNameSpecialAnnotation),
-		sp.keys,
+	sourceNameRule, _ := fillers.sourceNameFiller.fillResourceOrUseAnnotation(res,
+		sp.sourceAnnotationAttributes(atts, sourceNameSpecialAnnotation),
+		sp.annotationTemplates,
 	)
 
+	if debug {
+		sp.logResolution(input, atts, map[string]fillRule{
+			sourceHostKey:     sourceHostRule,
+			sourceCategoryKey: sourceCategoryRule,
+			sourceNameKey:     sourceNameRule,
+		})
+	}
+
 	return res
 }
 
@@ -331,100 +616,186 @@ func (sp *sourceProcessor) enrichPodName(atts *pdata.AttributeMap) {
 		return
 	}
 
-	podParts := strings.Split(pod.StringVal(), "-")
-	if len(podParts) < 2 {
+	// The parts are found by index rather than by splitting the name, to avoid allocations
+	podName := pod.StringVal()
+	lastDash := strings.LastIndexByte(podName, '-')
+	if lastDash < 0 {
 		// This is unexpected, fallback
 		return
 	}
+	podName = podName[:lastDash]
 
 	podTemplateHashAttr, found := atts.Get(sp.keys.podTemplateHashKey)
 
-	if found && len(podParts) > 2 {
+	if hashDash := strings.LastIndexByte(podName, '-'); found && hashDash >= 0 {
 		podTemplateHash := podTemplateHashAttr.StringVal()
-		if podTemplateHash == podParts[len(podParts)-2] || SafeEncodeString(podTemplateHash) == podParts[len(podParts)-2] {
-			atts.UpsertString(sp.keys.podNameKey, strings.Join(podParts[:len(podParts)-2], "-"))
+		hash := podName[hashDash+1:]
+		if podTemplateHash == hash || SafeEncodeString(podTemplateHash) == hash {
+			atts.UpsertString(sp.keys.podNameKey, podName[:hashDash])
 			return
 		}
 	}
-	atts.UpsertString(sp.keys.podNameKey, strings.Join(podParts[:len(podParts)-1], "-"))
+	atts.UpsertString(sp.keys.podNameKey, podName)
 }
 
-func extractFormat(format string, name string, keys sourceKeys) attributeFiller {
-	labels := make([]string, 0)
-	matches := formatRegex.FindAllStringSubmatch(format, -1)
-	for _, matchset := range matches {
-		labels = append(labels, keys.convertKey(matchset[1]))
+func extractFormat(format string, name string, keys sourceKeys) (attributeFiller, error) {
+	template, err := parseTemplate(format, keys)
+	if err != nil {
+		return attributeFiller{}, err
 	}
-	template := formatRegex.ReplaceAllString(format, "%s")
 
 	return attributeFiller{
 		name:            name,
-		compiledFormat:  template,
 		dashReplacement: "",
-		labels:          labels,
 		prefix:          "",
+		template:        template,
+	}, nil
+}
+
+// parseExpressions compiles the OTTL expressions computing the attribute
+func parseExpressions(texts []string) ([]*ottl.Expression, error) {
+	expressions := make([]*ottl.Expression, 0, len(texts))
+	for _, text := range texts {
+		expression, err := ottl.ParseExpression(ottl.ResourceContext, text)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expression)
 	}
+	return expressions, nil
 }
 
-func createSourceHostFiller() attributeFiller {
-	return attributeFiller{
-		name:            sourceHostKey,
-		compiledFormat:  "",
-		dashReplacement: "",
-		labels:          make([]string, 0),
-		prefix:          "",
+// createSourceFillers creates the fillers using the templates of the signal, if overridden
+func createSourceFillers(cfg *Config, signalCfg *SignalConfig, keys sourceKeys) (sourceFillers, error) {
+	merged := *cfg
+	if signalCfg != nil {
+		if signalCfg.SourceCategory != "" {
+			merged.SourceCategory = signalCfg.SourceCategory
+		}
+		if signalCfg.SourceName != "" {
+			merged.SourceName = signalCfg.SourceName
+		}
+		if signalCfg.SourceHost != "" {
+			merged.SourceHost = signalCfg.SourceHost
+		}
 	}
+
+	sourceCategoryFiller, err := createSourceCategoryFiller(&merged, keys)
+	if err != nil {
+		return sourceFillers{}, err
+	}
+	sourceNameFiller, err := createSourceNameFiller(&merged, keys)
+	if err != nil {
+		return sourceFillers{}, err
+	}
+	sourceHostFiller, err := createSourceHostFiller(&merged, keys)
+	if err != nil {
+		return sourceFillers{}, err
+	}
+	return sourceFillers{
+		sourceCategoryFiller: sourceCategoryFiller,
+		sourceNameFiller:     sourceNameFiller,
+		sourceHostFiller:     sourceHostFiller,
+	}, nil
 }
 
-func createSourceNameFiller(cfg *Config, keys sourceKeys) attributeFiller {
-	filler := extractFormat(cfg.SourceName, sourceNameKey, keys)
-	return filler
+func createSourceHostFiller(cfg *Config, keys sourceKeys) (attributeFiller, error) {
+	filler, err := extractFormat(cfg.SourceHost, sourceHostKey, keys)
+	if err != nil {
+		return filler, err
+	}
+	filler.ottlExpressions, err = parseExpressions(cfg.SourceHostExpressions)
+	if err != nil {
+		return filler, err
+	}
+	filler.dashReplacement = cfg.SourceHostReplaceDash
+	return filler, nil
 }
 
-func createSourceCategoryFiller(cfg *Config, keys sourceKeys) attributeFiller {
-	filler := extractFormat(cfg.SourceCategory, sourceCategoryKey, keys)
-	filler.compiledFormat = cfg.SourceCategoryPrefix + filler.compiledFormat
+func createSourceNameFiller(cfg *Config, keys sourceKeys) (attributeFiller, error) {
+	filler, err := extractFormat(cfg.SourceName, sourceNameKey, keys)
+	if err != nil {
+		return filler, err
+	}
+	filler.ottlExpressions, err = parseExpressions(cfg.SourceNameExpressions)
+	if err != nil {
+		return filler, err
+	}
+	filler.dashReplacement = cfg.SourceNameReplaceDash
+	return filler, nil
+}
+
+func createSourceCategoryFiller(cfg *Config, keys sourceKeys) (attributeFiller, error) {
+	filler, err := extractFormat(cfg.SourceCategory, sourceCategoryKey, keys)
+	if err != nil {
+		return filler, err
+	}
+	filler.ottlExpressions, err = parseExpressions(cfg.SourceCategoryExpressions)
+	if err != nil {
+		return filler, err
+	}
 	filler.dashReplacement = cfg.SourceCategoryReplaceDash
 	filler.prefix = cfg.SourceCategoryPrefix
-	return filler
+	return filler, nil
 }
 
-func (f *attributeFiller) fillResourceOrUseAnnotation(atts *pdata.AttributeMap, annotationKey string, keys sourceKeys) bool {
-	val, found := atts.Get(annotationKey)
-	if found {
-		annotationFiller := extractFormat(val.StringVal(), f.name, keys)
-		annotationFiller.dashReplacement = f.dashReplacement
-		annotationFiller.compiledFormat = f.prefix + annotationFiller.compiledFormat
-		return annotationFiller.fillAttributes(atts)
+// fillResourceOrUseAnnotation fills the attribute using the template from the first annotation found,
+// the OTTL expressions or the configured template if none of them is present. Annotations with invalid
+// templates are used literally. The returned rule describes which of them decided the value.
+func (f *attributeFiller) fillResourceOrUseAnnotation(res pdata.Resource, annotationKeys []string, templates *templateCache) (fillRule, bool) {
+	atts := res.Attributes()
+	for _, annotationKey := range annotationKeys {
+		val, found := atts.Get(annotationKey)
+		if found {
+			template := templates.get(annotationKey, val.StringVal())
+			return fillRule{kind: annotationRule, annotation: annotationKey, source: template.text}, f.fillTemplate(atts, template)
+		}
 	}
-	return f.fillAttributes(atts)
+	if expression, ok := f.fillFromExpressions(res); ok {
+		return fillRule{kind: expressionRule, source: expression}, true
+	}
+	return fillRule{kind: templateRule, source: f.template.text}, f.fillAttributes(atts)
 }
 
-func (f *attributeFiller) fillAttributes(atts *pdata.AttributeMap) bool {
-	if len(f.compiledFormat) == 0 {
-		return false
+// fillFromExpressions sets the attribute to the value of the first OTTL expression which yields a non-empty string
+// and returns that expression
+func (f *attributeFiller) fillFromExpressions(res pdata.Resource) (string, bool) {
+	if len(f.ottlExpressions) == 0 {
+		return "", false
 	}
 
-	labelValues := f.resourceLabelValues(atts)
-	if labelValues != nil {
-		str := fmt.Sprintf(f.compiledFormat, labelValues...)
-		if f.dashReplacement != "" {
-			str = strings.ReplaceAll(str, "-", f.dashReplacement)
+	tCtx := &ottl.TransformContext{Resource: res}
+	for _, expression := range f.ottlExpressions {
+		value, ok := expression.EvalString(tCtx)
+		if !ok || value == "" {
+			continue
 		}
-		atts.UpsertString(f.name, str)
-		return true
+		f.upsert(res.Attributes(), f.prefix+value)
+		return expression.String(), true
 	}
-	return false
+	return "", false
 }
 
-func (f *attributeFiller) resourceLabelValues(atts *pdata.AttributeMap) []interface{} {
-	arr := make([]interface{}, 0)
-	for _, label := range f.labels {
-		value, ok := atts.Get(label)
-		if !ok {
-			return nil
-		}
-		arr = append(arr, value.StringVal())
+func (f *attributeFiller) fillAttributes(atts pdata.AttributeMap) bool {
+	if f.prefix == "" && f.template.text == "" {
+		return false
+	}
+	return f.fillTemplate(atts, f.template)
+}
+
+// fillTemplate sets the attribute to the rendered template, unless any of the attributes it refers to is missing
+func (f *attributeFiller) fillTemplate(atts pdata.AttributeMap, template *compiledTemplate) bool {
+	str, ok := template.render(f.prefix, atts)
+	if !ok {
+		return false
+	}
+	f.upsert(atts, str)
+	return true
+}
+
+func (f *attributeFiller) upsert(atts pdata.AttributeMap, str string) {
+	if f.dashReplacement != "" {
+		str = strings.ReplaceAll(str, "-", f.dashReplacement)
 	}
-	return arr
+	atts.UpsertString(f.name, str)
 }
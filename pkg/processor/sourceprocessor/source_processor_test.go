@@ -19,7 +19,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func createConfig() *Config {
@@ -152,7 +156,8 @@ func TestTraceSourceProcessor(t *testing.T) {
 	want := newTraceData(mergedK8sLabelsWithMeta)
 	test := newTraceData(k8sLabels)
 
-	rtp := newSourceProcessor(cfg)
+	rtp, err := newSourceProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
 
 	td, err := rtp.ProcessTraces(context.Background(), test)
 	assert.NoError(t, err)
@@ -172,7 +177,8 @@ func TestTraceSourceProcessorNewTaxonomy(t *testing.T) {
 	config.PodTemplateHashKey = "k8s.pod.labels.pod-template-hash"
 	config.ContainerKey = "k8s.container.name"
 
-	rtp := newSourceProcessor(config)
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
 
 	td, err := rtp.ProcessTraces(context.Background(), test)
 	assert.NoError(t, err)
@@ -184,7 +190,8 @@ func TestTraceSourceProcessorEmpty(t *testing.T) {
 	want := newTraceData(limitedLabelsWithMeta)
 	test := newTraceData(limitedLabels)
 
-	rtp := newSourceProcessor(cfg)
+	rtp, err := newSourceProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
 
 	td, err := rtp.ProcessTraces(context.Background(), test)
 	assert.NoError(t, err)
@@ -208,7 +215,8 @@ func TestTraceSourceFilteringOutByRegex(t *testing.T) {
 		want.ResourceSpans().At(0).InstrumentationLibrarySpans().
 			RemoveIf(func(pdata.InstrumentationLibrarySpans) bool { return true })
 
-		rtp := newSourceProcessor(config)
+		rtp, err := newSourceProcessor(zap.NewNop(), config)
+		require.NoError(t, err)
 
 		td, err := rtp.ProcessTraces(context.Background(), test)
 		assert.NoError(t, err)
@@ -226,7 +234,8 @@ func TestTraceSourceFilteringOutByExclude(t *testing.T) {
 	want.ResourceSpans().At(0).InstrumentationLibrarySpans().
 		RemoveIf(func(pdata.InstrumentationLibrarySpans) bool { return true })
 
-	rtp := newSourceProcessor(cfg)
+	rtp, err := newSourceProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
 
 	td, err := rtp.ProcessTraces(context.Background(), test)
 	assert.NoError(t, err)
@@ -243,7 +252,8 @@ func TestTraceSourceIncludePrecedence(t *testing.T) {
 
 	cfg1 := createConfig()
 	cfg1.ExcludePodRegex = ".*"
-	rtp := newSourceProcessor(cfg)
+	rtp, err := newSourceProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
 
 	td, err := rtp.ProcessTraces(context.Background(), test)
 	assert.NoError(t, err)
@@ -262,10 +272,558 @@ func TestTraceSourceProcessorAnnotations(t *testing.T) {
 	mergedK8sLabelsWithMeta["_sourceCategory"] = "prefix/sc:pod#1234"
 	want := newTraceData(mergedK8sLabelsWithMeta)
 
-	rtp := newSourceProcessor(cfg)
+	rtp, err := newSourceProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
 
 	td, err := rtp.ProcessTraces(context.Background(), test)
 	assert.NoError(t, err)
 
 	assertTracesEqual(t, td, want)
 }
+
+func TestTraceSourceProcessorContainerAnnotations(t *testing.T) {
+	newLabels := func(container string) map[string]string {
+		return map[string]string{
+			"namespace": "namespace-1",
+			"pod_id":    "pod-1234",
+			"pod":       "pod-5db86d8867-sdqlj",
+			"container": container,
+			"pod_annotation_sumologic.com/sourceCategory":         "pod-level",
+			"pod_annotation_sumologic.com/sidecar.sourceCategory": "sidecar-%{container}",
+			"pod_annotation_sumologic.com/sidecar.sourceName":     "sidecar-name",
+		}
+	}
+
+	rtp, err := newSourceProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+
+	td, err := rtp.ProcessTraces(context.Background(), newTraceData(newLabels("sidecar")))
+	assert.NoError(t, err)
+	atts := td.ResourceSpans().At(0).Resource().Attributes()
+	sourceCategory, _ := atts.Get("_sourceCategory")
+	assert.Equal(t, "prefix/sidecar#sidecar", sourceCategory.StringVal())
+	sourceName, _ := atts.Get("_sourceName")
+	assert.Equal(t, "sidecar-name", sourceName.StringVal())
+
+	// Other containers of the pod use the pod-level annotations
+	td, err = rtp.ProcessTraces(context.Background(), newTraceData(newLabels("app")))
+	assert.NoError(t, err)
+	atts = td.ResourceSpans().At(0).Resource().Attributes()
+	sourceCategory, _ = atts.Get("_sourceCategory")
+	assert.Equal(t, "prefix/pod#level", sourceCategory.StringVal())
+	sourceName, _ = atts.Get("_sourceName")
+	assert.Equal(t, "namespace-1.pod-5db86d8867-sdqlj.app", sourceName.StringVal())
+}
+
+func TestTraceSourceProcessorNamespaceAnnotations(t *testing.T) {
+	newLabels := func() map[string]string {
+		return map[string]string{
+			"namespace": "namespace-1",
+			"pod_id":    "pod-1234",
+			"pod":       "pod-5db86d8867-sdqlj",
+			"container": "container-1",
+			"namespace_annotation_sumologic.com/sourceCategory": "team-a/%{container}",
+			"namespace_annotation_sumologic.com/fields":         "team=a, env=prod",
+		}
+	}
+
+	rtp, err := newSourceProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+
+	td, err := rtp.ProcessTraces(context.Background(), newTraceData(newLabels()))
+	assert.NoError(t, err)
+	atts := td.ResourceSpans().At(0).Resource().Attributes()
+	sourceCategory, _ := atts.Get("_sourceCategory")
+	assert.Equal(t, "prefix/team#a/container#1", sourceCategory.StringVal())
+	team, _ := atts.Get("team")
+	assert.Equal(t, "a", team.StringVal())
+	env, _ := atts.Get("env")
+	assert.Equal(t, "prod", env.StringVal())
+
+	// The pod annotations override the namespace defaults
+	labels := newLabels()
+	labels["pod_annotation_sumologic.com/sourceCategory"] = "pod-level"
+	labels["pod_annotation_sumologic.com/fields"] = "team=b"
+	td, err = rtp.ProcessTraces(context.Background(), newTraceData(labels))
+	assert.NoError(t, err)
+	atts = td.ResourceSpans().At(0).Resource().Attributes()
+	sourceCategory, _ = atts.Get("_sourceCategory")
+	assert.Equal(t, "prefix/pod#level", sourceCategory.StringVal())
+	team, _ = atts.Get("team")
+	assert.Equal(t, "b", team.StringVal())
+	env, _ = atts.Get("env")
+	assert.Equal(t, "prod", env.StringVal())
+}
+
+func TestTraceSourceProcessorAnnotationKeyPrefixes(t *testing.T) {
+	labels := map[string]string{
+		"namespace": "namespace-1",
+		"pod":       "pod-5db86d8867-sdqlj",
+		"container": "container-1",
+		"pod_annotation_sumologic.com/sourceCategory":   "sumo",
+		"pod_annotation_logging.acme.io/sourceCategory": "legacy",
+		"pod_annotation_logging.acme.io/sourceName":     "legacy-name",
+		"pod_annotation_sumologic.com/fields":           "team=sumo",
+		"pod_annotation_logging.acme.io/fields":         "team=legacy, env=prod",
+	}
+
+	config := createConfig()
+	config.AnnotationKeyPrefixes = []string{"sumologic.com/", "logging.acme.io/"}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	td, err := rtp.ProcessTraces(context.Background(), newTraceData(labels))
+	assert.NoError(t, err)
+	atts := td.ResourceSpans().At(0).Resource().Attributes()
+	sourceCategory, _ := atts.Get("_sourceCategory")
+	assert.Equal(t, "prefix/sumo", sourceCategory.StringVal())
+	sourceName, _ := atts.Get("_sourceName")
+	assert.Equal(t, "legacy-name", sourceName.StringVal())
+	team, _ := atts.Get("team")
+	assert.Equal(t, "sumo", team.StringVal())
+	env, _ := atts.Get("env")
+	assert.Equal(t, "prod", env.StringVal())
+
+	// Legacy annotations are ignored unless their prefix is configured
+	labels["pod_annotation_logging.acme.io/exclude"] = "true"
+	td, err = rtp.ProcessTraces(context.Background(), newTraceDataWithSpans(labels, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, td.SpanCount())
+
+	rtp, err = newSourceProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	td, err = rtp.ProcessTraces(context.Background(), newTraceDataWithSpans(labels, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, td.SpanCount())
+}
+
+func TestTraceSourceFilteringOutByNamespaceExclude(t *testing.T) {
+	labels := map[string]string{
+		"namespace": "namespace-1",
+		"pod":       "pod-5db86d8867-sdqlj",
+		"namespace_annotation_sumologic.com/exclude": "true",
+	}
+
+	rtp, err := newSourceProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+
+	td, err := rtp.ProcessTraces(context.Background(), newTraceDataWithSpans(labels, k8sLabels))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, td.ResourceSpans().At(0).InstrumentationLibrarySpans().Len())
+
+	// The pod can opt in despite the namespace exclusion
+	labels["pod_annotation_sumologic.com/include"] = "true"
+	td, err = rtp.ProcessTraces(context.Background(), newTraceDataWithSpans(labels, k8sLabels))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, td.ResourceSpans().At(0).InstrumentationLibrarySpans().Len())
+}
+
+func TestTraceSourceFilteringOutByAttributes(t *testing.T) {
+	config := createConfig()
+	config.Exclude = map[string]string{
+		"pod_labels_team":  "^legacy-.*",
+		"http.status_code": "^2..$",
+	}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	// Resource attributes exclude all the spans
+	labels := map[string]string{"namespace": "namespace-1", "pod_labels_team": "legacy-payments"}
+	td, err := rtp.ProcessTraces(context.Background(), newTraceDataWithSpans(labels, k8sLabels))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, td.ResourceSpans().At(0).InstrumentationLibrarySpans().Len())
+
+	// Record attributes exclude only the given span, also when the value is not a string
+	labels["pod_labels_team"] = "payments"
+	test := newTraceDataWithSpans(labels, k8sLabels)
+	spans := test.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans()
+	spans.At(0).Attributes().UpsertInt("http.status_code", 200)
+	spans.AppendEmpty().Attributes().UpsertInt("http.status_code", 500)
+	td, err = rtp.ProcessTraces(context.Background(), test)
+	assert.NoError(t, err)
+	spans = td.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans()
+	require.Equal(t, 1, spans.Len())
+	statusCode, _ := spans.At(0).Attributes().Get("http.status_code")
+	assert.Equal(t, int64(500), statusCode.IntVal())
+}
+
+func TestLogsSourceFilteringOutByAttributes(t *testing.T) {
+	config := createConfig()
+	config.Exclude = map[string]string{"level": "debug"}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	ld := pdata.NewLogs()
+	logs := ld.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	logs.AppendEmpty().Attributes().UpsertString("level", "debug")
+	logs.AppendEmpty().Attributes().UpsertString("level", "error")
+
+	ld, err = rtp.ProcessLogs(context.Background(), ld)
+	assert.NoError(t, err)
+	logs = ld.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
+	require.Equal(t, 1, logs.Len())
+	level, _ := logs.At(0).Attributes().Get("level")
+	assert.Equal(t, "error", level.StringVal())
+}
+
+func TestInvalidExcludeRegex(t *testing.T) {
+	config := createConfig()
+	config.Exclude = map[string]string{"level": "("}
+	_, err := newSourceProcessor(zap.NewNop(), config)
+	assert.Error(t, err)
+}
+
+func TestTraceSourceIncludeOnly(t *testing.T) {
+	config := createConfig()
+	config.Include = map[string]string{
+		"namespace":   "^kube-system$",
+		"http.target": "^/health",
+	}
+	config.Exclude = map[string]string{"container": "^excluded$"}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	newTestTraceData := func(namespace string, container string) pdata.Traces {
+		td := newTraceDataWithSpans(map[string]string{"namespace": namespace, "container": container}, nil)
+		spans := td.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans()
+		spans.AppendEmpty().Attributes().UpsertString("http.target", "/healthz")
+		return td
+	}
+
+	// All spans of the matching resource are kept
+	td, err := rtp.ProcessTraces(context.Background(), newTestTraceData("kube-system", "coredns"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, td.SpanCount())
+
+	// Only the matching span is kept for other resources
+	td, err = rtp.ProcessTraces(context.Background(), newTestTraceData("default", "app"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, td.SpanCount())
+
+	// The exclusions still apply
+	td, err = rtp.ProcessTraces(context.Background(), newTestTraceData("kube-system", "excluded"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, td.SpanCount())
+}
+
+func TestMetricsSourceIncludeOnly(t *testing.T) {
+	config := createConfig()
+	config.Include = map[string]string{"namespace": "^kube-system$"}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	md := pdata.NewMetrics()
+	for _, namespace := range []string{"kube-system", "default"} {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().UpsertString("namespace", namespace)
+		rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty().SetName("metric")
+	}
+
+	md, err = rtp.ProcessMetrics(context.Background(), md)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().Len())
+	assert.Equal(t, 0, md.ResourceMetrics().At(1).InstrumentationLibraryMetrics().Len())
+}
+
+func TestTraceSourceProcessorDashReplacement(t *testing.T) {
+	labels := map[string]string{
+		"namespace": "namespace-1",
+		"pod":       "pod-5db86d8867-sdqlj",
+		"container": "container-1",
+		"pod_annotation_sumologic.com/sourceHost": "host-%{container}",
+	}
+
+	config := createConfig()
+	config.SourceCategoryReplaceDash = ""
+	config.SourceNameReplaceDash = "_"
+	config.SourceHostReplaceDash = "."
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	td, err := rtp.ProcessTraces(context.Background(), newTraceData(labels))
+	assert.NoError(t, err)
+	atts := td.ResourceSpans().At(0).Resource().Attributes()
+	sourceCategory, _ := atts.Get("_sourceCategory")
+	assert.Equal(t, "prefix/namespace-1/pod-5db86d8867", sourceCategory.StringVal())
+	sourceName, _ := atts.Get("_sourceName")
+	assert.Equal(t, "namespace_1.pod_5db86d8867_sdqlj.container_1", sourceName.StringVal())
+	sourceHost, _ := atts.Get("_sourceHost")
+	assert.Equal(t, "host.container.1", sourceHost.StringVal())
+}
+
+func TestSourceProcessorStaticFields(t *testing.T) {
+	config := createConfig()
+	config.Fields = map[string]string{"cluster": "prod-eu", "team": "platform"}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	ld := pdata.NewLogs()
+	ld.ResourceLogs().AppendEmpty()
+	ld.ResourceLogs().AppendEmpty().Resource().Attributes().UpsertString("pod_annotation_sumologic.com/fields", "team=payments")
+
+	ld, err = rtp.ProcessLogs(context.Background(), ld)
+	assert.NoError(t, err)
+	for i, team := range []string{"platform", "payments"} {
+		atts := ld.ResourceLogs().At(i).Resource().Attributes()
+		cluster, _ := atts.Get("cluster")
+		assert.Equal(t, "prod-eu", cluster.StringVal())
+		value, _ := atts.Get("team")
+		assert.Equal(t, team, value.StringVal())
+	}
+}
+
+func TestSourceProcessorExpressions(t *testing.T) {
+	config := createConfig()
+	config.SourceCategoryReplaceDash = ""
+	config.SourceCategoryExpressions = []string{
+		`Concat(["critical", attributes["namespace"]], "/") where attributes["tier"] == "critical"`,
+		`attributes["team"]`,
+	}
+	config.SourceHostExpressions = []string{`ConvertCase(attributes["node"], "upper")`}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		labels   map[string]string
+		expected string
+	}{
+		{"condition met", map[string]string{"tier": "critical", "team": "payments"}, "prefix/critical/namespace-1"},
+		{"second expression", map[string]string{"team": "payments"}, "prefix/payments"},
+		{"template fallback", map[string]string{}, "prefix/namespace-1/pod-5db86d8867"},
+		{"annotation precedence", map[string]string{"team": "payments", "pod_annotation_sumologic.com/sourceCategory": "annotated"}, "prefix/annotated"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			labels := map[string]string{"namespace": "namespace-1", "pod": "pod-5db86d8867-sdqlj", "node": "node-a"}
+			for k, v := range tc.labels {
+				labels[k] = v
+			}
+
+			td, err := rtp.ProcessTraces(context.Background(), newTraceData(labels))
+			assert.NoError(t, err)
+			atts := td.ResourceSpans().At(0).Resource().Attributes()
+			sourceCategory, _ := atts.Get("_sourceCategory")
+			assert.Equal(t, tc.expected, sourceCategory.StringVal())
+			sourceHost, _ := atts.Get("_sourceHost")
+			assert.Equal(t, "NODE-A", sourceHost.StringVal())
+		})
+	}
+}
+
+func TestInvalidExpression(t *testing.T) {
+	config := createConfig()
+	config.SourceNameExpressions = []string{`Concat(attributes["a"]`}
+	_, err := newSourceProcessor(zap.NewNop(), config)
+	assert.Error(t, err)
+}
+
+func TestSourceProcessorSignalOverrides(t *testing.T) {
+	labels := map[string]string{
+		"namespace": "namespace-1",
+		"pod":       "pod-5db86d8867-sdqlj",
+		"container": "container-1",
+		"node":      "node-a",
+	}
+
+	config := createConfig()
+	config.SourceCategoryReplaceDash = ""
+	config.Logs = &SignalConfig{SourceCategory: "logs/%{namespace}", SourceHost: "%{node}"}
+	config.Metrics = &SignalConfig{SourceName: "%{container}"}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	td, err := rtp.ProcessTraces(context.Background(), newTraceData(labels))
+	assert.NoError(t, err)
+	traceAtts := td.ResourceSpans().At(0).Resource().Attributes()
+
+	ld := pdata.NewLogs()
+	newTraceData(labels).ResourceSpans().At(0).Resource().Attributes().CopyTo(ld.ResourceLogs().AppendEmpty().Resource().Attributes())
+	ld, err = rtp.ProcessLogs(context.Background(), ld)
+	assert.NoError(t, err)
+	logAtts := ld.ResourceLogs().At(0).Resource().Attributes()
+
+	md := pdata.NewMetrics()
+	newTraceData(labels).ResourceSpans().At(0).Resource().Attributes().CopyTo(md.ResourceMetrics().AppendEmpty().Resource().Attributes())
+	md, err = rtp.ProcessMetrics(context.Background(), md)
+	assert.NoError(t, err)
+	metricAtts := md.ResourceMetrics().At(0).Resource().Attributes()
+
+	for _, tc := range []struct {
+		atts           pdata.AttributeMap
+		sourceCategory string
+		sourceName     string
+		sourceHost     string
+	}{
+		{traceAtts, "prefix/namespace-1/pod-5db86d8867", "namespace-1.pod-5db86d8867-sdqlj.container-1", ""},
+		{logAtts, "prefix/logs/namespace-1", "namespace-1.pod-5db86d8867-sdqlj.container-1", "node-a"},
+		{metricAtts, "prefix/namespace-1/pod-5db86d8867", "container-1", ""},
+	} {
+		sourceCategory, _ := tc.atts.Get("_sourceCategory")
+		assert.Equal(t, tc.sourceCategory, sourceCategory.StringVal())
+		sourceName, _ := tc.atts.Get("_sourceName")
+		assert.Equal(t, tc.sourceName, sourceName.StringVal())
+		sourceHost, found := tc.atts.Get("_sourceHost")
+		assert.Equal(t, tc.sourceHost != "", found)
+		if found {
+			assert.Equal(t, tc.sourceHost, sourceHost.StringVal())
+		}
+	}
+}
+
+func TestSourceProcessorPodLabelFields(t *testing.T) {
+	config := createConfig()
+	config.Fields = map[string]string{"app": "default", "team": "platform"}
+	config.PodLabelFields = map[string]string{
+		"app.kubernetes.io/name": "app",
+		"team":                   "team",
+		"missing":                "missing",
+	}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	labels := map[string]string{
+		"pod_labels_app.kubernetes.io/name":   "checkout",
+		"pod_labels_team":                     "payments",
+		"pod_labels_tier":                     "backend",
+		"pod_annotation_sumologic.com/fields": "team=checkout-team",
+	}
+	td, err := rtp.ProcessTraces(context.Background(), newTraceData(labels))
+	assert.NoError(t, err)
+	atts := td.ResourceSpans().At(0).Resource().Attributes()
+
+	app, _ := atts.Get("app")
+	assert.Equal(t, "checkout", app.StringVal())
+	// The annotation fields take precedence over the labels
+	team, _ := atts.Get("team")
+	assert.Equal(t, "checkout-team", team.StringVal())
+	_, found := atts.Get("missing")
+	assert.False(t, found)
+	_, found = atts.Get("tier")
+	assert.False(t, found)
+}
+
+func TestSourceProcessorPodLabelFieldsNotString(t *testing.T) {
+	config := createConfig()
+	config.PodLabelFields = map[string]string{"version": "version"}
+	rtp, err := newSourceProcessor(zap.NewNop(), config)
+	require.NoError(t, err)
+
+	td := newTraceData(map[string]string{})
+	td.ResourceSpans().At(0).Resource().Attributes().InsertInt("pod_labels_version", 2)
+	td, err = rtp.ProcessTraces(context.Background(), td)
+	assert.NoError(t, err)
+
+	version, found := td.ResourceSpans().At(0).Resource().Attributes().Get("version")
+	require.True(t, found)
+	assert.Equal(t, "2", version.StringVal())
+}
+
+func TestSourceProcessorDebugLogging(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	config := createConfig()
+	config.Debug = DebugConfig{Enabled: true, SampleEvery: 2}
+	config.SourceNameExpressions = []string{`attributes["app"]`}
+	rtp, err := newSourceProcessor(zap.New(core), config)
+	require.NoError(t, err)
+
+	labels := map[string]string{
+		"namespace": "namespace-1",
+		"pod":       "pod-5db86d8867-sdqlj",
+		"app":       "checkout",
+		"pod_annotation_sumologic.com/sourceCategory": "payments",
+	}
+	for i := 0; i < 3; i++ {
+		_, err = rtp.ProcessTraces(context.Background(), newTraceData(labels))
+		assert.NoError(t, err)
+	}
+
+	// Only the first and third resources are sampled
+	require.Equal(t, 2, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, map[string]interface{}{
+		"attributes": map[string]string{
+			"namespace": "namespace-1",
+			"pod":       "pod-5db86d8867-sdqlj",
+			"app":       "checkout",
+			"pod_annotation_sumologic.com/sourceCategory": "payments",
+		},
+		"_sourceCategory":      "prefix/payments",
+		"_sourceCategory_rule": `annotation pod_annotation_sumologic.com/sourceCategory: "payments"`,
+		"_sourceName":          "checkout",
+		"_sourceName_rule":     `expression: "attributes[\"app\"]"`,
+		"_sourceHost":          "<not set>",
+		"_sourceHost_rule":     `template: ""`,
+	}, fields)
+}
+
+func TestSourceProcessorMalformedAnnotationTemplate(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	rtp, err := newSourceProcessor(zap.New(core), cfg)
+	require.NoError(t, err)
+
+	labels := map[string]string{
+		"namespace": "namespace-1",
+		"pod":       "pod-5db86d8867-sdqlj",
+		"pod_annotation_sumologic.com/sourceCategory": "%{unknown(namespace)}-app",
+		"pod_annotation_sumologic.com/sourceName":     "%{namespace",
+	}
+	td, err := rtp.ProcessTraces(context.Background(), newTraceData(labels))
+	require.NoError(t, err)
+
+	// The malformed templates are used literally, with the prefix and the dash replacement applied as usual
+	atts := td.ResourceSpans().At(0).Resource().Attributes()
+	sourceCategory, found := atts.Get("_sourceCategory")
+	require.True(t, found)
+	assert.Equal(t, "prefix/%{unknown(namespace)}#app", sourceCategory.StringVal())
+	sourceName, found := atts.Get("_sourceName")
+	require.True(t, found)
+	assert.Equal(t, "%{namespace", sourceName.StringVal())
+
+	require.Equal(t, 2, logs.Len())
+	annotations := []interface{}{logs.All()[0].ContextMap()["annotation"], logs.All()[1].ContextMap()["annotation"]}
+	assert.ElementsMatch(t, []interface{}{
+		"pod_annotation_sumologic.com/sourceCategory",
+		"pod_annotation_sumologic.com/sourceName",
+	}, annotations)
+}
+
+func TestInvalidDebugConfig(t *testing.T) {
+	config := createConfig()
+	config.Debug = DebugConfig{Enabled: true, SampleEvery: 0}
+	_, err := newSourceProcessor(zap.NewNop(), config)
+	assert.Error(t, err)
+}
+
+func benchmarkProcessResource(b *testing.B, labels map[string]string) {
+	rtp, err := newSourceProcessor(zap.NewNop(), createConfig())
+	require.NoError(b, err)
+
+	resources := make([]pdata.Resource, b.N)
+	for i := range resources {
+		resources[i] = newTraceData(labels).ResourceSpans().At(0).Resource()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rtp.processResource(resources[i], rtp.tracesFillers)
+	}
+}
+
+func BenchmarkProcessResource(b *testing.B) {
+	benchmarkProcessResource(b, k8sLabels)
+}
+
+func BenchmarkProcessResourceWithAnnotations(b *testing.B) {
+	labels := map[string]string{
+		"pod_annotation_sumologic.com/sourceCategory":             "%{namespace}/%{lower(pod_name)}",
+		"pod_annotation_sumologic.com/container-1.sourceName":     "%{container}/%{pod_id}",
+		"namespace_annotation_sumologic.com/sourceHost":           "%{namespace}-host",
+		"namespace_annotation_sumologic.com/fields":               "team=a",
+		"pod_annotation_sumologic.com/container-2.sourceCategory": "other",
+	}
+	for k, v := range k8sLabels {
+		labels[k] = v
+	}
+	benchmarkProcessResource(b, labels)
+}
@@ -0,0 +1,471 @@
+// Copyright 2019 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// templateExpression is a single `%{...}` placeholder of a source template
+type templateExpression interface {
+	// evaluate returns the value of the expression and false if any of the attributes it refers to is missing
+	evaluate(atts pdata.AttributeMap) (string, bool)
+}
+
+// attributeExpression refers to the value of the attribute, e.g. `%{namespace}`
+type attributeExpression struct {
+	key string
+}
+
+func (e attributeExpression) evaluate(atts pdata.AttributeMap) (string, bool) {
+	value, found := atts.Get(e.key)
+	if !found {
+		return "", false
+	}
+	return value.StringVal(), true
+}
+
+// functionExpression applies a function to the value of the inner expression, e.g. `%{lower(namespace)}`
+type functionExpression struct {
+	arg   templateExpression
+	apply func(string) string
+}
+
+func (e functionExpression) evaluate(atts pdata.AttributeMap) (string, bool) {
+	value, found := e.arg.evaluate(atts)
+	if !found {
+		return "", false
+	}
+	return e.apply(value), true
+}
+
+// fallbackExpression evaluates to the first alternative which is available and not empty,
+// e.g. `%{deployment || statefulset || pod}`
+type fallbackExpression struct {
+	alternatives []templateExpression
+}
+
+func (e fallbackExpression) evaluate(atts pdata.AttributeMap) (string, bool) {
+	for _, alternative := range e.alternatives {
+		if value, found := alternative.evaluate(atts); found && value != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// literalExpression is a constant, which can be used as the last fallback, e.g. `%{deployment || "unknown"}`
+type literalExpression struct {
+	value string
+}
+
+func (e literalExpression) evaluate(pdata.AttributeMap) (string, bool) {
+	return e.value, true
+}
+
+// containerVariable returns the expression of the variables derived from the container attributes:
+// `container_id` (in the short form), `image_name` and `image_tag`
+func (stk sourceKeys) containerVariable(name string) (templateExpression, bool) {
+	switch name {
+	case "container_id":
+		return functionExpression{arg: attributeExpression{key: stk.containerIDKey}, apply: shortContainerID}, true
+	case "image_name":
+		return functionExpression{arg: attributeExpression{key: stk.containerImageKey}, apply: imageName}, true
+	case "image_tag":
+		return functionExpression{arg: attributeExpression{key: stk.containerImageKey}, apply: imageTag}, true
+	}
+	return nil, false
+}
+
+// shortContainerID strips the runtime scheme (e.g. `docker://`) and shortens the id to 12 characters, as docker does
+func shortContainerID(id string) string {
+	if i := strings.Index(id, "://"); i >= 0 {
+		id = id[i+3:]
+	}
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// splitImage splits the image reference (e.g. `registry:5000/app:1.2@sha256:...`) into the name, tag and digest
+func splitImage(image string) (name string, tag string, digest string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image, digest = image[:i], image[i+1:]
+	}
+	// The colon might also separate the registry port, so only the last path segment is considered
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:], digest
+	}
+	return image, "", digest
+}
+
+func imageName(image string) string {
+	name, _, _ := splitImage(image)
+	return name
+}
+
+// imageTag returns the tag of the image, defaulting to `latest` unless the image is pinned by a digest
+func imageTag(image string) string {
+	_, tag, digest := splitImage(image)
+	if tag == "" && digest == "" {
+		return "latest"
+	}
+	return tag
+}
+
+// templateFunctions compile the functions available in templates, given their (constant) parameters
+var templateFunctions = map[string]func(params []string) (func(string) string, error){
+	"lower": func(params []string) (func(string) string, error) {
+		return strings.ToLower, expectParams("lower", params, 0)
+	},
+	"upper": func(params []string) (func(string) string, error) {
+		return strings.ToUpper, expectParams("upper", params, 0)
+	},
+	"replace": func(params []string) (func(string) string, error) {
+		if err := expectParams("replace", params, 2); err != nil {
+			return nil, err
+		}
+		return func(value string) string {
+			return strings.ReplaceAll(value, params[0], params[1])
+		}, nil
+	},
+	"trim_prefix": func(params []string) (func(string) string, error) {
+		if err := expectParams("trim_prefix", params, 1); err != nil {
+			return nil, err
+		}
+		return func(value string) string {
+			return strings.TrimPrefix(value, params[0])
+		}, nil
+	},
+	"trim_suffix": func(params []string) (func(string) string, error) {
+		if err := expectParams("trim_suffix", params, 1); err != nil {
+			return nil, err
+		}
+		return func(value string) string {
+			return strings.TrimSuffix(value, params[0])
+		}, nil
+	},
+	"substr": compileSubstr,
+}
+
+func expectParams(function string, params []string, count int) error {
+	if len(params) != count {
+		return fmt.Errorf("function %s expects %d parameter(s) besides the value, got %d", function, count, len(params))
+	}
+	return nil
+}
+
+// compileSubstr compiles substr(value, start) and substr(value, start, length), counting the characters
+// (not bytes) and clamping the range to the value
+func compileSubstr(params []string) (func(string) string, error) {
+	if len(params) != 1 && len(params) != 2 {
+		return nil, fmt.Errorf("function substr expects 1 or 2 parameter(s) besides the value, got %d", len(params))
+	}
+	start, err := strconv.Atoi(params[0])
+	if err != nil || start < 0 {
+		return nil, fmt.Errorf("substr start must be a non-negative integer, got %q", params[0])
+	}
+	length := -1
+	if len(params) == 2 {
+		length, err = strconv.Atoi(params[1])
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("substr length must be a non-negative integer, got %q", params[1])
+		}
+	}
+
+	return func(value string) string {
+		runes := []rune(value)
+		if start >= len(runes) {
+			return ""
+		}
+		end := len(runes)
+		if length >= 0 && start+length < end {
+			end = start + length
+		}
+		return string(runes[start:end])
+	}, nil
+}
+
+// compiledTemplate is a template split into the literal parts and the placeholder expressions between them,
+// so it is rendered without being parsed again. There is one more literal than expressions.
+type compiledTemplate struct {
+	text        string
+	literals    []string
+	expressions []templateExpression
+}
+
+// maxTemplateValues is the number of placeholder values which are rendered without a heap allocation
+const maxTemplateValues = 8
+
+// render returns the prefix followed by the rendered template, or false if any of the attributes
+// the template refers to is missing
+func (t *compiledTemplate) render(prefix string, atts pdata.AttributeMap) (string, bool) {
+	values := make([]string, 0, maxTemplateValues)
+	size := len(prefix)
+	for _, expression := range t.expressions {
+		value, ok := expression.evaluate(atts)
+		if !ok {
+			return "", false
+		}
+		values = append(values, value)
+		size += len(value)
+	}
+	for _, literal := range t.literals {
+		size += len(literal)
+	}
+
+	var sb strings.Builder
+	sb.Grow(size)
+	sb.WriteString(prefix)
+	for i, value := range values {
+		sb.WriteString(t.literals[i])
+		sb.WriteString(value)
+	}
+	sb.WriteString(t.literals[len(t.literals)-1])
+	return sb.String(), true
+}
+
+// parseTemplate compiles the template. The attribute names are translated using keys.
+func parseTemplate(text string, keys sourceKeys) (*compiledTemplate, error) {
+	template := &compiledTemplate{text: text}
+
+	rest := text
+	for {
+		start := strings.Index(rest, "%{")
+		if start < 0 {
+			template.literals = append(template.literals, rest)
+			return template, nil
+		}
+		template.literals = append(template.literals, rest[:start])
+
+		p := &templateParser{text: rest, pos: start + 2, keys: keys}
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, fmt.Errorf("invalid template %q: %w", text, err)
+		}
+		if err = p.expect('}'); err != nil {
+			return nil, fmt.Errorf("invalid template %q: %w", text, err)
+		}
+
+		template.expressions = append(template.expressions, expression)
+		rest = rest[p.pos:]
+	}
+}
+
+// maxCachedTemplates bounds the memory used by the templates compiled from the annotations
+const maxCachedTemplates = 1024
+
+// templateCache keeps the templates compiled from the annotations, which are shared by all the
+// resources of a pod, so they are not parsed again for each of them
+type templateCache struct {
+	keys      sourceKeys
+	logger    *zap.Logger
+	mu        sync.RWMutex
+	templates map[string]*compiledTemplate
+}
+
+func newTemplateCache(keys sourceKeys, logger *zap.Logger) *templateCache {
+	return &templateCache{keys: keys, logger: logger, templates: make(map[string]*compiledTemplate)}
+}
+
+// get returns the compiled template of the annotation value. An invalid template is reported once,
+// when it's first seen, and the value is used literally, as it was before the templates were supported.
+func (c *templateCache) get(annotation string, text string) *compiledTemplate {
+	c.mu.RLock()
+	template, found := c.templates[text]
+	c.mu.RUnlock()
+	if found {
+		return template
+	}
+
+	template, err := parseTemplate(text, c.keys)
+	if err != nil {
+		c.logger.Warn("Invalid template in the annotation, using its value literally",
+			zap.String("annotation", annotation), zap.String("value", text), zap.Error(err))
+		template = &compiledTemplate{text: text, literals: []string{text}}
+	}
+	c.mu.Lock()
+	if len(c.templates) >= maxCachedTemplates {
+		// The annotations changed a lot, start over rather than tracking the usage
+		c.templates = make(map[string]*compiledTemplate)
+	}
+	c.templates[text] = template
+	c.mu.Unlock()
+	return template
+}
+
+// templateParser parses a single placeholder expression, i.e. an attribute name, a string literal
+// or a function call, optionally followed by fallbacks, e.g. `replace(lower(pod_name), "_", "-") || pod`
+type templateParser struct {
+	text string
+	pos  int
+	keys sourceKeys
+}
+
+func (p *templateParser) parseExpression() (templateExpression, error) {
+	var alternatives []templateExpression
+	for {
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, term)
+
+		p.skipSpaces()
+		if !strings.HasPrefix(p.text[p.pos:], "||") {
+			break
+		}
+		p.pos += 2
+	}
+
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return fallbackExpression{alternatives: alternatives}, nil
+}
+
+func (p *templateParser) parseTerm() (templateExpression, error) {
+	p.skipSpaces()
+	if p.pos < len(p.text) && p.text[p.pos] == '"' {
+		value, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literalExpression{value: value}, nil
+	}
+
+	name := p.readIdentifier()
+	if name == "" {
+		return nil, p.errorf("expected attribute name or function")
+	}
+
+	p.skipSpaces()
+	if p.pos >= len(p.text) || p.text[p.pos] != '(' {
+		if variable, ok := p.keys.containerVariable(name); ok {
+			return variable, nil
+		}
+		return attributeExpression{key: p.keys.convertKey(name)}, nil
+	}
+	p.pos++
+
+	compile, ok := templateFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	arg, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	var params []string
+	for {
+		p.skipSpaces()
+		if p.pos < len(p.text) && p.text[p.pos] == ',' {
+			p.pos++
+			param, err := p.parseParam()
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, param)
+			continue
+		}
+		if err = p.expect(')'); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	apply, err := compile(params)
+	if err != nil {
+		return nil, err
+	}
+	return functionExpression{arg: arg, apply: apply}, nil
+}
+
+// parseParam reads a constant function parameter, either a double quoted string or a number
+func (p *templateParser) parseParam() (string, error) {
+	p.skipSpaces()
+	if p.pos < len(p.text) && p.text[p.pos] == '"' {
+		return p.parseString()
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) && (p.text[p.pos] == '-' || unicode.IsDigit(rune(p.text[p.pos]))) {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("expected string or number")
+	}
+	return p.text[start:p.pos], nil
+}
+
+// parseString reads a double quoted string, in which the backslash escapes the next character
+func (p *templateParser) parseString() (string, error) {
+	var value strings.Builder
+	for p.pos++; p.pos < len(p.text); p.pos++ {
+		switch c := p.text[p.pos]; c {
+		case '"':
+			p.pos++
+			return value.String(), nil
+		case '\\':
+			if p.pos+1 < len(p.text) {
+				p.pos++
+				value.WriteByte(p.text[p.pos])
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *templateParser) readIdentifier() string {
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := rune(p.text[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.' && c != '-' {
+			break
+		}
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *templateParser) skipSpaces() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *templateParser) expect(c byte) error {
+	p.skipSpaces()
+	if p.pos >= len(p.text) || p.text[p.pos] != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *templateParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos)
+}
@@ -0,0 +1,205 @@
+// Copyright 2019 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func renderTemplate(t *testing.T, template string, atts pdata.AttributeMap) (string, bool) {
+	keys := sourceKeys{
+		namespaceKey:      "namespace",
+		podIDKey:          "pod_id",
+		podNameKey:        "k8s.pod.pod_name",
+		containerKey:      "container",
+		containerIDKey:    "k8s.container.id",
+		containerImageKey: "k8s.container.image",
+	}
+	compiled, err := parseTemplate(template, keys)
+	require.NoError(t, err)
+	return compiled.render("", atts)
+}
+
+func TestTemplateFunctions(t *testing.T) {
+	atts := pdata.NewAttributeMap()
+	atts.UpsertString("namespace", "Team-Payments")
+	atts.UpsertString("k8s.pod.pod_name", "checkout_api")
+	atts.UpsertString("pod_id", "0123456789abcdef")
+
+	testcases := []struct {
+		template string
+		expected string
+	}{
+		{template: "%{namespace}/%{pod_name}", expected: "Team-Payments/checkout_api"},
+		{template: "%{lower(namespace)}", expected: "team-payments"},
+		{template: "%{upper(pod_name)}", expected: "CHECKOUT_API"},
+		{template: `%{replace(pod_name, "_", "-")}`, expected: "checkout-api"},
+		{template: `%{trim_prefix(lower(namespace), "team-")}/app`, expected: "payments/app"},
+		{template: `%{trim_suffix(pod_name, "_api")}`, expected: "checkout"},
+		{template: "%{substr(pod_id, 0, 8)}", expected: "01234567"},
+		{template: "%{substr(pod_id, 12)}", expected: "cdef"},
+		{template: "%{substr(pod_id, 20, 5)}", expected: ""},
+		{template: `%{ replace( namespace , "\"", "x" ) }`, expected: "Team-Payments"},
+		{template: "100%/%{namespace}", expected: "100%/Team-Payments"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.template, func(t *testing.T) {
+			result, ok := renderTemplate(t, tc.template, atts)
+			assert.True(t, ok)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+
+	_, ok := renderTemplate(t, "%{lower(container)}", atts)
+	assert.False(t, ok)
+}
+
+func TestTemplateContainerVariables(t *testing.T) {
+	testcases := []struct {
+		id        string
+		image     string
+		expected  string
+		available bool
+	}{
+		{
+			id:        "containerd://3f4e5d6c7b8a91827364554637281900aabbccddeeff",
+			image:     "registry.example.com:5000/team/app:1.4.2",
+			expected:  "3f4e5d6c7b8a/registry.example.com:5000/team/app/1.4.2",
+			available: true,
+		},
+		{id: "abc123", image: "nginx", expected: "abc123/nginx/latest", available: true},
+		{id: "abc123", image: "nginx@sha256:0123abcd", expected: "abc123/nginx/", available: true},
+		{id: "abc123", image: "nginx:1.21@sha256:0123abcd", expected: "abc123/nginx/1.21", available: true},
+		{id: "abc123", available: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.expected, func(t *testing.T) {
+			atts := pdata.NewAttributeMap()
+			atts.UpsertString("k8s.container.id", tc.id)
+			if tc.image != "" {
+				atts.UpsertString("k8s.container.image", tc.image)
+			}
+			result, ok := renderTemplate(t, "%{container_id}/%{image_name}/%{image_tag}", atts)
+			assert.Equal(t, tc.available, ok)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestTemplateFallbacks(t *testing.T) {
+	atts := pdata.NewAttributeMap()
+	atts.UpsertString("k8s.statefulset.name", "kafka")
+	atts.UpsertString("k8s.pod.name", "kafka-0")
+	atts.UpsertString("k8s.replicaset.name", "")
+
+	testcases := []struct {
+		template string
+		expected string
+	}{
+		{template: "%{k8s.deployment.name || k8s.statefulset.name || k8s.pod.name}", expected: "kafka"},
+		{template: "%{k8s.deployment.name||k8s.pod.name}", expected: "kafka-0"},
+		{template: "%{k8s.replicaset.name || k8s.pod.name}", expected: "kafka-0"},
+		{template: `%{k8s.deployment.name || "none"}/%{upper(k8s.deployment.name || k8s.statefulset.name)}`, expected: "none/KAFKA"},
+		{template: `%{lower(k8s.deployment.name) || k8s.pod.name}`, expected: "kafka-0"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.template, func(t *testing.T) {
+			result, ok := renderTemplate(t, tc.template, atts)
+			assert.True(t, ok)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+
+	_, ok := renderTemplate(t, "%{k8s.deployment.name || k8s.daemonset.name}", atts)
+	assert.False(t, ok)
+}
+
+func TestInvalidTemplates(t *testing.T) {
+	for _, template := range []string{
+		"%{namespace",
+		"%{}",
+		"%{unknown(namespace)}",
+		"%{lower(namespace, 1)}",
+		`%{replace(namespace, "a")}`,
+		`%{replace(namespace, "a", "b}`,
+		`%{substr(namespace, "a")}`,
+		"%{substr(namespace, -1)}",
+		"%{lower(namespace}",
+		"%{namespace ||}",
+		"%{namespace | pod}",
+		`%{namespace || "unknown}`,
+	} {
+		_, err := parseTemplate(template, sourceKeys{})
+		assert.Error(t, err, template)
+	}
+}
+
+func TestInvalidConfigTemplate(t *testing.T) {
+	config := createConfig()
+	config.SourceCategory = "%{unknown(namespace)}"
+	_, err := newSourceProcessor(zap.NewNop(), config)
+	assert.Error(t, err)
+}
+
+func TestTemplateCache(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	cache := newTemplateCache(sourceKeys{namespaceKey: "namespace"}, zap.New(core))
+
+	first := cache.get("pod_annotation_sumologic.com/sourceCategory", "%{namespace}/app")
+	second := cache.get("pod_annotation_sumologic.com/sourceCategory", "%{namespace}/app")
+	assert.Same(t, first, second)
+	assert.Equal(t, 0, logs.Len())
+
+	// An invalid template is used literally and reported only once
+	invalid := cache.get("pod_annotation_sumologic.com/sourceCategory", "%{unknown(namespace)}")
+	assert.Equal(t, []string{"%{unknown(namespace)}"}, invalid.literals)
+	assert.Empty(t, invalid.expressions)
+	assert.Same(t, invalid, cache.get("pod_annotation_sumologic.com/sourceCategory", "%{unknown(namespace)}"))
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "pod_annotation_sumologic.com/sourceCategory", logs.All()[0].ContextMap()["annotation"])
+
+	for i := 0; i < maxCachedTemplates; i++ {
+		cache.get("pod_annotation_sumologic.com/sourceCategory", fmt.Sprintf("%%{namespace}/%d", i))
+	}
+	assert.LessOrEqual(t, len(cache.templates), maxCachedTemplates)
+}
+
+func BenchmarkRenderTemplate(b *testing.B) {
+	keys := sourceKeys{namespaceKey: "namespace", podNameKey: "pod_name", containerKey: "container"}
+	template, err := parseTemplate(`%{namespace}/%{lower(pod_name)}/%{container || "none"}`, keys)
+	require.NoError(b, err)
+
+	atts := pdata.NewAttributeMap()
+	atts.UpsertString("namespace", "payments")
+	atts.UpsertString("pod_name", "Checkout")
+	atts.UpsertString("container", "app")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		template.render("kubernetes/", atts)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spaneventstologsprocessor
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the span events to logs conversion.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// LogsExporter is the ID of the exporter the log records are sent to, e.g. sumologic/logs
+	LogsExporter string `mapstructure:"logs_exporter"`
+	// EventNames are the names of the span events converted to log records, "*" matching all of them.
+	// When empty, only the exception events are converted.
+	EventNames []string `mapstructure:"event_names"`
+	// SpanAttributes are the attributes of the spans which are copied to the log records
+	SpanAttributes []string `mapstructure:"span_attributes"`
+}
+
+const (
+	allEvents      = "*"
+	exceptionEvent = "exception"
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.LogsExporter == "" {
+		return fmt.Errorf("logs_exporter must not be empty")
+	}
+	if _, err := config.NewIDFromString(cfg.LogsExporter); err != nil {
+		return fmt.Errorf("invalid logs_exporter: %w", err)
+	}
+	for _, name := range cfg.EventNames {
+		if name == "" {
+			return fmt.Errorf("event_names must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spaneventstologsprocessor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+const (
+	// the attributes of the exception events, as in the OpenTelemetry semantic conventions
+	exceptionTypeKey       = "exception.type"
+	exceptionMessageKey    = "exception.message"
+	exceptionStacktraceKey = "exception.stacktrace"
+
+	spanNameKey = "span.name"
+)
+
+// spanEventsToLogsProcessor creates log records from the span events, sends them to the logs exporter
+// and passes the spans through
+type spanEventsToLogsProcessor struct {
+	logger         *zap.Logger
+	nextConsumer   consumer.Traces
+	exporterID     config.ComponentID
+	logsExporter   component.LogsExporter
+	eventNames     map[string]bool
+	allEvents      bool
+	spanAttributes []string
+}
+
+var _ component.TracesProcessor = (*spanEventsToLogsProcessor)(nil)
+
+func newSpanEventsToLogsProcessor(logger *zap.Logger, cfg *Config, nextConsumer consumer.Traces) (*spanEventsToLogsProcessor, error) {
+	exporterID, err := config.NewIDFromString(cfg.LogsExporter)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &spanEventsToLogsProcessor{
+		logger:         logger,
+		nextConsumer:   nextConsumer,
+		exporterID:     exporterID,
+		eventNames:     map[string]bool{},
+		spanAttributes: cfg.SpanAttributes,
+	}
+	eventNames := cfg.EventNames
+	if len(eventNames) == 0 {
+		eventNames = []string{exceptionEvent}
+	}
+	for _, name := range eventNames {
+		if name == allEvents {
+			p.allEvents = true
+		}
+		p.eventNames[name] = true
+	}
+	return p, nil
+}
+
+// Start finds the logs exporter
+func (p *spanEventsToLogsProcessor) Start(_ context.Context, host component.Host) error {
+	exporter, ok := host.GetExporters()[config.LogsDataType][p.exporterID]
+	if !ok {
+		return fmt.Errorf("logs exporter %s not found, it needs to be used in a logs pipeline", p.exporterID)
+	}
+	logsExporter, ok := exporter.(component.LogsExporter)
+	if !ok {
+		return fmt.Errorf("exporter %s is not a logs exporter", p.exporterID)
+	}
+	p.logsExporter = logsExporter
+	return nil
+}
+
+// Shutdown is invoked during service shutdown
+func (p *spanEventsToLogsProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// Capabilities returns the consumer capabilities
+func (p *spanEventsToLogsProcessor) Capabilities() consumer.Capabilities {
+	return processorCapabilities
+}
+
+// ConsumeTraces sends the log records created from the matching span events and passes the spans through.
+// The spans are passed through even if the log records cannot be sent.
+func (p *spanEventsToLogsProcessor) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	ld := p.extractLogs(td)
+	if ld.LogRecordCount() > 0 {
+		if err := p.logsExporter.ConsumeLogs(ctx, ld); err != nil {
+			p.logger.Error("Failed to send the logs created from the span events", zap.Error(err))
+		}
+	}
+	return p.nextConsumer.ConsumeTraces(ctx, td)
+}
+
+// extractLogs creates a log record for each matching span event, keeping the resources of the spans
+func (p *spanEventsToLogsProcessor) extractLogs(td pdata.Traces) pdata.Logs {
+	ld := pdata.NewLogs()
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		var logs pdata.LogSlice
+		hasLogs := false
+		ilss := rss.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				events := span.Events()
+				for l := 0; l < events.Len(); l++ {
+					event := events.At(l)
+					if !p.allEvents && !p.eventNames[event.Name()] {
+						continue
+					}
+					if !hasLogs {
+						rl := ld.ResourceLogs().AppendEmpty()
+						rss.At(i).Resource().CopyTo(rl.Resource())
+						logs = rl.InstrumentationLibraryLogs().AppendEmpty().Logs()
+						hasLogs = true
+					}
+					p.appendLog(logs, span, event)
+				}
+			}
+		}
+	}
+	return ld
+}
+
+func (p *spanEventsToLogsProcessor) appendLog(logs pdata.LogSlice, span pdata.Span, event pdata.SpanEvent) {
+	record := logs.AppendEmpty()
+	record.SetName(event.Name())
+	record.SetTimestamp(event.Timestamp())
+	record.SetTraceID(span.TraceID())
+	record.SetSpanID(span.SpanID())
+
+	attributes := record.Attributes()
+	event.Attributes().CopyTo(attributes)
+	attributes.UpsertString(spanNameKey, span.Name())
+	for _, name := range p.spanAttributes {
+		if value, ok := span.Attributes().Get(name); ok {
+			attributes.Insert(name, value)
+		}
+	}
+
+	if event.Name() != exceptionEvent {
+		record.SetSeverityNumber(pdata.SeverityNumberINFO)
+		record.SetSeverityText("INFO")
+		record.Body().SetStringVal(event.Name())
+		return
+	}
+
+	record.SetSeverityNumber(pdata.SeverityNumberERROR)
+	record.SetSeverityText("ERROR")
+	record.Body().SetStringVal(exceptionBody(event.Attributes()))
+	// the stacktrace is in the body already
+	attributes.Delete(exceptionStacktraceKey)
+}
+
+// exceptionBody formats the exception the way it is usually logged, i.e. type: message followed by the stacktrace
+func exceptionBody(attributes pdata.AttributeMap) string {
+	var parts []string
+	if value, ok := attributes.Get(exceptionTypeKey); ok && value.StringVal() != "" {
+		parts = append(parts, value.StringVal())
+	}
+	if value, ok := attributes.Get(exceptionMessageKey); ok && value.StringVal() != "" {
+		parts = append(parts, value.StringVal())
+	}
+	body := strings.Join(parts, ": ")
+
+	if value, ok := attributes.Get(exceptionStacktraceKey); ok && value.StringVal() != "" {
+		stacktrace := value.StringVal()
+		// the stacktraces usually start with the type and message already
+		if body == "" || strings.HasPrefix(stacktrace, body) {
+			return stacktrace
+		}
+		return body + "\n" + stacktrace
+	}
+	if body == "" {
+		return exceptionEvent
+	}
+	return body
+}
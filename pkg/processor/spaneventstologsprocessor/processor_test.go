@@ -0,0 +1,220 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spaneventstologsprocessor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// testExporter is a logs exporter collecting the logs
+type testExporter struct {
+	consumertest.LogsSink
+	err error
+}
+
+func (e *testExporter) Start(context.Context, component.Host) error { return nil }
+func (e *testExporter) Shutdown(context.Context) error              { return nil }
+
+func (e *testExporter) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.LogsSink.ConsumeLogs(ctx, ld)
+}
+
+// testHost provides the logs exporters
+type testHost struct {
+	component.Host
+	exporters map[config.ComponentID]component.Exporter
+}
+
+func (h testHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return map[config.DataType]map[config.ComponentID]component.Exporter{config.LogsDataType: h.exporters}
+}
+
+var (
+	testTraceID   = pdata.NewTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	testSpanID    = pdata.NewSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	testTimestamp = pdata.TimestampFromTime(time.Unix(1600000000, 0))
+)
+
+func newTestTraces() pdata.Traces {
+	td := pdata.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().InsertString("service.name", "api")
+
+	spans := rs.InstrumentationLibrarySpans().AppendEmpty().Spans()
+	span := spans.AppendEmpty()
+	span.SetName("GET /orders")
+	span.SetTraceID(testTraceID)
+	span.SetSpanID(testSpanID)
+	span.Attributes().InsertString("http.route", "/orders")
+	span.Attributes().InsertString("http.method", "GET")
+
+	exception := span.Events().AppendEmpty()
+	exception.SetName("exception")
+	exception.SetTimestamp(testTimestamp)
+	exception.Attributes().InsertString("exception.type", "java.lang.IllegalStateException")
+	exception.Attributes().InsertString("exception.message", "order not found")
+	exception.Attributes().InsertString("exception.stacktrace",
+		"java.lang.IllegalStateException: order not found\n\tat com.example.Orders.get(Orders.java:42)")
+
+	retry := span.Events().AppendEmpty()
+	retry.SetName("retry")
+	retry.SetTimestamp(testTimestamp)
+	retry.Attributes().InsertInt("attempt", 2)
+
+	// the spans without the events do not create a resource
+	rs = td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().InsertString("service.name", "db")
+	rs.InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty().SetName("SELECT orders")
+	return td
+}
+
+func newTestProcessor(t *testing.T, cfg *Config) (*spanEventsToLogsProcessor, *testExporter, *consumertest.TracesSink) {
+	cfg.LogsExporter = "sumologic/logs"
+	require.NoError(t, cfg.Validate())
+
+	tracesSink := &consumertest.TracesSink{}
+	p, err := newSpanEventsToLogsProcessor(zap.NewNop(), cfg, tracesSink)
+	require.NoError(t, err)
+
+	exporter := &testExporter{}
+	host := testHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.ComponentID]component.Exporter{
+			config.NewIDWithName("sumologic", "logs"): exporter,
+		},
+	}
+	require.NoError(t, p.Start(context.Background(), host))
+	return p, exporter, tracesSink
+}
+
+func TestExceptions(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.SpanAttributes = []string{"http.route"}
+	p, exporter, tracesSink := newTestProcessor(t, cfg)
+
+	require.NoError(t, p.ConsumeTraces(context.Background(), newTestTraces()))
+	assert.Equal(t, 2, tracesSink.SpanCount())
+
+	require.Len(t, exporter.AllLogs(), 1)
+	ld := exporter.AllLogs()[0]
+	require.Equal(t, 1, ld.LogRecordCount())
+
+	rl := ld.ResourceLogs().At(0)
+	service, _ := rl.Resource().Attributes().Get("service.name")
+	assert.Equal(t, "api", service.StringVal())
+
+	record := rl.InstrumentationLibraryLogs().At(0).Logs().At(0)
+	assert.Equal(t, "exception", record.Name())
+	assert.Equal(t, testTimestamp, record.Timestamp())
+	assert.Equal(t, testTraceID, record.TraceID())
+	assert.Equal(t, testSpanID, record.SpanID())
+	assert.Equal(t, pdata.SeverityNumberERROR, record.SeverityNumber())
+	assert.Equal(t, "ERROR", record.SeverityText())
+	assert.Equal(t, "java.lang.IllegalStateException: order not found\n\tat com.example.Orders.get(Orders.java:42)",
+		record.Body().StringVal())
+	assert.Equal(t, map[string]string{
+		"exception.type":    "java.lang.IllegalStateException",
+		"exception.message": "order not found",
+		"span.name":         "GET /orders",
+		"http.route":        "/orders",
+	}, attributesToMap(record.Attributes()))
+}
+
+func TestAllEvents(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EventNames = []string{"*"}
+	p, exporter, _ := newTestProcessor(t, cfg)
+
+	require.NoError(t, p.ConsumeTraces(context.Background(), newTestTraces()))
+	require.Len(t, exporter.AllLogs(), 1)
+	logs := exporter.AllLogs()[0].ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
+	require.Equal(t, 2, logs.Len())
+
+	retry := logs.At(1)
+	assert.Equal(t, "retry", retry.Name())
+	assert.Equal(t, "retry", retry.Body().StringVal())
+	assert.Equal(t, pdata.SeverityNumberINFO, retry.SeverityNumber())
+	assert.Equal(t, map[string]string{"attempt": "2", "span.name": "GET /orders"}, attributesToMap(retry.Attributes()))
+}
+
+func TestExporterError(t *testing.T) {
+	p, exporter, tracesSink := newTestProcessor(t, createDefaultConfig().(*Config))
+	exporter.err = errors.New("failed")
+
+	require.NoError(t, p.ConsumeTraces(context.Background(), newTestTraces()))
+	assert.Equal(t, 2, tracesSink.SpanCount())
+}
+
+func TestExceptionBody(t *testing.T) {
+	testcases := []struct {
+		attributes map[string]string
+		expected   string
+	}{
+		{
+			attributes: map[string]string{"exception.type": "ValueError", "exception.message": "invalid id"},
+			expected:   "ValueError: invalid id",
+		},
+		{
+			attributes: map[string]string{
+				"exception.type":       "ValueError",
+				"exception.stacktrace": "Traceback (most recent call last):\n  File \"app.py\", line 1",
+			},
+			expected: "ValueError\nTraceback (most recent call last):\n  File \"app.py\", line 1",
+		},
+		{attributes: map[string]string{}, expected: "exception"},
+	}
+
+	for _, tc := range testcases {
+		attributes := pdata.NewAttributeMap()
+		for k, v := range tc.attributes {
+			attributes.InsertString(k, v)
+		}
+		assert.Equal(t, tc.expected, exceptionBody(attributes))
+	}
+}
+
+func TestMissingExporter(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LogsExporter = "sumologic/logs"
+	p, err := newSpanEventsToLogsProcessor(zap.NewNop(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	host := testHost{Host: componenttest.NewNopHost(), exporters: map[config.ComponentID]component.Exporter{}}
+	assert.Error(t, p.Start(context.Background(), host))
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
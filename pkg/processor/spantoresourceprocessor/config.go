@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spantoresourceprocessor
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// The strategies of choosing the promoted value among the values of the spans.
+const (
+	// strategyFirst promotes the value of the first span having the attribute
+	strategyFirst = "first"
+	// strategyConsensus promotes the value only if all the spans having the attribute agree on it
+	strategyConsensus = "consensus"
+)
+
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Attributes are the span attributes promoted to the resource
+	Attributes []AttributeConfig `mapstructure:"attributes"`
+	// Strategy is the strategy of the attributes which don't set their own one
+	Strategy string `mapstructure:"strategy"`
+	// Overwrite replaces the resource attributes which are already set
+	Overwrite bool `mapstructure:"overwrite"`
+	// RemoveFromSpans removes the promoted attributes from the spans having the promoted value
+	RemoveFromSpans bool `mapstructure:"remove_from_spans"`
+}
+
+// AttributeConfig describes a span attribute promoted to the resource
+type AttributeConfig struct {
+	// Key is the name of the span attribute
+	Key string `mapstructure:"key"`
+	// ResourceKey is the name of the resource attribute, the same as Key when empty
+	ResourceKey string `mapstructure:"resource_key"`
+	// Strategy overrides the strategy of the processor for the attribute
+	Strategy string `mapstructure:"strategy"`
+}
+
+const defaultStrategy = strategyFirst
+
+func (cfg *Config) Validate() error {
+	if len(cfg.Attributes) == 0 {
+		return errors.New("at least one attribute must be set")
+	}
+	if err := validateStrategy(cfg.Strategy); err != nil {
+		return err
+	}
+
+	resourceKeys := map[string]bool{}
+	for i, attr := range cfg.Attributes {
+		if attr.Key == "" {
+			return fmt.Errorf("attributes[%d]: key must be set", i)
+		}
+		if attr.Strategy != "" {
+			if err := validateStrategy(attr.Strategy); err != nil {
+				return fmt.Errorf("attributes[%d]: %w", i, err)
+			}
+		}
+		resourceKey := attr.resourceKey()
+		if resourceKeys[resourceKey] {
+			return fmt.Errorf("attributes[%d]: resource attribute %q is already promoted", i, resourceKey)
+		}
+		resourceKeys[resourceKey] = true
+	}
+	return nil
+}
+
+func validateStrategy(strategy string) error {
+	switch strategy {
+	case strategyFirst, strategyConsensus:
+		return nil
+	default:
+		return fmt.Errorf("strategy must be one of %q or %q, got %q", strategyFirst, strategyConsensus, strategy)
+	}
+}
+
+func (attr AttributeConfig) resourceKey() string {
+	if attr.ResourceKey == "" {
+		return attr.Key
+	}
+	return attr.ResourceKey
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spantoresourceprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "span_to_resource_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			Attributes:        []AttributeConfig{{Key: "tenant.id"}},
+			Strategy:          strategyFirst,
+		})
+	assert.Equal(t, cfg.Processors[config.NewIDWithName(typeStr, "custom")],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewIDWithName(typeStr, "custom")),
+			Attributes: []AttributeConfig{
+				{Key: "tenant.id", ResourceKey: "tenant"},
+				{Key: "deployment.environment", Strategy: strategyFirst},
+			},
+			Strategy:        strategyConsensus,
+			Overwrite:       true,
+			RemoveFromSpans: true,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "valid",
+			modify: func(cfg *Config) {
+				cfg.Attributes = []AttributeConfig{
+					{Key: "tenant.id", Strategy: strategyConsensus},
+					{Key: "tenant.id", ResourceKey: "tenant"},
+				}
+			},
+		},
+		{
+			name:    "no attributes",
+			modify:  func(cfg *Config) {},
+			wantErr: "at least one attribute must be set",
+		},
+		{
+			name: "invalid strategy",
+			modify: func(cfg *Config) {
+				cfg.Attributes = []AttributeConfig{{Key: "tenant.id"}}
+				cfg.Strategy = "last"
+			},
+			wantErr: `strategy must be one of "first" or "consensus", got "last"`,
+		},
+		{
+			name: "no key",
+			modify: func(cfg *Config) {
+				cfg.Attributes = []AttributeConfig{{ResourceKey: "tenant"}}
+			},
+			wantErr: "attributes[0]: key must be set",
+		},
+		{
+			name: "invalid attribute strategy",
+			modify: func(cfg *Config) {
+				cfg.Attributes = []AttributeConfig{{Key: "tenant.id"}, {Key: "env", Strategy: "majority"}}
+			},
+			wantErr: `attributes[1]: strategy must be one of "first" or "consensus", got "majority"`,
+		},
+		{
+			name: "duplicate resource key",
+			modify: func(cfg *Config) {
+				cfg.Attributes = []AttributeConfig{{Key: "tenant"}, {Key: "tenant.id", ResourceKey: "tenant"}}
+			},
+			wantErr: `attributes[1]: resource attribute "tenant" is already promoted`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
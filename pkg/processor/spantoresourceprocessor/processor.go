@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spantoresourceprocessor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// spanToResourceProcessor promotes the span attributes to the resource attributes
+type spanToResourceProcessor struct {
+	attributes      []promotedAttribute
+	overwrite       bool
+	removeFromSpans bool
+}
+
+type promotedAttribute struct {
+	key         string
+	resourceKey string
+	consensus   bool
+}
+
+func newSpanToResourceProcessor(cfg *Config) (*spanToResourceProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	attributes := make([]promotedAttribute, 0, len(cfg.Attributes))
+	for _, attr := range cfg.Attributes {
+		strategy := attr.Strategy
+		if strategy == "" {
+			strategy = cfg.Strategy
+		}
+		attributes = append(attributes, promotedAttribute{
+			key:         attr.Key,
+			resourceKey: attr.resourceKey(),
+			consensus:   strategy == strategyConsensus,
+		})
+	}
+
+	return &spanToResourceProcessor{
+		attributes:      attributes,
+		overwrite:       cfg.Overwrite,
+		removeFromSpans: cfg.RemoveFromSpans,
+	}, nil
+}
+
+func (stp *spanToResourceProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		for _, attr := range stp.attributes {
+			stp.promote(rs, attr)
+		}
+	}
+	return td, nil
+}
+
+// promote sets the resource attribute to the value chosen among the spans of the resource
+func (stp *spanToResourceProcessor) promote(rs pdata.ResourceSpans, attr promotedAttribute) {
+	resourceAttrs := rs.Resource().Attributes()
+	if _, ok := resourceAttrs.Get(attr.resourceKey); ok && !stp.overwrite {
+		return
+	}
+
+	value, ok := chooseValue(rs, attr)
+	if !ok {
+		return
+	}
+	resourceAttrs.Upsert(attr.resourceKey, value)
+
+	if stp.removeFromSpans {
+		// The value was copied to the resource, as the one found may be deleted from its span
+		promoted, _ := resourceAttrs.Get(attr.resourceKey)
+		forEachSpan(rs, func(span pdata.Span) bool {
+			if spanValue, ok := span.Attributes().Get(attr.key); ok && spanValue.Equal(promoted) {
+				span.Attributes().Delete(attr.key)
+			}
+			return true
+		})
+	}
+}
+
+// chooseValue returns the value of the attribute according to the strategy,
+// or false if no span has the attribute or the spans don't agree on its value
+func chooseValue(rs pdata.ResourceSpans, attr promotedAttribute) (pdata.AttributeValue, bool) {
+	var (
+		value    pdata.AttributeValue
+		found    bool
+		conflict bool
+	)
+	forEachSpan(rs, func(span pdata.Span) bool {
+		spanValue, ok := span.Attributes().Get(attr.key)
+		switch {
+		case !ok:
+			return true
+		case !found:
+			value, found = spanValue, true
+			return attr.consensus
+		case !spanValue.Equal(value):
+			conflict = true
+			return false
+		default:
+			return true
+		}
+	})
+	return value, found && !conflict
+}
+
+// forEachSpan calls fn for the spans of the resource until it returns false
+func forEachSpan(rs pdata.ResourceSpans, fn func(span pdata.Span) bool) {
+	ilss := rs.InstrumentationLibrarySpans()
+	for i := 0; i < ilss.Len(); i++ {
+		spans := ilss.At(i).Spans()
+		for j := 0; j < spans.Len(); j++ {
+			if !fn(spans.At(j)) {
+				return
+			}
+		}
+	}
+}
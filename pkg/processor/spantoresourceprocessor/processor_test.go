@@ -0,0 +1,235 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spantoresourceprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func attributesToMap(attrs pdata.AttributeMap) map[string]string {
+	m := map[string]string{}
+	attrs.Range(func(k string, v pdata.AttributeValue) bool {
+		m[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return m
+}
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) *spanToResourceProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Attributes = []AttributeConfig{{Key: "tenant.id"}}
+	if modify != nil {
+		modify(cfg)
+	}
+	stp, err := newSpanToResourceProcessor(cfg)
+	require.NoError(t, err)
+	return stp
+}
+
+// newTestTraces creates the traces of a single resource, with a span for each of the attribute maps,
+// the first half of them in one instrumentation library and the rest in another
+func newTestTraces(resourceAttrs map[string]string, spanAttrs ...map[string]string) pdata.Traces {
+	td := pdata.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	for k, v := range resourceAttrs {
+		rs.Resource().Attributes().InsertString(k, v)
+	}
+	ilss := rs.InstrumentationLibrarySpans()
+	ilss.AppendEmpty()
+	ilss.AppendEmpty()
+	for i, attrs := range spanAttrs {
+		span := ilss.At(2 * i / len(spanAttrs)).Spans().AppendEmpty()
+		for k, v := range attrs {
+			span.Attributes().InsertString(k, v)
+		}
+	}
+	return td
+}
+
+func spanAttributes(td pdata.Traces) []map[string]string {
+	var result []map[string]string
+	forEachSpan(td.ResourceSpans().At(0), func(span pdata.Span) bool {
+		result = append(result, attributesToMap(span.Attributes()))
+		return true
+	})
+	return result
+}
+
+func TestPromote(t *testing.T) {
+	testcases := []struct {
+		name             string
+		modify           func(cfg *Config)
+		resource         map[string]string
+		spans            []map[string]string
+		expectedResource map[string]string
+		expectedSpans    []map[string]string
+	}{
+		{
+			name:     "first value",
+			resource: map[string]string{"service.name": "checkout"},
+			spans: []map[string]string{
+				{"http.method": "GET"},
+				{"tenant.id": "acme"},
+				{"tenant.id": "globex"},
+			},
+			expectedResource: map[string]string{"service.name": "checkout", "tenant.id": "acme"},
+		},
+		{
+			name: "consensus",
+			modify: func(cfg *Config) {
+				cfg.Strategy = strategyConsensus
+			},
+			spans: []map[string]string{
+				{"tenant.id": "acme"},
+				{"http.method": "GET"},
+				{"tenant.id": "acme"},
+			},
+			expectedResource: map[string]string{"tenant.id": "acme"},
+		},
+		{
+			name: "no consensus",
+			modify: func(cfg *Config) {
+				cfg.Strategy = strategyConsensus
+			},
+			spans: []map[string]string{
+				{"tenant.id": "acme"},
+				{"tenant.id": "acme"},
+				{"tenant.id": "globex"},
+			},
+			expectedResource: map[string]string{},
+		},
+		{
+			name: "attribute strategy and resource key",
+			modify: func(cfg *Config) {
+				cfg.Strategy = strategyConsensus
+				cfg.Attributes = []AttributeConfig{
+					{Key: "tenant.id", ResourceKey: "tenant"},
+					{Key: "deployment.environment", Strategy: strategyFirst},
+				}
+			},
+			spans: []map[string]string{
+				{"tenant.id": "acme", "deployment.environment": "prod"},
+				{"tenant.id": "globex", "deployment.environment": "staging"},
+			},
+			expectedResource: map[string]string{"deployment.environment": "prod"},
+		},
+		{
+			name: "no spans with the attribute",
+			spans: []map[string]string{
+				{"http.method": "GET"},
+			},
+			expectedResource: map[string]string{},
+		},
+		{
+			name:             "resource attribute kept",
+			resource:         map[string]string{"tenant.id": "initech"},
+			spans:            []map[string]string{{"tenant.id": "acme"}},
+			expectedResource: map[string]string{"tenant.id": "initech"},
+		},
+		{
+			name: "resource attribute overwritten",
+			modify: func(cfg *Config) {
+				cfg.Overwrite = true
+			},
+			resource:         map[string]string{"tenant.id": "initech"},
+			spans:            []map[string]string{{"tenant.id": "acme"}},
+			expectedResource: map[string]string{"tenant.id": "acme"},
+		},
+		{
+			name: "removed from spans with the promoted value",
+			modify: func(cfg *Config) {
+				cfg.RemoveFromSpans = true
+				cfg.Attributes = []AttributeConfig{{Key: "tenant.id", ResourceKey: "tenant"}}
+			},
+			spans: []map[string]string{
+				{"tenant.id": "globex", "http.method": "GET"},
+				{"tenant.id": "acme"},
+				{"tenant.id": "globex"},
+			},
+			expectedResource: map[string]string{"tenant": "globex"},
+			expectedSpans: []map[string]string{
+				{"http.method": "GET"},
+				{"tenant.id": "acme"},
+				{},
+			},
+		},
+		{
+			name: "not removed when not promoted",
+			modify: func(cfg *Config) {
+				cfg.RemoveFromSpans = true
+			},
+			resource:         map[string]string{"tenant.id": "initech"},
+			spans:            []map[string]string{{"tenant.id": "initech"}},
+			expectedResource: map[string]string{"tenant.id": "initech"},
+			expectedSpans:    []map[string]string{{"tenant.id": "initech"}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stp := newTestProcessor(t, tc.modify)
+			td, err := stp.ProcessTraces(context.Background(), newTestTraces(tc.resource, tc.spans...))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedResource, attributesToMap(td.ResourceSpans().At(0).Resource().Attributes()))
+			expectedSpans := tc.expectedSpans
+			if expectedSpans == nil {
+				expectedSpans = tc.spans
+			}
+			assert.Equal(t, expectedSpans, spanAttributes(td))
+		})
+	}
+}
+
+func TestPromotePerResource(t *testing.T) {
+	stp := newTestProcessor(t, nil)
+
+	td := pdata.NewTraces()
+	newTestTraces(nil, map[string]string{"tenant.id": "acme"}).ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+	newTestTraces(nil, map[string]string{"tenant.id": "globex"}).ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+
+	td, err := stp.ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+	require.Equal(t, 2, td.ResourceSpans().Len())
+	assert.Equal(t, map[string]string{"tenant.id": "acme"},
+		attributesToMap(td.ResourceSpans().At(0).Resource().Attributes()))
+	assert.Equal(t, map[string]string{"tenant.id": "globex"},
+		attributesToMap(td.ResourceSpans().At(1).Resource().Attributes()))
+}
+
+func TestPromoteNonStringValue(t *testing.T) {
+	stp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Strategy = strategyConsensus
+		cfg.RemoveFromSpans = true
+		cfg.Attributes = []AttributeConfig{{Key: "http.status_code"}}
+	})
+
+	td := newTestTraces(nil, nil, nil)
+	spans := td.ResourceSpans().At(0).InstrumentationLibrarySpans()
+	spans.At(0).Spans().At(0).Attributes().InsertInt("http.status_code", 200)
+	spans.At(1).Spans().At(0).Attributes().InsertInt("http.status_code", 200)
+
+	td, err := stp.ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+	value, ok := td.ResourceSpans().At(0).Resource().Attributes().Get("http.status_code")
+	require.True(t, ok)
+	assert.Equal(t, pdata.NewAttributeValueInt(200), value)
+	assert.Equal(t, []map[string]string{{}, {}}, spanAttributes(td))
+}
@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stalenessprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the staleness markers.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Metrics are the regular expressions matching the names of the tracked metrics, all of them are tracked when empty
+	Metrics []string `mapstructure:"metrics"`
+	// StaleAfter is the time after which a series which stopped reporting is considered stale
+	StaleAfter time.Duration `mapstructure:"stale_after"`
+	// CheckInterval is the period in which the series are checked for staleness
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// Marker is the value emitted for a stale series: nan or zero
+	Marker string `mapstructure:"marker"`
+	// MaxSeries is the maximum number of the tracked series, the new ones above it are not tracked
+	MaxSeries int `mapstructure:"max_series"`
+}
+
+const (
+	// markerNaN is the staleness marker of Prometheus, a NaN value with a special bit pattern
+	markerNaN = "nan"
+	// markerZero is the zero value
+	markerZero = "zero"
+)
+
+const (
+	defaultStaleAfter    = 5 * time.Minute
+	defaultCheckInterval = 30 * time.Second
+	defaultMarker        = markerNaN
+	defaultMaxSeries     = 100000
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	for _, metric := range cfg.Metrics {
+		if _, err := regexp.Compile(metric); err != nil {
+			return fmt.Errorf("invalid metrics regex %q: %w", metric, err)
+		}
+	}
+	if cfg.StaleAfter <= 0 {
+		return fmt.Errorf("stale_after must be positive, got %s", cfg.StaleAfter)
+	}
+	if cfg.CheckInterval <= 0 {
+		return fmt.Errorf("check_interval must be positive, got %s", cfg.CheckInterval)
+	}
+	if cfg.Marker != markerNaN && cfg.Marker != markerZero {
+		return fmt.Errorf("marker must be one of %q or %q, got %q", markerNaN, markerZero, cfg.Marker)
+	}
+	if cfg.MaxSeries <= 0 {
+		return fmt.Errorf("max_series must be positive, got %d", cfg.MaxSeries)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stalenessprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "staleness_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)], factory.CreateDefaultConfig())
+	assert.Equal(t, cfg.Processors[config.NewIDWithName(typeStr, "custom")],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewIDWithName(typeStr, "custom")),
+			Metrics:           []string{"^kube_", "^container_"},
+			StaleAfter:        2 * time.Minute,
+			CheckInterval:     15 * time.Second,
+			Marker:            markerZero,
+			MaxSeries:         5000,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.NoError(t, createDefaultConfig().(*Config).Validate())
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Metrics = []string{"cpu("}
+	assert.Error(t, cfg.Validate())
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.StaleAfter = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.CheckInterval = -time.Second
+	assert.Error(t, cfg.Validate())
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.Marker = "last"
+	assert.EqualError(t, cfg.Validate(), `marker must be one of "nan" or "zero", got "last"`)
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.MaxSeries = 0
+	assert.Error(t, cfg.Validate())
+}
@@ -0,0 +1,348 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stalenessprocessor
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/attrhash"
+)
+
+// staleNaN is the value Prometheus uses to mark the stale series
+var staleNaN = math.Float64frombits(0x7ff0000000000002)
+
+// stalenessProcessor tracks the series of the gauges and sums passing through and emits a marker
+// for each series which stopped reporting
+type stalenessProcessor struct {
+	logger        *zap.Logger
+	nextConsumer  consumer.Metrics
+	metrics       []*regexp.Regexp
+	staleAfter    time.Duration
+	checkInterval time.Duration
+	zeroMarker    bool
+	maxSeries     int
+	now           func() time.Time
+
+	mu     sync.Mutex
+	series map[uint64]*series
+	// limitLogged is set once the series limit has been reported, until the number of the series drops
+	limitLogged bool
+
+	started bool
+	stopCh  chan struct{}
+	done    chan struct{}
+}
+
+// series is a tracked series with everything needed to emit its marker
+type series struct {
+	resourceKey uint64
+	resource    pdata.AttributeMap
+	library     pdata.InstrumentationLibrary
+
+	name        string
+	description string
+	unit        string
+	dataType    pdata.MetricDataType
+	temporality pdata.AggregationTemporality
+	monotonic   bool
+
+	attributes     pdata.AttributeMap
+	valueType      pdata.MetricValueType
+	startTimestamp pdata.Timestamp
+	lastSeen       time.Time
+}
+
+var _ component.MetricsProcessor = (*stalenessProcessor)(nil)
+
+func newStalenessProcessor(logger *zap.Logger, cfg *Config, nextConsumer consumer.Metrics) (*stalenessProcessor, error) {
+	p := &stalenessProcessor{
+		logger:        logger,
+		nextConsumer:  nextConsumer,
+		staleAfter:    cfg.StaleAfter,
+		checkInterval: cfg.CheckInterval,
+		zeroMarker:    cfg.Marker == markerZero,
+		maxSeries:     cfg.MaxSeries,
+		now:           time.Now,
+		series:        map[uint64]*series{},
+		stopCh:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, metric := range cfg.Metrics {
+		regex, err := regexp.Compile(metric)
+		if err != nil {
+			return nil, err
+		}
+		p.metrics = append(p.metrics, regex)
+	}
+	return p, nil
+}
+
+// Start starts checking the series for staleness
+func (p *stalenessProcessor) Start(context.Context, component.Host) error {
+	p.started = true
+	go p.checkLoop()
+	return nil
+}
+
+// Shutdown stops checking the series, no markers are emitted for the series which are still tracked
+func (p *stalenessProcessor) Shutdown(ctx context.Context) error {
+	if !p.started {
+		return nil
+	}
+	close(p.stopCh)
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Capabilities returns the consumer capabilities
+func (p *stalenessProcessor) Capabilities() consumer.Capabilities {
+	return processorCapabilities
+}
+
+// ConsumeMetrics records the series of the matching gauges and sums and passes the metrics through
+func (p *stalenessProcessor) ConsumeMetrics(ctx context.Context, md pdata.Metrics) error {
+	now := p.now()
+
+	p.mu.Lock()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resourceKey := attrhash.Attributes(fnv.New64a(), rm.Resource().Attributes())
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			ilm := ilms.At(j)
+			metrics := ilm.Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				if metric := metrics.At(k); p.match(metric.Name()) {
+					p.record(rm.Resource(), resourceKey, ilm.InstrumentationLibrary(), metric, now)
+				}
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	return p.nextConsumer.ConsumeMetrics(ctx, md)
+}
+
+func (p *stalenessProcessor) match(name string) bool {
+	if len(p.metrics) == 0 {
+		return true
+	}
+	for _, regex := range p.metrics {
+		if regex.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// record updates the last time the series of the data points have been seen,
+// the metrics other than the gauges and sums are not tracked
+func (p *stalenessProcessor) record(
+	resource pdata.Resource,
+	resourceKey uint64,
+	library pdata.InstrumentationLibrary,
+	metric pdata.Metric,
+	now time.Time,
+) {
+	var dps pdata.NumberDataPointSlice
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps = metric.Gauge().DataPoints()
+	case pdata.MetricDataTypeSum:
+		dps = metric.Sum().DataPoints()
+	default:
+		return
+	}
+
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		h := fnv.New64a()
+		h.Write([]byte(metric.Name()))
+		h.Write([]byte{0})
+		h.Write([]byte(library.Name()))
+		h.Write([]byte{0})
+		h.Write([]byte(library.Version()))
+		h.Write([]byte{0})
+		attrhash.WriteUint64(h, resourceKey)
+		key := attrhash.Attributes(h, dp.Attributes())
+
+		if s, ok := p.series[key]; ok {
+			s.valueType = dp.Type()
+			s.startTimestamp = dp.StartTimestamp()
+			s.lastSeen = now
+			continue
+		}
+
+		if len(p.series) >= p.maxSeries {
+			if !p.limitLogged {
+				p.logger.Warn("Maximum number of the tracked series reached, the new series are not tracked",
+					zap.Int("max_series", p.maxSeries))
+				p.limitLogged = true
+			}
+			continue
+		}
+
+		s := &series{
+			resourceKey:    resourceKey,
+			resource:       pdata.NewAttributeMap(),
+			library:        pdata.NewInstrumentationLibrary(),
+			name:           metric.Name(),
+			description:    metric.Description(),
+			unit:           metric.Unit(),
+			dataType:       metric.DataType(),
+			attributes:     pdata.NewAttributeMap(),
+			valueType:      dp.Type(),
+			startTimestamp: dp.StartTimestamp(),
+			lastSeen:       now,
+		}
+		resource.Attributes().CopyTo(s.resource)
+		library.CopyTo(s.library)
+		dp.Attributes().CopyTo(s.attributes)
+		if metric.DataType() == pdata.MetricDataTypeSum {
+			s.temporality = metric.Sum().AggregationTemporality()
+			s.monotonic = metric.Sum().IsMonotonic()
+		}
+		p.series[key] = s
+	}
+}
+
+func (p *stalenessProcessor) checkLoop() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.check(context.Background())
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+// check emits the markers of the series which haven't been seen for longer than stale_after
+// and stops tracking them
+func (p *stalenessProcessor) check(ctx context.Context) {
+	now := p.now()
+
+	p.mu.Lock()
+	var stale []*series
+	for key, s := range p.series {
+		if now.Sub(s.lastSeen) >= p.staleAfter {
+			stale = append(stale, s)
+			delete(p.series, key)
+		}
+	}
+	if len(p.series) < p.maxSeries {
+		p.limitLogged = false
+	}
+	p.mu.Unlock()
+
+	if len(stale) == 0 {
+		return
+	}
+
+	if err := p.nextConsumer.ConsumeMetrics(ctx, p.markers(stale, pdata.TimestampFromTime(now))); err != nil {
+		p.logger.Error("Failed to send the staleness markers", zap.Error(err))
+	}
+}
+
+// markers creates a data point with the marker for each of the series
+func (p *stalenessProcessor) markers(stale []*series, timestamp pdata.Timestamp) pdata.Metrics {
+	// The series are sorted to keep the order of the resources and the metrics stable
+	sort.Slice(stale, func(i, j int) bool {
+		if stale[i].resourceKey != stale[j].resourceKey {
+			return stale[i].resourceKey < stale[j].resourceKey
+		}
+		return stale[i].name < stale[j].name
+	})
+
+	md := pdata.NewMetrics()
+	resources := map[uint64]pdata.InstrumentationLibraryMetricsSlice{}
+	for _, s := range stale {
+		ilms, ok := resources[s.resourceKey]
+		if !ok {
+			rm := md.ResourceMetrics().AppendEmpty()
+			s.resource.CopyTo(rm.Resource().Attributes())
+			ilms = rm.InstrumentationLibraryMetrics()
+			resources[s.resourceKey] = ilms
+		}
+		p.appendMarker(libraryMetrics(ilms, s.library), s, timestamp)
+	}
+	return md
+}
+
+// libraryMetrics returns the metrics of the instrumentation library, adding it if missing
+func libraryMetrics(ilms pdata.InstrumentationLibraryMetricsSlice, library pdata.InstrumentationLibrary) pdata.MetricSlice {
+	for i := 0; i < ilms.Len(); i++ {
+		ilm := ilms.At(i)
+		if ilm.InstrumentationLibrary().Name() == library.Name() &&
+			ilm.InstrumentationLibrary().Version() == library.Version() {
+			return ilm.Metrics()
+		}
+	}
+	ilm := ilms.AppendEmpty()
+	library.CopyTo(ilm.InstrumentationLibrary())
+	return ilm.Metrics()
+}
+
+func (p *stalenessProcessor) appendMarker(metrics pdata.MetricSlice, s *series, timestamp pdata.Timestamp) {
+	metric := metrics.AppendEmpty()
+	metric.SetName(s.name)
+	metric.SetDescription(s.description)
+	metric.SetUnit(s.unit)
+	metric.SetDataType(s.dataType)
+
+	var dp pdata.NumberDataPoint
+	if s.dataType == pdata.MetricDataTypeGauge {
+		dp = metric.Gauge().DataPoints().AppendEmpty()
+	} else {
+		metric.Sum().SetAggregationTemporality(s.temporality)
+		metric.Sum().SetIsMonotonic(s.monotonic)
+		dp = metric.Sum().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(s.startTimestamp)
+		if p.zeroMarker && s.temporality == pdata.AggregationTemporalityCumulative {
+			// The zero of a cumulative sum is a reset
+			dp.SetStartTimestamp(timestamp)
+		}
+	}
+	s.attributes.CopyTo(dp.Attributes())
+	dp.SetTimestamp(timestamp)
+
+	switch {
+	case !p.zeroMarker:
+		dp.SetDoubleVal(staleNaN)
+	case s.valueType == pdata.MetricValueTypeInt:
+		dp.SetIntVal(0)
+	default:
+		dp.SetDoubleVal(0)
+	}
+}
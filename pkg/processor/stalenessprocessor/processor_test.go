@@ -0,0 +1,209 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stalenessprocessor
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+var testStart = time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestMetrics creates a metric with a data point for each of the pods, each in its own resource
+func newTestMetrics(name string, dataType pdata.MetricDataType, pods ...string) pdata.Metrics {
+	md := pdata.NewMetrics()
+	for _, pod := range pods {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().InsertString("k8s.pod.name", pod)
+		ilm := rm.InstrumentationLibraryMetrics().AppendEmpty()
+		ilm.InstrumentationLibrary().SetName("kubeletstats")
+		metric := ilm.Metrics().AppendEmpty()
+		metric.SetName(name)
+		metric.SetUnit("1")
+		metric.SetDataType(dataType)
+
+		var dp pdata.NumberDataPoint
+		switch dataType {
+		case pdata.MetricDataTypeGauge:
+			dp = metric.Gauge().DataPoints().AppendEmpty()
+			dp.SetDoubleVal(0.5)
+		case pdata.MetricDataTypeSum:
+			metric.Sum().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+			metric.Sum().SetIsMonotonic(true)
+			dp = metric.Sum().DataPoints().AppendEmpty()
+			dp.SetStartTimestamp(pdata.TimestampFromTime(testStart))
+			dp.SetIntVal(100)
+		case pdata.MetricDataTypeHistogram:
+			metric.Histogram().DataPoints().AppendEmpty().SetCount(1)
+			continue
+		}
+		dp.SetTimestamp(pdata.TimestampFromTime(testStart))
+		dp.Attributes().InsertString("container", "app")
+	}
+	return md
+}
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) (*stalenessProcessor, *consumertest.MetricsSink, *time.Time) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StaleAfter = time.Minute
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	sink := &consumertest.MetricsSink{}
+	p, err := newStalenessProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	now := testStart
+	p.now = func() time.Time { return now }
+	return p, sink, &now
+}
+
+// markerPoints returns the marker data points of the metrics emitted by the check, by the pod
+func markerPoints(t *testing.T, md pdata.Metrics) map[string]pdata.NumberDataPoint {
+	result := map[string]pdata.NumberDataPoint{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		pod, ok := rms.At(i).Resource().Attributes().Get("k8s.pod.name")
+		require.True(t, ok)
+		ilms := rms.At(i).InstrumentationLibraryMetrics()
+		require.Equal(t, 1, ilms.Len())
+		assert.Equal(t, "kubeletstats", ilms.At(0).InstrumentationLibrary().Name())
+		metrics := ilms.At(0).Metrics()
+		require.Equal(t, 1, metrics.Len())
+
+		var dp pdata.NumberDataPoint
+		switch metrics.At(0).DataType() {
+		case pdata.MetricDataTypeGauge:
+			dp = metrics.At(0).Gauge().DataPoints().At(0)
+		case pdata.MetricDataTypeSum:
+			dp = metrics.At(0).Sum().DataPoints().At(0)
+		}
+		container, _ := dp.Attributes().Get("container")
+		assert.Equal(t, "app", container.StringVal())
+		result[pod.StringVal()] = dp
+	}
+	return result
+}
+
+func TestStaleSeriesMarked(t *testing.T) {
+	p, sink, now := newTestProcessor(t, nil)
+
+	md := newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, "app-1", "app-2")
+	require.NoError(t, p.ConsumeMetrics(context.Background(), md))
+	require.Len(t, sink.AllMetrics(), 1, "the metrics are passed through")
+
+	*now = now.Add(30 * time.Second)
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, "app-1")))
+	p.check(context.Background())
+	require.Len(t, sink.AllMetrics(), 2, "no series is stale yet")
+
+	*now = now.Add(30 * time.Second)
+	p.check(context.Background())
+	require.Len(t, sink.AllMetrics(), 3)
+	markers := markerPoints(t, sink.AllMetrics()[2])
+	require.Len(t, markers, 1)
+	assert.True(t, math.IsNaN(markers["app-2"].DoubleVal()))
+	assert.Equal(t, math.Float64bits(staleNaN), math.Float64bits(markers["app-2"].DoubleVal()))
+	assert.Equal(t, pdata.TimestampFromTime(*now), markers["app-2"].Timestamp())
+
+	// The marker is emitted only once
+	p.check(context.Background())
+	require.Len(t, sink.AllMetrics(), 3)
+
+	*now = now.Add(30 * time.Second)
+	p.check(context.Background())
+	require.Len(t, sink.AllMetrics(), 4)
+	assert.Contains(t, markerPoints(t, sink.AllMetrics()[3]), "app-1")
+}
+
+func TestZeroMarker(t *testing.T) {
+	p, sink, now := newTestProcessor(t, func(cfg *Config) {
+		cfg.Marker = markerZero
+	})
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, "gauge")))
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("requests_total", pdata.MetricDataTypeSum, "sum")))
+	*now = now.Add(time.Minute)
+	p.check(context.Background())
+
+	require.Len(t, sink.AllMetrics(), 3)
+	markers := markerPoints(t, sink.AllMetrics()[2])
+	require.Len(t, markers, 2)
+
+	assert.Equal(t, pdata.MetricValueTypeDouble, markers["gauge"].Type())
+	assert.Equal(t, 0.0, markers["gauge"].DoubleVal())
+
+	assert.Equal(t, pdata.MetricValueTypeInt, markers["sum"].Type())
+	assert.Equal(t, int64(0), markers["sum"].IntVal())
+	assert.Equal(t, pdata.TimestampFromTime(*now), markers["sum"].StartTimestamp(),
+		"the zero of a cumulative sum is a reset")
+}
+
+func TestUntrackedMetrics(t *testing.T) {
+	p, sink, now := newTestProcessor(t, func(cfg *Config) {
+		cfg.Metrics = []string{"^kube_"}
+	})
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, "app")))
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("kube_latency", pdata.MetricDataTypeHistogram, "app")))
+	assert.Empty(t, p.series)
+
+	*now = now.Add(time.Hour)
+	p.check(context.Background())
+	assert.Len(t, sink.AllMetrics(), 2)
+}
+
+func TestMaxSeries(t *testing.T) {
+	p, sink, now := newTestProcessor(t, func(cfg *Config) {
+		cfg.MaxSeries = 2
+	})
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(),
+		newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, "app-1", "app-2", "app-3")))
+	assert.Len(t, p.series, 2)
+
+	*now = now.Add(time.Minute)
+	p.check(context.Background())
+	require.Len(t, sink.AllMetrics(), 2)
+	markers := markerPoints(t, sink.AllMetrics()[1])
+	assert.Len(t, markers, 2)
+	assert.NotContains(t, markers, "app-3")
+}
+
+func TestPeriodicCheck(t *testing.T) {
+	p, sink, _ := newTestProcessor(t, nil)
+	p.now = time.Now
+	p.staleAfter = 10 * time.Millisecond
+	p.checkInterval = 5 * time.Millisecond
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, p.Shutdown(context.Background()))
+	}()
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics("cpu_usage", pdata.MetricDataTypeGauge, "app")))
+	assert.Eventually(t, func() bool {
+		return sink.DataPointCount() == 2
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+const (
+	azureEndpoint = "http://169.254.169.254"
+
+	azureComputePath = "/metadata/instance/compute?api-version=2020-09-01&format=json"
+
+	azureMetadataHeader = "Metadata"
+)
+
+// azureDetector reads the compute metadata of the Azure Instance Metadata Service
+type azureDetector struct {
+	client   *http.Client
+	endpoint string
+}
+
+// azureComputeMetadata holds the used fields of the compute metadata
+type azureComputeMetadata struct {
+	Location       string `json:"location"`
+	SubscriptionID string `json:"subscriptionId"`
+	VMID           string `json:"vmId"`
+	VMSize         string `json:"vmSize"`
+	Zone           string `json:"zone"`
+}
+
+func (d *azureDetector) name() string {
+	return azureDetectorName
+}
+
+func (d *azureDetector) detect(ctx context.Context) (*cloudMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.endpoint+azureComputePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(azureMetadataHeader, "true")
+
+	var md azureComputeMetadata
+	if err := getJSON(d.client, req, &md); err != nil {
+		return nil, err
+	}
+	if md.VMID == "" {
+		return nil, errors.New("compute metadata without VM ID")
+	}
+
+	return &cloudMetadata{
+		provider:         "azure",
+		accountID:        md.SubscriptionID,
+		region:           md.Location,
+		availabilityZone: md.Zone,
+		instanceID:       md.VMID,
+		instanceType:     md.VMSize,
+	}, nil
+}
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the cloud resource detection.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Detectors are the cloud providers whose metadata services are queried, in order, until one of them responds.
+	// All of them are tried when it is empty.
+	Detectors []string `mapstructure:"detectors"`
+	// Timeout is the time limit of querying a single metadata service
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Override enables replacing the resource attributes already set with the detected ones
+	Override bool `mapstructure:"override"`
+}
+
+const (
+	ec2DetectorName   = "ec2"
+	gceDetectorName   = "gce"
+	azureDetectorName = "azure"
+
+	defaultTimeout = 2 * time.Second
+)
+
+var defaultDetectors = []string{ec2DetectorName, gceDetectorName, azureDetectorName}
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	seen := map[string]bool{}
+	for _, name := range cfg.Detectors {
+		switch name {
+		case ec2DetectorName, gceDetectorName, azureDetectorName:
+		default:
+			return fmt.Errorf("unknown detector %q, must be one of: ec2, gce, azure", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("detector %q is listed more than once", name)
+		}
+		seen[name] = true
+	}
+	if cfg.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	return nil
+}
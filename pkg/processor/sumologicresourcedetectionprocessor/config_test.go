@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "sumologic_resource_detection_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)], factory.CreateDefaultConfig())
+	assert.Equal(t, cfg.Processors[config.NewIDWithName(typeStr, "aws")],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewIDWithName(typeStr, "aws")),
+			Detectors:         []string{"ec2"},
+			Timeout:           5 * time.Second,
+			Override:          false,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "default", modify: func(cfg *Config) {}},
+		{name: "all detectors", modify: func(cfg *Config) { cfg.Detectors = []string{"azure", "gce", "ec2"} }},
+		{
+			name:    "unknown detector",
+			modify:  func(cfg *Config) { cfg.Detectors = []string{"ec2", "ecs"} },
+			wantErr: `unknown detector "ecs", must be one of: ec2, gce, azure`,
+		},
+		{
+			name:    "duplicated detector",
+			modify:  func(cfg *Config) { cfg.Detectors = []string{"gce", "gce"} },
+			wantErr: `detector "gce" is listed more than once`,
+		},
+		{
+			name:    "zero timeout",
+			modify:  func(cfg *Config) { cfg.Timeout = 0 },
+			wantErr: "timeout must be positive",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
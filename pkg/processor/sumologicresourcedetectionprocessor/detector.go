@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// The resource attributes set by the processor, named as expected by Sumo Logic.
+const (
+	accountIDAttribute        = "AccountId"
+	availabilityZoneAttribute = "AvailabilityZone"
+	cloudProviderAttribute    = "CloudProvider"
+	instanceIDAttribute       = "InstanceId"
+	instanceTypeAttribute     = "InstanceType"
+	regionAttribute           = "Region"
+)
+
+// maxResponseSize limits the size of the metadata service responses read
+const maxResponseSize = 1 << 20
+
+// detector queries the metadata service of a single cloud provider
+type detector interface {
+	name() string
+	detect(ctx context.Context) (*cloudMetadata, error)
+}
+
+// cloudMetadata describes the instance the collector runs on
+type cloudMetadata struct {
+	provider         string
+	accountID        string
+	region           string
+	availabilityZone string
+	instanceID       string
+	instanceType     string
+}
+
+// attributes returns the non-empty metadata as the Sumo Logic resource attributes
+func (m *cloudMetadata) attributes() pdata.AttributeMap {
+	attrs := pdata.NewAttributeMap()
+	for _, attr := range []struct {
+		key   string
+		value string
+	}{
+		{cloudProviderAttribute, m.provider},
+		{accountIDAttribute, m.accountID},
+		{regionAttribute, m.region},
+		{availabilityZoneAttribute, m.availabilityZone},
+		{instanceIDAttribute, m.instanceID},
+		{instanceTypeAttribute, m.instanceType},
+	} {
+		if attr.value != "" {
+			attrs.UpsertString(attr.key, attr.value)
+		}
+	}
+	return attrs
+}
+
+func newDetector(name string, client *http.Client) detector {
+	switch name {
+	case ec2DetectorName:
+		return &ec2Detector{client: client, endpoint: ec2Endpoint}
+	case gceDetectorName:
+		return &gceDetector{client: client, endpoint: gceEndpoint}
+	case azureDetectorName:
+		return &azureDetector{client: client, endpoint: azureEndpoint}
+	}
+	return nil
+}
+
+// doRequest sends the request and returns the body of the successful response
+func doRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{url: req.URL.String(), statusCode: resp.StatusCode}
+	}
+	return body, nil
+}
+
+// getJSON sends the request and decodes the JSON response into v
+func getJSON(client *http.Client, req *http.Request, v interface{}) error {
+	body, err := doRequest(client, req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode the response of %s: %w", req.URL, err)
+	}
+	return nil
+}
+
+// statusError is returned when the metadata service responds with an unexpected status
+type statusError struct {
+	url        string
+	statusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("request to %s failed with status %d", e.url, e.statusCode)
+}
@@ -0,0 +1,196 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ec2Document = `{
+  "accountId" : "123456789012",
+  "architecture" : "x86_64",
+  "availabilityZone" : "us-east-1b",
+  "imageId" : "ami-5fb8c835",
+  "instanceId" : "i-1234567890abcdef0",
+  "instanceType" : "t2.micro",
+  "privateIp" : "10.158.112.84",
+  "region" : "us-east-1"
+}`
+
+func newEC2Server(t *testing.T, imdsv2 bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == ec2TokenPath:
+			if !imdsv2 {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			assert.Equal(t, ec2TokenTTL, r.Header.Get(ec2TokenTTLHeader))
+			_, _ = w.Write([]byte("token"))
+		case r.Method == http.MethodGet && r.URL.Path == ec2DocumentPath:
+			if imdsv2 && r.Header.Get(ec2TokenHeader) != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(ec2Document))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestEC2Detector(t *testing.T) {
+	expected := &cloudMetadata{
+		provider:         "aws",
+		accountID:        "123456789012",
+		region:           "us-east-1",
+		availabilityZone: "us-east-1b",
+		instanceID:       "i-1234567890abcdef0",
+		instanceType:     "t2.micro",
+	}
+
+	for _, imdsv2 := range []bool{true, false} {
+		server := newEC2Server(t, imdsv2)
+		d := &ec2Detector{client: server.Client(), endpoint: server.URL}
+
+		md, err := d.detect(context.Background())
+		require.NoError(t, err, "IMDSv2: %v", imdsv2)
+		assert.Equal(t, expected, md, "IMDSv2: %v", imdsv2)
+		server.Close()
+	}
+}
+
+func TestEC2DetectorNotEC2(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	d := &ec2Detector{client: server.Client(), endpoint: server.URL}
+
+	_, err := d.detect(context.Background())
+	var statusErr *statusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.statusCode)
+}
+
+func TestEC2DetectorUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	d := &ec2Detector{client: server.Client(), endpoint: server.URL}
+
+	_, err := d.detect(context.Background())
+	assert.Error(t, err)
+}
+
+func TestGCEDetector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(gceFlavorHeader) != gceFlavor || r.URL.Path != "/computeMetadata/v1/" ||
+			r.URL.Query().Get("recursive") != "true" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+  "instance": {
+    "hostname": "instance-1.c.my-project.internal",
+    "id": 4520031799277581759,
+    "machineType": "projects/123456789/machineTypes/e2-medium",
+    "zone": "projects/123456789/zones/us-central1-a"
+  },
+  "project": {
+    "numericProjectId": 123456789,
+    "projectId": "my-project"
+  }
+}`))
+	}))
+	defer server.Close()
+	d := &gceDetector{client: server.Client(), endpoint: server.URL}
+
+	md, err := d.detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &cloudMetadata{
+		provider:         "gcp",
+		accountID:        "my-project",
+		region:           "us-central1",
+		availabilityZone: "us-central1-a",
+		instanceID:       "4520031799277581759",
+		instanceType:     "e2-medium",
+	}, md)
+}
+
+func TestAzureDetector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(azureMetadataHeader) != "true" || r.URL.Path != "/metadata/instance/compute" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+  "location": "westeurope",
+  "name": "vm-1",
+  "subscriptionId": "8d10da13-8125-4ba9-a717-bf7490507b3d",
+  "vmId": "02aab8a4-74ef-476e-8182-f6d2ba4166a6",
+  "vmSize": "Standard_A3",
+  "zone": "1"
+}`))
+	}))
+	defer server.Close()
+	d := &azureDetector{client: server.Client(), endpoint: server.URL}
+
+	md, err := d.detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &cloudMetadata{
+		provider:         "azure",
+		accountID:        "8d10da13-8125-4ba9-a717-bf7490507b3d",
+		region:           "westeurope",
+		availabilityZone: "1",
+		instanceID:       "02aab8a4-74ef-476e-8182-f6d2ba4166a6",
+		instanceType:     "Standard_A3",
+	}, md)
+}
+
+func TestDetectorInvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html>captive portal</html>`))
+	}))
+	defer server.Close()
+
+	for _, d := range []detector{
+		&ec2Detector{client: server.Client(), endpoint: server.URL},
+		&gceDetector{client: server.Client(), endpoint: server.URL},
+		&azureDetector{client: server.Client(), endpoint: server.URL},
+	} {
+		_, err := d.detect(context.Background())
+		assert.Error(t, err, d.name())
+	}
+}
+
+func TestMetadataAttributes(t *testing.T) {
+	md := &cloudMetadata{
+		provider:   "azure",
+		accountID:  "subscription",
+		region:     "westeurope",
+		instanceID: "vm",
+	}
+
+	assert.Equal(t, map[string]string{
+		"CloudProvider": "azure",
+		"AccountId":     "subscription",
+		"Region":        "westeurope",
+		"InstanceId":    "vm",
+	}, attributesToMap(md.attributes()))
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+const (
+	ec2Endpoint = "http://169.254.169.254"
+
+	ec2TokenPath    = "/latest/api/token"
+	ec2DocumentPath = "/latest/dynamic/instance-identity/document"
+
+	ec2TokenHeader    = "X-aws-ec2-metadata-token"
+	ec2TokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+	ec2TokenTTL       = "60"
+)
+
+// ec2Detector reads the instance identity document of the EC2 instance metadata service.
+// IMDSv2 is used when available, with a fallback to IMDSv1.
+type ec2Detector struct {
+	client   *http.Client
+	endpoint string
+}
+
+// ec2IdentityDocument holds the used fields of the instance identity document
+type ec2IdentityDocument struct {
+	AccountID        string `json:"accountId"`
+	AvailabilityZone string `json:"availabilityZone"`
+	InstanceID       string `json:"instanceId"`
+	InstanceType     string `json:"instanceType"`
+	Region           string `json:"region"`
+}
+
+func (d *ec2Detector) name() string {
+	return ec2DetectorName
+}
+
+func (d *ec2Detector) detect(ctx context.Context) (*cloudMetadata, error) {
+	token, err := d.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.endpoint+ec2DocumentPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set(ec2TokenHeader, token)
+	}
+
+	var doc ec2IdentityDocument
+	if err := getJSON(d.client, req, &doc); err != nil {
+		return nil, err
+	}
+	if doc.InstanceID == "" {
+		return nil, errors.New("instance identity document without instance ID")
+	}
+
+	return &cloudMetadata{
+		provider:         "aws",
+		accountID:        doc.AccountID,
+		region:           doc.Region,
+		availabilityZone: doc.AvailabilityZone,
+		instanceID:       doc.InstanceID,
+		instanceType:     doc.InstanceType,
+	}, nil
+}
+
+// token returns the IMDSv2 session token, or an empty one when the service responds
+// but IMDSv2 is not available
+func (d *ec2Detector) token(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.endpoint+ec2TokenPath, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(ec2TokenTTLHeader, ec2TokenTTL)
+
+	token, err := doRequest(d.client, req)
+	if err != nil {
+		var statusErr *statusError
+		if errors.As(err, &statusErr) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(token), nil
+}
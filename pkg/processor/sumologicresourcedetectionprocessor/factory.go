@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+const (
+	// The value of "type" Sumo Logic Resource Detection in configuration.
+	typeStr = "sumologic_resource_detection"
+)
+
+var processorCapabilities = consumer.Capabilities{MutatesData: true}
+
+type factory struct {
+	// processors are shared by the pipelines using the same configuration, so that the metadata
+	// services are queried only once
+	processors map[config.Processor]*resourceDetectionProcessor
+	lock       sync.Mutex
+}
+
+// NewFactory returns a new factory for the Sumo Logic Resource Detection processor.
+func NewFactory() component.ProcessorFactory {
+	f := &factory{
+		processors: map[config.Processor]*resourceDetectionProcessor{},
+	}
+
+	return processorhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		processorhelper.WithTraces(f.createTracesProcessor),
+		processorhelper.WithMetrics(f.createMetricsProcessor),
+		processorhelper.WithLogs(f.createLogsProcessor))
+}
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+		Timeout:           defaultTimeout,
+		Override:          true,
+	}
+}
+
+func (f *factory) createTracesProcessor(
+	_ context.Context,
+	params component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Traces,
+) (component.TracesProcessor, error) {
+	rdp, err := f.getProcessor(params, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewTracesProcessor(
+		cfg,
+		nextConsumer,
+		rdp.ProcessTraces,
+		processorhelper.WithCapabilities(processorCapabilities),
+		processorhelper.WithStart(rdp.Start))
+}
+
+func (f *factory) createMetricsProcessor(
+	_ context.Context,
+	params component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Metrics,
+) (component.MetricsProcessor, error) {
+	rdp, err := f.getProcessor(params, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewMetricsProcessor(
+		cfg,
+		nextConsumer,
+		rdp.ProcessMetrics,
+		processorhelper.WithCapabilities(processorCapabilities),
+		processorhelper.WithStart(rdp.Start))
+}
+
+func (f *factory) createLogsProcessor(
+	_ context.Context,
+	params component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Logs,
+) (component.LogsProcessor, error) {
+	rdp, err := f.getProcessor(params, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewLogsProcessor(
+		cfg,
+		nextConsumer,
+		rdp.ProcessLogs,
+		processorhelper.WithCapabilities(processorCapabilities),
+		processorhelper.WithStart(rdp.Start))
+}
+
+func (f *factory) getProcessor(params component.ProcessorCreateSettings, cfg config.Processor) (*resourceDetectionProcessor, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if rdp, ok := f.processors[cfg]; ok {
+		return rdp, nil
+	}
+
+	rdp, err := newResourceDetectionProcessor(params.Logger, cfg.(*Config))
+	if err != nil {
+		return nil, err
+	}
+	f.processors[cfg] = rdp
+	return rdp, nil
+}
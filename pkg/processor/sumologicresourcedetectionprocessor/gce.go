@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	gceEndpoint = "http://metadata.google.internal"
+
+	gceMetadataPath = "/computeMetadata/v1/?recursive=true"
+
+	gceFlavorHeader = "Metadata-Flavor"
+	gceFlavor       = "Google"
+)
+
+// gceDetector reads the instance and project metadata of the Google Compute Engine metadata server
+type gceDetector struct {
+	client   *http.Client
+	endpoint string
+}
+
+// gceMetadata holds the used fields of the recursive metadata listing
+type gceMetadata struct {
+	Instance struct {
+		ID          json.Number `json:"id"`
+		Zone        string      `json:"zone"`
+		MachineType string      `json:"machineType"`
+	} `json:"instance"`
+	Project struct {
+		ProjectID string `json:"projectId"`
+	} `json:"project"`
+}
+
+func (d *gceDetector) name() string {
+	return gceDetectorName
+}
+
+func (d *gceDetector) detect(ctx context.Context) (*cloudMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.endpoint+gceMetadataPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(gceFlavorHeader, gceFlavor)
+
+	var md gceMetadata
+	if err := getJSON(d.client, req, &md); err != nil {
+		return nil, err
+	}
+	if md.Instance.ID == "" {
+		return nil, errors.New("instance metadata without instance ID")
+	}
+
+	// zone and machine type are given as paths, e.g. projects/123456789/zones/us-central1-a
+	zone := lastPathElement(md.Instance.Zone)
+	return &cloudMetadata{
+		provider:         "gcp",
+		accountID:        md.Project.ProjectID,
+		region:           zoneToRegion(zone),
+		availabilityZone: zone,
+		instanceID:       md.Instance.ID.String(),
+		instanceType:     lastPathElement(md.Instance.MachineType),
+	}, nil
+}
+
+func lastPathElement(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// zoneToRegion strips the zone suffix, e.g. us-central1-a becomes us-central1
+func zoneToRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
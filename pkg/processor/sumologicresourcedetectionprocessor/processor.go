@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// resourceDetectionProcessor adds the Sumo Logic cloud attributes of the instance the collector runs on
+// to all the resources. The metadata services are queried once, on start.
+type resourceDetectionProcessor struct {
+	logger    *zap.Logger
+	detectors []detector
+	timeout   time.Duration
+	override  bool
+
+	once       sync.Once
+	attributes pdata.AttributeMap
+}
+
+func newResourceDetectionProcessor(logger *zap.Logger, cfg *Config) (*resourceDetectionProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	names := cfg.Detectors
+	if len(names) == 0 {
+		names = defaultDetectors
+	}
+	// the metadata services don't redirect, and the timeout is set on the requests' context
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	rdp := &resourceDetectionProcessor{
+		logger:     logger,
+		timeout:    cfg.Timeout,
+		override:   cfg.Override,
+		attributes: pdata.NewAttributeMap(),
+	}
+	for _, name := range names {
+		rdp.detectors = append(rdp.detectors, newDetector(name, client))
+	}
+	return rdp, nil
+}
+
+// Start detects the cloud resource. The detection is done only once, even if the processor is used
+// in multiple pipelines.
+func (rdp *resourceDetectionProcessor) Start(ctx context.Context, _ component.Host) error {
+	rdp.once.Do(func() {
+		rdp.attributes = rdp.detect(ctx)
+	})
+	return nil
+}
+
+// detect returns the attributes of the first detector which succeeds. The failures are expected
+// when not running in the given cloud, so they are only logged.
+func (rdp *resourceDetectionProcessor) detect(ctx context.Context) pdata.AttributeMap {
+	for _, d := range rdp.detectors {
+		detectCtx, cancel := context.WithTimeout(ctx, rdp.timeout)
+		md, err := d.detect(detectCtx)
+		cancel()
+		if err != nil {
+			rdp.logger.Debug("Cloud resource not detected", zap.String("detector", d.name()), zap.Error(err))
+			continue
+		}
+
+		attrs := md.attributes()
+		fields := []zap.Field{zap.String("detector", d.name())}
+		attrs.Sort().Range(func(k string, v pdata.AttributeValue) bool {
+			fields = append(fields, zap.String(k, v.StringVal()))
+			return true
+		})
+		rdp.logger.Info("Cloud resource detected", fields...)
+		return attrs
+	}
+
+	rdp.logger.Warn("No cloud resource detected, the resources will not be changed")
+	return pdata.NewAttributeMap()
+}
+
+func (rdp *resourceDetectionProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rdp.processResource(rss.At(i).Resource())
+	}
+	return td, nil
+}
+
+func (rdp *resourceDetectionProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rdp.processResource(rms.At(i).Resource())
+	}
+	return md, nil
+}
+
+func (rdp *resourceDetectionProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rdp.processResource(rls.At(i).Resource())
+	}
+	return ld, nil
+}
+
+func (rdp *resourceDetectionProcessor) processResource(resource pdata.Resource) {
+	attrs := resource.Attributes()
+	rdp.attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		if rdp.override {
+			attrs.Upsert(k, v)
+		} else {
+			attrs.Insert(k, v)
+		}
+		return true
+	})
+}
@@ -0,0 +1,202 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicresourcedetectionprocessor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+type fakeDetector struct {
+	detectorName string
+	md           *cloudMetadata
+	err          error
+	calls        int
+	deadline     bool
+}
+
+func (d *fakeDetector) name() string {
+	return d.detectorName
+}
+
+func (d *fakeDetector) detect(ctx context.Context) (*cloudMetadata, error) {
+	d.calls++
+	_, d.deadline = ctx.Deadline()
+	return d.md, d.err
+}
+
+func attributesToMap(attrs pdata.AttributeMap) map[string]string {
+	m := map[string]string{}
+	attrs.Range(func(k string, v pdata.AttributeValue) bool {
+		m[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return m
+}
+
+func newTestProcessor(t *testing.T, override bool, detectors ...detector) *resourceDetectionProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Override = override
+	rdp, err := newResourceDetectionProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	rdp.detectors = detectors
+	return rdp
+}
+
+var awsMetadata = &cloudMetadata{
+	provider:         "aws",
+	accountID:        "123456789012",
+	region:           "us-east-1",
+	availabilityZone: "us-east-1b",
+	instanceID:       "i-1234567890abcdef0",
+	instanceType:     "t2.micro",
+}
+
+func TestDefaultDetectors(t *testing.T) {
+	rdp, err := newResourceDetectionProcessor(zap.NewNop(), createDefaultConfig().(*Config))
+	require.NoError(t, err)
+
+	var names []string
+	for _, d := range rdp.detectors {
+		names = append(names, d.name())
+	}
+	assert.Equal(t, []string{"ec2", "gce", "azure"}, names)
+}
+
+func TestFirstDetectedResourceWins(t *testing.T) {
+	gce := &fakeDetector{detectorName: "gce", err: errors.New("no such host")}
+	ec2 := &fakeDetector{detectorName: "ec2", md: awsMetadata}
+	azure := &fakeDetector{detectorName: "azure", md: &cloudMetadata{provider: "azure", instanceID: "vm"}}
+	rdp := newTestProcessor(t, true, gce, ec2, azure)
+
+	require.NoError(t, rdp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, rdp.Start(context.Background(), componenttest.NewNopHost()))
+
+	assert.Equal(t, 1, gce.calls)
+	assert.Equal(t, 1, ec2.calls)
+	assert.Equal(t, 0, azure.calls)
+	assert.True(t, gce.deadline)
+	assert.Equal(t, attributesToMap(awsMetadata.attributes()), attributesToMap(rdp.attributes))
+}
+
+func TestNoResourceDetected(t *testing.T) {
+	rdp := newTestProcessor(t, true, &fakeDetector{detectorName: "ec2", err: errors.New("timeout")})
+	require.NoError(t, rdp.Start(context.Background(), componenttest.NewNopHost()))
+
+	ld := pdata.NewLogs()
+	ld.ResourceLogs().AppendEmpty().Resource().Attributes().InsertString("host", "my-host")
+
+	result, err := rdp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "my-host"},
+		attributesToMap(result.ResourceLogs().At(0).Resource().Attributes()))
+}
+
+func TestProcessAllSignals(t *testing.T) {
+	rdp := newTestProcessor(t, true, &fakeDetector{detectorName: "ec2", md: awsMetadata})
+	require.NoError(t, rdp.Start(context.Background(), componenttest.NewNopHost()))
+
+	expected := map[string]string{
+		"CloudProvider":    "aws",
+		"AccountId":        "123456789012",
+		"Region":           "us-east-1",
+		"AvailabilityZone": "us-east-1b",
+		"InstanceId":       "i-1234567890abcdef0",
+		"InstanceType":     "t2.micro",
+		"service.name":     "checkout",
+	}
+
+	td := pdata.NewTraces()
+	td.ResourceSpans().AppendEmpty().Resource().Attributes().InsertString("service.name", "checkout")
+	td, err := rdp.ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+	assert.Equal(t, expected, attributesToMap(td.ResourceSpans().At(0).Resource().Attributes()))
+
+	md := pdata.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().Resource().Attributes().InsertString("service.name", "checkout")
+	md, err = rdp.ProcessMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, expected, attributesToMap(md.ResourceMetrics().At(0).Resource().Attributes()))
+
+	ld := pdata.NewLogs()
+	ld.ResourceLogs().AppendEmpty().Resource().Attributes().InsertString("service.name", "checkout")
+	ld, err = rdp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, expected, attributesToMap(ld.ResourceLogs().At(0).Resource().Attributes()))
+}
+
+func TestOverride(t *testing.T) {
+	testcases := []struct {
+		override bool
+		expected string
+	}{
+		{override: true, expected: "us-east-1"},
+		{override: false, expected: "eu-west-1"},
+	}
+
+	for _, tc := range testcases {
+		rdp := newTestProcessor(t, tc.override, &fakeDetector{detectorName: "ec2", md: awsMetadata})
+		require.NoError(t, rdp.Start(context.Background(), componenttest.NewNopHost()))
+
+		md := pdata.NewMetrics()
+		md.ResourceMetrics().AppendEmpty().Resource().Attributes().InsertString("Region", "eu-west-1")
+		md, err := rdp.ProcessMetrics(context.Background(), md)
+		require.NoError(t, err)
+
+		attrs := attributesToMap(md.ResourceMetrics().At(0).Resource().Attributes())
+		assert.Equal(t, tc.expected, attrs["Region"], "override: %v", tc.override)
+		assert.Equal(t, "i-1234567890abcdef0", attrs["InstanceId"], "override: %v", tc.override)
+	}
+}
+
+func TestDetectionTimeout(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Timeout = 50 * time.Millisecond
+	cfg.Detectors = []string{"gce"}
+	rdp, err := newResourceDetectionProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+
+	blocked := make(chan struct{})
+	defer close(blocked)
+	server := newBlockingServer(blocked)
+	defer server.Close()
+	rdp.detectors = []detector{&gceDetector{client: server.Client(), endpoint: server.URL}}
+
+	start := time.Now()
+	require.NoError(t, rdp.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Less(t, time.Since(start).Nanoseconds(), (5 * time.Second).Nanoseconds())
+	assert.Equal(t, 0, rdp.attributes.Len())
+}
+
+// newBlockingServer returns a server which doesn't respond until the request is canceled
+// or the channel is closed
+func newBlockingServer(blocked chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-blocked:
+		}
+	}))
+}
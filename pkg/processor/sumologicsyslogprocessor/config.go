@@ -24,6 +24,13 @@ type Config struct {
 
 	// FacilityAttr is the name of the attribute the facility name should be placed into.
 	FacilityAttr string `mapstructure:"facility_attr"`
+
+	// ParseStructuredData turns on parsing the RFC 5424 structured data elements into attributes
+	// named <sd_id>.<param_name>.
+	ParseStructuredData bool `mapstructure:"parse_structured_data"`
+
+	// StructuredDataPrefix is prepended to the names of the structured data attributes.
+	StructuredDataPrefix string `mapstructure:"structured_data_prefix"`
 }
 
 const (
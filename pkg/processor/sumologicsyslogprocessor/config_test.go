@@ -38,7 +38,9 @@ func TestLoadConfig(t *testing.T) {
 
 	assert.Equal(t, cfg.Processors[config.NewID("sumologic_syslog")],
 		&Config{
-			ProcessorSettings: config.NewProcessorSettings(config.NewID("sumologic_syslog")),
-			FacilityAttr:      "testAttrName",
+			ProcessorSettings:    config.NewProcessorSettings(config.NewID("sumologic_syslog")),
+			FacilityAttr:         "testAttrName",
+			ParseStructuredData:  true,
+			StructuredDataPrefix: "sd.",
 		})
 }
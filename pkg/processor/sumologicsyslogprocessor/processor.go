@@ -28,6 +28,8 @@ import (
 type sumologicSyslogProcessor struct {
 	syslogFacilityAttrName string
 	syslogFacilityRegex    *regexp.Regexp
+	parseStructuredData    bool
+	structuredDataPrefix   string
 }
 
 const (
@@ -73,11 +75,14 @@ func newSumologicSyslogProcessor(cfg *Config) (*sumologicSyslogProcessor, error)
 	return &sumologicSyslogProcessor{
 		syslogFacilityAttrName: cfg.FacilityAttr,
 		syslogFacilityRegex:    r,
+		parseStructuredData:    cfg.ParseStructuredData,
+		structuredDataPrefix:   cfg.StructuredDataPrefix,
 	}, nil
 }
 
 // ProcessLogs tries to extract facility number from log syslog line and maps it to facility name.
 // Facility is taken as $number/8 rounded down, where log looks like `^<$number> .*`
+// When enabled, the RFC 5424 structured data parameters are added as attributes as well.
 func (ssp *sumologicSyslogProcessor) ProcessLogs(ctx context.Context, ld pdata.Logs) (pdata.Logs, error) {
 	// Iterate over ResourceLogs
 	rls := ld.ResourceLogs()
@@ -113,9 +118,28 @@ func (ssp *sumologicSyslogProcessor) ProcessLogs(ctx context.Context, ld pdata.L
 					}
 				}
 				log.Attributes().UpsertString(ssp.syslogFacilityAttrName, value)
+
+				if ssp.parseStructuredData {
+					ssp.addStructuredData(log)
+				}
 			}
 		}
 	}
 
 	return ld, nil
 }
+
+// addStructuredData adds the structured data parameters as <sd_id>.<param_name> attributes.
+// When a parameter is repeated, its last value is kept.
+func (ssp *sumologicSyslogProcessor) addStructuredData(log pdata.LogRecord) {
+	if log.Body().Type() != pdata.AttributeValueTypeString {
+		return
+	}
+	params, ok := parseStructuredData(log.Body().StringVal())
+	if !ok {
+		return
+	}
+	for _, param := range params {
+		log.Attributes().UpsertString(ssp.structuredDataPrefix+param.id+"."+param.name, param.value)
+	}
+}
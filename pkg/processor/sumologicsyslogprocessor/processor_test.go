@@ -64,3 +64,80 @@ func TestProcessLogs(t *testing.T) {
 		assert.Equal(t, line, attr.StringVal())
 	}
 }
+
+func TestProcessLogsStructuredData(t *testing.T) {
+	logs := pdata.NewLogs()
+	ills := logs.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty()
+	ills.Logs().AppendEmpty().Body().SetStringVal(
+		`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 ` +
+			`[exampleSDID@32473 iut="3" eventSource="Application"][examplePriority@32473 class="high"] An application event`)
+	ills.Logs().AppendEmpty().Body().SetStringVal(`<13> Example log`)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ParseStructuredData = true
+	cfg.StructuredDataPrefix = "sd."
+	processor, err := newSumologicSyslogProcessor(cfg)
+	require.NoError(t, err)
+
+	result, err := processor.ProcessLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	attrs := result.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Attributes()
+	assert.Equal(t, 4, attrs.Len())
+	for name, expected := range map[string]string{
+		"facility":                         "local use 4  (local4)",
+		"sd.exampleSDID@32473.iut":         "3",
+		"sd.exampleSDID@32473.eventSource": "Application",
+		"sd.examplePriority@32473.class":   "high",
+	} {
+		value, ok := attrs.Get(name)
+		require.True(t, ok, name)
+		assert.Equal(t, expected, value.StringVal())
+	}
+
+	attrs = result.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(1).Attributes()
+	assert.Equal(t, 1, attrs.Len())
+}
+
+func TestParseStructuredData(t *testing.T) {
+	const header = `<165>1 2003-10-11T22:14:15.003Z host app 1234 ID47 `
+	testcases := []struct {
+		name     string
+		line     string
+		expected []sdParam
+		ok       bool
+	}{
+		{
+			name:     "element without message",
+			line:     header + `[origin ip="192.0.2.1"]`,
+			expected: []sdParam{{id: "origin", name: "ip", value: "192.0.2.1"}},
+			ok:       true,
+		},
+		{
+			name: "escaped characters",
+			line: header + `[test@1 a="x\"y" b="c:\\d" c="[\]" d="\n"] message`,
+			expected: []sdParam{
+				{id: "test@1", name: "a", value: `x"y`},
+				{id: "test@1", name: "b", value: `c:\d`},
+				{id: "test@1", name: "c", value: `[]`},
+				{id: "test@1", name: "d", value: `\n`},
+			},
+			ok: true,
+		},
+		{name: "element without params", line: header + `[timeQuality] message`, ok: true},
+		{name: "nil structured data", line: header + `- message`},
+		{name: "not RFC 5424", line: `<13> Example log [origin ip="192.0.2.1"]`},
+		{name: "missing closing bracket", line: header + `[origin ip="192.0.2.1" message`},
+		{name: "missing closing quote", line: header + `[origin ip="192.0.2.1]`},
+		{name: "unquoted value", line: header + `[origin ip=192.0.2.1]`},
+		{name: "no space after structured data", line: header + `[origin ip="192.0.2.1"]message`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			params, ok := parseStructuredData(tc.line)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, params)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicsyslogprocessor
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rfc5424HeaderRegex matches the RFC 5424 header preceding the structured data:
+// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID
+var rfc5424HeaderRegex = regexp.MustCompile(`^<\d{1,3}>\d{1,2} \S+ \S+ \S+ \S+ \S+ `)
+
+// sdParam is a parameter of an RFC 5424 structured data element
+type sdParam struct {
+	id    string
+	name  string
+	value string
+}
+
+// parseStructuredData returns the parameters of the structured data elements of an RFC 5424 message,
+// e.g. [exampleSDID@32473 iut="3" eventSource="Application"]. It returns false for the other messages
+// and for malformed structured data.
+func parseStructuredData(line string) ([]sdParam, bool) {
+	loc := rfc5424HeaderRegex.FindStringIndex(line)
+	if loc == nil {
+		return nil, false
+	}
+	sd := line[loc[1]:]
+
+	var params []sdParam
+	i := 0
+	for i < len(sd) && sd[i] == '[' {
+		i++
+		start := i
+		for i < len(sd) && sd[i] != ' ' && sd[i] != ']' {
+			if sd[i] == '=' || sd[i] == '"' {
+				return nil, false
+			}
+			i++
+		}
+		id := sd[start:i]
+		if id == "" {
+			return nil, false
+		}
+
+		for i < len(sd) && sd[i] == ' ' {
+			i++
+			start = i
+			for i < len(sd) && sd[i] != '=' {
+				if sd[i] == ' ' || sd[i] == ']' || sd[i] == '"' {
+					return nil, false
+				}
+				i++
+			}
+			name := sd[start:i]
+			if name == "" || i+1 >= len(sd) || sd[i+1] != '"' {
+				return nil, false
+			}
+			i += 2
+
+			var value strings.Builder
+			for ; i < len(sd) && sd[i] != '"'; i++ {
+				// Only '"', '\' and ']' are escaped, a backslash before any other character is kept
+				if sd[i] == '\\' && i+1 < len(sd) && strings.IndexByte(`"\]`, sd[i+1]) >= 0 {
+					i++
+				}
+				value.WriteByte(sd[i])
+			}
+			if i >= len(sd) {
+				return nil, false
+			}
+			i++
+			params = append(params, sdParam{id: id, name: name, value: value.String()})
+		}
+
+		if i >= len(sd) || sd[i] != ']' {
+			return nil, false
+		}
+		i++
+	}
+
+	// The structured data is either nil ("-") or followed by the end of the message or a space
+	if i == 0 || (i < len(sd) && sd[i] != ' ') {
+		return nil, false
+	}
+	return params, true
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textparserprocessor
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	formatCSV      = "csv"
+	formatKeyValue = "kv"
+
+	typeString = "string"
+	typeInt    = "int"
+	typeFloat  = "float"
+	typeBool   = "bool"
+
+	defaultCSVDelimiter   = ","
+	defaultValueDelimiter = "="
+)
+
+// Config holds the configuration of the text parsing.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Format is the format of the parsed text, either csv or kv
+	Format string `mapstructure:"format"`
+	// SourceAttribute is the name of the attribute holding the text to parse, the string body is parsed when empty
+	SourceAttribute string `mapstructure:"source_attribute"`
+	// CSV configures the parsing of the CSV rows
+	CSV CSVConfig `mapstructure:"csv"`
+	// KeyValue configures the parsing of the key=value pairs
+	KeyValue KeyValueConfig `mapstructure:"kv"`
+	// Prefix is prepended to the names of the attributes set to the parsed values
+	Prefix string `mapstructure:"prefix"`
+	// Overwrite enables replacing the existing attributes with the parsed values
+	Overwrite bool `mapstructure:"overwrite"`
+	// InferTypes enables converting the values which look like integers, floats or booleans to such types
+	InferTypes bool `mapstructure:"infer_types"`
+	// FieldTypes are the types the values of the fields are converted to, they take precedence over the inferred ones
+	FieldTypes []FieldTypeConfig `mapstructure:"field_types"`
+}
+
+// CSVConfig configures the parsing of the CSV rows.
+type CSVConfig struct {
+	// Header are the names of the columns, in order
+	Header []string `mapstructure:"header"`
+	// Delimiter is the character separating the columns
+	Delimiter string `mapstructure:"delimiter"`
+	// LazyQuotes enables accepting the quotes which are not escaped by doubling them
+	LazyQuotes bool `mapstructure:"lazy_quotes"`
+}
+
+// KeyValueConfig configures the parsing of the key=value pairs.
+type KeyValueConfig struct {
+	// PairDelimiter is the string separating the pairs, any whitespace when empty
+	PairDelimiter string `mapstructure:"pair_delimiter"`
+	// ValueDelimiter is the string separating the key from the value
+	ValueDelimiter string `mapstructure:"value_delimiter"`
+}
+
+// FieldTypeConfig sets the type of a parsed field.
+type FieldTypeConfig struct {
+	// Field is the name of the field, before the prefix is prepended
+	Field string `mapstructure:"field"`
+	// Type is the type of the value, one of string, int, float or bool
+	Type string `mapstructure:"type"`
+}
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	switch cfg.Format {
+	case formatCSV:
+		if len(cfg.CSV.Header) == 0 {
+			return errors.New("the csv header must be configured")
+		}
+		if utf8.RuneCountInString(cfg.CSV.Delimiter) != 1 {
+			return fmt.Errorf("the csv delimiter must be a single character, got %q", cfg.CSV.Delimiter)
+		}
+		if cfg.CSV.Delimiter == "\"" || cfg.CSV.Delimiter == "\r" || cfg.CSV.Delimiter == "\n" {
+			return fmt.Errorf("invalid csv delimiter %q", cfg.CSV.Delimiter)
+		}
+	case formatKeyValue:
+		if cfg.KeyValue.ValueDelimiter == "" {
+			return errors.New("the kv value delimiter must not be empty")
+		}
+		if cfg.KeyValue.PairDelimiter == cfg.KeyValue.ValueDelimiter {
+			return errors.New("the kv pair and value delimiters must differ")
+		}
+	default:
+		return fmt.Errorf("unknown format %q, must be one of: csv, kv", cfg.Format)
+	}
+
+	for _, fieldType := range cfg.FieldTypes {
+		if fieldType.Field == "" {
+			return errors.New("the fields of the field types must not be empty")
+		}
+		switch fieldType.Type {
+		case typeString, typeInt, typeFloat, typeBool:
+		default:
+			return fmt.Errorf("unknown type %q of field %q, must be one of: string, int, float, bool", fieldType.Type, fieldType.Field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textparserprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "text_parser_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			Format:            "csv",
+			SourceAttribute:   "message",
+			CSV: CSVConfig{
+				Header:     []string{"time", "src.ip", "dst.ip", "action", "bytes"},
+				Delimiter:  ";",
+				LazyQuotes: true,
+			},
+			KeyValue: KeyValueConfig{
+				PairDelimiter:  ",",
+				ValueDelimiter: ":",
+			},
+			Prefix:     "firewall.",
+			Overwrite:  true,
+			InferTypes: true,
+			FieldTypes: []FieldTypeConfig{
+				{Field: "bytes", Type: "int"},
+				{Field: "action", Type: "string"},
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr bool
+	}{
+		{name: "valid csv", modify: func(cfg *Config) {}},
+		{name: "valid kv", modify: func(cfg *Config) { cfg.Format = "kv" }},
+		{name: "no format", modify: func(cfg *Config) { cfg.Format = "" }, wantErr: true},
+		{name: "unknown format", modify: func(cfg *Config) { cfg.Format = "xml" }, wantErr: true},
+		{name: "no header", modify: func(cfg *Config) { cfg.CSV.Header = nil }, wantErr: true},
+		{name: "long csv delimiter", modify: func(cfg *Config) { cfg.CSV.Delimiter = ";;" }, wantErr: true},
+		{name: "quote csv delimiter", modify: func(cfg *Config) { cfg.CSV.Delimiter = "\"" }, wantErr: true},
+		{name: "no value delimiter", modify: func(cfg *Config) {
+			cfg.Format = "kv"
+			cfg.KeyValue.ValueDelimiter = ""
+		}, wantErr: true},
+		{name: "same kv delimiters", modify: func(cfg *Config) {
+			cfg.Format = "kv"
+			cfg.KeyValue.PairDelimiter = "="
+		}, wantErr: true},
+		{name: "empty field type field", modify: func(cfg *Config) {
+			cfg.FieldTypes = []FieldTypeConfig{{Type: "int"}}
+		}, wantErr: true},
+		{name: "unknown field type", modify: func(cfg *Config) {
+			cfg.FieldTypes = []FieldTypeConfig{{Field: "bytes", Type: "long"}}
+		}, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Format = "csv"
+			cfg.CSV.Header = []string{"a", "b"}
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
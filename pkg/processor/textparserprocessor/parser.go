@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textparserprocessor
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// field is a parsed name and value pair
+type field struct {
+	name  string
+	value string
+}
+
+// parser splits the text into fields
+type parser func(text string) ([]field, error)
+
+// newCSVParser returns the parser of single CSV rows with the given header; the columns beyond
+// the header are dropped and the missing ones are not set
+func newCSVParser(cfg CSVConfig) parser {
+	delimiter, _ := utf8.DecodeRuneInString(cfg.Delimiter)
+	return func(text string) ([]field, error) {
+		reader := csv.NewReader(strings.NewReader(text))
+		reader.Comma = delimiter
+		reader.LazyQuotes = cfg.LazyQuotes
+		reader.FieldsPerRecord = -1
+		reader.ReuseRecord = true
+
+		values, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		fields := make([]field, 0, len(cfg.Header))
+		for i, value := range values {
+			if i >= len(cfg.Header) {
+				break
+			}
+			fields = append(fields, field{name: cfg.Header[i], value: value})
+		}
+		return fields, nil
+	}
+}
+
+var errUnterminatedQuote = errors.New("unterminated quoted value")
+
+// newKeyValueParser returns the parser of the key=value pairs, e.g. logfmt; the values can be quoted
+// with double or single quotes, in which backslash escapes the next character, and the keys without
+// a value delimiter are skipped
+func newKeyValueParser(cfg KeyValueConfig) parser {
+	return func(text string) ([]field, error) {
+		var fields []field
+		rest := text
+		for {
+			rest = trimPairDelimiters(rest, cfg.PairDelimiter)
+			if rest == "" {
+				return fields, nil
+			}
+
+			end := pairEnd(rest, cfg.PairDelimiter)
+			separator := strings.Index(rest[:end], cfg.ValueDelimiter)
+			if separator < 0 {
+				rest = rest[end:]
+				continue
+			}
+			key := strings.TrimSpace(rest[:separator])
+			rest = rest[separator+len(cfg.ValueDelimiter):]
+			// with the whitespace pair delimiter, a space following the value delimiter ends an empty value
+			if cfg.PairDelimiter != "" {
+				rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+			}
+
+			var value string
+			if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+				var err error
+				if value, rest, err = unquote(rest); err != nil {
+					return nil, err
+				}
+			} else {
+				end = pairEnd(rest, cfg.PairDelimiter)
+				value, rest = strings.TrimSpace(rest[:end]), rest[end:]
+			}
+			if key != "" {
+				fields = append(fields, field{name: key, value: value})
+			}
+		}
+	}
+}
+
+// trimPairDelimiters removes the leading pair delimiters and whitespace
+func trimPairDelimiters(text string, delimiter string) string {
+	for {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if delimiter == "" || !strings.HasPrefix(text, delimiter) {
+			return text
+		}
+		text = text[len(delimiter):]
+	}
+}
+
+// pairEnd returns the index of the first pair delimiter, or the length of the text when there is none
+func pairEnd(text string, delimiter string) int {
+	var end int
+	if delimiter == "" {
+		end = strings.IndexFunc(text, unicode.IsSpace)
+	} else {
+		end = strings.Index(text, delimiter)
+	}
+	if end < 0 {
+		return len(text)
+	}
+	return end
+}
+
+// unquote returns the value of the quoted string the text starts with and the text following it
+func unquote(text string) (string, string, error) {
+	quote := text[0]
+	var value strings.Builder
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if i+1 < len(text) {
+				i++
+				value.WriteByte(text[i])
+			}
+		case quote:
+			return value.String(), text[i+1:], nil
+		default:
+			value.WriteByte(text[i])
+		}
+	}
+	return "", "", errUnterminatedQuote
+}
@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textparserprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVParser(t *testing.T) {
+	testcases := []struct {
+		name     string
+		cfg      CSVConfig
+		text     string
+		expected []field
+		wantErr  bool
+	}{
+		{
+			name: "simple",
+			cfg:  CSVConfig{Header: []string{"a", "b", "c"}, Delimiter: ","},
+			text: "1,two,3",
+			expected: []field{
+				{name: "a", value: "1"},
+				{name: "b", value: "two"},
+				{name: "c", value: "3"},
+			},
+		},
+		{
+			name: "quoted",
+			cfg:  CSVConfig{Header: []string{"a", "b"}, Delimiter: ","},
+			text: `"x, y","say ""hi"""`,
+			expected: []field{
+				{name: "a", value: "x, y"},
+				{name: "b", value: `say "hi"`},
+			},
+		},
+		{
+			name: "custom delimiter",
+			cfg:  CSVConfig{Header: []string{"a", "b"}, Delimiter: "|"},
+			text: "1|2,3",
+			expected: []field{
+				{name: "a", value: "1"},
+				{name: "b", value: "2,3"},
+			},
+		},
+		{
+			name: "extra columns",
+			cfg:  CSVConfig{Header: []string{"a"}, Delimiter: ","},
+			text: "1,2,3",
+			expected: []field{
+				{name: "a", value: "1"},
+			},
+		},
+		{
+			name: "missing columns",
+			cfg:  CSVConfig{Header: []string{"a", "b", "c"}, Delimiter: ","},
+			text: "1,",
+			expected: []field{
+				{name: "a", value: "1"},
+				{name: "b", value: ""},
+			},
+		},
+		{
+			name:    "bare quote",
+			cfg:     CSVConfig{Header: []string{"a", "b"}, Delimiter: ","},
+			text:    `1,2 "inch"`,
+			wantErr: true,
+		},
+		{
+			name: "lazy quotes",
+			cfg:  CSVConfig{Header: []string{"a", "b"}, Delimiter: ",", LazyQuotes: true},
+			text: `1,2 "inch"`,
+			expected: []field{
+				{name: "a", value: "1"},
+				{name: "b", value: `2 "inch"`},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := newCSVParser(tc.cfg)(tc.text)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fields)
+		})
+	}
+}
+
+func TestKeyValueParser(t *testing.T) {
+	testcases := []struct {
+		name     string
+		cfg      KeyValueConfig
+		text     string
+		expected []field
+		wantErr  bool
+	}{
+		{
+			name: "logfmt",
+			cfg:  KeyValueConfig{ValueDelimiter: "="},
+			text: `level=info msg="user logged in" user='alice' duration=1.5s empty= flag`,
+			expected: []field{
+				{name: "level", value: "info"},
+				{name: "msg", value: "user logged in"},
+				{name: "user", value: "alice"},
+				{name: "duration", value: "1.5s"},
+				{name: "empty", value: ""},
+			},
+		},
+		{
+			name: "escaped quotes",
+			cfg:  KeyValueConfig{ValueDelimiter: "="},
+			text: `msg="say \"hi\"" path="C:\\temp"`,
+			expected: []field{
+				{name: "msg", value: `say "hi"`},
+				{name: "path", value: `C:\temp`},
+			},
+		},
+		{
+			name: "custom delimiters",
+			cfg:  KeyValueConfig{PairDelimiter: ";", ValueDelimiter: ":"},
+			text: "src: 10.0.0.1; dst:10.0.0.2 ;action : allow rule;;time:12:00",
+			expected: []field{
+				{name: "src", value: "10.0.0.1"},
+				{name: "dst", value: "10.0.0.2"},
+				{name: "action", value: "allow rule"},
+				{name: "time", value: "12:00"},
+			},
+		},
+		{
+			name:    "unterminated quote",
+			cfg:     KeyValueConfig{ValueDelimiter: "="},
+			text:    `a=1 msg="unterminated`,
+			wantErr: true,
+		},
+		{
+			name: "empty",
+			cfg:  KeyValueConfig{ValueDelimiter: "="},
+			text: "  ",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := newKeyValueParser(tc.cfg)(tc.text)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fields)
+		})
+	}
+}
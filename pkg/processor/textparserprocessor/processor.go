@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textparserprocessor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// textParserProcessor sets the attributes of the log records to the fields parsed from their CSV or key=value text
+type textParserProcessor struct {
+	logger          *zap.Logger
+	parse           parser
+	sourceAttribute string
+	prefix          string
+	overwrite       bool
+	inferTypes      bool
+	fieldTypes      map[string]string
+}
+
+func newTextParserProcessor(logger *zap.Logger, cfg *Config) (*textParserProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	tp := &textParserProcessor{
+		logger:          logger,
+		sourceAttribute: cfg.SourceAttribute,
+		prefix:          cfg.Prefix,
+		overwrite:       cfg.Overwrite,
+		inferTypes:      cfg.InferTypes,
+		fieldTypes:      map[string]string{},
+	}
+	switch cfg.Format {
+	case formatCSV:
+		tp.parse = newCSVParser(cfg.CSV)
+	case formatKeyValue:
+		tp.parse = newKeyValueParser(cfg.KeyValue)
+	}
+	for _, fieldType := range cfg.FieldTypes {
+		tp.fieldTypes[fieldType.Field] = fieldType.Type
+	}
+	return tp, nil
+}
+
+// ProcessLogs parses the log records
+func (tp *textParserProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				tp.processRecord(logs.At(k))
+			}
+		}
+	}
+	return ld, nil
+}
+
+// processRecord sets the parsed fields as attributes; the record is left unchanged when the text cannot be parsed
+func (tp *textParserProcessor) processRecord(lr pdata.LogRecord) {
+	text, ok := tp.source(lr)
+	if !ok {
+		return
+	}
+
+	fields, err := tp.parse(text)
+	if err != nil {
+		tp.logger.Debug("Cannot parse the log record", zap.Error(err))
+		return
+	}
+
+	attributes := lr.Attributes()
+	for _, f := range fields {
+		value := tp.convert(f)
+		if tp.overwrite {
+			attributes.Upsert(tp.prefix+f.name, value)
+		} else {
+			attributes.Insert(tp.prefix+f.name, value)
+		}
+	}
+}
+
+// source returns the text to parse, either the string value of the source attribute or the string body
+func (tp *textParserProcessor) source(lr pdata.LogRecord) (string, bool) {
+	value := lr.Body()
+	if tp.sourceAttribute != "" {
+		var ok bool
+		if value, ok = lr.Attributes().Get(tp.sourceAttribute); !ok {
+			return "", false
+		}
+	}
+	if value.Type() != pdata.AttributeValueTypeString {
+		return "", false
+	}
+	return value.StringVal(), true
+}
+
+// convert returns the value of the field as its configured or inferred type;
+// the values which are not valid for their configured type are kept as strings
+func (tp *textParserProcessor) convert(f field) pdata.AttributeValue {
+	if typ, ok := tp.fieldTypes[f.name]; ok {
+		if value, ok := convertTo(f.value, typ); ok {
+			return value
+		}
+		return pdata.NewAttributeValueString(f.value)
+	}
+
+	if tp.inferTypes {
+		for _, typ := range []string{typeInt, typeFloat, typeBool} {
+			// words like inf or nan are valid floats, but are rather meant as strings
+			if typ == typeFloat && strings.Trim(f.value, "0123456789+-.eE") != "" {
+				continue
+			}
+			if value, ok := convertTo(f.value, typ); ok {
+				return value
+			}
+		}
+	}
+	return pdata.NewAttributeValueString(f.value)
+}
+
+// convertTo converts the value to the type; only true and false, in any case, are inferred as booleans
+func convertTo(value string, typ string) (pdata.AttributeValue, bool) {
+	switch typ {
+	case typeInt:
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return pdata.NewAttributeValueInt(i), true
+		}
+	case typeFloat:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return pdata.NewAttributeValueDouble(f), true
+		}
+	case typeBool:
+		switch strings.ToLower(value) {
+		case "true":
+			return pdata.NewAttributeValueBool(true), true
+		case "false":
+			return pdata.NewAttributeValueBool(false), true
+		}
+	case typeString:
+		return pdata.NewAttributeValueString(value), true
+	}
+	return pdata.AttributeValue{}, false
+}
@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textparserprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) *textParserProcessor {
+	cfg := createDefaultConfig().(*Config)
+	modify(cfg)
+	tp, err := newTextParserProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	return tp
+}
+
+func newTestLogs(records ...func(lr pdata.LogRecord)) (pdata.Logs, pdata.LogSlice) {
+	ld := pdata.NewLogs()
+	logs := ld.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	for _, fill := range records {
+		fill(logs.AppendEmpty())
+	}
+	return ld, logs
+}
+
+func TestProcessLogsCSV(t *testing.T) {
+	tp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Format = "csv"
+		cfg.CSV.Header = []string{"time", "src.ip", "action", "bytes", "rule"}
+		cfg.Prefix = "firewall."
+		cfg.FieldTypes = []FieldTypeConfig{{Field: "bytes", Type: "int"}, {Field: "rule", Type: "string"}}
+	})
+
+	ld, logs := newTestLogs(
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal("2021-09-01T12:00:00Z,10.0.0.1,allow,1024,42")
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal(`2021-09-01T12:00:00Z,10.0.0.1,deny,"unterminated`)
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetIntVal(1)
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal("2021-09-01T12:00:00Z,10.0.0.2,allow,n/a")
+		},
+	)
+
+	_, err := tp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"firewall.time":   "2021-09-01T12:00:00Z",
+		"firewall.src.ip": "10.0.0.1",
+		"firewall.action": "allow",
+		"firewall.bytes":  int64(1024),
+		"firewall.rule":   "42",
+	}, attributesToMap(logs.At(0).Attributes()))
+	assert.Empty(t, attributesToMap(logs.At(1).Attributes()), "invalid rows are not parsed")
+	assert.Empty(t, attributesToMap(logs.At(2).Attributes()), "non-string bodies are not parsed")
+	assert.Equal(t, "n/a", attributesToMap(logs.At(3).Attributes())["firewall.bytes"],
+		"values invalid for their type are kept as strings")
+}
+
+func TestProcessLogsKeyValue(t *testing.T) {
+	tp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Format = "kv"
+		cfg.SourceAttribute = "message"
+		cfg.InferTypes = true
+		cfg.FieldTypes = []FieldTypeConfig{{Field: "id", Type: "string"}}
+	})
+
+	ld, logs := newTestLogs(
+		func(lr pdata.LogRecord) {
+			lr.Attributes().InsertString("message", `id=007 status=200 latency=0.25 cached=TRUE host=inf msg="not found"`)
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal("status=500")
+		},
+	)
+
+	_, err := tp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"message": `id=007 status=200 latency=0.25 cached=TRUE host=inf msg="not found"`,
+		"id":      "007",
+		"status":  int64(200),
+		"latency": 0.25,
+		"cached":  true,
+		"host":    "inf",
+		"msg":     "not found",
+	}, attributesToMap(logs.At(0).Attributes()))
+	assert.Empty(t, attributesToMap(logs.At(1).Attributes()), "the body is not parsed when a source attribute is set")
+}
+
+func TestProcessLogsOverwrite(t *testing.T) {
+	testcases := []struct {
+		name      string
+		overwrite bool
+		expected  string
+	}{
+		{name: "insert", overwrite: false, expected: "original"},
+		{name: "overwrite", overwrite: true, expected: "parsed"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp := newTestProcessor(t, func(cfg *Config) {
+				cfg.Format = "kv"
+				cfg.Overwrite = tc.overwrite
+			})
+			ld, logs := newTestLogs(func(lr pdata.LogRecord) {
+				lr.Attributes().InsertString("level", "original")
+				lr.Body().SetStringVal("level=parsed")
+			})
+
+			_, err := tp.ProcessLogs(context.Background(), ld)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"level": tc.expected}, attributesToMap(logs.At(0).Attributes()))
+		})
+	}
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]interface{} {
+	result := map[string]interface{}{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = valueToInterface(v)
+		return true
+	})
+	return result
+}
+
+func valueToInterface(v pdata.AttributeValue) interface{} {
+	switch v.Type() {
+	case pdata.AttributeValueTypeInt:
+		return v.IntVal()
+	case pdata.AttributeValueTypeDouble:
+		return v.DoubleVal()
+	case pdata.AttributeValueTypeBool:
+		return v.BoolVal()
+	default:
+		return v.StringVal()
+	}
+}
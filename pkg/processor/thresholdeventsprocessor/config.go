@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thresholdeventsprocessor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the threshold events.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// LogsExporter is the ID of the exporter the events are sent to, e.g. sumologic/logs
+	LogsExporter string `mapstructure:"logs_exporter"`
+	// Rules are the thresholds the metrics are checked against
+	Rules []RuleConfig `mapstructure:"rules"`
+	// ResolvedEvents enables sending an event when the value gets back within the threshold
+	ResolvedEvents bool `mapstructure:"resolved_events"`
+}
+
+// RuleConfig holds the threshold of a single metric. Exactly one of above and below must be set.
+type RuleConfig struct {
+	// Name is the name of the rule, set as the event attribute
+	Name string `mapstructure:"name"`
+	// Metric is the name of the checked metric, its gauge or sum data points are checked
+	Metric string `mapstructure:"metric"`
+	// Above is the threshold the values need to be greater than
+	Above *float64 `mapstructure:"above"`
+	// Below is the threshold the values need to be less than
+	Below *float64 `mapstructure:"below"`
+	// For is the period the values need to cross the threshold for before the event is sent
+	For time.Duration `mapstructure:"for"`
+	// Severity is the severity of the event: trace, debug, info, warn, error or fatal
+	Severity string `mapstructure:"severity"`
+	// Message is the body of the event, a message describing the crossed threshold is used when empty
+	Message string `mapstructure:"message"`
+}
+
+const (
+	defaultSeverity = "warn"
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.LogsExporter == "" {
+		return fmt.Errorf("logs_exporter must not be empty")
+	}
+	if _, err := config.NewIDFromString(cfg.LogsExporter); err != nil {
+		return fmt.Errorf("invalid logs_exporter: %w", err)
+	}
+	if len(cfg.Rules) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+	names := map[string]struct{}{}
+	for i, rule := range cfg.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+		if _, ok := names[rule.Name]; ok {
+			return fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+	}
+	return nil
+}
+
+func (rule *RuleConfig) validate() error {
+	if rule.Name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+	if rule.Metric == "" {
+		return fmt.Errorf("metric must not be empty")
+	}
+	if (rule.Above == nil) == (rule.Below == nil) {
+		return fmt.Errorf("exactly one of above and below must be set")
+	}
+	if rule.For < 0 {
+		return fmt.Errorf("for must not be negative, got %s", rule.For)
+	}
+	if _, ok := severityNumbers[strings.ToLower(rule.Severity)]; !ok && rule.Severity != "" {
+		return fmt.Errorf("unknown severity %q, expected one of: trace, debug, info, warn, error, fatal", rule.Severity)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thresholdeventsprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "threshold_events_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			LogsExporter:      "sumologic/logs",
+			ResolvedEvents:    false,
+			Rules: []RuleConfig{
+				{
+					Name:     "disk_nearly_full",
+					Metric:   "system.filesystem.utilization",
+					Above:    float64Ptr(0.9),
+					For:      5 * time.Minute,
+					Severity: "error",
+					Message:  "Disk nearly full",
+				},
+				{
+					Name:   "low_memory",
+					Metric: "system.memory.available",
+					Below:  float64Ptr(104857600),
+				},
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	validRule := func() RuleConfig {
+		return RuleConfig{Name: "disk", Metric: "system.filesystem.utilization", Above: float64Ptr(0.9)}
+	}
+
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(cfg *Config) {}},
+		{name: "no exporter", modify: func(cfg *Config) { cfg.LogsExporter = "" }, wantErr: true},
+		{name: "invalid exporter", modify: func(cfg *Config) { cfg.LogsExporter = "sumologic/" }, wantErr: true},
+		{name: "no rules", modify: func(cfg *Config) { cfg.Rules = nil }, wantErr: true},
+		{name: "no name", modify: func(cfg *Config) { cfg.Rules[0].Name = "" }, wantErr: true},
+		{name: "duplicate name", modify: func(cfg *Config) { cfg.Rules = append(cfg.Rules, validRule()) }, wantErr: true},
+		{name: "no metric", modify: func(cfg *Config) { cfg.Rules[0].Metric = "" }, wantErr: true},
+		{name: "no threshold", modify: func(cfg *Config) { cfg.Rules[0].Above = nil }, wantErr: true},
+		{name: "both thresholds", modify: func(cfg *Config) { cfg.Rules[0].Below = float64Ptr(0.1) }, wantErr: true},
+		{name: "negative period", modify: func(cfg *Config) { cfg.Rules[0].For = -time.Second }, wantErr: true},
+		{name: "unknown severity", modify: func(cfg *Config) { cfg.Rules[0].Severity = "critical" }, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.LogsExporter = "sumologic/logs"
+			cfg.Rules = []RuleConfig{validRule()}
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thresholdeventsprocessor
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/attrhash"
+)
+
+const (
+	stateTriggered = "triggered"
+	stateResolved  = "resolved"
+)
+
+var severityNumbers = map[string]pdata.SeverityNumber{
+	"trace": pdata.SeverityNumberTRACE,
+	"debug": pdata.SeverityNumberDEBUG,
+	"info":  pdata.SeverityNumberINFO,
+	"warn":  pdata.SeverityNumberWARN,
+	"error": pdata.SeverityNumberERROR,
+	"fatal": pdata.SeverityNumberFATAL,
+}
+
+// thresholdEventsProcessor checks the metric values against the thresholds, sends the events
+// to the logs exporter and passes the metrics through
+type thresholdEventsProcessor struct {
+	logger         *zap.Logger
+	nextConsumer   consumer.Metrics
+	exporterID     config.ComponentID
+	logsExporter   component.LogsExporter
+	rules          map[string][]*rule
+	resolvedEvents bool
+	now            func() time.Time
+
+	mu     sync.Mutex
+	series map[uint64]*series
+}
+
+// rule is the compiled RuleConfig
+type rule struct {
+	index     int
+	name      string
+	metric    string
+	above     bool
+	threshold float64
+	period    time.Duration
+	severity  pdata.SeverityNumber
+	message   string
+}
+
+// series holds the state of the values of a metric with the same resource and data point attributes
+type series struct {
+	// since is the time of the first value of the current threshold crossing
+	since pdata.Timestamp
+	// triggered is set when the event has been sent for the current threshold crossing
+	triggered bool
+}
+
+var _ component.MetricsProcessor = (*thresholdEventsProcessor)(nil)
+
+func newThresholdEventsProcessor(logger *zap.Logger, cfg *Config, nextConsumer consumer.Metrics) (*thresholdEventsProcessor, error) {
+	exporterID, err := config.NewIDFromString(cfg.LogsExporter)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &thresholdEventsProcessor{
+		logger:         logger,
+		nextConsumer:   nextConsumer,
+		exporterID:     exporterID,
+		rules:          map[string][]*rule{},
+		resolvedEvents: cfg.ResolvedEvents,
+		now:            time.Now,
+		series:         map[uint64]*series{},
+	}
+
+	for i, ruleCfg := range cfg.Rules {
+		r := &rule{
+			index:   i,
+			name:    ruleCfg.Name,
+			metric:  ruleCfg.Metric,
+			period:  ruleCfg.For,
+			message: ruleCfg.Message,
+		}
+		if ruleCfg.Above != nil {
+			r.above, r.threshold = true, *ruleCfg.Above
+		} else {
+			r.threshold = *ruleCfg.Below
+		}
+		severity := ruleCfg.Severity
+		if severity == "" {
+			severity = defaultSeverity
+		}
+		r.severity = severityNumbers[strings.ToLower(severity)]
+		p.rules[r.metric] = append(p.rules[r.metric], r)
+	}
+	return p, nil
+}
+
+// Start finds the logs exporter
+func (p *thresholdEventsProcessor) Start(_ context.Context, host component.Host) error {
+	exporter, ok := host.GetExporters()[config.LogsDataType][p.exporterID]
+	if !ok {
+		return fmt.Errorf("logs exporter %s not found, it needs to be used in a logs pipeline", p.exporterID)
+	}
+	logsExporter, ok := exporter.(component.LogsExporter)
+	if !ok {
+		return fmt.Errorf("exporter %s is not a logs exporter", p.exporterID)
+	}
+	p.logsExporter = logsExporter
+	return nil
+}
+
+// Shutdown does nothing, the events are sent as soon as they are created
+func (p *thresholdEventsProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// Capabilities returns the consumer capabilities
+func (p *thresholdEventsProcessor) Capabilities() consumer.Capabilities {
+	return processorCapabilities
+}
+
+// ConsumeMetrics checks the data points of the metrics with rules, sends the events and passes the metrics through
+func (p *thresholdEventsProcessor) ConsumeMetrics(ctx context.Context, md pdata.Metrics) error {
+	events := pdata.NewLogs()
+
+	p.mu.Lock()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		resource := rms.At(i).Resource().Attributes()
+		resourceKey := attrhash.Attributes(fnv.New64a(), resource)
+		// the events of the resource are added to the same resource logs, created with the first one
+		var records pdata.LogSlice
+		hasRecords := false
+		ilms := rms.At(i).InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				rules, ok := p.rules[metric.Name()]
+				if !ok {
+					continue
+				}
+				forEachDataPoint(metric, func(attributes pdata.AttributeMap, timestamp pdata.Timestamp, value float64) {
+					if timestamp == 0 {
+						timestamp = pdata.TimestampFromTime(p.now())
+					}
+					for _, r := range rules {
+						state, ok := p.check(r, resourceKey, attributes, timestamp, value)
+						if !ok {
+							continue
+						}
+						if !hasRecords {
+							rl := events.ResourceLogs().AppendEmpty()
+							resource.CopyTo(rl.Resource().Attributes())
+							records = rl.InstrumentationLibraryLogs().AppendEmpty().Logs()
+							hasRecords = true
+						}
+						r.appendEvent(records, state, attributes, timestamp, value)
+					}
+				})
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	p.send(ctx, events)
+	return p.nextConsumer.ConsumeMetrics(ctx, md)
+}
+
+// check updates the state of the series with the value and returns the state of the event to send, if any:
+// triggered when the threshold has been crossed for the period of the rule or, with the resolved events,
+// resolved when the value got back within the threshold
+func (p *thresholdEventsProcessor) check(
+	r *rule,
+	resourceKey uint64,
+	attributes pdata.AttributeMap,
+	timestamp pdata.Timestamp,
+	value float64,
+) (string, bool) {
+	h := fnv.New64a()
+	attrhash.WriteUint64(h, uint64(r.index))
+	attrhash.WriteUint64(h, resourceKey)
+	key := attrhash.Attributes(h, attributes)
+
+	crossed := value > r.threshold
+	if !r.above {
+		crossed = value < r.threshold
+	}
+
+	s, ok := p.series[key]
+	if !crossed {
+		if !ok {
+			return "", false
+		}
+		delete(p.series, key)
+		return stateResolved, s.triggered && p.resolvedEvents
+	}
+
+	if !ok {
+		s = &series{since: timestamp}
+		p.series[key] = s
+	}
+	if !s.triggered && timestamp.AsTime().Sub(s.since.AsTime()) >= r.period {
+		s.triggered = true
+		return stateTriggered, true
+	}
+	return "", false
+}
+
+// appendEvent adds the log record of the event
+func (r *rule) appendEvent(records pdata.LogSlice, state string, attributes pdata.AttributeMap, timestamp pdata.Timestamp, value float64) {
+	lr := records.AppendEmpty()
+	lr.SetTimestamp(timestamp)
+	lr.SetName(r.name)
+
+	severity := r.severity
+	if state == stateResolved {
+		severity = pdata.SeverityNumberINFO
+	}
+	lr.SetSeverityNumber(severity)
+	lr.SetSeverityText(strings.TrimPrefix(severity.String(), "SEVERITY_NUMBER_"))
+
+	direction := "above"
+	if !r.above {
+		direction = "below"
+	}
+	switch {
+	case r.message != "" && state == stateTriggered:
+		lr.Body().SetStringVal(r.message)
+	case state == stateTriggered:
+		lr.Body().SetStringVal(fmt.Sprintf("%s: %s is %g, %s the threshold of %g", r.name, r.metric, value, direction, r.threshold))
+	default:
+		lr.Body().SetStringVal(fmt.Sprintf("%s resolved: %s is %g, no longer %s the threshold of %g", r.name, r.metric, value, direction, r.threshold))
+	}
+
+	eventAttributes := lr.Attributes()
+	attributes.CopyTo(eventAttributes)
+	eventAttributes.UpsertString("threshold.rule", r.name)
+	eventAttributes.UpsertString("threshold.state", state)
+	eventAttributes.UpsertDouble("threshold.value", r.threshold)
+	eventAttributes.UpsertString("metric.name", r.metric)
+	eventAttributes.UpsertDouble("metric.value", value)
+}
+
+// send sends the events to the logs exporter, the errors are logged only, not to fail the metrics
+func (p *thresholdEventsProcessor) send(ctx context.Context, events pdata.Logs) {
+	if events.LogRecordCount() == 0 {
+		return
+	}
+	if err := p.logsExporter.ConsumeLogs(ctx, events); err != nil {
+		p.logger.Error("Failed to send the threshold events", zap.Error(err))
+	}
+}
+
+// forEachDataPoint calls the function with the numeric values of the gauge and sum data points;
+// the other metric types are skipped
+func forEachDataPoint(metric pdata.Metric, f func(attributes pdata.AttributeMap, timestamp pdata.Timestamp, value float64)) {
+	var dps pdata.NumberDataPointSlice
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps = metric.Gauge().DataPoints()
+	case pdata.MetricDataTypeSum:
+		dps = metric.Sum().DataPoints()
+	default:
+		return
+	}
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		value := dp.DoubleVal()
+		if dp.Type() == pdata.MetricValueTypeInt {
+			value = float64(dp.IntVal())
+		}
+		f(dp.Attributes(), dp.Timestamp(), value)
+	}
+}
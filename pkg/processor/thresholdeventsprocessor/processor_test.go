@@ -0,0 +1,281 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thresholdeventsprocessor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// testExporter is a logs exporter collecting the events
+type testExporter struct {
+	consumertest.LogsSink
+}
+
+func (e *testExporter) Start(context.Context, component.Host) error { return nil }
+func (e *testExporter) Shutdown(context.Context) error              { return nil }
+
+// testHost provides the logs exporters
+type testHost struct {
+	component.Host
+	exporters map[config.ComponentID]component.Exporter
+}
+
+func (h testHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return map[config.DataType]map[config.ComponentID]component.Exporter{config.LogsDataType: h.exporters}
+}
+
+var baseTime = time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+type testPoint struct {
+	host       string
+	mountpoint string
+	offset     time.Duration
+	value      float64
+}
+
+// newTestMetrics returns the system.filesystem.utilization gauge with the points grouped by the host resource
+func newTestMetrics(points ...testPoint) pdata.Metrics {
+	md := pdata.NewMetrics()
+	for _, point := range points {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().InsertString("host.name", point.host)
+		metric := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("system.filesystem.utilization")
+		metric.SetDataType(pdata.MetricDataTypeGauge)
+		dp := metric.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(pdata.TimestampFromTime(baseTime.Add(point.offset)))
+		dp.SetDoubleVal(point.value)
+		dp.Attributes().InsertString("mountpoint", point.mountpoint)
+	}
+	return md
+}
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) (*thresholdEventsProcessor, *testExporter, *consumertest.MetricsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LogsExporter = "sumologic/logs"
+	cfg.Rules = []RuleConfig{{
+		Name:   "disk_nearly_full",
+		Metric: "system.filesystem.utilization",
+		Above:  float64Ptr(0.9),
+		For:    5 * time.Minute,
+	}}
+	modify(cfg)
+	require.NoError(t, cfg.Validate())
+
+	metricsSink := &consumertest.MetricsSink{}
+	p, err := newThresholdEventsProcessor(zap.NewNop(), cfg, metricsSink)
+	require.NoError(t, err)
+
+	exporter := &testExporter{}
+	host := testHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.ComponentID]component.Exporter{
+			config.NewIDWithName("sumologic", "logs"): exporter,
+		},
+	}
+	require.NoError(t, p.Start(context.Background(), host))
+	return p, exporter, metricsSink
+}
+
+// collectEvents returns the event records with the host of their resource, if any, set as the host.name attribute
+func collectEvents(exporter *testExporter) []pdata.LogRecord {
+	var events []pdata.LogRecord
+	for _, ld := range exporter.AllLogs() {
+		rls := ld.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			host, hasHost := rls.At(i).Resource().Attributes().Get("host.name")
+			logs := rls.At(i).InstrumentationLibraryLogs().At(0).Logs()
+			for j := 0; j < logs.Len(); j++ {
+				if hasHost {
+					logs.At(j).Attributes().Insert("host.name", host)
+				}
+				events = append(events, logs.At(j))
+			}
+		}
+	}
+	return events
+}
+
+func TestSustainedThreshold(t *testing.T) {
+	p, exporter, metricsSink := newTestProcessor(t, func(cfg *Config) {})
+	ctx := context.Background()
+
+	require.NoError(t, p.ConsumeMetrics(ctx, newTestMetrics(
+		testPoint{host: "edge-1", mountpoint: "/", offset: 0, value: 0.95},
+		testPoint{host: "edge-1", mountpoint: "/data", offset: 0, value: 0.5},
+		testPoint{host: "edge-2", mountpoint: "/", offset: 0, value: 0.92},
+	)))
+	require.NoError(t, p.ConsumeMetrics(ctx, newTestMetrics(
+		testPoint{host: "edge-1", mountpoint: "/", offset: 3 * time.Minute, value: 0.96},
+		testPoint{host: "edge-2", mountpoint: "/", offset: 3 * time.Minute, value: 0.85},
+	)))
+	assert.Empty(t, exporter.AllLogs(), "the threshold has not been crossed for the period yet")
+
+	require.NoError(t, p.ConsumeMetrics(ctx, newTestMetrics(
+		testPoint{host: "edge-1", mountpoint: "/", offset: 5 * time.Minute, value: 0.97},
+		testPoint{host: "edge-2", mountpoint: "/", offset: 5 * time.Minute, value: 0.93},
+	)))
+	require.NoError(t, p.ConsumeMetrics(ctx, newTestMetrics(
+		testPoint{host: "edge-1", mountpoint: "/", offset: 6 * time.Minute, value: 0.98},
+	)))
+	// the metrics are passed through
+	assert.Equal(t, 8, metricsSink.DataPointCount())
+
+	events := collectEvents(exporter)
+	require.Len(t, events, 1, "the event is sent once per threshold crossing")
+	event := events[0]
+	assert.Equal(t, pdata.TimestampFromTime(baseTime.Add(5*time.Minute)), event.Timestamp())
+	assert.Equal(t, pdata.SeverityNumberWARN, event.SeverityNumber())
+	assert.Equal(t, "WARN", event.SeverityText())
+	assert.Equal(t, "disk_nearly_full", event.Name())
+	assert.Equal(t, "disk_nearly_full: system.filesystem.utilization is 0.97, above the threshold of 0.9",
+		event.Body().StringVal())
+	assert.Equal(t, map[string]string{
+		"host.name":       "edge-1",
+		"mountpoint":      "/",
+		"threshold.rule":  "disk_nearly_full",
+		"threshold.state": "triggered",
+		"threshold.value": "0.9",
+		"metric.name":     "system.filesystem.utilization",
+		"metric.value":    "0.97",
+	}, attributesToMap(event.Attributes()))
+}
+
+func TestResolvedEvents(t *testing.T) {
+	testcases := []struct {
+		name           string
+		resolvedEvents bool
+		expectedStates []string
+	}{
+		{name: "resolved events", resolvedEvents: true, expectedStates: []string{"triggered", "resolved", "triggered"}},
+		{name: "no resolved events", resolvedEvents: false, expectedStates: []string{"triggered", "triggered"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, exporter, _ := newTestProcessor(t, func(cfg *Config) {
+				cfg.Rules[0].For = 0
+				cfg.ResolvedEvents = tc.resolvedEvents
+			})
+
+			for i, value := range []float64{0.95, 0.96, 0.5, 0.4, 0.99} {
+				require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics(
+					testPoint{host: "edge-1", mountpoint: "/", offset: time.Duration(i) * time.Minute, value: value},
+				)))
+			}
+
+			var states []string
+			for _, event := range collectEvents(exporter) {
+				state, _ := event.Attributes().Get("threshold.state")
+				states = append(states, state.StringVal())
+			}
+			assert.Equal(t, tc.expectedStates, states)
+
+			if tc.resolvedEvents {
+				resolved := collectEvents(exporter)[1]
+				assert.Equal(t, pdata.SeverityNumberINFO, resolved.SeverityNumber())
+				assert.Equal(t, "disk_nearly_full resolved: system.filesystem.utilization is 0.5, no longer above the threshold of 0.9",
+					resolved.Body().StringVal())
+			}
+		})
+	}
+}
+
+func TestBelowThresholdAndMessage(t *testing.T) {
+	p, exporter, _ := newTestProcessor(t, func(cfg *Config) {
+		cfg.Rules = []RuleConfig{{
+			Name:     "low_memory",
+			Metric:   "system.memory.available",
+			Below:    float64Ptr(100),
+			Severity: "error",
+			Message:  "Memory nearly exhausted",
+		}}
+	})
+
+	md := pdata.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	metric := metrics.AppendEmpty()
+	metric.SetName("system.memory.available")
+	metric.SetDataType(pdata.MetricDataTypeSum)
+	metric.Sum().DataPoints().AppendEmpty().SetIntVal(150)
+	metric.Sum().DataPoints().AppendEmpty().SetIntVal(50)
+	// the other metrics are not checked
+	other := metrics.AppendEmpty()
+	other.SetName("system.memory.usage")
+	other.SetDataType(pdata.MetricDataTypeGauge)
+	other.Gauge().DataPoints().AppendEmpty().SetIntVal(10)
+	p.now = func() time.Time { return baseTime }
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), md))
+
+	events := collectEvents(exporter)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Memory nearly exhausted", events[0].Body().StringVal())
+	assert.Equal(t, pdata.SeverityNumberERROR, events[0].SeverityNumber())
+	assert.Equal(t, pdata.TimestampFromTime(baseTime), events[0].Timestamp(), "the points without timestamps use the current time")
+	value, _ := events[0].Attributes().Get("metric.value")
+	assert.Equal(t, 50.0, value.DoubleVal())
+}
+
+func TestExporterError(t *testing.T) {
+	p, _, metricsSink := newTestProcessor(t, func(cfg *Config) { cfg.Rules[0].For = 0 })
+	p.logsExporter = &failingExporter{}
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), newTestMetrics(
+		testPoint{host: "edge-1", mountpoint: "/", value: 0.95},
+	)), "the events errors do not fail the metrics")
+	assert.Equal(t, 1, metricsSink.DataPointCount())
+}
+
+type failingExporter struct {
+	testExporter
+}
+
+func (e *failingExporter) ConsumeLogs(context.Context, pdata.Logs) error {
+	return errors.New("backend unreachable")
+}
+
+func TestMissingExporter(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LogsExporter = "sumologic/logs"
+	cfg.Rules = []RuleConfig{{Name: "disk", Metric: "system.filesystem.utilization", Above: float64Ptr(0.9)}}
+	p, err := newThresholdEventsProcessor(zap.NewNop(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	host := testHost{Host: componenttest.NewNopHost(), exporters: map[config.ComponentID]component.Exporter{}}
+	assert.Error(t, p.Start(context.Background(), host))
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the timestamp extraction.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Sources are the places the timestamp is taken from, tried in order until one of them is parsed
+	Sources []SourceConfig `mapstructure:"sources"`
+	// Formats are the formats of the timestamp, tried in order. The common formats are tried when it is empty.
+	Formats []FormatConfig `mapstructure:"formats"`
+	// Location is the time zone of the timestamps without one, e.g. America/New_York, Local or UTC
+	Location string `mapstructure:"location"`
+	// Overwrite enables replacing the timestamps already set on the records
+	Overwrite bool `mapstructure:"overwrite"`
+	// RemoveSource enables removing the attribute or the body field the timestamp has been parsed from
+	RemoveSource bool `mapstructure:"remove_source"`
+}
+
+// SourceConfig is the place the timestamp is taken from. Exactly one of the fields must be set.
+type SourceConfig struct {
+	// Attribute is the name of the record attribute holding the timestamp
+	Attribute string `mapstructure:"attribute"`
+	// BodyField is the key of the map body holding the timestamp
+	BodyField string `mapstructure:"body_field"`
+	// BodyRegex matches the timestamp in the string body, in the "timestamp" named group or the whole match
+	BodyRegex string `mapstructure:"body_regex"`
+}
+
+// FormatConfig is the format of the timestamp. Exactly one of the fields must be set.
+type FormatConfig struct {
+	// Strptime is the layout in the strptime notation, e.g. %Y-%m-%d %H:%M:%S
+	Strptime string `mapstructure:"strptime"`
+	// GoTime is the layout in the notation of the Go time package, e.g. 2006-01-02 15:04:05
+	GoTime string `mapstructure:"gotime"`
+	// Epoch is the unit of the Unix timestamp: s, ms, us, ns, or auto to infer it from the number of digits
+	Epoch string `mapstructure:"epoch"`
+}
+
+const (
+	defaultLocation = "UTC"
+
+	timestampGroup = "timestamp"
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Sources) == 0 {
+		return errors.New("at least one source must be configured")
+	}
+	for i, source := range cfg.Sources {
+		if err := source.validate(); err != nil {
+			return fmt.Errorf("invalid source %d: %w", i, err)
+		}
+	}
+	for i, format := range cfg.Formats {
+		if err := format.validate(); err != nil {
+			return fmt.Errorf("invalid format %d: %w", i, err)
+		}
+	}
+	if _, err := time.LoadLocation(cfg.Location); err != nil {
+		return fmt.Errorf("invalid location %q: %w", cfg.Location, err)
+	}
+	return nil
+}
+
+func (s SourceConfig) validate() error {
+	set := 0
+	for _, field := range []string{s.Attribute, s.BodyField, s.BodyRegex} {
+		if field != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of attribute, body_field and body_regex must be set")
+	}
+	if s.BodyRegex != "" {
+		if _, err := regexp.Compile(s.BodyRegex); err != nil {
+			return fmt.Errorf("invalid body_regex %q: %w", s.BodyRegex, err)
+		}
+	}
+	return nil
+}
+
+func (f FormatConfig) validate() error {
+	set := 0
+	for _, field := range []string{f.Strptime, f.GoTime, f.Epoch} {
+		if field != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of strptime, gotime and epoch must be set")
+	}
+	if f.Strptime != "" {
+		if _, err := strptimeToGoLayout(f.Strptime); err != nil {
+			return err
+		}
+	}
+	if f.Epoch != "" {
+		if _, ok := epochUnits[f.Epoch]; !ok {
+			return fmt.Errorf("invalid epoch unit %q, must be one of: s, ms, us, ns, auto", f.Epoch)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeParser parses the timestamp, using the location when the timestamp has no time zone
+type timeParser func(value string, location *time.Location, now time.Time) (time.Time, error)
+
+// epochAuto infers the unit of the Unix timestamp from the number of its digits
+const epochAuto = "auto"
+
+var epochUnits = map[string]time.Duration{
+	"s":       time.Second,
+	"ms":      time.Millisecond,
+	"us":      time.Microsecond,
+	"ns":      time.Nanosecond,
+	epochAuto: 0,
+}
+
+// defaultFormats are the common formats tried when none are configured
+var defaultFormats = []FormatConfig{
+	{GoTime: time.RFC3339Nano},
+	{GoTime: "2006-01-02T15:04:05"},
+	{GoTime: "2006-01-02 15:04:05Z07:00"},
+	{GoTime: "2006-01-02 15:04:05 -0700"},
+	{GoTime: "2006-01-02 15:04:05"},
+	{GoTime: "2006-01-02 15:04:05,000"},
+	{GoTime: "02/Jan/2006:15:04:05 -0700"},
+	{GoTime: time.RFC1123Z},
+	{GoTime: time.RFC1123},
+	{GoTime: time.ANSIC},
+	{GoTime: time.Stamp},
+	{Epoch: epochAuto},
+}
+
+// strptimeDirectives maps the strptime directives to the Go layout elements
+var strptimeDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'H': "15",
+	'I': "03",
+	'l': "3",
+	'M': "04",
+	'S': "05",
+	'f': "999999999",
+	'L': "000",
+	'p': "PM",
+	'z': "-0700",
+	'Z': "MST",
+	'j': "002",
+	'T': "15:04:05",
+	'D': "01/02/06",
+	'F': "2006-01-02",
+	'R': "15:04",
+	'%': "%",
+}
+
+// strptimeToGoLayout converts the strptime layout to the Go time layout
+func strptimeToGoLayout(layout string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			sb.WriteByte(layout[i])
+			continue
+		}
+		if i+1 == len(layout) {
+			return "", fmt.Errorf("strptime layout %q ends with a single %%", layout)
+		}
+		i++
+		element, ok := strptimeDirectives[layout[i]]
+		if !ok {
+			return "", fmt.Errorf("unsupported strptime directive %%%c in %q", layout[i], layout)
+		}
+		sb.WriteString(element)
+	}
+	return sb.String(), nil
+}
+
+func newTimeParser(format FormatConfig) (timeParser, error) {
+	switch {
+	case format.Strptime != "":
+		layout, err := strptimeToGoLayout(format.Strptime)
+		if err != nil {
+			return nil, err
+		}
+		return newLayoutParser(layout), nil
+	case format.GoTime != "":
+		return newLayoutParser(format.GoTime), nil
+	default:
+		unit, ok := epochUnits[format.Epoch]
+		if !ok {
+			return nil, fmt.Errorf("invalid epoch unit %q", format.Epoch)
+		}
+		return func(value string, _ *time.Location, _ time.Time) (time.Time, error) {
+			return parseEpoch(value, unit)
+		}, nil
+	}
+}
+
+// newLayoutParser parses the timestamps with the layout. The timestamps without the year,
+// e.g. the syslog ones, are assumed to be from the last 12 months.
+func newLayoutParser(layout string) timeParser {
+	return func(value string, location *time.Location, now time.Time) (time.Time, error) {
+		t, err := time.ParseInLocation(layout, value, location)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if t.Year() == 0 {
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+		}
+		return t, nil
+	}
+}
+
+// parseEpoch parses the Unix timestamp, optionally with a fractional part, in the unit
+func parseEpoch(value string, unit time.Duration) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	whole, fraction := value, ""
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		whole, fraction = value[:i], value[i+1:]
+	}
+	if unit == 0 {
+		unit = inferEpochUnit(whole)
+	}
+
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid epoch timestamp %q", value)
+	}
+	var f float64
+	if fraction != "" {
+		if f, err = strconv.ParseFloat("0."+fraction, 64); err != nil {
+			return time.Time{}, fmt.Errorf("invalid epoch timestamp %q", value)
+		}
+	}
+	return time.Unix(0, w*int64(unit)+int64(f*float64(unit))).UTC(), nil
+}
+
+// inferEpochUnit infers the unit from the number of digits of the current timestamps in that unit
+func inferEpochUnit(whole string) time.Duration {
+	switch digits := len(strings.TrimLeft(whole, "-")); {
+	case digits <= 10:
+		return time.Second
+	case digits <= 13:
+		return time.Millisecond
+	case digits <= 16:
+		return time.Microsecond
+	default:
+		return time.Nanosecond
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrptimeToGoLayout(t *testing.T) {
+	layout, err := strptimeToGoLayout("%Y-%m-%dT%H:%M:%S.%f%z")
+	require.NoError(t, err)
+	assert.Equal(t, "2006-01-02T15:04:05.999999999-0700", layout)
+
+	layout, err = strptimeToGoLayout("%a %b %e %T %Z %Y 100%%")
+	require.NoError(t, err)
+	assert.Equal(t, "Mon Jan _2 15:04:05 MST 2006 100%", layout)
+}
+
+func TestParsers(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	testcases := []struct {
+		name     string
+		format   FormatConfig
+		value    string
+		expected time.Time
+	}{
+		{
+			name:     "strptime with zone",
+			format:   FormatConfig{Strptime: "%d/%b/%Y:%H:%M:%S %z"},
+			value:    "01/Sep/2021:10:00:00 +0200",
+			expected: time.Date(2021, 9, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "strptime in location",
+			format:   FormatConfig{Strptime: "%Y-%m-%d %H:%M:%S.%L"},
+			value:    "2021-09-01 08:00:00.250",
+			expected: time.Date(2021, 9, 1, 12, 0, 0, 250000000, time.UTC),
+		},
+		{
+			name:     "gotime",
+			format:   FormatConfig{GoTime: time.RFC3339Nano},
+			value:    "2021-09-01T12:00:00.123456789Z",
+			expected: time.Date(2021, 9, 1, 12, 0, 0, 123456789, time.UTC),
+		},
+		{
+			name:     "year from the current one",
+			format:   FormatConfig{GoTime: time.Stamp},
+			value:    "Aug 31 23:00:00",
+			expected: time.Date(2021, 9, 1, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "year from the previous one",
+			format:   FormatConfig{GoTime: time.Stamp},
+			value:    "Dec 31 19:00:00",
+			expected: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "epoch seconds with fraction",
+			format:   FormatConfig{Epoch: "s"},
+			value:    "1630497600.5",
+			expected: time.Date(2021, 9, 1, 12, 0, 0, 500000000, time.UTC),
+		},
+		{
+			name:     "epoch milliseconds",
+			format:   FormatConfig{Epoch: "ms"},
+			value:    "1630497600123",
+			expected: time.Date(2021, 9, 1, 12, 0, 0, 123000000, time.UTC),
+		},
+		{
+			name:     "epoch auto microseconds",
+			format:   FormatConfig{Epoch: epochAuto},
+			value:    "1630497600123456",
+			expected: time.Date(2021, 9, 1, 12, 0, 0, 123456000, time.UTC),
+		},
+		{
+			name:     "epoch auto nanoseconds",
+			format:   FormatConfig{Epoch: epochAuto},
+			value:    "1630497600123456789",
+			expected: time.Date(2021, 9, 1, 12, 0, 0, 123456789, time.UTC),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, err := newTimeParser(tc.format)
+			require.NoError(t, err)
+			parsed, err := parser(tc.value, newYork, now)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, parsed.UTC())
+		})
+	}
+}
+
+func TestParseEpochInvalid(t *testing.T) {
+	_, err := parseEpoch("yesterday", time.Second)
+	assert.Error(t, err)
+	_, err = parseEpoch("1630497600.x", time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// timestampProcessor sets the timestamps of the log records to the ones parsed from their attributes or bodies
+type timestampProcessor struct {
+	logger       *zap.Logger
+	sources      []*source
+	parsers      []timeParser
+	location     *time.Location
+	overwrite    bool
+	removeSource bool
+	now          func() time.Time
+}
+
+// source extracts the timestamp from the record
+type source struct {
+	attribute string
+	bodyField string
+	bodyRegex *regexp.Regexp
+	// group is the index of the regex submatch holding the timestamp
+	group int
+}
+
+func newTimestampProcessor(logger *zap.Logger, cfg *Config) (*timestampProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(cfg.Location)
+	if err != nil {
+		return nil, err
+	}
+	tp := &timestampProcessor{
+		logger:       logger,
+		location:     location,
+		overwrite:    cfg.Overwrite,
+		removeSource: cfg.RemoveSource,
+		now:          time.Now,
+	}
+
+	for _, sourceCfg := range cfg.Sources {
+		s := &source{attribute: sourceCfg.Attribute, bodyField: sourceCfg.BodyField}
+		if sourceCfg.BodyRegex != "" {
+			s.bodyRegex = regexp.MustCompile(sourceCfg.BodyRegex)
+			for i, name := range s.bodyRegex.SubexpNames() {
+				if name == timestampGroup {
+					s.group = i
+				}
+			}
+		}
+		tp.sources = append(tp.sources, s)
+	}
+
+	formats := cfg.Formats
+	if len(formats) == 0 {
+		formats = defaultFormats
+	}
+	for _, format := range formats {
+		parser, err := newTimeParser(format)
+		if err != nil {
+			return nil, err
+		}
+		tp.parsers = append(tp.parsers, parser)
+	}
+	return tp, nil
+}
+
+// ProcessLogs sets the timestamps of the log records
+func (tp *timestampProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	now := tp.now()
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				tp.processRecord(logs.At(k), now)
+			}
+		}
+	}
+	return ld, nil
+}
+
+// processRecord sets the timestamp parsed from the first source holding a valid one;
+// the record is left unchanged when none of them does
+func (tp *timestampProcessor) processRecord(lr pdata.LogRecord, now time.Time) {
+	if !tp.overwrite && lr.Timestamp() != 0 {
+		return
+	}
+
+	for _, s := range tp.sources {
+		value, ok := s.extract(lr)
+		if !ok {
+			continue
+		}
+		t, ok := tp.parse(value, now)
+		if !ok {
+			continue
+		}
+		lr.SetTimestamp(pdata.TimestampFromTime(t))
+		if tp.removeSource {
+			s.remove(lr)
+		}
+		return
+	}
+	tp.logger.Debug("No timestamp parsed from the log record")
+}
+
+// parse tries the formats in order
+func (tp *timestampProcessor) parse(value string, now time.Time) (time.Time, bool) {
+	for _, parser := range tp.parsers {
+		if t, err := parser(value, tp.location, now); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// extract returns the timestamp from the attribute, the map body field or the regex match of the string body
+func (s *source) extract(lr pdata.LogRecord) (string, bool) {
+	switch {
+	case s.attribute != "":
+		return attributeString(lr.Attributes(), s.attribute)
+	case s.bodyField != "":
+		if lr.Body().Type() != pdata.AttributeValueTypeMap {
+			return "", false
+		}
+		return attributeString(lr.Body().MapVal(), s.bodyField)
+	default:
+		if lr.Body().Type() != pdata.AttributeValueTypeString {
+			return "", false
+		}
+		match := s.bodyRegex.FindStringSubmatch(lr.Body().StringVal())
+		if match == nil {
+			return "", false
+		}
+		return match[s.group], true
+	}
+}
+
+// remove removes the attribute or the body field, the body is never changed when matched with a regex
+func (s *source) remove(lr pdata.LogRecord) {
+	switch {
+	case s.attribute != "":
+		lr.Attributes().Delete(s.attribute)
+	case s.bodyField != "":
+		lr.Body().MapVal().Delete(s.bodyField)
+	}
+}
+
+// attributeString returns the string or the numeric value of the attribute as a string
+func attributeString(attributes pdata.AttributeMap, name string) (string, bool) {
+	value, ok := attributes.Get(name)
+	if !ok {
+		return "", false
+	}
+	switch value.Type() {
+	case pdata.AttributeValueTypeString, pdata.AttributeValueTypeInt, pdata.AttributeValueTypeDouble:
+		return pdata.AttributeValueToString(value), true
+	default:
+		return "", false
+	}
+}
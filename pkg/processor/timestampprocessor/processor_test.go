@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) *timestampProcessor {
+	cfg := createDefaultConfig().(*Config)
+	modify(cfg)
+	tp, err := newTimestampProcessor(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	return tp
+}
+
+func newTestLogs(records ...func(lr pdata.LogRecord)) (pdata.Logs, pdata.LogSlice) {
+	ld := pdata.NewLogs()
+	logs := ld.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	for _, fill := range records {
+		fill(logs.AppendEmpty())
+	}
+	return ld, logs
+}
+
+func timestampOf(lr pdata.LogRecord) time.Time {
+	return lr.Timestamp().AsTime().UTC()
+}
+
+func TestProcessLogsSources(t *testing.T) {
+	tp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Sources = []SourceConfig{
+			{Attribute: "time"},
+			{BodyField: "ts"},
+			{BodyRegex: `^\[(?P<timestamp>[^\]]+)\]`},
+		}
+	})
+	received := pdata.TimestampFromTime(time.Date(2021, 9, 2, 0, 0, 0, 0, time.UTC))
+
+	ld, logs := newTestLogs(
+		func(lr pdata.LogRecord) {
+			lr.SetTimestamp(received)
+			lr.Attributes().InsertString("time", "2021-09-01T12:00:00Z")
+			lr.Body().SetStringVal("[2021-09-01 13:00:00] ignored")
+		},
+		func(lr pdata.LogRecord) {
+			lr.SetTimestamp(received)
+			lr.Attributes().InsertString("time", "invalid")
+			pdata.NewAttributeValueMap().CopyTo(lr.Body())
+			lr.Body().MapVal().InsertInt("ts", 1630497600)
+		},
+		func(lr pdata.LogRecord) {
+			lr.Body().SetStringVal("[01/Sep/2021:14:00:00 +0000] GET /index.html")
+		},
+		func(lr pdata.LogRecord) {
+			lr.SetTimestamp(received)
+			lr.Body().SetStringVal("no timestamp")
+		},
+	)
+
+	_, err := tp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC), timestampOf(logs.At(0)))
+	assert.Equal(t, time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC), timestampOf(logs.At(1)))
+	assert.Equal(t, time.Date(2021, 9, 1, 14, 0, 0, 0, time.UTC), timestampOf(logs.At(2)))
+	assert.Equal(t, received, logs.At(3).Timestamp())
+
+	// the sources are kept by default
+	_, ok := logs.At(0).Attributes().Get("time")
+	assert.True(t, ok)
+}
+
+func TestProcessLogsWithoutOverwrite(t *testing.T) {
+	tp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Sources = []SourceConfig{{Attribute: "time"}}
+		cfg.Overwrite = false
+	})
+	received := pdata.TimestampFromTime(time.Date(2021, 9, 2, 0, 0, 0, 0, time.UTC))
+
+	ld, logs := newTestLogs(
+		func(lr pdata.LogRecord) {
+			lr.SetTimestamp(received)
+			lr.Attributes().InsertString("time", "2021-09-01T12:00:00Z")
+		},
+		func(lr pdata.LogRecord) {
+			lr.Attributes().InsertString("time", "2021-09-01T12:00:00Z")
+		},
+	)
+
+	_, err := tp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, received, logs.At(0).Timestamp())
+	assert.Equal(t, time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC), timestampOf(logs.At(1)))
+}
+
+func TestProcessLogsRemoveSource(t *testing.T) {
+	tp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Sources = []SourceConfig{{Attribute: "time"}, {BodyField: "ts"}}
+		cfg.Formats = []FormatConfig{{Strptime: "%Y-%m-%d %H:%M:%S"}}
+		cfg.Location = "Europe/Warsaw"
+		cfg.RemoveSource = true
+	})
+
+	ld, logs := newTestLogs(
+		func(lr pdata.LogRecord) {
+			lr.Attributes().InsertString("time", "2021-09-01 14:00:00")
+		},
+		func(lr pdata.LogRecord) {
+			pdata.NewAttributeValueMap().CopyTo(lr.Body())
+			lr.Body().MapVal().InsertString("ts", "2021-09-01 15:00:00")
+			lr.Body().MapVal().InsertString("message", "started")
+		},
+	)
+
+	_, err := tp.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC), timestampOf(logs.At(0)))
+	assert.Equal(t, 0, logs.At(0).Attributes().Len())
+	assert.Equal(t, time.Date(2021, 9, 1, 13, 0, 0, 0, time.UTC), timestampOf(logs.At(1)))
+	assert.Equal(t, 1, logs.At(1).Body().MapVal().Len())
+}
+
+func TestDefaultFormats(t *testing.T) {
+	tp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Sources = []SourceConfig{{Attribute: "time"}}
+	})
+	tp.now = func() time.Time { return time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC) }
+	expected := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, value := range []string{
+		"2021-09-01T12:00:00Z",
+		"2021-09-01T14:00:00+02:00",
+		"2021-09-01T12:00:00",
+		"2021-09-01 12:00:00",
+		"2021-09-01 12:00:00,000",
+		"01/Sep/2021:12:00:00 +0000",
+		"Wed, 01 Sep 2021 12:00:00 +0000",
+		"Wed Sep  1 12:00:00 2021",
+		"Sep  1 12:00:00",
+		"1630497600",
+		"1630497600000",
+	} {
+		t.Run(value, func(t *testing.T) {
+			ld, logs := newTestLogs(func(lr pdata.LogRecord) {
+				lr.Attributes().InsertString("time", value)
+			})
+			_, err := tp.ProcessLogs(context.Background(), ld)
+			require.NoError(t, err)
+			assert.Equal(t, expected, timestampOf(logs.At(0)))
+		})
+	}
+}
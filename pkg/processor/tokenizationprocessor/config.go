@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizationprocessor
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the tokenization.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Keys are the tokenization keys: the first one is the active key, and the optional second one is the previous
+	// key during a rotation, whose tokens are added next to the ones of the active key
+	Keys []KeyConfig `mapstructure:"keys"`
+	// Format is either hmac, replacing the values with the keyed HMAC-SHA256 tokens, or format_preserving,
+	// replacing the letters and digits of the values while keeping their length and the other characters
+	Format string `mapstructure:"format"`
+	// Attributes are the names of the log, span and data point attributes to tokenize
+	Attributes []string `mapstructure:"attributes"`
+	// ResourceAttributes are the names of the resource attributes to tokenize
+	ResourceAttributes []string `mapstructure:"resource_attributes"`
+	// BodyFields are the paths of the fields to tokenize in the map log bodies, with the nested fields
+	// separated with dots, e.g. user.id
+	BodyFields []string `mapstructure:"body_fields"`
+	// PreviousTokenSuffix is appended to the names of the attributes and the fields holding the tokens
+	// of the previous key
+	PreviousTokenSuffix string `mapstructure:"previous_token_suffix"`
+}
+
+// KeyConfig configures a tokenization key; exactly one of the sources must be set.
+type KeyConfig struct {
+	// ID identifies the key, it prefixes the hmac tokens
+	ID string `mapstructure:"id"`
+	// Env is the name of the environment variable holding the base64 encoded key
+	Env string `mapstructure:"env"`
+	// File is the path of the file holding the base64 encoded key
+	File string `mapstructure:"file"`
+}
+
+const (
+	formatHMAC             = "hmac"
+	formatFormatPreserving = "format_preserving"
+
+	defaultPreviousTokenSuffix = ".previous_token"
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Keys) == 0 || len(cfg.Keys) > 2 {
+		return fmt.Errorf("keys must have the active key and optionally the previous one, got %d keys", len(cfg.Keys))
+	}
+	for i, key := range cfg.Keys {
+		if key.ID == "" {
+			return fmt.Errorf("keys[%d]: id must not be empty", i)
+		}
+		if (key.Env == "") == (key.File == "") {
+			return fmt.Errorf("keys[%d]: exactly one of env and file must be set", i)
+		}
+	}
+	if len(cfg.Keys) == 2 {
+		if cfg.Keys[0].ID == cfg.Keys[1].ID {
+			return fmt.Errorf("the ids of the keys must be different, got %q twice", cfg.Keys[0].ID)
+		}
+		if cfg.PreviousTokenSuffix == "" {
+			return errors.New("previous_token_suffix must not be empty with the previous key")
+		}
+	}
+	switch cfg.Format {
+	case formatHMAC, formatFormatPreserving:
+	default:
+		return fmt.Errorf("unknown format %q, expected %s or %s", cfg.Format, formatHMAC, formatFormatPreserving)
+	}
+	if len(cfg.Attributes) == 0 && len(cfg.ResourceAttributes) == 0 && len(cfg.BodyFields) == 0 {
+		return errors.New("at least one of attributes, resource_attributes and body_fields must be set")
+	}
+	return nil
+}
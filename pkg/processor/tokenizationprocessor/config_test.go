@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizationprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "tokenization_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors[config.NewID(typeStr)],
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewID(typeStr)),
+			Keys: []KeyConfig{
+				{ID: "2021-09", Env: "TOKENIZATION_KEY_2021_09"},
+				{ID: "2021-06", File: "/etc/otelcol/tokenization-2021-06.key"},
+			},
+			Format:              formatFormatPreserving,
+			Attributes:          []string{"customer.id", "enduser.id"},
+			ResourceAttributes:  []string{"account.id"},
+			BodyFields:          []string{"customer.email"},
+			PreviousTokenSuffix: "_previous",
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "valid", modify: func(cfg *Config) {}},
+		{
+			name: "rotation",
+			modify: func(cfg *Config) {
+				cfg.Keys = append(cfg.Keys, KeyConfig{ID: "old", File: "old.key"})
+			},
+		},
+		{
+			name:    "no keys",
+			modify:  func(cfg *Config) { cfg.Keys = nil },
+			wantErr: "keys must have the active key and optionally the previous one, got 0 keys",
+		},
+		{
+			name: "three keys",
+			modify: func(cfg *Config) {
+				cfg.Keys = append(cfg.Keys, KeyConfig{ID: "b", Env: "B"}, KeyConfig{ID: "c", Env: "C"})
+			},
+			wantErr: "keys must have the active key and optionally the previous one, got 3 keys",
+		},
+		{
+			name:    "no key id",
+			modify:  func(cfg *Config) { cfg.Keys[0].ID = "" },
+			wantErr: "keys[0]: id must not be empty",
+		},
+		{
+			name:    "two key sources",
+			modify:  func(cfg *Config) { cfg.Keys[0].File = "current.key" },
+			wantErr: "keys[0]: exactly one of env and file must be set",
+		},
+		{
+			name: "no key source",
+			modify: func(cfg *Config) {
+				cfg.Keys = append(cfg.Keys, KeyConfig{ID: "old"})
+			},
+			wantErr: "keys[1]: exactly one of env and file must be set",
+		},
+		{
+			name: "same key ids",
+			modify: func(cfg *Config) {
+				cfg.Keys = append(cfg.Keys, KeyConfig{ID: "current", File: "old.key"})
+			},
+			wantErr: `the ids of the keys must be different, got "current" twice`,
+		},
+		{
+			name: "no previous token suffix",
+			modify: func(cfg *Config) {
+				cfg.Keys = append(cfg.Keys, KeyConfig{ID: "old", File: "old.key"})
+				cfg.PreviousTokenSuffix = ""
+			},
+			wantErr: "previous_token_suffix must not be empty with the previous key",
+		},
+		{
+			name:    "unknown format",
+			modify:  func(cfg *Config) { cfg.Format = "fpe" },
+			wantErr: `unknown format "fpe", expected hmac or format_preserving`,
+		},
+		{
+			name:    "nothing to tokenize",
+			modify:  func(cfg *Config) { cfg.Attributes = nil },
+			wantErr: "at least one of attributes, resource_attributes and body_fields must be set",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Keys = []KeyConfig{{ID: "current", Env: "CURRENT"}}
+			cfg.Attributes = []string{"customer.id"}
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizationprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configcheck"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg := createDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configcheck.ValidateConfig(cfg))
+}
+
+func TestCreateProcessors(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Keys = []KeyConfig{{ID: "current", File: writeKeyFile(t, testKey)}}
+	cfg.Attributes = []string{"customer.id"}
+
+	params := component.ProcessorCreateSettings{}
+	tp, err := factory.CreateTracesProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotNil(t, tp)
+	mp, err := factory.CreateMetricsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotNil(t, mp)
+	lp, err := factory.CreateLogsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotNil(t, lp)
+}
+
+func TestCreateProcessorsWithInvalidConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(t *testing.T, cfg *Config)
+	}{
+		{name: "no keys", modify: func(t *testing.T, cfg *Config) { cfg.Keys = nil }},
+		{
+			name: "missing key file",
+			modify: func(t *testing.T, cfg *Config) {
+				cfg.Keys[0].File = "/nonexistent/tokenization.key"
+			},
+		},
+		{
+			name: "short previous key",
+			modify: func(t *testing.T, cfg *Config) {
+				cfg.Keys = append(cfg.Keys, KeyConfig{ID: "old", File: writeKeyFile(t, testKey[:8])})
+			},
+		},
+	}
+
+	factory := NewFactory()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.Keys = []KeyConfig{{ID: "current", File: writeKeyFile(t, testKey)}}
+			cfg.Attributes = []string{"customer.id"}
+			tc.modify(t, cfg)
+
+			params := component.ProcessorCreateSettings{}
+			_, err := factory.CreateLogsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+			assert.Error(t, err)
+		})
+	}
+}
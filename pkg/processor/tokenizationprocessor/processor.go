@@ -0,0 +1,223 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizationprocessor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// tokenizationProcessor replaces the values of the selected attributes and body fields with their tokens
+type tokenizationProcessor struct {
+	active   *tokenizer
+	previous *tokenizer
+
+	attributes          []string
+	resourceAttributes  []string
+	bodyFields          [][]string
+	previousTokenSuffix string
+}
+
+func newTokenizationProcessor(cfg *Config) (*tokenizationProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	tp := &tokenizationProcessor{
+		attributes:          cfg.Attributes,
+		resourceAttributes:  cfg.ResourceAttributes,
+		previousTokenSuffix: cfg.PreviousTokenSuffix,
+	}
+	var err error
+	if tp.active, err = newTokenizer(cfg.Keys[0], cfg.Format); err != nil {
+		return nil, err
+	}
+	if len(cfg.Keys) > 1 {
+		if tp.previous, err = newTokenizer(cfg.Keys[1], cfg.Format); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range cfg.BodyFields {
+		tp.bodyFields = append(tp.bodyFields, strings.Split(field, "."))
+	}
+	return tp, nil
+}
+
+// ProcessTraces tokenizes the attributes of the spans
+func (tp *tokenizationProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		tp.tokenizeAttributes(rs.Resource().Attributes(), tp.resourceAttributes)
+		ilss := rs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				tp.tokenizeAttributes(spans.At(k).Attributes(), tp.attributes)
+			}
+		}
+	}
+	return td, nil
+}
+
+// ProcessMetrics tokenizes the attributes of the data points
+func (tp *tokenizationProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		tp.tokenizeAttributes(rm.Resource().Attributes(), tp.resourceAttributes)
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				tp.tokenizeMetric(metrics.At(k))
+			}
+		}
+	}
+	return md, nil
+}
+
+func (tp *tokenizationProcessor) tokenizeMetric(metric pdata.Metric) {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			tp.tokenizeAttributes(dps.At(i).Attributes(), tp.attributes)
+		}
+	case pdata.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			tp.tokenizeAttributes(dps.At(i).Attributes(), tp.attributes)
+		}
+	case pdata.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			tp.tokenizeAttributes(dps.At(i).Attributes(), tp.attributes)
+		}
+	case pdata.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			tp.tokenizeAttributes(dps.At(i).Attributes(), tp.attributes)
+		}
+	}
+}
+
+// ProcessLogs tokenizes the attributes and the body fields of the log records
+func (tp *tokenizationProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		tp.tokenizeAttributes(rl.Resource().Attributes(), tp.resourceAttributes)
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				lr := logs.At(k)
+				tp.tokenizeAttributes(lr.Attributes(), tp.attributes)
+				tp.tokenizeBody(lr.Body())
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (tp *tokenizationProcessor) tokenizeAttributes(attributes pdata.AttributeMap, names []string) {
+	for _, name := range names {
+		tp.tokenizeEntry(attributes, name)
+	}
+}
+
+// tokenizeBody tokenizes the fields of the map body, the other bodies are left as they are
+func (tp *tokenizationProcessor) tokenizeBody(body pdata.AttributeValue) {
+	if body.Type() != pdata.AttributeValueTypeMap {
+		return
+	}
+	for _, path := range tp.bodyFields {
+		fields := body.MapVal()
+		found := true
+		for _, name := range path[:len(path)-1] {
+			value, ok := fields.Get(name)
+			if !ok || value.Type() != pdata.AttributeValueTypeMap {
+				found = false
+				break
+			}
+			fields = value.MapVal()
+		}
+		if found {
+			tp.tokenizeEntry(fields, path[len(path)-1])
+		}
+	}
+}
+
+// tokenizeEntry replaces the value of the entry with its token, and adds the token of the previous key next to it.
+// The scalar values are tokenized as strings, and so are the scalar elements of the arrays.
+func (tp *tokenizationProcessor) tokenizeEntry(attributes pdata.AttributeMap, name string) {
+	value, ok := attributes.Get(name)
+	if !ok {
+		return
+	}
+
+	switch value.Type() {
+	case pdata.AttributeValueTypeArray:
+		var previous pdata.AttributeValue
+		if tp.previous != nil {
+			previous = pdata.NewAttributeValueArray()
+		}
+		values := value.ArrayVal()
+		for i := 0; i < values.Len(); i++ {
+			item := values.At(i)
+			s, ok := stringValue(item)
+			if !ok {
+				continue
+			}
+			item.SetStringVal(tp.active.token(s))
+			if tp.previous != nil {
+				previous.ArrayVal().AppendEmpty().SetStringVal(tp.previous.token(s))
+			}
+		}
+		if tp.previous != nil {
+			attributes.Upsert(name+tp.previousTokenSuffix, previous)
+		}
+	default:
+		s, ok := stringValue(value)
+		if !ok {
+			return
+		}
+		// The value is set before the map is modified, which would invalidate it
+		value.SetStringVal(tp.active.token(s))
+		if tp.previous != nil {
+			attributes.UpsertString(name+tp.previousTokenSuffix, tp.previous.token(s))
+		}
+	}
+}
+
+// stringValue returns the string representation of the scalar value, the empty strings are not tokenized
+func stringValue(value pdata.AttributeValue) (string, bool) {
+	switch value.Type() {
+	case pdata.AttributeValueTypeString:
+		return value.StringVal(), value.StringVal() != ""
+	case pdata.AttributeValueTypeInt:
+		return strconv.FormatInt(value.IntVal(), 10), true
+	case pdata.AttributeValueTypeDouble:
+		return strconv.FormatFloat(value.DoubleVal(), 'f', -1, 64), true
+	case pdata.AttributeValueTypeBool:
+		return strconv.FormatBool(value.BoolVal()), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizationprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) *tokenizationProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Keys = []KeyConfig{{ID: "k2", File: writeKeyFile(t, testKey)}}
+	cfg.Attributes = []string{"customer.id", "enduser.id", "customer.tags"}
+	cfg.ResourceAttributes = []string{"account.id"}
+	cfg.BodyFields = []string{"customer_id", "customer.email"}
+	modify(cfg)
+	tp, err := newTokenizationProcessor(cfg)
+	require.NoError(t, err)
+	return tp
+}
+
+func newTestLogs() pdata.Logs {
+	logs := pdata.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("account.id", "acme")
+	rl.Resource().Attributes().InsertString("host.name", "web-1")
+	records := rl.InstrumentationLibraryLogs().AppendEmpty().Logs()
+
+	lr := records.AppendEmpty()
+	lr.Attributes().InsertString("customer.id", "customer-42")
+	lr.Attributes().InsertInt("enduser.id", 1234)
+	tags := pdata.NewAttributeValueArray()
+	tags.ArrayVal().AppendEmpty().SetStringVal("gold")
+	tags.ArrayVal().AppendEmpty().SetBoolVal(true)
+	lr.Attributes().Insert("customer.tags", tags)
+	lr.Attributes().InsertString("http.method", "GET")
+	pdata.NewAttributeValueMap().CopyTo(lr.Body())
+	lr.Body().MapVal().InsertString("customer_id", "customer-42")
+	customer := pdata.NewAttributeValueMap()
+	customer.MapVal().InsertString("email", "john@example.com")
+	customer.MapVal().InsertString("name", "John")
+	lr.Body().MapVal().Insert("customer", customer)
+
+	lr = records.AppendEmpty()
+	lr.Body().SetStringVal("customer-42 logged in")
+	lr.Attributes().InsertString("customer.id", "")
+	return logs
+}
+
+func TestProcessLogs(t *testing.T) {
+	tp := newTestProcessor(t, func(cfg *Config) {})
+	tok := &tokenizer{id: "k2", key: testKey, format: formatHMAC}
+
+	logs, err := tp.ProcessLogs(context.Background(), newTestLogs())
+	require.NoError(t, err)
+
+	rl := logs.ResourceLogs().At(0)
+	assert.Equal(t, map[string]interface{}{
+		"account.id": tok.token("acme"),
+		"host.name":  "web-1",
+	}, attributesToMap(rl.Resource().Attributes()))
+
+	lr := rl.InstrumentationLibraryLogs().At(0).Logs().At(0)
+	assert.Equal(t, map[string]interface{}{
+		"customer.id":   tok.token("customer-42"),
+		"enduser.id":    tok.token("1234"),
+		"customer.tags": []interface{}{tok.token("gold"), tok.token("true")},
+		"http.method":   "GET",
+	}, attributesToMap(lr.Attributes()))
+	assert.Equal(t, map[string]interface{}{
+		"customer_id": tok.token("customer-42"),
+		"customer": map[string]interface{}{
+			"email": tok.token("john@example.com"),
+			"name":  "John",
+		},
+	}, attributesToMap(lr.Body().MapVal()))
+
+	lr = rl.InstrumentationLibraryLogs().At(0).Logs().At(1)
+	assert.Equal(t, "customer-42 logged in", lr.Body().StringVal(), "only the map bodies are tokenized")
+	assert.Equal(t, map[string]interface{}{"customer.id": ""}, attributesToMap(lr.Attributes()),
+		"the empty values are not tokenized")
+}
+
+func TestProcessLogsWithPreviousKey(t *testing.T) {
+	previousKeyFile := writeKeyFile(t, testPreviousKey)
+	tp := newTestProcessor(t, func(cfg *Config) {
+		cfg.Keys = append(cfg.Keys, KeyConfig{ID: "k1", File: previousKeyFile})
+		cfg.Format = formatFormatPreserving
+	})
+	tok := &tokenizer{id: "k2", key: testKey, format: formatFormatPreserving}
+	previous := &tokenizer{id: "k1", key: testPreviousKey, format: formatFormatPreserving}
+
+	logs, err := tp.ProcessLogs(context.Background(), newTestLogs())
+	require.NoError(t, err)
+
+	rl := logs.ResourceLogs().At(0)
+	assert.Equal(t, map[string]interface{}{
+		"account.id":                tok.token("acme"),
+		"account.id.previous_token": previous.token("acme"),
+		"host.name":                 "web-1",
+	}, attributesToMap(rl.Resource().Attributes()))
+
+	lr := rl.InstrumentationLibraryLogs().At(0).Logs().At(0)
+	assert.Equal(t, map[string]interface{}{
+		"customer.id":                  tok.token("customer-42"),
+		"customer.id.previous_token":   previous.token("customer-42"),
+		"enduser.id":                   tok.token("1234"),
+		"enduser.id.previous_token":    previous.token("1234"),
+		"customer.tags":                []interface{}{tok.token("gold"), tok.token("true")},
+		"customer.tags.previous_token": []interface{}{previous.token("gold"), previous.token("true")},
+		"http.method":                  "GET",
+	}, attributesToMap(lr.Attributes()))
+	assert.Equal(t, map[string]interface{}{
+		"customer_id":                tok.token("customer-42"),
+		"customer_id.previous_token": previous.token("customer-42"),
+		"customer": map[string]interface{}{
+			"email":                tok.token("john@example.com"),
+			"email.previous_token": previous.token("john@example.com"),
+			"name":                 "John",
+		},
+	}, attributesToMap(lr.Body().MapVal()))
+	assert.NotEqual(t, tok.token("customer-42"), previous.token("customer-42"))
+}
+
+func TestProcessTraces(t *testing.T) {
+	tp := newTestProcessor(t, func(cfg *Config) {})
+	tok := &tokenizer{id: "k2", key: testKey, format: formatHMAC}
+
+	traces := pdata.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().InsertString("account.id", "acme")
+	span := rs.InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().InsertString("customer.id", "customer-42")
+	span.Attributes().InsertString("account.id", "acme")
+
+	traces, err := tp.ProcessTraces(context.Background(), traces)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"account.id": tok.token("acme")}, attributesToMap(rs.Resource().Attributes()))
+	assert.Equal(t, map[string]interface{}{
+		"customer.id": tok.token("customer-42"),
+		"account.id":  "acme",
+	}, attributesToMap(span.Attributes()), "the resource attributes are only tokenized in the resources")
+}
+
+func TestProcessMetrics(t *testing.T) {
+	tp := newTestProcessor(t, func(cfg *Config) {})
+	tok := &tokenizer{id: "k2", key: testKey, format: formatHMAC}
+
+	metrics := pdata.NewMetrics()
+	ms := metrics.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	gauge := ms.AppendEmpty()
+	gauge.SetDataType(pdata.MetricDataTypeGauge)
+	gauge.Gauge().DataPoints().AppendEmpty().Attributes().InsertString("customer.id", "customer-42")
+	sum := ms.AppendEmpty()
+	sum.SetDataType(pdata.MetricDataTypeSum)
+	sum.Sum().DataPoints().AppendEmpty().Attributes().InsertString("customer.id", "customer-43")
+
+	metrics, err := tp.ProcessMetrics(context.Background(), metrics)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"customer.id": tok.token("customer-42")},
+		attributesToMap(gauge.Gauge().DataPoints().At(0).Attributes()))
+	assert.Equal(t, map[string]interface{}{"customer.id": tok.token("customer-43")},
+		attributesToMap(sum.Sum().DataPoints().At(0).Attributes()))
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]interface{} {
+	result := map[string]interface{}{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = valueToInterface(v)
+		return true
+	})
+	return result
+}
+
+func valueToInterface(v pdata.AttributeValue) interface{} {
+	switch v.Type() {
+	case pdata.AttributeValueTypeInt:
+		return v.IntVal()
+	case pdata.AttributeValueTypeDouble:
+		return v.DoubleVal()
+	case pdata.AttributeValueTypeBool:
+		return v.BoolVal()
+	case pdata.AttributeValueTypeMap:
+		return attributesToMap(v.MapVal())
+	case pdata.AttributeValueTypeArray:
+		values := []interface{}{}
+		for i := 0; i < v.ArrayVal().Len(); i++ {
+			values = append(values, valueToInterface(v.ArrayVal().At(i)))
+		}
+		return values
+	default:
+		return v.StringVal()
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizationprocessor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const (
+	// minKeySize is the minimum size of the keys, as recommended for HMAC-SHA256
+	minKeySize = 32
+	// hmacTokenSize is the number of bytes of the HMAC kept in the hmac tokens
+	hmacTokenSize = 16
+)
+
+// tokenizer replaces the values with the deterministic tokens derived with its key
+type tokenizer struct {
+	id     string
+	key    []byte
+	format string
+}
+
+func newTokenizer(cfg KeyConfig, format string) (*tokenizer, error) {
+	key, err := loadKey(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", cfg.ID, err)
+	}
+	return &tokenizer{id: cfg.ID, key: key, format: format}, nil
+}
+
+// loadKey reads the key from the configured source
+func loadKey(cfg KeyConfig) ([]byte, error) {
+	var value string
+	if cfg.Env != "" {
+		var ok bool
+		if value, ok = os.LookupEnv(cfg.Env); !ok {
+			return nil, fmt.Errorf("environment variable %s with the key is not set", cfg.Env)
+		}
+	} else {
+		content, err := ioutil.ReadFile(cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the key file: %w", err)
+		}
+		value = string(content)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("the key is not base64 encoded: %w", err)
+	}
+	if len(key) < minKeySize {
+		return nil, fmt.Errorf("the key must be at least %d bytes long, got %d", minKeySize, len(key))
+	}
+	return key, nil
+}
+
+// token returns the token of the value
+func (t *tokenizer) token(value string) string {
+	if t.format == formatFormatPreserving {
+		return t.formatPreservingToken(value)
+	}
+	mac := t.mac(value, 0)
+	return t.id + ":" + hex.EncodeToString(mac[:hmacTokenSize])
+}
+
+// formatPreservingToken replaces each ASCII digit and letter with one of the same class derived from
+// the HMAC of the whole value, so the token keeps the length, the separators and the case of the value.
+// Unlike a format-preserving encryption, the tokens cannot be reversed.
+func (t *tokenizer) formatPreservingToken(value string) string {
+	var stream []byte
+	var counter uint32
+	next := func() byte {
+		if len(stream) == 0 {
+			stream = t.mac(value, counter)
+			counter++
+		}
+		b := stream[0]
+		stream = stream[1:]
+		return b
+	}
+
+	token := []byte(value)
+	for i, c := range token {
+		switch {
+		case '0' <= c && c <= '9':
+			token[i] = '0' + next()%10
+		case 'a' <= c && c <= 'z':
+			token[i] = 'a' + next()%26
+		case 'A' <= c && c <= 'Z':
+			token[i] = 'A' + next()%26
+		}
+	}
+	return string(token)
+}
+
+// mac returns the HMAC-SHA256 of the counter and the value
+func (t *tokenizer) mac(value string, counter uint32) []byte {
+	h := hmac.New(sha256.New, t.key)
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], counter)
+	h.Write(prefix[:])
+	h.Write([]byte(value))
+	return h.Sum(nil)
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenizationprocessor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKey         = bytes.Repeat([]byte{7}, minKeySize)
+	testPreviousKey = bytes.Repeat([]byte{3}, minKeySize)
+)
+
+func writeKeyFile(t *testing.T, key []byte) string {
+	file := filepath.Join(t.TempDir(), "tokenization.key")
+	require.NoError(t, ioutil.WriteFile(file, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600))
+	return file
+}
+
+func TestLoadKey(t *testing.T) {
+	require.NoError(t, os.Setenv("TOKENIZATION_TEST_KEY", base64.StdEncoding.EncodeToString(testKey)))
+	defer os.Unsetenv("TOKENIZATION_TEST_KEY")
+	key, err := loadKey(KeyConfig{ID: "env", Env: "TOKENIZATION_TEST_KEY"})
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	key, err = loadKey(KeyConfig{ID: "file", File: writeKeyFile(t, testKey)})
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	_, err = loadKey(KeyConfig{ID: "env", Env: "TOKENIZATION_TEST_MISSING_KEY"})
+	assert.EqualError(t, err, "environment variable TOKENIZATION_TEST_MISSING_KEY with the key is not set")
+
+	_, err = loadKey(KeyConfig{ID: "file", File: writeKeyFile(t, testKey[:16])})
+	assert.EqualError(t, err, "the key must be at least 32 bytes long, got 16")
+
+	_, err = loadKey(KeyConfig{ID: "file", File: filepath.Join(t.TempDir(), "missing.key")})
+	assert.Error(t, err)
+}
+
+func TestHMACToken(t *testing.T) {
+	tok := &tokenizer{id: "k1", key: testKey, format: formatHMAC}
+	token := tok.token("customer-42")
+	assert.Regexp(t, "^k1:[0-9a-f]{32}$", token)
+	assert.Equal(t, token, tok.token("customer-42"), "the tokens are deterministic")
+	assert.NotEqual(t, token, tok.token("customer-43"))
+
+	other := &tokenizer{id: "k1", key: testPreviousKey, format: formatHMAC}
+	assert.NotEqual(t, token, other.token("customer-42"), "the tokens depend on the key")
+}
+
+func TestFormatPreservingToken(t *testing.T) {
+	tok := &tokenizer{id: "k1", key: testKey, format: formatFormatPreserving}
+
+	testcases := []struct {
+		value   string
+		pattern string
+	}{
+		{value: "4111-1111-1111-1111", pattern: `^\d{4}-\d{4}-\d{4}-\d{4}$`},
+		{value: "john.Doe@example.com", pattern: `^[a-z]{4}\.[A-Z][a-z]{2}@[a-z]{7}\.[a-z]{3}$`},
+		{value: "ACME-Łódź-7", pattern: `^[A-Z]{4}-Łó[a-z]ź-\d$`},
+		{value: "a very long value which needs more than a single hmac for its 60 letters", pattern: `^[a-z ]+\d{2}[a-z ]+$`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			token := tok.token(tc.value)
+			assert.Regexp(t, tc.pattern, token)
+			assert.NotEqual(t, tc.value, token)
+			assert.Equal(t, token, tok.token(tc.value), "the tokens are deterministic")
+		})
+	}
+
+	assert.NotEqual(t, tok.token("4111-1111-1111-1111"), tok.token("4111-1111-1111-1112"))
+}
@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragentprocessor
+
+import (
+	"container/list"
+	"sync"
+)
+
+// parseCache is a least recently used cache of the parsed user agents. The user agents in
+// access logs repeat a lot, and parsing them is expensive.
+type parseCache struct {
+	size    int
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type cacheEntry struct {
+	userAgent string
+	parsed    *parsedUserAgent
+}
+
+func newParseCache(size int) *parseCache {
+	return &parseCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+func (c *parseCache) get(userAgent string) (*parsedUserAgent, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[userAgent]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).parsed, true
+}
+
+func (c *parseCache) put(userAgent string, parsed *parsedUserAgent) {
+	if c.size == 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.entries[userAgent]; ok {
+		c.order.MoveToFront(elem)
+		elem.Value.(*cacheEntry).parsed = parsed
+		return
+	}
+	c.entries[userAgent] = c.order.PushFront(&cacheEntry{userAgent: userAgent, parsed: parsed})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).userAgent)
+	}
+}
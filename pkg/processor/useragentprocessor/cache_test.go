@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragentprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCacheEviction(t *testing.T) {
+	cache := newParseCache(2)
+	a, b, c := &parsedUserAgent{}, &parsedUserAgent{}, &parsedUserAgent{}
+
+	cache.put("a", a)
+	cache.put("b", b)
+	// reading "a" makes "b" the least recently used
+	parsed, ok := cache.get("a")
+	assert.True(t, ok)
+	assert.Same(t, a, parsed)
+	cache.put("c", c)
+
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+	parsed, ok = cache.get("a")
+	assert.True(t, ok)
+	assert.Same(t, a, parsed)
+	parsed, ok = cache.get("c")
+	assert.True(t, ok)
+	assert.Same(t, c, parsed)
+	assert.Equal(t, 2, cache.order.Len())
+}
+
+func TestParseCacheDisabled(t *testing.T) {
+	cache := newParseCache(0)
+	cache.put("a", &parsedUserAgent{})
+
+	_, ok := cache.get("a")
+	assert.False(t, ok)
+	assert.Empty(t, cache.entries)
+}
@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragentprocessor
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config holds the configuration of the user agent parsing.
+type Config struct {
+	config.ProcessorSettings `mapstructure:"-"`
+
+	// Attribute is the name of the log record or span attribute holding the user agent string
+	Attribute string `mapstructure:"attribute"`
+	// Prefix is prepended to the names of the attributes the parsed user agent is placed into
+	Prefix string `mapstructure:"prefix"`
+	// CacheSize is the number of the most recently parsed user agents whose results are reused
+	CacheSize int `mapstructure:"cache_size"`
+}
+
+const (
+	defaultAttribute = "http.user_agent"
+	defaultPrefix    = "user_agent."
+	defaultCacheSize = 1000
+)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Attribute == "" {
+		return errors.New("attribute must be set")
+	}
+	if cfg.CacheSize < 0 {
+		return errors.New("cache_size must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragentprocessor
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ua-parser/uap-go/uaparser"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// The names of the attributes the parsed user agent is placed into, without the prefix.
+const (
+	browserNameAttribute    = "browser.name"
+	browserVersionAttribute = "browser.version"
+	osNameAttribute         = "os.name"
+	osVersionAttribute      = "os.version"
+	deviceNameAttribute     = "device.name"
+	deviceBrandAttribute    = "device.brand"
+	deviceModelAttribute    = "device.model"
+)
+
+// unknownFamily is returned by the parser for the browsers, systems and devices it doesn't recognize
+const unknownFamily = "Other"
+
+var (
+	// parser is shared by all the processors, as compiling the regular expressions
+	// of the user agent database takes a while
+	parser     *uaparser.Parser
+	parserOnce sync.Once
+)
+
+func getParser() *uaparser.Parser {
+	parserOnce.Do(func() {
+		parser = uaparser.NewFromSaved()
+	})
+	return parser
+}
+
+// userAgentProcessor parses the user agent attribute into the browser, operating system and device attributes
+type userAgentProcessor struct {
+	attribute string
+	prefix    string
+	parser    *uaparser.Parser
+	cache     *parseCache
+}
+
+// parsedUserAgent holds the attributes of a parsed user agent, without the prefix
+type parsedUserAgent struct {
+	attributes []parsedAttribute
+}
+
+type parsedAttribute struct {
+	name  string
+	value string
+}
+
+func newUserAgentProcessor(cfg *Config) (*userAgentProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &userAgentProcessor{
+		attribute: cfg.Attribute,
+		prefix:    cfg.Prefix,
+		parser:    getParser(),
+		cache:     newParseCache(cfg.CacheSize),
+	}, nil
+}
+
+func (uap *userAgentProcessor) ProcessLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				uap.processAttributes(logs.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (uap *userAgentProcessor) ProcessTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		ilss := rss.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				uap.processAttributes(spans.At(k).Attributes())
+			}
+		}
+	}
+	return td, nil
+}
+
+func (uap *userAgentProcessor) processAttributes(attrs pdata.AttributeMap) {
+	value, ok := attrs.Get(uap.attribute)
+	if !ok || value.Type() != pdata.AttributeValueTypeString || value.StringVal() == "" {
+		return
+	}
+
+	for _, attr := range uap.parse(value.StringVal()).attributes {
+		attrs.UpsertString(uap.prefix+attr.name, attr.value)
+	}
+}
+
+func (uap *userAgentProcessor) parse(userAgent string) *parsedUserAgent {
+	if parsed, ok := uap.cache.get(userAgent); ok {
+		return parsed
+	}
+
+	parsed := &parsedUserAgent{}
+	add := func(name, value string) {
+		if value != "" {
+			parsed.attributes = append(parsed.attributes, parsedAttribute{name: name, value: value})
+		}
+	}
+
+	if ua := uap.parser.ParseUserAgent(userAgent); ua.Family != unknownFamily {
+		add(browserNameAttribute, ua.Family)
+		add(browserVersionAttribute, ua.ToVersionString())
+	}
+	if os := uap.parser.ParseOs(userAgent); os.Family != unknownFamily {
+		add(osNameAttribute, os.Family)
+		add(osVersionAttribute, os.ToVersionString())
+	}
+	if device := uap.parser.ParseDevice(userAgent); device.Family != unknownFamily {
+		add(deviceNameAttribute, device.Family)
+		add(deviceBrandAttribute, device.Brand)
+		add(deviceModelAttribute, device.Model)
+	}
+
+	uap.cache.put(userAgent, parsed)
+	return parsed
+}
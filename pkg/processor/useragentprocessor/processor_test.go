@@ -0,0 +1,191 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package useragentprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	chromeWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) " +
+		"Chrome/92.0.4515.159 Safari/537.36"
+	safariIPhone = "Mozilla/5.0 (iPhone; CPU iPhone OS 14_7_1 like Mac OS X) AppleWebKit/605.1.15 " +
+		"(KHTML, like Gecko) Version/14.1.2 Mobile/15E148 Safari/604.1"
+	googlebot = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+)
+
+func attributesToMap(attrs pdata.AttributeMap) map[string]string {
+	m := map[string]string{}
+	attrs.Range(func(k string, v pdata.AttributeValue) bool {
+		m[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return m
+}
+
+func newTestProcessor(t *testing.T, modify func(cfg *Config)) *userAgentProcessor {
+	cfg := createDefaultConfig().(*Config)
+	if modify != nil {
+		modify(cfg)
+	}
+	uap, err := newUserAgentProcessor(cfg)
+	require.NoError(t, err)
+	return uap
+}
+
+func TestParseUserAgents(t *testing.T) {
+	testcases := []struct {
+		name      string
+		userAgent string
+		expected  map[string]string
+	}{
+		{
+			name:      "desktop browser",
+			userAgent: chromeWindows,
+			expected: map[string]string{
+				"user_agent.browser.name":    "Chrome",
+				"user_agent.browser.version": "92.0.4515",
+				"user_agent.os.name":         "Windows",
+				"user_agent.os.version":      "10",
+			},
+		},
+		{
+			name:      "mobile browser",
+			userAgent: safariIPhone,
+			expected: map[string]string{
+				"user_agent.browser.name":    "Mobile Safari",
+				"user_agent.browser.version": "14.1.2",
+				"user_agent.os.name":         "iOS",
+				"user_agent.os.version":      "14.7.1",
+				"user_agent.device.name":     "iPhone",
+				"user_agent.device.brand":    "Apple",
+				"user_agent.device.model":    "iPhone",
+			},
+		},
+		{
+			name:      "crawler",
+			userAgent: googlebot,
+			expected: map[string]string{
+				"user_agent.browser.name":    "Googlebot",
+				"user_agent.browser.version": "2.1",
+				"user_agent.device.name":     "Spider",
+				"user_agent.device.brand":    "Spider",
+				"user_agent.device.model":    "Desktop",
+			},
+		},
+		{
+			name:      "command line client",
+			userAgent: "curl/7.68.0",
+			expected: map[string]string{
+				"user_agent.browser.name":    "curl",
+				"user_agent.browser.version": "7.68.0",
+			},
+		},
+		{
+			name:      "unknown",
+			userAgent: "my-health-checker",
+			expected:  map[string]string{},
+		},
+	}
+
+	uap := newTestProcessor(t, nil)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			attrs := pdata.NewAttributeMap()
+			attrs.InsertString("http.user_agent", tc.userAgent)
+			uap.processAttributes(attrs)
+
+			tc.expected["http.user_agent"] = tc.userAgent
+			assert.Equal(t, tc.expected, attributesToMap(attrs))
+		})
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	uap := newTestProcessor(t, func(cfg *Config) {
+		cfg.Attribute = "agent"
+		cfg.Prefix = "ua_"
+	})
+
+	ld := pdata.NewLogs()
+	logs := ld.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	logs.AppendEmpty().Attributes().InsertString("agent", "curl/7.68.0")
+	logs.AppendEmpty().Attributes().InsertString("http.user_agent", "curl/7.68.0")
+	logs.AppendEmpty().Attributes().InsertInt("agent", 7)
+
+	result, err := uap.ProcessLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	logs = result.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
+	assert.Equal(t, map[string]string{
+		"agent":              "curl/7.68.0",
+		"ua_browser.name":    "curl",
+		"ua_browser.version": "7.68.0",
+	}, attributesToMap(logs.At(0).Attributes()))
+	assert.Equal(t, map[string]string{"http.user_agent": "curl/7.68.0"}, attributesToMap(logs.At(1).Attributes()))
+	assert.Equal(t, map[string]string{"agent": "7"}, attributesToMap(logs.At(2).Attributes()))
+}
+
+func TestProcessTraces(t *testing.T) {
+	uap := newTestProcessor(t, nil)
+
+	td := pdata.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().InsertString("http.user_agent", chromeWindows)
+	span.Attributes().InsertString("user_agent.os.name", "Linux")
+
+	result, err := uap.ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+
+	attrs := attributesToMap(result.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Attributes())
+	assert.Equal(t, "Chrome", attrs["user_agent.browser.name"])
+	assert.Equal(t, "Windows", attrs["user_agent.os.name"])
+}
+
+func TestParseCached(t *testing.T) {
+	uap := newTestProcessor(t, nil)
+
+	first := uap.parse(safariIPhone)
+	second := uap.parse(safariIPhone)
+	assert.Same(t, first, second)
+
+	uap = newTestProcessor(t, func(cfg *Config) { cfg.CacheSize = 0 })
+	first = uap.parse(safariIPhone)
+	second = uap.parse(safariIPhone)
+	assert.NotSame(t, first, second)
+	assert.Equal(t, first, second)
+}
+
+func BenchmarkProcessLogs(b *testing.B) {
+	cfg := createDefaultConfig().(*Config)
+	uap, err := newUserAgentProcessor(cfg)
+	require.NoError(b, err)
+
+	ld := pdata.NewLogs()
+	logs := ld.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	for _, userAgent := range []string{chromeWindows, safariIPhone, googlebot} {
+		logs.AppendEmpty().Attributes().InsertString("http.user_agent", userAgent)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = uap.ProcessLogs(context.Background(), ld)
+	}
+}
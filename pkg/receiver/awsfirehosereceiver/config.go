@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awsfirehosereceiver
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Config defines configuration for the Kinesis Data Firehose HTTP endpoint receiver.
+type Config struct {
+	config.ReceiverSettings       `mapstructure:"-"`
+	confighttp.HTTPServerSettings `mapstructure:",squash"`
+
+	// AccessKey is the access key configured for the HTTP endpoint destination of the delivery stream.
+	// When empty, the requests with any access key are accepted.
+	AccessKey string `mapstructure:"access_key"`
+
+	// RecordType is the format of the delivered records: cwlogs for the CloudWatch Logs subscriptions,
+	// raw for any other data, or auto to detect the CloudWatch Logs records and treat the other ones as raw.
+	RecordType string `mapstructure:"record_type"`
+
+	// MaxRequestBodySize is the maximum size of the request body, after the decompression.
+	MaxRequestBodySize int64 `mapstructure:"max_request_body_size"`
+}
+
+const (
+	recordTypeAuto   = "auto"
+	recordTypeCWLogs = "cwlogs"
+	recordTypeRaw    = "raw"
+
+	defaultEndpoint = "0.0.0.0:8443"
+	// Firehose buffers up to 64 MiB before the delivery, and the records are base64 encoded in the request
+	defaultMaxRequestBodySize = 100 * 1024 * 1024
+)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return fmt.Errorf("endpoint must not be empty")
+	}
+	switch cfg.RecordType {
+	case recordTypeAuto, recordTypeCWLogs, recordTypeRaw:
+	default:
+		return fmt.Errorf("unknown record_type %q, must be one of: auto, cwlogs, raw", cfg.RecordType)
+	}
+	if cfg.MaxRequestBodySize <= 0 {
+		return fmt.Errorf("max_request_body_size must be positive, got %d", cfg.MaxRequestBodySize)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awsfirehosereceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "awsfirehose_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, factory.CreateDefaultConfig(), cfg.Receivers[config.NewID(typeStr)])
+	assert.Equal(t, &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewIDWithName(typeStr, "cwlogs")),
+		HTTPServerSettings: confighttp.HTTPServerSettings{
+			Endpoint: "localhost:9443",
+		},
+		AccessKey:          "some-secret",
+		RecordType:         "cwlogs",
+		MaxRequestBodySize: 1048576,
+	}, cfg.Receivers[config.NewIDWithName(typeStr, "cwlogs")])
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "default", modify: func(cfg *Config) {}},
+		{name: "raw records", modify: func(cfg *Config) { cfg.RecordType = "raw" }},
+		{
+			name:    "no endpoint",
+			modify:  func(cfg *Config) { cfg.Endpoint = "" },
+			wantErr: "endpoint must not be empty",
+		},
+		{
+			name:    "unknown record type",
+			modify:  func(cfg *Config) { cfg.RecordType = "cwmetrics" },
+			wantErr: `unknown record_type "cwmetrics", must be one of: auto, cwlogs, raw`,
+		},
+		{
+			name:    "zero body size",
+			modify:  func(cfg *Config) { cfg.MaxRequestBodySize = 0 },
+			wantErr: "max_request_body_size must be positive, got 0",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awsfirehosereceiver
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.uber.org/zap"
+)
+
+const (
+	headerRequestID        = "X-Amz-Firehose-Request-Id"
+	headerAccessKey        = "X-Amz-Firehose-Access-Key"
+	headerCommonAttributes = "X-Amz-Firehose-Common-Attributes"
+	headerContentEncoding  = "Content-Encoding"
+	headerContentType      = "Content-Type"
+
+	contentEncodingGzip = "gzip"
+)
+
+var (
+	errBodyTooLarge        = errors.New("request body too large")
+	errUnsupportedEncoding = errors.New("unsupported content encoding")
+)
+
+// firehoseRequest is the body of the Firehose HTTP endpoint delivery request
+type firehoseRequest struct {
+	RequestID string `json:"requestId"`
+	Timestamp int64  `json:"timestamp"`
+	Records   []struct {
+		Data string `json:"data"`
+	} `json:"records"`
+}
+
+// firehoseResponse is the body of the response expected by Firehose
+type firehoseResponse struct {
+	RequestID    string `json:"requestId"`
+	Timestamp    int64  `json:"timestamp"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// firehoseCommonAttributes is the value of the common attributes header, holding the parameters
+// configured for the delivery stream
+type firehoseCommonAttributes struct {
+	CommonAttributes map[string]string `json:"commonAttributes"`
+}
+
+type firehoseReceiver struct {
+	config       *Config
+	logger       *zap.Logger
+	logsConsumer consumer.Logs
+
+	server *http.Server
+	wg     sync.WaitGroup
+}
+
+// Ensure this receiver adheres to required interfaces.
+var _ component.LogsReceiver = (*firehoseReceiver)(nil)
+
+func newFirehoseReceiver(logger *zap.Logger, cfg *Config, logsConsumer consumer.Logs) *firehoseReceiver {
+	return &firehoseReceiver{
+		config:       cfg,
+		logger:       logger,
+		logsConsumer: logsConsumer,
+	}
+}
+
+// Start starts the HTTP server
+func (r *firehoseReceiver) Start(_ context.Context, host component.Host) error {
+	listener, err := r.config.ToListener()
+	if err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", r.config.Endpoint, err)
+	}
+	r.server = r.config.ToServer(r)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if sErr := r.server.Serve(listener); sErr != nil && sErr != http.ErrServerClosed {
+			host.ReportFatalError(sErr)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops the HTTP server
+func (r *firehoseReceiver) Shutdown(ctx context.Context) error {
+	if r.server == nil {
+		return nil
+	}
+	err := r.server.Shutdown(ctx)
+	r.wg.Wait()
+	return err
+}
+
+// ServeHTTP handles the Firehose HTTP endpoint delivery requests
+func (r *firehoseReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	requestID := req.Header.Get(headerRequestID)
+	if req.Method != http.MethodPost {
+		r.respond(w, requestID, http.StatusMethodNotAllowed, "only POST requests are supported")
+		return
+	}
+	if r.config.AccessKey != "" &&
+		subtle.ConstantTimeCompare([]byte(req.Header.Get(headerAccessKey)), []byte(r.config.AccessKey)) != 1 {
+		r.respond(w, requestID, http.StatusUnauthorized, "invalid access key")
+		return
+	}
+
+	body, err := r.readBody(req)
+	switch {
+	case errors.Is(err, errBodyTooLarge):
+		r.respond(w, requestID, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	case errors.Is(err, errUnsupportedEncoding):
+		r.respond(w, requestID, http.StatusUnsupportedMediaType, err.Error())
+		return
+	case err != nil:
+		r.respond(w, requestID, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var request firehoseRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		r.respond(w, requestID, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if requestID == "" {
+		requestID = request.RequestID
+	}
+
+	var common firehoseCommonAttributes
+	if header := req.Header.Get(headerCommonAttributes); header != "" {
+		if err := json.Unmarshal([]byte(header), &common); err != nil {
+			r.respond(w, requestID, http.StatusBadRequest, fmt.Sprintf("invalid common attributes: %v", err))
+			return
+		}
+	}
+
+	timestamp := time.Now()
+	if request.Timestamp > 0 {
+		timestamp = time.Unix(0, request.Timestamp*int64(time.Millisecond))
+	}
+	builder := newLogsBuilder(common.CommonAttributes, timestamp)
+	for i, record := range request.Records {
+		data, err := base64.StdEncoding.DecodeString(record.Data)
+		if err != nil {
+			r.respond(w, requestID, http.StatusBadRequest, fmt.Sprintf("invalid record %d: %v", i, err))
+			return
+		}
+		if err := r.addRecord(builder, data); err != nil {
+			r.respond(w, requestID, http.StatusRequestEntityTooLarge, fmt.Sprintf("invalid record %d: %v", i, err))
+			return
+		}
+	}
+
+	if logs := builder.logs; logs.LogRecordCount() > 0 {
+		if err := r.logsConsumer.ConsumeLogs(req.Context(), logs); err != nil {
+			r.logger.Debug("Failed to consume the logs", zap.String("request_id", requestID), zap.Error(err))
+			r.respond(w, requestID, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+	}
+	r.respond(w, requestID, http.StatusOK, "")
+}
+
+// addRecord adds the record according to the record type. Only exceeding the maximum size is an error;
+// the records which aren't CloudWatch Logs messages are dropped, or treated as raw in the auto mode.
+func (r *firehoseReceiver) addRecord(builder *logsBuilder, data []byte) error {
+	if r.config.RecordType == recordTypeRaw {
+		builder.addRaw(data)
+		return nil
+	}
+
+	messages, err := decodeCWLogs(data, r.config.MaxRequestBodySize)
+	switch {
+	case errors.Is(err, errBodyTooLarge):
+		return err
+	case err == nil:
+		builder.addCWLogs(messages)
+	case r.config.RecordType == recordTypeAuto:
+		builder.addRaw(data)
+	default:
+		r.logger.Warn("Dropping the record, which is not a CloudWatch Logs message", zap.Error(err))
+	}
+	return nil
+}
+
+// respond writes the response in the format expected by Firehose
+func (r *firehoseReceiver) respond(w http.ResponseWriter, requestID string, statusCode int, errorMessage string) {
+	body, err := json.Marshal(firehoseResponse{
+		RequestID:    requestID,
+		Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
+		ErrorMessage: errorMessage,
+	})
+	if err != nil {
+		r.logger.Error("Failed to encode the response", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(headerContentType, "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
+
+// readBody reads the decompressed body, up to the maximum size
+func (r *firehoseReceiver) readBody(req *http.Request) ([]byte, error) {
+	var reader io.Reader
+	switch encoding := req.Header.Get(headerContentEncoding); encoding {
+	case "", "identity":
+		reader = req.Body
+	case contentEncodingGzip:
+		gzipReader, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedEncoding, encoding)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(reader, r.config.MaxRequestBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the body: %w", err)
+	}
+	if int64(len(body)) > r.config.MaxRequestBodySize {
+		return nil, errBodyTooLarge
+	}
+	return body, nil
+}
@@ -0,0 +1,345 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awsfirehosereceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/testutil"
+	"go.uber.org/zap"
+)
+
+const testTimestamp = 1630490405000
+
+type testReceiver struct {
+	*firehoseReceiver
+	logs *consumertest.LogsSink
+}
+
+func newTestReceiver(cfg *Config) testReceiver {
+	if cfg == nil {
+		cfg = createDefaultConfig().(*Config)
+	}
+	sink := new(consumertest.LogsSink)
+	return testReceiver{
+		firehoseReceiver: newFirehoseReceiver(zap.NewNop(), cfg, sink),
+		logs:             sink,
+	}
+}
+
+func (r testReceiver) post(body string, headers map[string]string) (*httptest.ResponseRecorder, firehoseResponse) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(headerRequestID, "request-1")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp firehoseResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+// requestBody returns the Firehose request body with the records
+func requestBody(t *testing.T, records ...[]byte) string {
+	request := map[string]interface{}{
+		"requestId": "request-1",
+		"timestamp": testTimestamp,
+	}
+	var encoded []map[string]string
+	for _, record := range records {
+		encoded = append(encoded, map[string]string{"data": base64.StdEncoding.EncodeToString(record)})
+	}
+	request["records"] = encoded
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func cwLogsRecord(t *testing.T) []byte {
+	data, err := ioutil.ReadFile(path.Join("testdata", "cwlogs_message.json"))
+	require.NoError(t, err)
+	return gzipData(t, data)
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]interface{} {
+	result := map[string]interface{}{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = v.StringVal()
+		return true
+	})
+	return result
+}
+
+func TestCWLogsRecords(t *testing.T) {
+	r := newTestReceiver(nil)
+
+	w, resp := r.post(requestBody(t, cwLogsRecord(t)), map[string]string{
+		headerCommonAttributes: `{"commonAttributes":{"_sourceCategory":"aws/lambda"}}`,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get(headerContentType))
+	assert.Equal(t, "request-1", resp.RequestID)
+	assert.NotZero(t, resp.Timestamp)
+	assert.Empty(t, resp.ErrorMessage)
+
+	require.Len(t, r.logs.AllLogs(), 1)
+	logs := r.logs.AllLogs()[0]
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	rl := logs.ResourceLogs().At(0)
+	assert.Equal(t, map[string]interface{}{
+		"_sourceCategory":     "aws/lambda",
+		"cloud.provider":      "aws",
+		"cloud.account.id":    "123456789012",
+		"aws.log.group.name":  "/aws/lambda/checkout",
+		"aws.log.stream.name": "2021/09/01/[$LATEST]0123456789abcdef",
+	}, attributesToMap(rl.Resource().Attributes()))
+
+	records := rl.InstrumentationLibraryLogs().At(0).Logs()
+	require.Equal(t, 2, records.Len())
+	assert.Equal(t, "START RequestId: 5c4c7ec4 Version: $LATEST\n", records.At(0).Body().StringVal())
+	assert.Equal(t, time.Unix(1630490400, 0).UTC(), records.At(0).Timestamp().AsTime())
+	assert.Equal(t, map[string]interface{}{
+		"aws.log.event.id": "36353455563846217590417155426924924932384374958735392768",
+	}, attributesToMap(records.At(0).Attributes()))
+	assert.Equal(t, `{"level":"info","msg":"order placed"}`, records.At(1).Body().StringVal())
+	assert.Equal(t, time.Unix(1630490400, int64(time.Millisecond)).UTC(), records.At(1).Timestamp().AsTime())
+}
+
+func TestRecordTypes(t *testing.T) {
+	control := gzipData(t, []byte(`{"messageType":"CONTROL_MESSAGE","owner":"CloudwatchLogs","logEvents":[]}`))
+
+	testcases := []struct {
+		recordType string
+		// bodies of the logs, in order
+		expected []string
+	}{
+		{
+			recordType: "auto",
+			expected: []string{
+				"raw line",
+				string(gzipData(t, []byte("not a CloudWatch Logs message"))),
+				"START RequestId: 5c4c7ec4 Version: $LATEST\n",
+				`{"level":"info","msg":"order placed"}`,
+			},
+		},
+		{
+			recordType: "cwlogs",
+			expected: []string{
+				"START RequestId: 5c4c7ec4 Version: $LATEST\n",
+				`{"level":"info","msg":"order placed"}`,
+			},
+		},
+		{
+			recordType: "raw",
+			expected: []string{
+				"raw line",
+				string(gzipData(t, []byte("not a CloudWatch Logs message"))),
+				string(cwLogsRecord(t)),
+				string(control),
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.recordType, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.RecordType = tc.recordType
+			r := newTestReceiver(cfg)
+
+			body := requestBody(t,
+				[]byte("raw line\n"),
+				gzipData(t, []byte("not a CloudWatch Logs message")),
+				cwLogsRecord(t),
+				control)
+			w, _ := r.post(body, nil)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			require.Len(t, r.logs.AllLogs(), 1)
+			var bodies []string
+			rls := r.logs.AllLogs()[0].ResourceLogs()
+			for i := 0; i < rls.Len(); i++ {
+				logs := rls.At(i).InstrumentationLibraryLogs().At(0).Logs()
+				for j := 0; j < logs.Len(); j++ {
+					bodies = append(bodies, logs.At(j).Body().StringVal())
+				}
+			}
+			assert.Equal(t, tc.expected, bodies)
+		})
+	}
+}
+
+func TestRawRecordsTimestamp(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.RecordType = "raw"
+	r := newTestReceiver(cfg)
+
+	w, _ := r.post(requestBody(t, []byte("first"), []byte("second")), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	logs := r.logs.AllLogs()[0]
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	records := logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
+	require.Equal(t, 2, records.Len())
+	for i := 0; i < records.Len(); i++ {
+		assert.Equal(t, time.Unix(1630490405, 0).UTC(), records.At(i).Timestamp().AsTime())
+	}
+}
+
+func TestGzipRequest(t *testing.T) {
+	r := newTestReceiver(nil)
+
+	body := gzipData(t, []byte(requestBody(t, []byte("compressed"))))
+	w, _ := r.post(string(body), map[string]string{headerContentEncoding: "gzip"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Len(t, r.logs.AllLogs(), 1)
+	assert.Equal(t, "compressed",
+		r.logs.AllLogs()[0].ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Body().StringVal())
+}
+
+func TestEmptyRequest(t *testing.T) {
+	r := newTestReceiver(nil)
+
+	w, resp := r.post(requestBody(t), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "request-1", resp.RequestID)
+	assert.Empty(t, r.logs.AllLogs())
+}
+
+func TestRequestErrors(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AccessKey = "secret"
+	cfg.MaxRequestBodySize = 1024
+	r := newTestReceiver(cfg)
+	withKey := map[string]string{headerAccessKey: "secret"}
+
+	testcases := []struct {
+		name     string
+		body     string
+		headers  map[string]string
+		expected int
+	}{
+		{name: "valid", body: requestBody(t, []byte("line")), headers: withKey, expected: http.StatusOK},
+		{name: "no access key", body: requestBody(t, []byte("line")), expected: http.StatusUnauthorized},
+		{
+			name:     "wrong access key",
+			body:     requestBody(t, []byte("line")),
+			headers:  map[string]string{headerAccessKey: "other"},
+			expected: http.StatusUnauthorized,
+		},
+		{name: "invalid JSON", body: "{", headers: withKey, expected: http.StatusBadRequest},
+		{
+			name:     "invalid base64",
+			body:     `{"requestId":"request-1","records":[{"data":"!!!"}]}`,
+			headers:  withKey,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid common attributes",
+			body:     requestBody(t, []byte("line")),
+			headers:  map[string]string{headerAccessKey: "secret", headerCommonAttributes: "{"},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "body too large",
+			body:     requestBody(t, bytes.Repeat([]byte("a"), 1024)),
+			headers:  withKey,
+			expected: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "record too large",
+			body:     requestBody(t, gzipData(t, bytes.Repeat([]byte(" "), 2048))),
+			headers:  withKey,
+			expected: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "unsupported encoding",
+			body:     requestBody(t, []byte("line")),
+			headers:  map[string]string{headerAccessKey: "secret", headerContentEncoding: "br"},
+			expected: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, resp := r.post(tc.body, tc.headers)
+			assert.Equal(t, tc.expected, w.Code)
+			assert.Equal(t, "request-1", resp.RequestID)
+			if tc.expected != http.StatusOK {
+				assert.NotEmpty(t, resp.ErrorMessage)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	assert.Len(t, r.logs.AllLogs(), 1)
+}
+
+func TestConsumerError(t *testing.T) {
+	r := newTestReceiver(nil)
+	r.logsConsumer = consumertest.NewErr(errors.New("queue is full"))
+
+	w, resp := r.post(requestBody(t, []byte("line")), nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "queue is full", resp.ErrorMessage)
+}
+
+func TestStartShutdown(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = testutil.GetAvailableLocalAddress(t)
+	r := newTestReceiver(cfg)
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	resp, err := http.Post("http://"+cfg.Endpoint, "application/json", strings.NewReader(requestBody(t, []byte("line"))))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, r.logs.AllLogs(), 1)
+
+	require.NoError(t, r.Shutdown(context.Background()))
+}
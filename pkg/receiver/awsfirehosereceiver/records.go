@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awsfirehosereceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	cwLogsMessageTypeData    = "DATA_MESSAGE"
+	cwLogsMessageTypeControl = "CONTROL_MESSAGE"
+
+	attributeCloudProvider  = "cloud.provider"
+	attributeCloudAccountID = "cloud.account.id"
+	attributeLogGroup       = "aws.log.group.name"
+	attributeLogStream      = "aws.log.stream.name"
+	attributeLogEventID     = "aws.log.event.id"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// cwLogsMessage is a message of a CloudWatch Logs subscription, delivered as a gzip compressed Firehose record
+type cwLogsMessage struct {
+	MessageType string `json:"messageType"`
+	Owner       string `json:"owner"`
+	LogGroup    string `json:"logGroup"`
+	LogStream   string `json:"logStream"`
+	LogEvents   []struct {
+		ID        string `json:"id"`
+		Timestamp int64  `json:"timestamp"`
+		Message   string `json:"message"`
+	} `json:"logEvents"`
+}
+
+// logsBuilder converts the Firehose records into logs. The CloudWatch Logs messages get a resource each,
+// and the raw records share a single one.
+type logsBuilder struct {
+	logs             pdata.Logs
+	commonAttributes map[string]string
+	timestamp        pdata.Timestamp
+	raw              pdata.LogSlice
+	hasRaw           bool
+}
+
+func newLogsBuilder(commonAttributes map[string]string, timestamp time.Time) *logsBuilder {
+	return &logsBuilder{
+		logs:             pdata.NewLogs(),
+		commonAttributes: commonAttributes,
+		timestamp:        pdata.TimestampFromTime(timestamp),
+	}
+}
+
+// newResourceLogs adds a resource with the common attributes of the request
+func (b *logsBuilder) newResourceLogs() pdata.ResourceLogs {
+	rl := b.logs.ResourceLogs().AppendEmpty()
+	attrs := rl.Resource().Attributes()
+	for k, v := range b.commonAttributes {
+		attrs.UpsertString(k, v)
+	}
+	rl.InstrumentationLibraryLogs().AppendEmpty()
+	return rl
+}
+
+// addRaw adds the record as a single log with the request's timestamp
+func (b *logsBuilder) addRaw(data []byte) {
+	if !b.hasRaw {
+		b.raw = b.newResourceLogs().InstrumentationLibraryLogs().At(0).Logs()
+		b.hasRaw = true
+	}
+	lr := b.raw.AppendEmpty()
+	lr.SetTimestamp(b.timestamp)
+	lr.Body().SetStringVal(strings.TrimRight(string(data), "\r\n"))
+}
+
+// addCWLogs adds the log events of the CloudWatch Logs data messages
+func (b *logsBuilder) addCWLogs(messages []cwLogsMessage) {
+	for _, msg := range messages {
+		if msg.MessageType != cwLogsMessageTypeData {
+			continue
+		}
+
+		rl := b.newResourceLogs()
+		attrs := rl.Resource().Attributes()
+		attrs.UpsertString(attributeCloudProvider, "aws")
+		attrs.UpsertString(attributeCloudAccountID, msg.Owner)
+		attrs.UpsertString(attributeLogGroup, msg.LogGroup)
+		attrs.UpsertString(attributeLogStream, msg.LogStream)
+
+		logs := rl.InstrumentationLibraryLogs().At(0).Logs()
+		for _, event := range msg.LogEvents {
+			lr := logs.AppendEmpty()
+			lr.SetTimestamp(pdata.TimestampFromTime(time.Unix(0, event.Timestamp*int64(time.Millisecond))))
+			lr.Body().SetStringVal(event.Message)
+			lr.Attributes().InsertString(attributeLogEventID, event.ID)
+		}
+	}
+}
+
+// decodeCWLogs decodes the gzip compressed CloudWatch Logs messages of the record, up to the maximum size
+func decodeCWLogs(data []byte, maxSize int64) ([]cwLogsMessage, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return nil, errors.New("record is not gzip compressed")
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip record: %w", err)
+	}
+	defer reader.Close()
+
+	limited := &io.LimitedReader{R: reader, N: maxSize + 1}
+	decoder := json.NewDecoder(limited)
+	var messages []cwLogsMessage
+	for {
+		var msg cwLogsMessage
+		err := decoder.Decode(&msg)
+		// the limit cuts the data short, so it must be checked before the end of the data
+		if limited.N <= 0 {
+			return nil, errBodyTooLarge
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CloudWatch Logs message: %w", err)
+		}
+		if msg.MessageType != cwLogsMessageTypeData && msg.MessageType != cwLogsMessageTypeControl {
+			return nil, fmt.Errorf("unknown CloudWatch Logs message type %q", msg.MessageType)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhubreceiver
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-amqp-common-go/v3/conn"
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the Azure Event Hub receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:"-"`
+
+	// Connection is the connection string of the Event Hub, including its EntityPath
+	Connection string `mapstructure:"connection"`
+
+	// ConsumerGroup is the consumer group the events are received in, $Default when empty
+	ConsumerGroup string `mapstructure:"consumer_group"`
+
+	// Partitions are the IDs of the partitions the events are received from, all of them when empty
+	Partitions []string `mapstructure:"partitions"`
+
+	// StartPosition is where the partitions without a checkpoint are received from: earliest or latest
+	StartPosition string `mapstructure:"start_position"`
+
+	// Format is the format of the events: azure for the diagnostic logs and metrics exported by the
+	// diagnostic settings, with the other events treated as raw, or raw for any data
+	Format string `mapstructure:"format"`
+
+	// Storage is the ID of the storage extension where the checkpoints of the partitions are persisted,
+	// so that the receiver resumes from them after a restart
+	Storage string `mapstructure:"storage"`
+}
+
+const (
+	startPositionEarliest = "earliest"
+	startPositionLatest   = "latest"
+
+	formatAzure = "azure"
+	formatRaw   = "raw"
+)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Connection == "" {
+		return fmt.Errorf("connection must not be empty")
+	}
+	parsed, err := conn.ParsedConnectionFromStr(cfg.Connection)
+	if err != nil {
+		return fmt.Errorf("invalid connection string: %w", err)
+	}
+	if parsed.HubName == "" {
+		return fmt.Errorf("connection string must include the EntityPath of the Event Hub")
+	}
+	switch cfg.StartPosition {
+	case startPositionEarliest, startPositionLatest:
+	default:
+		return fmt.Errorf("unknown start_position %q, must be one of: earliest, latest", cfg.StartPosition)
+	}
+	switch cfg.Format {
+	case formatAzure, formatRaw:
+	default:
+		return fmt.Errorf("unknown format %q, must be one of: azure, raw", cfg.Format)
+	}
+	if cfg.Storage != "" {
+		if _, err := config.NewIDFromString(cfg.Storage); err != nil {
+			return fmt.Errorf("invalid storage extension id %q: %w", cfg.Storage, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhubreceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+const testConnection = "Endpoint=sb://namespace.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;" +
+	"SharedAccessKey=c2VjcmV0;EntityPath=insights-logs"
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "azureeventhub_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	defaultCfg := factory.CreateDefaultConfig().(*Config)
+	defaultCfg.Connection = testConnection
+	assert.Equal(t, defaultCfg, cfg.Receivers[config.NewID(typeStr)])
+	assert.Equal(t, &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewIDWithName(typeStr, "all")),
+		Connection:       testConnection,
+		ConsumerGroup:    "otelcol",
+		Partitions:       []string{"0", "1"},
+		StartPosition:    "earliest",
+		Format:           "raw",
+		Storage:          "file_storage",
+	}, cfg.Receivers[config.NewIDWithName(typeStr, "all")])
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "default", modify: func(cfg *Config) {}},
+		{name: "raw format", modify: func(cfg *Config) { cfg.Format = "raw" }},
+		{name: "storage", modify: func(cfg *Config) { cfg.Storage = "file_storage/eventhub" }},
+		{
+			name:    "no connection",
+			modify:  func(cfg *Config) { cfg.Connection = "" },
+			wantErr: "connection must not be empty",
+		},
+		{
+			name:    "invalid connection",
+			modify:  func(cfg *Config) { cfg.Connection = "Endpoint=sb://namespace.servicebus.windows.net/" },
+			wantErr: `invalid connection string: key "SharedAccessKeyName" must not be empty`,
+		},
+		{
+			name: "namespace connection",
+			modify: func(cfg *Config) {
+				cfg.Connection = "Endpoint=sb://namespace.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=c2VjcmV0"
+			},
+			wantErr: "connection string must include the EntityPath of the Event Hub",
+		},
+		{
+			name:    "unknown start position",
+			modify:  func(cfg *Config) { cfg.StartPosition = "now" },
+			wantErr: `unknown start_position "now", must be one of: earliest, latest`,
+		},
+		{
+			name:    "unknown format",
+			modify:  func(cfg *Config) { cfg.Format = "json" },
+			wantErr: `unknown format "json", must be one of: azure, raw`,
+		},
+		{
+			name:    "invalid storage",
+			modify:  func(cfg *Config) { cfg.Storage = "file_storage/" },
+			wantErr: `invalid storage extension id "file_storage/": name part must be specified after / in type/name key`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Connection = testConnection
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
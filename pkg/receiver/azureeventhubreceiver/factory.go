@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhubreceiver
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+)
+
+const (
+	// The value of "type" Azure Event Hub receiver in configuration.
+	typeStr = "azureeventhub"
+)
+
+type factory struct {
+	// receivers are shared by the logs and metrics pipelines using the same configuration,
+	// as the diagnostic settings export both to the same Event Hub
+	receivers map[config.Receiver]*eventHubReceiver
+	lock      sync.Mutex
+}
+
+// NewFactory creates a factory for the Azure Event Hub receiver.
+func NewFactory() component.ReceiverFactory {
+	f := &factory{
+		receivers: map[config.Receiver]*eventHubReceiver{},
+	}
+
+	return receiverhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		receiverhelper.WithLogs(f.createLogsReceiver),
+		receiverhelper.WithMetrics(f.createMetricsReceiver),
+	)
+}
+
+func createDefaultConfig() config.Receiver {
+	return &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewID(typeStr)),
+		StartPosition:    startPositionLatest,
+		Format:           formatAzure,
+	}
+}
+
+// createLogsReceiver creates a logs receiver based on provided config.
+func (f *factory) createLogsReceiver(
+	_ context.Context,
+	params component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Logs,
+) (component.LogsReceiver, error) {
+	r, err := f.getReceiver(params, cfg)
+	if err != nil {
+		return nil, err
+	}
+	r.logsConsumer = nextConsumer
+	return r, nil
+}
+
+// createMetricsReceiver creates a metrics receiver based on provided config.
+func (f *factory) createMetricsReceiver(
+	_ context.Context,
+	params component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Metrics,
+) (component.MetricsReceiver, error) {
+	r, err := f.getReceiver(params, cfg)
+	if err != nil {
+		return nil, err
+	}
+	r.metricsConsumer = nextConsumer
+	return r, nil
+}
+
+func (f *factory) getReceiver(params component.ReceiverCreateSettings, cfg config.Receiver) (*eventHubReceiver, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if r, ok := f.receivers[cfg]; ok {
+		return r, nil
+	}
+
+	rCfg := cfg.(*Config)
+	if err := rCfg.Validate(); err != nil {
+		return nil, err
+	}
+	r := newEventHubReceiver(params.Logger, rCfg, newAzureHub)
+	f.receivers[cfg] = r
+	return r, nil
+}
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhubreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+
+	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/Azure/azure-event-hubs-go/v3/persist"
+	"go.opentelemetry.io/collector/extension/storage"
+)
+
+const (
+	// checkpointKey is the prefix of the storage keys holding the checkpoints, by Event Hub, consumer group and partition
+	checkpointKey = "checkpoint/"
+)
+
+var errNoCheckpoint = errors.New("no checkpoint")
+
+// hub receives the events of the partitions of an Event Hub
+type hub interface {
+	// partitionIDs returns the IDs of all the partitions of the Event Hub
+	partitionIDs(ctx context.Context) ([]string, error)
+	// receive starts receiving the events of the partition in the background, from its last checkpoint,
+	// the checkpoint is updated after the handler returns successfully
+	receive(ctx context.Context, partitionID string, handler eventhub.Handler) error
+	close(ctx context.Context) error
+}
+
+type azureHub struct {
+	hub     *eventhub.Hub
+	options []eventhub.ReceiveOption
+}
+
+var _ hub = (*azureHub)(nil)
+
+func newAzureHub(cfg *Config, persister persist.CheckpointPersister) (hub, error) {
+	h, err := eventhub.NewHubFromConnectionString(cfg.Connection, eventhub.HubWithOffsetPersistence(persister))
+	if err != nil {
+		return nil, err
+	}
+
+	var options []eventhub.ReceiveOption
+	// The consumer group is not set in the default configuration, as $Default would be expanded as
+	// an environment variable
+	if cfg.ConsumerGroup != "" {
+		options = append(options, eventhub.ReceiveWithConsumerGroup(cfg.ConsumerGroup))
+	}
+	// The starting offset only applies to the partitions without a checkpoint
+	if cfg.StartPosition == startPositionLatest {
+		options = append(options, eventhub.ReceiveWithLatestOffset())
+	} else {
+		options = append(options, eventhub.ReceiveWithStartingOffset(persist.StartOfStream))
+	}
+	return &azureHub{hub: h, options: options}, nil
+}
+
+func (h *azureHub) partitionIDs(ctx context.Context) ([]string, error) {
+	info, err := h.hub.GetRuntimeInformation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.PartitionIDs, nil
+}
+
+func (h *azureHub) receive(ctx context.Context, partitionID string, handler eventhub.Handler) error {
+	_, err := h.hub.Receive(ctx, partitionID, handler, h.options...)
+	return err
+}
+
+func (h *azureHub) close(ctx context.Context) error {
+	return h.hub.Close(ctx)
+}
+
+// storagePersister persists the checkpoints of the partitions with a storage extension
+type storagePersister struct {
+	client storage.Client
+}
+
+var _ persist.CheckpointPersister = (*storagePersister)(nil)
+
+func (p *storagePersister) key(namespace, name, consumerGroup, partitionID string) string {
+	return checkpointKey + path.Join(namespace, name, consumerGroup, partitionID)
+}
+
+func (p *storagePersister) Write(namespace, name, consumerGroup, partitionID string, checkpoint persist.Checkpoint) error {
+	value, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return p.client.Set(context.Background(), p.key(namespace, name, consumerGroup, partitionID), value)
+}
+
+// Read returns the checkpoint of the partition, or an error if there is none, so that the starting offset is used
+func (p *storagePersister) Read(namespace, name, consumerGroup, partitionID string) (persist.Checkpoint, error) {
+	value, err := p.client.Get(context.Background(), p.key(namespace, name, consumerGroup, partitionID))
+	if err != nil {
+		return persist.NewCheckpointFromStartOfStream(), fmt.Errorf("failed to read the checkpoint: %w", err)
+	}
+	if value == nil {
+		return persist.NewCheckpointFromStartOfStream(), errNoCheckpoint
+	}
+	var checkpoint persist.Checkpoint
+	if err := json.Unmarshal(value, &checkpoint); err != nil {
+		return persist.NewCheckpointFromStartOfStream(), fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
@@ -0,0 +1,187 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhubreceiver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/Azure/azure-event-hubs-go/v3/persist"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultRetryInterval is the time to wait before the data is passed again after a failure
+	defaultRetryInterval = time.Second
+)
+
+type eventHubReceiver struct {
+	config          *Config
+	logger          *zap.Logger
+	logsConsumer    consumer.Logs
+	metricsConsumer consumer.Metrics
+	newHub          func(*Config, persist.CheckpointPersister) (hub, error)
+	retryInterval   time.Duration
+
+	hub           hub
+	storageClient storage.Client
+	startOnce     sync.Once
+	startErr      error
+	shutdownOnce  sync.Once
+	shutdownErr   error
+}
+
+// Ensure this receiver adheres to required interfaces.
+var (
+	_ component.LogsReceiver    = (*eventHubReceiver)(nil)
+	_ component.MetricsReceiver = (*eventHubReceiver)(nil)
+)
+
+func newEventHubReceiver(
+	logger *zap.Logger,
+	cfg *Config,
+	newHub func(*Config, persist.CheckpointPersister) (hub, error),
+) *eventHubReceiver {
+	return &eventHubReceiver{
+		config:        cfg,
+		logger:        logger,
+		newHub:        newHub,
+		retryInterval: defaultRetryInterval,
+	}
+}
+
+// Start connects to the Event Hub and starts receiving the events of its partitions.
+// The receiver is shared by the pipelines, it is started only once.
+func (r *eventHubReceiver) Start(ctx context.Context, host component.Host) error {
+	r.startOnce.Do(func() {
+		r.startErr = r.start(ctx, host)
+	})
+	return r.startErr
+}
+
+func (r *eventHubReceiver) start(ctx context.Context, host component.Host) error {
+	var persister persist.CheckpointPersister = persist.NewMemoryPersister()
+	if r.config.Storage != "" {
+		client, err := r.getStorageClient(ctx, host)
+		if err != nil {
+			return err
+		}
+		r.storageClient = client
+		persister = &storagePersister{client: client}
+	}
+
+	h, err := r.newHub(r.config, persister)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the Event Hub: %w", err)
+	}
+	r.hub = h
+
+	partitions := r.config.Partitions
+	if len(partitions) == 0 {
+		if partitions, err = h.partitionIDs(ctx); err != nil {
+			return fmt.Errorf("failed to get the partitions of the Event Hub: %w", err)
+		}
+	}
+	for _, partitionID := range partitions {
+		if err := h.receive(ctx, partitionID, r.handleEvent); err != nil {
+			return fmt.Errorf("failed to receive the events of the partition %q: %w", partitionID, err)
+		}
+	}
+	return nil
+}
+
+// Shutdown stops receiving the events
+func (r *eventHubReceiver) Shutdown(ctx context.Context) error {
+	r.shutdownOnce.Do(func() {
+		if r.hub != nil {
+			r.shutdownErr = r.hub.close(ctx)
+		}
+		if r.storageClient != nil {
+			if err := r.storageClient.Close(ctx); err != nil && r.shutdownErr == nil {
+				r.shutdownErr = err
+			}
+		}
+	})
+	return r.shutdownErr
+}
+
+func (r *eventHubReceiver) getStorageClient(ctx context.Context, host component.Host) (storage.Client, error) {
+	id, err := config.NewIDFromString(r.config.Storage)
+	if err != nil {
+		return nil, err
+	}
+	extension, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", r.config.Storage)
+	}
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", r.config.Storage)
+	}
+	return storageExtension.GetClient(ctx, component.KindReceiver, r.config.ID(), "")
+}
+
+// handleEvent converts the event and passes it on. The failures are retried, so that the checkpoint
+// of the partition only moves past the events which have been passed on.
+func (r *eventHubReceiver) handleEvent(ctx context.Context, event *eventhub.Event) error {
+	logs, metrics := convertEvent(event, r.config.Format)
+
+	if logs.ResourceLogs().Len() > 0 && r.logsConsumer != nil {
+		err := r.consume(ctx, "logs", func(ctx context.Context) error {
+			return r.logsConsumer.ConsumeLogs(ctx, logs)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if metrics.ResourceMetrics().Len() > 0 && r.metricsConsumer != nil {
+		err := r.consume(ctx, "metrics", func(ctx context.Context) error {
+			return r.metricsConsumer.ConsumeMetrics(ctx, metrics)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// consume retries passing the data on until it succeeds, fails permanently or the context is done
+func (r *eventHubReceiver) consume(ctx context.Context, dataType string, consume func(context.Context) error) error {
+	for {
+		err := consume(ctx)
+		if err == nil {
+			return nil
+		}
+		if consumererror.IsPermanent(err) {
+			r.logger.Error("Failed to send the data, dropping it", zap.String("type", dataType), zap.Error(err))
+			return nil
+		}
+		r.logger.Warn("Failed to send the data, retrying", zap.String("type", dataType), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.retryInterval):
+		}
+	}
+}
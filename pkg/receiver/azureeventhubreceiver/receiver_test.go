@@ -0,0 +1,252 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhubreceiver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/Azure/azure-event-hubs-go/v3/persist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// fakeHub records the handlers of the partitions, the events are delivered to them by the tests
+type fakeHub struct {
+	partitions []string
+	persister  persist.CheckpointPersister
+	handlers   map[string]eventhub.Handler
+	closed     bool
+}
+
+func (h *fakeHub) partitionIDs(context.Context) ([]string, error) {
+	return h.partitions, nil
+}
+
+func (h *fakeHub) receive(_ context.Context, partitionID string, handler eventhub.Handler) error {
+	h.handlers[partitionID] = handler
+	return nil
+}
+
+func (h *fakeHub) close(context.Context) error {
+	h.closed = true
+	return nil
+}
+
+// deliver passes the event to the handler of the partition, and updates the checkpoint when it succeeds
+func (h *fakeHub) deliver(ctx context.Context, partitionID string, offset string, event *eventhub.Event) error {
+	if err := h.handlers[partitionID](ctx, event); err != nil {
+		return err
+	}
+	return h.persister.Write("namespace", "insights-logs", "$Default", partitionID, persist.NewCheckpoint(offset, 0, testEnqueuedTime))
+}
+
+func newTestReceiver(t *testing.T, cfg *Config) (*eventHubReceiver, *fakeHub) {
+	fake := &fakeHub{partitions: []string{"0", "1"}, handlers: map[string]eventhub.Handler{}}
+	hubs := 0
+	r := newEventHubReceiver(zap.NewNop(), cfg, func(_ *Config, persister persist.CheckpointPersister) (hub, error) {
+		hubs++
+		require.Equal(t, 1, hubs, "the hub is created only once")
+		fake.persister = persister
+		return fake, nil
+	})
+	r.retryInterval = time.Millisecond
+	return r, fake
+}
+
+func newTestConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Connection = testConnection
+	return cfg
+}
+
+func TestReceiverLogsAndMetrics(t *testing.T) {
+	r, fake := newTestReceiver(t, newTestConfig())
+	logsSink := new(consumertest.LogsSink)
+	metricsSink := new(consumertest.MetricsSink)
+	r.logsConsumer = logsSink
+	r.metricsConsumer = metricsSink
+
+	host := componenttest.NewNopHost()
+	require.NoError(t, r.Start(context.Background(), host))
+	require.NoError(t, r.Start(context.Background(), host), "the receiver is shared by the pipelines")
+	assert.Len(t, fake.handlers, 2, "all the partitions are received")
+
+	ctx := context.Background()
+	require.NoError(t, fake.deliver(ctx, "0", "100", readTestEvent(t, "diagnostic_logs.json")))
+	require.NoError(t, fake.deliver(ctx, "1", "200", readTestEvent(t, "diagnostic_metrics.json")))
+	assert.Equal(t, 3, logsSink.LogRecordCount())
+	assert.Equal(t, 2, metricsSink.DataPointCount())
+
+	checkpoint, err := fake.persister.Read("namespace", "insights-logs", "$Default", "0")
+	require.NoError(t, err)
+	assert.Equal(t, "100", checkpoint.Offset)
+
+	require.NoError(t, r.Shutdown(ctx))
+	require.NoError(t, r.Shutdown(ctx))
+	assert.True(t, fake.closed)
+}
+
+func TestReceiverPartitions(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Partitions = []string{"1"}
+	r, fake := newTestReceiver(t, cfg)
+	r.logsConsumer = consumertest.NewNop()
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Len(t, fake.handlers, 1)
+	assert.Contains(t, fake.handlers, "1")
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestReceiverWithoutConsumer(t *testing.T) {
+	r, fake := newTestReceiver(t, newTestConfig())
+	sink := new(consumertest.LogsSink)
+	r.logsConsumer = sink
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, fake.deliver(context.Background(), "0", "1", readTestEvent(t, "diagnostic_metrics.json")),
+		"the metrics are dropped without a metrics pipeline")
+	assert.Equal(t, 0, sink.LogRecordCount())
+}
+
+// failingLogsConsumer fails with the errors, one for each call, before passing the logs on
+type failingLogsConsumer struct {
+	consumertest.LogsSink
+	mu   sync.Mutex
+	errs []error
+}
+
+var _ consumer.Logs = (*failingLogsConsumer)(nil)
+
+func (c *failingLogsConsumer) ConsumeLogs(ctx context.Context, logs pdata.Logs) error {
+	c.mu.Lock()
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		c.mu.Unlock()
+		return err
+	}
+	c.mu.Unlock()
+	return c.LogsSink.ConsumeLogs(ctx, logs)
+}
+
+func TestReceiverRetry(t *testing.T) {
+	r, fake := newTestReceiver(t, newTestConfig())
+	logsConsumer := &failingLogsConsumer{errs: []error{errors.New("queue is full"), errors.New("queue is full")}}
+	r.logsConsumer = logsConsumer
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, fake.deliver(context.Background(), "0", "1", newTestEvent([]byte("first"))))
+	assert.Equal(t, 1, logsConsumer.LogRecordCount(), "the logs are passed on after the failures")
+
+	logsConsumer.errs = []error{consumererror.Permanent(errors.New("invalid data"))}
+	require.NoError(t, fake.deliver(context.Background(), "0", "2", newTestEvent([]byte("second"))))
+	assert.Equal(t, 1, logsConsumer.LogRecordCount(), "the logs are dropped after a permanent failure")
+
+	logsConsumer.errs = []error{errors.New("queue is full")}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := fake.deliver(ctx, "0", "3", newTestEvent([]byte("third")))
+	assert.ErrorIs(t, err, context.Canceled)
+	checkpoint, err := fake.persister.Read("namespace", "insights-logs", "$Default", "0")
+	require.NoError(t, err)
+	assert.Equal(t, "2", checkpoint.Offset, "the checkpoint is not updated when the receiver stops")
+}
+
+func TestReceiverStorage(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Storage = "file_storage"
+	r, fake := newTestReceiver(t, cfg)
+	r.logsConsumer = consumertest.NewNop()
+
+	client := &testStorageClient{data: map[string][]byte{}}
+	host := &testHost{extensions: map[config.ComponentID]component.Extension{
+		config.NewID("file_storage"): &testStorageExtension{client: client},
+	}}
+	require.NoError(t, r.Start(context.Background(), host))
+
+	_, err := fake.persister.Read("namespace", "insights-logs", "$Default", "0")
+	assert.Equal(t, errNoCheckpoint, err, "the starting offset is used without a checkpoint")
+
+	require.NoError(t, fake.deliver(context.Background(), "0", "42", newTestEvent([]byte("event"))))
+	assert.Contains(t, client.data, "checkpoint/namespace/insights-logs/$Default/0")
+	checkpoint, err := fake.persister.Read("namespace", "insights-logs", "$Default", "0")
+	require.NoError(t, err)
+	assert.Equal(t, persist.NewCheckpoint("42", 0, testEnqueuedTime), checkpoint)
+
+	require.NoError(t, r.Shutdown(context.Background()))
+	assert.True(t, client.closed)
+}
+
+func TestReceiverStorageNotFound(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Storage = "file_storage"
+	r, _ := newTestReceiver(t, cfg)
+
+	host := &testHost{extensions: map[config.ComponentID]component.Extension{}}
+	assert.EqualError(t, r.Start(context.Background(), host), `storage extension "file_storage" not found`)
+}
+
+type testStorageExtension struct {
+	component.Extension
+	client *testStorageClient
+}
+
+func (e *testStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return e.client, nil
+}
+
+type testStorageClient struct {
+	storage.Client
+	data   map[string][]byte
+	closed bool
+}
+
+func (c *testStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	return c.data[key], nil
+}
+
+func (c *testStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.data[key] = value
+	return nil
+}
+
+func (c *testStorageClient) Close(context.Context) error {
+	c.closed = true
+	return nil
+}
+
+type testHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
@@ -0,0 +1,386 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhubreceiver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	attributeCloudProvider     = "cloud.provider"
+	attributeCloudAccountID    = "cloud.account.id"
+	attributeCloudRegion       = "cloud.region"
+	attributeTenantID          = "azure.tenant.id"
+	attributeResourceID        = "azure.resource.id"
+	attributeResourceGroup     = "azure.resource_group.name"
+	attributeResourceProvider  = "azure.resource.provider"
+	attributeResourceType      = "azure.resource.type"
+	attributeResourceName      = "azure.resource.name"
+	attributeCategory          = "azure.category"
+	attributeOperationName     = "azure.operation.name"
+	attributeResultType        = "azure.result.type"
+	attributeCorrelationID     = "azure.correlation.id"
+	attributeCallerIPAddress   = "net.peer.ip"
+	attributeMetricTimeGrain   = "azure.time_grain"
+	cloudProviderAzure         = "azure"
+	localTimeWithoutZoneLayout = "2006-01-02T15:04:05.999999999"
+)
+
+var (
+	severityNumbers = map[string]pdata.SeverityNumber{
+		"verbose":       pdata.SeverityNumberDEBUG,
+		"debug":         pdata.SeverityNumberDEBUG,
+		"informational": pdata.SeverityNumberINFO,
+		"information":   pdata.SeverityNumberINFO,
+		"info":          pdata.SeverityNumberINFO,
+		"warning":       pdata.SeverityNumberWARN,
+		"warn":          pdata.SeverityNumberWARN,
+		"error":         pdata.SeverityNumberERROR,
+		"critical":      pdata.SeverityNumberFATAL,
+	}
+
+	timeGrainRegexp = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+)
+
+// diagnosticRecords is the body of the events sent by the diagnostic settings
+type diagnosticRecords struct {
+	Records []map[string]interface{} `json:"records"`
+}
+
+// telemetryBuilder converts the diagnostic records of an event into logs and metrics,
+// with a resource for each Azure resource
+type telemetryBuilder struct {
+	logs            pdata.Logs
+	metrics         pdata.Metrics
+	enqueuedTime    pdata.Timestamp
+	resourceLogs    map[string]pdata.LogSlice
+	resourceMetrics map[string]pdata.MetricSlice
+}
+
+// convertEvent converts the event into logs and metrics, depending on the format
+func convertEvent(event *eventhub.Event, format string) (pdata.Logs, pdata.Metrics) {
+	b := &telemetryBuilder{
+		logs:            pdata.NewLogs(),
+		metrics:         pdata.NewMetrics(),
+		resourceLogs:    map[string]pdata.LogSlice{},
+		resourceMetrics: map[string]pdata.MetricSlice{},
+	}
+	if event.SystemProperties != nil && event.SystemProperties.EnqueuedTime != nil {
+		b.enqueuedTime = pdata.TimestampFromTime(*event.SystemProperties.EnqueuedTime)
+	}
+
+	if format == formatAzure {
+		if records, ok := parseDiagnosticRecords(event.Data); ok {
+			for _, record := range records {
+				if _, ok := record["metricName"]; ok {
+					b.appendMetric(record)
+				} else {
+					b.appendLog(record)
+				}
+			}
+			return b.logs, b.metrics
+		}
+	}
+	b.appendRawLog(event)
+	return b.logs, b.metrics
+}
+
+// parseDiagnosticRecords returns the records of the event, if it was sent by the diagnostic settings
+func parseDiagnosticRecords(data []byte) ([]map[string]interface{}, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var body diagnosticRecords
+	if err := decoder.Decode(&body); err != nil || body.Records == nil {
+		return nil, false
+	}
+	return body.Records, true
+}
+
+func (b *telemetryBuilder) newResource(resource pdata.Resource, resourceID string, record map[string]interface{}) {
+	attrs := resource.Attributes()
+	attrs.UpsertString(attributeCloudProvider, cloudProviderAzure)
+	setResourceIDAttributes(attrs, resourceID)
+	if location := stringField(record, "location"); location != "" {
+		attrs.UpsertString(attributeCloudRegion, location)
+	}
+	if tenantID := stringField(record, "tenantId"); tenantID != "" {
+		attrs.UpsertString(attributeTenantID, tenantID)
+	}
+}
+
+func (b *telemetryBuilder) logSlice(record map[string]interface{}) pdata.LogSlice {
+	resourceID := stringField(record, "resourceId")
+	if logs, ok := b.resourceLogs[resourceID]; ok {
+		return logs
+	}
+	rl := b.logs.ResourceLogs().AppendEmpty()
+	b.newResource(rl.Resource(), resourceID, record)
+	logs := rl.InstrumentationLibraryLogs().AppendEmpty().Logs()
+	b.resourceLogs[resourceID] = logs
+	return logs
+}
+
+func (b *telemetryBuilder) metricSlice(record map[string]interface{}) pdata.MetricSlice {
+	resourceID := stringField(record, "resourceId")
+	if metrics, ok := b.resourceMetrics[resourceID]; ok {
+		return metrics
+	}
+	rm := b.metrics.ResourceMetrics().AppendEmpty()
+	b.newResource(rm.Resource(), resourceID, record)
+	metrics := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	b.resourceMetrics[resourceID] = metrics
+	return metrics
+}
+
+// appendLog adds a log with the diagnostic log record as the body
+func (b *telemetryBuilder) appendLog(record map[string]interface{}) {
+	lr := b.logSlice(record).AppendEmpty()
+	lr.SetTimestamp(b.timestamp(record))
+	if level := stringField(record, "level"); level != "" {
+		lr.SetSeverityText(level)
+		lr.SetSeverityNumber(severityNumbers[strings.ToLower(level)])
+	}
+	setValue(lr.Body(), record)
+
+	attrs := lr.Attributes()
+	for attribute, field := range map[string]string{
+		attributeCategory:        "category",
+		attributeOperationName:   "operationName",
+		attributeResultType:      "resultType",
+		attributeCorrelationID:   "correlationId",
+		attributeCallerIPAddress: "callerIpAddress",
+	} {
+		if value := stringField(record, field); value != "" {
+			attrs.UpsertString(attribute, value)
+		}
+	}
+	attrs.Sort()
+}
+
+// appendMetric adds a summary with the aggregations of the diagnostic metric record over its time grain:
+// the count, the total as the sum, and the minimum and maximum as the 0 and 1 quantiles
+func (b *telemetryBuilder) appendMetric(record map[string]interface{}) {
+	metric := b.metricSlice(record).AppendEmpty()
+	metric.SetName(stringField(record, "metricName"))
+	metric.SetDataType(pdata.MetricDataTypeSummary)
+
+	dp := metric.Summary().DataPoints().AppendEmpty()
+	start := b.timestamp(record)
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(start)
+	if timeGrain := stringField(record, "timeGrain"); timeGrain != "" {
+		dp.Attributes().UpsertString(attributeMetricTimeGrain, timeGrain)
+		if duration, ok := parseTimeGrain(timeGrain); ok {
+			dp.SetTimestamp(start + pdata.Timestamp(duration.Nanoseconds()))
+		}
+	}
+
+	if count, ok := numberField(record, "count"); ok && count > 0 {
+		dp.SetCount(uint64(count))
+	}
+	if total, ok := numberField(record, "total"); ok {
+		dp.SetSum(total)
+	}
+	quantiles := dp.QuantileValues()
+	if minimum, ok := numberField(record, "minimum"); ok {
+		q := quantiles.AppendEmpty()
+		q.SetQuantile(0)
+		q.SetValue(minimum)
+	}
+	if maximum, ok := numberField(record, "maximum"); ok {
+		q := quantiles.AppendEmpty()
+		q.SetQuantile(1)
+		q.SetValue(maximum)
+	}
+}
+
+// appendRawLog adds a log with the data of the event as the body and its properties as the attributes
+func (b *telemetryBuilder) appendRawLog(event *eventhub.Event) {
+	rl := b.logs.ResourceLogs().AppendEmpty()
+	lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	lr.SetTimestamp(b.enqueuedTime)
+	lr.Body().SetStringVal(string(event.Data))
+
+	attrs := lr.Attributes()
+	for k, v := range event.Properties {
+		switch value := v.(type) {
+		case string:
+			attrs.UpsertString(k, value)
+		case bool:
+			attrs.UpsertBool(k, value)
+		case int:
+			attrs.UpsertInt(k, int64(value))
+		case int32:
+			attrs.UpsertInt(k, int64(value))
+		case int64:
+			attrs.UpsertInt(k, value)
+		case float32:
+			attrs.UpsertDouble(k, float64(value))
+		case float64:
+			attrs.UpsertDouble(k, value)
+		default:
+			attrs.UpsertString(k, fmt.Sprint(value))
+		}
+	}
+	attrs.Sort()
+}
+
+// timestamp returns the time of the record, or the enqueued time of the event if it is missing or invalid
+func (b *telemetryBuilder) timestamp(record map[string]interface{}) pdata.Timestamp {
+	value := stringField(record, "time")
+	if value == "" {
+		return b.enqueuedTime
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		// Some of the services omit the time zone, the times are in UTC
+		if t, err = time.Parse(localTimeWithoutZoneLayout, value); err != nil {
+			return b.enqueuedTime
+		}
+	}
+	return pdata.TimestampFromTime(t)
+}
+
+// setResourceIDAttributes sets the attributes of the parts of the Azure resource ID, e.g.
+// /subscriptions/<subscription>/resourceGroups/<group>/providers/Microsoft.Web/sites/<name>
+func setResourceIDAttributes(attrs pdata.AttributeMap, resourceID string) {
+	if resourceID == "" {
+		return
+	}
+	attrs.UpsertString(attributeResourceID, resourceID)
+
+	parts := strings.Split(strings.Trim(resourceID, "/"), "/")
+	for i := 0; i+1 < len(parts); i += 2 {
+		switch strings.ToLower(parts[i]) {
+		case "subscriptions":
+			attrs.UpsertString(attributeCloudAccountID, parts[i+1])
+		case "resourcegroups":
+			attrs.UpsertString(attributeResourceGroup, parts[i+1])
+		case "providers":
+			// The provider is followed by the pairs of types and names of the resource and its parents
+			provider := parts[i+1]
+			types := []string{provider}
+			name := ""
+			for j := i + 2; j+1 < len(parts); j += 2 {
+				types = append(types, parts[j])
+				name = parts[j+1]
+			}
+			attrs.UpsertString(attributeResourceProvider, provider)
+			if len(types) > 1 {
+				attrs.UpsertString(attributeResourceType, strings.Join(types, "/"))
+				attrs.UpsertString(attributeResourceName, name)
+			}
+			return
+		}
+	}
+}
+
+// parseTimeGrain parses the ISO 8601 duration of the time grain of the metrics, e.g. PT1M
+func parseTimeGrain(value string) (time.Duration, bool) {
+	match := timeGrainRegexp.FindStringSubmatch(strings.ToUpper(value))
+	if match == nil {
+		return 0, false
+	}
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return 0, false
+		}
+		duration += time.Duration(n) * unit
+	}
+	return duration, duration > 0
+}
+
+// stringField returns the string value of the field of the record, the name is matched case-insensitively
+// as it differs between the services, e.g. resourceId and ResourceId
+func stringField(record map[string]interface{}, name string) string {
+	value, _ := field(record, name).(string)
+	return value
+}
+
+func numberField(record map[string]interface{}, name string) (float64, bool) {
+	number, ok := field(record, name).(json.Number)
+	if !ok {
+		return 0, false
+	}
+	value, err := number.Float64()
+	return value, err == nil
+}
+
+func field(record map[string]interface{}, name string) interface{} {
+	if value, ok := record[name]; ok {
+		return value
+	}
+	for k, value := range record {
+		if strings.EqualFold(k, name) {
+			return value
+		}
+	}
+	return nil
+}
+
+// setValue sets the attribute to the value decoded from JSON
+func setValue(attribute pdata.AttributeValue, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		pdata.NewAttributeValueNull().CopyTo(attribute)
+	case string:
+		attribute.SetStringVal(v)
+	case bool:
+		attribute.SetBoolVal(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			attribute.SetIntVal(i)
+		} else if f, err := v.Float64(); err == nil {
+			attribute.SetDoubleVal(f)
+		} else {
+			attribute.SetStringVal(v.String())
+		}
+	case []interface{}:
+		pdata.NewAttributeValueArray().CopyTo(attribute)
+		values := attribute.ArrayVal()
+		for _, item := range v {
+			setValue(values.AppendEmpty(), item)
+		}
+	case map[string]interface{}:
+		pdata.NewAttributeValueMap().CopyTo(attribute)
+		values := attribute.MapVal()
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			values.InsertNull(k)
+			item, _ := values.Get(k)
+			setValue(item, v[k])
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureeventhubreceiver
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+	"time"
+
+	eventhub "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+var testEnqueuedTime = time.Date(2021, 8, 30, 10, 20, 0, 0, time.UTC)
+
+func newTestEvent(data []byte) *eventhub.Event {
+	return &eventhub.Event{
+		Data:             data,
+		SystemProperties: &eventhub.SystemProperties{EnqueuedTime: &testEnqueuedTime},
+	}
+}
+
+func readTestEvent(t *testing.T, name string) *eventhub.Event {
+	data, err := ioutil.ReadFile(path.Join("testdata", name))
+	require.NoError(t, err)
+	return newTestEvent(data)
+}
+
+func TestConvertDiagnosticLogs(t *testing.T) {
+	logs, metrics := convertEvent(readTestEvent(t, "diagnostic_logs.json"), formatAzure)
+	assert.Equal(t, 0, metrics.ResourceMetrics().Len())
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+
+	site := logs.ResourceLogs().At(0)
+	assert.Equal(t, map[string]interface{}{
+		"cloud.provider":            "azure",
+		"cloud.account.id":          "3B0F1F7A-1234-4C3A-9E5D-0123456789AB",
+		"cloud.region":              "westeurope",
+		"azure.resource.id":         "/SUBSCRIPTIONS/3B0F1F7A-1234-4C3A-9E5D-0123456789AB/RESOURCEGROUPS/WEB-RG/PROVIDERS/MICROSOFT.WEB/SITES/SHOP",
+		"azure.resource_group.name": "WEB-RG",
+		"azure.resource.provider":   "MICROSOFT.WEB",
+		"azure.resource.type":       "MICROSOFT.WEB/SITES",
+		"azure.resource.name":       "SHOP",
+	}, attributesToMap(site.Resource().Attributes()))
+
+	siteLogs := site.InstrumentationLibraryLogs().At(0).Logs()
+	require.Equal(t, 2, siteLogs.Len())
+	lr := siteLogs.At(0)
+	assert.Equal(t, time.Date(2021, 8, 30, 10, 15, 3, 123456700, time.UTC), lr.Timestamp().AsTime())
+	assert.Equal(t, "Informational", lr.SeverityText())
+	assert.Equal(t, pdata.SeverityNumberINFO, lr.SeverityNumber())
+	assert.Equal(t, map[string]interface{}{
+		"azure.category":       "AppServiceHTTPLogs",
+		"azure.operation.name": "Microsoft.Web/sites/log",
+	}, attributesToMap(lr.Attributes()))
+	require.Equal(t, pdata.AttributeValueTypeMap, lr.Body().Type())
+	body := attributesToMap(lr.Body().MapVal())
+	assert.Equal(t, "AppServiceHTTPLogs", body["category"])
+	assert.Equal(t, map[string]interface{}{
+		"CsMethod":  "GET",
+		"CsUriStem": "/cart",
+		"ScStatus":  int64(200),
+		"TimeTaken": int64(12),
+	}, body["properties"])
+	assert.Equal(t, pdata.SeverityNumberERROR, siteLogs.At(1).SeverityNumber())
+
+	database := logs.ResourceLogs().At(1)
+	assert.Equal(t, map[string]interface{}{
+		"cloud.provider":            "azure",
+		"cloud.account.id":          "3b0f1f7a-1234-4c3a-9e5d-0123456789ab",
+		"azure.tenant.id":           "72f988bf-86f1-41af-91ab-2d7cd011db47",
+		"azure.resource.id":         "/subscriptions/3b0f1f7a-1234-4c3a-9e5d-0123456789ab/resourceGroups/web-rg/providers/Microsoft.Sql/servers/db-server/databases/orders",
+		"azure.resource_group.name": "web-rg",
+		"azure.resource.provider":   "Microsoft.Sql",
+		"azure.resource.type":       "Microsoft.Sql/servers/databases",
+		"azure.resource.name":       "orders",
+	}, attributesToMap(database.Resource().Attributes()))
+
+	lr = database.InstrumentationLibraryLogs().At(0).Logs().At(0)
+	assert.Equal(t, "Warning", lr.SeverityText(), "the field names are matched case-insensitively")
+	assert.Equal(t, pdata.SeverityNumberWARN, lr.SeverityNumber())
+	assert.Equal(t, map[string]interface{}{
+		"azure.category":       "Administrative",
+		"azure.operation.name": "MICROSOFT.SQL/SERVERS/DATABASES/WRITE",
+		"azure.result.type":    "Success",
+		"azure.correlation.id": "c8b5d0f4-9f2b-4a47-8d8e-5a1c2c3d4e5f",
+		"net.peer.ip":          "203.0.113.10",
+	}, attributesToMap(lr.Attributes()))
+}
+
+func TestConvertDiagnosticMetrics(t *testing.T) {
+	logs, metrics := convertEvent(readTestEvent(t, "diagnostic_metrics.json"), formatAzure)
+	assert.Equal(t, 0, logs.ResourceLogs().Len())
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+
+	rm := metrics.ResourceMetrics().At(0)
+	assert.Equal(t, "SHOP", attributesToMap(rm.Resource().Attributes())["azure.resource.name"])
+	ms := rm.InstrumentationLibraryMetrics().At(0).Metrics()
+	require.Equal(t, 2, ms.Len())
+
+	metric := ms.At(0)
+	assert.Equal(t, "CpuTime", metric.Name())
+	require.Equal(t, pdata.MetricDataTypeSummary, metric.DataType())
+	dp := metric.Summary().DataPoints().At(0)
+	assert.Equal(t, time.Date(2021, 8, 30, 10, 15, 0, 0, time.UTC), dp.StartTimestamp().AsTime())
+	assert.Equal(t, time.Date(2021, 8, 30, 10, 16, 0, 0, time.UTC), dp.Timestamp().AsTime())
+	assert.Equal(t, uint64(4), dp.Count())
+	assert.Equal(t, 18.5, dp.Sum())
+	require.Equal(t, 2, dp.QuantileValues().Len())
+	assert.Equal(t, 0.0, dp.QuantileValues().At(0).Quantile())
+	assert.Equal(t, 2.0, dp.QuantileValues().At(0).Value())
+	assert.Equal(t, 1.0, dp.QuantileValues().At(1).Quantile())
+	assert.Equal(t, 8.25, dp.QuantileValues().At(1).Value())
+	assert.Equal(t, map[string]interface{}{"azure.time_grain": "PT1M"}, attributesToMap(dp.Attributes()))
+
+	assert.Equal(t, "BytesSent", ms.At(1).Name())
+}
+
+func TestConvertMixedRecords(t *testing.T) {
+	event := newTestEvent([]byte(`{"records": [
+		{"resourceId": "/subscriptions/sub/resourceGroups/rg", "category": "Audit", "time": "2021-08-30T10:15:00"},
+		{"resourceId": "/subscriptions/sub/resourceGroups/rg", "metricName": "Requests", "count": 2, "total": 2}
+	]}`))
+	logs, metrics := convertEvent(event, formatAzure)
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+
+	lr := logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0)
+	assert.Equal(t, time.Date(2021, 8, 30, 10, 15, 0, 0, time.UTC), lr.Timestamp().AsTime(), "the time without zone is in UTC")
+	assert.Equal(t, pdata.SeverityNumberUNDEFINED, lr.SeverityNumber())
+
+	dp := metrics.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Summary().DataPoints().At(0)
+	assert.Equal(t, testEnqueuedTime, dp.Timestamp().AsTime(), "the enqueued time is used without the time of the record")
+	assert.Equal(t, 0, dp.QuantileValues().Len())
+}
+
+func TestConvertRawEvents(t *testing.T) {
+	testcases := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{name: "raw format", data: `{"records": []}`, format: formatRaw},
+		{name: "text", data: "GET /cart 200", format: formatAzure},
+		{name: "other JSON", data: `{"message": "started"}`, format: formatAzure},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			event := newTestEvent([]byte(tc.data))
+			event.Properties = map[string]interface{}{"source": "app", "retry": int64(2), "sampled": true}
+
+			logs, metrics := convertEvent(event, tc.format)
+			assert.Equal(t, 0, metrics.ResourceMetrics().Len())
+			require.Equal(t, 1, logs.ResourceLogs().Len())
+			lr := logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0)
+			assert.Equal(t, tc.data, lr.Body().StringVal())
+			assert.Equal(t, testEnqueuedTime, lr.Timestamp().AsTime())
+			assert.Equal(t, map[string]interface{}{
+				"source":  "app",
+				"retry":   int64(2),
+				"sampled": true,
+			}, attributesToMap(lr.Attributes()))
+		})
+	}
+}
+
+func TestSetResourceIDAttributes(t *testing.T) {
+	testcases := []struct {
+		resourceID string
+		expected   map[string]interface{}
+	}{
+		{
+			resourceID: "/subscriptions/sub",
+			expected: map[string]interface{}{
+				"azure.resource.id": "/subscriptions/sub",
+				"cloud.account.id":  "sub",
+			},
+		},
+		{
+			resourceID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1",
+			expected: map[string]interface{}{
+				"azure.resource.id":         "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1",
+				"cloud.account.id":          "sub",
+				"azure.resource_group.name": "rg",
+				"azure.resource.provider":   "Microsoft.Compute",
+				"azure.resource.type":       "Microsoft.Compute/virtualMachines",
+				"azure.resource.name":       "vm-1",
+			},
+		},
+		{
+			resourceID: "/tenants/tenant/providers/Microsoft.aadiam",
+			expected: map[string]interface{}{
+				"azure.resource.id":       "/tenants/tenant/providers/Microsoft.aadiam",
+				"azure.resource.provider": "Microsoft.aadiam",
+			},
+		},
+		{resourceID: "", expected: map[string]interface{}{}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.resourceID, func(t *testing.T) {
+			attrs := pdata.NewAttributeMap()
+			setResourceIDAttributes(attrs, tc.resourceID)
+			assert.Equal(t, tc.expected, attributesToMap(attrs))
+		})
+	}
+}
+
+func TestParseTimeGrain(t *testing.T) {
+	testcases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "PT1M", expected: time.Minute, ok: true},
+		{value: "PT1H30M", expected: 90 * time.Minute, ok: true},
+		{value: "P1D", expected: 24 * time.Hour, ok: true},
+		{value: "pt30s", expected: 30 * time.Second, ok: true},
+		{value: "PT", ok: false},
+		{value: "1m", ok: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			duration, ok := parseTimeGrain(tc.value)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, duration)
+		})
+	}
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]interface{} {
+	result := map[string]interface{}{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = valueToInterface(v)
+		return true
+	})
+	return result
+}
+
+func valueToInterface(v pdata.AttributeValue) interface{} {
+	switch v.Type() {
+	case pdata.AttributeValueTypeInt:
+		return v.IntVal()
+	case pdata.AttributeValueTypeDouble:
+		return v.DoubleVal()
+	case pdata.AttributeValueTypeBool:
+		return v.BoolVal()
+	case pdata.AttributeValueTypeMap:
+		return attributesToMap(v.MapVal())
+	case pdata.AttributeValueTypeArray:
+		values := []interface{}{}
+		for i := 0; i < v.ArrayVal().Len(); i++ {
+			values = append(values, valueToInterface(v.ArrayVal().At(i)))
+		}
+		return values
+	case pdata.AttributeValueTypeNull:
+		return nil
+	default:
+		return v.StringVal()
+	}
+}
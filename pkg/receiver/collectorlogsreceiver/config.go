@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectorlogsreceiver
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config defines configuration for the collector logs receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:"-"`
+
+	// Level is the lowest level of the received log entries, e.g. info
+	Level string `mapstructure:"level"`
+	// MaxBatchSize is the maximum number of entries sent in a single batch
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+	// QueueSize is the number of entries buffered before they are sent, the entries are dropped
+	// when the queue is full, so that logging never blocks the collector
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+const (
+	defaultLevel        = "info"
+	defaultMaxBatchSize = 100
+	defaultQueueSize    = 1000
+)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if _, err := parseLevel(cfg.Level); err != nil {
+		return err
+	}
+	if cfg.MaxBatchSize <= 0 {
+		return fmt.Errorf("max_batch_size must be positive, got %d", cfg.MaxBatchSize)
+	}
+	if cfg.QueueSize <= 0 {
+		return fmt.Errorf("queue_size must be positive, got %d", cfg.QueueSize)
+	}
+	return nil
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return l, fmt.Errorf("level must be one of debug, info, warn, error, dpanic, panic or fatal, got %q", level)
+	}
+	return l, nil
+}
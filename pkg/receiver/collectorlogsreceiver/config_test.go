@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectorlogsreceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "collector_logs_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Receivers[config.NewID(typeStr)], factory.CreateDefaultConfig())
+
+	id := config.NewIDWithName(typeStr, "custom")
+	assert.Equal(t, cfg.Receivers[id],
+		&Config{
+			ReceiverSettings: config.NewReceiverSettings(id),
+			Level:            "warn",
+			MaxBatchSize:     50,
+			QueueSize:        500,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.NoError(t, createDefaultConfig().(*Config).Validate())
+
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{name: "unknown level", modify: func(cfg *Config) { cfg.Level = "loud" }},
+		{name: "invalid max_batch_size", modify: func(cfg *Config) { cfg.MaxBatchSize = 0 }},
+		{name: "invalid queue_size", modify: func(cfg *Config) { cfg.QueueSize = -1 }},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectorlogsreceiver
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	attributeLogger     = "logger"
+	attributeCaller     = "caller"
+	attributeStacktrace = "stacktrace"
+)
+
+var severities = map[zapcore.Level]pdata.SeverityNumber{
+	zapcore.DebugLevel:  pdata.SeverityNumberDEBUG,
+	zapcore.InfoLevel:   pdata.SeverityNumberINFO,
+	zapcore.WarnLevel:   pdata.SeverityNumberWARN,
+	zapcore.ErrorLevel:  pdata.SeverityNumberERROR,
+	zapcore.DPanicLevel: pdata.SeverityNumberERROR2,
+	zapcore.PanicLevel:  pdata.SeverityNumberFATAL,
+	zapcore.FatalLevel:  pdata.SeverityNumberFATAL2,
+}
+
+// fillLogRecord sets the message as the body, the time, the severity of the level, and the logger name,
+// the caller, the stack trace and the fields as the attributes of the record
+func (e entry) fillLogRecord(lr pdata.LogRecord) {
+	lr.Body().SetStringVal(e.Message)
+	lr.SetTimestamp(pdata.TimestampFromTime(e.Time))
+	lr.SetSeverityNumber(severities[e.Level])
+	lr.SetSeverityText(e.Level.CapitalString())
+
+	attributes := lr.Attributes()
+	for name, value := range e.fields {
+		attributes.Upsert(name, attributeValue(value))
+	}
+	if e.LoggerName != "" {
+		attributes.UpsertString(attributeLogger, e.LoggerName)
+	}
+	if e.Caller.Defined {
+		attributes.UpsertString(attributeCaller, e.Caller.TrimmedPath())
+	}
+	if e.Stack != "" {
+		attributes.UpsertString(attributeStacktrace, e.Stack)
+	}
+}
+
+// attributeValue converts the field value encoded by zapcore.MapObjectEncoder; the objects and arrays
+// are set as JSON strings
+func attributeValue(value interface{}) pdata.AttributeValue {
+	switch v := value.(type) {
+	case string:
+		return pdata.NewAttributeValueString(v)
+	case bool:
+		return pdata.NewAttributeValueBool(v)
+	case int:
+		return pdata.NewAttributeValueInt(int64(v))
+	case int64:
+		return pdata.NewAttributeValueInt(v)
+	case int32:
+		return pdata.NewAttributeValueInt(int64(v))
+	case int16:
+		return pdata.NewAttributeValueInt(int64(v))
+	case int8:
+		return pdata.NewAttributeValueInt(int64(v))
+	case uint32:
+		return pdata.NewAttributeValueInt(int64(v))
+	case uint16:
+		return pdata.NewAttributeValueInt(int64(v))
+	case uint8:
+		return pdata.NewAttributeValueInt(int64(v))
+	case float64:
+		return pdata.NewAttributeValueDouble(v)
+	case float32:
+		return pdata.NewAttributeValueDouble(float64(v))
+	case time.Duration:
+		return pdata.NewAttributeValueString(v.String())
+	case time.Time:
+		return pdata.NewAttributeValueString(v.Format(time.RFC3339Nano))
+	case []byte:
+		return pdata.NewAttributeValueBytes(v)
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(v); err == nil {
+			return pdata.NewAttributeValueString(string(data))
+		}
+	}
+	return pdata.NewAttributeValueString(fmt.Sprint(value))
+}
@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectorlogsreceiver
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// entry is a log entry of the collector with its fields
+type entry struct {
+	zapcore.Entry
+	fields map[string]interface{}
+}
+
+// subscriber receives the entries of its level and above, up to the size of its queue
+type subscriber struct {
+	level   zapcore.Level
+	entries chan entry
+	// dropped is the number of entries dropped since it was last read, as the queue was full
+	dropped uint64
+}
+
+func (s *subscriber) takeDropped() uint64 {
+	return atomic.SwapUint64(&s.dropped, 0)
+}
+
+// hub passes the log entries of the collector to the subscribed receivers. The logger is created once,
+// before the receivers, which subscribe when they start and unsubscribe when they shut down.
+type hub struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+// defaultHub is the hub of the collector logger, set up with LoggingOption
+var defaultHub = newHub()
+
+func newHub() *hub {
+	return &hub{subscribers: map[*subscriber]struct{}{}}
+}
+
+// LoggingOption returns the option of the collector logger, which passes its entries to the collector logs receivers
+func LoggingOption() zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, &hubCore{hub: defaultHub})
+	})
+}
+
+func (h *hub) subscribe(level zapcore.Level, queueSize int) *subscriber {
+	s := &subscriber{
+		level:   level,
+		entries: make(chan entry, queueSize),
+	}
+	h.mu.Lock()
+	h.subscribers[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+func (h *hub) unsubscribe(s *subscriber) {
+	h.mu.Lock()
+	delete(h.subscribers, s)
+	h.mu.Unlock()
+}
+
+func (h *hub) enabled(level zapcore.Level) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subscribers {
+		if level >= s.level {
+			return true
+		}
+	}
+	return false
+}
+
+// publish passes the entry to the subscribers without blocking, the entry is dropped
+// for the subscribers whose queues are full
+func (h *hub) publish(e entry) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subscribers {
+		if e.Level < s.level {
+			continue
+		}
+		select {
+		case s.entries <- e:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
+
+// hubCore is the core of the logger publishing the entries in the hub
+type hubCore struct {
+	hub    *hub
+	fields []zapcore.Field
+}
+
+var _ zapcore.Core = (*hubCore)(nil)
+
+func (c *hubCore) Enabled(level zapcore.Level) bool {
+	return c.hub.enabled(level)
+}
+
+func (c *hubCore) With(fields []zapcore.Field) zapcore.Core {
+	return &hubCore{
+		hub:    c.hub,
+		fields: append(append([]zapcore.Field{}, c.fields...), fields...),
+	}
+}
+
+func (c *hubCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+func (c *hubCore) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	c.hub.publish(entry{Entry: e, fields: encoder.Fields})
+	return nil
+}
+
+func (c *hubCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectorlogsreceiver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// drainTimeout is the timeout of sending the entries on shutdown
+var drainTimeout = 5 * time.Second
+
+type collectorLogsReceiver struct {
+	config   *Config
+	logger   *zap.Logger
+	consumer consumer.Logs
+	hub      *hub
+
+	subscriber *subscriber
+	// cancel cancels sending the entries, when they are not sent before the drain timeout on shutdown
+	cancel context.CancelFunc
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Ensure this receiver adheres to required interface.
+var _ component.LogsReceiver = (*collectorLogsReceiver)(nil)
+
+func newCollectorLogsReceiver(
+	logger *zap.Logger,
+	cfg *Config,
+	nextConsumer consumer.Logs,
+	h *hub,
+) *collectorLogsReceiver {
+	return &collectorLogsReceiver{
+		config:   cfg,
+		logger:   logger,
+		consumer: nextConsumer,
+		hub:      h,
+	}
+}
+
+// Start subscribes to the log entries of the collector
+func (r *collectorLogsReceiver) Start(_ context.Context, _ component.Host) error {
+	// the level is checked by Validate
+	level, _ := parseLevel(r.config.Level)
+	r.subscriber = r.hub.subscribe(level, r.config.QueueSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.stop = make(chan struct{})
+	r.wg.Add(1)
+	go r.run(ctx)
+	return nil
+}
+
+// Shutdown unsubscribes from the log entries and sends the ones left in the queue; the receivers
+// are shut down before the exporters, so the entries can still be sent
+func (r *collectorLogsReceiver) Shutdown(context.Context) error {
+	if r.subscriber == nil {
+		return nil
+	}
+	r.hub.unsubscribe(r.subscriber)
+	close(r.stop)
+
+	timer := time.AfterFunc(drainTimeout, r.cancel)
+	r.wg.Wait()
+	timer.Stop()
+	r.cancel()
+	return nil
+}
+
+// run sends the entries until the receiver is stopped; the entries already queued are sent in a single batch,
+// up to the maximum batch size
+func (r *collectorLogsReceiver) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case <-r.stop:
+			for batch := r.queued(nil); len(batch) > 0; batch = r.queued(nil) {
+				r.consume(ctx, batch)
+			}
+			return
+		case e := <-r.subscriber.entries:
+			r.consume(ctx, r.queued([]entry{e}))
+		}
+	}
+}
+
+// queued appends the queued entries to the batch, up to the maximum batch size
+func (r *collectorLogsReceiver) queued(batch []entry) []entry {
+	for len(batch) < r.config.MaxBatchSize {
+		select {
+		case e := <-r.subscriber.entries:
+			batch = append(batch, e)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (r *collectorLogsReceiver) consume(ctx context.Context, entries []entry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	logs := pdata.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	for _, e := range entries {
+		e.fillLogRecord(records.AppendEmpty())
+	}
+	if err := r.consumer.ConsumeLogs(ctx, logs); err != nil {
+		r.logger.Warn("Failed to consume the collector log entries", zap.Error(err))
+	}
+
+	if dropped := r.subscriber.takeDropped(); dropped > 0 {
+		r.logger.Warn("Dropped the collector log entries, as the queue was full", zap.Uint64("count", dropped))
+	}
+}
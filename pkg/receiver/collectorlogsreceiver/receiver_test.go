@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectorlogsreceiver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestLogger(h *hub) *zap.Logger {
+	return zap.New(zapcore.NewNopCore(), zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, &hubCore{hub: h})
+	}))
+}
+
+func TestReceiver(t *testing.T) {
+	h := newHub()
+	logger := newTestLogger(h)
+	sink := new(consumertest.LogsSink)
+	cfg := createDefaultConfig().(*Config)
+	r := newCollectorLogsReceiver(zap.NewNop(), cfg, sink, h)
+
+	logger.Info("Dropped before the receiver is started")
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	logger.Named("exporter").With(zap.String("kind", "exporter")).Warn("Exporting failed", zap.Int("dropped", 2))
+	logger.Debug("Below the level")
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	logger.Error("Sent on shutdown")
+	require.NoError(t, r.Shutdown(context.Background()))
+	logger.Error("Dropped after the receiver is shut down")
+
+	require.Equal(t, 2, sink.LogRecordCount())
+	records := sink.AllLogs()[0].ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
+	record := records.At(0)
+	assert.Equal(t, "Exporting failed", record.Body().StringVal())
+	assert.Equal(t, pdata.SeverityNumberWARN, record.SeverityNumber())
+	assert.Equal(t, "WARN", record.SeverityText())
+	assert.NotZero(t, record.Timestamp())
+	assert.Equal(t, pdata.NewAttributeMap().InitFromMap(map[string]pdata.AttributeValue{
+		"logger":  pdata.NewAttributeValueString("exporter"),
+		"kind":    pdata.NewAttributeValueString("exporter"),
+		"dropped": pdata.NewAttributeValueInt(2),
+	}).Sort(), record.Attributes().Sort())
+
+	last := sink.AllLogs()[1].ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0)
+	assert.Equal(t, "Sent on shutdown", last.Body().StringVal())
+	assert.Equal(t, pdata.SeverityNumberERROR, last.SeverityNumber())
+}
+
+func TestReceiverQueueFull(t *testing.T) {
+	h := newHub()
+	logger := newTestLogger(h)
+	cfg := createDefaultConfig().(*Config)
+	cfg.QueueSize = 1
+	r := newCollectorLogsReceiver(zap.NewNop(), cfg, consumertest.NewNop(), h)
+	r.subscriber = h.subscribe(zapcore.InfoLevel, cfg.QueueSize)
+
+	logger.Info("queued")
+	logger.Info("dropped")
+	logger.Info("dropped")
+	assert.Len(t, r.queued(nil), 1)
+	assert.Equal(t, uint64(2), r.subscriber.takeDropped())
+	assert.Equal(t, uint64(0), r.subscriber.takeDropped())
+}
+
+func TestReceiverConsumeError(t *testing.T) {
+	h := newHub()
+	logger := newTestLogger(h)
+	cfg := createDefaultConfig().(*Config)
+	r := newCollectorLogsReceiver(zap.NewNop(), cfg, consumertest.NewErr(errors.New("failed")), h)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	logger.Info("not consumed")
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestAttributeValue(t *testing.T) {
+	assert.Equal(t, pdata.NewAttributeValueString("1s"), attributeValue(time.Second))
+	assert.Equal(t, pdata.NewAttributeValueBool(true), attributeValue(true))
+	assert.Equal(t, pdata.NewAttributeValueDouble(0.5), attributeValue(0.5))
+	assert.Equal(t, pdata.NewAttributeValueInt(3), attributeValue(uint8(3)))
+	assert.Equal(t, pdata.NewAttributeValueString(`{"a":1}`), attributeValue(map[string]interface{}{"a": 1}))
+	assert.Equal(t, pdata.NewAttributeValueString("18446744073709551615"), attributeValue(uint64(18446744073709551615)))
+}
@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockerstatsreceiver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+)
+
+// Config defines configuration for the Docker stats receiver.
+type Config struct {
+	scraperhelper.ScraperControllerSettings `mapstructure:",squash"`
+
+	// Endpoint is the address of the Docker Engine API, e.g. unix:///var/run/docker.sock or tcp://localhost:2375
+	Endpoint string `mapstructure:"endpoint"`
+	// Timeout is the timeout of the requests to the Docker Engine API
+	Timeout time.Duration `mapstructure:"timeout"`
+	// ExcludedContainers are the regular expressions of the names of the containers which are not scraped
+	ExcludedContainers []string `mapstructure:"excluded_containers"`
+	// ContainerLabelsToAttributes maps the container labels to the names of the resource attributes they are set as
+	ContainerLabelsToAttributes map[string]string `mapstructure:"container_labels_to_attributes"`
+	// IncludeAllLabels sets all the other container labels as container.label.<name> resource attributes
+	IncludeAllLabels bool `mapstructure:"include_all_labels"`
+}
+
+const (
+	defaultEndpoint           = "unix:///var/run/docker.sock"
+	defaultTimeout            = 5 * time.Second
+	defaultCollectionInterval = 10 * time.Second
+
+	unixScheme = "unix://"
+	tcpScheme  = "tcp://"
+)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.CollectionInterval <= 0 {
+		return fmt.Errorf("collection_interval must be positive, got %s", cfg.CollectionInterval)
+	}
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", cfg.Timeout)
+	}
+	if !strings.HasPrefix(cfg.Endpoint, unixScheme) && !strings.HasPrefix(cfg.Endpoint, tcpScheme) &&
+		!strings.HasPrefix(cfg.Endpoint, "http://") && !strings.HasPrefix(cfg.Endpoint, "https://") {
+		return fmt.Errorf("endpoint must start with one of: unix://, tcp://, http://, https://, got %q", cfg.Endpoint)
+	}
+	for _, expression := range cfg.ExcludedContainers {
+		if _, err := regexp.Compile(expression); err != nil {
+			return fmt.Errorf("invalid excluded_containers regex %q: %w", expression, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockerstatsreceiver
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+)
+
+const (
+	// The value of "type" Docker stats receiver in configuration.
+	typeStr = "docker_stats"
+)
+
+// NewFactory creates a factory for the Docker stats receiver.
+func NewFactory() component.ReceiverFactory {
+	return receiverhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		receiverhelper.WithMetrics(createMetricsReceiver),
+	)
+}
+
+func createDefaultConfig() config.Receiver {
+	scs := scraperhelper.DefaultScraperControllerSettings(typeStr)
+	scs.CollectionInterval = defaultCollectionInterval
+	return &Config{
+		ScraperControllerSettings: scs,
+		Endpoint:                  defaultEndpoint,
+		Timeout:                   defaultTimeout,
+		IncludeAllLabels:          true,
+	}
+}
+
+// createMetricsReceiver creates a metrics receiver based on provided config.
+func createMetricsReceiver(
+	_ context.Context,
+	params component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Metrics,
+) (component.MetricsReceiver, error) {
+	dCfg := cfg.(*Config)
+	if err := dCfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s, err := newScraper(params.Logger, dCfg)
+	if err != nil {
+		return nil, err
+	}
+	scraper := scraperhelper.NewResourceMetricsScraper(dCfg.ID(), s.scrape)
+
+	return scraperhelper.NewScraperControllerReceiver(
+		&dCfg.ScraperControllerSettings,
+		params.Logger,
+		nextConsumer,
+		scraperhelper.AddScraper(scraper),
+	)
+}
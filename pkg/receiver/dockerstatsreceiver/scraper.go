@@ -0,0 +1,279 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockerstatsreceiver
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/receiver/scrapererror"
+	"go.uber.org/zap"
+)
+
+const (
+	labelAttributePrefix = "container.label."
+
+	interfaceAttribute   = "interface"
+	deviceMajorAttribute = "device_major"
+	deviceMinorAttribute = "device_minor"
+	operationAttribute   = "operation"
+)
+
+type scraper struct {
+	logger     *zap.Logger
+	config     *Config
+	client     *dockerClient
+	excluded   []*regexp.Regexp
+	scrapeTime func() time.Time
+}
+
+func newScraper(logger *zap.Logger, cfg *Config) (*scraper, error) {
+	excluded := make([]*regexp.Regexp, 0, len(cfg.ExcludedContainers))
+	for _, expression := range cfg.ExcludedContainers {
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, err
+		}
+		excluded = append(excluded, re)
+	}
+
+	return &scraper{
+		logger:     logger,
+		config:     cfg,
+		client:     newDockerClient(cfg.Endpoint, cfg.Timeout),
+		excluded:   excluded,
+		scrapeTime: time.Now,
+	}, nil
+}
+
+// scrape fetches the stats of all running containers and converts them to metrics, one resource per container
+func (s *scraper) scrape(ctx context.Context) (pdata.ResourceMetricsSlice, error) {
+	rms := pdata.NewResourceMetricsSlice()
+
+	containers, err := s.client.containers(ctx)
+	if err != nil {
+		return rms, err
+	}
+
+	containers = s.filter(containers)
+	results := make([]*containerStats, len(containers))
+	errs := make([]error, len(containers))
+
+	// the stats endpoint samples the container for a while, so the containers are queried concurrently
+	var wg sync.WaitGroup
+	for i := range containers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = s.client.stats(ctx, containers[i].ID)
+		}(i)
+	}
+	wg.Wait()
+
+	var scrapeErrors scrapererror.ScrapeErrors
+	for i, c := range containers {
+		if errs[i] != nil {
+			scrapeErrors.AddPartial(1, errs[i])
+			continue
+		}
+		s.appendContainerMetrics(rms.AppendEmpty(), c, results[i])
+	}
+
+	return rms, scrapeErrors.Combine()
+}
+
+// filter drops the containers excluded by name
+func (s *scraper) filter(containers []container) []container {
+	if len(s.excluded) == 0 {
+		return containers
+	}
+
+	filtered := make([]container, 0, len(containers))
+	for _, c := range containers {
+		if !s.isExcluded(c.name()) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+func (s *scraper) isExcluded(name string) bool {
+	for _, re := range s.excluded {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scraper) appendContainerMetrics(rm pdata.ResourceMetrics, c container, stats *containerStats) {
+	s.setResourceAttributes(rm.Resource().Attributes(), c)
+
+	ts := stats.Read
+	if ts.IsZero() {
+		ts = s.scrapeTime()
+	}
+	b := metricsBuilder{
+		metrics:   rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics(),
+		timestamp: pdata.TimestampFromTime(ts),
+	}
+
+	cpu := stats.CPUStats
+	b.sum("container.cpu.usage.total", "Total CPU time consumed.", "ns", cpu.CPUUsage.TotalUsage, nil)
+	b.sum("container.cpu.usage.kernelmode", "Time spent by tasks of the container in kernel mode.", "ns", cpu.CPUUsage.UsageInKernelmode, nil)
+	b.sum("container.cpu.usage.usermode", "Time spent by tasks of the container in user mode.", "ns", cpu.CPUUsage.UsageInUsermode, nil)
+	b.sum("container.cpu.throttling_data.throttled_periods", "Number of periods when the container hit its throttling limit.", "1", cpu.ThrottlingData.ThrottledPeriods, nil)
+	b.sum("container.cpu.throttling_data.throttled_time", "Aggregate time the container was throttled.", "ns", cpu.ThrottlingData.ThrottledTime, nil)
+	b.gauge("container.cpu.percent", "Percent of CPU used by the container.", "1", cpuPercent(stats), nil)
+
+	memory := stats.MemoryStats
+	usage := memoryUsage(memory)
+	b.gaugeInt("container.memory.usage.total", "Memory usage of the container, excluding the cache.", "By", usage, nil)
+	b.gaugeInt("container.memory.usage.limit", "Memory limit of the container.", "By", memory.Limit, nil)
+	b.gaugeInt("container.memory.usage.max", "Maximum memory usage of the container.", "By", memory.MaxUsage, nil)
+	if memory.Limit > 0 {
+		b.gauge("container.memory.percent", "Percentage of memory used.", "1", float64(usage)/float64(memory.Limit)*100, nil)
+	}
+
+	for name, network := range stats.Networks {
+		attrs := map[string]string{interfaceAttribute: name}
+		b.sum("container.network.io.usage.rx_bytes", "Bytes received by the container.", "By", network.RxBytes, attrs)
+		b.sum("container.network.io.usage.tx_bytes", "Bytes sent by the container.", "By", network.TxBytes, attrs)
+		b.sum("container.network.io.usage.rx_packets", "Packets received by the container.", "1", network.RxPackets, attrs)
+		b.sum("container.network.io.usage.tx_packets", "Packets sent by the container.", "1", network.TxPackets, attrs)
+		b.sum("container.network.io.usage.rx_errors", "Received errors.", "1", network.RxErrors, attrs)
+		b.sum("container.network.io.usage.tx_errors", "Sent errors.", "1", network.TxErrors, attrs)
+		b.sum("container.network.io.usage.rx_dropped", "Incoming packets dropped.", "1", network.RxDropped, attrs)
+		b.sum("container.network.io.usage.tx_dropped", "Outgoing packets dropped.", "1", network.TxDropped, attrs)
+	}
+
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		b.sum("container.blockio.io_service_bytes_recursive", "Number of bytes transferred to and from the disk by the container.", "By", entry.Value, blkioAttributes(entry))
+	}
+	for _, entry := range stats.BlkioStats.IoServicedRecursive {
+		b.sum("container.blockio.io_serviced_recursive", "Number of IOs issued to the disk by the container.", "1", entry.Value, blkioAttributes(entry))
+	}
+}
+
+// setResourceAttributes sets the container metadata and its labels as resource attributes
+func (s *scraper) setResourceAttributes(attrs pdata.AttributeMap, c container) {
+	attrs.UpsertString("container.runtime", "docker")
+	attrs.UpsertString("container.id", c.ID)
+	attrs.UpsertString("container.name", c.name())
+	attrs.UpsertString("container.image.name", c.Image)
+
+	for label, value := range c.Labels {
+		if attribute, ok := s.config.ContainerLabelsToAttributes[label]; ok {
+			attrs.UpsertString(attribute, value)
+		} else if s.config.IncludeAllLabels {
+			attrs.UpsertString(labelAttributePrefix+label, value)
+		}
+	}
+}
+
+// cpuPercent calculates the CPU usage the same way as `docker stats` does
+func cpuPercent(stats *containerStats) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * onlineCPUs * 100
+}
+
+// memoryUsage returns the memory usage without the cache, the same way as `docker stats` does
+// for both cgroup v1 (total_inactive_file) and cgroup v2 (inactive_file)
+func memoryUsage(memory memoryStats) uint64 {
+	cache, ok := memory.Stats["total_inactive_file"]
+	if !ok {
+		cache = memory.Stats["inactive_file"]
+	}
+	if cache > memory.Usage {
+		return 0
+	}
+	return memory.Usage - cache
+}
+
+func blkioAttributes(entry blkioEntry) map[string]string {
+	return map[string]string{
+		deviceMajorAttribute: strconv.FormatUint(entry.Major, 10),
+		deviceMinorAttribute: strconv.FormatUint(entry.Minor, 10),
+		operationAttribute:   entry.Op,
+	}
+}
+
+// metricsBuilder appends metrics sharing the same timestamp, reusing the metric if it has already been added
+type metricsBuilder struct {
+	metrics   pdata.MetricSlice
+	timestamp pdata.Timestamp
+	byName    map[string]pdata.Metric
+}
+
+func (b *metricsBuilder) metric(name, description, unit string, dataType pdata.MetricDataType) pdata.Metric {
+	if metric, ok := b.byName[name]; ok {
+		return metric
+	}
+	if b.byName == nil {
+		b.byName = map[string]pdata.Metric{}
+	}
+
+	metric := b.metrics.AppendEmpty()
+	metric.SetName(name)
+	metric.SetDescription(description)
+	metric.SetUnit(unit)
+	metric.SetDataType(dataType)
+	if dataType == pdata.MetricDataTypeSum {
+		metric.Sum().SetIsMonotonic(true)
+		metric.Sum().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+	}
+	b.byName[name] = metric
+	return metric
+}
+
+func (b *metricsBuilder) sum(name, description, unit string, value uint64, attrs map[string]string) {
+	dp := b.metric(name, description, unit, pdata.MetricDataTypeSum).Sum().DataPoints().AppendEmpty()
+	dp.SetTimestamp(b.timestamp)
+	dp.SetIntVal(int64(value))
+	setAttributes(dp.Attributes(), attrs)
+}
+
+func (b *metricsBuilder) gaugeInt(name, description, unit string, value uint64, attrs map[string]string) {
+	dp := b.metric(name, description, unit, pdata.MetricDataTypeGauge).Gauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(b.timestamp)
+	dp.SetIntVal(int64(value))
+	setAttributes(dp.Attributes(), attrs)
+}
+
+func (b *metricsBuilder) gauge(name, description, unit string, value float64, attrs map[string]string) {
+	dp := b.metric(name, description, unit, pdata.MetricDataTypeGauge).Gauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(b.timestamp)
+	dp.SetDoubleVal(value)
+	setAttributes(dp.Attributes(), attrs)
+}
+
+func setAttributes(dst pdata.AttributeMap, attrs map[string]string) {
+	for k, v := range attrs {
+		dst.UpsertString(k, v)
+	}
+}
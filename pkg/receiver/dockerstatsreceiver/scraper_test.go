@@ -0,0 +1,211 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockerstatsreceiver
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/receiver/scrapererror"
+	"go.uber.org/zap"
+)
+
+// newDockerAPI serves the testdata responses of the Docker Engine API,
+// failing the stats requests of the containers listed in failing
+func newDockerAPI(t *testing.T, failing ...string) http.Handler {
+	containers, err := ioutil.ReadFile(path.Join(".", "testdata", "containers.json"))
+	require.NoError(t, err)
+	stats, err := ioutil.ReadFile(path.Join(".", "testdata", "stats.json"))
+	require.NoError(t, err)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/containers/json":
+			w.Write(containers)
+		case strings.HasSuffix(r.URL.Path, "/stats"):
+			assert.Equal(t, "false", r.URL.Query().Get("stream"))
+			for _, id := range failing {
+				if strings.Contains(r.URL.Path, id) {
+					http.Error(w, "no such container", http.StatusNotFound)
+					return
+				}
+			}
+			w.Write(stats)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func newTestScraper(t *testing.T, endpoint string, modify func(*Config)) *scraper {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	s, err := newScraper(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	return s
+}
+
+func metricsByName(rm pdata.ResourceMetrics) map[string]pdata.Metric {
+	metrics := map[string]pdata.Metric{}
+	ms := rm.InstrumentationLibraryMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		metrics[ms.At(i).Name()] = ms.At(i)
+	}
+	return metrics
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
+
+func TestScrape(t *testing.T) {
+	server := httptest.NewServer(newDockerAPI(t))
+	defer server.Close()
+
+	s := newTestScraper(t, server.URL, nil)
+	rms, err := s.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, rms.Len())
+
+	rm := rms.At(0)
+	attrs := attributesToMap(rm.Resource().Attributes())
+	assert.Equal(t, map[string]string{
+		"container.runtime":    "docker",
+		"container.id":         "9c4f1e2d7a3b",
+		"container.name":       "web",
+		"container.image.name": "nginx:1.21",
+		"container.label.com.docker.compose.service": "web",
+		"container.label.maintainer":                 "NGINX Docker Maintainers",
+	}, attrs)
+
+	metrics := metricsByName(rm)
+	assert.Len(t, metrics, 20)
+
+	cpuTotal := metrics["container.cpu.usage.total"]
+	assert.Equal(t, pdata.MetricDataTypeSum, cpuTotal.DataType())
+	assert.True(t, cpuTotal.Sum().IsMonotonic())
+	assert.Equal(t, pdata.AggregationTemporalityCumulative, cpuTotal.Sum().AggregationTemporality())
+	assert.EqualValues(t, 300000000, cpuTotal.Sum().DataPoints().At(0).IntVal())
+	assert.Equal(t, "2021-09-01T12:00:00.5Z", cpuTotal.Sum().DataPoints().At(0).Timestamp().AsTime().UTC().Format("2006-01-02T15:04:05.999Z"))
+
+	assert.InDelta(t, 10.0, metrics["container.cpu.percent"].Gauge().DataPoints().At(0).DoubleVal(), 0.0001)
+	assert.EqualValues(t, 8388608, metrics["container.memory.usage.total"].Gauge().DataPoints().At(0).IntVal())
+	assert.EqualValues(t, 104857600, metrics["container.memory.usage.limit"].Gauge().DataPoints().At(0).IntVal())
+	assert.InDelta(t, 8.0, metrics["container.memory.percent"].Gauge().DataPoints().At(0).DoubleVal(), 0.0001)
+
+	rx := metrics["container.network.io.usage.rx_bytes"].Sum().DataPoints().At(0)
+	assert.EqualValues(t, 1024, rx.IntVal())
+	assert.Equal(t, map[string]string{"interface": "eth0"}, attributesToMap(rx.Attributes()))
+
+	blkio := metrics["container.blockio.io_service_bytes_recursive"].Sum().DataPoints()
+	require.Equal(t, 2, blkio.Len())
+	assert.EqualValues(t, 8192, blkio.At(1).IntVal())
+	assert.Equal(t, map[string]string{
+		"device_major": "8",
+		"device_minor": "0",
+		"operation":    "Write",
+	}, attributesToMap(blkio.At(1).Attributes()))
+}
+
+func TestScrapeLabelsAndExclusions(t *testing.T) {
+	server := httptest.NewServer(newDockerAPI(t))
+	defer server.Close()
+
+	s := newTestScraper(t, "tcp://"+strings.TrimPrefix(server.URL, "http://"), func(cfg *Config) {
+		cfg.ExcludedContainers = []string{"^otelcol-"}
+		cfg.ContainerLabelsToAttributes = map[string]string{"com.docker.compose.service": "service.name"}
+		cfg.IncludeAllLabels = false
+	})
+	rms, err := s.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, rms.Len())
+
+	attrs := attributesToMap(rms.At(0).Resource().Attributes())
+	assert.Equal(t, "web", attrs["service.name"])
+	assert.NotContains(t, attrs, "container.label.maintainer")
+	assert.NotContains(t, attrs, "container.label.com.docker.compose.service")
+}
+
+func TestScrapePartialError(t *testing.T) {
+	server := httptest.NewServer(newDockerAPI(t, "0d8a6b5c4e3f"))
+	defer server.Close()
+
+	s := newTestScraper(t, server.URL, nil)
+	rms, err := s.scrape(context.Background())
+	require.Error(t, err)
+	assert.True(t, scrapererror.IsPartialScrapeError(err))
+	assert.Contains(t, err.Error(), "no such container")
+
+	require.Equal(t, 1, rms.Len())
+	name, _ := rms.At(0).Resource().Attributes().Get("container.name")
+	assert.Equal(t, "web", name.StringVal())
+}
+
+func TestScrapeListError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	s := newTestScraper(t, server.URL, nil)
+	rms, err := s.scrape(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 0, rms.Len())
+}
+
+func TestScrapeUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(newDockerAPI(t))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	s := newTestScraper(t, "unix://"+socket, nil)
+	rms, err := s.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, rms.Len())
+}
+
+func TestCPUPercentWithoutPreviousStats(t *testing.T) {
+	stats := &containerStats{}
+	stats.CPUStats.CPUUsage.TotalUsage = 100
+	stats.CPUStats.SystemUsage = 1000
+	assert.Zero(t, cpuPercent(stats))
+
+	stats.PreCPUStats.SystemUsage = 500
+	stats.CPUStats.CPUUsage.PercpuUsage = []uint64{50, 50}
+	assert.InDelta(t, 40.0, cpuPercent(stats), 0.0001)
+}
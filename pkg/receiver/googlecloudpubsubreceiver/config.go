@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudpubsubreceiver
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the Google Cloud Pub/Sub receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:"-"`
+
+	// Subscription is the full name of the subscription the messages are pulled from,
+	// i.e. projects/<project>/subscriptions/<subscription>
+	Subscription string `mapstructure:"subscription"`
+
+	// Encoding is the format of the messages: cloud_logging for the LogEntry JSON exported by the log sinks,
+	// raw_text for any other data, or auto to detect the log entries and treat the other messages as raw text.
+	Encoding string `mapstructure:"encoding"`
+
+	// CredentialsFile is the path of the service account key file. When empty, the application default
+	// credentials are used.
+	CredentialsFile string `mapstructure:"credentials_file"`
+
+	// Endpoint overrides the Pub/Sub API endpoint, e.g. to use the emulator
+	Endpoint string `mapstructure:"endpoint"`
+	// Insecure disables the TLS and the authentication, e.g. for the emulator
+	Insecure bool `mapstructure:"insecure"`
+
+	// MaxOutstandingMessages is the maximum number of the messages received, but not yet passed
+	// to the next component
+	MaxOutstandingMessages int `mapstructure:"max_outstanding_messages"`
+}
+
+const (
+	encodingAuto         = "auto"
+	encodingCloudLogging = "cloud_logging"
+	encodingRawText      = "raw_text"
+
+	defaultMaxOutstandingMessages = 1000
+)
+
+var subscriptionRegex = regexp.MustCompile(`^projects/([^/]+)/subscriptions/([^/]+)$`)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if !subscriptionRegex.MatchString(cfg.Subscription) {
+		return fmt.Errorf("subscription must be in the format projects/<project>/subscriptions/<subscription>, got %q",
+			cfg.Subscription)
+	}
+	switch cfg.Encoding {
+	case encodingAuto, encodingCloudLogging, encodingRawText:
+	default:
+		return fmt.Errorf("unknown encoding %q, must be one of: auto, cloud_logging, raw_text", cfg.Encoding)
+	}
+	if cfg.MaxOutstandingMessages <= 0 {
+		return fmt.Errorf("max_outstanding_messages must be positive, got %d", cfg.MaxOutstandingMessages)
+	}
+	return nil
+}
+
+// projectAndSubscriptionID splits the subscription name into the project and subscription IDs
+func (cfg *Config) projectAndSubscriptionID() (string, string) {
+	matches := subscriptionRegex.FindStringSubmatch(cfg.Subscription)
+	return matches[1], matches[2]
+}
@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudpubsubreceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "googlecloudpubsub_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	defaultCfg := factory.CreateDefaultConfig().(*Config)
+	defaultCfg.Subscription = "projects/my-project/subscriptions/logs-export"
+	assert.Equal(t, defaultCfg, cfg.Receivers[config.NewID(typeStr)])
+	assert.Equal(t, &Config{
+		ReceiverSettings:       config.NewReceiverSettings(config.NewIDWithName(typeStr, "audit")),
+		Subscription:           "projects/my-project/subscriptions/audit-logs",
+		Encoding:               "cloud_logging",
+		CredentialsFile:        "/etc/otelcol/gcp-key.json",
+		MaxOutstandingMessages: 100,
+	}, cfg.Receivers[config.NewIDWithName(typeStr, "audit")])
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "valid", modify: func(cfg *Config) {}},
+		{name: "raw text", modify: func(cfg *Config) { cfg.Encoding = "raw_text" }},
+		{
+			name:    "no subscription",
+			modify:  func(cfg *Config) { cfg.Subscription = "" },
+			wantErr: `subscription must be in the format projects/<project>/subscriptions/<subscription>, got ""`,
+		},
+		{
+			name:    "subscription ID only",
+			modify:  func(cfg *Config) { cfg.Subscription = "logs-export" },
+			wantErr: `subscription must be in the format projects/<project>/subscriptions/<subscription>, got "logs-export"`,
+		},
+		{
+			name:    "unknown encoding",
+			modify:  func(cfg *Config) { cfg.Encoding = "otlp_proto" },
+			wantErr: `unknown encoding "otlp_proto", must be one of: auto, cloud_logging, raw_text`,
+		},
+		{
+			name:    "no outstanding messages",
+			modify:  func(cfg *Config) { cfg.MaxOutstandingMessages = 0 },
+			wantErr: "max_outstanding_messages must be positive, got 0",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Subscription = "projects/my-project/subscriptions/logs-export"
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestProjectAndSubscriptionID(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Subscription = "projects/my-project/subscriptions/logs-export"
+
+	project, subscription := cfg.projectAndSubscriptionID()
+	assert.Equal(t, "my-project", project)
+	assert.Equal(t, "logs-export", subscription)
+}
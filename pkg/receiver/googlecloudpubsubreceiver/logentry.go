@@ -0,0 +1,285 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudpubsubreceiver
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	auditLogType = "type.googleapis.com/google.cloud.audit.AuditLog"
+
+	resourceLabelsPrefix = "gcp.resource.labels."
+	entryLabelsPrefix    = "gcp.labels."
+)
+
+// logEntry holds the used fields of the Cloud Logging LogEntry, as exported by the log sinks to Pub/Sub
+type logEntry struct {
+	LogName  string `json:"logName"`
+	InsertID string `json:"insertId"`
+	Resource struct {
+		Type   string            `json:"type"`
+		Labels map[string]string `json:"labels"`
+	} `json:"resource"`
+	Timestamp        time.Time         `json:"timestamp"`
+	ReceiveTimestamp time.Time         `json:"receiveTimestamp"`
+	Severity         string            `json:"severity"`
+	Labels           map[string]string `json:"labels"`
+	Trace            string            `json:"trace"`
+	SpanID           string            `json:"spanId"`
+	TextPayload      *string           `json:"textPayload"`
+	JSONPayload      json.RawMessage   `json:"jsonPayload"`
+	ProtoPayload     json.RawMessage   `json:"protoPayload"`
+	HTTPRequest      *struct {
+		RequestMethod string `json:"requestMethod"`
+		RequestURL    string `json:"requestUrl"`
+		Status        int64  `json:"status"`
+		UserAgent     string `json:"userAgent"`
+		RemoteIP      string `json:"remoteIp"`
+	} `json:"httpRequest"`
+}
+
+// auditLog holds the used fields of the protoPayload of the audit logs
+type auditLog struct {
+	Type               string `json:"@type"`
+	ServiceName        string `json:"serviceName"`
+	MethodName         string `json:"methodName"`
+	ResourceName       string `json:"resourceName"`
+	AuthenticationInfo struct {
+		PrincipalEmail string `json:"principalEmail"`
+	} `json:"authenticationInfo"`
+}
+
+// resourceLabelAttributes are the resource labels of the monitored resources mapped to the semantic conventions.
+// The other labels are prefixed with gcp.resource.labels.
+var resourceLabelAttributes = map[string]string{
+	"project_id":     "cloud.account.id",
+	"zone":           "cloud.availability_zone",
+	"location":       "cloud.region",
+	"region":         "cloud.region",
+	"instance_id":    "host.id",
+	"cluster_name":   "k8s.cluster.name",
+	"namespace_name": "k8s.namespace.name",
+	"pod_name":       "k8s.pod.name",
+	"container_name": "k8s.container.name",
+	"function_name":  "faas.name",
+	"service_name":   "service.name",
+}
+
+var severities = map[string]pdata.SeverityNumber{
+	"DEFAULT":   pdata.SeverityNumberUNDEFINED,
+	"DEBUG":     pdata.SeverityNumberDEBUG,
+	"INFO":      pdata.SeverityNumberINFO,
+	"NOTICE":    pdata.SeverityNumberINFO2,
+	"WARNING":   pdata.SeverityNumberWARN,
+	"ERROR":     pdata.SeverityNumberERROR,
+	"CRITICAL":  pdata.SeverityNumberFATAL,
+	"ALERT":     pdata.SeverityNumberFATAL2,
+	"EMERGENCY": pdata.SeverityNumberFATAL3,
+}
+
+// parseLogEntry parses the message as a log entry; the messages without the log name aren't log entries
+func parseLogEntry(data []byte) (*logEntry, error) {
+	var entry logEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	if entry.LogName == "" {
+		return nil, errors.New("message without the log name is not a log entry")
+	}
+	return &entry, nil
+}
+
+// setResource sets the resource attributes from the monitored resource of the entry
+func (e *logEntry) setResource(resource pdata.Resource) {
+	attrs := resource.Attributes()
+	attrs.UpsertString("cloud.provider", "gcp")
+	// log names are projects/<project>/logs/<log id>, or use organizations, folders or billing accounts
+	if parts := strings.SplitN(e.LogName, "/", 3); len(parts) == 3 && parts[0] == "projects" {
+		attrs.UpsertString("cloud.account.id", parts[1])
+	}
+	if e.Resource.Type != "" {
+		attrs.UpsertString("gcp.resource.type", e.Resource.Type)
+	}
+	for k, v := range e.Resource.Labels {
+		if name, ok := resourceLabelAttributes[k]; ok {
+			attrs.UpsertString(name, v)
+		} else {
+			attrs.UpsertString(resourceLabelsPrefix+k, v)
+		}
+	}
+}
+
+// setLogRecord sets the log record from the entry. The publish time is used when the entry has no timestamp.
+func (e *logEntry) setLogRecord(lr pdata.LogRecord, publishTime time.Time) error {
+	switch {
+	case !e.Timestamp.IsZero():
+		lr.SetTimestamp(pdata.TimestampFromTime(e.Timestamp))
+	case !e.ReceiveTimestamp.IsZero():
+		lr.SetTimestamp(pdata.TimestampFromTime(e.ReceiveTimestamp))
+	default:
+		lr.SetTimestamp(pdata.TimestampFromTime(publishTime))
+	}
+
+	if e.Severity != "" {
+		lr.SetSeverityText(e.Severity)
+		lr.SetSeverityNumber(severities[e.Severity])
+	}
+
+	switch {
+	case e.TextPayload != nil:
+		lr.Body().SetStringVal(*e.TextPayload)
+	case e.JSONPayload != nil:
+		if err := setJSONValue(lr.Body(), e.JSONPayload); err != nil {
+			return err
+		}
+	case e.ProtoPayload != nil:
+		if err := setJSONValue(lr.Body(), e.ProtoPayload); err != nil {
+			return err
+		}
+	}
+
+	attrs := lr.Attributes()
+	attrs.UpsertString("gcp.log_name", e.LogName)
+	if i := strings.LastIndex(e.LogName, "/logs/"); i >= 0 {
+		if logID, err := url.PathUnescape(e.LogName[i+len("/logs/"):]); err == nil {
+			attrs.UpsertString("gcp.log_id", logID)
+		}
+	}
+	if e.InsertID != "" {
+		attrs.UpsertString("gcp.insert_id", e.InsertID)
+	}
+	for k, v := range e.Labels {
+		attrs.UpsertString(entryLabelsPrefix+k, v)
+	}
+	e.setTraceContext(lr)
+	e.setHTTPRequest(attrs)
+	e.setAuditLog(attrs)
+	return nil
+}
+
+// setTraceContext sets the trace and span IDs; the trace is projects/<project>/traces/<trace id>
+func (e *logEntry) setTraceContext(lr pdata.LogRecord) {
+	var traceID [16]byte
+	if decoded, err := hex.DecodeString(e.Trace[strings.LastIndex(e.Trace, "/")+1:]); err == nil &&
+		len(decoded) == len(traceID) {
+		copy(traceID[:], decoded)
+		lr.SetTraceID(pdata.NewTraceID(traceID))
+	}
+	var spanID [8]byte
+	if decoded, err := hex.DecodeString(e.SpanID); err == nil && len(decoded) == len(spanID) {
+		copy(spanID[:], decoded)
+		lr.SetSpanID(pdata.NewSpanID(spanID))
+	}
+}
+
+func (e *logEntry) setHTTPRequest(attrs pdata.AttributeMap) {
+	if e.HTTPRequest == nil {
+		return
+	}
+	for name, value := range map[string]string{
+		"http.method":     e.HTTPRequest.RequestMethod,
+		"http.url":        e.HTTPRequest.RequestURL,
+		"http.user_agent": e.HTTPRequest.UserAgent,
+		"net.peer.ip":     e.HTTPRequest.RemoteIP,
+	} {
+		if value != "" {
+			attrs.UpsertString(name, value)
+		}
+	}
+	if e.HTTPRequest.Status != 0 {
+		attrs.UpsertInt("http.status_code", e.HTTPRequest.Status)
+	}
+}
+
+// setAuditLog sets the attributes identifying the operation and the caller of the audit logs
+func (e *logEntry) setAuditLog(attrs pdata.AttributeMap) {
+	if e.ProtoPayload == nil {
+		return
+	}
+	var audit auditLog
+	if err := json.Unmarshal(e.ProtoPayload, &audit); err != nil || audit.Type != auditLogType {
+		return
+	}
+	for name, value := range map[string]string{
+		"gcp.audit.service_name":    audit.ServiceName,
+		"gcp.audit.method_name":     audit.MethodName,
+		"gcp.audit.resource_name":   audit.ResourceName,
+		"gcp.audit.principal_email": audit.AuthenticationInfo.PrincipalEmail,
+	} {
+		if value != "" {
+			attrs.UpsertString(name, value)
+		}
+	}
+}
+
+// setJSONValue sets the attribute value from the JSON, keeping the integers as ints
+func setJSONValue(attribute pdata.AttributeValue, data json.RawMessage) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	setValue(attribute, value)
+	return nil
+}
+
+func setValue(attribute pdata.AttributeValue, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		pdata.NewAttributeValueNull().CopyTo(attribute)
+	case string:
+		attribute.SetStringVal(v)
+	case bool:
+		attribute.SetBoolVal(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			attribute.SetIntVal(i)
+		} else if f, err := v.Float64(); err == nil {
+			attribute.SetDoubleVal(f)
+		} else {
+			attribute.SetStringVal(v.String())
+		}
+	case []interface{}:
+		pdata.NewAttributeValueArray().CopyTo(attribute)
+		values := attribute.ArrayVal()
+		for _, item := range v {
+			setValue(values.AppendEmpty(), item)
+		}
+	case map[string]interface{}:
+		pdata.NewAttributeValueMap().CopyTo(attribute)
+		values := attribute.MapVal()
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			values.InsertNull(k)
+			item, _ := values.Get(k)
+			setValue(item, v[k])
+		}
+	}
+}
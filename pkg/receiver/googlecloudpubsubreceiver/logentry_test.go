@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudpubsubreceiver
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func readLogEntry(t *testing.T, name string) *logEntry {
+	data, err := ioutil.ReadFile(path.Join("testdata", name))
+	require.NoError(t, err)
+	entry, err := parseLogEntry(data)
+	require.NoError(t, err)
+	return entry
+}
+
+func convert(t *testing.T, entry *logEntry) (pdata.Resource, pdata.LogRecord) {
+	resource := pdata.NewResource()
+	entry.setResource(resource)
+	lr := pdata.NewLogRecord()
+	require.NoError(t, entry.setLogRecord(lr, time.Unix(1630490500, 0)))
+	return resource, lr
+}
+
+func TestAuditLogEntry(t *testing.T) {
+	resource, lr := convert(t, readLogEntry(t, "audit_log_entry.json"))
+
+	assert.Equal(t, map[string]interface{}{
+		"cloud.provider":          "gcp",
+		"cloud.account.id":        "my-project",
+		"cloud.availability_zone": "europe-west1-b",
+		"host.id":                 "4520031799277581759",
+		"gcp.resource.type":       "gce_instance",
+	}, attributesToMap(resource.Attributes()))
+
+	assert.Equal(t, time.Date(2021, 9, 1, 10, 0, 0, 500000000, time.UTC), lr.Timestamp().AsTime())
+	assert.Equal(t, "NOTICE", lr.SeverityText())
+	assert.Equal(t, pdata.SeverityNumberINFO2, lr.SeverityNumber())
+	assert.Equal(t, map[string]interface{}{
+		"gcp.log_name":              "projects/my-project/logs/cloudaudit.googleapis.com%2Factivity",
+		"gcp.log_id":                "cloudaudit.googleapis.com/activity",
+		"gcp.insert_id":             "1jvgf4ye1ca5k",
+		"gcp.audit.service_name":    "compute.googleapis.com",
+		"gcp.audit.method_name":     "v1.compute.instances.delete",
+		"gcp.audit.resource_name":   "projects/my-project/zones/europe-west1-b/instances/vm-1",
+		"gcp.audit.principal_email": "admin@example.com",
+	}, attributesToMap(lr.Attributes()))
+
+	require.Equal(t, pdata.AttributeValueTypeMap, lr.Body().Type())
+	body := attributesToMap(lr.Body().MapVal())
+	assert.Equal(t, "v1.compute.instances.delete", body["methodName"])
+	assert.Equal(t, map[string]interface{}{"principalEmail": "admin@example.com"}, body["authenticationInfo"])
+	assert.Equal(t, map[string]interface{}{}, body["status"])
+	assert.True(t, lr.TraceID().IsEmpty())
+}
+
+func TestRequestLogEntry(t *testing.T) {
+	resource, lr := convert(t, readLogEntry(t, "request_log_entry.json"))
+
+	assert.Equal(t, map[string]interface{}{
+		"cloud.provider":                    "gcp",
+		"cloud.account.id":                  "my-project",
+		"cloud.region":                      "europe-west1",
+		"service.name":                      "checkout",
+		"gcp.resource.type":                 "cloud_run_revision",
+		"gcp.resource.labels.revision_name": "checkout-00042-xyz",
+	}, attributesToMap(resource.Attributes()))
+
+	assert.Equal(t, time.Date(2021, 9, 1, 10, 0, 2, 0, time.UTC), lr.Timestamp().AsTime())
+	assert.Equal(t, pdata.SeverityNumberERROR, lr.SeverityNumber())
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", lr.TraceID().HexString())
+	assert.Equal(t, "b7ad6b7169203331", lr.SpanID().HexString())
+	assert.Equal(t, map[string]interface{}{
+		"gcp.log_name":          "projects/my-project/logs/run.googleapis.com%2Frequests",
+		"gcp.log_id":            "run.googleapis.com/requests",
+		"gcp.insert_id":         "613f0a1c000d2b7a6ff6ef5e",
+		"gcp.labels.instanceId": "00bf4bf02d",
+		"http.method":           "GET",
+		"http.url":              "https://checkout-abc.a.run.app/cart",
+		"http.status_code":      int64(503),
+		"http.user_agent":       "curl/7.68.0",
+		"net.peer.ip":           "81.2.69.160",
+	}, attributesToMap(lr.Attributes()))
+
+	assert.Equal(t, map[string]interface{}{
+		"message": "upstream timed out",
+		"retries": int64(3),
+		"latency": 1.5,
+		"tags":    []interface{}{"checkout", nil},
+	}, attributesToMap(lr.Body().MapVal()))
+}
+
+func TestTextPayloadEntry(t *testing.T) {
+	entry, err := parseLogEntry([]byte(`{
+		"logName": "organizations/123/logs/syslog",
+		"textPayload": "kernel: eth0 up",
+		"receiveTimestamp": "2021-09-01T10:00:03Z",
+		"severity": "DEFAULT"
+	}`))
+	require.NoError(t, err)
+	resource, lr := convert(t, entry)
+
+	assert.Equal(t, map[string]interface{}{"cloud.provider": "gcp"}, attributesToMap(resource.Attributes()))
+	assert.Equal(t, "kernel: eth0 up", lr.Body().StringVal())
+	assert.Equal(t, time.Date(2021, 9, 1, 10, 0, 3, 0, time.UTC), lr.Timestamp().AsTime())
+	assert.Equal(t, pdata.SeverityNumberUNDEFINED, lr.SeverityNumber())
+	assert.Equal(t, "DEFAULT", lr.SeverityText())
+}
+
+func TestEntryWithoutTimestamps(t *testing.T) {
+	entry, err := parseLogEntry([]byte(`{"logName": "projects/p/logs/app", "textPayload": "line"}`))
+	require.NoError(t, err)
+	_, lr := convert(t, entry)
+
+	assert.Equal(t, time.Unix(1630490500, 0).UTC(), lr.Timestamp().AsTime())
+}
+
+func TestInvalidLogEntry(t *testing.T) {
+	for _, data := range []string{"plain text", `{"textPayload": "no log name"}`, `["array"]`} {
+		_, err := parseLogEntry([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
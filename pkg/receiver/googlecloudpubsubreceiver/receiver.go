@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudpubsubreceiver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"cloud.google.com/go/pubsub"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+type pubSubReceiver struct {
+	config       *Config
+	logger       *zap.Logger
+	logsConsumer consumer.Logs
+
+	client *pubsub.Client
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Ensure this receiver adheres to required interfaces.
+var _ component.LogsReceiver = (*pubSubReceiver)(nil)
+
+func newPubSubReceiver(logger *zap.Logger, cfg *Config, logsConsumer consumer.Logs) *pubSubReceiver {
+	return &pubSubReceiver{
+		config:       cfg,
+		logger:       logger,
+		logsConsumer: logsConsumer,
+	}
+}
+
+// Start connects to Pub/Sub and starts receiving the messages of the subscription
+func (r *pubSubReceiver) Start(_ context.Context, host component.Host) error {
+	projectID, subscriptionID := r.config.projectAndSubscriptionID()
+
+	// the client lives as long as the receiver, not just the start
+	ctx, cancel := context.WithCancel(context.Background())
+	client, err := pubsub.NewClient(ctx, projectID, r.clientOptions()...)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create the Pub/Sub client: %w", err)
+	}
+	r.client, r.cancel = client, cancel
+
+	subscription := client.Subscription(subscriptionID)
+	subscription.ReceiveSettings.MaxOutstandingMessages = r.config.MaxOutstandingMessages
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		// Receive retries the transient errors itself, so the returned errors are permanent,
+		// e.g. a missing subscription or permission
+		if err := subscription.Receive(ctx, r.handleMessage); err != nil && ctx.Err() == nil {
+			r.logger.Error("Failed to receive the Pub/Sub messages",
+				zap.String("subscription", r.config.Subscription), zap.Error(err))
+			host.ReportFatalError(err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops receiving the messages and closes the client
+func (r *pubSubReceiver) Shutdown(context.Context) error {
+	if r.client == nil {
+		return nil
+	}
+	r.cancel()
+	r.wg.Wait()
+	return r.client.Close()
+}
+
+func (r *pubSubReceiver) clientOptions() []option.ClientOption {
+	var opts []option.ClientOption
+	if r.config.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(r.config.Endpoint))
+	}
+	if r.config.Insecure {
+		opts = append(opts,
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithInsecure()))
+	} else if r.config.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(r.config.CredentialsFile))
+	}
+	return opts
+}
+
+// handleMessage passes the message to the next component. The message is acknowledged when it has been
+// consumed or when it can't be converted, and not acknowledged when consuming fails, so that it is redelivered.
+func (r *pubSubReceiver) handleMessage(ctx context.Context, msg *pubsub.Message) {
+	logs, err := r.toLogs(msg)
+	if err != nil {
+		r.logger.Warn("Dropping the invalid message", zap.String("id", msg.ID), zap.Error(err))
+		msg.Ack()
+		return
+	}
+
+	if err := r.logsConsumer.ConsumeLogs(ctx, logs); err != nil {
+		r.logger.Debug("Failed to consume the logs", zap.String("id", msg.ID), zap.Error(err))
+		msg.Nack()
+		return
+	}
+	msg.Ack()
+}
+
+// toLogs converts the message according to the encoding
+func (r *pubSubReceiver) toLogs(msg *pubsub.Message) (pdata.Logs, error) {
+	logs := pdata.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+
+	if r.config.Encoding != encodingRawText {
+		entry, err := parseLogEntry(msg.Data)
+		switch {
+		case err == nil:
+			entry.setResource(rl.Resource())
+			return logs, entry.setLogRecord(lr, msg.PublishTime)
+		case r.config.Encoding == encodingCloudLogging:
+			return logs, fmt.Errorf("invalid log entry: %w", err)
+		}
+	}
+
+	// raw text, with the message attributes as the log attributes
+	lr.SetTimestamp(pdata.TimestampFromTime(msg.PublishTime))
+	lr.Body().SetStringVal(string(msg.Data))
+	for k, v := range msg.Attributes {
+		lr.Attributes().UpsertString(k, v)
+	}
+	return logs, nil
+}
@@ -0,0 +1,218 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudpubsubreceiver
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+const (
+	testTopic        = "projects/my-project/topics/logs"
+	testSubscription = "projects/my-project/subscriptions/logs-export"
+)
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]interface{} {
+	result := map[string]interface{}{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = valueToInterface(v)
+		return true
+	})
+	return result
+}
+
+func valueToInterface(v pdata.AttributeValue) interface{} {
+	switch v.Type() {
+	case pdata.AttributeValueTypeInt:
+		return v.IntVal()
+	case pdata.AttributeValueTypeDouble:
+		return v.DoubleVal()
+	case pdata.AttributeValueTypeBool:
+		return v.BoolVal()
+	case pdata.AttributeValueTypeMap:
+		return attributesToMap(v.MapVal())
+	case pdata.AttributeValueTypeArray:
+		values := []interface{}{}
+		for i := 0; i < v.ArrayVal().Len(); i++ {
+			values = append(values, valueToInterface(v.ArrayVal().At(i)))
+		}
+		return values
+	case pdata.AttributeValueTypeNull:
+		return nil
+	default:
+		return v.StringVal()
+	}
+}
+
+// newTestServer starts the fake Pub/Sub server with the test topic and subscription
+func newTestServer(t *testing.T) *pstest.Server {
+	srv := pstest.NewServer()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	require.NoError(t, err)
+	client, err := pubsub.NewClient(context.Background(), "my-project", option.WithGRPCConn(conn))
+	require.NoError(t, err)
+	defer client.Close()
+
+	topic, err := client.CreateTopic(context.Background(), "logs")
+	require.NoError(t, err)
+	_, err = client.CreateSubscription(context.Background(), "logs-export", pubsub.SubscriptionConfig{
+		Topic:       topic,
+		AckDeadline: 10 * time.Second,
+	})
+	require.NoError(t, err)
+	return srv
+}
+
+func startTestReceiver(t *testing.T, srv *pstest.Server, encoding string, consumer *testConsumer) *pubSubReceiver {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Subscription = testSubscription
+	cfg.Endpoint = srv.Addr
+	cfg.Insecure = true
+	cfg.Encoding = encoding
+
+	r := newPubSubReceiver(zap.NewNop(), cfg, consumer)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, r.Shutdown(context.Background()))
+	})
+	return r
+}
+
+// testConsumer fails consuming the given number of times, and then passes the logs to the sink
+type testConsumer struct {
+	consumertest.LogsSink
+	lock     sync.Mutex
+	failures int
+}
+
+func (c *testConsumer) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
+	c.lock.Lock()
+	if c.failures > 0 {
+		c.failures--
+		c.lock.Unlock()
+		return errors.New("queue is full")
+	}
+	c.lock.Unlock()
+	return c.LogsSink.ConsumeLogs(ctx, ld)
+}
+
+func waitForAcks(t *testing.T, srv *pstest.Server, count int) {
+	assert.Eventually(t, func() bool {
+		acked := 0
+		for _, msg := range srv.Messages() {
+			if msg.Acks > 0 {
+				acked++
+			}
+		}
+		return acked == count
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestReceiveLogEntries(t *testing.T) {
+	srv := newTestServer(t)
+	data, err := ioutil.ReadFile(path.Join("testdata", "audit_log_entry.json"))
+	require.NoError(t, err)
+	srv.Publish(testTopic, data, nil)
+	srv.Publish(testTopic, []byte("plain text"), map[string]string{"source": "app"})
+
+	sink := &testConsumer{}
+	startTestReceiver(t, srv, encodingAuto, sink)
+	waitForAcks(t, srv, 2)
+
+	require.Equal(t, 2, sink.LogRecordCount())
+	bodies := map[string]pdata.LogRecord{}
+	for _, logs := range sink.AllLogs() {
+		lr := logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0)
+		bodies[lr.Body().Type().String()] = lr
+	}
+
+	entry := bodies[pdata.AttributeValueTypeMap.String()]
+	assert.Equal(t, "v1.compute.instances.delete", attributesToMap(entry.Attributes())["gcp.audit.method_name"])
+
+	raw := bodies[pdata.AttributeValueTypeString.String()]
+	assert.Equal(t, "plain text", raw.Body().StringVal())
+	assert.Equal(t, map[string]interface{}{"source": "app"}, attributesToMap(raw.Attributes()))
+	assert.NotZero(t, raw.Timestamp())
+}
+
+func TestEncodings(t *testing.T) {
+	entry := `{"logName": "projects/p/logs/app", "textPayload": "from entry"}`
+
+	testcases := []struct {
+		encoding string
+		expected []string
+	}{
+		{encoding: encodingCloudLogging, expected: []string{"from entry"}},
+		{encoding: encodingRawText, expected: []string{entry, "plain text"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.encoding, func(t *testing.T) {
+			srv := newTestServer(t)
+			srv.Publish(testTopic, []byte(entry), nil)
+			srv.Publish(testTopic, []byte("plain text"), nil)
+
+			sink := &testConsumer{}
+			startTestReceiver(t, srv, tc.encoding, sink)
+			// the invalid log entries are acknowledged, to not be redelivered
+			waitForAcks(t, srv, 2)
+
+			var bodies []string
+			for _, logs := range sink.AllLogs() {
+				bodies = append(bodies, logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Body().StringVal())
+			}
+			assert.ElementsMatch(t, tc.expected, bodies)
+		})
+	}
+}
+
+func TestRedeliveryOnConsumerError(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Publish(testTopic, []byte("retried"), nil)
+
+	sink := &testConsumer{failures: 1}
+	startTestReceiver(t, srv, encodingRawText, sink)
+	waitForAcks(t, srv, 1)
+
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Equal(t, "retried",
+		sink.AllLogs()[0].ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Body().StringVal())
+	assert.Equal(t, 2, srv.Messages()[0].Deliveries)
+}
+
+func TestShutdownWithoutStart(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Subscription = testSubscription
+	r := newPubSubReceiver(zap.NewNop(), cfg, consumertest.NewNop())
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
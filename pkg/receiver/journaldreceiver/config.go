@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journaldreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the journald receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:"-"`
+
+	// Directory is the directory the journal files are read from, the system journal when empty
+	Directory string `mapstructure:"directory"`
+
+	// Units are the systemd units whose entries are received, all of them when empty
+	Units []string `mapstructure:"units"`
+	// Priority is the lowest priority of the received entries, as a name, e.g. warning, or a number
+	Priority string `mapstructure:"priority"`
+
+	// IncludeFields are the journal fields set as the attributes, all of them when empty
+	IncludeFields []string `mapstructure:"include_fields"`
+	// ExcludeFields are the journal fields which are not set as the attributes
+	ExcludeFields []string `mapstructure:"exclude_fields"`
+
+	// StartAt is where the reading of the journal starts when there is no cursor: beginning or end
+	StartAt string `mapstructure:"start_at"`
+	// MaxBatchSize is the maximum number of entries sent in a single batch
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+
+	// Storage is the ID of the storage extension where the cursor of the last read entry
+	// is persisted, so that the receiver resumes from it after a restart
+	Storage string `mapstructure:"storage"`
+}
+
+const (
+	startAtBeginning = "beginning"
+	startAtEnd       = "end"
+
+	defaultPriority     = "info"
+	defaultMaxBatchSize = 100
+)
+
+// priorities are the syslog priority names used by journalctl, by their numbers
+var priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	for _, unit := range cfg.Units {
+		if unit == "" {
+			return errors.New("unit must not be empty")
+		}
+	}
+	if _, err := parsePriority(cfg.Priority); err != nil {
+		return err
+	}
+	for _, field := range append(append([]string{}, cfg.IncludeFields...), cfg.ExcludeFields...) {
+		if field == "" {
+			return errors.New("field must not be empty")
+		}
+	}
+	if cfg.StartAt != startAtBeginning && cfg.StartAt != startAtEnd {
+		return fmt.Errorf("start_at must be either %q or %q, got %q", startAtBeginning, startAtEnd, cfg.StartAt)
+	}
+	if cfg.MaxBatchSize <= 0 {
+		return fmt.Errorf("max_batch_size must be positive, got %d", cfg.MaxBatchSize)
+	}
+	if cfg.Storage != "" {
+		if _, err := config.NewIDFromString(cfg.Storage); err != nil {
+			return fmt.Errorf("invalid storage extension id %q: %w", cfg.Storage, err)
+		}
+	}
+	return nil
+}
+
+// parsePriority returns the number of the priority given by its name or number
+func parsePriority(priority string) (int, error) {
+	for number, name := range priorities {
+		if strings.EqualFold(priority, name) {
+			return number, nil
+		}
+	}
+	if number, err := strconv.Atoi(priority); err == nil && number >= 0 && number < len(priorities) {
+		return number, nil
+	}
+	return 0, fmt.Errorf("priority must be one of %s or a number from 0 to %d, got %q",
+		strings.Join(priorities, ", "), len(priorities)-1, priority)
+}
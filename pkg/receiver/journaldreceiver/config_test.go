@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journaldreceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "journald_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Receivers[config.NewID(typeStr)], factory.CreateDefaultConfig())
+
+	id := config.NewIDWithName(typeStr, "custom")
+	assert.Equal(t, cfg.Receivers[id],
+		&Config{
+			ReceiverSettings: config.NewReceiverSettings(id),
+			Directory:        "/var/log/journal",
+			Units:            []string{"ssh.service", "kubelet.service"},
+			Priority:         "warning",
+			IncludeFields:    []string{"_SYSTEMD_UNIT", "_HOSTNAME", "_PID", "SYSLOG_IDENTIFIER"},
+			ExcludeFields:    []string{"_PID"},
+			StartAt:          "beginning",
+			MaxBatchSize:     50,
+			Storage:          "file_storage",
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.NoError(t, createDefaultConfig().(*Config).Validate())
+
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{name: "empty unit", modify: func(cfg *Config) { cfg.Units = []string{"ssh.service", ""} }},
+		{name: "unknown priority", modify: func(cfg *Config) { cfg.Priority = "loud" }},
+		{name: "priority out of range", modify: func(cfg *Config) { cfg.Priority = "8" }},
+		{name: "empty field", modify: func(cfg *Config) { cfg.ExcludeFields = []string{""} }},
+		{name: "invalid start_at", modify: func(cfg *Config) { cfg.StartAt = "middle" }},
+		{name: "invalid max_batch_size", modify: func(cfg *Config) { cfg.MaxBatchSize = 0 }},
+		{name: "invalid storage", modify: func(cfg *Config) { cfg.Storage = "/" }},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for priority, expected := range map[string]int{"emerg": 0, "ERR": 3, "warning": 4, "7": 7} {
+		number, err := parsePriority(priority)
+		require.NoError(t, err)
+		assert.Equal(t, expected, number, priority)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journaldreceiver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	attributePrefix = "journald."
+
+	fieldMessage           = "MESSAGE"
+	fieldPriority          = "PRIORITY"
+	fieldCursor            = "__CURSOR"
+	fieldRealtimeTimestamp = "__REALTIME_TIMESTAMP"
+)
+
+// severities are the severities of the syslog priorities, by their numbers
+var severities = []pdata.SeverityNumber{
+	pdata.SeverityNumberFATAL3,
+	pdata.SeverityNumberFATAL2,
+	pdata.SeverityNumberFATAL,
+	pdata.SeverityNumberERROR,
+	pdata.SeverityNumberWARN,
+	pdata.SeverityNumberINFO2,
+	pdata.SeverityNumberINFO,
+	pdata.SeverityNumberDEBUG,
+}
+
+// journalEntry is the entry in the journalctl JSON format, where the values are strings,
+// arrays of bytes for the binary values or arrays of both for the fields set more than once
+type journalEntry map[string]interface{}
+
+func unmarshalJournalEntry(line []byte) (journalEntry, error) {
+	var entry journalEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return nil, fmt.Errorf("invalid journal entry: %w", err)
+	}
+	if _, ok := entry[fieldCursor].(string); !ok {
+		return nil, errors.New("invalid journal entry: missing cursor")
+	}
+	return entry, nil
+}
+
+func (e journalEntry) cursor() string {
+	cursor, _ := e[fieldCursor].(string)
+	return cursor
+}
+
+// fillLogRecord sets the message as the body, the realtime timestamp, the severity of the priority
+// and the other fields selected by accept as the attributes of the record
+func (e journalEntry) fillLogRecord(lr pdata.LogRecord, accept func(field string) bool) {
+	if message, ok := e[fieldMessage]; ok {
+		setValue(lr.Body(), message)
+	}
+	if realtime, ok := e[fieldRealtimeTimestamp].(string); ok {
+		if micros, err := strconv.ParseUint(realtime, 10, 64); err == nil {
+			lr.SetTimestamp(pdata.Timestamp(micros * 1000))
+		}
+	}
+	if priority, ok := e[fieldPriority].(string); ok {
+		if number, err := strconv.Atoi(priority); err == nil && number >= 0 && number < len(priorities) {
+			lr.SetSeverityNumber(severities[number])
+			lr.SetSeverityText(priorities[number])
+		}
+	}
+
+	attributes := lr.Attributes()
+	for field, value := range e {
+		// the address fields, e.g. the cursor, are not a part of the entry data
+		if field == fieldMessage || strings.HasPrefix(field, "__") || !accept(field) {
+			continue
+		}
+		name := attributeName(field)
+		// the trusted fields, added by journald, take precedence over the ones set by the clients
+		if _, ok := attributes.Get(name); ok && !strings.HasPrefix(field, "_") {
+			continue
+		}
+		attribute := pdata.NewAttributeValueNull()
+		setValue(attribute, value)
+		attributes.Upsert(name, attribute)
+	}
+}
+
+// attributeName returns the attribute name of the field, e.g. journald.systemd_unit for _SYSTEMD_UNIT
+func attributeName(field string) string {
+	return attributePrefix + strings.ToLower(strings.TrimLeft(field, "_"))
+}
+
+func setValue(attribute pdata.AttributeValue, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		attribute.SetStringVal(v)
+	case []interface{}:
+		if data, ok := toBytes(v); ok {
+			if utf8.Valid(data) {
+				attribute.SetStringVal(string(data))
+			} else {
+				attribute.SetBytesVal(data)
+			}
+			return
+		}
+		pdata.NewAttributeValueArray().CopyTo(attribute)
+		values := attribute.ArrayVal()
+		for _, item := range v {
+			setValue(values.AppendEmpty(), item)
+		}
+	}
+}
+
+// toBytes converts the array of numbers, i.e. the binary value, to bytes
+func toBytes(values []interface{}) ([]byte, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+	data := make([]byte, len(values))
+	for i, value := range values {
+		number, ok := value.(float64)
+		if !ok || number < 0 || number > 255 {
+			return nil, false
+		}
+		data[i] = byte(number)
+	}
+	return data, true
+}
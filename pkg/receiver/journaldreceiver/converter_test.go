@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journaldreceiver
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func readEntries(t *testing.T) []journalEntry {
+	data, err := ioutil.ReadFile(path.Join(".", "testdata", "entries.json"))
+	require.NoError(t, err)
+	var entries []journalEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if entry, err := unmarshalJournalEntry([]byte(line)); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func acceptAll(string) bool {
+	return true
+}
+
+func TestFillLogRecord(t *testing.T) {
+	entries := readEntries(t)
+	require.Len(t, entries, 3)
+
+	lr := pdata.NewLogRecord()
+	entries[0].fillLogRecord(lr, acceptAll)
+	assert.Equal(t, "Accepted publickey for admin", lr.Body().StringVal())
+	assert.Equal(t, pdata.Timestamp(1630497600123456000), lr.Timestamp())
+	assert.Equal(t, pdata.SeverityNumberINFO, lr.SeverityNumber())
+	assert.Equal(t, "info", lr.SeverityText())
+	assert.Equal(t, map[string]string{
+		"journald.boot_id":           "b1",
+		"journald.priority":          "6",
+		"journald.syslog_identifier": "sshd",
+		"journald.pid":               "1234",
+		"journald.hostname":          "vm-1",
+		"journald.systemd_unit":      "ssh.service",
+	}, attributesToMap(lr.Attributes()))
+}
+
+func TestFillLogRecordValues(t *testing.T) {
+	entries := readEntries(t)
+
+	lr := pdata.NewLogRecord()
+	entries[1].fillLogRecord(lr, func(field string) bool { return field != "PRIORITY" })
+	assert.Equal(t, "failed\n", lr.Body().StringVal())
+	assert.Equal(t, pdata.SeverityNumberERROR, lr.SeverityNumber())
+	assert.Equal(t, "err", lr.SeverityText())
+
+	codeFile, ok := lr.Attributes().Get("journald.code_file")
+	require.True(t, ok)
+	require.Equal(t, pdata.AttributeValueTypeArray, codeFile.Type())
+	require.Equal(t, 2, codeFile.ArrayVal().Len())
+	assert.Equal(t, "b.go", codeFile.ArrayVal().At(1).StringVal())
+
+	binary, ok := lr.Attributes().Get("journald.binary")
+	require.True(t, ok)
+	assert.Equal(t, []byte{255, 254}, binary.BytesVal())
+
+	_, ok = lr.Attributes().Get("journald.priority")
+	assert.False(t, ok)
+}
+
+func TestFillLogRecordTrustedFields(t *testing.T) {
+	// the trusted _PID field is kept over the PID field set by the client
+	for i := 0; i < 10; i++ {
+		lr := pdata.NewLogRecord()
+		readEntries(t)[2].fillLogRecord(lr, acceptAll)
+		assert.Equal(t, pdata.SeverityNumberFATAL3, lr.SeverityNumber())
+		pid, ok := lr.Attributes().Get("journald.pid")
+		require.True(t, ok)
+		assert.Equal(t, "4321", pid.StringVal())
+	}
+}
+
+func TestUnmarshalInvalidEntry(t *testing.T) {
+	_, err := unmarshalJournalEntry([]byte(`{"MESSAGE":"no cursor"}`))
+	assert.Error(t, err)
+	_, err = unmarshalJournalEntry([]byte(`MESSAGE=text`))
+	assert.Error(t, err)
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journaldreceiver
+
+import (
+	"context"
+	"io"
+	"os/exec"
+)
+
+// journalctlPath is the journalctl binary, looked up in PATH
+const journalctlPath = "journalctl"
+
+// journalctl is the running journalctl process
+type journalctl struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+// openJournalctl starts journalctl with the arguments, returning its standard output
+func openJournalctl(ctx context.Context, args []string) (io.ReadCloser, error) {
+	cmd := exec.CommandContext(ctx, journalctlPath, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &journalctl{ReadCloser: stdout, cmd: cmd}, nil
+}
+
+// Close stops journalctl and waits for it to exit
+func (j *journalctl) Close() error {
+	// killing fails when journalctl has already exited and the exit error is expected otherwise
+	_ = j.cmd.Process.Kill()
+	_ = j.cmd.Wait()
+	return nil
+}
@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journaldreceiver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+const (
+	// cursorKey is the storage key holding the cursor of the last read entry
+	cursorKey = "cursor"
+)
+
+// restartDelay is the delay of restarting journalctl after it has exited or failed to start
+var restartDelay = 5 * time.Second
+
+// openJournalFunc starts reading the journal with the journalctl arguments,
+// returning the entries in the JSON format, one per line
+type openJournalFunc func(ctx context.Context, args []string) (io.ReadCloser, error)
+
+type journaldReceiver struct {
+	config   *Config
+	logger   *zap.Logger
+	consumer consumer.Logs
+	open     openJournalFunc
+
+	includeFields map[string]bool
+	excludeFields map[string]bool
+
+	storageClient storage.Client
+	cursor        string
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// Ensure this receiver adheres to required interface.
+var _ component.LogsReceiver = (*journaldReceiver)(nil)
+
+func newJournaldReceiver(
+	logger *zap.Logger,
+	cfg *Config,
+	nextConsumer consumer.Logs,
+	open openJournalFunc,
+) *journaldReceiver {
+	return &journaldReceiver{
+		config:        cfg,
+		logger:        logger,
+		consumer:      nextConsumer,
+		open:          open,
+		includeFields: toSet(cfg.IncludeFields),
+		excludeFields: toSet(cfg.ExcludeFields),
+	}
+}
+
+// Start loads the persisted cursor and starts reading the journal after it
+func (r *journaldReceiver) Start(ctx context.Context, host component.Host) error {
+	if r.config.Storage != "" {
+		var err error
+		if r.storageClient, err = r.getStorageClient(ctx, host); err != nil {
+			return err
+		}
+		cursor, err := r.storageClient.Get(ctx, cursorKey)
+		if err != nil {
+			return fmt.Errorf("failed to read the journal cursor: %w", err)
+		}
+		r.cursor = string(cursor)
+	}
+
+	rctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go r.run(rctx)
+	return nil
+}
+
+// Shutdown stops journalctl
+func (r *journaldReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	if r.storageClient != nil {
+		return r.storageClient.Close(ctx)
+	}
+	return nil
+}
+
+func (r *journaldReceiver) getStorageClient(ctx context.Context, host component.Host) (storage.Client, error) {
+	id, err := config.NewIDFromString(r.config.Storage)
+	if err != nil {
+		return nil, err
+	}
+	extension, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", r.config.Storage)
+	}
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", r.config.Storage)
+	}
+	return storageExtension.GetClient(ctx, component.KindReceiver, r.config.ID(), "")
+}
+
+// args returns the journalctl arguments, starting after the cursor if there is one
+func (r *journaldReceiver) args() []string {
+	args := []string{"--utc", "--output=json", "--follow", "--priority=" + r.config.Priority}
+	if r.config.Directory != "" {
+		args = append(args, "--directory="+r.config.Directory)
+	}
+	for _, unit := range r.config.Units {
+		args = append(args, "--unit="+unit)
+	}
+	switch {
+	case r.cursor != "":
+		args = append(args, "--after-cursor="+r.cursor)
+	case r.config.StartAt == startAtBeginning:
+		args = append(args, "--no-tail")
+	default:
+		args = append(args, "--lines=0")
+	}
+	return args
+}
+
+// run reads the journal until the context is done, restarting journalctl after the last read entry
+// whenever it exits
+func (r *journaldReceiver) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	for {
+		journal, err := r.open(ctx, r.args())
+		if err != nil {
+			r.logger.Error("Failed to start journalctl", zap.Error(err))
+		} else {
+			r.read(ctx, journal)
+			if err := journal.Close(); err != nil {
+				r.logger.Warn("Failed to stop journalctl", zap.Error(err))
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		r.logger.Warn("Reading the journal stopped, restarting", zap.Duration("delay", restartDelay))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(restartDelay):
+		}
+	}
+}
+
+// read consumes the entries of the journal until it ends; the entries already available
+// are sent in a single batch, up to the maximum batch size
+func (r *journaldReceiver) read(ctx context.Context, journal io.Reader) {
+	reader := bufio.NewReader(journal)
+	var batch []journalEntry
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			entry, entryErr := unmarshalJournalEntry(line)
+			if entryErr != nil {
+				r.logger.Warn("Dropping the journal entry", zap.Error(entryErr))
+			} else {
+				batch = append(batch, entry)
+			}
+		}
+		if err != nil || reader.Buffered() == 0 || len(batch) >= r.config.MaxBatchSize {
+			r.consume(ctx, batch)
+			batch = batch[:0]
+		}
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				r.logger.Warn("Failed to read the journal", zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+func (r *journaldReceiver) consume(ctx context.Context, entries []journalEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	logs := pdata.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	for _, entry := range entries {
+		entry.fillLogRecord(records.AppendEmpty(), r.acceptField)
+	}
+	if err := r.consumer.ConsumeLogs(ctx, logs); err != nil {
+		r.logger.Warn("Failed to consume the journal entries", zap.Error(err))
+	}
+
+	r.cursor = entries[len(entries)-1].cursor()
+	r.saveCursor(ctx)
+}
+
+func (r *journaldReceiver) saveCursor(ctx context.Context) {
+	if r.storageClient == nil {
+		return
+	}
+	if err := r.storageClient.Set(ctx, cursorKey, []byte(r.cursor)); err != nil {
+		r.logger.Warn("Failed to persist the journal cursor", zap.Error(err))
+	}
+}
+
+// acceptField checks the journal field against the include and exclude lists
+func (r *journaldReceiver) acceptField(field string) bool {
+	if len(r.includeFields) > 0 && !r.includeFields[field] {
+		return false
+	}
+	return !r.excludeFields[field]
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journaldreceiver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.uber.org/zap"
+)
+
+type testStorageExtension struct {
+	component.Extension
+	client *testStorageClient
+}
+
+func (e *testStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return e.client, nil
+}
+
+type testStorageClient struct {
+	storage.Client
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (c *testStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data[key], nil
+}
+
+func (c *testStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	return nil
+}
+
+func (c *testStorageClient) Close(context.Context) error {
+	return nil
+}
+
+type testHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+// fakeJournal serves the test entries on the first open and blocks until the context is done afterwards,
+// recording the arguments of each open
+type fakeJournal struct {
+	mu      sync.Mutex
+	entries string
+	args    [][]string
+}
+
+func (j *fakeJournal) open(ctx context.Context, args []string) (io.ReadCloser, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.args = append(j.args, args)
+	if len(j.args) == 1 {
+		return ioutil.NopCloser(strings.NewReader(j.entries)), nil
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		<-ctx.Done()
+		writer.Close()
+	}()
+	return reader, nil
+}
+
+func (j *fakeJournal) opened() [][]string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([][]string{}, j.args...)
+}
+
+func newFakeJournal(t *testing.T) *fakeJournal {
+	data, err := ioutil.ReadFile(path.Join(".", "testdata", "entries.json"))
+	require.NoError(t, err)
+	return &fakeJournal{entries: string(data)}
+}
+
+func TestReceiveEntries(t *testing.T) {
+	restartDelay = 10 * time.Millisecond
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Units = []string{"ssh.service", "kubelet.service"}
+	cfg.MaxBatchSize = 2
+	cfg.Storage = "file_storage"
+
+	storageClient := &testStorageClient{data: map[string][]byte{cursorKey: []byte("s=1;i=0")}}
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewID("file_storage"): &testStorageExtension{client: storageClient},
+		},
+	}
+
+	journal := newFakeJournal(t)
+	sink := new(consumertest.LogsSink)
+	r := newJournaldReceiver(zap.NewNop(), cfg, sink, journal.open)
+	require.NoError(t, r.Start(context.Background(), host))
+
+	// journalctl is restarted after the last read entry when it exits
+	assert.Eventually(t, func() bool {
+		return len(journal.opened()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	args := journal.opened()
+	assert.Equal(t, []string{
+		"--utc", "--output=json", "--follow", "--priority=info",
+		"--unit=ssh.service", "--unit=kubelet.service", "--after-cursor=s=1;i=0",
+	}, args[0])
+	assert.Equal(t, "--after-cursor=s=1;i=3", args[1][len(args[1])-1])
+	assert.Equal(t, "s=1;i=3", string(storageClient.data[cursorKey]))
+
+	// the invalid entry is dropped
+	assert.Equal(t, 3, sink.LogRecordCount())
+	assert.Len(t, sink.AllLogs(), 2)
+}
+
+func TestArgs(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	r := newJournaldReceiver(zap.NewNop(), cfg, consumertest.NewNop(), nil)
+	assert.Equal(t, []string{"--utc", "--output=json", "--follow", "--priority=info", "--lines=0"}, r.args())
+
+	cfg.StartAt = startAtBeginning
+	cfg.Directory = "/run/log/journal"
+	cfg.Priority = "3"
+	assert.Equal(t, []string{
+		"--utc", "--output=json", "--follow", "--priority=3", "--directory=/run/log/journal", "--no-tail",
+	}, r.args())
+}
+
+func TestStartFailureRetried(t *testing.T) {
+	restartDelay = 10 * time.Millisecond
+
+	var mu sync.Mutex
+	attempts := 0
+	open := func(context.Context, []string) (io.ReadCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return nil, errors.New("journalctl not found")
+	}
+
+	r := newJournaldReceiver(zap.NewNop(), createDefaultConfig().(*Config), consumertest.NewNop(), open)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts > 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestMissingStorageExtension(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = "file_storage"
+	r := newJournaldReceiver(zap.NewNop(), cfg, consumertest.NewNop(), nil)
+	assert.Error(t, r.Start(context.Background(), componenttest.NewNopHost()))
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8seventsreceiver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the Kubernetes events receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:"-"`
+	k8sconfig.APIConfig     `mapstructure:",squash"`
+
+	// Namespaces are the namespaces the events are watched in, all of them when empty
+	Namespaces []string `mapstructure:"namespaces"`
+
+	// Storage is the ID of the storage extension where the resource version of the last received event
+	// is persisted, so that the receiver resumes from it after a restart
+	Storage string `mapstructure:"storage"`
+
+	// DedupWindow is the period in which the repeated occurrences of an event are emitted only once,
+	// 0 disables the deduplication
+	DedupWindow time.Duration `mapstructure:"dedup_window"`
+
+	// RetryInterval is the time to wait before the watch is restarted after a failure
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+}
+
+const (
+	defaultDedupWindow   = time.Minute
+	defaultRetryInterval = 5 * time.Second
+)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if err := cfg.APIConfig.Validate(); err != nil {
+		return err
+	}
+	if cfg.Storage != "" {
+		if _, err := config.NewIDFromString(cfg.Storage); err != nil {
+			return fmt.Errorf("invalid storage extension id %q: %w", cfg.Storage, err)
+		}
+	}
+	if cfg.DedupWindow < 0 {
+		return fmt.Errorf("dedup_window must not be negative, got %s", cfg.DedupWindow)
+	}
+	if cfg.RetryInterval <= 0 {
+		return fmt.Errorf("retry_interval must be positive, got %s", cfg.RetryInterval)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8seventsreceiver
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+)
+
+const (
+	attributeNamespace = "k8s.namespace.name"
+
+	attributeEventName                = "k8s.event.name"
+	attributeEventUID                 = "k8s.event.uid"
+	attributeEventType                = "k8s.event.type"
+	attributeEventReason              = "k8s.event.reason"
+	attributeEventAction              = "k8s.event.action"
+	attributeEventCount               = "k8s.event.count"
+	attributeEventStartTime           = "k8s.event.start_time"
+	attributeEventReportingController = "k8s.event.reporting_controller"
+	attributeEventReportingInstance   = "k8s.event.reporting_instance"
+
+	// the prefixes of the attributes describing the object the event is about and the related object
+	regardingPrefix = "k8s.object."
+	relatedPrefix   = "k8s.related_object."
+)
+
+// eventToLogs appends a resource log holding the event as a log record with the event metadata
+// and the metadata of the involved objects as attributes
+func eventToLogs(event *eventsv1.Event, logs pdata.Logs) {
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString(attributeNamespace, event.Namespace)
+
+	lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	lr.SetTimestamp(pdata.TimestampFromTime(eventTimestamp(event)))
+	lr.Body().SetStringVal(event.Note)
+	lr.SetSeverityText(event.Type)
+	switch event.Type {
+	case corev1.EventTypeNormal:
+		lr.SetSeverityNumber(pdata.SeverityNumberINFO)
+	case corev1.EventTypeWarning:
+		lr.SetSeverityNumber(pdata.SeverityNumberWARN)
+	}
+
+	attributes := lr.Attributes()
+	attributes.InsertString(attributeEventName, event.Name)
+	attributes.InsertString(attributeEventUID, string(event.UID))
+	attributes.InsertString(attributeEventType, event.Type)
+	attributes.InsertString(attributeEventReason, event.Reason)
+	attributes.InsertInt(attributeEventCount, int64(eventCount(event)))
+	insertNotEmpty(attributes, attributeEventAction, event.Action)
+	insertNotEmpty(attributes, attributeEventReportingController, event.ReportingController)
+	insertNotEmpty(attributes, attributeEventReportingInstance, event.ReportingInstance)
+	if start := eventStartTime(event); !start.IsZero() {
+		attributes.InsertString(attributeEventStartTime, start.UTC().Format(time.RFC3339Nano))
+	}
+
+	insertObjectReference(attributes, regardingPrefix, &event.Regarding)
+	if event.Related != nil {
+		insertObjectReference(attributes, relatedPrefix, event.Related)
+	}
+}
+
+func insertObjectReference(attributes pdata.AttributeMap, prefix string, ref *corev1.ObjectReference) {
+	insertNotEmpty(attributes, prefix+"kind", ref.Kind)
+	insertNotEmpty(attributes, prefix+"name", ref.Name)
+	insertNotEmpty(attributes, prefix+"namespace", ref.Namespace)
+	insertNotEmpty(attributes, prefix+"uid", string(ref.UID))
+	insertNotEmpty(attributes, prefix+"api_version", ref.APIVersion)
+	insertNotEmpty(attributes, prefix+"resource_version", ref.ResourceVersion)
+	insertNotEmpty(attributes, prefix+"field_path", ref.FieldPath)
+}
+
+func insertNotEmpty(attributes pdata.AttributeMap, name string, value string) {
+	if value != "" {
+		attributes.InsertString(name, value)
+	}
+}
+
+// eventCount returns the number of occurrences of the event
+func eventCount(event *eventsv1.Event) int32 {
+	if event.Series != nil {
+		return event.Series.Count
+	}
+	if event.DeprecatedCount > 0 {
+		return event.DeprecatedCount
+	}
+	return 1
+}
+
+// eventTimestamp returns the time of the last occurrence of the event
+func eventTimestamp(event *eventsv1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.DeprecatedLastTimestamp.IsZero():
+		return event.DeprecatedLastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.DeprecatedFirstTimestamp.IsZero():
+		return event.DeprecatedFirstTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// eventStartTime returns the time of the first occurrence of the event
+func eventStartTime(event *eventsv1.Event) time.Time {
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.DeprecatedFirstTimestamp.Time
+}
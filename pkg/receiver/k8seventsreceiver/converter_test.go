@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8seventsreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventToLogs(t *testing.T) {
+	first := time.Date(2021, 8, 10, 12, 0, 0, 0, time.UTC)
+	last := first.Add(time.Minute)
+	event := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-1.169a0b2c3d",
+			Namespace:       "payments",
+			UID:             "4c0d4b4e",
+			ResourceVersion: "1200",
+		},
+		EventTime:           metav1.NewMicroTime(first),
+		Series:              &eventsv1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(last)},
+		ReportingController: "kubelet",
+		ReportingInstance:   "node-1",
+		Action:              "Pulling",
+		Reason:              "BackOff",
+		Note:                "Back-off restarting failed container",
+		Type:                corev1.EventTypeWarning,
+		Regarding: corev1.ObjectReference{
+			Kind:            "Pod",
+			Namespace:       "payments",
+			Name:            "app-1",
+			UID:             "a1b2",
+			APIVersion:      "v1",
+			ResourceVersion: "1100",
+			FieldPath:       "spec.containers{app}",
+		},
+		Related: &corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+	}
+
+	logs := pdata.NewLogs()
+	eventToLogs(event, logs)
+
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	rl := logs.ResourceLogs().At(0)
+	namespace, _ := rl.Resource().Attributes().Get(attributeNamespace)
+	assert.Equal(t, "payments", namespace.StringVal())
+
+	lr := rl.InstrumentationLibraryLogs().At(0).Logs().At(0)
+	assert.Equal(t, "Back-off restarting failed container", lr.Body().StringVal())
+	assert.Equal(t, pdata.TimestampFromTime(last), lr.Timestamp())
+	assert.Equal(t, pdata.SeverityNumberWARN, lr.SeverityNumber())
+	assert.Equal(t, "Warning", lr.SeverityText())
+
+	expected := map[string]interface{}{
+		"k8s.event.name":                 "app-1.169a0b2c3d",
+		"k8s.event.uid":                  "4c0d4b4e",
+		"k8s.event.type":                 "Warning",
+		"k8s.event.reason":               "BackOff",
+		"k8s.event.action":               "Pulling",
+		"k8s.event.count":                int64(3),
+		"k8s.event.start_time":           "2021-08-10T12:00:00Z",
+		"k8s.event.reporting_controller": "kubelet",
+		"k8s.event.reporting_instance":   "node-1",
+		"k8s.object.kind":                "Pod",
+		"k8s.object.name":                "app-1",
+		"k8s.object.namespace":           "payments",
+		"k8s.object.uid":                 "a1b2",
+		"k8s.object.api_version":         "v1",
+		"k8s.object.resource_version":    "1100",
+		"k8s.object.field_path":          "spec.containers{app}",
+		"k8s.related_object.kind":        "Node",
+		"k8s.related_object.name":        "node-1",
+	}
+	actual := map[string]interface{}{}
+	lr.Attributes().Range(func(name string, value pdata.AttributeValue) bool {
+		if value.Type() == pdata.AttributeValueTypeInt {
+			actual[name] = value.IntVal()
+		} else {
+			actual[name] = value.StringVal()
+		}
+		return true
+	})
+	assert.Equal(t, expected, actual)
+}
+
+func TestEventTimestamp(t *testing.T) {
+	created := time.Date(2021, 8, 10, 12, 0, 0, 0, time.UTC)
+	event := &eventsv1.Event{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}}
+	assert.Equal(t, created, eventTimestamp(event))
+	assert.Equal(t, int32(1), eventCount(event))
+
+	event.DeprecatedFirstTimestamp = metav1.NewTime(created.Add(time.Second))
+	assert.Equal(t, created.Add(time.Second), eventTimestamp(event))
+	assert.Equal(t, created.Add(time.Second), eventStartTime(event))
+
+	event.DeprecatedLastTimestamp = metav1.NewTime(created.Add(time.Minute))
+	event.DeprecatedCount = 4
+	assert.Equal(t, created.Add(time.Minute), eventTimestamp(event))
+	assert.Equal(t, int32(4), eventCount(event))
+}
@@ -0,0 +1,320 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8seventsreceiver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+	eventsv1 "k8s.io/api/events/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// resourceVersionKey is the prefix of the storage keys holding the resource versions, by namespace
+	resourceVersionKey = "resource_version."
+	allNamespacesKey   = "*"
+)
+
+type k8sEventsReceiver struct {
+	config     *Config
+	logger     *zap.Logger
+	consumer   consumer.Logs
+	makeClient func(k8sconfig.APIConfig) (k8s.Interface, error)
+
+	client        k8s.Interface
+	storageClient storage.Client
+	startTime     time.Time
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// namespaceWatcher watches the events of a single namespace, or all of them
+type namespaceWatcher struct {
+	receiver        *k8sEventsReceiver
+	namespace       string
+	resourceVersion string
+	// seen are the events received so far, by their UID
+	seen map[types.UID]*seenEvent
+}
+
+type seenEvent struct {
+	resourceVersion string
+	count           int32
+	emitted         time.Time
+}
+
+// Ensure this receiver adheres to required interface.
+var _ component.LogsReceiver = (*k8sEventsReceiver)(nil)
+
+func newK8sEventsReceiver(
+	logger *zap.Logger,
+	cfg *Config,
+	nextConsumer consumer.Logs,
+	makeClient func(k8sconfig.APIConfig) (k8s.Interface, error),
+) *k8sEventsReceiver {
+	return &k8sEventsReceiver{
+		config:     cfg,
+		logger:     logger,
+		consumer:   nextConsumer,
+		makeClient: makeClient,
+	}
+}
+
+// Start connects to the Kubernetes API and starts watching the events
+func (r *k8sEventsReceiver) Start(ctx context.Context, host component.Host) error {
+	client, err := r.makeClient(r.config.APIConfig)
+	if err != nil {
+		return err
+	}
+	r.client = client
+
+	if r.config.Storage != "" {
+		if r.storageClient, err = r.getStorageClient(ctx, host); err != nil {
+			return err
+		}
+	}
+
+	namespaces := r.config.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	watchers := make([]*namespaceWatcher, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		w := &namespaceWatcher{
+			receiver:  r,
+			namespace: namespace,
+			seen:      map[types.UID]*seenEvent{},
+		}
+		if err := w.loadResourceVersion(ctx); err != nil {
+			return err
+		}
+		watchers = append(watchers, w)
+	}
+
+	r.startTime = time.Now()
+	rctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	for _, w := range watchers {
+		r.wg.Add(1)
+		go w.run(rctx)
+	}
+	return nil
+}
+
+// Shutdown stops watching the events
+func (r *k8sEventsReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	if r.storageClient != nil {
+		return r.storageClient.Close(ctx)
+	}
+	return nil
+}
+
+func (r *k8sEventsReceiver) getStorageClient(ctx context.Context, host component.Host) (storage.Client, error) {
+	id, err := config.NewIDFromString(r.config.Storage)
+	if err != nil {
+		return nil, err
+	}
+	extension, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", r.config.Storage)
+	}
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", r.config.Storage)
+	}
+	return storageExtension.GetClient(ctx, component.KindReceiver, r.config.ID(), "")
+}
+
+func (w *namespaceWatcher) storageKey() string {
+	if w.namespace == metav1.NamespaceAll {
+		return resourceVersionKey + allNamespacesKey
+	}
+	return resourceVersionKey + w.namespace
+}
+
+func (w *namespaceWatcher) loadResourceVersion(ctx context.Context) error {
+	if w.receiver.storageClient == nil {
+		return nil
+	}
+	value, err := w.receiver.storageClient.Get(ctx, w.storageKey())
+	if err != nil {
+		return fmt.Errorf("failed to read the resource version: %w", err)
+	}
+	w.resourceVersion = string(value)
+	return nil
+}
+
+func (w *namespaceWatcher) setResourceVersion(ctx context.Context, resourceVersion string) {
+	if resourceVersion == "" || resourceVersion == w.resourceVersion {
+		return
+	}
+	w.resourceVersion = resourceVersion
+	if w.receiver.storageClient == nil {
+		return
+	}
+	if err := w.receiver.storageClient.Set(ctx, w.storageKey(), []byte(resourceVersion)); err != nil {
+		w.receiver.logger.Warn("Failed to persist the resource version", zap.String("namespace", w.namespace), zap.Error(err))
+	}
+}
+
+// run watches the events until the context is done, restarting the watch after failures
+func (w *namespaceWatcher) run(ctx context.Context) {
+	defer w.receiver.wg.Done()
+	for {
+		if err := w.watch(ctx); err != nil && ctx.Err() == nil {
+			w.receiver.logger.Warn("Watching the Kubernetes events failed, retrying",
+				zap.String("namespace", w.namespace), zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.receiver.config.RetryInterval):
+		}
+	}
+}
+
+// watch lists the events if there is no resource version to resume from and watches the events since then
+func (w *namespaceWatcher) watch(ctx context.Context) error {
+	events := w.receiver.client.EventsV1().Events(w.namespace)
+	if w.resourceVersion == "" {
+		list, err := events.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list the events: %w", err)
+		}
+		// Without any history, the events which happened before the start have most likely been
+		// received already, e.g. before a restart
+		skipOld := len(w.seen) == 0
+		logs := pdata.NewLogs()
+		for i := range list.Items {
+			event := &list.Items[i]
+			if w.track(event) && !(skipOld && eventTimestamp(event).Before(w.receiver.startTime)) {
+				eventToLogs(event, logs)
+			}
+		}
+		w.consume(ctx, logs)
+		w.setResourceVersion(ctx, list.ResourceVersion)
+	}
+
+	watcher, err := events.Watch(ctx, metav1.ListOptions{
+		ResourceVersion:     w.resourceVersion,
+		AllowWatchBookmarks: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch the events: %w", err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-watcher.ResultChan():
+			if !ok {
+				// The watch timed out, it is resumed from the last resource version
+				return nil
+			}
+			if err := w.handle(ctx, ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *namespaceWatcher) handle(ctx context.Context, ev watch.Event) error {
+	switch ev.Type {
+	case watch.Added, watch.Modified:
+		event, ok := ev.Object.(*eventsv1.Event)
+		if !ok {
+			return nil
+		}
+		if w.track(event) {
+			logs := pdata.NewLogs()
+			eventToLogs(event, logs)
+			w.consume(ctx, logs)
+		}
+		w.setResourceVersion(ctx, event.ResourceVersion)
+	case watch.Deleted:
+		if event, ok := ev.Object.(*eventsv1.Event); ok {
+			delete(w.seen, event.UID)
+			w.setResourceVersion(ctx, event.ResourceVersion)
+		}
+	case watch.Bookmark:
+		if object, err := meta.Accessor(ev.Object); err == nil {
+			w.setResourceVersion(ctx, object.GetResourceVersion())
+		}
+	case watch.Error:
+		err := apierrors.FromObject(ev.Object)
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			// The resource version is too old, the events are listed again
+			w.resourceVersion = ""
+		}
+		return err
+	}
+	return nil
+}
+
+// track records the event and returns whether it should be emitted: the deliveries of an event which
+// was already received, and its repeated occurrences in the dedup window, are not
+func (w *namespaceWatcher) track(event *eventsv1.Event) bool {
+	now := time.Now()
+	count := eventCount(event)
+	seen, ok := w.seen[event.UID]
+	if !ok {
+		w.seen[event.UID] = &seenEvent{resourceVersion: event.ResourceVersion, count: count, emitted: now}
+		return true
+	}
+
+	if seen.resourceVersion == event.ResourceVersion {
+		return false
+	}
+	seen.resourceVersion = event.ResourceVersion
+	if count <= seen.count {
+		return false
+	}
+	seen.count = count
+	if window := w.receiver.config.DedupWindow; window > 0 && now.Sub(seen.emitted) < window {
+		return false
+	}
+	seen.emitted = now
+	return true
+}
+
+func (w *namespaceWatcher) consume(ctx context.Context, logs pdata.Logs) {
+	if logs.ResourceLogs().Len() == 0 {
+		return
+	}
+	if err := w.receiver.consumer.ConsumeLogs(ctx, logs); err != nil {
+		w.receiver.logger.Error("Failed to send the Kubernetes events", zap.Error(err))
+	}
+}
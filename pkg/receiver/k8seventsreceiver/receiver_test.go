@@ -0,0 +1,209 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8seventsreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestEvent(name string, resourceVersion string, count int32, timestamp time.Time) *eventsv1.Event {
+	return &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			UID:             types.UID(name),
+			ResourceVersion: resourceVersion,
+		},
+		EventTime: metav1.NewMicroTime(timestamp),
+		Series:    &eventsv1.EventSeries{Count: count, LastObservedTime: metav1.NewMicroTime(timestamp)},
+		Reason:    "BackOff",
+		Note:      name,
+		Type:      corev1.EventTypeWarning,
+		Regarding: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "app"},
+	}
+}
+
+func newTestReceiver(cfg *Config, client *fake.Clientset) (*k8sEventsReceiver, *consumertest.LogsSink) {
+	sink := &consumertest.LogsSink{}
+	makeClient := func(k8sconfig.APIConfig) (k8s.Interface, error) {
+		return client, nil
+	}
+	return newK8sEventsReceiver(zap.NewNop(), cfg, sink, makeClient), sink
+}
+
+func waitForWatch(t *testing.T, client *fake.Clientset) {
+	assert.Eventually(t, func() bool {
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "watch" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 5*time.Millisecond)
+}
+
+func receivedNotes(sink *consumertest.LogsSink) []string {
+	var notes []string
+	for _, logs := range sink.AllLogs() {
+		rls := logs.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			notes = append(notes, rls.At(i).InstrumentationLibraryLogs().At(0).Logs().At(0).Body().StringVal())
+		}
+	}
+	return notes
+}
+
+func TestReceiveEvents(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newTestEvent("old", "1", 1, time.Now().Add(-time.Hour)),
+	)
+	cfg := createDefaultConfig().(*Config)
+	cfg.DedupWindow = 0
+	r, sink := newTestReceiver(cfg, client)
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, r.Shutdown(context.Background()))
+	}()
+	waitForWatch(t, client)
+
+	events := client.EventsV1().Events("default")
+	_, err := events.Create(context.Background(), newTestEvent("crash", "2", 1, time.Now()), metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = events.Update(context.Background(), newTestEvent("crash", "3", 2, time.Now()), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	// An update without a new occurrence
+	_, err = events.Update(context.Background(), newTestEvent("crash", "4", 2, time.Now()), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	// A repeated occurrence of the old event
+	_, err = events.Update(context.Background(), newTestEvent("old", "5", 2, time.Now()), metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 3
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"crash", "crash", "old"}, receivedNotes(sink))
+}
+
+func TestTrackDeduplication(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	w := &namespaceWatcher{
+		receiver: &k8sEventsReceiver{config: cfg},
+		seen:     map[types.UID]*seenEvent{},
+	}
+	now := time.Now()
+
+	assert.True(t, w.track(newTestEvent("crash", "1", 1, now)))
+	assert.False(t, w.track(newTestEvent("crash", "1", 1, now)), "the same resource version is received again")
+	assert.False(t, w.track(newTestEvent("crash", "2", 2, now)), "the repeated occurrence is in the dedup window")
+	assert.False(t, w.track(newTestEvent("crash", "3", 2, now)), "there is no new occurrence")
+
+	w.seen["crash"].emitted = now.Add(-2 * cfg.DedupWindow)
+	assert.True(t, w.track(newTestEvent("crash", "4", 3, now)))
+	assert.True(t, w.track(newTestEvent("other", "5", 1, now)))
+}
+
+type testStorageExtension struct {
+	component.Extension
+	client *testStorageClient
+}
+
+func (e *testStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return e.client, nil
+}
+
+type testStorageClient struct {
+	storage.Client
+	data map[string][]byte
+}
+
+func (c *testStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	return c.data[key], nil
+}
+
+func (c *testStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.data[key] = value
+	return nil
+}
+
+func (c *testStorageClient) Close(context.Context) error {
+	return nil
+}
+
+type testHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func TestResumeFromResourceVersion(t *testing.T) {
+	storageClient := &testStorageClient{data: map[string][]byte{"resource_version.default": []byte("100")}}
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewID("file_storage"): &testStorageExtension{client: storageClient},
+		},
+	}
+
+	client := fake.NewSimpleClientset()
+	cfg := createDefaultConfig().(*Config)
+	cfg.Namespaces = []string{"default"}
+	cfg.Storage = "file_storage"
+	r, sink := newTestReceiver(cfg, client)
+
+	require.NoError(t, r.Start(context.Background(), host))
+	waitForWatch(t, client)
+
+	_, err := client.EventsV1().Events("default").
+		Create(context.Background(), newTestEvent("crash", "101", 1, time.Now()), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "list", action.GetVerb(), "the events are not listed when resuming")
+	}
+	assert.Equal(t, "101", string(storageClient.data["resource_version.default"]))
+}
+
+func TestMissingStorageExtension(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = "file_storage"
+	r, _ := newTestReceiver(cfg, fake.NewSimpleClientset())
+	assert.Error(t, r.Start(context.Background(), componenttest.NewNopHost()))
+}
@@ -0,0 +1,56 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumohttpreceiver
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Config defines configuration for the Sumo Logic HTTP source compatible receiver.
+type Config struct {
+	config.ReceiverSettings       `mapstructure:"-"`
+	confighttp.HTTPServerSettings `mapstructure:",squash"`
+
+	// Tokens are the accepted tokens of the source URLs, i.e. /receiver/v1/http/<token>.
+	// When empty, the data sent to any path is accepted.
+	Tokens []string `mapstructure:"tokens"`
+
+	// MaxRequestBodySize is the maximum size of the request body, after the decompression.
+	MaxRequestBodySize int64 `mapstructure:"max_request_body_size"`
+}
+
+const (
+	defaultEndpoint           = "0.0.0.0:8080"
+	defaultMaxRequestBodySize = 10 * 1024 * 1024
+)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return fmt.Errorf("endpoint must not be empty")
+	}
+	if cfg.MaxRequestBodySize <= 0 {
+		return fmt.Errorf("max_request_body_size must be positive, got %d", cfg.MaxRequestBodySize)
+	}
+	for _, token := range cfg.Tokens {
+		if token == "" {
+			return fmt.Errorf("tokens must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumohttpreceiver
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.uber.org/zap"
+)
+
+const (
+	// The value of "type" Sumo HTTP receiver in configuration.
+	typeStr = "sumo_http"
+)
+
+// NewFactory creates a factory for the Sumo Logic HTTP source compatible receiver.
+func NewFactory() component.ReceiverFactory {
+	return receiverhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		receiverhelper.WithLogs(createLogsReceiver),
+		receiverhelper.WithMetrics(createMetricsReceiver),
+	)
+}
+
+func createDefaultConfig() config.Receiver {
+	return &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewID(typeStr)),
+		HTTPServerSettings: confighttp.HTTPServerSettings{
+			Endpoint: defaultEndpoint,
+		},
+		MaxRequestBodySize: defaultMaxRequestBodySize,
+	}
+}
+
+// receivers holds the receivers by their config, so that the logs and metrics pipelines
+// using the same receiver share its HTTP server
+var receivers = struct {
+	sync.Mutex
+	byConfig map[*Config]*sumoHTTPReceiver
+}{byConfig: map[*Config]*sumoHTTPReceiver{}}
+
+func getReceiver(logger *zap.Logger, cfg *Config) (*sumoHTTPReceiver, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	receivers.Lock()
+	defer receivers.Unlock()
+	r, ok := receivers.byConfig[cfg]
+	if !ok {
+		r = newSumoHTTPReceiver(logger, cfg, func() {
+			receivers.Lock()
+			defer receivers.Unlock()
+			delete(receivers.byConfig, cfg)
+		})
+		receivers.byConfig[cfg] = r
+	}
+	return r, nil
+}
+
+// createLogsReceiver creates a logs receiver based on provided config.
+func createLogsReceiver(
+	_ context.Context,
+	params component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Logs,
+) (component.LogsReceiver, error) {
+	r, err := getReceiver(params.Logger, cfg.(*Config))
+	if err != nil {
+		return nil, err
+	}
+	r.logsConsumer = nextConsumer
+	return r, nil
+}
+
+// createMetricsReceiver creates a metrics receiver based on provided config.
+func createMetricsReceiver(
+	_ context.Context,
+	params component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Metrics,
+) (component.MetricsReceiver, error) {
+	r, err := getReceiver(params.Logger, cfg.(*Config))
+	if err != nil {
+		return nil, err
+	}
+	r.metricsConsumer = nextConsumer
+	return r, nil
+}
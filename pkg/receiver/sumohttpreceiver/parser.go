@@ -0,0 +1,327 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumohttpreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	sourceHostKey     = "_sourceHost"
+	sourceNameKey     = "_sourceName"
+	sourceCategoryKey = "_sourceCategory"
+
+	// carbon2MetricKey is the intrinsic tag holding the metric name in carbon2
+	carbon2MetricKey = "metric"
+)
+
+type attribute struct {
+	key   string
+	value string
+}
+
+// metadata holds the attributes taken from the X-Sumo-* headers
+type metadata struct {
+	// resource are set as the resource attributes of the logs and metrics
+	resource []attribute
+	// dimensions are set as the attributes of the data points
+	dimensions []attribute
+}
+
+type metric struct {
+	name       string
+	value      float64
+	timestamp  time.Time
+	attributes []attribute
+}
+
+func newMetadata(header http.Header) metadata {
+	md := metadata{}
+	for _, h := range []struct {
+		header string
+		key    string
+	}{
+		{header: headerHost, key: sourceHostKey},
+		{header: headerName, key: sourceNameKey},
+		{header: headerCategory, key: sourceCategoryKey},
+	} {
+		if value := strings.TrimSpace(header.Get(h.header)); value != "" {
+			md.resource = append(md.resource, attribute{key: h.key, value: value})
+		}
+	}
+	md.resource = append(md.resource, parseKeyValues(header.Get(headerFields), ",")...)
+	md.dimensions = append(md.dimensions, parseKeyValues(header.Get(headerDimensions), ",")...)
+	md.dimensions = append(md.dimensions, parseKeyValues(header.Get(headerMetadata), ",")...)
+	return md
+}
+
+// parseKeyValues parses the key=value pairs separated with sep, skipping the invalid ones
+func parseKeyValues(s string, sep string) []attribute {
+	var attributes []attribute
+	for _, pair := range strings.Split(s, sep) {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(kv[0])
+		if key == "" {
+			continue
+		}
+		attributes = append(attributes, attribute{key: key, value: strings.TrimSpace(kv[1])})
+	}
+	return attributes
+}
+
+func setAttributes(attributes pdata.AttributeMap, values []attribute) {
+	for _, attr := range values {
+		attributes.UpsertString(attr.key, attr.value)
+	}
+}
+
+// lines returns the non-empty lines of the body
+func lines(body []byte) []string {
+	var result []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// parseLogs creates a log record for each non-empty line of the body
+func parseLogs(body []byte, md metadata) pdata.Logs {
+	logs := pdata.NewLogs()
+	lines := lines(body)
+	if len(lines) == 0 {
+		return logs
+	}
+
+	rl := logs.ResourceLogs().AppendEmpty()
+	setAttributes(rl.Resource().Attributes(), md.resource)
+	records := rl.InstrumentationLibraryLogs().AppendEmpty().Logs()
+	records.EnsureCapacity(len(lines))
+
+	now := pdata.TimestampFromTime(time.Now())
+	for _, line := range lines {
+		record := records.AppendEmpty()
+		record.SetTimestamp(now)
+		record.Body().SetStringVal(line)
+	}
+	return logs
+}
+
+// parseMetrics creates a gauge for each non-empty line of the body, parsed using parse
+func parseMetrics(body []byte, md metadata, parse func(string, metadata) (metric, error)) (pdata.Metrics, error) {
+	metrics := pdata.NewMetrics()
+	var parsed []metric
+	for _, line := range lines(body) {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		m, err := parse(line, md)
+		if err != nil {
+			return metrics, err
+		}
+		parsed = append(parsed, m)
+	}
+	if len(parsed) == 0 {
+		return metrics, nil
+	}
+
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	setAttributes(rm.Resource().Attributes(), md.resource)
+	ms := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	ms.EnsureCapacity(len(parsed))
+
+	for _, m := range parsed {
+		metric := ms.AppendEmpty()
+		metric.SetName(m.name)
+		metric.SetDataType(pdata.MetricDataTypeGauge)
+		dp := metric.Gauge().DataPoints().AppendEmpty()
+		dp.SetDoubleVal(m.value)
+		dp.SetTimestamp(pdata.TimestampFromTime(m.timestamp))
+		setAttributes(dp.Attributes(), md.dimensions)
+		setAttributes(dp.Attributes(), m.attributes)
+	}
+	return metrics, nil
+}
+
+// parsePrometheusLine parses the line in the Prometheus text format, i.e. name{key="value"} value [timestamp_ms]
+func parsePrometheusLine(line string, _ metadata) (metric, error) {
+	line = strings.TrimSpace(line)
+	m := metric{}
+
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd <= 0 {
+		return m, fmt.Errorf("invalid prometheus metric %q", line)
+	}
+	m.name = line[:nameEnd]
+	rest := line[nameEnd:]
+
+	if strings.HasPrefix(rest, "{") {
+		attributes, remaining, err := parsePrometheusLabels(rest[1:])
+		if err != nil {
+			return m, fmt.Errorf("invalid prometheus metric %q: %w", line, err)
+		}
+		m.attributes = attributes
+		rest = remaining
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 {
+		return m, fmt.Errorf("invalid prometheus metric %q", line)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return m, fmt.Errorf("invalid value of prometheus metric %q: %w", line, err)
+	}
+	m.value = value
+
+	m.timestamp = time.Now()
+	if len(fields) == 2 {
+		ms, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return m, fmt.Errorf("invalid timestamp of prometheus metric %q: %w", line, err)
+		}
+		m.timestamp = time.Unix(0, ms*int64(time.Millisecond))
+	}
+	return m, nil
+}
+
+// parsePrometheusLabels parses the labels following the opening brace and returns the rest of the line
+func parsePrometheusLabels(s string) ([]attribute, string, error) {
+	var attributes []attribute
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if strings.HasPrefix(s, "}") {
+			return attributes, s[1:], nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, "", fmt.Errorf("invalid labels")
+		}
+		key := strings.TrimSpace(s[:eq])
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		if !strings.HasPrefix(s, `"`) {
+			return nil, "", fmt.Errorf("label %s value is not quoted", key)
+		}
+
+		var value strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i == len(s) {
+			return nil, "", fmt.Errorf("label %s value is not terminated", key)
+		}
+		attributes = append(attributes, attribute{key: key, value: value.String()})
+		s = s[i+1:]
+	}
+}
+
+// parseCarbon2Line parses the line in the Carbon 2.0 format, i.e. intrinsic_tags  meta_tags value timestamp,
+// where the intrinsic tags are separated from the meta tags with two spaces
+func parseCarbon2Line(line string, _ metadata) (metric, error) {
+	line = strings.TrimSpace(line)
+	m := metric{}
+
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return m, fmt.Errorf("invalid carbon2 metric %q", line)
+	}
+	value, timestamp, err := parseValueAndTimestamp(fields[len(fields)-2], fields[len(fields)-1])
+	if err != nil {
+		return m, fmt.Errorf("invalid carbon2 metric %q: %w", line, err)
+	}
+	m.value = value
+	m.timestamp = timestamp
+
+	// the tags precede the value and the timestamp, which are the last two fields
+	tags := line
+	for i := 0; i < 2; i++ {
+		tags = strings.TrimRight(tags[:strings.LastIndexAny(tags, " \t")], " \t")
+	}
+	intrinsic, meta := tags, ""
+	if i := strings.Index(tags, "  "); i >= 0 {
+		intrinsic, meta = tags[:i], tags[i+2:]
+	}
+
+	for _, attr := range parseKeyValues(intrinsic, " ") {
+		if attr.key == carbon2MetricKey {
+			m.name = attr.value
+			continue
+		}
+		m.attributes = append(m.attributes, attr)
+	}
+	m.attributes = append(m.attributes, parseKeyValues(meta, " ")...)
+	if m.name == "" {
+		return m, fmt.Errorf("carbon2 metric %q has no %s tag", line, carbon2MetricKey)
+	}
+	return m, nil
+}
+
+// parseGraphiteLine parses the line in the Graphite plaintext format, i.e. path value timestamp
+func parseGraphiteLine(line string, _ metadata) (metric, error) {
+	m := metric{}
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return m, fmt.Errorf("invalid graphite metric %q", line)
+	}
+	value, timestamp, err := parseValueAndTimestamp(fields[1], fields[2])
+	if err != nil {
+		return m, fmt.Errorf("invalid graphite metric %q: %w", line, err)
+	}
+	m.name = fields[0]
+	m.value = value
+	m.timestamp = timestamp
+	return m, nil
+}
+
+// parseValueAndTimestamp parses the value and the timestamp in seconds
+func parseValueAndTimestamp(value string, timestamp string) (float64, time.Time, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid value: %w", err)
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	return v, time.Unix(ts, 0), nil
+}
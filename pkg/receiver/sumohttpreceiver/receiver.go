@@ -0,0 +1,256 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumohttpreceiver
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.uber.org/zap"
+)
+
+const (
+	// sourcePathPrefix is the path of the Sumo Logic HTTP sources, followed by the token
+	sourcePathPrefix = "/receiver/v1/http/"
+
+	headerContentType     = "Content-Type"
+	headerContentEncoding = "Content-Encoding"
+	headerHost            = "X-Sumo-Host"
+	headerName            = "X-Sumo-Name"
+	headerCategory        = "X-Sumo-Category"
+	headerFields          = "X-Sumo-Fields"
+	headerDimensions      = "X-Sumo-Dimensions"
+	headerMetadata        = "X-Sumo-Metadata"
+
+	contentTypePrometheus = "application/vnd.sumologic.prometheus"
+	contentTypeCarbon2    = "application/vnd.sumologic.carbon2"
+	contentTypeGraphite   = "application/vnd.sumologic.graphite"
+
+	contentEncodingGzip    = "gzip"
+	contentEncodingDeflate = "deflate"
+)
+
+var (
+	errBodyTooLarge        = errors.New("request body too large")
+	errUnsupportedEncoding = errors.New("unsupported content encoding")
+)
+
+type sumoHTTPReceiver struct {
+	config          *Config
+	logger          *zap.Logger
+	logsConsumer    consumer.Logs
+	metricsConsumer consumer.Metrics
+	tokens          map[string]bool
+
+	server     *http.Server
+	startOnce  sync.Once
+	stopOnce   sync.Once
+	onShutdown func()
+	wg         sync.WaitGroup
+}
+
+// Ensure this receiver adheres to required interfaces.
+var (
+	_ component.LogsReceiver    = (*sumoHTTPReceiver)(nil)
+	_ component.MetricsReceiver = (*sumoHTTPReceiver)(nil)
+)
+
+func newSumoHTTPReceiver(logger *zap.Logger, cfg *Config, onShutdown func()) *sumoHTTPReceiver {
+	r := &sumoHTTPReceiver{
+		config:     cfg,
+		logger:     logger,
+		onShutdown: onShutdown,
+	}
+	if len(cfg.Tokens) > 0 {
+		r.tokens = make(map[string]bool, len(cfg.Tokens))
+		for _, token := range cfg.Tokens {
+			r.tokens[token] = true
+		}
+	}
+	return r
+}
+
+// Start starts the HTTP server, which is shared by the logs and metrics pipelines
+func (r *sumoHTTPReceiver) Start(_ context.Context, host component.Host) error {
+	err := error(nil)
+	r.startOnce.Do(func() {
+		listener, lErr := r.config.ToListener()
+		if lErr != nil {
+			err = fmt.Errorf("failed to bind to address %s: %w", r.config.Endpoint, lErr)
+			return
+		}
+		r.server = r.config.ToServer(r)
+
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			if sErr := r.server.Serve(listener); sErr != nil && sErr != http.ErrServerClosed {
+				host.ReportFatalError(sErr)
+			}
+		}()
+	})
+	return err
+}
+
+// Shutdown stops the HTTP server
+func (r *sumoHTTPReceiver) Shutdown(ctx context.Context) error {
+	err := error(nil)
+	r.stopOnce.Do(func() {
+		if r.server != nil {
+			err = r.server.Shutdown(ctx)
+			r.wg.Wait()
+		}
+		r.onShutdown()
+	})
+	return err
+}
+
+// ServeHTTP handles the data sent the way it is sent to the Sumo Logic HTTP sources
+func (r *sumoHTTPReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.tokens != nil {
+		token := strings.TrimPrefix(req.URL.Path, sourcePathPrefix)
+		if !strings.HasPrefix(req.URL.Path, sourcePathPrefix) || !r.tokens[token] {
+			http.Error(w, "unknown source", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	body, err := r.readBody(req)
+	switch {
+	case errors.Is(err, errBodyTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, errUnsupportedEncoding):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	md := newMetadata(req.Header)
+	contentType, _, _ := mime.ParseMediaType(req.Header.Get(headerContentType))
+	switch contentType {
+	case contentTypePrometheus, contentTypeCarbon2, contentTypeGraphite:
+		r.handleMetrics(w, req.Context(), contentType, body, md)
+	default:
+		r.handleLogs(w, req.Context(), body, md)
+	}
+}
+
+func (r *sumoHTTPReceiver) handleLogs(w http.ResponseWriter, ctx context.Context, body []byte, md metadata) {
+	if r.logsConsumer == nil {
+		http.Error(w, "logs are not accepted by this receiver", http.StatusBadRequest)
+		return
+	}
+	logs := parseLogs(body, md)
+	if logs.LogRecordCount() == 0 {
+		return
+	}
+	if err := r.logsConsumer.ConsumeLogs(ctx, logs); err != nil {
+		r.logger.Debug("Failed to consume the logs", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	}
+}
+
+func (r *sumoHTTPReceiver) handleMetrics(w http.ResponseWriter, ctx context.Context, contentType string, body []byte, md metadata) {
+	if r.metricsConsumer == nil {
+		http.Error(w, "metrics are not accepted by this receiver", http.StatusBadRequest)
+		return
+	}
+
+	var parse func(string, metadata) (metric, error)
+	switch contentType {
+	case contentTypePrometheus:
+		parse = parsePrometheusLine
+	case contentTypeCarbon2:
+		parse = parseCarbon2Line
+	default:
+		parse = parseGraphiteLine
+	}
+	metrics, err := parseMetrics(body, md, parse)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if metrics.DataPointCount() == 0 {
+		return
+	}
+	if err := r.metricsConsumer.ConsumeMetrics(ctx, metrics); err != nil {
+		r.logger.Debug("Failed to consume the metrics", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	}
+}
+
+// readBody reads the decompressed body, up to the maximum size
+func (r *sumoHTTPReceiver) readBody(req *http.Request) ([]byte, error) {
+	var reader io.Reader
+	switch encoding := req.Header.Get(headerContentEncoding); encoding {
+	case "", "identity":
+		reader = req.Body
+	case contentEncodingGzip:
+		gzipReader, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case contentEncodingDeflate:
+		deflateReader, err := newDeflateReader(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deflate body: %w", err)
+		}
+		defer deflateReader.Close()
+		reader = deflateReader
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedEncoding, encoding)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(reader, r.config.MaxRequestBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the body: %w", err)
+	}
+	if int64(len(body)) > r.config.MaxRequestBodySize {
+		return nil, errBodyTooLarge
+	}
+	return body, nil
+}
+
+// newDeflateReader reads both the zlib streams and the raw deflate ones, which are sent by some clients
+func newDeflateReader(body io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(body)
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
@@ -0,0 +1,293 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumohttpreceiver
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/testutil"
+	"go.uber.org/zap"
+)
+
+type testReceiver struct {
+	*sumoHTTPReceiver
+	logs    *consumertest.LogsSink
+	metrics *consumertest.MetricsSink
+}
+
+func newTestReceiver(t *testing.T, cfg *Config) testReceiver {
+	if cfg == nil {
+		cfg = createDefaultConfig().(*Config)
+	}
+	r := testReceiver{
+		sumoHTTPReceiver: newSumoHTTPReceiver(zap.NewNop(), cfg, func() {}),
+		logs:             new(consumertest.LogsSink),
+		metrics:          new(consumertest.MetricsSink),
+	}
+	r.logsConsumer = r.logs
+	r.metricsConsumer = r.metrics
+	return r
+}
+
+func (r testReceiver) post(path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLogs(t *testing.T) {
+	r := newTestReceiver(t, nil)
+
+	w := r.post("/receiver/v1/http/any", "first line\n\nsecond line\r\n", map[string]string{
+		headerHost:     "host-1",
+		headerName:     "app.log",
+		headerCategory: "prod/app",
+		headerFields:   "cluster=eu, team = payments,invalid",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Len(t, r.logs.AllLogs(), 1)
+	logs := r.logs.AllLogs()[0]
+	require.Equal(t, 2, logs.LogRecordCount())
+
+	rl := logs.ResourceLogs().At(0)
+	assert.Equal(t, map[string]interface{}{
+		"_sourceHost":     "host-1",
+		"_sourceName":     "app.log",
+		"_sourceCategory": "prod/app",
+		"cluster":         "eu",
+		"team":            "payments",
+	}, attributesToMap(rl.Resource().Attributes()))
+
+	records := rl.InstrumentationLibraryLogs().At(0).Logs()
+	assert.Equal(t, "first line", records.At(0).Body().StringVal())
+	assert.Equal(t, "second line", records.At(1).Body().StringVal())
+	assert.NotZero(t, records.At(0).Timestamp())
+}
+
+func TestCompressedLogs(t *testing.T) {
+	compress := map[string]func(io.Writer) io.WriteCloser{
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser {
+			return zlib.NewWriter(w)
+		},
+		"raw deflate": func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		},
+	}
+
+	for name, newWriter := range compress {
+		t.Run(name, func(t *testing.T) {
+			r := newTestReceiver(t, nil)
+
+			var buf bytes.Buffer
+			cw := newWriter(&buf)
+			_, err := cw.Write([]byte("compressed line"))
+			require.NoError(t, err)
+			require.NoError(t, cw.Close())
+
+			encoding := strings.TrimPrefix(name, "raw ")
+			w := r.post("/", buf.String(), map[string]string{headerContentEncoding: encoding})
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			require.Len(t, r.logs.AllLogs(), 1)
+			body := r.logs.AllLogs()[0].ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Body()
+			assert.Equal(t, "compressed line", body.StringVal())
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	testcases := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    []metric
+	}{
+		{
+			name:        "prometheus",
+			contentType: contentTypePrometheus,
+			body: "# HELP cpu_usage CPU usage\n" +
+				`cpu_usage{core="0",mode="user \"x\""} 0.5 1600000000000` + "\n" +
+				"up 1 1600000000000\n",
+			expected: []metric{
+				{
+					name:       "cpu_usage",
+					value:      0.5,
+					timestamp:  time.Unix(1600000000, 0),
+					attributes: []attribute{{key: "core", value: "0"}, {key: "mode", value: `user "x"`}},
+				},
+				{name: "up", value: 1, timestamp: time.Unix(1600000000, 0)},
+			},
+		},
+		{
+			name:        "carbon2",
+			contentType: contentTypeCarbon2,
+			body:        "metric=cpu_usage core=0  host=web-1 0.5 1600000000\n",
+			expected: []metric{
+				{
+					name:       "cpu_usage",
+					value:      0.5,
+					timestamp:  time.Unix(1600000000, 0),
+					attributes: []attribute{{key: "core", value: "0"}, {key: "host", value: "web-1"}},
+				},
+			},
+		},
+		{
+			name:        "graphite",
+			contentType: contentTypeGraphite,
+			body:        "web-1.cpu.usage 0.5 1600000000\n",
+			expected: []metric{
+				{name: "web-1.cpu.usage", value: 0.5, timestamp: time.Unix(1600000000, 0)},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newTestReceiver(t, nil)
+			w := r.post("/", tc.body, map[string]string{
+				headerContentType: tc.contentType,
+				headerCategory:    "prod/metrics",
+				headerDimensions:  "env=prod",
+			})
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			require.Len(t, r.metrics.AllMetrics(), 1)
+
+			rm := r.metrics.AllMetrics()[0].ResourceMetrics().At(0)
+			assert.Equal(t, map[string]interface{}{"_sourceCategory": "prod/metrics"},
+				attributesToMap(rm.Resource().Attributes()))
+
+			ms := rm.InstrumentationLibraryMetrics().At(0).Metrics()
+			require.Equal(t, len(tc.expected), ms.Len())
+			for i, expected := range tc.expected {
+				m := ms.At(i)
+				assert.Equal(t, expected.name, m.Name())
+				require.Equal(t, pdata.MetricDataTypeGauge, m.DataType())
+				dp := m.Gauge().DataPoints().At(0)
+				assert.Equal(t, expected.value, dp.DoubleVal())
+				assert.Equal(t, pdata.TimestampFromTime(expected.timestamp), dp.Timestamp())
+
+				attributes := map[string]interface{}{"env": "prod"}
+				for _, attr := range expected.attributes {
+					attributes[attr.key] = attr.value
+				}
+				assert.Equal(t, attributes, attributesToMap(dp.Attributes()))
+			}
+		})
+	}
+}
+
+func TestInvalidMetrics(t *testing.T) {
+	for contentType, body := range map[string]string{
+		contentTypePrometheus: `cpu_usage{core="0} 0.5`,
+		contentTypeCarbon2:    "core=0  host=web-1 0.5 1600000000",
+		contentTypeGraphite:   "web-1.cpu.usage 0.5",
+	} {
+		r := newTestReceiver(t, nil)
+		w := r.post("/", body, map[string]string{headerContentType: contentType})
+		assert.Equal(t, http.StatusBadRequest, w.Code, contentType)
+		assert.Empty(t, r.metrics.AllMetrics())
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Tokens = []string{"secret"}
+	cfg.MaxRequestBodySize = 10
+	r := newTestReceiver(t, cfg)
+
+	w := r.post("/receiver/v1/http/secret", "line", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = r.post("/receiver/v1/http/other", "line", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = r.post("/secret", "line", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = r.post("/receiver/v1/http/secret", "line longer than the limit", nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = r.post("/receiver/v1/http/secret", "line", map[string]string{headerContentEncoding: "br"})
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/receiver/v1/http/secret", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	assert.Len(t, r.logs.AllLogs(), 1)
+}
+
+func TestConsumerErrors(t *testing.T) {
+	r := newTestReceiver(t, nil)
+	r.logsConsumer = consumertest.NewErr(consumererror.Permanent(errors.New("failed")))
+	w := r.post("/", "line", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	r.metricsConsumer = nil
+	w = r.post("/", "up 1", map[string]string{headerContentType: contentTypePrometheus})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStartShutdown(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = testutil.GetAvailableLocalAddress(t)
+	r := newTestReceiver(t, cfg)
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	// the second pipeline starting the shared receiver is a no-op
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	resp, err := http.Post("http://"+cfg.Endpoint+"/receiver/v1/http/token", "text/plain", strings.NewReader("line"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, r.logs.AllLogs(), 1)
+
+	require.NoError(t, r.Shutdown(context.Background()))
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]interface{} {
+	result := map[string]interface{}{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = v.StringVal()
+		return true
+	})
+	return result
+}
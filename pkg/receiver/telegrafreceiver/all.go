@@ -15,8 +15,8 @@
 package telegrafreceiver
 
 import (
-	// _ "github.com/influxdata/telegraf/plugins/aggregators/all"
+	_ "github.com/influxdata/telegraf/plugins/aggregators/all"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	// _ "github.com/influxdata/telegraf/plugins/outputs/all"
-	// _ "github.com/influxdata/telegraf/plugins/processors/all"
+	_ "github.com/influxdata/telegraf/plugins/processors/all"
 )
@@ -15,6 +15,9 @@
 package telegrafreceiver
 
 import (
+	"fmt"
+	"time"
+
 	"go.opentelemetry.io/collector/config"
 )
 
@@ -23,12 +26,49 @@ type Config struct {
 	*config.ReceiverSettings `mapstructure:"-"`
 
 	// AgentConfig is the yaml config used as telegraf configuration.
-	// Please note that only inputs should be configured as all metrics gathered
-	// by them will be passed through to otc pipeline for processing and export.
+	// Please note that outputs should not be configured as all metrics gathered
+	// by the inputs will be passed through to otc pipeline for processing and export.
+	// It can refer to the secrets from SecretStores, e.g. password = "@{store:key}".
 	AgentConfig string `mapstructure:"agent_config"`
 
 	// SeparateField controls whether the ingested metrics should have a field
 	// concatenated with metric name like e.g. metric=mem_available or maybe rather
 	// have it as a separate label like e.g. metric=mem field=available
 	SeparateField bool `mapstructure:"separate_field"`
+
+	// SecretStores are the stores the secrets referenced in AgentConfig are read from.
+	SecretStores []SecretStoreConfig `mapstructure:"secret_stores"`
+
+	// HealthMetrics controls the metrics reporting the gather duration and errors
+	// of each input plugin.
+	HealthMetrics HealthMetricsConfig `mapstructure:"health_metrics"`
+}
+
+// HealthMetricsConfig defines the reporting of the input plugins health metrics.
+type HealthMetricsConfig struct {
+	// Enabled turns on the health metrics.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the period the health metrics are reported with.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+const defaultHealthMetricsInterval = time.Minute
+
+// Validate checks if the receiver configuration is valid.
+func (cfg *Config) Validate() error {
+	ids := map[string]bool{}
+	for i := range cfg.SecretStores {
+		store := &cfg.SecretStores[i]
+		if err := store.validate(); err != nil {
+			return err
+		}
+		if ids[store.ID] {
+			return fmt.Errorf("duplicate secret store id %q", store.ID)
+		}
+		ids[store.ID] = true
+	}
+	if cfg.HealthMetrics.Enabled && cfg.HealthMetrics.Interval <= 0 {
+		return fmt.Errorf("health_metrics interval must be positive, got %s", cfg.HealthMetrics.Interval)
+	}
+	return nil
 }
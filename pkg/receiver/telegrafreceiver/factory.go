@@ -48,6 +48,9 @@ func createDefaultConfig() config.Receiver {
 	return &Config{
 		ReceiverSettings: &rs,
 		SeparateField:    false,
+		HealthMetrics: HealthMetricsConfig{
+			Interval: defaultHealthMetricsInterval,
+		},
 	}
 }
 
@@ -63,10 +66,20 @@ func createMetricsReceiver(
 		return nil, fmt.Errorf("failed reading telegraf agent config from otc config")
 	}
 
+	if err := tCfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	agentConfig, err := resolveSecrets(tCfg.AgentConfig, tCfg.SecretStores)
+	if err != nil {
+		return nil, err
+	}
+
 	tConfig := telegrafconfig.NewConfig()
-	if err := tConfig.LoadConfigData([]byte(tCfg.AgentConfig)); err != nil {
+	if err := tConfig.LoadConfigData([]byte(agentConfig)); err != nil {
 		return nil, fmt.Errorf("failed loading telegraf agent config: %w", err)
 	}
+	pipeline := newPluginPipeline(tConfig)
 	tAgent, err := telegrafagent.NewAgent(tConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed creating telegraf agent: %w", err)
@@ -74,8 +87,10 @@ func createMetricsReceiver(
 
 	return &telegrafreceiver{
 		agent:           tAgent,
+		pipeline:        pipeline,
 		consumer:        nextConsumer,
 		logger:          params.Logger,
 		metricConverter: newConverter(tCfg.SeparateField, params.Logger),
+		healthMetrics:   tCfg.HealthMetrics,
 	}, nil
 }
@@ -0,0 +1,132 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrafreceiver
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/selfstat"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+const (
+	healthMetricGatherDuration  = "telegraf_input_gather_duration_seconds"
+	healthMetricMetricsGathered = "telegraf_input_metrics_gathered"
+	healthMetricGatherErrors    = "telegraf_input_gather_errors"
+
+	healthAttributeInput = "input"
+	healthAttributeAlias = "alias"
+)
+
+// inputHealth holds the internal Telegraf stats of an input plugin
+type inputHealth struct {
+	name            string
+	alias           string
+	metricsGathered selfstat.Stat
+	gatherTime      selfstat.Stat
+	errors          selfstat.Stat
+}
+
+func newInputsHealth(inputs []*models.RunningInput) []inputHealth {
+	health := make([]inputHealth, 0, len(inputs))
+	for _, input := range inputs {
+		tags := map[string]string{healthAttributeInput: input.Config.Name}
+		if input.Config.Alias != "" {
+			tags[healthAttributeAlias] = input.Config.Alias
+		}
+		health = append(health, inputHealth{
+			name:            input.Config.Name,
+			alias:           input.Config.Alias,
+			metricsGathered: input.MetricsGathered,
+			gatherTime:      input.GatherTime,
+			// Registering the stat again returns the one the input plugin increments on errors
+			errors: selfstat.Register("gather", "errors", tags),
+		})
+	}
+	return health
+}
+
+// reportHealth sends the health metrics of the input plugins every interval until the context is done
+func (r *telegrafreceiver) reportHealth(ctx context.Context, inputs []inputHealth) {
+	start := pdata.TimestampFromTime(time.Now())
+	ticker := time.NewTicker(r.healthMetrics.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			md := healthMetrics(inputs, start, pdata.TimestampFromTime(time.Now()))
+			if err := r.consumer.ConsumeMetrics(ctx, md); err != nil {
+				r.logger.Error("ConsumeMetrics() error",
+					zap.String("error", err.Error()),
+				)
+			}
+		}
+	}
+}
+
+// healthMetrics returns the average gather duration in the last interval, as well as
+// the number of the gathered metrics and the errors since the start, of each input plugin
+func healthMetrics(inputs []inputHealth, start, now pdata.Timestamp) pdata.Metrics {
+	md := pdata.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+
+	duration := metrics.AppendEmpty()
+	duration.SetName(healthMetricGatherDuration)
+	duration.SetUnit("s")
+	duration.SetDataType(pdata.MetricDataTypeGauge)
+
+	gathered := newCumulativeSum(metrics.AppendEmpty(), healthMetricMetricsGathered)
+	errors := newCumulativeSum(metrics.AppendEmpty(), healthMetricGatherErrors)
+
+	for _, input := range inputs {
+		dp := duration.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetDoubleVal(time.Duration(input.gatherTime.Get()).Seconds())
+		input.insertAttributes(dp.Attributes())
+
+		for _, sum := range []struct {
+			metric pdata.Metric
+			stat   selfstat.Stat
+		}{{gathered, input.metricsGathered}, {errors, input.errors}} {
+			dp := sum.metric.Sum().DataPoints().AppendEmpty()
+			dp.SetStartTimestamp(start)
+			dp.SetTimestamp(now)
+			dp.SetIntVal(sum.stat.Get())
+			input.insertAttributes(dp.Attributes())
+		}
+	}
+	return md
+}
+
+func newCumulativeSum(metric pdata.Metric, name string) pdata.Metric {
+	metric.SetName(name)
+	metric.SetUnit("1")
+	metric.SetDataType(pdata.MetricDataTypeSum)
+	metric.Sum().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+	metric.Sum().SetIsMonotonic(true)
+	return metric
+}
+
+func (input *inputHealth) insertAttributes(attributes pdata.AttributeMap) {
+	attributes.InsertString(healthAttributeInput, input.name)
+	if input.alias != "" {
+		attributes.InsertString(healthAttributeAlias, input.alias)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrafreceiver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	telegrafagent "github.com/influxdata/telegraf/agent"
+	telegrafconfig "github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+// pluginPipeline runs the Telegraf processor and aggregator plugins on the metrics gathered
+// by the input plugins, chaining them the same way the Telegraf agent does:
+//
+//	inputs -> processors -> aggregators -> aggregator processors -> receiver
+//
+// The aggregators pass the original metrics on along with the aggregates, unless they are
+// configured with drop_original = true.
+type pluginPipeline struct {
+	processors    models.RunningProcessors
+	aggregators   []*models.RunningAggregator
+	aggProcessors models.RunningProcessors
+
+	interval      time.Duration
+	precision     time.Duration
+	roundInterval bool
+}
+
+// newPluginPipeline takes the processor and aggregator plugins out of the config, so that the
+// Telegraf agent only runs the input plugins
+func newPluginPipeline(cfg *telegrafconfig.Config) *pluginPipeline {
+	p := &pluginPipeline{
+		processors:    cfg.Processors,
+		aggregators:   cfg.Aggregators,
+		aggProcessors: cfg.AggProcessors,
+		interval:      time.Duration(cfg.Agent.Interval),
+		precision:     time.Duration(cfg.Agent.Precision),
+		roundInterval: cfg.Agent.RoundInterval,
+	}
+	cfg.Processors = nil
+	cfg.Aggregators = nil
+	cfg.AggProcessors = nil
+	return p
+}
+
+// start initializes and starts the plugins. The metrics written to the returned channel go through
+// the plugins and end up in out. The returned function runs the plugins until the returned channel
+// is closed and then closes out, once all the metrics have been written.
+func (p *pluginPipeline) start(out chan<- telegraf.Metric) (chan<- telegraf.Metric, func(), error) {
+	if err := p.init(); err != nil {
+		return nil, nil, err
+	}
+
+	var runs []func()
+	next := out
+	if len(p.aggregators) != 0 {
+		aggC := next
+		if len(p.aggProcessors) != 0 {
+			var run func()
+			var err error
+			if aggC, run, err = startProcessors(next, p.aggProcessors); err != nil {
+				return nil, nil, err
+			}
+			runs = append(runs, run)
+		}
+
+		var run func()
+		next, run = p.startAggregators(aggC, next)
+		runs = append(runs, run)
+	}
+
+	if len(p.processors) != 0 {
+		var run func()
+		var err error
+		if next, run, err = startProcessors(next, p.processors); err != nil {
+			return nil, nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return next, func() {
+		var wg sync.WaitGroup
+		for _, run := range runs {
+			wg.Add(1)
+			go func(run func()) {
+				defer wg.Done()
+				run()
+			}(run)
+		}
+		wg.Wait()
+	}, nil
+}
+
+func (p *pluginPipeline) init() error {
+	for _, processor := range p.processors {
+		if err := processor.Init(); err != nil {
+			return fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
+		}
+	}
+	for _, aggregator := range p.aggregators {
+		if err := aggregator.Init(); err != nil {
+			return fmt.Errorf("could not initialize aggregator %s: %w", aggregator.LogName(), err)
+		}
+	}
+	for _, processor := range p.aggProcessors {
+		if err := processor.Init(); err != nil {
+			return fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
+		}
+	}
+	return nil
+}
+
+// startProcessors starts the chain of the processors, in their order, and returns its source channel
+func startProcessors(
+	dst chan<- telegraf.Metric,
+	processors models.RunningProcessors,
+) (chan<- telegraf.Metric, func(), error) {
+	type processorUnit struct {
+		src       <-chan telegraf.Metric
+		dst       chan<- telegraf.Metric
+		processor *models.RunningProcessor
+	}
+	var units []*processorUnit
+
+	// The chain is built from the last processor to the first one
+	sort.SliceStable(processors, func(i, j int) bool {
+		return processors[i].Config.Order > processors[j].Config.Order
+	})
+
+	var src chan telegraf.Metric
+	for _, processor := range processors {
+		src = make(chan telegraf.Metric, 100)
+		if err := processor.Start(telegrafagent.NewAccumulator(processor, dst)); err != nil {
+			for _, u := range units {
+				u.processor.Stop()
+			}
+			return nil, nil, fmt.Errorf("starting processor %s: %w", processor.LogName(), err)
+		}
+		units = append(units, &processorUnit{src: src, dst: dst, processor: processor})
+		dst = src
+	}
+
+	return src, func() {
+		var wg sync.WaitGroup
+		for _, unit := range units {
+			wg.Add(1)
+			go func(unit *processorUnit) {
+				defer wg.Done()
+				acc := telegrafagent.NewAccumulator(unit.processor, unit.dst)
+				for m := range unit.src {
+					if err := unit.processor.Add(m, acc); err != nil {
+						acc.AddError(err)
+						m.Drop()
+					}
+				}
+				unit.processor.Stop()
+				close(unit.dst)
+			}(unit)
+		}
+		wg.Wait()
+	}, nil
+}
+
+// startAggregators returns the source channel of the aggregators, which write the aggregates to aggC
+// and pass the original metrics to outputC. aggC is closed once all of them have been written.
+func (p *pluginPipeline) startAggregators(
+	aggC chan<- telegraf.Metric,
+	outputC chan<- telegraf.Metric,
+) (chan<- telegraf.Metric, func()) {
+	src := make(chan telegraf.Metric, 100)
+	return src, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// The aggregation window is initialized before adding the metrics, so that all the metrics
+		// created after the start are aggregated
+		startTime := time.Now()
+		for _, aggregator := range p.aggregators {
+			since, until := updateWindow(startTime, p.roundInterval, aggregator.Period())
+			aggregator.UpdateWindow(since, until)
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range src {
+				var dropOriginal bool
+				for _, aggregator := range p.aggregators {
+					if ok := aggregator.Add(m); ok {
+						dropOriginal = true
+					}
+				}
+				if dropOriginal {
+					m.Drop()
+				} else {
+					outputC <- m
+				}
+			}
+			cancel()
+		}()
+
+		for _, aggregator := range p.aggregators {
+			wg.Add(1)
+			go func(aggregator *models.RunningAggregator) {
+				defer wg.Done()
+				acc := telegrafagent.NewAccumulator(aggregator, aggC)
+				acc.SetPrecision(getPrecision(p.precision, p.interval))
+				push(ctx, aggregator, acc)
+			}(aggregator)
+		}
+
+		wg.Wait()
+
+		// Without the aggregator processors, aggC is outputC. Otherwise, closing aggC closes outputC
+		// once the aggregator processors are done.
+		close(aggC)
+	}
+}
+
+// push pushes the aggregates at the end of every period and once more when the context is done
+func push(ctx context.Context, aggregator *models.RunningAggregator, acc telegraf.Accumulator) {
+	for {
+		select {
+		case <-time.After(time.Until(aggregator.EndPeriod())):
+			aggregator.Push(acc)
+		case <-ctx.Done():
+			aggregator.Push(acc)
+			return
+		}
+	}
+}
+
+func updateWindow(start time.Time, roundInterval bool, period time.Duration) (time.Time, time.Time) {
+	var until time.Time
+	if roundInterval {
+		until = alignTime(start, period)
+		if until == start {
+			until = alignTime(start.Add(time.Nanosecond), period)
+		}
+	} else {
+		until = start.Add(period)
+	}
+	return until.Add(-period), until
+}
+
+// alignTime returns the time of the next multiple of the interval
+func alignTime(tm time.Time, interval time.Duration) time.Time {
+	truncated := tm.Truncate(interval)
+	if truncated == tm {
+		return tm
+	}
+	return truncated.Add(interval)
+}
+
+func getPrecision(precision, interval time.Duration) time.Duration {
+	if precision > 0 {
+		return precision
+	}
+	switch {
+	case interval >= time.Second:
+		return time.Second
+	case interval >= time.Millisecond:
+		return time.Millisecond
+	case interval >= time.Microsecond:
+		return time.Microsecond
+	default:
+		return time.Nanosecond
+	}
+}
@@ -40,9 +40,11 @@ type telegrafreceiver struct {
 	cancel    context.CancelFunc
 
 	agent           *telegrafagent.Agent
+	pipeline        *pluginPipeline
 	consumer        consumer.Metrics
 	logger          *zap.Logger
 	metricConverter MetricConverter
+	healthMetrics   HealthMetricsConfig
 }
 
 // Ensure this receiver adheres to required interface.
@@ -62,47 +64,65 @@ func (r *telegrafreceiver) Start(ctx context.Context, host component.Host) error
 		r.cancel = cancel
 
 		ch := make(chan telegraf.Metric)
+		in, runPipeline, pErr := r.pipeline.start(ch)
+		if pErr != nil {
+			err = pErr
+			cancel()
+			return
+		}
+
 		go func() {
-			if rErr := r.agent.RunWithChannel(rctx, ch); rErr != nil {
+			if rErr := r.agent.RunWithChannel(rctx, in); rErr != nil {
 				r.logger.Error("Problem starting receiver", zap.Error(rErr))
+				// The input plugins did not start, so the channel needs to be closed
+				// for the processor and aggregator plugins to stop
+				close(in)
 			}
 		}()
 
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			runPipeline()
+		}()
+
+		if r.healthMetrics.Enabled {
+			inputs := newInputsHealth(r.agent.Config.Inputs)
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+				r.reportHealth(rctx, inputs)
+			}()
+		}
+
 		r.wg.Add(1)
 		go func() {
 			var fErr error
 			defer r.wg.Done()
-			for {
-				select {
-				case <-rctx.Done():
-					return
-
-				case m, ok := <-ch:
-					if !ok {
-						r.logger.Info("channel closed")
-						return
-					}
-					if m == nil {
-						r.logger.Info("got nil from channel")
-						break
-					}
-
-					var ms pdata.Metrics
-					if ms, fErr = r.metricConverter.Convert(m); fErr != nil {
-						r.logger.Error(
-							"Error converting telegraf.Metric to pdata.Metrics",
-							zap.Error(fErr),
-						)
-						continue
-					}
-
-					if fErr = r.consumer.ConsumeMetrics(rctx, ms); fErr != nil {
-						r.logger.Error("ConsumeMetrics() error",
-							zap.String("error", fErr.Error()),
-						)
-					}
+			// The metrics are received until the channel is closed, after the input plugins have
+			// stopped and the aggregator plugins have pushed their last aggregates
+			for m := range ch {
+				if m == nil {
+					r.logger.Info("got nil from channel")
+					continue
+				}
+
+				var ms pdata.Metrics
+				if ms, fErr = r.metricConverter.Convert(m); fErr != nil {
+					r.logger.Error(
+						"Error converting telegraf.Metric to pdata.Metrics",
+						zap.Error(fErr),
+					)
+					continue
+				}
+
+				if fErr = r.consumer.ConsumeMetrics(context.Background(), ms); fErr != nil {
+					r.logger.Error("ConsumeMetrics() error",
+						zap.String("error", fErr.Error()),
+					)
 				}
 			}
+			r.logger.Info("channel closed")
 		}()
 	})
 
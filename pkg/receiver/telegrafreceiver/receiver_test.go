@@ -0,0 +1,191 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrafreceiver
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+func metricNames(sink *consumertest.MetricsSink) []string {
+	var names []string
+	for _, md := range sink.AllMetrics() {
+		rms := md.ResourceMetrics()
+		for i := 0; i < rms.Len(); i++ {
+			ilms := rms.At(i).InstrumentationLibraryMetrics()
+			for j := 0; j < ilms.Len(); j++ {
+				metrics := ilms.At(j).Metrics()
+				for k := 0; k < metrics.Len(); k++ {
+					names = append(names, metrics.At(k).Name())
+				}
+			}
+		}
+	}
+	return names
+}
+
+func startTestReceiver(t *testing.T, agentConfig string) (component.MetricsReceiver, *consumertest.MetricsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AgentConfig = agentConfig
+	sink := &consumertest.MetricsSink{}
+
+	params := component.ReceiverCreateSettings{Logger: zap.NewNop()}
+	r, err := createMetricsReceiver(context.Background(), params, cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	return r, sink
+}
+
+func TestReceiverProcessorPlugins(t *testing.T) {
+	r, sink := startTestReceiver(t, `
+[agent]
+  interval = "50ms"
+  flush_interval = "50ms"
+[[inputs.mem]]
+[[processors.rename]]
+  [[processors.rename.replace]]
+    measurement = "mem"
+    dest = "memory"
+`)
+
+	assert.Eventually(t, func() bool {
+		return sink.DataPointCount() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	for _, name := range metricNames(sink) {
+		assert.True(t, strings.HasPrefix(name, "memory_"), name)
+	}
+}
+
+func TestReceiverAggregatorPlugins(t *testing.T) {
+	r, sink := startTestReceiver(t, `
+[agent]
+  interval = "50ms"
+  flush_interval = "50ms"
+[[inputs.mem]]
+  fieldpass = ["used"]
+[[aggregators.basicstats]]
+  period = "1h"
+  drop_original = true
+  stats = ["count", "max"]
+[[processors.rename]]
+  order = 1
+  [[processors.rename.replace]]
+    field = "used_count"
+    dest = "used_samples"
+[[processors.rename]]
+  order = 2
+  [[processors.rename.replace]]
+    field = "used_samples"
+    dest = "used_gathered"
+`)
+
+	time.Sleep(200 * time.Millisecond)
+	// The aggregates are pushed when the receiver shuts down
+	assert.Empty(t, sink.AllMetrics())
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	assert.ElementsMatch(t, []string{"mem_used_gathered", "mem_used_max"}, metricNames(sink))
+	for _, md := range sink.AllMetrics() {
+		metric := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
+		if metric.Name() == "mem_used_gathered" {
+			require.Equal(t, pdata.MetricDataTypeGauge, metric.DataType())
+			assert.Greater(t, metric.Gauge().DataPoints().At(0).IntVal(), int64(1))
+		}
+	}
+}
+
+func TestReceiverInvalidProcessorPlugin(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AgentConfig = `
+[[inputs.mem]]
+[[processors.unknown]]
+`
+	params := component.ReceiverCreateSettings{Logger: zap.NewNop()}
+	_, err := createMetricsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	assert.Error(t, err)
+}
+
+func TestReceiverHealthMetrics(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AgentConfig = `
+[agent]
+  interval = "50ms"
+[[inputs.mem]]
+  alias = "memory"
+`
+	cfg.HealthMetrics = HealthMetricsConfig{Enabled: true, Interval: 100 * time.Millisecond}
+	sink := &consumertest.MetricsSink{}
+
+	params := component.ReceiverCreateSettings{Logger: zap.NewNop()}
+	r, err := createMetricsReceiver(context.Background(), params, cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	assert.Eventually(t, func() bool {
+		for _, name := range metricNames(sink) {
+			if name == healthMetricGatherErrors {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	for _, md := range sink.AllMetrics() {
+		metrics := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+		if metrics.At(0).Name() != healthMetricGatherDuration {
+			continue
+		}
+		require.Equal(t, 3, metrics.Len())
+		dp := metrics.At(1).Sum().DataPoints().At(0)
+		input, _ := dp.Attributes().Get(healthAttributeInput)
+		alias, _ := dp.Attributes().Get(healthAttributeAlias)
+		assert.Equal(t, "mem", input.StringVal())
+		assert.Equal(t, "memory", alias.StringVal())
+		assert.Equal(t, healthMetricMetricsGathered, metrics.At(1).Name())
+	}
+}
+
+func TestReceiverSecrets(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AgentConfig = `
+[[inputs.mem]]
+  name_override = "@{env:NAME}"
+`
+	cfg.SecretStores = []SecretStoreConfig{{ID: "env", Type: secretStoreTypeEnv, Prefix: "TELEGRAF_TEST_SECRET_"}}
+
+	params := component.ReceiverCreateSettings{Logger: zap.NewNop()}
+	_, err := createMetricsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	assert.Error(t, err)
+
+	os.Setenv("TELEGRAF_TEST_SECRET_NAME", "memory")
+	defer os.Unsetenv("TELEGRAF_TEST_SECRET_NAME")
+	r, err := createMetricsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "memory", r.(*telegrafreceiver).agent.Config.Inputs[0].Config.NameOverride)
+}
@@ -0,0 +1,119 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrafreceiver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	secretStoreTypeEnv  = "env"
+	secretStoreTypeFile = "file"
+)
+
+var (
+	// secretReferenceRegex matches the Telegraf secret references, e.g. @{vault:db_password}
+	secretReferenceRegex = regexp.MustCompile(`@\{([\w-]+):([\w.-]+)\}`)
+	secretStoreIDRegex   = regexp.MustCompile(`^[\w-]+$`)
+
+	tomlEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+)
+
+// SecretStoreConfig defines a store the secrets referenced in the Telegraf config are read from.
+type SecretStoreConfig struct {
+	// ID is the name of the store used in the secret references, e.g. @{<id>:<key>}
+	ID string `mapstructure:"id"`
+
+	// Type is either "env", reading the secrets from the environment variables,
+	// or "file", reading them from the files in a directory.
+	Type string `mapstructure:"type"`
+
+	// Prefix is prepended to the key to get the name of the environment variable ("env" stores only).
+	Prefix string `mapstructure:"prefix"`
+
+	// Directory holds a file per secret, named after its key ("file" stores only).
+	Directory string `mapstructure:"directory"`
+}
+
+func (cfg *SecretStoreConfig) validate() error {
+	if !secretStoreIDRegex.MatchString(cfg.ID) {
+		return fmt.Errorf("invalid secret store id %q, only letters, digits, '_' and '-' are allowed", cfg.ID)
+	}
+	switch cfg.Type {
+	case secretStoreTypeEnv:
+	case secretStoreTypeFile:
+		if cfg.Directory == "" {
+			return fmt.Errorf("secret store %q: directory is required", cfg.ID)
+		}
+	default:
+		return fmt.Errorf("secret store %q: unknown type %q, expected %q or %q",
+			cfg.ID, cfg.Type, secretStoreTypeEnv, secretStoreTypeFile)
+	}
+	return nil
+}
+
+// get returns the secret with the given key
+func (cfg *SecretStoreConfig) get(key string) (string, error) {
+	switch cfg.Type {
+	case secretStoreTypeEnv:
+		value, ok := os.LookupEnv(cfg.Prefix + key)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", cfg.Prefix+key)
+		}
+		return value, nil
+	case secretStoreTypeFile:
+		// The key cannot contain a path separator, so the file is always in the directory
+		value, err := ioutil.ReadFile(filepath.Join(cfg.Directory, key))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(value), "\r\n"), nil
+	}
+	return "", fmt.Errorf("unknown type %q", cfg.Type)
+}
+
+// resolveSecrets replaces the secret references in the Telegraf config with the secrets read
+// from the stores. The secrets are escaped, so that they can be used in double quoted TOML strings.
+func resolveSecrets(agentConfig string, stores []SecretStoreConfig) (string, error) {
+	storesByID := make(map[string]*SecretStoreConfig, len(stores))
+	for i := range stores {
+		storesByID[stores[i].ID] = &stores[i]
+	}
+
+	var resolveErr error
+	resolved := secretReferenceRegex.ReplaceAllStringFunc(agentConfig, func(reference string) string {
+		if resolveErr != nil {
+			return reference
+		}
+		match := secretReferenceRegex.FindStringSubmatch(reference)
+		store, ok := storesByID[match[1]]
+		if !ok {
+			resolveErr = fmt.Errorf("secret %s refers to an unknown secret store", reference)
+			return reference
+		}
+		value, err := store.get(match[2])
+		if err != nil {
+			resolveErr = fmt.Errorf("failed reading secret %s: %w", reference, err)
+			return reference
+		}
+		return tomlEscaper.Replace(value)
+	})
+	return resolved, resolveErr
+}
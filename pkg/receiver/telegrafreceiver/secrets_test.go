@@ -0,0 +1,81 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telegrafreceiver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "db_password"), []byte("p\"a\\ss\n"), 0600))
+	os.Setenv("TELEGRAF_TEST_TOKEN", "s3cr3t")
+	defer os.Unsetenv("TELEGRAF_TEST_TOKEN")
+
+	stores := []SecretStoreConfig{
+		{ID: "env", Type: secretStoreTypeEnv, Prefix: "TELEGRAF_TEST_"},
+		{ID: "files", Type: secretStoreTypeFile, Directory: dir},
+	}
+
+	resolved, err := resolveSecrets(`
+[[inputs.postgresql]]
+  address = "user=telegraf password=@{files:db_password}"
+[[inputs.http]]
+  bearer_token_string = "@{env:TOKEN}"
+  urls = ["http://@{unknown-syntax}"]
+`, stores)
+	require.NoError(t, err)
+	assert.Equal(t, `
+[[inputs.postgresql]]
+  address = "user=telegraf password=p\"a\\ss"
+[[inputs.http]]
+  bearer_token_string = "s3cr3t"
+  urls = ["http://@{unknown-syntax}"]
+`, resolved)
+
+	_, err = resolveSecrets(`password = "@{vault:db_password}"`, stores)
+	assert.Error(t, err)
+
+	_, err = resolveSecrets(`password = "@{env:MISSING}"`, stores)
+	assert.Error(t, err)
+
+	_, err = resolveSecrets(`password = "@{files:missing}"`, stores)
+	assert.Error(t, err)
+}
+
+func TestValidateSecretStores(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.SecretStores = []SecretStoreConfig{
+		{ID: "env", Type: secretStoreTypeEnv},
+		{ID: "files", Type: secretStoreTypeFile, Directory: "/etc/secrets"},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	for _, stores := range [][]SecretStoreConfig{
+		{{ID: "env", Type: secretStoreTypeEnv}, {ID: "env", Type: secretStoreTypeEnv}},
+		{{ID: "my store", Type: secretStoreTypeEnv}},
+		{{ID: "files", Type: secretStoreTypeFile}},
+		{{ID: "vault", Type: "vault"}},
+	} {
+		cfg.SecretStores = stores
+		assert.Error(t, cfg.Validate())
+	}
+}
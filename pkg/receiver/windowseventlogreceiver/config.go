@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowseventlogreceiver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the Windows event log receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:"-"`
+
+	// Channels are the event log channels the events are read from, e.g. Application, System or Security
+	Channels []string `mapstructure:"channels"`
+
+	// IncludeProviders are the names of the providers whose events are received, all of them when empty
+	IncludeProviders []string `mapstructure:"include_providers"`
+	// ExcludeProviders are the names of the providers whose events are dropped
+	ExcludeProviders []string `mapstructure:"exclude_providers"`
+
+	// StartAt is where the reading of a channel starts when there is no bookmark: beginning or end
+	StartAt string `mapstructure:"start_at"`
+	// MaxReads is the maximum number of events read from a channel at once
+	MaxReads int `mapstructure:"max_reads"`
+	// PollInterval is the interval the channels are checked for the new events
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// Storage is the ID of the storage extension where the bookmarks of the last read events
+	// are persisted, so that the receiver resumes from them after a restart
+	Storage string `mapstructure:"storage"`
+}
+
+const (
+	startAtBeginning = "beginning"
+	startAtEnd       = "end"
+
+	defaultMaxReads     = 100
+	defaultPollInterval = time.Second
+)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Channels) == 0 {
+		return errors.New("at least one channel must be configured")
+	}
+	seen := map[string]bool{}
+	for _, channel := range cfg.Channels {
+		if channel == "" {
+			return errors.New("channel must not be empty")
+		}
+		if seen[channel] {
+			return fmt.Errorf("channel %q is configured more than once", channel)
+		}
+		seen[channel] = true
+	}
+	if cfg.StartAt != startAtBeginning && cfg.StartAt != startAtEnd {
+		return fmt.Errorf("start_at must be either %q or %q, got %q", startAtBeginning, startAtEnd, cfg.StartAt)
+	}
+	if cfg.MaxReads <= 0 {
+		return fmt.Errorf("max_reads must be positive, got %d", cfg.MaxReads)
+	}
+	if cfg.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive, got %s", cfg.PollInterval)
+	}
+	if cfg.Storage != "" {
+		if _, err := config.NewIDFromString(cfg.Storage); err != nil {
+			return fmt.Errorf("invalid storage extension id %q: %w", cfg.Storage, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowseventlogreceiver
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[factory.Type()] = factory
+
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "windows_event_log_config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Receivers[config.NewID(typeStr)], factory.CreateDefaultConfig())
+
+	id := config.NewIDWithName(typeStr, "custom")
+	assert.Equal(t, cfg.Receivers[id],
+		&Config{
+			ReceiverSettings: config.NewReceiverSettings(id),
+			Channels:         []string{"Application", "Security"},
+			IncludeProviders: []string{"Microsoft-Windows-Security-Auditing", "MyApp"},
+			ExcludeProviders: []string{"Microsoft-Windows-Security-SPP"},
+			StartAt:          "beginning",
+			MaxReads:         50,
+			PollInterval:     5 * time.Second,
+			Storage:          "file_storage",
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	newConfig := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Channels = []string{"Application"}
+		return cfg
+	}
+	assert.NoError(t, newConfig().Validate())
+
+	cfg := newConfig()
+	cfg.Channels = nil
+	assert.Error(t, cfg.Validate())
+
+	cfg = newConfig()
+	cfg.Channels = []string{"Application", ""}
+	assert.Error(t, cfg.Validate())
+
+	cfg = newConfig()
+	cfg.Channels = []string{"Application", "Application"}
+	assert.Error(t, cfg.Validate())
+
+	cfg = newConfig()
+	cfg.StartAt = "middle"
+	assert.Error(t, cfg.Validate())
+
+	cfg = newConfig()
+	cfg.MaxReads = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg = newConfig()
+	cfg.PollInterval = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg = newConfig()
+	cfg.Storage = "/"
+	assert.Error(t, cfg.Validate())
+}
@@ -0,0 +1,226 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowseventlogreceiver
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	attributePrefix          = "winlog."
+	eventDataAttributePrefix = attributePrefix + "event_data."
+	userDataAttributePrefix  = attributePrefix + "user_data."
+)
+
+// eventXML is the event rendered as XML, with the rendering info if the message has been formatted
+type eventXML struct {
+	System struct {
+		Provider struct {
+			Name            string `xml:"Name,attr"`
+			GUID            string `xml:"Guid,attr"`
+			EventSourceName string `xml:"EventSourceName,attr"`
+		} `xml:"Provider"`
+		EventID struct {
+			Qualifiers string `xml:"Qualifiers,attr"`
+			ID         string `xml:",chardata"`
+		} `xml:"EventID"`
+		Version     string `xml:"Version"`
+		Level       string `xml:"Level"`
+		Task        string `xml:"Task"`
+		Opcode      string `xml:"Opcode"`
+		Keywords    string `xml:"Keywords"`
+		TimeCreated struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+		EventRecordID string `xml:"EventRecordID"`
+		Correlation   struct {
+			ActivityID        string `xml:"ActivityID,attr"`
+			RelatedActivityID string `xml:"RelatedActivityID,attr"`
+		} `xml:"Correlation"`
+		Execution struct {
+			ProcessID string `xml:"ProcessID,attr"`
+			ThreadID  string `xml:"ThreadID,attr"`
+		} `xml:"Execution"`
+		Channel  string `xml:"Channel"`
+		Computer string `xml:"Computer"`
+		Security struct {
+			UserID string `xml:"UserID,attr"`
+		} `xml:"Security"`
+	} `xml:"System"`
+	EventData struct {
+		Data []struct {
+			Name  string `xml:"Name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Data"`
+		Binary string `xml:"Binary"`
+	} `xml:"EventData"`
+	UserData struct {
+		Nodes []xmlNode `xml:",any"`
+	} `xml:"UserData"`
+	RenderingInfo struct {
+		Message  string   `xml:"Message"`
+		Level    string   `xml:"Level"`
+		Task     string   `xml:"Task"`
+		Opcode   string   `xml:"Opcode"`
+		Keywords []string `xml:"Keywords>Keyword"`
+	} `xml:"RenderingInfo"`
+}
+
+// xmlNode is an arbitrary XML element, used for the provider specific user data
+type xmlNode struct {
+	XMLName xml.Name
+	Content string    `xml:",chardata"`
+	Nodes   []xmlNode `xml:",any"`
+}
+
+func unmarshalEventXML(raw string) (*eventXML, error) {
+	event := &eventXML{}
+	if err := xml.Unmarshal([]byte(raw), event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the event xml: %w", err)
+	}
+	return event, nil
+}
+
+// severity maps the standard event levels to the log severity
+func (e *eventXML) severity() (pdata.SeverityNumber, string) {
+	var number pdata.SeverityNumber
+	var text string
+	switch e.System.Level {
+	case "1":
+		number, text = pdata.SeverityNumberFATAL, "Critical"
+	case "2":
+		number, text = pdata.SeverityNumberERROR, "Error"
+	case "3":
+		number, text = pdata.SeverityNumberWARN, "Warning"
+	case "0", "4", "":
+		// level 0 is used by the events logged regardless of the level, e.g. the security audit events
+		number, text = pdata.SeverityNumberINFO, "Information"
+	case "5":
+		number, text = pdata.SeverityNumberDEBUG, "Verbose"
+	default:
+		number, text = pdata.SeverityNumberUNDEFINED, e.System.Level
+	}
+	if e.RenderingInfo.Level != "" {
+		text = e.RenderingInfo.Level
+	}
+	return number, text
+}
+
+func (e *eventXML) timestamp() pdata.Timestamp {
+	t, err := time.Parse(time.RFC3339Nano, e.System.TimeCreated.SystemTime)
+	if err != nil {
+		return pdata.TimestampFromTime(time.Now())
+	}
+	return pdata.TimestampFromTime(t)
+}
+
+// fillLogRecord sets the rendered message as the body of the record and the event fields as its attributes;
+// the raw XML is used as the body when the message could not be formatted
+func (e *eventXML) fillLogRecord(lr pdata.LogRecord, raw string) {
+	lr.SetTimestamp(e.timestamp())
+	number, text := e.severity()
+	lr.SetSeverityNumber(number)
+	lr.SetSeverityText(text)
+
+	if message := strings.TrimSpace(e.RenderingInfo.Message); message != "" {
+		lr.Body().SetStringVal(message)
+	} else {
+		lr.Body().SetStringVal(raw)
+	}
+
+	attrs := lr.Attributes()
+	system := e.System
+	insertString(attrs, "channel", system.Channel)
+	insertString(attrs, "computer", system.Computer)
+	insertString(attrs, "provider.name", system.Provider.Name)
+	insertString(attrs, "provider.guid", system.Provider.GUID)
+	insertString(attrs, "provider.event_source", system.Provider.EventSourceName)
+	insertInt(attrs, "event_id", system.EventID.ID)
+	insertInt(attrs, "event_id.qualifiers", system.EventID.Qualifiers)
+	insertInt(attrs, "record_id", system.EventRecordID)
+	insertInt(attrs, "version", system.Version)
+	insertString(attrs, "level", text)
+	insertString(attrs, "task", firstNonEmpty(e.RenderingInfo.Task, system.Task))
+	insertString(attrs, "opcode", firstNonEmpty(e.RenderingInfo.Opcode, system.Opcode))
+	if len(e.RenderingInfo.Keywords) > 0 {
+		insertString(attrs, "keywords", strings.Join(e.RenderingInfo.Keywords, ", "))
+	} else {
+		insertString(attrs, "keywords", system.Keywords)
+	}
+	insertString(attrs, "activity_id", system.Correlation.ActivityID)
+	insertString(attrs, "related_activity_id", system.Correlation.RelatedActivityID)
+	insertInt(attrs, "process.pid", system.Execution.ProcessID)
+	insertInt(attrs, "process.thread.id", system.Execution.ThreadID)
+	insertString(attrs, "user.identifier", system.Security.UserID)
+
+	for i, data := range e.EventData.Data {
+		name := data.Name
+		if name == "" {
+			// the classic event log sources provide only the values, in the order of the message parameters
+			name = "param" + strconv.Itoa(i+1)
+		}
+		attrs.UpsertString(eventDataAttributePrefix+name, data.Value)
+	}
+	if e.EventData.Binary != "" {
+		attrs.UpsertString(eventDataAttributePrefix+"binary", e.EventData.Binary)
+	}
+	for _, node := range e.UserData.Nodes {
+		node.flatten(attrs, userDataAttributePrefix+node.XMLName.Local)
+	}
+}
+
+// flatten sets the leaf elements of the node as attributes, named with the path of the element names
+func (n xmlNode) flatten(attrs pdata.AttributeMap, name string) {
+	if len(n.Nodes) == 0 {
+		attrs.UpsertString(name, strings.TrimSpace(n.Content))
+		return
+	}
+	for _, child := range n.Nodes {
+		child.flatten(attrs, name+"."+child.XMLName.Local)
+	}
+}
+
+func insertString(attrs pdata.AttributeMap, name, value string) {
+	if value != "" {
+		attrs.UpsertString(attributePrefix+name, value)
+	}
+}
+
+// insertInt sets the numeric fields as integers, falling back to the string for unexpected values
+func insertInt(attrs pdata.AttributeMap, name, value string) {
+	if value == "" {
+		return
+	}
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		attrs.UpsertInt(attributePrefix+name, i)
+		return
+	}
+	attrs.UpsertString(attributePrefix+name, value)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
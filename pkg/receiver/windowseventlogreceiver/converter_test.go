@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowseventlogreceiver
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func readEvent(t *testing.T, name string) string {
+	raw, err := ioutil.ReadFile(path.Join(".", "testdata", name))
+	require.NoError(t, err)
+	return string(raw)
+}
+
+func convertEvent(t *testing.T, raw string) pdata.LogRecord {
+	event, err := unmarshalEventXML(raw)
+	require.NoError(t, err)
+	lr := pdata.NewLogRecord()
+	event.fillLogRecord(lr, raw)
+	return lr
+}
+
+func attributesToMap(attributes pdata.AttributeMap) map[string]string {
+	result := map[string]string{}
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		result[k] = pdata.AttributeValueToString(v)
+		return true
+	})
+	return result
+}
+
+func TestConvertFormattedEvent(t *testing.T) {
+	lr := convertEvent(t, readEvent(t, "security_event.xml"))
+
+	assert.Equal(t, "An account failed to log on.\n\nAccount For Which Logon Failed:\n\tAccount Name:\t\tAdministrator", lr.Body().StringVal())
+	assert.Equal(t, time.Date(2021, 9, 1, 12, 0, 0, 123456700, time.UTC), lr.Timestamp().AsTime().UTC())
+	assert.Equal(t, pdata.SeverityNumberINFO, lr.SeverityNumber())
+	assert.Equal(t, "Information", lr.SeverityText())
+
+	assert.Equal(t, map[string]string{
+		"winlog.channel":                   "Security",
+		"winlog.computer":                  "web-1.example.com",
+		"winlog.provider.name":             "Microsoft-Windows-Security-Auditing",
+		"winlog.provider.guid":             "{54849625-5478-4994-a5ba-3e3b0328c30d}",
+		"winlog.event_id":                  "4625",
+		"winlog.record_id":                 "123456",
+		"winlog.version":                   "0",
+		"winlog.level":                     "Information",
+		"winlog.task":                      "Logon",
+		"winlog.opcode":                    "Info",
+		"winlog.keywords":                  "Audit Failure",
+		"winlog.activity_id":               "{4d8a0fbe-a1b2-0001-5c0f-8a4d8a1bd701}",
+		"winlog.process.pid":               "636",
+		"winlog.process.thread.id":         "4480",
+		"winlog.event_data.SubjectUserSid": "S-1-0-0",
+		"winlog.event_data.TargetUserName": "Administrator",
+		"winlog.event_data.LogonType":      "3",
+		"winlog.event_data.IpAddress":      "10.0.0.5",
+	}, attributesToMap(lr.Attributes()))
+
+	eventID, ok := lr.Attributes().Get("winlog.event_id")
+	require.True(t, ok)
+	assert.Equal(t, pdata.AttributeValueTypeInt, eventID.Type())
+}
+
+func TestConvertUnformattedEvent(t *testing.T) {
+	raw := readEvent(t, "classic_event.xml")
+	lr := convertEvent(t, raw)
+
+	// without the rendering info the raw XML is kept as the body
+	assert.Equal(t, raw, lr.Body().StringVal())
+	assert.Equal(t, pdata.SeverityNumberERROR, lr.SeverityNumber())
+	assert.Equal(t, "Error", lr.SeverityText())
+
+	attrs := attributesToMap(lr.Attributes())
+	assert.Equal(t, "49152", attrs["winlog.event_id.qualifiers"])
+	assert.Equal(t, "0", attrs["winlog.task"])
+	assert.Equal(t, "0x80000000000000", attrs["winlog.keywords"])
+	assert.Equal(t, "S-1-5-18", attrs["winlog.user.identifier"])
+	assert.Equal(t, "payments", attrs["winlog.event_data.param1"])
+	assert.Equal(t, "connection refused", attrs["winlog.event_data.param2"])
+	assert.Equal(t, "DEADBEEF", attrs["winlog.event_data.binary"])
+}
+
+func TestConvertUserData(t *testing.T) {
+	lr := convertEvent(t, readEvent(t, "user_data_event.xml"))
+
+	assert.Equal(t, "The audit log was cleared.", lr.Body().StringVal())
+	attrs := attributesToMap(lr.Attributes())
+	assert.Equal(t, "S-1-5-21-1", attrs["winlog.user_data.LogFileCleared.SubjectUserSid"])
+	assert.Equal(t, "admin", attrs["winlog.user_data.LogFileCleared.SubjectUserName"])
+}
+
+func TestSeverity(t *testing.T) {
+	testcases := []struct {
+		level  string
+		number pdata.SeverityNumber
+		text   string
+	}{
+		{level: "1", number: pdata.SeverityNumberFATAL, text: "Critical"},
+		{level: "2", number: pdata.SeverityNumberERROR, text: "Error"},
+		{level: "3", number: pdata.SeverityNumberWARN, text: "Warning"},
+		{level: "4", number: pdata.SeverityNumberINFO, text: "Information"},
+		{level: "5", number: pdata.SeverityNumberDEBUG, text: "Verbose"},
+		{level: "16", number: pdata.SeverityNumberUNDEFINED, text: "16"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.level, func(t *testing.T) {
+			event := &eventXML{}
+			event.System.Level = tc.level
+			number, text := event.severity()
+			assert.Equal(t, tc.number, number)
+			assert.Equal(t, tc.text, text)
+		})
+	}
+}
+
+func TestUnmarshalInvalidEvent(t *testing.T) {
+	_, err := unmarshalEventXML("<Event><System>")
+	assert.Error(t, err)
+}
@@ -0,0 +1,274 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package windowseventlogreceiver
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"go.uber.org/zap"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	wevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtSubscribe             = wevtapi.NewProc("EvtSubscribe")
+	procEvtNext                  = wevtapi.NewProc("EvtNext")
+	procEvtRender                = wevtapi.NewProc("EvtRender")
+	procEvtClose                 = wevtapi.NewProc("EvtClose")
+	procEvtCreateBookmark        = wevtapi.NewProc("EvtCreateBookmark")
+	procEvtUpdateBookmark        = wevtapi.NewProc("EvtUpdateBookmark")
+	procEvtOpenPublisherMetadata = wevtapi.NewProc("EvtOpenPublisherMetadata")
+	procEvtFormatMessage         = wevtapi.NewProc("EvtFormatMessage")
+)
+
+const (
+	evtSubscribeToFutureEvents      = 1
+	evtSubscribeStartAtOldestRecord = 2
+	evtSubscribeStartAfterBookmark  = 3
+
+	evtRenderEventXML = 1
+	evtRenderBookmark = 2
+
+	evtFormatMessageXML = 9
+
+	// errorInvalidOperation is returned by EvtNext when the subscription has no events yet
+	errorInvalidOperation syscall.Errno = 4317
+
+	// defaultBufferSize is the initial size of the rendering buffer, in characters
+	defaultBufferSize = 8192
+)
+
+// windowsChannelReader reads the events of a channel through a pull subscription of the Windows Event Log API
+type windowsChannelReader struct {
+	logger       *zap.Logger
+	channel      string
+	signal       windows.Handle
+	subscription uintptr
+	bookmark     uintptr
+	buffer       []uint16
+	// publishers are the metadata handles of the providers used to format the messages, 0 if unavailable
+	publishers map[string]uintptr
+}
+
+func newChannelReader(logger *zap.Logger, channel string, startAt string, bookmarkXML string) (channelReader, error) {
+	r := &windowsChannelReader{
+		logger:     logger,
+		channel:    channel,
+		buffer:     make([]uint16, defaultBufferSize),
+		publishers: map[string]uintptr{},
+	}
+
+	var flags uint32 = evtSubscribeToFutureEvents
+	if startAt == startAtBeginning {
+		flags = evtSubscribeStartAtOldestRecord
+	}
+	var err error
+	if bookmarkXML != "" {
+		if r.bookmark, err = evtCreateBookmark(bookmarkXML); err != nil {
+			return nil, fmt.Errorf("failed to open the bookmark: %w", err)
+		}
+		flags = evtSubscribeStartAfterBookmark
+	} else if r.bookmark, err = evtCreateBookmark(""); err != nil {
+		return nil, fmt.Errorf("failed to create the bookmark: %w", err)
+	}
+
+	if r.signal, err = windows.CreateEvent(nil, 0, 0, nil); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to create the signal event: %w", err)
+	}
+	channelPtr, err := syscall.UTF16PtrFromString(channel)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	var bookmark uintptr
+	if flags == evtSubscribeStartAfterBookmark {
+		bookmark = r.bookmark
+	}
+	if r.subscription, err = call(procEvtSubscribe, 0, uintptr(r.signal), uintptr(unsafe.Pointer(channelPtr)), 0, bookmark, 0, 0, uintptr(flags)); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+	return r, nil
+}
+
+// Read returns the next events, updating the bookmark to the last of them
+func (r *windowsChannelReader) Read(maxReads int) ([]string, error) {
+	handles := make([]uintptr, maxReads)
+	var returned uint32
+	_, err := call(procEvtNext, r.subscription, uintptr(maxReads), uintptr(unsafe.Pointer(&handles[0])), 0, 0, uintptr(unsafe.Pointer(&returned)))
+	if err == windows.ERROR_NO_MORE_ITEMS || (err == errorInvalidOperation && returned == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]string, 0, returned)
+	for _, handle := range handles[:returned] {
+		if event, err := r.renderEvent(handle); err != nil {
+			r.logger.Warn("Failed to render the event", zap.String("channel", r.channel), zap.Error(err))
+		} else {
+			events = append(events, event)
+		}
+		if _, err := call(procEvtUpdateBookmark, r.bookmark, handle); err != nil {
+			r.logger.Warn("Failed to update the bookmark", zap.String("channel", r.channel), zap.Error(err))
+		}
+		evtClose(handle)
+	}
+	return events, nil
+}
+
+// renderEvent renders the event with the formatted message and rendering info,
+// falling back to the event without them when the provider metadata is not available
+func (r *windowsChannelReader) renderEvent(handle uintptr) (string, error) {
+	raw, err := r.render(handle, evtRenderEventXML)
+	if err != nil {
+		return "", err
+	}
+	event, err := unmarshalEventXML(raw)
+	if err != nil {
+		return "", err
+	}
+
+	publisher := r.publisher(event.System.Provider.Name)
+	if publisher == 0 {
+		return raw, nil
+	}
+	formatted, err := r.format(publisher, handle)
+	if err != nil {
+		r.logger.Debug("Failed to format the event message", zap.String("channel", r.channel),
+			zap.String("provider", event.System.Provider.Name), zap.Error(err))
+		return raw, nil
+	}
+	return formatted, nil
+}
+
+// publisher returns the cached metadata handle of the provider, opening it on the first use
+func (r *windowsChannelReader) publisher(provider string) uintptr {
+	if handle, ok := r.publishers[provider]; ok {
+		return handle
+	}
+
+	var handle uintptr
+	providerPtr, err := syscall.UTF16PtrFromString(provider)
+	if err == nil {
+		handle, err = call(procEvtOpenPublisherMetadata, 0, uintptr(unsafe.Pointer(providerPtr)), 0, 0, 0)
+	}
+	if err != nil {
+		r.logger.Debug("Failed to open the provider metadata, the messages are not formatted",
+			zap.String("channel", r.channel), zap.String("provider", provider), zap.Error(err))
+	}
+	r.publishers[provider] = handle
+	return handle
+}
+
+// render renders the event or bookmark as XML; the sizes used by EvtRender are in bytes
+func (r *windowsChannelReader) render(handle uintptr, flags uint32) (string, error) {
+	for {
+		var used, count uint32
+		_, err := call(procEvtRender, 0, handle, uintptr(flags), uintptr(len(r.buffer)*2),
+			uintptr(unsafe.Pointer(&r.buffer[0])), uintptr(unsafe.Pointer(&used)), uintptr(unsafe.Pointer(&count)))
+		if err == windows.ERROR_INSUFFICIENT_BUFFER {
+			r.buffer = make([]uint16, used/2+1)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("EvtRender failed: %w", err)
+		}
+		return windows.UTF16ToString(r.buffer[:used/2]), nil
+	}
+}
+
+// format renders the event as XML including the rendering info; the sizes used by EvtFormatMessage are in characters
+func (r *windowsChannelReader) format(publisher uintptr, handle uintptr) (string, error) {
+	for {
+		var used uint32
+		_, err := call(procEvtFormatMessage, publisher, handle, 0, 0, 0, evtFormatMessageXML, uintptr(len(r.buffer)),
+			uintptr(unsafe.Pointer(&r.buffer[0])), uintptr(unsafe.Pointer(&used)))
+		if err == windows.ERROR_INSUFFICIENT_BUFFER {
+			r.buffer = make([]uint16, used+1)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("EvtFormatMessage failed: %w", err)
+		}
+		return windows.UTF16ToString(r.buffer[:used]), nil
+	}
+}
+
+// Bookmark renders the bookmark of the last read event
+func (r *windowsChannelReader) Bookmark() (string, error) {
+	return r.render(r.bookmark, evtRenderBookmark)
+}
+
+// Close closes the subscription, the bookmark and the provider metadata handles
+func (r *windowsChannelReader) Close() error {
+	var err error
+	if r.subscription != 0 {
+		err = evtClose(r.subscription)
+		r.subscription = 0
+	}
+	if r.bookmark != 0 {
+		evtClose(r.bookmark)
+		r.bookmark = 0
+	}
+	for provider, handle := range r.publishers {
+		if handle != 0 {
+			evtClose(handle)
+		}
+		delete(r.publishers, provider)
+	}
+	if r.signal != 0 {
+		windows.CloseHandle(r.signal)
+		r.signal = 0
+	}
+	return err
+}
+
+// evtCreateBookmark creates a bookmark from its XML, or an empty one
+func evtCreateBookmark(bookmarkXML string) (uintptr, error) {
+	var bookmarkPtr *uint16
+	if bookmarkXML != "" {
+		var err error
+		if bookmarkPtr, err = syscall.UTF16PtrFromString(bookmarkXML); err != nil {
+			return 0, err
+		}
+	}
+	return call(procEvtCreateBookmark, uintptr(unsafe.Pointer(bookmarkPtr)))
+}
+
+func evtClose(handle uintptr) error {
+	_, err := call(procEvtClose, handle)
+	return err
+}
+
+// call calls the Windows Event Log API function, whose result is 0 on failure
+func call(proc *windows.LazyProc, args ...uintptr) (uintptr, error) {
+	result, _, err := proc.Call(args...)
+	if result == 0 {
+		if errno, ok := err.(syscall.Errno); ok && errno != 0 {
+			return 0, errno
+		}
+		return 0, fmt.Errorf("%s failed", proc.Name)
+	}
+	return result, nil
+}
@@ -0,0 +1,250 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowseventlogreceiver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+const (
+	// bookmarkKey is the prefix of the storage keys holding the bookmarks, by channel
+	bookmarkKey = "bookmark."
+)
+
+// channelReader reads the events of a single event log channel
+type channelReader interface {
+	// Read returns up to maxReads of the next events rendered as XML, including the formatted message
+	// when the provider metadata is available
+	Read(maxReads int) ([]string, error)
+	// Bookmark returns the bookmark of the last read event rendered as XML
+	Bookmark() (string, error)
+	Close() error
+}
+
+// newChannelReaderFunc opens a reader of the channel, starting after the bookmark if it is not empty
+type newChannelReaderFunc func(logger *zap.Logger, channel string, startAt string, bookmark string) (channelReader, error)
+
+type windowsEventLogReceiver struct {
+	config    *Config
+	logger    *zap.Logger
+	consumer  consumer.Logs
+	newReader newChannelReaderFunc
+
+	includeProviders map[string]bool
+	excludeProviders map[string]bool
+
+	storageClient storage.Client
+	readers       map[string]channelReader
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// Ensure this receiver adheres to required interface.
+var _ component.LogsReceiver = (*windowsEventLogReceiver)(nil)
+
+func newWindowsEventLogReceiver(
+	logger *zap.Logger,
+	cfg *Config,
+	nextConsumer consumer.Logs,
+	newReader newChannelReaderFunc,
+) *windowsEventLogReceiver {
+	return &windowsEventLogReceiver{
+		config:           cfg,
+		logger:           logger,
+		consumer:         nextConsumer,
+		newReader:        newReader,
+		includeProviders: toLowerSet(cfg.IncludeProviders),
+		excludeProviders: toLowerSet(cfg.ExcludeProviders),
+		readers:          map[string]channelReader{},
+	}
+}
+
+// Start opens the channels, resuming from the persisted bookmarks, and starts polling them for the events
+func (r *windowsEventLogReceiver) Start(ctx context.Context, host component.Host) error {
+	if r.config.Storage != "" {
+		var err error
+		if r.storageClient, err = r.getStorageClient(ctx, host); err != nil {
+			return err
+		}
+	}
+
+	for _, channel := range r.config.Channels {
+		bookmark, err := r.loadBookmark(ctx, channel)
+		if err != nil {
+			r.closeReaders()
+			return err
+		}
+		reader, err := r.newReader(r.logger, channel, r.config.StartAt, bookmark)
+		if err != nil {
+			r.closeReaders()
+			return fmt.Errorf("failed to open the %s channel: %w", channel, err)
+		}
+		r.readers[channel] = reader
+	}
+
+	rctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	for channel, reader := range r.readers {
+		r.wg.Add(1)
+		go r.run(rctx, channel, reader)
+	}
+	return nil
+}
+
+// Shutdown stops polling and closes the channels
+func (r *windowsEventLogReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	r.closeReaders()
+	if r.storageClient != nil {
+		return r.storageClient.Close(ctx)
+	}
+	return nil
+}
+
+func (r *windowsEventLogReceiver) closeReaders() {
+	for channel, reader := range r.readers {
+		if err := reader.Close(); err != nil {
+			r.logger.Warn("Failed to close the channel", zap.String("channel", channel), zap.Error(err))
+		}
+		delete(r.readers, channel)
+	}
+}
+
+func (r *windowsEventLogReceiver) getStorageClient(ctx context.Context, host component.Host) (storage.Client, error) {
+	id, err := config.NewIDFromString(r.config.Storage)
+	if err != nil {
+		return nil, err
+	}
+	extension, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", r.config.Storage)
+	}
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", r.config.Storage)
+	}
+	return storageExtension.GetClient(ctx, component.KindReceiver, r.config.ID(), "")
+}
+
+func (r *windowsEventLogReceiver) loadBookmark(ctx context.Context, channel string) (string, error) {
+	if r.storageClient == nil {
+		return "", nil
+	}
+	value, err := r.storageClient.Get(ctx, bookmarkKey+channel)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the bookmark of the %s channel: %w", channel, err)
+	}
+	return string(value), nil
+}
+
+func (r *windowsEventLogReceiver) saveBookmark(ctx context.Context, channel string, reader channelReader) {
+	if r.storageClient == nil {
+		return
+	}
+	bookmark, err := reader.Bookmark()
+	if err != nil {
+		r.logger.Warn("Failed to render the bookmark", zap.String("channel", channel), zap.Error(err))
+		return
+	}
+	if err := r.storageClient.Set(ctx, bookmarkKey+channel, []byte(bookmark)); err != nil {
+		r.logger.Warn("Failed to persist the bookmark", zap.String("channel", channel), zap.Error(err))
+	}
+}
+
+// run reads the events of the channel every poll interval until the context is done
+func (r *windowsEventLogReceiver) run(ctx context.Context, channel string, reader channelReader) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		r.readToEnd(ctx, channel, reader)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// readToEnd reads the events of the channel until there are no more of them
+func (r *windowsEventLogReceiver) readToEnd(ctx context.Context, channel string, reader channelReader) {
+	for ctx.Err() == nil {
+		events, err := reader.Read(r.config.MaxReads)
+		if err != nil {
+			r.logger.Warn("Failed to read the events", zap.String("channel", channel), zap.Error(err))
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+		r.consume(ctx, channel, events)
+		r.saveBookmark(ctx, channel, reader)
+	}
+}
+
+func (r *windowsEventLogReceiver) consume(ctx context.Context, channel string, events []string) {
+	logs := pdata.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	for _, raw := range events {
+		event, err := unmarshalEventXML(raw)
+		if err != nil {
+			r.logger.Warn("Dropping the event", zap.String("channel", channel), zap.Error(err))
+			continue
+		}
+		if !r.acceptProvider(event.System.Provider.Name) {
+			continue
+		}
+		event.fillLogRecord(records.AppendEmpty(), raw)
+	}
+	if records.Len() == 0 {
+		return
+	}
+
+	if err := r.consumer.ConsumeLogs(ctx, logs); err != nil {
+		r.logger.Warn("Failed to consume the events", zap.String("channel", channel), zap.Error(err))
+	}
+}
+
+// acceptProvider checks the provider of the event against the include and exclude lists, ignoring the case
+func (r *windowsEventLogReceiver) acceptProvider(provider string) bool {
+	provider = strings.ToLower(provider)
+	if len(r.includeProviders) > 0 && !r.includeProviders[provider] {
+		return false
+	}
+	return !r.excludeProviders[provider]
+}
+
+func toLowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[strings.ToLower(value)] = true
+	}
+	return set
+}
@@ -0,0 +1,206 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowseventlogreceiver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/storage"
+	"go.uber.org/zap"
+)
+
+// fakeReader returns the queued events, using the number of the read events as the bookmark
+type fakeReader struct {
+	mu       sync.Mutex
+	channel  string
+	startAt  string
+	bookmark string
+	events   []string
+	read     int
+	closed   bool
+}
+
+func (r *fakeReader) Read(maxReads int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) < maxReads {
+		maxReads = len(r.events)
+	}
+	events := r.events[:maxReads]
+	r.events = r.events[maxReads:]
+	r.read += len(events)
+	return events, nil
+}
+
+func (r *fakeReader) Bookmark() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strconv.Itoa(r.read), nil
+}
+
+func (r *fakeReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+type testStorageExtension struct {
+	component.Extension
+	client *testStorageClient
+}
+
+func (e *testStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return e.client, nil
+}
+
+type testStorageClient struct {
+	storage.Client
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (c *testStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data[key], nil
+}
+
+func (c *testStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	return nil
+}
+
+func (c *testStorageClient) Close(context.Context) error {
+	return nil
+}
+
+type testHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func newTestConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Channels = []string{"Security", "Application"}
+	cfg.PollInterval = 10 * time.Millisecond
+	cfg.MaxReads = 1
+	return cfg
+}
+
+func TestReceiveEvents(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.ExcludeProviders = []string{"microsoft-windows-eventlog"}
+	cfg.Storage = "file_storage"
+
+	storageClient := &testStorageClient{data: map[string][]byte{bookmarkKey + "Security": []byte("10")}}
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewID("file_storage"): &testStorageExtension{client: storageClient},
+		},
+	}
+
+	readers := map[string]*fakeReader{
+		"Security":    {events: []string{readEvent(t, "security_event.xml"), readEvent(t, "user_data_event.xml")}},
+		"Application": {events: []string{readEvent(t, "classic_event.xml"), "<Event>"}},
+	}
+	newReader := func(_ *zap.Logger, channel string, startAt string, bookmark string) (channelReader, error) {
+		reader := readers[channel]
+		reader.channel, reader.startAt, reader.bookmark = channel, startAt, bookmark
+		return reader, nil
+	}
+
+	sink := new(consumertest.LogsSink)
+	r := newWindowsEventLogReceiver(zap.NewNop(), cfg, sink, newReader)
+	require.NoError(t, r.Start(context.Background(), host))
+
+	assert.Eventually(t, func() bool {
+		storageClient.mu.Lock()
+		defer storageClient.mu.Unlock()
+		return string(storageClient.data[bookmarkKey+"Security"]) == "2" &&
+			string(storageClient.data[bookmarkKey+"Application"]) == "2"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	assert.Equal(t, "10", readers["Security"].bookmark)
+	assert.Equal(t, "", readers["Application"].bookmark)
+	assert.Equal(t, startAtEnd, readers["Application"].startAt)
+	assert.True(t, readers["Security"].closed)
+	assert.True(t, readers["Application"].closed)
+
+	// the event log event is excluded and the invalid event is dropped
+	require.Equal(t, 2, sink.LogRecordCount())
+	providers := map[string]bool{}
+	for _, logs := range sink.AllLogs() {
+		lr := logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0)
+		provider, _ := lr.Attributes().Get("winlog.provider.name")
+		providers[provider.StringVal()] = true
+	}
+	assert.Equal(t, map[string]bool{"Microsoft-Windows-Security-Auditing": true, "MyLegacyApp": true}, providers)
+}
+
+func TestAcceptProvider(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.IncludeProviders = []string{"Microsoft-Windows-Security-Auditing", "MyApp"}
+	cfg.ExcludeProviders = []string{"MyApp"}
+	r := newWindowsEventLogReceiver(zap.NewNop(), cfg, consumertest.NewNop(), nil)
+
+	assert.True(t, r.acceptProvider("microsoft-windows-security-auditing"))
+	assert.False(t, r.acceptProvider("MyApp"))
+	assert.False(t, r.acceptProvider("Other"))
+}
+
+func TestStartFailure(t *testing.T) {
+	cfg := newTestConfig()
+	opened := &fakeReader{}
+	newReader := func(_ *zap.Logger, channel string, _ string, _ string) (channelReader, error) {
+		if channel == "Application" {
+			return nil, errors.New("channel not found")
+		}
+		return opened, nil
+	}
+
+	r := newWindowsEventLogReceiver(zap.NewNop(), cfg, consumertest.NewNop(), newReader)
+	err := r.Start(context.Background(), componenttest.NewNopHost())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Application")
+	assert.True(t, opened.closed)
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestMissingStorageExtension(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Storage = "file_storage"
+	r := newWindowsEventLogReceiver(zap.NewNop(), cfg, consumertest.NewNop(), nil)
+	assert.Error(t, r.Start(context.Background(), componenttest.NewNopHost()))
+}
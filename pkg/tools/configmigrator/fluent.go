@@ -0,0 +1,460 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmigrator
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// The formats of the configuration files of the Fluent log processors
+const (
+	formatFluentd   = "fluentd"
+	formatFluentBit = "fluent-bit"
+)
+
+// fluentConfig is the Fluentd or Fluent Bit configuration reduced to what the collector supports:
+// the inputs, tagging the records, and the filters and outputs, matching the records by their tags
+type fluentConfig struct {
+	inputs []*fluentInput
+	// stages are the filters and the outputs in the order the records are routed through them
+	stages []*fluentStage
+	// allOutputs is set when the records are sent to all the matching outputs, like in Fluent Bit,
+	// and not only to the first one, like in Fluentd
+	allOutputs bool
+	warnings   []string
+}
+
+func (c *fluentConfig) warnf(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// fluentInput is an input converted to a receiver
+type fluentInput struct {
+	// description identifies the input in the warnings
+	description string
+	name        string
+	// tag is the tag of the records, with the wildcard expanded like Fluentd and Fluent Bit do
+	tag string
+	// anyTag is set when the tags are not known, e.g. they are set by the senders of the records
+	anyTag       bool
+	receiverType string
+	receiver     yaml.MapSlice
+	// operators are the stanza operators of the receiver, nil if the receiver doesn't support them
+	operators []yaml.MapSlice
+	stanza    bool
+	// parsed is set once the records are parsed to the attributes, which are then the fields of the record
+	parsed bool
+}
+
+// field returns the stanza field holding the key of the record
+func (in *fluentInput) field(key string) string {
+	if !in.parsed {
+		return "$body"
+	}
+	return "$attributes." + key
+}
+
+// fluentStage is a filter or an output, applied to the records with matching tags
+type fluentStage struct {
+	description string
+	name        string
+	match       *regexp.Regexp
+	filter      *fluentFilter
+	output      *fluentOutput
+}
+
+// fluentFilter is a filter converted to stanza operators of the receivers or to an attributes processor
+type fluentFilter struct {
+	// grep are the rules the records must pass, applied in order
+	grep []grepRule
+	// parser parses the key of the record, or the body when the record is not parsed yet
+	parser    *fluentParser
+	parserKey string
+	// attributes are the actions of the attributes processor
+	attributes []yaml.MapSlice
+}
+
+// grepRule keeps the records with the key matching the regular expression, or drops them when exclude is set
+type grepRule struct {
+	key     string
+	regexp  string
+	exclude bool
+}
+
+// fluentOutput is an output converted to an exporter; the records routed to the outputs
+// without the exporter are not converted
+type fluentOutput struct {
+	exporterType string
+	exporter     yaml.MapSlice
+	// fields are set as the resource attributes, which the Sumo Logic exporter sends as fields
+	fields map[string]string
+}
+
+// fluentParser is a parser of Fluentd or Fluent Bit
+type fluentParser struct {
+	format string
+	// regexp is the regular expression of the regexp format, with the named groups in the Go syntax
+	regexp string
+	// lineStartPattern is the regular expression matching the first lines of multiline records
+	lineStartPattern string
+	timeKey          string
+	timeFormat       string
+	keepTime         bool
+	// protocol is the syslog protocol of the syslog format
+	protocol string
+}
+
+// criRegexp parses the lines of the CRI log files, e.g. 2021-09-01T12:00:00.000000000Z stdout F message
+const criRegexp = `^(?P<time>[^ ]+) (?P<stream>stdout|stderr) (?P<logtag>[^ ]*) ?(?P<log>.*)$`
+
+// rubyNamedGroup matches the named groups in the Ruby and Onigmo syntax, (?<name>...)
+var rubyNamedGroup = regexp.MustCompile(`\(\?<([A-Za-z_][A-Za-z0-9_]*)>`)
+
+// newRegexpParser returns the parser of the regular expression, converted from the Ruby and Onigmo syntax
+func newRegexpParser(expression string) (*fluentParser, error) {
+	expression = rubyNamedGroup.ReplaceAllString(expression, "(?P<$1>")
+	if _, err := regexp.Compile(expression); err != nil {
+		return nil, err
+	}
+	return &fluentParser{format: "regexp", regexp: expression}, nil
+}
+
+// operators returns the stanza operators parsing the field to the attributes of the records
+func (p *fluentParser) operators(parseFrom string) ([]yaml.MapSlice, error) {
+	operator := yaml.MapSlice{}
+	switch p.format {
+	case "none":
+		return nil, nil
+	case "json":
+		operator = append(operator, yaml.MapItem{Key: "type", Value: "json_parser"})
+	case "regexp":
+		operator = append(operator, yaml.MapItem{Key: "type", Value: "regex_parser"}, yaml.MapItem{Key: "regex", Value: p.regexp})
+	case "cri":
+		operator = append(operator, yaml.MapItem{Key: "type", Value: "regex_parser"}, yaml.MapItem{Key: "regex", Value: criRegexp})
+	case "syslog":
+		protocol := p.protocol
+		if protocol == "" {
+			protocol = "rfc3164"
+		}
+		operator = append(operator, yaml.MapItem{Key: "type", Value: "syslog_parser"}, yaml.MapItem{Key: "protocol", Value: protocol})
+	default:
+		return nil, fmt.Errorf("%s format is not supported", p.format)
+	}
+	operator = append(operator,
+		yaml.MapItem{Key: "parse_from", Value: parseFrom},
+		yaml.MapItem{Key: "parse_to", Value: "$attributes"},
+	)
+
+	timeKey := p.timeKey
+	if timeKey == "" {
+		timeKey = "time"
+	}
+	timestamp := yaml.MapSlice{{Key: "parse_from", Value: "$attributes." + timeKey}}
+	switch {
+	case p.format == "cri":
+		timestamp = append(timestamp,
+			yaml.MapItem{Key: "layout_type", Value: "gotime"},
+			yaml.MapItem{Key: "layout", Value: "2006-01-02T15:04:05.999999999Z07:00"},
+		)
+	case p.timeFormat != "":
+		timestamp = append(timestamp,
+			yaml.MapItem{Key: "layout_type", Value: "strptime"},
+			yaml.MapItem{Key: "layout", Value: p.timeFormat},
+		)
+	default:
+		timestamp = nil
+	}
+	if timestamp != nil {
+		if p.keepTime {
+			timestamp = append(timestamp, yaml.MapItem{Key: "preserve_to", Value: "$attributes." + timeKey})
+		}
+		operator = append(operator, yaml.MapItem{Key: "timestamp", Value: timestamp})
+	}
+	return []yaml.MapSlice{operator}, nil
+}
+
+// fluentdTagPattern matches the wildcards of the Fluentd match patterns
+var fluentdTagPattern = regexp.MustCompile(`\.\*\*|\*\*\.|\*\*|\*|\{[^}]*\}`)
+
+// fluentdMatch returns the regular expression of the Fluentd match patterns, separated by spaces,
+// where * matches a part of the tag, ** zero or more parts and {a,b} any of the alternatives
+func fluentdMatch(patterns string) (*regexp.Regexp, error) {
+	var alternatives []string
+	for _, pattern := range strings.Fields(patterns) {
+		var re strings.Builder
+		last := 0
+		for _, loc := range fluentdTagPattern.FindAllStringIndex(pattern, -1) {
+			re.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+			switch wildcard := pattern[loc[0]:loc[1]]; wildcard {
+			case ".**":
+				re.WriteString(`(?:\..*)?`)
+			case "**.":
+				re.WriteString(`(?:.*\.)?`)
+			case "**":
+				re.WriteString(`.*`)
+			case "*":
+				re.WriteString(`[^.]+`)
+			default:
+				options := strings.Split(wildcard[1:len(wildcard)-1], ",")
+				for i := range options {
+					options[i] = regexp.QuoteMeta(options[i])
+				}
+				re.WriteString("(?:" + strings.Join(options, "|") + ")")
+			}
+			last = loc[1]
+		}
+		re.WriteString(regexp.QuoteMeta(pattern[last:]))
+		alternatives = append(alternatives, re.String())
+	}
+	if len(alternatives) == 0 {
+		alternatives = append(alternatives, `.*`)
+	}
+	return regexp.Compile("^(?:" + strings.Join(alternatives, "|") + ")$")
+}
+
+// expandTag returns the tag of the records read from the path, with the wildcard replaced
+// by the path with the slashes replaced by dots
+func expandTag(tag string, path string) string {
+	path = strings.Trim(strings.ReplaceAll(path, "/", "."), ".")
+	return strings.Replace(tag, "*", path, 1)
+}
+
+// convertFluent converts the inputs to the pipelines, with the filters and outputs matching their tags
+func convertFluent(c *fluentConfig, origin string) (*Migration, error) {
+	var receivers, processors, exporters, pipelines yaml.MapSlice
+	defined := map[string]bool{}
+	define := func(section *yaml.MapSlice, id string, config yaml.MapSlice) {
+		if !defined[id] {
+			defined[id] = true
+			*section = append(*section, yaml.MapItem{Key: id, Value: config})
+		}
+	}
+
+	for _, in := range c.inputs {
+		var filters, outputs []*fluentStage
+		var discarded *fluentStage
+		for _, stage := range c.stages {
+			if !in.anyTag && !stage.match.MatchString(in.tag) {
+				continue
+			}
+			if stage.filter != nil {
+				filters = append(filters, stage)
+				continue
+			}
+			if stage.output.exporter != nil {
+				outputs = append(outputs, stage)
+			} else if discarded == nil {
+				discarded = stage
+			}
+			if !c.allOutputs {
+				break
+			}
+		}
+		if len(outputs) == 0 {
+			if discarded != nil {
+				c.warnf("The records of %s are routed to %s, which is not supported, so the input is not converted", in.description, discarded.description)
+			} else {
+				c.warnf("The records of %s are not routed to any output, so the input is not converted", in.description)
+			}
+			continue
+		}
+
+		var pipelineProcessors []string
+		for _, stage := range filters {
+			operators, err := stage.filter.operators(in)
+			if err != nil {
+				c.warnf("%s is not applied to the records of %s: %v", stage.description, in.description, err)
+			}
+			in.operators = append(in.operators, operators...)
+			if len(stage.filter.attributes) > 0 {
+				id := "attributes/" + stage.name
+				define(&processors, id, yaml.MapSlice{{Key: "actions", Value: stage.filter.attributes}})
+				pipelineProcessors = append(pipelineProcessors, id)
+			}
+		}
+
+		var pipelineExporters []string
+		for _, stage := range outputs {
+			if len(stage.output.fields) > 0 {
+				id := "resource/" + stage.name
+				var attributes []yaml.MapSlice
+				for _, field := range sortedMap(stage.output.fields) {
+					attributes = append(attributes, yaml.MapSlice{
+						{Key: "key", Value: field.Key},
+						{Key: "value", Value: field.Value},
+						{Key: "action", Value: "upsert"},
+					})
+				}
+				define(&processors, id, yaml.MapSlice{{Key: "attributes", Value: attributes}})
+				pipelineProcessors = append(pipelineProcessors, id)
+			}
+			id := stage.output.exporterType + "/" + stage.name
+			define(&exporters, id, stage.output.exporter)
+			pipelineExporters = append(pipelineExporters, id)
+		}
+
+		receiverID := in.receiverType + "/" + in.name
+		receiver := in.receiver
+		if len(in.operators) > 0 {
+			receiver = append(receiver, yaml.MapItem{Key: "operators", Value: in.operators})
+		}
+		receivers = append(receivers, yaml.MapItem{Key: receiverID, Value: receiver})
+
+		pipeline := yaml.MapSlice{{Key: "receivers", Value: []string{receiverID}}}
+		if len(pipelineProcessors) > 0 {
+			pipeline = append(pipeline, yaml.MapItem{Key: "processors", Value: pipelineProcessors})
+		}
+		pipeline = append(pipeline, yaml.MapItem{Key: "exporters", Value: pipelineExporters})
+		pipelines = append(pipelines, yaml.MapItem{Key: "logs/" + in.name, Value: pipeline})
+	}
+	if len(pipelines) == 0 {
+		return nil, errors.New("none of the inputs can be converted")
+	}
+
+	config := yaml.MapSlice{{Key: "receivers", Value: receivers}}
+	if len(processors) > 0 {
+		config = append(config, yaml.MapItem{Key: "processors", Value: processors})
+	}
+	config = append(config,
+		yaml.MapItem{Key: "exporters", Value: exporters},
+		yaml.MapItem{Key: "service", Value: yaml.MapSlice{{Key: "pipelines", Value: pipelines}}},
+	)
+	return &Migration{Config: config, Warnings: c.warnings, origin: origin}, nil
+}
+
+// operators returns the stanza operators of the filter applied to the records of the input
+func (f *fluentFilter) operators(in *fluentInput) ([]yaml.MapSlice, error) {
+	if len(f.grep) == 0 && f.parser == nil {
+		return nil, nil
+	}
+	if !in.stanza {
+		return nil, fmt.Errorf("the %s receiver doesn't support operators", in.receiverType)
+	}
+
+	var operators []yaml.MapSlice
+	for _, rule := range f.grep {
+		value := "$body"
+		if in.parsed {
+			value = fmt.Sprintf("$attributes[%s]", strconv.Quote(rule.key))
+		}
+		// The filter operator drops the records matching the expression
+		expr := fmt.Sprintf("%s matches %s", value, strconv.Quote(rule.regexp))
+		if !rule.exclude {
+			expr = "not (" + expr + ")"
+		}
+		operators = append(operators, yaml.MapSlice{{Key: "type", Value: "filter"}, {Key: "expr", Value: expr}})
+	}
+	if f.parser != nil {
+		parsers, err := f.parser.operators(in.field(f.parserKey))
+		if err != nil {
+			return operators, err
+		}
+		operators = append(operators, parsers...)
+		in.parsed = in.parsed || len(parsers) > 0
+	}
+	return operators, nil
+}
+
+// NewFluentCommand returns the migrate-fluent command, converting the configuration of Fluentd or Fluent Bit.
+// The configuration is written to --output, or to the standard output, and the warnings about the constructs
+// which couldn't be converted to the standard error.
+func NewFluentCommand() *cobra.Command {
+	var inputPath, parsersPath, format, outputPath string
+	cmd := &cobra.Command{
+		Use:          "migrate-fluent",
+		Short:        "Convert the configuration of Fluentd or Fluent Bit to the configuration of the collector",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			content, err := ioutil.ReadFile(inputPath)
+			if err != nil {
+				return err
+			}
+			if format == "" {
+				if format, err = detectFluentFormat(string(content)); err != nil {
+					return err
+				}
+			}
+
+			var migration *Migration
+			switch format {
+			case formatFluentd:
+				migration, err = ConvertFluentd(string(content))
+			case formatFluentBit:
+				var parsers string
+				if parsersPath != "" {
+					parsersContent, err := ioutil.ReadFile(parsersPath)
+					if err != nil {
+						return err
+					}
+					parsers = string(parsersContent)
+				}
+				migration, err = ConvertFluentBit(string(content), parsers)
+			default:
+				return fmt.Errorf("unknown format %q, use %s or %s", format, formatFluentd, formatFluentBit)
+			}
+			if err != nil {
+				return err
+			}
+
+			for _, warning := range migration.Warnings {
+				fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: %s\n", warning)
+			}
+			out, err := migration.YAML()
+			if err != nil {
+				return err
+			}
+			if outputPath == "" {
+				_, err = cmd.OutOrStdout().Write(out)
+				return err
+			}
+			return ioutil.WriteFile(outputPath, out, 0600)
+		},
+	}
+
+	cmd.Flags().StringVar(&inputPath, "input", "", "Path to the configuration file of Fluentd or Fluent Bit")
+	cmd.Flags().StringVar(&parsersPath, "parsers", "", "Path to the parsers file of Fluent Bit")
+	cmd.Flags().StringVar(&format, "format", "", "Format of the configuration, fluentd or fluent-bit; detected from the content by default")
+	cmd.Flags().StringVar(&outputPath, "output", "", "Path to write the configuration to, instead of the standard output")
+	_ = cmd.MarkFlagRequired("input")
+	return cmd
+}
+
+// fluentdDirectivePattern and fluentBitSectionPattern match the beginnings of the Fluentd directives
+// and the Fluent Bit sections
+var (
+	fluentdDirectivePattern = regexp.MustCompile(`(?m)^\s*<(source|match|filter|label)[\s>]`)
+	fluentBitSectionPattern = regexp.MustCompile(`(?mi)^\s*\[(SERVICE|INPUT|FILTER|OUTPUT|PARSER)\]`)
+)
+
+// detectFluentFormat returns the format of the configuration
+func detectFluentFormat(content string) (string, error) {
+	switch {
+	case fluentdDirectivePattern.MatchString(content):
+		return formatFluentd, nil
+	case fluentBitSectionPattern.MatchString(content):
+		return formatFluentBit, nil
+	default:
+		return "", errors.New("cannot detect the format of the configuration, set it with --format")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmigrator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestdata(t *testing.T, name string) string {
+	content, err := ioutil.ReadFile(path.Join(".", "testdata", name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestConvertFluentd(t *testing.T) {
+	migration, err := ConvertFluentd(readTestdata(t, "fluentd.conf"))
+	require.NoError(t, err)
+	assert.Contains(t, migration.Warnings, `http input "http" is not supported`)
+	assert.Contains(t, migration.Warnings, `The records of tail input "containers" are routed to `+
+		`stdout output "containers.**", which is not supported, so the input is not converted`)
+
+	out, err := migration.YAML()
+	require.NoError(t, err)
+	assert.Equal(t, readTestdata(t, "fluentd.yaml"), string(out))
+}
+
+func TestConvertFluentBit(t *testing.T) {
+	migration, err := ConvertFluentBit(readTestdata(t, "fluent-bit.conf"), readTestdata(t, "parsers.conf"))
+	require.NoError(t, err)
+	assert.Contains(t, migration.Warnings, `@INCLUDE extra.conf is not supported`)
+	assert.Contains(t, migration.Warnings, `systemd input "systemd" is not supported`)
+
+	out, err := migration.YAML()
+	require.NoError(t, err)
+	assert.Equal(t, readTestdata(t, "fluent-bit.yaml"), string(out))
+}
+
+func TestConvertFluentErrors(t *testing.T) {
+	_, err := ConvertFluentd("<source>\n  @type tail\n")
+	assert.EqualError(t, err, "failed to parse the Fluentd configuration: <source> is not closed")
+
+	_, err = ConvertFluentd("<source>\n</match>\n")
+	assert.EqualError(t, err, "failed to parse the Fluentd configuration: line 2: unexpected </match>")
+
+	_, err = ConvertFluentd("<source>\n  @type tail\n  path /var/log/app.log\n  tag app\n</source>\n")
+	assert.EqualError(t, err, "none of the inputs can be converted")
+
+	_, err = ConvertFluentBit("Name tail\n", "")
+	assert.EqualError(t, err, `failed to parse the Fluent Bit configuration: line 1: "Name tail" is not in a section`)
+}
+
+func TestFluentdMatch(t *testing.T) {
+	testcases := []struct {
+		pattern string
+		tag     string
+		matches bool
+	}{
+		{"app.*", "app.web", true},
+		{"app.*", "app.web.1", false},
+		{"app.*", "app", false},
+		{"app.**", "app", true},
+		{"app.**", "app.web.1", true},
+		{"app.**", "application", false},
+		{"**.error", "app.web.error", true},
+		{"**.error", "error", true},
+		{"app.{web,db}", "app.db", true},
+		{"app.{web,db}", "app.cache", false},
+		{"app.web db.**", "db.primary", true},
+		{"**", "anything.at.all", true},
+	}
+	for _, tc := range testcases {
+		match, err := fluentdMatch(tc.pattern)
+		require.NoError(t, err)
+		assert.Equal(t, tc.matches, match.MatchString(tc.tag), "%s matching %s", tc.pattern, tc.tag)
+	}
+}
+
+func TestExpandTag(t *testing.T) {
+	assert.Equal(t, "kube.var.log.containers.*.log", expandTag("kube.*", "/var/log/containers/*.log"))
+	assert.Equal(t, "app", expandTag("app", "/var/log/app.log"))
+}
+
+func TestFluentCommand(t *testing.T) {
+	cmd := NewFluentCommand()
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	cmd.SetArgs([]string{
+		"--input", path.Join(".", "testdata", "fluent-bit.conf"),
+		"--parsers", path.Join(".", "testdata", "parsers.conf"),
+	})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, readTestdata(t, "fluent-bit.yaml"), out.String())
+	assert.Contains(t, errOut.String(), "WARNING: @INCLUDE extra.conf is not supported\n")
+
+	cmd = NewFluentCommand()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"--input", path.Join(".", "testdata", "user.properties")})
+	assert.EqualError(t, cmd.Execute(), "cannot detect the format of the configuration, set it with --format")
+}
@@ -0,0 +1,427 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmigrator
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// fluentBitSection is a section of the Fluent Bit configuration, e.g. [INPUT], with the keys in lowercase,
+// as they are case insensitive
+type fluentBitSection struct {
+	name   string
+	params []fluentdParam
+}
+
+// param returns the value of the key, or an empty string when it's not set
+func (s *fluentBitSection) param(key string) string {
+	for i := len(s.params) - 1; i >= 0; i-- {
+		if s.params[i].key == key {
+			return s.params[i].value
+		}
+	}
+	return ""
+}
+
+// values returns all the values of the key, which can be set multiple times, e.g. Regex of the grep filter
+func (s *fluentBitSection) values(key string) []string {
+	var values []string
+	for _, param := range s.params {
+		if param.key == key {
+			values = append(values, param.value)
+		}
+	}
+	return values
+}
+
+// on returns whether the boolean key is enabled
+func (s *fluentBitSection) on(key string) bool {
+	switch strings.ToLower(s.param(key)) {
+	case "on", "true", "yes", "1":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseFluentBit parses the Fluent Bit configuration to the sections, and reports the commands
+// which are not supported
+func (c *fluentConfig) parseFluentBit(content string) ([]*fluentBitSection, error) {
+	var sections []*fluentBitSection
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "@"):
+			c.warnf("%s is not supported", line)
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			sections = append(sections, &fluentBitSection{name: strings.ToUpper(strings.TrimSpace(line[1 : len(line)-1]))})
+		case len(sections) == 0:
+			return nil, fmt.Errorf("line %d: %q is not in a section", lineNumber, line)
+		default:
+			parts := strings.Fields(line)
+			param := fluentdParam{key: strings.ToLower(parts[0])}
+			if len(parts) > 1 {
+				param.value = strings.TrimSpace(line[len(parts[0]):])
+			}
+			current := sections[len(sections)-1]
+			current.params = append(current.params, param)
+		}
+	}
+	return sections, scanner.Err()
+}
+
+// ConvertFluentBit converts the Fluent Bit configuration, and the parsers defined in a separate file,
+// to the configuration of the collector, with a logs pipeline for each input. The records of the input
+// go through the matching filters to all the matching outputs, like in Fluent Bit.
+func ConvertFluentBit(content string, parsersContent string) (*Migration, error) {
+	c := &fluentConfig{allOutputs: true}
+	sections, err := c.parseFluentBit(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Fluent Bit configuration: %w", err)
+	}
+	parserSections, err := c.parseFluentBit(parsersContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Fluent Bit parsers: %w", err)
+	}
+
+	parsers := map[string]*fluentBitSection{}
+	for _, section := range append(parserSections, sections...) {
+		if section.name == "PARSER" {
+			parsers[section.param("name")] = section
+		}
+	}
+
+	names := nameSet{}
+	var filters, outputs []*fluentStage
+	inputs := 0
+	for _, section := range sections {
+		pluginName := strings.ToLower(section.param("name"))
+		name := names.unique(section.param("alias"), pluginName)
+		switch section.name {
+		case "INPUT":
+			c.addFluentBitInput(section, name, inputs, parsers)
+			inputs++
+		case "FILTER":
+			if stage := c.fluentBitStage(section, name, c.fluentBitFilter(section, parsers)); stage != nil {
+				filters = append(filters, stage)
+			}
+		case "OUTPUT":
+			if stage := c.fluentBitStage(section, name, c.fluentBitOutput(section)); stage != nil {
+				outputs = append(outputs, stage)
+			}
+		case "SERVICE", "PARSER":
+		default:
+			c.warnf("[%s] is not supported", section.name)
+		}
+	}
+	// The filters are applied before the records are sent to the outputs
+	c.stages = append(filters, outputs...)
+	return convertFluent(c, "Fluent Bit with otelcol-sumo migrate-fluent")
+}
+
+func (c *fluentConfig) addFluentBitInput(s *fluentBitSection, name string, index int, parsers map[string]*fluentBitSection) {
+	inputType := strings.ToLower(s.param("name"))
+	description := fmt.Sprintf("%s input %q", inputType, name)
+	in := &fluentInput{description: description, name: name, tag: s.param("tag")}
+	if in.tag == "" {
+		in.tag = fmt.Sprintf("%s.%d", inputType, index)
+	}
+
+	switch inputType {
+	case "tail":
+		paths := splitList(s.param("path"))
+		if len(paths) == 0 {
+			c.warnf("%s is not converted, as the path is not set", description)
+			return
+		}
+		in.tag = expandTag(in.tag, paths[0])
+		in.receiverType, in.stanza = "filelog", true
+		in.receiver = yaml.MapSlice{{Key: "include", Value: paths}}
+		if exclude := splitList(s.param("exclude_path")); len(exclude) > 0 {
+			in.receiver = append(in.receiver, yaml.MapItem{Key: "exclude", Value: exclude})
+		}
+		startAt := "end"
+		if s.on("read_from_head") {
+			startAt = "beginning"
+		}
+		in.receiver = append(in.receiver, yaml.MapItem{Key: "start_at", Value: startAt})
+		c.parseFluentBitInput(in, s, parsers)
+	case "forward":
+		in.receiverType = "fluentforward"
+		in.receiver = yaml.MapSlice{{Key: "endpoint", Value: listenAddress(s.param("listen"), s.param("port"), "24224")}}
+		in.anyTag = true
+		c.warnf("The records of %s are routed to the filters and outputs matching any tag, as the tags are set by the senders", description)
+	default:
+		c.warnf("%s is not supported", description)
+		return
+	}
+	c.inputs = append(c.inputs, in)
+}
+
+// parseFluentBitInput adds the parser and the multiline settings of the tail input to the receiver
+func (c *fluentConfig) parseFluentBitInput(in *fluentInput, s *fluentBitSection, parsers map[string]*fluentBitSection) {
+	var parser *fluentParser
+	var err error
+	// The first supported multiline parser is used
+	for _, name := range splitList(s.param("multiline.parser")) {
+		if parser != nil {
+			break
+		}
+		switch name {
+		case "docker":
+			parser = &fluentParser{format: "json"}
+		case "cri":
+			parser = &fluentParser{format: "cri"}
+		default:
+			c.warnf("%s multiline parser of %s is not supported", name, in.description)
+		}
+	}
+
+	switch {
+	case parser != nil:
+	case s.on("multiline") && s.param("parser_firstline") != "":
+		// The first line parser parses the whole multiline record
+		parser, err = fluentBitParser(parsers, s.param("parser_firstline"))
+		if err == nil {
+			parser.lineStartPattern = parser.regexp
+			parser.regexp = "(?s)" + parser.regexp
+		}
+	case s.param("parser") != "":
+		parser, err = fluentBitParser(parsers, s.param("parser"))
+	}
+	if err != nil {
+		c.warnf("The parser of %s is not converted: %v", in.description, err)
+		return
+	}
+	if parser == nil {
+		return
+	}
+
+	if parser.lineStartPattern != "" {
+		in.receiver = append(in.receiver, yaml.MapItem{Key: "multiline", Value: yaml.MapSlice{
+			{Key: "line_start_pattern", Value: parser.lineStartPattern},
+		}})
+	}
+	operators, err := parser.operators("$body")
+	if err != nil {
+		c.warnf("The parser of %s is not converted: %v", in.description, err)
+		return
+	}
+	in.operators = append(in.operators, operators...)
+	in.parsed = len(operators) > 0
+}
+
+// fluentBitParser returns the parser defined in the [PARSER] section with the name
+func fluentBitParser(parsers map[string]*fluentBitSection, name string) (*fluentParser, error) {
+	s, ok := parsers[name]
+	if !ok {
+		return nil, fmt.Errorf("%s parser is not defined", name)
+	}
+
+	var parser *fluentParser
+	var err error
+	switch format := strings.ToLower(s.param("format")); format {
+	case "json":
+		parser = &fluentParser{format: format}
+	case "regex":
+		if parser, err = newRegexpParser(s.param("regex")); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s format of %s parser is not supported", format, name)
+	}
+	parser.timeKey = s.param("time_key")
+	parser.timeFormat = s.param("time_format")
+	parser.keepTime = s.on("time_keep")
+	return parser, nil
+}
+
+func (c *fluentConfig) fluentBitStage(s *fluentBitSection, name string, stage *fluentStage) *fluentStage {
+	if stage == nil {
+		return nil
+	}
+	kind := "filter"
+	if stage.output != nil {
+		kind = "output"
+	}
+	pattern := s.param("match")
+	if s.param("match_regex") != "" {
+		pattern = s.param("match_regex")
+	}
+	description := fmt.Sprintf("%s %s %q", strings.ToLower(s.param("name")), kind, pattern)
+
+	var match *regexp.Regexp
+	var err error
+	if s.param("match_regex") != "" {
+		match, err = regexp.Compile(s.param("match_regex"))
+	} else {
+		// The wildcard of Fluent Bit matches any characters, including the dots
+		match, err = regexp.Compile("^" + strings.ReplaceAll(regexp.QuoteMeta(s.param("match")), `\*`, ".*") + "$")
+	}
+	if err != nil {
+		c.warnf("%s is not converted: %v", description, err)
+		return nil
+	}
+	stage.name, stage.description, stage.match = name, description, match
+	return stage
+}
+
+// fluentBitFilter returns the filter of the [FILTER] section, or nil when it's not supported
+func (c *fluentConfig) fluentBitFilter(s *fluentBitSection, parsers map[string]*fluentBitSection) *fluentStage {
+	filter := &fluentFilter{}
+	filterType := strings.ToLower(s.param("name"))
+	description := fmt.Sprintf("%s filter %q", filterType, s.param("match"))
+	switch filterType {
+	case "grep":
+		for _, key := range []string{"regex", "exclude"} {
+			for _, value := range s.values(key) {
+				parts := strings.SplitN(value, " ", 2)
+				if len(parts) != 2 {
+					c.warnf("%s %s of %s is not converted, as the regular expression is not set", key, value, description)
+					continue
+				}
+				filter.grep = append(filter.grep, grepRule{
+					key:     parts[0],
+					regexp:  strings.TrimSpace(parts[1]),
+					exclude: key == "exclude",
+				})
+			}
+		}
+	case "record_modifier":
+		if len(s.values("allowlist_key")) > 0 || len(s.values("whitelist_key")) > 0 {
+			c.warnf("allowlist_key of %s is not supported", description)
+		}
+		for _, value := range s.values("record") {
+			if parts := strings.SplitN(value, " ", 2); len(parts) == 2 {
+				filter.attributes = append(filter.attributes, setAction(parts[0], strings.TrimSpace(parts[1]), "upsert"))
+			}
+		}
+		for _, key := range append(s.values("remove_key"), s.values("remove_keys")...) {
+			filter.attributes = append(filter.attributes, yaml.MapSlice{{Key: "key", Value: key}, {Key: "action", Value: "delete"}})
+		}
+	case "modify":
+		if len(s.values("condition")) > 0 {
+			c.warnf("%s is not converted, as conditions are not supported", description)
+			return nil
+		}
+		for _, param := range s.params {
+			parts := strings.Fields(param.value)
+			switch {
+			case param.key == "match" || param.key == "match_regex" || param.key == "name" || param.key == "alias":
+			case param.key == "set" && len(parts) >= 2:
+				filter.attributes = append(filter.attributes, setAction(parts[0], strings.Join(parts[1:], " "), "upsert"))
+			case param.key == "add" && len(parts) >= 2:
+				filter.attributes = append(filter.attributes, setAction(parts[0], strings.Join(parts[1:], " "), "insert"))
+			case param.key == "remove" && len(parts) == 1:
+				filter.attributes = append(filter.attributes, yaml.MapSlice{{Key: "key", Value: parts[0]}, {Key: "action", Value: "delete"}})
+			case (param.key == "rename" || param.key == "hard_rename") && len(parts) == 2:
+				action := "insert"
+				if param.key == "hard_rename" {
+					action = "upsert"
+				}
+				filter.attributes = append(filter.attributes,
+					yaml.MapSlice{{Key: "key", Value: parts[1]}, {Key: "from_attribute", Value: parts[0]}, {Key: "action", Value: action}},
+					yaml.MapSlice{{Key: "key", Value: parts[0]}, {Key: "action", Value: "delete"}},
+				)
+			case (param.key == "copy" || param.key == "hard_copy") && len(parts) == 2:
+				action := "insert"
+				if param.key == "hard_copy" {
+					action = "upsert"
+				}
+				filter.attributes = append(filter.attributes,
+					yaml.MapSlice{{Key: "key", Value: parts[1]}, {Key: "from_attribute", Value: parts[0]}, {Key: "action", Value: action}},
+				)
+			default:
+				c.warnf("%s %s of %s is not supported", param.key, param.value, description)
+			}
+		}
+	case "parser":
+		parser, err := fluentBitParser(parsers, s.param("parser"))
+		if err != nil {
+			c.warnf("%s is not converted: %v", description, err)
+			return nil
+		}
+		filter.parser, filter.parserKey = parser, s.param("key_name")
+	case "kubernetes":
+		c.warnf("%s is not converted; use the k8s_tagger processor to add the Kubernetes metadata", description)
+		return nil
+	default:
+		c.warnf("%s is not supported", description)
+		return nil
+	}
+	return &fluentStage{filter: filter}
+}
+
+// setAction returns the action of the attributes processor setting the key to the value
+func setAction(key string, value string, action string) yaml.MapSlice {
+	return yaml.MapSlice{{Key: "key", Value: key}, {Key: "value", Value: value}, {Key: "action", Value: action}}
+}
+
+// sumoHeaders map the headers of the requests sent to the HTTP Sources to the settings of the exporter
+var sumoHeaders = map[string]string{
+	"x-sumo-category": "source_category",
+	"x-sumo-name":     "source_name",
+	"x-sumo-host":     "source_host",
+}
+
+// fluentBitOutput returns the output of the [OUTPUT] section; the outputs which are not supported
+// are returned without the exporter. The http output sending to Sumo Logic is converted to the exporter.
+func (c *fluentConfig) fluentBitOutput(s *fluentBitSection) *fluentStage {
+	output := &fluentOutput{}
+	outputType := strings.ToLower(s.param("name"))
+	description := fmt.Sprintf("%s output %q", outputType, s.param("match"))
+	if outputType != "http" || !strings.Contains(s.param("host"), "sumologic") {
+		c.warnf("%s is not supported", description)
+		return &fluentStage{output: output}
+	}
+
+	endpoint := url.URL{Scheme: "http", Host: s.param("host"), Path: s.param("uri")}
+	defaultPort := "80"
+	if s.on("tls") {
+		endpoint.Scheme, defaultPort = "https", "443"
+	}
+	if port := s.param("port"); port != "" && port != defaultPort {
+		endpoint.Host += ":" + port
+	}
+	output.exporterType = "sumologic"
+	output.exporter = yaml.MapSlice{
+		{Key: "endpoint", Value: endpoint.String()},
+		{Key: "log_format", Value: "json"},
+	}
+	if format := s.param("format"); !strings.HasPrefix(format, "json") {
+		c.warnf("format %s of %s is not supported, the records are sent as JSON", format, description)
+	}
+	for _, header := range s.values("header") {
+		parts := strings.SplitN(header, " ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		if setting, ok := sumoHeaders[strings.ToLower(parts[0])]; ok {
+			output.exporter = append(output.exporter, yaml.MapItem{Key: setting, Value: value})
+		} else if strings.EqualFold(parts[0], "X-Sumo-Fields") {
+			output.fields = parseFields(value)
+		}
+	}
+	return &fluentStage{output: output}
+}
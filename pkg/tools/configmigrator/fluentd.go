@@ -0,0 +1,432 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmigrator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// fluentdParam is a parameter of a Fluentd directive
+type fluentdParam struct {
+	key   string
+	value string
+}
+
+// fluentdDirective is a directive of the Fluentd configuration, e.g. <match app.**>, with its parameters
+// and the nested directives
+type fluentdDirective struct {
+	name     string
+	arg      string
+	params   []fluentdParam
+	children []*fluentdDirective
+}
+
+// param returns the value of the parameter, or an empty string when it's not set
+func (d *fluentdDirective) param(key string) string {
+	for i := len(d.params) - 1; i >= 0; i-- {
+		if d.params[i].key == key {
+			return d.params[i].value
+		}
+	}
+	return ""
+}
+
+// child returns the first nested directive with the name, or nil when there isn't one
+func (d *fluentdDirective) child(name string) *fluentdDirective {
+	for _, child := range d.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// parseFluentd parses the Fluentd configuration to the directives, nested in the returned root
+func parseFluentd(content string) (*fluentdDirective, error) {
+	root := &fluentdDirective{}
+	stack := []*fluentdDirective{root}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		current := stack[len(stack)-1]
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "</") && strings.HasSuffix(line, ">"):
+			name := strings.TrimSpace(line[2 : len(line)-1])
+			if len(stack) == 1 || current.name != name {
+				return nil, fmt.Errorf("line %d: unexpected </%s>", lineNumber, name)
+			}
+			stack = stack[:len(stack)-1]
+		case strings.HasPrefix(line, "<") && strings.HasSuffix(line, ">"):
+			parts := strings.SplitN(strings.TrimSpace(line[1:len(line)-1]), " ", 2)
+			directive := &fluentdDirective{name: parts[0]}
+			if len(parts) == 2 {
+				directive.arg = strings.TrimSpace(parts[1])
+			}
+			current.children = append(current.children, directive)
+			stack = append(stack, directive)
+		default:
+			parts := strings.SplitN(line, " ", 2)
+			param := fluentdParam{key: parts[0]}
+			if len(parts) == 2 {
+				param.value = unquote(strings.TrimSpace(parts[1]))
+			}
+			current.params = append(current.params, param)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("<%s> is not closed", stack[len(stack)-1].name)
+	}
+	return root, nil
+}
+
+// unquote returns the value without the surrounding quotes
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		if value[0] == '"' {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				return unquoted
+			}
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// rubyRegexp returns the regular expression written as /regexp/flags, with the flags converted
+// to the Go syntax; other values are returned as they are
+func rubyRegexp(value string) string {
+	end := strings.LastIndex(value, "/")
+	if !strings.HasPrefix(value, "/") || end < 1 {
+		return value
+	}
+	expression, flags := value[1:end], value[end+1:]
+	if strings.Contains(flags, "m") {
+		expression = "(?s)" + expression
+	}
+	if strings.Contains(flags, "i") {
+		expression = "(?i)" + expression
+	}
+	return expression
+}
+
+// ConvertFluentd converts the Fluentd configuration to the configuration of the collector,
+// with a logs pipeline for each input. The records of the input go through the matching filters
+// to the first matching output, like in Fluentd.
+func ConvertFluentd(content string) (*Migration, error) {
+	root, err := parseFluentd(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Fluentd configuration: %w", err)
+	}
+
+	c := &fluentConfig{}
+	names := nameSet{}
+	for _, param := range root.params {
+		c.warnf("%s %s is not supported", param.key, param.value)
+	}
+	for _, d := range root.children {
+		name := names.unique(d.param("@id"), d.param("@type"))
+		switch d.name {
+		case "source":
+			c.addFluentdSource(d, name)
+		case "filter":
+			c.addFluentdStage(d, name, c.fluentdFilter(d))
+		case "match":
+			c.addFluentdStage(d, name, c.fluentdOutput(d))
+		case "system":
+		default:
+			c.warnf("<%s %s> is not supported", d.name, d.arg)
+		}
+	}
+	return convertFluent(c, "Fluentd with otelcol-sumo migrate-fluent")
+}
+
+func (c *fluentConfig) addFluentdSource(d *fluentdDirective, name string) {
+	inputType := d.param("@type")
+	description := fmt.Sprintf("%s input %q", inputType, name)
+	if label := d.param("@label"); label != "" {
+		c.warnf("%s is not converted, as the records are routed to the %s label", description, label)
+		return
+	}
+
+	in := &fluentInput{description: description, name: name, tag: d.param("tag")}
+	switch inputType {
+	case "tail":
+		paths := splitList(d.param("path"))
+		if len(paths) == 0 {
+			c.warnf("%s is not converted, as the path is not set", description)
+			return
+		}
+		in.tag = expandTag(in.tag, paths[0])
+		in.receiverType, in.stanza = "filelog", true
+		in.receiver = yaml.MapSlice{{Key: "include", Value: paths}}
+		if exclude := d.param("exclude_path"); exclude != "" {
+			var paths []string
+			if err := json.Unmarshal([]byte(exclude), &paths); err != nil {
+				c.warnf("exclude_path of %s is not converted, as it is not an array: %v", description, err)
+			} else {
+				in.receiver = append(in.receiver, yaml.MapItem{Key: "exclude", Value: paths})
+			}
+		}
+		startAt := "end"
+		if d.param("read_from_head") == "true" {
+			startAt = "beginning"
+		}
+		in.receiver = append(in.receiver, yaml.MapItem{Key: "start_at", Value: startAt})
+		if encoding := d.param("encoding"); encoding != "" {
+			in.receiver = append(in.receiver, yaml.MapItem{Key: "encoding", Value: strings.ToLower(encoding)})
+		}
+		c.parseFluentdInput(in, d.child("parse"))
+	case "forward":
+		in.receiverType = "fluentforward"
+		in.receiver = yaml.MapSlice{{Key: "endpoint", Value: listenAddress(d.param("bind"), d.param("port"), "24224")}}
+		in.anyTag = true
+		c.warnf("The records of %s are routed to the filters and outputs matching any tag, as the tags are set by the senders", description)
+	default:
+		c.warnf("%s is not supported", description)
+		return
+	}
+	c.inputs = append(c.inputs, in)
+}
+
+// parseFluentdInput adds the parser of the input to the receiver
+func (c *fluentConfig) parseFluentdInput(in *fluentInput, d *fluentdDirective) {
+	if d == nil {
+		return
+	}
+	parser, err := fluentdParser(d)
+	if err != nil {
+		c.warnf("The parser of %s is not converted: %v", in.description, err)
+		return
+	}
+	if parser.lineStartPattern != "" {
+		in.receiver = append(in.receiver, yaml.MapItem{Key: "multiline", Value: yaml.MapSlice{
+			{Key: "line_start_pattern", Value: parser.lineStartPattern},
+		}})
+	}
+	operators, err := parser.operators("$body")
+	if err != nil {
+		c.warnf("The parser of %s is not converted: %v", in.description, err)
+		return
+	}
+	in.operators = append(in.operators, operators...)
+	in.parsed = len(operators) > 0
+}
+
+// fluentdParser returns the parser of the <parse> directive
+func fluentdParser(d *fluentdDirective) (*fluentParser, error) {
+	var parser *fluentParser
+	var err error
+	switch parserType := d.param("@type"); parserType {
+	case "none", "json", "cri":
+		parser = &fluentParser{format: parserType}
+	case "regexp":
+		if parser, err = newRegexpParser(rubyRegexp(d.param("expression"))); err != nil {
+			return nil, err
+		}
+	case "multiline":
+		var formats []string
+		for i := 1; d.param(fmt.Sprintf("format%d", i)) != ""; i++ {
+			formats = append(formats, rubyRegexp(d.param(fmt.Sprintf("format%d", i))))
+		}
+		// The formats are joined to a single regular expression, where the dot matches the new lines
+		if parser, err = newRegexpParser("(?s)" + strings.Join(formats, "")); err != nil {
+			return nil, err
+		}
+		parser.lineStartPattern = rubyRegexp(d.param("format_firstline"))
+	case "syslog":
+		parser = &fluentParser{format: "syslog", protocol: "rfc3164"}
+		if d.param("message_format") == "rfc5424" {
+			parser.protocol = "rfc5424"
+		}
+	default:
+		return nil, fmt.Errorf("%s parser is not supported", parserType)
+	}
+	parser.timeKey = d.param("time_key")
+	parser.timeFormat = d.param("time_format")
+	parser.keepTime = d.param("keep_time_key") == "true"
+	return parser, nil
+}
+
+func (c *fluentConfig) addFluentdStage(d *fluentdDirective, name string, stage *fluentStage) {
+	if stage == nil {
+		return
+	}
+	description := fmt.Sprintf("%s filter %q", d.param("@type"), d.arg)
+	if stage.output != nil {
+		description = fmt.Sprintf("%s output %q", d.param("@type"), d.arg)
+	}
+	match, err := fluentdMatch(d.arg)
+	if err != nil {
+		c.warnf("%s is not converted: %v", description, err)
+		return
+	}
+	stage.name, stage.description, stage.match = name, description, match
+	c.stages = append(c.stages, stage)
+}
+
+// fluentdFilter returns the filter of the <filter> directive, or nil when it's not supported
+func (c *fluentConfig) fluentdFilter(d *fluentdDirective) *fluentStage {
+	filter := &fluentFilter{}
+	description := fmt.Sprintf("%s filter %q", d.param("@type"), d.arg)
+	switch d.param("@type") {
+	case "record_transformer":
+		if d.param("enable_ruby") == "true" || d.param("renew_record") == "true" || d.param("keep_keys") != "" {
+			c.warnf("enable_ruby, renew_record and keep_keys of %s are not supported", description)
+		}
+		if record := d.child("record"); record != nil {
+			for _, param := range record.params {
+				if strings.Contains(param.value, "${") || strings.Contains(param.value, "#{") {
+					c.warnf("%s of %s is not converted, as placeholders are not supported", param.key, description)
+					continue
+				}
+				filter.attributes = append(filter.attributes, yaml.MapSlice{
+					{Key: "key", Value: param.key},
+					{Key: "value", Value: param.value},
+					{Key: "action", Value: "upsert"},
+				})
+			}
+		}
+		for _, key := range splitList(d.param("remove_keys")) {
+			filter.attributes = append(filter.attributes, yaml.MapSlice{
+				{Key: "key", Value: key},
+				{Key: "action", Value: "delete"},
+			})
+		}
+	case "grep":
+		for _, child := range d.children {
+			switch child.name {
+			case "regexp", "exclude":
+				filter.grep = append(filter.grep, grepRule{
+					key:     child.param("key"),
+					regexp:  rubyRegexp(child.param("pattern")),
+					exclude: child.name == "exclude",
+				})
+			default:
+				c.warnf("<%s> of %s is not supported", child.name, description)
+			}
+		}
+	case "parser":
+		parse := d.child("parse")
+		if parse == nil {
+			c.warnf("%s is not converted, as <parse> is not set", description)
+			return nil
+		}
+		parser, err := fluentdParser(parse)
+		if err != nil {
+			c.warnf("%s is not converted: %v", description, err)
+			return nil
+		}
+		filter.parser, filter.parserKey = parser, d.param("key_name")
+	case "kubernetes_metadata":
+		c.warnf("%s is not converted; use the k8s_tagger processor to add the Kubernetes metadata", description)
+		return nil
+	default:
+		c.warnf("%s is not supported", description)
+		return nil
+	}
+	return &fluentStage{filter: filter}
+}
+
+// fluentdOutput returns the output of the <match> directive; the outputs which are not supported
+// are returned without the exporter, as they still consume the records
+func (c *fluentConfig) fluentdOutput(d *fluentdDirective) *fluentStage {
+	output := &fluentOutput{}
+	description := fmt.Sprintf("%s output %q", d.param("@type"), d.arg)
+	if d.param("@type") != "sumologic" {
+		c.warnf("%s is not supported", description)
+		return &fluentStage{output: output}
+	}
+	if dataType := d.param("data_type"); dataType != "" && dataType != "logs" {
+		c.warnf("%s is not converted, as only logs are supported", description)
+		return &fluentStage{output: output}
+	}
+	if d.param("endpoint") == "" {
+		c.warnf("%s is not converted, as the endpoint is not set", description)
+		return &fluentStage{output: output}
+	}
+
+	output.exporterType = "sumologic"
+	output.exporter = yaml.MapSlice{{Key: "endpoint", Value: d.param("endpoint")}}
+	switch d.param("log_format") {
+	case "", "json", "json_merge":
+		output.exporter = append(output.exporter, yaml.MapItem{Key: "log_format", Value: "json"})
+	case "text":
+		output.exporter = append(output.exporter, yaml.MapItem{Key: "log_format", Value: "text"})
+	case "fields":
+		// The attributes of the records are sent as fields in the otlp format
+		output.exporter = append(output.exporter, yaml.MapItem{Key: "log_format", Value: "otlp"})
+	default:
+		c.warnf("log_format %s of %s is not supported", d.param("log_format"), description)
+	}
+	for _, setting := range []string{"source_category", "source_name", "source_host"} {
+		if value := d.param(setting); value != "" {
+			output.exporter = append(output.exporter, yaml.MapItem{Key: setting, Value: value})
+		}
+	}
+	if d.param("compress") == "true" && d.param("compress_encoding") == "deflate" {
+		output.exporter = append(output.exporter, yaml.MapItem{Key: "compress_encoding", Value: "deflate"})
+	}
+	if fields := parseFields(d.param("custom_fields")); len(fields) > 0 {
+		output.fields = fields
+	}
+	if d.child("buffer") != nil {
+		c.warnf("<buffer> of %s is not converted; use the sending_queue of the exporter to buffer the records", description)
+	}
+	return &fluentStage{output: output}
+}
+
+// splitList returns the values of the comma separated list
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseFields returns the fields set as key1=value1,key2=value2
+func parseFields(list string) map[string]string {
+	fields := map[string]string{}
+	for _, field := range strings.Split(list, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) != "" {
+			fields[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return fields
+}
+
+// listenAddress returns the address to listen on, with the defaults of Fluentd and Fluent Bit
+func listenAddress(host string, port string, defaultPort string) string {
+	if host == "" {
+		host = "0.0.0.0"
+	}
+	if port == "" {
+		port = defaultPort
+	}
+	return host + ":" + port
+}
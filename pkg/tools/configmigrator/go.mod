@@ -0,0 +1,9 @@
+module github.com/open-telemetry/opentelemetry-collector-contrib/tools/configmigrator
+
+go 1.14
+
+require (
+	github.com/spf13/cobra v1.2.1
+	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v2 v2.4.0
+)
@@ -0,0 +1,400 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configmigrator implements the migrate command of the collector, which converts the sources
+// of the Installed Collector, defined in sources.json, to the configuration of the collector.
+package configmigrator
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// The credentials are not copied from user.properties, but read from the environment
+const (
+	accessIDPlaceholder  = "${SUMOLOGIC_ACCESS_ID}"
+	accessKeyPlaceholder = "${SUMOLOGIC_ACCESS_KEY}"
+)
+
+// defaultSyslogPort is the port of the Syslog Source when it's not set
+const defaultSyslogPort = 514
+
+// extensionProperties maps the settings of user.properties to the settings of the sumologic extension
+var extensionProperties = []struct{ property, setting string }{
+	{"name", "collector_name"},
+	{"description", "collector_description"},
+	{"category", "collector_category"},
+	{"timeZone", "time_zone"},
+	{"url", "api_base_url"},
+}
+
+// NewCommand returns the migrate command. The configuration is written to --output, or to the standard output,
+// and the warnings about the settings which couldn't be migrated to the standard error.
+func NewCommand() *cobra.Command {
+	var sourcesPath, propertiesPath, outputPath string
+	cmd := &cobra.Command{
+		Use:          "migrate",
+		Short:        "Convert the sources of the Installed Collector to the configuration of the collector",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sources, err := ReadSources(sourcesPath)
+			if err != nil {
+				return err
+			}
+			props := UserProperties{}
+			if propertiesPath != "" {
+				if props, err = ReadUserProperties(propertiesPath); err != nil {
+					return err
+				}
+			}
+
+			migration, err := Migrate(sources, props)
+			if err != nil {
+				return err
+			}
+			for _, warning := range migration.Warnings {
+				fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: %s\n", warning)
+			}
+			out, err := migration.YAML()
+			if err != nil {
+				return err
+			}
+			if outputPath == "" {
+				_, err = cmd.OutOrStdout().Write(out)
+				return err
+			}
+			return ioutil.WriteFile(outputPath, out, 0600)
+		},
+	}
+
+	cmd.Flags().StringVar(&sourcesPath, "sources", "", "Path to sources.json, or to the directory with the JSON files of the sources")
+	cmd.Flags().StringVar(&propertiesPath, "user-properties", "", "Path to user.properties of the Installed Collector")
+	cmd.Flags().StringVar(&outputPath, "output", "", "Path to write the configuration to, instead of the standard output")
+	_ = cmd.MarkFlagRequired("sources")
+	return cmd
+}
+
+// Migration is the configuration of the collector converted from the Installed Collector,
+// with the warnings about the settings which couldn't be migrated
+type Migration struct {
+	Config   yaml.MapSlice
+	Warnings []string
+
+	// origin describes where the configuration was migrated from, e.g. the Installed Collector
+	origin string
+}
+
+// YAML returns the configuration, preceded by the warnings as comments
+func (m *Migration) YAML() ([]byte, error) {
+	out, err := yaml.Marshal(m.Config)
+	if err != nil {
+		return nil, err
+	}
+	var header strings.Builder
+	header.WriteString("# Migrated from " + m.origin + "\n")
+	for _, warning := range m.Warnings {
+		header.WriteString("# WARNING: " + warning + "\n")
+	}
+	return append([]byte(header.String()), out...), nil
+}
+
+type migrator struct {
+	props      UserProperties
+	names      nameSet
+	receivers  yaml.MapSlice
+	processors yaml.MapSlice
+	exporters  yaml.MapSlice
+	pipelines  yaml.MapSlice
+	warnings   []string
+}
+
+// Migrate converts the sources and the collector settings to the configuration of the collector.
+// Each source is migrated to a separate logs pipeline, named after the source.
+func Migrate(sources []Source, props UserProperties) (*Migration, error) {
+	m := &migrator{props: props, names: nameSet{}}
+	for i, source := range sources {
+		m.migrateSource(i, source)
+	}
+	if len(m.pipelines) == 0 {
+		return nil, errors.New("none of the sources can be migrated")
+	}
+
+	return &Migration{
+		Config: yaml.MapSlice{
+			{Key: "extensions", Value: yaml.MapSlice{{Key: "sumologic", Value: m.extension()}}},
+			{Key: "receivers", Value: m.receivers},
+			{Key: "processors", Value: m.processors},
+			{Key: "exporters", Value: m.exporters},
+			{Key: "service", Value: yaml.MapSlice{
+				{Key: "extensions", Value: []string{"sumologic"}},
+				{Key: "pipelines", Value: m.pipelines},
+			}},
+		},
+		Warnings: m.warnings,
+		origin:   "the Installed Collector with otelcol-sumo migrate",
+	}, nil
+}
+
+func (m *migrator) warnf(format string, args ...interface{}) {
+	m.warnings = append(m.warnings, fmt.Sprintf(format, args...))
+}
+
+// extension returns the settings of the sumologic extension, which registers the collector
+func (m *migrator) extension() yaml.MapSlice {
+	ext := yaml.MapSlice{
+		{Key: "access_id", Value: accessIDPlaceholder},
+		{Key: "access_key", Value: accessKeyPlaceholder},
+	}
+	for _, p := range extensionProperties {
+		if value := m.props[p.property]; value != "" {
+			ext = append(ext, yaml.MapItem{Key: p.setting, Value: value})
+		}
+	}
+	if fields := m.props.fields(); len(fields) > 0 {
+		ext = append(ext, yaml.MapItem{Key: "collector_fields", Value: sortedMap(fields)})
+	}
+	for _, property := range []string{"clobber", "ephemeral"} {
+		if value, err := strconv.ParseBool(m.props[property]); err == nil && value {
+			ext = append(ext, yaml.MapItem{Key: property, Value: true})
+		}
+	}
+	return ext
+}
+
+func (m *migrator) migrateSource(i int, s Source) {
+	name := m.names.unique(s.Name, fmt.Sprintf("source_%d", i+1))
+
+	var receiverID string
+	var receiver yaml.MapSlice
+	var processors []string
+	exporter := yaml.MapSlice{}
+
+	switch s.SourceType {
+	case sourceTypeLocalFile:
+		receiverID, receiver = "filelog/"+name, m.fileReceiver(name, s)
+		exporter = append(exporter, yaml.MapItem{Key: "source_name", Value: sourceName(name, s)})
+		if host := m.sourceHost(s); host != "" {
+			exporter = append(exporter, yaml.MapItem{Key: "source_host", Value: host})
+		}
+	case sourceTypeSyslog:
+		receiverID, receiver = m.syslogReceiver(name, s)
+		processors = append(processors, "sumologic_syslog/"+name)
+		m.processors = append(m.processors, yaml.MapItem{Key: "sumologic_syslog/" + name, Value: yaml.MapSlice{}})
+		// The facility and the address of the sender are the Source Name and the Source Host, like in the Installed Collector
+		exporter = append(exporter,
+			yaml.MapItem{Key: "source_name", Value: "%{facility}"},
+			yaml.MapItem{Key: "source_host", Value: "%{net.peer.name}"},
+			yaml.MapItem{Key: "metadata_attributes", Value: []string{`^facility$`, `^net\.peer\.name$`}},
+		)
+	case sourceTypeScript:
+		m.warnf("Script Source %q is not migrated, as scripts are not supported by the collector; "+
+			"schedule the script to write its output to a file collected with the filelog receiver instead", s.Name)
+		return
+	default:
+		m.warnf("%s Source %q is not migrated, as it is not supported by the migration", s.SourceType, s.Name)
+		return
+	}
+	m.receivers = append(m.receivers, yaml.MapItem{Key: receiverID, Value: receiver})
+
+	processors = append(processors, "source/"+name)
+	m.processors = append(m.processors, yaml.MapItem{Key: "source/" + name, Value: m.sourceProcessor(name, s)})
+
+	if category := m.sourceCategory(s); category != "" {
+		exporter = append(exporter, yaml.MapItem{Key: "source_category", Value: category})
+	}
+	if s.AutomaticDateParsing != nil && !*s.AutomaticDateParsing {
+		exporter = append(exporter, yaml.MapItem{Key: "clear_logs_timestamp", Value: false})
+	}
+	m.exporters = append(m.exporters, yaml.MapItem{Key: "sumologic/" + name, Value: exporter})
+
+	m.pipelines = append(m.pipelines, yaml.MapItem{Key: "logs/" + name, Value: yaml.MapSlice{
+		{Key: "receivers", Value: []string{receiverID}},
+		{Key: "processors", Value: processors},
+		{Key: "exporters", Value: []string{"sumologic/" + name}},
+	}})
+
+	m.checkUnsupported(s)
+}
+
+// fileReceiver returns the filelog receiver of the Local File Source
+func (m *migrator) fileReceiver(name string, s Source) yaml.MapSlice {
+	receiver := yaml.MapSlice{
+		{Key: "include", Value: []string{s.PathExpression}},
+	}
+	if exclude := append(append([]string{}, s.Denylist...), s.Blacklist...); len(exclude) > 0 {
+		receiver = append(receiver, yaml.MapItem{Key: "exclude", Value: exclude})
+	}
+	// The Installed Collector has already collected the existing logs
+	receiver = append(receiver, yaml.MapItem{Key: "start_at", Value: "end"})
+	if s.Encoding != "" {
+		receiver = append(receiver, yaml.MapItem{Key: "encoding", Value: strings.ToLower(s.Encoding)})
+	}
+
+	multiline := s.MultilineProcessingEnabled == nil || *s.MultilineProcessingEnabled
+	autoline := s.UseAutolineMatching == nil || *s.UseAutolineMatching
+	switch {
+	case multiline && !autoline && s.ManualPrefixRegexp != "":
+		// The boundary regex matches the whole first line of the message
+		receiver = append(receiver, yaml.MapItem{Key: "multiline", Value: yaml.MapSlice{
+			{Key: "line_start_pattern", Value: "^(?:" + s.ManualPrefixRegexp + ")$"},
+		}})
+	case multiline && autoline:
+		m.warnf("Source %q uses the automatic detection of multiline messages, which is not supported; "+
+			"each line is a separate message, unless multiline.line_start_pattern of receivers::filelog/%s is set", s.Name, name)
+	}
+
+	if operators := m.filterOperators(s); len(operators) > 0 {
+		receiver = append(receiver, yaml.MapItem{Key: "operators", Value: operators})
+	}
+	return receiver
+}
+
+// syslogReceiver returns the tcplog or udplog receiver of the Syslog Source
+func (m *migrator) syslogReceiver(name string, s Source) (string, yaml.MapSlice) {
+	port := s.Port
+	if port == 0 {
+		port = defaultSyslogPort
+	}
+	receiverType := "udplog"
+	if strings.EqualFold(s.Protocol, "TCP") {
+		receiverType = "tcplog"
+	}
+
+	receiver := yaml.MapSlice{
+		{Key: "listen_address", Value: fmt.Sprintf("0.0.0.0:%d", port)},
+		// Adds net.peer.name, the Source Host
+		{Key: "add_attributes", Value: true},
+	}
+	if operators := m.filterOperators(s); len(operators) > 0 {
+		receiver = append(receiver, yaml.MapItem{Key: "operators", Value: operators})
+	}
+	return receiverType + "/" + name, receiver
+}
+
+// filterOperators returns the filter operators dropping the messages as the Include and Exclude filters do.
+// The regular expressions of the Installed Collector match the whole message.
+func (m *migrator) filterOperators(s Source) []yaml.MapSlice {
+	var operators []yaml.MapSlice
+	for _, f := range s.Filters {
+		match := fmt.Sprintf("body matches %s", strconv.Quote("^(?:"+f.Regexp+")$"))
+		switch f.FilterType {
+		case filterTypeExclude:
+			operators = append(operators, yaml.MapSlice{{Key: "type", Value: "filter"}, {Key: "expr", Value: match}})
+		case filterTypeInclude:
+			operators = append(operators, yaml.MapSlice{{Key: "type", Value: "filter"}, {Key: "expr", Value: "not (" + match + ")"}})
+		default:
+			m.warnf("%s filter %q of Source %q is not migrated; the PII Masking processor can mask or hash "+
+				"the matching values, but it replaces the whole match instead of the capture groups", f.FilterType, f.Name, s.Name)
+		}
+	}
+	return operators
+}
+
+// sourceProcessor returns the source processor, setting the fields, the collector name and _source.
+// The source templates for Kubernetes are disabled.
+func (m *migrator) sourceProcessor(name string, s Source) yaml.MapSlice {
+	processor := yaml.MapSlice{}
+	if collector := m.props["name"]; collector != "" {
+		processor = append(processor, yaml.MapItem{Key: "collector", Value: collector})
+	}
+	processor = append(processor,
+		yaml.MapItem{Key: "source", Value: sourceName(name, s)},
+		yaml.MapItem{Key: "source_name", Value: ""},
+		yaml.MapItem{Key: "source_category", Value: ""},
+		yaml.MapItem{Key: "source_category_prefix", Value: ""},
+		yaml.MapItem{Key: "source_category_replace_dash", Value: ""},
+	)
+	if len(s.Fields) > 0 {
+		processor = append(processor, yaml.MapItem{Key: "fields", Value: sortedMap(s.Fields)})
+	}
+	return processor
+}
+
+// sourceName returns the name of the source, or the name of its components when it's not set
+func sourceName(name string, s Source) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return name
+}
+
+// sourceCategory returns the category of the source, or the category of the collector
+func (m *migrator) sourceCategory(s Source) string {
+	if s.Category != "" {
+		return s.Category
+	}
+	return m.props["category"]
+}
+
+// sourceHost returns the host name of the source, or the host name of the collector
+func (m *migrator) sourceHost(s Source) string {
+	if s.HostName != "" {
+		return s.HostName
+	}
+	return m.props["hostName"]
+}
+
+// checkUnsupported reports the settings of the source which are not migrated
+func (m *migrator) checkUnsupported(s Source) {
+	if s.ForceTimeZone || s.TimeZone != "" {
+		m.warnf("The time zone of Source %q is not migrated; the time_zone of the sumologic extension applies to all the sources", s.Name)
+	}
+	if s.CutoffTimestamp != 0 || s.CutoffRelativeTime != "" {
+		m.warnf("The collection cutoff of Source %q is not migrated; the collection starts at the end of the files", s.Name)
+	}
+}
+
+// unsafeNameChars matches the characters which are replaced in the names of the components
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// nameSet holds the names of the components already used
+type nameSet map[string]bool
+
+// unique returns the name, with the unsafe characters replaced, or the fallback when it's empty,
+// suffixed with a number when it's already used
+func (s nameSet) unique(name string, fallback string) string {
+	name = strings.Trim(unsafeNameChars.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		name = fallback
+	}
+	unique := name
+	for n := 2; s[unique]; n++ {
+		unique = fmt.Sprintf("%s_%d", name, n)
+	}
+	s[unique] = true
+	return unique
+}
+
+// sortedMap returns the map with the keys sorted, so the output is stable
+func sortedMap(values map[string]string) yaml.MapSlice {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make(yaml.MapSlice, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, yaml.MapItem{Key: key, Value: values[key]})
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmigrator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMigrate(t *testing.T) {
+	sources, err := ReadSources(path.Join(".", "testdata", "sources.json"))
+	require.NoError(t, err)
+	require.Len(t, sources, 4)
+	props, err := ReadUserProperties(path.Join(".", "testdata", "user.properties"))
+	require.NoError(t, err)
+
+	migration, err := Migrate(sources, props)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`Mask filter "Tokens" of Source "Nginx Access" is not migrated; the PII Masking processor can mask or hash ` +
+			`the matching values, but it replaces the whole match instead of the capture groups`,
+		`The collection cutoff of Source "App" is not migrated; the collection starts at the end of the files`,
+		`Script Source "Disk usage" is not migrated, as scripts are not supported by the collector; ` +
+			`schedule the script to write its output to a file collected with the filelog receiver instead`,
+	}, migration.Warnings)
+
+	out, err := migration.YAML()
+	require.NoError(t, err)
+	expected, err := ioutil.ReadFile(path.Join(".", "testdata", "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(out))
+}
+
+func TestMigrateDirectory(t *testing.T) {
+	sources, err := ReadSources(path.Join(".", "testdata", "sources.d"))
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+
+	migration, err := Migrate(sources, UserProperties{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`Source "Messages" uses the automatic detection of multiline messages, which is not supported; ` +
+			`each line is a separate message, unless multiline.line_start_pattern of receivers::filelog/Messages is set`,
+	}, migration.Warnings)
+
+	receivers := migration.Config[1].Value.(yaml.MapSlice)
+	require.Len(t, receivers, 2)
+	assert.Equal(t, "filelog/Messages", receivers[0].Key)
+	assert.Equal(t, "udplog/Messages_2", receivers[1].Key)
+	assert.Equal(t, "0.0.0.0:514", receivers[1].Value.(yaml.MapSlice)[0].Value)
+}
+
+func TestMigrateNothing(t *testing.T) {
+	sources, err := ReadSources(path.Join(".", "testdata", "unsupported.json"))
+	require.NoError(t, err)
+
+	_, err = Migrate(sources, UserProperties{})
+	assert.EqualError(t, err, "none of the sources can be migrated")
+}
+
+func TestReadSourcesInvalid(t *testing.T) {
+	_, err := ReadSources(path.Join(".", "testdata", "invalid.json"))
+	assert.Error(t, err)
+
+	_, err = ReadSources(path.Join(".", "testdata", "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestCommand(t *testing.T) {
+	cmd := NewCommand()
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	cmd.SetArgs([]string{
+		"--sources", path.Join(".", "testdata", "sources.json"),
+		"--user-properties", path.Join(".", "testdata", "user.properties"),
+	})
+	require.NoError(t, cmd.Execute())
+
+	expected, err := ioutil.ReadFile(path.Join(".", "testdata", "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+	assert.Contains(t, errOut.String(), `WARNING: Script Source "Disk usage" is not migrated`)
+
+	cmd = NewCommand()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{})
+	assert.EqualError(t, cmd.Execute(), `required flag(s) "sources" not set`)
+}
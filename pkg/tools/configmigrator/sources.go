@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmigrator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The source types of the Installed Collector
+const (
+	sourceTypeLocalFile = "LocalFile"
+	sourceTypeSyslog    = "Syslog"
+	sourceTypeScript    = "Script"
+)
+
+// The filter types of the Installed Collector
+const (
+	filterTypeExclude = "Exclude"
+	filterTypeInclude = "Include"
+)
+
+// Source is a source of the Installed Collector, as defined in sources.json. Only the parameters
+// which are migrated, or reported when they can't be, are read.
+type Source struct {
+	SourceType  string            `json:"sourceType"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Category    string            `json:"category"`
+	HostName    string            `json:"hostName"`
+	Fields      map[string]string `json:"fields"`
+	Filters     []Filter          `json:"filters"`
+
+	AutomaticDateParsing *bool  `json:"automaticDateParsing"`
+	ForceTimeZone        bool   `json:"forceTimeZone"`
+	TimeZone             string `json:"timeZone"`
+	CutoffTimestamp      int64  `json:"cutoffTimestamp"`
+	CutoffRelativeTime   string `json:"cutoffRelativeTime"`
+
+	// LocalFile
+	PathExpression             string   `json:"pathExpression"`
+	Denylist                   []string `json:"denylist"`
+	Blacklist                  []string `json:"blacklist"`
+	Encoding                   string   `json:"encoding"`
+	MultilineProcessingEnabled *bool    `json:"multilineProcessingEnabled"`
+	UseAutolineMatching        *bool    `json:"useAutolineMatching"`
+	ManualPrefixRegexp         string   `json:"manualPrefixRegexp"`
+
+	// Syslog
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
+
+	// Script
+	Commands       []string `json:"commands"`
+	Script         string   `json:"script"`
+	File           string   `json:"file"`
+	CronExpression string   `json:"cronExpression"`
+}
+
+// Filter is a processing rule of a source
+type Filter struct {
+	FilterType string `json:"filterType"`
+	Name       string `json:"name"`
+	Regexp     string `json:"regexp"`
+	Mask       string `json:"mask"`
+}
+
+// sourcesFile is the JSON file with either a single source or a list of them
+type sourcesFile struct {
+	Source  *Source  `json:"source"`
+	Sources []Source `json:"sources"`
+}
+
+// ReadSources reads the sources from the JSON file, or from all the JSON files in the directory,
+// the same way the Installed Collector reads the sources and syncSources paths
+func ReadSources(path string) ([]Source, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	var sources []Source
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var parsed sourcesFile
+		if err := json.Unmarshal(content, &parsed); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if parsed.Source != nil {
+			sources = append(sources, *parsed.Source)
+		}
+		sources = append(sources, parsed.Sources...)
+	}
+	return sources, nil
+}
+
+// UserProperties are the settings of the Installed Collector from user.properties
+type UserProperties map[string]string
+
+// ReadUserProperties reads the key=value lines of user.properties
+func ReadUserProperties(path string) (UserProperties, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	props := UserProperties{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		props[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return props, scanner.Err()
+}
+
+// fields returns the collector fields, set as fields=key1=value1,key2=value2
+func (p UserProperties) fields() map[string]string {
+	return parseFields(p["fields"])
+}
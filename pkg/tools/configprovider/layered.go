@@ -0,0 +1,261 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configprovider holds the configuration's parser providers of the collector.
+package configprovider
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configparser"
+	"go.opentelemetry.io/collector/service/parserprovider"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverridesKey is the top level section of the local configuration, which configures the overrides.
+// It is removed from the configuration provided to the collector.
+const OverridesKey = "config_overrides"
+
+const defaultOverridesTimeout = 10 * time.Second
+
+// OverridesConfig configures the documents overriding the local configuration, e.g.
+//
+//	config_overrides:
+//	  sources:
+//	    - url: https://config.example.com/fleet/exporters.yaml
+//	      headers:
+//	        Authorization: Bearer ${CONFIG_TOKEN}
+//	  local_sections: [receivers]
+type OverridesConfig struct {
+	// Sources are the override documents, applied in order, so the later ones take precedence
+	Sources []OverrideSource `mapstructure:"sources"`
+	// LocalSections are the top level sections, in which the local configuration takes precedence,
+	// so the overrides can only add new entries to them. Default: [receivers]
+	LocalSections []string `mapstructure:"local_sections"`
+	// Timeout is the timeout of fetching a single document. Default: 10s
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// OverrideSource is a single override document
+type OverrideSource struct {
+	// URL is the http(s) or file URL of the YAML document, the environment variables are expanded in it
+	URL string `mapstructure:"url"`
+	// Headers are added to the HTTP requests, the environment variables are expanded in their values
+	Headers map[string]string `mapstructure:"headers"`
+	// Optional makes the collector start without the document if it can't be fetched the first time
+	Optional bool `mapstructure:"optional"`
+}
+
+// Layered merges the local configuration provided by base with the override documents, configured
+// in its config_overrides section. The documents are merged recursively: the maps are joined and
+// the other values, including lists, are replaced. When a document can't be fetched, the last
+// fetched version is used.
+type Layered struct {
+	base   parserprovider.ParserProvider
+	client *http.Client
+
+	mu     sync.Mutex
+	logger *zap.Logger
+	// fetched are the last fetched override documents, keyed by URL
+	fetched map[string]map[string]interface{}
+}
+
+var _ parserprovider.ParserProvider = (*Layered)(nil)
+
+// NewLayered returns the provider merging the configuration provided by base with its overrides
+func NewLayered(base parserprovider.ParserProvider) *Layered {
+	return &Layered{
+		base:    base,
+		client:  &http.Client{},
+		logger:  zap.NewNop(),
+		fetched: map[string]map[string]interface{}{},
+	}
+}
+
+// LoggingOption returns the option of the collector logger, which makes the provider log with it
+func (l *Layered) LoggingOption() zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		l.mu.Lock()
+		l.logger = zap.New(core).With(zap.String("component", "config_overrides"))
+		l.mu.Unlock()
+		return core
+	})
+}
+
+// Get returns the local configuration merged with the overrides
+func (l *Layered) Get() (*configparser.Parser, error) {
+	cp, err := l.base.Get()
+	if err != nil {
+		return nil, err
+	}
+	if !cp.IsSet(OverridesKey) {
+		return cp, nil
+	}
+
+	overridesParser, err := cp.Sub(OverridesKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", OverridesKey, err)
+	}
+	cfg := OverridesConfig{
+		LocalSections: []string{"receivers"},
+		Timeout:       defaultOverridesTimeout,
+	}
+	if err = overridesParser.UnmarshalExact(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", OverridesKey, err)
+	}
+
+	merged := cp.ToStringMap()
+	delete(merged, OverridesKey)
+	localSections := map[string]bool{}
+	for _, section := range cfg.LocalSections {
+		localSections[section] = true
+	}
+
+	for _, source := range cfg.Sources {
+		document, err := l.fetch(source, cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		if document == nil {
+			continue
+		}
+		for section, value := range document {
+			if section == OverridesKey {
+				continue
+			}
+			if localSections[section] {
+				merged[section] = mergeValues(value, merged[section])
+			} else {
+				merged[section] = mergeValues(merged[section], value)
+			}
+		}
+	}
+	return configparser.NewParserFromStringMap(merged), nil
+}
+
+// fetch returns the override document, the last fetched one if it can't be fetched now,
+// or nil if it is optional and was never fetched
+func (l *Layered) fetch(source OverrideSource, timeout time.Duration) (map[string]interface{}, error) {
+	document, err := l.fetchDocument(source, timeout)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err == nil {
+		l.fetched[source.URL] = document
+		return copyMap(document), nil
+	}
+
+	if last, ok := l.fetched[source.URL]; ok {
+		l.logger.Warn("Failed to fetch the configuration override, using the last fetched version",
+			zap.String("url", source.URL), zap.Error(err))
+		return copyMap(last), nil
+	}
+	if source.Optional {
+		l.logger.Warn("Failed to fetch the optional configuration override, skipping it",
+			zap.String("url", source.URL), zap.Error(err))
+		return nil, nil
+	}
+	return nil, fmt.Errorf("failed to fetch the configuration override %q: %w", source.URL, err)
+}
+
+func (l *Layered) fetchDocument(source OverrideSource, timeout time.Duration) (map[string]interface{}, error) {
+	location := os.ExpandEnv(source.URL)
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	switch u.Scheme {
+	case "file":
+		if content, err = ioutil.ReadFile(u.Path); err != nil {
+			return nil, err
+		}
+	case "http", "https":
+		if content, err = l.get(location, source.Headers, timeout); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme %q, use http, https or file", u.Scheme)
+	}
+
+	cp, err := configparser.NewParserFromBuffer(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML document: %w", err)
+	}
+	return cp.ToStringMap(), nil
+}
+
+func (l *Layered) get(location string, headers map[string]string, timeout time.Duration) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+
+	client := *l.client
+	client.Timeout = timeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// mergeValues merges the override into the value: the maps are merged recursively and the other values
+// are replaced. A nil override, e.g. a component without settings, doesn't replace the value.
+func mergeValues(value interface{}, override interface{}) interface{} {
+	if override == nil {
+		return value
+	}
+	overrideMap, ok := override.(map[string]interface{})
+	if !ok {
+		return override
+	}
+	valueMap, ok := value.(map[string]interface{})
+	if !ok {
+		return overrideMap
+	}
+	for key, overrideValue := range overrideMap {
+		valueMap[key] = mergeValues(valueMap[key], overrideValue)
+	}
+	return valueMap
+}
+
+// copyMap copies the maps recursively, so the cached documents are not modified by merging
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		if valueMap, ok := value.(map[string]interface{}); ok {
+			value = copyMap(valueMap)
+		}
+		result[key] = value
+	}
+	return result
+}
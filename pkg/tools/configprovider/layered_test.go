@@ -0,0 +1,232 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configparser"
+)
+
+const testConfig = `
+receivers:
+  nop:
+exporters:
+  nop:
+service:
+  pipelines:
+    logs:
+      receivers: [nop]
+      exporters: [nop]
+`
+
+// testProvider provides the given configuration
+type testProvider struct {
+	mu      sync.Mutex
+	content string
+}
+
+func (p *testProvider) Get() (*configparser.Parser, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return configparser.NewParserFromBuffer(bytes.NewBufferString(p.content))
+}
+
+const testLocalConfig = `
+config_overrides:
+  sources:
+    - url: %s
+      headers:
+        Authorization: Bearer ${TEST_CONFIG_TOKEN}
+receivers:
+  filelog:
+    include: [/var/log/app.log]
+processors:
+  batch:
+    timeout: 1s
+exporters:
+  sumologic:
+    endpoint: http://local
+service:
+  pipelines:
+    logs:
+      receivers: [filelog]
+      processors: [batch]
+      exporters: [sumologic]
+`
+
+const testOverride = `
+receivers:
+  filelog:
+    include: [/var/log/*.log]
+    start_at: beginning
+processors:
+  batch:
+    send_batch_size: 100
+  memory_limiter:
+    limit_mib: 512
+exporters:
+  sumologic:
+    endpoint: http://remote
+service:
+  pipelines:
+    logs:
+      processors: [memory_limiter, batch]
+`
+
+// testServer serves the override document which can be changed in the tests
+type testServer struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	content       string
+	status        int
+	authorization string
+}
+
+func newTestServer(t *testing.T, content string) *testServer {
+	s := &testServer{content: content, status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.authorization = req.Header.Get("Authorization")
+		w.WriteHeader(s.status)
+		_, _ = w.Write([]byte(s.content))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func TestLayered(t *testing.T) {
+	require.NoError(t, os.Setenv("TEST_CONFIG_TOKEN", "token"))
+	defer os.Unsetenv("TEST_CONFIG_TOKEN")
+
+	server := newTestServer(t, testOverride)
+	l := NewLayered(&testProvider{content: fmt.Sprintf(testLocalConfig, server.URL)})
+
+	cp, err := l.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token", server.authorization)
+
+	assert.False(t, cp.IsSet(OverridesKey))
+	// the local receivers take precedence, but can be extended
+	assert.Equal(t, []interface{}{"/var/log/app.log"}, cp.Get("receivers::filelog::include"))
+	assert.Equal(t, "beginning", cp.Get("receivers::filelog::start_at"))
+	// the maps are merged
+	assert.Equal(t, "1s", cp.Get("processors::batch::timeout"))
+	assert.Equal(t, 100, cp.Get("processors::batch::send_batch_size"))
+	assert.Equal(t, 512, cp.Get("processors::memory_limiter::limit_mib"))
+	assert.Equal(t, "http://remote", cp.Get("exporters::sumologic::endpoint"))
+	// the lists are replaced
+	assert.Equal(t, []interface{}{"memory_limiter", "batch"}, cp.Get("service::pipelines::logs::processors"))
+	assert.Equal(t, []interface{}{"filelog"}, cp.Get("service::pipelines::logs::receivers"))
+
+	// the last fetched document is used when the server fails
+	server.setStatus(http.StatusInternalServerError)
+	cp, err = l.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "http://remote", cp.Get("exporters::sumologic::endpoint"))
+}
+
+func TestLayeredPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+	require.NoError(t, ioutil.WriteFile(first, []byte("exporters:\n  sumologic:\n    endpoint: http://first\n    compress_encoding: gzip\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(second, []byte("exporters:\n  sumologic:\n    endpoint: http://second\n"), 0600))
+
+	l := NewLayered(&testProvider{content: fmt.Sprintf(`
+config_overrides:
+  sources:
+    - url: file://%s
+    - url: file://%s
+  local_sections: []
+receivers:
+  filelog:
+    include: [/var/log/app.log]
+exporters:
+  sumologic:
+    endpoint: http://local
+`, first, second)})
+
+	cp, err := l.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "http://second", cp.Get("exporters::sumologic::endpoint"))
+	assert.Equal(t, "gzip", cp.Get("exporters::sumologic::compress_encoding"))
+}
+
+func TestLayeredFetchFailure(t *testing.T) {
+	server := newTestServer(t, testOverride)
+	server.setStatus(http.StatusNotFound)
+
+	l := NewLayered(&testProvider{content: fmt.Sprintf(testLocalConfig, server.URL)})
+	_, err := l.Get()
+	assert.EqualError(t, err, fmt.Sprintf(`failed to fetch the configuration override %q: unexpected status: 404 Not Found`, server.URL))
+
+	optional := strings.Replace(testLocalConfig, "headers:", "optional: true\n      headers:", 1)
+	l = NewLayered(&testProvider{content: fmt.Sprintf(optional, server.URL)})
+	cp, err := l.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "http://local", cp.Get("exporters::sumologic::endpoint"))
+}
+
+func TestLayeredInvalidConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "unknown key",
+			config: "config_overrides:\n  source: []\n",
+			err:    "invalid config_overrides",
+		},
+		{
+			name:   "unsupported scheme",
+			config: "config_overrides:\n  sources:\n    - url: ftp://example.com/config.yaml\n",
+			err:    `unsupported URL scheme "ftp"`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewLayered(&testProvider{content: tc.config}).Get()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestLayeredWithoutOverrides(t *testing.T) {
+	cp, err := NewLayered(&testProvider{content: testConfig}).Get()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"nop"}, cp.Get("service::pipelines::logs::receivers"))
+}
@@ -0,0 +1,222 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configparser"
+	"go.opentelemetry.io/collector/service/parserprovider"
+)
+
+const (
+	// MemoryLimitsKey is the top level section of the configuration, which enables sizing the memory limiter
+	// and the ballast from the memory limit. It is removed from the configuration provided to the collector.
+	MemoryLimitsKey = "memory_limits"
+
+	// memoryLimiter is the type and the ID of the memory limiter processor added to the pipelines
+	memoryLimiter = "memory_limiter"
+	// memoryBallast is the type and the ID of the ballast extension added to the service
+	memoryBallast = "memory_ballast"
+
+	mib = 1024 * 1024
+
+	defaultMemoryLimitPercentage      = 80
+	defaultMemorySpikeLimitPercentage = 20
+	defaultMemoryBallastPercentage    = 33
+	defaultMemoryCheckInterval        = time.Second
+)
+
+// MemoryLimitsConfig configures sizing the memory limiter and the ballast from the memory limit
+type MemoryLimitsConfig struct {
+	// Enabled enables the sizing
+	Enabled bool `mapstructure:"enabled"`
+	// LimitMiB is the memory limit, detected from the cgroup of the collector or the host memory when 0
+	LimitMiB uint64 `mapstructure:"limit_mib"`
+	// LimitPercentage is the limit of the memory limiter, as the percentage of the memory limit
+	LimitPercentage uint64 `mapstructure:"limit_percentage"`
+	// SpikeLimitPercentage is the spike limit of the memory limiter, as the percentage of the memory limit
+	SpikeLimitPercentage uint64 `mapstructure:"spike_limit_percentage"`
+	// BallastPercentage is the size of the ballast, as the percentage of the memory limit, 0 disables the ballast
+	BallastPercentage uint64 `mapstructure:"ballast_percentage"`
+	// CheckInterval is the interval of checking the memory usage by the memory limiter
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// AddMemoryLimiter adds the memory limiter as the first processor of the pipelines without one
+	AddMemoryLimiter bool `mapstructure:"add_memory_limiter"`
+}
+
+func (cfg *MemoryLimitsConfig) validate() error {
+	if cfg.LimitPercentage == 0 || cfg.LimitPercentage > 100 {
+		return fmt.Errorf("%s::limit_percentage must be between 1 and 100, got %d", MemoryLimitsKey, cfg.LimitPercentage)
+	}
+	if cfg.SpikeLimitPercentage >= cfg.LimitPercentage {
+		return fmt.Errorf("%s::spike_limit_percentage must be lower than limit_percentage, got %d",
+			MemoryLimitsKey, cfg.SpikeLimitPercentage)
+	}
+	if cfg.BallastPercentage >= 100 {
+		return fmt.Errorf("%s::ballast_percentage must be lower than 100, got %d", MemoryLimitsKey, cfg.BallastPercentage)
+	}
+	if cfg.CheckInterval <= 0 {
+		return fmt.Errorf("%s::check_interval must be positive, got %s", MemoryLimitsKey, cfg.CheckInterval)
+	}
+	return nil
+}
+
+// MemoryLimits sizes the memory limiter processors and the ballast extension from the memory limit
+// of the collector, configured in its memory_limits section. The limit is read from the cgroup v1 or v2
+// of the collector, e.g. the memory limit of the Kubernetes container, or the host memory when there's
+// no limit. The settings present in the configuration are not changed, except for the percentages
+// of the memory limiter and the ballast, which are converted to the sizes, as the collector core
+// reads only the cgroup v1 limits.
+type MemoryLimits struct {
+	base parserprovider.ParserProvider
+	// detect returns the memory limit in bytes
+	detect func() (uint64, error)
+}
+
+var _ parserprovider.ParserProvider = (*MemoryLimits)(nil)
+
+// NewMemoryLimits returns the provider sizing the memory limiter and the ballast in the configuration provided by base
+func NewMemoryLimits(base parserprovider.ParserProvider) *MemoryLimits {
+	return &MemoryLimits{
+		base:   base,
+		detect: detectMemoryLimit,
+	}
+}
+
+// Get returns the configuration with the memory limiter and the ballast sized from the memory limit
+func (m *MemoryLimits) Get() (*configparser.Parser, error) {
+	cp, err := m.base.Get()
+	if err != nil || !cp.IsSet(MemoryLimitsKey) {
+		return cp, err
+	}
+
+	sub, err := cp.Sub(MemoryLimitsKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", MemoryLimitsKey, err)
+	}
+	cfg := MemoryLimitsConfig{
+		LimitPercentage:      defaultMemoryLimitPercentage,
+		SpikeLimitPercentage: defaultMemorySpikeLimitPercentage,
+		BallastPercentage:    defaultMemoryBallastPercentage,
+		CheckInterval:        defaultMemoryCheckInterval,
+		AddMemoryLimiter:     true,
+	}
+	if err = sub.UnmarshalExact(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", MemoryLimitsKey, err)
+	}
+	if err = cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	stringMap := cp.ToStringMap()
+	delete(stringMap, MemoryLimitsKey)
+	cp = configparser.NewParserFromStringMap(stringMap)
+	if !cfg.Enabled {
+		return cp, nil
+	}
+
+	limit := cfg.LimitMiB
+	if limit == 0 {
+		bytes, err := m.detect()
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect the memory limit, set %s::limit_mib: %w", MemoryLimitsKey, err)
+		}
+		limit = bytes / mib
+	}
+	percentage := func(p uint64) uint64 {
+		return limit * p / 100
+	}
+
+	if cfg.AddMemoryLimiter {
+		if err := addMemoryLimiter(cp); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range componentIDs(cp, "processors") {
+		if componentType(id) != memoryLimiter {
+			continue
+		}
+		key := "processors::" + id
+		limitPercentage := cfg.LimitPercentage
+		if p, ok := cp.Get(key + "::limit_percentage").(int); ok && p > 0 {
+			limitPercentage = uint64(p)
+		}
+		spikeLimitPercentage := cfg.SpikeLimitPercentage
+		if p, ok := cp.Get(key + "::spike_limit_percentage").(int); ok && p > 0 {
+			spikeLimitPercentage = uint64(p)
+		}
+		if cp.Get(key+"::limit_mib") == nil {
+			cp.Set(key+"::limit_mib", percentage(limitPercentage))
+			setDefault(cp, key+"::spike_limit_mib", percentage(spikeLimitPercentage))
+		}
+		setDefault(cp, key+"::check_interval", cfg.CheckInterval.String())
+	}
+
+	if cfg.BallastPercentage == 0 {
+		return cp, nil
+	}
+	ballasts := 0
+	for _, id := range componentIDs(cp, "extensions") {
+		if componentType(id) == memoryBallast {
+			ballasts++
+		}
+	}
+	if ballasts == 0 {
+		extensions, ok := cp.Get("service::extensions").([]interface{})
+		if !ok && cp.IsSet("service::extensions") {
+			return nil, errors.New("service::extensions must be a list")
+		}
+		cp.Set("extensions::"+memoryBallast, map[string]interface{}{})
+		cp.Set("service::extensions", append(extensions, memoryBallast))
+	}
+	for _, id := range componentIDs(cp, "extensions") {
+		if componentType(id) != memoryBallast {
+			continue
+		}
+		key := "extensions::" + id
+		ballastPercentage := cfg.BallastPercentage
+		if p, ok := cp.Get(key + "::size_in_percentage").(int); ok && p > 0 {
+			ballastPercentage = uint64(p)
+		}
+		setDefault(cp, key+"::size_mib", percentage(ballastPercentage))
+	}
+	return cp, nil
+}
+
+// addMemoryLimiter adds the memory limiter as the first processor of the pipelines without one
+func addMemoryLimiter(cp *configparser.Parser) error {
+	for _, pipeline := range componentIDs(cp, "service::pipelines") {
+		key := "service::pipelines::" + pipeline + "::processors"
+		processors, ok := cp.Get(key).([]interface{})
+		if !ok && cp.IsSet(key) {
+			return fmt.Errorf("%s must be a list", key)
+		}
+		hasLimiter := false
+		for _, processor := range processors {
+			if id, ok := processor.(string); ok && componentType(id) == memoryLimiter {
+				hasLimiter = true
+			}
+		}
+		if hasLimiter {
+			continue
+		}
+		setDefault(cp, "processors::"+memoryLimiter, map[string]interface{}{})
+		cp.Set(key, append([]interface{}{memoryLimiter}, processors...))
+	}
+	return nil
+}
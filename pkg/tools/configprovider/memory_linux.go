@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package configprovider
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// memoryFiles are the files the memory limit is read from
+type memoryFiles struct {
+	// cgroups lists the cgroups of the process
+	cgroups string
+	// cgroupRoot is the mount point of the cgroup filesystem
+	cgroupRoot string
+	// meminfo holds the host memory
+	meminfo string
+}
+
+var hostMemoryFiles = memoryFiles{
+	cgroups:    "/proc/self/cgroup",
+	cgroupRoot: "/sys/fs/cgroup",
+	meminfo:    "/proc/meminfo",
+}
+
+// detectMemoryLimit returns the memory limit of the collector in bytes
+func detectMemoryLimit() (uint64, error) {
+	return hostMemoryFiles.limit()
+}
+
+// limit returns the lowest of the host memory and the memory limits of the cgroup v2 and v1 of the process
+// and their parents; the cgroup v1 reports no limit as a huge value, so the host memory is used then
+func (f memoryFiles) limit() (uint64, error) {
+	limit, err := f.memTotal()
+	if err != nil {
+		return 0, err
+	}
+
+	content, err := ioutil.ReadFile(f.cgroups)
+	if errors.Is(err, os.ErrNotExist) {
+		return limit, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		var dir, file string
+		switch {
+		case fields[0] == "0" && fields[1] == "":
+			dir, file = f.cgroupRoot, "memory.max"
+		case containsController(fields[1], "memory"):
+			dir, file = filepath.Join(f.cgroupRoot, "memory"), "memory.limit_in_bytes"
+		default:
+			continue
+		}
+		cgroupLimit, err := cgroupMemoryLimit(dir, path.Clean(fields[2]), file)
+		if err != nil {
+			return 0, err
+		}
+		if cgroupLimit < limit {
+			limit = cgroupLimit
+		}
+	}
+	return limit, nil
+}
+
+// cgroupMemoryLimit returns the lowest memory limit of the cgroup and its parents present in the hierarchy
+// mounted in dir; the cgroup path may be missing, when the container has its own cgroup namespace
+func cgroupMemoryLimit(dir string, cgroup string, file string) (uint64, error) {
+	limit := uint64(0)
+	for {
+		content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(cgroup), file))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return 0, err
+		default:
+			value := strings.TrimSpace(string(content))
+			if value != "max" {
+				cgroupLimit, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					return 0, fmt.Errorf("invalid memory limit in %s: %w", file, err)
+				}
+				if limit == 0 || cgroupLimit < limit {
+					limit = cgroupLimit
+				}
+			}
+		}
+		if cgroup == "/" {
+			break
+		}
+		cgroup = path.Dir(cgroup)
+	}
+	if limit == 0 {
+		return ^uint64(0), nil
+	}
+	return limit, nil
+}
+
+func containsController(controllers string, controller string) bool {
+	for _, c := range strings.Split(controllers, ",") {
+		if c == controller {
+			return true
+		}
+	}
+	return false
+}
+
+// memTotal returns the host memory in bytes
+func (f memoryFiles) memTotal() (uint64, error) {
+	content, err := ioutil.ReadFile(f.meminfo)
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		// MemTotal:       16318412 kB
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "MemTotal:" && fields[2] == "kB" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid MemTotal in %s: %w", f.meminfo, err)
+			}
+			return kb * 1024, nil
+		}
+	}
+	return 0, fmt.Errorf("MemTotal not found in %s", f.meminfo)
+}
@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package configprovider
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMemoryFiles writes the files relative to a temporary directory and returns the memory files in it
+func newTestMemoryFiles(t *testing.T, cgroups string, files map[string]string) memoryFiles {
+	dir := t.TempDir()
+	f := memoryFiles{
+		cgroups:    filepath.Join(dir, "cgroup"),
+		cgroupRoot: filepath.Join(dir, "fs"),
+		meminfo:    filepath.Join(dir, "meminfo"),
+	}
+	files["meminfo"] = "MemTotal:        4194304 kB\nMemFree:         1048576 kB\n"
+	if cgroups != "" {
+		files["cgroup"] = cgroups
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	}
+	return f
+}
+
+func TestMemoryFilesLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		cgroups string
+		files   map[string]string
+		limit   uint64
+	}{
+		{
+			name:  "no cgroups",
+			limit: 4096 * mib,
+		},
+		{
+			name:    "cgroup v2",
+			cgroups: "0::/kubepods/pod1/container\n",
+			files: map[string]string{
+				"fs/kubepods/pod1/container/memory.max": "536870912\n",
+				"fs/kubepods/pod1/memory.max":           "max\n",
+			},
+			limit: 512 * mib,
+		},
+		{
+			name:    "cgroup v2 parent",
+			cgroups: "0::/system.slice/otelcol-sumo.service\n",
+			files: map[string]string{
+				"fs/system.slice/otelcol-sumo.service/memory.max": "max\n",
+				"fs/system.slice/memory.max":                      "1073741824\n",
+			},
+			limit: 1024 * mib,
+		},
+		{
+			name:    "cgroup v2 namespace",
+			cgroups: "0::/\n",
+			files: map[string]string{
+				"fs/memory.max": "268435456\n",
+			},
+			limit: 256 * mib,
+		},
+		{
+			name:    "cgroup v2 unlimited",
+			cgroups: "0::/\n",
+			files: map[string]string{
+				"fs/memory.max": "max\n",
+			},
+			limit: 4096 * mib,
+		},
+		{
+			name:    "cgroup v1",
+			cgroups: "5:cpu,cpuacct:/docker/abc\n4:memory:/docker/abc\n0::/\n",
+			files: map[string]string{
+				"fs/memory/docker/abc/memory.limit_in_bytes": "805306368\n",
+			},
+			limit: 768 * mib,
+		},
+		{
+			name:    "cgroup v1 unlimited",
+			cgroups: "4:memory:/\n",
+			files: map[string]string{
+				"fs/memory/memory.limit_in_bytes": "9223372036854771712\n",
+			},
+			limit: 4096 * mib,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.files == nil {
+				tt.files = map[string]string{}
+			}
+			limit, err := newTestMemoryFiles(t, tt.cgroups, tt.files).limit()
+			require.NoError(t, err)
+			assert.Equal(t, tt.limit, limit)
+		})
+	}
+}
+
+func TestMemoryFilesLimitInvalid(t *testing.T) {
+	_, err := newTestMemoryFiles(t, "0::/\n", map[string]string{"fs/memory.max": "1G"}).limit()
+	assert.Error(t, err)
+
+	f := newTestMemoryFiles(t, "", map[string]string{})
+	f.meminfo = f.cgroups
+	_, err = f.limit()
+	assert.Error(t, err)
+}
@@ -0,0 +1,219 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMemoryLimitsConfig = `
+memory_limits:
+  enabled: true
+receivers:
+  filelog:
+    include: [/var/log/*.log]
+processors:
+  batch:
+exporters:
+  sumologic:
+service:
+  extensions: [health_check]
+  pipelines:
+    logs:
+      receivers: [filelog]
+      processors: [batch]
+      exporters: [sumologic]
+`
+
+func newTestMemoryLimits(content string, limit uint64) *MemoryLimits {
+	m := NewMemoryLimits(&testProvider{content: content})
+	m.detect = func() (uint64, error) {
+		return limit, nil
+	}
+	return m
+}
+
+func TestMemoryLimits(t *testing.T) {
+	cp, err := newTestMemoryLimits(testMemoryLimitsConfig, 2048*mib).Get()
+	require.NoError(t, err)
+
+	assert.False(t, cp.IsSet(MemoryLimitsKey))
+	assert.Equal(t, map[string]interface{}{
+		"limit_mib":       uint64(1638),
+		"spike_limit_mib": uint64(409),
+		"check_interval":  "1s",
+	}, cp.Get("processors::memory_limiter"))
+	assert.Equal(t, []interface{}{"memory_limiter", "batch"}, cp.Get("service::pipelines::logs::processors"))
+	assert.Equal(t, uint64(675), cp.Get("extensions::memory_ballast::size_mib"))
+	assert.Equal(t, []interface{}{"health_check", "memory_ballast"}, cp.Get("service::extensions"))
+}
+
+func TestMemoryLimitsSettings(t *testing.T) {
+	cp, err := newTestMemoryLimits(`
+memory_limits:
+  enabled: true
+  limit_mib: 1000
+  limit_percentage: 70
+  spike_limit_percentage: 10
+  ballast_percentage: 40
+  check_interval: 5s
+  add_memory_limiter: false
+processors:
+  memory_limiter:
+  memory_limiter/percentage:
+    limit_percentage: 50
+    spike_limit_percentage: 5
+  memory_limiter/static:
+    limit_mib: 200
+    check_interval: 2s
+extensions:
+  memory_ballast:
+    size_in_percentage: 20
+service:
+  extensions: [memory_ballast]
+  pipelines:
+    logs:
+      receivers: [filelog]
+      exporters: [sumologic]
+`, 2048*mib).Get()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"limit_mib":       uint64(700),
+		"spike_limit_mib": uint64(100),
+		"check_interval":  "5s",
+	}, cp.Get("processors::memory_limiter"))
+	// the percentages are converted to the sizes
+	assert.Equal(t, uint64(500), cp.Get("processors::memory_limiter/percentage::limit_mib"))
+	assert.Equal(t, uint64(50), cp.Get("processors::memory_limiter/percentage::spike_limit_mib"))
+	// the configured sizes are kept
+	assert.Equal(t, map[string]interface{}{
+		"limit_mib":      200,
+		"check_interval": "2s",
+	}, cp.Get("processors::memory_limiter/static"))
+	assert.Nil(t, cp.Get("service::pipelines::logs::processors"))
+	assert.Equal(t, uint64(200), cp.Get("extensions::memory_ballast::size_mib"))
+	assert.Equal(t, []interface{}{"memory_ballast"}, cp.Get("service::extensions"))
+}
+
+func TestMemoryLimitsPipelineWithLimiter(t *testing.T) {
+	cp, err := newTestMemoryLimits(`
+memory_limits:
+  enabled: true
+  ballast_percentage: 0
+processors:
+  memory_limiter/logs:
+    check_interval: 2s
+service:
+  pipelines:
+    logs:
+      processors: [batch, memory_limiter/logs]
+    metrics:
+      exporters: [sumologic]
+`, 1024*mib).Get()
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{"batch", "memory_limiter/logs"}, cp.Get("service::pipelines::logs::processors"))
+	assert.Equal(t, []interface{}{"memory_limiter"}, cp.Get("service::pipelines::metrics::processors"))
+	assert.Equal(t, uint64(819), cp.Get("processors::memory_limiter/logs::limit_mib"))
+	assert.Equal(t, "2s", cp.Get("processors::memory_limiter/logs::check_interval"))
+	assert.Equal(t, uint64(819), cp.Get("processors::memory_limiter::limit_mib"))
+	assert.False(t, cp.IsSet("extensions::memory_ballast"))
+	assert.False(t, cp.IsSet("service::extensions"))
+}
+
+func TestMemoryLimitsDisabled(t *testing.T) {
+	cp, err := newTestMemoryLimits(`
+memory_limits:
+  enabled: false
+service:
+  pipelines:
+    logs:
+      receivers: [filelog]
+`, 1024*mib).Get()
+	require.NoError(t, err)
+
+	assert.False(t, cp.IsSet(MemoryLimitsKey))
+	assert.False(t, cp.IsSet("processors"))
+	assert.False(t, cp.IsSet("extensions"))
+
+	cp, err = newTestMemoryLimits(testSelfMonitoringConfig, 1024*mib).Get()
+	require.NoError(t, err)
+	assert.False(t, cp.IsSet("processors::memory_limiter"))
+}
+
+func TestMemoryLimitsInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "unknown setting",
+			config: "memory_limits:\n  limit: 100",
+			err:    "invalid memory_limits",
+		},
+		{
+			name:   "limit percentage",
+			config: "memory_limits:\n  limit_percentage: 120",
+			err:    "memory_limits::limit_percentage must be between 1 and 100, got 120",
+		},
+		{
+			name:   "spike limit percentage",
+			config: "memory_limits:\n  spike_limit_percentage: 80",
+			err:    "memory_limits::spike_limit_percentage must be lower than limit_percentage, got 80",
+		},
+		{
+			name:   "ballast percentage",
+			config: "memory_limits:\n  ballast_percentage: 100",
+			err:    "memory_limits::ballast_percentage must be lower than 100, got 100",
+		},
+		{
+			name:   "check interval",
+			config: "memory_limits:\n  check_interval: 0s",
+			err:    "memory_limits::check_interval must be positive, got 0s",
+		},
+		{
+			name:   "extensions",
+			config: "memory_limits:\n  enabled: true\nservice:\n  extensions: health_check",
+			err:    "service::extensions must be a list",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestMemoryLimits(tt.config, 1024*mib).Get()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestMemoryLimitsDetectionError(t *testing.T) {
+	m := NewMemoryLimits(&testProvider{content: "memory_limits:\n  enabled: true"})
+	m.detect = func() (uint64, error) {
+		return 0, errors.New("no meminfo")
+	}
+	_, err := m.Get()
+	assert.EqualError(t, err, "failed to detect the memory limit, set memory_limits::limit_mib: no meminfo")
+
+	m.base = &testProvider{content: "memory_limits:\n  enabled: true\n  limit_mib: 512"}
+	cp, err := m.Get()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(168), cp.Get("extensions::memory_ballast::size_mib"))
+}
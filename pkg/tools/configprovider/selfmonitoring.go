@@ -0,0 +1,196 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configparser"
+	"go.opentelemetry.io/collector/service/parserprovider"
+)
+
+const (
+	// SelfMonitoringKey is the top level section of the configuration, which enables the self-monitoring.
+	// It is removed from the configuration provided to the collector.
+	SelfMonitoringKey = "self_monitoring"
+
+	// selfMonitoringName is the name of the components and pipelines added by the self-monitoring
+	selfMonitoringName = "self_monitoring"
+
+	defaultSelfMonitoringSourceCategory  = "otelcol-sumo/self-monitoring"
+	defaultSelfMonitoringMetricsEndpoint = "localhost:8888"
+	defaultSelfMonitoringScrapeInterval  = time.Minute
+	defaultSelfMonitoringLogLevel        = "info"
+)
+
+// SelfMonitoringConfig configures the pipelines sending the metrics and logs of the collector itself
+type SelfMonitoringConfig struct {
+	// Enabled enables the self-monitoring
+	Enabled bool `mapstructure:"enabled"`
+	// Exporter is the ID of the Sumo Logic exporter the data is sent with, the only one configured when empty
+	Exporter string `mapstructure:"exporter"`
+	// SourceCategory is the source category of the data
+	SourceCategory string `mapstructure:"source_category"`
+	// MetricsEndpoint is the address the collector serves its metrics at, set with the --metrics-addr flag
+	MetricsEndpoint string `mapstructure:"metrics_endpoint"`
+	// ScrapeInterval is the interval of scraping the collector metrics
+	ScrapeInterval time.Duration `mapstructure:"scrape_interval"`
+	// LogLevel is the lowest level of the sent log entries
+	LogLevel string `mapstructure:"log_level"`
+}
+
+// SelfMonitoring adds the pipelines sending the metrics and logs of the collector, configured
+// in its self_monitoring section, with a copy of the Sumo Logic exporter setting the source category:
+//
+//	receivers:
+//	  prometheus/self_monitoring:        # scraping the metrics endpoint
+//	  collector_logs/self_monitoring:
+//	processors:
+//	  resourcedetection/self_monitoring: # setting host.name
+//	exporters:
+//	  sumologic/self_monitoring:         # the exporter with the source category
+//	service:
+//	  pipelines:
+//	    metrics/self_monitoring:
+//	    logs/self_monitoring:
+type SelfMonitoring struct {
+	base parserprovider.ParserProvider
+}
+
+var _ parserprovider.ParserProvider = (*SelfMonitoring)(nil)
+
+// NewSelfMonitoring returns the provider adding the self-monitoring pipelines to the configuration provided by base
+func NewSelfMonitoring(base parserprovider.ParserProvider) *SelfMonitoring {
+	return &SelfMonitoring{base: base}
+}
+
+// Get returns the configuration with the self-monitoring pipelines
+func (s *SelfMonitoring) Get() (*configparser.Parser, error) {
+	cp, err := s.base.Get()
+	if err != nil || !cp.IsSet(SelfMonitoringKey) {
+		return cp, err
+	}
+
+	sub, err := cp.Sub(SelfMonitoringKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", SelfMonitoringKey, err)
+	}
+	cfg := SelfMonitoringConfig{
+		SourceCategory:  defaultSelfMonitoringSourceCategory,
+		MetricsEndpoint: defaultSelfMonitoringMetricsEndpoint,
+		ScrapeInterval:  defaultSelfMonitoringScrapeInterval,
+		LogLevel:        defaultSelfMonitoringLogLevel,
+	}
+	if err = sub.UnmarshalExact(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", SelfMonitoringKey, err)
+	}
+
+	stringMap := cp.ToStringMap()
+	delete(stringMap, SelfMonitoringKey)
+	cp = configparser.NewParserFromStringMap(stringMap)
+	if !cfg.Enabled {
+		return cp, nil
+	}
+
+	exporter, err := selfMonitoringExporter(cp, cfg.Exporter)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		metricsReceiver = "prometheus/" + selfMonitoringName
+		logsReceiver    = "collector_logs/" + selfMonitoringName
+		processor       = "resourcedetection/" + selfMonitoringName
+		sumologic       = "sumologic/" + selfMonitoringName
+		pipelines       = map[string]string{
+			"metrics/" + selfMonitoringName: metricsReceiver,
+			"logs/" + selfMonitoringName:    logsReceiver,
+		}
+	)
+	added := []string{"receivers::" + metricsReceiver, "receivers::" + logsReceiver,
+		"processors::" + processor, "exporters::" + sumologic}
+	for pipeline := range pipelines {
+		added = append(added, "service::pipelines::"+pipeline)
+	}
+	for _, key := range added {
+		if cp.IsSet(key) {
+			return nil, fmt.Errorf("%s is added by %s, it must not be configured", key, SelfMonitoringKey)
+		}
+	}
+
+	exporterConfig, _ := cp.Get("exporters::" + exporter).(map[string]interface{})
+	if exporterConfig == nil {
+		exporterConfig = map[string]interface{}{}
+	}
+	exporterConfig["source_category"] = cfg.SourceCategory
+
+	cp.Set("receivers::"+metricsReceiver, map[string]interface{}{
+		"config": map[string]interface{}{
+			"scrape_configs": []interface{}{
+				map[string]interface{}{
+					"job_name":        "otelcol-sumo",
+					"scrape_interval": cfg.ScrapeInterval.String(),
+					"static_configs": []interface{}{
+						map[string]interface{}{"targets": []interface{}{cfg.MetricsEndpoint}},
+					},
+				},
+			},
+		},
+	})
+	cp.Set("receivers::"+logsReceiver, map[string]interface{}{
+		"level": cfg.LogLevel,
+	})
+	cp.Set("processors::"+processor, map[string]interface{}{
+		"detectors": []interface{}{"system"},
+		"override":  true,
+	})
+	cp.Set("exporters::"+sumologic, exporterConfig)
+	for pipeline, receiver := range pipelines {
+		cp.Set("service::pipelines::"+pipeline, map[string]interface{}{
+			"receivers":  []interface{}{receiver},
+			"processors": []interface{}{processor},
+			"exporters":  []interface{}{sumologic},
+		})
+	}
+	return cp, nil
+}
+
+// selfMonitoringExporter returns the ID of the Sumo Logic exporter the self-monitoring data is sent with
+func selfMonitoringExporter(cp *configparser.Parser, id string) (string, error) {
+	if id != "" {
+		if componentType(id) != "sumologic" {
+			return "", fmt.Errorf("%s::exporter must be a sumologic exporter, got %q", SelfMonitoringKey, id)
+		}
+		if !cp.IsSet("exporters::" + id) {
+			return "", fmt.Errorf("%s::exporter %q is not configured", SelfMonitoringKey, id)
+		}
+		return id, nil
+	}
+
+	var exporters []string
+	for _, id := range componentIDs(cp, "exporters") {
+		if componentType(id) == "sumologic" {
+			exporters = append(exporters, id)
+		}
+	}
+	if len(exporters) != 1 {
+		sort.Strings(exporters)
+		return "", fmt.Errorf("%s::exporter must be set when there is not exactly one sumologic exporter, got %v",
+			SelfMonitoringKey, exporters)
+	}
+	return exporters[0], nil
+}
@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSelfMonitoringConfig = `
+self_monitoring:
+  enabled: true
+receivers:
+  filelog:
+    include: [/var/log/*.log]
+exporters:
+  sumologic:
+    endpoint: http://local
+    log_format: otlp
+service:
+  pipelines:
+    logs:
+      receivers: [filelog]
+      exporters: [sumologic]
+`
+
+func TestSelfMonitoring(t *testing.T) {
+	cp, err := NewSelfMonitoring(&testProvider{content: testSelfMonitoringConfig}).Get()
+	require.NoError(t, err)
+
+	assert.False(t, cp.IsSet(SelfMonitoringKey))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"job_name":        "otelcol-sumo",
+			"scrape_interval": "1m0s",
+			"static_configs": []interface{}{
+				map[string]interface{}{"targets": []interface{}{"localhost:8888"}},
+			},
+		},
+	}, cp.Get("receivers::prometheus/self_monitoring::config::scrape_configs"))
+	assert.Equal(t, "info", cp.Get("receivers::collector_logs/self_monitoring::level"))
+	assert.Equal(t, []interface{}{"system"}, cp.Get("processors::resourcedetection/self_monitoring::detectors"))
+
+	assert.Equal(t, map[string]interface{}{
+		"endpoint":        "http://local",
+		"log_format":      "otlp",
+		"source_category": "otelcol-sumo/self-monitoring",
+	}, cp.Get("exporters::sumologic/self_monitoring"))
+	assert.Nil(t, cp.Get("exporters::sumologic::source_category"))
+
+	assert.Equal(t, map[string]interface{}{
+		"receivers":  []interface{}{"prometheus/self_monitoring"},
+		"processors": []interface{}{"resourcedetection/self_monitoring"},
+		"exporters":  []interface{}{"sumologic/self_monitoring"},
+	}, cp.Get("service::pipelines::metrics/self_monitoring"))
+	assert.Equal(t, []interface{}{"collector_logs/self_monitoring"}, cp.Get("service::pipelines::logs/self_monitoring::receivers"))
+	assert.Equal(t, []interface{}{"filelog"}, cp.Get("service::pipelines::logs::receivers"))
+}
+
+func TestSelfMonitoringSettings(t *testing.T) {
+	cp, err := NewSelfMonitoring(&testProvider{content: `
+self_monitoring:
+  enabled: true
+  exporter: sumologic/metrics
+  source_category: fleet/otelcol
+  metrics_endpoint: localhost:9999
+  scrape_interval: 30s
+  log_level: warn
+exporters:
+  sumologic/logs:
+  sumologic/metrics:
+`}).Get()
+	require.NoError(t, err)
+
+	assert.Equal(t, "fleet/otelcol", cp.Get("exporters::sumologic/self_monitoring::source_category"))
+	assert.Equal(t, "warn", cp.Get("receivers::collector_logs/self_monitoring::level"))
+	scrapeConfig := cp.Get("receivers::prometheus/self_monitoring::config::scrape_configs").([]interface{})[0]
+	assert.Equal(t, "30s", scrapeConfig.(map[string]interface{})["scrape_interval"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"targets": []interface{}{"localhost:9999"}}},
+		scrapeConfig.(map[string]interface{})["static_configs"])
+}
+
+func TestSelfMonitoringDisabled(t *testing.T) {
+	cp, err := NewSelfMonitoring(&testProvider{content: "self_monitoring:\n  enabled: false\n" + testConfig}).Get()
+	require.NoError(t, err)
+	assert.False(t, cp.IsSet(SelfMonitoringKey))
+	assert.False(t, cp.IsSet("service::pipelines::logs/self_monitoring"))
+
+	cp, err = NewSelfMonitoring(&testProvider{content: testConfig}).Get()
+	require.NoError(t, err)
+	assert.False(t, cp.IsSet("service::pipelines::logs/self_monitoring"))
+}
+
+func TestSelfMonitoringInvalidConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "unknown key",
+			config: "self_monitoring:\n  enable: true\n",
+			err:    "invalid self_monitoring",
+		},
+		{
+			name:   "no exporter",
+			config: "self_monitoring:\n  enabled: true\nexporters:\n  nop:\n",
+			err:    "self_monitoring::exporter must be set when there is not exactly one sumologic exporter, got []",
+		},
+		{
+			name:   "many exporters",
+			config: "self_monitoring:\n  enabled: true\nexporters:\n  sumologic/b:\n  sumologic/a:\n",
+			err:    "self_monitoring::exporter must be set when there is not exactly one sumologic exporter, got [sumologic/a sumologic/b]",
+		},
+		{
+			name:   "not a sumologic exporter",
+			config: "self_monitoring:\n  enabled: true\n  exporter: nop\nexporters:\n  nop:\n",
+			err:    `self_monitoring::exporter must be a sumologic exporter, got "nop"`,
+		},
+		{
+			name:   "exporter not configured",
+			config: "self_monitoring:\n  enabled: true\n  exporter: sumologic/other\nexporters:\n  sumologic:\n",
+			err:    `self_monitoring::exporter "sumologic/other" is not configured`,
+		},
+		{
+			name:   "conflicting component",
+			config: "self_monitoring:\n  enabled: true\nexporters:\n  sumologic:\nprocessors:\n  resourcedetection/self_monitoring:\n",
+			err:    "processors::resourcedetection/self_monitoring is added by self_monitoring, it must not be configured",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewSelfMonitoring(&testProvider{content: tc.config}).Get()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/configparser"
+	"go.opentelemetry.io/collector/service/parserprovider"
+)
+
+const (
+	// StorageDirFlag is the flag of the directory, in which the collector persists its state
+	StorageDirFlag = "storage-dir"
+	// StorageExtension is the ID of the file storage extension added by the storage directory flag
+	StorageExtension = "file_storage"
+	// MinStorageFreeSpace is the free space required in the storage directory, in bytes
+	MinStorageFreeSpace = 100 * 1024 * 1024
+)
+
+// checkpointReceivers are the types of the receivers, which keep their checkpoints in the storage
+// extension set in their storage option. The receivers based on stanza, e.g. filelog, use the storage
+// extension automatically.
+var checkpointReceivers = map[string]bool{
+	"azureeventhub":     true,
+	"journald":          true,
+	"k8s_events":        true,
+	"windows_event_log": true,
+}
+
+// StorageDirFromArgs returns the value of the --storage-dir flag and the other arguments,
+// which are passed to the collector
+func StorageDirFromArgs(args []string) (string, []string, error) {
+	var (
+		dir  string
+		rest = make([]string, 0, len(args))
+	)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--"+StorageDirFlag:
+			if i+1 == len(args) {
+				return "", nil, fmt.Errorf("flag needs an argument: --%s", StorageDirFlag)
+			}
+			i++
+			dir = args[i]
+		case strings.HasPrefix(arg, "--"+StorageDirFlag+"="):
+			dir = strings.TrimPrefix(arg, "--"+StorageDirFlag+"=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return dir, rest, nil
+}
+
+// Storage sets up the persistence of the collector state in the storage directory: it adds the file
+// storage extension, sets it in the storage option of the receivers keeping the checkpoints
+// and enables the sending queues of the Sumo Logic exporters, persisted in the storage extension. The settings present in the configuration
+// are not changed.
+type Storage struct {
+	base parserprovider.ParserProvider
+	dir  string
+}
+
+var _ parserprovider.ParserProvider = (*Storage)(nil)
+
+// NewStorage returns the provider setting up the persistence in dir, which is disabled when dir is empty
+func NewStorage(base parserprovider.ParserProvider, dir string) *Storage {
+	return &Storage{
+		base: base,
+		dir:  dir,
+	}
+}
+
+// Get returns the configuration with the persistence set up
+func (s *Storage) Get() (*configparser.Parser, error) {
+	cp, err := s.base.Get()
+	if err != nil || s.dir == "" {
+		return cp, err
+	}
+	if err := checkStorageDir(s.dir); err != nil {
+		return nil, err
+	}
+
+	setDefault(cp, "extensions::"+StorageExtension+"::directory", s.dir)
+	extensions, ok := cp.Get("service::extensions").([]interface{})
+	if !ok && cp.IsSet("service::extensions") {
+		return nil, errors.New("service::extensions must be a list")
+	}
+	if !containsString(extensions, StorageExtension) {
+		cp.Set("service::extensions", append(extensions, StorageExtension))
+	}
+
+	for _, id := range componentIDs(cp, "receivers") {
+		if checkpointReceivers[componentType(id)] {
+			setDefault(cp, "receivers::"+id+"::storage", StorageExtension)
+		}
+	}
+	for _, id := range componentIDs(cp, "exporters") {
+		if componentType(id) == "sumologic" {
+			setDefault(cp, "exporters::"+id+"::sending_queue::enabled", true)
+			// The sending queue is persisted only if it isn't disabled in the configuration
+			if cp.Get("exporters::"+id+"::sending_queue::enabled") == true {
+				setDefault(cp, "exporters::"+id+"::storage", StorageExtension)
+			}
+		}
+	}
+	return cp, nil
+}
+
+// checkStorageDir creates the storage directory and checks if it is writable and has enough free space
+func checkStorageDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create the storage directory: %w", err)
+	}
+	f, err := ioutil.TempFile(dir, ".check")
+	if err != nil {
+		return fmt.Errorf("the storage directory is not writable: %w", err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	free, err := freeSpace(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check the free space in the storage directory: %w", err)
+	}
+	if free < MinStorageFreeSpace {
+		return fmt.Errorf("the storage directory %s has %d MiB of free space, at least %d MiB is required",
+			dir, free/1024/1024, MinStorageFreeSpace/1024/1024)
+	}
+	return nil
+}
+
+// setDefault sets the value if the key is not set, including the empty component settings, e.g. `nop:`
+func setDefault(cp *configparser.Parser, key string, value interface{}) {
+	if cp.Get(key) == nil {
+		cp.Set(key, value)
+	}
+}
+
+// componentIDs returns the IDs of the components in the section, e.g. receivers
+func componentIDs(cp *configparser.Parser, section string) []string {
+	components, ok := cp.Get(section).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	ids := make([]string, 0, len(components))
+	for id := range components {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// componentType returns the type of the component ID, e.g. sumologic for sumologic/logs
+func componentType(id string) string {
+	return strings.SplitN(id, "/", 2)[0]
+}
+
+func containsString(values []interface{}, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
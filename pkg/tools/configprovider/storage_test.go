@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageDirFromArgs(t *testing.T) {
+	dir, args, err := StorageDirFromArgs([]string{"--config", "config.yaml", "--storage-dir", "/var/lib/otelcol-sumo"})
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/otelcol-sumo", dir)
+	assert.Equal(t, []string{"--config", "config.yaml"}, args)
+
+	dir, args, err = StorageDirFromArgs([]string{"--storage-dir=/var/lib/otelcol-sumo", "--config=config.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/otelcol-sumo", dir)
+	assert.Equal(t, []string{"--config=config.yaml"}, args)
+
+	dir, args, err = StorageDirFromArgs([]string{"--config", "config.yaml"})
+	require.NoError(t, err)
+	assert.Empty(t, dir)
+	assert.Equal(t, []string{"--config", "config.yaml"}, args)
+
+	_, _, err = StorageDirFromArgs([]string{"--storage-dir"})
+	assert.EqualError(t, err, "flag needs an argument: --storage-dir")
+}
+
+func TestStorage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "storage")
+	s := NewStorage(&testProvider{content: `
+receivers:
+  journald:
+  k8s_events:
+    storage: file_storage/events
+  filelog:
+    include: [/var/log/*.log]
+exporters:
+  sumologic:
+  sumologic/traces:
+    sending_queue:
+      enabled: false
+extensions:
+  sumologic:
+    collector_name: test
+service:
+  extensions: [sumologic]
+  pipelines:
+    logs:
+      receivers: [journald, k8s_events, filelog]
+      exporters: [sumologic]
+`}, dir)
+
+	cp, err := s.Get()
+	require.NoError(t, err)
+	assert.DirExists(t, dir)
+	assert.Equal(t, dir, cp.Get("extensions::file_storage::directory"))
+	assert.Equal(t, []interface{}{"sumologic", "file_storage"}, cp.Get("service::extensions"))
+	assert.Equal(t, "file_storage", cp.Get("receivers::journald::storage"))
+	assert.Equal(t, "file_storage/events", cp.Get("receivers::k8s_events::storage"))
+	assert.Nil(t, cp.Get("receivers::filelog::storage"))
+	assert.Equal(t, true, cp.Get("exporters::sumologic::sending_queue::enabled"))
+	assert.Equal(t, "file_storage", cp.Get("exporters::sumologic::storage"))
+	assert.Equal(t, false, cp.Get("exporters::sumologic/traces::sending_queue::enabled"))
+	assert.Nil(t, cp.Get("exporters::sumologic/traces::storage"))
+}
+
+func TestStorageExistingExtension(t *testing.T) {
+	s := NewStorage(&testProvider{content: `
+extensions:
+  file_storage:
+    directory: /var/lib/custom
+service:
+  extensions: [file_storage]
+`}, t.TempDir())
+
+	cp, err := s.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/custom", cp.Get("extensions::file_storage::directory"))
+	assert.Equal(t, []interface{}{"file_storage"}, cp.Get("service::extensions"))
+}
+
+func TestStorageDisabled(t *testing.T) {
+	cp, err := NewStorage(&testProvider{content: testConfig}, "").Get()
+	require.NoError(t, err)
+	assert.False(t, cp.IsSet("extensions"))
+}
+
+func TestStorageInvalidDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, ioutil.WriteFile(file, nil, 0600))
+
+	_, err := NewStorage(&testProvider{content: testConfig}, file).Get()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create the storage directory")
+}
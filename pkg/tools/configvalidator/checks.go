@@ -0,0 +1,191 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configvalidator
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/config"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sumologicexporter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/sumologicextension"
+	cfconfig "github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor/config"
+)
+
+// check is a Sumo Logic specific check of the whole configuration. It reports the problems which
+// don't prevent the collector from starting, but most likely break the data ingestion.
+type check func(cfg *config.Config) []error
+
+var checks = []check{
+	checkSumologicExporters,
+	checkCascadingFilters,
+}
+
+// checkSumologicExporters checks if the exporters using the sumologic extension have it enabled and use
+// the formats it supports, if the endpoints are the URLs of HTTP sources and if the templates are valid
+func checkSumologicExporters(cfg *config.Config) []error {
+	var errs []error
+	for _, id := range sortedExporterIDs(cfg) {
+		exporterCfg, ok := cfg.Exporters[id].(*sumologicexporter.Config)
+		if !ok {
+			continue
+		}
+		report := func(format string, args ...interface{}) {
+			errs = append(errs, fmt.Errorf("exporters::%s: %s", id, fmt.Sprintf(format, args...)))
+		}
+
+		httpSettings := exporterCfg.HTTPClientSettings
+		switch {
+		case httpSettings.Endpoint == "" && httpSettings.Auth != nil &&
+			strings.HasPrefix(httpSettings.Auth.AuthenticatorName, "sumologic"):
+			if err := checkSumologicExtension(cfg, httpSettings.Auth.AuthenticatorName); err != nil {
+				report("%v", err)
+			}
+			if exporterCfg.LogFormat != sumologicexporter.OTLPLogFormat {
+				report("log_format %q is not supported with the sumologic extension, use otlp", exporterCfg.LogFormat)
+			}
+			if exporterCfg.MetricFormat != sumologicexporter.OTLPMetricFormat {
+				report("metric_format %q is not supported with the sumologic extension, use otlp", exporterCfg.MetricFormat)
+			}
+		case httpSettings.Endpoint != "":
+			if u, err := url.Parse(httpSettings.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				report("endpoint %q is not the URL of an HTTP source", httpSettings.Endpoint)
+			}
+		}
+
+		templates := map[string]string{
+			"source_category": exporterCfg.SourceCategory,
+			"source_name":     exporterCfg.SourceName,
+			"source_host":     exporterCfg.SourceHost,
+		}
+		if exporterCfg.MetricFormat == sumologicexporter.GraphiteFormat {
+			templates["graphite_template"] = exporterCfg.GraphiteTemplate
+		}
+		for _, name := range []string{"source_category", "source_name", "source_host", "graphite_template"} {
+			if err := checkTemplate(templates[name]); err != nil {
+				report("%s: %v", name, err)
+			}
+		}
+	}
+	return errs
+}
+
+// checkSumologicExtension checks if the authenticator is a sumologic extension enabled in the service
+func checkSumologicExtension(cfg *config.Config, authenticator string) error {
+	id, err := config.NewIDFromString(authenticator)
+	if err != nil {
+		return fmt.Errorf("invalid auth.authenticator %q: %w", authenticator, err)
+	}
+	extensionCfg, ok := cfg.Extensions[id]
+	if !ok {
+		return fmt.Errorf("auth.authenticator %q is not configured in extensions, "+
+			"configure the sumologic extension or set the endpoint", authenticator)
+	}
+	if _, ok := extensionCfg.(*sumologicextension.Config); !ok {
+		return fmt.Errorf("auth.authenticator %q is not a sumologic extension", authenticator)
+	}
+	for _, enabled := range cfg.Service.Extensions {
+		if enabled == id {
+			return nil
+		}
+	}
+	return fmt.Errorf("auth.authenticator %q is not enabled in service::extensions", authenticator)
+}
+
+// placeholderRegex matches the placeholders supported by the exporter templates, e.g. `%{k8s.pod.name}`
+var placeholderRegex = regexp.MustCompile(`^%\{[\w\.]+\}`)
+
+// checkTemplate checks if all `%` characters of the template start a placeholder, as anything else
+// would be either sent as is or break the formatting of the value
+func checkTemplate(template string) error {
+	rest := template
+	for {
+		i := strings.Index(rest, "%")
+		if i < 0 {
+			return nil
+		}
+		match := placeholderRegex.FindString(rest[i:])
+		if match == "" {
+			return fmt.Errorf("invalid placeholder at %q in template %q, use %%{attribute_name}", rest[i:], template)
+		}
+		rest = rest[i+len(match):]
+	}
+}
+
+// checkCascadingFilters checks if the budgets of the cascading filter processors allow sampling any traces
+func checkCascadingFilters(cfg *config.Config) []error {
+	var errs []error
+	for _, id := range sortedProcessorIDs(cfg) {
+		processorCfg, ok := cfg.Processors[id].(*cfconfig.Config)
+		if !ok {
+			continue
+		}
+		report := func(format string, args ...interface{}) {
+			errs = append(errs, fmt.Errorf("processors::%s: %s", id, fmt.Sprintf(format, args...)))
+		}
+
+		if processorCfg.SpansPerSecond <= 0 {
+			report("spans_per_second must be positive, got %d, so no traces would be sampled", processorCfg.SpansPerSecond)
+		}
+		if ratio := processorCfg.ProbabilisticFilteringRatio; ratio != nil && (*ratio < 0 || *ratio > 1) {
+			report("probabilistic_filtering_ratio must be between 0.0 and 1.0, got %v", *ratio)
+		}
+		// The policies are loaded from the file at runtime when reloading is enabled
+		if processorCfg.PoliciesReloadCfg != nil {
+			continue
+		}
+		for _, policy := range processorCfg.PolicyCfgs {
+			switch {
+			case policy.AlwaysKeep:
+			case policy.SpansPerSecond < -1:
+				report("policy %q: spans_per_second must be positive or -1 for no limit, got %d", policy.Name, policy.SpansPerSecond)
+			case policy.SpansPerSecond == 0:
+				report("policy %q: spans_per_second is 0, so the policy never samples any traces, "+
+					"set the budget or -1 for no limit", policy.Name)
+			case processorCfg.SpansPerSecond > 0 && policy.SpansPerSecond > processorCfg.SpansPerSecond:
+				report("policy %q: spans_per_second %d exceeds the total spans_per_second %d, so it is never used fully",
+					policy.Name, policy.SpansPerSecond, processorCfg.SpansPerSecond)
+			}
+		}
+	}
+	return errs
+}
+
+func sortedExporterIDs(cfg *config.Config) []config.ComponentID {
+	ids := make([]config.ComponentID, 0, len(cfg.Exporters))
+	for id := range cfg.Exporters {
+		ids = append(ids, id)
+	}
+	return sortIDs(ids)
+}
+
+func sortedProcessorIDs(cfg *config.Config) []config.ComponentID {
+	ids := make([]config.ComponentID, 0, len(cfg.Processors))
+	for id := range cfg.Processors {
+		ids = append(ids, id)
+	}
+	return sortIDs(ids)
+}
+
+func sortIDs(ids []config.ComponentID) []config.ComponentID {
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids
+}
@@ -0,0 +1,26 @@
+module github.com/open-telemetry/opentelemetry-collector-contrib/tools/configvalidator
+
+go 1.14
+
+require (
+	github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sumologicexporter v0.33.0
+	github.com/open-telemetry/opentelemetry-collector-contrib/extension/sumologicextension v0.33.0
+	github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor v0.33.0
+	github.com/spf13/cobra v1.2.1
+	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/collector v0.33.0
+)
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sumologicexporter => ../../exporter/sumologicexporter
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/extension/sumologicextension => ../../extension/sumologicextension
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/fips => ../../internal/fips
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/ottl => ../../internal/ottl
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor => ../../processor/cascadingfilterprocessor
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain => ../drain
+
+replace go.opentelemetry.io/collector => github.com/SumoLogic/opentelemetry-collector v0.33.0-sumo-1
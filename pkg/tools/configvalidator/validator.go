@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configvalidator implements the validate command of the collector, which loads
+// the configuration and reports all the problems found without starting the pipelines.
+package configvalidator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configunmarshaler"
+	"go.opentelemetry.io/collector/service/parserprovider"
+)
+
+// NewCommand returns the validate command. The configuration is loaded by the parser provider,
+// which reads the --config and --set flags, the same way the collector does.
+func NewCommand(factories component.Factories, parserProvider parserprovider.ParserProvider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "validate",
+		Short:        "Validate the configuration without starting the collector",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			errs := Validate(parserProvider, factories)
+			for _, err := range errs {
+				fmt.Fprintf(cmd.OutOrStdout(), "- %v\n", err)
+			}
+			if len(errs) > 0 {
+				return fmt.Errorf("found %d problem(s) in the configuration", len(errs))
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "The configuration is valid")
+			return nil
+		},
+	}
+
+	flagSet := new(flag.FlagSet)
+	parserprovider.Flags(flagSet)
+	cmd.Flags().AddGoFlagSet(flagSet)
+	return cmd
+}
+
+// Validate loads the configuration and returns all the problems found: the errors of the components
+// and the service, then the problems reported by the Sumo Logic specific checks
+func Validate(parserProvider parserprovider.ParserProvider, factories component.Factories) []error {
+	if closeable, ok := parserProvider.(parserprovider.Closeable); ok {
+		defer closeable.Close(context.Background())
+	}
+
+	cp, err := parserProvider.Get()
+	if err != nil {
+		return []error{fmt.Errorf("cannot load configuration's parser: %w", err)}
+	}
+	cfg, err := configunmarshaler.NewDefault().Unmarshal(cp, factories)
+	if err != nil {
+		return []error{fmt.Errorf("cannot load configuration: %w", err)}
+	}
+
+	errs := validateComponents(cfg)
+	// The components are validated again, so the service is validated only when all of them are valid
+	if len(errs) == 0 {
+		if err := cfg.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, check := range checks {
+		errs = append(errs, check(cfg)...)
+	}
+	return errs
+}
+
+// componentConfig is the configuration of a single component
+type componentConfig struct {
+	// kind is the configuration section of the component, e.g. exporters
+	kind string
+	id   config.ComponentID
+	cfg  interface{ Validate() error }
+}
+
+// validateComponents validates all the components, unlike config.Config.Validate which stops at the first error
+func validateComponents(cfg *config.Config) []error {
+	var components []componentConfig
+	for id, c := range cfg.Extensions {
+		components = append(components, componentConfig{kind: "extensions", id: id, cfg: c})
+	}
+	for id, c := range cfg.Receivers {
+		components = append(components, componentConfig{kind: "receivers", id: id, cfg: c})
+	}
+	for id, c := range cfg.Processors {
+		components = append(components, componentConfig{kind: "processors", id: id, cfg: c})
+	}
+	for id, c := range cfg.Exporters {
+		components = append(components, componentConfig{kind: "exporters", id: id, cfg: c})
+	}
+	// The problems are reported in a stable order
+	sort.SliceStable(components, func(i, j int) bool {
+		if components[i].kind != components[j].kind {
+			return kindOrder[components[i].kind] < kindOrder[components[j].kind]
+		}
+		return components[i].id.String() < components[j].id.String()
+	})
+
+	var errs []error
+	for _, c := range components {
+		if err := c.cfg.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s::%s: %w", c.kind, c.id, err))
+		}
+	}
+	return errs
+}
+
+var kindOrder = map[string]int{"extensions": 0, "receivers": 1, "processors": 2, "exporters": 3}
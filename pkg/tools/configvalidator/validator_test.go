@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configvalidator
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/service/parserprovider"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sumologicexporter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/sumologicextension"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cascadingfilterprocessor"
+)
+
+func testFactories(t *testing.T) component.Factories {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	exporterFactory := sumologicexporter.NewFactory()
+	factories.Exporters[exporterFactory.Type()] = exporterFactory
+	extensionFactory := sumologicextension.NewFactory()
+	factories.Extensions[extensionFactory.Type()] = extensionFactory
+	processorFactory := cascadingfilterprocessor.NewFactory()
+	factories.Processors[processorFactory.Type()] = processorFactory
+	return factories
+}
+
+func testParserProvider(t *testing.T, file string) parserprovider.ParserProvider {
+	f, err := os.Open(path.Join(".", "testdata", file))
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	return parserprovider.NewInMemory(f)
+}
+
+func TestValidate(t *testing.T) {
+	errs := Validate(testParserProvider(t, "valid.yaml"), testFactories(t))
+	assert.Empty(t, errs)
+}
+
+func TestValidateProblems(t *testing.T) {
+	errs := Validate(testParserProvider(t, "invalid.yaml"), testFactories(t))
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		"extensions::sumologic: access_key and/or access_id not provided, " +
+			"set them e.g. with access_id: ${SUMOLOGIC_ACCESS_ID} and access_key: ${SUMOLOGIC_ACCESS_KEY}",
+		`exporters::sumologic: log_format "json" is not supported with the sumologic extension, use otlp`,
+		`exporters::sumologic/graphite: graphite_template: invalid placeholder at "%{host" in template "%{_metric_}.%{host", use %{attribute_name}`,
+		`exporters::sumologic/http_source: endpoint "collectors.sumologic.com/receiver/v1/http/token" is not the URL of an HTTP source`,
+		`exporters::sumologic/http_source: source_category: invalid placeholder at "%" in template "%{namespace}/100%", use %{attribute_name}`,
+		`exporters::sumologic/other: auth.authenticator "sumologic/other" is not enabled in service::extensions`,
+		"processors::cascading_filter: probabilistic_filtering_ratio must be between 0.0 and 1.0, got 1.5",
+		`processors::cascading_filter: policy "no_budget": spans_per_second is 0, so the policy never samples any traces, set the budget or -1 for no limit`,
+		`processors::cascading_filter: policy "too_large_budget": spans_per_second 2000 exceeds the total spans_per_second 1000, so it is never used fully`,
+	}, messages)
+}
+
+func TestValidateLoadError(t *testing.T) {
+	errs := Validate(parserprovider.NewInMemory(bytes.NewBufferString("exporters:\n  unknown:\n")), testFactories(t))
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "cannot load configuration")
+}
+
+func TestCheckTemplate(t *testing.T) {
+	assert.NoError(t, checkTemplate(""))
+	assert.NoError(t, checkTemplate("kubernetes/%{k8s.namespace.name}/%{_sourceName}"))
+	assert.Error(t, checkTemplate("%{namespace"))
+	assert.Error(t, checkTemplate("%{}"))
+	assert.Error(t, checkTemplate("%s"))
+}
+
+func TestCommand(t *testing.T) {
+	cmd := NewCommand(testFactories(t), parserprovider.Default())
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"--config", path.Join(".", "testdata", "valid.yaml")})
+	require.NoError(t, cmd.Execute(), out.String())
+	assert.Equal(t, "The configuration is valid\n", out.String())
+
+	cmd = NewCommand(testFactories(t), parserprovider.Default())
+	out.Reset()
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"--config", path.Join(".", "testdata", "invalid.yaml")})
+	assert.EqualError(t, cmd.Execute(), "found 9 problem(s) in the configuration")
+	assert.Contains(t, out.String(), "- extensions::sumologic: access_key and/or access_id not provided")
+}
@@ -0,0 +1,217 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crashreport recovers the panics of the Sumo Logic components.
+//
+// The factories of the components are wrapped, so the panics in the calls of the collector, e.g. processing
+// the data, are recovered: the call returns an error, a crash report is written to the directory set with
+// OTELCOL_SUMO_CRASH_REPORT_DIR, and the restart counter metric is incremented. The component keeps running.
+package crashreport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricproducer"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+)
+
+// DirEnvVar is the environment variable setting the directory of the crash reports
+const DirEnvVar = "OTELCOL_SUMO_CRASH_REPORT_DIR"
+
+// reportInterval is the minimum interval between the crash reports of a component,
+// so a component panicking on every call doesn't fill the disk
+var reportInterval = time.Minute
+
+var (
+	tagKindKey, _      = tag.NewKey("kind")
+	tagComponentKey, _ = tag.NewKey("component")
+
+	statRestarts = stats.Int64("component_restarts", "Count of the panics recovered in the Sumo Logic components",
+		stats.UnitDimensionless)
+)
+
+func init() {
+	err := view.Register(&view.View{
+		Name:        statRestarts.Name(),
+		Measure:     statRestarts,
+		Description: statRestarts.Description(),
+		TagKeys:     []tag.Key{tagKindKey, tagComponentKey},
+		Aggregation: view.Sum(),
+	})
+	if err != nil {
+		panic("failed to register crashreport: " + err.Error())
+	}
+}
+
+// DefaultDir returns the default directory of the crash reports
+func DefaultDir() string {
+	return filepath.Join(os.TempDir(), "otelcol-sumo-crash-reports")
+}
+
+// DirFromEnv returns the directory of the crash reports set with DirEnvVar, or the default one
+func DirFromEnv() string {
+	if dir := os.Getenv(DirEnvVar); dir != "" {
+		return dir
+	}
+	return DefaultDir()
+}
+
+// Writer writes the crash reports of the recovered panics
+type Writer struct {
+	mu  sync.Mutex
+	dir string
+	// reported is the time of the last crash report, by the component
+	reported map[string]time.Time
+	now      func() time.Time
+}
+
+// NewWriter returns the writer of the crash reports to dir
+func NewWriter(dir string) *Writer {
+	return &Writer{
+		dir:      dir,
+		reported: map[string]time.Time{},
+		now:      time.Now,
+	}
+}
+
+// crash is the recovered panic of a component
+type crash struct {
+	kind       string
+	id         string
+	version    string
+	configHash string
+	panic      interface{}
+	stack      []byte
+}
+
+// handle reports the recovered panic and returns the error returned by the component call
+func (w *Writer) handle(c crash, logger *zap.Logger) error {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(tagKindKey, c.kind), tag.Upsert(tagComponentKey, c.id)},
+		statRestarts.M(1),
+	)
+
+	err := fmt.Errorf("%s %s recovered from panic: %v", c.kind, c.id, c.panic)
+	path, writeErr := w.write(c)
+	switch {
+	case writeErr != nil:
+		logger.Error("Recovered from panic, failed to write the crash report",
+			zap.Any("panic", c.panic), zap.Error(writeErr), zap.ByteString("stack", c.stack))
+	case path == "":
+		logger.Error("Recovered from panic, the crash report was written recently", zap.Any("panic", c.panic))
+	default:
+		logger.Error("Recovered from panic", zap.Any("panic", c.panic), zap.String("crash_report", path))
+	}
+	return err
+}
+
+// write writes the crash report and returns its path, which is empty when the component was reported recently
+func (w *Writer) write(c crash) (string, error) {
+	now := w.now()
+	key := c.kind + " " + c.id
+	w.mu.Lock()
+	if last, ok := w.reported[key]; ok && now.Sub(last) < reportInterval {
+		w.mu.Unlock()
+		return "", nil
+	}
+	w.reported[key] = now
+	w.mu.Unlock()
+
+	if err := os.MkdirAll(w.dir, 0700); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("crash-%s-%s-%s.txt", now.UTC().Format("20060102T150405.000Z"), c.kind,
+		unsafeFileChars.ReplaceAllString(c.id, "_"))
+	path := filepath.Join(w.dir, name)
+	return path, ioutil.WriteFile(path, []byte(report(c, now)), 0600)
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// report returns the content of the crash report
+func report(c crash, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "time: %s\n", now.UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "component: %s %s\n", c.kind, c.id)
+	fmt.Fprintf(&b, "version: %s\n", c.version)
+	fmt.Fprintf(&b, "config_hash: %s\n", c.configHash)
+	fmt.Fprintf(&b, "panic: %v\n", c.panic)
+	fmt.Fprintf(&b, "\nstack:\n%s\n", c.stack)
+	b.WriteString("metrics:\n")
+	for _, line := range metricsSnapshot() {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// configHash returns the hash of the component configuration, which identifies it without revealing the secrets
+func configHash(cfg interface{}) string {
+	content, err := json.Marshal(cfg)
+	if err != nil {
+		content = []byte(fmt.Sprintf("%+v", cfg))
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
+// metricsSnapshot returns the current values of the internal metrics of the collector, one time series per line
+func metricsSnapshot() []string {
+	var lines []string
+	for _, producer := range metricproducer.GlobalManager().GetAll() {
+		for _, metric := range producer.Read() {
+			for _, ts := range metric.TimeSeries {
+				if len(ts.Points) == 0 {
+					continue
+				}
+				labels := make([]string, 0, len(ts.LabelValues))
+				for i, value := range ts.LabelValues {
+					if value.Present && i < len(metric.Descriptor.LabelKeys) {
+						labels = append(labels, fmt.Sprintf("%s=%q", metric.Descriptor.LabelKeys[i].Key, value.Value))
+					}
+				}
+				lines = append(lines, fmt.Sprintf("%s{%s} %s", metric.Descriptor.Name, strings.Join(labels, ","),
+					pointValue(ts.Points[len(ts.Points)-1])))
+			}
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func pointValue(point metricdata.Point) string {
+	switch value := point.Value.(type) {
+	case *metricdata.Distribution:
+		return fmt.Sprintf("count=%d sum=%g", value.Count, value.Sum)
+	case *metricdata.Summary:
+		return fmt.Sprintf("count=%d sum=%g", value.Count, value.Sum)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crashreport
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+)
+
+// readReports returns the content of the crash reports in the directory
+func readReports(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	require.NoError(t, err)
+	reports := make([]string, 0, len(files))
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		reports = append(reports, string(content))
+	}
+	return reports
+}
+
+func TestDirFromEnv(t *testing.T) {
+	os.Unsetenv(DirEnvVar)
+	assert.Equal(t, filepath.Join(os.TempDir(), "otelcol-sumo-crash-reports"), DirFromEnv())
+
+	os.Setenv(DirEnvVar, "/var/lib/otelcol-sumo/crash")
+	defer os.Unsetenv(DirEnvVar)
+	assert.Equal(t, "/var/lib/otelcol-sumo/crash", DirFromEnv())
+}
+
+func TestWriterHandle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "crash")
+	w := NewWriter(dir)
+	now := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	w.now = func() time.Time {
+		return now
+	}
+	c := crash{
+		kind:       "processor",
+		id:         "source/k8s",
+		version:    "v0.0.33-beta.0",
+		configHash: configHash(map[string]string{"collector": "k8s"}),
+		panic:      "index out of range",
+		stack:      []byte("goroutine 1 [running]:\n"),
+	}
+
+	err := w.handle(c, zap.NewNop())
+	assert.EqualError(t, err, "processor source/k8s recovered from panic: index out of range")
+	content, err := ioutil.ReadFile(filepath.Join(dir, "crash-20210901T120000.000Z-processor-source_k8s.txt"))
+	require.NoError(t, err)
+	report := string(content)
+	assert.Contains(t, report, "time: 2021-09-01T12:00:00Z\n"+
+		"component: processor source/k8s\n"+
+		"version: v0.0.33-beta.0\n"+
+		"config_hash: sha256:")
+	assert.Contains(t, report, "panic: index out of range\n\nstack:\ngoroutine 1 [running]:\n")
+	assert.Contains(t, report, "\nmetrics:\n")
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	// the restart counter is incremented on every panic, the report is written once per interval
+	now = now.Add(reportInterval / 2)
+	require.Error(t, w.handle(c, zap.NewNop()))
+	assert.Len(t, readReports(t, dir), 1)
+	now = now.Add(reportInterval)
+	require.Error(t, w.handle(c, zap.NewNop()))
+	assert.Len(t, readReports(t, dir), 2)
+
+	rows, err := view.RetrieveData(statRestarts.Name())
+	require.NoError(t, err)
+	var restarts float64
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == tagComponentKey && tag.Value == "source/k8s" {
+				restarts = row.Data.(*view.SumData).Value
+			}
+		}
+	}
+	assert.Equal(t, float64(3), restarts)
+}
+
+func TestWriterHandleNotWritable(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, ioutil.WriteFile(file, nil, 0600))
+
+	err := NewWriter(file).handle(crash{kind: "exporter", id: "sumologic", panic: "nil map"}, zap.NewNop())
+	assert.EqualError(t, err, "exporter sumologic recovered from panic: nil map")
+}
+
+func TestMetricsSnapshot(t *testing.T) {
+	measure := stats.Int64("crashreport_test_records", "Count of the test records", stats.UnitDimensionless)
+	testView := &view.View{Name: measure.Name(), Measure: measure, Aggregation: view.Sum()}
+	require.NoError(t, view.Register(testView))
+	defer view.Unregister(testView)
+
+	stats.Record(context.Background(), measure.M(5))
+	// the recorded values are aggregated asynchronously
+	_, err := view.RetrieveData(measure.Name())
+	require.NoError(t, err)
+
+	assert.Contains(t, metricsSnapshot(), "crashreport_test_records{} 5")
+}
+
+func TestConfigHash(t *testing.T) {
+	type cfg struct {
+		Endpoint string
+		Callback func()
+	}
+	assert.Equal(t, configHash(cfg{Endpoint: "a"}), configHash(cfg{Endpoint: "a"}))
+	assert.NotEqual(t, configHash(cfg{Endpoint: "a"}), configHash(cfg{Endpoint: "b"}))
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", configHash(cfg{Callback: func() {}}))
+}
@@ -0,0 +1,332 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crashreport
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+)
+
+// Components are the types of the components, whose panics are recovered. The extensions are not wrapped,
+// as the other components find them by their type, e.g. the storage extensions.
+type Components struct {
+	Receivers  []config.Type
+	Processors []config.Type
+	Exporters  []config.Type
+}
+
+// Wrap returns the factories, in which the factories of the components recover their panics
+// and report them with the writer
+func (w *Writer) Wrap(factories component.Factories, components Components) component.Factories {
+	wrapped := component.Factories{
+		Receivers:  make(map[config.Type]component.ReceiverFactory, len(factories.Receivers)),
+		Processors: make(map[config.Type]component.ProcessorFactory, len(factories.Processors)),
+		Exporters:  make(map[config.Type]component.ExporterFactory, len(factories.Exporters)),
+		Extensions: factories.Extensions,
+	}
+	for typ, factory := range factories.Receivers {
+		wrapped.Receivers[typ] = factory
+	}
+	for typ, factory := range factories.Processors {
+		wrapped.Processors[typ] = factory
+	}
+	for typ, factory := range factories.Exporters {
+		wrapped.Exporters[typ] = factory
+	}
+
+	for _, typ := range components.Receivers {
+		if factory, ok := factories.Receivers[typ]; ok {
+			wrapped.Receivers[typ] = &receiverFactory{ReceiverFactory: factory, writer: w}
+		}
+	}
+	for _, typ := range components.Processors {
+		if factory, ok := factories.Processors[typ]; ok {
+			wrapped.Processors[typ] = &processorFactory{ProcessorFactory: factory, writer: w}
+		}
+	}
+	for _, typ := range components.Exporters {
+		if factory, ok := factories.Exporters[typ]; ok {
+			wrapped.Exporters[typ] = &exporterFactory{ExporterFactory: factory, writer: w}
+		}
+	}
+	return wrapped
+}
+
+// guard recovers the panics of a component
+type guard struct {
+	writer *Writer
+	logger *zap.Logger
+	crash  crash
+}
+
+func newGuard(w *Writer, kind string, id config.ComponentID, cfg interface{}, logger *zap.Logger,
+	info component.BuildInfo) *guard {
+	return &guard{
+		writer: w,
+		logger: logger,
+		crash: crash{
+			kind:       kind,
+			id:         id.String(),
+			version:    info.Version,
+			configHash: configHash(cfg),
+		},
+	}
+}
+
+// recover recovers the panic and sets the error returned by the call; it has to be deferred
+func (g *guard) recover(err *error) {
+	if r := recover(); r != nil {
+		c := g.crash
+		c.panic = r
+		c.stack = debug.Stack()
+		*err = g.writer.handle(c, g.logger)
+	}
+}
+
+// guardedComponent recovers the panics of the component lifecycle
+type guardedComponent struct {
+	*guard
+	component component.Component
+}
+
+func (c *guardedComponent) Start(ctx context.Context, host component.Host) (err error) {
+	defer c.recover(&err)
+	return c.component.Start(ctx, host)
+}
+
+func (c *guardedComponent) Shutdown(ctx context.Context) (err error) {
+	defer c.recover(&err)
+	return c.component.Shutdown(ctx)
+}
+
+type receiverFactory struct {
+	component.ReceiverFactory
+	writer *Writer
+
+	mu sync.Mutex
+	// receivers are the wrapped receivers, as the collector expects the same receiver for all the data types
+	receivers map[component.Receiver]*guardedReceiver
+}
+
+// guardedReceiver recovers the panics of the receiver lifecycle
+type guardedReceiver struct {
+	guardedComponent
+	factory *receiverFactory
+}
+
+func (r *guardedReceiver) Shutdown(ctx context.Context) error {
+	r.factory.mu.Lock()
+	delete(r.factory.receivers, r.component)
+	r.factory.mu.Unlock()
+	return r.guardedComponent.Shutdown(ctx)
+}
+
+func (f *receiverFactory) guard(cfg config.Receiver, set component.ReceiverCreateSettings) *guard {
+	return newGuard(f.writer, "receiver", cfg.ID(), cfg, set.Logger, set.BuildInfo)
+}
+
+// wrap returns the wrapped receiver, the same one for all the data types
+func (f *receiverFactory) wrap(g *guard, receiver component.Receiver) *guardedReceiver {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.receivers == nil {
+		f.receivers = map[component.Receiver]*guardedReceiver{}
+	}
+	wrapped, ok := f.receivers[receiver]
+	if !ok {
+		wrapped = &guardedReceiver{
+			guardedComponent: guardedComponent{guard: g, component: receiver},
+			factory:          f,
+		}
+		f.receivers[receiver] = wrapped
+	}
+	return wrapped
+}
+
+func (f *receiverFactory) CreateTracesReceiver(ctx context.Context, set component.ReceiverCreateSettings,
+	cfg config.Receiver, next consumer.Traces) (receiver component.TracesReceiver, err error) {
+	g := f.guard(cfg, set)
+	defer g.recover(&err)
+	receiver, err = f.ReceiverFactory.CreateTracesReceiver(ctx, set, cfg, next)
+	if err != nil || receiver == nil {
+		return receiver, err
+	}
+	return f.wrap(g, receiver), nil
+}
+
+func (f *receiverFactory) CreateMetricsReceiver(ctx context.Context, set component.ReceiverCreateSettings,
+	cfg config.Receiver, next consumer.Metrics) (receiver component.MetricsReceiver, err error) {
+	g := f.guard(cfg, set)
+	defer g.recover(&err)
+	receiver, err = f.ReceiverFactory.CreateMetricsReceiver(ctx, set, cfg, next)
+	if err != nil || receiver == nil {
+		return receiver, err
+	}
+	return f.wrap(g, receiver), nil
+}
+
+func (f *receiverFactory) CreateLogsReceiver(ctx context.Context, set component.ReceiverCreateSettings,
+	cfg config.Receiver, next consumer.Logs) (receiver component.LogsReceiver, err error) {
+	g := f.guard(cfg, set)
+	defer g.recover(&err)
+	receiver, err = f.ReceiverFactory.CreateLogsReceiver(ctx, set, cfg, next)
+	if err != nil || receiver == nil {
+		return receiver, err
+	}
+	return f.wrap(g, receiver), nil
+}
+
+// guardedConsumer recovers the panics of the component lifecycle and of consuming the data
+type guardedConsumer struct {
+	guardedComponent
+	capabilities consumer.Capabilities
+	traces       consumer.Traces
+	metrics      consumer.Metrics
+	logs         consumer.Logs
+}
+
+func (c *guardedConsumer) Capabilities() consumer.Capabilities {
+	return c.capabilities
+}
+
+func (c *guardedConsumer) ConsumeTraces(ctx context.Context, td pdata.Traces) (err error) {
+	defer c.recover(&err)
+	return c.traces.ConsumeTraces(ctx, td)
+}
+
+func (c *guardedConsumer) ConsumeMetrics(ctx context.Context, md pdata.Metrics) (err error) {
+	defer c.recover(&err)
+	return c.metrics.ConsumeMetrics(ctx, md)
+}
+
+func (c *guardedConsumer) ConsumeLogs(ctx context.Context, ld pdata.Logs) (err error) {
+	defer c.recover(&err)
+	return c.logs.ConsumeLogs(ctx, ld)
+}
+
+type processorFactory struct {
+	component.ProcessorFactory
+	writer *Writer
+}
+
+func (f *processorFactory) guard(cfg config.Processor, set component.ProcessorCreateSettings) *guard {
+	return newGuard(f.writer, "processor", cfg.ID(), cfg, set.Logger, set.BuildInfo)
+}
+
+func (f *processorFactory) CreateTracesProcessor(ctx context.Context, set component.ProcessorCreateSettings,
+	cfg config.Processor, next consumer.Traces) (processor component.TracesProcessor, err error) {
+	g := f.guard(cfg, set)
+	defer g.recover(&err)
+	processor, err = f.ProcessorFactory.CreateTracesProcessor(ctx, set, cfg, next)
+	if err != nil || processor == nil {
+		return processor, err
+	}
+	return &guardedConsumer{
+		guardedComponent: guardedComponent{guard: g, component: processor},
+		capabilities:     processor.Capabilities(),
+		traces:           processor,
+	}, nil
+}
+
+func (f *processorFactory) CreateMetricsProcessor(ctx context.Context, set component.ProcessorCreateSettings,
+	cfg config.Processor, next consumer.Metrics) (processor component.MetricsProcessor, err error) {
+	g := f.guard(cfg, set)
+	defer g.recover(&err)
+	processor, err = f.ProcessorFactory.CreateMetricsProcessor(ctx, set, cfg, next)
+	if err != nil || processor == nil {
+		return processor, err
+	}
+	return &guardedConsumer{
+		guardedComponent: guardedComponent{guard: g, component: processor},
+		capabilities:     processor.Capabilities(),
+		metrics:          processor,
+	}, nil
+}
+
+func (f *processorFactory) CreateLogsProcessor(ctx context.Context, set component.ProcessorCreateSettings,
+	cfg config.Processor, next consumer.Logs) (processor component.LogsProcessor, err error) {
+	g := f.guard(cfg, set)
+	defer g.recover(&err)
+	processor, err = f.ProcessorFactory.CreateLogsProcessor(ctx, set, cfg, next)
+	if err != nil || processor == nil {
+		return processor, err
+	}
+	return &guardedConsumer{
+		guardedComponent: guardedComponent{guard: g, component: processor},
+		capabilities:     processor.Capabilities(),
+		logs:             processor,
+	}, nil
+}
+
+type exporterFactory struct {
+	component.ExporterFactory
+	writer *Writer
+}
+
+func (f *exporterFactory) guard(cfg config.Exporter, set component.ExporterCreateSettings) *guard {
+	return newGuard(f.writer, "exporter", cfg.ID(), cfg, set.Logger, set.BuildInfo)
+}
+
+func (f *exporterFactory) CreateTracesExporter(ctx context.Context, set component.ExporterCreateSettings,
+	cfg config.Exporter) (exporter component.TracesExporter, err error) {
+	g := f.guard(cfg, set)
+	defer g.recover(&err)
+	exporter, err = f.ExporterFactory.CreateTracesExporter(ctx, set, cfg)
+	if err != nil || exporter == nil {
+		return exporter, err
+	}
+	return &guardedConsumer{
+		guardedComponent: guardedComponent{guard: g, component: exporter},
+		capabilities:     exporter.Capabilities(),
+		traces:           exporter,
+	}, nil
+}
+
+func (f *exporterFactory) CreateMetricsExporter(ctx context.Context, set component.ExporterCreateSettings,
+	cfg config.Exporter) (exporter component.MetricsExporter, err error) {
+	g := f.guard(cfg, set)
+	defer g.recover(&err)
+	exporter, err = f.ExporterFactory.CreateMetricsExporter(ctx, set, cfg)
+	if err != nil || exporter == nil {
+		return exporter, err
+	}
+	return &guardedConsumer{
+		guardedComponent: guardedComponent{guard: g, component: exporter},
+		capabilities:     exporter.Capabilities(),
+		metrics:          exporter,
+	}, nil
+}
+
+func (f *exporterFactory) CreateLogsExporter(ctx context.Context, set component.ExporterCreateSettings,
+	cfg config.Exporter) (exporter component.LogsExporter, err error) {
+	g := f.guard(cfg, set)
+	defer g.recover(&err)
+	exporter, err = f.ExporterFactory.CreateLogsExporter(ctx, set, cfg)
+	if err != nil || exporter == nil {
+		return exporter, err
+	}
+	return &guardedConsumer{
+		guardedComponent: guardedComponent{guard: g, component: exporter},
+		capabilities:     exporter.Capabilities(),
+		logs:             exporter,
+	}, nil
+}
@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crashreport
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// panicking is the component, which panics when its panic is set
+type panicking struct {
+	panic interface{}
+}
+
+func (p *panicking) Start(context.Context, component.Host) error {
+	if p.panic != nil {
+		panic(p.panic)
+	}
+	return nil
+}
+
+func (p *panicking) Shutdown(context.Context) error {
+	return nil
+}
+
+func (p *panicking) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (p *panicking) ConsumeLogs(context.Context, pdata.Logs) error {
+	if p.panic != nil {
+		panic(p.panic)
+	}
+	return nil
+}
+
+type panickingReceiverFactory struct {
+	component.ReceiverFactory
+	receiver *panicking
+}
+
+func (f *panickingReceiverFactory) CreateTracesReceiver(context.Context, component.ReceiverCreateSettings,
+	config.Receiver, consumer.Traces) (component.TracesReceiver, error) {
+	return f.receiver, nil
+}
+
+func (f *panickingReceiverFactory) CreateLogsReceiver(context.Context, component.ReceiverCreateSettings,
+	config.Receiver, consumer.Logs) (component.LogsReceiver, error) {
+	return f.receiver, nil
+}
+
+type panickingProcessorFactory struct {
+	component.ProcessorFactory
+	processor *panicking
+}
+
+func (f *panickingProcessorFactory) CreateLogsProcessor(context.Context, component.ProcessorCreateSettings,
+	config.Processor, consumer.Logs) (component.LogsProcessor, error) {
+	return f.processor, nil
+}
+
+func (f *panickingProcessorFactory) CreateMetricsProcessor(context.Context, component.ProcessorCreateSettings,
+	config.Processor, consumer.Metrics) (component.MetricsProcessor, error) {
+	panic("invalid config")
+}
+
+func TestWrapProcessor(t *testing.T) {
+	dir := t.TempDir()
+	processor := &panicking{}
+	factory := &panickingProcessorFactory{ProcessorFactory: componenttest.NewNopProcessorFactory(), processor: processor}
+	factories := NewWriter(dir).Wrap(component.Factories{
+		Processors: map[config.Type]component.ProcessorFactory{"nop": factory},
+	}, Components{Processors: []config.Type{"nop"}})
+
+	wrapped := factories.Processors["nop"]
+	cfg := wrapped.CreateDefaultConfig()
+	logs, err := wrapped.CreateLogsProcessor(context.Background(), componenttest.NewNopProcessorCreateSettings(),
+		cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, consumer.Capabilities{MutatesData: true}, logs.Capabilities())
+	require.NoError(t, logs.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, logs.ConsumeLogs(context.Background(), pdata.NewLogs()))
+
+	processor.panic = errors.New("boom")
+	err = logs.ConsumeLogs(context.Background(), pdata.NewLogs())
+	assert.EqualError(t, err, "processor nop recovered from panic: boom")
+	reports := readReports(t, dir)
+	require.Len(t, reports, 1)
+	assert.Contains(t, reports[0], "component: processor nop\n")
+	assert.Contains(t, reports[0], "panic: boom\n")
+	assert.Contains(t, reports[0], "config_hash: "+configHash(cfg)+"\n")
+	assert.Contains(t, reports[0], "(*panicking).ConsumeLogs")
+
+	_, err = wrapped.CreateMetricsProcessor(context.Background(), componenttest.NewNopProcessorCreateSettings(),
+		cfg, consumertest.NewNop())
+	assert.EqualError(t, err, "processor nop recovered from panic: invalid config")
+	// the component is reported once per interval
+	assert.Len(t, readReports(t, dir), 1)
+}
+
+func TestWrapReceiver(t *testing.T) {
+	dir := t.TempDir()
+	receiver := &panicking{panic: "start"}
+	factory := &panickingReceiverFactory{ReceiverFactory: componenttest.NewNopReceiverFactory(), receiver: receiver}
+	wrapped := NewWriter(dir).Wrap(component.Factories{
+		Receivers: map[config.Type]component.ReceiverFactory{"nop": factory},
+	}, Components{Receivers: []config.Type{"nop"}}).Receivers["nop"]
+
+	cfg := wrapped.CreateDefaultConfig()
+	traces, err := wrapped.CreateTracesReceiver(context.Background(), componenttest.NewNopReceiverCreateSettings(),
+		cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	logs, err := wrapped.CreateLogsReceiver(context.Background(), componenttest.NewNopReceiverCreateSettings(),
+		cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	// the collector expects the same receiver for all the data types
+	assert.Same(t, traces, logs)
+
+	err = traces.Start(context.Background(), componenttest.NewNopHost())
+	assert.EqualError(t, err, "receiver nop recovered from panic: start")
+	assert.Len(t, readReports(t, dir), 1)
+
+	require.NoError(t, traces.Shutdown(context.Background()))
+	logs, err = wrapped.CreateLogsReceiver(context.Background(), componenttest.NewNopReceiverCreateSettings(),
+		cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotSame(t, traces, logs)
+}
+
+func TestWrapExporter(t *testing.T) {
+	factories := NewWriter(t.TempDir()).Wrap(component.Factories{
+		Exporters:  map[config.Type]component.ExporterFactory{"nop": componenttest.NewNopExporterFactory()},
+		Processors: map[config.Type]component.ProcessorFactory{"nop": componenttest.NewNopProcessorFactory()},
+		Extensions: map[config.Type]component.ExtensionFactory{"nop": componenttest.NewNopExtensionFactory()},
+	}, Components{Exporters: []config.Type{"nop"}})
+
+	wrapped := factories.Exporters["nop"]
+	metrics, err := wrapped.CreateMetricsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(),
+		wrapped.CreateDefaultConfig())
+	require.NoError(t, err)
+	assert.IsType(t, &guardedConsumer{}, metrics)
+	require.NoError(t, metrics.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, metrics.ConsumeMetrics(context.Background(), pdata.NewMetrics()))
+	require.NoError(t, metrics.Shutdown(context.Background()))
+
+	// the other components are not wrapped
+	assert.Equal(t, componenttest.NewNopProcessorFactory(), factories.Processors["nop"])
+	assert.Equal(t, componenttest.NewNopExtensionFactory(), factories.Extensions["nop"])
+}
@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drain coordinates the graceful shutdown of the Sumo Logic components.
+//
+// The collector shuts down the receivers first, then the processors and the exporters. The components
+// flush their buffers and drain their queues when they are shut down, within the shutdown deadline
+// of the Coordinator they are created with, which lasts OTELCOL_SUMO_SHUTDOWN_TIMEOUT. The data which
+// can't be sent before the deadline is reported.
+package drain
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TimeoutEnvVar is the environment variable setting the shutdown timeout, e.g. OTELCOL_SUMO_SHUTDOWN_TIMEOUT=60s
+const TimeoutEnvVar = "OTELCOL_SUMO_SHUTDOWN_TIMEOUT"
+
+// DefaultTimeout is the default shutdown timeout, below the default termination grace period of Kubernetes
+const DefaultTimeout = 20 * time.Second
+
+// exitGracePeriod is the time after the deadline, after which the shutdown which is not complete expires
+var exitGracePeriod = 5 * time.Second
+
+// Coordinator holds the shutdown deadline and the report of the data left behind
+type Coordinator struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	started time.Time
+	logger  *zap.Logger
+	// left is the number of the items left behind, by the component and the unit
+	left map[string]int
+	// expired is done when the shutdown is not complete after the exit grace period
+	expired context.Context
+	cancel  context.CancelFunc
+}
+
+// NewCoordinator returns the coordinator with the shutdown timeout
+func NewCoordinator(timeout time.Duration) *Coordinator {
+	return &Coordinator{
+		timeout: timeout,
+		logger:  zap.NewNop(),
+		left:    map[string]int{},
+	}
+}
+
+// TimeoutFromEnv returns the shutdown timeout set with TimeoutEnvVar, or the default one
+func TimeoutFromEnv() (time.Duration, error) {
+	value := os.Getenv(TimeoutEnvVar)
+	if value == "" {
+		return DefaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration, e.g. 30s, got %q", TimeoutEnvVar, value)
+	}
+	return timeout, nil
+}
+
+// LoggingOption returns the option of the collector logger, which the data left behind is reported with
+func (c *Coordinator) LoggingOption() zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		c.mu.Lock()
+		c.logger = zap.New(core).With(zap.String("component", "shutdown"))
+		c.mu.Unlock()
+		return core
+	})
+}
+
+// Start starts the shutdown deadline, e.g. when the collector receives SIGTERM. The returned context is done
+// when the shutdown is still not complete after the exit grace period following the deadline.
+func (c *Coordinator) Start() context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.expired != nil {
+		return c.expired
+	}
+	c.started = time.Now()
+	c.expired, c.cancel = context.WithDeadline(context.Background(), c.started.Add(c.timeout+exitGracePeriod))
+	c.logger.Info("Shutting down", zap.Stringer("timeout", c.timeout))
+	return c.expired
+}
+
+// Deadline returns the shutdown deadline, which starts now when the collector is not shutting down,
+// e.g. when the components are shut down to reload the configuration
+func (c *Coordinator) Deadline() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started.IsZero() {
+		return time.Now().Add(c.timeout)
+	}
+	return c.started.Add(c.timeout)
+}
+
+// Context returns the context of the shutdown of a component, which is done at the shutdown deadline
+func (c *Coordinator) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithDeadline(ctx, c.Deadline())
+}
+
+// Report reports the items left behind by the component
+func (c *Coordinator) Report(component string, left int, unit string) {
+	if left <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.left[component+" "+unit] += left
+	c.logger.Warn("Data left behind on shutdown, as it wasn't sent before the deadline",
+		zap.String("name", component), zap.Int("left", left), zap.String("unit", unit))
+}
+
+// Summary returns the items left behind, e.g. "exporter/sumologic requests: 3"
+func (c *Coordinator) Summary() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	summary := make([]string, 0, len(c.left))
+	for key, left := range c.left {
+		summary = append(summary, fmt.Sprintf("%s: %d", key, left))
+	}
+	sort.Strings(summary)
+	return summary
+}
+
+// NotComplete returns the error of the shutdown, which was not complete before the deadline
+func (c *Coordinator) NotComplete() error {
+	return fmt.Errorf("shutdown was not complete before the deadline, left behind: %v", c.Summary())
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drain
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTimeoutFromEnv(t *testing.T) {
+	defer os.Unsetenv(TimeoutEnvVar)
+
+	timeout, err := TimeoutFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, timeout)
+
+	os.Setenv(TimeoutEnvVar, "1m")
+	timeout, err = TimeoutFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, timeout)
+
+	os.Setenv(TimeoutEnvVar, "soon")
+	_, err = TimeoutFromEnv()
+	assert.Error(t, err)
+
+	os.Setenv(TimeoutEnvVar, "0s")
+	_, err = TimeoutFromEnv()
+	assert.Error(t, err)
+}
+
+func TestDeadline(t *testing.T) {
+	c := NewCoordinator(time.Minute)
+
+	// Without the shutdown, the deadline starts when the component is shut down
+	ctx, cancel := c.Context(context.Background())
+	deadline, ok := ctx.Deadline()
+	cancel()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	c.started = time.Now().Add(-50 * time.Second)
+	ctx, cancel = c.Context(context.Background())
+	deadline, ok = ctx.Deadline()
+	cancel()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), deadline, time.Second)
+}
+
+func TestReport(t *testing.T) {
+	c := NewCoordinator(time.Minute)
+	c.Report("exporter/sumologic", 3, "requests")
+	c.Report("processor/cascading_filter", 0, "traces")
+	c.Report("exporter/sumologic", 2, "requests")
+	c.Report("processor/cascading_filter", 10, "traces")
+
+	assert.Equal(t, []string{
+		"exporter/sumologic requests: 5",
+		"processor/cascading_filter traces: 10",
+	}, c.Summary())
+}
+
+func TestStartExpiresWhenShutdownNotComplete(t *testing.T) {
+	previous := exitGracePeriod
+	exitGracePeriod = 10 * time.Millisecond
+	t.Cleanup(func() { exitGracePeriod = previous })
+
+	c := NewCoordinator(10 * time.Millisecond)
+	expired := c.Start()
+	assert.Equal(t, expired, c.Start())
+	select {
+	case <-expired.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the shutdown did not expire")
+	}
+
+	c.Report("exporter/sumologic", 2, "requests")
+	assert.EqualError(t, c.NotComplete(),
+		"shutdown was not complete before the deadline, left behind: [exporter/sumologic requests: 2]")
+}
+
+func TestLoggingOption(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	c := NewCoordinator(time.Minute)
+	zap.New(core, c.LoggingOption())
+
+	c.Start()
+	c.Report("processor/cascading_filter", 4, "traces")
+	require.Equal(t, 2, logs.Len())
+	entry := logs.All()[1]
+	assert.Equal(t, "Data left behind on shutdown, as it wasn't sent before the deadline", entry.Message)
+	assert.Equal(t, map[string]interface{}{
+		"component": "shutdown",
+		"name":      "processor/cascading_filter",
+		"left":      int64(4),
+		"unit":      "traces",
+	}, entry.ContextMap())
+}
@@ -0,0 +1,8 @@
+module github.com/open-telemetry/opentelemetry-collector-contrib/tools/drain
+
+go 1.14
+
+require (
+	github.com/stretchr/testify v1.7.0
+	go.uber.org/zap v1.19.0
+)